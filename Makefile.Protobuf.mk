@@ -96,6 +96,7 @@ proto-api-v2:
 	$(call proto_compile, proto-gen/api_v2, idl/proto/api_v2/query.proto)
 	$(call proto_compile, proto-gen/api_v2, idl/proto/api_v2/collector.proto)
 	$(call proto_compile, proto-gen/api_v2, idl/proto/api_v2/sampling.proto)
+	$(call proto_compile, proto-gen/api_v2, model/proto/api_v2/query_extensions.proto, -Imodel/proto/api_v2)
 
 .PHONY: proto-openmetrics
 proto-openmetrics:
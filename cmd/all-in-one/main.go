@@ -51,6 +51,7 @@ import (
 	"github.com/jaegertracing/jaeger/ports"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	metricsstoreMetrics "github.com/jaegertracing/jaeger/storage/metricsstore/metrics"
+	"github.com/jaegertracing/jaeger/storage/slowquery"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
@@ -100,7 +101,11 @@ by default uses only in-memory database.`,
 			collectorMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "collector"})
 			queryMetricsFactory := baseFactory.Namespace(metrics.NSOptions{Name: "query"})
 
-			tracer, err := jtracer.New("jaeger-all-in-one")
+			tracingOpts, err := new(jtracer.Options).InitFromViper(v)
+			if err != nil {
+				logger.Fatal("Failed to configure the self-tracing", zap.Error(err))
+			}
+			tracer, err := jtracer.NewFromOptions("jaeger-all-in-one", *tracingOpts)
 			if err != nil {
 				logger.Fatal("Failed to initialize tracer", zap.Error(err))
 			}
@@ -157,7 +162,10 @@ by default uses only in-memory database.`,
 				logger.Fatal("Failed to configure query service", zap.Error(err))
 			}
 
-			tm := tenancy.NewManager(&cOpts.GRPC.Tenancy)
+			tm, err := tenancy.NewManagerWithConfigFile(&cOpts.GRPC.Tenancy, logger)
+			if err != nil {
+				logger.Fatal("Failed to create the tenancy manager", zap.Error(err))
+			}
 
 			// collector
 			c := collectorApp.New(&collectorApp.CollectorParams{
@@ -173,6 +181,15 @@ by default uses only in-memory database.`,
 			if err := c.Start(cOpts); err != nil {
 				log.Fatal(err)
 			}
+			if topServices := c.TopServices(); topServices != nil {
+				svc.Admin.Handle(collectorApp.TopServicesPath, topServices)
+			}
+			if flushStorage := c.FlushStorageHandler(); flushStorage != nil {
+				svc.Admin.Handle(collectorApp.FlushStoragePath, flushStorage)
+			}
+			if status := c.StatusHandler(); status != nil {
+				svc.Admin.Handle(collectorApp.StatusPath, status)
+			}
 
 			// agent
 			// if the agent reporter grpc host:port was not explicitly set then use whatever the collector is listening on
@@ -206,6 +223,7 @@ by default uses only in-memory database.`,
 				_ = cp.Close()
 				_ = c.Close()
 				_ = querySrv.Close()
+				_ = tm.Close()
 				if closer, ok := spanWriter.(io.Closer); ok {
 					if err := closer.Close(); err != nil {
 						logger.Error("Failed to close span writer", zap.Error(err))
@@ -240,6 +258,7 @@ by default uses only in-memory database.`,
 		queryApp.AddFlags,
 		samplingStrategyFactory.AddFlags,
 		metricsReaderFactory.AddFlags,
+		jtracer.AddFlags,
 	)
 
 	if err := command.Execute(); err != nil {
@@ -278,11 +297,27 @@ func startQuery(
 	jt *jtracer.JTracer,
 ) *queryApp.Server {
 	spanReader = storageMetrics.NewReadMetricsDecorator(spanReader, metricsFactory)
+	if qOpts.SlowQueries.Threshold > 0 {
+		slowQueries := slowquery.NewLog(qOpts.SlowQueries, svc.Logger)
+		spanReader = slowquery.NewSpanReader(spanReader, slowQueries)
+		depReader = slowquery.NewDependencyReader(depReader, slowQueries)
+		svc.Admin.Handle(slowquery.Path, slowQueries)
+	}
+	queryOpts.MetricsFactory = metricsFactory
 	qs := querysvc.NewQueryService(spanReader, depReader, *queryOpts)
-	server, err := queryApp.NewServer(svc.Logger, svc.HC(), qs, metricsQueryService, qOpts, tm, jt)
+	server, err := queryApp.NewServer(svc.Logger, svc.HC(), metricsFactory, qs, metricsQueryService, qOpts, tm, jt)
 	if err != nil {
 		svc.Logger.Fatal("Could not create jaeger-query", zap.Error(err))
 	}
+	svc.Admin.Handle(queryApp.AdminTracesPath, queryApp.NewAdminHandler(qs, qOpts, tm, jt, svc.Logger))
+	svc.Admin.Handle(queryApp.AdminMaintenancePath, server.Maintenance())
+	if qOpts.AdminTokenFile != "" {
+		configHandler, err := queryApp.NewAdminConfigHandler(qOpts)
+		if err != nil {
+			svc.Logger.Fatal("Could not create the admin configuration handler", zap.Error(err))
+		}
+		svc.Admin.Handle(queryApp.AdminConfigPath, configHandler)
+	}
 	if err := server.Start(); err != nil {
 		svc.Logger.Fatal("Could not start jaeger-query", zap.Error(err))
 	}
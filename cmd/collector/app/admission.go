@@ -0,0 +1,71 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+)
+
+const (
+	// admissionUpdateInterval is how often the rejection probability is recomputed
+	admissionUpdateInterval = time.Second
+
+	// admissionQuantile is the quantile of the latency of the span writes compared to the target
+	admissionQuantile = 0.99
+)
+
+// admissionController rejects span batches while the p99 latency of the span writes over the window is above
+// the target. Like client-side adaptive throttling, the batches are rejected at random rather than all at once,
+// with the probability of the fraction of the load to shed for the latency to come back to the target, assuming
+// it grows with the load: (p99 - target) / p99, capped by the max rejection probability.
+type admissionController struct {
+	options   flags.AdmissionOptions
+	latencies *latencyWindow
+	random    func() float64
+
+	// rejectionProbability holds the bits of the float64 probability of rejecting a span batch
+	rejectionProbability atomic.Uint64
+}
+
+// newAdmissionController creates an admissionController reading the latencies of the span writes from latencies,
+// whose window must cover the one of the options.
+func newAdmissionController(options flags.AdmissionOptions, latencies *latencyWindow) *admissionController {
+	return &admissionController{
+		options:   options,
+		latencies: latencies,
+		random:    rand.Float64,
+	}
+}
+
+// p99 returns the estimated p99 latency of the span writes over the window ending at now,
+// and false if there were no span writes.
+func (ac *admissionController) p99(now time.Time) (time.Duration, bool) {
+	quantiles, count := ac.latencies.quantiles(now, ac.options.Window, admissionQuantile)
+	if count == 0 {
+		return 0, false
+	}
+	return quantiles[0], true
+}
+
+// update recomputes the probability of rejecting a span batch from the latency of the span writes
+// over the window ending at now, and returns the overload and that probability.
+func (ac *admissionController) update(now time.Time) (overload float64, probability float64) {
+	if p99, ok := ac.p99(now); ok && p99 > ac.options.TargetLatency {
+		overload = float64(p99-ac.options.TargetLatency) / float64(p99)
+		probability = min(overload, ac.options.MaxRejectionProbability)
+	}
+	ac.rejectionProbability.Store(math.Float64bits(probability))
+	return overload, probability
+}
+
+// admit returns false if a span batch is to be rejected.
+func (ac *admissionController) admit() bool {
+	probability := math.Float64frombits(ac.rejectionProbability.Load())
+	return probability == 0 || ac.random() >= probability
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestAdmissionControllerLatencyProfile(t *testing.T) {
+	options := flags.AdmissionOptions{
+		TargetLatency:           100 * time.Millisecond,
+		Window:                  10 * time.Second,
+		MaxRejectionProbability: 0.5,
+	}
+	ac := newAdmissionController(options, newLatencyWindow(options.Window))
+	// a scripted latency profile: the span writes take the latency of each phase for its duration
+	profile := []struct {
+		name      string
+		duration  time.Duration
+		latency   time.Duration
+		degrading bool
+		// the overload and the rejection probability expected at the end of the phase
+		overload    float64
+		probability float64
+	}{
+		{name: "healthy", duration: 10 * time.Second, latency: 50 * time.Millisecond},
+		{name: "mild overload", duration: 10 * time.Second, latency: 150 * time.Millisecond, degrading: true, overload: 1.0 / 3, probability: 1.0 / 3},
+		{name: "severe overload", duration: 10 * time.Second, latency: time.Second, degrading: true, overload: 0.9, probability: 0.5},
+		{name: "recovered", duration: 10 * time.Second, latency: 80 * time.Millisecond},
+	}
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var previousProbability float64
+	for _, phase := range profile {
+		var overload, probability float64
+		for end := now.Add(phase.duration); now.Before(end); now = now.Add(time.Second) {
+			// a hundred span writes per second, a few of them much faster
+			for i := 0; i < 100; i++ {
+				latency := phase.latency
+				if i%10 == 0 {
+					latency = time.Millisecond
+				}
+				ac.latencies.record(now.Add(time.Duration(i)*10*time.Millisecond), latency)
+			}
+			overload, probability = ac.update(now.Add(time.Second))
+			assert.LessOrEqual(t, probability, 0.5, phase.name)
+			if phase.degrading {
+				assert.GreaterOrEqual(t, probability, previousProbability, "%s: the rejection probability grows with the latency", phase.name)
+			}
+			previousProbability = probability
+		}
+		// the p99 estimate is within 25% above the actual one
+		assert.InDelta(t, phase.overload, overload, 0.1, phase.name)
+		assert.InDelta(t, phase.probability, probability, 0.1, phase.name)
+	}
+}
+
+func TestAdmissionControllerWithoutWrites(t *testing.T) {
+	options := flags.AdmissionOptions{TargetLatency: time.Millisecond, Window: time.Second, MaxRejectionProbability: 1}
+	ac := newAdmissionController(options, newLatencyWindow(options.Window))
+	now := time.Now()
+	ac.latencies.record(now, time.Second)
+	_, probability := ac.update(now)
+	assert.Greater(t, probability, 0.9)
+
+	// the writes older than the window are forgotten
+	overload, probability := ac.update(now.Add(2 * time.Second))
+	assert.Zero(t, overload)
+	assert.Zero(t, probability)
+	assert.True(t, ac.admit())
+}
+
+func TestAdmissionControllerSlowestWrites(t *testing.T) {
+	options := flags.AdmissionOptions{TargetLatency: time.Minute, Window: time.Second, MaxRejectionProbability: 1}
+	ac := newAdmissionController(options, newLatencyWindow(options.Window))
+	now := time.Now()
+	ac.latencies.record(now, time.Hour)
+	p99, ok := ac.p99(now)
+	require.True(t, ok)
+	assert.Greater(t, p99, time.Minute)
+	overload, _ := ac.update(now)
+	assert.Greater(t, overload, 0.0)
+}
+
+func TestAdmissionControllerAdmit(t *testing.T) {
+	options := flags.AdmissionOptions{TargetLatency: 100 * time.Millisecond, Window: time.Second, MaxRejectionProbability: 0.5}
+	ac := newAdmissionController(options, newLatencyWindow(options.Window))
+	now := time.Now()
+	ac.latencies.record(now, time.Second)
+	ac.update(now)
+
+	ac.random = func() float64 { return 0.3 }
+	assert.False(t, ac.admit())
+	ac.random = func() float64 { return 0.6 }
+	assert.True(t, ac.admit())
+}
+
+func TestSpanProcessorAdmission(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	w := &fakeSpanWriter{}
+	p := NewSpanProcessor(w,
+		nil,
+		Options.Admission(flags.AdmissionOptions{
+			TargetLatency:           100 * time.Millisecond,
+			Window:                  time.Minute,
+			MaxRejectionProbability: 0.5,
+		}),
+		Options.ServiceMetrics(mb),
+		Options.HostMetrics(mb),
+	).(*spanProcessor)
+	defer func() { require.NoError(t, p.Close()) }()
+	require.NotNil(t, p.admission)
+	p.admission.random = func() float64 { return 0.1 }
+
+	spans := []*model.Span{
+		{Process: &model.Process{ServiceName: "x"}},
+		{Process: &model.Process{ServiceName: "x"}},
+	}
+	_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+
+	p.stats.writes.record(time.Now(), 200*time.Millisecond)
+	p.updateAdmission()
+	_, err = p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.ErrorIs(t, err, processor.ErrBusy)
+
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "admission.spans.rejected", Value: 2})
+	// the p99 of 200ms is estimated at the bucket bound of 211ms, 53% over the target
+	mb.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "admission.overload-percent", Value: 53},
+		metricstest.ExpectedMetric{Name: "admission.rejection-percent", Value: 50},
+	)
+}
+
+func TestSpanProcessorWithoutAdmission(t *testing.T) {
+	p := newSpanProcessor(&fakeSpanWriter{}, nil)
+	assert.Nil(t, p.admission)
+}
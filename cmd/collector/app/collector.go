@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/trace"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
@@ -32,6 +34,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/collector/app/server"
 	"github.com/jaegertracing/jaeger/internal/safeexpvar"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -56,6 +59,11 @@ type Collector struct {
 	spanProcessor      processor.SpanProcessor
 	spanHandlers       *SpanHandlers
 	tenancyMgr         *tenancy.Manager
+	tracerProvider     trace.TracerProvider
+	topServices        *TopServices
+	traceSizes         *TraceSizes
+	flushStorage       http.Handler
+	status             http.Handler
 
 	// state, read only
 	hServer                    *http.Server
@@ -77,10 +85,16 @@ type CollectorParams struct {
 	SamplingAggregator samplingstrategy.Aggregator
 	HealthCheck        *healthcheck.HealthCheck
 	TenancyMgr         *tenancy.Manager
+	// TracerProvider traces the requests of the OTLP and Zipkin receivers, they are not traced when nil
+	TracerProvider trace.TracerProvider
 }
 
 // New constructs a new collector component, ready to be started
 func New(params *CollectorParams) *Collector {
+	tracerProvider := params.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = nooptrace.NewTracerProvider()
+	}
 	return &Collector{
 		serviceName:        params.ServiceName,
 		logger:             params.Logger,
@@ -90,6 +104,7 @@ func New(params *CollectorParams) *Collector {
 		samplingAggregator: params.SamplingAggregator,
 		hCheck:             params.HealthCheck,
 		tenancyMgr:         params.TenancyMgr,
+		tracerProvider:     tracerProvider,
 	}
 }
 
@@ -102,6 +117,14 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 		MetricsFactory: c.metricsFactory,
 		TenancyMgr:     c.tenancyMgr,
 	}
+	if options.TopServices.Size > 0 {
+		c.topServices = NewTopServices(options.TopServices, c.metricsFactory)
+		handlerBuilder.TopServices = c.topServices
+	}
+	if options.TraceSizes.SampleRatio > 0 {
+		c.traceSizes = NewTraceSizes(options.TraceSizes, c.metricsFactory)
+		handlerBuilder.TraceSizes = c.traceSizes
+	}
 
 	var additionalProcessors []ProcessSpan
 	if c.samplingAggregator != nil {
@@ -110,31 +133,55 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 		})
 	}
 
+	if err := grpccompression.Configure(c.metricsFactory, options.GRPCDisabledCompression); err != nil {
+		return err
+	}
+
 	c.spanProcessor = handlerBuilder.BuildSpanProcessor(additionalProcessors...)
 	c.spanHandlers = handlerBuilder.BuildHandlers(c.spanProcessor)
+	if f, ok := c.spanProcessor.(flusher); ok && options.FlushStorageEndpoint {
+		c.flushStorage = &flushStorageHandler{flusher: f, logger: c.logger}
+	}
+	if r, ok := c.spanProcessor.(statusReporter); ok {
+		c.status = &statusHandler{
+			processor:   r,
+			sampling:    c.samplingProvider,
+			serviceName: c.serviceName,
+			logger:      c.logger,
+			now:         time.Now,
+		}
+	}
 
 	grpcServer, err := server.StartGRPCServer(&server.GRPCServerParams{
 		HostPort:                options.GRPC.HostPort,
 		Handler:                 c.spanHandlers.GRPCHandler,
 		TLSConfig:               options.GRPC.TLS,
 		SamplingProvider:        c.samplingProvider,
+		TenancyMgr:              c.tenancyMgr,
 		Logger:                  c.logger,
 		MaxReceiveMessageLength: options.GRPC.MaxReceiveMessageLength,
 		MaxConnectionAge:        options.GRPC.MaxConnectionAge,
 		MaxConnectionAgeGrace:   options.GRPC.MaxConnectionAgeGrace,
+		ServerOptions:           options.GRPC.ServerOptions,
 	})
 	if err != nil {
 		return fmt.Errorf("could not start gRPC server: %w", err)
 	}
 	c.grpcServer = grpcServer
 
+	var xrayHandler *handler.XRayHandler
+	if options.XRay.Enabled {
+		xrayHandler = handler.NewXRayHandler(c.logger, c.spanProcessor)
+	}
 	httpServer, err := server.StartHTTPServer(&server.HTTPServerParams{
 		HostPort:         options.HTTP.HostPort,
 		Handler:          c.spanHandlers.JaegerBatchesHandler,
+		XRayHandler:      xrayHandler,
 		TLSConfig:        options.HTTP.TLS,
 		HealthCheck:      c.hCheck,
 		MetricsFactory:   c.metricsFactory,
 		SamplingProvider: c.samplingProvider,
+		TenancyMgr:       c.tenancyMgr,
 		Logger:           c.logger,
 	})
 	if err != nil {
@@ -149,7 +196,7 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 	if options.Zipkin.HTTPHostPort == "" {
 		c.logger.Info("Not listening for Zipkin HTTP traffic, port not configured")
 	} else {
-		zipkinReceiver, err := handler.StartZipkinReceiver(options, c.logger, c.spanProcessor, c.tenancyMgr)
+		zipkinReceiver, err := handler.StartZipkinReceiver(options, c.logger, c.spanProcessor, c.tenancyMgr, c.tracerProvider)
 		if err != nil {
 			return fmt.Errorf("could not start Zipkin receiver: %w", err)
 		}
@@ -157,7 +204,7 @@ func (c *Collector) Start(options *flags.CollectorOptions) error {
 	}
 
 	if options.OTLP.Enabled {
-		otlpReceiver, err := handler.StartOTLPReceiver(options, c.logger, c.spanProcessor, c.tenancyMgr)
+		otlpReceiver, err := handler.StartOTLPReceiver(options, c.logger, c.spanProcessor, c.tenancyMgr, c.tracerProvider)
 		if err != nil {
 			return fmt.Errorf("could not start OTLP receiver: %w", err)
 		}
@@ -176,6 +223,11 @@ func (*Collector) publishOpts(cOpts *flags.CollectorOptions) {
 
 // Close the component and all its underlying dependencies
 func (c *Collector) Close() error {
+	// Stop routing traffic to this instance while the span queue is being drained
+	if c.hCheck != nil {
+		c.hCheck.Set(healthcheck.Unavailable)
+	}
+
 	// Stop gRPC server
 	if c.grpcServer != nil {
 		c.grpcServer.GracefulStop()
@@ -212,6 +264,13 @@ func (c *Collector) Close() error {
 		c.logger.Error("failed to close span processor.", zap.Error(err))
 	}
 
+	if c.topServices != nil {
+		_ = c.topServices.Close()
+	}
+	if c.traceSizes != nil {
+		_ = c.traceSizes.Close()
+	}
+
 	// aggregator does not exist for all strategy stores. only Close() if exists.
 	if c.samplingAggregator != nil {
 		if err := c.samplingAggregator.Close(); err != nil {
@@ -233,6 +292,24 @@ func (c *Collector) Close() error {
 	return nil
 }
 
+// TopServices returns the services sending the most spans, to register on the admin server at TopServicesPath,
+// or nil if they are not tracked.
+func (c *Collector) TopServices() *TopServices {
+	return c.topServices
+}
+
+// FlushStorageHandler returns the endpoint flushing the span storage, to register on the admin server
+// at FlushStoragePath, or nil if it is not enabled.
+func (c *Collector) FlushStorageHandler() http.Handler {
+	return c.flushStorage
+}
+
+// StatusHandler returns the endpoint serving the status of the collector, to register on the admin server
+// at StatusPath, or nil if the span processor does not report it.
+func (c *Collector) StatusHandler() http.Handler {
+	return c.status
+}
+
 // SpanHandlers returns span handlers used by the Collector.
 func (c *Collector) SpanHandlers() *SpanHandlers {
 	return c.spanHandlers
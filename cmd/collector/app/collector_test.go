@@ -24,6 +24,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
@@ -45,6 +47,7 @@ func optionsForEphemeralPorts() *flags.CollectorOptions {
 	collectorOpts.OTLP.GRPC.HostPort = ":0"
 	collectorOpts.OTLP.HTTP.HostPort = ":0"
 	collectorOpts.Zipkin.HTTPHostPort = ":0"
+	collectorOpts.XRay.Enabled = true
 	return collectorOpts
 }
 
@@ -94,6 +97,16 @@ func TestNewCollector(t *testing.T) {
 	require.NoError(t, c.Close())
 }
 
+func TestNewCollectorTracerProvider(t *testing.T) {
+	c := New(&CollectorParams{Logger: zap.NewNop()})
+	assert.Equal(t, nooptrace.NewTracerProvider(), c.tracerProvider)
+
+	tracerProvider := sdktrace.NewTracerProvider()
+	defer tracerProvider.Shutdown(context.Background())
+	c = New(&CollectorParams{Logger: zap.NewNop(), TracerProvider: tracerProvider})
+	assert.Same(t, tracerProvider, c.tracerProvider)
+}
+
 func TestCollector_StartErrors(t *testing.T) {
 	run := func(name string, options *flags.CollectorOptions, expErr string) {
 		t.Run(name, func(t *testing.T) {
@@ -18,14 +18,19 @@ package flags
 import (
 	"flag"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/tailsampling"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/tagfilter"
 	"github.com/jaegertracing/jaeger/cmd/internal/flags"
 	"github.com/jaegertracing/jaeger/pkg/config/corscfg"
+	"github.com/jaegertracing/jaeger/pkg/config/grpccfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/ports"
 )
@@ -34,9 +39,37 @@ const (
 	flagDynQueueSizeMemory     = "collector.queue-size-memory"
 	flagNumWorkers             = "collector.num-workers"
 	flagQueueSize              = "collector.queue-size"
+	flagQueueDrainTimeout      = "collector.queue.drain-timeout"
 	flagCollectorTags          = "collector.tags"
 	flagSpanSizeMetricsEnabled = "collector.enable-span-size-metrics"
 
+	flagBackpressureThreshold    = "collector.backpressure.threshold"
+	flagBackpressureMode         = "collector.backpressure.mode"
+	flagBackpressureBlockTimeout = "collector.backpressure.block-timeout"
+
+	flagAdmissionTargetLatency           = "collector.admission.target-latency"
+	flagAdmissionWindow                  = "collector.admission.window"
+	flagAdmissionMaxRejectionProbability = "collector.admission.max-rejection-probability"
+
+	flagTailSamplingPolicyFile       = "collector.tailsampling.policy-file"
+	flagTailSamplingDecisionWait     = "collector.tailsampling.decision-wait"
+	flagTailSamplingRootSpanWait     = "collector.tailsampling.root-span-wait"
+	flagTailSamplingMaxSpans         = "collector.tailsampling.max-spans"
+	flagTailSamplingEvictionDecision = "collector.tailsampling.eviction-decision"
+
+	flagTagFilterDeny   = "collector.tag-filter.deny"
+	flagTagFilterAllow  = "collector.tag-filter.allow"
+	flagTagFilterDryRun = "collector.tag-filter.dry-run"
+
+	flagTopServicesSize   = "collector.top-services.size"
+	flagTopServicesWindow = "collector.top-services.window"
+
+	flagTraceSizesSampleRatio = "collector.trace-sizes.sample-ratio"
+	flagTraceSizesWindow      = "collector.trace-sizes.window"
+	flagTraceSizesMaxTraces   = "collector.trace-sizes.max-traces"
+
+	flagFlushStorageEndpoint = "collector.debug.flush-storage-endpoint"
+
 	flagSuffixHostPort = "host-port"
 
 	flagSuffixHTTPReadTimeout       = "read-timeout"
@@ -47,15 +80,43 @@ const (
 	flagSuffixGRPCMaxConnectionAge        = "max-connection-age"
 	flagSuffixGRPCMaxConnectionAgeGrace   = "max-connection-age-grace"
 
+	flagGRPCDisabledCompression = "collector.grpc.disabled-compression"
+
 	flagCollectorOTLPEnabled = "collector.otlp.enabled"
 
 	flagZipkinHTTPHostPort     = "collector.zipkin.host-port"
 	flagZipkinKeepAliveEnabled = "collector.zipkin.keep-alive"
 
+	flagCollectorXRayEnabled = "collector.xray.enabled"
+
 	// DefaultNumWorkers is the default number of workers consuming from the processor queue
 	DefaultNumWorkers = 50
 	// DefaultQueueSize is the size of the processor's queue
 	DefaultQueueSize = 2000
+	// DefaultQueueDrainTimeout is how long the processor's queue is drained on shutdown
+	DefaultQueueDrainTimeout = 5 * time.Second
+	// DefaultBackpressureBlockTimeout is how long a span batch waits for the span writer to catch up in block mode
+	DefaultBackpressureBlockTimeout = time.Second
+	// BackpressureModeReject rejects span batches as busy while the span writer is behind
+	BackpressureModeReject = "reject"
+	// BackpressureModeBlock holds span batches until the span writer catches up or the block timeout expires
+	BackpressureModeBlock = "block"
+	// DefaultAdmissionWindow is the period over which the span writes latency is measured for admission control
+	DefaultAdmissionWindow = 30 * time.Second
+	// DefaultAdmissionMaxRejectionProbability is the maximum probability of rejecting a span batch under overload
+	DefaultAdmissionMaxRejectionProbability = 0.5
+	// DefaultTailSamplingDecisionWait is how long the spans of a trace are buffered before it is tail sampled
+	DefaultTailSamplingDecisionWait = 10 * time.Second
+	// DefaultTailSamplingRootSpanWait is how long the spans of a trace are buffered after its root span
+	DefaultTailSamplingRootSpanWait = 2 * time.Second
+	// DefaultTailSamplingMaxSpans is the number of spans buffered for tail sampling above which traces are evicted
+	DefaultTailSamplingMaxSpans = 100_000
+	// DefaultTopServicesWindow is the period over which the span and byte rates of the top services are measured
+	DefaultTopServicesWindow = time.Minute
+	// DefaultTraceSizesWindow is how long the spans of a sampled trace are counted after its last span
+	DefaultTraceSizesWindow = 30 * time.Second
+	// DefaultTraceSizesMaxTraces is the number of sampled traces counted at once
+	DefaultTraceSizesMaxTraces = 10_000
 	// DefaultGRPCMaxReceiveMessageLength is the default max receivable message size for the gRPC Collector
 	DefaultGRPCMaxReceiveMessageLength = 4 * 1024 * 1024
 )
@@ -64,10 +125,16 @@ var grpcServerFlagsCfg = serverFlagsConfig{
 	// for legacy reasons the prefixes are different
 	prefix: "collector.grpc-server",
 	tls: tlscfg.ServerFlagsConfig{
-		Prefix: "collector.grpc",
+		Prefix:              "collector.grpc",
+		EnableALPNProtocols: true,
 	},
 }
 
+// grpcServerOptionsFlagsCfg configures the tuning of the collector's gRPC server, which the OTLP receiver does not support
+var grpcServerOptionsFlagsCfg = grpccfg.ServerFlagsConfig{
+	Prefix: "collector.grpc-server",
+}
+
 var httpServerFlagsCfg = serverFlagsConfig{
 	// for legacy reasons the prefixes are different
 	prefix: "collector.http-server",
@@ -114,6 +181,8 @@ type CollectorOptions struct {
 	DynQueueSizeMemory uint
 	// QueueSize is the size of collector's queue
 	QueueSize int
+	// QueueDrainTimeout is how long the collector keeps consuming its queue on shutdown
+	QueueDrainTimeout time.Duration
 	// NumWorkers is the number of internal workers in a collector
 	NumWorkers int
 	// HTTP section defines options for HTTP server
@@ -137,10 +206,72 @@ type CollectorOptions struct {
 		// KeepAlive configures allow Keep-Alive for Zipkin HTTP server
 		KeepAlive bool
 	}
+	// XRay section defines options for the endpoint accepting AWS X-Ray segment documents
+	XRay struct {
+		// Enabled registers the X-Ray endpoint on the collector's HTTP server
+		Enabled bool
+	}
 	// CollectorTags is the string representing collector tags to append to each and every span
 	CollectorTags map[string]string
 	// SpanSizeMetricsEnabled determines whether to enable metrics based on processed span size
 	SpanSizeMetricsEnabled bool
+	// Backpressure defines how the collector slows down span intake when the span writer falls behind
+	Backpressure BackpressureOptions
+	// Admission defines how the collector sheds span intake when span writes become slow
+	Admission AdmissionOptions
+	// TailSampling defines which traces the collector keeps once their spans are received, disabled without policies
+	TailSampling tailsampling.Options
+	// TagFilter defines the tags removed from the spans before they are written, disabled without patterns
+	TagFilter tagfilter.Options
+	// TopServices defines the per-service ingestion metrics of the services sending the most spans
+	TopServices TopServicesOptions
+	// TraceSizes defines the histograms of the sizes of the spans and traces received
+	TraceSizes TraceSizesOptions
+	// FlushStorageEndpoint enables the admin endpoint flushing the span storage, for tests
+	FlushStorageEndpoint bool
+	// GRPCDisabledCompression are the compression codecs refused by the gRPC and OTLP/gRPC servers
+	GRPCDisabledCompression []string
+}
+
+// BackpressureOptions defines how the collector slows down span intake when the span writer falls behind
+type BackpressureOptions struct {
+	// Threshold is the number of pending writes above which the span writer is considered behind, 0 disables backpressure
+	Threshold int64
+	// Mode is either BackpressureModeReject or BackpressureModeBlock
+	Mode string
+	// BlockTimeout is how long a span batch waits for the span writer in block mode before being rejected
+	BlockTimeout time.Duration
+}
+
+// TopServicesOptions defines the tracking of the services sending the most spans or bytes, which get
+// their own ingestion metrics, the other services being counted together
+type TopServicesOptions struct {
+	// Size is the number of top services tracked with their own metrics, 0 disables the tracking
+	Size int
+	// Window is the period over which the span and byte rates of the services are measured
+	Window time.Duration
+}
+
+// TraceSizesOptions defines the histograms of the sizes of the spans and of the numbers of spans and services
+// of the traces, recorded for a fraction of the traces
+type TraceSizesOptions struct {
+	// SampleRatio is the fraction of the traces recorded, selected by trace ID, 0 disables the histograms
+	SampleRatio float64
+	// Window is how long the spans of a trace are counted after its last span before the trace is recorded
+	Window time.Duration
+	// MaxTraces is the number of traces counted at once, above which the least recently updated traces are recorded
+	MaxTraces int
+}
+
+// AdmissionOptions defines how the collector rejects span batches, with a probability growing with the overload,
+// while the p99 latency of the span writes is above a target
+type AdmissionOptions struct {
+	// TargetLatency is the p99 latency of the span writes above which span batches are rejected, 0 disables admission control
+	TargetLatency time.Duration
+	// Window is the period over which the latency of the span writes is measured
+	Window time.Duration
+	// MaxRejectionProbability caps the probability of rejecting a span batch, so that some spans are still accepted
+	MaxRejectionProbability float64
 }
 
 type serverFlagsConfig struct {
@@ -178,6 +309,8 @@ type GRPCOptions struct {
 	// MaxConnectionAgeGrace is an additive period after MaxConnectionAge after which the connection will be forcibly closed.
 	// See gRPC's keepalive.ServerParameters#MaxConnectionAgeGrace.
 	MaxConnectionAgeGrace time.Duration
+	// ServerOptions tunes the gRPC server, it is only supported by the collector's gRPC server
+	ServerOptions grpccfg.ServerOptions
 	// Tenancy configures tenancy for endpoints that collect spans
 	Tenancy tenancy.Options
 }
@@ -186,12 +319,39 @@ type GRPCOptions struct {
 func AddFlags(flags *flag.FlagSet) {
 	flags.Int(flagNumWorkers, DefaultNumWorkers, "The number of workers pulling items from the queue")
 	flags.Int(flagQueueSize, DefaultQueueSize, "The queue size of the collector")
+	flags.Duration(flagQueueDrainTimeout, DefaultQueueDrainTimeout, "How long to keep writing spans remaining in the queue on shutdown before abandoning them; set to 0s to disable draining")
 	flags.Uint(flagDynQueueSizeMemory, 0, "(experimental) The max memory size in MiB to use for the dynamic queue.")
 	flags.String(flagCollectorTags, "", "One or more tags to be added to the Process tags of all spans passing through this collector. Ex: key1=value1,key2=${envVar:defaultValue}")
 	flags.Bool(flagSpanSizeMetricsEnabled, false, "Enables metrics based on processed span size, which are more expensive to calculate.")
+	flags.Int64(flagBackpressureThreshold, 0, "The number of writes pending in span storage above which incoming spans are throttled; only supported by Elasticsearch/OpenSearch, 0 disables backpressure")
+	flags.String(flagBackpressureMode, BackpressureModeReject, fmt.Sprintf("How incoming spans are throttled when span storage is behind: %q answers with a retryable busy error, %q waits for the storage to catch up", BackpressureModeReject, BackpressureModeBlock))
+	flags.Duration(flagBackpressureBlockTimeout, DefaultBackpressureBlockTimeout, "How long incoming spans wait for span storage to catch up in block mode before being rejected as busy")
+	flags.Duration(flagAdmissionTargetLatency, 0, "The p99 latency of span storage writes above which incoming spans are probabilistically rejected with a retryable busy error, the more the higher the latency; 0 disables admission control")
+	flags.Duration(flagAdmissionWindow, DefaultAdmissionWindow, "The period over which the latency of span storage writes is measured for admission control")
+	flags.Float64(flagAdmissionMaxRejectionProbability, DefaultAdmissionMaxRejectionProbability, "The maximum probability, between 0 and 1, of rejecting incoming spans for admission control")
+	flags.String(flagTailSamplingPolicyFile, "", "(experimental) The path of the YAML file of the policies deciding which traces are kept once their spans are buffered; enables tail sampling. The decisions are local to each collector, spans of a trace received by other collectors are decided independently")
+	flags.Duration(flagTailSamplingDecisionWait, DefaultTailSamplingDecisionWait, "How long the spans of a trace are buffered after its first span before the trace is tail sampled")
+	flags.Duration(flagTailSamplingRootSpanWait, DefaultTailSamplingRootSpanWait, "How long the spans of a trace are buffered after its root span before the trace is tail sampled, if shorter than the decision wait")
+	flags.Int(flagTailSamplingMaxSpans, DefaultTailSamplingMaxSpans, "The number of spans buffered for tail sampling above which the oldest traces are evicted, bounding the memory used")
+	flags.String(flagTailSamplingEvictionDecision, tailsampling.EvictionDecisionDrop, fmt.Sprintf("Whether the traces evicted from the full tail sampling buffer are kept or dropped, regardless of the policies: %q or %q", tailsampling.EvictionDecisionKeep, tailsampling.EvictionDecisionDrop))
+	flags.String(flagTagFilterDeny, "", "Comma-separated list of regular expressions matching the whole keys of the span tags, log fields and process tags removed before spans are written, e.g. \"thread\\..*,internal\\..*\"; tags the UI relies on, such as span.kind and error, are always kept")
+	flags.String(flagTagFilterAllow, "", "Comma-separated list of regular expressions matching the whole keys of the only span tags, log fields and process tags kept before spans are written, along with the tags the UI relies on; the deny list takes precedence")
+	flags.Bool(flagTagFilterDryRun, false, "Only count the tags the tag filter would remove, by key prefix, without removing them")
+	flags.Int(flagTopServicesSize, 0, "The number of services sending the most spans or bytes reported with their own received, dropped and bytes metrics, "+
+		"the other services being counted as \"other\", and listed by /debug/top-services on the admin server; 0 disables the tracking")
+	flags.Duration(flagTopServicesWindow, DefaultTopServicesWindow, "The sliding window over which the span and byte rates of the top services are measured")
+	flags.Float64(flagTraceSizesSampleRatio, 0, "The fraction of the traces, selected by trace ID, whose span sizes and numbers of spans and services are recorded in histograms, "+
+		"e.g. for capacity planning; 0 disables the histograms")
+	flags.Duration(flagTraceSizesWindow, DefaultTraceSizesWindow, "How long the spans of a trace are counted after its last span before its numbers of spans and services are recorded")
+	flags.Int(flagTraceSizesMaxTraces, DefaultTraceSizesMaxTraces, "The number of traces whose spans are counted at once, above which the least recently updated traces are recorded early, bounding the memory used")
+	flags.Bool(flagFlushStorageEndpoint, false, "(for tests) Enable POST /debug/flush-storage on the admin server, which returns once the spans received before it are written "+
+		"and visible to the reads, if the span storage supports it, e.g. memory and badger")
 
 	addHTTPFlags(flags, httpServerFlagsCfg, ports.PortToHostPort(ports.CollectorHTTP))
 	addGRPCFlags(flags, grpcServerFlagsCfg, ports.PortToHostPort(ports.CollectorGRPC))
+	grpcServerOptionsFlagsCfg.AddFlags(flags)
+	flags.String(flagGRPCDisabledCompression, "", fmt.Sprintf("Comma-separated list of the compression codecs (%s) refused by the collector's gRPC and OTLP/gRPC servers, "+
+		"the calls compressed with them failing", strings.Join(grpccompression.Codecs, ", ")))
 
 	flags.Bool(flagCollectorOTLPEnabled, true, "Enables OpenTelemetry OTLP receiver on dedicated HTTP and gRPC ports")
 	addHTTPFlags(flags, otlpServerFlagsCfg.HTTP, "")
@@ -203,6 +363,8 @@ func AddFlags(flags *flag.FlagSet) {
 	tlsZipkinFlagsConfig.AddFlags(flags)
 	corsZipkinFlags.AddFlags(flags)
 
+	flags.Bool(flagCollectorXRayEnabled, false, "Enables the AWS X-Ray receiver, accepting PutTraceSegments payloads at /TraceSegments on the collector's HTTP port")
+
 	tenancy.AddFlags(flags)
 }
 
@@ -267,9 +429,49 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper, logger *zap.Logger)
 	cOpts.CollectorTags = flags.ParseJaegerTags(v.GetString(flagCollectorTags))
 	cOpts.NumWorkers = v.GetInt(flagNumWorkers)
 	cOpts.QueueSize = v.GetInt(flagQueueSize)
+	cOpts.QueueDrainTimeout = v.GetDuration(flagQueueDrainTimeout)
 	cOpts.DynQueueSizeMemory = v.GetUint(flagDynQueueSizeMemory) * 1024 * 1024 // we receive in MiB and store in bytes
 	cOpts.SpanSizeMetricsEnabled = v.GetBool(flagSpanSizeMetricsEnabled)
 
+	cOpts.Backpressure.Threshold = v.GetInt64(flagBackpressureThreshold)
+	cOpts.Backpressure.Mode = v.GetString(flagBackpressureMode)
+	cOpts.Backpressure.BlockTimeout = v.GetDuration(flagBackpressureBlockTimeout)
+	if mode := cOpts.Backpressure.Mode; mode != BackpressureModeReject && mode != BackpressureModeBlock {
+		return cOpts, fmt.Errorf("invalid backpressure mode %q, expected %q or %q", mode, BackpressureModeReject, BackpressureModeBlock)
+	}
+
+	cOpts.Admission.TargetLatency = v.GetDuration(flagAdmissionTargetLatency)
+	cOpts.Admission.Window = v.GetDuration(flagAdmissionWindow)
+	cOpts.Admission.MaxRejectionProbability = v.GetFloat64(flagAdmissionMaxRejectionProbability)
+	if a := cOpts.Admission; a.TargetLatency < 0 || a.Window <= 0 || a.MaxRejectionProbability < 0 || a.MaxRejectionProbability > 1 {
+		return cOpts, fmt.Errorf("invalid admission control options: the target latency must not be negative, "+
+			"the window must be positive and the max rejection probability between 0 and 1, got %s, %s and %v",
+			a.TargetLatency, a.Window, a.MaxRejectionProbability)
+	}
+
+	if err := cOpts.initTailSamplingFromViper(v); err != nil {
+		return cOpts, err
+	}
+
+	if err := cOpts.initTagFilterFromViper(v); err != nil {
+		return cOpts, err
+	}
+
+	cOpts.TopServices.Size = v.GetInt(flagTopServicesSize)
+	cOpts.TopServices.Window = v.GetDuration(flagTopServicesWindow)
+	if t := cOpts.TopServices; t.Size < 0 || t.Window <= 0 {
+		return cOpts, fmt.Errorf("invalid top services options: the size must not be negative and the window must be positive, got %d and %s",
+			t.Size, t.Window)
+	}
+	cOpts.TraceSizes.SampleRatio = v.GetFloat64(flagTraceSizesSampleRatio)
+	cOpts.TraceSizes.Window = v.GetDuration(flagTraceSizesWindow)
+	cOpts.TraceSizes.MaxTraces = v.GetInt(flagTraceSizesMaxTraces)
+	if t := cOpts.TraceSizes; t.SampleRatio < 0 || t.SampleRatio > 1 || t.Window <= 0 || t.MaxTraces <= 0 {
+		return cOpts, fmt.Errorf("invalid trace sizes options: the sample ratio must be between 0 and 1, and the window and the max traces must be positive, got %v, %s and %d",
+			t.SampleRatio, t.Window, t.MaxTraces)
+	}
+	cOpts.FlushStorageEndpoint = v.GetBool(flagFlushStorageEndpoint)
+
 	if err := cOpts.HTTP.initFromViper(v, logger, httpServerFlagsCfg); err != nil {
 		return cOpts, fmt.Errorf("failed to parse HTTP server options: %w", err)
 	}
@@ -277,6 +479,20 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper, logger *zap.Logger)
 	if err := cOpts.GRPC.initFromViper(v, logger, grpcServerFlagsCfg); err != nil {
 		return cOpts, fmt.Errorf("failed to parse gRPC server options: %w", err)
 	}
+	grpcServerOptions, err := grpcServerOptionsFlagsCfg.InitFromViper(v)
+	if err != nil {
+		return cOpts, err
+	}
+	cOpts.GRPC.ServerOptions = grpcServerOptions
+	cOpts.GRPCDisabledCompression = nil
+	for _, codec := range strings.Split(v.GetString(flagGRPCDisabledCompression), ",") {
+		if codec = strings.TrimSpace(codec); codec != "" {
+			cOpts.GRPCDisabledCompression = append(cOpts.GRPCDisabledCompression, codec)
+		}
+	}
+	if err := grpccompression.Validate(cOpts.GRPCDisabledCompression); err != nil {
+		return cOpts, err
+	}
 
 	cOpts.OTLP.Enabled = v.GetBool(flagCollectorOTLPEnabled)
 	if err := cOpts.OTLP.HTTP.initFromViper(v, logger, otlpServerFlagsCfg.HTTP); err != nil {
@@ -296,5 +512,54 @@ func (cOpts *CollectorOptions) InitFromViper(v *viper.Viper, logger *zap.Logger)
 	cOpts.Zipkin.TLS = tlsZipkin
 	cOpts.Zipkin.CORS = corsZipkinFlags.InitFromViper(v)
 
+	cOpts.XRay.Enabled = v.GetBool(flagCollectorXRayEnabled)
+
 	return cOpts, nil
 }
+
+func (cOpts *CollectorOptions) initTailSamplingFromViper(v *viper.Viper) error {
+	ts := &cOpts.TailSampling
+	ts.DecisionWait = v.GetDuration(flagTailSamplingDecisionWait)
+	ts.RootSpanWait = v.GetDuration(flagTailSamplingRootSpanWait)
+	ts.MaxSpans = v.GetInt(flagTailSamplingMaxSpans)
+	ts.EvictionDecision = v.GetString(flagTailSamplingEvictionDecision)
+	if ts.DecisionWait <= 0 || ts.RootSpanWait <= 0 || ts.MaxSpans <= 0 {
+		return fmt.Errorf("invalid tail sampling options: the decision wait, the root span wait and the max spans must be positive, got %s, %s and %d",
+			ts.DecisionWait, ts.RootSpanWait, ts.MaxSpans)
+	}
+	if d := ts.EvictionDecision; d != tailsampling.EvictionDecisionKeep && d != tailsampling.EvictionDecisionDrop {
+		return fmt.Errorf("invalid tail sampling eviction decision %q, expected %q or %q", d, tailsampling.EvictionDecisionKeep, tailsampling.EvictionDecisionDrop)
+	}
+	if path := v.GetString(flagTailSamplingPolicyFile); path != "" {
+		policies, err := tailsampling.LoadPolicyFile(path)
+		if err != nil {
+			return err
+		}
+		ts.Policies = policies
+	}
+	return nil
+}
+
+func (cOpts *CollectorOptions) initTagFilterFromViper(v *viper.Viper) error {
+	tf := &cOpts.TagFilter
+	var err error
+	if tf.Deny, err = tagfilter.ParsePatterns(splitList(v.GetString(flagTagFilterDeny))); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", flagTagFilterDeny, err)
+	}
+	if tf.Allow, err = tagfilter.ParsePatterns(splitList(v.GetString(flagTagFilterAllow))); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", flagTagFilterAllow, err)
+	}
+	tf.DryRun = v.GetBool(flagTagFilterDryRun)
+	return nil
+}
+
+// splitList parses a comma-separated list, ignoring whitespace and empty elements
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -15,6 +15,8 @@
 package flags
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -22,7 +24,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/tailsampling"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/grpccfg"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 )
 
@@ -123,6 +127,249 @@ func TestCollectorOptionsWithFlags_CheckMaxReceiveMessageLength(t *testing.T) {
 	assert.Equal(t, 8388608, c.GRPC.MaxReceiveMessageLength)
 }
 
+func TestCollectorOptionsWithFlags_CheckGRPCServerOptions(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--collector.grpc-server.max-send-message-size=1048576",
+		"--collector.grpc-server.keepalive.time=2m",
+		"--collector.grpc-server.keepalive.timeout=10s",
+		"--collector.grpc-server.keepalive.permit-without-stream=true",
+		"--collector.grpc-server.disable-reflection=true",
+	})
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, grpccfg.ServerOptions{
+		MaxSendMessageSize:           1048576,
+		KeepaliveTime:                2 * time.Minute,
+		KeepaliveTimeout:             10 * time.Second,
+		KeepalivePermitWithoutStream: true,
+		DisableReflection:            true,
+	}, c.GRPC.ServerOptions)
+	assert.Equal(t, grpccfg.ServerOptions{}, c.OTLP.GRPC.ServerOptions)
+
+	command.ParseFlags([]string{
+		"--collector.grpc-server.max-send-message-size=-1",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "the maximum send message size cannot be negative")
+}
+
+func TestCollectorOptionsWithFlags_CheckQueueDrainTimeout(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultQueueDrainTimeout, c.QueueDrainTimeout)
+
+	command.ParseFlags([]string{
+		"--collector.queue.drain-timeout=30s",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, c.QueueDrainTimeout)
+}
+
+func TestCollectorOptionsWithFlags_CheckBackpressure(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, BackpressureOptions{
+		Mode:         BackpressureModeReject,
+		BlockTimeout: DefaultBackpressureBlockTimeout,
+	}, c.Backpressure)
+
+	command.ParseFlags([]string{
+		"--collector.backpressure.threshold=5000",
+		"--collector.backpressure.mode=block",
+		"--collector.backpressure.block-timeout=3s",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, BackpressureOptions{
+		Threshold:    5000,
+		Mode:         BackpressureModeBlock,
+		BlockTimeout: 3 * time.Second,
+	}, c.Backpressure)
+
+	command.ParseFlags([]string{
+		"--collector.backpressure.mode=drop",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `invalid backpressure mode "drop"`)
+}
+
+func TestCollectorOptionsWithFlags_CheckAdmission(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, AdmissionOptions{
+		Window:                  DefaultAdmissionWindow,
+		MaxRejectionProbability: DefaultAdmissionMaxRejectionProbability,
+	}, c.Admission)
+
+	command.ParseFlags([]string{
+		"--collector.admission.target-latency=200ms",
+		"--collector.admission.window=1m",
+		"--collector.admission.max-rejection-probability=0.8",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, AdmissionOptions{
+		TargetLatency:           200 * time.Millisecond,
+		Window:                  time.Minute,
+		MaxRejectionProbability: 0.8,
+	}, c.Admission)
+
+	for _, flag := range []string{
+		"--collector.admission.target-latency=-1s",
+		"--collector.admission.window=0s",
+		"--collector.admission.max-rejection-probability=1.5",
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err = c.InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid admission control options", flag)
+	}
+}
+
+func TestCollectorOptionsWithFlags_CheckTailSampling(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, tailsampling.Options{
+		DecisionWait:     DefaultTailSamplingDecisionWait,
+		RootSpanWait:     DefaultTailSamplingRootSpanWait,
+		MaxSpans:         DefaultTailSamplingMaxSpans,
+		EvictionDecision: tailsampling.EvictionDecisionDrop,
+	}, c.TailSampling)
+
+	policyFile := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte("policies:\n  - name: errors\n    type: error\n"), 0o600))
+	command.ParseFlags([]string{
+		"--collector.tailsampling.policy-file=" + policyFile,
+		"--collector.tailsampling.decision-wait=30s",
+		"--collector.tailsampling.root-span-wait=5s",
+		"--collector.tailsampling.max-spans=5000",
+		"--collector.tailsampling.eviction-decision=keep",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, tailsampling.Options{
+		Policies:         []tailsampling.NamedPolicy{{Name: "errors", Policy: tailsampling.ErrorPolicy{}}},
+		DecisionWait:     30 * time.Second,
+		RootSpanWait:     5 * time.Second,
+		MaxSpans:         5000,
+		EvictionDecision: tailsampling.EvictionDecisionKeep,
+	}, c.TailSampling)
+
+	for flag, errMsg := range map[string]string{
+		"--collector.tailsampling.decision-wait=0s":         "invalid tail sampling options",
+		"--collector.tailsampling.max-spans=-1":             "invalid tail sampling options",
+		"--collector.tailsampling.eviction-decision=defer":  `invalid tail sampling eviction decision "defer"`,
+		"--collector.tailsampling.policy-file=missing.yaml": "failed to read the tail sampling policy file",
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err = (&CollectorOptions{}).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, errMsg, flag)
+	}
+}
+
+func TestCollectorOptionsWithFlags_CheckTagFilter(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.False(t, c.TagFilter.Enabled())
+
+	command.ParseFlags([]string{
+		`--collector.tag-filter.deny=thread\..*, internal\..*`,
+		`--collector.tag-filter.allow=http\..*`,
+		"--collector.tag-filter.dry-run=true",
+	})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, c.TagFilter.Deny, 2)
+	assert.Equal(t, `^(?:internal\..*)$`, c.TagFilter.Deny[1].String())
+	require.Len(t, c.TagFilter.Allow, 1)
+	assert.True(t, c.TagFilter.DryRun)
+
+	for _, flag := range []string{"--collector.tag-filter.deny=(", "--collector.tag-filter.allow=["} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err = (&CollectorOptions{}).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid tag key pattern", flag)
+	}
+}
+
+func TestCollectorOptionsWithFlags_CheckTopServices(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, TopServicesOptions{Window: DefaultTopServicesWindow}, c.TopServices)
+
+	command.ParseFlags([]string{"--collector.top-services.size=20", "--collector.top-services.window=30s"})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, TopServicesOptions{Size: 20, Window: 30 * time.Second}, c.TopServices)
+
+	for _, flag := range []string{"--collector.top-services.size=-1", "--collector.top-services.window=0s"} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err = (&CollectorOptions{}).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid top services options", flag)
+	}
+}
+
+func TestCollectorOptionsWithFlags_CheckTraceSizes(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, TraceSizesOptions{Window: DefaultTraceSizesWindow, MaxTraces: DefaultTraceSizesMaxTraces}, c.TraceSizes)
+
+	command.ParseFlags([]string{"--collector.trace-sizes.sample-ratio=0.01", "--collector.trace-sizes.window=1m", "--collector.trace-sizes.max-traces=100"})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, TraceSizesOptions{SampleRatio: 0.01, Window: time.Minute, MaxTraces: 100}, c.TraceSizes)
+
+	for _, flag := range []string{
+		"--collector.trace-sizes.sample-ratio=-0.5",
+		"--collector.trace-sizes.sample-ratio=2",
+		"--collector.trace-sizes.window=0s",
+		"--collector.trace-sizes.max-traces=0",
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err = (&CollectorOptions{}).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid trace sizes options", flag)
+	}
+}
+
+func TestCollectorOptionsWithFlags_CheckGRPCDisabledCompression(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Empty(t, c.GRPCDisabledCompression)
+
+	command.ParseFlags([]string{"--collector.grpc.disabled-compression=zstd, snappy"})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zstd", "snappy"}, c.GRPCDisabledCompression)
+
+	v, command = config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--collector.grpc.disabled-compression=lz4"})
+	_, err = (&CollectorOptions{}).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `unknown gRPC compression codec "lz4"`)
+}
+
 func TestCollectorOptionsWithFlags_CheckMaxConnectionAge(t *testing.T) {
 	c := &CollectorOptions{}
 	v, command := config.Viperize(AddFlags)
@@ -190,6 +437,33 @@ func TestCollectorOptionsWithFlags_CheckZipkinKeepAlive(t *testing.T) {
 	assert.False(t, c.Zipkin.KeepAlive)
 }
 
+func TestCollectorOptionsWithFlags_CheckXRay(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	c.InitFromViper(v, zap.NewNop())
+	assert.False(t, c.XRay.Enabled)
+
+	command.ParseFlags([]string{
+		"--collector.xray.enabled=true",
+	})
+	c.InitFromViper(v, zap.NewNop())
+	assert.True(t, c.XRay.Enabled)
+}
+
 func TestMain(m *testing.M) {
 	testutils.VerifyGoLeaks(m)
 }
+
+func TestCollectorOptionsWithFlags_CheckFlushStorageEndpoint(t *testing.T) {
+	c := &CollectorOptions{}
+	v, command := config.Viperize(AddFlags)
+	_, err := c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.False(t, c.FlushStorageEndpoint)
+
+	command.ParseFlags([]string{"--collector.debug.flush-storage-endpoint=true"})
+	_, err = c.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, c.FlushStorageEndpoint)
+}
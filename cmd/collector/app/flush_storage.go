@@ -0,0 +1,46 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// FlushStoragePath is the path of the endpoint flushing the span storage, to register the
+// FlushStorageHandler of the collector on the admin server.
+const FlushStoragePath = "/debug/flush-storage"
+
+// flusher is implemented by the span processor, see spanProcessor.Flush.
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// flushStorageHandler returns once the spans received by the collector before the request are written
+// and visible to the reads, so that the end-to-end tests can read them back without polling.
+type flushStorageHandler struct {
+	flusher flusher
+	logger  *zap.Logger
+}
+
+func (h *flushStorageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	err := h.flusher.Flush(r.Context())
+	switch {
+	case errors.Is(err, errors.ErrUnsupported):
+		http.Error(w, "the span storage does not support flushing", http.StatusNotImplemented)
+	case err != nil:
+		h.logger.Error("Failed to flush the span storage", zap.Error(err))
+		http.Error(w, "failed to flush the span storage: "+err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
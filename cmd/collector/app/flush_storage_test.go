@@ -0,0 +1,174 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+type flushingWriter struct {
+	slowWriter
+	// writtenAtFlush is the number of spans written when the writer was flushed
+	writtenAtFlush atomic.Int32
+}
+
+func (w *flushingWriter) Flush(context.Context) error {
+	w.writtenAtFlush.Store(w.written.Load())
+	return nil
+}
+
+func newFlushTestSpanProcessor(t *testing.T, w spanstore.Writer) *spanProcessor {
+	p := NewSpanProcessor(w, nil, Options.NumWorkers(2), Options.QueueSize(100)).(*spanProcessor)
+	t.Cleanup(func() {
+		require.NoError(t, p.Close())
+	})
+	return p
+}
+
+func TestSpanProcessorFlush(t *testing.T) {
+	w := &flushingWriter{slowWriter: slowWriter{delay: time.Millisecond}}
+	p := newFlushTestSpanProcessor(t, w)
+	spans := make([]*model.Span, 20)
+	for i := range spans {
+		spans[i] = &model.Span{Process: &model.Process{ServiceName: "x"}}
+	}
+	_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+
+	// the spans queued before the flush are all written before the writer is flushed
+	require.NoError(t, p.Flush(context.Background()))
+	assert.EqualValues(t, 20, w.writtenAtFlush.Load())
+	assert.Zero(t, p.queuedSpans[0].Load()+p.queuedSpans[1].Load())
+}
+
+func TestSpanProcessorFlushUnderContinuousIntake(t *testing.T) {
+	w := &flushingWriter{slowWriter: slowWriter{delay: time.Millisecond}}
+	p := newFlushTestSpanProcessor(t, w)
+	enqueue := func() {
+		_, err := p.ProcessSpans([]*model.Span{{Process: &model.Process{ServiceName: "x"}}},
+			processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 20; i++ {
+		enqueue()
+	}
+
+	// the spans keep being enqueued while flushing, so that some are always waiting to be processed
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				enqueue()
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+	assert.Eventually(t, func() bool {
+		return p.queuedSpans[0].Load() > 20
+	}, 5*time.Second, time.Millisecond)
+
+	// the flush only waits for the spans enqueued before it
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, p.Flush(ctx))
+	assert.GreaterOrEqual(t, w.writtenAtFlush.Load(), int32(20))
+	require.NoError(t, p.Flush(ctx))
+}
+
+func TestSpanProcessorFlushErrors(t *testing.T) {
+	p := newFlushTestSpanProcessor(t, &fakeSpanWriter{})
+	require.ErrorIs(t, p.Flush(context.Background()), errors.ErrUnsupported)
+
+	w := &blockingWriter{}
+	w.Lock()
+	defer w.Unlock()
+	p = newFlushTestSpanProcessor(t, w)
+	_, err := p.ProcessSpans([]*model.Span{{Process: &model.Process{ServiceName: "x"}}},
+		processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, p.Flush(ctx), context.DeadlineExceeded)
+}
+
+type flusherFunc func(ctx context.Context) error
+
+func (f flusherFunc) Flush(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestFlushStorageHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		status int
+	}{
+		{name: "flushed", method: http.MethodPost, status: http.StatusNoContent},
+		{name: "unsupported", method: http.MethodPost, err: errors.ErrUnsupported, status: http.StatusNotImplemented},
+		{name: "failed", method: http.MethodPost, err: errors.New("disk full"), status: http.StatusInternalServerError},
+		{name: "GET", method: http.MethodGet, status: http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := &flushStorageHandler{
+				flusher: flusherFunc(func(context.Context) error { return test.err }),
+				logger:  zap.NewNop(),
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(test.method, FlushStoragePath, nil))
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+}
+
+func TestCollectorFlushStorageHandler(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		baseMetrics := metricstest.NewFactory(time.Hour)
+		defer baseMetrics.Backend.Stop()
+		c := New(&CollectorParams{
+			ServiceName:      "collector",
+			Logger:           zap.NewNop(),
+			MetricsFactory:   baseMetrics,
+			SpanWriter:       &fakeSpanWriter{},
+			SamplingProvider: &mockSamplingProvider{},
+			HealthCheck:      healthcheck.New(),
+			TenancyMgr:       &tenancy.Manager{},
+		})
+		collectorOpts := optionsForEphemeralPorts()
+		collectorOpts.FlushStorageEndpoint = enabled
+		require.NoError(t, c.Start(collectorOpts))
+		if enabled {
+			assert.NotNil(t, c.FlushStorageHandler())
+		} else {
+			assert.Nil(t, c.FlushStorageHandler())
+		}
+		require.NoError(t, c.Close())
+	}
+}
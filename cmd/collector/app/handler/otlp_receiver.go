@@ -18,7 +18,6 @@ import (
 	"context"
 	"fmt"
 
-	otlp2jaeger "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/confighttp"
@@ -29,11 +28,12 @@ import (
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
 	noopmetric "go.opentelemetry.io/otel/metric/noop"
-	nooptrace "go.opentelemetry.io/otel/trace/noop"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/otlptranslator"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -42,13 +42,21 @@ import (
 var _ component.Host = (*otelHost)(nil) // API check
 
 // StartOTLPReceiver starts OpenTelemetry OTLP receiver listening on gRPC and HTTP ports.
-func StartOTLPReceiver(options *flags.CollectorOptions, logger *zap.Logger, spanProcessor processor.SpanProcessor, tm *tenancy.Manager) (receiver.Traces, error) {
+// The receiver traces its own requests with the tracer provider.
+func StartOTLPReceiver(
+	options *flags.CollectorOptions,
+	logger *zap.Logger,
+	spanProcessor processor.SpanProcessor,
+	tm *tenancy.Manager,
+	tracerProvider trace.TracerProvider,
+) (receiver.Traces, error) {
 	otlpFactory := otlpreceiver.NewFactory()
 	return startOTLPReceiver(
 		options,
 		logger,
 		spanProcessor,
 		tm,
+		tracerProvider,
 		otlpFactory,
 		consumer.NewTraces,
 		otlpFactory.CreateTracesReceiver,
@@ -63,6 +71,7 @@ func startOTLPReceiver(
 	logger *zap.Logger,
 	spanProcessor processor.SpanProcessor,
 	tm *tenancy.Manager,
+	tracerProvider trace.TracerProvider,
 	// from here: params that can be mocked in tests
 	otlpFactory receiver.Factory,
 	newTraces func(consume consumer.ConsumeTracesFunc, options ...consumer.Option) (consumer.Traces, error),
@@ -79,7 +88,7 @@ func startOTLPReceiver(
 	otlpReceiverSettings := receiver.Settings{
 		TelemetrySettings: component.TelemetrySettings{
 			Logger:         logger,
-			TracerProvider: nooptrace.NewTracerProvider(),
+			TracerProvider: tracerProvider,
 			MeterProvider:  noopmetric.NewMeterProvider(), // TODO wire this with jaegerlib metrics?
 			ReportStatus:   statusReporter,
 		},
@@ -140,17 +149,29 @@ func applyHTTPSettings(cfg *confighttp.ServerConfig, opts *flags.HTTPOptions) {
 	}
 }
 
+// applyTLSSettings converts the TLS options to the OTEL configuration. The OTEL receivers require
+// the certificates of the clients whenever a client CA is set, so the tlscfg.ClientAuthRequest
+// policy is enforced as tlscfg.ClientAuthRequireAndVerify.
 func applyTLSSettings(opts *tlscfg.Options) *configtls.ServerConfig {
+	minVersion := opts.MinVersion
+	if minVersion == "" {
+		minVersion = tlscfg.DefaultMinVersion
+	}
+	clientCAFile := opts.ClientCAPath
+	if opts.ClientAuthType == tlscfg.ClientAuthNone {
+		clientCAFile = ""
+	}
 	return &configtls.ServerConfig{
 		Config: configtls.Config{
 			CAFile:         opts.CAPath,
 			CertFile:       opts.CertPath,
 			KeyFile:        opts.KeyPath,
-			MinVersion:     opts.MinVersion,
+			CipherSuites:   opts.CipherSuites,
+			MinVersion:     minVersion,
 			MaxVersion:     opts.MaxVersion,
 			ReloadInterval: opts.ReloadInterval,
 		},
-		ClientCAFile: opts.ClientCAPath,
+		ClientCAFile: clientCAFile,
 	}
 }
 
@@ -161,7 +182,7 @@ func newConsumerDelegate(logger *zap.Logger, spanProcessor processor.SpanProcess
 			processor.UnknownTransport, // could be gRPC or HTTP
 			processor.OTLPSpanFormat,
 			tm),
-		protoFromTraces: otlp2jaeger.ProtoFromTraces,
+		protoFromTraces: otlptranslator.ProtoFromTraces,
 	}
 }
 
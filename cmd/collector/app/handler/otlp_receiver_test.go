@@ -17,6 +17,7 @@ package handler
 import (
 	"context"
 	"errors"
+	"net"
 	"testing"
 	"time"
 
@@ -25,13 +26,18 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"go.opentelemetry.io/collector/receiver"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config/corscfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 )
@@ -51,7 +57,7 @@ func TestStartOtlpReceiver(t *testing.T) {
 	spanProcessor := &mockSpanProcessor{}
 	logger, _ := testutils.NewLogger()
 	tm := &tenancy.Manager{}
-	rec, err := StartOTLPReceiver(optionsWithPorts(":0"), logger, spanProcessor, tm)
+	rec, err := StartOTLPReceiver(optionsWithPorts(":0"), logger, spanProcessor, tm, nooptrace.NewTracerProvider())
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, rec.Shutdown(context.Background()))
@@ -61,6 +67,37 @@ func TestStartOtlpReceiver(t *testing.T) {
 	// So we will rely on otlpreceiver being tested in the OTEL repos, and we only test the consumer function.
 }
 
+func TestOtlpReceiverCompression(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	hostPort := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	spanProcessor := &mockSpanProcessor{}
+	logger, _ := testutils.NewLogger()
+	opts := optionsWithPorts(hostPort)
+	opts.OTLP.HTTP.HostPort = "127.0.0.1:0"
+	rec, err := StartOTLPReceiver(opts, logger, spanProcessor, &tenancy.Manager{}, nooptrace.NewTracerProvider())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, rec.Shutdown(context.Background()))
+	}()
+
+	conn, err := grpc.NewClient(hostPort, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := ptraceotlp.NewGRPCClient(conn)
+	for i, codec := range grpccompression.Codecs {
+		t.Run(codec, func(t *testing.T) {
+			_, err := client.Export(context.Background(), ptraceotlp.NewExportRequestFromTraces(makeTracesOneSpan()), grpc.UseCompressor(codec))
+			require.NoError(t, err)
+			spans := spanProcessor.getSpans()
+			require.Len(t, spans, i+1)
+			assert.Equal(t, "test", spans[i].OperationName)
+		})
+	}
+}
+
 func makeTracesOneSpan() ptrace.Traces {
 	traces := ptrace.NewTraces()
 	rSpans := traces.ResourceSpans().AppendEmpty()
@@ -102,7 +139,7 @@ func TestStartOtlpReceiver_Error(t *testing.T) {
 	logger, _ := testutils.NewLogger()
 	opts := optionsWithPorts(":-1")
 	tm := &tenancy.Manager{}
-	_, err := StartOTLPReceiver(opts, logger, spanProcessor, tm)
+	_, err := StartOTLPReceiver(opts, logger, spanProcessor, tm, nooptrace.NewTracerProvider())
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not start the OTLP receiver")
 
@@ -110,7 +147,7 @@ func TestStartOtlpReceiver_Error(t *testing.T) {
 		return nil, errors.New("mock error")
 	}
 	f := otlpreceiver.NewFactory()
-	_, err = startOTLPReceiver(opts, logger, spanProcessor, &tenancy.Manager{}, f, newTraces, f.CreateTracesReceiver)
+	_, err = startOTLPReceiver(opts, logger, spanProcessor, &tenancy.Manager{}, nooptrace.NewTracerProvider(), f, newTraces, f.CreateTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create the OTLP consumer")
 
@@ -119,7 +156,7 @@ func TestStartOtlpReceiver_Error(t *testing.T) {
 	) (receiver.Traces, error) {
 		return nil, errors.New("mock error")
 	}
-	_, err = startOTLPReceiver(opts, logger, spanProcessor, &tenancy.Manager{}, f, consumer.NewTraces, createTracesReceiver)
+	_, err = startOTLPReceiver(opts, logger, spanProcessor, &tenancy.Manager{}, nooptrace.NewTracerProvider(), f, consumer.NewTraces, createTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create the OTLP receiver")
 }
@@ -169,6 +206,7 @@ func TestApplyOTLPGRPCServerSettings(t *testing.T) {
 			CertPath:       "cert",
 			KeyPath:        "key",
 			ClientCAPath:   "clientca",
+			CipherSuites:   []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
 			MinVersion:     "1.1",
 			MaxVersion:     "1.3",
 			ReloadInterval: 24 * time.Hour,
@@ -187,11 +225,22 @@ func TestApplyOTLPGRPCServerSettings(t *testing.T) {
 	assert.Equal(t, "cert", out.TLSSetting.CertFile)
 	assert.Equal(t, "key", out.TLSSetting.KeyFile)
 	assert.Equal(t, "clientca", out.TLSSetting.ClientCAFile)
+	assert.Equal(t, []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}, out.TLSSetting.CipherSuites)
 	assert.Equal(t, "1.1", out.TLSSetting.MinVersion)
 	assert.Equal(t, "1.3", out.TLSSetting.MaxVersion)
 	assert.Equal(t, 24*time.Hour, out.TLSSetting.ReloadInterval)
 }
 
+func TestApplyTLSSettingsDefaults(t *testing.T) {
+	out := applyTLSSettings(&tlscfg.Options{
+		Enabled:        true,
+		ClientCAPath:   "clientca",
+		ClientAuthType: tlscfg.ClientAuthNone,
+	})
+	assert.Equal(t, tlscfg.DefaultMinVersion, out.MinVersion)
+	assert.Empty(t, out.ClientCAFile)
+}
+
 func TestApplyOTLPHTTPServerSettings(t *testing.T) {
 	otlpFactory := otlpreceiver.NewFactory()
 	otlpReceiverConfig := otlpFactory.CreateDefaultConfig().(*otlpreceiver.Config)
@@ -0,0 +1,118 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/converter/xray"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const (
+	xrayErrorCodeInvalidDocument = "InvalidSegmentDocument"
+	xrayErrorCodeInvalidSegment  = "InvalidSegment"
+)
+
+// XRayHandler accepts the AWS X-Ray segment documents sent to the PutTraceSegments API.
+type XRayHandler struct {
+	logger        *zap.Logger
+	spanProcessor processor.SpanProcessor
+}
+
+// PutTraceSegmentsOutput is the response of the PutTraceSegments API.
+type PutTraceSegmentsOutput struct {
+	UnprocessedTraceSegments []UnprocessedTraceSegment `json:"UnprocessedTraceSegments"`
+}
+
+// UnprocessedTraceSegment describes a segment document which could not be processed.
+type UnprocessedTraceSegment struct {
+	ID        string `json:"Id,omitempty"`
+	ErrorCode string `json:"ErrorCode"`
+	Message   string `json:"Message"`
+}
+
+// NewXRayHandler returns a new XRayHandler
+func NewXRayHandler(logger *zap.Logger, spanProcessor processor.SpanProcessor) *XRayHandler {
+	return &XRayHandler{
+		logger:        logger,
+		spanProcessor: spanProcessor,
+	}
+}
+
+// RegisterRoutes registers routes for this handler on the given router
+func (h *XRayHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/TraceSegments", h.PutTraceSegments).Methods(http.MethodPost)
+}
+
+// PutTraceSegments converts the segment documents of the request body into spans and submits them
+// to the span processor. The documents which cannot be converted are reported in the response
+// without failing the request, like the X-Ray API does.
+func (h *XRayHandler) PutTraceSegments(w http.ResponseWriter, r *http.Request) {
+	bodyBytes, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, fmt.Sprintf(UnableToReadBodyErrFormat, err), http.StatusInternalServerError)
+		return
+	}
+	var input xray.PutTraceSegmentsInput
+	if err := json.Unmarshal(bodyBytes, &input); err != nil {
+		http.Error(w, fmt.Sprintf(UnableToReadBodyErrFormat, err), http.StatusBadRequest)
+		return
+	}
+
+	output := PutTraceSegmentsOutput{UnprocessedTraceSegments: []UnprocessedTraceSegment{}}
+	var spans []*model.Span
+	for _, document := range input.TraceSegmentDocuments {
+		segment, err := xray.DeserializeSegment(document)
+		if err != nil {
+			output.UnprocessedTraceSegments = append(output.UnprocessedTraceSegments, UnprocessedTraceSegment{
+				ErrorCode: xrayErrorCodeInvalidDocument,
+				Message:   err.Error(),
+			})
+			continue
+		}
+		segmentSpans, err := xray.ToDomainSpans(segment)
+		if err != nil {
+			output.UnprocessedTraceSegments = append(output.UnprocessedTraceSegments, UnprocessedTraceSegment{
+				ID:        segment.ID,
+				ErrorCode: xrayErrorCodeInvalidSegment,
+				Message:   err.Error(),
+			})
+			continue
+		}
+		spans = append(spans, segmentSpans...)
+	}
+
+	if len(spans) > 0 {
+		_, err = h.spanProcessor.ProcessSpans(spans, processor.SpansOptions{
+			SpanFormat:       processor.XRaySpanFormat,
+			InboundTransport: processor.HTTPTransport,
+			Tenant:           tenancy.GetTenant(r.Context()),
+		})
+		if err != nil {
+			if errors.Is(err, processor.ErrBusy) {
+				http.Error(w, fmt.Sprintf("Cannot submit X-Ray segments: %v", err), http.StatusServiceUnavailable)
+				return
+			}
+			h.logger.Error("cannot process spans", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Cannot submit X-Ray segments: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(output); err != nil {
+		h.logger.Error("cannot write the X-Ray response", zap.Error(err))
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/converter/xray"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const (
+	xrayTraceID = "1-581cf771-a006649127e371903a2de979"
+	xraySegment = `{
+		"name": "checkout",
+		"id": "70de5b6f19ff9a0a",
+		"trace_id": "` + xrayTraceID + `",
+		"start_time": 1478293361.271,
+		"end_time": 1478293361.449,
+		"subsegments": [{
+			"name": "payments",
+			"id": "53995c3f42cd8ad8",
+			"start_time": 1478293361.3,
+			"end_time": 1478293361.4,
+			"namespace": "remote"
+		}]
+	}`
+)
+
+func postXRaySegments(handler http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/TraceSegments", bytes.NewBufferString(body))
+	req = req.WithContext(tenancy.WithTenant(req.Context(), "acme"))
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+	return rw
+}
+
+func xraySegmentsBody(t *testing.T, documents ...string) string {
+	body, err := json.Marshal(xray.PutTraceSegmentsInput{TraceSegmentDocuments: documents})
+	require.NoError(t, err)
+	return string(body)
+}
+
+func newXRayRouter(spanProcessor processor.SpanProcessor) *mux.Router {
+	router := mux.NewRouter()
+	NewXRayHandler(zap.NewNop(), spanProcessor).RegisterRoutes(router)
+	return router
+}
+
+func TestXRayHandler(t *testing.T) {
+	spanProcessor := &mockSpanProcessor{}
+	rw := postXRaySegments(newXRayRouter(spanProcessor), xraySegmentsBody(t,
+		xraySegment,
+		`not json`,
+		`{"name": "bad", "id": "53995c3f42cd8ad9", "trace_id": "1-bad"}`,
+	))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+
+	var output PutTraceSegmentsOutput
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &output))
+	require.Len(t, output.UnprocessedTraceSegments, 2)
+	assert.Equal(t, "InvalidSegmentDocument", output.UnprocessedTraceSegments[0].ErrorCode)
+	assert.Empty(t, output.UnprocessedTraceSegments[0].ID)
+	assert.Equal(t, UnprocessedTraceSegment{
+		ID:        "53995c3f42cd8ad9",
+		ErrorCode: "InvalidSegment",
+		Message:   `invalid X-Ray trace ID "1-bad"`,
+	}, output.UnprocessedTraceSegments[1])
+
+	spans := spanProcessor.getSpans()
+	require.Len(t, spans, 2)
+	traceID := model.NewTraceID(0x581cf771a0066491, 0x27e371903a2de979)
+	assert.Equal(t, traceID, spans[0].TraceID)
+	assert.Equal(t, "checkout", spans[0].OperationName)
+	assert.Equal(t, "payments", spans[1].OperationName)
+	assert.Equal(t, spans[0].SpanID, spans[1].ParentSpanID())
+	assert.Equal(t, processor.XRaySpanFormat, spanProcessor.spanFormat)
+	assert.Equal(t, processor.HTTPTransport, spanProcessor.transport)
+	assert.Equal(t, map[string]bool{"acme": true}, spanProcessor.getTenants())
+}
+
+func TestXRayHandlerNoSpans(t *testing.T) {
+	spanProcessor := &mockSpanProcessor{}
+	rw := postXRaySegments(newXRayRouter(spanProcessor), xraySegmentsBody(t))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"UnprocessedTraceSegments": []}`, rw.Body.String())
+	assert.Empty(t, spanProcessor.getSpans())
+}
+
+func TestXRayHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		processorErr  error
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:          "bad envelope",
+			body:          `{"TraceSegmentDocuments": 42}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Unable to process request body",
+		},
+		{
+			name:          "busy processor",
+			body:          xraySegmentsBody(t, xraySegment),
+			processorErr:  processor.ErrBusy,
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedError: "Cannot submit X-Ray segments: server busy",
+		},
+		{
+			name:          "failing processor",
+			body:          xraySegmentsBody(t, xraySegment),
+			processorErr:  errors.New("boom"),
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "Cannot submit X-Ray segments: boom",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spanProcessor := &mockSpanProcessor{expectedError: test.processorErr}
+			rw := postXRaySegments(newXRayRouter(spanProcessor), test.body)
+			assert.Equal(t, test.expectedCode, rw.Code)
+			assert.Contains(t, rw.Body.String(), test.expectedError)
+		})
+	}
+}
+
+func TestXRayHandlerReadError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/TraceSegments", &errReader{})
+	rw := httptest.NewRecorder()
+	NewXRayHandler(zap.NewNop(), &mockSpanProcessor{}).PutTraceSegments(rw, req)
+	assert.Equal(t, http.StatusInternalServerError, rw.Code)
+}
@@ -12,7 +12,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
 	noopmetric "go.opentelemetry.io/otel/metric/noop"
-	nooptrace "go.opentelemetry.io/otel/trace/noop"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
@@ -21,11 +21,13 @@ import (
 )
 
 // StartZipkinReceiver starts Zipkin receiver from OTEL Collector.
+// The receiver traces its own requests with the tracer provider.
 func StartZipkinReceiver(
 	options *flags.CollectorOptions,
 	logger *zap.Logger,
 	spanProcessor processor.SpanProcessor,
 	tm *tenancy.Manager,
+	tracerProvider trace.TracerProvider,
 ) (receiver.Traces, error) {
 	zipkinFactory := zipkinreceiver.NewFactory()
 	return startZipkinReceiver(
@@ -33,6 +35,7 @@ func StartZipkinReceiver(
 		logger,
 		spanProcessor,
 		tm,
+		tracerProvider,
 		zipkinFactory,
 		consumer.NewTraces,
 		zipkinFactory.CreateTracesReceiver,
@@ -47,6 +50,7 @@ func startZipkinReceiver(
 	logger *zap.Logger,
 	spanProcessor processor.SpanProcessor,
 	tm *tenancy.Manager,
+	tracerProvider trace.TracerProvider,
 	// from here: params that can be mocked in tests
 	zipkinFactory receiver.Factory,
 	newTraces func(consume consumer.ConsumeTracesFunc, options ...consumer.Option) (consumer.Traces, error),
@@ -63,7 +67,7 @@ func startZipkinReceiver(
 	receiverSettings := receiver.Settings{
 		TelemetrySettings: component.TelemetrySettings{
 			Logger:         logger,
-			TracerProvider: nooptrace.NewTracerProvider(),
+			TracerProvider: tracerProvider,
 			MeterProvider:  noopmetric.NewMeterProvider(), // TODO wire this with jaegerlib metrics?
 		},
 	}
@@ -21,6 +21,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
@@ -39,7 +40,7 @@ func TestZipkinReceiver(t *testing.T) {
 	opts := &flags.CollectorOptions{}
 	opts.Zipkin.HTTPHostPort = ":11911"
 
-	rec, err := StartZipkinReceiver(opts, logger, spanProcessor, tm)
+	rec, err := StartZipkinReceiver(opts, logger, spanProcessor, tm, nooptrace.NewTracerProvider())
 	require.NoError(t, err)
 	defer func() {
 		require.NoError(t, rec.Shutdown(context.Background()))
@@ -140,7 +141,7 @@ func TestStartZipkinReceiver_Error(t *testing.T) {
 	opts := &flags.CollectorOptions{}
 	opts.Zipkin.HTTPHostPort = ":-1"
 
-	_, err := StartZipkinReceiver(opts, logger, spanProcessor, tm)
+	_, err := StartZipkinReceiver(opts, logger, spanProcessor, tm, nooptrace.NewTracerProvider())
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not start Zipkin receiver")
 
@@ -148,7 +149,7 @@ func TestStartZipkinReceiver_Error(t *testing.T) {
 		return nil, errors.New("mock error")
 	}
 	f := zipkinreceiver.NewFactory()
-	_, err = startZipkinReceiver(opts, logger, spanProcessor, tm, f, newTraces, f.CreateTracesReceiver)
+	_, err = startZipkinReceiver(opts, logger, spanProcessor, tm, nooptrace.NewTracerProvider(), f, newTraces, f.CreateTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create Zipkin consumer")
 
@@ -157,7 +158,7 @@ func TestStartZipkinReceiver_Error(t *testing.T) {
 	) (receiver.Traces, error) {
 		return nil, errors.New("mock error")
 	}
-	_, err = startZipkinReceiver(opts, logger, spanProcessor, tm, f, consumer.NewTraces, createTracesReceiver)
+	_, err = startZipkinReceiver(opts, logger, spanProcessor, tm, nooptrace.NewTracerProvider(), f, consumer.NewTraces, createTracesReceiver)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "could not create Zipkin receiver")
 }
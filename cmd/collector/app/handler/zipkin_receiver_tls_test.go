@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
@@ -178,7 +179,7 @@ func TestSpanCollectorZipkinTLS(t *testing.T) {
 			opts.Zipkin.TLS = test.serverTLS
 			defer test.serverTLS.Close()
 
-			server, err := StartZipkinReceiver(opts, logger, spanProcessor, tm)
+			server, err := StartZipkinReceiver(opts, logger, spanProcessor, tm, nooptrace.NewTracerProvider())
 			if test.expectServerFail {
 				require.Error(t, err)
 				return
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// latencyWindowSlices is the number of slices of the shortest window, the oldest one being dropped as time passes
+	latencyWindowSlices = 10
+	// maxLatencyWindowSlices bounds the number of slices of the longest window, and so the memory of the histogram
+	maxLatencyWindowSlices = 600
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets, growing by 25%
+// from 1ms to about a minute, so that the quantile estimates are within 25% of the actual ones.
+var latencyBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for b := float64(time.Millisecond); b < float64(time.Minute); b *= 1.25 {
+		bounds = append(bounds, time.Duration(b))
+	}
+	return bounds
+}()
+
+// slicedCounter is the count of a slice of a sliding window, updated without locks: the low 32 bits
+// of the number of the slice and the count are packed in a single word, and the count is reset
+// when the counter is reused for a later slice.
+type slicedCounter struct {
+	value atomic.Uint64
+}
+
+// add adds n to the count of the slice.
+func (c *slicedCounter) add(slice int64, n uint64) {
+	number := uint64(uint32(slice))
+	for {
+		value := c.value.Load()
+		updated := number<<32 | n
+		if value>>32 == number {
+			updated = value + n
+		}
+		if c.value.CompareAndSwap(value, updated) {
+			return
+		}
+	}
+}
+
+// count returns the count if it is the one of a slice from last-slices+1 to last, and 0 otherwise.
+func (c *slicedCounter) count(last int64, slices int) uint64 {
+	value := c.value.Load()
+	if uint32(last)-uint32(value>>32) >= uint32(slices) {
+		return 0
+	}
+	return value & math.MaxUint32
+}
+
+// latencyWindow is the histogram of the latencies of the span writes over a sliding window, estimating
+// their quantiles with bounded memory. The latencies are recorded without locks, on the hot path of the
+// span writes, and their quantiles are estimated over any window up to the one of the histogram.
+type latencyWindow struct {
+	sliceDuration time.Duration
+	// slices are the counts of the latency buckets of each slice of the window
+	slices [][]slicedCounter
+}
+
+// newLatencyWindow creates the histogram of the latencies over the longest of the windows, with slices of a tenth
+// of the shortest one, unless that makes more than maxLatencyWindowSlices slices.
+func newLatencyWindow(windows ...time.Duration) *latencyWindow {
+	shortest, longest := slices.Min(windows), slices.Max(windows)
+	lw := &latencyWindow{
+		sliceDuration: max(shortest/latencyWindowSlices, longest/maxLatencyWindowSlices, time.Millisecond),
+	}
+	lw.slices = make([][]slicedCounter, lw.sliceCount(longest))
+	for i := range lw.slices {
+		lw.slices[i] = make([]slicedCounter, len(latencyBounds)+1)
+	}
+	return lw
+}
+
+// sliceCount returns the number of slices covering the window.
+func (lw *latencyWindow) sliceCount(window time.Duration) int {
+	return max(int((window+lw.sliceDuration-1)/lw.sliceDuration), 1)
+}
+
+// record adds a latency measured at now.
+func (lw *latencyWindow) record(now time.Time, latency time.Duration) {
+	bucket := len(latencyBounds)
+	for i, bound := range latencyBounds {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	slice := now.UnixNano() / int64(lw.sliceDuration)
+	lw.slices[slice%int64(len(lw.slices))][bucket].add(slice, 1)
+}
+
+// quantiles returns the estimated quantiles of the latencies over the window ending at now, in the order
+// of the given ones, and the number of latencies recorded; the quantiles are nil if there were none.
+// The window is rounded up to a number of slices, and capped by the window of the histogram.
+func (lw *latencyWindow) quantiles(now time.Time, window time.Duration, quantiles ...float64) ([]time.Duration, uint64) {
+	last := now.UnixNano() / int64(lw.sliceDuration)
+	sliceCount := min(lw.sliceCount(window), len(lw.slices))
+	counts := make([]uint64, len(latencyBounds)+1)
+	var total uint64
+	for _, slice := range lw.slices {
+		for i := range slice {
+			count := slice[i].count(last, sliceCount)
+			counts[i] += count
+			total += count
+		}
+	}
+	if total == 0 {
+		return nil, 0
+	}
+	estimates := make([]time.Duration, len(quantiles))
+	for q, quantile := range quantiles {
+		rank := max(uint64(math.Ceil(quantile*float64(total))), 1)
+		// the latencies above the largest bound are counted at twice the bound
+		estimates[q] = 2 * latencyBounds[len(latencyBounds)-1]
+		var seen uint64
+		for i, count := range counts[:len(latencyBounds)] {
+			seen += count
+			if seen >= rank {
+				estimates[q] = latencyBounds[i]
+				break
+			}
+		}
+	}
+	return estimates, total
+}
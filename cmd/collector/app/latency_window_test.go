@@ -0,0 +1,74 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyWindowQuantiles(t *testing.T) {
+	lw := newLatencyWindow(time.Minute)
+	now := time.Now()
+	quantiles, count := lw.quantiles(now, time.Minute, 0.5)
+	assert.Nil(t, quantiles)
+	assert.Zero(t, count)
+
+	for i := 1; i <= 100; i++ {
+		lw.record(now, time.Duration(i)*time.Millisecond)
+	}
+	quantiles, count = lw.quantiles(now, time.Minute, 0.5, 0.9, 0.99)
+	assert.EqualValues(t, 100, count)
+	for i, actual := range []time.Duration{50 * time.Millisecond, 90 * time.Millisecond, 99 * time.Millisecond} {
+		// the estimates are the bucket bounds, within 25% above the actual quantiles
+		assert.GreaterOrEqual(t, quantiles[i], actual)
+		assert.LessOrEqual(t, quantiles[i], actual*5/4)
+	}
+
+	// the latencies older than the window are dropped
+	_, count = lw.quantiles(now.Add(2*time.Minute), time.Minute, 0.5)
+	assert.Zero(t, count)
+}
+
+func TestLatencyWindowShorterWindow(t *testing.T) {
+	lw := newLatencyWindow(time.Minute, 10*time.Second)
+	now := time.Now()
+	lw.record(now.Add(-30*time.Second), time.Second)
+	lw.record(now, 10*time.Millisecond)
+
+	quantiles, count := lw.quantiles(now, 10*time.Second, 0.99)
+	assert.EqualValues(t, 1, count)
+	assert.Less(t, quantiles[0], 20*time.Millisecond)
+	quantiles, count = lw.quantiles(now, time.Minute, 0.99)
+	assert.EqualValues(t, 2, count)
+	assert.GreaterOrEqual(t, quantiles[0], time.Second)
+	// the window is capped by the one of the histogram
+	_, count = lw.quantiles(now.Add(50*time.Second), time.Hour, 0.99)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestLatencyWindowSlices(t *testing.T) {
+	assert.Equal(t, 100*time.Millisecond, newLatencyWindow(time.Second).sliceDuration)
+	assert.Len(t, newLatencyWindow(time.Second).slices, 10)
+	// the slices are long enough for the longest window not to exceed maxLatencyWindowSlices slices
+	lw := newLatencyWindow(time.Hour, time.Second)
+	assert.Equal(t, 6*time.Second, lw.sliceDuration)
+	assert.Len(t, lw.slices, maxLatencyWindowSlices)
+}
+
+func TestSlicedCounter(t *testing.T) {
+	var c slicedCounter
+	c.add(100, 2)
+	c.add(100, 3)
+	assert.EqualValues(t, 5, c.count(100, 1))
+	assert.EqualValues(t, 5, c.count(109, 10))
+	assert.Zero(t, c.count(110, 10))
+	// the count is reset when the counter is reused for a later slice
+	c.add(110, 1)
+	assert.EqualValues(t, 1, c.count(110, 10))
+	// the slices before the counter's are not counted
+	assert.Zero(t, c.count(99, 10))
+}
@@ -67,6 +67,20 @@ type SpanProcessorMetrics struct {
 	InQueueLatency metrics.Timer
 	// SpansDropped measures the number of spans we discarded because the queue was full
 	SpansDropped metrics.Counter
+	// SpansAbandoned measures the number of spans left in the queue when draining it on shutdown timed out
+	SpansAbandoned metrics.Counter
+	// SpansThrottled measures the number of spans rejected as busy because the span writer was behind
+	SpansThrottled metrics.Counter
+	// BatchesBlocked measures the number of span batches held until the span writer caught up
+	BatchesBlocked metrics.Counter
+	// SpansRejectedByAdmission measures the number of spans rejected as busy by the admission control
+	SpansRejectedByAdmission metrics.Counter
+	// AdmissionRejectionPercent records the probability, in percent, of rejecting a span batch by the admission control
+	AdmissionRejectionPercent metrics.Gauge
+	// AdmissionOverloadPercent records how much, in percent of the p99 latency of the span writes, it exceeds the target
+	AdmissionOverloadPercent metrics.Gauge
+	// PendingWrites records how many writes are pending in the span writer
+	PendingWrites metrics.Gauge
 	// SpansBytes records how many bytes were processed
 	SpansBytes metrics.Gauge
 	// BatchSize measures the span batch size
@@ -134,6 +148,10 @@ func NewSpanProcessorMetrics(serviceMetrics metrics.Factory, hostMetrics metrics
 		SaveLatency:    hostMetrics.Timer(metrics.TimerOptions{Name: "save-latency", Tags: nil}),
 		InQueueLatency: hostMetrics.Timer(metrics.TimerOptions{Name: "in-queue-latency", Tags: nil}),
 		SpansDropped:   hostMetrics.Counter(metrics.Options{Name: "spans.dropped", Tags: nil}),
+		SpansAbandoned: hostMetrics.Counter(metrics.Options{Name: "spans.abandoned", Tags: nil}),
+		SpansThrottled: hostMetrics.Counter(metrics.Options{Name: "spans.throttled", Tags: nil}),
+		BatchesBlocked: hostMetrics.Counter(metrics.Options{Name: "batches.blocked", Tags: nil}),
+		PendingWrites:  hostMetrics.Gauge(metrics.Options{Name: "pending-writes", Tags: nil}),
 		BatchSize:      hostMetrics.Gauge(metrics.Options{Name: "batch-size", Tags: nil}),
 		QueueCapacity:  hostMetrics.Gauge(metrics.Options{Name: "queue-capacity", Tags: nil}),
 		QueueLength:    hostMetrics.Gauge(metrics.Options{Name: "queue-length", Tags: nil}),
@@ -142,6 +160,10 @@ func NewSpanProcessorMetrics(serviceMetrics metrics.Factory, hostMetrics metrics
 		SavedErrBySvc:  newMetricsBySvc(serviceMetrics.Namespace(metrics.NSOptions{Name: "", Tags: map[string]string{"result": "err"}}), "saved-by-svc"),
 		spanCounts:     spanCounts,
 		serviceNames:   hostMetrics.Gauge(metrics.Options{Name: "spans.serviceNames", Tags: nil}),
+
+		SpansRejectedByAdmission:  hostMetrics.Counter(metrics.Options{Name: "admission.spans.rejected", Tags: nil}),
+		AdmissionRejectionPercent: hostMetrics.Gauge(metrics.Options{Name: "admission.rejection-percent", Tags: nil}),
+		AdmissionOverloadPercent:  hostMetrics.Gauge(metrics.Options{Name: "admission.overload-percent", Tags: nil}),
 	}
 
 	return m
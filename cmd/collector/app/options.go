@@ -16,11 +16,15 @@
 package app
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/tailsampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/tagfilter"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 )
@@ -36,6 +40,13 @@ type options struct {
 	numWorkers             int
 	blockingSubmit         bool
 	queueSize              int
+	queueDrainTimeout      time.Duration
+	backpressure           flags.BackpressureOptions
+	admission              flags.AdmissionOptions
+	tailSampling           tailsampling.Options
+	tagFilter              tagfilter.Options
+	topServices            *TopServices
+	traceSizes             *TraceSizes
 	dynQueueSizeWarmup     uint
 	dynQueueSizeMemory     uint
 	reportBusy             bool
@@ -123,6 +134,59 @@ func (options) QueueSize(queueSize int) Option {
 	}
 }
 
+// QueueDrainTimeout creates an Option that initializes how long the processor keeps consuming
+// the queue on Close before abandoning the remaining spans. Zero disables draining.
+func (options) QueueDrainTimeout(queueDrainTimeout time.Duration) Option {
+	return func(b *options) {
+		b.queueDrainTimeout = queueDrainTimeout
+	}
+}
+
+// Backpressure creates an Option that initializes how the processor throttles incoming spans
+// while the span writer reports more pending writes than the threshold.
+func (options) Backpressure(backpressure flags.BackpressureOptions) Option {
+	return func(b *options) {
+		b.backpressure = backpressure
+	}
+}
+
+// Admission creates an Option that initializes how the processor rejects incoming spans
+// while the span writes are slower than the target latency.
+func (options) Admission(admission flags.AdmissionOptions) Option {
+	return func(b *options) {
+		b.admission = admission
+	}
+}
+
+// TailSampling creates an Option that initializes the policies and the buffering of the spans
+// deciding which traces are saved once their spans are received.
+func (options) TailSampling(tailSampling tailsampling.Options) Option {
+	return func(b *options) {
+		b.tailSampling = tailSampling
+	}
+}
+
+// TagFilter creates an Option that initializes the patterns of the tags removed from the spans before they are written.
+func (options) TagFilter(tagFilter tagfilter.Options) Option {
+	return func(b *options) {
+		b.tagFilter = tagFilter
+	}
+}
+
+// TopServices creates an Option that initializes the tracking of the services sending the most spans.
+func (options) TopServices(topServices *TopServices) Option {
+	return func(b *options) {
+		b.topServices = topServices
+	}
+}
+
+// TraceSizes creates an Option that initializes the histograms of the sizes of the spans and traces received.
+func (options) TraceSizes(traceSizes *TraceSizes) Option {
+	return func(b *options) {
+		b.traceSizes = traceSizes
+	}
+}
+
 // DynQueueSizeWarmup creates an Option that initializes the dynamic queue size
 func (options) DynQueueSizeWarmup(dynQueueSizeWarmup uint) Option {
 	return func(b *options) {
@@ -62,6 +62,8 @@ const (
 	ProtoSpanFormat SpanFormat = "proto"
 	// OTLPSpanFormat is for OpenTelemetry OTLP format.
 	OTLPSpanFormat SpanFormat = "otlp"
+	// XRaySpanFormat is for AWS X-Ray segment documents.
+	XRaySpanFormat SpanFormat = "xray"
 	// UnknownSpanFormat is the fallback/catch-all category.
 	UnknownSpanFormat SpanFormat = "unknown"
 )
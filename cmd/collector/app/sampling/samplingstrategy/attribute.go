@@ -0,0 +1,37 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstrategy
+
+import (
+	"context"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// AttributeStrategy samples with the probability SamplingRate the traces whose root span has the tag Key
+// with the value Value, e.g. all the traces of the tenant=vip requests. The attribute strategies of a service
+// take precedence over its operation strategies, the first one matching a span applying.
+type AttributeStrategy struct {
+	Key          string
+	Value        string
+	SamplingRate float64
+}
+
+// AttributeProvider is implemented by the Providers which also have attribute strategies.
+// The attribute strategies are not part of api_v2.SamplingStrategyResponse, so the clients
+// which do not know them keep sampling with the operation strategies.
+type AttributeProvider interface {
+	// GetAttributeStrategies retrieves the attribute strategies of the specified service, in order of precedence.
+	GetAttributeStrategies(ctx context.Context, serviceName string) ([]AttributeStrategy, error)
+}
+
+// MatchAttributeStrategy returns the first of the strategies matching one of the tags, compared by their string value.
+func MatchAttributeStrategy(strategies []AttributeStrategy, tags model.KeyValues) (AttributeStrategy, bool) {
+	for _, strategy := range strategies {
+		if tag, ok := tags.FindByKey(strategy.Key); ok && tag.AsString() == strategy.Value {
+			return strategy, true
+		}
+	}
+	return AttributeStrategy{}, false
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package samplingstrategy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestMatchAttributeStrategy(t *testing.T) {
+	strategies := []AttributeStrategy{
+		{Key: "tenant", Value: "vip", SamplingRate: 1},
+		{Key: "debug", Value: "true", SamplingRate: 0.5},
+		{Key: "tenant", Value: "free", SamplingRate: 0.01},
+	}
+	testCases := []struct {
+		name     string
+		tags     model.KeyValues
+		expected AttributeStrategy
+		matched  bool
+	}{
+		{
+			name:     "string tag",
+			tags:     model.KeyValues{model.String("http.method", "GET"), model.String("tenant", "free")},
+			expected: strategies[2],
+			matched:  true,
+		},
+		{
+			name:     "bool tag compared by its string value",
+			tags:     model.KeyValues{model.Bool("debug", true)},
+			expected: strategies[1],
+			matched:  true,
+		},
+		{
+			name:     "first matching strategy",
+			tags:     model.KeyValues{model.Bool("debug", true), model.String("tenant", "vip")},
+			expected: strategies[0],
+			matched:  true,
+		},
+		{
+			name: "no matching value",
+			tags: model.KeyValues{model.String("tenant", "other")},
+		},
+		{
+			name: "no tags",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			strategy, matched := MatchAttributeStrategy(strategies, tc.tags)
+			assert.Equal(t, tc.matched, matched)
+			assert.Equal(t, tc.expected, strategy)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tailsampling
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// policyConfig is a policy of the policy file, the policies of the and/or types being nested.
+type policyConfig struct {
+	Name         string         `yaml:"name"`
+	Type         string         `yaml:"type"`
+	MinDuration  time.Duration  `yaml:"min_duration"`
+	Key          string         `yaml:"key"`
+	Values       []string       `yaml:"values"`
+	SamplingRate *float64       `yaml:"sampling_rate"`
+	Policies     []policyConfig `yaml:"policies"`
+}
+
+// configFile is the format of the policy file, e.g.
+//
+//	policies:
+//	  - name: errors
+//	    type: error
+//	  - name: slow
+//	    type: latency
+//	    min_duration: 2s
+//	  - name: slow-checkout
+//	    type: and
+//	    policies:
+//	      - {type: tag, key: http.route, values: [/checkout]}
+//	      - {type: latency, min_duration: 500ms}
+//	  - name: baseline
+//	    type: probabilistic
+//	    sampling_rate: 0.01
+//
+// A trace is kept by the first policy that keeps it, and dropped if none does.
+type configFile struct {
+	Policies []policyConfig `yaml:"policies"`
+}
+
+// LoadPolicyFile reads the tail sampling policies from the YAML file.
+func LoadPolicyFile(path string) ([]NamedPolicy, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tail sampling policy file: %w", err)
+	}
+	var file configFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse the tail sampling policy file %s: %w", path, err)
+	}
+	if len(file.Policies) == 0 {
+		return nil, fmt.Errorf("invalid tail sampling policy file %s: no policies", path)
+	}
+	names := make(map[string]struct{}, len(file.Policies))
+	policies := make([]NamedPolicy, len(file.Policies))
+	for i, config := range file.Policies {
+		if config.Name == "" {
+			return nil, fmt.Errorf("invalid tail sampling policy file %s: policy #%d has no name", path, i+1)
+		}
+		if _, ok := names[config.Name]; ok {
+			return nil, fmt.Errorf("invalid tail sampling policy file %s: duplicate policy %q", path, config.Name)
+		}
+		names[config.Name] = struct{}{}
+		policy, err := config.policy()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tail sampling policy %q in %s: %w", config.Name, path, err)
+		}
+		policies[i] = NamedPolicy{Name: config.Name, Policy: policy}
+	}
+	return policies, nil
+}
+
+func (c *policyConfig) policy() (Policy, error) {
+	switch c.Type {
+	case "error":
+		return ErrorPolicy{}, nil
+	case "latency":
+		if c.MinDuration <= 0 {
+			return nil, errors.New("the latency policy requires a positive min_duration")
+		}
+		return LatencyPolicy{MinDuration: c.MinDuration}, nil
+	case "tag":
+		if c.Key == "" {
+			return nil, errors.New("the tag policy requires a key")
+		}
+		return TagPolicy{Key: c.Key, Values: c.Values}, nil
+	case "probabilistic":
+		if c.SamplingRate == nil || *c.SamplingRate < 0 || *c.SamplingRate > 1 {
+			return nil, errors.New("the probabilistic policy requires a sampling_rate between 0 and 1")
+		}
+		return ProbabilisticPolicy{SamplingRate: *c.SamplingRate}, nil
+	case "and", "or":
+		if len(c.Policies) == 0 {
+			return nil, fmt.Errorf("the %s policy requires nested policies", c.Type)
+		}
+		nested := make([]Policy, len(c.Policies))
+		for i := range c.Policies {
+			policy, err := c.Policies[i].policy()
+			if err != nil {
+				return nil, err
+			}
+			nested[i] = policy
+		}
+		if c.Type == "and" {
+			return AndPolicy(nested), nil
+		}
+		return OrPolicy(nested), nil
+	default:
+		return nil, fmt.Errorf("unknown policy type %q, expected one of error, latency, tag, probabilistic, and, or", c.Type)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tailsampling
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePolicyFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	path := writePolicyFile(t, `
+policies:
+  - name: errors
+    type: error
+  - name: slow
+    type: latency
+    min_duration: 2s
+  - name: slow-checkout
+    type: and
+    policies:
+      - {type: tag, key: http.route, values: [/checkout]}
+      - type: or
+        policies:
+          - {type: latency, min_duration: 500ms}
+          - {type: tag, key: customer.tier}
+  - name: baseline
+    type: probabilistic
+    sampling_rate: 0.01
+`)
+	policies, err := LoadPolicyFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []NamedPolicy{
+		{Name: "errors", Policy: ErrorPolicy{}},
+		{Name: "slow", Policy: LatencyPolicy{MinDuration: 2 * time.Second}},
+		{Name: "slow-checkout", Policy: AndPolicy{
+			TagPolicy{Key: "http.route", Values: []string{"/checkout"}},
+			OrPolicy{
+				LatencyPolicy{MinDuration: 500 * time.Millisecond},
+				TagPolicy{Key: "customer.tier"},
+			},
+		}},
+		{Name: "baseline", Policy: ProbabilisticPolicy{SamplingRate: 0.01}},
+	}, policies)
+}
+
+func TestLoadPolicyFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{name: "not YAML", content: "policies: [", errMsg: "failed to parse the tail sampling policy file"},
+		{name: "unknown field", content: "policies:\n  - {name: slow, type: latency, min_duration: 1s, max_duration: 2s}", errMsg: "field max_duration not found"},
+		{name: "no policies", content: "policies: []", errMsg: "no policies"},
+		{name: "no name", content: "policies:\n  - type: error", errMsg: "policy #1 has no name"},
+		{name: "duplicate name", content: "policies:\n  - {name: errors, type: error}\n  - {name: errors, type: error}", errMsg: `duplicate policy "errors"`},
+		{name: "unknown type", content: "policies:\n  - {name: vip, type: rate_limiting}", errMsg: `unknown policy type "rate_limiting"`},
+		{name: "no min duration", content: "policies:\n  - {name: slow, type: latency}", errMsg: "requires a positive min_duration"},
+		{name: "no tag key", content: "policies:\n  - {name: vip, type: tag, values: [gold]}", errMsg: "the tag policy requires a key"},
+		{name: "no sampling rate", content: "policies:\n  - {name: baseline, type: probabilistic}", errMsg: "requires a sampling_rate between 0 and 1"},
+		{name: "sampling rate too high", content: "policies:\n  - {name: baseline, type: probabilistic, sampling_rate: 10}", errMsg: "requires a sampling_rate between 0 and 1"},
+		{name: "empty and", content: "policies:\n  - {name: both, type: and}", errMsg: "the and policy requires nested policies"},
+		{name: "invalid nested", content: "policies:\n  - name: either\n    type: or\n    policies: [{type: latency}]", errMsg: `invalid tail sampling policy "either"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadPolicyFile(writePolicyFile(t, test.content))
+			require.ErrorContains(t, err, test.errMsg)
+		})
+	}
+
+	_, err := LoadPolicyFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tailsampling
+
+import (
+	"math"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Policy decides whether to keep a trace from its spans buffered by the collector.
+type Policy interface {
+	// ShouldKeep returns whether the trace of the spans, which all have the same trace ID, is kept.
+	ShouldKeep(spans []*model.Span) bool
+}
+
+// NamedPolicy is a policy of the policy file, its name tagging the metrics of its decisions.
+type NamedPolicy struct {
+	Name string
+	Policy
+}
+
+// ErrorPolicy keeps the traces with a span in error, i.e. with the error tag set
+// to true, or the otel.status_code tag of the spans received as OTLP set to ERROR.
+type ErrorPolicy struct{}
+
+// ShouldKeep implements Policy
+func (ErrorPolicy) ShouldKeep(spans []*model.Span) bool {
+	for _, span := range spans {
+		for _, tag := range span.Tags {
+			switch {
+			case tag.Key == "error" && tag.AsString() == "true":
+				return true
+			case tag.Key == "otel.status_code" && tag.AsString() == "ERROR":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LatencyPolicy keeps the traces lasting at least MinDuration, from the start of
+// their first span to the end of their last one.
+type LatencyPolicy struct {
+	MinDuration time.Duration
+}
+
+// ShouldKeep implements Policy
+func (p LatencyPolicy) ShouldKeep(spans []*model.Span) bool {
+	if len(spans) == 0 {
+		return false
+	}
+	start, end := spans[0].StartTime, spans[0].StartTime.Add(spans[0].Duration)
+	for _, span := range spans[1:] {
+		if span.StartTime.Before(start) {
+			start = span.StartTime
+		}
+		if spanEnd := span.StartTime.Add(span.Duration); spanEnd.After(end) {
+			end = spanEnd
+		}
+	}
+	return end.Sub(start) >= p.MinDuration
+}
+
+// TagPolicy keeps the traces with a span, or the process of a span, having the tag Key
+// with one of the Values, or with any value when there are none.
+type TagPolicy struct {
+	Key    string
+	Values []string
+}
+
+// ShouldKeep implements Policy
+func (p TagPolicy) ShouldKeep(spans []*model.Span) bool {
+	for _, span := range spans {
+		if p.matches(span.Tags) || span.Process != nil && p.matches(span.Process.Tags) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p TagPolicy) matches(tags []model.KeyValue) bool {
+	for _, tag := range tags {
+		if tag.Key != p.Key {
+			continue
+		}
+		if len(p.Values) == 0 {
+			return true
+		}
+		value := tag.AsString()
+		for _, v := range p.Values {
+			if v == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProbabilisticPolicy keeps the ratio SamplingRate of the traces, e.g. as the last policy
+// to keep a baseline of the traces no other policy keeps. The decision only depends on
+// the trace ID, so that the collectors keep the same traces.
+type ProbabilisticPolicy struct {
+	SamplingRate float64
+}
+
+// ShouldKeep implements Policy
+func (p ProbabilisticPolicy) ShouldKeep(spans []*model.Span) bool {
+	if len(spans) == 0 || p.SamplingRate <= 0 {
+		return false
+	}
+	if p.SamplingRate >= 1 {
+		return true
+	}
+	return spans[0].TraceID.Low < uint64(p.SamplingRate*math.MaxUint64)
+}
+
+// AndPolicy keeps the traces all its policies keep.
+type AndPolicy []Policy
+
+// ShouldKeep implements Policy
+func (p AndPolicy) ShouldKeep(spans []*model.Span) bool {
+	for _, policy := range p {
+		if !policy.ShouldKeep(spans) {
+			return false
+		}
+	}
+	return len(p) > 0
+}
+
+// OrPolicy keeps the traces any of its policies keeps.
+type OrPolicy []Policy
+
+// ShouldKeep implements Policy
+func (p OrPolicy) ShouldKeep(spans []*model.Span) bool {
+	for _, policy := range p {
+		if policy.ShouldKeep(spans) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tailsampling
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var policyTestStart = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func testSpan(traceID uint64, start, duration time.Duration, tags ...model.KeyValue) *model.Span {
+	return &model.Span{
+		TraceID:   model.NewTraceID(0, traceID),
+		SpanID:    model.NewSpanID(uint64(len(tags)) + 1),
+		StartTime: policyTestStart.Add(start),
+		Duration:  duration,
+		Tags:      tags,
+		Process:   model.NewProcess("frontend", []model.KeyValue{model.String("region", "eu-west-1")}),
+	}
+}
+
+func TestErrorPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []model.KeyValue
+		keep bool
+	}{
+		{name: "error tag", tags: []model.KeyValue{model.Bool("error", true)}, keep: true},
+		{name: "error string tag", tags: []model.KeyValue{model.String("error", "true")}, keep: true},
+		{name: "OTLP status", tags: []model.KeyValue{model.String("otel.status_code", "ERROR")}, keep: true},
+		{name: "error tag false", tags: []model.KeyValue{model.Bool("error", false)}},
+		{name: "OTLP status ok", tags: []model.KeyValue{model.String("otel.status_code", "OK")}},
+		{name: "no tags"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spans := []*model.Span{testSpan(1, 0, time.Second), testSpan(1, 0, time.Second, test.tags...)}
+			assert.Equal(t, test.keep, ErrorPolicy{}.ShouldKeep(spans))
+		})
+	}
+}
+
+func TestLatencyPolicy(t *testing.T) {
+	policy := LatencyPolicy{MinDuration: time.Second}
+	// the duration of the trace spans from the start of its first span to the end of its last one
+	assert.True(t, policy.ShouldKeep([]*model.Span{
+		testSpan(1, 200*time.Millisecond, 300*time.Millisecond),
+		testSpan(1, 0, 100*time.Millisecond),
+		testSpan(1, 600*time.Millisecond, 400*time.Millisecond),
+	}))
+	assert.False(t, policy.ShouldKeep([]*model.Span{
+		testSpan(1, 0, 500*time.Millisecond),
+		testSpan(1, 100*time.Millisecond, 899*time.Millisecond),
+	}))
+	assert.True(t, policy.ShouldKeep([]*model.Span{testSpan(1, 0, time.Second)}))
+	assert.False(t, policy.ShouldKeep(nil))
+}
+
+func TestTagPolicy(t *testing.T) {
+	spans := []*model.Span{
+		testSpan(1, 0, time.Second),
+		testSpan(1, 0, time.Second, model.String("http.route", "/checkout"), model.Int64("http.status_code", 503)),
+	}
+	tests := []struct {
+		name   string
+		policy TagPolicy
+		keep   bool
+	}{
+		{name: "matching value", policy: TagPolicy{Key: "http.route", Values: []string{"/cart", "/checkout"}}, keep: true},
+		{name: "other value", policy: TagPolicy{Key: "http.route", Values: []string{"/cart"}}},
+		{name: "any value", policy: TagPolicy{Key: "http.route"}, keep: true},
+		{name: "non-string value", policy: TagPolicy{Key: "http.status_code", Values: []string{"503"}}, keep: true},
+		{name: "process tag", policy: TagPolicy{Key: "region", Values: []string{"eu-west-1"}}, keep: true},
+		{name: "missing tag", policy: TagPolicy{Key: "customer.tier"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.keep, test.policy.ShouldKeep(spans))
+		})
+	}
+}
+
+func TestProbabilisticPolicy(t *testing.T) {
+	low := []*model.Span{testSpan(math.MaxUint64/10, 0, time.Second)}
+	high := []*model.Span{testSpan(math.MaxUint64/10*9, 0, time.Second)}
+
+	policy := ProbabilisticPolicy{SamplingRate: 0.5}
+	assert.True(t, policy.ShouldKeep(low))
+	assert.False(t, policy.ShouldKeep(high))
+	assert.True(t, ProbabilisticPolicy{SamplingRate: 1}.ShouldKeep(high))
+	assert.False(t, ProbabilisticPolicy{SamplingRate: 0}.ShouldKeep(low))
+	assert.False(t, policy.ShouldKeep(nil))
+
+	// the ratio of the traces kept is close to the sampling rate
+	var kept int
+	for i := uint64(0); i < 1000; i++ {
+		if policy.ShouldKeep([]*model.Span{testSpan(i*(math.MaxUint64/1000), 0, time.Second)}) {
+			kept++
+		}
+	}
+	assert.InDelta(t, 500, kept, 1)
+}
+
+func TestCompositePolicies(t *testing.T) {
+	spans := []*model.Span{testSpan(1, 0, 2*time.Second, model.String("http.route", "/checkout"))}
+	checkout := TagPolicy{Key: "http.route", Values: []string{"/checkout"}}
+	slow := LatencyPolicy{MinDuration: time.Second}
+
+	assert.True(t, AndPolicy{checkout, slow}.ShouldKeep(spans))
+	assert.False(t, AndPolicy{checkout, slow, ErrorPolicy{}}.ShouldKeep(spans))
+	assert.False(t, AndPolicy{}.ShouldKeep(spans))
+	assert.True(t, OrPolicy{ErrorPolicy{}, slow}.ShouldKeep(spans))
+	assert.False(t, OrPolicy{ErrorPolicy{}, LatencyPolicy{MinDuration: time.Minute}}.ShouldKeep(spans))
+	assert.True(t, OrPolicy{ErrorPolicy{}, AndPolicy{checkout, slow}}.ShouldKeep(spans))
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tailsampling decides which traces the collector saves once their spans are received, e.g. to keep
+// the traces with errors or slow ones, which head-based sampling cannot select. Each collector decides alone
+// on the spans it receives: there is no coordination between the collectors.
+package tailsampling
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const (
+	// EvictionDecisionKeep keeps the traces evicted from the buffer when it is full
+	EvictionDecisionKeep = "keep"
+	// EvictionDecisionDrop drops the traces evicted from the buffer when it is full
+	EvictionDecisionDrop = "drop"
+
+	// numShards is the number of shards of the buffer, each with its own lock and decision loop
+	numShards = 16
+
+	// minDecisionCacheSize is the minimum number of recent decisions remembered by a shard
+	minDecisionCacheSize = 1024
+)
+
+// Options configures the tail sampling of the collector.
+type Options struct {
+	// Policies decide which traces are kept, tail sampling being disabled without policies
+	Policies []NamedPolicy
+	// DecisionWait is how long the spans of a trace are buffered after its first span before it is decided
+	DecisionWait time.Duration
+	// RootSpanWait is how long the spans of a trace are buffered after its root span, if shorter than the decision wait
+	RootSpanWait time.Duration
+	// MaxSpans is the number of spans buffered above which the oldest traces are evicted
+	MaxSpans int
+	// EvictionDecision is whether the evicted traces are kept or dropped, either EvictionDecisionKeep or EvictionDecisionDrop
+	EvictionDecision string
+}
+
+// FlushSpan saves a span of a kept trace.
+type FlushSpan func(span *model.Span, tenant string)
+
+// Sampler buffers the spans per trace, until a decision wait elapses after the first span of the trace, or a shorter
+// wait after its root span, which is usually the last one to finish, and then keeps or drops the trace with the policies.
+// The spans of the kept traces are flushed, as well as the spans received later for the traces recently kept.
+//
+// The decisions are local to the collector, which has no coordination with the other collectors: the spans of
+// a trace received by several collectors are decided independently, so the collectors are best behind a load
+// balancer routing the spans by trace ID.
+type Sampler struct {
+	options   Options
+	flush     FlushSpan
+	logger    *zap.Logger
+	metrics   samplerMetrics
+	keptBy    map[string]metrics.Counter
+	now       func() time.Time
+	tick      time.Duration
+	shards    [numShards]*shard
+	maxSpans  int
+	stopCh    chan struct{}
+	closeOnce sync.Once
+	done      sync.WaitGroup
+
+	spansBuffered  atomic.Int64
+	tracesBuffered atomic.Int64
+}
+
+type samplerMetrics struct {
+	// TracesDropped counts the traces no policy kept
+	TracesDropped metrics.Counter `metric:"traces" tags:"policy=none,decision=drop"`
+	// TracesForcedKept counts the traces kept because they were evicted from the full buffer
+	TracesForcedKept metrics.Counter `metric:"traces" tags:"policy=forced,decision=keep"`
+	// TracesForcedDropped counts the traces dropped because they were evicted from the full buffer
+	TracesForcedDropped metrics.Counter `metric:"traces" tags:"policy=forced,decision=drop"`
+	// LateSpansKept counts the spans received after their trace was kept
+	LateSpansKept metrics.Counter `metric:"spans.late" tags:"decision=keep"`
+	// LateSpansDropped counts the spans received after their trace was dropped
+	LateSpansDropped metrics.Counter `metric:"spans.late" tags:"decision=drop"`
+	// DecidedOnDecisionWait counts the traces decided once the decision wait elapsed
+	DecidedOnDecisionWait metrics.Counter `metric:"traces.decided" tags:"trigger=decision-wait"`
+	// DecidedOnRootSpan counts the traces decided once the root span wait elapsed
+	DecidedOnRootSpan metrics.Counter `metric:"traces.decided" tags:"trigger=root-span"`
+	// DecidedOnShutdown counts the traces decided when the collector stops
+	DecidedOnShutdown metrics.Counter `metric:"traces.decided" tags:"trigger=shutdown"`
+	// SpansBuffered records the number of spans buffered
+	SpansBuffered metrics.Gauge `metric:"spans.buffered"`
+	// TracesBuffered records the number of traces buffered
+	TracesBuffered metrics.Gauge `metric:"traces.buffered"`
+}
+
+// traceKey identifies a trace, the traces of the tenants being buffered apart.
+type traceKey struct {
+	tenant  string
+	traceID model.TraceID
+}
+
+type bufferedTrace struct {
+	key       traceKey
+	spans     []*model.Span
+	firstSeen time.Time
+	// rootSeen is when the root span was received, zero until then
+	rootSeen time.Time
+	element  *list.Element
+}
+
+// shard buffers the traces whose trace ID falls into it, and remembers its recent decisions.
+type shard struct {
+	mu     sync.Mutex
+	traces map[traceKey]*bufferedTrace
+	// order holds the buffered traces from the oldest to the newest, the oldest being evicted first
+	order *list.List
+	spans int
+
+	// decisions holds whether the recently decided traces were kept, decisionKeys being the ring of their keys
+	decisions    map[traceKey]bool
+	decisionKeys []traceKey
+	nextDecision int
+}
+
+// NewSampler creates a Sampler flushing the spans of the kept traces, and starts its decision loops.
+func NewSampler(options Options, flush FlushSpan, metricsFactory metrics.Factory, logger *zap.Logger) *Sampler {
+	s := newSampler(options, flush, metricsFactory, logger)
+	for _, sh := range s.shards {
+		s.done.Add(1)
+		go s.decisionLoop(sh)
+	}
+	logger.Info("Tail sampling the traces",
+		zap.Int("policies", len(options.Policies)),
+		zap.Duration("decision-wait", options.DecisionWait),
+		zap.Duration("root-span-wait", options.RootSpanWait),
+		zap.Int("max-spans", options.MaxSpans),
+		zap.String("eviction-decision", options.EvictionDecision))
+	return s
+}
+
+func newSampler(options Options, flush FlushSpan, metricsFactory metrics.Factory, logger *zap.Logger) *Sampler {
+	s := &Sampler{
+		options: options,
+		flush:   flush,
+		logger:  logger,
+		keptBy:  make(map[string]metrics.Counter, len(options.Policies)),
+		now:     time.Now,
+		// the traces are decided within a tenth of the shortest wait
+		tick:     min(max(min(options.DecisionWait, options.RootSpanWait)/10, 10*time.Millisecond), time.Second),
+		maxSpans: max(options.MaxSpans/numShards, 1),
+		stopCh:   make(chan struct{}),
+	}
+	metrics.MustInit(&s.metrics, metricsFactory, nil)
+	for _, policy := range options.Policies {
+		s.keptBy[policy.Name] = metricsFactory.Counter(metrics.Options{
+			Name: "traces",
+			Tags: map[string]string{"policy": policy.Name, "decision": "keep"},
+		})
+	}
+	// a shard remembers the decisions of at least as many traces as it can buffer
+	decisionCacheSize := max(s.maxSpans, minDecisionCacheSize)
+	for i := range s.shards {
+		s.shards[i] = &shard{
+			traces:       make(map[traceKey]*bufferedTrace),
+			order:        list.New(),
+			decisions:    make(map[traceKey]bool, decisionCacheSize),
+			decisionKeys: make([]traceKey, decisionCacheSize),
+		}
+	}
+	return s
+}
+
+// Add buffers the span until its trace is decided, or flushes or drops it right away if its trace was recently decided.
+// The oldest traces are evicted, and kept or dropped regardless of the policies, while the buffer holds too many spans.
+func (s *Sampler) Add(span *model.Span, tenant string) {
+	key := traceKey{tenant: tenant, traceID: span.TraceID}
+	sh := s.shards[span.TraceID.Low%numShards]
+
+	sh.mu.Lock()
+	if keep, ok := sh.decisions[key]; ok {
+		sh.mu.Unlock()
+		if keep {
+			s.metrics.LateSpansKept.Inc(1)
+			s.flush(span, tenant)
+		} else {
+			s.metrics.LateSpansDropped.Inc(1)
+		}
+		return
+	}
+	now := s.now()
+	trace, ok := sh.traces[key]
+	if !ok {
+		trace = &bufferedTrace{key: key, firstSeen: now}
+		trace.element = sh.order.PushBack(trace)
+		sh.traces[key] = trace
+		s.tracesBuffered.Add(1)
+	}
+	trace.spans = append(trace.spans, span)
+	sh.spans++
+	s.spansBuffered.Add(1)
+	if trace.rootSeen.IsZero() && span.ParentSpanID() == 0 {
+		trace.rootSeen = now
+	}
+	var evicted []*bufferedTrace
+	keep := s.options.EvictionDecision == EvictionDecisionKeep
+	for sh.spans > s.maxSpans {
+		oldest := sh.order.Front().Value.(*bufferedTrace)
+		s.remove(sh, oldest)
+		sh.remember(oldest.key, keep)
+		evicted = append(evicted, oldest)
+	}
+	sh.mu.Unlock()
+
+	for _, trace := range evicted {
+		if keep {
+			s.metrics.TracesForcedKept.Inc(1)
+			s.flushTrace(trace)
+		} else {
+			s.metrics.TracesForcedDropped.Inc(1)
+		}
+	}
+}
+
+// decisionLoop decides the traces of the shard whose wait elapsed, until the sampler is closed.
+func (s *Sampler) decisionLoop(sh *shard) {
+	defer s.done.Done()
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.decideReady(sh, s.now())
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// decideReady decides the traces of the shard whose decision wait or root span wait elapsed at now.
+func (s *Sampler) decideReady(sh *shard, now time.Time) {
+	var kept []*bufferedTrace
+	sh.mu.Lock()
+	for e := sh.order.Front(); e != nil; {
+		trace := e.Value.(*bufferedTrace)
+		e = e.Next()
+		switch {
+		case !now.Before(trace.firstSeen.Add(s.options.DecisionWait)):
+			s.metrics.DecidedOnDecisionWait.Inc(1)
+		case !trace.rootSeen.IsZero() && !now.Before(trace.rootSeen.Add(s.options.RootSpanWait)):
+			s.metrics.DecidedOnRootSpan.Inc(1)
+		default:
+			continue
+		}
+		if s.decide(sh, trace) {
+			kept = append(kept, trace)
+		}
+	}
+	sh.mu.Unlock()
+	s.updateGauges()
+
+	for _, trace := range kept {
+		s.flushTrace(trace)
+	}
+}
+
+// decide removes the trace from the shard and returns whether it is kept, according to the first policy that keeps it.
+// The policies are evaluated with the lock of the shard held, so that the spans received meanwhile follow the decision.
+func (s *Sampler) decide(sh *shard, trace *bufferedTrace) bool {
+	s.remove(sh, trace)
+	for _, policy := range s.options.Policies {
+		if policy.ShouldKeep(trace.spans) {
+			s.keptBy[policy.Name].Inc(1)
+			sh.remember(trace.key, true)
+			return true
+		}
+	}
+	s.metrics.TracesDropped.Inc(1)
+	sh.remember(trace.key, false)
+	return false
+}
+
+func (s *Sampler) flushTrace(trace *bufferedTrace) {
+	for _, span := range trace.spans {
+		s.flush(span, trace.key.tenant)
+	}
+}
+
+func (s *Sampler) updateGauges() {
+	s.metrics.SpansBuffered.Update(s.spansBuffered.Load())
+	s.metrics.TracesBuffered.Update(s.tracesBuffered.Load())
+}
+
+// Close stops the decision loops, and decides the traces still buffered with the spans received so far.
+func (s *Sampler) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stopCh)
+		s.done.Wait()
+		for _, sh := range s.shards {
+			var kept []*bufferedTrace
+			sh.mu.Lock()
+			for sh.order.Len() > 0 {
+				trace := sh.order.Front().Value.(*bufferedTrace)
+				s.metrics.DecidedOnShutdown.Inc(1)
+				if s.decide(sh, trace) {
+					kept = append(kept, trace)
+				}
+			}
+			sh.mu.Unlock()
+			for _, trace := range kept {
+				s.flushTrace(trace)
+			}
+		}
+	})
+	return nil
+}
+
+func (s *Sampler) remove(sh *shard, trace *bufferedTrace) {
+	sh.order.Remove(trace.element)
+	delete(sh.traces, trace.key)
+	sh.spans -= len(trace.spans)
+	s.spansBuffered.Add(-int64(len(trace.spans)))
+	s.tracesBuffered.Add(-1)
+}
+
+// remember records the decision of the trace, forgetting the oldest decision once the ring is full.
+func (sh *shard) remember(key traceKey, keep bool) {
+	if oldest := sh.decisionKeys[sh.nextDecision]; oldest != (traceKey{}) {
+		delete(sh.decisions, oldest)
+	}
+	sh.decisionKeys[sh.nextDecision] = key
+	sh.decisions[key] = keep
+	sh.nextDecision = (sh.nextDecision + 1) % len(sh.decisionKeys)
+}
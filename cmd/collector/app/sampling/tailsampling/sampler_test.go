@@ -0,0 +1,311 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tailsampling
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type flushedSpan struct {
+	traceID model.TraceID
+	spanID  model.SpanID
+	tenant  string
+}
+
+// flushRecorder records the spans flushed by the sampler.
+type flushRecorder struct {
+	mu    sync.Mutex
+	spans []flushedSpan
+}
+
+func (r *flushRecorder) flush(span *model.Span, tenant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, flushedSpan{traceID: span.TraceID, spanID: span.SpanID, tenant: tenant})
+}
+
+func (r *flushRecorder) flushed() []flushedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]flushedSpan(nil), r.spans...)
+}
+
+var errorsPolicy = NamedPolicy{Name: "errors", Policy: ErrorPolicy{}}
+
+func samplerSpan(traceID, spanID, parentID uint64, tags ...model.KeyValue) *model.Span {
+	span := &model.Span{
+		TraceID: model.NewTraceID(0, traceID),
+		SpanID:  model.NewSpanID(spanID),
+		Tags:    tags,
+		Process: model.NewProcess("frontend", nil),
+	}
+	if parentID != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(span.TraceID, model.NewSpanID(parentID))}
+	}
+	return span
+}
+
+type samplerTest struct {
+	sampler *Sampler
+	flushes *flushRecorder
+	metrics *metricstest.Factory
+	now     time.Time
+}
+
+// newSamplerTest creates a sampler without decision loops, whose clock is advanced by the tests.
+func newSamplerTest(t *testing.T, options Options) *samplerTest {
+	st := &samplerTest{
+		flushes: &flushRecorder{},
+		metrics: metricstest.NewFactory(time.Hour),
+		now:     time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	t.Cleanup(st.metrics.Stop)
+	st.sampler = newSampler(options, st.flushes.flush, st.metrics, zap.NewNop())
+	st.sampler.now = func() time.Time { return st.now }
+	return st
+}
+
+func (st *samplerTest) decideReady() {
+	for _, sh := range st.sampler.shards {
+		st.sampler.decideReady(sh, st.now)
+	}
+}
+
+func defaultTestOptions(policies ...NamedPolicy) Options {
+	return Options{
+		Policies:         policies,
+		DecisionWait:     10 * time.Second,
+		RootSpanWait:     2 * time.Second,
+		MaxSpans:         1000,
+		EvictionDecision: EvictionDecisionDrop,
+	}
+}
+
+func TestSamplerDecisionWait(t *testing.T) {
+	st := newSamplerTest(t, defaultTestOptions(errorsPolicy))
+	st.sampler.Add(samplerSpan(1, 2, 1), "")
+	st.sampler.Add(samplerSpan(1, 3, 1, model.Bool("error", true)), "")
+	st.sampler.Add(samplerSpan(2, 2, 1), "")
+
+	st.now = st.now.Add(10*time.Second - time.Millisecond)
+	st.decideReady()
+	assert.Empty(t, st.flushes.flushed())
+	st.metrics.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "spans.buffered", Value: 3},
+		metricstest.ExpectedMetric{Name: "traces.buffered", Value: 2},
+	)
+
+	st.now = st.now.Add(time.Millisecond)
+	st.decideReady()
+	assert.Equal(t, []flushedSpan{
+		{traceID: model.NewTraceID(0, 1), spanID: model.NewSpanID(2)},
+		{traceID: model.NewTraceID(0, 1), spanID: model.NewSpanID(3)},
+	}, st.flushes.flushed())
+	st.metrics.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "errors", "decision": "keep"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "none", "decision": "drop"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "traces.decided", Tags: map[string]string{"trigger": "decision-wait"}, Value: 2},
+	)
+	st.metrics.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "spans.buffered", Value: 0},
+		metricstest.ExpectedMetric{Name: "traces.buffered", Value: 0},
+	)
+}
+
+func TestSamplerFirstPolicyKeeping(t *testing.T) {
+	st := newSamplerTest(t, defaultTestOptions(
+		NamedPolicy{Name: "checkout", Policy: TagPolicy{Key: "http.route", Values: []string{"/checkout"}}},
+		errorsPolicy,
+		NamedPolicy{Name: "baseline", Policy: ProbabilisticPolicy{SamplingRate: 1}},
+	))
+	st.sampler.Add(samplerSpan(1, 1, 0, model.Bool("error", true)), "")
+	st.sampler.Add(samplerSpan(2, 1, 0, model.Bool("error", true), model.String("http.route", "/checkout")), "")
+	st.sampler.Add(samplerSpan(3, 1, 0), "")
+	st.now = st.now.Add(time.Minute)
+	st.decideReady()
+
+	assert.Len(t, st.flushes.flushed(), 3)
+	// the traces are counted by the first policy keeping them
+	st.metrics.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "checkout", "decision": "keep"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "errors", "decision": "keep"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "baseline", "decision": "keep"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "none", "decision": "drop"}, Value: 0},
+	)
+}
+
+func TestSamplerRootSpanWait(t *testing.T) {
+	st := newSamplerTest(t, defaultTestOptions(errorsPolicy))
+	st.sampler.Add(samplerSpan(1, 2, 1, model.Bool("error", true)), "")
+	st.now = st.now.Add(time.Second)
+	// the root span has no parent
+	st.sampler.Add(samplerSpan(1, 1, 0), "")
+
+	st.now = st.now.Add(2*time.Second - time.Millisecond)
+	st.decideReady()
+	assert.Empty(t, st.flushes.flushed())
+
+	st.now = st.now.Add(time.Millisecond)
+	st.decideReady()
+	assert.Len(t, st.flushes.flushed(), 2)
+	st.metrics.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "traces.decided", Tags: map[string]string{"trigger": "root-span"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "traces.decided", Tags: map[string]string{"trigger": "decision-wait"}, Value: 0},
+	)
+}
+
+func TestSamplerLateSpans(t *testing.T) {
+	st := newSamplerTest(t, defaultTestOptions(errorsPolicy))
+	st.sampler.Add(samplerSpan(1, 1, 0, model.Bool("error", true)), "")
+	st.sampler.Add(samplerSpan(2, 1, 0), "")
+	st.now = st.now.Add(time.Minute)
+	st.decideReady()
+	require.Len(t, st.flushes.flushed(), 1)
+
+	// the spans received after the decision of their trace follow it
+	st.sampler.Add(samplerSpan(1, 2, 1), "")
+	st.sampler.Add(samplerSpan(2, 2, 1), "")
+	assert.Equal(t, flushedSpan{traceID: model.NewTraceID(0, 1), spanID: model.NewSpanID(2)}, st.flushes.flushed()[1])
+	assert.Len(t, st.flushes.flushed(), 2)
+	st.metrics.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "spans.late", Tags: map[string]string{"decision": "keep"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "spans.late", Tags: map[string]string{"decision": "drop"}, Value: 1},
+	)
+	st.metrics.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "traces.buffered", Value: 0})
+}
+
+func TestSamplerTenants(t *testing.T) {
+	st := newSamplerTest(t, defaultTestOptions(errorsPolicy))
+	st.sampler.Add(samplerSpan(1, 1, 0, model.Bool("error", true)), "acme")
+	st.sampler.Add(samplerSpan(1, 1, 0), "country-store")
+	st.now = st.now.Add(time.Minute)
+	st.decideReady()
+
+	// the traces of the tenants are decided apart, even with the same trace ID
+	assert.Equal(t, []flushedSpan{{traceID: model.NewTraceID(0, 1), spanID: model.NewSpanID(1), tenant: "acme"}}, st.flushes.flushed())
+}
+
+func TestSamplerEviction(t *testing.T) {
+	tests := []struct {
+		decision      string
+		forcedKept    int
+		forcedDrop    int
+		lateSpansKept int
+	}{
+		{decision: EvictionDecisionKeep, forcedKept: 1, lateSpansKept: 1},
+		{decision: EvictionDecisionDrop, forcedDrop: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.decision, func(t *testing.T) {
+			options := defaultTestOptions(errorsPolicy)
+			// each shard buffers up to 2 spans
+			options.MaxSpans = 2 * numShards
+			options.EvictionDecision = test.decision
+			st := newSamplerTest(t, options)
+
+			// the trace IDs fall into the same shard
+			oldest, newer := uint64(numShards), uint64(2*numShards)
+			st.sampler.Add(samplerSpan(oldest, 1, 0), "")
+			st.sampler.Add(samplerSpan(oldest, 2, 1), "")
+			assert.Empty(t, st.flushes.flushed())
+			st.sampler.Add(samplerSpan(newer, 1, 0, model.Bool("error", true)), "")
+
+			// the oldest trace is evicted, regardless of the policies
+			var expected []flushedSpan
+			if test.forcedKept > 0 {
+				expected = []flushedSpan{
+					{traceID: model.NewTraceID(0, oldest), spanID: model.NewSpanID(1)},
+					{traceID: model.NewTraceID(0, oldest), spanID: model.NewSpanID(2)},
+				}
+			}
+			assert.Equal(t, expected, st.flushes.flushed())
+			st.metrics.AssertCounterMetrics(t,
+				metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "forced", "decision": "keep"}, Value: test.forcedKept},
+				metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "forced", "decision": "drop"}, Value: test.forcedDrop},
+			)
+
+			// the late spans of the evicted trace follow the forced decision
+			st.sampler.Add(samplerSpan(oldest, 3, 1), "")
+			assert.Len(t, st.flushes.flushed(), len(expected)+test.lateSpansKept)
+
+			// the newer trace is still decided by the policies
+			st.now = st.now.Add(time.Minute)
+			st.decideReady()
+			assert.Equal(t, flushedSpan{traceID: model.NewTraceID(0, newer), spanID: model.NewSpanID(1)},
+				st.flushes.flushed()[len(st.flushes.flushed())-1])
+			st.metrics.AssertCounterMetrics(t,
+				metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "errors", "decision": "keep"}, Value: 1},
+			)
+		})
+	}
+}
+
+func TestSamplerEvictionOfOversizedTrace(t *testing.T) {
+	options := defaultTestOptions(errorsPolicy)
+	options.MaxSpans = 2 * numShards
+	options.EvictionDecision = EvictionDecisionKeep
+	st := newSamplerTest(t, options)
+
+	// a trace with more spans than a shard can buffer evicts itself
+	for i := uint64(1); i <= 3; i++ {
+		st.sampler.Add(samplerSpan(1, i, 0), "")
+	}
+	assert.Len(t, st.flushes.flushed(), 3)
+	st.sampler.Add(samplerSpan(1, 4, 0), "")
+	assert.Len(t, st.flushes.flushed(), 4)
+	st.metrics.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "traces", Tags: map[string]string{"policy": "forced", "decision": "keep"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "spans.late", Tags: map[string]string{"decision": "keep"}, Value: 1},
+	)
+}
+
+func TestSamplerForgetsOldestDecisions(t *testing.T) {
+	sh := &shard{decisions: make(map[traceKey]bool), decisionKeys: make([]traceKey, 2)}
+	keys := []traceKey{
+		{traceID: model.NewTraceID(0, 1)},
+		{traceID: model.NewTraceID(0, 2)},
+		{traceID: model.NewTraceID(0, 3)},
+	}
+	sh.remember(keys[0], true)
+	sh.remember(keys[1], false)
+	sh.remember(keys[2], true)
+	assert.Equal(t, map[traceKey]bool{keys[1]: false, keys[2]: true}, sh.decisions)
+}
+
+func TestSamplerDecisionLoopsAndClose(t *testing.T) {
+	flushes := &flushRecorder{}
+	metricsFactory := metricstest.NewFactory(time.Hour)
+	defer metricsFactory.Stop()
+	s := NewSampler(Options{
+		Policies:         []NamedPolicy{errorsPolicy},
+		DecisionWait:     time.Hour,
+		RootSpanWait:     50 * time.Millisecond,
+		MaxSpans:         1000,
+		EvictionDecision: EvictionDecisionDrop,
+	}, flushes.flush, metricsFactory, zap.NewNop())
+
+	s.Add(samplerSpan(1, 1, 0, model.Bool("error", true)), "")
+	assert.Eventually(t, func() bool {
+		return len(flushes.flushed()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the traces still buffered are decided on close
+	s.Add(samplerSpan(2, 2, 1, model.Bool("error", true)), "")
+	s.Add(samplerSpan(3, 2, 1), "")
+	require.NoError(t, s.Close())
+	require.NoError(t, s.Close())
+	assert.Len(t, flushes.flushed(), 2)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "traces.decided", Tags: map[string]string{"trigger": "shutdown"}, Value: 2},
+	)
+}
@@ -30,7 +30,9 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/collector/app/handler"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/pkg/config/grpccfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
@@ -40,11 +42,13 @@ type GRPCServerParams struct {
 	HostPort                string
 	Handler                 *handler.GRPCHandler
 	SamplingProvider        samplingstrategy.Provider
+	TenancyMgr              *tenancy.Manager
 	Logger                  *zap.Logger
 	OnError                 func(error)
 	MaxReceiveMessageLength int
 	MaxConnectionAge        time.Duration
 	MaxConnectionAgeGrace   time.Duration
+	ServerOptions           grpccfg.ServerOptions
 
 	// Set by the server to indicate the actual host:port of the server.
 	HostPortActual string
@@ -58,14 +62,19 @@ func StartGRPCServer(params *GRPCServerParams) (*grpc.Server, error) {
 	if params.MaxReceiveMessageLength > 0 {
 		grpcOpts = append(grpcOpts, grpc.MaxRecvMsgSize(params.MaxReceiveMessageLength))
 	}
-	grpcOpts = append(grpcOpts, grpc.KeepaliveParams(keepalive.ServerParameters{
+	grpcOpts = append(grpcOpts, params.ServerOptions.GRPCServerOptions(keepalive.ServerParameters{
 		MaxConnectionAge:      params.MaxConnectionAge,
 		MaxConnectionAgeGrace: params.MaxConnectionAgeGrace,
-	}))
+	})...)
+
+	if params.TenancyMgr != nil && params.TenancyMgr.Enabled {
+		// the tenant selects the sampling strategies, the spans are guarded by the handler
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(tenancy.NewAttachingUnaryInterceptor(params.TenancyMgr)))
+	}
 
 	if params.TLSConfig.Enabled {
 		// user requested a server with TLS, setup creds
-		tlsCfg, err := params.TLSConfig.Config(params.Logger)
+		tlsCfg, err := params.TLSConfig.GRPCConfig(params.Logger)
 		if err != nil {
 			return nil, err
 		}
@@ -75,7 +84,9 @@ func StartGRPCServer(params *GRPCServerParams) (*grpc.Server, error) {
 	}
 
 	server = grpc.NewServer(grpcOpts...)
-	reflection.Register(server)
+	if !params.ServerOptions.DisableReflection {
+		reflection.Register(server)
+	}
 
 	listener, err := net.Listen("tcp", params.HostPort)
 	if err != nil {
@@ -101,7 +112,10 @@ func serveGRPC(server *grpc.Server, listener net.Listener, params *GRPCServerPar
 
 	grpc_health_v1.RegisterHealthServer(server, healthServer)
 
-	params.Logger.Info("Starting jaeger-collector gRPC server", zap.String("grpc.host-port", params.HostPortActual))
+	params.Logger.Info("Starting jaeger-collector gRPC server",
+		zap.String("grpc.host-port", params.HostPortActual),
+		zap.Int("grpc.max-message-size", params.MaxReceiveMessageLength),
+		zap.Object("grpc.server-options", params.ServerOptions))
 	go func() {
 		if err := server.Serve(listener); err != nil {
 			params.Logger.Error("Could not launch gRPC service", zap.Error(err))
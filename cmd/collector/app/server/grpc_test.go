@@ -16,22 +16,36 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
 	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/handler"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/internal/grpctest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config/grpccfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/grpccompression"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/static"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
@@ -96,6 +110,80 @@ func TestSpanCollector(t *testing.T) {
 	require.NotNil(t, response)
 }
 
+func TestSpanCollectorMaxReceiveMessageLength(t *testing.T) {
+	largeBatch := &api_v2.PostSpansRequest{Batch: model.Batch{
+		Process: &model.Process{ServiceName: strings.Repeat("x", 2048)},
+	}}
+	tests := []struct {
+		name         string
+		maxLength    int
+		expectedCode codes.Code
+	}{
+		{name: "over limit", maxLength: 1024, expectedCode: codes.ResourceExhausted},
+		{name: "within limit", maxLength: 4096, expectedCode: codes.OK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := zaptest.NewLogger(t)
+			params := &GRPCServerParams{
+				Handler:                 handler.NewGRPCHandler(logger, &mockSpanProcessor{}, &tenancy.Manager{}),
+				SamplingProvider:        &mockSamplingProvider{},
+				Logger:                  logger,
+				MaxReceiveMessageLength: test.maxLength,
+				ServerOptions: grpccfg.ServerOptions{
+					MaxConcurrentStreams: 10,
+					KeepaliveMinTime:     time.Minute,
+				},
+			}
+			server, err := StartGRPCServer(params)
+			require.NoError(t, err)
+			defer server.Stop()
+
+			conn, err := grpc.NewClient(
+				params.HostPortActual,
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(context.Background(), largeBatch)
+			assert.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestSpanCollectorCompression(t *testing.T) {
+	largeBatch := &api_v2.PostSpansRequest{Batch: model.Batch{
+		Process: &model.Process{ServiceName: strings.Repeat("x", 2048)},
+	}}
+	logger := zaptest.NewLogger(t)
+	params := &GRPCServerParams{
+		Handler:                 handler.NewGRPCHandler(logger, &mockSpanProcessor{}, &tenancy.Manager{}),
+		SamplingProvider:        &mockSamplingProvider{},
+		Logger:                  logger,
+		MaxReceiveMessageLength: 1024,
+	}
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(
+		params.HostPortActual,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := api_v2.NewCollectorServiceClient(conn)
+	for _, codec := range grpccompression.Codecs {
+		t.Run(codec, func(t *testing.T) {
+			_, err := client.PostSpans(context.Background(), &api_v2.PostSpansRequest{}, grpc.UseCompressor(codec))
+			require.NoError(t, err)
+			// the batch is compressed below the limit, which applies once decompressed
+			_, err = client.PostSpans(context.Background(), largeBatch, grpc.UseCompressor(codec))
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		})
+	}
+}
+
 func TestCollectorStartWithTLS(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	params := &GRPCServerParams{
@@ -115,6 +203,34 @@ func TestCollectorStartWithTLS(t *testing.T) {
 	defer params.TLSConfig.Close()
 }
 
+func TestCollectorStartWithTLSALPNProtocols(t *testing.T) {
+	params := &GRPCServerParams{
+		Handler:          handler.NewGRPCHandler(zap.NewNop(), &mockSpanProcessor{}, &tenancy.Manager{}),
+		SamplingProvider: &mockSamplingProvider{},
+		Logger:           zap.NewNop(),
+		HostPort:         "localhost:0",
+		TLSConfig: tlscfg.Options{
+			Enabled:       true,
+			CertPath:      testCertKeyLocation + "/example-server-cert.pem",
+			KeyPath:       testCertKeyLocation + "/example-server-key.pem",
+			ALPNProtocols: []string{"x-proxy", "h2"},
+		},
+	}
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+	defer params.TLSConfig.Close()
+
+	// the proxy negotiates its own protocol with the server
+	conn, err := tls.Dial("tcp", params.HostPortActual, &tls.Config{
+		NextProtos:         []string{"x-proxy"},
+		InsecureSkipVerify: true, /* #nosec G402 */
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "x-proxy", conn.ConnectionState().NegotiatedProtocol)
+}
+
 func TestCollectorReflection(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	params := &GRPCServerParams{
@@ -137,3 +253,80 @@ func TestCollectorReflection(t *testing.T) {
 		},
 	}.Execute(t)
 }
+
+func TestCollectorReflectionDisabled(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	params := &GRPCServerParams{
+		Handler:          handler.NewGRPCHandler(logger, &mockSpanProcessor{}, &tenancy.Manager{}),
+		SamplingProvider: &mockSamplingProvider{},
+		Logger:           logger,
+		ServerOptions:    grpccfg.ServerOptions{DisableReflection: true},
+	}
+
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+
+	_, registered := server.GetServiceInfo()["grpc.reflection.v1alpha.ServerReflection"]
+	assert.False(t, registered)
+	assert.Contains(t, server.GetServiceInfo(), "jaeger.api_v2.CollectorService")
+}
+
+// newTenantSamplingProvider creates a provider with the strategies of the tenant acme,
+// and the default strategies for the other tenants.
+func newTenantSamplingProvider(t *testing.T) samplingstrategy.Provider {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.json"),
+		[]byte(`{"default_strategy": {"type": "probabilistic", "param": 0.5}}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme.json"),
+		[]byte(`{"default_strategy": {"type": "probabilistic", "param": 0.1}}`), 0o600))
+	provider, err := static.NewProvider(static.Options{StrategiesDir: dir, IncludeDefaultOpStrategies: true}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, provider.Close()) })
+	return provider
+}
+
+var samplingTenantTests = []struct {
+	name         string
+	tenant       string
+	samplingRate float64
+}{
+	{name: "tenant strategies", tenant: "acme", samplingRate: 0.1},
+	{name: "tenant without strategies", tenant: "megacorp", samplingRate: 0.5},
+	{name: "unknown tenant", tenant: "evilcorp", samplingRate: 0.5},
+	{name: "missing tenant", tenant: "", samplingRate: 0.5},
+}
+
+func TestSamplingManagerTenancy(t *testing.T) {
+	logger := zap.NewNop()
+	tm := tenancy.NewManager(&tenancy.Options{Enabled: true, Tenants: []string{"acme", "megacorp"}})
+	params := &GRPCServerParams{
+		Handler:          handler.NewGRPCHandler(logger, &mockSpanProcessor{}, tm),
+		SamplingProvider: newTenantSamplingProvider(t),
+		TenancyMgr:       tm,
+		Logger:           logger,
+	}
+
+	server, err := StartGRPCServer(params)
+	require.NoError(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(
+		params.HostPortActual,
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	c := api_v2.NewSamplingManagerClient(conn)
+	for _, test := range samplingTenantTests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.tenant != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, tm.Header, test.tenant)
+			}
+			resp, err := c.GetSamplingStrategy(ctx, &api_v2.SamplingStrategyParameters{ServiceName: "foo"})
+			require.NoError(t, err)
+			assert.InDelta(t, test.samplingRate, resp.ProbabilisticSampling.SamplingRate, 0.01)
+		})
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/httpmetrics"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
 )
 
 // HTTPServerParams to construct a new Jaeger Collector HTTP Server
@@ -38,7 +39,9 @@ type HTTPServerParams struct {
 	TLSConfig        tlscfg.Options
 	HostPort         string
 	Handler          handler.JaegerBatchesHandler
+	XRayHandler      *handler.XRayHandler
 	SamplingProvider samplingstrategy.Provider
+	TenancyMgr       *tenancy.Manager
 	MetricsFactory   metrics.Factory
 	HealthCheck      *healthcheck.HealthCheck
 	Logger           *zap.Logger
@@ -97,8 +100,16 @@ func serveHTTP(server *http.Server, listener net.Listener, params *HTTPServerPar
 	})
 	cfgHandler.RegisterRoutes(r)
 
+	if params.XRayHandler != nil {
+		params.XRayHandler.RegisterRoutes(r)
+	}
+
 	recoveryHandler := recoveryhandler.NewRecoveryHandler(params.Logger, true)
-	server.Handler = httpmetrics.Wrap(recoveryHandler(r), params.MetricsFactory, params.Logger)
+	var h http.Handler = r
+	if params.TenancyMgr != nil {
+		h = tenancy.AttachTenantHTTPHandler(params.TenancyMgr, h)
+	}
+	server.Handler = httpmetrics.Wrap(recoveryHandler(h), params.MetricsFactory, params.Logger)
 	go func() {
 		var err error
 		if params.TLSConfig.Enabled {
@@ -16,10 +16,12 @@ package server
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +33,7 @@ import (
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/ports"
 )
 
@@ -278,3 +281,86 @@ func TestStartHTTPServerParams(t *testing.T) {
 	assert.Equal(t, 6*time.Minute, server.ReadTimeout)
 	assert.Equal(t, 7*time.Second, server.ReadHeaderTimeout)
 }
+
+func TestSamplingHTTPTenancy(t *testing.T) {
+	mFact := metricstest.NewFactory(time.Hour)
+	defer mFact.Stop()
+	logger := zap.NewNop()
+	tm := tenancy.NewManager(&tenancy.Options{Enabled: true, Tenants: []string{"acme", "megacorp"}})
+	params := &HTTPServerParams{
+		Handler:          handler.NewJaegerSpanHandler(logger, &mockSpanProcessor{}),
+		SamplingProvider: newTenantSamplingProvider(t),
+		TenancyMgr:       tm,
+		MetricsFactory:   mFact,
+		HealthCheck:      healthcheck.New(),
+		Logger:           logger,
+	}
+
+	server := httptest.NewServer(nil)
+	defer server.Close()
+	serveHTTP(server.Config, server.Listener, params)
+
+	for _, test := range samplingTenantTests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/api/sampling?service=foo", nil)
+			require.NoError(t, err)
+			if test.tenant != "" {
+				req.Header.Set(tm.Header, test.tenant)
+			}
+			response, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer response.Body.Close()
+			require.Equal(t, http.StatusOK, response.StatusCode)
+
+			var resp struct {
+				ProbabilisticSampling struct {
+					SamplingRate float64 `json:"samplingRate"`
+				} `json:"probabilisticSampling"`
+			}
+			require.NoError(t, json.NewDecoder(response.Body).Decode(&resp))
+			assert.InDelta(t, test.samplingRate, resp.ProbabilisticSampling.SamplingRate, 0.01)
+		})
+	}
+}
+
+func TestXRayHTTPEndpoint(t *testing.T) {
+	mFact := metricstest.NewFactory(time.Hour)
+	defer mFact.Stop()
+	logger := zap.NewNop()
+	body := `{"TraceSegmentDocuments": []}`
+	tests := []struct {
+		name         string
+		xrayHandler  *handler.XRayHandler
+		expectedCode int
+	}{
+		{
+			name:         "disabled",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "enabled",
+			xrayHandler:  handler.NewXRayHandler(logger, &mockSpanProcessor{}),
+			expectedCode: http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := &HTTPServerParams{
+				Handler:          handler.NewJaegerSpanHandler(logger, &mockSpanProcessor{}),
+				XRayHandler:      test.xrayHandler,
+				SamplingProvider: &mockSamplingProvider{},
+				MetricsFactory:   mFact,
+				HealthCheck:      healthcheck.New(),
+				Logger:           logger,
+			}
+			server := httptest.NewServer(nil)
+			defer server.Close()
+			serveHTTP(server.Config, server.Listener, params)
+
+			response, err := http.Post(server.URL+"/TraceSegments", "application/json", strings.NewReader(body))
+			require.NoError(t, err)
+			defer response.Body.Close()
+			assert.Equal(t, test.expectedCode, response.StatusCode)
+		})
+	}
+}
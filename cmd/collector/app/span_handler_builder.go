@@ -37,6 +37,10 @@ type SpanHandlerBuilder struct {
 	Logger         *zap.Logger
 	MetricsFactory metrics.Factory
 	TenancyMgr     *tenancy.Manager
+	// TopServices, if set, tracks the services sending the most spans
+	TopServices *TopServices
+	// TraceSizes, if set, records the histograms of the sizes of the spans and traces
+	TraceSizes *TraceSizes
 }
 
 // SpanHandlers holds instances to the span handlers built by the SpanHandlerBuilder
@@ -61,6 +65,13 @@ func (b *SpanHandlerBuilder) BuildSpanProcessor(additional ...ProcessSpan) proce
 		Options.SpanFilter(defaultSpanFilter),
 		Options.NumWorkers(b.CollectorOpts.NumWorkers),
 		Options.QueueSize(b.CollectorOpts.QueueSize),
+		Options.QueueDrainTimeout(b.CollectorOpts.QueueDrainTimeout),
+		Options.Backpressure(b.CollectorOpts.Backpressure),
+		Options.Admission(b.CollectorOpts.Admission),
+		Options.TailSampling(b.CollectorOpts.TailSampling),
+		Options.TagFilter(b.CollectorOpts.TagFilter),
+		Options.TopServices(b.TopServices),
+		Options.TraceSizes(b.TraceSizes),
 		Options.CollectorTags(b.CollectorOpts.CollectorTags),
 		Options.DynQueueSizeWarmup(uint(b.CollectorOpts.QueueSize)), // same as queue size for now
 		Options.DynQueueSizeMemory(b.CollectorOpts.DynQueueSizeMemory),
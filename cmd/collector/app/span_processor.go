@@ -17,15 +17,21 @@ package app
 
 import (
 	"context"
+	"errors"
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/tailsampling"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/tagfilter"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/queue"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -37,6 +43,12 @@ const (
 
 	// if the new queue size isn't 20% bigger than the previous one, don't change
 	minRequiredChange = 1.2
+
+	// how often a blocked span batch checks whether the span writer caught up
+	backpressurePollInterval = 10 * time.Millisecond
+
+	// how often Flush checks whether the queued spans were processed
+	flushPollInterval = 10 * time.Millisecond
 )
 
 type spanProcessor struct {
@@ -49,20 +61,38 @@ type spanProcessor struct {
 	processSpan        ProcessSpan
 	logger             *zap.Logger
 	spanWriter         spanstore.Writer
+	backpressureWriter spanstore.WriterWithBackpressure
+	backpressure       flags.BackpressureOptions
+	admission          *admissionController
+	tailSampler        *tailsampling.Sampler
+	tagFilter          *tagfilter.Filter
+	topServices        *TopServices
+	traceSizes         *TraceSizes
 	reportBusy         bool
 	numWorkers         int
+	queueDrainTimeout  time.Duration
 	collectorTags      map[string]string
 	dynQueueSizeWarmup uint
 	dynQueueSizeMemory uint
 	bytesProcessed     atomic.Uint64
 	spansProcessed     atomic.Uint64
-	stopCh             chan struct{}
+	// queuedSpans counts the spans enqueued and not yet processed in each of the two flush generations:
+	// the spans are counted in the generation current when they are enqueued, and Flush starts a new
+	// generation then waits for the spans of the previous one only.
+	queuedSpans [2]atomic.Int64
+	generation  atomic.Uint32
+	// flushMu serializes the flushes, each one waiting for the generation it ended
+	flushMu sync.Mutex
+	stats   *processorStats
+	stopCh  chan struct{}
 }
 
 type queueItem struct {
 	queuedTime time.Time
 	span       *model.Span
 	tenant     string
+	// generation is the flush generation of the span, see spanProcessor.queuedSpans
+	generation uint32
 }
 
 // NewSpanProcessor returns a SpanProcessor that preProcesses, filters, queues, sanitizes, and processes spans.
@@ -80,6 +110,10 @@ func NewSpanProcessor(
 
 	sp.background(1*time.Second, sp.updateGauges)
 
+	if sp.admission != nil {
+		sp.background(admissionUpdateInterval, sp.updateAdmission)
+	}
+
 	if sp.dynQueueSizeMemory > 0 {
 		sp.background(1*time.Minute, sp.updateQueueSize)
 	}
@@ -95,6 +129,9 @@ func newSpanProcessor(spanWriter spanstore.Writer, additional []ProcessSpan, opt
 		options.extraFormatTypes)
 	droppedItemHandler := func(item any) {
 		handlerMetrics.SpansDropped.Inc(1)
+		if options.topServices != nil {
+			options.topServices.dropped(item.(*queueItem).span)
+		}
 		if options.onDroppedSpan != nil {
 			options.onDroppedSpan(item.(*queueItem).span)
 		}
@@ -106,6 +143,12 @@ func newSpanProcessor(spanWriter spanstore.Writer, additional []ProcessSpan, opt
 		sanitizers = append(sanitizers, options.sanitizer)
 	}
 
+	// the latencies of the span writes are recorded once, for the status and the admission control
+	writeLatencyWindows := []time.Duration{statusWindow}
+	if options.admission.TargetLatency > 0 {
+		writeLatencyWindows = append(writeLatencyWindows, options.admission.Window)
+	}
+
 	sp := spanProcessor{
 		queue:              boundedQueue,
 		metrics:            handlerMetrics,
@@ -115,14 +158,55 @@ func newSpanProcessor(spanWriter spanstore.Writer, additional []ProcessSpan, opt
 		sanitizer:          sanitizer.NewChainedSanitizer(sanitizers...),
 		reportBusy:         options.reportBusy,
 		numWorkers:         options.numWorkers,
+		queueDrainTimeout:  options.queueDrainTimeout,
 		spanWriter:         spanWriter,
 		collectorTags:      options.collectorTags,
 		stopCh:             make(chan struct{}),
 		dynQueueSizeMemory: options.dynQueueSizeMemory,
 		dynQueueSizeWarmup: options.dynQueueSizeWarmup,
+		topServices:        options.topServices,
+		traceSizes:         options.traceSizes,
+		stats:              newProcessorStats(newLatencyWindow(writeLatencyWindows...)),
+	}
+
+	if options.backpressure.Threshold > 0 {
+		if w, ok := spanWriter.(spanstore.WriterWithBackpressure); ok {
+			sp.backpressureWriter = w
+			sp.backpressure = options.backpressure
+			options.logger.Info("Throttling incoming spans when span storage is behind",
+				zap.Int64("threshold", options.backpressure.Threshold),
+				zap.String("mode", options.backpressure.Mode))
+		} else {
+			options.logger.Warn("Span storage does not report pending writes, backpressure is disabled")
+		}
+	}
+
+	if options.admission.TargetLatency > 0 {
+		sp.admission = newAdmissionController(options.admission, sp.stats.writes)
+		options.logger.Info("Rejecting incoming spans when span storage writes are slow",
+			zap.Duration("target-latency", options.admission.TargetLatency),
+			zap.Duration("window", options.admission.Window),
+			zap.Float64("max-rejection-probability", options.admission.MaxRejectionProbability))
 	}
 
-	processSpanFuncs := []ProcessSpan{options.preSave, sp.saveSpan}
+	if options.tagFilter.Enabled() {
+		sp.tagFilter = tagfilter.NewFilter(options.tagFilter,
+			options.hostMetrics.Namespace(metrics.NSOptions{Name: "tag-filter"}))
+		options.logger.Info("Filtering the tags of incoming spans",
+			zap.Int("deny-patterns", len(options.tagFilter.Deny)),
+			zap.Int("allow-patterns", len(options.tagFilter.Allow)),
+			zap.Bool("dry-run", options.tagFilter.DryRun))
+	}
+
+	saveSpan := sp.saveSpan
+	if len(options.tailSampling.Policies) > 0 {
+		// the spans are saved once their trace is kept
+		sp.tailSampler = tailsampling.NewSampler(options.tailSampling, sp.saveSpan,
+			options.hostMetrics.Namespace(metrics.NSOptions{Name: "tail-sampling"}), options.logger)
+		saveSpan = sp.tailSampler.Add
+	}
+
+	processSpanFuncs := []ProcessSpan{options.preSave, saveSpan}
 	if options.dynQueueSizeMemory > 0 {
 		options.logger.Info("Dynamically adjusting the queue size at runtime.",
 			zap.Uint("memory-mib", options.dynQueueSizeMemory/1024/1024),
@@ -141,8 +225,19 @@ func newSpanProcessor(spanWriter spanstore.Writer, additional []ProcessSpan, opt
 
 func (sp *spanProcessor) Close() error {
 	close(sp.stopCh)
-	sp.queue.Stop()
-
+	if sp.queueDrainTimeout <= 0 {
+		sp.queue.Stop()
+	} else {
+		sp.logger.Info("Draining the span queue", zap.Int("queue-length", sp.queue.Size()), zap.Duration("timeout", sp.queueDrainTimeout))
+		if abandoned := sp.queue.StopWithDrain(sp.queueDrainTimeout); abandoned > 0 {
+			sp.logger.Warn("Span queue drain timed out, abandoning remaining spans", zap.Int("abandoned", abandoned))
+			sp.metrics.SpansAbandoned.Inc(int64(abandoned))
+		}
+	}
+	if sp.tailSampler != nil {
+		// the traces still buffered are decided with the spans received so far
+		return sp.tailSampler.Close()
+	}
 	return nil
 }
 
@@ -166,7 +261,10 @@ func (sp *spanProcessor) saveSpan(span *model.Span, tenant string) {
 			zap.Stringer("trace-id", span.TraceID), zap.Stringer("span-id", span.SpanID))
 		sp.metrics.SavedOkBySvc.ReportServiceNameForSpan(span)
 	}
-	sp.metrics.SaveLatency.Record(time.Since(startTime))
+	now := time.Now()
+	latency := now.Sub(startTime)
+	sp.metrics.SaveLatency.Record(latency)
+	sp.stats.writes.record(now, latency)
 }
 
 func (sp *spanProcessor) countSpan(span *model.Span, _ string /* tenant */) {
@@ -175,6 +273,14 @@ func (sp *spanProcessor) countSpan(span *model.Span, _ string /* tenant */) {
 }
 
 func (sp *spanProcessor) ProcessSpans(mSpans []*model.Span, options processor.SpansOptions) ([]bool, error) {
+	if err := sp.checkBackpressure(); err != nil {
+		sp.metrics.SpansThrottled.Inc(int64(len(mSpans)))
+		return nil, err
+	}
+	if sp.admission != nil && !sp.admission.admit() {
+		sp.metrics.SpansRejectedByAdmission.Inc(int64(len(mSpans)))
+		return nil, processor.ErrBusy
+	}
 	sp.preProcessSpans(mSpans, options.Tenant)
 	sp.metrics.BatchSize.Update(int64(len(mSpans)))
 	retMe := make([]bool, len(mSpans))
@@ -183,8 +289,11 @@ func (sp *spanProcessor) ProcessSpans(mSpans []*model.Span, options processor.Sp
 	// and Process can be shared between different spans in the batch, but we no longer know that,
 	// the relation is lost upstream and it's impossible in Go to dedupe pointers. But at least here
 	// we have a single thread updating all spans that may share the same Process, before concurrency
-	// kicks in.
+	// kicks in. For the same reason, the tags are filtered here, before the collector tags are added.
 	for _, span := range mSpans {
+		if sp.tagFilter != nil {
+			sp.tagFilter.Apply(span)
+		}
 		sp.addCollectorTags(span)
 	}
 
@@ -198,7 +307,72 @@ func (sp *spanProcessor) ProcessSpans(mSpans []*model.Span, options processor.Sp
 	return retMe, nil
 }
 
+// checkBackpressure returns processor.ErrBusy if the span writer has more pending writes than
+// the threshold. In block mode it first waits up to the block timeout for the writer to catch up.
+func (sp *spanProcessor) checkBackpressure() error {
+	if sp.backpressureWriter == nil || !sp.writerBehind() {
+		return nil
+	}
+	if sp.backpressure.Mode != flags.BackpressureModeBlock {
+		return processor.ErrBusy
+	}
+	sp.metrics.BatchesBlocked.Inc(1)
+	timeout := time.NewTimer(sp.backpressure.BlockTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(backpressurePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !sp.writerBehind() {
+				return nil
+			}
+		case <-timeout.C:
+			return processor.ErrBusy
+		case <-sp.stopCh:
+			return processor.ErrBusy
+		}
+	}
+}
+
+// Flush waits for the spans enqueued before the call to be processed, then flushes the span writer,
+// see spanstore.Flush, so that the spans accepted by the processor are visible to the reads. The spans
+// buffered for tail sampling are not flushed, they are written once their trace is decided.
+// The spans enqueued during the flush are not waited for, so that it completes under continuous intake.
+func (sp *spanProcessor) Flush(ctx context.Context) error {
+	sp.flushMu.Lock()
+	defer sp.flushMu.Unlock()
+	// the spans enqueued from now on are counted in the next generation
+	previous := (sp.generation.Add(1) - 1) % 2
+	ticker := time.NewTicker(flushPollInterval)
+	defer ticker.Stop()
+	for sp.queuedSpans[previous].Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sp.stopCh:
+			return errors.New("the span processor is closed")
+		}
+	}
+	return spanstore.Flush(ctx, sp.spanWriter)
+}
+
+func (sp *spanProcessor) writerBehind() bool {
+	return sp.backpressureWriter.PendingWrites() > sp.backpressure.Threshold
+}
+
+// updateAdmission recomputes the probability of rejecting span batches from the latency of the span writes.
+func (sp *spanProcessor) updateAdmission() {
+	overload, probability := sp.admission.update(time.Now())
+	sp.metrics.AdmissionOverloadPercent.Update(int64(math.Round(overload * 100)))
+	sp.metrics.AdmissionRejectionPercent.Update(int64(math.Round(probability * 100)))
+}
+
 func (sp *spanProcessor) processItemFromQueue(item *queueItem) {
+	defer sp.queuedSpans[item.generation].Add(-1)
+	sp.stats.busyWorkers.Add(1)
+	defer sp.stats.busyWorkers.Add(-1)
 	sp.processSpan(sp.sanitizer(item.span), item.tenant)
 	sp.metrics.InQueueLatency.Record(time.Since(item.queuedTime))
 }
@@ -229,6 +403,13 @@ func (sp *spanProcessor) addCollectorTags(span *model.Span) {
 func (sp *spanProcessor) enqueueSpan(span *model.Span, originalFormat processor.SpanFormat, transport processor.InboundTransport, tenant string) bool {
 	spanCounts := sp.metrics.GetCountsForFormat(originalFormat, transport)
 	spanCounts.ReceivedBySvc.ReportServiceNameForSpan(span)
+	sp.stats.received(time.Now(), originalFormat, transport)
+	if sp.topServices != nil {
+		sp.topServices.received(span)
+	}
+	if sp.traceSizes != nil {
+		sp.traceSizes.received(span)
+	}
 
 	if !sp.filterSpan(span) {
 		spanCounts.RejectedBySvc.ReportServiceNameForSpan(span)
@@ -242,8 +423,15 @@ func (sp *spanProcessor) enqueueSpan(span *model.Span, originalFormat processor.
 		queuedTime: time.Now(),
 		span:       span,
 		tenant:     tenant,
+		generation: sp.generation.Load() % 2,
 	}
-	return sp.queue.Produce(item)
+	sp.queuedSpans[item.generation].Add(1)
+	if !sp.queue.Produce(item) {
+		sp.queuedSpans[item.generation].Add(-1)
+		sp.stats.dropped(time.Now(), originalFormat, transport)
+		return false
+	}
+	return true
 }
 
 func (sp *spanProcessor) background(reportPeriod time.Duration, callback func()) {
@@ -304,8 +492,22 @@ func (sp *spanProcessor) updateQueueSize() {
 	}
 }
 
+// status returns the state of the queue, the workers, the receivers and the span writes at now,
+// for the status of the collector.
+func (sp *spanProcessor) status(now time.Time) processorStatus {
+	return processorStatus{
+		Queue:         queueStatus{Length: sp.queue.Size(), Capacity: sp.queue.Capacity()},
+		Workers:       workersStatus{Total: sp.numWorkers, Busy: sp.stats.busyWorkers.Load()},
+		Receivers:     sp.stats.receiverStatuses(now),
+		StorageWriter: sp.stats.storageWriterStatus(now),
+	}
+}
+
 func (sp *spanProcessor) updateGauges() {
 	sp.metrics.SpansBytes.Update(int64(sp.bytesProcessed.Load()))
 	sp.metrics.QueueLength.Update(int64(sp.queue.Size()))
 	sp.metrics.QueueCapacity.Update(int64(sp.queue.Capacity()))
+	if sp.backpressureWriter != nil {
+		sp.metrics.PendingWrites.Update(sp.backpressureWriter.PendingWrites())
+	}
 }
@@ -16,9 +16,12 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -27,16 +30,22 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/handler"
 	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/tailsampling"
 	zipkinsanitizer "github.com/jaegertracing/jaeger/cmd/collector/app/sanitizer/zipkin"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/tagfilter"
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	zc "github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
 )
@@ -234,13 +243,13 @@ func makeJaegerSpan(service string, rootSpan bool, debugEnabled bool) (*jaeger.S
 		parentID = int64(1)
 	}
 	return &jaeger.Span{
-			OperationName: "jaeger",
-			Flags:         flags,
-			ParentSpanId:  parentID,
-			TraceIdLow:    42,
-		}, &jaeger.Process{
-			ServiceName: service,
-		}
+		OperationName: "jaeger",
+		Flags:         flags,
+		ParentSpanId:  parentID,
+		TraceIdLow:    42,
+	}, &jaeger.Process{
+		ServiceName: service,
+	}
 }
 
 func TestSpanProcessor(t *testing.T) {
@@ -347,6 +356,252 @@ func TestSpanProcessorBusy(t *testing.T) {
 	assert.Nil(t, res)
 }
 
+type slowWriter struct {
+	delay   time.Duration
+	written atomic.Int32
+}
+
+func (w *slowWriter) WriteSpan(context.Context, *model.Span) error {
+	time.Sleep(w.delay)
+	w.written.Add(1)
+	return nil
+}
+
+func TestSpanProcessorDrainOnClose(t *testing.T) {
+	const numSpans = 20
+	tests := []struct {
+		name         string
+		drainTimeout time.Duration
+		allWritten   bool
+	}{
+		{name: "generous timeout", drainTimeout: 5 * time.Second, allWritten: true},
+		{name: "short timeout", drainTimeout: 10 * time.Millisecond, allWritten: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := &slowWriter{delay: 5 * time.Millisecond}
+			mb := metricstest.NewFactory(time.Hour)
+			defer mb.Backend.Stop()
+			p := NewSpanProcessor(w,
+				nil,
+				Options.NumWorkers(1),
+				Options.QueueSize(numSpans),
+				Options.QueueDrainTimeout(test.drainTimeout),
+				Options.ServiceMetrics(mb),
+				Options.HostMetrics(mb),
+			).(*spanProcessor)
+
+			spans := make([]*model.Span, numSpans)
+			for i := range spans {
+				spans[i] = &model.Span{Process: &model.Process{ServiceName: "x"}}
+			}
+			_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+			require.NoError(t, err)
+			require.NoError(t, p.Close())
+
+			written := int(w.written.Load())
+			counters, _ := mb.Snapshot()
+			abandoned := int(counters["spans.abandoned"])
+			if test.allWritten {
+				assert.Equal(t, numSpans, written)
+				assert.Zero(t, abandoned)
+			} else {
+				assert.Less(t, written, numSpans)
+				assert.Equal(t, numSpans-written, abandoned)
+			}
+		})
+	}
+}
+
+func TestSpanProcessorTailSampling(t *testing.T) {
+	w := &fakeSpanWriter{}
+	p := NewSpanProcessor(w,
+		nil,
+		Options.QueueSize(10),
+		Options.TailSampling(tailsampling.Options{
+			Policies:         []tailsampling.NamedPolicy{{Name: "errors", Policy: tailsampling.ErrorPolicy{}}},
+			DecisionWait:     time.Hour,
+			RootSpanWait:     time.Hour,
+			MaxSpans:         100,
+			EvictionDecision: tailsampling.EvictionDecisionDrop,
+		}),
+	).(*spanProcessor)
+	require.NotNil(t, p.tailSampler)
+
+	_, err := p.ProcessSpans([]*model.Span{
+		{TraceID: model.NewTraceID(0, 1), Process: &model.Process{ServiceName: "x"}, Tags: []model.KeyValue{model.Bool("error", true)}},
+		{TraceID: model.NewTraceID(0, 2), Process: &model.Process{ServiceName: "x"}},
+	}, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	// the spans are buffered until their trace is decided, on close at the latest
+	require.NoError(t, p.Close())
+
+	require.Len(t, w.spans, 1)
+	assert.Equal(t, model.NewTraceID(0, 1), w.spans[0].TraceID)
+}
+
+func newTagFilterSpanProcessor(t *testing.T, w *fakeSpanWriter) *spanProcessor {
+	deny, err := tagfilter.ParsePatterns([]string{`thread\..*`, "error"})
+	require.NoError(t, err)
+	p := NewSpanProcessor(w, nil,
+		Options.QueueSize(10),
+		Options.TagFilter(tagfilter.Options{Deny: deny}),
+	).(*spanProcessor)
+	require.NotNil(t, p.tagFilter)
+	return p
+}
+
+func tagKeys(tags []model.KeyValue) []string {
+	keys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		keys = append(keys, tag.Key)
+	}
+	return keys
+}
+
+func TestSpanProcessorTagFilterJaegerProto(t *testing.T) {
+	w := &fakeSpanWriter{}
+	p := newTagFilterSpanProcessor(t, w)
+	grpcHandler := handler.NewGRPCHandler(zap.NewNop(), p, &tenancy.Manager{})
+
+	_, err := grpcHandler.PostSpans(context.Background(), &api_v2.PostSpansRequest{Batch: model.Batch{
+		Process: model.NewProcess("frontend", []model.KeyValue{model.String("thread.pool", "io")}),
+		Spans: []*model.Span{{
+			OperationName: "GET /",
+			Tags:          []model.KeyValue{model.Bool("error", true), model.Int64("thread.id", 42), model.String("http.method", "GET")},
+			Logs:          []model.Log{{Fields: []model.KeyValue{model.String("event", "retry"), model.String("thread.name", "main")}}},
+		}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, p.Close())
+
+	require.Len(t, w.spans, 1)
+	// error is always kept
+	assert.Equal(t, []string{"error", "http.method"}, tagKeys(w.spans[0].Tags)[:2])
+	assert.NotContains(t, tagKeys(w.spans[0].Tags), "thread.id")
+	assert.Equal(t, []string{"event"}, tagKeys(w.spans[0].Logs[0].Fields))
+	assert.Empty(t, w.spans[0].Process.Tags)
+}
+
+func TestSpanProcessorTagFilterOTLP(t *testing.T) {
+	w := &fakeSpanWriter{}
+	p := newTagFilterSpanProcessor(t, w)
+
+	// reserve a port for the OTLP/HTTP receiver
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+	opts := &flags.CollectorOptions{}
+	opts.OTLP.HTTP.HostPort = addr
+	opts.OTLP.GRPC.HostPort = "localhost:0"
+	rec, err := handler.StartOTLPReceiver(opts, zap.NewNop(), p, &tenancy.Manager{}, nooptrace.NewTracerProvider())
+	require.NoError(t, err)
+
+	traces := ptrace.NewTraces()
+	rSpans := traces.ResourceSpans().AppendEmpty()
+	rSpans.Resource().Attributes().PutStr("service.name", "frontend")
+	rSpans.Resource().Attributes().PutStr("thread.pool", "io")
+	span := rSpans.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("GET /")
+	span.SetTraceID([16]byte{1})
+	span.SetSpanID([8]byte{1})
+	span.SetKind(ptrace.SpanKindServer)
+	span.Attributes().PutInt("thread.id", 42)
+	span.Attributes().PutStr("http.method", "GET")
+	body, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(traces)
+	require.NoError(t, err)
+	resp, err := http.Post("http://"+addr+"/v1/traces", "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, rec.Shutdown(context.Background()))
+	require.NoError(t, p.Close())
+	require.Len(t, w.spans, 1)
+	// span.kind is always kept
+	assert.Contains(t, tagKeys(w.spans[0].Tags), "span.kind")
+	assert.Contains(t, tagKeys(w.spans[0].Tags), "http.method")
+	assert.NotContains(t, tagKeys(w.spans[0].Tags), "thread.id")
+	assert.NotContains(t, tagKeys(w.spans[0].Process.Tags), "thread.pool")
+}
+
+type backpressureWriter struct {
+	fakeSpanWriter
+	pending atomic.Int64
+}
+
+func (w *backpressureWriter) PendingWrites() int64 {
+	return w.pending.Load()
+}
+
+func TestSpanProcessorBackpressure(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		pending       int64
+		catchUp       bool
+		expectedErr   error
+		throttled     int64
+		blocked       int64
+		expectedSpans int
+	}{
+		{name: "below threshold", mode: flags.BackpressureModeReject, pending: 10, expectedSpans: 2},
+		{name: "reject", mode: flags.BackpressureModeReject, pending: 11, expectedErr: processor.ErrBusy, throttled: 2},
+		{name: "block until caught up", mode: flags.BackpressureModeBlock, pending: 11, catchUp: true, blocked: 1, expectedSpans: 2},
+		{name: "block timeout", mode: flags.BackpressureModeBlock, pending: 11, expectedErr: processor.ErrBusy, throttled: 2, blocked: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := &backpressureWriter{}
+			w.pending.Store(test.pending)
+			mb := metricstest.NewFactory(time.Hour)
+			defer mb.Backend.Stop()
+			p := NewSpanProcessor(w,
+				nil,
+				Options.NumWorkers(1),
+				Options.QueueSize(10),
+				Options.Backpressure(flags.BackpressureOptions{
+					Threshold:    10,
+					Mode:         test.mode,
+					BlockTimeout: 50 * time.Millisecond,
+				}),
+				Options.ServiceMetrics(mb),
+				Options.HostMetrics(mb),
+			).(*spanProcessor)
+			defer func() { require.NoError(t, p.Close()) }()
+
+			if test.catchUp {
+				time.AfterFunc(10*time.Millisecond, func() { w.pending.Store(0) })
+			}
+			spans := []*model.Span{
+				{Process: &model.Process{ServiceName: "x"}},
+				{Process: &model.Process{ServiceName: "x"}},
+			}
+			_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+			require.ErrorIs(t, err, test.expectedErr)
+
+			assert.Eventually(t, func() bool {
+				w.spansLock.Lock()
+				defer w.spansLock.Unlock()
+				return len(w.spans) == test.expectedSpans
+			}, time.Second, time.Millisecond)
+			mb.AssertCounterMetrics(t,
+				metricstest.ExpectedMetric{Name: "spans.throttled", Value: int(test.throttled)},
+				metricstest.ExpectedMetric{Name: "batches.blocked", Value: int(test.blocked)},
+			)
+			p.updateGauges()
+			mb.AssertGaugeMetrics(t, metricstest.ExpectedMetric{Name: "pending-writes", Value: int(w.pending.Load())})
+		})
+	}
+}
+
+func TestSpanProcessorBackpressureNotSupported(t *testing.T) {
+	p := newSpanProcessor(&fakeSpanWriter{}, nil, Options.Backpressure(flags.BackpressureOptions{Threshold: 10}))
+	assert.Nil(t, p.backpressureWriter)
+	assert.NoError(t, p.checkBackpressure())
+}
+
 func TestSpanProcessorWithNilProcess(t *testing.T) {
 	mb := metricstest.NewFactory(time.Hour)
 	defer mb.Backend.Stop()
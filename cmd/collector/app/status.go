@@ -0,0 +1,286 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/pkg/version"
+)
+
+const (
+	// StatusPath is the path of the status of the collector, to register the StatusHandler of the collector on the admin server.
+	StatusPath = "/status"
+
+	// statusWindow is the window of the span counts and of the latencies of the span writes reported
+	statusWindow = time.Minute
+	// statusCountSlices is the number of slices of the window of the span counts, one per second
+	statusCountSlices = 60
+
+	// defaultStatusWatchInterval is how often the status is streamed in watch mode, unless requested otherwise
+	defaultStatusWatchInterval = 5 * time.Second
+	// minStatusWatchInterval is the shortest interval of the watch mode
+	minStatusWatchInterval = 100 * time.Millisecond
+
+	// statusSamplingTimeout bounds the time taken to check that the sampling strategies are served
+	statusSamplingTimeout = time.Second
+)
+
+// countWindow counts over the sliding statusWindow, made of slices of a second, the oldest one being dropped as time passes.
+// It is updated without locks, on the hot path of the spans received.
+type countWindow struct {
+	slices [statusCountSlices]slicedCounter
+}
+
+// add counts n at now.
+func (cw *countWindow) add(now time.Time, n uint64) {
+	slice := now.UnixNano() / int64(statusWindow/statusCountSlices)
+	cw.slices[slice%statusCountSlices].add(slice, n)
+}
+
+// total returns the count over the window ending at now.
+func (cw *countWindow) total(now time.Time) uint64 {
+	last := now.UnixNano() / int64(statusWindow/statusCountSlices)
+	var total uint64
+	for i := range cw.slices {
+		total += cw.slices[i].count(last, statusCountSlices)
+	}
+	return total
+}
+
+// receiverKey identifies a receiver of the collector by the format and transport of the spans.
+type receiverKey struct {
+	format    processor.SpanFormat
+	transport processor.InboundTransport
+}
+
+type receiverStats struct {
+	received countWindow
+	dropped  countWindow
+}
+
+// processorStats is the registry of the state of the span processor reported by the status of the collector,
+// updated without locks as the spans are processed rather than read back from the metrics.
+type processorStats struct {
+	busyWorkers atomic.Int64
+	// writes are the latencies of the span writes, shared with the admission controller
+	writes *latencyWindow
+	// receivers holds the *receiverStats of each receiverKey
+	receivers sync.Map
+}
+
+func newProcessorStats(writes *latencyWindow) *processorStats {
+	return &processorStats{writes: writes}
+}
+
+func (ps *processorStats) receiver(format processor.SpanFormat, transport processor.InboundTransport) *receiverStats {
+	key := receiverKey{format: format, transport: transport}
+	if stats, ok := ps.receivers.Load(key); ok {
+		return stats.(*receiverStats)
+	}
+	stats, _ := ps.receivers.LoadOrStore(key, &receiverStats{})
+	return stats.(*receiverStats)
+}
+
+// received counts a span received by a receiver at now.
+func (ps *processorStats) received(now time.Time, format processor.SpanFormat, transport processor.InboundTransport) {
+	ps.receiver(format, transport).received.add(now, 1)
+}
+
+// dropped counts a span of a receiver dropped at now because the queue was full.
+func (ps *processorStats) dropped(now time.Time, format processor.SpanFormat, transport processor.InboundTransport) {
+	ps.receiver(format, transport).dropped.add(now, 1)
+}
+
+// receiverStatuses returns the span counts of the receivers over the window ending at now,
+// sorted by format and transport.
+func (ps *processorStats) receiverStatuses(now time.Time) []receiverStatus {
+	var statuses []receiverStatus
+	ps.receivers.Range(func(k, v any) bool {
+		key, stats := k.(receiverKey), v.(*receiverStats)
+		statuses = append(statuses, receiverStatus{
+			Format:        string(key.format),
+			Transport:     string(key.transport),
+			SpansReceived: stats.received.total(now),
+			SpansDropped:  stats.dropped.total(now),
+		})
+		return true
+	})
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Format != statuses[j].Format {
+			return statuses[i].Format < statuses[j].Format
+		}
+		return statuses[i].Transport < statuses[j].Transport
+	})
+	return statuses
+}
+
+// storageWriterStatus returns the number and latency percentiles of the span writes over the window ending at now.
+func (ps *processorStats) storageWriterStatus(now time.Time) storageWriterStatus {
+	quantiles, writes := ps.writes.quantiles(now, statusWindow, 0.5, 0.9, 0.99)
+	status := storageWriterStatus{Writes: writes}
+	if writes > 0 {
+		millis := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+		status.LatencyMillis = &latencyPercentiles{P50: millis(quantiles[0]), P90: millis(quantiles[1]), P99: millis(quantiles[2])}
+	}
+	return status
+}
+
+// statusReporter is implemented by the span processor, see spanProcessor.status.
+type statusReporter interface {
+	status(now time.Time) processorStatus
+}
+
+// processorStatus is the state of the span processor at a point in time.
+type processorStatus struct {
+	Queue         queueStatus         `json:"queue"`
+	Workers       workersStatus       `json:"workers"`
+	Receivers     []receiverStatus    `json:"receivers"`
+	StorageWriter storageWriterStatus `json:"storage_writer"`
+}
+
+type queueStatus struct {
+	Length   int `json:"length"`
+	Capacity int `json:"capacity"`
+}
+
+type workersStatus struct {
+	Total int   `json:"total"`
+	Busy  int64 `json:"busy"`
+}
+
+type receiverStatus struct {
+	Format        string `json:"format"`
+	Transport     string `json:"transport"`
+	SpansReceived uint64 `json:"spans_received_last_minute"`
+	SpansDropped  uint64 `json:"spans_dropped_last_minute"`
+}
+
+type storageWriterStatus struct {
+	Writes uint64 `json:"writes_last_minute"`
+	// LatencyMillis are estimates within 25% above the actual percentiles, absent without writes
+	LatencyMillis *latencyPercentiles `json:"latency_ms,omitempty"`
+}
+
+type latencyPercentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
+
+type samplingStatus struct {
+	// Enabled is whether the collector serves sampling strategies
+	Enabled bool `json:"enabled"`
+	// Healthy is whether the strategy of the collector's own service could be retrieved
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+type statusResponse struct {
+	Time time.Time `json:"time"`
+	processorStatus
+	Sampling samplingStatus `json:"sampling"`
+	Build    version.Info   `json:"build"`
+}
+
+// statusHandler serves the state of the queue, the workers, the receivers and the storage writer of the collector,
+// along with the health of the sampling strategies and the build info, as a single JSON document, or streamed as
+// NDJSON with ?watch=true, every interval (e.g. ?interval=2s), until the request is cancelled.
+type statusHandler struct {
+	processor   statusReporter
+	sampling    samplingstrategy.Provider
+	serviceName string
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+func (h *statusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	watch, interval, err := parseStatusWatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !watch {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.status(r.Context()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := encoder.Encode(h.status(r.Context())); err != nil {
+			return
+		}
+		if err := controller.Flush(); err != nil {
+			h.logger.Debug("Failed to flush the collector status", zap.Error(err))
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func parseStatusWatch(r *http.Request) (watch bool, interval time.Duration, err error) {
+	interval = defaultStatusWatchInterval
+	if value := r.URL.Query().Get("watch"); value != "" {
+		if watch, err = strconv.ParseBool(value); err != nil {
+			return false, 0, fmt.Errorf("invalid watch parameter %q: %w", value, err)
+		}
+	}
+	if value := r.URL.Query().Get("interval"); value != "" {
+		if interval, err = time.ParseDuration(value); err != nil {
+			return false, 0, fmt.Errorf("invalid interval parameter %q: %w", value, err)
+		}
+		if interval < minStatusWatchInterval {
+			return false, 0, fmt.Errorf("invalid interval parameter %q: must be at least %v", value, minStatusWatchInterval)
+		}
+	}
+	return watch, interval, nil
+}
+
+func (h *statusHandler) status(ctx context.Context) statusResponse {
+	now := h.now()
+	return statusResponse{
+		Time:            now.UTC(),
+		processorStatus: h.processor.status(now),
+		Sampling:        h.samplingStatus(ctx),
+		Build:           version.Get(),
+	}
+}
+
+func (h *statusHandler) samplingStatus(ctx context.Context) samplingStatus {
+	if h.sampling == nil {
+		return samplingStatus{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, statusSamplingTimeout)
+	defer cancel()
+	if _, err := h.sampling.GetSamplingStrategy(ctx, h.serviceName); err != nil {
+		return samplingStatus{Enabled: true, Error: err.Error()}
+	}
+	return samplingStatus{Enabled: true, Healthy: true}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+type failingSamplingProvider struct {
+	mockSamplingProvider
+}
+
+func (*failingSamplingProvider) GetSamplingStrategy(context.Context, string /* serviceName */) (*api_v2.SamplingStrategyResponse, error) {
+	return nil, errors.New("sampling store unavailable")
+}
+
+func newTestStatusHandler(t *testing.T, p *spanProcessor) *statusHandler {
+	t.Cleanup(func() {
+		require.NoError(t, p.Close())
+	})
+	return &statusHandler{
+		processor:   p,
+		sampling:    &mockSamplingProvider{},
+		serviceName: "jaeger-collector",
+		logger:      zap.NewNop(),
+		now:         time.Now,
+	}
+}
+
+// waitForProcessed waits for the queued spans to be written, the test writers not supporting flushes.
+func waitForProcessed(t *testing.T, p *spanProcessor) {
+	require.ErrorIs(t, p.Flush(context.Background()), errors.ErrUnsupported)
+}
+
+func getStatus(t *testing.T, h http.Handler) (statusResponse, map[string]any) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, StatusPath, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var status statusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	return status, document
+}
+
+func TestStatusSchema(t *testing.T) {
+	p := NewSpanProcessor(&fakeSpanWriter{}, nil, Options.NumWorkers(3), Options.QueueSize(50)).(*spanProcessor)
+	h := newTestStatusHandler(t, p)
+	_, err := p.ProcessSpans([]*model.Span{{Process: &model.Process{ServiceName: "x"}}},
+		processor.SpansOptions{SpanFormat: processor.ProtoSpanFormat, InboundTransport: processor.GRPCTransport})
+	require.NoError(t, err)
+	waitForProcessed(t, p)
+
+	_, document := getStatus(t, h)
+	keys := func(m any) []string {
+		var list []string
+		for key := range m.(map[string]any) {
+			list = append(list, key)
+		}
+		return list
+	}
+	assert.ElementsMatch(t, []string{"time", "queue", "workers", "receivers", "storage_writer", "sampling", "build"}, keys(document))
+	assert.ElementsMatch(t, []string{"length", "capacity"}, keys(document["queue"]))
+	assert.ElementsMatch(t, []string{"total", "busy"}, keys(document["workers"]))
+	receivers := document["receivers"].([]any)
+	require.Len(t, receivers, 1)
+	assert.ElementsMatch(t, []string{"format", "transport", "spans_received_last_minute", "spans_dropped_last_minute"}, keys(receivers[0]))
+	assert.ElementsMatch(t, []string{"writes_last_minute", "latency_ms"}, keys(document["storage_writer"]))
+	assert.ElementsMatch(t, []string{"p50", "p90", "p99"}, keys(document["storage_writer"].(map[string]any)["latency_ms"]))
+	assert.ElementsMatch(t, []string{"enabled", "healthy"}, keys(document["sampling"]))
+	assert.ElementsMatch(t, []string{"gitCommit", "gitVersion", "buildDate"}, keys(document["build"]))
+}
+
+func TestStatusValuesMoveWithSpans(t *testing.T) {
+	w := &slowWriter{delay: time.Millisecond}
+	p := NewSpanProcessor(w, nil, Options.NumWorkers(2), Options.QueueSize(100)).(*spanProcessor)
+	h := newTestStatusHandler(t, p)
+
+	status, _ := getStatus(t, h)
+	assert.Equal(t, queueStatus{Length: 0, Capacity: 100}, status.Queue)
+	assert.Equal(t, workersStatus{Total: 2}, status.Workers)
+	assert.Empty(t, status.Receivers)
+	assert.Equal(t, storageWriterStatus{}, status.StorageWriter)
+	assert.Equal(t, samplingStatus{Enabled: true, Healthy: true}, status.Sampling)
+
+	spans := make([]*model.Span, 10)
+	for i := range spans {
+		spans[i] = &model.Span{Process: &model.Process{ServiceName: "x"}}
+	}
+	_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, InboundTransport: processor.HTTPTransport})
+	require.NoError(t, err)
+	_, err = p.ProcessSpans(spans[:3], processor.SpansOptions{SpanFormat: processor.ZipkinSpanFormat, InboundTransport: processor.HTTPTransport})
+	require.NoError(t, err)
+	waitForProcessed(t, p)
+
+	status, _ = getStatus(t, h)
+	assert.Equal(t, []receiverStatus{
+		{Format: "jaeger", Transport: "http", SpansReceived: 10},
+		{Format: "zipkin", Transport: "http", SpansReceived: 3},
+	}, status.Receivers)
+	assert.EqualValues(t, 13, status.StorageWriter.Writes)
+	require.NotNil(t, status.StorageWriter.LatencyMillis)
+	assert.GreaterOrEqual(t, status.StorageWriter.LatencyMillis.P50, 1.0)
+	assert.GreaterOrEqual(t, status.StorageWriter.LatencyMillis.P99, status.StorageWriter.LatencyMillis.P50)
+}
+
+func TestStatusBusyWorkersAndDroppedSpans(t *testing.T) {
+	w := &blockingWriter{}
+	w.Lock()
+	p := NewSpanProcessor(w, nil, Options.NumWorkers(1), Options.QueueSize(1)).(*spanProcessor)
+	h := newTestStatusHandler(t, p)
+	defer w.Unlock()
+
+	spans := []*model.Span{{Process: &model.Process{ServiceName: "x"}}}
+	options := processor.SpansOptions{SpanFormat: processor.ProtoSpanFormat, InboundTransport: processor.GRPCTransport}
+	_, err := p.ProcessSpans(spans, options)
+	require.NoError(t, err)
+	// the worker is blocked writing the first span
+	assert.Eventually(t, func() bool {
+		status, _ := getStatus(t, h)
+		return status.Workers.Busy == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// the second span fills the queue, and the third one is dropped
+	for i := 0; i < 2; i++ {
+		_, err = p.ProcessSpans(spans, options)
+		require.NoError(t, err)
+	}
+	status, _ := getStatus(t, h)
+	assert.Equal(t, queueStatus{Length: 1, Capacity: 1}, status.Queue)
+	assert.Equal(t, []receiverStatus{{Format: "proto", Transport: "grpc", SpansReceived: 3, SpansDropped: 1}}, status.Receivers)
+}
+
+func TestStatusSampling(t *testing.T) {
+	p := NewSpanProcessor(&fakeSpanWriter{}, nil).(*spanProcessor)
+	h := newTestStatusHandler(t, p)
+
+	h.sampling = &failingSamplingProvider{}
+	status, _ := getStatus(t, h)
+	assert.Equal(t, samplingStatus{Enabled: true, Error: "sampling store unavailable"}, status.Sampling)
+
+	h.sampling = nil
+	status, _ = getStatus(t, h)
+	assert.Equal(t, samplingStatus{}, status.Sampling)
+}
+
+func TestStatusWatch(t *testing.T) {
+	p := NewSpanProcessor(&fakeSpanWriter{}, nil).(*spanProcessor)
+	server := httptest.NewServer(newTestStatusHandler(t, p))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+StatusPath+"?watch=true&interval=100ms", nil)
+	require.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	var previous time.Time
+	for i := 0; i < 3; i++ {
+		require.True(t, scanner.Scan())
+		var status statusResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &status))
+		assert.True(t, status.Time.After(previous))
+		previous = status.Time
+		if i == 0 {
+			// the updates reflect the spans processed in the meantime
+			_, err := p.ProcessSpans([]*model.Span{{Process: &model.Process{ServiceName: "x"}}},
+				processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat, InboundTransport: processor.GRPCTransport})
+			require.NoError(t, err)
+			waitForProcessed(t, p)
+		} else {
+			require.Len(t, status.Receivers, 1)
+			assert.EqualValues(t, 1, status.Receivers[0].SpansReceived)
+		}
+	}
+}
+
+func TestStatusBadRequests(t *testing.T) {
+	h := newTestStatusHandler(t, NewSpanProcessor(&fakeSpanWriter{}, nil).(*spanProcessor))
+	tests := []struct {
+		method string
+		query  string
+		code   int
+		errMsg string
+	}{
+		{method: http.MethodPost, code: http.StatusMethodNotAllowed, errMsg: "only GET is supported"},
+		{method: http.MethodGet, query: "?watch=often", code: http.StatusBadRequest, errMsg: `invalid watch parameter "often"`},
+		{method: http.MethodGet, query: "?watch=true&interval=soon", code: http.StatusBadRequest, errMsg: `invalid interval parameter "soon"`},
+		{method: http.MethodGet, query: "?watch=true&interval=1ms", code: http.StatusBadRequest, errMsg: "must be at least 100ms"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(test.method, StatusPath+test.query, nil))
+		assert.Equal(t, test.code, w.Code)
+		assert.Contains(t, w.Body.String(), test.errMsg)
+	}
+}
+
+func TestCountWindow(t *testing.T) {
+	var cw countWindow
+	now := time.Unix(1700000000, 0)
+	cw.add(now, 2)
+	cw.add(now.Add(30*time.Second), 3)
+	assert.EqualValues(t, 5, cw.total(now.Add(30*time.Second)))
+	// the counts older than the window are dropped
+	assert.EqualValues(t, 3, cw.total(now.Add(statusWindow)))
+	// the slice of the first count is reused
+	cw.add(now.Add(statusWindow), 1)
+	assert.EqualValues(t, 4, cw.total(now.Add(statusWindow)))
+	assert.Zero(t, cw.total(now.Add(3*statusWindow)))
+}
+
+func TestCollectorStatusHandler(t *testing.T) {
+	baseMetrics := metricstest.NewFactory(time.Hour)
+	defer baseMetrics.Backend.Stop()
+	c := New(&CollectorParams{
+		ServiceName:      "collector",
+		Logger:           zap.NewNop(),
+		MetricsFactory:   baseMetrics,
+		SpanWriter:       &fakeSpanWriter{},
+		SamplingProvider: &mockSamplingProvider{},
+		HealthCheck:      healthcheck.New(),
+		TenancyMgr:       &tenancy.Manager{},
+	})
+	require.NoError(t, c.Start(optionsForEphemeralPorts()))
+	defer c.Close()
+	require.NotNil(t, c.StatusHandler())
+
+	status, _ := getStatus(t, c.StatusHandler())
+	assert.Positive(t, status.Workers.Total)
+	assert.True(t, status.Sampling.Healthy)
+}
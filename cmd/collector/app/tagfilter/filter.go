@@ -0,0 +1,150 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tagfilter removes the span tags, log fields and process tags nobody queries before
+// the spans are written, to reduce the storage volume.
+package tagfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const (
+	// maxPrefixes bounds the number of key prefixes the removed tags are counted by,
+	// the tags of the other prefixes being counted as otherPrefix
+	maxPrefixes = 100
+	otherPrefix = "other"
+)
+
+// alwaysKept are the tags never removed, as the UI and the query service rely on them.
+var alwaysKept = map[string]struct{}{
+	"span.kind":               {},
+	"error":                   {},
+	"peer.service":            {},
+	"otel.status_code":        {},
+	"otel.status_description": {},
+	"sampler.type":            {},
+	"sampler.param":           {},
+	"internal.span.format":    {},
+}
+
+// Options configures the tag filter, disabled without deny and allow patterns.
+type Options struct {
+	// Deny are the patterns of the keys of the tags removed
+	Deny []*regexp.Regexp
+	// Allow are the patterns of the keys of the tags kept, all the others being removed, if not empty
+	Allow []*regexp.Regexp
+	// DryRun only counts the tags that would be removed, without removing them
+	DryRun bool
+}
+
+// Enabled returns whether the options remove any tag.
+func (o Options) Enabled() bool {
+	return len(o.Deny) > 0 || len(o.Allow) > 0
+}
+
+// ParsePatterns compiles the patterns of tag keys, each of which must match the whole key.
+func ParsePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag key pattern %q: %w", pattern, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Filter removes the tags of the spans per its options. It is safe for concurrent use,
+// but the spans sharing a process must be filtered by a single goroutine.
+type Filter struct {
+	options        Options
+	metricsFactory metrics.Factory
+
+	mu sync.Mutex
+	// removed counts the removed tags by key prefix
+	removed map[string]metrics.Counter
+}
+
+// NewFilter creates a Filter reporting the removed tags to the metrics factory.
+func NewFilter(options Options, metricsFactory metrics.Factory) *Filter {
+	return &Filter{
+		options:        options,
+		metricsFactory: metricsFactory,
+		removed:        make(map[string]metrics.Counter),
+	}
+}
+
+// Apply removes the filtered span tags, log fields and process tags of the span, unless in dry run.
+func (f *Filter) Apply(span *model.Span) {
+	span.Tags = f.filter(span.Tags)
+	for i := range span.Logs {
+		span.Logs[i].Fields = f.filter(span.Logs[i].Fields)
+	}
+	if span.Process != nil {
+		span.Process.Tags = f.filter(span.Process.Tags)
+	}
+}
+
+// filter removes the filtered tags in place, preserving the order of the others.
+func (f *Filter) filter(tags []model.KeyValue) []model.KeyValue {
+	kept := tags[:0]
+	for _, tag := range tags {
+		if f.keep(tag.Key) {
+			kept = append(kept, tag)
+			continue
+		}
+		f.countRemoved(tag.Key)
+		if f.options.DryRun {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
+
+func (f *Filter) keep(key string) bool {
+	if _, ok := alwaysKept[key]; ok {
+		return true
+	}
+	if matchAny(f.options.Deny, key) {
+		return false
+	}
+	return len(f.options.Allow) == 0 || matchAny(f.options.Allow, key)
+}
+
+func (f *Filter) countRemoved(key string) {
+	prefix, _, _ := strings.Cut(key, ".")
+	f.mu.Lock()
+	counter, ok := f.removed[prefix]
+	if !ok {
+		if len(f.removed) >= maxPrefixes {
+			prefix = otherPrefix
+			counter, ok = f.removed[prefix]
+		}
+		if !ok {
+			counter = f.metricsFactory.Counter(metrics.Options{
+				Name: "tags.removed",
+				Tags: map[string]string{"prefix": prefix, "dry_run": fmt.Sprint(f.options.DryRun)},
+			})
+			f.removed[prefix] = counter
+		}
+	}
+	f.mu.Unlock()
+	counter.Inc(1)
+}
+
+func matchAny(patterns []*regexp.Regexp, key string) bool {
+	for _, re := range patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
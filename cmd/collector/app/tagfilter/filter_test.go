@@ -0,0 +1,128 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tagfilter
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func mustParsePatterns(t *testing.T, patterns ...string) []*regexp.Regexp {
+	res, err := ParsePatterns(patterns)
+	require.NoError(t, err)
+	return res
+}
+
+func makeSpan() *model.Span {
+	return &model.Span{
+		Tags: []model.KeyValue{
+			model.String("span.kind", "server"),
+			model.Bool("error", true),
+			model.String("http.method", "GET"),
+			model.Int64("thread.id", 42),
+			model.String("thread.name", "main"),
+		},
+		Logs: []model.Log{{Fields: []model.KeyValue{
+			model.String("event", "retry"),
+			model.String("internal.pointer", "0xc000123"),
+		}}},
+		Process: model.NewProcess("frontend", []model.KeyValue{
+			model.String("hostname", "host-1"),
+			model.String("internal.build", "abc"),
+		}),
+	}
+}
+
+func keys(tags []model.KeyValue) []string {
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		res = append(res, tag.Key)
+	}
+	return res
+}
+
+func TestFilterDeny(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(time.Hour)
+	defer metricsFactory.Stop()
+	f := NewFilter(Options{Deny: mustParsePatterns(t, `thread\..*`, `internal\..*`, "error")}, metricsFactory)
+
+	span := makeSpan()
+	f.Apply(span)
+	// error is always kept
+	assert.Equal(t, []string{"span.kind", "error", "http.method"}, keys(span.Tags))
+	assert.Equal(t, []string{"event"}, keys(span.Logs[0].Fields))
+	assert.Equal(t, []string{"hostname"}, keys(span.Process.Tags))
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "tags.removed", Tags: map[string]string{"prefix": "thread", "dry_run": "false"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "tags.removed", Tags: map[string]string{"prefix": "internal", "dry_run": "false"}, Value: 2},
+	)
+}
+
+func TestFilterAllow(t *testing.T) {
+	f := NewFilter(Options{
+		Allow: mustParsePatterns(t, `http\..*`, "event", "hostname", `thread\..*`),
+		Deny:  mustParsePatterns(t, `thread\.id`),
+	}, metricstest.NewFactory(0))
+
+	span := makeSpan()
+	f.Apply(span)
+	// the deny patterns take precedence over the allow patterns
+	assert.Equal(t, []string{"span.kind", "error", "http.method", "thread.name"}, keys(span.Tags))
+	assert.Equal(t, []string{"event"}, keys(span.Logs[0].Fields))
+	assert.Equal(t, []string{"hostname"}, keys(span.Process.Tags))
+}
+
+func TestFilterDryRun(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(time.Hour)
+	defer metricsFactory.Stop()
+	f := NewFilter(Options{Deny: mustParsePatterns(t, `thread\..*`), DryRun: true}, metricsFactory)
+
+	span := makeSpan()
+	f.Apply(span)
+	assert.Equal(t, makeSpan(), span)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "tags.removed", Tags: map[string]string{"prefix": "thread", "dry_run": "true"}, Value: 2},
+	)
+}
+
+func TestFilterMaxPrefixes(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(time.Hour)
+	defer metricsFactory.Stop()
+	f := NewFilter(Options{Deny: mustParsePatterns(t, `k\d+\..*`)}, metricsFactory)
+
+	span := &model.Span{}
+	for i := 0; i < maxPrefixes+10; i++ {
+		span.Tags = append(span.Tags, model.String(fmt.Sprintf("k%d.x", i), "v"))
+	}
+	f.Apply(span)
+	assert.Empty(t, span.Tags)
+	assert.Len(t, f.removed, maxPrefixes+1)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "tags.removed", Tags: map[string]string{"prefix": "other", "dry_run": "false"}, Value: 10},
+	)
+}
+
+func TestOptionsEnabled(t *testing.T) {
+	assert.False(t, Options{DryRun: true}.Enabled())
+	assert.True(t, Options{Deny: mustParsePatterns(t, "x")}.Enabled())
+	assert.True(t, Options{Allow: mustParsePatterns(t, "x")}.Enabled())
+}
+
+func TestParsePatterns(t *testing.T) {
+	patterns := mustParsePatterns(t, `thread\..*`)
+	assert.True(t, patterns[0].MatchString("thread.id"))
+	// the patterns match whole keys
+	assert.False(t, patterns[0].MatchString("java.thread.id"))
+
+	_, err := ParsePatterns([]string{"("})
+	require.ErrorContains(t, err, `invalid tag key pattern "("`)
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/internal/topk"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/normalizer"
+)
+
+const (
+	// TopServicesPath is the path of the top services, to register the TopServices of the collector on the admin server.
+	TopServicesPath = "/debug/top-services"
+
+	// otherTopServices is the label of the services not among the top services
+	otherTopServices = "other"
+
+	// topServicesBuckets is the number of buckets of the sliding window of the span and byte rates
+	topServicesBuckets = 6
+	// topServicesSketchFactor is how many more services than the top ones are monitored by the sketches,
+	// the more the more accurate the rates of the top services
+	topServicesSketchFactor = 4
+	// topServicesPromoteInterval is how often the top services get their own metrics
+	topServicesPromoteInterval = time.Second
+)
+
+// TopServices tracks the services sending the most spans or bytes over a sliding window with bounded memory,
+// counting the received and dropped spans and the received bytes of each one, the other services being counted
+// together as "other" so that the number of metrics is bounded. A service is counted as "other" until it is
+// among the top services, and keeps its own metrics once it was, until as many services as the size of the top have some.
+//
+// It is an http.Handler serving the current top services by span and byte rates.
+type TopServices struct {
+	size   int
+	spans  *topk.Window
+	bytes  *topk.Window
+	now    func() time.Time
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	factory metrics.Factory
+	mu      sync.RWMutex
+	// counters holds the metrics of the top services, by service name, at most size
+	counters map[string]*serviceCounters
+	other    *serviceCounters
+}
+
+type serviceCounters struct {
+	received metrics.Counter
+	dropped  metrics.Counter
+	bytes    metrics.Counter
+}
+
+// NewTopServices returns the TopServices tracking the options.Size top services, updating their metrics in the background until closed.
+func NewTopServices(options flags.TopServicesOptions, metricsFactory metrics.Factory) *TopServices {
+	capacity := options.Size * topServicesSketchFactor
+	t := &TopServices{
+		size:     options.Size,
+		spans:    topk.NewWindow(capacity, options.Window, topServicesBuckets),
+		bytes:    topk.NewWindow(capacity, options.Window, topServicesBuckets),
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+		factory:  metricsFactory.Namespace(metrics.NSOptions{Name: "top-services"}),
+		counters: make(map[string]*serviceCounters, options.Size),
+	}
+	t.other = t.newServiceCounters(otherTopServices)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(topServicesPromoteInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.promote()
+			case <-t.stopCh:
+				return
+			}
+		}
+	}()
+	return t
+}
+
+func (t *TopServices) newServiceCounters(service string) *serviceCounters {
+	tags := map[string]string{"svc": service}
+	return &serviceCounters{
+		received: t.factory.Counter(metrics.Options{Name: "spans.received", Tags: tags, Help: "The number of spans received from the top services"}),
+		dropped:  t.factory.Counter(metrics.Options{Name: "spans.dropped", Tags: tags, Help: "The number of spans of the top services dropped because the queue was full"}),
+		bytes:    t.factory.Counter(metrics.Options{Name: "bytes.received", Tags: tags, Help: "The number of bytes of the spans received from the top services"}),
+	}
+}
+
+// received counts the span received.
+func (t *TopServices) received(span *model.Span) {
+	service := topServiceName(span)
+	size := uint64(span.Size())
+	now := t.now()
+	t.spans.Add(now, service, 1)
+	t.bytes.Add(now, service, size)
+	counters := t.countersFor(service)
+	counters.received.Inc(1)
+	counters.bytes.Inc(int64(size))
+}
+
+// dropped counts the span dropped.
+func (t *TopServices) dropped(span *model.Span) {
+	t.countersFor(topServiceName(span)).dropped.Inc(1)
+}
+
+func (t *TopServices) countersFor(service string) *serviceCounters {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if counters, ok := t.counters[service]; ok {
+		return counters
+	}
+	return t.other
+}
+
+// promote creates the metrics of the current top services, while there is room for them.
+func (t *TopServices) promote() {
+	now := t.now()
+	top := append(t.spans.Top(now, t.size), t.bytes.Top(now, t.size)...)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, item := range top {
+		if len(t.counters) >= t.size {
+			return
+		}
+		if _, ok := t.counters[item.Key]; !ok {
+			t.counters[item.Key] = t.newServiceCounters(normalizer.ServiceName(item.Key))
+		}
+	}
+}
+
+// Close stops updating the metrics of the top services.
+func (t *TopServices) Close() error {
+	close(t.stopCh)
+	t.wg.Wait()
+	return nil
+}
+
+func topServiceName(span *model.Span) string {
+	if span.Process == nil || span.Process.ServiceName == "" {
+		return "__unknown"
+	}
+	return span.Process.ServiceName
+}
+
+type topServicesResponse struct {
+	Window     string           `json:"window"`
+	BySpanRate []topServiceRate `json:"by_span_rate"`
+	ByByteRate []topServiceRate `json:"by_byte_rate"`
+}
+
+type topServiceRate struct {
+	Service        string  `json:"service"`
+	SpansPerSecond float64 `json:"spans_per_second"`
+	BytesPerSecond float64 `json:"bytes_per_second"`
+}
+
+// ServeHTTP implements http.Handler, serving the top services by span rate and by byte rate over the window.
+// The rates are estimates, the ones ranking the services never below the actual rates.
+func (t *TopServices) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	now := t.now()
+	window := t.spans.Duration()
+	seconds := window.Seconds()
+	rates := func(top []topk.Item, spansOf, bytesOf func(topk.Item) uint64) []topServiceRate {
+		list := make([]topServiceRate, 0, len(top))
+		for _, item := range top {
+			list = append(list, topServiceRate{
+				Service:        item.Key,
+				SpansPerSecond: float64(spansOf(item)) / seconds,
+				BytesPerSecond: float64(bytesOf(item)) / seconds,
+			})
+		}
+		return list
+	}
+	count := func(item topk.Item) uint64 { return item.Count }
+	response := topServicesResponse{
+		Window: window.String(),
+		BySpanRate: rates(t.spans.Top(now, t.size), count, func(item topk.Item) uint64 {
+			return t.bytes.Estimate(now, item.Key).Count
+		}),
+		ByByteRate: rates(t.bytes.Top(now, t.size), func(item topk.Item) uint64 {
+			return t.spans.Estimate(now, item.Key).Count
+		}, count),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func newTopServicesSpan(service string, tagSize int) *model.Span {
+	span := &model.Span{Process: &model.Process{ServiceName: service}}
+	if tagSize > 0 {
+		span.Tags = []model.KeyValue{model.String("payload", strings.Repeat("x", tagSize))}
+	}
+	return span
+}
+
+func newTestTopServices(t *testing.T, size int, metricsFactory metrics.Factory) *TopServices {
+	ts := NewTopServices(flags.TopServicesOptions{Size: size, Window: time.Minute}, metricsFactory)
+	t.Cleanup(func() {
+		require.NoError(t, ts.Close())
+	})
+	now := time.Unix(1_700_000_000, 0)
+	ts.now = func() time.Time { return now }
+	return ts
+}
+
+func TestTopServicesMetrics(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	ts := newTestTopServices(t, 2, mb)
+
+	// the services are counted as other until they are among the top services
+	for i := 0; i < 3; i++ {
+		ts.received(newTopServicesSpan("a", 0))
+	}
+	ts.promote()
+	ts.received(newTopServicesSpan("a", 0))
+	ts.received(newTopServicesSpan("b", 0))
+	ts.received(newTopServicesSpan("c", 1000))
+	ts.promote()
+	// a then b and c tied by spans, c then a by bytes, leaving no room for c
+	ts.received(newTopServicesSpan("b", 0))
+	ts.received(newTopServicesSpan("c", 0))
+	ts.dropped(newTopServicesSpan("a", 0))
+	ts.dropped(newTopServicesSpan("c", 0))
+	ts.received(&model.Span{})
+
+	bytesOf := func(spans ...*model.Span) int {
+		var size int
+		for _, span := range spans {
+			size += span.Size()
+		}
+		return size
+	}
+	otherBytes := bytesOf(newTopServicesSpan("a", 0), newTopServicesSpan("a", 0), newTopServicesSpan("a", 0),
+		newTopServicesSpan("b", 0), newTopServicesSpan("c", 1000), newTopServicesSpan("c", 0), &model.Span{})
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "top-services.spans.received", Tags: map[string]string{"svc": "a"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "top-services.spans.received", Tags: map[string]string{"svc": "b"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "top-services.spans.received", Tags: map[string]string{"svc": "other"}, Value: 7},
+		metricstest.ExpectedMetric{Name: "top-services.spans.dropped", Tags: map[string]string{"svc": "a"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "top-services.spans.dropped", Tags: map[string]string{"svc": "other"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "top-services.bytes.received", Tags: map[string]string{"svc": "b"}, Value: bytesOf(newTopServicesSpan("b", 0))},
+		metricstest.ExpectedMetric{Name: "top-services.bytes.received", Tags: map[string]string{"svc": "other"}, Value: otherBytes},
+	)
+	counters, _ := mb.Snapshot()
+	assert.NotContains(t, counters, "top-services.spans.received|svc=c")
+}
+
+func TestTopServicesHandler(t *testing.T) {
+	ts := newTestTopServices(t, 2, metrics.NullFactory)
+	for i := 0; i < 3; i++ {
+		ts.received(newTopServicesSpan("frontend", 0))
+	}
+	ts.received(newTopServicesSpan("batch", 6000))
+	ts.received(newTopServicesSpan("rare", 0))
+
+	w := httptest.NewRecorder()
+	ts.ServeHTTP(w, httptest.NewRequest(http.MethodGet, TopServicesPath, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response topServicesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	frontendBytes := float64(3*newTopServicesSpan("frontend", 0).Size()) / 60
+	batchBytes := float64(newTopServicesSpan("batch", 6000).Size()) / 60
+	assert.Equal(t, topServicesResponse{
+		Window: "1m0s",
+		BySpanRate: []topServiceRate{
+			{Service: "frontend", SpansPerSecond: 3.0 / 60, BytesPerSecond: frontendBytes},
+			{Service: "batch", SpansPerSecond: 1.0 / 60, BytesPerSecond: batchBytes},
+		},
+		ByByteRate: []topServiceRate{
+			{Service: "batch", SpansPerSecond: 1.0 / 60, BytesPerSecond: batchBytes},
+			{Service: "frontend", SpansPerSecond: 3.0 / 60, BytesPerSecond: frontendBytes},
+		},
+	}, response)
+
+	w = httptest.NewRecorder()
+	ts.ServeHTTP(w, httptest.NewRequest(http.MethodPost, TopServicesPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestSpanProcessorTopServices(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	ts := newTestTopServices(t, 1, mb)
+	// the queue is not consumed, so that the spans beyond its size are dropped
+	p := newSpanProcessor(&fakeSpanWriter{}, nil, Options.QueueSize(1), Options.TopServices(ts))
+	defer p.queue.Stop()
+
+	spans := []*model.Span{newTopServicesSpan("a", 0), newTopServicesSpan("a", 0), newTopServicesSpan("a", 0)}
+	_, err := p.ProcessSpans(spans, processor.SpansOptions{SpanFormat: processor.JaegerSpanFormat})
+	require.NoError(t, err)
+	mb.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "top-services.spans.received", Tags: map[string]string{"svc": "other"}, Value: 3},
+		metricstest.ExpectedMetric{Name: "top-services.spans.dropped", Tags: map[string]string{"svc": "other"}, Value: 2},
+	)
+}
+
+// BenchmarkTopServicesReceived measures the overhead of the top services for each span received.
+func BenchmarkTopServicesReceived(b *testing.B) {
+	for _, services := range []int{10, 10_000} {
+		b.Run(fmt.Sprintf("services=%d", services), func(b *testing.B) {
+			ts := NewTopServices(flags.TopServicesOptions{Size: 20, Window: time.Minute}, metrics.NullFactory)
+			defer ts.Close()
+			spans := make([]*model.Span, 1000)
+			for i := range spans {
+				// a skewed stream, a quarter of the spans coming from the same service
+				service := "flood"
+				if i%4 != 0 {
+					service = fmt.Sprintf("service-%d", i%services)
+				}
+				spans[i] = newTopServicesSpan(service, 100)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					ts.received(spans[i%len(spans)])
+				}
+			})
+		})
+	}
+}
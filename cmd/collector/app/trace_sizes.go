@@ -0,0 +1,176 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"container/list"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const (
+	// traceSizesMaxServices caps the number of services counted for a trace, bounding the memory of each trace
+	traceSizesMaxServices = 64
+	// traceSizesExpireTicks is how many times per window the traces without new spans are recorded
+	traceSizesExpireTicks = 10
+)
+
+var (
+	spanBytesBuckets        = []float64{128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65536, 131072, 262144, 524288, 1048576}
+	spansPerTraceBuckets    = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000}
+	servicesPerTraceBuckets = []float64{1, 2, 3, 4, 5, 7, 10, 15, 20, 30, 50}
+)
+
+// TraceSizes records the distributions of the sizes of the spans, and of the numbers of spans and services of the
+// traces, for a fraction of the traces selected by their trace ID. The spans of a trace are counted until none was
+// received for the window, and at most MaxTraces traces are counted at once, the least recently updated ones being
+// recorded early when more arrive, so that the memory is bounded.
+type TraceSizes struct {
+	// threshold is the hash of the trace IDs below which the traces are sampled
+	threshold uint64
+	sampleAll bool
+	window    time.Duration
+	maxTraces int
+	now       func() time.Time
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+
+	mu sync.Mutex
+	// traces holds the elements of order by trace ID, order holding the traces by last update, oldest first
+	traces map[model.TraceID]*list.Element
+	order  *list.List
+
+	spanBytes        metrics.Histogram
+	spansPerTrace    metrics.Histogram
+	servicesPerTrace metrics.Histogram
+	evicted          metrics.Counter
+}
+
+type traceSize struct {
+	traceID  model.TraceID
+	spans    int
+	services []string
+	lastSeen time.Time
+}
+
+// NewTraceSizes returns the TraceSizes sampling the options.SampleRatio of the traces, recording the traces in the
+// background until closed.
+func NewTraceSizes(options flags.TraceSizesOptions, metricsFactory metrics.Factory) *TraceSizes {
+	factory := metricsFactory.Namespace(metrics.NSOptions{Name: "trace-sizes"})
+	t := &TraceSizes{
+		sampleAll: options.SampleRatio >= 1,
+		window:    options.Window,
+		maxTraces: options.MaxTraces,
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+		traces:    make(map[model.TraceID]*list.Element),
+		order:     list.New(),
+		spanBytes: factory.Histogram(metrics.HistogramOptions{
+			Name: "span-bytes", Buckets: spanBytesBuckets, Help: "The size in bytes of the spans of the sampled traces",
+		}),
+		spansPerTrace: factory.Histogram(metrics.HistogramOptions{
+			Name: "spans-per-trace", Buckets: spansPerTraceBuckets, Help: "The number of spans of the sampled traces",
+		}),
+		servicesPerTrace: factory.Histogram(metrics.HistogramOptions{
+			Name: "services-per-trace", Buckets: servicesPerTraceBuckets, Help: "The number of services of the sampled traces",
+		}),
+		evicted: factory.Counter(metrics.Options{
+			Name: "traces.evicted", Help: "The number of sampled traces recorded before the end of their window because too many traces were counted",
+		}),
+	}
+	if !t.sampleAll {
+		t.threshold = uint64(options.SampleRatio * math.MaxUint64)
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.window / traceSizesExpireTicks)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.expire()
+			case <-t.stopCh:
+				return
+			}
+		}
+	}()
+	return t
+}
+
+// sampled returns whether the trace is sampled, consistently across the collectors.
+func (t *TraceSizes) sampled(traceID model.TraceID) bool {
+	if t.sampleAll {
+		return true
+	}
+	// the trace IDs may not be random in all their bits, they are mixed first
+	hash := (traceID.High ^ traceID.Low) * 0x9E3779B97F4A7C15
+	return hash < t.threshold
+}
+
+// received counts the span if its trace is sampled.
+func (t *TraceSizes) received(span *model.Span) {
+	if !t.sampled(span.TraceID) {
+		return
+	}
+	t.spanBytes.Record(float64(span.Size()))
+	service := topServiceName(span)
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if elem, ok := t.traces[span.TraceID]; ok {
+		size := elem.Value.(*traceSize)
+		size.spans++
+		size.addService(service)
+		size.lastSeen = now
+		t.order.MoveToBack(elem)
+		return
+	}
+	if len(t.traces) >= t.maxTraces {
+		t.evicted.Inc(1)
+		t.recordLocked(t.order.Front())
+	}
+	size := &traceSize{traceID: span.TraceID, spans: 1, services: []string{service}, lastSeen: now}
+	t.traces[span.TraceID] = t.order.PushBack(size)
+}
+
+func (s *traceSize) addService(service string) {
+	if len(s.services) < traceSizesMaxServices && !slices.Contains(s.services, service) {
+		s.services = append(s.services, service)
+	}
+}
+
+// expire records the traces without new spans for the window.
+func (t *TraceSizes) expire() {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for elem := t.order.Front(); elem != nil; elem = t.order.Front() {
+		if now.Sub(elem.Value.(*traceSize).lastSeen) < t.window {
+			return
+		}
+		t.recordLocked(elem)
+	}
+}
+
+func (t *TraceSizes) recordLocked(elem *list.Element) {
+	size := t.order.Remove(elem).(*traceSize)
+	delete(t.traces, size.traceID)
+	t.spansPerTrace.Record(float64(size.spans))
+	t.servicesPerTrace.Record(float64(len(size.services)))
+}
+
+// Close stops recording the traces, the traces being counted are discarded.
+func (t *TraceSizes) Close() error {
+	close(t.stopCh)
+	t.wg.Wait()
+	return nil
+}
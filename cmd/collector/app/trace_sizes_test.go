@@ -0,0 +1,148 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/flags"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func newTraceSizesSpan(traceID uint64, service string) *model.Span {
+	return &model.Span{
+		TraceID: model.NewTraceID(0, traceID),
+		Process: &model.Process{ServiceName: service},
+	}
+}
+
+func newTestTraceSizes(t *testing.T, options flags.TraceSizesOptions, metricsFactory metrics.Factory) (*TraceSizes, *time.Time) {
+	ts := NewTraceSizes(options, metricsFactory)
+	t.Cleanup(func() {
+		require.NoError(t, ts.Close())
+	})
+	now := time.Unix(1_700_000_000, 0)
+	ts.now = func() time.Time { return now }
+	return ts, &now
+}
+
+func TestTraceSizesHistograms(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	ts, now := newTestTraceSizes(t, flags.TraceSizesOptions{SampleRatio: 1, Window: time.Minute, MaxTraces: 10}, mb)
+
+	for _, service := range []string{"frontend", "backend", "backend", "db"} {
+		ts.received(newTraceSizesSpan(1, service))
+	}
+	ts.received(newTraceSizesSpan(2, "frontend"))
+	*now = now.Add(30 * time.Second)
+	ts.received(newTraceSizesSpan(2, "frontend"))
+
+	// trace 1 is done, trace 2 received a span during the window
+	*now = now.Add(40 * time.Second)
+	ts.expire()
+	assert.Equal(t, 1, ts.order.Len())
+	_, gauges := mb.Snapshot()
+	assert.EqualValues(t, 4, gauges["trace-sizes.spans-per-trace.P50"])
+	assert.EqualValues(t, 3, gauges["trace-sizes.services-per-trace.P50"])
+	assert.Positive(t, gauges["trace-sizes.span-bytes.P50"])
+
+	*now = now.Add(time.Minute)
+	ts.expire()
+	assert.Empty(t, ts.traces)
+	_, gauges = mb.Snapshot()
+	assert.EqualValues(t, 2, gauges["trace-sizes.spans-per-trace.P50"])
+	assert.EqualValues(t, 4, gauges["trace-sizes.spans-per-trace.P99"])
+	assert.EqualValues(t, 1, gauges["trace-sizes.services-per-trace.P50"])
+}
+
+func TestTraceSizesMaxTraces(t *testing.T) {
+	mb := metricstest.NewFactory(time.Hour)
+	defer mb.Backend.Stop()
+	ts, now := newTestTraceSizes(t, flags.TraceSizesOptions{SampleRatio: 1, Window: time.Minute, MaxTraces: 2}, mb)
+
+	ts.received(newTraceSizesSpan(1, "a"))
+	*now = now.Add(time.Second)
+	ts.received(newTraceSizesSpan(2, "a"))
+	*now = now.Add(time.Second)
+	// trace 1 becomes the most recently updated
+	ts.received(newTraceSizesSpan(1, "a"))
+	ts.received(newTraceSizesSpan(3, "a"))
+
+	assert.Len(t, ts.traces, 2)
+	assert.Contains(t, ts.traces, model.NewTraceID(0, 1))
+	assert.Contains(t, ts.traces, model.NewTraceID(0, 3))
+	mb.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "trace-sizes.traces.evicted", Value: 1})
+}
+
+func TestTraceSizesMaxServices(t *testing.T) {
+	ts, _ := newTestTraceSizes(t, flags.TraceSizesOptions{SampleRatio: 1, Window: time.Minute, MaxTraces: 2}, metrics.NullFactory)
+	for i := 0; i < 2*traceSizesMaxServices; i++ {
+		ts.received(newTraceSizesSpan(1, fmt.Sprintf("service-%d", i)))
+	}
+	size := ts.traces[model.NewTraceID(0, 1)].Value.(*traceSize)
+	assert.Equal(t, 2*traceSizesMaxServices, size.spans)
+	assert.Len(t, size.services, traceSizesMaxServices)
+}
+
+func TestTraceSizesSampling(t *testing.T) {
+	for _, ratio := range []float64{0.1, 0.5} {
+		t.Run(fmt.Sprint(ratio), func(t *testing.T) {
+			ts, _ := newTestTraceSizes(t, flags.TraceSizesOptions{SampleRatio: ratio, Window: time.Minute, MaxTraces: 1}, metrics.NullFactory)
+			const traces = 100_000
+			sampled := 0
+			for i := uint64(1); i <= traces; i++ {
+				// sequential trace IDs, as generated by some clients
+				if ts.sampled(model.NewTraceID(0, i)) {
+					sampled++
+				}
+			}
+			assert.InDelta(t, ratio, float64(sampled)/traces, 0.01)
+			// the decision only depends on the trace ID
+			traceID := model.NewTraceID(42, 7)
+			assert.Equal(t, ts.sampled(traceID), ts.sampled(traceID))
+		})
+	}
+}
+
+func TestTraceSizesExpireInBackground(t *testing.T) {
+	ts := NewTraceSizes(flags.TraceSizesOptions{SampleRatio: 1, Window: 10 * time.Millisecond, MaxTraces: 10}, metrics.NullFactory)
+	defer ts.Close()
+	ts.received(newTraceSizesSpan(1, "a"))
+	assert.Eventually(t, func() bool {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		return len(ts.traces) == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+// BenchmarkTraceSizesReceived measures the overhead of the trace sizes for each span received.
+func BenchmarkTraceSizesReceived(b *testing.B) {
+	for _, ratio := range []float64{0.01, 1} {
+		b.Run(fmt.Sprintf("ratio=%v", ratio), func(b *testing.B) {
+			ts := NewTraceSizes(flags.TraceSizesOptions{SampleRatio: ratio, Window: time.Minute, MaxTraces: 10_000}, metrics.NullFactory)
+			defer ts.Close()
+			spans := make([]*model.Span, 100_000)
+			for i := range spans {
+				// traces of 10 spans from 3 services
+				spans[i] = newTraceSizesSpan(uint64(i/10), fmt.Sprintf("service-%d", i%3))
+				spans[i].Tags = []model.KeyValue{model.String("http.url", "/api/traces")}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					ts.received(spans[i%len(spans)])
+				}
+			})
+		})
+	}
+}
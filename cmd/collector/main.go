@@ -16,6 +16,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -34,6 +35,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/internal/printconfig"
 	"github.com/jaegertracing/jaeger/cmd/internal/status"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/pkg/version"
@@ -100,7 +102,22 @@ func main() {
 			if err != nil {
 				logger.Fatal("Failed to initialize collector", zap.Error(err))
 			}
-			tm := tenancy.NewManager(&collectorOpts.GRPC.Tenancy)
+			tm, err := tenancy.NewManagerWithConfigFile(&collectorOpts.GRPC.Tenancy, logger)
+			if err != nil {
+				logger.Fatal("Failed to create the tenancy manager", zap.Error(err))
+			}
+			tracingOpts, err := new(jtracer.Options).InitFromViper(v)
+			if err != nil {
+				logger.Fatal("Failed to configure the self-tracing", zap.Error(err))
+			}
+			// the collector is only traced when a self-tracing exporter is selected
+			tracer := jtracer.NoOp()
+			if tracingOpts.Exporter != "" {
+				tracer, err = jtracer.NewFromOptions(serviceName, *tracingOpts)
+				if err != nil {
+					logger.Fatal("Failed to create tracer", zap.Error(err))
+				}
+			}
 
 			collector := app.New(&app.CollectorParams{
 				ServiceName:        serviceName,
@@ -111,16 +128,32 @@ func main() {
 				SamplingAggregator: samplingAggregator,
 				HealthCheck:        svc.HC(),
 				TenancyMgr:         tm,
+				TracerProvider:     tracer.OTEL,
 			})
 			// Start all Collector services
 			if err := collector.Start(collectorOpts); err != nil {
 				logger.Fatal("Failed to start collector", zap.Error(err))
 			}
+			if topServices := collector.TopServices(); topServices != nil {
+				svc.Admin.Handle(app.TopServicesPath, topServices)
+			}
+			if flushStorage := collector.FlushStorageHandler(); flushStorage != nil {
+				svc.Admin.Handle(app.FlushStoragePath, flushStorage)
+			}
+			if status := collector.StatusHandler(); status != nil {
+				svc.Admin.Handle(app.StatusPath, status)
+			}
 			// Wait for shutdown
 			svc.RunAndThen(func() {
 				if err := collector.Close(); err != nil {
 					logger.Error("failed to cleanly close the collector", zap.Error(err))
 				}
+				if err := tm.Close(); err != nil {
+					logger.Error("Failed to close the tenancy manager", zap.Error(err))
+				}
+				if err := tracer.Close(context.Background()); err != nil {
+					logger.Error("Error shutting down tracer provider", zap.Error(err))
+				}
 				if closer, ok := spanWriter.(io.Closer); ok {
 					err := closer.Close()
 					if err != nil {
@@ -151,6 +184,7 @@ func main() {
 		flags.AddFlags,
 		storageFactory.AddPipelineFlags,
 		samplingStrategyFactory.AddFlags,
+		jtracer.AddFlags,
 	)
 
 	if err := command.Execute(); err != nil {
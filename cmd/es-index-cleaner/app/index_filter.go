@@ -50,9 +50,9 @@ func (i *IndexFilter) filter(indices []client.Index) []client.Index {
 		// archive works only for rollover
 		reg, _ = regexp.Compile(fmt.Sprintf("^%sjaeger-span-archive-\\d{6}", i.IndexPrefix))
 	case i.Rollover:
-		reg, _ = regexp.Compile(fmt.Sprintf("^%sjaeger-(span|service|dependencies|sampling)-\\d{6}", i.IndexPrefix))
+		reg, _ = regexp.Compile(fmt.Sprintf("^%sjaeger-(span|service|dependencies|operation-dependencies|sampling)-\\d{6}", i.IndexPrefix))
 	default:
-		reg, _ = regexp.Compile(fmt.Sprintf("^%sjaeger-(span|service|dependencies|sampling)-\\d{4}%s\\d{2}%s\\d{2}", i.IndexPrefix, i.IndexDateSeparator, i.IndexDateSeparator))
+		reg, _ = regexp.Compile(fmt.Sprintf("^%sjaeger-(span|service|dependencies|operation-dependencies|sampling)-\\d{4}%s\\d{2}%s\\d{2}", i.IndexPrefix, i.IndexDateSeparator, i.IndexDateSeparator))
 	}
 
 	var filtered []client.Index
@@ -63,6 +63,7 @@ func (i *IndexFilter) filter(indices []client.Index) []client.Index {
 				in.Aliases[i.IndexPrefix+"jaeger-service-write"] ||
 				in.Aliases[i.IndexPrefix+"jaeger-span-archive-write"] ||
 				in.Aliases[i.IndexPrefix+"jaeger-dependencies-write"] ||
+				in.Aliases[i.IndexPrefix+"jaeger-operation-dependencies-write"] ||
 				in.Aliases[i.IndexPrefix+"jaeger-sampling-write"] {
 				continue
 			}
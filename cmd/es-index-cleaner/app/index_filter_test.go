@@ -372,3 +372,29 @@ func testIndexFilter(t *testing.T, prefix string) {
 		})
 	}
 }
+
+func TestIndexFilterOperationDependencies(t *testing.T) {
+	old := client.Index{
+		Index:        "jaeger-operation-dependencies-2020-08-05",
+		CreationTime: time.Date(2020, time.August, 0o5, 15, 0, 0, 0, time.UTC),
+		Aliases:      map[string]bool{},
+	}
+	recent := client.Index{
+		Index:        "jaeger-operation-dependencies-2020-08-06",
+		CreationTime: time.Date(2020, time.August, 0o6, 15, 0, 0, 0, time.UTC),
+		Aliases:      map[string]bool{},
+	}
+	filter := &IndexFilter{
+		IndexDateSeparator:   "-",
+		DeleteBeforeThisDate: time.Date(2020, time.August, 0o6, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, []client.Index{old}, filter.Filter([]client.Index{old, recent}))
+
+	written := client.Index{
+		Index:        "jaeger-operation-dependencies-000001",
+		CreationTime: time.Date(2020, time.August, 0o5, 15, 0, 0, 0, time.UTC),
+		Aliases:      map[string]bool{"jaeger-operation-dependencies-write": true},
+	}
+	filter.Rollover = true
+	assert.Empty(t, filter.Filter([]client.Index{written}))
+}
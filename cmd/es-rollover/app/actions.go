@@ -46,6 +46,14 @@ type Action interface {
 	Do() error
 }
 
+// ActionFunc is an adapter allowing the use of a function as an Action
+type ActionFunc func() error
+
+// Do calls the function
+func (f ActionFunc) Do() error {
+	return f()
+}
+
 // ActionExecuteOptions are the options passed to the execute action function
 type ActionExecuteOptions struct {
 	Args     []string
@@ -30,6 +30,7 @@ const (
 	timeout          = "timeout"
 	skipDependencies = "skip-dependencies"
 	adaptiveSampling = "adaptive-sampling"
+	dryRun           = "dry-run"
 )
 
 // Config holds the global configurations for the es rollover, common to all actions
@@ -44,6 +45,7 @@ type Config struct {
 	Timeout          int
 	SkipDependencies bool
 	AdaptiveSampling bool
+	DryRun           bool
 }
 
 // AddFlags adds flags
@@ -57,6 +59,7 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.Int(timeout, 120, "Number of seconds to wait for master node response")
 	flags.Bool(skipDependencies, false, "Disable rollover for dependencies index")
 	flags.Bool(adaptiveSampling, false, "Enable rollover for adaptive sampling index")
+	flags.Bool(dryRun, false, "Print the API calls modifying the indices and aliases instead of making them")
 }
 
 // InitFromViper initializes config from viper.Viper.
@@ -73,4 +76,5 @@ func (c *Config) InitFromViper(v *viper.Viper) {
 	c.Timeout = v.GetInt(timeout)
 	c.SkipDependencies = v.GetBool(skipDependencies)
 	c.AdaptiveSampling = v.GetBool(adaptiveSampling)
+	c.DryRun = v.GetBool(dryRun)
 }
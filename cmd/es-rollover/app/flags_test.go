@@ -43,6 +43,7 @@ func TestBindFlags(t *testing.T) {
 		"--es.ilm-policy-name=jaeger-ilm",
 		"--skip-dependencies=true",
 		"--adaptive-sampling=true",
+		"--dry-run=true",
 	})
 	require.NoError(t, err)
 
@@ -55,4 +56,5 @@ func TestBindFlags(t *testing.T) {
 	assert.Equal(t, "jaeger-ilm", c.ILMPolicyName)
 	assert.True(t, c.SkipDependencies)
 	assert.True(t, c.AdaptiveSampling)
+	assert.True(t, c.DryRun)
 }
@@ -38,7 +38,7 @@ type Action struct {
 	ILMClient     client.IndexManagementLifecycleAPI
 }
 
-func (c Action) getMapping(version uint, templateName string) (string, error) {
+func (c Action) getMapping(version client.ClusterVersion, templateName string) (string, error) {
 	mappingBuilder := mappings.MappingBuilder{
 		TemplateBuilder:              es.TextTemplateBuilder{},
 		PrioritySpanTemplate:         int64(c.Config.PrioritySpanTemplate),
@@ -50,19 +50,20 @@ func (c Action) getMapping(version uint, templateName string) (string, error) {
 		IndexPrefix:                  c.Config.IndexPrefix,
 		UseILM:                       c.Config.UseILM,
 		ILMPolicyName:                c.Config.ILMPolicyName,
-		EsVersion:                    version,
+		EsVersion:                    version.EsCompatibleVersion(),
+		UseComposableTemplates:       version.UseComposableTemplates(),
 	}
 	return mappingBuilder.GetMapping(templateName)
 }
 
 // Do the init action
 func (c Action) Do() error {
-	version, err := c.ClusterClient.Version()
+	version, err := c.ClusterClient.ClusterVersion()
 	if err != nil {
 		return err
 	}
 	if c.Config.UseILM {
-		if version < ilmVersionSupport {
+		if version.EsCompatibleVersion() < ilmVersionSupport {
 			return fmt.Errorf("ILM is supported only for ES version 7+")
 		}
 		policyExist, err := c.ILMClient.Exists(c.Config.ILMPolicyName)
@@ -109,7 +110,7 @@ func createIndexIfNotExist(c client.IndexAPI, index string) error {
 	return nil
 }
 
-func (c Action) init(version uint, indexopt app.IndexOption) error {
+func (c Action) init(version client.ClusterVersion, indexopt app.IndexOption) error {
 	mapping, err := c.getMapping(version, indexopt.Mapping)
 	if err != nil {
 		return err
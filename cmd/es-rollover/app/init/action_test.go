@@ -98,7 +98,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "Unsupported version",
 			setupCallExpectations: func(_ *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, _ *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(5), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 5}, nil)
 			},
 			config: Config{
 				Config: app.Config{
@@ -111,7 +111,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "error getting version",
 			setupCallExpectations: func(_ *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, _ *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(0), errors.New("version error"))
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{}, errors.New("version error"))
 			},
 			expectedErr: errors.New("version error"),
 			config: Config{
@@ -124,7 +124,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "ilm doesnt exist",
 			setupCallExpectations: func(_ *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, ilmClient *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(7), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 7}, nil)
 				ilmClient.On("Exists", "myilmpolicy").Return(false, nil)
 			},
 			expectedErr: errors.New("ILM policy myilmpolicy doesn't exist in Elasticsearch. Please create it and re-run init"),
@@ -139,7 +139,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "fail get ilm policy",
 			setupCallExpectations: func(_ *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, ilmClient *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(7), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 7}, nil)
 				ilmClient.On("Exists", "myilmpolicy").Return(false, errors.New("error getting ilm policy"))
 			},
 			expectedErr: errors.New("error getting ilm policy"),
@@ -154,7 +154,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "fail to create template",
 			setupCallExpectations: func(indexClient *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, _ *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(7), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 7}, nil)
 				indexClient.On("CreateTemplate", mock.Anything, "jaeger-span").Return(errors.New("error creating template"))
 			},
 			expectedErr: errors.New("error creating template"),
@@ -168,7 +168,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "fail to get jaeger indices",
 			setupCallExpectations: func(indexClient *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, _ *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(7), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 7}, nil)
 				indexClient.On("CreateTemplate", mock.Anything, "jaeger-span").Return(nil)
 				indexClient.On("CreateIndex", "jaeger-span-archive-000001").Return(nil)
 				indexClient.On("GetJaegerIndices", "").Return([]client.Index{}, errors.New("error getting jaeger indices"))
@@ -184,7 +184,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "fail to create alias",
 			setupCallExpectations: func(indexClient *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, _ *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(7), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 7}, nil)
 				indexClient.On("CreateTemplate", mock.Anything, "jaeger-span").Return(nil)
 				indexClient.On("CreateIndex", "jaeger-span-archive-000001").Return(nil)
 				indexClient.On("GetJaegerIndices", "").Return([]client.Index{}, nil)
@@ -204,7 +204,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "create rollover index",
 			setupCallExpectations: func(indexClient *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, _ *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(7), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 7}, nil)
 				indexClient.On("CreateTemplate", mock.Anything, "jaeger-span").Return(nil)
 				indexClient.On("CreateIndex", "jaeger-span-archive-000001").Return(nil)
 				indexClient.On("GetJaegerIndices", "").Return([]client.Index{}, nil)
@@ -224,7 +224,7 @@ func TestRolloverAction(t *testing.T) {
 		{
 			name: "create rollover index with ilm",
 			setupCallExpectations: func(indexClient *mocks.IndexAPI, clusterClient *mocks.ClusterAPI, ilmClient *mocks.IndexManagementLifecycleAPI) {
-				clusterClient.On("Version").Return(uint(7), nil)
+				clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Major: 7}, nil)
 				indexClient.On("CreateTemplate", mock.Anything, "jaeger-span").Return(nil)
 				indexClient.On("CreateIndex", "jaeger-span-archive-000001").Return(nil)
 				indexClient.On("GetJaegerIndices", "").Return([]client.Index{}, nil)
@@ -15,7 +15,7 @@
 package rollover
 
 import (
-	"encoding/json"
+	"errors"
 
 	"github.com/jaegertracing/jaeger/cmd/es-rollover/app"
 	"github.com/jaegertracing/jaeger/pkg/es/client"
@@ -26,28 +26,43 @@ import (
 type Action struct {
 	Config
 	IndicesClient client.IndexAPI
+	// ClusterClient is only required by the conditions whose support depends on the cluster version
+	ClusterClient client.ClusterAPI
 }
 
 // Do the rollover action
 func (a *Action) Do() error {
+	conditionsMap, err := a.conditions()
+	if err != nil {
+		return err
+	}
 	rolloverIndices := app.RolloverIndices(a.Config.Archive, a.Config.SkipDependencies, a.Config.AdaptiveSampling, a.Config.IndexPrefix)
 	for _, indexName := range rolloverIndices {
-		if err := a.rollover(indexName); err != nil {
+		if err := a.rollover(indexName, conditionsMap); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (a *Action) rollover(indexSet app.IndexOption) error {
-	conditionsMap := map[string]any{}
-	if len(a.Conditions) > 0 {
-		err := json.Unmarshal([]byte(a.Config.Conditions), &conditionsMap)
-		if err != nil {
-			return err
+func (a *Action) conditions() (map[string]any, error) {
+	conditions, err := ParseConditions(a.Config.Conditions)
+	if err != nil {
+		return nil, err
+	}
+	var version client.ClusterVersion
+	if conditions.NeedsClusterVersion() {
+		if a.ClusterClient == nil {
+			return nil, errors.New("the cluster client is required by the rollover conditions")
+		}
+		if version, err = a.ClusterClient.ClusterVersion(); err != nil {
+			return nil, err
 		}
 	}
+	return conditions.Map(version)
+}
 
+func (a *Action) rollover(indexSet app.IndexOption, conditionsMap map[string]any) error {
 	writeAlias := indexSet.WriteAliasName()
 	readAlias := indexSet.ReadAliasName()
 	err := a.IndicesClient.Rollover(writeAlias, conditionsMap)
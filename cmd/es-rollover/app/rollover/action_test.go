@@ -144,3 +144,44 @@ func TestRolloverAction(t *testing.T) {
 		})
 	}
 }
+
+func TestRolloverActionClusterVersion(t *testing.T) {
+	readIndices := []client.Index{{Index: "jaeger-span-000002", Aliases: map[string]bool{"jaeger-span-write": true}}}
+	config := Config{
+		Conditions: `{"max_primary_shard_size": "50gb"}`,
+		Config:     app.Config{SkipDependencies: true},
+	}
+
+	t.Run("supported", func(t *testing.T) {
+		indexClient := &mocks.IndexAPI{}
+		clusterClient := &mocks.ClusterAPI{}
+		clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Distribution: client.DistributionElasticsearch, Major: 8}, nil)
+		for _, alias := range []string{"jaeger-span", "jaeger-service"} {
+			indexClient.On("Rollover", alias+"-write", map[string]any{"max_primary_shard_size": "50gb"}).Return(nil)
+		}
+		indexClient.On("GetJaegerIndices", "").Return(readIndices, nil)
+		indexClient.On("CreateAlias", []client.Alias{{Index: "jaeger-span-000002", Name: "jaeger-span-read"}}).Return(nil)
+		action := Action{Config: config, IndicesClient: indexClient, ClusterClient: clusterClient}
+		require.NoError(t, action.Do())
+		indexClient.AssertExpectations(t)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		clusterClient := &mocks.ClusterAPI{}
+		clusterClient.On("ClusterVersion").Return(client.ClusterVersion{Distribution: client.DistributionOpenSearch, Major: 1}, nil)
+		action := Action{Config: config, IndicesClient: &mocks.IndexAPI{}, ClusterClient: clusterClient}
+		require.ErrorContains(t, action.Do(), "not supported by version opensearch1")
+	})
+
+	t.Run("version error", func(t *testing.T) {
+		clusterClient := &mocks.ClusterAPI{}
+		clusterClient.On("ClusterVersion").Return(client.ClusterVersion{}, errors.New("unreachable"))
+		action := Action{Config: config, IndicesClient: &mocks.IndexAPI{}, ClusterClient: clusterClient}
+		require.ErrorContains(t, action.Do(), "unreachable")
+	})
+
+	t.Run("no cluster client", func(t *testing.T) {
+		action := Action{Config: config, IndicesClient: &mocks.IndexAPI{}}
+		require.ErrorContains(t, action.Do(), "cluster client is required")
+	})
+}
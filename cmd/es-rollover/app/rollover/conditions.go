@@ -0,0 +1,69 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rollover
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jaegertracing/jaeger/pkg/es/client"
+)
+
+// Conditions are the conditions of the rollover API, the write alias being rolled over to a new index
+// as soon as one of them is met by its current index.
+type Conditions struct {
+	// MaxAge is the maximum age of the index, e.g. 2d
+	MaxAge string `json:"max_age,omitempty"`
+	// MaxDocs is the maximum number of documents of the index
+	MaxDocs int64 `json:"max_docs,omitempty"`
+	// MaxSize is the maximum total size of the primary shards of the index, e.g. 50gb
+	MaxSize string `json:"max_size,omitempty"`
+	// MaxPrimaryShardSize is the maximum size of the largest primary shard of the index, e.g. 50gb.
+	// It is supported by Elasticsearch 7.13+ and OpenSearch 2+.
+	MaxPrimaryShardSize string `json:"max_primary_shard_size,omitempty"`
+}
+
+// ParseConditions parses the conditions from their JSON representation in the rollover API,
+// rejecting the conditions not supported.
+func ParseConditions(conditions string) (Conditions, error) {
+	var c Conditions
+	if conditions == "" {
+		return c, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(conditions)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return c, fmt.Errorf("invalid rollover conditions %s: %w", conditions, err)
+	}
+	return c, nil
+}
+
+// NeedsClusterVersion returns whether the support of the conditions depends on the cluster version.
+func (c Conditions) NeedsClusterVersion() bool {
+	return c.MaxPrimaryShardSize != ""
+}
+
+// Map returns the conditions in the shape of the rollover API of the cluster, an empty map meaning
+// an unconditional rollover. The version is only checked when NeedsClusterVersion is true.
+func (c Conditions) Map(version client.ClusterVersion) (map[string]any, error) {
+	conditions := map[string]any{}
+	if c.MaxAge != "" {
+		conditions["max_age"] = c.MaxAge
+	}
+	if c.MaxDocs != 0 {
+		conditions["max_docs"] = c.MaxDocs
+	}
+	if c.MaxSize != "" {
+		conditions["max_size"] = c.MaxSize
+	}
+	if c.MaxPrimaryShardSize != "" {
+		// OpenSearch 1.x was forked from Elasticsearch 7.10, before the condition was introduced
+		if version.IsOpenSearch() && version.Major < 2 || !version.IsOpenSearch() && version.Major < 7 {
+			return nil, fmt.Errorf("the max_primary_shard_size rollover condition is not supported by version %s", version)
+		}
+		conditions["max_primary_shard_size"] = c.MaxPrimaryShardSize
+	}
+	return conditions, nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rollover
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/es/client"
+)
+
+func TestParseConditions(t *testing.T) {
+	conditions, err := ParseConditions(`{"max_age": "2d", "max_docs": 1000, "max_size": "100gb", "max_primary_shard_size": "50gb"}`)
+	require.NoError(t, err)
+	assert.Equal(t, Conditions{MaxAge: "2d", MaxDocs: 1000, MaxSize: "100gb", MaxPrimaryShardSize: "50gb"}, conditions)
+	assert.True(t, conditions.NeedsClusterVersion())
+
+	conditions, err = ParseConditions("")
+	require.NoError(t, err)
+	assert.Equal(t, Conditions{}, conditions)
+	assert.False(t, conditions.NeedsClusterVersion())
+
+	_, err = ParseConditions(`{"max_shards": 2}`)
+	require.ErrorContains(t, err, "invalid rollover conditions")
+	_, err = ParseConditions(`{"max_docs": "many"}`)
+	require.ErrorContains(t, err, "invalid rollover conditions")
+}
+
+func TestConditionsMap(t *testing.T) {
+	es6 := client.ClusterVersion{Distribution: client.DistributionElasticsearch, Major: 6}
+	es7 := client.ClusterVersion{Distribution: client.DistributionElasticsearch, Major: 7}
+	es8 := client.ClusterVersion{Distribution: client.DistributionElasticsearch, Major: 8}
+	os1 := client.ClusterVersion{Distribution: client.DistributionOpenSearch, Major: 1}
+	os2 := client.ClusterVersion{Distribution: client.DistributionOpenSearch, Major: 2}
+	all := Conditions{MaxAge: "2d", MaxDocs: 1000, MaxSize: "100gb", MaxPrimaryShardSize: "50gb"}
+	allBody := `{"conditions":{"max_age":"2d","max_docs":1000,"max_primary_shard_size":"50gb","max_size":"100gb"}}`
+	noShardSize := Conditions{MaxAge: "2d", MaxDocs: 1000, MaxSize: "100gb"}
+	noShardSizeBody := `{"conditions":{"max_age":"2d","max_docs":1000,"max_size":"100gb"}}`
+
+	tests := []struct {
+		name          string
+		conditions    Conditions
+		version       client.ClusterVersion
+		expectedBody  string
+		expectedError string
+	}{
+		{name: "elasticsearch 8", conditions: all, version: es8, expectedBody: allBody},
+		{name: "elasticsearch 7", conditions: all, version: es7, expectedBody: allBody},
+		{name: "opensearch 2", conditions: all, version: os2, expectedBody: allBody},
+		{
+			name:          "elasticsearch 6 max_primary_shard_size",
+			conditions:    all,
+			version:       es6,
+			expectedError: "not supported by version 6",
+		},
+		{
+			name:          "opensearch 1 max_primary_shard_size",
+			conditions:    all,
+			version:       os1,
+			expectedError: "not supported by version opensearch1",
+		},
+		{name: "elasticsearch 6", conditions: noShardSize, version: es6, expectedBody: noShardSizeBody},
+		{name: "opensearch 1", conditions: noShardSize, version: os1, expectedBody: noShardSizeBody},
+		{name: "unknown version", conditions: noShardSize, expectedBody: noShardSizeBody},
+		{name: "no conditions", version: es8, expectedBody: `{"conditions":{}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conditions, err := test.conditions.Map(test.version)
+			if test.expectedError != "" {
+				require.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			// the body of the rollover API is the same for Elasticsearch and OpenSearch
+			body, err := json.Marshal(map[string]any{"conditions": conditions})
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expectedBody, string(body))
+		})
+	}
+}
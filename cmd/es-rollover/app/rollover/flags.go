@@ -35,7 +35,7 @@ type Config struct {
 
 // AddFlags adds flags for TLS to the FlagSet.
 func (*Config) AddFlags(flags *flag.FlagSet) {
-	flags.String(conditions, defaultRollbackCondition, "conditions used to rollover to a new write index")
+	flags.String(conditions, defaultRollbackCondition, "conditions used to rollover to a new write index, a JSON object with max_age, max_docs, max_size and/or max_primary_shard_size")
 }
 
 // InitFromViper initializes config from viper.Viper.
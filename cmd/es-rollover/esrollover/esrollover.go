@@ -0,0 +1,221 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package esrollover runs the actions of jaeger-es-rollover, for embedding them e.g. in a scheduler.
+package esrollover
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/es-rollover/app"
+	initialize "github.com/jaegertracing/jaeger/cmd/es-rollover/app/init"
+	"github.com/jaegertracing/jaeger/cmd/es-rollover/app/lookback"
+	"github.com/jaegertracing/jaeger/cmd/es-rollover/app/rollover"
+	"github.com/jaegertracing/jaeger/pkg/es/client"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// Names of the actions
+const (
+	ActionInit     = "init"
+	ActionRollover = "rollover"
+	ActionLookback = "lookback"
+)
+
+// Options are the options of the execution of the actions
+type Options struct {
+	// MetricsFactory creates the metrics of the actions, none being reported if nil
+	MetricsFactory metrics.Factory
+	// Logger logs the progress of the actions, nothing being logged if nil
+	Logger *zap.Logger
+}
+
+// APICall is a call to the API of the cluster modifying its indices or aliases
+type APICall struct {
+	Method string `json:"method"`
+	// URI is the path and query of the call, relative to the host of the cluster
+	URI  string `json:"uri"`
+	Body string `json:"body,omitempty"`
+}
+
+// String returns the call in the format of the Dev Tools console of Kibana and OpenSearch Dashboards
+func (c APICall) String() string {
+	if c.Body == "" {
+		return c.Method + " " + c.URI
+	}
+	return c.Method + " " + c.URI + "\n" + c.Body
+}
+
+// Result is the outcome of an action
+type Result struct {
+	Action string `json:"action"`
+	// DryRun tells that the calls were not made, the read-only calls being made nonetheless
+	DryRun bool `json:"dryRun"`
+	// Calls are the calls modifying the cluster that were attempted, in order, the last one having
+	// failed when the action failed because of the cluster
+	Calls    []APICall     `json:"calls"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Init creates the index templates, the initial indices and their read and write aliases.
+func Init(c client.Client, cfg initialize.Config, options Options) (*Result, error) {
+	return run(ActionInit, c, cfg.Config, options, func(c client.Client) app.Action {
+		return &initialize.Action{
+			IndicesClient: &client.IndicesClient{Client: c, MasterTimeoutSeconds: cfg.Timeout},
+			ClusterClient: &client.ClusterClient{Client: c},
+			ILMClient:     &client.ILMClient{Client: c},
+			Config:        cfg,
+		}
+	})
+}
+
+// Rollover rolls the write aliases over to new indices when the conditions are met,
+// and adds the new indices to the read aliases.
+func Rollover(c client.Client, cfg rollover.Config, options Options) (*Result, error) {
+	return run(ActionRollover, c, cfg.Config, options, func(c client.Client) app.Action {
+		return &rollover.Action{
+			IndicesClient: &client.IndicesClient{Client: c, MasterTimeoutSeconds: cfg.Timeout},
+			ClusterClient: &client.ClusterClient{Client: c},
+			Config:        cfg,
+		}
+	})
+}
+
+// Lookback removes the indices older than the lookback period from the read aliases.
+func Lookback(c client.Client, cfg lookback.Config, options Options) (*Result, error) {
+	return run(ActionLookback, c, cfg.Config, options, func(c client.Client) app.Action {
+		return &lookback.Action{
+			IndicesClient: &client.IndicesClient{Client: c, MasterTimeoutSeconds: cfg.Timeout},
+			Config:        cfg,
+			Logger:        options.logger(),
+		}
+	})
+}
+
+type actionMetrics struct {
+	succeeded metrics.Counter
+	failed    metrics.Counter
+	calls     metrics.Counter
+	duration  metrics.Timer
+}
+
+func newActionMetrics(factory metrics.Factory, action string) actionMetrics {
+	f := factory.Namespace(metrics.NSOptions{Name: "es_rollover", Tags: map[string]string{"action": action}})
+	return actionMetrics{
+		succeeded: f.Counter(metrics.Options{Name: "runs", Tags: map[string]string{"result": "ok"}}),
+		failed:    f.Counter(metrics.Options{Name: "runs", Tags: map[string]string{"result": "err"}}),
+		calls:     f.Counter(metrics.Options{Name: "api_calls"}),
+		duration:  f.Timer(metrics.TimerOptions{Name: "duration"}),
+	}
+}
+
+func (o Options) logger() *zap.Logger {
+	if o.Logger == nil {
+		return zap.NewNop()
+	}
+	return o.Logger
+}
+
+func run(action string, c client.Client, cfg app.Config, options Options, newAction func(client.Client) app.Action) (*Result, error) {
+	factory := options.MetricsFactory
+	if factory == nil {
+		factory = metrics.NullFactory
+	}
+	m := newActionMetrics(factory, action)
+	logger := options.logger().With(zap.String("action", action))
+
+	recorder := newCallRecorder(c.Client, cfg.DryRun)
+	c.Client = recorder.client
+	start := time.Now()
+	err := newAction(c).Do()
+	result := &Result{
+		Action:   action,
+		DryRun:   cfg.DryRun,
+		Calls:    recorder.recordedCalls(),
+		Duration: time.Since(start),
+	}
+	m.duration.Record(result.Duration)
+	m.calls.Inc(int64(len(result.Calls)))
+	if err != nil {
+		m.failed.Inc(1)
+		result.Error = err.Error()
+		logger.Error("Action failed", zap.Int("calls", len(result.Calls)), zap.Error(err))
+		return result, err
+	}
+	m.succeeded.Inc(1)
+	logger.Info("Action completed", zap.Int("calls", len(result.Calls)), zap.Bool("dry-run", cfg.DryRun))
+	return result, nil
+}
+
+// callRecorder records the calls modifying the cluster, which are not sent in dry-run.
+type callRecorder struct {
+	next   http.RoundTripper
+	dryRun bool
+	client *http.Client
+
+	mu    sync.Mutex
+	calls []APICall
+}
+
+func newCallRecorder(httpClient *http.Client, dryRun bool) *callRecorder {
+	r := &callRecorder{dryRun: dryRun}
+	recordingClient := &http.Client{}
+	if httpClient != nil {
+		*recordingClient = *httpClient
+	}
+	r.next = recordingClient.Transport
+	if r.next == nil {
+		r.next = http.DefaultTransport
+	}
+	recordingClient.Transport = r
+	r.client = recordingClient
+	return r
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *callRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return r.next.RoundTrip(req)
+	}
+	call := APICall{Method: req.Method, URI: req.URL.RequestURI()}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		call.Body = string(body)
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+	if r.dryRun {
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      req.Proto,
+			ProtoMajor: req.ProtoMajor,
+			ProtoMinor: req.ProtoMinor,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader("{}")),
+			Request:    req,
+		}, nil
+	}
+	return r.next.RoundTrip(req)
+}
+
+func (r *callRecorder) recordedCalls() []APICall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
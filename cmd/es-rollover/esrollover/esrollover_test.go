@@ -0,0 +1,177 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package esrollover
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/es-rollover/app"
+	initialize "github.com/jaegertracing/jaeger/cmd/es-rollover/app/init"
+	"github.com/jaegertracing/jaeger/cmd/es-rollover/app/lookback"
+	"github.com/jaegertracing/jaeger/cmd/es-rollover/app/rollover"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/es/client"
+)
+
+// fakeCluster serves the read-only calls of the actions and records the others.
+type fakeCluster struct {
+	*httptest.Server
+	indices  string
+	failPath string
+
+	mu       sync.Mutex
+	received []APICall
+}
+
+func newFakeCluster(t *testing.T, indices string) *fakeCluster {
+	f := &fakeCluster{indices: indices}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/":
+			w.Write([]byte(`{"version": {"number": "8.12.0"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/jaeger-*":
+			w.Write([]byte(f.indices))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"jaeger-ilm-policy": {}}`))
+		default:
+			body, _ := io.ReadAll(r.Body)
+			f.mu.Lock()
+			f.received = append(f.received, APICall{Method: r.Method, URI: r.URL.RequestURI(), Body: string(body)})
+			f.mu.Unlock()
+			if r.URL.Path == f.failPath {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeCluster) client() client.Client {
+	return client.Client{Endpoint: f.URL, Client: f.Server.Client()}
+}
+
+func (f *fakeCluster) receivedCalls() []APICall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.received
+}
+
+const writeIndices = `{
+	"jaeger-span-000001": {"aliases": {"jaeger-span-write": {}, "jaeger-span-read": {}}, "settings": {"index.creation_date": "1000"}},
+	"jaeger-service-000001": {"aliases": {"jaeger-service-read": {}}, "settings": {"index.creation_date": "1000"}}
+}`
+
+func rolloverConfig(dryRun bool) rollover.Config {
+	return rollover.Config{
+		Config:     app.Config{SkipDependencies: true, DryRun: dryRun, Timeout: 60},
+		Conditions: `{"max_age": "2d", "max_primary_shard_size": "50gb"}`,
+	}
+}
+
+var expectedRolloverCalls = []APICall{
+	{Method: http.MethodPost, URI: "/jaeger-span-write/_rollover/", Body: `{"conditions":{"max_age":"2d","max_primary_shard_size":"50gb"}}`},
+	{Method: http.MethodPost, URI: "/_aliases", Body: `{"actions":[{"add":{"alias":"jaeger-span-read","index":"jaeger-span-000001"}}]}`},
+	{Method: http.MethodPost, URI: "/jaeger-service-write/_rollover/", Body: `{"conditions":{"max_age":"2d","max_primary_shard_size":"50gb"}}`},
+}
+
+func TestRollover(t *testing.T) {
+	cluster := newFakeCluster(t, writeIndices)
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+
+	result, err := Rollover(cluster.client(), rolloverConfig(false), Options{MetricsFactory: metricsFactory})
+	require.NoError(t, err)
+	assert.Equal(t, ActionRollover, result.Action)
+	assert.False(t, result.DryRun)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, expectedRolloverCalls, result.Calls)
+	assert.Equal(t, expectedRolloverCalls, cluster.receivedCalls())
+
+	counters, _ := metricsFactory.Snapshot()
+	assert.Equal(t, map[string]int64{
+		"es_rollover.runs|action=rollover|result=ok": 1,
+		"es_rollover.api_calls|action=rollover":      3,
+	}, counters)
+}
+
+func TestRolloverDryRun(t *testing.T) {
+	cluster := newFakeCluster(t, writeIndices)
+
+	result, err := Rollover(cluster.client(), rolloverConfig(true), Options{})
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, expectedRolloverCalls, result.Calls)
+	assert.Empty(t, cluster.receivedCalls())
+	assert.Equal(t, "POST /jaeger-span-write/_rollover/\n"+expectedRolloverCalls[0].Body, result.Calls[0].String())
+}
+
+func TestRolloverFailure(t *testing.T) {
+	cluster := newFakeCluster(t, writeIndices)
+	cluster.failPath = "/jaeger-service-write/_rollover/"
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+
+	result, err := Rollover(cluster.client(), rolloverConfig(false), Options{MetricsFactory: metricsFactory})
+	require.ErrorContains(t, err, "failed to create rollover target: jaeger-service-write")
+	assert.Equal(t, err.Error(), result.Error)
+	// the failed call is the last one
+	assert.Equal(t, expectedRolloverCalls, result.Calls)
+
+	counters, _ := metricsFactory.Snapshot()
+	assert.Equal(t, map[string]int64{
+		"es_rollover.runs|action=rollover|result=err": 1,
+		"es_rollover.api_calls|action=rollover":       3,
+	}, counters)
+}
+
+func TestLookbackDryRun(t *testing.T) {
+	cluster := newFakeCluster(t, writeIndices)
+
+	result, err := Lookback(cluster.client(), lookback.Config{
+		Config:    app.Config{SkipDependencies: true, DryRun: true},
+		Unit:      "days",
+		UnitCount: 1,
+	}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, ActionLookback, result.Action)
+	assert.Equal(t, []APICall{
+		{Method: http.MethodPost, URI: "/_aliases", Body: `{"actions":[{"remove":{"alias":"jaeger-service-read","index":"jaeger-service-000001"}}]}`},
+	}, result.Calls)
+	assert.Empty(t, cluster.receivedCalls())
+}
+
+func TestInitDryRun(t *testing.T) {
+	cluster := newFakeCluster(t, `{}`)
+
+	result, err := Init(cluster.client(), initialize.Config{
+		Config: app.Config{SkipDependencies: true, DryRun: true, Timeout: 60},
+		Shards: 5,
+	}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, ActionInit, result.Action)
+	var calls []string
+	for _, call := range result.Calls {
+		calls = append(calls, call.Method+" "+call.URI)
+	}
+	assert.Equal(t, []string{
+		"PUT /_index_template/jaeger-span",
+		"PUT /jaeger-span-000001",
+		"POST /_aliases",
+		"PUT /_index_template/jaeger-service",
+		"PUT /jaeger-service-000001",
+		"POST /_aliases",
+	}, calls)
+	assert.True(t, strings.HasPrefix(result.Calls[0].Body, "{"))
+	assert.Empty(t, cluster.receivedCalls())
+}
@@ -16,6 +16,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -26,6 +27,7 @@ import (
 	initialize "github.com/jaegertracing/jaeger/cmd/es-rollover/app/init"
 	"github.com/jaegertracing/jaeger/cmd/es-rollover/app/lookback"
 	"github.com/jaegertracing/jaeger/cmd/es-rollover/app/rollover"
+	"github.com/jaegertracing/jaeger/cmd/es-rollover/esrollover"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/es/client"
@@ -42,6 +44,7 @@ func main() {
 	}
 
 	tlsFlags := tlscfg.ClientFlagsConfig{Prefix: "es"}
+	options := esrollover.Options{Logger: logger}
 
 	// Init command
 	initCfg := &initialize.Config{}
@@ -60,22 +63,9 @@ func main() {
 			}, func(c client.Client, cfg app.Config) app.Action {
 				initCfg.Config = cfg
 				initCfg.InitFromViper(v)
-				indicesClient := &client.IndicesClient{
-					Client:               c,
-					MasterTimeoutSeconds: initCfg.Timeout,
-				}
-				clusterClient := &client.ClusterClient{
-					Client: c,
-				}
-				ilmClient := &client.ILMClient{
-					Client: c,
-				}
-				return &initialize.Action{
-					IndicesClient: indicesClient,
-					ClusterClient: clusterClient,
-					ILMClient:     ilmClient,
-					Config:        *initCfg,
-				}
+				return app.ActionFunc(func() error {
+					return printDryRun(esrollover.Init(c, *initCfg, options))
+				})
 			})
 		},
 	}
@@ -98,15 +88,9 @@ func main() {
 			}, func(c client.Client, cfg app.Config) app.Action {
 				rolloverCfg.Config = cfg
 				rolloverCfg.InitFromViper(v)
-				indicesClient := &client.IndicesClient{
-					Client:               c,
-					MasterTimeoutSeconds: rolloverCfg.Timeout,
-				}
-
-				return &rollover.Action{
-					IndicesClient: indicesClient,
-					Config:        *rolloverCfg,
-				}
+				return app.ActionFunc(func() error {
+					return printDryRun(esrollover.Rollover(c, *rolloverCfg, options))
+				})
 			})
 		},
 	}
@@ -127,15 +111,9 @@ func main() {
 			}, func(c client.Client, cfg app.Config) app.Action {
 				lookbackCfg.Config = cfg
 				lookbackCfg.InitFromViper(v)
-				indicesClient := &client.IndicesClient{
-					Client:               c,
-					MasterTimeoutSeconds: lookbackCfg.Timeout,
-				}
-				return &lookback.Action{
-					IndicesClient: indicesClient,
-					Config:        lookbackCfg,
-					Logger:        logger,
-				}
+				return app.ActionFunc(func() error {
+					return printDryRun(esrollover.Lookback(c, lookbackCfg, options))
+				})
 			})
 		},
 	}
@@ -150,6 +128,16 @@ func main() {
 	}
 }
 
+// printDryRun prints the calls that would have been made by the action in dry-run
+func printDryRun(result *esrollover.Result, err error) error {
+	if result.DryRun {
+		for _, call := range result.Calls {
+			fmt.Println(call)
+		}
+	}
+	return err
+}
+
 func addSubCommand(v *viper.Viper, rootCmd, cmd *cobra.Command, addFlags func(*flag.FlagSet)) {
 	rootCmd.AddCommand(cmd)
 	config.AddFlags(
@@ -15,13 +15,17 @@
 package builder
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/nats-io/nats.go/jetstream"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/ingester/app"
 	"github.com/jaegertracing/jaeger/cmd/ingester/app/consumer"
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/natsconsumer"
 	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor"
 	kafkaConsumer "github.com/jaegertracing/jaeger/pkg/kafka/consumer"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
@@ -87,3 +91,52 @@ func CreateConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanWrit
 	}
 	return consumer.New(consumerParams)
 }
+
+// natsLagInterval is the interval of the consumer lag reports of the NATS consumer.
+const natsLagInterval = 10 * time.Second
+
+// CreateNATSConsumer creates a new span consumer for the ingester consuming from NATS JetStream.
+// The stream is created when missing, and the durable consumer is created or updated.
+func CreateNATSConsumer(logger *zap.Logger, metricsFactory metrics.Factory, spanWriter spanstore.Writer, options app.Options) (*natsconsumer.Consumer, error) {
+	conn, err := options.NATS.Connect(logger)
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), options.NATS.ConnectTimeout)
+	defer cancel()
+	if _, err := options.NATS.EnsureStream(ctx, js); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	jsConsumer, err := js.CreateOrUpdateConsumer(ctx, options.NATS.Stream, jetstream.ConsumerConfig{
+		Durable:       options.NATS.Durable,
+		FilterSubject: options.NATS.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       options.NATS.AckWait,
+		MaxDeliver:    options.NATS.MaxDeliver,
+		MaxAckPending: options.Parallelism,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create the NATS consumer %s: %w", options.NATS.Durable, err)
+	}
+
+	spanProcessor := processor.NewSpanProcessor(processor.SpanProcessorParams{
+		Writer:       spanWriter,
+		Unmarshaller: kafka.NewProtobufUnmarshaller(),
+	})
+	return natsconsumer.New(natsconsumer.Params{
+		Consumer:       jsConsumer,
+		Conn:           conn,
+		Processor:      spanProcessor,
+		Parallelism:    options.Parallelism,
+		LagInterval:    natsLagInterval,
+		MetricsFactory: metricsFactory,
+		Logger:         logger,
+	}), nil
+}
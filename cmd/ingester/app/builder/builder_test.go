@@ -0,0 +1,83 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package builder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/ingester/app"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/plugin/storage/nats"
+)
+
+func TestCreateNATSConsumer(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natsserver.RunServer(&opts)
+	defer func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	}()
+
+	// the collector side publishes the spans with the nats span storage
+	natsFactory := nats.NewFactory()
+	v, command := config.Viperize(natsFactory.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--nats.producer.url=" + srv.ClientURL()}))
+	natsFactory.InitFromViper(v, zap.NewNop())
+	require.NoError(t, natsFactory.Initialize(metrics.NullFactory, zap.NewNop()))
+	defer natsFactory.Close()
+	natsWriter, err := natsFactory.CreateSpanWriter()
+	require.NoError(t, err)
+	traceID := model.NewTraceID(0, 1)
+	require.NoError(t, natsWriter.WriteSpan(context.Background(), &model.Span{
+		TraceID:       traceID,
+		SpanID:        model.NewSpanID(1),
+		OperationName: "GET /orders",
+		StartTime:     time.Now(),
+		Process:       model.NewProcess("frontend", nil),
+	}))
+
+	options := app.Options{}
+	v, command = config.Viperize(app.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--ingester.source=nats",
+		"--nats.consumer.url=" + srv.ClientURL(),
+	}))
+	options.InitFromViper(v)
+	store := memory.NewStore()
+	consumer, err := CreateNATSConsumer(zap.NewNop(), metrics.NullFactory, store, options)
+	require.NoError(t, err)
+	require.NoError(t, consumer.Start())
+	defer consumer.Close()
+
+	assert.Eventually(t, func() bool {
+		trace, err := store.GetTrace(context.Background(), traceID)
+		return err == nil && len(trace.Spans) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCreateNATSConsumerConnectionError(t *testing.T) {
+	options := app.Options{}
+	v, command := config.Viperize(app.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--nats.consumer.url=nats://127.0.0.1:1",
+		"--nats.consumer.connect-timeout=1s",
+	}))
+	options.InitFromViper(v)
+	_, err := CreateNATSConsumer(zap.NewNop(), metrics.NullFactory, memory.NewStore(), options)
+	require.ErrorContains(t, err, "failed to connect to NATS")
+}
@@ -17,6 +17,7 @@ package app
 import (
 	"flag"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/kafka/auth"
 	kafkaConsumer "github.com/jaegertracing/jaeger/pkg/kafka/consumer"
 	"github.com/jaegertracing/jaeger/plugin/storage/kafka"
+	"github.com/jaegertracing/jaeger/plugin/storage/nats"
 )
 
 const (
@@ -33,6 +35,16 @@ const (
 	ConfigPrefix = "ingester"
 	// KafkaConsumerConfigPrefix is a prefix for the Kafka flags
 	KafkaConsumerConfigPrefix = "kafka.consumer"
+	// NATSConsumerConfigPrefix is a prefix for the NATS flags
+	NATSConsumerConfigPrefix = "nats.consumer"
+	// SuffixSource is a suffix for the source flag
+	SuffixSource = ".source"
+	// SuffixDurable is a suffix for the NATS durable consumer flag
+	SuffixDurable = ".durable"
+	// SuffixAckWait is a suffix for the NATS ack-wait flag
+	SuffixAckWait = ".ack-wait"
+	// SuffixMaxDeliver is a suffix for the NATS max-deliver flag
+	SuffixMaxDeliver = ".max-deliver"
 	// SuffixBrokers is a suffix for the brokers flag
 	SuffixBrokers = ".brokers"
 	// SuffixTopic is a suffix for the topic flag
@@ -71,6 +83,18 @@ const (
 	DefaultDeadlockInterval = time.Duration(0)
 	// DefaultFetchMaxMessageBytes is the default for kafka.consumer.fetch-max-message-bytes flag
 	DefaultFetchMaxMessageBytes = 1024 * 1024 // 1MB
+	// SourceKafka consumes the spans from Kafka
+	SourceKafka = "kafka"
+	// SourceNATS consumes the spans from NATS JetStream
+	SourceNATS = "nats"
+	// DefaultSource is the default source of the spans
+	DefaultSource = SourceKafka
+	// DefaultDurable is the default name of the NATS durable consumer
+	DefaultDurable = "jaeger-ingester"
+	// DefaultAckWait is the default time NATS waits for the ack of a message before redelivering it
+	DefaultAckWait = 30 * time.Second
+	// DefaultMaxDeliver is the default maximum number of deliveries of a message by NATS
+	DefaultMaxDeliver = 10
 )
 
 // Options stores the configuration options for the Ingester
@@ -79,6 +103,16 @@ type Options struct {
 	Parallelism                 int           `mapstructure:"parallelism"`
 	Encoding                    string        `mapstructure:"encoding"`
 	DeadlockInterval            time.Duration `mapstructure:"deadlock_interval"`
+	Source                      string        `mapstructure:"source"`
+	NATS                        NATSOptions   `mapstructure:"nats"`
+}
+
+// NATSOptions stores the configuration options of the NATS JetStream consumer
+type NATSOptions struct {
+	nats.Options `mapstructure:",squash"`
+	Durable      string        `mapstructure:"durable"`
+	AckWait      time.Duration `mapstructure:"ack_wait"`
+	MaxDeliver   int           `mapstructure:"max_deliver"`
 }
 
 // AddFlags adds flags for Builder
@@ -91,6 +125,10 @@ func AddFlags(flagSet *flag.FlagSet) {
 		ConfigPrefix+SuffixDeadlockInterval,
 		DefaultDeadlockInterval,
 		"Interval to check for deadlocks. If no messages gets processed in given time, ingester app will exit. Value of 0 disables deadlock check.")
+	flagSet.String(
+		ConfigPrefix+SuffixSource,
+		DefaultSource,
+		fmt.Sprintf(`The message broker the spans are consumed from ("%s" or "%s")`, SourceKafka, SourceNATS))
 
 	// Authentication flags
 	flagSet.String(
@@ -127,6 +165,20 @@ func AddFlags(flagSet *flag.FlagSet) {
 		"The maximum number of message bytes to fetch from the broker in a single request. So you must be sure this is at least as large as your largest message.")
 
 	auth.AddFlags(KafkaConsumerConfigPrefix, flagSet)
+
+	nats.AddFlagsWithPrefix(NATSConsumerConfigPrefix, flagSet)
+	flagSet.String(
+		NATSConsumerConfigPrefix+SuffixDurable,
+		DefaultDurable,
+		"The name of the NATS durable consumer, shared by the ingesters consuming on behalf of the same group")
+	flagSet.Duration(
+		NATSConsumerConfigPrefix+SuffixAckWait,
+		DefaultAckWait,
+		"The time NATS waits for the acknowledgement of a message before redelivering it")
+	flagSet.Int(
+		NATSConsumerConfigPrefix+SuffixMaxDeliver,
+		DefaultMaxDeliver,
+		"The maximum number of deliveries of a message whose span failed to be written with a transient error, e.g. a storage timeout, after which the message is dropped")
 }
 
 // InitFromViper initializes Builder with properties from viper
@@ -145,6 +197,14 @@ func (o *Options) InitFromViper(v *viper.Viper) {
 	authenticationOptions := auth.AuthenticationConfig{}
 	authenticationOptions.InitFromViper(KafkaConsumerConfigPrefix, v)
 	o.AuthenticationConfig = authenticationOptions
+
+	o.Source = v.GetString(ConfigPrefix + SuffixSource)
+	if err := o.NATS.InitFromViperWithPrefix(NATSConsumerConfigPrefix, v); err != nil {
+		log.Fatal(err)
+	}
+	o.NATS.Durable = v.GetString(NATSConsumerConfigPrefix + SuffixDurable)
+	o.NATS.AckWait = v.GetDuration(NATSConsumerConfigPrefix + SuffixAckWait)
+	o.NATS.MaxDeliver = v.GetInt(NATSConsumerConfigPrefix + SuffixMaxDeliver)
 }
 
 // stripWhiteSpace removes all whitespace characters from a string
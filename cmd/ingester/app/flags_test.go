@@ -27,6 +27,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/kafka/auth"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/plugin/storage/kafka"
+	"github.com/jaegertracing/jaeger/plugin/storage/nats"
 )
 
 func TestOptionsWithFlags(t *testing.T) {
@@ -113,6 +114,40 @@ func TestFlagDefaults(t *testing.T) {
 	assert.Equal(t, int32(DefaultFetchMaxMessageBytes), o.FetchMaxMessageBytes)
 	assert.Equal(t, DefaultEncoding, o.Encoding)
 	assert.Equal(t, DefaultDeadlockInterval, o.DeadlockInterval)
+	assert.Equal(t, DefaultSource, o.Source)
+	assert.Equal(t, nats.DefaultURL, o.NATS.URL)
+	assert.Equal(t, nats.DefaultStream, o.NATS.Stream)
+	assert.Equal(t, nats.DefaultSubject, o.NATS.Subject)
+	assert.Equal(t, DefaultDurable, o.NATS.Durable)
+	assert.Equal(t, DefaultAckWait, o.NATS.AckWait)
+	assert.Equal(t, DefaultMaxDeliver, o.NATS.MaxDeliver)
+}
+
+func TestNATSOptionsWithFlags(t *testing.T) {
+	o := &Options{}
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--ingester.source=nats",
+		"--nats.consumer.url=nats://nats:4222",
+		"--nats.consumer.stream=spans",
+		"--nats.consumer.subject=spans.in",
+		"--nats.consumer.creds-file=/etc/nats/jaeger.creds",
+		"--nats.consumer.durable=ingesters",
+		"--nats.consumer.ack-wait=1m",
+		"--nats.consumer.max-deliver=5",
+		"--nats.consumer.tls.enabled=true",
+	})
+	o.InitFromViper(v)
+
+	assert.Equal(t, SourceNATS, o.Source)
+	assert.Equal(t, "nats://nats:4222", o.NATS.URL)
+	assert.Equal(t, "spans", o.NATS.Stream)
+	assert.Equal(t, "spans.in", o.NATS.Subject)
+	assert.Equal(t, "/etc/nats/jaeger.creds", o.NATS.CredsFile)
+	assert.Equal(t, "ingesters", o.NATS.Durable)
+	assert.Equal(t, time.Minute, o.NATS.AckWait)
+	assert.Equal(t, 5, o.NATS.MaxDeliver)
+	assert.True(t, o.NATS.TLS.Enabled)
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,227 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package natsconsumer consumes the spans published to NATS JetStream with a durable consumer.
+package natsconsumer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+const (
+	consumerNamespace = "nats-consumer"
+
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// Params are the parameters of a Consumer
+type Params struct {
+	// Consumer is the durable JetStream consumer of the spans, with explicit acks.
+	Consumer jetstream.Consumer
+	// Conn is the connection of the consumer, closed with it.
+	Conn        *natsio.Conn
+	Processor   processor.SpanProcessor
+	Parallelism int
+	LagInterval time.Duration
+	// MinBackoff is the delay of the first redelivery of a message whose span failed to be written
+	// with a transient error, doubled on each redelivery up to MaxBackoff.
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+	MetricsFactory metrics.Factory
+	Logger         *zap.Logger
+}
+
+type consumerMetrics struct {
+	messages      metrics.Counter
+	errors        metrics.Counter
+	dropped       metrics.Counter
+	lag           metrics.Gauge
+	ackPending    metrics.Gauge
+	lagInfoErrors metrics.Counter
+}
+
+// Consumer processes the messages of a JetStream consumer, acknowledging each one once its span has been
+// written. The messages whose span failed to be written with a transient error are redelivered after
+// a backoff, up to the MaxDeliver of the consumer, the other failed messages are dropped.
+type Consumer struct {
+	consumer    jetstream.Consumer
+	conn        *natsio.Conn
+	processor   processor.SpanProcessor
+	parallelism chan struct{}
+	lagInterval time.Duration
+	maxDeliver  int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	metrics     consumerMetrics
+	logger      *zap.Logger
+
+	consumeContext jetstream.ConsumeContext
+	inFlight       sync.WaitGroup
+	stopLag        chan struct{}
+	lagDone        sync.WaitGroup
+}
+
+// New creates a Consumer
+func New(params Params) *Consumer {
+	info := params.Consumer.CachedInfo()
+	f := params.MetricsFactory.Namespace(metrics.NSOptions{
+		Name: consumerNamespace,
+		Tags: map[string]string{"stream": info.Stream, "consumer": info.Name},
+	})
+	parallelism := params.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	minBackoff, maxBackoff := params.MinBackoff, params.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = max(defaultMaxBackoff, minBackoff)
+	}
+	return &Consumer{
+		consumer:    params.Consumer,
+		conn:        params.Conn,
+		processor:   params.Processor,
+		parallelism: make(chan struct{}, parallelism),
+		lagInterval: params.LagInterval,
+		maxDeliver:  info.Config.MaxDeliver,
+		minBackoff:  minBackoff,
+		maxBackoff:  maxBackoff,
+		metrics: consumerMetrics{
+			messages:      f.Counter(metrics.Options{Name: "messages"}),
+			errors:        f.Counter(metrics.Options{Name: "errors"}),
+			dropped:       f.Counter(metrics.Options{Name: "dropped"}),
+			lag:           f.Gauge(metrics.Options{Name: "lag"}),
+			ackPending:    f.Gauge(metrics.Options{Name: "ack-pending"}),
+			lagInfoErrors: f.Counter(metrics.Options{Name: "lag-errors"}),
+		},
+		logger:  params.Logger,
+		stopLag: make(chan struct{}),
+	}
+}
+
+// Start begins consuming the messages
+func (c *Consumer) Start() error {
+	consumeContext, err := c.consumer.Consume(c.handle)
+	if err != nil {
+		return err
+	}
+	c.consumeContext = consumeContext
+	if c.lagInterval > 0 {
+		c.lagDone.Add(1)
+		go c.reportLag()
+	}
+	return nil
+}
+
+// Close stops consuming, waiting for the messages being processed
+func (c *Consumer) Close() error {
+	if c.consumeContext != nil {
+		c.consumeContext.Stop()
+	}
+	close(c.stopLag)
+	c.lagDone.Wait()
+	c.inFlight.Wait()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// handle processes the messages concurrently, up to the parallelism.
+func (c *Consumer) handle(msg jetstream.Msg) {
+	c.parallelism <- struct{}{}
+	c.inFlight.Add(1)
+	go func() {
+		defer func() {
+			<-c.parallelism
+			c.inFlight.Done()
+		}()
+		c.metrics.messages.Inc(1)
+		if err := c.processor.Process(message{msg}); err != nil {
+			c.metrics.errors.Inc(1)
+			c.handleError(msg, err)
+			return
+		}
+		if err := msg.Ack(); err != nil {
+			c.logger.Error("Failed to acknowledge the message", zap.Error(err))
+		}
+	}()
+}
+
+// handleError redelivers the message after a backoff if its span failed to be written with a transient error,
+// like a storage timeout, and it has not been delivered MaxDeliver times yet. The other messages are terminated,
+// so that the messages which cannot be unmarshalled or written are not redelivered.
+func (c *Consumer) handleError(msg jetstream.Msg, err error) {
+	delivered := uint64(1)
+	if metadata, metadataErr := msg.Metadata(); metadataErr == nil {
+		delivered = metadata.NumDelivered
+	}
+	if storage.IsTransientError(err) && (c.maxDeliver <= 0 || delivered < uint64(c.maxDeliver)) {
+		c.logger.Warn("Failed to process the message, it will be redelivered",
+			zap.Uint64("deliveries", delivered), zap.Error(err))
+		if err := msg.NakWithDelay(c.backoff(delivered)); err != nil {
+			c.logger.Error("Failed to negatively acknowledge the message", zap.Error(err))
+		}
+		return
+	}
+	c.metrics.dropped.Inc(1)
+	c.logger.Error("Failed to process the message, dropping it", zap.Uint64("deliveries", delivered), zap.Error(err))
+	if err := msg.Term(); err != nil {
+		c.logger.Error("Failed to terminate the message", zap.Error(err))
+	}
+}
+
+// backoff returns the delay of the redelivery of a message delivered the given number of times.
+func (c *Consumer) backoff(delivered uint64) time.Duration {
+	backoff := c.minBackoff
+	for i := uint64(1); i < delivered && backoff < c.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, c.maxBackoff)
+}
+
+// reportLag reports the number of messages not delivered yet, and not acknowledged yet, from the consumer info.
+func (c *Consumer) reportLag() {
+	defer c.lagDone.Done()
+	ticker := time.NewTicker(c.lagInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.lagInterval)
+			info, err := c.consumer.Info(ctx)
+			cancel()
+			if err != nil {
+				c.metrics.lagInfoErrors.Inc(1)
+				c.logger.Warn("Failed to get the consumer info", zap.Error(err))
+				continue
+			}
+			c.metrics.lag.Update(int64(info.NumPending))
+			c.metrics.ackPending.Update(int64(info.NumAckPending))
+		case <-c.stopLag:
+			return
+		}
+	}
+}
+
+// message adapts a JetStream message to processor.Message.
+type message struct {
+	msg jetstream.Msg
+}
+
+func (m message) Value() []byte {
+	return m.msg.Data()
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package natsconsumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/ingester/app/processor"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+)
+
+const (
+	testStream  = "spans"
+	testSubject = "spans.in"
+)
+
+// fakeProcessor records the messages processed, failing the first ones with a transient error,
+// and failing the poison messages as if they could not be unmarshalled.
+type fakeProcessor struct {
+	sync.Mutex
+	failures  int
+	poison    string
+	attempts  int
+	processed []string
+}
+
+func (p *fakeProcessor) Process(msg processor.Message) error {
+	p.Lock()
+	defer p.Unlock()
+	p.attempts++
+	if string(msg.Value()) == p.poison {
+		return fmt.Errorf("cannot unmarshall byte array into span: %w", errors.New("unexpected EOF"))
+	}
+	if p.failures > 0 {
+		p.failures--
+		return fmt.Errorf("storage unavailable: %w", context.DeadlineExceeded)
+	}
+	p.processed = append(p.processed, string(msg.Value()))
+	return nil
+}
+
+func (*fakeProcessor) Close() error {
+	return nil
+}
+
+func (p *fakeProcessor) getAttempts() int {
+	p.Lock()
+	defer p.Unlock()
+	return p.attempts
+}
+
+func (p *fakeProcessor) getProcessed() []string {
+	p.Lock()
+	defer p.Unlock()
+	return append([]string(nil), p.processed...)
+}
+
+type consumerTest struct {
+	conn     *natsio.Conn
+	js       jetstream.JetStream
+	consumer jetstream.Consumer
+}
+
+func setUp(t *testing.T, maxDeliver int) *consumerTest {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natsserver.RunServer(&opts)
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+
+	conn, err := natsio.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: testStream, Subjects: []string{testSubject}})
+	require.NoError(t, err)
+	consumer, err := js.CreateOrUpdateConsumer(ctx, testStream, jetstream.ConsumerConfig{
+		Durable:    "ingester",
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		AckWait:    time.Second,
+		MaxDeliver: maxDeliver,
+	})
+	require.NoError(t, err)
+	return &consumerTest{conn: conn, js: js, consumer: consumer}
+}
+
+func (ct *consumerTest) publish(t *testing.T, values ...string) {
+	for _, value := range values {
+		_, err := ct.js.Publish(context.Background(), testSubject, []byte(value))
+		require.NoError(t, err)
+	}
+}
+
+// idle tells whether the consumer has no message pending nor waiting for an ack. The info is read
+// through another handle of the consumer, the Info of a handle not being safe for concurrent use.
+func (ct *consumerTest) idle() bool {
+	consumer, err := ct.js.Consumer(context.Background(), testStream, "ingester")
+	if err != nil {
+		return false
+	}
+	info, err := consumer.Info(context.Background())
+	return err == nil && info.NumAckPending == 0 && info.NumPending == 0
+}
+
+func TestConsumer(t *testing.T) {
+	ct := setUp(t, 0)
+	ct.publish(t, "span-1", "span-2", "span-3")
+
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	spanProcessor := &fakeProcessor{}
+	consumer := New(Params{
+		Consumer:       ct.consumer,
+		Processor:      spanProcessor,
+		Parallelism:    2,
+		LagInterval:    10 * time.Millisecond,
+		MetricsFactory: metricsFactory,
+		Logger:         zap.NewNop(),
+	})
+	require.NoError(t, consumer.Start())
+
+	assert.Eventually(t, func() bool {
+		return len(spanProcessor.getProcessed()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{"span-1", "span-2", "span-3"}, spanProcessor.getProcessed())
+
+	// the messages are acknowledged, so that the consumer has nothing pending
+	assert.Eventually(t, ct.idle, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, consumer.Close())
+
+	tags := map[string]string{"stream": testStream, "consumer": "ingester"}
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "nats-consumer.messages", Tags: tags, Value: 3},
+		metricstest.ExpectedMetric{Name: "nats-consumer.errors", Tags: tags, Value: 0},
+	)
+	_, gauges := metricsFactory.Snapshot()
+	assert.Contains(t, gauges, "nats-consumer.lag|consumer=ingester|stream=spans")
+}
+
+func TestConsumerRedeliversFailedMessages(t *testing.T) {
+	ct := setUp(t, 0)
+	ct.publish(t, "span-1")
+
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	spanProcessor := &fakeProcessor{failures: 1}
+	consumer := New(Params{
+		Consumer:       ct.consumer,
+		Processor:      spanProcessor,
+		MinBackoff:     10 * time.Millisecond,
+		MetricsFactory: metricsFactory,
+		Logger:         zap.NewNop(),
+	})
+	require.NoError(t, consumer.Start())
+
+	assert.Eventually(t, func() bool {
+		return len(spanProcessor.getProcessed()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, consumer.Close())
+
+	tags := map[string]string{"stream": testStream, "consumer": "ingester"}
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "nats-consumer.messages", Tags: tags, Value: 2},
+		metricstest.ExpectedMetric{Name: "nats-consumer.errors", Tags: tags, Value: 1},
+		metricstest.ExpectedMetric{Name: "nats-consumer.dropped", Tags: tags, Value: 0},
+	)
+}
+
+func TestConsumerTerminatesPoisonMessages(t *testing.T) {
+	ct := setUp(t, 0)
+	ct.publish(t, "poison", "span-1")
+
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	spanProcessor := &fakeProcessor{poison: "poison"}
+	consumer := New(Params{
+		Consumer:       ct.consumer,
+		Processor:      spanProcessor,
+		MinBackoff:     10 * time.Millisecond,
+		MetricsFactory: metricsFactory,
+		Logger:         zap.NewNop(),
+	})
+	require.NoError(t, consumer.Start())
+
+	// the poison message is terminated, not redelivered
+	assert.Eventually(t, ct.idle, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, consumer.Close())
+	assert.Equal(t, []string{"span-1"}, spanProcessor.getProcessed())
+	assert.Equal(t, 2, spanProcessor.getAttempts())
+
+	tags := map[string]string{"stream": testStream, "consumer": "ingester"}
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "nats-consumer.messages", Tags: tags, Value: 2},
+		metricstest.ExpectedMetric{Name: "nats-consumer.errors", Tags: tags, Value: 1},
+		metricstest.ExpectedMetric{Name: "nats-consumer.dropped", Tags: tags, Value: 1},
+	)
+}
+
+func TestConsumerDropsMessagesAfterMaxDeliver(t *testing.T) {
+	ct := setUp(t, 3)
+	ct.publish(t, "span-1")
+
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	spanProcessor := &fakeProcessor{failures: 5}
+	consumer := New(Params{
+		Consumer:       ct.consumer,
+		Processor:      spanProcessor,
+		MinBackoff:     10 * time.Millisecond,
+		MetricsFactory: metricsFactory,
+		Logger:         zap.NewNop(),
+	})
+	require.NoError(t, consumer.Start())
+
+	assert.Eventually(t, func() bool {
+		return spanProcessor.getAttempts() == 3 && ct.idle()
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, consumer.Close())
+	assert.Empty(t, spanProcessor.getProcessed())
+
+	tags := map[string]string{"stream": testStream, "consumer": "ingester"}
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "nats-consumer.errors", Tags: tags, Value: 3},
+		metricstest.ExpectedMetric{Name: "nats-consumer.dropped", Tags: tags, Value: 1},
+	)
+}
+
+func TestConsumerBackoff(t *testing.T) {
+	c := &Consumer{minBackoff: time.Second, maxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, c.backoff(1))
+	assert.Equal(t, 2*time.Second, c.backoff(2))
+	assert.Equal(t, 4*time.Second, c.backoff(3))
+	assert.Equal(t, 5*time.Second, c.backoff(4))
+	assert.Equal(t, 5*time.Second, c.backoff(100))
+}
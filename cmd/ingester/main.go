@@ -50,8 +50,8 @@ func main() {
 	v := viper.New()
 	command := &cobra.Command{
 		Use:   "jaeger-ingester",
-		Short: "Jaeger ingester consumes from Kafka and writes to storage.",
-		Long:  `Jaeger ingester consumes spans from a particular Kafka topic and writes them to a configured storage.`,
+		Short: "Jaeger ingester consumes from Kafka or NATS and writes to storage.",
+		Long:  `Jaeger ingester consumes spans from a particular Kafka topic or NATS JetStream stream and writes them to a configured storage.`,
 		RunE: func(_ *cobra.Command, _ /* args */ []string) error {
 			if err := svc.Start(v); err != nil {
 				return err
@@ -72,16 +72,35 @@ func main() {
 
 			options := app.Options{}
 			options.InitFromViper(v)
-			consumer, err := builder.CreateConsumer(logger, metricsFactory, spanWriter, options)
-			if err != nil {
-				logger.Fatal("Unable to create consumer", zap.Error(err))
+			var consumer io.Closer
+			switch options.Source {
+			case app.SourceNATS:
+				natsConsumer, err := builder.CreateNATSConsumer(logger, metricsFactory, spanWriter, options)
+				if err != nil {
+					logger.Fatal("Unable to create consumer", zap.Error(err))
+				}
+				if err := natsConsumer.Start(); err != nil {
+					logger.Fatal("Unable to start consumer", zap.Error(err))
+				}
+				consumer = natsConsumer
+			case app.SourceKafka:
+				kafkaConsumer, err := builder.CreateConsumer(logger, metricsFactory, spanWriter, options)
+				if err != nil {
+					logger.Fatal("Unable to create consumer", zap.Error(err))
+				}
+				kafkaConsumer.Start()
+				consumer = kafkaConsumer
+			default:
+				logger.Fatal("Unknown source of the spans", zap.String("source", options.Source))
 			}
-			consumer.Start()
 
 			svc.RunAndThen(func() {
 				if err := options.TLS.Close(); err != nil {
 					logger.Error("Failed to close TLS certificates watcher", zap.Error(err))
 				}
+				if err := options.NATS.TLS.Close(); err != nil {
+					logger.Error("Failed to close NATS TLS certificates watcher", zap.Error(err))
+				}
 				if err = consumer.Close(); err != nil {
 					logger.Error("Failed to close consumer", zap.Error(err))
 				}
@@ -41,9 +41,10 @@ The following configuration options are only available via environment variables
 `
 	storageTypeDescription = `The type of backend [%s] used for trace storage.
 Multiple backends can be specified as comma-separated list, e.g. "cassandra,elasticsearch"
-(currently only for writing spans). Note that "kafka" is only valid in jaeger-collector;
-it is not a replacement for a proper storage backend, and only used as a buffer for spans
-when Jaeger is deployed in the collector+ingester configuration.
+(currently only for writing spans). Note that "kafka" and "nats" are only valid in jaeger-collector;
+they are not a replacement for a proper storage backend, and only used as a buffer for spans
+when Jaeger is deployed in the collector+ingester configuration. Conversely, "file" is read-only
+and only valid in jaeger-query, serving the traces of OTLP or Jaeger JSON export files.
 `
 
 	samplingTypeDescription = `The method [%s] used for determining the sampling rates served
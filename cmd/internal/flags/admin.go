@@ -37,7 +37,22 @@ import (
 )
 
 const (
-	adminHTTPHostPort = "admin.http.host-port"
+	adminHTTPHostPort     = "admin.http.host-port"
+	adminHTTPLoopbackOnly = "admin.http.loopback-only"
+
+	logLevelRoute = "/loglevel"
+)
+
+// LoopbackOnlyMode tells how the admin server reacts to a host-port not restricted to the loopback interface.
+type LoopbackOnlyMode string
+
+const (
+	// LoopbackOnlyDisabled accepts any host-port for the admin server.
+	LoopbackOnlyDisabled LoopbackOnlyMode = "disabled"
+	// LoopbackOnlyWarn logs a warning when the admin server is not bound to the loopback interface.
+	LoopbackOnlyWarn LoopbackOnlyMode = "warn"
+	// LoopbackOnlyStrict refuses to start the admin server when it is not bound to the loopback interface.
+	LoopbackOnlyStrict LoopbackOnlyMode = "strict"
 )
 
 var tlsAdminHTTPFlagsConfig = tlscfg.ServerFlagsConfig{
@@ -53,6 +68,7 @@ type AdminServer struct {
 	server               *http.Server
 	tlsCfg               *tls.Config
 	tlsCertWatcherCloser io.Closer
+	logLevel             *zap.AtomicLevel
 }
 
 // NewAdminServer creates a new admin server.
@@ -76,9 +92,17 @@ func (s *AdminServer) setLogger(logger *zap.Logger) {
 	s.hc.SetLogger(logger)
 }
 
+// setLogLevel enables the /loglevel endpoint, to get and set the level of the logger at runtime.
+func (s *AdminServer) setLogLevel(level zap.AtomicLevel) {
+	s.logLevel = &level
+}
+
 // AddFlags registers CLI flags.
 func (s *AdminServer) AddFlags(flagSet *flag.FlagSet) {
 	flagSet.String(adminHTTPHostPort, s.adminHostPort, fmt.Sprintf("The host:port (e.g. 127.0.0.1%s or %s) for the admin server, including health check, /metrics, etc.", s.adminHostPort, s.adminHostPort))
+	flagSet.String(adminHTTPLoopbackOnly, string(LoopbackOnlyDisabled), fmt.Sprintf(
+		"Whether the admin server must only be bound to the loopback interface, a guardrail against exposing it: %s, %s to log a warning if it is not, or %s to refuse to start",
+		LoopbackOnlyDisabled, LoopbackOnlyWarn, LoopbackOnlyStrict))
 	tlsAdminHTTPFlagsConfig.AddFlags(flagSet)
 }
 
@@ -87,6 +111,9 @@ func (s *AdminServer) initFromViper(v *viper.Viper, logger *zap.Logger) error {
 	s.setLogger(logger)
 
 	s.adminHostPort = v.GetString(adminHTTPHostPort)
+	if err := s.checkLoopbackOnly(LoopbackOnlyMode(v.GetString(adminHTTPLoopbackOnly))); err != nil {
+		return err
+	}
 	var tlsAdminHTTP tlscfg.Options
 	tlsAdminHTTP, err := tlsAdminHTTPFlagsConfig.InitFromViper(v)
 	if err != nil {
@@ -105,6 +132,41 @@ func (s *AdminServer) initFromViper(v *viper.Viper, logger *zap.Logger) error {
 	return nil
 }
 
+// checkLoopbackOnly warns about, or refuses, a host-port of the admin server not restricted to the loopback interface.
+func (s *AdminServer) checkLoopbackOnly(mode LoopbackOnlyMode) error {
+	switch mode {
+	case LoopbackOnlyDisabled:
+		return nil
+	case LoopbackOnlyWarn, LoopbackOnlyStrict:
+	default:
+		return fmt.Errorf("invalid %s mode %q, expected %s, %s or %s", adminHTTPLoopbackOnly, mode, LoopbackOnlyDisabled, LoopbackOnlyWarn, LoopbackOnlyStrict)
+	}
+	if isLoopbackHostPort(s.adminHostPort) {
+		return nil
+	}
+	if mode == LoopbackOnlyStrict {
+		return fmt.Errorf("the admin server host-port %q is not restricted to the loopback interface, as required by --%s=%s",
+			s.adminHostPort, adminHTTPLoopbackOnly, mode)
+	}
+	s.logger.Warn("The admin server is not restricted to the loopback interface, its endpoints may be exposed",
+		zap.String("http.host-port", s.adminHostPort), zap.String(adminHTTPLoopbackOnly, string(mode)))
+	return nil
+}
+
+// isLoopbackHostPort returns whether the host of the host-port is a loopback IP address or localhost.
+// An empty host binds all the interfaces, and the other host names are not resolved.
+func isLoopbackHostPort(hostPort string) bool {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // Handle adds a new handler to the admin server.
 func (s *AdminServer) Handle(path string, handler http.Handler) {
 	s.mux.Handle(path, handler)
@@ -131,6 +193,10 @@ func (s *AdminServer) serveWithListener(l net.Listener) {
 	s.mux.Handle("/", s.hc.Handler())
 	version.RegisterHandler(s.mux, s.logger)
 	s.registerPprofHandlers()
+	if s.logLevel != nil {
+		s.logger.Info("Mounting log level handler on admin server", zap.String("route", logLevelRoute))
+		s.mux.Handle(logLevelRoute, s.logLevelHandler())
+	}
 	recoveryHandler := recoveryhandler.NewRecoveryHandler(s.logger, true)
 	errorLog, _ := zap.NewStdLogAt(s.logger, zapcore.ErrorLevel)
 	s.server = &http.Server{
@@ -168,6 +234,32 @@ func (s *AdminServer) registerPprofHandlers() {
 	s.mux.Handle("/debug/pprof/block", pprof.Handler("block"))
 }
 
+// logLevelHandler serves the level of the logger with GET, and changes it with PUT, e.g. {"level":"debug"}.
+// The changes are only accepted from the clients with a certificate verified by the admin server,
+// which requires admin.http.tls.client-ca.
+func (s *AdminServer) logLevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			s.logLevel.ServeHTTP(w, r)
+			return
+		}
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "changing the log level requires a client certificate verified by the admin server, see --"+tlsAdminHTTPFlagsConfig.Prefix+".tls.client-ca", http.StatusForbidden)
+			return
+		}
+		previous := s.logLevel.Level()
+		s.logLevel.ServeHTTP(w, r)
+		if current := s.logLevel.Level(); current != previous {
+			s.logger.Info("Log level changed",
+				zap.Stringer("previous", previous),
+				zap.Stringer("level", current),
+				zap.String("subject", r.TLS.VerifiedChains[0][0].Subject.CommonName),
+				zap.String("remote_addr", r.RemoteAddr),
+			)
+		}
+	})
+}
+
 // Close stops the HTTP server
 func (s *AdminServer) Close() error {
 	return errors.Join(
@@ -17,6 +17,7 @@ package flags
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strconv"
@@ -110,6 +111,45 @@ func TestAdminWithFailedFlags(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to parse admin server TLS options")
 }
 
+func TestAdminLoopbackOnly(t *testing.T) {
+	testCases := []struct {
+		hostPort string
+		mode     string
+		warning  bool
+		err      string
+	}{
+		{hostPort: ":14269", mode: "disabled"},
+		{hostPort: "127.0.0.1:14269", mode: "warn"},
+		{hostPort: "[::1]:14269", mode: "strict"},
+		{hostPort: "localhost:14269", mode: "strict"},
+		{hostPort: ":14269", mode: "warn", warning: true},
+		{hostPort: "10.0.0.1:14269", mode: "warn", warning: true},
+		{hostPort: ":14269", mode: "strict", err: `the admin server host-port ":14269" is not restricted to the loopback interface, as required by --admin.http.loopback-only=strict`},
+		{hostPort: "0.0.0.0:14269", mode: "strict", err: "is not restricted to the loopback interface"},
+		{hostPort: "admin.example.com:14269", mode: "strict", err: "is not restricted to the loopback interface"},
+		{hostPort: "127.0.0.1:14269", mode: "always", err: `invalid admin.http.loopback-only mode "always"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.mode+" "+tc.hostPort, func(t *testing.T) {
+			adminServer := NewAdminServer(":0")
+			v, command := config.Viperize(adminServer.AddFlags)
+			require.NoError(t, command.ParseFlags([]string{
+				"--admin.http.host-port=" + tc.hostPort,
+				"--admin.http.loopback-only=" + tc.mode,
+			}))
+			zapCore, logs := observer.New(zap.InfoLevel)
+			err := adminServer.initFromViper(v, zap.New(zapCore))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			warnings := logs.FilterMessage("The admin server is not restricted to the loopback interface, its endpoints may be exposed")
+			assert.Equal(t, tc.warning, warnings.Len() == 1)
+		})
+	}
+}
+
 func TestAdminServerTLS(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -169,3 +209,109 @@ func TestAdminServerTLS(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminLogLevel(t *testing.T) {
+	adminServer := NewAdminServer(":0")
+	v, command := config.Viperize(adminServer.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--admin.http.tls.enabled=true",
+		"--admin.http.tls.cert=" + testCertKeyLocation + "/example-server-cert.pem",
+		"--admin.http.tls.key=" + testCertKeyLocation + "/example-server-key.pem",
+		"--admin.http.tls.client-ca=" + testCertKeyLocation + "/example-CA-cert.pem",
+		"--admin.http.tls.client-auth=request",
+	}))
+	logLevel := zap.NewAtomicLevelAt(zap.InfoLevel)
+	zapCore, logs := observer.New(logLevel)
+	logger := zap.New(zapCore)
+	require.NoError(t, adminServer.initFromViper(v, logger))
+	adminServer.setLogLevel(logLevel)
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	adminServer.serveWithListener(l)
+	defer adminServer.Close()
+	url := "https://" + l.Addr().String() + logLevelRoute
+
+	newClient := func(clientTLS tlscfg.Options) *http.Client {
+		clientTLSCfg, err := clientTLS.Config(zap.NewNop())
+		require.NoError(t, err)
+		t.Cleanup(func() { clientTLS.Close() })
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLSCfg}}
+	}
+	anonymousClient := newClient(tlscfg.Options{
+		CAPath:     testCertKeyLocation + "/example-CA-cert.pem",
+		ServerName: "example.com",
+	})
+	client := newClient(tlscfg.Options{
+		CAPath:     testCertKeyLocation + "/example-CA-cert.pem",
+		ServerName: "example.com",
+		CertPath:   testCertKeyLocation + "/example-client-cert.pem",
+		KeyPath:    testCertKeyLocation + "/example-client-key.pem",
+	})
+	putLevel := func(client *http.Client, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Close = true // avoid persistent connections which leak goroutines
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	logger.Debug("hidden debug message")
+	assert.Zero(t, logs.FilterMessage("hidden debug message").Len())
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Close = true
+	resp, err := anonymousClient.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"level":"info"}`, string(body))
+
+	resp = putLevel(anonymousClient, `{"level":"debug"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, zap.InfoLevel, logLevel.Level())
+
+	resp = putLevel(client, `{"level":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, zap.InfoLevel, logLevel.Level())
+
+	resp = putLevel(client, `{"level":"debug"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, zap.DebugLevel, logLevel.Level())
+	logger.Debug("visible debug message")
+	assert.Equal(t, 1, logs.FilterMessage("visible debug message").Len())
+
+	changes := logs.FilterMessage("Log level changed").All()
+	require.Len(t, changes, 1)
+	assert.Equal(t, "info", changes[0].ContextMap()["previous"])
+	assert.Equal(t, "debug", changes[0].ContextMap()["level"])
+	assert.Equal(t, "Jaeger", changes[0].ContextMap()["subject"])
+}
+
+func TestAdminLogLevelWithoutTLS(t *testing.T) {
+	adminServer := NewAdminServer(":0")
+	v, _ := config.Viperize(adminServer.AddFlags)
+	logLevel := zap.NewAtomicLevelAt(zap.InfoLevel)
+	require.NoError(t, adminServer.initFromViper(v, zap.NewNop()))
+	adminServer.setLogLevel(logLevel)
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	adminServer.serveWithListener(l)
+	defer adminServer.Close()
+
+	req, err := http.NewRequest(http.MethodPut, "http://"+l.Addr().String()+logLevelRoute, strings.NewReader(`{"level":"debug"}`))
+	require.NoError(t, err)
+	req.Close = true
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, zap.InfoLevel, logLevel.Level())
+}
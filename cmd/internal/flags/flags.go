@@ -124,15 +124,26 @@ func (flags *SharedFlags) InitFromViper(v *viper.Viper) *SharedFlags {
 
 // NewLogger returns logger based on configuration in SharedFlags
 func (flags *SharedFlags) NewLogger(conf zap.Config, options ...zap.Option) (*zap.Logger, error) {
+	logger, _, err := flags.NewLoggerWithLevel(conf, options...)
+	return logger, err
+}
+
+// NewLoggerWithLevel returns logger based on configuration in SharedFlags,
+// along with the level of the logger which can be changed at runtime.
+func (flags *SharedFlags) NewLoggerWithLevel(conf zap.Config, options ...zap.Option) (*zap.Logger, zap.AtomicLevel, error) {
 	var level zapcore.Level
 	err := (&level).UnmarshalText([]byte(flags.Logging.Level))
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
 	conf.Level = zap.NewAtomicLevelAt(level)
 	conf.Encoding = flags.Logging.Encoding
 	if flags.Logging.Encoding == "console" {
 		conf.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	}
-	return conf.Build(options...)
+	logger, err := conf.Build(options...)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
+	}
+	return logger, conf.Level, nil
 }
@@ -15,6 +15,7 @@
 package flags
 
 import (
+	"context"
 	"expvar"
 	"flag"
 	"fmt"
@@ -51,6 +52,7 @@ type Service struct {
 	MetricsFactory metrics.Factory
 
 	signalsChannel chan os.Signal
+	metricsBuilder *metricsbuilder.Builder
 }
 
 // NewService creates a new Service.
@@ -85,22 +87,27 @@ func (s *Service) Start(v *viper.Viper) error {
 	sFlags := new(SharedFlags).InitFromViper(v)
 	newProdConfig := zap.NewProductionConfig()
 	newProdConfig.Sampling = nil
-	logger, err := sFlags.NewLogger(newProdConfig)
+	logger, logLevel, err := sFlags.NewLoggerWithLevel(newProdConfig)
 	if err != nil {
 		return fmt.Errorf("cannot create logger: %w", err)
 	}
 	s.Logger = logger
+	s.Admin.setLogLevel(logLevel)
 	grpclog.SetLoggerV2(zapgrpc.NewLogger(
 		logger.WithOptions(
 			zap.AddCallerSkip(5), // ensure the actual caller:lineNo is shown
 		)))
 
-	metricsBuilder := new(metricsbuilder.Builder).InitFromViper(v)
+	metricsBuilder, err := new(metricsbuilder.Builder).InitFromViper(v, s.Logger)
+	if err != nil {
+		return fmt.Errorf("cannot initialize metrics builder: %w", err)
+	}
 	metricsFactory, err := metricsBuilder.CreateMetricsFactory("")
 	if err != nil {
 		return fmt.Errorf("cannot create metrics factory: %w", err)
 	}
 	s.MetricsFactory = metricsFactory
+	s.metricsBuilder = metricsBuilder
 
 	if err = s.Admin.initFromViper(v, s.Logger); err != nil {
 		return fmt.Errorf("cannot initialize admin server: %w", err)
@@ -143,6 +150,11 @@ func (s *Service) RunAndThen(shutdown func()) {
 		shutdown()
 	}
 
+	if s.metricsBuilder != nil {
+		if err := s.metricsBuilder.Shutdown(context.Background()); err != nil {
+			s.Logger.Error("Failed to shut down the metrics backend", zap.Error(err))
+		}
+	}
 	s.Admin.Close()
 	s.Logger.Info("Shutdown complete")
 }
@@ -85,6 +85,7 @@ func TestStartErrors(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
+			require.NotNil(t, s.Admin.logLevel, "the log level must be exposed by the admin server")
 
 			var stopped atomic.Bool
 			shutdown := func() {
@@ -87,8 +87,10 @@ func TestAllFlag(t *testing.T) {
 	expected := `-----------------------------------------------------------------
 | Configuration Option Name      Value            Source        |
 -----------------------------------------------------------------
+| multi-tenancy.config-file                       default       |
 | multi-tenancy.enabled          false            default       |
 | multi-tenancy.header           x-scope-orgid    user-assigned |
+| multi-tenancy.storage-prefix                    default       |
 | multi-tenancy.tenants                           default       |
 | test-plugin.binary             noop-test-plugin user-assigned |
 | test-plugin.configuration-file config.json      user-assigned |
@@ -97,8 +99,11 @@ func TestAllFlag(t *testing.T) {
 | test-remote.server                              default       |
 | test.tls.ca                                     default       |
 | test.tls.cert                                   default       |
+| test.tls.cipher-suites                          default       |
 | test.tls.enabled               false            default       |
 | test.tls.key                                    default       |
+| test.tls.max-version                            default       |
+| test.tls.min-version                            default       |
 | test.tls.server-name                            default       |
 | test.tls.skip-host-verify      false            default       |
 -----------------------------------------------------------------
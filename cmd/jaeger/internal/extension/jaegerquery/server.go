@@ -17,6 +17,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/plugin/metrics/disabled"
 	"github.com/jaegertracing/jaeger/ports"
@@ -32,6 +33,7 @@ type server struct {
 	logger  *zap.Logger
 	server  *queryApp.Server
 	jtracer *jtracer.JTracer
+	tm      *tenancy.Manager
 }
 
 func newServer(config *Config, otel component.TelemetrySettings) *server {
@@ -68,9 +70,16 @@ func (s *server) Start(_ context.Context, host component.Host) error {
 	if err := s.addArchiveStorage(&opts, host); err != nil {
 		return err
 	}
+	tm, err := tenancy.NewManagerWithConfigFile(&s.config.Tenancy, s.logger)
+	if err != nil {
+		return fmt.Errorf("cannot create the tenancy manager: %w", err)
+	}
+	s.tm = tm
+	opts.TenancyMgr = tm
+	opts.SearchGuardrails = s.config.SearchGuardrails
+	opts.SelfTracing = s.config.SelfTracing
 	qs := querysvc.NewQueryService(spanReader, depReader, opts)
 	metricsQueryService, _ := disabled.NewMetricsReader()
-	tm := tenancy.NewManager(&s.config.Tenancy)
 
 	// TODO OTel-collector does not initialize the tracer currently
 	// https://github.com/open-telemetry/opentelemetry-collector/issues/7532
@@ -86,6 +95,8 @@ func (s *server) Start(_ context.Context, host component.Host) error {
 		s.logger,
 		// TODO propagate healthcheck updates up to the collector's runtime
 		healthcheck.New(),
+		// TODO wire the collector's telemetry metrics once jaeger-query metrics are supported in v2
+		metrics.NullFactory,
 		qs,
 		metricsQueryService,
 		s.makeQueryOptions(),
@@ -138,5 +149,8 @@ func (s *server) Shutdown(ctx context.Context) error {
 	if s.jtracer != nil {
 		errs = append(errs, s.jtracer.Close(ctx))
 	}
+	if s.tm != nil {
+		errs = append(errs, s.tm.Close())
+	}
 	return errors.Join(errs...)
 }
@@ -8,9 +8,9 @@ import (
 	"errors"
 	"fmt"
 
-	otlp2jaeger "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
+	"github.com/jaegertracing/jaeger/internal/otlptranslator"
 	spanstore_v1 "github.com/jaegertracing/jaeger/storage/spanstore"
 	"github.com/jaegertracing/jaeger/storage_v2/spanstore"
 )
@@ -27,7 +27,7 @@ func NewTraceWriter(spanWriter spanstore_v1.Writer) spanstore.Writer {
 
 // WriteTraces implements spanstore.Writer.
 func (t *TraceWriter) WriteTraces(ctx context.Context, td ptrace.Traces) error {
-	batches, err := otlp2jaeger.ProtoFromTraces(td)
+	batches, err := otlptranslator.ProtoFromTraces(td)
 	if err != nil {
 		return fmt.Errorf("cannot transform OTLP traces to Jaeger format: %w", err)
 	}
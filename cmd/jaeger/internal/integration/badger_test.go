@@ -21,6 +21,7 @@ func TestBadgerStorage(t *testing.T) {
 			// TODO: remove this once badger supports returning spanKind from GetOperations
 			// Cf https://github.com/jaegertracing/jaeger/issues/1922
 			GetOperationsMissingSpanKind: true,
+			UnsupportedCapabilities:      []integration.Capability{integration.CapabilityOperationKind, integration.CapabilitySingleSpanTags},
 		},
 	}
 	s.e2eInitialize(t, "badger")
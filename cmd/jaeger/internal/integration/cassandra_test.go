@@ -18,7 +18,8 @@ func TestCassandraStorage(t *testing.T) {
 			GetDependenciesReturnsSource: true,
 			SkipArchiveTest:              true,
 
-			SkipList: integration.CassandraSkippedTests,
+			SkipList:                integration.CassandraSkippedTests,
+			UnsupportedCapabilities: integration.CassandraUnsupportedCapabilities,
 		},
 	}
 	s.e2eInitialize(t, "cassandra")
@@ -18,6 +18,7 @@ func TestESStorage(t *testing.T) {
 			CleanUp:                      purge,
 			Fixtures:                     integration.LoadAndParseQueryTestCases(t, "fixtures/queries_es.json"),
 			GetOperationsMissingSpanKind: true,
+			UnsupportedCapabilities:      []integration.Capability{integration.CapabilityOperationKind},
 		},
 	}
 	s.e2eInitialize(t, "elasticsearch")
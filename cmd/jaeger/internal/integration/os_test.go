@@ -17,6 +17,7 @@ func TestOSStorage(t *testing.T) {
 			CleanUp:                      purge,
 			Fixtures:                     integration.LoadAndParseQueryTestCases(t, "fixtures/queries_es.json"),
 			GetOperationsMissingSpanKind: true,
+			UnsupportedCapabilities:      []integration.Capability{integration.CapabilityOperationKind},
 		},
 	}
 	s.e2eInitialize(t, "opensearch")
@@ -8,13 +8,13 @@ import (
 	"fmt"
 	"time"
 
-	jaeger2otlp "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/jaeger/internal/extension/jaegerstorage"
+	"github.com/jaegertracing/jaeger/internal/otlptranslator"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
@@ -117,7 +117,7 @@ func (r *storageReceiver) consumeSpans(ctx context.Context, tc *consumedTrace, s
 	for _, span := range spans {
 		if _, ok := tc.spanIDs[span.SpanID]; !ok {
 			tc.spanIDs[span.SpanID] = struct{}{}
-			td, err := jaeger2otlp.ProtoToTraces([]*model.Batch{
+			td, err := otlptranslator.ProtoToTraces([]*model.Batch{
 				{
 					Spans:   []*model.Span{span},
 					Process: span.Process,
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// AdminTracesPath is the path prefix of the administrative trace routes, to register the handler
+// returned by NewAdminHandler on the admin server.
+const AdminTracesPath = "/" + defaultAPIPrefix + "/admin/traces/"
+
+// NewAdminHandler returns the handler of the administrative API of the query service,
+// such as DELETE /api/admin/traces/{trace-id}. It is meant to be served on the admin port,
+// which is not exposed to the users of the UI.
+func NewAdminHandler(
+	querySvc *querysvc.QueryService,
+	queryOpts *QueryOptions,
+	tm *tenancy.Manager,
+	tracer *jtracer.JTracer,
+	logger *zap.Logger,
+) http.Handler {
+	apiHandler := NewAPIHandler(
+		querySvc,
+		tm,
+		HandlerOptions.Logger(logger),
+		HandlerOptions.Tracer(tracer),
+	)
+	r := NewRouter()
+	apiHandler.RegisterAdminRoutes(r)
+	var handler http.Handler = r
+	if queryOpts.SubjectHeader != "" {
+		handler = subjectHandler(handler, queryOpts.SubjectHeader)
+	}
+	return handler
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func deleteTrace(t *testing.T, url string, header http.Header) *http.Response {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	req.Header = header
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestAdminDeleteTrace(t *testing.T) {
+	store := memory.NewStore()
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	for _, span := range mockTrace.Spans {
+		require.NoError(t, store.WriteSpan(ctx, span))
+	}
+	logger, logBuf := testutils.NewLogger()
+	qs := querysvc.NewQueryService(store, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	tm := tenancy.NewManager(&tenancy.Options{Enabled: true})
+	handler := NewAdminHandler(qs, &QueryOptions{QueryOptionsBase: QueryOptionsBase{SubjectHeader: "X-Subject"}}, tm, jtracer.NoOp(), logger)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	_, err := qs.GetTrace(ctx, mockTraceID)
+	require.NoError(t, err)
+
+	url := server.URL + AdminTracesPath + mockTraceID.String()
+	header := http.Header{}
+	header.Set(tm.Header, "acme")
+	header.Set("X-Subject", "alice")
+	// deleting twice succeeds
+	for i := 0; i < 2; i++ {
+		resp := deleteTrace(t, url, header)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	_, err = qs.GetTrace(ctx, mockTraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+
+	audit := logBuf.JSONLine(0)
+	assert.Equal(t, "Trace deleted", audit["msg"])
+	assert.Equal(t, mockTraceID.String(), audit["trace_id"])
+	assert.Equal(t, "alice", audit["subject"])
+	assert.Equal(t, "acme", audit["tenant"])
+	assert.NotEmpty(t, audit["remote_addr"])
+	assert.NotEmpty(t, audit["time"])
+
+	// the tenant is required
+	resp := deleteTrace(t, url, http.Header{})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// only DELETE is routed
+	resp, err = httpClient.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestAdminDeleteTraceErrors(t *testing.T) {
+	purgeErr := errors.New("storage error")
+	for _, tc := range []struct {
+		name           string
+		reader         spanstore.Reader
+		traceID        string
+		expectedStatus int
+	}{
+		{
+			name:           "bad trace ID",
+			reader:         &spanstoremocks.Reader{},
+			traceID:        "not-a-trace-id",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported",
+			reader:         &spanstoremocks.Reader{},
+			traceID:        mockTraceID.String(),
+			expectedStatus: http.StatusNotImplemented,
+		},
+		{
+			name: "storage error",
+			reader: func() spanstore.Reader {
+				reader := &spanstoremocks.Reader{}
+				reader.On("DeleteTrace", mock.Anything, mockTraceID).Return(purgeErr)
+				return traceDeleter{reader}
+			}(),
+			traceID:        mockTraceID.String(),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			qs := querysvc.NewQueryService(tc.reader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+			server := httptest.NewServer(NewAdminHandler(qs, &QueryOptions{}, &tenancy.Manager{}, jtracer.NoOp(), zap.NewNop()))
+			defer server.Close()
+
+			resp := deleteTrace(t, server.URL+AdminTracesPath+tc.traceID, http.Header{})
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+// traceDeleter is a span reader implementing spanstore.TraceDeleter.
+type traceDeleter struct {
+	*spanstoremocks.Reader
+}
+
+func (r traceDeleter) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	return r.Called(ctx, traceID).Error(0)
+}
@@ -16,15 +16,16 @@ package apiv3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/gogo/protobuf/types"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
-	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -38,15 +39,21 @@ var _ api_v3.QueryServiceServer = (*Handler)(nil)
 
 // GetTrace implements api_v3.QueryServiceServer's GetTrace
 func (h *Handler) GetTrace(request *api_v3.GetTraceRequest, stream api_v3.QueryService_GetTraceServer) error {
-	traceID, err := model.TraceIDFromString(request.GetTraceId())
+	traceID, err := querysvc.ParseTraceID(request.GetTraceId())
 	if err != nil {
 		return fmt.Errorf("malform trace ID: %w", err)
 	}
 
-	trace, err := h.QueryService.GetTrace(stream.Context(), traceID)
+	ctx := querysvc.ContextWithWarnings(stream.Context())
+	trace, err := h.QueryService.GetTrace(ctx, traceID)
 	if err != nil {
 		return fmt.Errorf("cannot retrieve trace: %w", err)
 	}
+	if warnings := querysvc.GetWarnings(ctx); len(warnings) > 0 {
+		if err := stream.SetHeader(metadata.MD{querysvc.WarningsMetadataKey: warnings}); err != nil {
+			return err
+		}
+	}
 	td, err := modelToOTLP(trace.GetSpans())
 	if err != nil {
 		return err
@@ -101,10 +108,19 @@ func (h *Handler) FindTraces(request *api_v3.FindTracesRequest, stream api_v3.Qu
 		queryParams.DurationMax = durationMax
 	}
 
-	traces, err := h.QueryService.FindTraces(stream.Context(), queryParams)
+	ctx := querysvc.ContextWithWarnings(stream.Context())
+	traces, err := h.QueryService.FindTraces(ctx, queryParams)
+	if errors.Is(err, querysvc.ErrSearchRejected) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return err
 	}
+	if warnings := querysvc.GetWarnings(ctx); len(warnings) > 0 {
+		if err := stream.SetHeader(metadata.MD{querysvc.WarningsMetadataKey: warnings}); err != nil {
+			return err
+		}
+	}
 	for _, t := range traces {
 		td, err := modelToOTLP(t.GetSpans())
 		if err != nil {
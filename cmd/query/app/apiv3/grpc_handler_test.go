@@ -19,13 +19,16 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
@@ -63,6 +66,10 @@ type testServerClient struct {
 }
 
 func newTestServerClient(t *testing.T) *testServerClient {
+	return newTestServerClientWithOptions(t, querysvc.QueryServiceOptions{})
+}
+
+func newTestServerClientWithOptions(t *testing.T, options querysvc.QueryServiceOptions) *testServerClient {
 	tsc := &testServerClient{
 		reader: &spanstoremocks.Reader{},
 	}
@@ -70,7 +77,7 @@ func newTestServerClient(t *testing.T) *testServerClient {
 	q := querysvc.NewQueryService(
 		tsc.reader,
 		&dependencyStoreMocks.Reader{},
-		querysvc.QueryServiceOptions{},
+		options,
 	)
 	h := &Handler{
 		QueryService: q,
@@ -176,6 +183,50 @@ func TestFindTraces(t *testing.T) {
 	require.EqualValues(t, 1, td.SpanCount())
 }
 
+func TestFindTracesGuardrails(t *testing.T) {
+	tsc := newTestServerClientWithOptions(t, querysvc.QueryServiceOptions{
+		SearchGuardrails: querysvc.SearchGuardrails{MaxLimit: 10},
+	})
+	tsc.reader.On("FindTraces", matchContext, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.NumTraces == 10
+	})).Return([]*model.Trace{{Spans: []*model.Span{{OperationName: "name"}}}}, nil).Once()
+
+	responseStream, err := tsc.client.FindTraces(context.Background(), &api_v3.FindTracesRequest{
+		Query: &api_v3.TraceQueryParameters{
+			StartTimeMin: &types.Timestamp{},
+			StartTimeMax: &types.Timestamp{},
+			NumTraces:    100,
+		},
+	})
+	require.NoError(t, err)
+	_, err = responseStream.Recv()
+	require.NoError(t, err)
+	header, err := responseStream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"search limit 100 reduced to the maximum of 10"}, header.Get(querysvc.WarningsMetadataKey))
+}
+
+func TestFindTracesGuardrailsRejection(t *testing.T) {
+	tsc := newTestServerClientWithOptions(t, querysvc.QueryServiceOptions{
+		SearchGuardrails: querysvc.SearchGuardrails{
+			MaxLookback:  time.Hour,
+			LookbackMode: querysvc.LookbackModeReject,
+		},
+	})
+	responseStream, err := tsc.client.FindTraces(context.Background(), &api_v3.FindTracesRequest{
+		Query: &api_v3.TraceQueryParameters{
+			StartTimeMin: &types.Timestamp{},
+			StartTimeMax: &types.Timestamp{Seconds: 86400},
+		},
+	})
+	require.NoError(t, err)
+	_, err = responseStream.Recv()
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+	assert.Contains(t, s.Message(), "the time window of 24h0m0s exceeds the maximum lookback of 1h0m0s")
+}
+
 func TestFindTracesQueryNil(t *testing.T) {
 	tsc := newTestServerClient(t)
 	responseStream, err := tsc.client.FindTraces(context.Background(), &api_v3.FindTracesRequest{})
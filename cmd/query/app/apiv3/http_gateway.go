@@ -88,6 +88,9 @@ func (h *HTTPGateway) tryHandleError(w http.ResponseWriter, err error, statusCod
 	if errors.Is(err, spanstore.ErrTraceNotFound) {
 		statusCode = http.StatusNotFound
 	}
+	if errors.Is(err, querysvc.ErrSearchRejected) {
+		statusCode = http.StatusBadRequest
+	}
 	if statusCode == http.StatusInternalServerError {
 		h.Logger.Error("HTTP handler, Internal Server Error", zap.Error(err))
 	}
@@ -138,14 +141,18 @@ func (*HTTPGateway) marshalResponse(response proto.Message, w http.ResponseWrite
 func (h *HTTPGateway) getTrace(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	traceIDVar := vars[paramTraceID]
-	traceID, err := model.TraceIDFromString(traceIDVar)
+	traceID, err := querysvc.ParseTraceID(traceIDVar)
 	if h.tryParamError(w, err, paramTraceID) {
 		return
 	}
-	trace, err := h.QueryService.GetTrace(r.Context(), traceID)
+	ctx := querysvc.ContextWithWarnings(r.Context())
+	trace, err := h.QueryService.GetTrace(ctx, traceID)
 	if h.tryHandleError(w, err, http.StatusInternalServerError) {
 		return
 	}
+	for _, warning := range querysvc.GetWarnings(ctx) {
+		w.Header().Add(querysvc.WarningsMetadataKey, warning)
+	}
 	h.returnSpans(trace.Spans, w)
 }
 
@@ -155,11 +162,15 @@ func (h *HTTPGateway) findTraces(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	traces, err := h.QueryService.FindTraces(r.Context(), queryParams)
+	ctx := querysvc.ContextWithWarnings(r.Context())
+	traces, err := h.QueryService.FindTraces(ctx, queryParams)
 	// TODO how do we distinguish internal error from bad parameters for FindTrace?
 	if h.tryHandleError(w, err, http.StatusInternalServerError) {
 		return
 	}
+	for _, warning := range querysvc.GetWarnings(ctx) {
+		w.Header().Add(querysvc.WarningsMetadataKey, warning)
+	}
 	var spans []*model.Span
 	for _, trace := range traces {
 		spans = append(spans, trace.Spans...)
@@ -14,6 +14,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
@@ -29,9 +30,18 @@ import (
 )
 
 func setupHTTPGatewayNoServer(
+	t *testing.T,
+	basePath string,
+	tenancyOptions tenancy.Options,
+) *testGateway {
+	return setupHTTPGatewayWithQueryOptions(t, basePath, tenancyOptions, querysvc.QueryServiceOptions{})
+}
+
+func setupHTTPGatewayWithQueryOptions(
 	_ *testing.T,
 	basePath string,
 	tenancyOptions tenancy.Options,
+	queryOptions querysvc.QueryServiceOptions,
 ) *testGateway {
 	gw := &testGateway{
 		reader: &spanstoremocks.Reader{},
@@ -39,7 +49,7 @@ func setupHTTPGatewayNoServer(
 
 	q := querysvc.NewQueryService(gw.reader,
 		&dependencyStoreMocks.Reader{},
-		querysvc.QueryServiceOptions{},
+		queryOptions,
 	)
 
 	hgw := &HTTPGateway{
@@ -254,6 +264,78 @@ func TestHTTPGatewayFindTracesErrors(t *testing.T) {
 	})
 }
 
+func TestHTTPGatewayFindTracesGuardrails(t *testing.T) {
+	q, qp := mockFindQueries()
+	q.Set(paramNumTraces, "100")
+	r, err := http.NewRequest(http.MethodGet, "/api/v3/traces?"+q.Encode(), nil)
+	require.NoError(t, err)
+
+	t.Run("clamped", func(t *testing.T) {
+		gw := setupHTTPGatewayWithQueryOptions(t, "", tenancy.Options{}, querysvc.QueryServiceOptions{
+			SearchGuardrails: querysvc.SearchGuardrails{MaxLimit: 10},
+		})
+		gw.reader.
+			On("FindTraces", matchContext, qp).
+			Return([]*model.Trace{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		gw.router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"search limit 100 reduced to the maximum of 10"}, w.Header().Values(querysvc.WarningsMetadataKey))
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		gw := setupHTTPGatewayWithQueryOptions(t, "", tenancy.Options{}, querysvc.QueryServiceOptions{
+			SearchGuardrails: querysvc.SearchGuardrails{
+				MaxLookback:  time.Nanosecond,
+				LookbackMode: querysvc.LookbackModeReject,
+			},
+		})
+		q := url.Values{}
+		q.Set(paramTimeMin, "2024-01-01T00:00:00Z")
+		q.Set(paramTimeMax, "2024-01-02T00:00:00Z")
+		r, err := http.NewRequest(http.MethodGet, "/api/v3/traces?"+q.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		gw.router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "exceeds the maximum lookback")
+	})
+
+	unscoped := url.Values{}
+	unscoped.Set(paramTimeMin, "2024-01-01T00:00:00Z")
+	unscoped.Set(paramTimeMax, "2024-01-02T00:00:00Z")
+
+	t.Run("service required", func(t *testing.T) {
+		gw := setupHTTPGatewayWithQueryOptions(t, "", tenancy.Options{}, querysvc.QueryServiceOptions{
+			SearchGuardrails: querysvc.SearchGuardrails{RequireService: true},
+		})
+		r, err := http.NewRequest(http.MethodGet, "/api/v3/traces?"+unscoped.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		gw.router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "specify the service of the traces to search")
+		gw.reader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+	})
+
+	t.Run("service not required", func(t *testing.T) {
+		gw := setupHTTPGateway(t, "", tenancy.Options{})
+		gw.reader.
+			On("FindTraces", matchContext, mock.Anything).
+			Return([]*model.Trace{}, nil).Once()
+		r, err := http.NewRequest(http.MethodGet, "/api/v3/traces?"+unscoped.Encode(), nil)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		gw.router.ServeHTTP(w, r)
+		assert.NotEqual(t, http.StatusBadRequest, w.Code)
+		gw.reader.AssertExpectations(t)
+	})
+}
+
 func TestHTTPGatewayGetServicesErrors(t *testing.T) {
 	gw := setupHTTPGatewayNoServer(t, "", tenancy.Options{})
 
@@ -0,0 +1,326 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+// AdminConfigPath is the path of the effective configuration of the query service, to register
+// the handler returned by NewAdminConfigHandler on the admin server.
+const AdminConfigPath = "/" + defaultAPIPrefix + "/admin/config"
+
+// redacted replaces the paths and the secrets of the configuration snapshot.
+const redacted = "<redacted>"
+
+// configSnapshot is the effective configuration of the query service. It lists the settings
+// explicitly, so that a new option is only exposed once it is known not to be a secret,
+// and it masks the paths, which may reveal where the certificates and tokens are stored.
+type configSnapshot struct {
+	HTTP                   httpConfigSnapshot          `json:"http"`
+	GRPC                   grpcConfigSnapshot          `json:"grpc"`
+	UI                     uiConfigSnapshot            `json:"ui"`
+	Tenancy                tenancyConfigSnapshot       `json:"tenancy"`
+	BearerTokenPropagation bool                        `json:"bearerTokenPropagation"`
+	AdditionalHeaders      map[string]string           `json:"additionalHeaders"`
+	MaxClockSkewAdjust     string                      `json:"maxClockSkewAdjust"`
+	OrphanSpans            string                      `json:"orphanSpans"`
+	EnableTracing          bool                        `json:"enableTracing"`
+	TraceIDCompatibility   bool                        `json:"traceIDCompatibility"`
+	Anonymization          anonymizationConfigSnapshot `json:"anonymization"`
+	Redaction              redactionConfigSnapshot     `json:"redaction"`
+	SubjectHeader          string                      `json:"subjectHeader"`
+	Timeouts               timeoutsConfigSnapshot      `json:"timeouts"`
+	Limits                 limitsConfigSnapshot        `json:"limits"`
+	RateLimit              rateLimitConfigSnapshot     `json:"rateLimit"`
+	StorageHealth          storageHealthConfigSnapshot `json:"storageHealth"`
+	Federation             federationConfigSnapshot    `json:"federation"`
+	SlowQueries            slowQueriesConfigSnapshot   `json:"slowQueries"`
+}
+
+type httpConfigSnapshot struct {
+	HostPort            string            `json:"hostPort"`
+	BasePath            string            `json:"basePath"`
+	MaxHeaderBytes      int               `json:"maxHeaderBytes"`
+	MaxRequestBodyBytes int64             `json:"maxRequestBodyBytes"`
+	TLS                 tlsConfigSnapshot `json:"tls"`
+}
+
+type grpcConfigSnapshot struct {
+	HostPort                string            `json:"hostPort"`
+	MaxReceiveMessageLength int               `json:"maxReceiveMessageLength"`
+	ReflectionEnabled       bool              `json:"reflectionEnabled"`
+	TLS                     tlsConfigSnapshot `json:"tls"`
+}
+
+type tlsConfigSnapshot struct {
+	Enabled        bool     `json:"enabled"`
+	CA             string   `json:"ca,omitempty"`
+	Cert           string   `json:"cert,omitempty"`
+	Key            string   `json:"key,omitempty"`
+	ClientCA       string   `json:"clientCA,omitempty"`
+	ServerName     string   `json:"serverName,omitempty"`
+	ClientAuthType string   `json:"clientAuthType,omitempty"`
+	MinVersion     string   `json:"minVersion,omitempty"`
+	MaxVersion     string   `json:"maxVersion,omitempty"`
+	CipherSuites   []string `json:"cipherSuites,omitempty"`
+	SkipHostVerify bool     `json:"skipHostVerify,omitempty"`
+	ReloadInterval string   `json:"reloadInterval,omitempty"`
+}
+
+type uiConfigSnapshot struct {
+	StaticAssets          string `json:"staticAssets"`
+	LogStaticAssetsAccess bool   `json:"logStaticAssetsAccess"`
+	ConfigFile            string `json:"configFile"`
+}
+
+type tenancyConfigSnapshot struct {
+	Enabled       bool   `json:"enabled"`
+	Header        string `json:"header"`
+	Tenants       int    `json:"tenants"`
+	StoragePrefix string `json:"storagePrefix"`
+	ConfigFile    string `json:"configFile"`
+}
+
+type anonymizationConfigSnapshot struct {
+	Enabled           bool     `json:"enabled"`
+	HashedTags        []string `json:"hashedTags"`
+	StrippedLogFields []string `json:"strippedLogFields"`
+}
+
+type redactionConfigSnapshot struct {
+	Rules              int `json:"rules"`
+	UnredactedSubjects int `json:"unredactedSubjects"`
+}
+
+type timeoutsConfigSnapshot struct {
+	Default      string `json:"default"`
+	Services     string `json:"services"`
+	Operations   string `json:"operations"`
+	FindTraces   string `json:"findTraces"`
+	GetTrace     string `json:"getTrace"`
+	Dependencies string `json:"dependencies"`
+}
+
+type limitsConfigSnapshot struct {
+	MaxOperations         int      `json:"maxOperations"`
+	MaxBatchTraces        int      `json:"maxBatchTraces"`
+	MaxTraceSpans         int      `json:"maxTraceSpans"`
+	MaxDependencyLookback string   `json:"maxDependencyLookback"`
+	DefaultSearchLimit    int      `json:"defaultSearchLimit"`
+	MaxSearchLimit        int      `json:"maxSearchLimit"`
+	MaxSearchLookback     string   `json:"maxSearchLookback"`
+	SearchLookbackMode    string   `json:"searchLookbackMode"`
+	DefaultSearchLookback string   `json:"defaultSearchLookback"`
+	AllowedSearchTagKeys  []string `json:"allowedSearchTagKeys"`
+	ActiveServicesWindow  string   `json:"activeServicesWindow"`
+}
+
+type rateLimitConfigSnapshot struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+	MaxClients        int     `json:"maxClients"`
+	ClientIPHeader    string  `json:"clientIPHeader"`
+}
+
+type storageHealthConfigSnapshot struct {
+	CheckInterval    string `json:"checkInterval"`
+	FailureThreshold string `json:"failureThreshold"`
+}
+
+type federationConfigSnapshot struct {
+	Timeout   string                       `json:"timeout"`
+	Endpoints []federationEndpointSnapshot `json:"endpoints"`
+}
+
+type federationEndpointSnapshot struct {
+	HostPort string            `json:"hostPort"`
+	TLS      tlsConfigSnapshot `json:"tls"`
+	Headers  map[string]string `json:"headers"`
+}
+
+type slowQueriesConfigSnapshot struct {
+	Threshold  string `json:"threshold"`
+	BufferSize int    `json:"bufferSize"`
+}
+
+// redactPath masks a path, keeping whether it is set.
+func redactPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactValues keeps the names of the headers, whose values may be credentials.
+func redactValues(names []string) map[string]string {
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		values[name] = redacted
+	}
+	return values
+}
+
+func newTLSConfigSnapshot(options tlscfg.Options) tlsConfigSnapshot {
+	snapshot := tlsConfigSnapshot{Enabled: options.Enabled}
+	if !options.Enabled {
+		return snapshot
+	}
+	snapshot.CA = redactPath(options.CAPath)
+	snapshot.Cert = redactPath(options.CertPath)
+	snapshot.Key = redactPath(options.KeyPath)
+	snapshot.ClientCA = redactPath(options.ClientCAPath)
+	snapshot.ServerName = options.ServerName
+	snapshot.ClientAuthType = options.ClientAuthType
+	snapshot.MinVersion = options.MinVersion
+	snapshot.MaxVersion = options.MaxVersion
+	snapshot.CipherSuites = options.CipherSuites
+	snapshot.SkipHostVerify = options.SkipHostVerify
+	if options.ReloadInterval > 0 {
+		snapshot.ReloadInterval = options.ReloadInterval.String()
+	}
+	return snapshot
+}
+
+// newConfigSnapshot returns the redacted snapshot of the query options.
+func newConfigSnapshot(qOpts *QueryOptions) configSnapshot {
+	additionalHeaders := make([]string, 0, len(qOpts.AdditionalHeaders))
+	for name := range qOpts.AdditionalHeaders {
+		additionalHeaders = append(additionalHeaders, name)
+	}
+	sort.Strings(additionalHeaders)
+	endpoints := make([]federationEndpointSnapshot, 0, len(qOpts.Federation.Endpoints))
+	for _, endpoint := range qOpts.Federation.Endpoints {
+		headers := make([]string, 0, len(endpoint.Headers))
+		for name := range endpoint.Headers {
+			headers = append(headers, name)
+		}
+		endpoints = append(endpoints, federationEndpointSnapshot{
+			HostPort: endpoint.HostPort,
+			TLS:      newTLSConfigSnapshot(endpoint.TLS),
+			Headers:  redactValues(headers),
+		})
+	}
+	return configSnapshot{
+		HTTP: httpConfigSnapshot{
+			HostPort:            qOpts.HTTPHostPort,
+			BasePath:            qOpts.BasePath,
+			MaxHeaderBytes:      qOpts.HTTPMaxHeaderBytes,
+			MaxRequestBodyBytes: qOpts.MaxRequestBodyBytes,
+			TLS:                 newTLSConfigSnapshot(qOpts.TLSHTTP),
+		},
+		GRPC: grpcConfigSnapshot{
+			HostPort:                qOpts.GRPCHostPort,
+			MaxReceiveMessageLength: qOpts.GRPCMaxReceiveMessageLength,
+			ReflectionEnabled:       !qOpts.GRPCServer.DisableReflection,
+			TLS:                     newTLSConfigSnapshot(qOpts.TLSGRPC),
+		},
+		UI: uiConfigSnapshot{
+			StaticAssets:          redactPath(qOpts.StaticAssets.Path),
+			LogStaticAssetsAccess: qOpts.StaticAssets.LogAccess,
+			ConfigFile:            redactPath(qOpts.UIConfig),
+		},
+		Tenancy: tenancyConfigSnapshot{
+			Enabled:       qOpts.Tenancy.Enabled,
+			Header:        qOpts.Tenancy.Header,
+			Tenants:       len(qOpts.Tenancy.Tenants),
+			StoragePrefix: qOpts.Tenancy.StoragePrefix,
+			ConfigFile:    redactPath(qOpts.Tenancy.ConfigFile),
+		},
+		BearerTokenPropagation: qOpts.BearerTokenPropagation,
+		AdditionalHeaders:      redactValues(additionalHeaders),
+		MaxClockSkewAdjust:     qOpts.MaxClockSkewAdjust.String(),
+		OrphanSpans:            string(qOpts.OrphanSpans),
+		EnableTracing:          qOpts.EnableTracing,
+		TraceIDCompatibility:   qOpts.TraceIDCompatibility,
+		Anonymization: anonymizationConfigSnapshot{
+			Enabled:           qOpts.Anonymization.Enabled,
+			HashedTags:        qOpts.Anonymization.HashedTags,
+			StrippedLogFields: qOpts.Anonymization.StrippedLogFields,
+		},
+		Redaction: redactionConfigSnapshot{
+			Rules:              len(qOpts.Redaction.Rules),
+			UnredactedSubjects: len(qOpts.Redaction.UnredactedSubjects),
+		},
+		SubjectHeader: qOpts.SubjectHeader,
+		Timeouts: timeoutsConfigSnapshot{
+			Default:      qOpts.Timeouts.Default.String(),
+			Services:     qOpts.Timeouts.Services.String(),
+			Operations:   qOpts.Timeouts.Operations.String(),
+			FindTraces:   qOpts.Timeouts.FindTraces.String(),
+			GetTrace:     qOpts.Timeouts.GetTrace.String(),
+			Dependencies: qOpts.Timeouts.Dependencies.String(),
+		},
+		Limits: limitsConfigSnapshot{
+			MaxOperations:         qOpts.MaxOperations,
+			MaxBatchTraces:        qOpts.MaxBatchTraces,
+			MaxTraceSpans:         qOpts.MaxTraceSpans,
+			MaxDependencyLookback: qOpts.MaxDependencyLookback.String(),
+			DefaultSearchLimit:    qOpts.DefaultSearchLimit,
+			MaxSearchLimit:        qOpts.SearchGuardrails.MaxLimit,
+			MaxSearchLookback:     qOpts.SearchGuardrails.MaxLookback.String(),
+			SearchLookbackMode:    qOpts.SearchGuardrails.LookbackMode,
+			DefaultSearchLookback: qOpts.SearchGuardrails.DefaultLookback.String(),
+			AllowedSearchTagKeys:  qOpts.AllowedSearchTagKeys,
+			ActiveServicesWindow:  qOpts.ActiveServicesWindow.String(),
+		},
+		RateLimit: rateLimitConfigSnapshot{
+			RequestsPerSecond: qOpts.RateLimit.RequestsPerSecond,
+			Burst:             qOpts.RateLimit.Burst,
+			MaxClients:        qOpts.RateLimit.MaxClients,
+			ClientIPHeader:    qOpts.RateLimit.ClientIPHeader,
+		},
+		StorageHealth: storageHealthConfigSnapshot{
+			CheckInterval:    qOpts.StorageHealthCheckInterval.String(),
+			FailureThreshold: qOpts.StorageFailureThreshold.String(),
+		},
+		Federation: federationConfigSnapshot{
+			Timeout:   qOpts.Federation.Timeout.String(),
+			Endpoints: endpoints,
+		},
+		SlowQueries: slowQueriesConfigSnapshot{
+			Threshold:  qOpts.SlowQueries.Threshold.String(),
+			BufferSize: qOpts.SlowQueries.BufferSize,
+		},
+	}
+}
+
+// NewAdminConfigHandler returns the handler of GET /api/admin/config, which returns the redacted
+// snapshot of the effective configuration of the query service. The requests must carry the bearer
+// token of queryOpts.AdminTokenFile, and are rejected with 401 Unauthorized otherwise.
+func NewAdminConfigHandler(queryOpts *QueryOptions) (http.Handler, error) {
+	data, err := os.ReadFile(filepath.Clean(queryOpts.AdminTokenFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the admin token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return nil, fmt.Errorf("the admin token file %s is empty", queryOpts.AdminTokenFile)
+	}
+	snapshot := newConfigSnapshot(queryOpts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(structuredResponse{Data: snapshot})
+	}), nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/federation"
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const adminToken = "s3cr3t-admin-token"
+
+// sensitiveQueryOptions returns query options holding paths and secrets which must not be exposed.
+func sensitiveQueryOptions(t *testing.T) *QueryOptions {
+	tokenFile := filepath.Join(t.TempDir(), "admin-token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte(adminToken+"\n"), 0o600))
+	return &QueryOptions{
+		QueryOptionsBase: QueryOptionsBase{
+			BasePath:          "/jaeger",
+			StaticAssets:      QueryOptionsStaticAssets{Path: "/srv/jaeger-ui"},
+			UIConfig:          "/etc/jaeger/ui.json",
+			AdditionalHeaders: http.Header{"X-Api-Key": {"header-secret"}},
+			Tenancy: tenancy.Options{
+				Enabled:    true,
+				Header:     "x-tenant",
+				ConfigFile: "/etc/jaeger/tenants.yaml",
+			},
+			Redaction: querysvc.RedactionOptions{UnredactedSubjects: []string{"alice"}},
+			Timeouts:  querysvc.QueryTimeouts{Default: 30 * time.Second, FindTraces: time.Minute},
+			SearchGuardrails: querysvc.SearchGuardrails{
+				MaxLimit:     500,
+				MaxLookback:  72 * time.Hour,
+				LookbackMode: querysvc.LookbackModeClamp,
+			},
+			MaxDependencyLookback: 168 * time.Hour,
+			DefaultSearchLimit:    20,
+			RateLimit:             RateLimitOptions{RequestsPerSecond: 2.5, Burst: 5, MaxClients: 100},
+		},
+		HTTPHostPort: ":16686",
+		GRPCHostPort: ":16685",
+		TLSHTTP: tlscfg.Options{
+			Enabled:        true,
+			CertPath:       "/etc/jaeger/tls/server.crt",
+			KeyPath:        "/etc/jaeger/tls/server.key",
+			ClientCAPath:   "/etc/jaeger/tls/client-ca.crt",
+			ClientAuthType: tlscfg.ClientAuthRequireAndVerify,
+		},
+		Federation: federation.Options{
+			Timeout: 10 * time.Second,
+			Endpoints: []federation.Endpoint{{
+				HostPort: "jaeger-eu:16685",
+				TLS:      tlscfg.Options{Enabled: true, KeyPath: "/etc/jaeger/tls/eu.key"},
+				Headers:  map[string]string{"authorization": "Bearer upstream-secret"},
+			}},
+		},
+		AdminTokenFile: tokenFile,
+	}
+}
+
+func getAdminConfig(t *testing.T, handler http.Handler, method, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, AdminConfigPath, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminConfigHandler(t *testing.T) {
+	handler, err := NewAdminConfigHandler(sensitiveQueryOptions(t))
+	require.NoError(t, err)
+
+	w := getAdminConfig(t, handler, http.MethodGet, "Bearer "+adminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	config := response.Data
+	assert.Equal(t, ":16686", config["http"].(map[string]any)["hostPort"])
+	assert.Equal(t, "/jaeger", config["http"].(map[string]any)["basePath"])
+	assert.Equal(t, map[string]any{
+		"enabled":        true,
+		"cert":           redacted,
+		"key":            redacted,
+		"clientCA":       redacted,
+		"clientAuthType": tlscfg.ClientAuthRequireAndVerify,
+	}, config["http"].(map[string]any)["tls"])
+	assert.Equal(t, map[string]any{"enabled": false}, config["grpc"].(map[string]any)["tls"])
+	assert.Equal(t, map[string]any{
+		"enabled":       true,
+		"header":        "x-tenant",
+		"tenants":       float64(0),
+		"storagePrefix": "",
+		"configFile":    redacted,
+	}, config["tenancy"])
+	assert.Equal(t, map[string]any{"X-Api-Key": redacted}, config["additionalHeaders"])
+	assert.Equal(t, redacted, config["ui"].(map[string]any)["staticAssets"])
+	assert.Equal(t, redacted, config["ui"].(map[string]any)["configFile"])
+	assert.Equal(t, "30s", config["timeouts"].(map[string]any)["default"])
+	assert.Equal(t, "1m0s", config["timeouts"].(map[string]any)["findTraces"])
+	limits := config["limits"].(map[string]any)
+	assert.Equal(t, "168h0m0s", limits["maxDependencyLookback"])
+	assert.InDelta(t, 500, limits["maxSearchLimit"], 0)
+	assert.Equal(t, "72h0m0s", limits["maxSearchLookback"])
+	assert.InDelta(t, 2.5, config["rateLimit"].(map[string]any)["requestsPerSecond"], 0)
+	assert.InDelta(t, 1, config["redaction"].(map[string]any)["unredactedSubjects"], 0)
+	endpoint := config["federation"].(map[string]any)["endpoints"].([]any)[0].(map[string]any)
+	assert.Equal(t, "jaeger-eu:16685", endpoint["hostPort"])
+	assert.Equal(t, map[string]any{"authorization": redacted}, endpoint["headers"])
+
+	for _, secret := range []string{
+		adminToken, "header-secret", "upstream-secret", "alice",
+		"/etc/jaeger", "/srv/jaeger-ui", "admin-token",
+	} {
+		assert.NotContains(t, w.Body.String(), secret)
+	}
+}
+
+func TestAdminConfigHandlerAuth(t *testing.T) {
+	handler, err := NewAdminConfigHandler(sensitiveQueryOptions(t))
+	require.NoError(t, err)
+
+	for _, authorization := range []string{"", "Bearer wrong", adminToken, "Basic " + adminToken} {
+		w := getAdminConfig(t, handler, http.MethodGet, authorization)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+		assert.NotContains(t, w.Body.String(), "hostPort")
+	}
+
+	w := getAdminConfig(t, handler, http.MethodPost, "Bearer "+adminToken)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestAdminConfigHandlerTokenFile(t *testing.T) {
+	_, err := NewAdminConfigHandler(&QueryOptions{AdminTokenFile: filepath.Join(t.TempDir(), "missing")})
+	require.ErrorContains(t, err, "failed to read the admin token file")
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(empty, []byte(" \n"), 0o600))
+	_, err = NewAdminConfigHandler(&QueryOptions{AdminTokenFile: empty})
+	require.ErrorContains(t, err, "is empty")
+}
@@ -24,7 +24,7 @@ import (
 
 var (
 	defaultDependencyLookbackDuration   = time.Hour * 24
-	defaultTraceQueryLookbackDuration   = time.Hour * 24 * 2
+	defaultDependencyIntervalDuration   = time.Hour
 	defaultMetricsQueryLookbackDuration = time.Hour
 	defaultMetricsQueryStepDuration     = 5 * time.Second
 	defaultMetricsQueryRateDuration     = 10 * time.Minute
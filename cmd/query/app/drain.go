@@ -0,0 +1,108 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// drainingMessage is the status message of the gRPC streams ended by the shutdown of the server.
+	drainingMessage = "the query server is shutting down, please reconnect"
+	// drainStreamsGrace is how long the streams ended at the end of the drain have to send their status
+	// before their connections are closed.
+	drainStreamsGrace = time.Second
+)
+
+// longLivedStreams are the gRPC streams which only end when their client cancels them, e.g. to watch
+// for changes. They are ended as soon as the server drains, instead of delaying its shutdown.
+var longLivedStreams = map[string]bool{
+	"/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/Watch": true,
+}
+
+// grpcDrainer ends the gRPC streams during the shutdown of the server with an Unavailable status telling
+// the clients to reconnect, rather than by closing their connections: the long-lived streams when the
+// drain starts, the other streams when the drain timeout expires.
+type grpcDrainer struct {
+	draining   context.Context
+	startDrain context.CancelFunc
+	ending     context.Context
+	endStreams context.CancelFunc
+}
+
+func newGRPCDrainer() *grpcDrainer {
+	d := &grpcDrainer{}
+	d.draining, d.startDrain = context.WithCancel(context.Background())
+	d.ending, d.endStreams = context.WithCancel(context.Background())
+	return d
+}
+
+// drainingServerStream replaces the context of the stream with one cancelled when the stream is ended by the drain.
+type drainingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *drainingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamInterceptor cancels the context of the streams when they are ended by the drain, and returns the
+// Unavailable status of the drain instead of the error of the handler for the cancelled context.
+func (d *grpcDrainer) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		end := d.ending
+		if longLivedStreams[info.FullMethod] {
+			end = d.draining
+		}
+		ctx, cancel := context.WithCancel(ss.Context())
+		defer cancel()
+		stop := context.AfterFunc(end, cancel)
+		defer stop()
+		err := handler(srv, &drainingServerStream{ServerStream: ss, ctx: ctx})
+		if end.Err() != nil && ss.Context().Err() == nil {
+			return status.Error(codes.Unavailable, drainingMessage)
+		}
+		return err
+	}
+}
+
+// drain stops the server gracefully, sending GOAWAY to the clients so that they stop sending new calls,
+// and ending the long-lived streams. The calls still running after the timeout are ended, and a timeout
+// of 0 stops the server right away, closing the connections.
+func (d *grpcDrainer) drain(server *grpc.Server, timeout time.Duration) {
+	d.startDrain()
+	if timeout <= 0 {
+		d.endStreams()
+		server.Stop()
+		return
+	}
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+		return
+	case <-timer.C:
+	}
+	d.endStreams()
+	grace := time.NewTimer(drainStreamsGrace)
+	defer grace.Stop()
+	select {
+	case <-stopped:
+	case <-grace.C:
+		server.Stop()
+		<-stopped
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+// startDrainTestServer starts a server with the drain timeouts, and returns it with a health watch
+// stream open on its gRPC server.
+func startDrainTestServer(t *testing.T, grpcDrainTimeout time.Duration) (*Server, grpc_health_v1.Health_WatchClient) {
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, makeQuerySvc().qs, nil,
+		&QueryOptions{
+			GRPCHostPort:     ":0",
+			HTTPHostPort:     ":0",
+			GRPCDrainTimeout: grpcDrainTimeout,
+			HTTPDrainTimeout: time.Minute,
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	conn, err := grpc.NewClient(server.grpcConn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	watch, err := grpc_health_v1.NewHealthClient(conn).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "jaeger.api_v2.QueryService"})
+	require.NoError(t, err)
+	update, err := watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, update.Status)
+	return server, watch
+}
+
+// requireDrained returns once the watch stream is ended by the drain.
+func requireDrained(t *testing.T, watch grpc_health_v1.Health_WatchClient) {
+	for {
+		update, err := watch.Recv()
+		if err != nil {
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+			assert.Equal(t, drainingMessage, status.Convert(err).Message())
+			return
+		}
+		assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, update.Status)
+	}
+}
+
+func TestServerDrainEndsLongLivedStreams(t *testing.T) {
+	server, watch := startDrainTestServer(t, time.Minute)
+
+	start := time.Now()
+	closed := make(chan error)
+	go func() {
+		closed <- server.Close()
+	}()
+	requireDrained(t, watch)
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("the server was not closed")
+	}
+	assert.Less(t, time.Since(start), 30*time.Second, "the long-lived streams do not delay the shutdown")
+}
+
+func TestServerDrainTimeout(t *testing.T) {
+	// the watch stream is drained like a call which did not finish in time
+	delete(longLivedStreams, "/grpc.health.v1.Health/Watch")
+	t.Cleanup(func() {
+		longLivedStreams["/grpc.health.v1.Health/Watch"] = true
+	})
+	const timeout = 200 * time.Millisecond
+	server, watch := startDrainTestServer(t, timeout)
+
+	start := time.Now()
+	require.NoError(t, server.Close())
+	requireDrained(t, watch)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, timeout)
+	assert.Less(t, elapsed, timeout+drainStreamsGrace+5*time.Second)
+}
+
+func TestServerDrainHTTPAfterGRPC(t *testing.T) {
+	server, watch := startDrainTestServer(t, time.Minute)
+	require.NoError(t, server.Close())
+	requireDrained(t, watch)
+
+	_, err := http.Get("http://" + server.httpConn.Addr().String() + "/api/services")
+	require.Error(t, err, "the HTTP server is stopped")
+}
+
+func TestGRPCDrainerInterceptor(t *testing.T) {
+	drainer := newGRPCDrainer()
+	interceptor := drainer.streamInterceptor()
+	ended := make(chan error)
+	go func() {
+		ended <- interceptor(nil, &drainingServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/jaeger.api_v2.QueryService/FindTraces"},
+			func(_ any, stream grpc.ServerStream) error {
+				<-stream.Context().Done()
+				return stream.Context().Err()
+			})
+	}()
+
+	drainer.startDrain()
+	select {
+	case <-ended:
+		t.Fatal("the calls are given the drain timeout to finish")
+	case <-time.After(10 * time.Millisecond):
+	}
+	drainer.endStreams()
+	err := <-ended
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// the errors of the calls cancelled by their client are kept
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = interceptor(nil, &drainingServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/jaeger.api_v2.QueryService/FindTraces"},
+		func(_ any, stream grpc.ServerStream) error {
+			return stream.Context().Err()
+		})
+	require.ErrorIs(t, err, context.Canceled)
+}
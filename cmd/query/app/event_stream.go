@@ -0,0 +1,72 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+
+	progressEvent = "progress"
+	resultEvent   = "result"
+	errorEvent    = "error"
+)
+
+// eventStream writes server-sent events, see https://html.spec.whatwg.org/multipage/server-sent-events.html
+type eventStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	closed  bool
+}
+
+// acceptsEventStream returns true if the client asked for server-sent events.
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), eventStreamContentType)
+}
+
+// newEventStream starts the event stream response, or returns nil if the ResponseWriter cannot stream.
+func newEventStream(w http.ResponseWriter) *eventStream {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil
+	}
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &eventStream{w: w, flusher: flusher}
+}
+
+// send writes an event with the JSON of data, and flushes it to the client.
+// The events sent after the stream is closed are dropped.
+func (s *eventStream) send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// close drops the events sent afterwards, since the response cannot be written once the handler returns.
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
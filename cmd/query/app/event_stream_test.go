@@ -0,0 +1,42 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nonFlushingWriter struct {
+	http.ResponseWriter
+}
+
+func TestEventStreamRequiresFlusher(t *testing.T) {
+	assert.Nil(t, newEventStream(nonFlushingWriter{httptest.NewRecorder()}))
+}
+
+func TestEventStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	stream := newEventStream(w)
+	require.NotNil(t, stream)
+	require.NoError(t, stream.send(progressEvent, map[string]int{"tracesFound": 1}))
+	require.Error(t, stream.send(progressEvent, make(chan int)))
+	stream.close()
+	require.NoError(t, stream.send(resultEvent, "dropped"))
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "event: progress\ndata: {\"tracesFound\":1}\n\n", w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func TestAcceptsEventStream(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/traces", nil)
+	assert.False(t, acceptsEventStream(r))
+	r.Header.Set("Accept", "text/event-stream, application/json")
+	assert.True(t, acceptsEventStream(r))
+}
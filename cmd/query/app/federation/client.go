@@ -0,0 +1,77 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package federation
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	_ "github.com/jaegertracing/jaeger/pkg/gogocodec" // force gogo codec registration
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+// Upstream is a remote Jaeger query service of the federation.
+type Upstream struct {
+	Name   string
+	Client api_v2.QueryServiceClient
+	// Headers are added to the gRPC metadata of the requests.
+	Headers map[string]string
+}
+
+// Connections holds the gRPC connections to the upstreams.
+type Connections struct {
+	Upstreams []Upstream
+	conns     []*grpc.ClientConn
+	endpoints []Endpoint
+}
+
+// Connect creates the gRPC clients of the endpoints.
+func Connect(endpoints []Endpoint, logger *zap.Logger) (*Connections, error) {
+	c := &Connections{endpoints: endpoints}
+	for i := range c.endpoints {
+		endpoint := &c.endpoints[i]
+		conn, err := dial(endpoint, logger)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to connect to federation endpoint %s: %w", endpoint.HostPort, err), c.Close())
+		}
+		c.conns = append(c.conns, conn)
+		c.Upstreams = append(c.Upstreams, Upstream{
+			Name:    endpoint.HostPort,
+			Client:  api_v2.NewQueryServiceClient(conn),
+			Headers: endpoint.Headers,
+		})
+	}
+	return c, nil
+}
+
+func dial(endpoint *Endpoint, logger *zap.Logger) (*grpc.ClientConn, error) {
+	var dialOptions []grpc.DialOption
+	if endpoint.TLS.Enabled {
+		tlsConf, err := endpoint.TLS.Config(logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS config: %w", err)
+		}
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConf)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	return grpc.NewClient(endpoint.HostPort, dialOptions...)
+}
+
+// Close closes the connections to the upstreams.
+func (c *Connections) Close() error {
+	var errs []error
+	for _, conn := range c.conns {
+		errs = append(errs, conn.Close())
+	}
+	for i := range c.endpoints {
+		errs = append(errs, c.endpoints[i].TLS.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package federation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+// Options configures the upstream Jaeger query services of the federation.
+type Options struct {
+	// Endpoints are the upstream query services. Federation is enabled when it is not empty.
+	Endpoints []Endpoint
+	// Timeout limits the duration of the requests to each upstream, 0 means no timeout.
+	Timeout time.Duration
+}
+
+// Endpoint is the address of the gRPC API of an upstream Jaeger query service.
+type Endpoint struct {
+	HostPort string
+	TLS      tlscfg.Options
+	// Headers are added to the gRPC metadata of the requests, e.g. to set the tenant.
+	Headers map[string]string
+}
+
+// ParseEndpoint parses an endpoint in the form host:port[;option=value...], where the options are
+// tls.enabled, tls.ca, tls.cert, tls.key, tls.server-name, tls.skip-host-verify and header.<name>.
+// For example: jaeger-eu:16685;tls.enabled=true;header.x-tenant=acme
+func ParseEndpoint(s string) (Endpoint, error) {
+	parts := strings.Split(s, ";")
+	endpoint := Endpoint{HostPort: strings.TrimSpace(parts[0])}
+	if endpoint.HostPort == "" {
+		return endpoint, fmt.Errorf("missing host:port in federation endpoint %q", s)
+	}
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return endpoint, fmt.Errorf("invalid option %q in federation endpoint %q", part, s)
+		}
+		var err error
+		switch key {
+		case "tls.enabled":
+			endpoint.TLS.Enabled, err = strconv.ParseBool(value)
+		case "tls.ca":
+			endpoint.TLS.CAPath = value
+		case "tls.cert":
+			endpoint.TLS.CertPath = value
+		case "tls.key":
+			endpoint.TLS.KeyPath = value
+		case "tls.server-name":
+			endpoint.TLS.ServerName = value
+		case "tls.skip-host-verify":
+			endpoint.TLS.SkipHostVerify, err = strconv.ParseBool(value)
+		default:
+			header, ok := strings.CutPrefix(key, "header.")
+			if !ok || header == "" {
+				return endpoint, fmt.Errorf("unknown option %q in federation endpoint %q", key, s)
+			}
+			if endpoint.Headers == nil {
+				endpoint.Headers = make(map[string]string)
+			}
+			endpoint.Headers[strings.ToLower(header)] = value
+		}
+		if err != nil {
+			return endpoint, fmt.Errorf("invalid value of option %q in federation endpoint %q: %w", key, s, err)
+		}
+	}
+	return endpoint, nil
+}
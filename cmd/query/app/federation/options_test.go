@@ -0,0 +1,69 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package federation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		expected Endpoint
+	}{
+		{
+			endpoint: "jaeger-us:16685",
+			expected: Endpoint{HostPort: "jaeger-us:16685"},
+		},
+		{
+			endpoint: " jaeger-eu:16685 ; tls.enabled=true;tls.ca=/ca.pem;tls.cert=/cert.pem;tls.key=/key.pem;tls.server-name=jaeger;tls.skip-host-verify=true;header.X-Tenant=acme",
+			expected: Endpoint{
+				HostPort: "jaeger-eu:16685",
+				TLS: tlscfg.Options{
+					Enabled:        true,
+					CAPath:         "/ca.pem",
+					CertPath:       "/cert.pem",
+					KeyPath:        "/key.pem",
+					ServerName:     "jaeger",
+					SkipHostVerify: true,
+				},
+				Headers: map[string]string{"x-tenant": "acme"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			endpoint, err := ParseEndpoint(tc.endpoint)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, endpoint)
+		})
+	}
+}
+
+func TestParseEndpointErrors(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		err      string
+	}{
+		{endpoint: "", err: "missing host:port"},
+		{endpoint: ";tls.enabled=true", err: "missing host:port"},
+		{endpoint: "host:1;tls.enabled", err: "invalid option"},
+		{endpoint: "host:1;=x", err: "invalid option"},
+		{endpoint: "host:1;tls.enabled=maybe", err: "invalid value of option \"tls.enabled\""},
+		{endpoint: "host:1;tls.skip-host-verify=maybe", err: "invalid value of option \"tls.skip-host-verify\""},
+		{endpoint: "host:1;unknown=x", err: "unknown option \"unknown\""},
+		{endpoint: "host:1;header.=x", err: "unknown option \"header.\""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			_, err := ParseEndpoint(tc.endpoint)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package federation implements span and dependency readers backed by remote Jaeger
+// query services, so that a single query service can serve the data of several installations.
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var (
+	_ spanstore.Reader       = (*Reader)(nil)
+	_ dependencystore.Reader = (*Reader)(nil)
+)
+
+// Reader reads spans and dependencies from all the upstreams. The results are merged, and the
+// failures of some of the upstreams are reported as warnings with querysvc.AddWarning.
+// The request fails only when all the upstreams fail.
+type Reader struct {
+	upstreams []Upstream
+	timeout   time.Duration
+	logger    *zap.Logger
+}
+
+// NewReader creates a Reader of the upstreams. The timeout limits the duration of
+// the requests to each upstream, 0 means no timeout.
+func NewReader(upstreams []Upstream, timeout time.Duration, logger *zap.Logger) *Reader {
+	return &Reader{
+		upstreams: upstreams,
+		timeout:   timeout,
+		logger:    logger,
+	}
+}
+
+// GetServices returns the union of the services of the upstreams.
+func (r *Reader) GetServices(ctx context.Context) ([]string, error) {
+	results := make([][]string, len(r.upstreams))
+	err := r.fanOut(ctx, func(ctx context.Context, i int, upstream Upstream) error {
+		resp, err := upstream.Client.GetServices(ctx, &api_v2.GetServicesRequest{})
+		if err != nil {
+			return err
+		}
+		results[i] = resp.Services
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]struct{})
+	services := []string{}
+	for _, upstreamServices := range results {
+		for _, service := range upstreamServices {
+			if _, ok := set[service]; !ok {
+				set[service] = struct{}{}
+				services = append(services, service)
+			}
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// GetOperations returns the union of the operations of the service in the upstreams.
+func (r *Reader) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	results := make([][]spanstore.Operation, len(r.upstreams))
+	err := r.fanOut(ctx, func(ctx context.Context, i int, upstream Upstream) error {
+		resp, err := upstream.Client.GetOperations(ctx, &api_v2.GetOperationsRequest{
+			Service:  query.ServiceName,
+			SpanKind: query.SpanKind,
+		})
+		if err != nil {
+			return err
+		}
+		for _, operation := range resp.Operations {
+			results[i] = append(results[i], spanstore.Operation{Name: operation.Name, SpanKind: operation.SpanKind})
+		}
+		if len(resp.Operations) == 0 {
+			for _, name := range resp.OperationNames {
+				results[i] = append(results[i], spanstore.Operation{Name: name})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[spanstore.Operation]struct{})
+	operations := []spanstore.Operation{}
+	for _, upstreamOperations := range results {
+		for _, operation := range upstreamOperations {
+			if _, ok := set[operation]; !ok {
+				set[operation] = struct{}{}
+				operations = append(operations, operation)
+			}
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].SpanKind < operations[j].SpanKind
+	})
+	return operations, nil
+}
+
+// GetTrace queries the upstreams concurrently and returns the trace of the first one that has it.
+func (r *Reader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	// the requests to the other upstreams are canceled when the trace is found
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		trace *model.Trace
+		err   error
+	}
+	results := make(chan result, len(r.upstreams))
+	for _, upstream := range r.upstreams {
+		go func(upstream Upstream) {
+			ctx, cancel := r.upstreamContext(ctx, upstream)
+			defer cancel()
+			trace, err := getTrace(ctx, upstream, traceID)
+			if err != nil && !errors.Is(err, spanstore.ErrTraceNotFound) {
+				err = fmt.Errorf("upstream %s: %w", upstream.Name, err)
+			}
+			results <- result{trace: trace, err: err}
+		}(upstream)
+	}
+	var errs []error
+	for range r.upstreams {
+		res := <-results
+		if res.err == nil {
+			return res.trace, nil
+		}
+		if !errors.Is(res.err, spanstore.ErrTraceNotFound) {
+			errs = append(errs, res.err)
+		}
+	}
+	if len(errs) > 0 && len(errs) == len(r.upstreams) {
+		return nil, errors.Join(errs...)
+	}
+	r.warn(ctx, errs)
+	return nil, spanstore.ErrTraceNotFound
+}
+
+func getTrace(ctx context.Context, upstream Upstream, traceID model.TraceID) (*model.Trace, error) {
+	stream, err := upstream.Client.GetTrace(ctx, &api_v2.GetTraceRequest{TraceID: traceID})
+	if err != nil {
+		return nil, unwrapNotFoundErr(err)
+	}
+	trace := &model.Trace{}
+	for received, err := stream.Recv(); !errors.Is(err, io.EOF); received, err = stream.Recv() {
+		if err != nil {
+			return nil, unwrapNotFoundErr(err)
+		}
+		for i := range received.Spans {
+			trace.Spans = append(trace.Spans, &received.Spans[i])
+		}
+	}
+	if len(trace.Spans) == 0 {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return trace, nil
+}
+
+func unwrapNotFoundErr(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return spanstore.ErrTraceNotFound
+	}
+	return err
+}
+
+// FindTraces searches the traces in all the upstreams. The traces found in several upstreams are
+// merged, and the most recent ones are returned up to the number of traces of the query.
+func (r *Reader) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	results := make([][]*model.Trace, len(r.upstreams))
+	err := r.fanOut(ctx, func(ctx context.Context, i int, upstream Upstream) error {
+		traces, err := findTraces(ctx, upstream, query)
+		results[i] = traces
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeTraces(results, query.NumTraces), nil
+}
+
+// FindTraceIDs returns the IDs of the traces found by FindTraces, since the upstreams
+// do not support searching trace IDs only.
+func (r *Reader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	traces, err := r.FindTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	traceIDs := make([]model.TraceID, len(traces))
+	for i, trace := range traces {
+		traceIDs[i] = trace.Spans[0].TraceID
+	}
+	return traceIDs, nil
+}
+
+func findTraces(ctx context.Context, upstream Upstream, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	stream, err := upstream.Client.FindTraces(ctx, &api_v2.FindTracesRequest{
+		Query: &api_v2.TraceQueryParameters{
+			ServiceName:   query.ServiceName,
+			OperationName: query.OperationName,
+			Tags:          query.Tags,
+			StartTimeMin:  query.StartTimeMin,
+			StartTimeMax:  query.StartTimeMax,
+			DurationMin:   query.DurationMin,
+			DurationMax:   query.DurationMax,
+			SearchDepth:   int32(query.NumTraces),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the spans of each trace are sent consecutively
+	var traces []*model.Trace
+	var trace *model.Trace
+	for received, err := stream.Recv(); !errors.Is(err, io.EOF); received, err = stream.Recv() {
+		if err != nil {
+			return nil, err
+		}
+		for i, span := range received.Spans {
+			if trace == nil || span.TraceID != trace.Spans[0].TraceID {
+				trace = &model.Trace{}
+				traces = append(traces, trace)
+			}
+			trace.Spans = append(trace.Spans, &received.Spans[i])
+		}
+	}
+	return traces, nil
+}
+
+// mergeTraces merges the traces with the same ID and returns the most recent ones up to the limit.
+func mergeTraces(results [][]*model.Trace, limit int) []*model.Trace {
+	traces := []*model.Trace{}
+	byID := make(map[model.TraceID]*model.Trace)
+	for _, upstreamTraces := range results {
+		for _, trace := range upstreamTraces {
+			traceID := trace.Spans[0].TraceID
+			if merged, ok := byID[traceID]; ok {
+				merged.Spans = append(merged.Spans, trace.Spans...)
+				continue
+			}
+			byID[traceID] = trace
+			traces = append(traces, trace)
+		}
+	}
+	startTimes := make(map[*model.Trace]time.Time, len(traces))
+	for _, trace := range traces {
+		startTimes[trace] = traceStartTime(trace)
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return startTimes[traces[i]].After(startTimes[traces[j]])
+	})
+	if limit > 0 && len(traces) > limit {
+		traces = traces[:limit]
+	}
+	return traces
+}
+
+func traceStartTime(trace *model.Trace) time.Time {
+	startTime := trace.Spans[0].StartTime
+	for _, span := range trace.Spans[1:] {
+		if span.StartTime.Before(startTime) {
+			startTime = span.StartTime
+		}
+	}
+	return startTime
+}
+
+// GetDependencies returns the dependencies of all the upstreams, adding up the calls between the same services.
+func (r *Reader) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	results := make([][]model.DependencyLink, len(r.upstreams))
+	err := r.fanOut(ctx, func(ctx context.Context, i int, upstream Upstream) error {
+		resp, err := upstream.Client.GetDependencies(ctx, &api_v2.GetDependenciesRequest{
+			StartTime: endTs.Add(-lookback),
+			EndTime:   endTs,
+		})
+		if err != nil {
+			return err
+		}
+		results[i] = resp.Dependencies
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	type linkKey struct {
+		parent, child, source string
+	}
+	dependencies := []model.DependencyLink{}
+	indexes := make(map[linkKey]int)
+	for _, upstreamDependencies := range results {
+		for _, link := range upstreamDependencies {
+			key := linkKey{parent: link.Parent, child: link.Child, source: link.Source}
+			if i, ok := indexes[key]; ok {
+				dependencies[i].CallCount += link.CallCount
+				continue
+			}
+			indexes[key] = len(dependencies)
+			dependencies = append(dependencies, link)
+		}
+	}
+	return dependencies, nil
+}
+
+// fanOut calls all the upstreams concurrently. It fails only if all the upstreams fail,
+// otherwise the failures are reported as warnings.
+func (r *Reader) fanOut(ctx context.Context, call func(ctx context.Context, i int, upstream Upstream) error) error {
+	errs := make([]error, len(r.upstreams))
+	var wg sync.WaitGroup
+	for i, upstream := range r.upstreams {
+		wg.Add(1)
+		go func(i int, upstream Upstream) {
+			defer wg.Done()
+			ctx, cancel := r.upstreamContext(ctx, upstream)
+			defer cancel()
+			if err := call(ctx, i, upstream); err != nil {
+				errs[i] = fmt.Errorf("upstream %s: %w", upstream.Name, err)
+			}
+		}(i, upstream)
+	}
+	wg.Wait()
+
+	var failures []error
+	for _, err := range errs {
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 && len(failures) == len(r.upstreams) {
+		return errors.Join(failures...)
+	}
+	r.warn(ctx, failures)
+	return nil
+}
+
+func (r *Reader) warn(ctx context.Context, errs []error) {
+	for _, err := range errs {
+		r.logger.Warn("Federation upstream failed", zap.Error(err))
+		querysvc.AddWarning(ctx, err.Error())
+	}
+}
+
+func (r *Reader) upstreamContext(ctx context.Context, upstream Upstream) (context.Context, context.CancelFunc) {
+	for key, value := range upstream.Headers {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	}
+	if r.timeout > 0 {
+		return context.WithTimeout(ctx, r.timeout)
+	}
+	return context.WithCancel(ctx)
+}
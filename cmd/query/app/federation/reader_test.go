@@ -0,0 +1,335 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package federation
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// fakeUpstream is an in-process Jaeger query service.
+type fakeUpstream struct {
+	api_v2.UnimplementedQueryServiceServer
+	services     []string
+	operations   *api_v2.GetOperationsResponse
+	traces       []*model.Trace
+	dependencies []model.DependencyLink
+	// err fails all the requests
+	err error
+	// block makes the requests wait until they are canceled
+	block bool
+
+	mu      sync.Mutex
+	tenants []string
+}
+
+func (f *fakeUpstream) respond(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	f.mu.Lock()
+	f.tenants = append(f.tenants, md.Get("x-tenant")...)
+	f.mu.Unlock()
+	if f.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return f.err
+}
+
+func (f *fakeUpstream) receivedTenants() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tenants
+}
+
+func (f *fakeUpstream) GetServices(ctx context.Context, _ *api_v2.GetServicesRequest) (*api_v2.GetServicesResponse, error) {
+	if err := f.respond(ctx); err != nil {
+		return nil, err
+	}
+	return &api_v2.GetServicesResponse{Services: f.services}, nil
+}
+
+func (f *fakeUpstream) GetOperations(ctx context.Context, _ *api_v2.GetOperationsRequest) (*api_v2.GetOperationsResponse, error) {
+	if err := f.respond(ctx); err != nil {
+		return nil, err
+	}
+	return f.operations, nil
+}
+
+func (f *fakeUpstream) GetTrace(r *api_v2.GetTraceRequest, stream api_v2.QueryService_GetTraceServer) error {
+	if err := f.respond(stream.Context()); err != nil {
+		return err
+	}
+	for _, trace := range f.traces {
+		if trace.Spans[0].TraceID == r.TraceID {
+			return sendSpans(trace, stream.Send)
+		}
+	}
+	return status.Errorf(codes.NotFound, "trace not found: %v", spanstore.ErrTraceNotFound)
+}
+
+func (f *fakeUpstream) FindTraces(_ *api_v2.FindTracesRequest, stream api_v2.QueryService_FindTracesServer) error {
+	if err := f.respond(stream.Context()); err != nil {
+		return err
+	}
+	for _, trace := range f.traces {
+		if err := sendSpans(trace, stream.Send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeUpstream) GetDependencies(ctx context.Context, _ *api_v2.GetDependenciesRequest) (*api_v2.GetDependenciesResponse, error) {
+	if err := f.respond(ctx); err != nil {
+		return nil, err
+	}
+	return &api_v2.GetDependenciesResponse{Dependencies: f.dependencies}, nil
+}
+
+// sendSpans sends a chunk per span to exercise the reassembly of traces.
+func sendSpans(trace *model.Trace, send func(*api_v2.SpansResponseChunk) error) error {
+	for _, span := range trace.Spans {
+		if err := send(&api_v2.SpansResponseChunk{Spans: []model.Span{*span}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startUpstreams serves the fake upstreams and returns the endpoints to connect to them.
+func startUpstreams(t *testing.T, upstreams ...*fakeUpstream) []Endpoint {
+	var endpoints []Endpoint
+	for _, upstream := range upstreams {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		server := grpc.NewServer()
+		api_v2.RegisterQueryServiceServer(server, upstream)
+		go func() {
+			_ = server.Serve(lis)
+		}()
+		t.Cleanup(server.Stop)
+		endpoints = append(endpoints, Endpoint{HostPort: lis.Addr().String()})
+	}
+	return endpoints
+}
+
+func newTestReader(t *testing.T, timeout time.Duration, endpoints []Endpoint) *Reader {
+	conns, err := Connect(endpoints, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conns.Close())
+	})
+	return NewReader(conns.Upstreams, timeout, zap.NewNop())
+}
+
+func makeTrace(traceID uint64, spanID uint64, startTime time.Time) *model.Trace {
+	return &model.Trace{Spans: []*model.Span{{
+		TraceID:       model.NewTraceID(0, traceID),
+		SpanID:        model.NewSpanID(spanID),
+		OperationName: "op",
+		StartTime:     startTime.UTC(),
+		Process:       model.NewProcess("svc", nil),
+	}}}
+}
+
+func traceIDs(traces []*model.Trace) []model.TraceID {
+	ids := make([]model.TraceID, len(traces))
+	for i, trace := range traces {
+		ids[i] = trace.Spans[0].TraceID
+	}
+	return ids
+}
+
+func TestGetServices(t *testing.T) {
+	reader := newTestReader(t, 0, startUpstreams(t,
+		&fakeUpstream{services: []string{"frontend", "mysql"}},
+		&fakeUpstream{services: []string{"redis", "frontend"}},
+	))
+	services, err := reader.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "mysql", "redis"}, services)
+}
+
+func TestGetOperations(t *testing.T) {
+	reader := newTestReader(t, 0, startUpstreams(t,
+		&fakeUpstream{operations: &api_v2.GetOperationsResponse{
+			Operations: []*api_v2.Operation{{Name: "GET", SpanKind: "server"}, {Name: "GET", SpanKind: "client"}},
+		}},
+		&fakeUpstream{operations: &api_v2.GetOperationsResponse{
+			Operations: []*api_v2.Operation{{Name: "GET", SpanKind: "server"}, {Name: "POST", SpanKind: "server"}},
+		}},
+		// legacy upstreams only return the operation names
+		&fakeUpstream{operations: &api_v2.GetOperationsResponse{OperationNames: []string{"DELETE"}}},
+	))
+	operations, err := reader.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{
+		{Name: "DELETE"},
+		{Name: "GET", SpanKind: "client"},
+		{Name: "GET", SpanKind: "server"},
+		{Name: "POST", SpanKind: "server"},
+	}, operations)
+}
+
+func TestFindTracesMerge(t *testing.T) {
+	start := time.Unix(1000, 0)
+	sharedTrace := makeTrace(1, 1, start)
+	otherPart := makeTrace(1, 2, start.Add(time.Second))
+	reader := newTestReader(t, 0, startUpstreams(t,
+		&fakeUpstream{traces: []*model.Trace{sharedTrace, makeTrace(2, 1, start.Add(3*time.Second))}},
+		&fakeUpstream{traces: []*model.Trace{makeTrace(3, 1, start.Add(2*time.Second)), otherPart}},
+	))
+
+	traces, err := reader.FindTraces(context.Background(), &spanstore.TraceQueryParameters{ServiceName: "svc"})
+	require.NoError(t, err)
+	// the most recent traces first
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 2), model.NewTraceID(0, 3), model.NewTraceID(0, 1)}, traceIDs(traces))
+	require.Len(t, traces[2].Spans, 2, "the spans of the trace from both upstreams are merged")
+	assert.Equal(t, model.NewSpanID(1), traces[2].Spans[0].SpanID)
+	assert.Equal(t, model.NewSpanID(2), traces[2].Spans[1].SpanID)
+
+	traces, err = reader.FindTraces(context.Background(), &spanstore.TraceQueryParameters{ServiceName: "svc", NumTraces: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 2), model.NewTraceID(0, 3)}, traceIDs(traces))
+
+	ids, err := reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{ServiceName: "svc", NumTraces: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 2), model.NewTraceID(0, 3)}, ids)
+}
+
+func TestFindTracesPartialFailure(t *testing.T) {
+	endpoints := startUpstreams(t,
+		&fakeUpstream{traces: []*model.Trace{makeTrace(1, 1, time.Unix(1000, 0))}},
+		&fakeUpstream{err: status.Error(codes.Unavailable, "storage is down")},
+		&fakeUpstream{block: true},
+	)
+	reader := newTestReader(t, 100*time.Millisecond, endpoints)
+
+	ctx := querysvc.ContextWithWarnings(context.Background())
+	traces, err := reader.FindTraces(ctx, &spanstore.TraceQueryParameters{ServiceName: "svc"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1)}, traceIDs(traces))
+
+	warnings := querysvc.GetWarnings(ctx)
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "upstream "+endpoints[1].HostPort)
+	assert.Contains(t, warnings[0], "storage is down")
+	assert.Contains(t, warnings[1], "upstream "+endpoints[2].HostPort)
+	assert.Contains(t, warnings[1], "DeadlineExceeded")
+}
+
+func TestFanOutAllUpstreamsFail(t *testing.T) {
+	reader := newTestReader(t, 0, startUpstreams(t,
+		&fakeUpstream{err: status.Error(codes.Unavailable, "storage is down")},
+		&fakeUpstream{err: status.Error(codes.Internal, "storage is broken")},
+	))
+	_, err := reader.GetServices(context.Background())
+	require.ErrorContains(t, err, "storage is down")
+	require.ErrorContains(t, err, "storage is broken")
+
+	_, err = reader.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "svc"})
+	require.Error(t, err)
+	_, err = reader.FindTraces(context.Background(), &spanstore.TraceQueryParameters{ServiceName: "svc"})
+	require.Error(t, err)
+	_, err = reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{ServiceName: "svc"})
+	require.Error(t, err)
+	_, err = reader.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.Error(t, err)
+	_, err = reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorContains(t, err, "storage is down")
+	require.NotErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
+
+func TestGetTraceFirstHit(t *testing.T) {
+	trace := makeTrace(1, 1, time.Unix(1000, 0))
+	trace.Spans = append(trace.Spans, makeTrace(1, 2, time.Unix(1001, 0)).Spans...)
+	reader := newTestReader(t, 0, startUpstreams(t,
+		&fakeUpstream{},
+		&fakeUpstream{traces: []*model.Trace{trace}},
+		// the slow upstream is canceled once the trace is found
+		&fakeUpstream{block: true},
+	))
+	actual, err := reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, trace, actual)
+}
+
+func TestGetTraceNotFound(t *testing.T) {
+	reader := newTestReader(t, 0, startUpstreams(t, &fakeUpstream{}, &fakeUpstream{}))
+	_, err := reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+
+	endpoints := startUpstreams(t, &fakeUpstream{}, &fakeUpstream{err: status.Error(codes.Unavailable, "storage is down")})
+	reader = newTestReader(t, 0, endpoints)
+	ctx := querysvc.ContextWithWarnings(context.Background())
+	_, err = reader.GetTrace(ctx, model.NewTraceID(0, 1))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	warnings := querysvc.GetWarnings(ctx)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "upstream "+endpoints[1].HostPort)
+}
+
+func TestGetDependencies(t *testing.T) {
+	reader := newTestReader(t, 0, startUpstreams(t,
+		&fakeUpstream{dependencies: []model.DependencyLink{
+			{Parent: "frontend", Child: "mysql", CallCount: 1},
+			{Parent: "frontend", Child: "redis", CallCount: 2},
+		}},
+		&fakeUpstream{dependencies: []model.DependencyLink{
+			{Parent: "frontend", Child: "redis", CallCount: 3},
+		}},
+	))
+	dependencies, err := reader.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{
+		{Parent: "frontend", Child: "mysql", CallCount: 1},
+		{Parent: "frontend", Child: "redis", CallCount: 5},
+	}, dependencies)
+}
+
+func TestUpstreamHeaders(t *testing.T) {
+	acme, other := &fakeUpstream{}, &fakeUpstream{}
+	endpoints := startUpstreams(t, acme, other)
+	endpoints[0].Headers = map[string]string{"x-tenant": "acme"}
+	reader := newTestReader(t, 0, endpoints)
+	_, err := reader.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme"}, acme.receivedTenants())
+	assert.Empty(t, other.receivedTenants())
+}
+
+func TestConnectTLSError(t *testing.T) {
+	_, err := Connect([]Endpoint{
+		{HostPort: "127.0.0.1:1"},
+		{HostPort: "127.0.0.1:2", TLS: tlscfg.Options{Enabled: true, CAPath: "/does/not/exist"}},
+	}, zap.NewNop())
+	require.ErrorContains(t, err, "failed to connect to federation endpoint 127.0.0.1:2")
+}
+
+func TestConnectTLS(t *testing.T) {
+	conns, err := Connect([]Endpoint{
+		{HostPort: "127.0.0.1:1", TLS: tlscfg.Options{Enabled: true}},
+	}, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, conns.Upstreams, 1)
+	assert.Equal(t, "127.0.0.1:1", conns.Upstreams[0].Name)
+	require.NoError(t, conns.Close())
+}
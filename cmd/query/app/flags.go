@@ -17,42 +17,120 @@ package app
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"net/textproto"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/federation"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/grpccfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/ports"
 	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/slowquery"
 )
 
 const (
 	queryHTTPHostPort          = "query.http-server.host-port"
+	queryHTTPMaxHeaderBytes    = "query.http-server.max-header-bytes"
+	queryHTTPMaxBodyBytes      = "query.http-server.max-request-body-bytes"
 	queryGRPCHostPort          = "query.grpc-server.host-port"
+	queryGRPCMaxMessageSize    = "query.grpc-server.max-message-size"
+	queryGRPCDrainTimeout      = "query.grpc-server.drain-timeout"
+	queryHTTPDrainTimeout      = "query.http-server.drain-timeout"
 	queryBasePath              = "query.base-path"
 	queryStaticFiles           = "query.static-files"
+	queryUIAssetsPath          = "query.ui-assets-path"
+	queryUIAssetsSHA256        = "query.ui-assets-sha256"
 	queryLogStaticAssetsAccess = "query.log-static-assets-access"
 	queryUIConfig              = "query.ui-config"
 	queryTokenPropagation      = "query.bearer-token-propagation"
 	queryAdditionalHeaders     = "query.additional-headers"
 	queryMaxClockSkewAdjust    = "query.max-clock-skew-adjustment"
 	queryEnableTracing         = "query.enable-tracing"
+	querySelfTracing           = "query.self-tracing"
+	queryTraceIDCompatibility  = "query.trace-id-compatibility"
+	queryAnonymizationEnabled  = "query.anonymization.enabled"
+	queryAnonymizationTags     = "query.anonymization.hashed-tags"
+	queryAnonymizationLogs     = "query.anonymization.stripped-log-fields"
+	queryHashSecretFile        = "query.hash-secret-file"
+	queryTimeoutDefault        = "query.timeout.default"
+	queryTimeoutServices       = "query.timeout.services"
+	queryTimeoutOperations     = "query.timeout.operations"
+	queryTimeoutFindTraces     = "query.timeout.find-traces"
+	queryTimeoutGetTrace       = "query.timeout.get-trace"
+	queryTimeoutDependencies   = "query.timeout.dependencies"
+	queryStorageHealthInterval = "query.storage-health-check.interval"
+	queryStorageHealthFailure  = "query.storage-health-check.failure-threshold"
+	queryFederationEndpoints   = "query.federation.endpoints"
+	queryRedactTags            = "query.redact-tags"
+	queryRedactionSubjects     = "query.redaction.unredacted-subjects"
+	queryRedactionSubjectHdr   = "query.redaction.subject-header"
+	queryFederationTimeout     = "query.federation.timeout"
+	queryMaxOperations         = "query.max-operations"
+	queryMaxBatchTraces        = "query.max-batch-traces"
+	queryMaxTraceSpans         = "query.max-trace-spans"
+	queryMaxTraversalSpans     = "query.max-traversal-spans"
+	queryTrimZeroDurationSpans = "query.trim-zero-duration-spans"
+	queryMaxDependencyLookback = "query.max-dependency-lookback"
+	queryDependenciesCacheTTL  = "query.dependencies-cache.ttl"
+	queryDependenciesCacheStep = "query.dependencies-cache.granularity"
+	queryDependenciesJitter    = "query.dependencies-cache.refresh-jitter"
+	queryReadRetriesAttempts   = "query.read-retries.max-attempts"
+	queryReadRetriesBackoff    = "query.read-retries.backoff"
+	queryReadRetriesFindTraces = "query.read-retries.find-traces"
+	queryOrphanSpans           = "query.orphan-spans"
+	queryDefaultSearchLimit    = "query.search.default-limit"
+	queryMaxSearchLimit        = "query.search.max-limit"
+	queryMaxLimit              = "query.max-limit"
+	queryMaxLookback           = "query.max-lookback"
+	queryMaxLookbackMode       = "query.max-lookback-mode"
+	queryDefaultLookback       = "query.default-lookback"
+	queryAllowedSearchTagKeys  = "query.search.allowed-tag-keys"
+	queryRequireServiceFilter  = "query.search.require-service"
+	queryWidenSteps            = "query.search.widen-steps"
+	queryWidenFactor           = "query.search.widen-factor"
+	queryWidenMaxWindow        = "query.search.widen-max-window"
+	queryActiveServicesWindow  = "query.services.active-within"
+	queryRateLimitRPS          = "query.rate-limit.requests-per-second"
+	queryRateLimitBurst        = "query.rate-limit.burst"
+	queryRateLimitMaxClients   = "query.rate-limit.max-clients"
+	queryRateLimitIPHeader     = "query.rate-limit.client-ip-header"
+	querySlowQueryThreshold    = "query.slow-query-threshold"
+	querySlowQueryLogSize      = "query.slow-query-log-size"
+	queryAdminTokenFile        = "query.admin.token-file"
 )
 
+// defaultDrainTimeout is how long the gRPC and the HTTP servers each let the running requests finish on shutdown.
+const defaultDrainTimeout = 10 * time.Second
+
+// defaultHTTPMaxHeaderBytes leaves room for large bearer tokens, above the 1 MiB default of net/http.
+const defaultHTTPMaxHeaderBytes = 2 * 1024 * 1024
+
+// defaultHTTPMaxRequestBodyBytes is ample for the trace IDs of the batch endpoint.
+const defaultHTTPMaxRequestBodyBytes = 1024 * 1024
+
 var tlsGRPCFlagsConfig = tlscfg.ServerFlagsConfig{
-	Prefix: "query.grpc",
+	Prefix:              "query.grpc",
+	EnableALPNProtocols: true,
+}
+
+var grpcServerFlagsConfig = grpccfg.ServerFlagsConfig{
+	Prefix: "query.grpc-server",
 }
 
 var tlsHTTPFlagsConfig = tlscfg.ServerFlagsConfig{
@@ -61,8 +139,11 @@ var tlsHTTPFlagsConfig = tlscfg.ServerFlagsConfig{
 
 // QueryOptionsStaticAssets contains configuration for handling static assets
 type QueryOptionsStaticAssets struct {
-	// Path is the path for the static assets for the UI (https://github.com/uber/jaeger-ui)
+	// Path is the path for the static assets for the UI (https://github.com/uber/jaeger-ui),
+	// either a directory or the http(s) URL of a tarball of the UI bundle downloaded at startup
 	Path string `valid:"optional" mapstructure:"path"`
+	// SHA256 is the hex-encoded SHA-256 checksum of the tarball, required when Path is a URL
+	SHA256 string `valid:"optional" mapstructure:"sha256"`
 	// LogAccess tells static handler to log access to static assets, useful in debugging
 	LogAccess bool `valid:"optional" mapstructure:"log_access"`
 }
@@ -82,10 +163,57 @@ type QueryOptionsBase struct {
 	AdditionalHeaders http.Header
 	// MaxClockSkewAdjust is the maximum duration by which jaeger-query will adjust a span
 	MaxClockSkewAdjust time.Duration
+	// OrphanSpans selects how the spans whose parent is missing from the trace are reattached, empty to leave them as is
+	OrphanSpans adjuster.OrphanSpansMode
 	// Tenancy configures tenancy for query
 	Tenancy tenancy.Options
 	// EnableTracing determines whether traces will be emitted by jaeger-query.
 	EnableTracing bool
+	// SelfTracing adds the phases of the HTTP API queries to their traces, and returns their trace ID
+	SelfTracing bool
+	// TraceIDCompatibility enables looking up 128-bit trace IDs by their lower 64 bits when not found
+	TraceIDCompatibility bool
+	// Anonymization configures the scrubbing of traces requested with anonymization
+	Anonymization querysvc.AnonymizationOptions
+	// Timeouts limits the duration of the storage queries of each endpoint
+	Timeouts querysvc.QueryTimeouts
+	// Redaction configures the redaction of the tags of the returned traces
+	Redaction querysvc.RedactionOptions
+	// HashKey is the secret key with which the tag values are hashed by anonymization and redaction
+	HashKey []byte
+	// SubjectHeader is the request header holding the authenticated subject, set by an authenticating proxy
+	SubjectHeader string
+	// MaxOperations caps the number of operations returned for a service, 0 means no cap
+	MaxOperations int
+	// MaxBatchTraces caps the number of traces requested at once from the batch endpoint, 0 means no cap
+	MaxBatchTraces int
+	// MaxTraceSpans caps the number of spans of the traces fetched by ID, 0 means no cap
+	MaxTraceSpans int
+	// MaxTraversalSpans caps the number of spans visited when following the references of a trace, 0 means no cap
+	MaxTraversalSpans int
+	// TrimZeroDurationSpans trims the zero-duration spans without logs from the returned traces, unless requested otherwise
+	TrimZeroDurationSpans bool
+	// MaxDependencyLookback caps the lookback of the dependencies requested, 0 means no cap
+	MaxDependencyLookback time.Duration
+	// DependenciesCache configures the cache of the dependency graphs
+	DependenciesCache querysvc.DependenciesCacheOptions
+	// ReadRetries configures the retries of the storage reads failed with a transient error
+	ReadRetries querysvc.ReadRetryOptions
+	// DefaultSearchLimit is the number of traces searched when the request does not specify a limit
+	DefaultSearchLimit int
+	// SearchGuardrails limits the time window and the number of traces of the searches
+	SearchGuardrails querysvc.SearchGuardrails
+	// RateLimit limits the rate of the requests of each client IP
+	RateLimit RateLimitOptions
+	// SearchWidening configures the widening of the time window of the searches finding no traces, requested with the widen parameter
+	SearchWidening querysvc.SearchWidening
+	// RequireServiceFilter rejects the searches without a service by all the query APIs
+	RequireServiceFilter bool
+	// AllowedSearchTagKeys restricts the tag keys of the searches, an empty list meaning no restriction
+	AllowedSearchTagKeys []string
+	// ActiveServicesWindow leaves out the services without traces within this window before now
+	// from the services listed by the HTTP API by default, 0 listing all the services
+	ActiveServicesWindow time.Duration
 }
 
 // QueryOptions holds configuration for query service
@@ -98,23 +226,120 @@ type QueryOptions struct {
 	GRPCHostPort string
 	// TLSGRPC configures secure transport (Consumer to Query service GRPC API)
 	TLSGRPC tlscfg.Options
+	// GRPCMaxReceiveMessageLength is the maximum size of the messages received by the gRPC server, 0 means the gRPC default
+	GRPCMaxReceiveMessageLength int
+	// GRPCServer tunes the gRPC server
+	GRPCServer grpccfg.ServerOptions
+	// HTTPMaxHeaderBytes is the maximum size of the request headers accepted by the HTTP server, 0 means the net/http default
+	HTTPMaxHeaderBytes int
+	// MaxRequestBodyBytes is the maximum size of the request bodies accepted by the HTTP API, 0 means no limit
+	MaxRequestBodyBytes int64
 	// TLSHTTP configures secure transport (Consumer to Query service HTTP API)
 	TLSHTTP tlscfg.Options
+	// StorageHealthCheckInterval is how often the storage is pinged to report the gRPC health status, 0 disables the checks
+	StorageHealthCheckInterval time.Duration
+	// StorageFailureThreshold is how long the storage must be failing before the gRPC services are reported as not serving
+	StorageFailureThreshold time.Duration
+	// Federation configures the upstream query services to read from instead of the local storage
+	Federation federation.Options
+	// SlowQueries configures the log of the storage reads slower than a threshold
+	SlowQueries slowquery.Options
+	// AdminTokenFile is the file holding the bearer token of the configuration endpoint of the admin
+	// server, which is disabled when empty
+	AdminTokenFile string
+	// GRPCDrainTimeout is how long the gRPC calls may run when the server is closed, which is drained first;
+	// the long-lived streams are ended right away, and 0 ends all the calls right away
+	GRPCDrainTimeout time.Duration
+	// HTTPDrainTimeout is how long the HTTP requests may run when the server is closed, after the gRPC server
+	// is drained; 0 interrupts them right away
+	HTTPDrainTimeout time.Duration
+	// AllowNoStorage lets NewServer accept a query service without span reader, e.g. in tests
+	AllowNoStorage bool
 }
 
 // AddFlags adds flags for QueryOptions
 func AddFlags(flagSet *flag.FlagSet) {
 	flagSet.Var(&config.StringSlice{}, queryAdditionalHeaders, `Additional HTTP response headers.  Can be specified multiple times.  Format: "Key: Value"`)
 	flagSet.String(queryHTTPHostPort, ports.PortToHostPort(ports.QueryHTTP), "The host:port (e.g. 127.0.0.1:14268 or :14268) of the query's HTTP server")
+	flagSet.Int(queryHTTPMaxHeaderBytes, defaultHTTPMaxHeaderBytes, "The maximum size of the request headers accepted by the query's HTTP server, e.g. to allow large bearer tokens; larger headers are rejected with 431 Request Header Fields Too Large")
+	flagSet.Int64(queryHTTPMaxBodyBytes, defaultHTTPMaxRequestBodyBytes, "The maximum size of the request bodies accepted by the query's HTTP API, e.g. by POST /api/traces/batch; larger bodies are rejected with 413 Request Entity Too Large; set to 0 for no limit")
 	flagSet.String(queryGRPCHostPort, ports.PortToHostPort(ports.QueryGRPC), "The host:port (e.g. 127.0.0.1:14250 or :14250) of the query's gRPC server")
+	flagSet.Int(queryGRPCMaxMessageSize, 4*1024*1024, "The maximum size of the messages received by the query's gRPC server")
+	flagSet.Duration(queryGRPCDrainTimeout, defaultDrainTimeout, "How long the running calls of the query's gRPC server may finish on shutdown, before which the server is drained; "+
+		"the long-lived streams, e.g. health watches, are ended right away with an Unavailable status; set to 0s to end all the calls right away")
+	flagSet.Duration(queryHTTPDrainTimeout, defaultDrainTimeout, "How long the running requests of the query's HTTP server may finish on shutdown, after the gRPC server is drained; "+
+		"set to 0s to interrupt them right away")
 	flagSet.String(queryBasePath, "/", "The base path for all HTTP routes, e.g. /jaeger; useful when running behind a reverse proxy. See https://github.com/jaegertracing/jaeger/blob/main/examples/reverse-proxy/README.md")
 	flagSet.String(queryStaticFiles, "", "The directory path override for the static assets for the UI")
+	flagSet.String(queryUIAssetsPath, "", "The UI bundle served instead of the embedded one, e.g. to test a custom jaeger-ui build: either a directory "+
+		"or the http(s) URL of a .tar.gz of the bundle downloaded at startup; takes precedence over "+queryStaticFiles)
+	flagSet.String(queryUIAssetsSHA256, "", "The hex-encoded SHA-256 checksum of the UI bundle tarball, required when "+queryUIAssetsPath+" is a URL")
 	flagSet.Bool(queryLogStaticAssetsAccess, false, "Log when static assets are accessed (for debugging)")
 	flagSet.String(queryUIConfig, "", "The path to the UI configuration file in JSON format")
 	flagSet.Bool(queryTokenPropagation, false, "Allow propagation of bearer token to be used by storage plugins")
 	flagSet.Duration(queryMaxClockSkewAdjust, 0, "The maximum delta by which span timestamps may be adjusted in the UI due to clock skew; set to 0s to disable clock skew adjustments")
+	flagSet.String(queryOrphanSpans, "", "How the spans whose parent is missing from the trace are reattached: root (re-parent them to the root span) or placeholder (attach them under a synthetic span per service); leave empty to keep them as is")
 	flagSet.Bool(queryEnableTracing, false, "Enables emitting jaeger-query traces")
+	flagSet.Bool(querySelfTracing, false, "Adds spans for the parameters validation, the storage access and the serialization to the traces of the HTTP API queries, "+
+		"and returns their trace ID in the "+SelfTraceIDHeader+" response header; the queries are only traced with tracing enabled, "+
+		"and their traces are stored like the others, which looking at them produces more of")
+	flagSet.Bool(queryAnonymizationEnabled, false, "Allow clients to request anonymized traces, e.g. for sharing them outside of the organization")
+	flagSet.String(queryAnonymizationTags, "user.id,http.url", "Comma-separated list of tag keys whose values are hashed in anonymized traces; only the query string of URL values is hashed")
+	flagSet.String(queryAnonymizationLogs, "", "Comma-separated list of log field keys that are removed from anonymized traces")
+	flagSet.String(queryHashSecretFile, "", "The path to a file holding the secret key of the HMAC-SHA256 hashing the tag values in anonymized traces "+
+		"and with the hash redaction strategy, required by both; the file is read at startup, and rotating the secret changes all the hashes, "+
+		"so that the values hashed before and after the rotation can no longer be correlated")
+	flagSet.Duration(queryTimeoutDefault, 0, "The default timeout of storage queries, used for the endpoints without a specific timeout; set to 0s for no timeout")
+	flagSet.Duration(queryTimeoutServices, 0, "The timeout of storage queries listing services; set to 0s to use the default timeout")
+	flagSet.Duration(queryTimeoutOperations, 0, "The timeout of storage queries listing operations; set to 0s to use the default timeout")
+	flagSet.Duration(queryTimeoutFindTraces, 0, "The timeout of storage queries searching traces; set to 0s to use the default timeout")
+	flagSet.Duration(queryTimeoutGetTrace, 0, "The timeout of storage queries fetching a trace by ID; set to 0s to use the default timeout")
+	flagSet.Duration(queryTimeoutDependencies, 0, "The timeout of storage queries fetching dependencies; set to 0s to use the default timeout")
+	flagSet.Var(&config.StringSlice{}, queryRedactTags, `Redact the values of the span tags, process tags and log fields whose keys match a regular expression.  Can be specified multiple times.  Format: "key-regex[:mask|hash|drop]", the default strategy being mask, e.g. ".*email.*:hash"`)
+	flagSet.String(queryRedactionSubjects, "", "Comma-separated list of the authenticated subjects allowed to see traces unredacted; requires "+queryRedactionSubjectHdr)
+	flagSet.String(queryRedactionSubjectHdr, "", "The HTTP header or gRPC metadata holding the authenticated subject of the requests; it must be set by a trusted authenticating proxy")
+	flagSet.Int(queryMaxOperations, 0, "The maximum number of operations returned for a service, in alphabetical order; set to 0 for no limit")
+	flagSet.Int(queryMaxBatchTraces, 100, "The maximum number of trace IDs accepted by the batch endpoint POST /api/traces/batch; set to 0 for no limit")
+	flagSet.Int(queryMaxTraceSpans, 0, "The maximum number of spans of a trace fetched by ID, larger traces being truncated with a warning; set to 0 for no limit")
+	flagSet.Int(queryMaxTraversalSpans, querysvc.DefaultMaxTraversalSpans, "The maximum number of spans visited when following the references of a trace, e.g. to compute its critical path, "+
+		"a partial result being returned with a warning for larger traces; set to 0 for no limit")
+	flagSet.Bool(queryTrimZeroDurationSpans, false, "Remove the zero-duration spans without logs, e.g. placeholder spans, from the returned traces, re-parenting their children; requests can override it with the trimZeroDurationSpans parameter")
+	flagSet.Duration(queryMaxDependencyLookback, 0, "The maximum lookback of the dependencies requested, larger lookbacks being reduced with a warning to protect the dependency storage; set to 0s for no limit")
+	flagSet.Duration(queryDependenciesCacheTTL, 0, "How long the dependency graphs are cached, the graphs requested after about half of it being refreshed in the background; set to 0s to disable the cache")
+	flagSet.Duration(queryDependenciesCacheStep, time.Minute, "The period the end times of the dependency requests are rounded up to, so that the requests of the same period share their cached graph; set to 0s for no rounding")
+	flagSet.Float64(queryDependenciesJitter, 0.1, "The fraction, between 0 and 1, by which the background refreshes of the cached dependency graphs are randomly brought forward, so that the query services sharing a storage do not refresh them in sync")
+	flagSet.Int(queryReadRetriesAttempts, 1, "The maximum number of attempts of the storage reads failed with a transient error, e.g. a timeout or an unavailable storage, "+
+		"within the deadline of the request; set to 1 for no retries")
+	flagSet.Duration(queryReadRetriesBackoff, 100*time.Millisecond, "The delay before the first retry of a storage read, doubled before each next retry")
+	flagSet.Bool(queryReadRetriesFindTraces, false, "Also retry the searches of traces, which are expensive for the storage, per "+queryReadRetriesAttempts)
+	flagSet.Int(queryDefaultSearchLimit, defaultQueryLimit, "The number of traces returned by a search that does not specify a limit")
+	flagSet.Duration(queryActiveServicesWindow, 0, "By default, list only the services with traces within this window before now in GET /api/services, "+
+		"probing each service for a recent trace; the activeWithin parameter overrides it, and services that cannot be probed are listed anyway; set to 0s to list all services")
+	flagSet.Int(queryMaxSearchLimit, 0, "(deprecated, use "+queryMaxLimit+") The maximum number of traces returned by a search")
+	flagSet.Int(queryMaxLimit, 0, "The maximum number of traces returned by a search, larger limits being reduced to it with a warning; set to 0 for no limit")
+	flagSet.Duration(queryMaxLookback, 0, "The maximum time window of a search, larger windows being clamped or rejected per "+queryMaxLookbackMode+"; set to 0s for no limit")
+	flagSet.String(queryMaxLookbackMode, querysvc.LookbackModeClamp, "How the searches exceeding "+queryMaxLookback+" are handled: clamp (reduce the window to its most recent part, with a warning) or reject")
+	flagSet.Duration(queryDefaultLookback, querysvc.DefaultSearchLookback, "The time window of the searches that do not specify a start time")
+	flagSet.Int(queryWidenSteps, 3, "The maximum number of times the time window of a search requested with the widen parameter is widened while it finds no traces; set to 0 to disable the widening")
+	flagSet.Int(queryWidenFactor, querysvc.DefaultSearchWideningFactor, "The factor, at least 2, by which the time window of a search is widened at each step")
+	flagSet.Duration(queryWidenMaxWindow, 0, "The maximum widened time window of a search, besides "+queryMaxLookback+"; set to 0s for no limit")
+	flagSet.Bool(queryRequireServiceFilter, false, "Reject the searches that do not specify a service, which scan the traces of all the services, with 400 Bad Request or InvalidArgument")
+	flagSet.String(queryAllowedSearchTagKeys, "", "Comma-separated list of the tag keys allowed in searches, e.g. the indexed tags of the storage; searches by other tags are rejected, and all tags are allowed if empty")
+	flagSet.Float64(queryRateLimitRPS, 0, "The rate of the requests allowed per client IP, applied separately by the HTTP and gRPC servers; larger rates are rejected with 429 Too Many Requests or ResourceExhausted; set to 0 for no limit")
+	flagSet.Int(queryRateLimitBurst, 20, "The number of requests a client IP can make at once above "+queryRateLimitRPS)
+	flagSet.Int(queryRateLimitMaxClients, 10000, "The maximum number of client IPs tracked by the rate limiter, the least recently seen ones being forgotten")
+	flagSet.String(queryRateLimitIPHeader, "", "The HTTP header or gRPC metadata holding the client IP, e.g. X-Forwarded-For, whose last IP is used; it must be set by a trusted proxy, the remote address being used otherwise")
+	flagSet.Bool(queryTraceIDCompatibility, false, "When a 128-bit trace ID is not found, also look up its lower 64 bits, as emitted by clients that only support 64-bit trace IDs; this doubles the storage lookups for missing traces")
+	flagSet.Duration(queryStorageHealthInterval, 10*time.Second, "How often the storage is pinged to report the status of the gRPC health service; set to 0s to disable storage health checks")
+	flagSet.Duration(queryStorageHealthFailure, 30*time.Second, "How long the storage must be failing before the gRPC health service reports the query services as not serving")
+	flagSet.Var(&config.StringSlice{}, queryFederationEndpoints, `The gRPC endpoints of upstream Jaeger query services to read traces from instead of the local storage.  Can be specified multiple times.  Format: "host:port[;option=value...]", where the options are tls.enabled, tls.ca, tls.cert, tls.key, tls.server-name, tls.skip-host-verify and header.<name>, e.g. "jaeger-eu:16685;tls.enabled=true;header.x-tenant=acme"`)
+	flagSet.Duration(queryFederationTimeout, 10*time.Second, "The timeout of the requests to each upstream query service in federation mode; set to 0s for no timeout")
+	flagSet.Duration(querySlowQueryThreshold, 0, "The duration over which a storage read is logged as a slow query, at a bounded rate, and kept for "+slowquery.Path+" on the admin server; set to 0s to disable the slow query log")
+	flagSet.Int(querySlowQueryLogSize, 100, "The number of the last slow queries kept for "+slowquery.Path+" on the admin server")
+	flagSet.String(queryAdminTokenFile, "", "The path to a file holding the bearer token required by "+AdminConfigPath+" on the admin server, "+
+		"which returns the effective query configuration with the paths and secrets redacted; the endpoint is disabled when empty")
 	tlsGRPCFlagsConfig.AddFlags(flagSet)
+	grpcServerFlagsConfig.AddFlags(flagSet)
 	tlsHTTPFlagsConfig.AddFlags(flagSet)
 }
 
@@ -127,6 +352,25 @@ func (qOpts *QueryOptions) InitFromViper(v *viper.Viper, logger *zap.Logger) (*Q
 		return qOpts, fmt.Errorf("failed to process gRPC TLS options: %w", err)
 	}
 	qOpts.TLSGRPC = tlsGrpc
+	qOpts.GRPCMaxReceiveMessageLength = v.GetInt(queryGRPCMaxMessageSize)
+	qOpts.GRPCDrainTimeout = v.GetDuration(queryGRPCDrainTimeout)
+	qOpts.HTTPDrainTimeout = v.GetDuration(queryHTTPDrainTimeout)
+	if qOpts.GRPCMaxReceiveMessageLength < 0 {
+		return qOpts, fmt.Errorf("the maximum message size of the gRPC server cannot be negative: %d", qOpts.GRPCMaxReceiveMessageLength)
+	}
+	grpcServer, err := grpcServerFlagsConfig.InitFromViper(v)
+	if err != nil {
+		return qOpts, err
+	}
+	qOpts.GRPCServer = grpcServer
+	qOpts.HTTPMaxHeaderBytes = v.GetInt(queryHTTPMaxHeaderBytes)
+	if qOpts.HTTPMaxHeaderBytes < 0 {
+		return qOpts, fmt.Errorf("the maximum header size of the HTTP server cannot be negative: %d", qOpts.HTTPMaxHeaderBytes)
+	}
+	qOpts.MaxRequestBodyBytes = v.GetInt64(queryHTTPMaxBodyBytes)
+	if qOpts.MaxRequestBodyBytes < 0 {
+		return qOpts, fmt.Errorf("the maximum request body size of the HTTP server cannot be negative: %d", qOpts.MaxRequestBodyBytes)
+	}
 	tlsHTTP, err := tlsHTTPFlagsConfig.InitFromViper(v)
 	if err != nil {
 		return qOpts, fmt.Errorf("failed to process HTTP TLS options: %w", err)
@@ -134,11 +378,23 @@ func (qOpts *QueryOptions) InitFromViper(v *viper.Viper, logger *zap.Logger) (*Q
 	qOpts.TLSHTTP = tlsHTTP
 	qOpts.BasePath = v.GetString(queryBasePath)
 	qOpts.StaticAssets.Path = v.GetString(queryStaticFiles)
+	if uiAssetsPath := v.GetString(queryUIAssetsPath); uiAssetsPath != "" {
+		qOpts.StaticAssets.Path = uiAssetsPath
+	}
+	qOpts.StaticAssets.SHA256 = v.GetString(queryUIAssetsSHA256)
+	if isUIAssetsURL(qOpts.StaticAssets.Path) && qOpts.StaticAssets.SHA256 == "" {
+		return qOpts, fmt.Errorf("%s is required to verify the UI bundle downloaded from %s", queryUIAssetsSHA256, qOpts.StaticAssets.Path)
+	}
 	qOpts.StaticAssets.LogAccess = v.GetBool(queryLogStaticAssetsAccess)
 	qOpts.UIConfig = v.GetString(queryUIConfig)
 	qOpts.BearerTokenPropagation = v.GetBool(queryTokenPropagation)
 
 	qOpts.MaxClockSkewAdjust = v.GetDuration(queryMaxClockSkewAdjust)
+	qOpts.OrphanSpans = adjuster.OrphanSpansMode(v.GetString(queryOrphanSpans))
+	if qOpts.OrphanSpans != "" && !adjuster.ValidOrphanSpansMode(qOpts.OrphanSpans) {
+		return qOpts, fmt.Errorf("invalid %s %q, expected %s or %s", queryOrphanSpans,
+			qOpts.OrphanSpans, adjuster.OrphanSpansRoot, adjuster.OrphanSpansPlaceholder)
+	}
 	stringSlice := v.GetStringSlice(queryAdditionalHeaders)
 	headers, err := stringSliceAsHeader(stringSlice)
 	if err != nil {
@@ -148,6 +404,133 @@ func (qOpts *QueryOptions) InitFromViper(v *viper.Viper, logger *zap.Logger) (*Q
 	}
 	qOpts.Tenancy = tenancy.InitFromViper(v)
 	qOpts.EnableTracing = v.GetBool(queryEnableTracing)
+	qOpts.SelfTracing = v.GetBool(querySelfTracing)
+	qOpts.TraceIDCompatibility = v.GetBool(queryTraceIDCompatibility)
+	qOpts.MaxOperations = v.GetInt(queryMaxOperations)
+	qOpts.MaxBatchTraces = v.GetInt(queryMaxBatchTraces)
+	qOpts.MaxTraceSpans = v.GetInt(queryMaxTraceSpans)
+	qOpts.MaxTraversalSpans = v.GetInt(queryMaxTraversalSpans)
+	qOpts.TrimZeroDurationSpans = v.GetBool(queryTrimZeroDurationSpans)
+	qOpts.MaxDependencyLookback = v.GetDuration(queryMaxDependencyLookback)
+	if qOpts.MaxDependencyLookback < 0 {
+		return qOpts, fmt.Errorf("the maximum lookback of %s cannot be negative: %v", queryMaxDependencyLookback, qOpts.MaxDependencyLookback)
+	}
+	qOpts.DependenciesCache = querysvc.DependenciesCacheOptions{
+		TTL:           v.GetDuration(queryDependenciesCacheTTL),
+		Granularity:   v.GetDuration(queryDependenciesCacheStep),
+		RefreshJitter: v.GetFloat64(queryDependenciesJitter),
+	}
+	if qOpts.DependenciesCache.TTL < 0 || qOpts.DependenciesCache.Granularity < 0 {
+		return qOpts, fmt.Errorf("invalid dependencies cache: %s and %s cannot be negative",
+			queryDependenciesCacheTTL, queryDependenciesCacheStep)
+	}
+	if jitter := qOpts.DependenciesCache.RefreshJitter; jitter < 0 || jitter > 1 {
+		return qOpts, fmt.Errorf("invalid dependencies cache: %s must be between 0 and 1: %v", queryDependenciesJitter, jitter)
+	}
+	qOpts.ReadRetries = querysvc.ReadRetryOptions{
+		MaxAttempts: v.GetInt(queryReadRetriesAttempts),
+		Backoff:     v.GetDuration(queryReadRetriesBackoff),
+		FindTraces:  v.GetBool(queryReadRetriesFindTraces),
+	}
+	if qOpts.ReadRetries.MaxAttempts < 1 {
+		return qOpts, fmt.Errorf("invalid read retries: %s must be at least 1: %d", queryReadRetriesAttempts, qOpts.ReadRetries.MaxAttempts)
+	}
+	if qOpts.ReadRetries.Backoff < 0 {
+		return qOpts, fmt.Errorf("invalid read retries: %s cannot be negative: %v", queryReadRetriesBackoff, qOpts.ReadRetries.Backoff)
+	}
+	qOpts.SearchWidening = querysvc.SearchWidening{
+		Steps:     v.GetInt(queryWidenSteps),
+		Factor:    v.GetInt(queryWidenFactor),
+		MaxWindow: v.GetDuration(queryWidenMaxWindow),
+	}
+	if qOpts.SearchWidening.Steps < 0 || qOpts.SearchWidening.MaxWindow < 0 {
+		return qOpts, fmt.Errorf("invalid search widening: %s and %s cannot be negative", queryWidenSteps, queryWidenMaxWindow)
+	}
+	if qOpts.SearchWidening.Factor < 2 {
+		return qOpts, fmt.Errorf("invalid search widening: %s must be at least 2: %d", queryWidenFactor, qOpts.SearchWidening.Factor)
+	}
+	qOpts.DefaultSearchLimit = v.GetInt(queryDefaultSearchLimit)
+	qOpts.ActiveServicesWindow = v.GetDuration(queryActiveServicesWindow)
+	if qOpts.ActiveServicesWindow < 0 {
+		return qOpts, fmt.Errorf("the window of %s cannot be negative: %v", queryActiveServicesWindow, qOpts.ActiveServicesWindow)
+	}
+	qOpts.SearchGuardrails = querysvc.SearchGuardrails{
+		MaxLimit:        v.GetInt(queryMaxLimit),
+		MaxLookback:     v.GetDuration(queryMaxLookback),
+		LookbackMode:    v.GetString(queryMaxLookbackMode),
+		DefaultLookback: v.GetDuration(queryDefaultLookback),
+	}
+	if qOpts.SearchGuardrails.MaxLimit == 0 {
+		qOpts.SearchGuardrails.MaxLimit = v.GetInt(queryMaxSearchLimit)
+	}
+	switch qOpts.SearchGuardrails.LookbackMode {
+	case querysvc.LookbackModeClamp, querysvc.LookbackModeReject:
+	default:
+		return qOpts, fmt.Errorf("invalid %s %q, expected %s or %s", queryMaxLookbackMode,
+			qOpts.SearchGuardrails.LookbackMode, querysvc.LookbackModeClamp, querysvc.LookbackModeReject)
+	}
+	qOpts.RequireServiceFilter = v.GetBool(queryRequireServiceFilter)
+	qOpts.AllowedSearchTagKeys = splitList(v.GetString(queryAllowedSearchTagKeys))
+	qOpts.RateLimit = RateLimitOptions{
+		RequestsPerSecond: v.GetFloat64(queryRateLimitRPS),
+		Burst:             v.GetInt(queryRateLimitBurst),
+		MaxClients:        v.GetInt(queryRateLimitMaxClients),
+		ClientIPHeader:    v.GetString(queryRateLimitIPHeader),
+	}
+	if qOpts.RateLimit.RequestsPerSecond < 0 || qOpts.RateLimit.RequestsPerSecond > 0 && (qOpts.RateLimit.Burst < 1 || qOpts.RateLimit.MaxClients < 1) {
+		return qOpts, fmt.Errorf("invalid rate limit: %s must not be negative, and %s and %s must be positive",
+			queryRateLimitRPS, queryRateLimitBurst, queryRateLimitMaxClients)
+	}
+	qOpts.Timeouts = querysvc.QueryTimeouts{
+		Default:      v.GetDuration(queryTimeoutDefault),
+		Services:     v.GetDuration(queryTimeoutServices),
+		Operations:   v.GetDuration(queryTimeoutOperations),
+		FindTraces:   v.GetDuration(queryTimeoutFindTraces),
+		GetTrace:     v.GetDuration(queryTimeoutGetTrace),
+		Dependencies: v.GetDuration(queryTimeoutDependencies),
+	}
+	qOpts.Anonymization = querysvc.AnonymizationOptions{
+		Enabled:           v.GetBool(queryAnonymizationEnabled),
+		HashedTags:        splitList(v.GetString(queryAnonymizationTags)),
+		StrippedLogFields: splitList(v.GetString(queryAnonymizationLogs)),
+	}
+	qOpts.Redaction.UnredactedSubjects = splitList(v.GetString(queryRedactionSubjects))
+	qOpts.SubjectHeader = v.GetString(queryRedactionSubjectHdr)
+	for _, s := range v.GetStringSlice(queryRedactTags) {
+		rule, err := querysvc.ParseRedactionRule(s)
+		if err != nil {
+			return qOpts, err
+		}
+		qOpts.Redaction.Rules = append(qOpts.Redaction.Rules, rule)
+	}
+	if hashSecretFile := v.GetString(queryHashSecretFile); hashSecretFile != "" {
+		key, err := readHashKey(hashSecretFile)
+		if err != nil {
+			return qOpts, err
+		}
+		qOpts.HashKey = key
+	} else if qOpts.Anonymization.Enabled || hasHashRedaction(qOpts.Redaction.Rules) {
+		return qOpts, fmt.Errorf("%s is required to hash the tag values in anonymized or redacted traces", queryHashSecretFile)
+	}
+	qOpts.StorageHealthCheckInterval = v.GetDuration(queryStorageHealthInterval)
+	qOpts.StorageFailureThreshold = v.GetDuration(queryStorageHealthFailure)
+	qOpts.Federation.Timeout = v.GetDuration(queryFederationTimeout)
+	for _, s := range v.GetStringSlice(queryFederationEndpoints) {
+		endpoint, err := federation.ParseEndpoint(s)
+		if err != nil {
+			return qOpts, err
+		}
+		qOpts.Federation.Endpoints = append(qOpts.Federation.Endpoints, endpoint)
+	}
+	qOpts.AdminTokenFile = v.GetString(queryAdminTokenFile)
+	qOpts.SlowQueries = slowquery.Options{
+		Threshold:  v.GetDuration(querySlowQueryThreshold),
+		BufferSize: v.GetInt(querySlowQueryLogSize),
+	}
+	if qOpts.SlowQueries.Threshold < 0 || qOpts.SlowQueries.Threshold > 0 && qOpts.SlowQueries.BufferSize < 1 {
+		return qOpts, fmt.Errorf("invalid slow query log: %s must not be negative, and %s must be positive",
+			querySlowQueryThreshold, querySlowQueryLogSize)
+	}
 	return qOpts, nil
 }
 
@@ -158,11 +541,62 @@ func (qOpts *QueryOptions) BuildQueryServiceOptions(storageFactory storage.Facto
 		logger.Info("Archive storage not initialized")
 	}
 
-	opts.Adjuster = adjuster.Sequence(querysvc.StandardAdjusters(qOpts.MaxClockSkewAdjust)...)
+	opts.Adjuster = adjuster.Sequence(querysvc.ConfiguredAdjusters(qOpts.MaxClockSkewAdjust, qOpts.OrphanSpans)...)
+	opts.TraceIDCompatibility = qOpts.TraceIDCompatibility
+	opts.Anonymization = qOpts.Anonymization
+	opts.Timeouts = qOpts.Timeouts
+	opts.Redaction = qOpts.Redaction
+	opts.HashKey = qOpts.HashKey
+	opts.MaxOperations = qOpts.MaxOperations
+	opts.MaxBatchTraces = qOpts.MaxBatchTraces
+	opts.MaxTraceSpans = qOpts.MaxTraceSpans
+	opts.MaxTraversalSpans = qOpts.MaxTraversalSpans
+	opts.TrimZeroDurationSpans = qOpts.TrimZeroDurationSpans
+	opts.MaxDependencyLookback = qOpts.MaxDependencyLookback
+	opts.DependenciesCache = qOpts.DependenciesCache
+	opts.ReadRetries = qOpts.ReadRetries
+	opts.SearchWidening = qOpts.SearchWidening
+	opts.SelfTracing = qOpts.SelfTracing
+	opts.SearchGuardrails = qOpts.SearchGuardrails
+	opts.SearchGuardrails.RequireService = qOpts.RequireServiceFilter
+	opts.TenancyMgr = tenancy.NewManager(&qOpts.Tenancy)
 
 	return opts
 }
 
+// readHashKey reads the secret key of the hashes of the tag values from a file.
+func readHashKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the hash secret file: %w", err)
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("the hash secret file %s is empty", path)
+	}
+	return key, nil
+}
+
+func hasHashRedaction(rules []querysvc.RedactionRule) bool {
+	for _, rule := range rules {
+		if rule.Strategy == querysvc.RedactionHash {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList parses a comma-separated list, ignoring whitespace and empty elements
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 // stringSliceAsHeader parses a slice of strings and returns a http.Header.
 // Each string in the slice is expected to be in the format "key: value"
 func stringSliceAsHeader(slice []string) (http.Header, error) {
@@ -17,6 +17,8 @@ package app
 
 import (
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -25,13 +27,21 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/federation"
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/grpccfg"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/ports"
 	"github.com/jaegertracing/jaeger/storage/mocks"
+	"github.com/jaegertracing/jaeger/storage/slowquery"
 	spanstore_mocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
 )
 
 func TestQueryBuilderFlags(t *testing.T) {
+	hashSecretFile := filepath.Join(t.TempDir(), "hash-secret")
+	require.NoError(t, os.WriteFile(hashSecretFile, []byte("s3cret\n"), 0o600))
 	v, command := config.Viperize(AddFlags)
 	command.ParseFlags([]string{
 		"--query.static-files=/dev/null",
@@ -40,9 +50,68 @@ func TestQueryBuilderFlags(t *testing.T) {
 		"--query.base-path=/jaeger",
 		"--query.http-server.host-port=127.0.0.1:8080",
 		"--query.grpc-server.host-port=127.0.0.1:8081",
+		"--query.grpc-server.max-message-size=8388608",
+		"--query.grpc-server.drain-timeout=30s",
+		"--query.http-server.drain-timeout=0s",
+		"--query.http-server.max-header-bytes=4194304",
+		"--query.http-server.max-request-body-bytes=2048",
+		"--query.grpc-server.max-concurrent-streams=100",
+		"--query.grpc-server.keepalive.min-time=1m",
 		"--query.additional-headers=access-control-allow-origin:blerg",
 		"--query.additional-headers=whatever:thing",
 		"--query.max-clock-skew-adjustment=10s",
+		"--query.storage-health-check.interval=5s",
+		"--query.storage-health-check.failure-threshold=1m",
+		"--query.trace-id-compatibility=true",
+		"--query.self-tracing=true",
+		"--query.anonymization.enabled=true",
+		"--query.anonymization.hashed-tags=user.id, customer.email,",
+		"--query.anonymization.stripped-log-fields=message",
+		"--query.hash-secret-file=" + hashSecretFile,
+		"--query.timeout.default=30s",
+		"--query.timeout.services=1s",
+		"--query.timeout.operations=2s",
+		"--query.timeout.find-traces=3s",
+		"--query.timeout.get-trace=4s",
+		"--query.timeout.dependencies=5s",
+		"--query.federation.endpoints=jaeger-us:16685",
+		"--query.federation.endpoints=jaeger-eu:16685;tls.enabled=true;header.x-tenant=acme",
+		"--query.federation.timeout=3s",
+		"--query.redact-tags=user.email",
+		"--query.redact-tags=.*token:drop",
+		"--query.redaction.unredacted-subjects=alice, bob",
+		"--query.redaction.subject-header=X-Forwarded-User",
+		"--query.max-operations=500",
+		"--query.max-batch-traces=20",
+		"--query.max-trace-spans=10000",
+		"--query.max-traversal-spans=5000",
+		"--query.trim-zero-duration-spans=true",
+		"--query.max-dependency-lookback=168h",
+		"--query.dependencies-cache.ttl=5m",
+		"--query.dependencies-cache.granularity=30s",
+		"--query.dependencies-cache.refresh-jitter=0.25",
+		"--query.read-retries.max-attempts=3",
+		"--query.read-retries.backoff=50ms",
+		"--query.read-retries.find-traces=true",
+		"--query.search.widen-steps=2",
+		"--query.search.widen-factor=3",
+		"--query.search.widen-max-window=24h",
+		"--query.orphan-spans=placeholder",
+		"--query.search.default-limit=50",
+		"--query.max-limit=500",
+		"--query.max-lookback=72h",
+		"--query.max-lookback-mode=reject",
+		"--query.default-lookback=1h",
+		"--query.search.allowed-tag-keys=error, http.status_code",
+		"--query.search.require-service=true",
+		"--query.services.active-within=24h",
+		"--query.slow-query-threshold=2s",
+		"--query.slow-query-log-size=50",
+		"--query.admin.token-file=/etc/jaeger/admin-token",
+		"--query.rate-limit.requests-per-second=2.5",
+		"--query.rate-limit.burst=5",
+		"--query.rate-limit.max-clients=100",
+		"--query.rate-limit.client-ip-header=X-Forwarded-For",
 	})
 	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
 	require.NoError(t, err)
@@ -52,11 +121,252 @@ func TestQueryBuilderFlags(t *testing.T) {
 	assert.Equal(t, "/jaeger", qOpts.BasePath)
 	assert.Equal(t, "127.0.0.1:8080", qOpts.HTTPHostPort)
 	assert.Equal(t, "127.0.0.1:8081", qOpts.GRPCHostPort)
+	assert.Equal(t, 8388608, qOpts.GRPCMaxReceiveMessageLength)
+	assert.Equal(t, 30*time.Second, qOpts.GRPCDrainTimeout)
+	assert.Zero(t, qOpts.HTTPDrainTimeout)
+	assert.Equal(t, 4194304, qOpts.HTTPMaxHeaderBytes)
+	assert.Equal(t, int64(2048), qOpts.MaxRequestBodyBytes)
+	assert.Equal(t, grpccfg.ServerOptions{
+		MaxConcurrentStreams: 100,
+		KeepaliveMinTime:     time.Minute,
+	}, qOpts.GRPCServer)
 	assert.Equal(t, http.Header{
 		"Access-Control-Allow-Origin": []string{"blerg"},
 		"Whatever":                    []string{"thing"},
 	}, qOpts.AdditionalHeaders)
 	assert.Equal(t, 10*time.Second, qOpts.MaxClockSkewAdjust)
+	assert.Equal(t, 5*time.Second, qOpts.StorageHealthCheckInterval)
+	assert.Equal(t, time.Minute, qOpts.StorageFailureThreshold)
+	assert.True(t, qOpts.TraceIDCompatibility)
+	assert.True(t, qOpts.SelfTracing)
+	assert.Equal(t, querysvc.AnonymizationOptions{
+		Enabled:           true,
+		HashedTags:        []string{"user.id", "customer.email"},
+		StrippedLogFields: []string{"message"},
+	}, qOpts.Anonymization)
+	assert.Equal(t, []byte("s3cret"), qOpts.HashKey)
+	assert.Equal(t, querysvc.QueryTimeouts{
+		Default:      30 * time.Second,
+		Services:     time.Second,
+		Operations:   2 * time.Second,
+		FindTraces:   3 * time.Second,
+		GetTrace:     4 * time.Second,
+		Dependencies: 5 * time.Second,
+	}, qOpts.Timeouts)
+	assert.Equal(t, federation.Options{
+		Endpoints: []federation.Endpoint{
+			{HostPort: "jaeger-us:16685"},
+			{
+				HostPort: "jaeger-eu:16685",
+				TLS:      tlscfg.Options{Enabled: true},
+				Headers:  map[string]string{"x-tenant": "acme"},
+			},
+		},
+		Timeout: 3 * time.Second,
+	}, qOpts.Federation)
+	require.Len(t, qOpts.Redaction.Rules, 2)
+	assert.Equal(t, "^(?:user.email)$", qOpts.Redaction.Rules[0].KeyPattern.String())
+	assert.Equal(t, querysvc.RedactionMask, qOpts.Redaction.Rules[0].Strategy)
+	assert.Equal(t, "^(?:.*token)$", qOpts.Redaction.Rules[1].KeyPattern.String())
+	assert.Equal(t, querysvc.RedactionDrop, qOpts.Redaction.Rules[1].Strategy)
+	assert.Equal(t, []string{"alice", "bob"}, qOpts.Redaction.UnredactedSubjects)
+	assert.Equal(t, "X-Forwarded-User", qOpts.SubjectHeader)
+	assert.Equal(t, 500, qOpts.MaxOperations)
+	assert.Equal(t, 20, qOpts.MaxBatchTraces)
+	assert.Equal(t, 10000, qOpts.MaxTraceSpans)
+	assert.Equal(t, 5000, qOpts.MaxTraversalSpans)
+	assert.True(t, qOpts.TrimZeroDurationSpans)
+	assert.Equal(t, 7*24*time.Hour, qOpts.MaxDependencyLookback)
+	assert.Equal(t, querysvc.DependenciesCacheOptions{TTL: 5 * time.Minute, Granularity: 30 * time.Second, RefreshJitter: 0.25}, qOpts.DependenciesCache)
+	assert.Equal(t, querysvc.ReadRetryOptions{MaxAttempts: 3, Backoff: 50 * time.Millisecond, FindTraces: true}, qOpts.ReadRetries)
+	assert.Equal(t, querysvc.SearchWidening{Steps: 2, Factor: 3, MaxWindow: 24 * time.Hour}, qOpts.SearchWidening)
+	assert.Equal(t, adjuster.OrphanSpansPlaceholder, qOpts.OrphanSpans)
+	assert.Equal(t, RateLimitOptions{
+		RequestsPerSecond: 2.5,
+		Burst:             5,
+		MaxClients:        100,
+		ClientIPHeader:    "X-Forwarded-For",
+	}, qOpts.RateLimit)
+	assert.Equal(t, 50, qOpts.DefaultSearchLimit)
+	assert.Equal(t, querysvc.SearchGuardrails{
+		MaxLimit:        500,
+		MaxLookback:     72 * time.Hour,
+		LookbackMode:    querysvc.LookbackModeReject,
+		DefaultLookback: time.Hour,
+	}, qOpts.SearchGuardrails)
+	assert.True(t, qOpts.RequireServiceFilter)
+	assert.Equal(t, []string{"error", "http.status_code"}, qOpts.AllowedSearchTagKeys)
+	assert.Equal(t, 24*time.Hour, qOpts.ActiveServicesWindow)
+	assert.Equal(t, slowquery.Options{Threshold: 2 * time.Second, BufferSize: 50}, qOpts.SlowQueries)
+	assert.Equal(t, "/etc/jaeger/admin-token", qOpts.AdminTokenFile)
+}
+
+func TestQueryBuilderSearchGuardrailsFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{})
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, querysvc.SearchGuardrails{
+		LookbackMode:    querysvc.LookbackModeClamp,
+		DefaultLookback: querysvc.DefaultSearchLookback,
+	}, qOpts.SearchGuardrails)
+
+	command.ParseFlags([]string{"--query.search.max-limit=200"})
+	qOpts, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 200, qOpts.SearchGuardrails.MaxLimit)
+
+	command.ParseFlags([]string{"--query.max-lookback-mode=drop"})
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `invalid query.max-lookback-mode "drop"`)
+}
+
+func TestQueryBuilderUIAssetsFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--query.static-files=/dev/null",
+		"--query.ui-assets-path=https://example.com/jaeger-ui.tar.gz",
+		"--query.ui-assets-sha256=abcd",
+	})
+	qOpts, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, QueryOptionsStaticAssets{Path: "https://example.com/jaeger-ui.tar.gz", SHA256: "abcd"}, qOpts.StaticAssets)
+
+	v, command = config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--query.ui-assets-path=https://example.com/jaeger-ui.tar.gz"})
+	_, err = new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "query.ui-assets-sha256 is required")
+}
+
+func TestQueryBuilderNegativeActiveServicesWindow(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--query.services.active-within=-1h"})
+	_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "query.services.active-within cannot be negative")
+}
+
+func TestQueryBuilderNegativeMaxDependencyLookback(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--query.max-dependency-lookback=-1h"})
+	_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "query.max-dependency-lookback cannot be negative")
+}
+
+func TestQueryBuilderInvalidDependenciesCache(t *testing.T) {
+	for _, flag := range []string{
+		"--query.dependencies-cache.ttl=-1m",
+		"--query.dependencies-cache.refresh-jitter=-0.1",
+		"--query.dependencies-cache.refresh-jitter=1.5",
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid dependencies cache", flag)
+	}
+}
+
+func TestQueryBuilderInvalidReadRetries(t *testing.T) {
+	for _, flag := range []string{
+		"--query.read-retries.max-attempts=0",
+		"--query.read-retries.backoff=-1s",
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid read retries", flag)
+	}
+}
+
+func TestQueryBuilderInvalidSearchWidening(t *testing.T) {
+	for _, flag := range []string{
+		"--query.search.widen-steps=-1",
+		"--query.search.widen-factor=1",
+		"--query.search.widen-max-window=-1h",
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{flag})
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid search widening", flag)
+	}
+}
+
+func TestQueryBuilderBadSlowQueryFlags(t *testing.T) {
+	for _, flags := range [][]string{
+		{"--query.slow-query-threshold=-1s"},
+		{"--query.slow-query-threshold=1s", "--query.slow-query-log-size=0"},
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags(flags)
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "invalid slow query log")
+	}
+}
+
+func TestQueryBuilderBadOrphanSpansFlag(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{"--query.orphan-spans=drop"})
+	_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `invalid query.orphan-spans "drop"`)
+}
+
+func TestQueryBuilderBadRedactionFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--query.redact-tags=user.(email",
+	})
+	_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "invalid tag key pattern")
+}
+
+func TestQueryBuilderHashSecretFlags(t *testing.T) {
+	for _, flags := range [][]string{
+		{"--query.anonymization.enabled=true"},
+		{"--query.redact-tags=.*email:hash"},
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags(flags)
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, "query.hash-secret-file is required")
+	}
+
+	emptyFile := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(emptyFile, []byte(" \n"), 0o600))
+	for file, msg := range map[string]string{
+		emptyFile:              "is empty",
+		emptyFile + ".missing": "failed to read the hash secret file",
+	} {
+		v, command := config.Viperize(AddFlags)
+		command.ParseFlags([]string{"--query.hash-secret-file=" + file})
+		_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+		require.ErrorContains(t, err, msg)
+	}
+}
+
+func TestQueryBuilderBadFederationFlags(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	command.ParseFlags([]string{
+		"--query.federation.endpoints=jaeger-us:16685;tls.enabled=maybe",
+	})
+	_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, "invalid value of option \"tls.enabled\"")
+}
+
+func TestQueryBuilderBadGRPCServerFlags(t *testing.T) {
+	for _, flag := range []string{
+		"--query.grpc-server.max-message-size=-1",
+		"--query.grpc-server.keepalive.timeout=-1s",
+		"--query.http-server.max-header-bytes=-1",
+		"--query.http-server.max-request-body-bytes=-1",
+		"--query.rate-limit.requests-per-second=-1",
+		"--query.rate-limit.requests-per-second=1 --query.rate-limit.burst=0",
+	} {
+		t.Run(flag, func(t *testing.T) {
+			v, command := config.Viperize(AddFlags)
+			require.NoError(t, command.ParseFlags(strings.Fields(flag)))
+			_, err := new(QueryOptions).InitFromViper(v, zap.NewNop())
+			require.Error(t, err)
+		})
+	}
 }
 
 func TestQueryBuilderBadHeadersFlags(t *testing.T) {
@@ -105,6 +415,20 @@ func TestBuildQueryServiceOptions(t *testing.T) {
 	qSvcOpts := qOpts.BuildQueryServiceOptions(&mocks.Factory{}, zap.NewNop())
 	assert.NotNil(t, qSvcOpts)
 	assert.NotNil(t, qSvcOpts.Adjuster)
+	assert.False(t, qSvcOpts.TraceIDCompatibility)
+	assert.Equal(t, querysvc.QueryTimeouts{}, qSvcOpts.Timeouts)
+	assert.Empty(t, qSvcOpts.Redaction.Rules)
+	assert.Zero(t, qSvcOpts.MaxOperations)
+	assert.Equal(t, 100, qSvcOpts.MaxBatchTraces)
+	assert.Zero(t, qSvcOpts.MaxTraceSpans)
+	assert.Equal(t, querysvc.DefaultMaxTraversalSpans, qSvcOpts.MaxTraversalSpans)
+	assert.False(t, qSvcOpts.TrimZeroDurationSpans)
+	assert.Zero(t, qSvcOpts.MaxDependencyLookback)
+	assert.Equal(t, querysvc.DependenciesCacheOptions{Granularity: time.Minute, RefreshJitter: 0.1}, qSvcOpts.DependenciesCache)
+	assert.Equal(t, querysvc.ReadRetryOptions{MaxAttempts: 1, Backoff: 100 * time.Millisecond}, qSvcOpts.ReadRetries)
+	assert.Equal(t, querysvc.SearchWidening{Steps: 3, Factor: querysvc.DefaultSearchWideningFactor}, qSvcOpts.SearchWidening)
+	assert.False(t, qSvcOpts.SelfTracing)
+	assert.False(t, qSvcOpts.SearchGuardrails.RequireService)
 	assert.Nil(t, qSvcOpts.ArchiveSpanReader)
 	assert.Nil(t, qSvcOpts.ArchiveSpanWriter)
 
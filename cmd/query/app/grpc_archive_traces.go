@@ -0,0 +1,100 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+var _ api_v2.TraceArchiveServiceServer = (*GRPCHandler)(nil)
+
+// ArchiveTraces is the gRPC handler archiving several traces, selected by their IDs or by a search query.
+// The outcome of each trace is streamed as soon as it is known. When the deadline of the request is exceeded,
+// the archiving stops and the request fails after the outcomes of the traces archived so far.
+func (g *GRPCHandler) ArchiveTraces(r *api_v2.ArchiveTracesRequest, stream api_v2.TraceArchiveService_ArchiveTracesServer) error {
+	if r == nil {
+		return errNilRequest
+	}
+	params, err := parseArchiveTracesRequest(r)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid ArchiveTraces request: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(querysvc.ContextWithWarnings(stream.Context()))
+	defer cancel()
+	var sendErr error
+	report := func(result querysvc.ArchivedTrace) {
+		if sendErr != nil {
+			return
+		}
+		if err := stream.Send(newArchiveTracesResponse(result)); err != nil {
+			sendErr = err
+			cancel()
+		}
+	}
+	results, err := g.queryService.ArchiveTraces(ctx, params, report)
+	if sendErr != nil {
+		g.logger.Error("failed to send the archived traces", zap.Error(sendErr))
+		return sendErr
+	}
+	if results == nil && err != nil {
+		if errors.Is(err, querysvc.ErrInvalidArchiveTraces) || errors.Is(err, querysvc.ErrSearchRejected) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		g.logger.Error("failed to archive traces", zap.Error(err))
+		return status.Errorf(codes.Internal, "failed to archive traces: %v", err)
+	}
+	g.sendWarnings(ctx)
+	if err != nil {
+		var archived int
+		for _, result := range results {
+			if result.Err == nil {
+				archived++
+			}
+		}
+		g.logger.Warn("Archiving of the traces stopped", zap.Int("archived", archived), zap.Int("traces", len(results)), zap.Error(err))
+		return status.Errorf(status.FromContextError(err).Code(),
+			"archiving of the traces stopped after archiving %d of %d traces: %v", archived, len(results), err)
+	}
+	return nil
+}
+
+func parseArchiveTracesRequest(r *api_v2.ArchiveTracesRequest) (querysvc.ArchiveTracesParameters, error) {
+	params := querysvc.ArchiveTracesParameters{
+		TraceIDs:  r.TraceIDs,
+		MaxTraces: int(r.MaxTraces),
+	}
+	for _, traceID := range r.TraceIDs {
+		if traceID == (model.TraceID{}) {
+			return querysvc.ArchiveTracesParameters{}, errUninitializedTraceID
+		}
+	}
+	if r.Query != nil {
+		if r.Query.ServiceName == "" {
+			return querysvc.ArchiveTracesParameters{}, errors.New("the query has no service name")
+		}
+		params.Query = toTraceQueryParameters(r.Query)
+	}
+	return params, nil
+}
+
+func newArchiveTracesResponse(result querysvc.ArchivedTrace) *api_v2.ArchiveTracesResponse {
+	response := &api_v2.ArchiveTracesResponse{
+		TraceID:  result.TraceID,
+		Archived: result.Err == nil,
+	}
+	if result.Err != nil {
+		response.Error = result.Err.Error()
+	}
+	return response
+}
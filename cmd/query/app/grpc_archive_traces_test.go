@@ -0,0 +1,142 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// archiveTracesGRPC calls ArchiveTraces and returns the streamed outcomes, and the error ending the stream.
+func archiveTracesGRPC(t *testing.T, ctx context.Context, client *grpcClient, request *api_v2.ArchiveTracesRequest) ([]*api_v2.ArchiveTracesResponse, error) {
+	stream, err := client.ArchiveTraces(ctx, request)
+	require.NoError(t, err)
+	var outcomes []*api_v2.ArchiveTracesResponse
+	for {
+		out, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return outcomes, nil
+		}
+		if err != nil {
+			return outcomes, err
+		}
+		outcomes = append(outcomes, out)
+	}
+}
+
+func TestArchiveTracesByIDsGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 0x1f00)).
+			Return(mockTrace, nil).Once()
+		server.spanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 0x2f00)).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+		server.archiveSpanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 0x2f00)).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+		server.archiveSpanWriter.On("WriteSpan", mock.Anything, mock.AnythingOfType("*model.Span")).
+			Return(nil).Times(2)
+
+		outcomes, err := archiveTracesGRPC(t, context.Background(), client, &api_v2.ArchiveTracesRequest{
+			TraceIDs: []model.TraceID{model.NewTraceID(0, 0x1f00), model.NewTraceID(0, 0x2f00)},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*api_v2.ArchiveTracesResponse{
+			{TraceID: model.NewTraceID(0, 0x1f00), Archived: true},
+			{TraceID: model.NewTraceID(0, 0x2f00), Error: spanstore.ErrTraceNotFound.Error()},
+		}, outcomes)
+	})
+}
+
+func TestArchiveTracesByQueryGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraceIDs", mock.Anything, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+			return query.ServiceName == "frontend" && query.DurationMin == time.Second && query.NumTraces == 5
+		})).Return([]model.TraceID{mockTraceID}, nil).Once()
+		server.spanReader.On("GetTrace", mock.Anything, mockTraceID).
+			Return(mockTrace, nil).Once()
+		server.archiveSpanWriter.On("WriteSpan", mock.Anything, mock.AnythingOfType("*model.Span")).
+			Return(nil).Times(2)
+
+		outcomes, err := archiveTracesGRPC(t, context.Background(), client, &api_v2.ArchiveTracesRequest{
+			Query:     &api_v2.TraceQueryParameters{ServiceName: "frontend", DurationMin: time.Second},
+			MaxTraces: 5,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*api_v2.ArchiveTracesResponse{{TraceID: mockTraceID, Archived: true}}, outcomes)
+	})
+}
+
+func TestArchiveTracesDeadlineGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		server.spanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 1)).
+			Return(mockTrace, nil).Once()
+		// the other traces take until the deadline of the request
+		server.spanReader.On("GetTrace", mock.Anything, mock.Anything).
+			Return(func(ctx context.Context, _ model.TraceID) (*model.Trace, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+		server.archiveSpanReader.On("GetTrace", mock.Anything, mock.Anything).
+			Return(nil, spanstore.ErrTraceNotFound)
+		server.archiveSpanWriter.On("WriteSpan", mock.Anything, mock.Anything).
+			Return(nil).Times(2)
+
+		traceIDs := make([]model.TraceID, 10)
+		for i := range traceIDs {
+			traceIDs[i] = model.NewTraceID(0, uint64(i+1))
+		}
+		outcomes, err := archiveTracesGRPC(t, ctx, client, &api_v2.ArchiveTracesRequest{TraceIDs: traceIDs})
+		assertGRPCError(t, err, codes.DeadlineExceeded, "")
+		// the outcomes streamed before the deadline are received
+		require.NotEmpty(t, outcomes)
+		assert.Equal(t, &api_v2.ArchiveTracesResponse{TraceID: model.NewTraceID(0, 1), Archived: true}, outcomes[0])
+	})
+}
+
+func TestArchiveTracesInvalidRequestGRPC(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *api_v2.ArchiveTracesRequest
+		errMsg  string
+	}{
+		{
+			name:    "uninitialized trace ID",
+			request: &api_v2.ArchiveTracesRequest{TraceIDs: []model.TraceID{{}}},
+			errMsg:  errUninitializedTraceID.Error(),
+		},
+		{
+			name:    "query without service",
+			request: &api_v2.ArchiveTracesRequest{Query: &api_v2.TraceQueryParameters{OperationName: "checkout"}},
+			errMsg:  "the query has no service name",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := (&GRPCHandler{}).ArchiveTraces(test.request, nil)
+			assertGRPCError(t, err, codes.InvalidArgument, test.errMsg)
+		})
+	}
+
+	require.EqualError(t, (&GRPCHandler{}).ArchiveTraces(nil, nil), errNilRequest.Error())
+}
+
+func TestArchiveTracesNothingToArchiveGRPC(t *testing.T) {
+	withServerAndClient(t, func(_ *grpcServer, client *grpcClient) {
+		_, err := archiveTracesGRPC(t, context.Background(), client, &api_v2.ArchiveTracesRequest{})
+		assertGRPCError(t, err, codes.InvalidArgument, "either trace IDs or a query are expected")
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var _ api_v2.CriticalPathServiceServer = (*GRPCHandler)(nil)
+
+// GetCriticalPath is the gRPC handler to fetch the critical path of a trace.
+// The response lists the IDs of the spans on the critical path, in order.
+func (g *GRPCHandler) GetCriticalPath(ctx context.Context, r *api_v2.GetCriticalPathRequest) (*api_v2.GetCriticalPathResponse, error) {
+	if r == nil {
+		return nil, errNilRequest
+	}
+	if r.TraceID == (model.TraceID{}) {
+		return nil, errUninitializedTraceID
+	}
+	ctx = querysvc.ContextWithWarnings(ctx)
+	path, err := g.queryService.GetCriticalPath(ctx, r.TraceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		g.logger.Warn(msgTraceNotFound, zap.Stringer("id", r.TraceID), zap.Error(err))
+		return nil, status.Errorf(codes.NotFound, "%s: %v", msgTraceNotFound, err)
+	}
+	if err != nil {
+		g.logger.Error("failed to fetch spans from the backend", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to fetch spans from the backend: %v", err)
+	}
+	g.sendWarnings(ctx)
+	return &api_v2.GetCriticalPathResponse{SpanIDs: path}, nil
+}
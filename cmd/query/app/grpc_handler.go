@@ -20,7 +20,9 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
@@ -100,7 +102,12 @@ func (g *GRPCHandler) GetTrace(r *api_v2.GetTraceRequest, stream api_v2.QuerySer
 	if r.TraceID == (model.TraceID{}) {
 		return errUninitializedTraceID
 	}
-	trace, err := g.queryService.GetTrace(stream.Context(), r.TraceID)
+	ctx, err := contextWithSpanFields(stream.Context())
+	if err != nil {
+		return err
+	}
+	ctx = querysvc.ContextWithWarnings(ctx)
+	trace, err := g.queryService.GetTrace(ctx, r.TraceID)
 	if errors.Is(err, spanstore.ErrTraceNotFound) {
 		g.logger.Warn(msgTraceNotFound, zap.Stringer("id", r.TraceID), zap.Error(err))
 		return status.Errorf(codes.NotFound, "%s: %v", msgTraceNotFound, err)
@@ -109,6 +116,7 @@ func (g *GRPCHandler) GetTrace(r *api_v2.GetTraceRequest, stream api_v2.QuerySer
 		g.logger.Error("failed to fetch spans from the backend", zap.Error(err))
 		return status.Errorf(codes.Internal, "failed to fetch spans from the backend: %v", err)
 	}
+	g.sendWarnings(ctx)
 	return g.sendSpanChunks(trace.Spans, stream.Send)
 }
 
@@ -142,7 +150,30 @@ func (g *GRPCHandler) FindTraces(r *api_v2.FindTracesRequest, stream api_v2.Quer
 	if query == nil {
 		return status.Errorf(codes.InvalidArgument, "missing query")
 	}
-	queryParams := spanstore.TraceQueryParameters{
+	ctx, err := contextWithSpanFields(stream.Context())
+	if err != nil {
+		return err
+	}
+	ctx = querysvc.ContextWithWarnings(ctx)
+	traces, err := g.queryService.FindTraces(ctx, toTraceQueryParameters(query))
+	if errors.Is(err, querysvc.ErrSearchRejected) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		g.logger.Error("failed when searching for traces", zap.Error(err))
+		return status.Errorf(codes.Internal, "failed when searching for traces: %v", err)
+	}
+	g.sendWarnings(ctx)
+	for _, trace := range traces {
+		if err := g.sendSpanChunks(trace.Spans, stream.Send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toTraceQueryParameters(query *api_v2.TraceQueryParameters) *spanstore.TraceQueryParameters {
+	return &spanstore.TraceQueryParameters{
 		ServiceName:   query.ServiceName,
 		OperationName: query.OperationName,
 		Tags:          query.Tags,
@@ -152,17 +183,28 @@ func (g *GRPCHandler) FindTraces(r *api_v2.FindTracesRequest, stream api_v2.Quer
 		DurationMax:   query.DurationMax,
 		NumTraces:     int(query.SearchDepth),
 	}
-	traces, err := g.queryService.FindTraces(stream.Context(), &queryParams)
+}
+
+// contextWithSpanFields returns a context projecting the returned traces to the span fields
+// requested in the metadata of the request, see querysvc.SpanFieldsMetadataKey.
+func contextWithSpanFields(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	fields, err := querysvc.ParseSpanFields(md.Get(querysvc.SpanFieldsMetadataKey))
 	if err != nil {
-		g.logger.Error("failed when searching for traces", zap.Error(err))
-		return status.Errorf(codes.Internal, "failed when searching for traces: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s metadata: %v", querysvc.SpanFieldsMetadataKey, err)
 	}
-	for _, trace := range traces {
-		if err := g.sendSpanChunks(trace.Spans, stream.Send); err != nil {
-			return err
-		}
+	return querysvc.ContextWithSpanFields(ctx, fields), nil
+}
+
+// sendWarnings sends the warnings reported about the request in the header of the response.
+func (g *GRPCHandler) sendWarnings(ctx context.Context) {
+	warnings := querysvc.GetWarnings(ctx)
+	if len(warnings) == 0 {
+		return
+	}
+	if err := grpc.SetHeader(ctx, metadata.MD{querysvc.WarningsMetadataKey: warnings}); err != nil {
+		g.logger.Error("failed to send the warnings to client", zap.Error(err))
 	}
-	return nil
 }
 
 func (g *GRPCHandler) sendSpanChunks(spans []*model.Span, sendFn func(*api_v2.SpansResponseChunk) error) error {
@@ -234,7 +276,7 @@ func (g *GRPCHandler) GetDependencies(ctx context.Context, r *api_v2.GetDependen
 		return nil, status.Errorf(codes.InvalidArgument, "StartTime and EndTime must be initialized.")
 	}
 
-	dependencies, err := g.queryService.GetDependencies(ctx, startTime, endTime.Sub(startTime))
+	dependencies, err := g.queryService.GetDependencies(ctx, endTime, endTime.Sub(startTime))
 	if err != nil {
 		g.logger.Error("failed to fetch dependencies", zap.Error(err))
 		return nil, status.Errorf(codes.Internal, "failed to fetch dependencies: %v", err)
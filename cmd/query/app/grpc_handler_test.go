@@ -141,6 +141,11 @@ type grpcServer struct {
 
 type grpcClient struct {
 	api_v2.QueryServiceClient
+	api_v2.CriticalPathServiceClient
+	api_v2.TraceProfileServiceClient
+	api_v2.SearchValidationServiceClient
+	api_v2.TraceArchiveServiceClient
+	api_v2.OperationLatenciesServiceClient
 	metrics.MetricsQueryServiceClient
 	conn *grpc.ClientConn
 }
@@ -163,6 +168,11 @@ func newGRPCServer(t *testing.T, q *querysvc.QueryService, mq querysvc.MetricsQu
 		},
 	})
 	api_v2.RegisterQueryServiceServer(grpcServer, grpcHandler)
+	api_v2.RegisterCriticalPathServiceServer(grpcServer, grpcHandler)
+	api_v2.RegisterTraceProfileServiceServer(grpcServer, grpcHandler)
+	api_v2.RegisterOperationLatenciesServiceServer(grpcServer, grpcHandler)
+	api_v2.RegisterSearchValidationServiceServer(grpcServer, grpcHandler)
+	api_v2.RegisterTraceArchiveServiceServer(grpcServer, grpcHandler)
 	metrics.RegisterMetricsQueryServiceServer(grpcServer, grpcHandler)
 
 	go func() {
@@ -178,9 +188,14 @@ func newGRPCClient(t *testing.T, addr string) *grpcClient {
 	require.NoError(t, err)
 
 	return &grpcClient{
-		QueryServiceClient:        api_v2.NewQueryServiceClient(conn),
-		MetricsQueryServiceClient: metrics.NewMetricsQueryServiceClient(conn),
-		conn:                      conn,
+		QueryServiceClient:              api_v2.NewQueryServiceClient(conn),
+		CriticalPathServiceClient:       api_v2.NewCriticalPathServiceClient(conn),
+		TraceProfileServiceClient:       api_v2.NewTraceProfileServiceClient(conn),
+		SearchValidationServiceClient:   api_v2.NewSearchValidationServiceClient(conn),
+		TraceArchiveServiceClient:       api_v2.NewTraceArchiveServiceClient(conn),
+		OperationLatenciesServiceClient: api_v2.NewOperationLatenciesServiceClient(conn),
+		MetricsQueryServiceClient:       metrics.NewMetricsQueryServiceClient(conn),
+		conn:                            conn,
 	}
 }
 
@@ -189,6 +204,10 @@ type testOption func(*testQueryService)
 type testQueryService struct {
 	// metricsQueryService is used when creating a new GRPCHandler.
 	metricsQueryService querysvc.MetricsQueryService
+	// searchGuardrails are the search guardrails of the query service.
+	searchGuardrails querysvc.SearchGuardrails
+	// maxTraceSpans caps the number of spans of the traces returned by the query service.
+	maxTraceSpans int
 }
 
 func withMetricsQuery() testOption {
@@ -198,6 +217,18 @@ func withMetricsQuery() testOption {
 	}
 }
 
+func withSearchGuardrails(guardrails querysvc.SearchGuardrails) testOption {
+	return func(ts *testQueryService) {
+		ts.searchGuardrails = guardrails
+	}
+}
+
+func withMaxTraceSpans(maxTraceSpans int) testOption {
+	return func(ts *testQueryService) {
+		ts.maxTraceSpans = maxTraceSpans
+	}
+}
+
 func withServerAndClient(t *testing.T, actualTest func(server *grpcServer, client *grpcClient), options ...testOption) {
 	server := initializeTenantedTestServerGRPCWithOptions(t, &tenancy.Manager{}, options...)
 	client := newGRPCClient(t, server.lisAddr.String())
@@ -223,6 +254,105 @@ func TestGetTraceSuccessGRPC(t *testing.T) {
 	})
 }
 
+func TestGetTraceTruncatedGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		trace := &model.Trace{Spans: []*model.Span{
+			{TraceID: mockTraceID, SpanID: model.NewSpanID(1), Process: &model.Process{}},
+			{TraceID: mockTraceID, SpanID: model.NewSpanID(2), Process: &model.Process{}},
+		}}
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mockTraceID).
+			Return(trace, nil).Once()
+
+		res, err := client.GetTrace(context.Background(), &api_v2.GetTraceRequest{
+			TraceID: mockTraceID,
+		})
+		require.NoError(t, err)
+		spanResChunk, err := res.Recv()
+		require.NoError(t, err)
+		assert.Len(t, spanResChunk.Spans, 1)
+		header, err := res.Header()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"trace 000000000001e240 truncated to the maximum of 1 spans"},
+			header.Get(querysvc.WarningsMetadataKey))
+	}, withMaxTraceSpans(1))
+}
+
+func TestGetTraceProjectionGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		startTime := time.Unix(10, 0).UTC()
+		trace := &model.Trace{Spans: []*model.Span{{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "op",
+			StartTime:     startTime,
+			Duration:      time.Second,
+			Tags:          []model.KeyValue{model.String("k", "v")},
+			Process:       model.NewProcess("svc", []model.KeyValue{model.String("hostname", "host")}),
+		}}}
+		server.spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(trace, nil).Once()
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), querysvc.SpanFieldsMetadataKey, "startTime,duration")
+		res, err := client.GetTrace(ctx, &api_v2.GetTraceRequest{TraceID: mockTraceID})
+		require.NoError(t, err)
+		spanResChunk, err := res.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, []model.Span{{
+			TraceID:   mockTraceID,
+			SpanID:    model.NewSpanID(1),
+			StartTime: startTime,
+			Duration:  time.Second,
+			Process:   model.NewProcess("svc", nil),
+		}}, spanResChunk.Spans)
+	})
+}
+
+func TestFindTracesProjectionGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		trace := &model.Trace{Spans: []*model.Span{{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "op",
+			Duration:      time.Second,
+			Logs:          []model.Log{{Timestamp: time.Unix(10, 0)}},
+			Process:       model.NewProcess("svc", nil),
+		}}}
+		server.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{trace}, nil).Once()
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), querysvc.SpanFieldsMetadataKey, "operationName")
+		res, err := client.FindTraces(ctx, &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "svc"},
+		})
+		require.NoError(t, err)
+		spanResChunk, err := res.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, []model.Span{{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "op",
+			StartTime:     model.EpochMicrosecondsAsTime(0),
+			Process:       model.NewProcess("svc", nil),
+		}}, spanResChunk.Spans)
+	})
+}
+
+func TestProjectionInvalidFieldGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), querysvc.SpanFieldsMetadataKey, "references")
+		res, err := client.GetTrace(ctx, &api_v2.GetTraceRequest{TraceID: mockTraceID})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		assertGRPCError(t, err, codes.InvalidArgument, "unsupported span field 'references'")
+
+		res2, err := client.FindTraces(ctx, &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{ServiceName: "svc"},
+		})
+		require.NoError(t, err)
+		_, err = res2.Recv()
+		assertGRPCError(t, err, codes.InvalidArgument, "unsupported span field 'references'")
+		server.spanReader.AssertNotCalled(t, "GetTrace", mock.Anything, mock.Anything)
+	})
+}
+
 func assertGRPCError(t *testing.T, err error, code codes.Code, msg string) {
 	s, ok := status.FromError(err)
 	require.True(t, ok, "expecting gRPC status")
@@ -281,6 +411,49 @@ func TestGetTraceNotFoundGRPC(t *testing.T) {
 	})
 }
 
+func TestGetCriticalPathSuccessGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+			Return(&model.Trace{Spans: []*model.Span{
+				{TraceID: mockTraceID, SpanID: model.NewSpanID(1), StartTime: now, Duration: time.Second, Process: &model.Process{}},
+			}}, nil).Once()
+
+		res, err := client.GetCriticalPath(context.Background(), &api_v2.GetCriticalPathRequest{TraceID: mockTraceID})
+		require.NoError(t, err)
+		assert.Equal(t, []model.SpanID{model.NewSpanID(1)}, res.SpanIDs)
+	})
+}
+
+func TestGetCriticalPathFailureGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+			Return(nil, errStorageGRPC).Once()
+
+		_, err := client.GetCriticalPath(context.Background(), &api_v2.GetCriticalPathRequest{TraceID: mockTraceID})
+		assertGRPCError(t, err, codes.Internal, "failed to fetch spans from the backend")
+	})
+}
+
+func TestGetCriticalPathNotFoundGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+		server.archiveSpanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+
+		_, err := client.GetCriticalPath(context.Background(), &api_v2.GetCriticalPathRequest{TraceID: mockTraceID})
+		assertGRPCError(t, err, codes.NotFound, "trace not found")
+	})
+}
+
+func TestGetCriticalPathInvalidRequestOnHandlerGRPC(t *testing.T) {
+	grpcHandler := &GRPCHandler{}
+	_, err := grpcHandler.GetCriticalPath(context.Background(), nil)
+	require.EqualError(t, err, errNilRequest.Error())
+	_, err = grpcHandler.GetCriticalPath(context.Background(), &api_v2.GetCriticalPathRequest{})
+	require.EqualError(t, err, errUninitializedTraceID.Error())
+}
+
 // test from GRPCHandler and not grpcClient as Generated Go client panics with `nil` request
 func TestGetTraceNilRequestOnHandlerGRPC(t *testing.T) {
 	grpcHandler := &GRPCHandler{}
@@ -403,6 +576,47 @@ func TestFindTracesSuccess_SpanStreamingGRPC(t *testing.T) {
 	})
 }
 
+func TestFindTracesGuardrailsGRPC(t *testing.T) {
+	guardrails := querysvc.SearchGuardrails{MaxLimit: 10, MaxLookback: time.Hour}
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+			return q.NumTraces == 10 && q.StartTimeMax.Sub(q.StartTimeMin) == time.Hour
+		})).Return([]*model.Trace{mockTraceGRPC}, nil).Once()
+
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{
+				ServiceName:  "service",
+				StartTimeMin: now.Add(-24 * time.Hour),
+				StartTimeMax: now,
+				SearchDepth:  100,
+			},
+		})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		require.NoError(t, err)
+		header, err := res.Header()
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"search time window of 24h0m0s reduced to the maximum lookback of 1h0m0s",
+			"search limit 100 reduced to the maximum of 10",
+		}, header.Get(querysvc.WarningsMetadataKey))
+	}, withSearchGuardrails(guardrails))
+
+	guardrails.LookbackMode = querysvc.LookbackModeReject
+	withServerAndClient(t, func(_ *grpcServer, client *grpcClient) {
+		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
+			Query: &api_v2.TraceQueryParameters{
+				ServiceName:  "service",
+				StartTimeMin: now.Add(-24 * time.Hour),
+				StartTimeMax: now,
+			},
+		})
+		require.NoError(t, err)
+		_, err = res.Recv()
+		assertGRPCError(t, err, codes.InvalidArgument, "the time window of 24h0m0s exceeds the maximum lookback of 1h0m0s")
+	}, withSearchGuardrails(guardrails))
+}
+
 func TestFindTracesMissingQuery_GRPC(t *testing.T) {
 	withServerAndClient(t, func(_ *grpcServer, client *grpcClient) {
 		res, err := client.FindTraces(context.Background(), &api_v2.FindTracesRequest{
@@ -524,7 +738,7 @@ func TestGetDependenciesSuccessGRPC(t *testing.T) {
 		endTs := time.Now().UTC()
 		server.depReader.On("GetDependencies",
 			mock.Anything, // context.Context
-			endTs.Add(time.Duration(-1)*defaultDependencyLookbackDuration),
+			endTs,
 			defaultDependencyLookbackDuration,
 		).Return(expectedDependencies, nil).Times(1)
 
@@ -543,7 +757,7 @@ func TestGetDependenciesFailureGRPC(t *testing.T) {
 		server.depReader.On(
 			"GetDependencies",
 			mock.Anything, // context.Context
-			endTs.Add(time.Duration(-1)*defaultDependencyLookbackDuration),
+			endTs,
 			defaultDependencyLookbackDuration).Return(nil, errStorageGRPC).Times(1)
 
 		_, err := client.GetDependencies(context.Background(), &api_v2.GetDependenciesRequest{
@@ -912,14 +1126,6 @@ func initializeTenantedTestServerGRPCWithOptions(t *testing.T, tm *tenancy.Manag
 	disabledReader, err := disabled.NewMetricsReader()
 	require.NoError(t, err)
 
-	q := querysvc.NewQueryService(
-		spanReader,
-		dependencyReader,
-		querysvc.QueryServiceOptions{
-			ArchiveSpanReader: archiveSpanReader,
-			ArchiveSpanWriter: archiveSpanWriter,
-		})
-
 	tqs := &testQueryService{
 		// Disable metrics query by default.
 		metricsQueryService: disabledReader,
@@ -928,6 +1134,16 @@ func initializeTenantedTestServerGRPCWithOptions(t *testing.T, tm *tenancy.Manag
 		opt(tqs)
 	}
 
+	q := querysvc.NewQueryService(
+		spanReader,
+		dependencyReader,
+		querysvc.QueryServiceOptions{
+			ArchiveSpanReader: archiveSpanReader,
+			ArchiveSpanWriter: archiveSpanWriter,
+			SearchGuardrails:  tqs.searchGuardrails,
+			MaxTraceSpans:     tqs.maxTraceSpans,
+		})
+
 	logger := zap.NewNop()
 	tracer := jtracer.NoOp()
 
@@ -0,0 +1,179 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/plugin/metrics/disabled"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/metricsstore"
+)
+
+// defaultOperationLatenciesWindow is the time window of the requests without start time
+const defaultOperationLatenciesWindow = time.Hour
+
+var _ api_v2.OperationLatenciesServiceServer = (*GRPCHandler)(nil)
+
+// GetOperationLatencies is the gRPC handler returning the p50, p95 and p99 latencies of the operations
+// of a service over a time window. The latencies come from the metrics store when it is enabled,
+// otherwise they are computed from a sample of the traces and flagged approximate.
+func (g *GRPCHandler) GetOperationLatencies(ctx context.Context, r *api_v2.GetOperationLatenciesRequest) (*api_v2.GetOperationLatenciesResponse, error) {
+	if r == nil {
+		return nil, errNilRequest
+	}
+	if err := validateOperationLatenciesRequest(r); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid GetOperationLatencies request: %v", err)
+	}
+	query := operationLatenciesQuery(r, g.nowFn())
+
+	source := api_v2.GetOperationLatenciesResponse_METRICS
+	latencies, err := g.operationLatenciesFromMetrics(ctx, query)
+	if errors.Is(err, disabled.ErrDisabled) {
+		source = api_v2.GetOperationLatenciesResponse_TRACES
+		ctx = querysvc.ContextWithWarnings(ctx)
+		latencies, err = g.queryService.GetOperationLatencies(ctx, query)
+		if errors.Is(err, querysvc.ErrSearchRejected) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		g.sendWarnings(ctx)
+	}
+	if err != nil {
+		g.logger.Error("failed to fetch the operation latencies", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to fetch the operation latencies: %v", err)
+	}
+	return newOperationLatenciesResponse(source, latencies), nil
+}
+
+func validateOperationLatenciesRequest(r *api_v2.GetOperationLatenciesRequest) error {
+	if r.Service == "" {
+		return errors.New("the service is required")
+	}
+	if r.MaxSamples < 0 {
+		return fmt.Errorf("max_samples cannot be negative: %d", r.MaxSamples)
+	}
+	if !r.StartTimeMin.IsZero() && !r.StartTimeMax.IsZero() && !r.StartTimeMin.Before(r.StartTimeMax) {
+		return errors.New("start_time_min must be before start_time_max")
+	}
+	return nil
+}
+
+func operationLatenciesQuery(r *api_v2.GetOperationLatenciesRequest, now time.Time) querysvc.OperationLatenciesQuery {
+	query := querysvc.OperationLatenciesQuery{
+		ServiceName:   r.Service,
+		OperationName: r.Operation,
+		StartTimeMin:  r.StartTimeMin,
+		StartTimeMax:  r.StartTimeMax,
+		MaxSamples:    int(r.MaxSamples),
+	}
+	if query.StartTimeMax.IsZero() {
+		query.StartTimeMax = now
+	}
+	if query.StartTimeMin.IsZero() {
+		query.StartTimeMin = query.StartTimeMax.Add(-defaultOperationLatenciesWindow)
+	}
+	return query
+}
+
+// operationLatenciesFromMetrics fetches the latency percentiles of the operations from the metrics store,
+// with a single data point over the time window. The metrics store reports the latencies in milliseconds.
+func (g *GRPCHandler) operationLatenciesFromMetrics(ctx context.Context, query querysvc.OperationLatenciesQuery) (*querysvc.OperationLatencies, error) {
+	window := query.StartTimeMax.Sub(query.StartTimeMin)
+	percentiles := make(map[string][]time.Duration)
+	for i, quantile := range querysvc.OperationLatencyPercentiles {
+		family, err := g.metricsQueryService.GetLatencies(ctx, &metricsstore.LatenciesQueryParameters{
+			BaseQueryParameters: metricsstore.BaseQueryParameters{
+				ServiceNames:     []string{query.ServiceName},
+				GroupByOperation: true,
+				EndTime:          &query.StartTimeMax,
+				Lookback:         &window,
+				Step:             &window,
+				RatePer:          &window,
+			},
+			Quantile: quantile,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, metric := range family.Metrics {
+			operation := metricLabel(metric, "operation")
+			if query.OperationName != "" && operation != query.OperationName {
+				continue
+			}
+			value, ok := lastGaugeValue(metric)
+			if !ok {
+				continue
+			}
+			if _, ok := percentiles[operation]; !ok {
+				percentiles[operation] = make([]time.Duration, len(querysvc.OperationLatencyPercentiles))
+			}
+			percentiles[operation][i] = time.Duration(value * float64(time.Millisecond))
+		}
+	}
+	latencies := &querysvc.OperationLatencies{Operations: make([]querysvc.OperationLatency, 0, len(percentiles))}
+	for operation, p := range percentiles {
+		latencies.Operations = append(latencies.Operations, querysvc.OperationLatency{
+			OperationName: operation,
+			Percentiles:   p,
+		})
+	}
+	sort.Slice(latencies.Operations, func(i, j int) bool {
+		return latencies.Operations[i].OperationName < latencies.Operations[j].OperationName
+	})
+	return latencies, nil
+}
+
+func metricLabel(metric *metrics.Metric, name string) string {
+	for _, label := range metric.Labels {
+		if label.Name == name {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+// lastGaugeValue returns the value of the last data point of the metric, if it is a number.
+func lastGaugeValue(metric *metrics.Metric) (float64, bool) {
+	if len(metric.MetricPoints) == 0 {
+		return 0, false
+	}
+	gauge := metric.MetricPoints[len(metric.MetricPoints)-1].GetGaugeValue()
+	if gauge == nil {
+		return 0, false
+	}
+	value := gauge.GetDoubleValue()
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
+
+func newOperationLatenciesResponse(source api_v2.GetOperationLatenciesResponse_Source, latencies *querysvc.OperationLatencies) *api_v2.GetOperationLatenciesResponse {
+	response := &api_v2.GetOperationLatenciesResponse{
+		Source:      source,
+		Approximate: latencies.Approximate,
+		Operations:  make([]api_v2.OperationLatency, len(latencies.Operations)),
+	}
+	for i, latency := range latencies.Operations {
+		response.Operations[i] = api_v2.OperationLatency{
+			Operation: latency.OperationName,
+			Samples:   int32(latency.Samples),
+			P50:       latency.Percentiles[0],
+			P95:       latency.Percentiles[1],
+			P99:       latency.Percentiles[2],
+		}
+	}
+	return response
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
+	"github.com/jaegertracing/jaeger/storage/metricsstore"
+	metricsmocks "github.com/jaegertracing/jaeger/storage/metricsstore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// latencyMetric is a latency metric of the operation with data points of the values, in milliseconds.
+func latencyMetric(operation string, values ...float64) *metrics.Metric {
+	metric := &metrics.Metric{Labels: []*metrics.Label{
+		{Name: "service_name", Value: "shop"},
+		{Name: "operation", Value: operation},
+	}}
+	for _, value := range values {
+		metric.MetricPoints = append(metric.MetricPoints, &metrics.MetricPoint{
+			Value: &metrics.MetricPoint_GaugeValue{GaugeValue: &metrics.GaugeValue{
+				Value: &metrics.GaugeValue_DoubleValue{DoubleValue: value},
+			}},
+		})
+	}
+	return metric
+}
+
+func TestGetOperationLatenciesFromTracesGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		traces := make([]*model.Trace, 20)
+		for i := range traces {
+			traces[i] = &model.Trace{Spans: []*model.Span{{
+				TraceID:       model.NewTraceID(0, uint64(i+1)),
+				SpanID:        model.NewSpanID(1),
+				OperationName: "checkout",
+				Duration:      time.Duration(i+1) * 10 * time.Millisecond,
+				Process:       &model.Process{ServiceName: "shop"},
+			}}}
+		}
+		server.spanReader.On("FindTraces", mock.Anything, &spanstore.TraceQueryParameters{
+			ServiceName:  "shop",
+			StartTimeMin: now.Add(-defaultOperationLatenciesWindow),
+			StartTimeMax: now,
+			NumTraces:    20,
+		}).Return(traces, nil).Once()
+
+		res, err := client.GetOperationLatencies(context.Background(),
+			&api_v2.GetOperationLatenciesRequest{Service: "shop", MaxSamples: 20})
+		require.NoError(t, err)
+		assert.Equal(t, &api_v2.GetOperationLatenciesResponse{
+			Source:      api_v2.GetOperationLatenciesResponse_TRACES,
+			Approximate: true,
+			Operations: []api_v2.OperationLatency{
+				{Operation: "checkout", Samples: 20, P50: 100 * time.Millisecond, P95: 190 * time.Millisecond, P99: 200 * time.Millisecond},
+			},
+		}, res)
+	})
+}
+
+func TestGetOperationLatenciesFromMetricsGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		m := server.metricsQueryService.(*metricsmocks.Reader)
+		start := now.Add(-30 * time.Minute)
+		for quantile, value := range map[float64]float64{0.5: 12, 0.95: 40, 0.99: 85.5} {
+			quantile, value := quantile, value
+			m.On("GetLatencies", mock.Anything, mock.MatchedBy(func(p *metricsstore.LatenciesQueryParameters) bool {
+				return p.Quantile == quantile && p.GroupByOperation &&
+					p.EndTime.Equal(now) && *p.Lookback == 30*time.Minute && *p.Step == 30*time.Minute
+			})).Return(&metrics.MetricFamily{Metrics: []*metrics.Metric{
+				latencyMetric("checkout", 1, value),
+				latencyMetric("render", math.NaN()),
+				latencyMetric("browse", 5),
+			}}, nil).Once()
+		}
+
+		res, err := client.GetOperationLatencies(context.Background(), &api_v2.GetOperationLatenciesRequest{
+			Service:      "shop",
+			Operation:    "checkout",
+			StartTimeMin: start,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &api_v2.GetOperationLatenciesResponse{
+			Source: api_v2.GetOperationLatenciesResponse_METRICS,
+			Operations: []api_v2.OperationLatency{
+				{Operation: "checkout", P50: 12 * time.Millisecond, P95: 40 * time.Millisecond, P99: 85500 * time.Microsecond},
+			},
+		}, res)
+		server.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+		m.AssertExpectations(t)
+	}, withMetricsQuery())
+}
+
+func TestGetOperationLatenciesErrorsGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		for _, test := range []struct {
+			request *api_v2.GetOperationLatenciesRequest
+			message string
+		}{
+			{request: &api_v2.GetOperationLatenciesRequest{}, message: "the service is required"},
+			{request: &api_v2.GetOperationLatenciesRequest{Service: "shop", MaxSamples: -1}, message: "max_samples cannot be negative"},
+			{
+				request: &api_v2.GetOperationLatenciesRequest{Service: "shop", StartTimeMin: now, StartTimeMax: now.Add(-time.Hour)},
+				message: "start_time_min must be before start_time_max",
+			},
+		} {
+			_, err := client.GetOperationLatencies(context.Background(), test.request)
+			assertGRPCError(t, err, codes.InvalidArgument, test.message)
+		}
+
+		server.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+		_, err := client.GetOperationLatencies(context.Background(), &api_v2.GetOperationLatenciesRequest{Service: "shop"})
+		assertGRPCError(t, err, codes.Internal, "failed to fetch the operation latencies")
+
+		_, err = (&GRPCHandler{}).GetOperationLatencies(context.Background(), nil)
+		require.EqualError(t, err, errNilRequest.Error())
+	})
+}
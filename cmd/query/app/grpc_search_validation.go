@@ -0,0 +1,48 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+var _ api_v2.SearchValidationServiceServer = (*GRPCHandler)(nil)
+
+// DryRunSearch is the gRPC handler validating a search like FindTraces, without searching the storage.
+// The response holds the effective query, after the defaults and the limits are applied, along with
+// the warnings reporting the adjustments, which are also sent in the header of the response.
+func (g *GRPCHandler) DryRunSearch(ctx context.Context, r *api_v2.DryRunSearchRequest) (*api_v2.DryRunSearchResponse, error) {
+	if r == nil {
+		return nil, errNilRequest
+	}
+	query := r.GetQuery()
+	if query == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "missing query")
+	}
+	ctx = querysvc.ContextWithWarnings(ctx)
+	effective, err := g.queryService.ValidateSearch(ctx, toTraceQueryParameters(query))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	g.sendWarnings(ctx)
+	return &api_v2.DryRunSearchResponse{
+		Query: &api_v2.TraceQueryParameters{
+			ServiceName:   effective.ServiceName,
+			OperationName: effective.OperationName,
+			Tags:          effective.Tags,
+			StartTimeMin:  effective.StartTimeMin,
+			StartTimeMax:  effective.StartTimeMax,
+			DurationMin:   effective.DurationMin,
+			DurationMax:   effective.DurationMax,
+			SearchDepth:   int32(effective.NumTraces),
+		},
+		Warnings: querysvc.GetWarnings(ctx),
+	}, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+func TestDryRunSearchGRPC(t *testing.T) {
+	guardrails := querysvc.SearchGuardrails{MaxLimit: 10, MaxLookback: time.Hour}
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		var header metadata.MD
+		res, err := client.DryRunSearch(context.Background(),
+			&api_v2.DryRunSearchRequest{Query: &api_v2.TraceQueryParameters{
+				ServiceName:  "frontend",
+				Tags:         map[string]string{"error": "true"},
+				StartTimeMin: now.Add(-24 * time.Hour),
+				StartTimeMax: now,
+				DurationMin:  10 * time.Millisecond,
+				SearchDepth:  100,
+			}}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.True(t, now.Add(-time.Hour).Equal(res.Query.StartTimeMin))
+		assert.True(t, now.Equal(res.Query.StartTimeMax))
+		res.Query.StartTimeMin, res.Query.StartTimeMax = time.Time{}, time.Time{}
+		assert.Equal(t, &api_v2.TraceQueryParameters{
+			ServiceName: "frontend",
+			Tags:        map[string]string{"error": "true"},
+			DurationMin: 10 * time.Millisecond,
+			SearchDepth: 10,
+		}, res.Query)
+		warnings := []string{
+			"search time window of 24h0m0s reduced to the maximum lookback of 1h0m0s",
+			"search limit 100 reduced to the maximum of 10",
+		}
+		assert.Equal(t, warnings, res.Warnings)
+		assert.Equal(t, warnings, header.Get(querysvc.WarningsMetadataKey))
+		server.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+	}, withSearchGuardrails(guardrails))
+}
+
+func TestDryRunSearchFailuresGRPC(t *testing.T) {
+	guardrails := querysvc.SearchGuardrails{MaxLookback: time.Hour, LookbackMode: querysvc.LookbackModeReject}
+	withServerAndClient(t, func(_ *grpcServer, client *grpcClient) {
+		_, err := client.DryRunSearch(context.Background(), &api_v2.DryRunSearchRequest{})
+		assertGRPCError(t, err, codes.InvalidArgument, "missing query")
+
+		_, err = client.DryRunSearch(context.Background(), &api_v2.DryRunSearchRequest{Query: &api_v2.TraceQueryParameters{
+			ServiceName:  "frontend",
+			StartTimeMin: now.Add(-24 * time.Hour),
+			StartTimeMax: now,
+		}})
+		assertGRPCError(t, err, codes.InvalidArgument, "the time window of 24h0m0s exceeds the maximum lookback of 1h0m0s")
+	}, withSearchGuardrails(guardrails))
+}
+
+func TestDryRunSearchNilRequestOnHandlerGRPC(t *testing.T) {
+	grpcHandler := &GRPCHandler{logger: zap.NewNop()}
+	_, err := grpcHandler.DryRunSearch(context.Background(), nil)
+	require.EqualError(t, err, errNilRequest.Error())
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var _ api_v2.TraceProfileServiceServer = (*GRPCHandler)(nil)
+
+// CompareTrace is the gRPC handler comparing a trace with a profile of required operations,
+// e.g. to check in CI that a trace matches a baseline.
+func (g *GRPCHandler) CompareTrace(ctx context.Context, r *api_v2.CompareTraceRequest) (*api_v2.CompareTraceResponse, error) {
+	if r == nil {
+		return nil, errNilRequest
+	}
+	if r.TraceID == (model.TraceID{}) {
+		return nil, errUninitializedTraceID
+	}
+	profile, err := traceProfile(r.Operations)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid CompareTrace request: %v", err)
+	}
+	comparison, err := g.queryService.CompareTrace(ctx, r.TraceID, profile)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		g.logger.Warn(msgTraceNotFound, zap.Stringer("id", r.TraceID), zap.Error(err))
+		return nil, status.Errorf(codes.NotFound, "%s: %v", msgTraceNotFound, err)
+	}
+	if err != nil {
+		g.logger.Error("failed to fetch spans from the backend", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "failed to fetch spans from the backend: %v", err)
+	}
+	return newCompareTraceResponse(comparison), nil
+}
+
+func traceProfile(operations []api_v2.ProfileOperation) (querysvc.TraceProfile, error) {
+	if len(operations) == 0 {
+		return querysvc.TraceProfile{}, errors.New("the profile has no operations")
+	}
+	profile := querysvc.TraceProfile{Operations: make([]querysvc.ProfileOperation, len(operations))}
+	for i, operation := range operations {
+		if operation.Operation == "" {
+			return querysvc.TraceProfile{}, errors.New("the profile has an operation without name")
+		}
+		if operation.MaxDuration < 0 {
+			return querysvc.TraceProfile{}, fmt.Errorf("the operation %s has a negative max duration", operation.Operation)
+		}
+		profile.Operations[i] = querysvc.ProfileOperation{
+			ServiceName:   operation.Service,
+			OperationName: operation.Operation,
+			MaxDuration:   operation.MaxDuration,
+		}
+	}
+	return profile, nil
+}
+
+func newCompareTraceResponse(comparison *querysvc.ProfileComparison) *api_v2.CompareTraceResponse {
+	response := &api_v2.CompareTraceResponse{
+		Passed: comparison.Passed,
+		Checks: make([]api_v2.ProfileCheck, len(comparison.Checks)),
+	}
+	for i, check := range comparison.Checks {
+		response.Checks[i] = api_v2.ProfileCheck{
+			Operation: api_v2.ProfileOperation{
+				Service:     check.Operation.ServiceName,
+				Operation:   check.Operation.OperationName,
+				MaxDuration: check.Operation.MaxDuration,
+			},
+			Passed:          check.Passed,
+			Spans:           int32(check.Spans),
+			LongestDuration: check.LongestDuration,
+			OnCriticalPath:  check.OnCriticalPath,
+			Message:         check.Message,
+		}
+	}
+	return response
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// checkoutTrace has a root checkout span calling the database for dbDuration.
+func checkoutTrace(traceID model.TraceID, dbDuration time.Duration) *model.Trace {
+	return &model.Trace{Spans: []*model.Span{
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "checkout",
+			StartTime:     now,
+			Duration:      200 * time.Millisecond,
+			Process:       &model.Process{ServiceName: "shop"},
+		},
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "insert",
+			References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+			StartTime:     now.Add(10 * time.Millisecond),
+			Duration:      dbDuration,
+			Process:       &model.Process{ServiceName: "db"},
+		},
+	}}
+}
+
+func compareTraceRequest(traceID model.TraceID) *api_v2.CompareTraceRequest {
+	return &api_v2.CompareTraceRequest{
+		TraceID: traceID,
+		Operations: []api_v2.ProfileOperation{
+			{Service: "shop", Operation: "checkout", MaxDuration: 500 * time.Millisecond},
+			{Service: "db", Operation: "insert", MaxDuration: 100 * time.Millisecond},
+		},
+	}
+}
+
+func TestCompareTraceGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		passingID, failingID := model.NewTraceID(0, 0x1f00), model.NewTraceID(0, 0x2f00)
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), passingID).
+			Return(checkoutTrace(passingID, 50*time.Millisecond), nil).Once()
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), failingID).
+			Return(checkoutTrace(failingID, 150*time.Millisecond), nil).Once()
+
+		res, err := client.CompareTrace(context.Background(), compareTraceRequest(passingID))
+		require.NoError(t, err)
+		assert.Equal(t, &api_v2.CompareTraceResponse{
+			Passed: true,
+			Checks: []api_v2.ProfileCheck{
+				{
+					Operation:       api_v2.ProfileOperation{Service: "shop", Operation: "checkout", MaxDuration: 500 * time.Millisecond},
+					Passed:          true,
+					Spans:           1,
+					LongestDuration: 200 * time.Millisecond,
+					OnCriticalPath:  true,
+				},
+				{
+					Operation:       api_v2.ProfileOperation{Service: "db", Operation: "insert", MaxDuration: 100 * time.Millisecond},
+					Passed:          true,
+					Spans:           1,
+					LongestDuration: 50 * time.Millisecond,
+					OnCriticalPath:  true,
+				},
+			},
+		}, res)
+
+		res, err = client.CompareTrace(context.Background(), compareTraceRequest(failingID))
+		require.NoError(t, err)
+		assert.False(t, res.Passed)
+		require.Len(t, res.Checks, 2)
+		assert.True(t, res.Checks[0].Passed)
+		assert.Equal(t, "operation db/insert took 150ms, more than 100ms", res.Checks[1].Message)
+	})
+}
+
+func TestCompareTraceFailuresGRPC(t *testing.T) {
+	withServerAndClient(t, func(server *grpcServer, client *grpcClient) {
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 0x1f00)).
+			Return(nil, errStorageGRPC).Once()
+		server.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 0x2f00)).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+		server.archiveSpanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 0x2f00)).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+
+		_, err := client.CompareTrace(context.Background(), compareTraceRequest(model.NewTraceID(0, 0x1f00)))
+		assertGRPCError(t, err, codes.Internal, "failed to fetch spans from the backend")
+
+		_, err = client.CompareTrace(context.Background(), compareTraceRequest(model.NewTraceID(0, 0x2f00)))
+		assertGRPCError(t, err, codes.NotFound, "trace not found")
+	})
+}
+
+func TestCompareTraceInvalidRequestGRPC(t *testing.T) {
+	traceID := model.NewTraceID(0, 0x1f00)
+	tests := []struct {
+		name    string
+		request *api_v2.CompareTraceRequest
+		errMsg  string
+	}{
+		{
+			name:    "no operations",
+			request: &api_v2.CompareTraceRequest{TraceID: traceID},
+			errMsg:  "the profile has no operations",
+		},
+		{
+			name:    "operation without name",
+			request: &api_v2.CompareTraceRequest{TraceID: traceID, Operations: []api_v2.ProfileOperation{{Service: "shop"}}},
+			errMsg:  "the profile has an operation without name",
+		},
+		{
+			name: "negative duration",
+			request: &api_v2.CompareTraceRequest{
+				TraceID:    traceID,
+				Operations: []api_v2.ProfileOperation{{Operation: "checkout", MaxDuration: -time.Second}},
+			},
+			errMsg: "the operation checkout has a negative max duration",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := (&GRPCHandler{}).CompareTrace(context.Background(), test.request)
+			assertGRPCError(t, err, codes.InvalidArgument, test.errMsg)
+		})
+	}
+
+	_, err := (&GRPCHandler{}).CompareTrace(context.Background(), nil)
+	require.EqualError(t, err, errNilRequest.Error())
+	_, err = (&GRPCHandler{}).CompareTrace(context.Background(), &api_v2.CompareTraceRequest{})
+	require.EqualError(t, err, errUninitializedTraceID.Error())
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
+	ui "github.com/jaegertracing/jaeger/model/json"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
 )
@@ -138,3 +139,65 @@ func TestArchiveTrace_WriteErrors(t *testing.T) {
 		require.EqualError(t, err, `500 error from server: {"data":null,"total":0,"limit":0,"offset":0,"errors":[{"code":500,"msg":"cannot save\ncannot save"}]}`+"\n")
 	}, querysvc.QueryServiceOptions{ArchiveSpanWriter: mockWriter})
 }
+
+func TestArchiveTraces_ByTraceIDs(t *testing.T) {
+	mockWriter := &spanstoremocks.Writer{}
+	mockWriter.On("WriteSpan", mock.Anything, mock.AnythingOfType("*model.Span")).
+		Return(nil).Times(2)
+	withTestServer(func(ts *testServer) {
+		ts.spanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 0x1f00)).
+			Return(mockTrace, nil).Once()
+		ts.spanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 0x2f00)).
+			Return(nil, spanstore.ErrTraceNotFound).Once()
+		var response struct {
+			Data   []archivedTrace   `json:"data"`
+			Total  int               `json:"total"`
+			Errors []structuredError `json:"errors"`
+		}
+		err := postJSON(ts.server.URL+"/api/archive?traceID=1f00&traceID=2f00", []string{}, &response)
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.Total)
+		assert.Equal(t, []archivedTrace{
+			{TraceID: "0000000000001f00", Archived: true},
+			{TraceID: "0000000000002f00", Error: "trace not found"},
+		}, response.Data)
+		assert.Equal(t, []structuredError{
+			{Code: 404, Msg: "trace not found", TraceID: "0000000000002f00"},
+		}, response.Errors)
+	}, querysvc.QueryServiceOptions{ArchiveSpanWriter: mockWriter})
+}
+
+func TestArchiveTraces_ByQuery(t *testing.T) {
+	mockWriter := &spanstoremocks.Writer{}
+	mockWriter.On("WriteSpan", mock.Anything, mock.AnythingOfType("*model.Span")).
+		Return(nil).Times(2)
+	withTestServer(func(ts *testServer) {
+		ts.spanReader.On("FindTraceIDs", mock.Anything, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+			return query.ServiceName == "frontend" && query.NumTraces == 3
+		})).Return([]model.TraceID{mockTraceID}, nil).Once()
+		ts.spanReader.On("GetTrace", mock.Anything, mockTraceID).
+			Return(mockTrace, nil).Once()
+		var response struct {
+			Data []archivedTrace `json:"data"`
+		}
+		err := postJSON(ts.server.URL+"/api/archive?service=frontend&limit=3", []string{}, &response)
+		require.NoError(t, err)
+		assert.Equal(t, []archivedTrace{{TraceID: ui.TraceID(mockTraceID.String()), Archived: true}}, response.Data)
+	}, querysvc.QueryServiceOptions{ArchiveSpanWriter: mockWriter})
+}
+
+func TestArchiveTraces_Errors(t *testing.T) {
+	withTestServer(func(ts *testServer) {
+		var response structuredResponse
+		err := postJSON(ts.server.URL+"/api/archive?traceID=x", []string{}, &response)
+		require.ErrorContains(t, err, "400 error from server")
+
+		err = postJSON(ts.server.URL+"/api/archive", []string{}, &response)
+		require.ErrorContains(t, err, "400 error from server")
+
+		ts.spanReader.On("FindTraceIDs", mock.Anything, mock.Anything).
+			Return(nil, errors.New("storage unavailable")).Once()
+		err = postJSON(ts.server.URL+"/api/archive?service=frontend", []string{}, &response)
+		require.EqualError(t, err, `500 error from server: {"data":null,"total":0,"limit":0,"offset":0,"errors":[{"code":500,"msg":"storage unavailable"}]}`+"\n")
+	}, querysvc.QueryServiceOptions{ArchiveSpanWriter: &spanstoremocks.Writer{}})
+}
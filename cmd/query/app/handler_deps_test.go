@@ -16,6 +16,8 @@
 package app
 
 import (
+	"context"
+	"net/http/httptest"
 	"sort"
 	"testing"
 	"time"
@@ -23,9 +25,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	ui "github.com/jaegertracing/jaeger/model/json"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
 )
 
 func TestDeduplicateDependencies(t *testing.T) {
@@ -359,3 +367,213 @@ func TestGetDependenciesLookbackParsingFailure(t *testing.T) {
 	err := getJSON(ts.server.URL+"/api/dependencies?endTs=1476374248550&service=testing&lookback=shazbot", &response)
 	require.Error(t, err)
 }
+
+func TestDeduplicateDependenciesWithErrors(t *testing.T) {
+	handler := &APIHandler{}
+	dependencies := []querysvc.DependencyLinkWithErrors{
+		{DependencyLink: model.DependencyLink{Parent: "Drogon", Child: "Rhaegal", CallCount: 10}, ErrorCount: 2},
+		{DependencyLink: model.DependencyLink{Parent: "Drogon", Child: "Rhaegal", CallCount: 5}, ErrorCount: 1, ErrorCountApproximate: true},
+		{DependencyLink: model.DependencyLink{Parent: "Viserion", Child: "Drogon", CallCount: 3}},
+		{DependencyLink: model.DependencyLink{Parent: "Viserion", Child: "Balerion", CallCount: 4}, ErrorCount: 4},
+	}
+	errorCount := func(n uint64) *uint64 { return &n }
+	sortLinks := func(links []ui.DependencyLink) {
+		sort.Slice(links, func(i, j int) bool { return links[i].Child < links[j].Child })
+	}
+
+	actual := handler.deduplicateDependenciesWithErrors(dependencies, "Drogon")
+	sortLinks(actual)
+	assert.Equal(t, []ui.DependencyLink{
+		{Parent: "Viserion", Child: "Drogon", CallCount: 3, ErrorCount: errorCount(0)},
+		{Parent: "Drogon", Child: "Rhaegal", CallCount: 15, ErrorCount: errorCount(3), ErrorCountApproximate: true},
+	}, actual)
+
+	assert.Len(t, handler.deduplicateDependenciesWithErrors(dependencies, ""), 3)
+}
+
+func TestGetDependenciesWithErrors(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	ts.dependencyReader.On("GetDependencies",
+		mock.Anything, // context
+		endTs,
+		defaultDependencyLookbackDuration,
+	).Return([]model.DependencyLink{{Parent: "killer", Child: "queen", CallCount: 12}}, nil).Times(1)
+	ts.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{}, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/dependencies?endTs=1476374248550&service=queen&withErrors=true", &response)
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{
+		"parent":                "killer",
+		"child":                 "queen",
+		"callCount":             12.0,
+		"errorCount":            0.0,
+		"errorCountApproximate": true,
+	}}, response.Data)
+}
+
+func TestGetDependenciesWithErrorsFailures(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	ts.dependencyReader.On("GetDependencies", mock.Anything, endTs, defaultDependencyLookbackDuration).Return(nil, errStorage).Times(1)
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/dependencies?endTs=1476374248550&withErrors=true", &response)
+	require.ErrorContains(t, err, "500 error")
+
+	err = getJSON(ts.server.URL+"/api/dependencies?endTs=1476374248550&withErrors=shazbot", &response)
+	require.ErrorContains(t, err, "400 error")
+}
+
+func TestGetDependenciesTimeSeries(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	start := time.UnixMilli(1476374248550)
+	ts.dependencyReader.On("GetDependencies", mock.Anything, start.Add(time.Hour), time.Hour).
+		Return([]model.DependencyLink{
+			{Parent: "killer", Child: "queen", CallCount: 12},
+			{Parent: "bishop", Child: "rook", CallCount: 3},
+		}, nil).Once()
+	ts.dependencyReader.On("GetDependencies", mock.Anything, start.Add(2*time.Hour), time.Hour).
+		Return([]model.DependencyLink{{Parent: "killer", Child: "queen", CallCount: 5}}, nil).Once()
+
+	var response struct {
+		Data []dependenciesInterval `json:"data"`
+	}
+	err := getJSON(ts.server.URL+"/api/dependencies/timeseries?start=1476374248550&end=1476381448550&interval=1h&service=queen", &response)
+	require.NoError(t, err)
+	assert.Equal(t, []dependenciesInterval{
+		{
+			Start:        1476374248550,
+			End:          1476377848550,
+			Dependencies: []ui.DependencyLink{{Parent: "killer", Child: "queen", CallCount: 12}},
+		},
+		{
+			Start:        1476377848550,
+			End:          1476381448550,
+			Dependencies: []ui.DependencyLink{{Parent: "killer", Child: "queen", CallCount: 5}},
+		},
+	}, response.Data)
+	ts.dependencyReader.AssertExpectations(t)
+}
+
+func TestGetDependenciesTimeSeriesDefaultInterval(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.dependencyReader.On("GetDependencies", mock.Anything, mock.Anything, defaultDependencyIntervalDuration).
+		Return([]model.DependencyLink{}, nil).Times(24)
+
+	var response struct {
+		Data []dependenciesInterval `json:"data"`
+	}
+	err := getJSON(ts.server.URL+"/api/dependencies/timeseries?end=1476374248550", &response)
+	require.NoError(t, err)
+	// the default range is the default lookback of the dependencies
+	assert.Len(t, response.Data, 24)
+	assert.Equal(t, int64(1476374248550)-defaultDependencyLookbackDuration.Milliseconds(), response.Data[0].Start)
+	ts.dependencyReader.AssertExpectations(t)
+}
+
+func TestGetDependenciesTimeSeriesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status string
+	}{
+		{name: "invalid start", query: "start=shazbot", status: "400 error"},
+		{name: "invalid end", query: "end=shazbot", status: "400 error"},
+		{name: "invalid interval", query: "interval=shazbot", status: "400 error"},
+		{name: "too many intervals", query: "start=0&end=1476374248550&interval=1h", status: "400 error"},
+		{name: "storage failure", query: "start=1476374248550&end=1476377848550", status: "500 error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := initializeTestServer()
+			defer ts.server.Close()
+			ts.dependencyReader.On("GetDependencies", mock.Anything, mock.Anything, mock.Anything).Return(nil, errStorage)
+
+			var response structuredResponse
+			err := getJSON(ts.server.URL+"/api/dependencies/timeseries?"+test.query, &response)
+			require.ErrorContains(t, err, test.status)
+		})
+	}
+}
+
+// operationDependencyReader is a dependency reader implementing dependencystore.OperationDependencyReader.
+type operationDependencyReader struct {
+	*depsmocks.Reader
+}
+
+func (r operationDependencyReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	args := r.Called(ctx, service, endTs, lookback)
+	links, _ := args.Get(0).([]dependencystore.OperationDependencyLink)
+	return links, args.Error(1)
+}
+
+func initializeOperationDependenciesTestServer(t *testing.T) (*httptest.Server, *depsmocks.Reader) {
+	dependencyReader := &depsmocks.Reader{}
+	qs := querysvc.NewQueryService(&spanstoremocks.Reader{}, operationDependencyReader{dependencyReader}, querysvc.QueryServiceOptions{})
+	r := NewRouter()
+	NewAPIHandler(qs, &tenancy.Manager{}, HandlerOptions.Logger(zap.NewNop())).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server, dependencyReader
+}
+
+func TestGetOperationDependencies(t *testing.T) {
+	server, dependencyReader := initializeOperationDependenciesTestServer(t)
+	endTs := time.UnixMilli(1476374248550)
+	dependencyReader.On("GetOperationDependencies", mock.Anything, "queen", endTs, time.Hour).
+		Return([]dependencystore.OperationDependencyLink{
+			{Parent: "killer", ParentOperation: "sing", Child: "queen", ChildOperation: "rock", CallCount: 12},
+		}, nil)
+
+	var response struct {
+		Data []ui.OperationDependencyLink `json:"data"`
+	}
+	err := getJSON(server.URL+"/api/dependencies/operations?service=queen&endTs=1476374248550&lookback=3600000", &response)
+	require.NoError(t, err)
+	assert.Equal(t, []ui.OperationDependencyLink{
+		{Parent: "killer", ParentOperation: "sing", Child: "queen", ChildOperation: "rock", CallCount: 12},
+	}, response.Data)
+}
+
+func TestGetOperationDependenciesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status string
+	}{
+		{name: "missing service", query: "endTs=1476374248550", status: "400 error"},
+		{name: "invalid end", query: "service=queen&endTs=shazbot", status: "400 error"},
+		{name: "storage failure", query: "service=queen", status: "500 error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, dependencyReader := initializeOperationDependenciesTestServer(t)
+			dependencyReader.On("GetOperationDependencies", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(nil, errStorage)
+
+			var response structuredResponse
+			err := getJSON(server.URL+"/api/dependencies/operations?"+test.query, &response)
+			require.ErrorContains(t, err, test.status)
+		})
+	}
+}
+
+func TestGetOperationDependenciesUnsupported(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/dependencies/operations?service=queen", &response)
+	require.ErrorContains(t, err, "501 error")
+}
@@ -54,13 +54,60 @@ func (handlerOptions) Prefix(prefix string) HandlerOption {
 	}
 }
 
-// QueryLookbackDuration creates a HandlerOption that initializes lookback duration
+// QueryLookbackDuration creates a HandlerOption that initializes the lookback duration of the searches
+// without start time, overriding the default lookback of the query service.
 func (handlerOptions) QueryLookbackDuration(queryLookbackDuration time.Duration) HandlerOption {
 	return func(apiHandler *APIHandler) {
 		apiHandler.queryParser.traceQueryLookbackDuration = queryLookbackDuration
 	}
 }
 
+// DefaultSearchLimit creates a HandlerOption that initializes the number of traces searched
+// when the request does not specify a limit.
+func (handlerOptions) DefaultSearchLimit(limit int) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.queryParser.defaultSearchLimit = limit
+	}
+}
+
+// AllowedSearchTagKeys creates a HandlerOption that restricts the tag keys of the searches,
+// an empty list meaning no restriction.
+func (handlerOptions) AllowedSearchTagKeys(keys []string) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		if len(keys) == 0 {
+			return
+		}
+		apiHandler.queryParser.allowedTagKeys = make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			apiHandler.queryParser.allowedTagKeys[key] = struct{}{}
+		}
+	}
+}
+
+// ActiveServicesWindow creates a HandlerOption that lists only the services with traces within the window
+// before now, when the request does not specify its own window; 0 lists all the services.
+func (handlerOptions) ActiveServicesWindow(window time.Duration) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.activeServicesWindow = window
+	}
+}
+
+// MaxRequestBodyBytes creates a HandlerOption that limits the size of the request bodies,
+// larger bodies being rejected with 413 Request Entity Too Large; 0 means no limit.
+func (handlerOptions) MaxRequestBodyBytes(maxBytes int64) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.maxRequestBodyBytes = maxBytes
+	}
+}
+
+// SelfTracing creates a HandlerOption that adds the phases of the queries to their traces,
+// and returns their trace ID in the SelfTraceIDHeader response header.
+func (handlerOptions) SelfTracing(enabled bool) HandlerOption {
+	return func(apiHandler *APIHandler) {
+		apiHandler.selfTracing = enabled
+	}
+}
+
 // Tracer creates a HandlerOption that passes the tracer to the handler
 func (handlerOptions) Tracer(tracer *jtracer.JTracer) HandlerOption {
 	return func(apiHandler *APIHandler) {
@@ -28,6 +28,7 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/gorilla/mux"
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
@@ -46,12 +47,27 @@ import (
 
 const (
 	traceIDParam          = "traceID"
+	traceIDPrefixParam    = "traceIDPrefix"
 	endTsParam            = "endTs"
 	lookbackParam         = "lookback"
 	stepParam             = "step"
 	rateParam             = "ratePer"
 	quantileParam         = "quantile"
 	groupByOperationParam = "groupByOperation"
+	fieldsParam           = "fields"
+	anonymizeParam        = "anonymize"
+	trimParam             = "trimZeroDurationSpans"
+	activeWithinParam     = "activeWithin"
+	intervalParam         = "interval"
+	spanOffsetParam       = "spanOffset"
+	spanLimitParam        = "spanLimit"
+	windowParam           = "window"
+
+	// totalSpanCountHeader is the number of spans of a trace served by windows of spans.
+	totalSpanCountHeader = "X-Total-Span-Count"
+
+	// SelfTraceIDHeader is the ID of the self-trace of a query, i.e. the trace of the query in Jaeger.
+	SelfTraceIDHeader = "X-Jaeger-Self-Trace-Id"
 
 	defaultAPIPrefix  = "api"
 	prettyPrintIndent = "    "
@@ -68,6 +84,44 @@ type structuredResponse struct {
 	Limit  int               `json:"limit"`
 	Offset int               `json:"offset"`
 	Errors []structuredError `json:"errors"`
+	// Warnings report partial failures, e.g. of some of the upstreams in federation mode
+	Warnings []string `json:"warnings,omitempty"`
+	// Truncated reports that Data holds only the first Limit results
+	Truncated bool `json:"truncated,omitempty"`
+	// Cost estimates the work done for the searches of the request
+	Cost *queryCostResponse `json:"cost,omitempty"`
+	// SearchWindow is the time window of a search requested with the widen parameter
+	SearchWindow *searchWindowResponse `json:"searchWindow,omitempty"`
+}
+
+// searchWindowResponse is the time window of a search widened while it found no traces, see querysvc.SearchWindow.
+// The times are in microseconds since the epoch, like the start and end parameters.
+type searchWindowResponse struct {
+	Start   int64 `json:"start"`
+	End     int64 `json:"end"`
+	Widened int   `json:"widened"`
+}
+
+// queryCostResponse is the estimated cost of the searches of a request, see querysvc.QueryCost.
+type queryCostResponse struct {
+	SpansScanned   int  `json:"spansScanned"`
+	TracesExamined int  `json:"tracesExamined"`
+	PostFilters    int  `json:"postFilters"`
+	ScanBudgetHit  bool `json:"scanBudgetHit"`
+}
+
+// queryCostToResponse returns the cost of the searches of the request, nil if there was none.
+func queryCostToResponse(ctx context.Context) *queryCostResponse {
+	cost := querysvc.GetQueryCost(ctx)
+	if cost == nil {
+		return nil
+	}
+	return &queryCostResponse{
+		SpansScanned:   cost.SpansScanned,
+		TracesExamined: cost.TracesExamined,
+		PostFilters:    cost.PostFilters,
+		ScanBudgetHit:  cost.ScanBudgetHit,
+	}
 }
 
 type structuredError struct {
@@ -91,6 +145,10 @@ type APIHandler struct {
 	apiPrefix           string
 	logger              *zap.Logger
 	tracer              *jtracer.JTracer
+	maxRequestBodyBytes int64
+	selfTracing         bool
+	// activeServicesWindow is the default window of the services listed, 0 listing all the services
+	activeServicesWindow time.Duration
 }
 
 // NewAPIHandler returns an APIHandler
@@ -98,8 +156,7 @@ func NewAPIHandler(queryService *querysvc.QueryService, tm *tenancy.Manager, opt
 	aH := &APIHandler{
 		queryService: queryService,
 		queryParser: queryParser{
-			traceQueryLookbackDuration: defaultTraceQueryLookbackDuration,
-			timeNow:                    time.Now,
+			timeNow: time.Now,
 		},
 		tenancyMgr: tm,
 	}
@@ -121,22 +178,36 @@ func NewAPIHandler(queryService *querysvc.QueryService, tm *tenancy.Manager, opt
 
 // RegisterRoutes registers routes for this handler on the given router
 func (aH *APIHandler) RegisterRoutes(router *mux.Router) {
+	aH.handleFunc(router, aH.getTracesBatch, "/traces/batch").Methods(http.MethodPost)
+	aH.handleFunc(router, aH.dryRunSearch, "/traces/dry-run").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.getTrace, "/traces/{%s}", traceIDParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getCriticalPath, "/traces/{%s}/critical-path", traceIDParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getTraceSummary, "/traces/{%s}/summary", traceIDParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getTraceSkeleton, "/traces/{%s}/skeleton", traceIDParam).Methods(http.MethodGet)
 	aH.handleFunc(router, aH.archiveTrace, "/archive/{%s}", traceIDParam).Methods(http.MethodPost)
+	aH.handleFunc(router, aH.archiveTraces, "/archive").Methods(http.MethodPost)
 	aH.handleFunc(router, aH.search, "/traces").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.getServices, "/services").Methods(http.MethodGet)
 	// TODO change the UI to use this endpoint. Requires ?service= parameter.
 	aH.handleFunc(router, aH.getOperations, "/operations").Methods(http.MethodGet)
 	// TODO - remove this when UI catches up
 	aH.handleFunc(router, aH.getOperationsLegacy, "/services/{%s}/operations", serviceParam).Methods(http.MethodGet)
+	aH.handleFunc(router, aH.getTagKeys, "/services/{%s}/tag-keys", serviceParam).Methods(http.MethodGet)
 	aH.handleFunc(router, aH.transformOTLP, "/transform").Methods(http.MethodPost)
 	aH.handleFunc(router, aH.dependencies, "/dependencies").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.dependenciesTimeSeries, "/dependencies/timeseries").Methods(http.MethodGet)
+	aH.handleFunc(router, aH.operationDependencies, "/dependencies/operations").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.latencies, "/metrics/latencies").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.calls, "/metrics/calls").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.errors, "/metrics/errors").Methods(http.MethodGet)
 	aH.handleFunc(router, aH.minStep, "/metrics/minstep").Methods(http.MethodGet)
 }
 
+// RegisterAdminRoutes registers the administrative routes, which are meant to be served by the admin server only.
+func (aH *APIHandler) RegisterAdminRoutes(router *mux.Router) {
+	aH.handleFunc(router, aH.deleteTrace, "/admin/traces/{%s}", traceIDParam).Methods(http.MethodDelete)
+}
+
 func (aH *APIHandler) handleFunc(
 	router *mux.Router,
 	f func(http.ResponseWriter, *http.Request),
@@ -144,7 +215,22 @@ func (aH *APIHandler) handleFunc(
 	args ...any,
 ) *mux.Route {
 	route := aH.formatRoute(routeFmt, args...)
-	var handler http.Handler = http.HandlerFunc(f)
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if aH.maxRequestBodyBytes > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, aH.maxRequestBodyBytes)
+		}
+		ctx := querysvc.ContextWithQueryCost(querysvc.ContextWithWarnings(r.Context()))
+		if aH.selfTracing {
+			var traceID string
+			var ok bool
+			if ctx, traceID, ok = querysvc.ContextWithSelfTrace(ctx); ok {
+				w.Header().Set(SelfTraceIDHeader, traceID)
+				querysvc.StartSelfTracePhase(ctx, "validate parameters")
+				defer querysvc.EndSelfTracePhase(ctx)
+			}
+		}
+		f(w, r.WithContext(ctx))
+	})
 	if aH.tenancyMgr.Enabled {
 		handler = tenancy.ExtractTenantHTTPHandler(aH.tenancyMgr, handler)
 	}
@@ -160,8 +246,22 @@ func (aH *APIHandler) formatRoute(route string, args ...any) string {
 	return fmt.Sprintf("/%s"+route, args...)
 }
 
+// getServices lists the services, only the ones with traces within the activeWithin window before now
+// if set, e.g. activeWithin=24h, or within the default window of the handler; activeWithin=0 lists all of them.
 func (aH *APIHandler) getServices(w http.ResponseWriter, r *http.Request) {
-	services, err := aH.queryService.GetServices(r.Context())
+	window := aH.activeServicesWindow
+	if param := r.FormValue(activeWithinParam); param != "" {
+		var err error
+		window, err = time.ParseDuration(param)
+		if err == nil && window < 0 {
+			err = fmt.Errorf("%q is negative", param)
+		}
+		if err != nil {
+			aH.handleError(w, newParseError(err, activeWithinParam), http.StatusBadRequest)
+			return
+		}
+	}
+	services, err := aH.queryService.GetActiveServices(r.Context(), window)
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
 	}
@@ -195,6 +295,49 @@ func (aH *APIHandler) getOperationsLegacy(w http.ResponseWriter, r *http.Request
 	aH.writeJSON(w, r, &structuredRes)
 }
 
+// tagKeysResponse is the data of the response of getTagKeys.
+type tagKeysResponse struct {
+	Keys []string `json:"keys"`
+	// Approximate tells whether the keys are collected from a sample of the recent traces.
+	Approximate bool `json:"approximate"`
+}
+
+// getTagKeys returns the distinct tag keys of the spans of the service started within the window,
+// querysvc.DefaultTagKeysWindow by default, sorted and limited to the limit parameter.
+func (aH *APIHandler) getTagKeys(w http.ResponseWriter, r *http.Request) {
+	// given how getTagKeys is bound to URL route, serviceParam cannot be empty
+	service, _ := url.QueryUnescape(mux.Vars(r)[serviceParam])
+	var window time.Duration
+	if param := r.FormValue(windowParam); param != "" {
+		var err error
+		window, err = time.ParseDuration(param)
+		if err == nil && window <= 0 {
+			err = fmt.Errorf("%q is not positive", param)
+		}
+		if err != nil {
+			aH.handleError(w, newParseError(err, windowParam), http.StatusBadRequest)
+			return
+		}
+	}
+	limit, err := aH.parseOperationsLimit(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	tagKeys, err := aH.queryService.GetTagKeys(r.Context(), service, window, limit)
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	structuredRes := structuredResponse{
+		Data:      tagKeysResponse{Keys: tagKeys.Keys, Approximate: tagKeys.Approximate},
+		Total:     len(tagKeys.Keys),
+		Truncated: tagKeys.Truncated,
+	}
+	if tagKeys.Truncated {
+		structuredRes.Limit = len(tagKeys.Keys)
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
 func (aH *APIHandler) transformOTLP(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if aH.handleError(w, err, http.StatusBadRequest) {
@@ -207,7 +350,7 @@ func (aH *APIHandler) transformOTLP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var uiErrors []structuredError
-	structuredRes := aH.tracesToResponse(traces, false, uiErrors)
+	structuredRes := aH.tracesToResponse(traces, false, nil, false, uiErrors)
 	aH.writeJSON(w, r, structuredRes)
 }
 
@@ -219,9 +362,14 @@ func (aH *APIHandler) getOperations(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	spanKind := r.FormValue(spanKindParam)
-	operations, err := aH.queryService.GetOperations(
+	limit, err := aH.parseOperationsLimit(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	operations, truncated, err := aH.queryService.GetOperationsWithLimit(
 		r.Context(),
 		spanstore.OperationQueryParameters{ServiceName: service, SpanKind: spanKind},
+		limit,
 	)
 
 	if aH.handleError(w, err, http.StatusInternalServerError) {
@@ -235,40 +383,269 @@ func (aH *APIHandler) getOperations(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	structuredRes := structuredResponse{
-		Data:  data,
-		Total: len(operations),
+		Data:      data,
+		Total:     len(operations),
+		Truncated: truncated,
+	}
+	if truncated {
+		structuredRes.Limit = len(operations)
 	}
 	aH.writeJSON(w, r, &structuredRes)
 }
 
+func (*APIHandler) parseOperationsLimit(r *http.Request) (int, error) {
+	param := r.FormValue(limitParam)
+	if param == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(param)
+	if err != nil || limit < 0 {
+		return 0, newParseError(fmt.Errorf("%q is not a valid limit", param), limitParam)
+	}
+	return limit, nil
+}
+
 func (aH *APIHandler) search(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue(traceIDPrefixParam) != "" {
+		aH.searchByTraceIDPrefix(w, r)
+		return
+	}
 	tQuery, err := aH.queryParser.parseTraceQueryParams(r)
 	if aH.handleError(w, err, http.StatusBadRequest) {
 		return
 	}
+	fields, err := querysvc.ParseSpanFields(r.URL.Query()[fieldsParam])
+	if err != nil {
+		aH.handleError(w, newParseError(err, fieldsParam), http.StatusBadRequest)
+		return
+	}
+	anonymize, err := aH.parseAnonymize(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	trim, err := aH.parseTrim(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+
+	if !acceptsZipkinV2(r) && acceptsEventStream(r) {
+		if stream := newEventStream(w); stream != nil {
+			aH.searchWithProgress(stream, r, tQuery, fields, anonymize, trim)
+			return
+		}
+	}
 
 	var uiErrors []structuredError
 	var tracesFromStorage []*model.Trace
+	var searchWindow *searchWindowResponse
 	if len(tQuery.traceIDs) > 0 {
 		tracesFromStorage, uiErrors, err = aH.tracesByIDs(r.Context(), tQuery.traceIDs)
 		if aH.handleError(w, err, http.StatusInternalServerError) {
 			return
 		}
 	} else {
-		tracesFromStorage, err = aH.queryService.FindTraces(r.Context(), &tQuery.TraceQueryParameters)
+		tracesFromStorage, searchWindow, err = aH.findTraces(r.Context(), tQuery)
+		if aH.handleError(w, err, searchErrorStatus(err)) {
+			return
+		}
+	}
+	aH.trimTraces(tracesFromStorage, trim)
+
+	if acceptsZipkinV2(r) {
+		// like the search of Zipkin, the traces not found are left out
+		zipkinTraces, err := aH.tracesToZipkin(tracesFromStorage, true, fields, anonymize)
 		if aH.handleError(w, err, http.StatusInternalServerError) {
 			return
 		}
+		aH.writeJSON(w, r, zipkinTraces)
+		return
 	}
+	structuredRes := aH.tracesToResponse(tracesFromStorage, true, fields, anonymize, uiErrors)
+	structuredRes.SearchWindow = searchWindow
+	aH.writeJSON(w, r, structuredRes)
+}
 
-	structuredRes := aH.tracesToResponse(tracesFromStorage, true, uiErrors)
+// findTraces searches the traces of the query, widening its time window while it finds no traces
+// if the query asks for it, in which case the time window searched is returned.
+func (aH *APIHandler) findTraces(ctx context.Context, tQuery *traceQueryParameters) ([]*model.Trace, *searchWindowResponse, error) {
+	if !tQuery.widen {
+		traces, err := aH.queryService.FindTracesWithFilter(ctx, &tQuery.TraceQueryParameters, tQuery.traceFilter())
+		return traces, nil, err
+	}
+	traces, window, err := aH.queryService.FindTracesWidening(ctx, &tQuery.TraceQueryParameters, tQuery.traceFilter())
+	if err != nil {
+		return nil, nil, err
+	}
+	return traces, &searchWindowResponse{
+		Start:   window.StartTimeMin.UnixMicro(),
+		End:     window.StartTimeMax.UnixMicro(),
+		Widened: window.Widened,
+	}, nil
+}
+
+// searchByTraceIDPrefix implements the search of the traces whose ID starts with the traceIDPrefix parameter,
+// e.g. for the trace IDs truncated in logs. All the matching traces are returned, up to a small limit,
+// the response being truncated when more traces match.
+func (aH *APIHandler) searchByTraceIDPrefix(w http.ResponseWriter, r *http.Request) {
+	prefix, err := querysvc.ParseTraceIDPrefix(r.FormValue(traceIDPrefixParam))
+	if err != nil {
+		aH.handleError(w, newParseError(err, traceIDPrefixParam), http.StatusBadRequest)
+		return
+	}
+	fields, err := querysvc.ParseSpanFields(r.URL.Query()[fieldsParam])
+	if err != nil {
+		aH.handleError(w, newParseError(err, fieldsParam), http.StatusBadRequest)
+		return
+	}
+	anonymize, err := aH.parseAnonymize(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	trim, err := aH.parseTrim(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	traces, truncated, err := aH.queryService.SearchByTraceIDPrefix(r.Context(), prefix)
+	if errors.Is(err, querysvc.ErrTraceIDPrefixUnsupported) {
+		aH.handleError(w, err, http.StatusNotImplemented)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	aH.trimTraces(traces, trim)
+
+	if acceptsZipkinV2(r) {
+		zipkinTraces, err := aH.tracesToZipkin(traces, true, fields, anonymize)
+		if aH.handleError(w, err, http.StatusInternalServerError) {
+			return
+		}
+		aH.writeJSON(w, r, zipkinTraces)
+		return
+	}
+	structuredRes := aH.tracesToResponse(traces, true, fields, anonymize, nil)
+	structuredRes.Limit = querysvc.TraceIDPrefixLimit
+	structuredRes.Truncated = truncated
 	aH.writeJSON(w, r, structuredRes)
 }
 
-func (aH *APIHandler) tracesToResponse(traces []*model.Trace, adjust bool, uiErrors []structuredError) *structuredResponse {
+// searchWithProgress streams the progress reported by the storage as server-sent events,
+// followed by the result, or an error if the search failed.
+func (aH *APIHandler) searchWithProgress(stream *eventStream, r *http.Request, tQuery *traceQueryParameters, fields querysvc.SpanFields, anonymize, trim bool) {
+	defer stream.close()
+	ctx := spanstore.ContextWithProgress(r.Context(), func(progress spanstore.SearchProgress) {
+		if err := stream.send(progressEvent, progress); err != nil {
+			aH.logger.Debug("Failed writing search progress", zap.Error(err))
+		}
+	})
+
+	var uiErrors []structuredError
+	var tracesFromStorage []*model.Trace
+	var searchWindow *searchWindowResponse
+	var err error
+	if len(tQuery.traceIDs) > 0 {
+		tracesFromStorage, uiErrors, err = aH.tracesByIDs(ctx, tQuery.traceIDs)
+	} else {
+		tracesFromStorage, searchWindow, err = aH.findTraces(ctx, tQuery)
+	}
+	if err != nil {
+		aH.logger.Error("HTTP handler, Internal Server Error", zap.Error(err))
+		errorRes := structuredResponse{
+			Errors: []structuredError{{Code: searchErrorStatus(err), Msg: err.Error()}},
+		}
+		if err := stream.send(errorEvent, errorRes); err != nil {
+			aH.logger.Error("Failed writing search error", zap.Error(err))
+		}
+		return
+	}
+	aH.trimTraces(tracesFromStorage, trim)
+
+	structuredRes := aH.tracesToResponse(tracesFromStorage, true, fields, anonymize, uiErrors)
+	structuredRes.Warnings = querysvc.GetWarnings(r.Context())
+	structuredRes.Cost = queryCostToResponse(r.Context())
+	structuredRes.SearchWindow = searchWindow
+	if err := stream.send(resultEvent, structuredRes); err != nil {
+		aH.logger.Error("Failed writing search result", zap.Error(err))
+	}
+}
+
+// dryRunSearchResponse is the effective query of a search, using the names of the search parameters.
+type dryRunSearchResponse struct {
+	Service       string            `json:"service"`
+	Operation     string            `json:"operation,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Start         int64             `json:"start"`
+	End           int64             `json:"end"`
+	MinDuration   string            `json:"minDuration,omitempty"`
+	MaxDuration   string            `json:"maxDuration,omitempty"`
+	Limit         int               `json:"limit"`
+	MinSpanCount  int               `json:"minSpanCount,omitempty"`
+	MaxSpanCount  int               `json:"maxSpanCount,omitempty"`
+	HasError      bool              `json:"hasError,omitempty"`
+	RootSpanOnly  bool              `json:"rootSpanOnly,omitempty"`
+	CallerService string            `json:"callerService,omitempty"`
+	CalleeService string            `json:"calleeService,omitempty"`
+	TraceIDs      []string          `json:"traceID,omitempty"`
+}
+
+// dryRunSearch validates the parameters of a search like the search endpoint, without searching the storage,
+// and returns the effective query, after the defaults and the limits are applied, with the warnings about them.
+func (aH *APIHandler) dryRunSearch(w http.ResponseWriter, r *http.Request) {
+	tQuery, err := aH.queryParser.parseTraceQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	response := dryRunSearchResponse{
+		MinSpanCount:  tQuery.spanCount.Min,
+		MaxSpanCount:  tQuery.spanCount.Max,
+		HasError:      tQuery.errorFilter.HasError,
+		RootSpanOnly:  tQuery.errorFilter.RootSpanOnly,
+		CallerService: tQuery.serviceEdge.CallerService,
+		CalleeService: tQuery.serviceEdge.CalleeService,
+	}
+	// searches by trace IDs do not search the storage by criteria, so they are not subject to the guardrails
+	query := &tQuery.TraceQueryParameters
+	if len(tQuery.traceIDs) > 0 {
+		for _, traceID := range tQuery.traceIDs {
+			response.TraceIDs = append(response.TraceIDs, traceID.String())
+		}
+	} else {
+		query, err = aH.queryService.ValidateSearch(r.Context(), query)
+		if aH.handleError(w, err, searchErrorStatus(err)) {
+			return
+		}
+	}
+	response.Service = query.ServiceName
+	response.Operation = query.OperationName
+	if len(query.Tags) > 0 {
+		response.Tags = query.Tags
+	}
+	if !query.StartTimeMin.IsZero() {
+		response.Start = query.StartTimeMin.UnixMicro()
+	}
+	response.End = query.StartTimeMax.UnixMicro()
+	if query.DurationMin > 0 {
+		response.MinDuration = query.DurationMin.String()
+	}
+	if query.DurationMax > 0 {
+		response.MaxDuration = query.DurationMax.String()
+	}
+	response.Limit = query.NumTraces
+	aH.writeJSON(w, r, &structuredResponse{Data: response})
+}
+
+// searchErrorStatus returns the HTTP status of a failed search.
+func searchErrorStatus(err error) int {
+	if errors.Is(err, querysvc.ErrSearchRejected) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (aH *APIHandler) tracesToResponse(traces []*model.Trace, adjust bool, fields querysvc.SpanFields, anonymize bool, uiErrors []structuredError) *structuredResponse {
 	uiTraces := make([]*ui.Trace, len(traces))
 	for i, v := range traces {
-		uiTrace, uiErr := aH.convertModelToUI(v, adjust)
+		uiTrace, uiErr := aH.convertModelToUI(v, adjust, fields, anonymize)
 		if uiErr != nil {
 			uiErrors = append(uiErrors, *uiErr)
 		}
@@ -281,6 +658,22 @@ func (aH *APIHandler) tracesToResponse(traces []*model.Trace, adjust bool, uiErr
 	}
 }
 
+// tracesToZipkin converts the traces to Zipkin v2 spans, a list per trace. The format having no room
+// for the errors of the adjusters or of the anonymization, they are logged.
+func (aH *APIHandler) tracesToZipkin(traces []*model.Trace, adjust bool, fields querysvc.SpanFields, anonymize bool) ([][]*zipkinmodel.SpanModel, error) {
+	zipkinTraces := make([][]*zipkinmodel.SpanModel, len(traces))
+	for i, trace := range traces {
+		trace, err := aH.prepareTrace(trace, adjust, fields, anonymize)
+		if err != nil {
+			aH.logger.Warn("Failed preparing the trace for Zipkin", zap.Error(err))
+		}
+		if zipkinTraces[i], err = modelToZipkin(trace); err != nil {
+			return nil, err
+		}
+	}
+	return zipkinTraces, nil
+}
+
 func (aH *APIHandler) tracesByIDs(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, []structuredError, error) {
 	var traceErrors []structuredError
 	retMe := make([]*model.Trace, 0, len(traceIDs))
@@ -307,6 +700,18 @@ func (aH *APIHandler) dependencies(w http.ResponseWriter, r *http.Request) {
 	}
 	service := r.FormValue(serviceParam)
 
+	if dqp.withErrors {
+		dependencies, err := aH.queryService.GetDependenciesWithErrors(r.Context(), dqp.endTs, dqp.lookback)
+		if aH.handleError(w, err, http.StatusInternalServerError) {
+			return
+		}
+		structuredRes := structuredResponse{
+			Data: aH.deduplicateDependenciesWithErrors(dependencies, service),
+		}
+		aH.writeJSON(w, r, &structuredRes)
+		return
+	}
+
 	dependencies, err := aH.queryService.GetDependencies(r.Context(), dqp.endTs, dqp.lookback)
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
@@ -319,6 +724,68 @@ func (aH *APIHandler) dependencies(w http.ResponseWriter, r *http.Request) {
 	aH.writeJSON(w, r, &structuredRes)
 }
 
+// dependenciesInterval is the dependency graph of an interval of the dependencies time series,
+// with its start and end as unix milliseconds.
+type dependenciesInterval struct {
+	Start        int64               `json:"start"`
+	End          int64               `json:"end"`
+	Dependencies []ui.DependencyLink `json:"dependencies"`
+}
+
+func (aH *APIHandler) dependenciesTimeSeries(w http.ResponseWriter, r *http.Request) {
+	tsp, err := aH.queryParser.parseDependenciesTimeSeriesParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	service := r.FormValue(serviceParam)
+
+	intervals, err := aH.queryService.GetDependenciesTimeSeries(r.Context(), tsp.start, tsp.end, tsp.interval)
+	if errors.Is(err, querysvc.ErrInvalidDependencyIntervals) {
+		aH.handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	data := make([]dependenciesInterval, 0, len(intervals))
+	for _, interval := range intervals {
+		data = append(data, dependenciesInterval{
+			Start:        interval.Start.UnixMilli(),
+			End:          interval.End.UnixMilli(),
+			Dependencies: aH.deduplicateDependencies(aH.filterDependenciesByService(interval.Dependencies, service)),
+		})
+	}
+	aH.writeJSON(w, r, &structuredResponse{Data: data})
+}
+
+// operationDependencies returns the calls between the operations of the service and the operations of the other services.
+func (aH *APIHandler) operationDependencies(w http.ResponseWriter, r *http.Request) {
+	dqp, err := aH.queryParser.parseDependenciesQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	service := r.FormValue(serviceParam)
+	if service == "" {
+		aH.handleError(w, errServiceParameterRequired, http.StatusBadRequest)
+		return
+	}
+
+	dependencies, err := aH.queryService.GetOperationDependencies(r.Context(), service, dqp.endTs, dqp.lookback)
+	if errors.Is(err, querysvc.ErrOperationDependenciesUnsupported) {
+		aH.handleError(w, err, http.StatusNotImplemented)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	data := make([]ui.OperationDependencyLink, 0, len(dependencies))
+	for _, d := range dependencies {
+		data = append(data, ui.OperationDependencyLink(d))
+	}
+	aH.writeJSON(w, r, &structuredResponse{Data: data})
+}
+
 func (aH *APIHandler) latencies(w http.ResponseWriter, r *http.Request) {
 	q, err := strconv.ParseFloat(r.FormValue(quantileParam), 64)
 	if err != nil {
@@ -373,7 +840,8 @@ func (aH *APIHandler) metrics(w http.ResponseWriter, r *http.Request, getMetrics
 	aH.writeJSON(w, r, m)
 }
 
-func (aH *APIHandler) convertModelToUI(trace *model.Trace, adjust bool) (*ui.Trace, *structuredError) {
+// prepareTrace adjusts, anonymizes and projects the trace, returning the trace even if some of these failed.
+func (aH *APIHandler) prepareTrace(trace *model.Trace, adjust bool, fields querysvc.SpanFields, anonymize bool) (*model.Trace, error) {
 	var errs []error
 	if adjust {
 		var err error
@@ -382,9 +850,21 @@ func (aH *APIHandler) convertModelToUI(trace *model.Trace, adjust bool) (*ui.Tra
 			errs = append(errs, err)
 		}
 	}
+	if anonymize {
+		if err := aH.queryService.AnonymizeTrace(trace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// projection is applied after adjusters, which may depend on the fields being removed
+	querysvc.ProjectTrace(trace, fields)
+	return trace, errors.Join(errs...)
+}
+
+func (aH *APIHandler) convertModelToUI(trace *model.Trace, adjust bool, fields querysvc.SpanFields, anonymize bool) (*ui.Trace, *structuredError) {
+	trace, err := aH.prepareTrace(trace, adjust, fields, anonymize)
 	uiTrace := uiconv.FromDomain(trace)
 	var uiError *structuredError
-	if err := errors.Join(errs...); err != nil {
+	if err != nil {
 		uiError = &structuredError{
 			Msg:     err.Error(),
 			TraceID: uiTrace.TraceID,
@@ -412,6 +892,45 @@ func (*APIHandler) deduplicateDependencies(dependencies []model.DependencyLink)
 	return result
 }
 
+// deduplicateDependenciesWithErrors adds up the calls and the errors between the same services,
+// keeping the links of the service if one is given.
+func (*APIHandler) deduplicateDependenciesWithErrors(dependencies []querysvc.DependencyLinkWithErrors, service string) []ui.DependencyLink {
+	type Key struct {
+		parent string
+		child  string
+	}
+	links := make(map[Key]*querysvc.DependencyLinkWithErrors)
+
+	for _, l := range dependencies {
+		if service != "" && l.Parent != service && l.Child != service {
+			continue
+		}
+		key := Key{l.Parent, l.Child}
+		if link, ok := links[key]; ok {
+			link.CallCount += l.CallCount
+			link.ErrorCount += l.ErrorCount
+			link.ErrorCountApproximate = link.ErrorCountApproximate || l.ErrorCountApproximate
+		} else {
+			link := l
+			links[key] = &link
+		}
+	}
+
+	result := make([]ui.DependencyLink, 0, len(links))
+	for k, v := range links {
+		errorCount := v.ErrorCount
+		result = append(result, ui.DependencyLink{
+			Parent:                k.parent,
+			Child:                 k.child,
+			CallCount:             v.CallCount,
+			ErrorCount:            &errorCount,
+			ErrorCountApproximate: v.ErrorCountApproximate,
+		})
+	}
+
+	return result
+}
+
 func (*APIHandler) filterDependenciesByService(
 	dependencies []model.DependencyLink,
 	service string,
@@ -433,7 +952,7 @@ func (*APIHandler) filterDependenciesByService(
 func (aH *APIHandler) parseTraceID(w http.ResponseWriter, r *http.Request) (model.TraceID, bool) {
 	vars := mux.Vars(r)
 	traceIDVar := vars[traceIDParam]
-	traceID, err := model.TraceIDFromString(traceIDVar)
+	traceID, err := querysvc.ParseTraceID(traceIDVar)
 	if aH.handleError(w, err, http.StatusBadRequest) {
 		return traceID, false
 	}
@@ -442,12 +961,34 @@ func (aH *APIHandler) parseTraceID(w http.ResponseWriter, r *http.Request) (mode
 
 // getTrace implements the REST API /traces/{trace-id}
 // It parses trace ID from the path, fetches the trace from QueryService,
-// formats it in the UI JSON format, and responds to the client.
+// formats it in the UI JSON format, or as the Zipkin v2 spans of the trace
+// with Accept: application/json; format=zipkin-v2, and responds to the client.
 func (aH *APIHandler) getTrace(w http.ResponseWriter, r *http.Request) {
 	traceID, ok := aH.parseTraceID(w, r)
 	if !ok {
 		return
 	}
+	fields, err := querysvc.ParseSpanFields(r.URL.Query()[fieldsParam])
+	if err != nil {
+		aH.handleError(w, newParseError(err, fieldsParam), http.StatusBadRequest)
+		return
+	}
+	anonymize, err := aH.parseAnonymize(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	trim, err := aH.parseTrim(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	spanOffset, err := parseSpanCount(r, spanOffsetParam)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	spanLimit, err := parseSpanCount(r, spanLimitParam)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
 	trace, err := aH.queryService.GetTrace(r.Context(), traceID)
 	if errors.Is(err, spanstore.ErrTraceNotFound) {
 		aH.handleError(w, err, http.StatusNotFound)
@@ -456,12 +997,296 @@ func (aH *APIHandler) getTrace(w http.ResponseWriter, r *http.Request) {
 	if aH.handleError(w, err, http.StatusInternalServerError) {
 		return
 	}
+	if trim {
+		trace = aH.queryService.TrimTrace(trace)
+	}
 
+	adjust := shouldAdjust(r)
 	var uiErrors []structuredError
-	structuredRes := aH.tracesToResponse([]*model.Trace{trace}, shouldAdjust(r), uiErrors)
+	if r.FormValue(spanOffsetParam) != "" || r.FormValue(spanLimitParam) != "" {
+		// the whole trace is adjusted before its window of spans is taken, as the adjusters depend on the other spans,
+		// and the window is projected afterwards, as the spans are ordered by their start time
+		trace, err = aH.prepareTrace(trace, adjust, nil, anonymize)
+		if err != nil {
+			aH.logger.Warn("Failed preparing the trace for its window of spans", zap.Error(err))
+			uiErrors = append(uiErrors, structuredError{Msg: err.Error(), TraceID: ui.TraceID(traceID.String())})
+		}
+		w.Header().Set(totalSpanCountHeader, strconv.Itoa(len(trace.Spans)))
+		trace = querysvc.SpanWindow(trace, spanOffset, spanLimit)
+		adjust, anonymize = false, false
+	}
+
+	if acceptsZipkinV2(r) {
+		zipkinTraces, err := aH.tracesToZipkin([]*model.Trace{trace}, adjust, fields, anonymize)
+		if aH.handleError(w, err, http.StatusInternalServerError) {
+			return
+		}
+		aH.writeJSON(w, r, zipkinTraces[0])
+		return
+	}
+	structuredRes := aH.tracesToResponse([]*model.Trace{trace}, adjust, fields, anonymize, uiErrors)
 	aH.writeJSON(w, r, structuredRes)
 }
 
+// getTracesBatch implements the REST API POST /traces/batch, whose body is a JSON array of trace IDs.
+// The traces are returned in the order of the IDs, with null in place of the traces not found,
+// which are also reported in the errors.
+func (aH *APIHandler) getTracesBatch(w http.ResponseWriter, r *http.Request) {
+	var rawTraceIDs []string
+	if err := json.NewDecoder(r.Body).Decode(&rawTraceIDs); err != nil {
+		aH.handleError(w, fmt.Errorf("cannot parse the trace IDs: %w", err), http.StatusBadRequest)
+		return
+	}
+	traceIDs := make([]model.TraceID, len(rawTraceIDs))
+	for i, rawTraceID := range rawTraceIDs {
+		traceID, err := querysvc.ParseTraceID(rawTraceID)
+		if err != nil {
+			aH.handleError(w, newParseError(err, traceIDParam), http.StatusBadRequest)
+			return
+		}
+		traceIDs[i] = traceID
+	}
+	fields, err := querysvc.ParseSpanFields(r.URL.Query()[fieldsParam])
+	if err != nil {
+		aH.handleError(w, newParseError(err, fieldsParam), http.StatusBadRequest)
+		return
+	}
+	anonymize, err := aH.parseAnonymize(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	trim, err := aH.parseTrim(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	traces, err := aH.queryService.GetTraces(r.Context(), traceIDs)
+	if errors.Is(err, querysvc.ErrTooManyTraceIDs) {
+		aH.handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	aH.trimTraces(traces, trim)
+
+	adjust := shouldAdjust(r)
+	var uiErrors []structuredError
+	uiTraces := make([]*ui.Trace, len(traces))
+	for i, trace := range traces {
+		if trace == nil {
+			uiErrors = append(uiErrors, structuredError{
+				Code:    http.StatusNotFound,
+				Msg:     spanstore.ErrTraceNotFound.Error(),
+				TraceID: ui.TraceID(traceIDs[i].String()),
+			})
+			continue
+		}
+		uiTrace, uiErr := aH.convertModelToUI(trace, adjust, fields, anonymize)
+		if uiErr != nil {
+			uiErrors = append(uiErrors, *uiErr)
+		}
+		uiTraces[i] = uiTrace
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:   uiTraces,
+		Total:  len(uiTraces),
+		Errors: uiErrors,
+	})
+}
+
+// getCriticalPath implements the REST API /traces/{trace-id}/critical-path.
+// It responds with the ordered list of IDs of the spans on the critical path of the trace.
+func (aH *APIHandler) getCriticalPath(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	path, err := aH.queryService.GetCriticalPath(r.Context(), traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	spanIDs := make([]string, len(path))
+	for i, spanID := range path {
+		spanIDs[i] = spanID.String()
+	}
+	structuredRes := structuredResponse{
+		Data:  spanIDs,
+		Total: len(spanIDs),
+	}
+	aH.writeJSON(w, r, &structuredRes)
+}
+
+// traceSummaryResponse is the summary of a trace, with its times in microseconds like in the traces.
+type traceSummaryResponse struct {
+	TraceID        ui.TraceID                    `json:"traceID"`
+	SpanCount      int                           `json:"spanCount"`
+	ErrorSpanCount int                           `json:"errorSpanCount"`
+	StartTime      uint64                        `json:"startTime"`
+	Duration       uint64                        `json:"duration"`
+	Services       []serviceSummaryResponse      `json:"services"`
+	CriticalPath   []criticalPathSegmentResponse `json:"criticalPath"`
+}
+
+type serviceSummaryResponse struct {
+	ServiceName string `json:"serviceName"`
+	SpanCount   int    `json:"spanCount"`
+	SelfTime    uint64 `json:"selfTime"`
+}
+
+// criticalPathSegmentResponse is a segment of the critical path, with the time it contributed to the trace latency.
+type criticalPathSegmentResponse struct {
+	SpanID       ui.SpanID `json:"spanID"`
+	StartTime    uint64    `json:"startTime"`
+	Contribution uint64    `json:"contribution"`
+}
+
+// getTraceSummary implements the REST API /traces/{trace-id}/summary.
+// It responds with the statistics and the critical path of the trace, computed without sending its spans.
+func (aH *APIHandler) getTraceSummary(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	summary, err := aH.queryService.GetTraceSummary(r.Context(), traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	response := traceSummaryResponse{
+		TraceID:        ui.TraceID(summary.TraceID.String()),
+		SpanCount:      summary.SpanCount,
+		ErrorSpanCount: summary.ErrorSpanCount,
+		StartTime:      model.TimeAsEpochMicroseconds(summary.StartTime),
+		Duration:       model.DurationAsMicroseconds(summary.Duration),
+		Services:       make([]serviceSummaryResponse, len(summary.Services)),
+		CriticalPath:   make([]criticalPathSegmentResponse, len(summary.CriticalPath)),
+	}
+	for i, service := range summary.Services {
+		response.Services[i] = serviceSummaryResponse{
+			ServiceName: service.ServiceName,
+			SpanCount:   service.SpanCount,
+			SelfTime:    model.DurationAsMicroseconds(service.SelfTime),
+		}
+	}
+	for i, segment := range summary.CriticalPath {
+		response.CriticalPath[i] = criticalPathSegmentResponse{
+			SpanID:       ui.SpanID(segment.SpanID.String()),
+			StartTime:    model.TimeAsEpochMicroseconds(segment.Start),
+			Contribution: model.DurationAsMicroseconds(segment.Duration),
+		}
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:     response,
+		Total:    1,
+		Warnings: querysvc.GetWarnings(r.Context()),
+	})
+}
+
+// traceSkeletonResponse is the skeleton of a trace, with its times in microseconds like in the traces.
+type traceSkeletonResponse struct {
+	TraceID ui.TraceID             `json:"traceID"`
+	Spans   []skeletonSpanResponse `json:"spans"`
+}
+
+type skeletonSpanResponse struct {
+	SpanID ui.SpanID `json:"spanID"`
+	// ParentSpanID is empty for the root spans.
+	ParentSpanID  ui.SpanID `json:"parentSpanID,omitempty"`
+	ServiceName   string    `json:"serviceName"`
+	OperationName string    `json:"operationName"`
+	StartTime     uint64    `json:"startTime"`
+	Duration      uint64    `json:"duration"`
+}
+
+// getTraceSkeleton implements the REST API /traces/{trace-id}/skeleton.
+// It responds with the span tree of the trace, without the tags and logs of the spans,
+// for rendering the trace before loading it in full.
+func (aH *APIHandler) getTraceSkeleton(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+	skeleton, err := aH.queryService.GetTraceSkeleton(r.Context(), traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		aH.handleError(w, err, http.StatusNotFound)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+	response := traceSkeletonResponse{
+		TraceID: ui.TraceID(skeleton.TraceID.String()),
+		Spans:   make([]skeletonSpanResponse, len(skeleton.Spans)),
+	}
+	for i, span := range skeleton.Spans {
+		response.Spans[i] = skeletonSpanResponse{
+			SpanID:        ui.SpanID(span.SpanID.String()),
+			ServiceName:   span.ServiceName,
+			OperationName: span.OperationName,
+			StartTime:     model.TimeAsEpochMicroseconds(span.StartTime),
+			Duration:      model.DurationAsMicroseconds(span.Duration),
+		}
+		if span.ParentSpanID != 0 {
+			response.Spans[i].ParentSpanID = ui.SpanID(span.ParentSpanID.String())
+		}
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:     response,
+		Total:    1,
+		Warnings: querysvc.GetWarnings(r.Context()),
+	})
+}
+
+// parseAnonymize returns true if the request asks for anonymized traces,
+// which is only allowed when anonymization is enabled for the deployment.
+func (aH *APIHandler) parseAnonymize(r *http.Request) (bool, error) {
+	raw := r.FormValue(anonymizeParam)
+	if raw == "" {
+		return false, nil
+	}
+	anonymize, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, newParseError(err, anonymizeParam)
+	}
+	if anonymize && !aH.queryService.AnonymizationEnabled() {
+		return false, querysvc.ErrAnonymizationDisabled
+	}
+	return anonymize, nil
+}
+
+// parseTrim returns true if the zero-duration spans without logs are to be trimmed from the traces,
+// as requested or else as configured for the deployment.
+func (aH *APIHandler) parseTrim(r *http.Request) (bool, error) {
+	raw := r.FormValue(trimParam)
+	if raw == "" {
+		return aH.queryService.TrimZeroDurationSpansByDefault(), nil
+	}
+	trim, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, newParseError(err, trimParam)
+	}
+	return trim, nil
+}
+
+// trimTraces trims the zero-duration spans without logs from the traces in place, if trim is true.
+func (aH *APIHandler) trimTraces(traces []*model.Trace, trim bool) {
+	if !trim {
+		return
+	}
+	for i, trace := range traces {
+		if trace != nil {
+			traces[i] = aH.queryService.TrimTrace(trace)
+		}
+	}
+}
+
 func shouldAdjust(r *http.Request) bool {
 	raw := r.FormValue("raw")
 	isRaw, _ := strconv.ParseBool(raw)
@@ -493,6 +1318,100 @@ func (aH *APIHandler) archiveTrace(w http.ResponseWriter, r *http.Request) {
 	aH.writeJSON(w, r, &structuredRes)
 }
 
+// archivedTrace is the outcome of archiving one of the traces of POST:/archive.
+type archivedTrace struct {
+	TraceID  ui.TraceID `json:"traceID"`
+	Archived bool       `json:"archived"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// archiveTraces implements the REST API POST:/archive, archiving either the traces of the traceID
+// parameters or up to limit traces found with the parameters of the search API. It responds with
+// the outcome of each trace, the ones which could not be archived being reported as errors too.
+// When the request times out, the traces archived so far are reported along with the timeout.
+func (aH *APIHandler) archiveTraces(w http.ResponseWriter, r *http.Request) {
+	tQuery, err := aH.queryParser.parseTraceQueryParams(r)
+	if aH.handleError(w, err, http.StatusBadRequest) {
+		return
+	}
+	params := querysvc.ArchiveTracesParameters{
+		TraceIDs:  tQuery.traceIDs,
+		MaxTraces: tQuery.NumTraces,
+	}
+	if len(tQuery.traceIDs) == 0 {
+		params.Query = &tQuery.TraceQueryParameters
+	}
+
+	results, err := aH.queryService.ArchiveTraces(r.Context(), params, nil)
+	var uiErrors []structuredError
+	if err != nil {
+		if results == nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, querysvc.ErrInvalidArchiveTraces) || errors.Is(err, querysvc.ErrSearchRejected) {
+				status = http.StatusBadRequest
+			}
+			aH.handleError(w, err, status)
+			return
+		}
+		aH.logger.Warn("Archiving of the traces stopped", zap.Error(err))
+		uiErrors = append(uiErrors, structuredError{
+			Code: http.StatusServiceUnavailable,
+			Msg:  fmt.Sprintf("archiving of the traces stopped: %v", err),
+		})
+	}
+	archived := make([]archivedTrace, len(results))
+	for i, result := range results {
+		archived[i] = archivedTrace{TraceID: ui.TraceID(result.TraceID.String()), Archived: result.Err == nil}
+		if result.Err != nil {
+			archived[i].Error = result.Err.Error()
+			uiErrors = append(uiErrors, structuredError{
+				Code:    archiveErrorStatus(result.Err),
+				Msg:     result.Err.Error(),
+				TraceID: archived[i].TraceID,
+			})
+		}
+	}
+	aH.writeJSON(w, r, &structuredResponse{
+		Data:   archived,
+		Total:  len(archived),
+		Errors: uiErrors,
+	})
+}
+
+func archiveErrorStatus(err error) int {
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// deleteTrace implements the REST API DELETE /admin/traces/{trace-id}, used to honor erasure requests.
+// Deleting a trace which is not stored succeeds, so that the requests can be retried.
+func (aH *APIHandler) deleteTrace(w http.ResponseWriter, r *http.Request) {
+	traceID, ok := aH.parseTraceID(w, r)
+	if !ok {
+		return
+	}
+
+	err := aH.queryService.DeleteTrace(r.Context(), traceID)
+	if errors.Is(err, querysvc.ErrTraceDeletionUnsupported) {
+		aH.handleError(w, err, http.StatusNotImplemented)
+		return
+	}
+	if aH.handleError(w, err, http.StatusInternalServerError) {
+		return
+	}
+
+	aH.logger.Named("audit").Info("Trace deleted",
+		zap.Stringer("trace_id", traceID),
+		zap.String("subject", querysvc.GetSubject(r.Context())),
+		zap.String("tenant", tenancy.GetTenant(r.Context())),
+		zap.String("remote_addr", r.RemoteAddr),
+		zap.Time("time", time.Now()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (aH *APIHandler) handleError(w http.ResponseWriter, err error, statusCode int) bool {
 	if err == nil {
 		return false
@@ -500,6 +1419,10 @@ func (aH *APIHandler) handleError(w http.ResponseWriter, err error, statusCode i
 	if errors.Is(err, disabled.ErrDisabled) {
 		statusCode = http.StatusNotImplemented
 	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		statusCode = http.StatusRequestEntityTooLarge
+	}
 	if statusCode == http.StatusInternalServerError {
 		aH.logger.Error("HTTP handler, Internal Server Error", zap.Error(err))
 	}
@@ -528,6 +1451,12 @@ func (aH *APIHandler) writeJSON(w http.ResponseWriter, r *http.Request, response
 		marshal = newStructJSONMarshaler(prettyPrint)
 	}
 
+	querysvc.StartSelfTracePhase(r.Context(), "serialize")
+	defer querysvc.EndSelfTracePhase(r.Context())
+	if res, ok := response.(*structuredResponse); ok {
+		res.Warnings = querysvc.GetWarnings(r.Context())
+		res.Cost = queryCostToResponse(r.Context())
+	}
 	w.Header().Set("Content-Type", "application/json")
 	if err := marshal(w, response); err != nil {
 		aH.handleError(w, fmt.Errorf("failed writing HTTP response: %w", err), http.StatusInternalServerError)
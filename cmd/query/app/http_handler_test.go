@@ -23,9 +23,11 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,6 +49,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/plugin/metrics/disabled"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
 	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
 	metricsmocks "github.com/jaegertracing/jaeger/storage/metricsstore/mocks"
@@ -112,7 +115,7 @@ func initializeTestServerWithHandler(queryOptions querysvc.QueryServiceOptions,
 				// add options for test coverage
 				HandlerOptions.Prefix(defaultAPIPrefix),
 				HandlerOptions.BasePath("/"),
-				HandlerOptions.QueryLookbackDuration(defaultTraceQueryLookbackDuration),
+				HandlerOptions.QueryLookbackDuration(querysvc.DefaultSearchLookback),
 			},
 			options...,
 		)...,
@@ -163,6 +166,19 @@ func TestGetTraceSuccess(t *testing.T) {
 	assert.Empty(t, response.Errors)
 }
 
+func TestGetTraceTraceparent(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	traceID := model.NewTraceID(0x4bf92f3577b34da6, 0xa3ce929d0e0e4736)
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), traceID).
+		Return(mockTrace, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+}
+
 type logData struct {
 	e zapcore.Entry
 	f []zapcore.Field
@@ -342,6 +358,60 @@ func TestGetTrace(t *testing.T) {
 	}
 }
 
+func TestGetTraceSelfTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+	)
+	defer tracerProvider.Shutdown(context.Background())
+
+	ts := initializeTestServerWithHandler(
+		querysvc.QueryServiceOptions{SelfTracing: true},
+		HandlerOptions.Tracer(&jtracer.JTracer{OTEL: tracerProvider}),
+		HandlerOptions.SelfTracing(true),
+	)
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 0x123456)).Return(mockTrace, nil).Once()
+
+	resp, err := httpClient.Get(ts.server.URL + `/api/traces/123456`)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	selfTraceID := resp.Header.Get(SelfTraceIDHeader)
+	require.NotEmpty(t, selfTraceID)
+
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		assert.Equal(t, selfTraceID, span.SpanContext.TraceID().String())
+		names = append(names, span.Name)
+	}
+	assert.Equal(t, []string{"validate parameters", "storage GetTrace", "serialize", "/api/traces/{traceID}"}, names)
+}
+
+func TestGetTraceSelfTraceDisabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []HandlerOption
+	}{
+		{name: "without tracer", options: []HandlerOption{HandlerOptions.SelfTracing(true)}},
+		{name: "without self tracing", options: []HandlerOption{HandlerOptions.Tracer(&jtracer.JTracer{OTEL: sdktrace.NewTracerProvider()})}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{SelfTracing: true}, test.options...)
+			defer ts.server.Close()
+			ts.spanReader.On("GetTrace", mock.Anything, model.NewTraceID(0, 0x123456)).Return(mockTrace, nil).Once()
+
+			resp, err := httpClient.Get(ts.server.URL + `/api/traces/123456`)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Empty(t, resp.Header.Get(SelfTraceIDHeader))
+		})
+	}
+}
+
 func TestGetTraceDBFailure(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -364,6 +434,206 @@ func TestGetTraceNotFound(t *testing.T) {
 	require.EqualError(t, err, parsedError(404, "trace not found"))
 }
 
+func TestGetCriticalPath(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	start := time.Now()
+	trace := &model.Trace{Spans: []*model.Span{
+		{TraceID: mockTraceID, SpanID: model.NewSpanID(1), StartTime: start, Duration: 10 * time.Millisecond, Process: &model.Process{}},
+		{
+			TraceID: mockTraceID, SpanID: model.NewSpanID(2), StartTime: start.Add(time.Millisecond), Duration: 5 * time.Millisecond, Process: &model.Process{},
+			References: []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(1))},
+		},
+	}}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(trace, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/`+mockTraceID.String()+`/critical-path`, &response)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"0000000000000001", "0000000000000002", "0000000000000001"}, response.Data)
+	assert.Equal(t, 3, response.Total)
+}
+
+func TestGetCriticalPathNotFound(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456/critical-path`, &response)
+	require.EqualError(t, err, parsedError(404, "trace not found"))
+}
+
+func TestGetCriticalPathDBFailure(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456/critical-path`, &response)
+	require.Error(t, err)
+}
+
+func TestGetTraceSummary(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{MaxTraceSpans: 3})
+	defer ts.server.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	span := func(id, parent uint64, service string, startMillis, endMillis int) *model.Span {
+		s := &model.Span{
+			TraceID:   mockTraceID,
+			SpanID:    model.NewSpanID(id),
+			StartTime: start.Add(time.Duration(startMillis) * time.Millisecond),
+			Duration:  time.Duration(endMillis-startMillis) * time.Millisecond,
+			Process:   model.NewProcess(service, nil),
+		}
+		if parent != 0 {
+			s.References = []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(parent))}
+		}
+		return s
+	}
+	trace := &model.Trace{Spans: []*model.Span{
+		span(1, 0, "frontend", 0, 10),
+		span(2, 1, "backend", 1, 6),
+		span(3, 2, "backend", 2, 4),
+		span(4, 1, "backend", 6, 8),
+	}}
+	trace.Spans[2].Tags = []model.KeyValue{model.Bool("error", true)}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(trace, nil).Once()
+
+	var response struct {
+		Data     traceSummaryResponse `json:"data"`
+		Warnings []string             `json:"warnings"`
+	}
+	err := getJSON(ts.server.URL+`/api/traces/`+mockTraceID.String()+`/summary`, &response)
+	require.NoError(t, err)
+	startMicros := model.TimeAsEpochMicroseconds(start)
+	assert.Equal(t, traceSummaryResponse{
+		TraceID:        ui.TraceID(mockTraceID.String()),
+		SpanCount:      3,
+		ErrorSpanCount: 1,
+		StartTime:      startMicros,
+		Duration:       10000,
+		Services: []serviceSummaryResponse{
+			{ServiceName: "backend", SpanCount: 2, SelfTime: 3000 + 2000},
+			{ServiceName: "frontend", SpanCount: 1, SelfTime: 5000},
+		},
+		CriticalPath: []criticalPathSegmentResponse{
+			{SpanID: "0000000000000001", StartTime: startMicros, Contribution: 1000},
+			{SpanID: "0000000000000002", StartTime: startMicros + 1000, Contribution: 1000},
+			{SpanID: "0000000000000003", StartTime: startMicros + 2000, Contribution: 2000},
+			{SpanID: "0000000000000002", StartTime: startMicros + 4000, Contribution: 2000},
+			{SpanID: "0000000000000001", StartTime: startMicros + 6000, Contribution: 4000},
+		},
+	}, response.Data)
+	// the span 4 is left out of the summary
+	assert.Equal(t, []string{"trace " + mockTraceID.String() + " truncated to the maximum of 3 spans"}, response.Warnings)
+}
+
+func TestGetTraceSummaryErrors(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456/summary`, &response)
+	require.EqualError(t, err, parsedError(404, "trace not found"))
+	err = getJSON(ts.server.URL+`/api/traces/123456/summary`, &response)
+	require.EqualError(t, err, parsedError(500, errStorage.Error()))
+}
+
+func TestGetTraceSkeleton(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trace := &model.Trace{Spans: []*model.Span{
+		{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "GET /",
+			StartTime:     start,
+			Duration:      10 * time.Millisecond,
+			Process:       model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "web-1")}),
+			Tags:          []model.KeyValue{model.String("http.url", "/")},
+		},
+		{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "query",
+			References:    []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(1))},
+			StartTime:     start.Add(2 * time.Millisecond),
+			Duration:      5 * time.Millisecond,
+			Process:       model.NewProcess("backend", nil),
+			Logs:          []model.Log{{Timestamp: start, Fields: []model.KeyValue{model.String("event", "retry")}}},
+		},
+	}}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(trace, nil).Once()
+
+	resp, err := http.Get(ts.server.URL + `/api/traces/` + mockTraceID.String() + `/skeleton`)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	// the details of the spans are not sent
+	for _, field := range []string{"tags", "logs", "process", "references", "http.url", "retry", "web-1"} {
+		assert.NotContains(t, string(body), field)
+	}
+
+	var response struct {
+		Data traceSkeletonResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &response))
+	startMicros := model.TimeAsEpochMicroseconds(start)
+	assert.Equal(t, traceSkeletonResponse{
+		TraceID: ui.TraceID(mockTraceID.String()),
+		Spans: []skeletonSpanResponse{
+			{SpanID: "0000000000000001", ServiceName: "frontend", OperationName: "GET /", StartTime: startMicros, Duration: 10000},
+			{
+				SpanID:        "0000000000000002",
+				ParentSpanID:  "0000000000000001",
+				ServiceName:   "backend",
+				OperationName: "query",
+				StartTime:     startMicros + 2000,
+				Duration:      5000,
+			},
+		},
+	}, response.Data)
+}
+
+func TestGetTraceSkeletonErrors(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, errStorage).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456/skeleton`, &response)
+	require.EqualError(t, err, parsedError(404, "trace not found"))
+	err = getJSON(ts.server.URL+`/api/traces/123456/skeleton`, &response)
+	require.EqualError(t, err, parsedError(500, errStorage.Error()))
+	err = getJSON(ts.server.URL+`/api/traces/not-a-trace-id/skeleton`, &response)
+	require.Error(t, err)
+}
+
+func TestGetCriticalPathBadTraceID(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/chumbawumba/critical-path`, &response)
+	require.Error(t, err)
+}
+
 func TestGetTraceAdjustmentFailure(t *testing.T) {
 	ts := initializeTestServerWithHandler(
 		querysvc.QueryServiceOptions{
@@ -392,6 +662,402 @@ func TestGetTraceBadTraceID(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGetTraceWithFieldProjection(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       mockTraceID,
+				SpanID:        model.NewSpanID(1),
+				OperationName: "root",
+				StartTime:     time.Unix(10, 0),
+				Duration:      time.Second,
+				Tags:          []model.KeyValue{model.String("k", "v")},
+				Process:       model.NewProcess("service", []model.KeyValue{model.String("hostname", "host")}),
+			},
+		},
+	}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(trace, nil).Once()
+
+	var response structuredTraceResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456?fields=startTime,duration`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Traces, 1)
+	require.Len(t, response.Traces[0].Spans, 1)
+	span := response.Traces[0].Spans[0]
+	assert.EqualValues(t, 10_000_000, span.StartTime)
+	assert.EqualValues(t, 1_000_000, span.Duration)
+	assert.Empty(t, span.OperationName)
+	assert.Empty(t, span.Tags)
+	process := response.Traces[0].Processes[span.ProcessID]
+	assert.Equal(t, "service", process.ServiceName)
+	assert.Empty(t, process.Tags)
+}
+
+func TestGetTraceWithInvalidFieldProjection(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456?fields=bogus`, &response)
+	require.EqualError(t, err, parsedError(400, "unable to parse param 'fields': unsupported span field 'bogus'"))
+}
+
+func TestGetTraceSpanWindows(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	traceID := model.NewTraceID(0, 123456)
+	process := model.NewProcess("service", nil)
+	makeTrace := func() *model.Trace {
+		// the spans are returned by the storage in a different order on each read
+		trace := &model.Trace{}
+		for _, i := range rand.Perm(10) {
+			trace.Spans = append(trace.Spans, &model.Span{
+				TraceID:       traceID,
+				SpanID:        model.NewSpanID(uint64(i + 1)),
+				OperationName: fmt.Sprintf("op-%d", i+1),
+				StartTime:     time.Unix(10, int64(i/3)*int64(time.Millisecond)),
+				Process:       process,
+			})
+		}
+		return trace
+	}
+	ts.spanReader.On("GetTrace", mock.Anything, traceID).Return(func(context.Context, model.TraceID) *model.Trace {
+		return makeTrace()
+	}, nil)
+
+	var operations []string
+	for offset := 0; offset < 10; offset += 4 {
+		resp, err := httpClient.Get(fmt.Sprintf("%s/api/traces/%s?spanOffset=%d&spanLimit=4&fields=operationName", ts.server.URL, traceID, offset))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "10", resp.Header.Get(totalSpanCountHeader))
+		var response structuredTraceResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.Len(t, response.Traces, 1)
+		for _, span := range response.Traces[0].Spans {
+			operations = append(operations, span.OperationName)
+		}
+	}
+	assert.Equal(t, []string{"op-1", "op-2", "op-3", "op-4", "op-5", "op-6", "op-7", "op-8", "op-9", "op-10"}, operations)
+}
+
+func TestGetTraceInvalidSpanWindow(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456?spanOffset=-1`, &response)
+	require.EqualError(t, err, parsedError(400, "unable to parse param 'spanOffset': span count cannot be negative"))
+	err = getJSON(ts.server.URL+`/api/traces/123456?spanLimit=bogus`, &response)
+	require.ErrorContains(t, err, "unable to parse param 'spanLimit'")
+}
+
+func TestGetTracesBatch(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	found := model.NewTraceID(0, 1)
+	missing := model.NewTraceID(0, 2)
+	trace := &model.Trace{Spans: []*model.Span{{
+		TraceID:       found,
+		SpanID:        model.NewSpanID(1),
+		OperationName: "login",
+		Process:       model.NewProcess("service", nil),
+	}}}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.cancelCtx"), missing).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.cancelCtx"), found).
+		Return(trace, nil).Once()
+
+	var response structuredTraceResponse
+	err := postJSON(ts.server.URL+"/api/traces/batch", []string{missing.String(), found.String()}, &response)
+	require.NoError(t, err)
+	require.Len(t, response.Traces, 2)
+	assert.Nil(t, response.Traces[0])
+	require.NotNil(t, response.Traces[1])
+	assert.Equal(t, ui.TraceID(found.String()), response.Traces[1].TraceID)
+	assert.Equal(t, []structuredError{{
+		Code:    http.StatusNotFound,
+		Msg:     spanstore.ErrTraceNotFound.Error(),
+		TraceID: ui.TraceID(missing.String()),
+	}}, response.Errors)
+}
+
+func TestGetTracesBatchErrors(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{MaxBatchTraces: 2})
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.cancelCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(nil, errStorage).Once()
+
+	tests := []struct {
+		name          string
+		body          any
+		expectedError string
+	}{
+		{name: "not an array", body: "abc", expectedError: "400 error from server"},
+		{name: "bad trace ID", body: []string{"xyz"}, expectedError: "unable to parse param 'traceID'"},
+		{name: "too many trace IDs", body: []string{"1", "2", "3"}, expectedError: "too many trace IDs"},
+		{name: "storage error", body: []string{"1"}, expectedError: "500 error from server"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var response structuredResponse
+			err := postJSON(ts.server.URL+"/api/traces/batch", test.body, &response)
+			require.ErrorContains(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestRequestBodyTooLarge(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{}, HandlerOptions.MaxRequestBodyBytes(16))
+	defer ts.server.Close()
+
+	for _, route := range []string{"/api/traces/batch", "/api/transform"} {
+		t.Run(route, func(t *testing.T) {
+			body := strings.NewReader(`["` + strings.Repeat("1", 32) + `"]`)
+			resp, err := ts.server.Client().Post(ts.server.URL+route, "application/json", body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		})
+	}
+}
+
+func TestGetTraceRedacted(t *testing.T) {
+	rule, err := querysvc.ParseRedactionRule(`.*\.email:drop`)
+	require.NoError(t, err)
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		Redaction: querysvc.RedactionOptions{Rules: []querysvc.RedactionRule{rule}},
+	})
+	defer ts.server.Close()
+	trace := &model.Trace{Spans: []*model.Span{{
+		TraceID:       mockTraceID,
+		SpanID:        model.NewSpanID(1),
+		OperationName: "login",
+		Process:       model.NewProcess("service", nil),
+		Tags:          []model.KeyValue{model.String("user.email", "alice@example.com"), model.String("region", "eu")},
+	}}}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(trace, nil).Once()
+
+	var response structuredTraceResponse
+	require.NoError(t, getJSON(ts.server.URL+"/api/traces/"+mockTraceID.String(), &response))
+	require.Len(t, response.Traces, 1)
+	assert.Equal(t, []ui.KeyValue{{Key: "region", Type: "string", Value: "eu"}}, response.Traces[0].Spans[0].Tags)
+}
+
+func TestGetTraceDeduplicatesProcesses(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	newProcess := func(service, host string) *model.Process {
+		return model.NewProcess(service, []model.KeyValue{model.String("host.name", host)})
+	}
+	trace := &model.Trace{Spans: []*model.Span{
+		{TraceID: mockTraceID, SpanID: model.NewSpanID(1), Process: newProcess("frontend", "h1")},
+		{TraceID: mockTraceID, SpanID: model.NewSpanID(2), Process: newProcess("backend", "h2")},
+		{TraceID: mockTraceID, SpanID: model.NewSpanID(3), Process: newProcess("frontend", "h1")},
+		{TraceID: mockTraceID, SpanID: model.NewSpanID(4), Process: newProcess("backend", "h3")},
+	}}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(trace, nil).Once()
+
+	var response structuredTraceResponse
+	require.NoError(t, getJSON(ts.server.URL+"/api/traces/"+mockTraceID.String()+"?raw=true", &response))
+	require.Len(t, response.Traces, 1)
+	uiTrace := response.Traces[0]
+	assert.Len(t, uiTrace.Processes, 3)
+	require.Len(t, uiTrace.Spans, len(trace.Spans))
+	for i, span := range uiTrace.Spans {
+		assert.Nil(t, span.Process)
+		process, ok := uiTrace.Processes[span.ProcessID]
+		require.True(t, ok, "process %s of span %s", span.ProcessID, span.SpanID)
+		assert.Equal(t, trace.Spans[i].Process.ServiceName, process.ServiceName)
+		assert.Equal(t, []ui.KeyValue{
+			{Key: "host.name", Type: ui.StringType, Value: trace.Spans[i].Process.Tags[0].VStr},
+		}, process.Tags)
+	}
+	assert.Equal(t, uiTrace.Spans[0].ProcessID, uiTrace.Spans[2].ProcessID)
+}
+
+func TestGetTraceAnonymized(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		Anonymization: querysvc.AnonymizationOptions{
+			Enabled:           true,
+			HashedTags:        []string{"user.id"},
+			StrippedLogFields: []string{"password"},
+		},
+		HashKey: []byte("secret"),
+	})
+	defer ts.server.Close()
+	makeTrace := func() *model.Trace {
+		return &model.Trace{Spans: []*model.Span{{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "login",
+			Process:       model.NewProcess("service", nil),
+			Tags:          []model.KeyValue{model.String("user.id", "alice"), model.String("region", "eu")},
+			Logs: []model.Log{{Fields: []model.KeyValue{
+				model.String("event", "attempt"),
+				model.String("password", "secret"),
+			}}},
+		}}}
+	}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(makeTrace(), nil).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(makeTrace(), nil).Once()
+
+	getUserID := func(t *testing.T) any {
+		var response structuredTraceResponse
+		err := getJSON(ts.server.URL+`/api/traces/123456?anonymize=true`, &response)
+		require.NoError(t, err)
+		assert.Empty(t, response.Errors)
+		require.Len(t, response.Traces, 1)
+		span := response.Traces[0].Spans[0]
+		assert.Equal(t, "login", span.OperationName)
+		require.Len(t, span.Tags, 2)
+		assert.Equal(t, "region", span.Tags[1].Key)
+		assert.Equal(t, "eu", span.Tags[1].Value)
+		require.Len(t, span.Logs, 1)
+		require.Len(t, span.Logs[0].Fields, 1)
+		assert.Equal(t, "event", span.Logs[0].Fields[0].Key)
+		assert.Equal(t, "user.id", span.Tags[0].Key)
+		return span.Tags[0].Value
+	}
+	userID := getUserID(t)
+	assert.NotEqual(t, "alice", userID)
+	assert.Equal(t, userID, getUserID(t), "hashes must be consistent")
+}
+
+func TestGetTraceAnonymizedErrors(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456?anonymize=true`, &response)
+	require.EqualError(t, err, parsedError(400, "trace anonymization is not enabled"))
+
+	err = getJSON(ts.server.URL+`/api/traces?service=service&anonymize=bogus`, &response)
+	require.ErrorContains(t, err, "unable to parse param 'anonymize'")
+}
+
+func TestGetTraceTrimZeroDurationSpans(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{TrimZeroDurationSpans: true})
+	defer ts.server.Close()
+	makeTrace := func() *model.Trace {
+		span := func(id uint64, duration time.Duration, parent uint64) *model.Span {
+			s := &model.Span{
+				TraceID:       mockTraceID,
+				SpanID:        model.NewSpanID(id),
+				OperationName: "op",
+				Process:       model.NewProcess("service", nil),
+				StartTime:     time.Unix(1700000000, 0),
+				Duration:      duration,
+			}
+			if parent != 0 {
+				s.References = []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(parent))}
+			}
+			return s
+		}
+		// the placeholder spans 2 and 3 stand between the root and the leaf spans
+		return &model.Trace{Spans: []*model.Span{
+			span(1, time.Second, 0),
+			span(2, 0, 1),
+			span(3, 0, 2),
+			span(4, time.Millisecond, 3),
+			span(5, time.Millisecond, 2),
+		}}
+	}
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(makeTrace(), nil).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(makeTrace(), nil).Once()
+
+	getParents := func(t *testing.T, query string) map[string]string {
+		var response structuredTraceResponse
+		require.NoError(t, getJSON(ts.server.URL+`/api/traces/123456`+query, &response))
+		assert.Empty(t, response.Errors)
+		require.Len(t, response.Traces, 1)
+		parents := make(map[string]string)
+		for _, span := range response.Traces[0].Spans {
+			parents[string(span.SpanID)] = ""
+			if len(span.References) > 0 {
+				parents[string(span.SpanID)] = string(span.References[0].SpanID)
+			}
+		}
+		return parents
+	}
+	id := func(id uint64) string {
+		return model.NewSpanID(id).String()
+	}
+	// trimmed by default, the leaf spans being attached to the root span
+	assert.Equal(t, map[string]string{id(1): "", id(4): id(1), id(5): id(1)}, getParents(t, ""))
+	assert.Equal(t, map[string]string{
+		id(1): "", id(2): id(1), id(3): id(2), id(4): id(3), id(5): id(2),
+	}, getParents(t, "?trimZeroDurationSpans=false"))
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces/123456?trimZeroDurationSpans=bogus`, &response)
+	require.ErrorContains(t, err, "unable to parse param 'trimZeroDurationSpans'")
+}
+
+func TestSearchByTraceIDPrefix(t *testing.T) {
+	store := memory.NewStore()
+	traceIDs := []model.TraceID{
+		model.NewTraceID(0xabcd1234aaaaaaaa, 1),
+		model.NewTraceID(0xabcd1234bbbbbbbb, 1),
+		model.NewTraceID(0xabcd5678aaaaaaaa, 1),
+	}
+	for _, traceID := range traceIDs {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "op",
+			Process:       model.NewProcess("service", nil),
+		}))
+	}
+	qs := querysvc.NewQueryService(store, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	r := NewRouter()
+	NewAPIHandler(qs, &tenancy.Manager{}, HandlerOptions.Logger(zap.NewNop())).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	search := func(t *testing.T, prefix string) []ui.TraceID {
+		var response structuredTraceResponse
+		require.NoError(t, getJSON(server.URL+`/api/traces?traceIDPrefix=`+prefix, &response))
+		assert.Empty(t, response.Errors)
+		assert.Equal(t, querysvc.TraceIDPrefixLimit, response.Limit)
+		var found []ui.TraceID
+		for _, trace := range response.Traces {
+			found = append(found, trace.TraceID)
+		}
+		return found
+	}
+	assert.Equal(t, []ui.TraceID{ui.TraceID(traceIDs[2].String())}, search(t, "ABCD5"))
+	// an ambiguous prefix returns all the candidates
+	assert.Equal(t, []ui.TraceID{
+		ui.TraceID(traceIDs[0].String()), ui.TraceID(traceIDs[1].String()),
+	}, search(t, "abcd1234"))
+	assert.Empty(t, search(t, "ffff"))
+
+	var response structuredResponse
+	err := getJSON(server.URL+`/api/traces?traceIDPrefix=xyz`, &response)
+	require.ErrorContains(t, err, "unable to parse param 'traceIDPrefix'")
+}
+
+func TestSearchByTraceIDPrefixUnsupported(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?traceIDPrefix=abcd`, &response)
+	require.EqualError(t, err, parsedError(http.StatusNotImplemented, querysvc.ErrTraceIDPrefixUnsupported.Error()))
+}
+
 func TestSearchSuccess(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -404,6 +1070,459 @@ func TestSearchSuccess(t *testing.T) {
 	assert.Empty(t, response.Errors)
 }
 
+func TestSearchWithStorageWarnings(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	warning := "1 of 5 Elasticsearch shards failed, the results may be incomplete"
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Run(func(args mock.Arguments) {
+			// storage readers report warnings through the spanstore package
+			ctx := args.Get(0).(context.Context)
+			spanstore.AddWarning(ctx, warning)
+			spanstore.AddWarning(ctx, warning)
+		}).
+		Return([]*model.Trace{mockTrace}, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&start=0&end=0&operation=operation&limit=20`, &response)
+	require.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, []string{warning}, response.Warnings)
+}
+
+func TestSearchQueryCost(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		// the post-filtered searches look for more candidate traces
+		return q.NumTraces == 200
+	})).Return([]*model.Trace{mockTrace}, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&limit=20&minSpanCount=2`, &response)
+	require.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, &queryCostResponse{SpansScanned: 2, TracesExamined: 1, PostFilters: 1}, response.Cost)
+
+	// the cost is omitted by the APIs not searching traces
+	ts.spanReader.On("GetServices", mock.AnythingOfType("*context.valueCtx")).Return([]string{"service"}, nil).Once()
+	response = structuredResponse{}
+	require.NoError(t, getJSON(ts.server.URL+`/api/services`, &response))
+	assert.Nil(t, response.Cost)
+}
+
+func TestSearchWidening(t *testing.T) {
+	ts := initializeTestServerWithOptions(&tenancy.Manager{}, querysvc.QueryServiceOptions{
+		SearchWidening: querysvc.SearchWidening{Steps: 2, Factor: 4},
+	})
+	defer ts.server.Close()
+	end := time.UnixMicro(1700000000000000)
+	ts.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.StartTimeMax.Equal(end)
+	})).Return(nil, nil).Once()
+	// the widened search finds the traces in the part of the window not searched yet
+	ts.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.StartTimeMax.Equal(end.Add(-time.Hour)) && q.StartTimeMin.Equal(end.Add(-4*time.Hour))
+	})).Return([]*model.Trace{mockTrace}, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&start=1699996400000000&end=1700000000000000&widen=true`, &response)
+	require.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, &searchWindowResponse{Start: 1699985600000000, End: 1700000000000000, Widened: 1}, response.SearchWindow)
+	ts.spanReader.AssertExpectations(t)
+
+	// the window is omitted by the searches not widened
+	ts.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	response = structuredResponse{}
+	require.NoError(t, getJSON(ts.server.URL+`/api/traces?service=service&start=1699996400000000&end=1700000000000000`, &response))
+	assert.Empty(t, response.Data)
+	assert.Nil(t, response.SearchWindow)
+
+	err = getJSON(ts.server.URL+`/api/traces?service=service&widen=maybe`, &response)
+	require.ErrorContains(t, err, "unable to parse param 'widen'")
+}
+
+func TestSearchLimits(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		expectedLimit    int
+		expectedWarnings []string
+	}{
+		{name: "default", query: "", expectedLimit: 20},
+		{name: "below max", query: "&limit=30", expectedLimit: 30},
+		{name: "clamped", query: "&limit=1000", expectedLimit: 50, expectedWarnings: []string{"search limit 1000 reduced to the maximum of 50"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := initializeTestServerWithHandler(
+				querysvc.QueryServiceOptions{SearchGuardrails: querysvc.SearchGuardrails{MaxLimit: 50}},
+				HandlerOptions.DefaultSearchLimit(20))
+			defer ts.server.Close()
+			ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+				return q.NumTraces == test.expectedLimit
+			})).Return([]*model.Trace{mockTrace}, nil).Once()
+
+			var response structuredResponse
+			err := getJSON(ts.server.URL+`/api/traces?service=service`+test.query, &response)
+			require.NoError(t, err)
+			assert.Empty(t, response.Errors)
+			assert.Equal(t, test.expectedWarnings, response.Warnings)
+		})
+	}
+}
+
+func TestSearchLookbackGuardrails(t *testing.T) {
+	tests := []struct {
+		name             string
+		mode             string
+		query            string
+		expectedWindow   time.Duration
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{name: "default lookback", query: "&end=1700000000000000", expectedWindow: 2 * time.Hour},
+		{name: "within max", query: "&start=1699989200000000&end=1700000000000000", expectedWindow: 3 * time.Hour},
+		{
+			name:             "clamped",
+			query:            "&start=1699913600000000&end=1700000000000000",
+			expectedWindow:   6 * time.Hour,
+			expectedWarnings: []string{"search time window of 24h0m0s reduced to the maximum lookback of 6h0m0s"},
+		},
+		{
+			name:          "rejected",
+			mode:          querysvc.LookbackModeReject,
+			query:         "&start=1699913600000000&end=1700000000000000",
+			expectedError: "400 error from server: {\"data\":null,\"total\":0,\"limit\":0,\"offset\":0,\"errors\":[{\"code\":400,\"msg\":\"search rejected: the time window of 24h0m0s exceeds the maximum lookback of 6h0m0s\"}]}",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := initializeTestServerWithOptions(&tenancy.Manager{}, querysvc.QueryServiceOptions{
+				SearchGuardrails: querysvc.SearchGuardrails{
+					MaxLookback:     6 * time.Hour,
+					LookbackMode:    test.mode,
+					DefaultLookback: 2 * time.Hour,
+				},
+			})
+			defer ts.server.Close()
+			ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+				return q.StartTimeMax.Sub(q.StartTimeMin) == test.expectedWindow
+			})).Return([]*model.Trace{mockTrace}, nil).Maybe()
+
+			var response structuredResponse
+			err := getJSON(ts.server.URL+`/api/traces?service=service`+test.query, &response)
+			if test.expectedError != "" {
+				require.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, response.Errors)
+			assert.Equal(t, test.expectedWarnings, response.Warnings)
+		})
+	}
+}
+
+func TestDryRunSearch(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		expected         map[string]any
+		expectedWarnings []string
+		expectedError    string
+	}{
+		{
+			name:  "defaults applied",
+			query: "?service=frontend&end=1700000000000000",
+			expected: map[string]any{
+				"service": "frontend",
+				"start":   float64(1700000000000000 - 2*time.Hour.Microseconds()),
+				"end":     float64(1700000000000000),
+				"limit":   float64(20),
+			},
+		},
+		{
+			name:  "limits applied",
+			query: `?service=frontend&operation=checkout&tag=error:true&start=1699913600000000&end=1700000000000000&limit=1000&minDuration=10ms&minSpanCount=5&hasError=true&callerService=frontend&calleeService=driver`,
+			expected: map[string]any{
+				"service":       "frontend",
+				"operation":     "checkout",
+				"tags":          map[string]any{"error": "true"},
+				"start":         float64(1700000000000000 - 6*time.Hour.Microseconds()),
+				"end":           float64(1700000000000000),
+				"minDuration":   "10ms",
+				"limit":         float64(50),
+				"minSpanCount":  float64(5),
+				"hasError":      true,
+				"callerService": "frontend",
+				"calleeService": "driver",
+			},
+			expectedWarnings: []string{
+				"search time window of 24h0m0s reduced to the maximum lookback of 6h0m0s",
+				"search limit 1000 reduced to the maximum of 50",
+			},
+		},
+		{
+			name:  "trace IDs",
+			query: "?traceID=1f00&traceID=2f00&end=1700000000000000",
+			expected: map[string]any{
+				"service": "",
+				"start":   float64(0),
+				"end":     float64(1700000000000000),
+				"limit":   float64(20),
+				"traceID": []any{"0000000000001f00", "0000000000002f00"},
+			},
+		},
+		{
+			name:          "invalid parameters",
+			query:         "?service=frontend&minDuration=10ms&maxDuration=1ms",
+			expectedError: "400 error from server",
+		},
+		{
+			name:          "invalid tags",
+			query:         "?service=frontend&tags=error",
+			expectedError: "malformed 'tags' parameter",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+				SearchGuardrails: querysvc.SearchGuardrails{
+					MaxLimit:        50,
+					MaxLookback:     6 * time.Hour,
+					DefaultLookback: 2 * time.Hour,
+				},
+			}, HandlerOptions.QueryLookbackDuration(0), HandlerOptions.DefaultSearchLimit(20))
+			defer ts.server.Close()
+
+			var response structuredResponse
+			err := getJSON(ts.server.URL+"/api/traces/dry-run"+test.query, &response)
+			if test.expectedError != "" {
+				require.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, response.Data)
+			assert.Equal(t, test.expectedWarnings, response.Warnings)
+			ts.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDryRunSearchRejected(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{
+		SearchGuardrails: querysvc.SearchGuardrails{
+			MaxLookback:  6 * time.Hour,
+			LookbackMode: querysvc.LookbackModeReject,
+		},
+	})
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/traces/dry-run?service=frontend&start=1699913600000000&end=1700000000000000", &response)
+	require.ErrorContains(t, err, "400 error from server")
+	require.ErrorContains(t, err, "exceeds the maximum lookback of 6h0m0s")
+}
+
+func TestSearchAllowedTagKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowedKeys   []string
+		query         string
+		expectedError string
+	}{
+		{name: "no restriction", query: "&tag=db.instance:users"},
+		{name: "allowed key", allowedKeys: []string{"http.status_code", "error"}, query: `&tag=error:true&tags={"http.status_code":"500"}`},
+		{
+			name:          "disallowed key",
+			allowedKeys:   []string{"http.status_code", "error"},
+			query:         "&tag=error:true&tag=db.instance:users",
+			expectedError: "400 error from server: {\"data\":null,\"total\":0,\"limit\":0,\"offset\":0,\"errors\":[{\"code\":400,\"msg\":\"tag key 'db.instance' is not allowed in searches, the allowed tag keys are: error, http.status_code\"}]}",
+		},
+		{
+			name:          "disallowed JSON keys",
+			allowedKeys:   []string{"error"},
+			query:         `&tags={"peer.service":"db","db.instance":"users"}`,
+			expectedError: "tag keys 'db.instance', 'peer.service' are not allowed in searches, the allowed tag keys are: error",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := initializeTestServer(HandlerOptions.AllowedSearchTagKeys(test.allowedKeys))
+			defer ts.server.Close()
+			ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+				Return([]*model.Trace{mockTrace}, nil).Maybe()
+
+			var response structuredResponse
+			err := getJSON(ts.server.URL+`/api/traces?service=service`+test.query, &response)
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				assert.Empty(t, response.Errors)
+				return
+			}
+			require.ErrorContains(t, err, test.expectedError)
+			ts.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSearchBySpanCount(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	smallSpan := *mockTrace.Spans[0]
+	smallSpan.TraceID = model.NewTraceID(0, 1)
+	smallTrace := &model.Trace{Spans: []*model.Span{&smallSpan}}
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		// more candidate traces are searched to make up for the filtered ones
+		return q.NumTraces == 10
+	})).Return([]*model.Trace{smallTrace, mockTrace}, nil).Once()
+
+	var response structuredTraceResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&start=0&end=0&limit=1&minSpanCount=2`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Traces, 1)
+	assert.Len(t, response.Traces[0].Spans, len(mockTrace.Spans))
+}
+
+func TestSearchBySpanCountBadParam(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&minSpanCount=5&maxSpanCount=2`, &response)
+	require.ErrorContains(t, err, "'maxSpanCount' should be greater than 'minSpanCount'")
+}
+
+func TestSearchByError(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	successfulTrace := &model.Trace{Spans: []*model.Span{{
+		TraceID: model.NewTraceID(0, 1),
+		SpanID:  model.NewSpanID(1),
+		Process: &model.Process{ServiceName: "service"},
+		Tags:    []model.KeyValue{model.Bool("error", false)},
+	}}}
+	failedTrace := &model.Trace{Spans: []*model.Span{{
+		TraceID: model.NewTraceID(0, 2),
+		SpanID:  model.NewSpanID(1),
+		Process: &model.Process{ServiceName: "service"},
+		Tags:    []model.KeyValue{model.Bool("error", true)},
+	}}}
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		// more candidate traces are searched to make up for the filtered ones
+		return q.NumTraces == 20
+	})).Return([]*model.Trace{successfulTrace, failedTrace}, nil).Once()
+
+	var response structuredTraceResponse
+	err := getJSON(ts.server.URL+`/api/traces?service=service&start=0&end=0&limit=2&hasError=true`, &response)
+	require.NoError(t, err)
+	assert.Empty(t, response.Errors)
+	require.Len(t, response.Traces, 1)
+	assert.Equal(t, model.NewTraceID(0, 2).String(), string(response.Traces[0].TraceID))
+}
+
+// getEvents sends a search request asking for server-sent events, and returns the events received
+// as pairs of event name and data.
+func getEvents(t *testing.T, url string) [][2]string {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var events [][2]string
+	for _, block := range strings.Split(strings.TrimSuffix(string(body), "\n\n"), "\n\n") {
+		lines := strings.Split(block, "\n")
+		require.Len(t, lines, 2, "an event is made of an event line and a data line: %q", block)
+		require.True(t, strings.HasPrefix(lines[0], "event: "), lines[0])
+		require.True(t, strings.HasPrefix(lines[1], "data: "), lines[1])
+		events = append(events, [2]string{strings.TrimPrefix(lines[0], "event: "), strings.TrimPrefix(lines[1], "data: ")})
+	}
+	return events
+}
+
+func TestSearchWithProgress(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+				p.BucketsScanned++
+				p.TracesFound = 1
+			})
+			spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+				p.BucketsScanned++
+				p.TracesFound = 2
+			})
+			spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+				p.TracesLoaded = 2
+			})
+		}).
+		Return([]*model.Trace{mockTrace, checkoutTrace(model.NewTraceID(0, 1), time.Millisecond)}, nil).Once()
+
+	events := getEvents(t, ts.server.URL+`/api/traces?service=service&start=0&end=0`)
+	require.Len(t, events, 4)
+	expectedProgress := []spanstore.SearchProgress{
+		{BucketsScanned: 1, TracesFound: 1},
+		{BucketsScanned: 2, TracesFound: 2},
+		{BucketsScanned: 2, TracesFound: 2, TracesLoaded: 2},
+	}
+	for i, expected := range expectedProgress {
+		assert.Equal(t, "progress", events[i][0])
+		var progress spanstore.SearchProgress
+		require.NoError(t, json.Unmarshal([]byte(events[i][1]), &progress))
+		assert.Equal(t, expected, progress)
+	}
+	assert.Equal(t, "result", events[3][0])
+	var response structuredTraceResponse
+	require.NoError(t, json.Unmarshal([]byte(events[3][1]), &response))
+	assert.Empty(t, response.Errors)
+	assert.Len(t, response.Traces, 2)
+}
+
+func TestSearchWithProgressFailure(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Run(func(args mock.Arguments) {
+			spanstore.UpdateProgress(args.Get(0).(context.Context), func(p *spanstore.SearchProgress) {
+				p.BucketsScanned++
+			})
+		}).
+		Return(nil, errStorage).Once()
+
+	events := getEvents(t, ts.server.URL+`/api/traces?service=service&start=0&end=0`)
+	require.Len(t, events, 2)
+	assert.Equal(t, "progress", events[0][0])
+	assert.Equal(t, "error", events[1][0])
+	var response structuredResponse
+	require.NoError(t, json.Unmarshal([]byte(events[1][1]), &response))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, errStorage.Error(), response.Errors[0].Msg)
+}
+
+func TestSearchWithProgressBadParam(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.server.URL+`/api/traces?service=service&minSpanCount=5&maxSpanCount=2`, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	// invalid queries are rejected before streaming
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestSearchByTraceIDSuccess(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -503,6 +1622,10 @@ func TestSearchFailures(t *testing.T) {
 			`/api/traces?service=service&start=0&end=0&operation=operation&maxDuration=10ms&limit=200&minDuration=20ms`,
 			parsedError(400, "'maxDuration' should be greater than 'minDuration'"),
 		},
+		{
+			`/api/traces?service=service&fields=duration,bogus`,
+			parsedError(400, "unable to parse param 'fields': unsupported span field 'bogus'"),
+		},
 	}
 	for _, test := range tests {
 		testIndividualSearchFailures(t, test.urlStr, test.errMsg)
@@ -546,6 +1669,133 @@ func TestGetServicesStorageFailure(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGetServicesWithWarnings(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetServices", mock.AnythingOfType("*context.valueCtx")).
+		Run(func(args mock.Arguments) {
+			querysvc.AddWarning(args.Get(0).(context.Context), "upstream jaeger-eu:16685: unavailable")
+		}).
+		Return([]string{"trifle"}, nil).Once()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/services", &response)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"trifle"}, response.Data)
+	assert.Equal(t, []string{"upstream jaeger-eu:16685: unavailable"}, response.Warnings)
+}
+
+func TestGetServicesActiveWithin(t *testing.T) {
+	recentTraces := func(service string) any {
+		return mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+			return query.ServiceName == service && query.NumTraces == 1
+		})
+	}
+	testCases := []struct {
+		name     string
+		options  []HandlerOption
+		query    string
+		expected []any
+	}{
+		{
+			name:     "all services by default",
+			expected: []any{"frontend", "legacy"},
+		},
+		{
+			name:     "window from the request",
+			query:    "?activeWithin=24h",
+			expected: []any{"frontend"},
+		},
+		{
+			name:     "window from the handler",
+			options:  []HandlerOption{HandlerOptions.ActiveServicesWindow(24 * time.Hour)},
+			expected: []any{"frontend"},
+		},
+		{
+			name:     "request listing all services over the handler window",
+			options:  []HandlerOption{HandlerOptions.ActiveServicesWindow(24 * time.Hour)},
+			query:    "?activeWithin=0s",
+			expected: []any{"frontend", "legacy"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := initializeTestServer(tc.options...)
+			defer ts.server.Close()
+			ts.spanReader.On("GetServices", mock.Anything).Return([]string{"frontend", "legacy"}, nil).Once()
+			ts.spanReader.On("FindTraceIDs", mock.Anything, recentTraces("frontend")).
+				Return([]model.TraceID{{Low: 1}}, nil).Maybe()
+			ts.spanReader.On("FindTraceIDs", mock.Anything, recentTraces("legacy")).
+				Return([]model.TraceID{}, nil).Maybe()
+
+			var response structuredResponse
+			err := getJSON(ts.server.URL+"/api/services"+tc.query, &response)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, response.Data)
+		})
+	}
+}
+
+func TestGetServicesBadActiveWithin(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	for _, param := range []string{"yesterday", "-1h"} {
+		var response structuredResponse
+		err := getJSON(ts.server.URL+"/api/services?activeWithin="+param, &response)
+		require.ErrorContains(t, err, "400 error from server")
+		require.ErrorContains(t, err, "activeWithin")
+	}
+}
+
+func TestGetTagKeys(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	traceID := model.NewTraceID(0, 1)
+	ts.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		return query.ServiceName == "shop" && query.StartTimeMax.Sub(query.StartTimeMin) == 10*time.Minute
+	})).Return([]*model.Trace{{Spans: []*model.Span{
+		{
+			TraceID: traceID,
+			SpanID:  1,
+			Tags:    model.KeyValues{model.String("span.kind", "server"), model.String("http.method", "GET")},
+			Process: &model.Process{ServiceName: "shop"},
+		},
+		{
+			TraceID: traceID,
+			SpanID:  2,
+			Tags:    model.KeyValues{model.String("http.url", "/cart")},
+			Process: &model.Process{ServiceName: "shop"},
+		},
+	}}}, nil).Twice()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/services/shop/tag-keys?window=10m", &response)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"keys":        []any{"http.method", "http.url", "span.kind"},
+		"approximate": true,
+	}, response.Data)
+	assert.Equal(t, 3, response.Total)
+	assert.False(t, response.Truncated)
+
+	response = structuredResponse{}
+	err = getJSON(ts.server.URL+"/api/services/shop/tag-keys?window=10m&limit=2", &response)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"http.method", "http.url"}, response.Data.(map[string]any)["keys"])
+	assert.Equal(t, 2, response.Limit)
+	assert.True(t, response.Truncated)
+}
+
+func TestGetTagKeysBadParameters(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	for _, query := range []string{"window=yesterday", "window=0s", "limit=-1"} {
+		var response structuredResponse
+		err := getJSON(ts.server.URL+"/api/services/shop/tag-keys?"+query, &response)
+		require.ErrorContains(t, err, "400 error from server", query)
+	}
+}
+
 func TestGetOperationsSuccess(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -576,6 +1826,47 @@ func TestGetOperationsSuccess(t *testing.T) {
 	}
 }
 
+func TestGetOperationsTruncated(t *testing.T) {
+	ts := initializeTestServerWithHandler(querysvc.QueryServiceOptions{MaxOperations: 2})
+	defer ts.server.Close()
+	ts.spanReader.On("GetOperations", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("spanstore.OperationQueryParameters")).
+		Return([]spanstore.Operation{{Name: "c"}, {Name: "a"}, {Name: "b"}}, nil)
+
+	tests := []struct {
+		query     string
+		expected  []ui.Operation
+		truncated bool
+	}{
+		{query: "", expected: []ui.Operation{{Name: "a"}, {Name: "b"}}, truncated: true},
+		{query: "&limit=1", expected: []ui.Operation{{Name: "a"}}, truncated: true},
+		{query: "&limit=10", expected: []ui.Operation{{Name: "a"}, {Name: "b"}}, truncated: true},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			var response struct {
+				Operations []ui.Operation `json:"data"`
+				Total      int            `json:"total"`
+				Limit      int            `json:"limit"`
+				Truncated  bool           `json:"truncated"`
+			}
+			require.NoError(t, getJSON(ts.server.URL+"/api/operations?service=svc"+test.query, &response))
+			assert.Equal(t, test.expected, response.Operations)
+			assert.Equal(t, len(test.expected), response.Total)
+			assert.Equal(t, len(test.expected), response.Limit)
+			assert.Equal(t, test.truncated, response.Truncated)
+		})
+	}
+}
+
+func TestGetOperationsBadLimit(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+
+	var response structuredResponse
+	err := getJSON(ts.server.URL+"/api/operations?service=svc&limit=-1", &response)
+	require.ErrorContains(t, err, "unable to parse param 'limit'")
+}
+
 func TestGetOperationsNoServiceName(t *testing.T) {
 	ts := initializeTestServer()
 	defer ts.server.Close()
@@ -0,0 +1,182 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+)
+
+// AdminMaintenancePath is the path of the maintenance mode of the query service, to register
+// the MaintenanceMode of the server on the admin server.
+const AdminMaintenancePath = "/" + defaultAPIPrefix + "/admin/maintenance"
+
+// defaultMaintenanceMessage is returned by the query endpoints when the maintenance mode is enabled without a message.
+const defaultMaintenanceMessage = "the query service is in maintenance, please retry later"
+
+// healthServiceMethodPrefix prefixes the methods of the gRPC health service, which stay available in maintenance.
+var healthServiceMethodPrefix = "/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/"
+
+// maintenanceState is the state of the maintenance mode, as served by the admin endpoint.
+type maintenanceState struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// MaintenanceMode makes the query endpoints return 503 Service Unavailable, or Unavailable over gRPC,
+// with a descriptive message, e.g. during storage migrations, rather than serving partial data.
+// The server is reported as not ready while in maintenance, the admin endpoints staying available.
+//
+// It is an http.Handler serving the state of the maintenance mode with GET, and changing it with PUT,
+// e.g. {"enabled":true,"message":"migrating the storage until 18:00 UTC"}.
+type MaintenanceMode struct {
+	healthCheck  *healthcheck.HealthCheck
+	healthServer *health.Server
+	logger       *zap.Logger
+
+	mu    sync.RWMutex
+	state maintenanceState
+	// storageFailing, if set, tells whether the services must stay not serving when exiting the maintenance
+	storageFailing func() bool
+}
+
+func newMaintenanceMode(healthCheck *healthcheck.HealthCheck, healthServer *health.Server, logger *zap.Logger) *MaintenanceMode {
+	return &MaintenanceMode{
+		healthCheck:  healthCheck,
+		healthServer: healthServer,
+		logger:       logger,
+	}
+}
+
+// Enter enables the maintenance mode, the query endpoints returning the message.
+func (m *MaintenanceMode) Enter(message string) {
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+	since := time.Now()
+	m.mu.Lock()
+	m.state = maintenanceState{Enabled: true, Message: message, Since: &since}
+	m.mu.Unlock()
+	m.logger.Warn("Entering maintenance mode, the queries are rejected", zap.String("message", message))
+	m.healthCheck.Set(healthcheck.Unavailable)
+	m.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+// Exit disables the maintenance mode, the query endpoints serving the queries again.
+func (m *MaintenanceMode) Exit() {
+	m.mu.Lock()
+	m.state = maintenanceState{}
+	storageFailing := m.storageFailing
+	m.mu.Unlock()
+	m.logger.Info("Exiting maintenance mode, the queries are served again")
+	if storageFailing != nil && storageFailing() {
+		m.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	} else {
+		m.setServingStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+	m.healthCheck.Set(healthcheck.Ready)
+}
+
+// setStorageFailing sets the function telling whether the storage is failing.
+func (m *MaintenanceMode) setStorageFailing(storageFailing func() bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.storageFailing = storageFailing
+}
+
+// enabled returns whether the maintenance mode is enabled.
+func (m *MaintenanceMode) enabled() bool {
+	_, ok := m.message()
+	return ok
+}
+
+func (m *MaintenanceMode) setServingStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	for _, service := range healthCheckedServices {
+		m.healthServer.SetServingStatus(service, status)
+	}
+}
+
+// message returns the message of the maintenance mode and true if it is enabled.
+func (m *MaintenanceMode) message() (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.Message, m.state.Enabled
+}
+
+// ServeHTTP implements http.Handler, serving and changing the state of the maintenance mode.
+func (m *MaintenanceMode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var request maintenanceState
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "malformed maintenance mode, expected {\"enabled\":true,\"message\":\"...\"}: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if request.Enabled {
+			m.Enter(request.Message)
+		} else {
+			m.Exit()
+		}
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+		http.Error(w, "only GET and PUT are supported", http.StatusMethodNotAllowed)
+		return
+	}
+	m.mu.RLock()
+	state := m.state
+	m.mu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// maintenanceHandler rejects the requests of the API under apiPrefix with 503 Service Unavailable
+// while in maintenance, the UI staying available to show the errors of the API.
+func maintenanceHandler(h http.Handler, m *MaintenanceMode, apiPrefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if message, ok := m.message(); ok && strings.HasPrefix(r.URL.Path, apiPrefix) {
+			resp, _ := json.Marshal(&structuredResponse{
+				Errors: []structuredError{{Code: http.StatusServiceUnavailable, Msg: message}},
+			})
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, string(resp), http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// newMaintenanceUnaryInterceptor rejects the calls with Unavailable while in maintenance, except the health checks.
+func newMaintenanceUnaryInterceptor(m *MaintenanceMode) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if message, ok := m.message(); ok && !strings.HasPrefix(info.FullMethod, healthServiceMethodPrefix) {
+			return nil, status.Error(codes.Unavailable, message)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// newMaintenanceStreamInterceptor rejects the streams with Unavailable while in maintenance, except the health checks.
+func newMaintenanceStreamInterceptor(m *MaintenanceMode) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if message, ok := m.message(); ok && !strings.HasPrefix(info.FullMethod, healthServiceMethodPrefix) {
+			return status.Error(codes.Unavailable, message)
+		}
+		return handler(srv, ss)
+	}
+}
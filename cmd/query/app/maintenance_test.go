@@ -0,0 +1,156 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+func setMaintenance(t *testing.T, handler http.Handler, body string) maintenanceState {
+	req := httptest.NewRequest(http.MethodPut, AdminMaintenancePath, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var state maintenanceState
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+	return state
+}
+
+func TestMaintenanceModeAdminHandler(t *testing.T) {
+	hc := healthcheck.New()
+	hc.Ready()
+	healthServer := health.NewServer()
+	m := newMaintenanceMode(hc, healthServer, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, AdminMaintenancePath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled":false}`, w.Body.String())
+
+	state := setMaintenance(t, m, `{"enabled":true,"message":"migrating the storage"}`)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, "migrating the storage", state.Message)
+	assert.NotNil(t, state.Since)
+	assert.Equal(t, healthcheck.Unavailable, hc.Get())
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	state = setMaintenance(t, m, `{"enabled":true}`)
+	assert.Equal(t, defaultMaintenanceMessage, state.Message)
+
+	state = setMaintenance(t, m, `{"enabled":false}`)
+	assert.Equal(t, maintenanceState{}, state)
+	assert.Equal(t, healthcheck.Ready, hc.Get())
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodPut, AdminMaintenancePath, strings.NewReader("{")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, AdminMaintenancePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PUT", w.Header().Get("Allow"))
+}
+
+func TestMaintenanceModeExitWithStorageFailing(t *testing.T) {
+	hc := healthcheck.New()
+	healthServer := health.NewServer()
+	m := newMaintenanceMode(hc, healthServer, zap.NewNop())
+	m.setStorageFailing(func() bool { return true })
+
+	m.Enter("")
+	m.Exit()
+	assert.Equal(t, healthcheck.Ready, hc.Get())
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestServerMaintenanceMode(t *testing.T) {
+	hc := healthcheck.New()
+	querySvc := makeQuerySvc()
+	server, err := NewServer(zaptest.NewLogger(t), hc, metrics.NullFactory, querySvc.qs, nil,
+		&QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0"},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+	hc.Ready()
+
+	client := newGRPCClient(t, server.grpcConn.Addr().String())
+	t.Cleanup(func() {
+		require.NoError(t, client.conn.Close())
+	})
+	healthClient := grpc_health_v1.NewHealthClient(client.conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	servicesURL := "http://" + server.httpConn.Addr().String() + "/api/services"
+
+	getServices := func() *http.Response {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, servicesURL, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	setMaintenance(t, server.Maintenance(), `{"enabled":true,"message":"migrating the storage"}`)
+	assert.Equal(t, healthcheck.Unavailable, hc.Get())
+
+	resp := getServices()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+	var body structuredResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []structuredError{{Code: http.StatusServiceUnavailable, Msg: "migrating the storage"}}, body.Errors)
+
+	_, err = client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "migrating the storage")
+
+	// the health checks stay available to report the maintenance
+	res, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "jaeger.api_v2.QueryService"})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, res.Status)
+	assert.True(t, apiServicesHave(ctx, healthClient, grpc_health_v1.HealthCheckResponse_NOT_SERVING))
+
+	setMaintenance(t, server.Maintenance(), `{"enabled":false}`)
+	assert.Equal(t, healthcheck.Ready, hc.Get())
+
+	resp = getServices()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	services, err := client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, querySvc.expectedServices, services.Services)
+
+	res, err = healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "jaeger.api_v2.QueryService"})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.Status)
+	assert.True(t, apiServicesHave(ctx, healthClient, grpc_health_v1.HealthCheckResponse_SERVING))
+}
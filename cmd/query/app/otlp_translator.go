@@ -17,9 +17,9 @@ package app
 import (
 	"fmt"
 
-	model2otel "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
+	"github.com/jaegertracing/jaeger/internal/otlptranslator"
 	"github.com/jaegertracing/jaeger/model"
 )
 
@@ -29,7 +29,7 @@ func otlp2traces(otlpSpans []byte) ([]*model.Trace, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cannot unmarshal OTLP : %w", err)
 	}
-	jaegerBatches, _ := model2otel.ProtoFromTraces(otlpTraces)
+	jaegerBatches, _ := otlptranslator.ProtoFromTraces(otlpTraces)
 	// ProtoFromTraces will not give an error
 
 	var traces []*model.Trace
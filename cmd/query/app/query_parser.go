@@ -20,10 +20,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
 	"github.com/jaegertracing/jaeger/storage/metricsstore"
@@ -33,22 +35,36 @@ import (
 const (
 	defaultQueryLimit = 100
 
-	operationParam   = "operation"
-	tagParam         = "tag"
-	tagsParam        = "tags"
-	startTimeParam   = "start"
-	limitParam       = "limit"
-	minDurationParam = "minDuration"
-	maxDurationParam = "maxDuration"
-	serviceParam     = "service"
-	spanKindParam    = "spanKind"
-	endTimeParam     = "end"
-	prettyPrintParam = "prettyPrint"
+	operationParam     = "operation"
+	tagParam           = "tag"
+	tagsParam          = "tags"
+	startTimeParam     = "start"
+	limitParam         = "limit"
+	minDurationParam   = "minDuration"
+	maxDurationParam   = "maxDuration"
+	minSpanCountParam  = "minSpanCount"
+	maxSpanCountParam  = "maxSpanCount"
+	hasErrorParam      = "hasError"
+	rootSpanOnlyParam  = "rootSpanOnly"
+	callerServiceParam = "callerService"
+	calleeServiceParam = "calleeService"
+	serviceParam       = "service"
+	spanKindParam      = "spanKind"
+	endTimeParam       = "end"
+	prettyPrintParam   = "prettyPrint"
+	withErrorsParam    = "withErrors"
+	widenParam         = "widen"
 )
 
 var (
 	errMaxDurationGreaterThanMin = fmt.Errorf("'%s' should be greater than '%s'", maxDurationParam, minDurationParam)
 
+	errMaxSpanCountGreaterThanMin = fmt.Errorf("'%s' should be greater than '%s'", maxSpanCountParam, minSpanCountParam)
+
+	errRootSpanOnlyWithoutHasError = fmt.Errorf("'%s' requires '%s'", rootSpanOnlyParam, hasErrorParam)
+
+	errServiceEdgeIncomplete = fmt.Errorf("'%s' and '%s' must be set together", callerServiceParam, calleeServiceParam)
+
 	// errServiceParameterRequired occurs when no service name is defined.
 	errServiceParameterRequired = fmt.Errorf("parameter '%s' is required", serviceParam)
 
@@ -67,16 +83,32 @@ type (
 	queryParser struct {
 		traceQueryLookbackDuration time.Duration
 		timeNow                    func() time.Time
+		// defaultSearchLimit is used when the limit is omitted, defaultQueryLimit if not set
+		defaultSearchLimit int
+		// allowedTagKeys restricts the tag keys of the searches, nil means no restriction
+		allowedTagKeys map[string]struct{}
 	}
 
 	traceQueryParameters struct {
 		spanstore.TraceQueryParameters
-		traceIDs []model.TraceID
+		traceIDs    []model.TraceID
+		spanCount   querysvc.SpanCountFilter
+		errorFilter querysvc.ErrorFilter
+		serviceEdge querysvc.ServiceEdgeFilter
+		// widen widens the time window of the search while it finds no traces, see querysvc.FindTracesWidening
+		widen bool
 	}
 
 	dependenciesQueryParameters struct {
-		endTs    time.Time
-		lookback time.Duration
+		endTs      time.Time
+		lookback   time.Duration
+		withErrors bool
+	}
+
+	dependenciesTimeSeriesParameters struct {
+		start    time.Time
+		end      time.Time
+		interval time.Duration
 	}
 
 	durationParser = func(s string) (time.Duration, error)
@@ -112,7 +144,7 @@ func newDurationUnitsParser(units time.Duration) durationParser {
 // Trace query syntax:
 //
 //	query ::= param | param '&' query
-//	param ::= service | operation | limit | start | end | minDuration | maxDuration | tag | tags
+//	param ::= service | operation | limit | start | end | minDuration | maxDuration | minSpanCount | maxSpanCount | hasError | rootSpanOnly | callerService | calleeService | tag | tags
 //	service ::= 'service=' strValue
 //	operation ::= 'operation=' strValue
 //	limit ::= 'limit=' intValue
@@ -120,6 +152,12 @@ func newDurationUnitsParser(units time.Duration) durationParser {
 //	end ::= 'end=' intValue in unix microseconds
 //	minDuration ::= 'minDuration=' strValue (units are "ns", "us" (or "µs"), "ms", "s", "m", "h")
 //	maxDuration ::= 'maxDuration=' strValue (units are "ns", "us" (or "µs"), "ms", "s", "m", "h")
+//	minSpanCount ::= 'minSpanCount=' intValue
+//	maxSpanCount ::= 'maxSpanCount=' intValue
+//	hasError ::= 'hasError=' boolValue
+//	rootSpanOnly ::= 'rootSpanOnly=' boolValue (only the errors of the root spans match hasError)
+//	callerService ::= 'callerService=' strValue (only the traces where it calls calleeService, which is required)
+//	calleeService ::= 'calleeService=' strValue (only the traces where callerService, which is required, calls it)
 //	tag ::= 'tag=' key | 'tag=' keyvalue
 //	key := strValue
 //	keyValue := strValue ':' strValue
@@ -143,7 +181,10 @@ func (p *queryParser) parseTraceQueryParams(r *http.Request) (*traceQueryParamet
 	}
 
 	limitParam := r.FormValue(limitParam)
-	limit := defaultQueryLimit
+	limit := p.defaultSearchLimit
+	if limit == 0 {
+		limit = defaultQueryLimit
+	}
 	if limitParam != "" {
 		limitParsed, err := strconv.ParseInt(limitParam, 10, 32)
 		if err != nil {
@@ -163,9 +204,34 @@ func (p *queryParser) parseTraceQueryParams(r *http.Request) (*traceQueryParamet
 		return nil, err
 	}
 
+	minSpanCount, err := parseSpanCount(r, minSpanCountParam)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSpanCount, err := parseSpanCount(r, maxSpanCountParam)
+	if err != nil {
+		return nil, err
+	}
+
+	hasError, err := parseBool(r, hasErrorParam)
+	if err != nil {
+		return nil, err
+	}
+
+	rootSpanOnly, err := parseBool(r, rootSpanOnlyParam)
+	if err != nil {
+		return nil, err
+	}
+
+	widen, err := parseBool(r, widenParam)
+	if err != nil {
+		return nil, err
+	}
+
 	var traceIDs []model.TraceID
 	for _, id := range r.Form[traceIDParam] {
-		traceID, err := model.TraceIDFromString(id)
+		traceID, err := querysvc.ParseTraceID(id)
 		if err != nil {
 			return nil, fmt.Errorf("cannot parse traceID param: %w", err)
 		}
@@ -183,7 +249,14 @@ func (p *queryParser) parseTraceQueryParams(r *http.Request) (*traceQueryParamet
 			DurationMin:   minDuration,
 			DurationMax:   maxDuration,
 		},
-		traceIDs: traceIDs,
+		traceIDs:    traceIDs,
+		spanCount:   querysvc.SpanCountFilter{Min: minSpanCount, Max: maxSpanCount},
+		errorFilter: querysvc.ErrorFilter{HasError: hasError, RootSpanOnly: rootSpanOnly},
+		serviceEdge: querysvc.ServiceEdgeFilter{
+			CallerService: r.FormValue(callerServiceParam),
+			CalleeService: r.FormValue(calleeServiceParam),
+		},
+		widen: widen,
 	}
 
 	if err := p.validateQuery(traceQuery); err != nil {
@@ -204,9 +277,34 @@ func (p *queryParser) parseDependenciesQueryParams(r *http.Request) (dqp depende
 	}
 
 	dqp.lookback, err = parseDuration(r, lookbackParam, newDurationUnitsParser(time.Millisecond), defaultDependencyLookbackDuration)
+	if err != nil {
+		return dqp, err
+	}
+
+	dqp.withErrors, err = parseBool(r, withErrorsParam)
 	return dqp, err
 }
 
+// parseDependenciesTimeSeriesParams takes a request and constructs a model of dependencies time series parameters.
+//
+// Like the dependencies API, the start and end times are expressed as unix milliseconds, the end defaulting to now
+// and the start to the default lookback of the dependencies before the end. The interval is a duration string like "1h".
+func (p *queryParser) parseDependenciesTimeSeriesParams(r *http.Request) (tsp dependenciesTimeSeriesParameters, err error) {
+	tsp.end, err = p.parseTime(r, endTimeParam, time.Millisecond)
+	if err != nil {
+		return tsp, err
+	}
+	tsp.start = tsp.end.Add(-defaultDependencyLookbackDuration)
+	if r.FormValue(startTimeParam) != "" {
+		tsp.start, err = p.parseTime(r, startTimeParam, time.Millisecond)
+		if err != nil {
+			return tsp, err
+		}
+	}
+	tsp.interval, err = parseDuration(r, intervalParam, newDurationStringParser(), defaultDependencyIntervalDuration)
+	return tsp, err
+}
+
 // parseMetricsQueryParams takes a request and constructs a model of metrics query parameters.
 //
 // Why the API is designed using an end time (endTs) and lookback:
@@ -289,6 +387,10 @@ func (p *queryParser) parseTime(r *http.Request, paramName string, units time.Du
 	formValue := r.FormValue(paramName)
 	if formValue == "" {
 		if paramName == startTimeParam {
+			// without lookback, the default lookback of the query service applies
+			if p.traceQueryLookbackDuration == 0 {
+				return time.Time{}, nil
+			}
 			return p.timeNow().Add(-1 * p.traceQueryLookbackDuration), nil
 		}
 		return p.timeNow(), nil
@@ -314,6 +416,26 @@ func parseDuration(r *http.Request, paramName string, parse durationParser, defa
 	return d, nil
 }
 
+// traceFilter returns the filter applied to the traces found by the search.
+func (q *traceQueryParameters) traceFilter() querysvc.TraceFilter {
+	return querysvc.TraceFilter{SpanCount: q.spanCount, Error: q.errorFilter, ServiceEdge: q.serviceEdge}
+}
+
+func parseSpanCount(r *http.Request, paramName string) (int, error) {
+	formValue := r.FormValue(paramName)
+	if formValue == "" {
+		return 0, nil
+	}
+	count, err := strconv.Atoi(formValue)
+	if err != nil {
+		return 0, newParseError(err, paramName)
+	}
+	if count < 0 {
+		return 0, newParseError(errors.New("span count cannot be negative"), paramName)
+	}
+	return count, nil
+}
+
 func parseBool(r *http.Request, paramName string) (b bool, err error) {
 	formVal := r.FormValue(paramName)
 	if formVal == "" {
@@ -378,10 +500,21 @@ func (*queryParser) validateQuery(traceQuery *traceQueryParameters) error {
 			return errMaxDurationGreaterThanMin
 		}
 	}
+	if traceQuery.spanCount.Min != 0 && traceQuery.spanCount.Max != 0 {
+		if traceQuery.spanCount.Max < traceQuery.spanCount.Min {
+			return errMaxSpanCountGreaterThanMin
+		}
+	}
+	if traceQuery.errorFilter.RootSpanOnly && !traceQuery.errorFilter.HasError {
+		return errRootSpanOnlyWithoutHasError
+	}
+	if (traceQuery.serviceEdge.CallerService == "") != (traceQuery.serviceEdge.CalleeService == "") {
+		return errServiceEdgeIncomplete
+	}
 	return nil
 }
 
-func (*queryParser) parseTags(simpleTags []string, jsonTags []string) (map[string]string, error) {
+func (p *queryParser) parseTags(simpleTags []string, jsonTags []string) (map[string]string, error) {
 	retMe := make(map[string]string)
 	for _, tag := range simpleTags {
 		keyAndValue := strings.Split(tag, ":")
@@ -399,9 +532,40 @@ func (*queryParser) parseTags(simpleTags []string, jsonTags []string) (map[strin
 			retMe[k] = v
 		}
 	}
+	if err := p.checkAllowedTagKeys(retMe); err != nil {
+		return nil, err
+	}
 	return retMe, nil
 }
 
+// checkAllowedTagKeys rejects the tags whose keys are not allowed in searches.
+func (p *queryParser) checkAllowedTagKeys(tags map[string]string) error {
+	if len(p.allowedTagKeys) == 0 {
+		return nil
+	}
+	var disallowed []string
+	for k := range tags {
+		if _, ok := p.allowedTagKeys[k]; !ok {
+			disallowed = append(disallowed, k)
+		}
+	}
+	if len(disallowed) == 0 {
+		return nil
+	}
+	sort.Strings(disallowed)
+	allowed := make([]string, 0, len(p.allowedTagKeys))
+	for k := range p.allowedTagKeys {
+		allowed = append(allowed, k)
+	}
+	sort.Strings(allowed)
+	if len(disallowed) == 1 {
+		return fmt.Errorf("tag key '%s' is not allowed in searches, the allowed tag keys are: %s",
+			disallowed[0], strings.Join(allowed, ", "))
+	}
+	return fmt.Errorf("tag keys '%s' are not allowed in searches, the allowed tag keys are: %s",
+		strings.Join(disallowed, "', '"), strings.Join(allowed, ", "))
+}
+
 func newParseError(err error, paramName string) error {
 	return fmt.Errorf("unable to parse param '%s': %w", paramName, err)
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2/metrics"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -49,6 +50,53 @@ func TestParseTraceQuery(t *testing.T) {
 		{"x?service=service&start=0&end=0&operation=operation&limit=200&minDuration=20s&maxDuration=30", `unable to parse param 'maxDuration': time: missing unit in duration "?30"?$`, nil},
 		{"x?service=service&start=0&end=0&operation=operation&limit=200&tag=k:v&tag=x:y&tag=k&log=k:v&log=k", `malformed 'tag' parameter, expecting key:value, received: k`, nil},
 		{"x?service=service&start=0&end=0&operation=operation&limit=200&minDuration=25s&maxDuration=1s", `'maxDuration' should be greater than 'minDuration'`, nil},
+		{"x?service=service&start=0&end=0&minSpanCount=many", `unable to parse param 'minSpanCount': strconv.Atoi: parsing "many": invalid syntax`, nil},
+		{"x?service=service&start=0&end=0&maxSpanCount=-1", `unable to parse param 'maxSpanCount': span count cannot be negative`, nil},
+		{"x?service=service&start=0&end=0&minSpanCount=10&maxSpanCount=5", `'maxSpanCount' should be greater than 'minSpanCount'`, nil},
+		{"x?service=service&start=0&end=0&hasError=maybe", `unable to parse param 'hasError': strconv.ParseBool: parsing "maybe": invalid syntax`, nil},
+		{"x?service=service&start=0&end=0&rootSpanOnly=maybe", `unable to parse param 'rootSpanOnly': strconv.ParseBool: parsing "maybe": invalid syntax`, nil},
+		{"x?service=service&start=0&end=0&rootSpanOnly=true", `'rootSpanOnly' requires 'hasError'`, nil},
+		{"x?service=service&start=0&end=0&callerService=frontend", `'callerService' and 'calleeService' must be set together`, nil},
+		{"x?service=service&start=0&end=0&calleeService=driver", `'callerService' and 'calleeService' must be set together`, nil},
+		{
+			"x?service=service&start=0&end=0&limit=20&callerService=frontend&calleeService=driver", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    20,
+					Tags:         make(map[string]string),
+				},
+				serviceEdge: querysvc.ServiceEdgeFilter{CallerService: "frontend", CalleeService: "driver"},
+			},
+		},
+		{
+			"x?service=service&start=0&end=0&limit=20&hasError=true&rootSpanOnly=true", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    20,
+					Tags:         make(map[string]string),
+				},
+				errorFilter: querysvc.ErrorFilter{HasError: true, RootSpanOnly: true},
+			},
+		},
+		{
+			"x?service=service&start=0&end=0&limit=20&minSpanCount=10&maxSpanCount=50", noErr,
+			&traceQueryParameters{
+				TraceQueryParameters: spanstore.TraceQueryParameters{
+					ServiceName:  "service",
+					StartTimeMin: time.Unix(0, 0),
+					StartTimeMax: time.Unix(0, 0),
+					NumTraces:    20,
+					Tags:         make(map[string]string),
+				},
+				spanCount: querysvc.SpanCountFilter{Min: 10, Max: 50},
+			},
+		},
 		{
 			"x?service=service&start=0&end=0&operation=operation&limit=200&tag=k:v&tag=x:y", noErr,
 			&traceQueryParameters{
@@ -127,7 +175,6 @@ func TestParseTraceQuery(t *testing.T) {
 			&traceQueryParameters{
 				TraceQueryParameters: spanstore.TraceQueryParameters{
 					NumTraces:    100,
-					StartTimeMin: timeNow,
 					StartTimeMax: timeNow,
 					Tags:         make(map[string]string),
 				},
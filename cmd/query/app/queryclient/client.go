@@ -0,0 +1,33 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package queryclient provides clients of the query API for the programs embedding Jaeger components.
+// The LocalClient calls a querysvc.QueryService in the same process, and the GRPCClient calls a remote
+// query service with the api_v2 gRPC API, so that the callers can switch from one to the other.
+package queryclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// Client is the query API. The traces are returned with the adjusters applied, the tenant of
+// the requests is read from the context with tenancy.GetTenant, and the traces not found are
+// reported with spanstore.ErrTraceNotFound.
+type Client interface {
+	// GetTrace returns the trace with the given ID.
+	GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error)
+	// FindTraces returns the traces matching the query.
+	FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error)
+	// GetServices returns the names of the services.
+	GetServices(ctx context.Context) ([]string, error)
+	// GetOperations returns the operations of a service, optionally filtered by span kind.
+	GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error)
+	// GetDependencies returns the calls between the services during the lookback period until endTs.
+	GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error)
+	// ArchiveTrace copies the trace with the given ID to the archive storage.
+	ArchiveTrace(ctx context.Context, traceID model.TraceID) error
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package queryclient
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app"
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const testTenant = "acme"
+
+var testTraceID = model.NewTraceID(0, 1)
+
+type clientFixture struct {
+	client       Client
+	archiveStore *memory.Store
+}
+
+func TestMain(m *testing.M) {
+	testutils.VerifyGoLeaks(m)
+}
+
+func newQueryService(t *testing.T) (*querysvc.QueryService, *memory.Store) {
+	store := memory.NewStore()
+	archiveStore := memory.NewStore()
+	ctx := tenancy.WithTenant(context.Background(), testTenant)
+	startTime := time.Now().Add(-time.Minute)
+	spans := []*model.Span{
+		{
+			TraceID:       testTraceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "GET /orders",
+			StartTime:     startTime,
+			Duration:      time.Second,
+			Process:       model.NewProcess("frontend", nil),
+			Logs: []model.Log{{
+				Timestamp: startTime,
+				Fields:    []model.KeyValue{model.String("b", "2"), model.String("a", "1")},
+			}},
+		},
+		{
+			TraceID:       testTraceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "SELECT orders",
+			References:    []model.SpanRef{model.NewChildOfRef(testTraceID, model.NewSpanID(1))},
+			StartTime:     startTime.Add(time.Millisecond),
+			Duration:      time.Millisecond,
+			Process:       model.NewProcess("database", nil),
+		},
+	}
+	for _, span := range spans {
+		require.NoError(t, store.WriteSpan(ctx, span))
+	}
+	qs := querysvc.NewQueryService(store, store, querysvc.QueryServiceOptions{
+		ArchiveSpanReader: archiveStore,
+		ArchiveSpanWriter: archiveStore,
+	})
+	return qs, archiveStore
+}
+
+func newLocalFixture(t *testing.T) *clientFixture {
+	qs, archiveStore := newQueryService(t)
+	return &clientFixture{client: NewLocalClient(qs), archiveStore: archiveStore}
+}
+
+func newGRPCFixture(t *testing.T) *clientFixture {
+	qs, archiveStore := newQueryService(t)
+	tm := tenancy.NewManager(&tenancy.Options{Enabled: true})
+	server := grpc.NewServer(
+		grpc.StreamInterceptor(tenancy.NewGuardingStreamInterceptor(tm)),
+		grpc.UnaryInterceptor(tenancy.NewGuardingUnaryInterceptor(tm)),
+	)
+	api_v2.RegisterQueryServiceServer(server, app.NewGRPCHandler(qs, nil, app.GRPCHandlerOptions{}))
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, conn.Close()) })
+	return &clientFixture{
+		client:       NewGRPCClient(conn, GRPCClientOptions{Tenancy: tm}),
+		archiveStore: archiveStore,
+	}
+}
+
+func TestClients(t *testing.T) {
+	fixtures := map[string]func(t *testing.T) *clientFixture{
+		"local": newLocalFixture,
+		"grpc":  newGRPCFixture,
+	}
+	for name, newFixture := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			testClient(t, newFixture(t))
+		})
+	}
+}
+
+func testClient(t *testing.T, f *clientFixture) {
+	ctx := tenancy.WithTenant(context.Background(), testTenant)
+
+	t.Run("GetServices", func(t *testing.T) {
+		services, err := f.client.GetServices(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"frontend", "database"}, services)
+	})
+
+	t.Run("GetOperations", func(t *testing.T) {
+		operations, err := f.client.GetOperations(ctx, spanstore.OperationQueryParameters{ServiceName: "frontend"})
+		require.NoError(t, err)
+		assert.Equal(t, []spanstore.Operation{{Name: "GET /orders", SpanKind: "unspecified"}}, operations)
+	})
+
+	t.Run("GetTrace", func(t *testing.T) {
+		trace, err := f.client.GetTrace(ctx, testTraceID)
+		require.NoError(t, err)
+		require.Len(t, trace.Spans, 2)
+		// the log fields are sorted by the adjusters
+		span := trace.FindSpanByID(model.NewSpanID(1))
+		require.NotNil(t, span)
+		assert.Equal(t, []model.KeyValue{model.String("a", "1"), model.String("b", "2")}, span.Logs[0].Fields)
+	})
+
+	t.Run("GetTrace of another tenant", func(t *testing.T) {
+		_, err := f.client.GetTrace(tenancy.WithTenant(context.Background(), "other"), testTraceID)
+		require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	})
+
+	t.Run("FindTraces", func(t *testing.T) {
+		traces, err := f.client.FindTraces(ctx, &spanstore.TraceQueryParameters{
+			ServiceName:  "frontend",
+			StartTimeMin: time.Now().Add(-time.Hour),
+			StartTimeMax: time.Now(),
+			NumTraces:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, traces, 1)
+		assert.Len(t, traces[0].Spans, 2)
+	})
+
+	t.Run("GetDependencies", func(t *testing.T) {
+		dependencies, err := f.client.GetDependencies(ctx, time.Now(), time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []model.DependencyLink{{Parent: "frontend", Child: "database", CallCount: 1}}, dependencies)
+	})
+
+	t.Run("ArchiveTrace", func(t *testing.T) {
+		require.NoError(t, f.client.ArchiveTrace(ctx, testTraceID))
+		archived, err := f.archiveStore.GetTrace(ctx, testTraceID)
+		require.NoError(t, err)
+		assert.Len(t, archived.Spans, 2)
+
+		err = f.client.ArchiveTrace(ctx, model.NewTraceID(0, 2))
+		require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	})
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package queryclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	_ "github.com/jaegertracing/jaeger/pkg/gogocodec" // force gogo codec registration
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// defaultMaxClockSkewAdjust is the clock skew adjustment of the query service by default.
+const defaultMaxClockSkewAdjust = time.Second
+
+var _ Client = (*GRPCClient)(nil)
+
+// GRPCClientOptions configures a GRPCClient.
+type GRPCClientOptions struct {
+	// Adjuster is applied to the traces received, since the api_v2 gRPC API returns them unadjusted.
+	// The standard adjusters of the query service are used when nil.
+	Adjuster adjuster.Adjuster
+	// Tenancy adds the tenant of the context to the metadata of the requests, under the tenancy header,
+	// when the multi-tenancy is enabled.
+	Tenancy *tenancy.Manager
+}
+
+// GRPCClient is a Client calling a remote query service with the api_v2 gRPC API.
+type GRPCClient struct {
+	client  api_v2.QueryServiceClient
+	options GRPCClientOptions
+}
+
+// NewGRPCClient creates a Client of the query service listening at the other end of the connection.
+func NewGRPCClient(conn *grpc.ClientConn, options GRPCClientOptions) *GRPCClient {
+	if options.Adjuster == nil {
+		options.Adjuster = adjuster.Sequence(querysvc.StandardAdjusters(defaultMaxClockSkewAdjust)...)
+	}
+	return &GRPCClient{
+		client:  api_v2.NewQueryServiceClient(conn),
+		options: options,
+	}
+}
+
+// GetTrace implements Client.GetTrace.
+func (c *GRPCClient) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	stream, err := c.client.GetTrace(c.outgoingContext(ctx), &api_v2.GetTraceRequest{TraceID: traceID})
+	if err != nil {
+		return nil, unwrapNotFoundErr(err)
+	}
+	trace := &model.Trace{}
+	for received, err := stream.Recv(); !errors.Is(err, io.EOF); received, err = stream.Recv() {
+		if err != nil {
+			return nil, unwrapNotFoundErr(err)
+		}
+		for i := range received.Spans {
+			trace.Spans = append(trace.Spans, &received.Spans[i])
+		}
+	}
+	if len(trace.Spans) == 0 {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return c.adjust(ctx, trace), nil
+}
+
+// FindTraces implements Client.FindTraces.
+func (c *GRPCClient) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	stream, err := c.client.FindTraces(c.outgoingContext(ctx), &api_v2.FindTracesRequest{
+		Query: &api_v2.TraceQueryParameters{
+			ServiceName:   query.ServiceName,
+			OperationName: query.OperationName,
+			Tags:          query.Tags,
+			StartTimeMin:  query.StartTimeMin,
+			StartTimeMax:  query.StartTimeMax,
+			DurationMin:   query.DurationMin,
+			DurationMax:   query.DurationMax,
+			SearchDepth:   int32(query.NumTraces),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the spans of each trace are sent consecutively
+	var traces []*model.Trace
+	var trace *model.Trace
+	for received, err := stream.Recv(); !errors.Is(err, io.EOF); received, err = stream.Recv() {
+		if err != nil {
+			return nil, err
+		}
+		for i, span := range received.Spans {
+			if trace == nil || span.TraceID != trace.Spans[0].TraceID {
+				trace = &model.Trace{}
+				traces = append(traces, trace)
+			}
+			trace.Spans = append(trace.Spans, &received.Spans[i])
+		}
+	}
+	for i, trace := range traces {
+		traces[i] = c.adjust(ctx, trace)
+	}
+	return traces, nil
+}
+
+// GetServices implements Client.GetServices.
+func (c *GRPCClient) GetServices(ctx context.Context) ([]string, error) {
+	resp, err := c.client.GetServices(c.outgoingContext(ctx), &api_v2.GetServicesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Services, nil
+}
+
+// GetOperations implements Client.GetOperations.
+func (c *GRPCClient) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	resp, err := c.client.GetOperations(c.outgoingContext(ctx), &api_v2.GetOperationsRequest{
+		Service:  query.ServiceName,
+		SpanKind: query.SpanKind,
+	})
+	if err != nil {
+		return nil, err
+	}
+	operations := make([]spanstore.Operation, len(resp.Operations))
+	for i, operation := range resp.Operations {
+		operations[i] = spanstore.Operation{Name: operation.Name, SpanKind: operation.SpanKind}
+	}
+	return operations, nil
+}
+
+// GetDependencies implements Client.GetDependencies.
+func (c *GRPCClient) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	resp, err := c.client.GetDependencies(c.outgoingContext(ctx), &api_v2.GetDependenciesRequest{
+		StartTime: endTs.Add(-lookback),
+		EndTime:   endTs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Dependencies, nil
+}
+
+// ArchiveTrace implements Client.ArchiveTrace.
+func (c *GRPCClient) ArchiveTrace(ctx context.Context, traceID model.TraceID) error {
+	_, err := c.client.ArchiveTrace(c.outgoingContext(ctx), &api_v2.ArchiveTraceRequest{TraceID: traceID})
+	return unwrapNotFoundErr(err)
+}
+
+// outgoingContext adds the tenant of the context to the metadata of the request.
+func (c *GRPCClient) outgoingContext(ctx context.Context) context.Context {
+	if c.options.Tenancy == nil || !c.options.Tenancy.Enabled {
+		return ctx
+	}
+	if tenant := tenancy.GetTenant(ctx); tenant != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, c.options.Tenancy.Header, tenant)
+	}
+	return ctx
+}
+
+// adjust applies the adjusters, reporting their failures as warnings.
+func (c *GRPCClient) adjust(ctx context.Context, trace *model.Trace) *model.Trace {
+	adjusted, err := c.options.Adjuster.Adjust(trace)
+	if err != nil {
+		querysvc.AddWarning(ctx, err.Error())
+	}
+	return adjusted
+}
+
+func unwrapNotFoundErr(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return spanstore.ErrTraceNotFound
+	}
+	return err
+}
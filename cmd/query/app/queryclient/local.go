@@ -0,0 +1,75 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package queryclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var _ Client = (*LocalClient)(nil)
+
+// LocalClient is a Client calling a query service in the same process, without network round trips.
+type LocalClient struct {
+	queryService *querysvc.QueryService
+}
+
+// NewLocalClient creates a Client of the query service.
+func NewLocalClient(queryService *querysvc.QueryService) *LocalClient {
+	return &LocalClient{queryService: queryService}
+}
+
+// GetTrace implements Client.GetTrace.
+func (c *LocalClient) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	trace, err := c.queryService.GetTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	return c.adjust(ctx, trace), nil
+}
+
+// FindTraces implements Client.FindTraces.
+func (c *LocalClient) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	traces, err := c.queryService.FindTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i, trace := range traces {
+		traces[i] = c.adjust(ctx, trace)
+	}
+	return traces, nil
+}
+
+// GetServices implements Client.GetServices.
+func (c *LocalClient) GetServices(ctx context.Context) ([]string, error) {
+	return c.queryService.GetServices(ctx)
+}
+
+// GetOperations implements Client.GetOperations.
+func (c *LocalClient) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	return c.queryService.GetOperations(ctx, query)
+}
+
+// GetDependencies implements Client.GetDependencies.
+func (c *LocalClient) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	return c.queryService.GetDependencies(ctx, endTs, lookback)
+}
+
+// ArchiveTrace implements Client.ArchiveTrace.
+func (c *LocalClient) ArchiveTrace(ctx context.Context, traceID model.TraceID) error {
+	return c.queryService.ArchiveTrace(ctx, traceID)
+}
+
+// adjust applies the adjusters of the query service, reporting their failures as warnings.
+func (c *LocalClient) adjust(ctx context.Context, trace *model.Trace) *model.Trace {
+	adjusted, err := c.queryService.Adjust(trace)
+	if err != nil {
+		querysvc.AddWarning(ctx, err.Error())
+	}
+	return adjusted
+}
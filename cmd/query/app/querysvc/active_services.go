@@ -0,0 +1,78 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// maxConcurrentServiceProbes bounds the number of services probed at once for recent traces.
+const maxConcurrentServiceProbes = 8
+
+// GetActiveServices returns the services with at least one trace started within the window before now,
+// all the services being returned when the window is not positive. Each service is probed by searching
+// one of its trace IDs, within the FindTraces timeout. The services whose probe fails are kept,
+// with a warning, rather than being wrongly left out.
+func (qs QueryService) GetActiveServices(ctx context.Context, window time.Duration) ([]string, error) {
+	services, err := qs.GetServices(ctx)
+	if err != nil || window <= 0 {
+		return services, err
+	}
+	now := time.Now()
+	active := make([]bool, len(services))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures int
+	)
+	probes := make(chan struct{}, maxConcurrentServiceProbes)
+	for i, service := range services {
+		probes <- struct{}{}
+		wg.Add(1)
+		go func(i int, service string) {
+			defer func() {
+				<-probes
+				wg.Done()
+			}()
+			found, err := qs.probeService(ctx, service, now.Add(-window), now)
+			if err != nil {
+				mu.Lock()
+				failures++
+				mu.Unlock()
+			}
+			active[i] = found || err != nil
+		}(i, service)
+	}
+	wg.Wait()
+
+	if failures > 0 {
+		AddWarning(ctx, fmt.Sprintf("%d services could not be probed for recent traces and are listed anyway", failures))
+	}
+	activeServices := make([]string, 0, len(services))
+	for i, service := range services {
+		if active[i] {
+			activeServices = append(activeServices, service)
+		}
+	}
+	return activeServices, nil
+}
+
+// probeService returns whether the service has a trace started between start and end.
+func (qs QueryService) probeService(ctx context.Context, service string, start, end time.Time) (bool, error) {
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.FindTraces)
+	defer cancel()
+	traceIDs, err := qs.spanReader.FindTraceIDs(ctx, qs.toStorageQuery(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:  service,
+		StartTimeMin: start,
+		StartTimeMax: end,
+		NumTraces:    1,
+	}))
+	qs.errorMetrics.record(err)
+	return len(traceIDs) > 0, err
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func matchService(service string) any {
+	return mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		return query.ServiceName == service
+	})
+}
+
+func TestGetActiveServices(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetServices", mock.Anything).
+		Return([]string{"frontend", "decommissioned", "flaky", "backend"}, nil)
+	var window time.Duration
+	tqs.spanReader.On("FindTraceIDs", mock.Anything, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		window = query.StartTimeMax.Sub(query.StartTimeMin)
+		return query.NumTraces == 1 && query.ServiceName == "frontend"
+	})).Return([]model.TraceID{model.NewTraceID(0, 1)}, nil)
+	tqs.spanReader.On("FindTraceIDs", mock.Anything, matchService("backend")).
+		Return([]model.TraceID{model.NewTraceID(0, 2)}, nil)
+	tqs.spanReader.On("FindTraceIDs", mock.Anything, matchService("decommissioned")).
+		Return(nil, nil)
+	tqs.spanReader.On("FindTraceIDs", mock.Anything, matchService("flaky")).
+		Return(nil, errors.New("storage unavailable"))
+
+	ctx := ContextWithWarnings(context.Background())
+	services, err := tqs.queryService.GetActiveServices(ctx, 2*time.Hour)
+	require.NoError(t, err)
+	// the services that cannot be probed are kept
+	assert.Equal(t, []string{"frontend", "flaky", "backend"}, services)
+	assert.Equal(t, 2*time.Hour, window)
+	assert.Equal(t, []string{"1 services could not be probed for recent traces and are listed anyway"}, GetWarnings(ctx))
+}
+
+func TestGetActiveServicesWithoutWindow(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetServices", mock.Anything).Return([]string{"frontend", "decommissioned"}, nil)
+
+	services, err := tqs.queryService.GetActiveServices(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "decommissioned"}, services)
+	tqs.spanReader.AssertNotCalled(t, "FindTraceIDs", mock.Anything, mock.Anything)
+}
+
+func TestGetActiveServicesError(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetServices", mock.Anything).Return(nil, errors.New("storage error"))
+
+	_, err := tqs.queryService.GetActiveServices(context.Background(), time.Hour)
+	require.EqualError(t, err, "storage error")
+}
+
+func TestGetActiveServicesManyServices(t *testing.T) {
+	tqs := initializeTestService()
+	var services, expected []string
+	for i := 0; i < 3*maxConcurrentServiceProbes; i++ {
+		service := fmt.Sprintf("service-%d", i)
+		services = append(services, service)
+		var traceIDs []model.TraceID
+		if i%3 != 0 {
+			traceIDs = []model.TraceID{model.NewTraceID(0, uint64(i+1))}
+			expected = append(expected, service)
+		}
+		tqs.spanReader.On("FindTraceIDs", mock.Anything, matchService(service)).Return(traceIDs, nil)
+	}
+	tqs.spanReader.On("GetServices", mock.Anything).Return(services, nil)
+
+	active, err := tqs.queryService.GetActiveServices(context.Background(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, expected, active)
+}
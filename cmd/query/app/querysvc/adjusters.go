@@ -34,3 +34,13 @@ func StandardAdjusters(maxClockSkewAdjust time.Duration) []adjuster.Adjuster {
 		adjuster.ParentReference(),
 	}
 }
+
+// ConfiguredAdjusters returns the StandardAdjusters followed, when orphanSpans is not empty,
+// by the adjuster reattaching the spans whose parent is missing from the trace.
+func ConfiguredAdjusters(maxClockSkewAdjust time.Duration, orphanSpans adjuster.OrphanSpansMode) []adjuster.Adjuster {
+	adjusters := StandardAdjusters(maxClockSkewAdjust)
+	if orphanSpans != "" {
+		adjusters = append(adjusters, adjuster.OrphanSpans(orphanSpans))
+	}
+	return adjusters
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+)
+
+func TestConfiguredAdjusters(t *testing.T) {
+	assert.Len(t, ConfiguredAdjusters(time.Second, ""), len(StandardAdjusters(time.Second)))
+
+	adjusters := ConfiguredAdjusters(time.Second, adjuster.OrphanSpansRoot)
+	require.Len(t, adjusters, len(StandardAdjusters(time.Second))+1)
+
+	traceID := model.NewTraceID(0, 1)
+	process := model.NewProcess("svc", nil)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{TraceID: traceID, SpanID: 1, Process: process},
+			{TraceID: traceID, SpanID: 2, Process: process, References: []model.SpanRef{model.NewChildOfRef(traceID, 3)}},
+		},
+	}
+	trace, err := adjuster.Sequence(adjusters...).Adjust(trace)
+	require.NoError(t, err)
+	assert.Equal(t, model.SpanID(1), trace.Spans[1].ParentSpanID())
+}
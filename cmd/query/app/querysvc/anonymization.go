@@ -0,0 +1,133 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// ErrAnonymizationDisabled is returned when anonymized traces are requested but anonymization is not enabled.
+var ErrAnonymizationDisabled = errors.New("trace anonymization is not enabled")
+
+// AnonymizationOptions configures how traces are scrubbed of personal data before they are returned.
+type AnonymizationOptions struct {
+	// Enabled allows clients to request anonymized traces. It requires QueryServiceOptions.HashKey,
+	// anonymization being disabled without it.
+	Enabled bool
+	// HashedTags are the keys of span, process and log tags whose values are replaced with a hash.
+	// When a value is a URL with a query string, only the query string is hashed.
+	HashedTags []string
+	// StrippedLogFields are the keys of the log fields that are removed.
+	StrippedLogFields []string
+}
+
+type anonymizer struct {
+	hasher            *tagHasher
+	hashedTags        map[string]struct{}
+	strippedLogFields map[string]struct{}
+}
+
+func newAnonymizer(options AnonymizationOptions, hasher *tagHasher) *anonymizer {
+	if !options.Enabled || hasher == nil {
+		return nil
+	}
+	return &anonymizer{
+		hasher:            hasher,
+		hashedTags:        toSet(options.HashedTags),
+		strippedLogFields: toSet(options.StrippedLogFields),
+	}
+}
+
+func toSet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return set
+}
+
+// anonymizeTrace scrubs the trace in place. Structural fields such as IDs, references,
+// timings, operation and service names are preserved.
+func (a *anonymizer) anonymizeTrace(trace *model.Trace) {
+	processes := make(map[*model.Process]*model.Process)
+	for _, span := range trace.Spans {
+		span.Tags = a.anonymizeTags(span.Tags)
+		for i := range span.Logs {
+			span.Logs[i].Fields = a.anonymizeTags(a.stripLogFields(span.Logs[i].Fields))
+		}
+		if span.Process == nil {
+			continue
+		}
+		// processes may be shared between spans, so they are replaced rather than modified
+		process, ok := processes[span.Process]
+		if !ok {
+			process = model.NewProcess(span.Process.ServiceName, a.anonymizeTags(span.Process.Tags))
+			processes[span.Process] = process
+		}
+		span.Process = process
+	}
+}
+
+func (a *anonymizer) anonymizeTags(tags []model.KeyValue) []model.KeyValue {
+	if len(tags) == 0 {
+		return tags
+	}
+	result := make([]model.KeyValue, len(tags))
+	for i, tag := range tags {
+		if _, ok := a.hashedTags[tag.Key]; ok {
+			tag = model.String(tag.Key, a.hashTagValue(tag.AsString()))
+		}
+		result[i] = tag
+	}
+	return result
+}
+
+func (a *anonymizer) stripLogFields(fields []model.KeyValue) []model.KeyValue {
+	result := make([]model.KeyValue, 0, len(fields))
+	for _, field := range fields {
+		if _, ok := a.strippedLogFields[field.Key]; !ok {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// hashTagValue returns a one-way hash of the value, which is the same for equal values so that
+// anonymized traces can still be correlated. Only the query string of URLs is hashed.
+func (a *anonymizer) hashTagValue(value string) string {
+	if strings.Contains(value, "?") {
+		if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.RawQuery != "" {
+			u.RawQuery = a.hasher.hash(u.RawQuery)
+			return u.String()
+		}
+	}
+	return a.hasher.hash(value)
+}
+
+// tagHasher hashes the tag values with HMAC-SHA256 keyed with a secret, so that the values,
+// which often come from a small set like user IDs, cannot be recovered by hashing guesses.
+type tagHasher struct {
+	key []byte
+}
+
+// newTagHasher returns nil without a key, the tag values not being hashed then.
+func newTagHasher(key []byte) *tagHasher {
+	if len(key) == 0 {
+		return nil
+	}
+	return &tagHasher{key: key}
+}
+
+func (h *tagHasher) hash(value string) string {
+	mac := hmac.New(sha256.New, h.key)
+	_, _ = mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
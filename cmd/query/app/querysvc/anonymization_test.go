@@ -0,0 +1,152 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func makeAnonymizationTrace() *model.Trace {
+	process := model.NewProcess("frontend", []model.KeyValue{
+		model.String("hostname", "host1"),
+		model.String("user.id", "alice"),
+	})
+	traceID := model.NewTraceID(0, 1)
+	return &model.Trace{Spans: []*model.Span{
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "GET /api",
+			StartTime:     time.Unix(100, 0),
+			Duration:      time.Second,
+			Process:       process,
+			Tags: []model.KeyValue{
+				model.String("user.id", "alice"),
+				model.String("http.url", "https://example.com/api?email=alice@example.com"),
+				model.Int64("http.status_code", 200),
+			},
+			Logs: []model.Log{{
+				Timestamp: time.Unix(100, 0),
+				Fields: []model.KeyValue{
+					model.String("event", "login"),
+					model.String("password", "secret"),
+					model.String("user.id", "alice"),
+				},
+			}},
+		},
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "SELECT",
+			References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+			Process:       process,
+			Tags: []model.KeyValue{
+				model.Int64("user.id", 42),
+				model.String("db.statement", "SELECT 1"),
+			},
+		},
+	}}
+}
+
+func TestAnonymizeTrace(t *testing.T) {
+	a := newAnonymizer(AnonymizationOptions{
+		Enabled:           true,
+		HashedTags:        []string{"user.id", "http.url"},
+		StrippedLogFields: []string{"password"},
+	}, newTagHasher(testHashKey))
+	require.NotNil(t, a)
+	trace := makeAnonymizationTrace()
+	sharedProcess := trace.Spans[0].Process
+	a.anonymizeTrace(trace)
+
+	span := trace.Spans[0]
+	assert.Equal(t, []model.KeyValue{
+		model.String("user.id", hash("alice")),
+		model.String("http.url", "https://example.com/api?"+hash("email=alice@example.com")),
+		model.Int64("http.status_code", 200),
+	}, span.Tags)
+	assert.Equal(t, []model.KeyValue{
+		model.String("event", "login"),
+		model.String("user.id", hash("alice")),
+	}, span.Logs[0].Fields)
+	assert.Equal(t, []model.KeyValue{
+		model.String("hostname", "host1"),
+		model.String("user.id", hash("alice")),
+	}, span.Process.Tags)
+	assert.Equal(t, "frontend", span.Process.ServiceName)
+
+	// structural fields are preserved
+	assert.Equal(t, model.NewSpanID(1), span.SpanID)
+	assert.Equal(t, "GET /api", span.OperationName)
+	assert.Equal(t, time.Second, span.Duration)
+	assert.Equal(t, model.NewSpanID(1), trace.Spans[1].ParentSpanID())
+
+	// shared processes are anonymized once and not modified in place
+	assert.Same(t, trace.Spans[0].Process, trace.Spans[1].Process)
+	assert.Equal(t, "alice", sharedProcess.Tags[1].VStr)
+
+	// non-string values are hashed from their string representation
+	assert.Equal(t, []model.KeyValue{
+		model.String("user.id", hash("42")),
+		model.String("db.statement", "SELECT 1"),
+	}, trace.Spans[1].Tags)
+}
+
+func TestAnonymizeTraceConsistentHashes(t *testing.T) {
+	a := newAnonymizer(AnonymizationOptions{Enabled: true, HashedTags: []string{"user.id"}}, newTagHasher(testHashKey))
+	trace1, trace2 := makeAnonymizationTrace(), makeAnonymizationTrace()
+	a.anonymizeTrace(trace1)
+	a.anonymizeTrace(trace2)
+	assert.Equal(t, trace1.Spans[0].Tags, trace2.Spans[0].Tags)
+	assert.Equal(t, trace1.Spans[0].Tags[0], trace1.Spans[0].Process.Tags[1])
+	assert.NotEqual(t, "alice", trace1.Spans[0].Tags[0].VStr)
+}
+
+func TestAnonymizerDisabled(t *testing.T) {
+	assert.Nil(t, newAnonymizer(AnonymizationOptions{HashedTags: []string{"user.id"}}, newTagHasher(testHashKey)))
+	assert.Nil(t, newAnonymizer(AnonymizationOptions{Enabled: true, HashedTags: []string{"user.id"}}, nil))
+}
+
+func TestHashTagValue(t *testing.T) {
+	a := newAnonymizer(AnonymizationOptions{Enabled: true}, newTagHasher(testHashKey))
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "alice", expected: hash("alice")},
+		{value: "https://example.com/path?a=b", expected: "https://example.com/path?" + hash("a=b")},
+		{value: "https://example.com/path", expected: hash("https://example.com/path")},
+		{value: "/path?a=b", expected: hash("/path?a=b")},
+		{value: "what?", expected: hash("what?")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.expected, a.hashTagValue(tc.value))
+		})
+	}
+}
+
+func TestTagHasher(t *testing.T) {
+	assert.Nil(t, newTagHasher(nil))
+
+	h := newTagHasher(testHashKey)
+	assert.Equal(t, h.hash("alice"), h.hash("alice"))
+	assert.NotEqual(t, h.hash("alice"), h.hash("bob"))
+	assert.Len(t, h.hash("alice"), 64)
+	// the hashes change with the key, e.g. when it is rotated
+	assert.NotEqual(t, h.hash("alice"), newTagHasher([]byte("rotated-secret")).hash("alice"))
+}
+
+var testHashKey = []byte("test-secret")
+
+// hash is the hash of the value with the test key.
+func hash(value string) string {
+	return newTagHasher(testHashKey).hash(value)
+}
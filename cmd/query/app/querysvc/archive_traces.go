@@ -0,0 +1,136 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// MaxArchiveTraces caps the number of traces archived by one ArchiveTraces call.
+	MaxArchiveTraces = 1000
+
+	// maxConcurrentArchives bounds the number of traces archived at once by ArchiveTraces.
+	maxConcurrentArchives = 4
+)
+
+// ErrInvalidArchiveTraces is returned, wrapped, by ArchiveTraces for invalid parameters.
+var ErrInvalidArchiveTraces = errors.New("invalid traces to archive")
+
+// ArchiveTracesParameters selects the traces to archive, either by their IDs or by a search.
+type ArchiveTracesParameters struct {
+	TraceIDs []model.TraceID
+	// Query searches the traces to archive when TraceIDs is empty.
+	Query *spanstore.TraceQueryParameters
+	// MaxTraces caps the number of traces to archive, MaxArchiveTraces if not positive.
+	MaxTraces int
+}
+
+// ArchivedTrace is the outcome of archiving one of the traces of ArchiveTraces.
+type ArchivedTrace struct {
+	TraceID model.TraceID
+	// Err is nil if the trace was archived.
+	Err error
+}
+
+// ArchiveTraces archives the selected traces, like ArchiveTrace, a few at once. The outcome of each trace
+// is passed to report, if not nil, as soon as it is known, and all the outcomes are returned in the order
+// of the trace IDs. Once ctx is done no more traces are archived: the traces not archived yet fail with
+// the error of ctx, which is returned along with the partial outcomes.
+func (qs QueryService) ArchiveTraces(ctx context.Context, params ArchiveTracesParameters, report func(ArchivedTrace)) ([]ArchivedTrace, error) {
+	if qs.options.ArchiveSpanWriter == nil {
+		return nil, errNoArchiveSpanStorage
+	}
+	traceIDs, err := qs.findTraceIDsToArchive(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ArchivedTrace, len(traceIDs))
+	var (
+		wg       sync.WaitGroup
+		reportMu sync.Mutex
+	)
+	done := func(i int, err error) {
+		results[i] = ArchivedTrace{TraceID: traceIDs[i], Err: err}
+		if report != nil {
+			reportMu.Lock()
+			report(results[i])
+			reportMu.Unlock()
+		}
+	}
+	archives := make(chan struct{}, maxConcurrentArchives)
+	for i, traceID := range traceIDs {
+		select {
+		case archives <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			done(i, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(i int, traceID model.TraceID) {
+			defer func() {
+				<-archives
+				wg.Done()
+			}()
+			done(i, qs.ArchiveTrace(ctx, traceID))
+		}(i, traceID)
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+// findTraceIDsToArchive returns the IDs of the traces selected by params, without duplicates.
+func (qs QueryService) findTraceIDsToArchive(ctx context.Context, params ArchiveTracesParameters) ([]model.TraceID, error) {
+	maxTraces := params.MaxTraces
+	if maxTraces <= 0 || maxTraces > MaxArchiveTraces {
+		maxTraces = MaxArchiveTraces
+	}
+	traceIDs := params.TraceIDs
+	switch {
+	case len(traceIDs) > 0 && params.Query != nil:
+		return nil, fmt.Errorf("%w: either trace IDs or a query are expected, not both", ErrInvalidArchiveTraces)
+	case len(traceIDs) > maxTraces:
+		return nil, fmt.Errorf("%w: %d trace IDs exceed the maximum of %d", ErrInvalidArchiveTraces, len(traceIDs), maxTraces)
+	case len(traceIDs) == 0 && params.Query == nil:
+		return nil, fmt.Errorf("%w: either trace IDs or a query are expected", ErrInvalidArchiveTraces)
+	case params.Query != nil:
+		query := *params.Query
+		if query.NumTraces <= 0 || query.NumTraces > maxTraces {
+			query.NumTraces = maxTraces
+		}
+		effective, err := qs.applySearchGuardrails(ctx, &query)
+		if err != nil {
+			return nil, err
+		}
+		findCtx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.FindTraces)
+		defer cancel()
+		traceIDs, err = qs.spanReader.FindTraceIDs(findCtx, qs.toStorageQuery(ctx, effective))
+		qs.errorMetrics.record(err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[model.TraceID]struct{}, len(traceIDs))
+	deduped := make([]model.TraceID, 0, len(traceIDs))
+	for _, traceID := range traceIDs {
+		if _, ok := seen[traceID]; !ok {
+			seen[traceID] = struct{}{}
+			deduped = append(deduped, traceID)
+		}
+	}
+	if len(deduped) > maxTraces {
+		deduped = deduped[:maxTraces]
+	}
+	return deduped, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func archivableTrace(traceID model.TraceID) *model.Trace {
+	return &model.Trace{Spans: []*model.Span{{TraceID: traceID, SpanID: model.NewSpanID(1)}}}
+}
+
+func TestArchiveTracesByIDs(t *testing.T) {
+	tqs := initializeTestService(withArchiveSpanWriter())
+	traceIDs := []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2), model.NewTraceID(0, 3)}
+	tqs.spanReader.On("GetTrace", mock.Anything, traceIDs[0]).Return(archivableTrace(traceIDs[0]), nil)
+	tqs.spanReader.On("GetTrace", mock.Anything, traceIDs[1]).Return(nil, spanstore.ErrTraceNotFound)
+	tqs.spanReader.On("GetTrace", mock.Anything, traceIDs[2]).Return(archivableTrace(traceIDs[2]), nil)
+	tqs.archiveSpanWriter.On("WriteSpan", mock.Anything, mock.Anything).Return(nil).Times(2)
+
+	var (
+		mu       sync.Mutex
+		reported []model.TraceID
+	)
+	results, err := tqs.queryService.ArchiveTraces(context.Background(), ArchiveTracesParameters{
+		// the duplicates are archived once
+		TraceIDs: append(traceIDs, traceIDs[0]),
+	}, func(result ArchivedTrace) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, result.TraceID)
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, ArchivedTrace{TraceID: traceIDs[0]}, results[0])
+	assert.Equal(t, traceIDs[1], results[1].TraceID)
+	require.ErrorIs(t, results[1].Err, spanstore.ErrTraceNotFound)
+	assert.Equal(t, ArchivedTrace{TraceID: traceIDs[2]}, results[2])
+	assert.ElementsMatch(t, traceIDs, reported)
+	tqs.archiveSpanWriter.AssertExpectations(t)
+}
+
+func TestArchiveTracesByQuery(t *testing.T) {
+	tqs := initializeTestService(withArchiveSpanWriter())
+	traceIDs := []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2), model.NewTraceID(0, 3)}
+	tqs.spanReader.On("FindTraceIDs", mock.Anything, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		return query.ServiceName == "frontend" && query.NumTraces == 2 && !query.StartTimeMin.IsZero()
+	})).Return(traceIDs, nil).Once()
+	for _, traceID := range traceIDs[:2] {
+		tqs.spanReader.On("GetTrace", mock.Anything, traceID).Return(archivableTrace(traceID), nil).Once()
+	}
+	tqs.archiveSpanWriter.On("WriteSpan", mock.Anything, mock.Anything).Return(nil).Times(2)
+
+	results, err := tqs.queryService.ArchiveTraces(context.Background(), ArchiveTracesParameters{
+		Query:     &spanstore.TraceQueryParameters{ServiceName: "frontend"},
+		MaxTraces: 2,
+	}, nil)
+	require.NoError(t, err)
+	// the storage returning more traces than asked for, they are truncated to the max count
+	assert.Equal(t, []ArchivedTrace{{TraceID: traceIDs[0]}, {TraceID: traceIDs[1]}}, results)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestArchiveTracesFindError(t *testing.T) {
+	tqs := initializeTestService(withArchiveSpanWriter())
+	tqs.spanReader.On("FindTraceIDs", mock.Anything, mock.Anything).Return(nil, errors.New("storage unavailable")).Once()
+
+	results, err := tqs.queryService.ArchiveTraces(context.Background(), ArchiveTracesParameters{
+		Query: &spanstore.TraceQueryParameters{ServiceName: "frontend"},
+	}, nil)
+	require.EqualError(t, err, "storage unavailable")
+	assert.Nil(t, results)
+}
+
+func TestArchiveTracesInvalid(t *testing.T) {
+	traceIDs := []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}
+	tests := []struct {
+		name   string
+		params ArchiveTracesParameters
+	}{
+		{name: "nothing to archive", params: ArchiveTracesParameters{}},
+		{name: "trace IDs and query", params: ArchiveTracesParameters{TraceIDs: traceIDs, Query: &spanstore.TraceQueryParameters{}}},
+		{name: "too many trace IDs", params: ArchiveTracesParameters{TraceIDs: traceIDs, MaxTraces: 1}},
+	}
+	tqs := initializeTestService(withArchiveSpanWriter())
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := tqs.queryService.ArchiveTraces(context.Background(), test.params, nil)
+			require.ErrorIs(t, err, ErrInvalidArchiveTraces)
+		})
+	}
+
+	tqs = initializeTestService()
+	_, err := tqs.queryService.ArchiveTraces(context.Background(), ArchiveTracesParameters{TraceIDs: traceIDs}, nil)
+	require.ErrorIs(t, err, errNoArchiveSpanStorage)
+}
+
+func TestArchiveTracesContextDone(t *testing.T) {
+	tqs := initializeTestService(withArchiveSpanWriter())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var traceIDs []model.TraceID
+	for i := uint64(1); i <= 3*maxConcurrentArchives; i++ {
+		traceIDs = append(traceIDs, model.NewTraceID(0, i))
+	}
+	// the first trace cancels the request once it is archived, the other ones wait for the request to be done
+	tqs.spanReader.On("GetTrace", mock.Anything, mock.Anything).Return(func(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+		if traceID != traceIDs[0] {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return archivableTrace(traceID), nil
+	})
+	tqs.archiveSpanWriter.On("WriteSpan", mock.Anything, mock.Anything).Run(func(mock.Arguments) { cancel() }).Return(nil).Once()
+
+	results, err := tqs.queryService.ArchiveTraces(ctx, ArchiveTracesParameters{TraceIDs: traceIDs}, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, results, len(traceIDs))
+	assert.Equal(t, ArchivedTrace{TraceID: traceIDs[0]}, results[0])
+	for i, result := range results[1:] {
+		assert.Equal(t, traceIDs[i+1], result.TraceID)
+		require.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/criticalpath"
+)
+
+// CriticalPath returns the IDs of the spans on the critical path of the trace, in chronological order,
+// the chain of work that determines the overall latency of the trace, see criticalpath.Compute.
+// A span may appear several times on the path when its own work alternates with the work of its children.
+func CriticalPath(trace *model.Trace) []model.SpanID {
+	return segmentSpanIDs(criticalpath.Compute(trace))
+}
+
+// criticalPath returns the critical path of the trace, following the references of at most
+// MaxTraversalSpans spans, with a warning when the path is truncated.
+func (qs QueryService) criticalPath(ctx context.Context, trace *model.Trace) []criticalpath.Segment {
+	path, truncated := criticalpath.ComputeWithBudget(trace, qs.options.MaxTraversalSpans)
+	if truncated && len(trace.Spans) > 0 {
+		AddWarning(ctx, fmt.Sprintf("critical path of trace %s truncated after visiting the maximum of %d spans",
+			trace.Spans[0].TraceID, qs.options.MaxTraversalSpans))
+	}
+	return path
+}
+
+func segmentSpanIDs(path []criticalpath.Segment) []model.SpanID {
+	if len(path) == 0 {
+		return nil
+	}
+	spanIDs := make([]model.SpanID, len(path))
+	for i, segment := range path {
+		spanIDs[i] = segment.SpanID
+	}
+	return spanIDs
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var criticalPathTraceID = model.NewTraceID(0, 42)
+
+func makeSpan(id uint64, parent uint64, refType model.SpanRefType, startMillis, endMillis int64) *model.Span {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	span := &model.Span{
+		TraceID:   criticalPathTraceID,
+		SpanID:    model.NewSpanID(id),
+		StartTime: base.Add(time.Duration(startMillis) * time.Millisecond),
+		Duration:  time.Duration(endMillis-startMillis) * time.Millisecond,
+	}
+	if parent != 0 {
+		span.References = []model.SpanRef{{
+			TraceID: criticalPathTraceID,
+			SpanID:  model.NewSpanID(parent),
+			RefType: refType,
+		}}
+	}
+	return span
+}
+
+func spanIDs(ids ...uint64) []model.SpanID {
+	result := make([]model.SpanID, len(ids))
+	for i, id := range ids {
+		result[i] = model.NewSpanID(id)
+	}
+	return result
+}
+
+// bottleneckTrace has a root span 1 whose latency is dominated by span 2,
+// which in turn mostly waits on span 4. Span 5 runs in parallel with span 2.
+//
+//	1 [0-100]
+//	  5 [5-20]
+//	  2 [10-90]
+//	    3 [15-30]
+//	    4 [40-85]
+//	  6 [92-98]
+func bottleneckTrace() *model.Trace {
+	return &model.Trace{Spans: []*model.Span{
+		makeSpan(1, 0, model.ChildOf, 0, 100),
+		makeSpan(5, 1, model.ChildOf, 5, 20),
+		makeSpan(2, 1, model.ChildOf, 10, 90),
+		makeSpan(3, 2, model.ChildOf, 15, 30),
+		makeSpan(4, 2, model.ChildOf, 40, 85),
+		makeSpan(6, 1, model.ChildOf, 92, 98),
+	}}
+}
+
+func TestCriticalPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		trace    *model.Trace
+		expected []model.SpanID
+	}{
+		{
+			name:     "bottleneck",
+			trace:    bottleneckTrace(),
+			expected: spanIDs(1, 2, 3, 2, 4, 2, 1, 6, 1),
+		},
+		{
+			name: "single span",
+			trace: &model.Trace{Spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+			}},
+			expected: spanIDs(1),
+		},
+		{
+			name: "child covering the whole parent",
+			trace: &model.Trace{Spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+				makeSpan(2, 1, model.ChildOf, 0, 10),
+			}},
+			expected: spanIDs(2),
+		},
+		{
+			name: "child overflowing the parent is clipped",
+			trace: &model.Trace{Spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+				makeSpan(2, 1, model.ChildOf, 5, 20),
+				makeSpan(3, 1, model.ChildOf, 20, 30),
+			}},
+			expected: spanIDs(1, 2),
+		},
+		{
+			name: "follows from children do not block the parent",
+			trace: &model.Trace{Spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+				makeSpan(2, 1, model.FollowsFrom, 2, 8),
+			}},
+			expected: spanIDs(1),
+		},
+		{
+			name: "root that finished last is used",
+			trace: &model.Trace{Spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+				makeSpan(2, 7, model.ChildOf, 0, 20),
+			}},
+			expected: spanIDs(2),
+		},
+		{
+			name:  "empty trace",
+			trace: &model.Trace{},
+		},
+		{
+			name: "reference cycle",
+			trace: &model.Trace{Spans: []*model.Span{
+				makeSpan(1, 2, model.ChildOf, 0, 10),
+				makeSpan(2, 1, model.ChildOf, 0, 10),
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, CriticalPath(test.trace))
+		})
+	}
+}
+
+func TestGetCriticalPath(t *testing.T) {
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		// the adjuster reports a problem, but the adjusted trace is still used
+		options.Adjuster = adjuster.Func(func(trace *model.Trace) (*model.Trace, error) {
+			trace.Spans = trace.Spans[:1]
+			return trace, errAdjustment
+		})
+	})
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(bottleneckTrace(), nil).Once()
+
+	path, err := tqs.queryService.GetCriticalPath(context.Background(), criticalPathTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, spanIDs(1), path)
+}
+
+func TestGetCriticalPathNotFound(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	_, err := tqs.queryService.GetCriticalPath(context.Background(), criticalPathTraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
+
+func TestGetCriticalPathTruncated(t *testing.T) {
+	// the root has a huge fan-out of children, one per millisecond
+	const fanOut = 50_000
+	spans := []*model.Span{makeSpan(1, 0, model.ChildOf, 0, 2*fanOut)}
+	for i := int64(0); i < fanOut; i++ {
+		spans = append(spans, makeSpan(uint64(i+2), 1, model.ChildOf, 2*i, 2*i+1))
+	}
+	for _, span := range spans {
+		span.Process = &model.Process{ServiceName: "service"}
+	}
+	const maxSpans = 100
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MaxTraversalSpans = maxSpans
+	})
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(&model.Trace{Spans: spans}, nil).Twice()
+
+	ctx := ContextWithWarnings(context.Background())
+	path, err := tqs.queryService.GetCriticalPath(ctx, criticalPathTraceID)
+	require.NoError(t, err)
+	onPath := make(map[model.SpanID]struct{})
+	for _, spanID := range path {
+		onPath[spanID] = struct{}{}
+	}
+	assert.Len(t, onPath, maxSpans, "the path is computed from the spans within the budget")
+	assert.Equal(t, []string{"critical path of trace 000000000000002a truncated after visiting the maximum of 100 spans"}, GetWarnings(ctx))
+
+	ctx = ContextWithWarnings(context.Background())
+	summary, err := tqs.queryService.GetTraceSummary(ctx, criticalPathTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, fanOut+1, summary.SpanCount, "only the critical path is truncated")
+	assert.NotEmpty(t, summary.CriticalPath)
+	assert.Len(t, GetWarnings(ctx), 1)
+}
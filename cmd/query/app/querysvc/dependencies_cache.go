@@ -0,0 +1,187 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const (
+	// maxDependenciesCacheEntries bounds the number of dependency graphs cached
+	maxDependenciesCacheEntries = 1000
+
+	// dependenciesRefreshAge is the fraction of the TTL after which a cached dependency graph
+	// is refreshed in the background when requested, so that it is rarely requested expired,
+	// before the jitter
+	dependenciesRefreshAge = 0.5
+)
+
+// DependenciesCacheOptions configures the cache of the dependency graphs of GetDependencies.
+type DependenciesCacheOptions struct {
+	// TTL is how long a dependency graph is served from the cache, 0 disables the cache.
+	TTL time.Duration
+	// Granularity is the period the end timestamps of the requests are rounded up to, so that the
+	// requests of the same period, e.g. of the pages of the UI, share their cached dependency graph.
+	Granularity time.Duration
+	// RefreshJitter is the fraction, between 0 and 1, by which the background refresh of each graph
+	// is randomly brought forward, so that the caches of a fleet of query services sharing a storage,
+	// e.g. started together, do not all refresh their graphs at the same time.
+	RefreshJitter float64
+}
+
+type dependenciesCacheMetrics struct {
+	Hits            metrics.Counter `metric:"dependencies_cache.requests" tags:"result=hit"`
+	Misses          metrics.Counter `metric:"dependencies_cache.requests" tags:"result=miss"`
+	RefreshFailures metrics.Counter `metric:"dependencies_cache.refresh_failures"`
+}
+
+type dependenciesCacheKey struct {
+	tenant string
+	// endTs is the rounded up end timestamp in Unix nanoseconds, time.Time not being comparable across locations
+	endTs    int64
+	lookback time.Duration
+}
+
+type dependenciesCacheEntry struct {
+	dependencies []model.DependencyLink
+	fetched      time.Time
+	// refreshAge is the age after which the entry is refreshed, with jitter
+	refreshAge time.Duration
+	refreshing bool
+}
+
+// fetchDependencies reads the dependencies from the dependency storage.
+type fetchDependencies func(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error)
+
+// dependenciesCache caches the dependency graphs per tenant, rounded up end timestamp and lookback.
+// The graphs requested after about half their TTL, see RefreshJitter, are refreshed in the background,
+// so that the graphs requested regularly, e.g. by the UI, stay warm. The errors are not cached.
+type dependenciesCache struct {
+	options DependenciesCacheOptions
+	metrics dependenciesCacheMetrics
+	now     func() time.Time
+	// random returns the random numbers in [0, 1) of the jitter
+	random func() float64
+
+	mu      sync.Mutex
+	entries map[dependenciesCacheKey]*dependenciesCacheEntry
+	// refreshes tracks the background refreshes, so that the tests can wait for them
+	refreshes sync.WaitGroup
+}
+
+func newDependenciesCache(options DependenciesCacheOptions, metricsFactory metrics.Factory) *dependenciesCache {
+	c := &dependenciesCache{
+		options: options,
+		now:     time.Now,
+		random:  rand.Float64,
+		entries: make(map[dependenciesCacheKey]*dependenciesCacheEntry),
+	}
+	metrics.Init(&c.metrics, metricsFactory, nil)
+	return c
+}
+
+// get returns the cached dependency graph of the request, fetching it on a miss. The graph is fetched
+// for the end timestamp rounded up to the granularity, so that it matches all the requests it is served to.
+func (c *dependenciesCache) get(ctx context.Context, endTs time.Time, lookback time.Duration, fetch fetchDependencies) ([]model.DependencyLink, error) {
+	endTs = c.roundUp(endTs)
+	key := dependenciesCacheKey{tenant: tenancy.GetTenant(ctx), endTs: endTs.UnixNano(), lookback: lookback}
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && now.Sub(entry.fetched) < c.options.TTL {
+		refresh := !entry.refreshing && now.Sub(entry.fetched) >= entry.refreshAge
+		entry.refreshing = entry.refreshing || refresh
+		dependencies := entry.dependencies
+		c.mu.Unlock()
+		c.metrics.Hits.Inc(1)
+		if refresh {
+			c.refreshes.Add(1)
+			go c.refresh(key, endTs, fetch)
+		}
+		return slices.Clone(dependencies), nil
+	}
+	c.mu.Unlock()
+
+	c.metrics.Misses.Inc(1)
+	dependencies, err := fetch(ctx, endTs, lookback)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, dependencies, now)
+	return slices.Clone(dependencies), nil
+}
+
+// refresh fetches the dependency graph of the key again, outside of any request.
+func (c *dependenciesCache) refresh(key dependenciesCacheKey, endTs time.Time, fetch fetchDependencies) {
+	defer c.refreshes.Done()
+	ctx := context.Background()
+	if key.tenant != "" {
+		ctx = tenancy.WithTenant(ctx, key.tenant)
+	}
+	now := c.now()
+	dependencies, err := fetch(ctx, endTs, key.lookback)
+	if err != nil {
+		c.metrics.RefreshFailures.Inc(1)
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			// the next request retries the refresh
+			entry.refreshing = false
+		}
+		c.mu.Unlock()
+		return
+	}
+	c.store(key, dependencies, now)
+}
+
+func (c *dependenciesCache) store(key dependenciesCacheKey, dependencies []model.DependencyLink, fetched time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxDependenciesCacheEntries {
+		c.evict(fetched)
+	}
+	c.entries[key] = &dependenciesCacheEntry{dependencies: dependencies, fetched: fetched, refreshAge: c.refreshAge()}
+}
+
+// refreshAge returns the age after which a new entry is refreshed, brought forward by a random
+// fraction of up to RefreshJitter, so that the refreshes of the entries stored together spread out.
+func (c *dependenciesCache) refreshAge() time.Duration {
+	age := float64(c.options.TTL) * dependenciesRefreshAge
+	return time.Duration(age * (1 - c.options.RefreshJitter*c.random()))
+}
+
+// evict drops the expired entries, or else the oldest one, to make room for a new entry.
+func (c *dependenciesCache) evict(now time.Time) {
+	var oldest dependenciesCacheKey
+	var oldestFetched time.Time
+	for key, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.options.TTL {
+			delete(c.entries, key)
+		} else if oldestFetched.IsZero() || entry.fetched.Before(oldestFetched) {
+			oldest, oldestFetched = key, entry.fetched
+		}
+	}
+	if len(c.entries) >= maxDependenciesCacheEntries {
+		delete(c.entries, oldest)
+	}
+}
+
+func (c *dependenciesCache) roundUp(endTs time.Time) time.Time {
+	if c.options.Granularity <= 0 {
+		return endTs
+	}
+	rounded := endTs.Truncate(c.options.Granularity)
+	if rounded.Before(endTs) {
+		rounded = rounded.Add(c.options.Granularity)
+	}
+	return rounded
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+func withDependenciesCache(ttl, granularity time.Duration) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.DependenciesCache = DependenciesCacheOptions{TTL: ttl, Granularity: granularity}
+	}
+}
+
+// setDependenciesCacheClock replaces the clock of the dependencies cache, returning a function moving it forward.
+func setDependenciesCacheClock(qs *QueryService, now time.Time) func(time.Duration) {
+	qs.dependenciesCache.now = func() time.Time { return now }
+	return func(d time.Duration) {
+		now = now.Add(d)
+	}
+}
+
+func TestGetDependenciesCacheHit(t *testing.T) {
+	tqs := initializeTestService(withDependenciesCache(time.Minute, time.Minute))
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	setDependenciesCacheClock(tqs.queryService, now)
+	expected := []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 10}}
+	tqs.depsReader.On("GetDependencies", mock.Anything, now.Add(time.Minute), time.Hour).
+		Return(expected, nil).Once()
+
+	// the requests of the same minute share the graph fetched for the end of the minute
+	for _, endTs := range []time.Time{now.Add(time.Second), now.Add(30 * time.Second), now.Add(time.Minute)} {
+		dependencies, err := tqs.queryService.GetDependencies(context.Background(), endTs, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, expected, dependencies)
+	}
+	tqs.depsReader.AssertExpectations(t)
+}
+
+func TestGetDependenciesCacheKeys(t *testing.T) {
+	tqs := initializeTestService(withDependenciesCache(time.Minute, time.Minute))
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	setDependenciesCacheClock(tqs.queryService, now)
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).
+		Return([]model.DependencyLink{}, nil).Times(3)
+	tqs.depsReader.On("GetDependencies", mock.Anything, now.Add(time.Minute), time.Hour).
+		Return([]model.DependencyLink{}, nil).Once()
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, 2*time.Hour).
+		Return([]model.DependencyLink{}, nil).Once()
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		tenancy.WithTenant(context.Background(), "acme"),
+		tenancy.WithTenant(context.Background(), "globex"),
+		// cached
+		tenancy.WithTenant(context.Background(), "acme"),
+	} {
+		_, err := tqs.queryService.GetDependencies(ctx, now, time.Hour)
+		require.NoError(t, err)
+	}
+	_, err := tqs.queryService.GetDependencies(context.Background(), now.Add(time.Second), time.Hour)
+	require.NoError(t, err)
+	_, err = tqs.queryService.GetDependencies(context.Background(), now, 2*time.Hour)
+	require.NoError(t, err)
+	tqs.depsReader.AssertExpectations(t)
+}
+
+func TestGetDependenciesCacheExpiry(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	tqs := initializeTestService(withDependenciesCache(time.Minute, 0), func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MetricsFactory = metricsFactory
+	})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	advance := setDependenciesCacheClock(tqs.queryService, now)
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).
+		Return([]model.DependencyLink{}, nil).Twice()
+
+	_, err := tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	advance(10 * time.Second)
+	_, err = tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	advance(time.Minute)
+	_, err = tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+
+	tqs.depsReader.AssertExpectations(t)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "dependencies_cache.requests", Tags: map[string]string{"result": "hit"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "dependencies_cache.requests", Tags: map[string]string{"result": "miss"}, Value: 2},
+	)
+}
+
+func TestGetDependenciesCacheBackgroundRefresh(t *testing.T) {
+	tqs := initializeTestService(withDependenciesCache(time.Minute, 0))
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	advance := setDependenciesCacheClock(tqs.queryService, now)
+	stale := []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 10}}
+	fresh := []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 12}}
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).Return(stale, nil).Once()
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).Return(fresh, nil).Once()
+
+	_, err := tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	advance(40 * time.Second)
+	// served from the cache while refreshed in the background
+	dependencies, err := tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, stale, dependencies)
+	tqs.queryService.dependenciesCache.refreshes.Wait()
+
+	// the refreshed graph is served past the TTL of the first one
+	advance(25 * time.Second)
+	dependencies, err = tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, fresh, dependencies)
+	tqs.depsReader.AssertExpectations(t)
+}
+
+func TestGetDependenciesCacheErrors(t *testing.T) {
+	tqs := initializeTestService(withDependenciesCache(time.Minute, 0))
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	advance := setDependenciesCacheClock(tqs.queryService, now)
+	errStorage := errors.New("storage unavailable")
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).Return(nil, errStorage).Once()
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).Return([]model.DependencyLink{}, nil).Once()
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).Return(nil, errStorage).Once()
+
+	// the errors are not cached
+	_, err := tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.ErrorIs(t, err, errStorage)
+	_, err = tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+
+	// a failed refresh keeps serving the cached graph
+	advance(40 * time.Second)
+	_, err = tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	tqs.queryService.dependenciesCache.refreshes.Wait()
+	assert.False(t, tqs.queryService.dependenciesCache.entries[dependenciesCacheKey{endTs: now.UnixNano(), lookback: time.Hour}].refreshing)
+	tqs.depsReader.AssertExpectations(t)
+}
+
+func TestDependenciesCacheEviction(t *testing.T) {
+	c := newDependenciesCache(DependenciesCacheOptions{TTL: time.Minute}, nil)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < maxDependenciesCacheEntries; i++ {
+		c.store(dependenciesCacheKey{endTs: int64(i)}, nil, now.Add(time.Duration(i)*time.Millisecond))
+	}
+	c.store(dependenciesCacheKey{endTs: -1}, nil, now.Add(30*time.Second))
+	assert.Len(t, c.entries, maxDependenciesCacheEntries)
+	assert.NotContains(t, c.entries, dependenciesCacheKey{endTs: 0})
+
+	// the expired entries are all dropped
+	c.store(dependenciesCacheKey{endTs: -2}, nil, now.Add(61*time.Second))
+	assert.Len(t, c.entries, 2)
+}
+
+func TestDependenciesCacheRefreshJitter(t *testing.T) {
+	options := DependenciesCacheOptions{TTL: time.Minute, RefreshJitter: 0.5}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	key := dependenciesCacheKey{endTs: now.UnixNano(), lookback: time.Hour}
+
+	// the caches of two query services started together refresh the same graph at different times
+	c1 := newDependenciesCache(options, nil)
+	c2 := newDependenciesCache(options, nil)
+	c1.store(key, nil, now)
+	c2.store(key, nil, now)
+	age1, age2 := c1.entries[key].refreshAge, c2.entries[key].refreshAge
+	assert.NotEqual(t, age1, age2)
+	for _, age := range []time.Duration{age1, age2} {
+		assert.GreaterOrEqual(t, age, 15*time.Second)
+		assert.LessOrEqual(t, age, 30*time.Second)
+	}
+
+	c1.random = func() float64 { return 0.5 }
+	c1.store(key, nil, now)
+	assert.Equal(t, 22500*time.Millisecond, c1.entries[key].refreshAge)
+
+	// without jitter, the graphs are refreshed after half their TTL
+	c := newDependenciesCache(DependenciesCacheOptions{TTL: time.Minute}, nil)
+	c.store(key, nil, now)
+	assert.Equal(t, 30*time.Second, c.entries[key].refreshAge)
+}
+
+func TestGetDependenciesCacheJitteredRefresh(t *testing.T) {
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.DependenciesCache = DependenciesCacheOptions{TTL: time.Minute, RefreshJitter: 0.5}
+	})
+	tqs.queryService.dependenciesCache.random = func() float64 { return 0.5 }
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	advance := setDependenciesCacheClock(tqs.queryService, now)
+	tqs.depsReader.On("GetDependencies", mock.Anything, now, time.Hour).Return([]model.DependencyLink{}, nil).Twice()
+
+	_, err := tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	// not refreshed before the jittered age of 22.5s
+	advance(22 * time.Second)
+	_, err = tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	tqs.queryService.dependenciesCache.refreshes.Wait()
+	tqs.depsReader.AssertNumberOfCalls(t, "GetDependencies", 1)
+
+	advance(time.Second)
+	_, err = tqs.queryService.GetDependencies(context.Background(), now, time.Hour)
+	require.NoError(t, err)
+	tqs.queryService.dependenciesCache.refreshes.Wait()
+	tqs.depsReader.AssertExpectations(t)
+}
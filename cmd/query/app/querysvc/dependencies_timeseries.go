@@ -0,0 +1,87 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// MaxDependencyIntervals is the maximum number of intervals of a dependencies time series,
+	// e.g. a week of hourly graphs.
+	MaxDependencyIntervals = 168
+
+	// maxConcurrentDependencyQueries bounds the number of intervals whose dependencies are read at once.
+	maxConcurrentDependencyQueries = 4
+)
+
+// ErrInvalidDependencyIntervals is returned for a dependencies time series without intervals, or with too many of them.
+var ErrInvalidDependencyIntervals = errors.New("invalid dependencies time series")
+
+// DependenciesInterval is the dependency graph of the calls between Start and End.
+type DependenciesInterval struct {
+	Start        time.Time
+	End          time.Time
+	Dependencies []model.DependencyLink
+}
+
+// GetDependenciesTimeSeries returns the dependency graphs of the consecutive intervals between start
+// and end, the last interval being cut at end. As the dependency links are not timestamped,
+// the dependencies of each interval are read separately, a few intervals at a time.
+func (qs QueryService) GetDependenciesTimeSeries(
+	ctx context.Context,
+	start, end time.Time,
+	interval time.Duration,
+) ([]DependenciesInterval, error) {
+	if interval <= 0 || !end.After(start) {
+		return nil, fmt.Errorf("%w: the interval must be positive and the end after the start", ErrInvalidDependencyIntervals)
+	}
+	span := end.Sub(start)
+	count := span / interval
+	if span%interval != 0 {
+		count++
+	}
+	if count > MaxDependencyIntervals {
+		return nil, fmt.Errorf("%w: %d intervals of %s requested, the maximum is %d",
+			ErrInvalidDependencyIntervals, count, interval, MaxDependencyIntervals)
+	}
+
+	intervals := make([]DependenciesInterval, count)
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	queries := make(chan struct{}, maxConcurrentDependencyQueries)
+	for i := range intervals {
+		intervalStart := start.Add(time.Duration(i) * interval)
+		intervalEnd := intervalStart.Add(interval)
+		if intervalEnd.After(end) {
+			intervalEnd = end
+		}
+		intervals[i] = DependenciesInterval{Start: intervalStart, End: intervalEnd}
+		queries <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-queries
+				wg.Done()
+			}()
+			in := &intervals[i]
+			in.Dependencies, errs[i] = qs.GetDependencies(ctx, in.End, in.End.Sub(in.Start))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the dependencies between %s and %s: %w",
+				intervals[i].Start.Format(time.RFC3339), intervals[i].End.Format(time.RFC3339), err)
+		}
+	}
+	return intervals, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestGetDependenciesTimeSeries(t *testing.T) {
+	tqs := initializeTestService()
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(150 * time.Minute)
+	tqs.depsReader.On("GetDependencies", mock.Anything, start.Add(time.Hour), time.Hour).
+		Return([]model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 10}}, nil).Once()
+	tqs.depsReader.On("GetDependencies", mock.Anything, start.Add(2*time.Hour), time.Hour).
+		Return([]model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 7}}, nil).Once()
+	tqs.depsReader.On("GetDependencies", mock.Anything, end, 30*time.Minute).
+		Return([]model.DependencyLink{}, nil).Once()
+
+	intervals, err := tqs.queryService.GetDependenciesTimeSeries(context.Background(), start, end, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []DependenciesInterval{
+		{
+			Start:        start,
+			End:          start.Add(time.Hour),
+			Dependencies: []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 10}},
+		},
+		{
+			Start:        start.Add(time.Hour),
+			End:          start.Add(2 * time.Hour),
+			Dependencies: []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 7}},
+		},
+		{
+			// the last interval is cut at the end
+			Start:        start.Add(2 * time.Hour),
+			End:          end,
+			Dependencies: []model.DependencyLink{},
+		},
+	}, intervals)
+	tqs.depsReader.AssertExpectations(t)
+}
+
+func TestGetDependenciesTimeSeriesInvalidIntervals(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		end      time.Time
+		interval time.Duration
+	}{
+		{name: "interval not positive", end: start.Add(time.Hour), interval: 0},
+		{name: "end before start", end: start.Add(-time.Hour), interval: time.Hour},
+		{name: "too many intervals", end: start.Add((MaxDependencyIntervals + 1) * time.Hour), interval: time.Hour},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tqs := initializeTestService()
+			_, err := tqs.queryService.GetDependenciesTimeSeries(context.Background(), start, test.end, test.interval)
+			require.ErrorIs(t, err, ErrInvalidDependencyIntervals)
+			tqs.depsReader.AssertNotCalled(t, "GetDependencies", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetDependenciesTimeSeriesFailure(t *testing.T) {
+	tqs := initializeTestService()
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	errStorage := errors.New("storage unavailable")
+	tqs.depsReader.On("GetDependencies", mock.Anything, start.Add(time.Hour), time.Hour).
+		Return([]model.DependencyLink{}, nil)
+	tqs.depsReader.On("GetDependencies", mock.Anything, start.Add(2*time.Hour), time.Hour).
+		Return(nil, errStorage)
+
+	_, err := tqs.queryService.GetDependenciesTimeSeries(context.Background(), start, start.Add(2*time.Hour), time.Hour)
+	require.ErrorIs(t, err, errStorage)
+	assert.Contains(t, err.Error(), "between 2024-05-01T11:00:00Z and 2024-05-01T12:00:00Z")
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// dependencyErrorsSampleSize is the number of traces of each parent service searched
+// to derive the error counts when the dependency storage does not record them.
+const dependencyErrorsSampleSize = 100
+
+// DependencyLinkWithErrors is a dependency link along with the number of its calls which failed.
+type DependencyLinkWithErrors struct {
+	model.DependencyLink
+	ErrorCount uint64
+	// ErrorCountApproximate is set when the dependency storage does not record the failed calls,
+	// the error count being derived from a sample of the traces of the parent service.
+	ErrorCountApproximate bool
+}
+
+type dependencyKey struct {
+	parent string
+	child  string
+}
+
+// GetDependenciesWithErrors returns the dependencies like GetDependencies, along with their error counts.
+func (qs QueryService) GetDependenciesWithErrors(ctx context.Context, endTs time.Time, lookback time.Duration) ([]DependencyLinkWithErrors, error) {
+	lookback = qs.clampDependencyLookback(ctx, lookback)
+	dependencies, err := qs.GetDependencies(ctx, endTs, lookback)
+	if err != nil {
+		return nil, err
+	}
+	if errorCountReader, ok := qs.dependencyReader.(dependencystore.ErrorCountReader); ok {
+		return qs.readDependencyErrors(ctx, errorCountReader, dependencies, endTs, lookback)
+	}
+	return qs.deriveDependencyErrors(ctx, dependencies, endTs, lookback)
+}
+
+// readDependencyErrors adds the error counts recorded by the dependency storage.
+func (qs QueryService) readDependencyErrors(
+	ctx context.Context,
+	reader dependencystore.ErrorCountReader,
+	dependencies []model.DependencyLink,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]DependencyLinkWithErrors, error) {
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.Dependencies)
+	defer cancel()
+	linkErrors, err := reader.GetDependencyErrors(ctx, endTs, lookback)
+	qs.errorMetrics.record(err)
+	if err != nil {
+		return nil, err
+	}
+	// the error counts are carried as call counts to be renamed like the dependencies
+	errorLinks := make([]model.DependencyLink, 0, len(linkErrors))
+	for _, l := range linkErrors {
+		errorLinks = append(errorLinks, model.DependencyLink{Parent: l.Parent, Child: l.Child, CallCount: l.ErrorCount})
+	}
+	errorCounts := make(map[dependencyKey]uint64, len(errorLinks))
+	for _, l := range qs.fromStorageDependencies(ctx, errorLinks) {
+		errorCounts[dependencyKey{l.Parent, l.Child}] += l.CallCount
+	}
+
+	result := make([]DependencyLinkWithErrors, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		key := dependencyKey{dependency.Parent, dependency.Child}
+		result = append(result, DependencyLinkWithErrors{
+			DependencyLink: dependency,
+			ErrorCount:     errorCounts[key],
+		})
+		// a link split across several entries gets its error count once
+		delete(errorCounts, key)
+	}
+	return result, nil
+}
+
+// deriveDependencyErrors approximates the error counts by applying the ratio of failed calls
+// between the services, in a sample of the traces of the parent service, to the call counts.
+func (qs QueryService) deriveDependencyErrors(
+	ctx context.Context,
+	dependencies []model.DependencyLink,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]DependencyLinkWithErrors, error) {
+	type sampledCalls struct {
+		calls  uint64
+		errors uint64
+	}
+	samples := make(map[dependencyKey]*sampledCalls)
+	sampledParents := make(map[string]bool)
+	for _, dependency := range dependencies {
+		if sampledParents[dependency.Parent] {
+			continue
+		}
+		sampledParents[dependency.Parent] = true
+		traces, err := qs.FindTraces(ctx, &spanstore.TraceQueryParameters{
+			ServiceName:  dependency.Parent,
+			StartTimeMin: endTs.Add(-lookback),
+			StartTimeMax: endTs,
+			NumTraces:    dependencyErrorsSampleSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range traces {
+			spans := make(map[model.SpanID]*model.Span, len(trace.Spans))
+			for _, span := range trace.Spans {
+				spans[span.SpanID] = span
+			}
+			for _, span := range trace.Spans {
+				parent, ok := spans[span.ParentSpanID()]
+				if !ok || parent.Process.ServiceName != dependency.Parent ||
+					span.Process.ServiceName == dependency.Parent {
+					continue
+				}
+				key := dependencyKey{dependency.Parent, span.Process.ServiceName}
+				sample, ok := samples[key]
+				if !ok {
+					sample = &sampledCalls{}
+					samples[key] = sample
+				}
+				sample.calls++
+				if spanHasError(span) {
+					sample.errors++
+				}
+			}
+		}
+	}
+
+	result := make([]DependencyLinkWithErrors, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		link := DependencyLinkWithErrors{
+			DependencyLink:        dependency,
+			ErrorCountApproximate: true,
+		}
+		if sample, ok := samples[dependencyKey{dependency.Parent, dependency.Child}]; ok {
+			errorRatio := float64(sample.errors) / float64(sample.calls)
+			link.ErrorCount = uint64(math.Round(errorRatio * float64(dependency.CallCount)))
+		}
+		result = append(result, link)
+	}
+	return result, nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+var errDependencyStorage = errors.New("dependency storage error")
+
+// errorCountDepsReader is a dependency reader implementing dependencystore.ErrorCountReader.
+type errorCountDepsReader struct {
+	*depsmocks.Reader
+}
+
+func (r errorCountDepsReader) GetDependencyErrors(ctx context.Context, endTs time.Time, lookback time.Duration) ([]dependencystore.DependencyLinkErrors, error) {
+	args := r.Called(ctx, endTs, lookback)
+	links, _ := args.Get(0).([]dependencystore.DependencyLinkErrors)
+	return links, args.Error(1)
+}
+
+func TestGetDependenciesWithNativeErrors(t *testing.T) {
+	depsReader := &depsmocks.Reader{}
+	qs := NewQueryService(&spanstoremocks.Reader{}, errorCountDepsReader{depsReader}, QueryServiceOptions{})
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	depsReader.On("GetDependencies", mock.Anything, endTs, defaultDependencyLookbackDuration).Return([]model.DependencyLink{
+		{Parent: "killer", Child: "queen", CallCount: 12},
+		{Parent: "killer", Child: "queen", CallCount: 3},
+		{Parent: "queen", Child: "bee", CallCount: 7},
+	}, nil).Once()
+	depsReader.On("GetDependencyErrors", mock.Anything, endTs, defaultDependencyLookbackDuration).Return([]dependencystore.DependencyLinkErrors{
+		{Parent: "killer", Child: "queen", ErrorCount: 4},
+	}, nil).Once()
+
+	dependencies, err := qs.GetDependenciesWithErrors(context.Background(), endTs, defaultDependencyLookbackDuration)
+	require.NoError(t, err)
+	assert.Equal(t, []DependencyLinkWithErrors{
+		{DependencyLink: model.DependencyLink{Parent: "killer", Child: "queen", CallCount: 12}, ErrorCount: 4},
+		{DependencyLink: model.DependencyLink{Parent: "killer", Child: "queen", CallCount: 3}},
+		{DependencyLink: model.DependencyLink{Parent: "queen", Child: "bee", CallCount: 7}},
+	}, dependencies)
+
+	depsReader.On("GetDependencies", mock.Anything, endTs, defaultDependencyLookbackDuration).Return([]model.DependencyLink{}, nil).Once()
+	depsReader.On("GetDependencyErrors", mock.Anything, endTs, defaultDependencyLookbackDuration).Return(nil, errDependencyStorage).Once()
+	_, err = qs.GetDependenciesWithErrors(context.Background(), endTs, defaultDependencyLookbackDuration)
+	require.ErrorIs(t, err, errDependencyStorage)
+}
+
+func TestGetDependenciesWithErrorsMaxLookback(t *testing.T) {
+	depsReader := &depsmocks.Reader{}
+	qs := NewQueryService(&spanstoremocks.Reader{}, errorCountDepsReader{depsReader}, QueryServiceOptions{
+		MaxDependencyLookback: time.Hour,
+	})
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	depsReader.On("GetDependencies", mock.Anything, endTs, time.Hour).Return([]model.DependencyLink{}, nil).Once()
+	depsReader.On("GetDependencyErrors", mock.Anything, endTs, time.Hour).Return([]dependencystore.DependencyLinkErrors{}, nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	_, err := qs.GetDependenciesWithErrors(ctx, endTs, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dependencies lookback of 24h0m0s reduced to the maximum of 1h0m0s"}, GetWarnings(ctx))
+	depsReader.AssertExpectations(t)
+}
+
+// callTrace returns a trace where the parent service calls the child service, the call failing if failed is set.
+func callTrace(traceID uint64, parent, child string, failed bool) *model.Trace {
+	id := model.NewTraceID(0, traceID)
+	childSpan := &model.Span{
+		TraceID:    id,
+		SpanID:     model.NewSpanID(2),
+		References: []model.SpanRef{model.NewChildOfRef(id, model.NewSpanID(1))},
+		Process:    &model.Process{ServiceName: child},
+	}
+	if failed {
+		childSpan.Tags = []model.KeyValue{model.Bool("error", true)}
+	}
+	return &model.Trace{Spans: []*model.Span{
+		{TraceID: id, SpanID: model.NewSpanID(1), Process: &model.Process{ServiceName: parent}},
+		childSpan,
+		// internal span of the child service
+		{
+			TraceID:    id,
+			SpanID:     model.NewSpanID(3),
+			References: []model.SpanRef{model.NewChildOfRef(id, model.NewSpanID(2))},
+			Process:    &model.Process{ServiceName: child},
+		},
+	}}
+}
+
+func TestGetDependenciesWithDerivedErrors(t *testing.T) {
+	tqs := initializeTestService()
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	tqs.depsReader.On("GetDependencies", mock.Anything, endTs, defaultDependencyLookbackDuration).Return([]model.DependencyLink{
+		{Parent: "killer", Child: "queen", CallCount: 12},
+		{Parent: "killer", Child: "drone", CallCount: 5},
+		{Parent: "queen", Child: "bee", CallCount: 7},
+	}, nil).Once()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "killer" && q.StartTimeMin.Equal(endTs.Add(-defaultDependencyLookbackDuration)) &&
+			q.StartTimeMax.Equal(endTs) && q.NumTraces == dependencyErrorsSampleSize
+	})).Return([]*model.Trace{
+		callTrace(1, "killer", "queen", true),
+		callTrace(2, "killer", "queen", false),
+		callTrace(3, "killer", "queen", false),
+		callTrace(4, "killer", "queen", false),
+	}, nil).Once()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.ServiceName == "queen"
+	})).Return([]*model.Trace{}, nil).Once()
+
+	dependencies, err := tqs.queryService.GetDependenciesWithErrors(context.Background(), endTs, defaultDependencyLookbackDuration)
+	require.NoError(t, err)
+	assert.Equal(t, []DependencyLinkWithErrors{
+		// a quarter of the sampled calls failed
+		{DependencyLink: model.DependencyLink{Parent: "killer", Child: "queen", CallCount: 12}, ErrorCount: 3, ErrorCountApproximate: true},
+		// no sampled calls
+		{DependencyLink: model.DependencyLink{Parent: "killer", Child: "drone", CallCount: 5}, ErrorCountApproximate: true},
+		{DependencyLink: model.DependencyLink{Parent: "queen", Child: "bee", CallCount: 7}, ErrorCountApproximate: true},
+	}, dependencies)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestGetDependenciesWithDerivedErrorsFailure(t *testing.T) {
+	tqs := initializeTestService()
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	tqs.depsReader.On("GetDependencies", mock.Anything, endTs, defaultDependencyLookbackDuration).Return([]model.DependencyLink{
+		{Parent: "killer", Child: "queen", CallCount: 12},
+	}, nil).Once()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errDependencyStorage).Once()
+	_, err := tqs.queryService.GetDependenciesWithErrors(context.Background(), endTs, defaultDependencyLookbackDuration)
+	require.ErrorIs(t, err, errDependencyStorage)
+
+	tqs.depsReader.On("GetDependencies", mock.Anything, endTs, defaultDependencyLookbackDuration).Return(nil, errDependencyStorage).Once()
+	_, err = tqs.queryService.GetDependenciesWithErrors(context.Background(), endTs, defaultDependencyLookbackDuration)
+	require.ErrorIs(t, err, errDependencyStorage)
+}
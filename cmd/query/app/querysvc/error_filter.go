@@ -0,0 +1,57 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	errorTagKey      = "error"
+	statusCodeTagKey = "otel.status_code"
+	statusCodeError  = "ERROR"
+)
+
+// ErrorFilter selects the traces with an error, i.e. a span tagged with error=true
+// or with the error status code.
+type ErrorFilter struct {
+	HasError bool
+	// RootSpanOnly only looks for the error on the root spans of the trace.
+	RootSpanOnly bool
+}
+
+func (f ErrorFilter) enabled() bool {
+	return f.HasError
+}
+
+func (f ErrorFilter) matches(trace *model.Trace) bool {
+	for _, span := range trace.Spans {
+		if f.RootSpanOnly && span.ParentSpanID() != 0 {
+			continue
+		}
+		if spanHasError(span) {
+			return true
+		}
+	}
+	return false
+}
+
+func spanHasError(span *model.Span) bool {
+	for _, tag := range span.Tags {
+		switch tag.Key {
+		case errorTagKey:
+			// the error tag is a boolean, but some instrumentations set it as a string
+			if strings.EqualFold(tag.AsString(), "true") {
+				return true
+			}
+		case statusCodeTagKey:
+			if tag.AsString() == statusCodeError {
+				return true
+			}
+		}
+	}
+	return false
+}
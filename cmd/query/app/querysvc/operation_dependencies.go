@@ -0,0 +1,40 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+)
+
+// ErrOperationDependenciesUnsupported is returned by GetOperationDependencies when the dependency storage
+// does not record the calls between the operations of the services.
+var ErrOperationDependenciesUnsupported = errors.New("the dependency storage does not support the operation dependencies")
+
+// GetOperationDependencies returns the calls between the operations of the service and the operations
+// of the other services, by decreasing number of calls. The storage bounds the number of operations of each
+// service, merging the operations with the fewest calls into dependencystore.OtherOperations.
+func (qs QueryService) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	reader, ok := qs.dependencyReader.(dependencystore.OperationDependencyReader)
+	if !ok {
+		return nil, ErrOperationDependenciesUnsupported
+	}
+	lookback = qs.clampDependencyLookback(ctx, lookback)
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.Dependencies)
+	defer cancel()
+	dependencies, err := reader.GetOperationDependencies(ctx, qs.options.TenancyMgr.ToStorageName(ctx, service), endTs, lookback)
+	qs.errorMetrics.record(err)
+	if err != nil {
+		return nil, err
+	}
+	return qs.fromStorageOperationDependencies(ctx, dependencies), nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+// operationDepsReader is a dependency reader implementing dependencystore.OperationDependencyReader.
+type operationDepsReader struct {
+	*depsmocks.Reader
+}
+
+func (r operationDepsReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	args := r.Called(ctx, service, endTs, lookback)
+	links, _ := args.Get(0).([]dependencystore.OperationDependencyLink)
+	return links, args.Error(1)
+}
+
+func TestGetOperationDependencies(t *testing.T) {
+	depsReader := &depsmocks.Reader{}
+	qs := NewQueryService(&spanstoremocks.Reader{}, operationDepsReader{depsReader}, QueryServiceOptions{})
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	links := []dependencystore.OperationDependencyLink{
+		{Parent: "frontend", ParentOperation: "/checkout", Child: "orders", ChildOperation: "create", CallCount: 3},
+	}
+	depsReader.On("GetOperationDependencies", mock.Anything, "frontend", endTs, time.Hour).Return(links, nil)
+
+	dependencies, err := qs.GetOperationDependencies(context.Background(), "frontend", endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, links, dependencies)
+}
+
+func TestGetOperationDependenciesFailure(t *testing.T) {
+	depsReader := &depsmocks.Reader{}
+	qs := NewQueryService(&spanstoremocks.Reader{}, operationDepsReader{depsReader}, QueryServiceOptions{})
+	depsReader.On("GetOperationDependencies", mock.Anything, "frontend", mock.Anything, mock.Anything).
+		Return(nil, errDependencyStorage)
+
+	_, err := qs.GetOperationDependencies(context.Background(), "frontend", time.Now(), time.Hour)
+	require.ErrorIs(t, err, errDependencyStorage)
+}
+
+func TestGetOperationDependenciesUnsupported(t *testing.T) {
+	qs := NewQueryService(&spanstoremocks.Reader{}, &depsmocks.Reader{}, QueryServiceOptions{})
+	_, err := qs.GetOperationDependencies(context.Background(), "frontend", time.Now(), time.Hour)
+	require.ErrorIs(t, err, ErrOperationDependenciesUnsupported)
+}
+
+func TestStoragePrefixGetOperationDependencies(t *testing.T) {
+	depsReader := &depsmocks.Reader{}
+	depsReader.On("GetOperationDependencies", mock.Anything, "acme.frontend", mock.Anything, mock.Anything).
+		Return([]dependencystore.OperationDependencyLink{
+			{Parent: "acme.frontend", ParentOperation: "acme./checkout", Child: "acme.orders", ChildOperation: "acme.create", CallCount: 3},
+			{Parent: "acme.frontend", ParentOperation: dependencystore.OtherOperations, Child: "acme.orders", ChildOperation: dependencystore.OtherOperations, CallCount: 2},
+			{Parent: "acme.frontend", ParentOperation: "acme./checkout", Child: "megacorp.orders", ChildOperation: "megacorp.create", CallCount: 1},
+		}, nil)
+	qs := NewQueryService(&spanstoremocks.Reader{}, operationDepsReader{depsReader}, QueryServiceOptions{
+		TenancyMgr: tenancy.NewManager(&tenancy.Options{Enabled: true, StoragePrefix: "{tenant}."}),
+	})
+
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	dependencies, err := qs.GetOperationDependencies(ctx, "frontend", time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []dependencystore.OperationDependencyLink{
+		{Parent: "frontend", ParentOperation: "/checkout", Child: "orders", ChildOperation: "create", CallCount: 3},
+		{Parent: "frontend", ParentOperation: dependencystore.OtherOperations, Child: "orders", ChildOperation: dependencystore.OtherOperations, CallCount: 2},
+	}, dependencies)
+}
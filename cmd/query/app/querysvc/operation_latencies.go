@@ -0,0 +1,135 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// DefaultOperationLatencySamples is the number of traces sampled for the operation latencies,
+	// unless the query specifies it.
+	DefaultOperationLatencySamples = 100
+	// MaxOperationLatencySamples caps the number of traces sampled for the operation latencies,
+	// bounding the cost of the query.
+	MaxOperationLatencySamples = 1000
+)
+
+// OperationLatencyPercentiles are the percentiles of the operation latencies.
+var OperationLatencyPercentiles = []float64{0.5, 0.95, 0.99}
+
+// OperationLatenciesQuery selects the spans whose latencies are aggregated by operation.
+type OperationLatenciesQuery struct {
+	// ServiceName is the service of the spans, required.
+	ServiceName string
+	// OperationName restricts the latencies to a single operation, if not empty.
+	OperationName string
+	StartTimeMin  time.Time
+	StartTimeMax  time.Time
+	// MaxSamples is the number of traces sampled, DefaultOperationLatencySamples if not set,
+	// capped to MaxOperationLatencySamples.
+	MaxSamples int
+}
+
+// OperationLatency are the latency percentiles of the spans of an operation.
+type OperationLatency struct {
+	OperationName string
+	// Samples is the number of spans the percentiles are computed from.
+	Samples int
+	// Percentiles are the latencies at OperationLatencyPercentiles, in the same order.
+	Percentiles []time.Duration
+}
+
+// OperationLatencies are the latencies of the operations of a service, sorted by operation name.
+type OperationLatencies struct {
+	Operations []OperationLatency
+	// Approximate tells whether the latencies are computed from a sample of the traces.
+	Approximate bool
+}
+
+// GetOperationLatencies computes the latency percentiles of the operations of the service
+// from the spans of a sample of the traces matching the query. The latencies are approximate,
+// as the traces are sampled and the search guardrails apply to the search.
+func (qs QueryService) GetOperationLatencies(ctx context.Context, query OperationLatenciesQuery) (*OperationLatencies, error) {
+	if query.ServiceName == "" {
+		return nil, errors.New("service name is required")
+	}
+	samples := query.MaxSamples
+	switch {
+	case samples <= 0:
+		samples = DefaultOperationLatencySamples
+	case samples > MaxOperationLatencySamples:
+		AddWarning(ctx, fmt.Sprintf("latency samples %d reduced to the maximum of %d", samples, MaxOperationLatencySamples))
+		samples = MaxOperationLatencySamples
+	}
+	traces, err := qs.FindTraces(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:   query.ServiceName,
+		OperationName: query.OperationName,
+		StartTimeMin:  query.StartTimeMin,
+		StartTimeMax:  query.StartTimeMax,
+		NumTraces:     samples,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &OperationLatencies{
+		Operations:  aggregateOperationLatencies(traces, query.ServiceName, query.OperationName),
+		Approximate: true,
+	}, nil
+}
+
+// aggregateOperationLatencies computes the latency percentiles of the spans of the service by operation,
+// restricted to the operation if not empty.
+func aggregateOperationLatencies(traces []*model.Trace, serviceName, operationName string) []OperationLatency {
+	durations := make(map[string][]time.Duration)
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			if span.Process == nil || span.Process.ServiceName != serviceName {
+				continue
+			}
+			if operationName != "" && span.OperationName != operationName {
+				continue
+			}
+			durations[span.OperationName] = append(durations[span.OperationName], span.Duration)
+		}
+	}
+	latencies := make([]OperationLatency, 0, len(durations))
+	for operation, d := range durations {
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+		latency := OperationLatency{
+			OperationName: operation,
+			Samples:       len(d),
+			Percentiles:   make([]time.Duration, len(OperationLatencyPercentiles)),
+		}
+		for i, p := range OperationLatencyPercentiles {
+			latency.Percentiles[i] = percentile(d, p)
+		}
+		latencies = append(latencies, latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i].OperationName < latencies[j].OperationName
+	})
+	return latencies
+}
+
+// percentile returns the p-th percentile, 0 < p <= 1, of the sorted durations
+// with the nearest-rank method, i.e. the smallest duration at least p of the durations are lower or equal to.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
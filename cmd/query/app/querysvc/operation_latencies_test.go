@@ -0,0 +1,128 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func TestPercentile(t *testing.T) {
+	// 1ms to 100ms, so that the p-th percentile is p ms
+	durations := make([]time.Duration, 100)
+	for i := range durations {
+		durations[i] = time.Duration(i+1) * time.Millisecond
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(durations, 0.5))
+	assert.Equal(t, 95*time.Millisecond, percentile(durations, 0.95))
+	assert.Equal(t, 99*time.Millisecond, percentile(durations, 0.99))
+	assert.Equal(t, 100*time.Millisecond, percentile(durations, 1))
+	assert.Equal(t, time.Millisecond, percentile(durations, 0))
+
+	assert.Equal(t, 7*time.Millisecond, percentile([]time.Duration{7 * time.Millisecond}, 0.99))
+	assert.Zero(t, percentile(nil, 0.5))
+}
+
+// latencyTraces returns a trace per duration of 1ms to 100ms, in random order, each with
+// a "checkout" span of the duration, a "render" span of a tenth of it, and a span of another service.
+func latencyTraces() []*model.Trace {
+	traces := make([]*model.Trace, 100)
+	for i, n := range rand.Perm(100) {
+		d := time.Duration(n+1) * time.Millisecond
+		traceID := model.NewTraceID(0, uint64(i+1))
+		traces[i] = &model.Trace{Spans: []*model.Span{
+			{TraceID: traceID, SpanID: 1, OperationName: "checkout", Duration: d, Process: &model.Process{ServiceName: "shop"}},
+			{TraceID: traceID, SpanID: 2, OperationName: "render", Duration: d / 10, Process: &model.Process{ServiceName: "shop"}},
+			{TraceID: traceID, SpanID: 3, OperationName: "insert", Duration: time.Second, Process: &model.Process{ServiceName: "db"}},
+		}}
+	}
+	return traces
+}
+
+func TestGetOperationLatencies(t *testing.T) {
+	tqs := initializeTestService()
+	end := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tqs.spanReader.On("FindTraces", mock.Anything, &spanstore.TraceQueryParameters{
+		ServiceName:  "shop",
+		StartTimeMin: end.Add(-time.Hour),
+		StartTimeMax: end,
+		NumTraces:    DefaultOperationLatencySamples,
+	}).Return(latencyTraces(), nil).Once()
+
+	latencies, err := tqs.queryService.GetOperationLatencies(context.Background(), OperationLatenciesQuery{
+		ServiceName:  "shop",
+		StartTimeMin: end.Add(-time.Hour),
+		StartTimeMax: end,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &OperationLatencies{
+		Operations: []OperationLatency{
+			{
+				OperationName: "checkout",
+				Samples:       100,
+				Percentiles:   []time.Duration{50 * time.Millisecond, 95 * time.Millisecond, 99 * time.Millisecond},
+			},
+			{
+				OperationName: "render",
+				Samples:       100,
+				Percentiles:   []time.Duration{5 * time.Millisecond, 9500 * time.Microsecond, 9900 * time.Microsecond},
+			},
+		},
+		Approximate: true,
+	}, latencies)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestGetOperationLatenciesOperationFilter(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.OperationName == "render" && q.NumTraces == 10
+	})).Return(latencyTraces(), nil).Once()
+
+	latencies, err := tqs.queryService.GetOperationLatencies(context.Background(), OperationLatenciesQuery{
+		ServiceName:   "shop",
+		OperationName: "render",
+		MaxSamples:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, latencies.Operations, 1)
+	assert.Equal(t, "render", latencies.Operations[0].OperationName)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestGetOperationLatenciesSampleCap(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return q.NumTraces == MaxOperationLatencySamples
+	})).Return([]*model.Trace{}, nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	latencies, err := tqs.queryService.GetOperationLatencies(ctx, OperationLatenciesQuery{
+		ServiceName: "shop",
+		MaxSamples:  5000,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, latencies.Operations)
+	assert.Equal(t, []string{"latency samples 5000 reduced to the maximum of 1000"}, GetWarnings(ctx))
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestGetOperationLatenciesErrors(t *testing.T) {
+	tqs := initializeTestService()
+	_, err := tqs.queryService.GetOperationLatencies(context.Background(), OperationLatenciesQuery{})
+	require.EqualError(t, err, "service name is required")
+
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	_, err = tqs.queryService.GetOperationLatencies(context.Background(), OperationLatenciesQuery{ServiceName: "shop"})
+	require.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Names of the top-level span fields that can be selected in a SpanFields projection.
+// Trace and span IDs, references and the process service name are required for the
+// structural integrity of a trace and are always included.
+const (
+	SpanFieldOperationName = "operationName"
+	SpanFieldFlags         = "flags"
+	SpanFieldStartTime     = "startTime"
+	SpanFieldDuration      = "duration"
+	SpanFieldTags          = "tags"
+	SpanFieldLogs          = "logs"
+	SpanFieldProcessTags   = "processTags"
+	SpanFieldWarnings      = "warnings"
+)
+
+var projectableSpanFields = map[string]struct{}{
+	SpanFieldOperationName: {},
+	SpanFieldFlags:         {},
+	SpanFieldStartTime:     {},
+	SpanFieldDuration:      {},
+	SpanFieldTags:          {},
+	SpanFieldLogs:          {},
+	SpanFieldProcessTags:   {},
+	SpanFieldWarnings:      {},
+}
+
+// SpanFieldsMetadataKey is the gRPC metadata holding the span fields to which the returned traces are
+// projected, in the same format as the fields parameter of the HTTP API.
+const SpanFieldsMetadataKey = "jaeger-span-fields"
+
+type spanFieldsContextKey struct{}
+
+// SpanFields is the set of span fields to keep when projecting a trace.
+// A nil SpanFields keeps all fields.
+type SpanFields map[string]struct{}
+
+// ParseSpanFields builds SpanFields from a list of field names. Each name may also be
+// a comma-separated list. It returns nil SpanFields when no names are given.
+func ParseSpanFields(names []string) (SpanFields, error) {
+	var fields SpanFields
+	for _, name := range names {
+		for _, field := range strings.Split(name, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if _, ok := projectableSpanFields[field]; !ok {
+				return nil, fmt.Errorf("unsupported span field '%s'", field)
+			}
+			if fields == nil {
+				fields = make(SpanFields)
+			}
+			fields[field] = struct{}{}
+		}
+	}
+	return fields, nil
+}
+
+func (f SpanFields) has(field string) bool {
+	_, ok := f[field]
+	return ok
+}
+
+// ContextWithSpanFields returns a context in which the traces returned by GetTrace, GetTraces and the
+// trace searches of the query service are projected to the fields. Callers which process the traces
+// further, like the HTTP API adjusting them, must not use it and call ProjectTrace last instead, since
+// the adjusters depend on the fields removed by the projection.
+func ContextWithSpanFields(ctx context.Context, fields SpanFields) context.Context {
+	if fields == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanFieldsContextKey{}, fields)
+}
+
+// projectTrace projects the trace to the span fields of the context, if any.
+func projectTrace(ctx context.Context, trace *model.Trace) {
+	if fields, ok := ctx.Value(spanFieldsContextKey{}).(SpanFields); ok {
+		ProjectTrace(trace, fields)
+	}
+}
+
+// ProjectTrace zeroes out the span fields that are not included in the projection.
+// The trace is modified in place.
+func ProjectTrace(trace *model.Trace, fields SpanFields) {
+	if fields == nil {
+		return
+	}
+	for _, span := range trace.Spans {
+		projectSpan(span, fields)
+	}
+}
+
+func projectSpan(span *model.Span, fields SpanFields) {
+	if !fields.has(SpanFieldOperationName) {
+		span.OperationName = ""
+	}
+	if !fields.has(SpanFieldFlags) {
+		span.Flags = 0
+	}
+	if !fields.has(SpanFieldStartTime) {
+		span.StartTime = model.EpochMicrosecondsAsTime(0)
+	}
+	if !fields.has(SpanFieldDuration) {
+		span.Duration = 0
+	}
+	if !fields.has(SpanFieldTags) {
+		span.Tags = nil
+	}
+	if !fields.has(SpanFieldLogs) {
+		span.Logs = nil
+	}
+	if !fields.has(SpanFieldWarnings) {
+		span.Warnings = nil
+	}
+	if !fields.has(SpanFieldProcessTags) && span.Process != nil {
+		// processes may be shared between spans, so they are replaced rather than modified
+		span.Process = model.NewProcess(span.Process.ServiceName, nil)
+	}
+}
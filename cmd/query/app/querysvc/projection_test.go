@@ -0,0 +1,120 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestParseSpanFields(t *testing.T) {
+	fields, err := ParseSpanFields(nil)
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+
+	fields, err = ParseSpanFields([]string{"startTime, duration", "", "logs"})
+	require.NoError(t, err)
+	assert.Equal(t, SpanFields{
+		SpanFieldStartTime: {},
+		SpanFieldDuration:  {},
+		SpanFieldLogs:      {},
+	}, fields)
+
+	_, err = ParseSpanFields([]string{"startTime,references"})
+	require.EqualError(t, err, "unsupported span field 'references'")
+}
+
+func TestProjectTraceTimingOnly(t *testing.T) {
+	process := model.NewProcess("svc", []model.KeyValue{model.String("hostname", "host")})
+	startTime := time.Unix(10, 0)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{
+				TraceID:       model.NewTraceID(0, 1),
+				SpanID:        model.NewSpanID(1),
+				OperationName: "root",
+				Flags:         model.Flags(1),
+				StartTime:     startTime,
+				Duration:      time.Second,
+				Tags:          []model.KeyValue{model.String("k", "v")},
+				Logs:          []model.Log{{Timestamp: startTime}},
+				Process:       process,
+				Warnings:      []string{"warning"},
+			},
+			{
+				TraceID:    model.NewTraceID(0, 1),
+				SpanID:     model.NewSpanID(2),
+				References: []model.SpanRef{model.NewChildOfRef(model.NewTraceID(0, 1), model.NewSpanID(1))},
+				StartTime:  startTime.Add(time.Millisecond),
+				Duration:   time.Millisecond,
+				Process:    process,
+			},
+		},
+	}
+	fields, err := ParseSpanFields([]string{"startTime,duration"})
+	require.NoError(t, err)
+	ProjectTrace(trace, fields)
+
+	root := trace.Spans[0]
+	assert.Equal(t, model.NewSpanID(1), root.SpanID)
+	assert.Equal(t, startTime, root.StartTime)
+	assert.Equal(t, time.Second, root.Duration)
+	assert.Empty(t, root.OperationName)
+	assert.Zero(t, root.Flags)
+	assert.Nil(t, root.Tags)
+	assert.Nil(t, root.Logs)
+	assert.Nil(t, root.Warnings)
+	assert.Equal(t, "svc", root.Process.ServiceName)
+	assert.Empty(t, root.Process.Tags)
+	// the shared process must not be modified
+	assert.Len(t, process.Tags, 1)
+
+	child := trace.Spans[1]
+	assert.Equal(t, model.NewSpanID(1), child.ParentSpanID())
+	assert.Equal(t, time.Millisecond, child.Duration)
+}
+
+func TestProjectTraceExcludesTiming(t *testing.T) {
+	trace := &model.Trace{
+		Spans: []*model.Span{{
+			OperationName: "op",
+			StartTime:     time.Unix(10, 0),
+			Duration:      time.Second,
+			Process:       model.NewProcess("svc", nil),
+		}},
+	}
+	ProjectTrace(trace, SpanFields{SpanFieldOperationName: {}})
+	assert.Equal(t, "op", trace.Spans[0].OperationName)
+	assert.Equal(t, model.EpochMicrosecondsAsTime(0), trace.Spans[0].StartTime)
+	assert.Zero(t, trace.Spans[0].Duration)
+}
+
+func TestProjectTraceAllFields(t *testing.T) {
+	span := &model.Span{
+		OperationName: "op",
+		Tags:          []model.KeyValue{model.String("k", "v")},
+	}
+	ProjectTrace(&model.Trace{Spans: []*model.Span{span}}, nil)
+	assert.Equal(t, "op", span.OperationName)
+	assert.Len(t, span.Tags, 1)
+}
+
+func TestContextWithSpanFields(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, ContextWithSpanFields(ctx, nil))
+
+	trace := &model.Trace{Spans: []*model.Span{{OperationName: "op", Duration: time.Second}}}
+	projectTrace(ctx, trace)
+	assert.Equal(t, time.Second, trace.Spans[0].Duration)
+
+	projectTrace(ContextWithSpanFields(ctx, SpanFields{SpanFieldOperationName: {}}), trace)
+	assert.Equal(t, "op", trace.Spans[0].OperationName)
+	assert.Zero(t, trace.Spans[0].Duration)
+}
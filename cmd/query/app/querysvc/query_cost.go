@@ -0,0 +1,72 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type queryCostContextKey struct{}
+
+// QueryCost estimates the work done by the storage and the query service for the searches of a request,
+// to help understand the expensive queries.
+type QueryCost struct {
+	// SpansScanned is the number of spans of the traces returned by the storage, before the post-filters.
+	SpansScanned int
+	// TracesExamined is the number of traces returned by the storage, before the post-filters.
+	TracesExamined int
+	// PostFilters is the number of filters applied by the query service to the traces returned by the storage.
+	PostFilters int
+	// ScanBudgetHit reports that the storage returned as many traces as searched, so that more traces
+	// may match the query than were examined.
+	ScanBudgetHit bool
+}
+
+type queryCost struct {
+	mu   sync.Mutex
+	cost QueryCost
+}
+
+// ContextWithQueryCost returns a context in which the query service accumulates the cost of the searches
+// done with it, retrieved with GetQueryCost.
+func ContextWithQueryCost(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryCostContextKey{}, &queryCost{})
+}
+
+// GetQueryCost returns the cost of the searches done with the context, or nil if there was none
+// or the context was not created with ContextWithQueryCost.
+func GetQueryCost(ctx context.Context) *QueryCost {
+	c, ok := ctx.Value(queryCostContextKey{}).(*queryCost)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cost == (QueryCost{}) {
+		return nil
+	}
+	cost := c.cost
+	return &cost
+}
+
+// addQueryCost accumulates the cost of a search returning the traces for the storage query into the context.
+func addQueryCost(ctx context.Context, storageLimit int, traces []*model.Trace, filter TraceFilter) {
+	c, ok := ctx.Value(queryCostContextKey{}).(*queryCost)
+	if !ok {
+		return
+	}
+	spans := 0
+	for _, trace := range traces {
+		spans += len(trace.Spans)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cost.SpansScanned += spans
+	c.cost.TracesExamined += len(traces)
+	c.cost.PostFilters = max(c.cost.PostFilters, filter.count())
+	c.cost.ScanBudgetHit = c.cost.ScanBudgetHit || (storageLimit > 0 && len(traces) >= storageLimit)
+}
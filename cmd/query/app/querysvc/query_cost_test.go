@@ -0,0 +1,64 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func TestQueryCostWithPostFilters(t *testing.T) {
+	tqs := initializeTestService()
+	candidates := []*model.Trace{
+		traceWithError(model.NewTraceID(0, 1), nil, nil),
+		traceWithError(model.NewTraceID(0, 2), []model.KeyValue{model.Bool("error", true)}, nil),
+		traceWithError(model.NewTraceID(0, 3), []model.KeyValue{model.Bool("error", true)}, nil),
+	}
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(candidates, nil).Once()
+
+	ctx := ContextWithQueryCost(context.Background())
+	traces, err := tqs.queryService.FindTracesWithFilter(ctx,
+		&spanstore.TraceQueryParameters{ServiceName: "service", NumTraces: 1},
+		TraceFilter{SpanCount: SpanCountFilter{Min: 2}, Error: ErrorFilter{HasError: true}})
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+	assert.Equal(t, &QueryCost{SpansScanned: 6, TracesExamined: 3, PostFilters: 2}, GetQueryCost(ctx))
+}
+
+func TestQueryCostScanBudgetHit(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		return query.NumTraces == 2
+	})).Return([]*model.Trace{
+		traceWithError(model.NewTraceID(0, 1), nil, nil),
+		traceWithError(model.NewTraceID(0, 2), nil, nil),
+	}, nil).Twice()
+
+	ctx := ContextWithQueryCost(context.Background())
+	for i := 0; i < 2; i++ {
+		_, err := tqs.queryService.FindTraces(ctx, &spanstore.TraceQueryParameters{ServiceName: "service", NumTraces: 2})
+		require.NoError(t, err)
+	}
+	// the costs of the searches of the request are accumulated
+	assert.Equal(t, &QueryCost{SpansScanned: 8, TracesExamined: 4, ScanBudgetHit: true}, GetQueryCost(ctx))
+}
+
+func TestQueryCostEmpty(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+	_, err := tqs.queryService.FindTraces(context.Background(), &spanstore.TraceQueryParameters{ServiceName: "service"})
+	require.NoError(t, err)
+	assert.Nil(t, GetQueryCost(context.Background()))
+
+	ctx := ContextWithQueryCost(context.Background())
+	assert.Nil(t, GetQueryCost(ctx), "the cost is omitted without search")
+}
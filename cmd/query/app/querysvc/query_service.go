@@ -17,12 +17,17 @@ package querysvc
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -30,8 +35,23 @@ import (
 
 var errNoArchiveSpanStorage = errors.New("archive span storage was not configured")
 
+// ErrTooManyTraceIDs is returned by GetTraces when asked for more traces than MaxBatchTraces.
+var ErrTooManyTraceIDs = errors.New("too many trace IDs")
+
+// ErrTraceDeletionUnsupported is returned by DeleteTrace when the span storage cannot delete traces.
+var ErrTraceDeletionUnsupported = errors.New("the span storage does not support deleting traces")
+
+var trimZeroDurationSpans = adjuster.TrimZeroDurationSpans()
+
+// DefaultMaxTraversalSpans is the default of MaxTraversalSpans, generous enough for the largest legitimate traces.
+const DefaultMaxTraversalSpans = 1_000_000
+
 const (
 	defaultMaxClockSkewAdjust = time.Second
+
+	// getTracesParallelism bounds the concurrent GetTrace calls of GetTraces
+	// for the span readers unable to load several traces at once.
+	getTracesParallelism = 10
 )
 
 // QueryServiceOptions has optional members of QueryService
@@ -39,6 +59,49 @@ type QueryServiceOptions struct {
 	ArchiveSpanReader spanstore.Reader
 	ArchiveSpanWriter spanstore.Writer
 	Adjuster          adjuster.Adjuster
+	// MetricsFactory is used to report storage errors by category; metrics are not reported when nil.
+	MetricsFactory metrics.Factory
+	// TraceIDCompatibility enables looking up a 128-bit trace ID that is not found by its lower 64 bits.
+	TraceIDCompatibility bool
+	// Anonymization configures the scrubbing of traces requested with anonymization.
+	Anonymization AnonymizationOptions
+	// Timeouts limits the duration of the storage queries of each endpoint.
+	Timeouts QueryTimeouts
+	// Redaction configures the redaction of the tags of the returned traces.
+	Redaction RedactionOptions
+	// HashKey is the secret key of the HMAC-SHA256 hashing the tag values in anonymized traces and
+	// with the hash redaction strategy. Rotating it changes all the hashes, so that the values hashed
+	// before and after the rotation can no longer be correlated.
+	HashKey []byte
+	// MaxOperations caps the number of operations returned for a service, 0 means no cap.
+	MaxOperations int
+	// MaxBatchTraces caps the number of traces requested at once from GetTraces, 0 means no cap.
+	MaxBatchTraces int
+	// MaxTraceSpans caps the number of spans of the traces returned by GetTrace, 0 means no cap.
+	// The traces of a spanstore.PagedReader stop being fetched when the cap is reached.
+	MaxTraceSpans int
+	// MaxTraversalSpans caps the number of spans visited when following the references of a trace,
+	// e.g. to compute its critical path, a partial result being returned with a warning, 0 means no cap.
+	MaxTraversalSpans int
+	// TrimZeroDurationSpans trims the zero-duration spans without logs from the returned traces by default,
+	// see TrimTrace.
+	TrimZeroDurationSpans bool
+	// TenancyMgr translates the service and operation names of the tenants to the names stored
+	// with their prefix, when a storage prefix is configured.
+	TenancyMgr *tenancy.Manager
+	// SearchGuardrails limits the time window and the number of traces of the searches.
+	SearchGuardrails SearchGuardrails
+	// MaxDependencyLookback caps the lookback of GetDependencies, larger lookbacks being reduced
+	// with a warning, 0 means no cap.
+	MaxDependencyLookback time.Duration
+	// SelfTracing records the accesses to the storage in the self-traces of the queries, see ContextWithSelfTrace.
+	SelfTracing bool
+	// DependenciesCache configures the cache of the dependency graphs returned by GetDependencies.
+	DependenciesCache DependenciesCacheOptions
+	// SearchWidening configures the widening of the time window of the searches finding no traces, see FindTracesWidening.
+	SearchWidening SearchWidening
+	// ReadRetries configures the retries of the storage reads failed with a transient error.
+	ReadRetries ReadRetryOptions
 }
 
 // StorageCapabilities is a feature flag for query service
@@ -50,13 +113,25 @@ type StorageCapabilities struct {
 
 // QueryService contains span utils required by the query-service.
 type QueryService struct {
-	spanReader       spanstore.Reader
-	dependencyReader dependencystore.Reader
-	options          QueryServiceOptions
+	spanReader        spanstore.Reader
+	dependencyReader  dependencystore.Reader
+	options           QueryServiceOptions
+	errorMetrics      *storageErrorMetrics
+	guardrailsMetrics *searchGuardrailsMetrics
+	retryMetrics      *readRetryMetrics
+	anonymizer        *anonymizer
+	redactor          *redactor
+	dependenciesCache *dependenciesCache
 }
 
 // NewQueryService returns a new QueryService.
 func NewQueryService(spanReader spanstore.Reader, dependencyReader dependencystore.Reader, options QueryServiceOptions) *QueryService {
+	if options.SelfTracing {
+		if spanReader != nil {
+			spanReader = selfTracingSpanReader{spanReader: spanReader}
+		}
+		dependencyReader = newSelfTracingDependencyReader(dependencyReader)
+	}
 	qsvc := &QueryService{
 		spanReader:       spanReader,
 		dependencyReader: dependencyReader,
@@ -66,24 +141,182 @@ func NewQueryService(spanReader spanstore.Reader, dependencyReader dependencysto
 	if qsvc.options.Adjuster == nil {
 		qsvc.options.Adjuster = adjuster.Sequence(StandardAdjusters(defaultMaxClockSkewAdjust)...)
 	}
+	if qsvc.options.MetricsFactory == nil {
+		qsvc.options.MetricsFactory = metrics.NullFactory
+	}
+	qsvc.errorMetrics = newStorageErrorMetrics(qsvc.options.MetricsFactory)
+	qsvc.guardrailsMetrics = newSearchGuardrailsMetrics(qsvc.options.MetricsFactory)
+	qsvc.retryMetrics = newReadRetryMetrics(qsvc.options.MetricsFactory)
+	hasher := newTagHasher(qsvc.options.HashKey)
+	qsvc.anonymizer = newAnonymizer(qsvc.options.Anonymization, hasher)
+	qsvc.redactor = newRedactor(qsvc.options.Redaction, hasher)
+	if qsvc.options.DependenciesCache.TTL > 0 {
+		qsvc.dependenciesCache = newDependenciesCache(qsvc.options.DependenciesCache, qsvc.options.MetricsFactory)
+	}
 	return qsvc
 }
 
+// HasSpanReader returns whether the service was created with a span reader, without which every query fails.
+func (qs QueryService) HasSpanReader() bool {
+	return qs.spanReader != nil
+}
+
 // GetTrace is the queryService implementation of spanstore.Reader.GetTrace
 func (qs QueryService) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
-	trace, err := qs.spanReader.GetTrace(ctx, traceID)
+	trace, err := qs.fetchTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	qs.fromStorageTrace(ctx, trace)
+	qs.redact(ctx, trace)
+	projectTrace(ctx, trace)
+	return trace, nil
+}
+
+// GetTraces returns the traces with the given IDs in the same order, with nil for the traces not found.
+// The span reader loads them with a single query when it is a spanstore.BatchReader, otherwise they are
+// fetched concurrently. The traces missing from the batch are looked up like in GetTrace, when the archive
+// storage or the trace ID compatibility could find them.
+func (qs QueryService) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	if maxTraces := qs.options.MaxBatchTraces; maxTraces > 0 && len(traceIDs) > maxTraces {
+		return nil, fmt.Errorf("%w: %d requested, at most %d allowed", ErrTooManyTraceIDs, len(traceIDs), maxTraces)
+	}
+	traces := make([]*model.Trace, len(traceIDs))
+	batched, err := qs.batchGetTraces(ctx, traceIDs, traces)
+	if err != nil {
+		return nil, err
+	}
+	if !batched || qs.options.ArchiveSpanReader != nil || qs.options.TraceIDCompatibility {
+		if err := qs.fetchMissingTraces(ctx, traceIDs, traces); err != nil {
+			return nil, err
+		}
+	}
+	for _, trace := range traces {
+		if trace != nil {
+			qs.fromStorageTrace(ctx, trace)
+			qs.redact(ctx, trace)
+			projectTrace(ctx, trace)
+		}
+	}
+	return traces, nil
+}
+
+// batchGetTraces loads the traces with a single query if the span reader supports it.
+func (qs QueryService) batchGetTraces(ctx context.Context, traceIDs []model.TraceID, traces []*model.Trace) (bool, error) {
+	batchReader, ok := qs.spanReader.(spanstore.BatchReader)
+	if !ok {
+		return false, nil
+	}
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.GetTrace)
+	defer cancel()
+	found, err := batchReader.GetTraces(ctx, traceIDs)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return false, nil
+	}
+	qs.errorMetrics.record(err)
+	if err != nil {
+		return false, err
+	}
+	foundByID := make(map[model.TraceID]*model.Trace, len(found))
+	for _, trace := range found {
+		if len(trace.Spans) > 0 {
+			foundByID[trace.Spans[0].TraceID] = trace
+		}
+	}
+	for i, traceID := range traceIDs {
+		traces[i] = foundByID[traceID]
+	}
+	return true, nil
+}
+
+// fetchMissingTraces looks up the traces still missing one by one, a few at a time.
+func (qs QueryService) fetchMissingTraces(ctx context.Context, traceIDs []model.TraceID, traces []*model.Trace) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, getTracesParallelism)
+	for i, traceID := range traceIDs {
+		if traces[i] != nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, traceID model.TraceID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			trace, err := qs.fetchTrace(ctx, traceID)
+			if err != nil && !errors.Is(err, spanstore.ErrTraceNotFound) {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			traces[i] = trace
+		}(i, traceID)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// fetchTrace reads the trace from the storage without redacting it.
+func (qs QueryService) fetchTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.GetTrace)
+	defer cancel()
+	trace, err := qs.getTrace(ctx, traceID)
+	if errors.Is(err, spanstore.ErrTraceNotFound) && qs.options.TraceIDCompatibility {
+		if altTraceID, ok := compatibleTraceID(traceID); ok {
+			trace, err = qs.getTrace(ctx, altTraceID)
+		}
+	}
+	return trace, err
+}
+
+func (qs QueryService) getTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	trace, err := qs.retryReadTrace(ctx, qs.spanReader, traceID)
 	if errors.Is(err, spanstore.ErrTraceNotFound) {
 		if qs.options.ArchiveSpanReader == nil {
 			return nil, err
 		}
-		trace, err = qs.options.ArchiveSpanReader.GetTrace(ctx, traceID)
+		trace, err = qs.retryReadTrace(ctx, qs.options.ArchiveSpanReader, traceID)
 	}
 	return trace, err
 }
 
+// retryReadTrace reads the trace from the reader, retrying it after a transient error, see ReadRetryOptions.
+func (qs QueryService) retryReadTrace(ctx context.Context, reader spanstore.Reader, traceID model.TraceID) (*model.Trace, error) {
+	return retryRead(ctx, qs.options.ReadRetries, qs.retryMetrics.GetTrace, func(ctx context.Context) (*model.Trace, error) {
+		trace, err := qs.readTrace(ctx, reader, traceID)
+		qs.errorMetrics.record(err)
+		return trace, err
+	})
+}
+
 // GetServices is the queryService implementation of spanstore.Reader.GetServices
 func (qs QueryService) GetServices(ctx context.Context) ([]string, error) {
-	return qs.spanReader.GetServices(ctx)
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.Services)
+	defer cancel()
+	services, err := retryRead(ctx, qs.options.ReadRetries, qs.retryMetrics.GetServices, func(ctx context.Context) ([]string, error) {
+		services, err := qs.spanReader.GetServices(ctx)
+		qs.errorMetrics.record(err)
+		return services, err
+	})
+	return qs.fromStorageServices(ctx, services), err
 }
 
 // GetOperations is the queryService implementation of spanstore.Reader.GetOperations
@@ -91,12 +324,128 @@ func (qs QueryService) GetOperations(
 	ctx context.Context,
 	query spanstore.OperationQueryParameters,
 ) ([]spanstore.Operation, error) {
-	return qs.spanReader.GetOperations(ctx, query)
+	operations, truncated, err := qs.GetOperationsWithLimit(ctx, query, 0)
+	if truncated {
+		AddWarning(ctx, fmt.Sprintf("operations of service %s truncated to %d", query.ServiceName, len(operations)))
+	}
+	return operations, err
+}
+
+// GetOperationsWithLimit returns the operations sorted by name and span kind, keeping at most limit
+// of them, and reports whether the list was truncated. The limit is capped by MaxOperations, 0 means no limit.
+func (qs QueryService) GetOperationsWithLimit(
+	ctx context.Context,
+	query spanstore.OperationQueryParameters,
+	limit int,
+) ([]spanstore.Operation, bool, error) {
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.Operations)
+	defer cancel()
+	query.ServiceName = qs.options.TenancyMgr.ToStorageName(ctx, query.ServiceName)
+	operations, err := retryRead(ctx, qs.options.ReadRetries, qs.retryMetrics.GetOperations, func(ctx context.Context) ([]spanstore.Operation, error) {
+		operations, err := qs.spanReader.GetOperations(ctx, query)
+		qs.errorMetrics.record(err)
+		return operations, err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	qs.fromStorageOperations(ctx, operations)
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].SpanKind < operations[j].SpanKind
+	})
+	if maxOps := qs.options.MaxOperations; maxOps > 0 && (limit <= 0 || limit > maxOps) {
+		limit = maxOps
+	}
+	if limit > 0 && len(operations) > limit {
+		return operations[:limit], true, nil
+	}
+	return operations, false, nil
 }
 
 // FindTraces is the queryService implementation of spanstore.Reader.FindTraces
 func (qs QueryService) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
-	return qs.spanReader.FindTraces(ctx, query)
+	return qs.FindTracesWithFilter(ctx, query, TraceFilter{})
+}
+
+// FindTracesWithSpanCount searches traces like FindTraces and keeps the ones matching the span count filter.
+func (qs QueryService) FindTracesWithSpanCount(ctx context.Context, query *spanstore.TraceQueryParameters, filter SpanCountFilter) ([]*model.Trace, error) {
+	return qs.FindTracesWithFilter(ctx, query, TraceFilter{SpanCount: filter})
+}
+
+// FindTracesWithFilter searches traces like FindTraces and keeps the ones matching the filter.
+// Since few storage backends index the criteria of the filter, more candidate traces are searched
+// and filtered before being truncated to query.NumTraces.
+// The search guardrails are applied to the query first, a rejected query returning ErrSearchRejected.
+func (qs QueryService) FindTracesWithFilter(ctx context.Context, query *spanstore.TraceQueryParameters, filter TraceFilter) ([]*model.Trace, error) {
+	query, err := qs.applySearchGuardrails(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return qs.searchTraces(ctx, query, filter)
+}
+
+// searchTraces searches the traces of the query, to which the search guardrails are applied, keeping the ones
+// matching the filter.
+func (qs QueryService) searchTraces(ctx context.Context, query *spanstore.TraceQueryParameters, filter TraceFilter) ([]*model.Trace, error) {
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.FindTraces)
+	defer cancel()
+	storageQuery := qs.toStorageQuery(ctx, query)
+	if filter.enabled() && query.NumTraces > 0 {
+		candidatesQuery := *storageQuery
+		candidatesQuery.NumTraces = query.NumTraces * filterCandidatesFactor
+		storageQuery = &candidatesQuery
+	}
+	retries := qs.options.ReadRetries
+	if !retries.FindTraces {
+		retries.MaxAttempts = 1
+	}
+	traces, err := retryRead(ctx, retries, qs.retryMetrics.FindTraces, func(ctx context.Context) ([]*model.Trace, error) {
+		traces, err := qs.spanReader.FindTraces(ctx, storageQuery)
+		qs.errorMetrics.record(err)
+		return traces, err
+	})
+	addQueryCost(ctx, storageQuery.NumTraces, traces, filter)
+	traces = dedupeTraces(traces)
+	if filter.enabled() {
+		traces = filter.apply(traces)
+	}
+	if query.NumTraces > 0 && len(traces) > query.NumTraces {
+		traces = traces[:query.NumTraces]
+	}
+	for _, trace := range traces {
+		qs.fromStorageTrace(ctx, trace)
+		qs.redact(ctx, trace)
+		projectTrace(ctx, trace)
+	}
+	return traces, err
+}
+
+// dedupeTraces drops the traces already returned with the same ID, which some storage backends
+// return more than once across shard boundaries, keeping the first occurrence.
+func dedupeTraces(traces []*model.Trace) []*model.Trace {
+	seen := make(map[model.TraceID]struct{}, len(traces))
+	deduped := traces[:0]
+	for _, trace := range traces {
+		if len(trace.Spans) > 0 {
+			traceID := trace.Spans[0].TraceID
+			if _, ok := seen[traceID]; ok {
+				continue
+			}
+			seen[traceID] = struct{}{}
+		}
+		deduped = append(deduped, trace)
+	}
+	return deduped
+}
+
+// redact applies the redaction rules to the trace, unless the subject of the request is allowed to see it unredacted.
+func (qs QueryService) redact(ctx context.Context, trace *model.Trace) {
+	if qs.redactor != nil && !qs.redactor.allowsUnredacted(ctx) {
+		qs.redactor.redactTrace(trace)
+	}
 }
 
 // ArchiveTrace is the queryService utility to archive traces.
@@ -104,7 +453,8 @@ func (qs QueryService) ArchiveTrace(ctx context.Context, traceID model.TraceID)
 	if qs.options.ArchiveSpanWriter == nil {
 		return errNoArchiveSpanStorage
 	}
-	trace, err := qs.GetTrace(ctx, traceID)
+	// the archived trace is not redacted, since redaction only applies to the returned traces
+	trace, err := qs.fetchTrace(ctx, traceID)
 	if err != nil {
 		return err
 	}
@@ -119,14 +469,119 @@ func (qs QueryService) ArchiveTrace(ctx context.Context, traceID model.TraceID)
 	return errors.Join(writeErrors...)
 }
 
+// DeleteTrace deletes the trace from the span storage, and from the archive storage if it supports it.
+func (qs QueryService) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	deleter, ok := qs.spanReader.(spanstore.TraceDeleter)
+	if !ok {
+		return ErrTraceDeletionUnsupported
+	}
+	err := deleter.DeleteTrace(ctx, traceID)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return ErrTraceDeletionUnsupported
+	}
+	qs.errorMetrics.record(err)
+	if err != nil {
+		return err
+	}
+	if archiveDeleter, ok := qs.options.ArchiveSpanReader.(spanstore.TraceDeleter); ok {
+		err := archiveDeleter.DeleteTrace(ctx, traceID)
+		if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+			return err
+		}
+	}
+	return nil
+}
+
 // Adjust applies adjusters to the trace.
 func (qs QueryService) Adjust(trace *model.Trace) (*model.Trace, error) {
 	return qs.options.Adjuster.Adjust(trace)
 }
 
+// AnonymizationEnabled returns true if traces can be anonymized with AnonymizeTrace.
+func (qs QueryService) AnonymizationEnabled() bool {
+	return qs.anonymizer != nil
+}
+
+// AnonymizeTrace scrubs personal data from the trace in place, as configured in AnonymizationOptions.
+func (qs QueryService) AnonymizeTrace(trace *model.Trace) error {
+	if qs.anonymizer == nil {
+		return ErrAnonymizationDisabled
+	}
+	qs.anonymizer.anonymizeTrace(trace)
+	return nil
+}
+
+// TrimZeroDurationSpansByDefault returns true if the traces are trimmed with TrimTrace
+// unless requested otherwise.
+func (qs QueryService) TrimZeroDurationSpansByDefault() bool {
+	return qs.options.TrimZeroDurationSpans
+}
+
+// TrimTrace removes the zero-duration spans without logs from the trace, e.g. the placeholder spans
+// of some instrumentations, re-parenting their children so that the trace remains connected.
+func (QueryService) TrimTrace(trace *model.Trace) *model.Trace {
+	trace, _ = trimZeroDurationSpans.Adjust(trace)
+	return trace
+}
+
+// GetCriticalPath returns the IDs of the spans on the critical path of the trace, see CriticalPath.
+// The trace is adjusted first, so that the path is computed from the corrected span timings.
+func (qs QueryService) GetCriticalPath(ctx context.Context, traceID model.TraceID) ([]model.SpanID, error) {
+	trace, err := qs.GetTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	// adjusters return a usable trace even when they report problems with it
+	trace, _ = qs.Adjust(trace)
+	return segmentSpanIDs(qs.criticalPath(ctx, trace)), nil
+}
+
+// CompareTrace compares the trace with the profile, see CompareTraceProfile.
+func (qs QueryService) CompareTrace(ctx context.Context, traceID model.TraceID, profile TraceProfile) (*ProfileComparison, error) {
+	trace, err := qs.GetTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	// adjusters return a usable trace even when they report problems with it
+	trace, _ = qs.Adjust(trace)
+	return compareTraceProfile(trace, profile, segmentSpanIDs(qs.criticalPath(ctx, trace))), nil
+}
+
 // GetDependencies implements dependencystore.Reader.GetDependencies
+// When the dependencies cache is enabled, the dependency graph may be served from it.
 func (qs QueryService) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
-	return qs.dependencyReader.GetDependencies(ctx, endTs, lookback)
+	lookback = qs.clampDependencyLookback(ctx, lookback)
+	var (
+		dependencies []model.DependencyLink
+		err          error
+	)
+	if qs.dependenciesCache != nil {
+		dependencies, err = qs.dependenciesCache.get(ctx, endTs, lookback, qs.readDependencies)
+	} else {
+		dependencies, err = qs.readDependencies(ctx, endTs, lookback)
+	}
+	return qs.fromStorageDependencies(ctx, dependencies), err
+}
+
+// readDependencies reads the dependencies from the dependency storage, within the Dependencies timeout.
+func (qs QueryService) readDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.Dependencies)
+	defer cancel()
+	return retryRead(ctx, qs.options.ReadRetries, qs.retryMetrics.GetDependencies, func(ctx context.Context) ([]model.DependencyLink, error) {
+		dependencies, err := qs.dependencyReader.GetDependencies(ctx, endTs, lookback)
+		qs.errorMetrics.record(err)
+		return dependencies, err
+	})
+}
+
+// clampDependencyLookback reduces the lookback to MaxDependencyLookback, reporting it as a warning of the request.
+func (qs QueryService) clampDependencyLookback(ctx context.Context, lookback time.Duration) time.Duration {
+	maxLookback := qs.options.MaxDependencyLookback
+	if maxLookback <= 0 || lookback <= maxLookback {
+		return lookback
+	}
+	AddWarning(ctx, fmt.Sprintf("dependencies lookback of %s reduced to the maximum of %s", lookback, maxLookback))
+	return maxLookback
 }
 
 // GetCapabilities returns the features supported by the query service.
@@ -17,6 +17,7 @@ package querysvc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
@@ -68,6 +70,9 @@ type testQueryService struct {
 
 	archiveSpanReader *spanstoremocks.Reader
 	archiveSpanWriter *spanstoremocks.Writer
+
+	batchReader bool
+	pagedReader bool
 }
 
 type testOption func(*testQueryService, *QueryServiceOptions)
@@ -111,10 +116,38 @@ func initializeTestService(optionAppliers ...testOption) *testQueryService {
 		optApplier(&tqs, &options)
 	}
 
-	tqs.queryService = NewQueryService(readStorage, dependencyStorage, options)
+	var spanReader spanstore.Reader = readStorage
+	if tqs.batchReader {
+		spanReader = batchReader{readStorage}
+	}
+	if tqs.pagedReader {
+		spanReader = pagedReader{readStorage}
+	}
+	tqs.queryService = NewQueryService(spanReader, dependencyStorage, options)
 	return &tqs
 }
 
+// batchReader is a span reader implementing spanstore.BatchReader.
+type batchReader struct {
+	*spanstoremocks.Reader
+}
+
+func (r batchReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	args := r.Called(ctx, traceIDs)
+	traces, _ := args.Get(0).([]*model.Trace)
+	return traces, args.Error(1)
+}
+
+func withBatchReader() testOption {
+	return func(tqs *testQueryService, _ *QueryServiceOptions) {
+		tqs.batchReader = true
+	}
+}
+
+func traceWithID(traceID model.TraceID) *model.Trace {
+	return &model.Trace{Spans: []*model.Span{{TraceID: traceID, SpanID: model.NewSpanID(1), Process: &model.Process{}}}}
+}
+
 // Test QueryService.GetTrace()
 func TestGetTraceSuccess(t *testing.T) {
 	tqs := initializeTestService()
@@ -155,6 +188,152 @@ func TestGetTraceFromArchiveStorage(t *testing.T) {
 	assert.Equal(t, res, mockTrace)
 }
 
+// Test QueryService.GetTrace() with trace ID compatibility enabled
+func TestGetTraceIDCompatibility(t *testing.T) {
+	longTraceID := model.NewTraceID(1, 123456)
+	testCases := []struct {
+		name          string
+		compatibility bool
+		traceID       model.TraceID
+		expectLookups []model.TraceID
+		expectFound   bool
+	}{
+		{
+			name:          "128-bit ID found by lower 64 bits",
+			compatibility: true,
+			traceID:       longTraceID,
+			expectLookups: []model.TraceID{longTraceID, mockTraceID},
+			expectFound:   true,
+		},
+		{
+			name:          "compatibility disabled",
+			traceID:       longTraceID,
+			expectLookups: []model.TraceID{longTraceID},
+		},
+		{
+			name:          "64-bit ID has no alternative",
+			compatibility: true,
+			traceID:       model.NewTraceID(0, 42),
+			expectLookups: []model.TraceID{model.NewTraceID(0, 42)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+				options.TraceIDCompatibility = tc.compatibility
+			})
+			tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(mockTrace, nil).Maybe()
+			tqs.spanReader.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
+				Return(nil, spanstore.ErrTraceNotFound)
+
+			res, err := tqs.queryService.GetTrace(context.Background(), tc.traceID)
+			if tc.expectFound {
+				require.NoError(t, err)
+				assert.Equal(t, mockTrace, res)
+			} else {
+				require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+			}
+			var lookups []model.TraceID
+			for _, call := range tqs.spanReader.Calls {
+				lookups = append(lookups, call.Arguments.Get(1).(model.TraceID))
+			}
+			assert.Equal(t, tc.expectLookups, lookups)
+		})
+	}
+}
+
+func TestAnonymizeTraceOption(t *testing.T) {
+	tqs := initializeTestService()
+	assert.False(t, tqs.queryService.AnonymizationEnabled())
+	require.ErrorIs(t, tqs.queryService.AnonymizeTrace(makeAnonymizationTrace()), ErrAnonymizationDisabled)
+
+	tqs = initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.Anonymization = AnonymizationOptions{Enabled: true, HashedTags: []string{"user.id"}}
+		options.HashKey = testHashKey
+	})
+	assert.True(t, tqs.queryService.AnonymizationEnabled())
+	trace := makeAnonymizationTrace()
+	require.NoError(t, tqs.queryService.AnonymizeTrace(trace))
+	assert.Equal(t, model.String("user.id", hash("alice")), trace.Spans[0].Tags[0])
+}
+
+func TestGetTraces(t *testing.T) {
+	traceIDs := []model.TraceID{model.NewTraceID(0, 3), model.NewTraceID(0, 1), model.NewTraceID(0, 2)}
+	t.Run("batch", func(t *testing.T) {
+		tqs := initializeTestService(withBatchReader())
+		tqs.spanReader.On("GetTraces", mock.Anything, traceIDs).
+			Return([]*model.Trace{traceWithID(traceIDs[1]), traceWithID(traceIDs[0])}, nil).Once()
+
+		traces, err := tqs.queryService.GetTraces(context.Background(), traceIDs)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Trace{traceWithID(traceIDs[0]), traceWithID(traceIDs[1]), nil}, traces)
+		tqs.spanReader.AssertNotCalled(t, "GetTrace", mock.Anything, mock.Anything)
+	})
+	t.Run("batch with archive", func(t *testing.T) {
+		tqs := initializeTestService(withBatchReader(), withArchiveSpanReader())
+		tqs.spanReader.On("GetTraces", mock.Anything, traceIDs).
+			Return([]*model.Trace{traceWithID(traceIDs[1])}, nil).Once()
+		tqs.spanReader.On("GetTrace", mock.Anything, mock.Anything).Return(nil, spanstore.ErrTraceNotFound)
+		tqs.archiveSpanReader.On("GetTrace", mock.Anything, traceIDs[0]).Return(traceWithID(traceIDs[0]), nil).Once()
+		tqs.archiveSpanReader.On("GetTrace", mock.Anything, traceIDs[2]).Return(nil, spanstore.ErrTraceNotFound).Once()
+
+		traces, err := tqs.queryService.GetTraces(context.Background(), traceIDs)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Trace{traceWithID(traceIDs[0]), traceWithID(traceIDs[1]), nil}, traces)
+	})
+	t.Run("fallback", func(t *testing.T) {
+		tqs := initializeTestService()
+		tqs.spanReader.On("GetTrace", mock.Anything, traceIDs[0]).Return(traceWithID(traceIDs[0]), nil).Once()
+		tqs.spanReader.On("GetTrace", mock.Anything, traceIDs[1]).Return(nil, spanstore.ErrTraceNotFound).Once()
+		tqs.spanReader.On("GetTrace", mock.Anything, traceIDs[2]).Return(traceWithID(traceIDs[2]), nil).Once()
+
+		traces, err := tqs.queryService.GetTraces(context.Background(), traceIDs)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Trace{traceWithID(traceIDs[0]), nil, traceWithID(traceIDs[2])}, traces)
+	})
+	t.Run("unsupported batch", func(t *testing.T) {
+		tqs := initializeTestService(withBatchReader())
+		tqs.spanReader.On("GetTraces", mock.Anything, traceIDs).Return(nil, errors.ErrUnsupported).Once()
+		tqs.spanReader.On("GetTrace", mock.Anything, mock.Anything).Return(nil, spanstore.ErrTraceNotFound).Times(3)
+
+		traces, err := tqs.queryService.GetTraces(context.Background(), traceIDs)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Trace{nil, nil, nil}, traces)
+	})
+}
+
+func TestGetTracesErrors(t *testing.T) {
+	traceIDs := []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}
+	t.Run("too many trace IDs", func(t *testing.T) {
+		tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+			options.MaxBatchTraces = 1
+		})
+		_, err := tqs.queryService.GetTraces(context.Background(), traceIDs)
+		require.ErrorIs(t, err, ErrTooManyTraceIDs)
+	})
+	t.Run("batch storage error", func(t *testing.T) {
+		tqs := initializeTestService(withBatchReader())
+		tqs.spanReader.On("GetTraces", mock.Anything, traceIDs).Return(nil, assert.AnError).Once()
+		_, err := tqs.queryService.GetTraces(context.Background(), traceIDs)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+	t.Run("storage error", func(t *testing.T) {
+		tqs := initializeTestService()
+		tqs.spanReader.On("GetTrace", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+		_, err := tqs.queryService.GetTraces(context.Background(), traceIDs)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+	t.Run("deadline exceeded", func(t *testing.T) {
+		tqs := initializeTestService()
+		tqs.spanReader.On("GetTrace", mock.Anything, mock.Anything).Run(waitForDeadline).
+			Return(nil, spanstore.ErrTraceNotFound)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := tqs.queryService.GetTraces(ctx, traceIDs)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
 // Test QueryService.GetServices() for success.
 func TestGetServices(t *testing.T) {
 	tqs := initializeTestService()
@@ -186,6 +365,76 @@ func TestGetOperations(t *testing.T) {
 	assert.Equal(t, expectedOperations, actualOperations)
 }
 
+func withMaxOperations(maxOperations int) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MaxOperations = maxOperations
+	}
+}
+
+func TestGetOperationsWithLimit(t *testing.T) {
+	operations := []spanstore.Operation{
+		{Name: "get", SpanKind: "server"},
+		{Name: "delete", SpanKind: "server"},
+		{Name: "get", SpanKind: "client"},
+		{Name: "post", SpanKind: "server"},
+	}
+	sorted := []spanstore.Operation{
+		{Name: "delete", SpanKind: "server"},
+		{Name: "get", SpanKind: "client"},
+		{Name: "get", SpanKind: "server"},
+		{Name: "post", SpanKind: "server"},
+	}
+	tests := []struct {
+		name          string
+		maxOperations int
+		limit         int
+		expected      []spanstore.Operation
+		truncated     bool
+	}{
+		{name: "no limit", expected: sorted},
+		{name: "limit", limit: 2, expected: sorted[:2], truncated: true},
+		{name: "limit above count", limit: 10, expected: sorted},
+		{name: "cap", maxOperations: 3, expected: sorted[:3], truncated: true},
+		{name: "limit below cap", maxOperations: 3, limit: 1, expected: sorted[:1], truncated: true},
+		{name: "limit above cap", maxOperations: 3, limit: 10, expected: sorted[:3], truncated: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tqs := initializeTestService(withMaxOperations(test.maxOperations))
+			tqs.spanReader.On("GetOperations", mock.Anything, mock.Anything).
+				Return(append([]spanstore.Operation(nil), operations...), nil).Once()
+
+			actual, truncated, err := tqs.queryService.GetOperationsWithLimit(context.Background(),
+				spanstore.OperationQueryParameters{ServiceName: "svc"}, test.limit)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+			assert.Equal(t, test.truncated, truncated)
+		})
+	}
+}
+
+func TestGetOperationsTruncatedWarning(t *testing.T) {
+	tqs := initializeTestService(withMaxOperations(1))
+	tqs.spanReader.On("GetOperations", mock.Anything, mock.Anything).
+		Return([]spanstore.Operation{{Name: "b"}, {Name: "a"}}, nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	actual, err := tqs.queryService.GetOperations(ctx, spanstore.OperationQueryParameters{ServiceName: "svc"})
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{{Name: "a"}}, actual)
+	assert.Equal(t, []string{"operations of service svc truncated to 1"}, GetWarnings(ctx))
+}
+
+func TestGetOperationsError(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetOperations", mock.Anything, mock.Anything).
+		Return(nil, assert.AnError).Once()
+
+	_, truncated, err := tqs.queryService.GetOperationsWithLimit(context.Background(), spanstore.OperationQueryParameters{}, 1)
+	require.ErrorIs(t, err, assert.AnError)
+	assert.False(t, truncated)
+}
+
 // Test QueryService.FindTraces() for success.
 func TestFindTraces(t *testing.T) {
 	tqs := initializeTestService()
@@ -207,6 +456,201 @@ func TestFindTraces(t *testing.T) {
 	assert.Len(t, traces, 1)
 }
 
+func TestFindTracesDeduplicated(t *testing.T) {
+	trace1 := traceWithSpans(model.NewTraceID(0, 1), 1)
+	trace2 := traceWithSpans(model.NewTraceID(0, 2), 2)
+	trace3 := traceWithSpans(model.NewTraceID(0, 3), 1)
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{trace1, traceWithSpans(model.NewTraceID(0, 1), 3), trace2, trace1, trace3}, nil).Once()
+
+	traces, err := tqs.queryService.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName:  "service",
+		StartTimeMax: time.Now(),
+		NumTraces:    2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{trace1, trace2}, traces)
+}
+
+// traceWithSpans returns a trace of n spans, alternating between two services.
+func traceWithSpans(traceID model.TraceID, n int) *model.Trace {
+	trace := &model.Trace{}
+	for i := 0; i < n; i++ {
+		process := &model.Process{ServiceName: "service"}
+		if i%2 == 1 {
+			process.ServiceName = "downstream"
+		}
+		trace.Spans = append(trace.Spans, &model.Span{TraceID: traceID, SpanID: model.NewSpanID(uint64(i + 1)), Process: process})
+	}
+	return trace
+}
+
+func TestFindTracesWithSpanCount(t *testing.T) {
+	candidates := func() []*model.Trace {
+		return []*model.Trace{
+			traceWithSpans(model.NewTraceID(0, 1), 1),
+			traceWithSpans(model.NewTraceID(0, 2), 5),
+			traceWithSpans(model.NewTraceID(0, 3), 3),
+			traceWithSpans(model.NewTraceID(0, 4), 8),
+			traceWithSpans(model.NewTraceID(0, 5), 4),
+		}
+	}
+	tests := []struct {
+		name              string
+		filter            SpanCountFilter
+		numTraces         int
+		expectedNumTraces int
+		expectedIDs       []uint64
+	}{
+		{name: "no filter", numTraces: 5, expectedNumTraces: 5, expectedIDs: []uint64{1, 2, 3, 4, 5}},
+		{name: "min", filter: SpanCountFilter{Min: 4}, numTraces: 5, expectedNumTraces: 50, expectedIDs: []uint64{2, 4, 5}},
+		{name: "max", filter: SpanCountFilter{Max: 3}, numTraces: 5, expectedNumTraces: 50, expectedIDs: []uint64{1, 3}},
+		{name: "min and max", filter: SpanCountFilter{Min: 3, Max: 5}, numTraces: 5, expectedNumTraces: 50, expectedIDs: []uint64{2, 3, 5}},
+		{name: "filter then truncate", filter: SpanCountFilter{Min: 4}, numTraces: 2, expectedNumTraces: 20, expectedIDs: []uint64{2, 4}},
+		{name: "default limit", filter: SpanCountFilter{Min: 5}, expectedIDs: []uint64{2, 4}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tqs := initializeTestService()
+			tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+				return q.NumTraces == test.expectedNumTraces
+			})).Return(candidates(), nil).Once()
+
+			query := &spanstore.TraceQueryParameters{ServiceName: "service", NumTraces: test.numTraces}
+			traces, err := tqs.queryService.FindTracesWithSpanCount(context.Background(), query, test.filter)
+			require.NoError(t, err)
+			ids := make([]uint64, len(traces))
+			for i, trace := range traces {
+				ids[i] = trace.Spans[0].TraceID.Low
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+			assert.Equal(t, test.numTraces, query.NumTraces, "the query of the caller is not modified")
+		})
+	}
+}
+
+// traceWithError returns a trace of a root span and a child span, tagged with the given tags.
+func traceWithError(traceID model.TraceID, rootTags, childTags []model.KeyValue) *model.Trace {
+	root := &model.Span{TraceID: traceID, SpanID: model.NewSpanID(1), Tags: rootTags}
+	child := &model.Span{
+		TraceID:    traceID,
+		SpanID:     model.NewSpanID(2),
+		References: []model.SpanRef{model.NewChildOfRef(traceID, root.SpanID)},
+		Tags:       childTags,
+	}
+	return &model.Trace{Spans: []*model.Span{root, child}}
+}
+
+func TestFindTracesWithErrorFilter(t *testing.T) {
+	candidates := func() []*model.Trace {
+		return []*model.Trace{
+			traceWithError(model.NewTraceID(0, 1), nil, nil),
+			traceWithError(model.NewTraceID(0, 2), nil, []model.KeyValue{model.Bool("error", true)}),
+			traceWithError(model.NewTraceID(0, 3), []model.KeyValue{model.String("otel.status_code", "ERROR")}, nil),
+			traceWithError(model.NewTraceID(0, 4), []model.KeyValue{model.String("error", "true")}, nil),
+			traceWithError(model.NewTraceID(0, 5), []model.KeyValue{model.Bool("error", false), model.String("otel.status_code", "OK")}, nil),
+		}
+	}
+	tests := []struct {
+		name        string
+		filter      ErrorFilter
+		expectedIDs []uint64
+	}{
+		{name: "no filter", expectedIDs: []uint64{1, 2, 3, 4, 5}},
+		{name: "has error", filter: ErrorFilter{HasError: true}, expectedIDs: []uint64{2, 3, 4}},
+		{name: "root span only", filter: ErrorFilter{HasError: true, RootSpanOnly: true}, expectedIDs: []uint64{3, 4}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tqs := initializeTestService()
+			tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(candidates(), nil).Once()
+
+			query := &spanstore.TraceQueryParameters{ServiceName: "service", NumTraces: 5}
+			traces, err := tqs.queryService.FindTracesWithFilter(context.Background(), query, TraceFilter{Error: test.filter})
+			require.NoError(t, err)
+			ids := make([]uint64, len(traces))
+			for i, trace := range traces {
+				ids[i] = trace.Spans[0].TraceID.Low
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+		})
+	}
+}
+
+func TestFindTracesWithSpanCountAndErrorFilter(t *testing.T) {
+	tqs := initializeTestService()
+	small := traceWithError(model.NewTraceID(0, 1), []model.KeyValue{model.Bool("error", true)}, nil)
+	large := traceWithError(model.NewTraceID(0, 2), []model.KeyValue{model.Bool("error", true)}, nil)
+	large.Spans = append(large.Spans, &model.Span{TraceID: large.Spans[0].TraceID, SpanID: model.NewSpanID(3)})
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{small, large}, nil).Once()
+
+	traces, err := tqs.queryService.FindTracesWithFilter(context.Background(),
+		&spanstore.TraceQueryParameters{ServiceName: "service"},
+		TraceFilter{SpanCount: SpanCountFilter{Min: 3}, Error: ErrorFilter{HasError: true}})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{large}, traces)
+}
+
+// traceWithServices returns a trace whose spans are of the services, each span being the child of the previous one.
+func traceWithServices(traceID model.TraceID, services ...string) *model.Trace {
+	trace := &model.Trace{}
+	for i, service := range services {
+		span := &model.Span{TraceID: traceID, SpanID: model.NewSpanID(uint64(i + 1)), Process: model.NewProcess(service, nil)}
+		if i > 0 {
+			span.References = []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(uint64(i)))}
+		}
+		trace.Spans = append(trace.Spans, span)
+	}
+	return trace
+}
+
+func TestFindTracesWithServiceEdgeFilter(t *testing.T) {
+	// the frontend calls the database, which is not instrumented
+	withPeerService := traceWithServices(model.NewTraceID(0, 4), "frontend", "driver")
+	withPeerService.Spans[1].Tags = []model.KeyValue{model.String("peer.service", "mysql")}
+	candidates := []*model.Trace{
+		traceWithServices(model.NewTraceID(0, 1), "frontend", "driver", "redis"),
+		// the driver service is called, but by the route service
+		traceWithServices(model.NewTraceID(0, 2), "frontend", "route", "driver"),
+		traceWithServices(model.NewTraceID(0, 3), "frontend", "driver"),
+		withPeerService,
+	}
+	tests := []struct {
+		name        string
+		filter      ServiceEdgeFilter
+		expectedIDs []uint64
+	}{
+		{name: "child span of the callee", filter: ServiceEdgeFilter{CallerService: "frontend", CalleeService: "driver"}, expectedIDs: []uint64{1, 3, 4}},
+		{name: "deeper edge", filter: ServiceEdgeFilter{CallerService: "route", CalleeService: "driver"}, expectedIDs: []uint64{2}},
+		{name: "peer service", filter: ServiceEdgeFilter{CallerService: "driver", CalleeService: "mysql"}, expectedIDs: []uint64{4}},
+		{name: "reversed edge", filter: ServiceEdgeFilter{CallerService: "driver", CalleeService: "frontend"}, expectedIDs: []uint64{}},
+		{name: "caller only", filter: ServiceEdgeFilter{CallerService: "route"}, expectedIDs: []uint64{1, 2, 3, 4}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tqs := initializeTestService()
+			numTraces := 0
+			tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				numTraces = args.Get(1).(*spanstore.TraceQueryParameters).NumTraces
+			}).Return(append([]*model.Trace(nil), candidates...), nil).Once()
+
+			query := &spanstore.TraceQueryParameters{ServiceName: "frontend", NumTraces: 5}
+			traces, err := tqs.queryService.FindTracesWithFilter(context.Background(), query, TraceFilter{ServiceEdge: test.filter})
+			require.NoError(t, err)
+			ids := make([]uint64, len(traces))
+			for i, trace := range traces {
+				ids[i] = trace.Spans[0].TraceID.Low
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+			if test.filter.enabled() {
+				// more candidate traces are searched to make up for the traces filtered out
+				assert.Equal(t, 5*filterCandidatesFactor, numTraces)
+			}
+		})
+	}
+}
+
 // Test QueryService.ArchiveTrace() with no ArchiveSpanWriter.
 func TestArchiveTraceNoOptions(t *testing.T) {
 	tqs := initializeTestService()
@@ -260,6 +704,61 @@ func TestArchiveTraceSuccess(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// traceDeleter is a span reader implementing spanstore.TraceDeleter.
+type traceDeleter struct {
+	*spanstoremocks.Reader
+}
+
+func (r traceDeleter) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	return r.Called(ctx, traceID).Error(0)
+}
+
+func TestDeleteTrace(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("DeleteTrace", mock.Anything, mockTraceID).Return(nil).Once()
+	archiveReader := &spanstoremocks.Reader{}
+	archiveReader.On("DeleteTrace", mock.Anything, mockTraceID).Return(nil).Once()
+	qs := NewQueryService(traceDeleter{reader}, &depsmocks.Reader{}, QueryServiceOptions{
+		ArchiveSpanReader: traceDeleter{archiveReader},
+	})
+
+	require.NoError(t, qs.DeleteTrace(context.Background(), mockTraceID))
+	reader.AssertExpectations(t)
+	archiveReader.AssertExpectations(t)
+}
+
+func TestDeleteTraceUnsupported(t *testing.T) {
+	tqs := initializeTestService()
+	err := tqs.queryService.DeleteTrace(context.Background(), mockTraceID)
+	require.ErrorIs(t, err, ErrTraceDeletionUnsupported)
+
+	reader := &spanstoremocks.Reader{}
+	reader.On("DeleteTrace", mock.Anything, mockTraceID).Return(errors.ErrUnsupported).Once()
+	qs := NewQueryService(traceDeleter{reader}, &depsmocks.Reader{}, QueryServiceOptions{})
+	err = qs.DeleteTrace(context.Background(), mockTraceID)
+	require.ErrorIs(t, err, ErrTraceDeletionUnsupported)
+}
+
+func TestDeleteTraceErrors(t *testing.T) {
+	reader := &spanstoremocks.Reader{}
+	reader.On("DeleteTrace", mock.Anything, mockTraceID).Return(errors.New("storage error")).Once()
+	qs := NewQueryService(traceDeleter{reader}, &depsmocks.Reader{}, QueryServiceOptions{})
+	require.EqualError(t, qs.DeleteTrace(context.Background(), mockTraceID), "storage error")
+
+	// the archive storage is not a trace deleter, or does not support deletions
+	reader.On("DeleteTrace", mock.Anything, mockTraceID).Return(nil)
+	archiveReader := &spanstoremocks.Reader{}
+	archiveReader.On("DeleteTrace", mock.Anything, mockTraceID).Return(errors.ErrUnsupported).Once()
+	for _, archive := range []spanstore.Reader{&spanstoremocks.Reader{}, traceDeleter{archiveReader}} {
+		qs = NewQueryService(traceDeleter{reader}, &depsmocks.Reader{}, QueryServiceOptions{ArchiveSpanReader: archive})
+		require.NoError(t, qs.DeleteTrace(context.Background(), mockTraceID))
+	}
+
+	archiveReader.On("DeleteTrace", mock.Anything, mockTraceID).Return(errors.New("archive error")).Once()
+	qs = NewQueryService(traceDeleter{reader}, &depsmocks.Reader{}, QueryServiceOptions{ArchiveSpanReader: traceDeleter{archiveReader}})
+	require.EqualError(t, qs.DeleteTrace(context.Background(), mockTraceID), "archive error")
+}
+
 // Test QueryService.Adjust()
 func TestTraceAdjustmentFailure(t *testing.T) {
 	tqs := initializeTestService(withAdjuster())
@@ -291,6 +790,27 @@ func TestGetDependencies(t *testing.T) {
 	assert.Equal(t, expectedDependencies, actualDependencies)
 }
 
+func TestGetDependenciesMaxLookback(t *testing.T) {
+	const maxLookback = 7 * 24 * time.Hour
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MaxDependencyLookback = maxLookback
+	})
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	tqs.depsReader.On("GetDependencies", mock.Anything, endTs, maxLookback).Return([]model.DependencyLink{}, nil).Once()
+	tqs.depsReader.On("GetDependencies", mock.Anything, endTs, time.Hour).Return([]model.DependencyLink{}, nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	_, err := tqs.queryService.GetDependencies(ctx, endTs, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dependencies lookback of 720h0m0s reduced to the maximum of 168h0m0s"}, GetWarnings(ctx))
+
+	ctx = ContextWithWarnings(context.Background())
+	_, err = tqs.queryService.GetDependencies(ctx, endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, GetWarnings(ctx))
+	tqs.depsReader.AssertExpectations(t)
+}
+
 // Test QueryService.GetCapacities()
 func TestGetCapabilities(t *testing.T) {
 	tqs := initializeTestService()
@@ -373,3 +893,26 @@ func TestInitArchiveStorage(t *testing.T) {
 func TestMain(m *testing.M) {
 	testutils.VerifyGoLeaks(m)
 }
+
+func TestStorageErrorMetrics(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MetricsFactory = metricsFactory
+	})
+	tqs.spanReader.On("GetServices", mock.Anything).
+		Return(nil, fmt.Errorf("query failed: %w", context.DeadlineExceeded)).Once()
+	tqs.spanReader.On("GetTrace", mock.Anything, mock.AnythingOfType("model.TraceID")).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	_, err := tqs.queryService.GetServices(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = tqs.queryService.GetTrace(context.Background(), mockTraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "storage_errors", Tags: map[string]string{"category": "timeout"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage_errors", Tags: map[string]string{"category": "not_found"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage_errors", Tags: map[string]string{"category": "unavailable"}, Value: 0},
+	)
+}
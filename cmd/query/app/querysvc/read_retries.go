@@ -0,0 +1,67 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+// ReadRetryOptions configures the retries of the storage reads failed with a transient error,
+// e.g. a timeout of the storage or its unavailability, see storage.IsTransientError.
+// The traces, services, operations and dependencies reads are retried, and the searches of traces
+// only if FindTraces is set, as they are expensive.
+type ReadRetryOptions struct {
+	// MaxAttempts is the maximum number of attempts of a read, 0 or 1 disabling the retries.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled before each next retry.
+	Backoff time.Duration
+	// FindTraces also retries the searches of traces.
+	FindTraces bool
+}
+
+// readRetryMetrics counts the retries of the storage reads, by operation.
+type readRetryMetrics struct {
+	GetTrace        metrics.Counter `metric:"storage_read_retries" tags:"operation=get_trace"`
+	GetServices     metrics.Counter `metric:"storage_read_retries" tags:"operation=get_services"`
+	GetOperations   metrics.Counter `metric:"storage_read_retries" tags:"operation=get_operations"`
+	GetDependencies metrics.Counter `metric:"storage_read_retries" tags:"operation=get_dependencies"`
+	FindTraces      metrics.Counter `metric:"storage_read_retries" tags:"operation=find_traces"`
+}
+
+func newReadRetryMetrics(factory metrics.Factory) *readRetryMetrics {
+	m := &readRetryMetrics{}
+	metrics.Init(m, factory, nil)
+	return m
+}
+
+// retryRead reads with read, retrying it after a transient error up to options.MaxAttempts attempts,
+// with an exponential backoff, as long as the deadline of the context leaves time for another attempt.
+// The attempt of each retry is in its context, see storage.ReadAttemptFromContext, and counted by retries.
+func retryRead[T any](ctx context.Context, options ReadRetryOptions, retries metrics.Counter, read func(context.Context) (T, error)) (T, error) {
+	result, err := read(ctx)
+	backoff := options.Backoff
+	for attempt := 2; attempt <= options.MaxAttempts; attempt++ {
+		if err == nil || ctx.Err() != nil || !storage.IsTransientError(err) {
+			break
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+			break
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		}
+		retries.Inc(1)
+		result, err = read(storage.ContextWithReadAttempt(ctx, attempt))
+		backoff *= 2
+	}
+	return result, err
+}
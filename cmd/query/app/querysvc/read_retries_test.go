@@ -0,0 +1,138 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var errTransient = status.Error(codes.Unavailable, "storage unavailable")
+
+func withReadRetries(metricsFactory metrics.Factory, findTraces bool) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MetricsFactory = metricsFactory
+		options.ReadRetries = ReadRetryOptions{MaxAttempts: 3, Backoff: time.Millisecond, FindTraces: findTraces}
+	}
+}
+
+// attempt matches the contexts of the reads of the attempt.
+func attempt(n int) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return storage.ReadAttemptFromContext(ctx) == n
+	})
+}
+
+func TestReadRetries(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	tqs := initializeTestService(withReadRetries(metricsFactory, false))
+	tqs.spanReader.On("GetTrace", attempt(1), mockTraceID).Return(nil, errTransient).Once()
+	tqs.spanReader.On("GetTrace", attempt(2), mockTraceID).Return(mockTrace, nil).Once()
+	tqs.spanReader.On("GetServices", attempt(1)).Return(nil, errTransient).Once()
+	tqs.spanReader.On("GetServices", attempt(2)).Return([]string{"shop"}, nil).Once()
+	tqs.spanReader.On("GetOperations", attempt(1), mock.Anything).Return(nil, errTransient).Once()
+	tqs.spanReader.On("GetOperations", attempt(2), mock.Anything).Return([]spanstore.Operation{{Name: "checkout"}}, nil).Once()
+	tqs.depsReader.On("GetDependencies", attempt(1), mock.Anything, mock.Anything).Return(nil, errTransient).Once()
+	tqs.depsReader.On("GetDependencies", attempt(2), mock.Anything, mock.Anything).
+		Return([]model.DependencyLink{{Parent: "shop", Child: "db", CallCount: 1}}, nil).Once()
+
+	trace, err := tqs.queryService.GetTrace(context.Background(), mockTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, mockTrace, trace)
+	services, err := tqs.queryService.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop"}, services)
+	operations, err := tqs.queryService.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "shop"})
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{{Name: "checkout"}}, operations)
+	dependencies, err := tqs.queryService.GetDependencies(context.Background(), time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, dependencies, 1)
+
+	tqs.spanReader.AssertExpectations(t)
+	tqs.depsReader.AssertExpectations(t)
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "storage_read_retries", Tags: map[string]string{"operation": "get_trace"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage_read_retries", Tags: map[string]string{"operation": "get_services"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage_read_retries", Tags: map[string]string{"operation": "get_operations"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage_read_retries", Tags: map[string]string{"operation": "get_dependencies"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "storage_errors", Tags: map[string]string{"category": "unavailable"}, Value: 4},
+	)
+}
+
+func TestReadRetriesMaxAttempts(t *testing.T) {
+	tqs := initializeTestService(withReadRetries(metrics.NullFactory, false))
+	tqs.spanReader.On("GetServices", mock.Anything).Return(nil, errTransient).Times(3)
+
+	_, err := tqs.queryService.GetServices(context.Background())
+	require.ErrorIs(t, err, errTransient)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestReadRetriesPermanentError(t *testing.T) {
+	tqs := initializeTestService(withReadRetries(metrics.NullFactory, false))
+	tqs.spanReader.On("GetServices", mock.Anything).Return(nil, errors.New("bad query")).Once()
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	_, err := tqs.queryService.GetServices(context.Background())
+	require.EqualError(t, err, "bad query")
+	_, err = tqs.queryService.GetTrace(context.Background(), mockTraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestReadRetriesFindTraces(t *testing.T) {
+	query := &spanstore.TraceQueryParameters{ServiceName: "shop", NumTraces: 10}
+
+	tqs := initializeTestService(withReadRetries(metrics.NullFactory, false))
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errTransient).Once()
+	_, err := tqs.queryService.FindTraces(context.Background(), query)
+	require.ErrorIs(t, err, errTransient)
+	tqs.spanReader.AssertExpectations(t)
+
+	tqs = initializeTestService(withReadRetries(metrics.NullFactory, true))
+	tqs.spanReader.On("FindTraces", attempt(1), mock.Anything).Return(nil, errTransient).Once()
+	tqs.spanReader.On("FindTraces", attempt(2), mock.Anything).Return([]*model.Trace{mockTrace}, nil).Once()
+	traces, err := tqs.queryService.FindTraces(context.Background(), query)
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestReadRetriesDeadline(t *testing.T) {
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.ReadRetries = ReadRetryOptions{MaxAttempts: 3, Backoff: time.Minute}
+	})
+	tqs.spanReader.On("GetServices", mock.Anything).Return(nil, errTransient).Once()
+
+	// the backoff exceeds the remaining time of the request, the read is not retried
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := tqs.queryService.GetServices(ctx)
+	require.ErrorIs(t, err, errTransient)
+	tqs.spanReader.AssertExpectations(t)
+
+	// the request is canceled during the backoff
+	tqs.spanReader.On("GetServices", mock.Anything).Return(nil, errTransient).Once()
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = tqs.queryService.GetServices(ctx)
+	require.ErrorIs(t, err, errTransient)
+	tqs.spanReader.AssertExpectations(t)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// RedactionStrategy is how the values of redacted tags are replaced.
+type RedactionStrategy string
+
+const (
+	// RedactionMask replaces the values with RedactedValue.
+	RedactionMask RedactionStrategy = "mask"
+	// RedactionHash replaces the values with a one-way hash, so that they can still be correlated.
+	// It requires QueryServiceOptions.HashKey, the values being masked without it.
+	RedactionHash RedactionStrategy = "hash"
+	// RedactionDrop removes the tags.
+	RedactionDrop RedactionStrategy = "drop"
+
+	// RedactedValue is the value of masked tags.
+	RedactedValue = "[REDACTED]"
+)
+
+// RedactionRule redacts the tags whose keys match a pattern.
+type RedactionRule struct {
+	// KeyPattern must match the whole key of the tag.
+	KeyPattern *regexp.Regexp
+	Strategy   RedactionStrategy
+}
+
+// ParseRedactionRule parses a rule in the form <key-regex>[:mask|hash|drop], where the
+// regular expression must match the whole key and the strategy defaults to mask.
+func ParseRedactionRule(s string) (RedactionRule, error) {
+	pattern, strategy := s, RedactionMask
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		switch suffix := RedactionStrategy(s[i+1:]); suffix {
+		case RedactionMask, RedactionHash, RedactionDrop:
+			pattern, strategy = s[:i], suffix
+		}
+	}
+	if pattern == "" {
+		return RedactionRule{}, fmt.Errorf("empty tag key pattern in redaction rule %q", s)
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return RedactionRule{}, fmt.Errorf("invalid tag key pattern in redaction rule %q: %w", s, err)
+	}
+	return RedactionRule{KeyPattern: re, Strategy: strategy}, nil
+}
+
+// RedactionOptions configures the redaction of span tags, process tags and log fields in the returned traces.
+type RedactionOptions struct {
+	// Rules are applied in order, the first rule matching a key is used.
+	Rules []RedactionRule
+	// UnredactedSubjects are the authenticated subjects allowed to see the traces unredacted.
+	UnredactedSubjects []string
+}
+
+type subjectKeyType string
+
+const subjectKey = subjectKeyType("subject")
+
+// ContextWithSubject returns a context with the authenticated subject of the request.
+func ContextWithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey, subject)
+}
+
+// GetSubject returns the authenticated subject of the request, or an empty string.
+func GetSubject(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectKey).(string)
+	return subject
+}
+
+type redactor struct {
+	hasher             *tagHasher
+	rules              []RedactionRule
+	unredactedSubjects map[string]struct{}
+}
+
+func newRedactor(options RedactionOptions, hasher *tagHasher) *redactor {
+	if len(options.Rules) == 0 {
+		return nil
+	}
+	return &redactor{
+		hasher:             hasher,
+		rules:              options.Rules,
+		unredactedSubjects: toSet(options.UnredactedSubjects),
+	}
+}
+
+// allowsUnredacted returns true if the subject of the request may see the traces unredacted.
+func (r *redactor) allowsUnredacted(ctx context.Context) bool {
+	subject := GetSubject(ctx)
+	if subject == "" {
+		return false
+	}
+	_, ok := r.unredactedSubjects[subject]
+	return ok
+}
+
+// redactTrace redacts the trace in place.
+func (r *redactor) redactTrace(trace *model.Trace) {
+	processes := make(map[*model.Process]*model.Process)
+	for _, span := range trace.Spans {
+		span.Tags = r.redactTags(span.Tags)
+		for i := range span.Logs {
+			span.Logs[i].Fields = r.redactTags(span.Logs[i].Fields)
+		}
+		if span.Process == nil {
+			continue
+		}
+		// processes may be shared between spans, so they are replaced rather than modified
+		process, ok := processes[span.Process]
+		if !ok {
+			process = model.NewProcess(span.Process.ServiceName, r.redactTags(span.Process.Tags))
+			processes[span.Process] = process
+		}
+		span.Process = process
+	}
+}
+
+func (r *redactor) redactTags(tags []model.KeyValue) []model.KeyValue {
+	if len(tags) == 0 {
+		return tags
+	}
+	result := make([]model.KeyValue, 0, len(tags))
+	for _, tag := range tags {
+		rule, ok := r.matchingRule(tag.Key)
+		if !ok {
+			result = append(result, tag)
+			continue
+		}
+		switch {
+		case rule.Strategy == RedactionHash && r.hasher != nil:
+			result = append(result, model.String(tag.Key, r.hasher.hash(tag.AsString())))
+		case rule.Strategy == RedactionDrop:
+		default:
+			result = append(result, model.String(tag.Key, RedactedValue))
+		}
+	}
+	return result
+}
+
+func (r *redactor) matchingRule(key string) (RedactionRule, bool) {
+	for _, rule := range r.rules {
+		if rule.KeyPattern.MatchString(key) {
+			return rule, true
+		}
+	}
+	return RedactionRule{}, false
+}
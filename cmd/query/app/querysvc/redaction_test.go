@@ -0,0 +1,207 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func mustParseRedactionRules(t *testing.T, rules ...string) []RedactionRule {
+	var result []RedactionRule
+	for _, s := range rules {
+		rule, err := ParseRedactionRule(s)
+		require.NoError(t, err)
+		result = append(result, rule)
+	}
+	return result
+}
+
+func TestParseRedactionRule(t *testing.T) {
+	testCases := []struct {
+		rule     string
+		pattern  string
+		strategy RedactionStrategy
+		matches  []string
+		others   []string
+	}{
+		{rule: "user.email", pattern: "^(?:user.email)$", strategy: RedactionMask, matches: []string{"user.email"}, others: []string{"user.email.domain", "the.user.email"}},
+		{rule: `.*\.email:hash`, pattern: `^(?:.*\.email)$`, strategy: RedactionHash, matches: []string{"user.email", "http.request.user.email"}, others: []string{"email"}},
+		{rule: "password|secret:drop", pattern: "^(?:password|secret)$", strategy: RedactionDrop, matches: []string{"password", "secret"}, others: []string{"secrets"}},
+		{rule: "token:mask", pattern: "^(?:token)$", strategy: RedactionMask},
+		{rule: "http:url", pattern: "^(?:http:url)$", strategy: RedactionMask, matches: []string{"http:url"}},
+		{rule: "(?:a|b):hash", pattern: "^(?:(?:a|b))$", strategy: RedactionHash, matches: []string{"a", "b"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.rule, func(t *testing.T) {
+			rule, err := ParseRedactionRule(tc.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tc.pattern, rule.KeyPattern.String())
+			assert.Equal(t, tc.strategy, rule.Strategy)
+			for _, key := range tc.matches {
+				assert.True(t, rule.KeyPattern.MatchString(key), key)
+			}
+			for _, key := range tc.others {
+				assert.False(t, rule.KeyPattern.MatchString(key), key)
+			}
+		})
+	}
+}
+
+func TestParseRedactionRuleErrors(t *testing.T) {
+	_, err := ParseRedactionRule(":hash")
+	require.ErrorContains(t, err, "empty tag key pattern")
+	_, err = ParseRedactionRule("user.(email:drop")
+	require.ErrorContains(t, err, "invalid tag key pattern")
+}
+
+func makeRedactionTrace() *model.Trace {
+	process := model.NewProcess("frontend", []model.KeyValue{
+		model.String("hostname", "host1"),
+		model.String("owner.email", "ops@example.com"),
+	})
+	traceID := model.NewTraceID(0, 1)
+	return &model.Trace{Spans: []*model.Span{
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "login",
+			StartTime:     time.Unix(100, 0),
+			Process:       process,
+			Tags: []model.KeyValue{
+				model.String("user.email", "alice@example.com"),
+				model.String("password", "secret"),
+				model.Int64("http.status_code", 200),
+			},
+			Logs: []model.Log{{
+				Timestamp: time.Unix(100, 0),
+				Fields: []model.KeyValue{
+					model.String("event", "request"),
+					model.String("http.request.header.authorization", "Bearer abc"),
+					model.String("http.request.body.user.email", "alice@example.com"),
+				},
+			}},
+		},
+		{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "SELECT",
+			Process:       process,
+			Tags:          []model.KeyValue{model.Int64("user.email", 42)},
+		},
+	}}
+}
+
+func TestRedactTrace(t *testing.T) {
+	r := newRedactor(RedactionOptions{Rules: mustParseRedactionRules(t,
+		`(.+\.)?email:hash`,
+		"password:drop",
+		`http\.request\.header\..*`,
+		// not used, the first matching rule applies
+		"user.email:drop",
+	)}, newTagHasher(testHashKey))
+	require.NotNil(t, r)
+	trace := makeRedactionTrace()
+	sharedProcess := trace.Spans[0].Process
+	r.redactTrace(trace)
+
+	span := trace.Spans[0]
+	assert.Equal(t, []model.KeyValue{
+		model.String("user.email", hash("alice@example.com")),
+		model.Int64("http.status_code", 200),
+	}, span.Tags)
+	assert.Equal(t, []model.KeyValue{
+		model.String("event", "request"),
+		model.String("http.request.header.authorization", RedactedValue),
+		model.String("http.request.body.user.email", hash("alice@example.com")),
+	}, span.Logs[0].Fields)
+	assert.Equal(t, []model.KeyValue{
+		model.String("hostname", "host1"),
+		model.String("owner.email", hash("ops@example.com")),
+	}, span.Process.Tags)
+	assert.Equal(t, []model.KeyValue{model.String("user.email", hash("42"))}, trace.Spans[1].Tags)
+
+	// shared processes are redacted once and not modified in place
+	assert.Same(t, trace.Spans[0].Process, trace.Spans[1].Process)
+	assert.Equal(t, "ops@example.com", sharedProcess.Tags[1].VStr)
+}
+
+func TestRedactTraceHashWithoutKey(t *testing.T) {
+	r := newRedactor(RedactionOptions{Rules: mustParseRedactionRules(t, `(.+\.)?email:hash`)}, nil)
+	trace := makeRedactionTrace()
+	r.redactTrace(trace)
+	assert.Equal(t, []model.KeyValue{model.String("user.email", RedactedValue)}, trace.Spans[1].Tags)
+}
+
+func TestRedactorDisabled(t *testing.T) {
+	assert.Nil(t, newRedactor(RedactionOptions{UnredactedSubjects: []string{"alice"}}, newTagHasher(testHashKey)))
+}
+
+func TestRedactorAllowsUnredacted(t *testing.T) {
+	r := newRedactor(RedactionOptions{
+		Rules:              mustParseRedactionRules(t, "user.email"),
+		UnredactedSubjects: []string{"alice"},
+	}, nil)
+	ctx := context.Background()
+	assert.False(t, r.allowsUnredacted(ctx))
+	assert.False(t, r.allowsUnredacted(ContextWithSubject(ctx, "bob")))
+	assert.False(t, r.allowsUnredacted(ContextWithSubject(ctx, "")))
+	assert.True(t, r.allowsUnredacted(ContextWithSubject(ctx, "alice")))
+}
+
+func withRedaction(t *testing.T, rules ...string) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.Redaction = RedactionOptions{
+			Rules:              mustParseRedactionRules(t, rules...),
+			UnredactedSubjects: []string{"analyst-lead"},
+		}
+		options.HashKey = testHashKey
+	}
+}
+
+func TestGetTraceRedacted(t *testing.T) {
+	tqs := initializeTestService(withRedaction(t, "user.email"))
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).
+		Return(func(context.Context, model.TraceID) *model.Trace { return makeRedactionTrace() }, nil)
+
+	trace, err := tqs.queryService.GetTrace(context.Background(), mockTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, model.String("user.email", RedactedValue), trace.Spans[0].Tags[0])
+
+	trace, err = tqs.queryService.GetTrace(ContextWithSubject(context.Background(), "analyst-lead"), mockTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, model.String("user.email", "alice@example.com"), trace.Spans[0].Tags[0])
+}
+
+func TestFindTracesRedacted(t *testing.T) {
+	tqs := initializeTestService(withRedaction(t, "user.email:drop"))
+	query := &spanstore.TraceQueryParameters{ServiceName: "frontend"}
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{makeRedactionTrace()}, nil).Once()
+
+	traces, err := tqs.queryService.FindTraces(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Empty(t, traces[0].Spans[1].Tags)
+}
+
+func TestArchiveTraceUnredacted(t *testing.T) {
+	tqs := initializeTestService(withArchiveSpanWriter(), withRedaction(t, "user.email"))
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).Return(makeRedactionTrace(), nil).Once()
+	tqs.archiveSpanWriter.On("WriteSpan", mock.Anything, mock.MatchedBy(func(span *model.Span) bool {
+		tag, _ := model.KeyValues(span.Tags).FindByKey("user.email")
+		return tag.VStr == "alice@example.com" || tag.VInt64 == 42
+	})).Return(nil).Times(2)
+
+	require.NoError(t, tqs.queryService.ArchiveTrace(context.Background(), mockTraceID))
+	tqs.archiveSpanWriter.AssertExpectations(t)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// LookbackModeClamp reduces the time window of the searches exceeding the maximum lookback.
+	LookbackModeClamp = "clamp"
+	// LookbackModeReject rejects the searches exceeding the maximum lookback.
+	LookbackModeReject = "reject"
+
+	// DefaultSearchLookback is the time window of the searches without start time, unless configured.
+	DefaultSearchLookback = 48 * time.Hour
+)
+
+// ErrSearchRejected is returned, wrapped, for the searches rejected by the guardrails.
+var ErrSearchRejected = errors.New("search rejected")
+
+// SearchGuardrails are the limits enforced on the searches of traces by all the query APIs,
+// protecting the storage from searches over a large time window or for many traces.
+type SearchGuardrails struct {
+	// MaxLimit caps the number of traces of a search, 0 means no cap.
+	MaxLimit int
+	// MaxLookback caps the time window of a search, 0 means no cap.
+	MaxLookback time.Duration
+	// LookbackMode is either LookbackModeClamp, the default, or LookbackModeReject.
+	LookbackMode string
+	// DefaultLookback is the time window of the searches without start time, DefaultSearchLookback if not set.
+	DefaultLookback time.Duration
+	// RequireService rejects the searches without a service, which scan the traces of all the services.
+	RequireService bool
+}
+
+// searchGuardrailsMetrics counts the searches adjusted or rejected by the guardrails.
+type searchGuardrailsMetrics struct {
+	ClampedLimit     metrics.Counter `metric:"search_guardrails" tags:"action=clamped_limit"`
+	ClampedLookback  metrics.Counter `metric:"search_guardrails" tags:"action=clamped_lookback"`
+	RejectedLookback metrics.Counter `metric:"search_guardrails" tags:"action=rejected_lookback"`
+	RejectedService  metrics.Counter `metric:"search_guardrails" tags:"action=rejected_service"`
+}
+
+func newSearchGuardrailsMetrics(factory metrics.Factory) *searchGuardrailsMetrics {
+	m := &searchGuardrailsMetrics{}
+	metrics.Init(m, factory, nil)
+	return m
+}
+
+// ValidateSearch applies the search guardrails to the query like FindTraces does, without searching
+// the storage, and returns the effective query; the adjustments are reported as warnings of the request.
+func (qs QueryService) ValidateSearch(ctx context.Context, query *spanstore.TraceQueryParameters) (*spanstore.TraceQueryParameters, error) {
+	return qs.applySearchGuardrails(ctx, query)
+}
+
+// applySearchGuardrails returns the query with the default lookback applied and clamped to the limits,
+// reporting the clamping as warnings of the request, or an error if the query is rejected.
+func (qs QueryService) applySearchGuardrails(ctx context.Context, query *spanstore.TraceQueryParameters) (*spanstore.TraceQueryParameters, error) {
+	g := qs.options.SearchGuardrails
+	if g.RequireService && query.ServiceName == "" {
+		qs.guardrailsMetrics.RejectedService.Inc(1)
+		return nil, fmt.Errorf("%w: a service is required, specify the service of the traces to search", ErrSearchRejected)
+	}
+	q := *query
+	if q.StartTimeMax.IsZero() {
+		q.StartTimeMax = time.Now()
+	}
+	if q.StartTimeMin.IsZero() {
+		lookback := g.DefaultLookback
+		if lookback <= 0 {
+			lookback = DefaultSearchLookback
+		}
+		q.StartTimeMin = q.StartTimeMax.Add(-lookback)
+	}
+
+	if window := q.StartTimeMax.Sub(q.StartTimeMin); g.MaxLookback > 0 && window > g.MaxLookback {
+		if g.LookbackMode == LookbackModeReject {
+			qs.guardrailsMetrics.RejectedLookback.Inc(1)
+			return nil, fmt.Errorf("%w: the time window of %s exceeds the maximum lookback of %s", ErrSearchRejected, window, g.MaxLookback)
+		}
+		q.StartTimeMin = q.StartTimeMax.Add(-g.MaxLookback)
+		qs.guardrailsMetrics.ClampedLookback.Inc(1)
+		AddWarning(ctx, fmt.Sprintf("search time window of %s reduced to the maximum lookback of %s", window, g.MaxLookback))
+	}
+
+	if g.MaxLimit > 0 {
+		switch {
+		case q.NumTraces > g.MaxLimit:
+			qs.guardrailsMetrics.ClampedLimit.Inc(1)
+			AddWarning(ctx, fmt.Sprintf("search limit %d reduced to the maximum of %d", q.NumTraces, g.MaxLimit))
+			q.NumTraces = g.MaxLimit
+		case q.NumTraces <= 0:
+			q.NumTraces = g.MaxLimit
+		}
+	}
+	return &q, nil
+}
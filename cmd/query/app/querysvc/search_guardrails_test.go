@@ -0,0 +1,185 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func withSearchGuardrails(guardrails SearchGuardrails, metricsFactory *metricstest.Factory) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.SearchGuardrails = guardrails
+		options.MetricsFactory = metricsFactory
+	}
+}
+
+func TestSearchGuardrails(t *testing.T) {
+	end := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name             string
+		guardrails       SearchGuardrails
+		query            spanstore.TraceQueryParameters
+		expectedQuery    spanstore.TraceQueryParameters
+		expectedWarnings []string
+		expectedMetric   string
+	}{
+		{
+			name:          "default lookback",
+			query:         spanstore.TraceQueryParameters{StartTimeMax: end, NumTraces: 20},
+			expectedQuery: spanstore.TraceQueryParameters{StartTimeMin: end.Add(-DefaultSearchLookback), StartTimeMax: end, NumTraces: 20},
+		},
+		{
+			name:          "configured default lookback",
+			guardrails:    SearchGuardrails{DefaultLookback: time.Hour},
+			query:         spanstore.TraceQueryParameters{StartTimeMax: end, NumTraces: 20},
+			expectedQuery: spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end, NumTraces: 20},
+		},
+		{
+			name:          "within limits",
+			guardrails:    SearchGuardrails{MaxLimit: 50, MaxLookback: 6 * time.Hour},
+			query:         spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end, NumTraces: 20},
+			expectedQuery: spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end, NumTraces: 20},
+		},
+		{
+			name:             "clamped lookback",
+			guardrails:       SearchGuardrails{MaxLookback: 6 * time.Hour},
+			query:            spanstore.TraceQueryParameters{StartTimeMin: end.Add(-24 * time.Hour), StartTimeMax: end, NumTraces: 20},
+			expectedQuery:    spanstore.TraceQueryParameters{StartTimeMin: end.Add(-6 * time.Hour), StartTimeMax: end, NumTraces: 20},
+			expectedWarnings: []string{"search time window of 24h0m0s reduced to the maximum lookback of 6h0m0s"},
+			expectedMetric:   "clamped_lookback",
+		},
+		{
+			name:             "clamped limit",
+			guardrails:       SearchGuardrails{MaxLimit: 50},
+			query:            spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end, NumTraces: 1000},
+			expectedQuery:    spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end, NumTraces: 50},
+			expectedWarnings: []string{"search limit 1000 reduced to the maximum of 50"},
+			expectedMetric:   "clamped_limit",
+		},
+		{
+			name:          "unlimited search capped",
+			guardrails:    SearchGuardrails{MaxLimit: 50},
+			query:         spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end},
+			expectedQuery: spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end, NumTraces: 50},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metricsFactory := metricstest.NewFactory(0)
+			defer metricsFactory.Stop()
+			tqs := initializeTestService(withSearchGuardrails(test.guardrails, metricsFactory))
+			tqs.spanReader.On("FindTraces", mock.Anything, &test.expectedQuery).
+				Return([]*model.Trace{}, nil).Once()
+
+			ctx := ContextWithWarnings(context.Background())
+			query := test.query
+			_, err := tqs.queryService.FindTraces(ctx, &query)
+			require.NoError(t, err)
+			assert.Equal(t, test.query, query, "the query of the caller must not be modified")
+			assert.Equal(t, test.expectedWarnings, GetWarnings(ctx))
+			for _, action := range []string{"clamped_limit", "clamped_lookback", "rejected_lookback"} {
+				expected := 0
+				if action == test.expectedMetric {
+					expected = 1
+				}
+				metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+					Name: "search_guardrails", Tags: map[string]string{"action": action}, Value: expected,
+				})
+			}
+		})
+	}
+}
+
+func TestSearchGuardrailsDefaultEndTime(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(q *spanstore.TraceQueryParameters) bool {
+		return time.Since(q.StartTimeMax) < time.Minute && q.StartTimeMax.Sub(q.StartTimeMin) == DefaultSearchLookback
+	})).Return([]*model.Trace{}, nil).Once()
+
+	_, err := tqs.queryService.FindTraces(context.Background(), &spanstore.TraceQueryParameters{})
+	require.NoError(t, err)
+}
+
+func TestSearchGuardrailsRejection(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	tqs := initializeTestService(withSearchGuardrails(SearchGuardrails{
+		MaxLookback:  6 * time.Hour,
+		LookbackMode: LookbackModeReject,
+	}, metricsFactory))
+
+	end := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, err := tqs.queryService.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		StartTimeMin: end.Add(-24 * time.Hour),
+		StartTimeMax: end,
+	})
+	require.ErrorIs(t, err, ErrSearchRejected)
+	require.EqualError(t, err, "search rejected: the time window of 24h0m0s exceeds the maximum lookback of 6h0m0s")
+	tqs.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "search_guardrails", Tags: map[string]string{"action": "rejected_lookback"}, Value: 1,
+	})
+}
+
+func TestSearchGuardrailsRequireService(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	tqs := initializeTestService(withSearchGuardrails(SearchGuardrails{RequireService: true}, metricsFactory))
+
+	_, err := tqs.queryService.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		Tags: map[string]string{"error": "true"},
+	})
+	require.ErrorIs(t, err, ErrSearchRejected)
+	require.EqualError(t, err, "search rejected: a service is required, specify the service of the traces to search")
+	tqs.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "search_guardrails", Tags: map[string]string{"action": "rejected_service"}, Value: 1,
+	})
+
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{}, nil).Once()
+	_, err = tqs.queryService.FindTraces(context.Background(), &spanstore.TraceQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+}
+
+func TestSearchGuardrailsServiceNotRequired(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{}, nil).Once()
+	_, err := tqs.queryService.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		Tags: map[string]string{"error": "true"},
+	})
+	require.NoError(t, err)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestValidateSearch(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	tqs := initializeTestService(withSearchGuardrails(SearchGuardrails{MaxLimit: 50}, metricsFactory))
+	end := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx := ContextWithWarnings(context.Background())
+	query, err := tqs.queryService.ValidateSearch(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:  "frontend",
+		StartTimeMax: end,
+		NumTraces:    100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &spanstore.TraceQueryParameters{
+		ServiceName:  "frontend",
+		StartTimeMin: end.Add(-DefaultSearchLookback),
+		StartTimeMax: end,
+		NumTraces:    50,
+	}, query)
+	assert.Equal(t, []string{"search limit 100 reduced to the maximum of 50"}, GetWarnings(ctx))
+	tqs.spanReader.AssertNotCalled(t, "FindTraces", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// DefaultSearchWideningFactor multiplies the time window of a search at each step of its widening, unless configured.
+const DefaultSearchWideningFactor = 4
+
+// SearchWidening configures the widening of the time window of the searches finding no traces,
+// requested with FindTracesWidening.
+type SearchWidening struct {
+	// Steps is the maximum number of times the time window of a search is widened, 0 disabling the widening.
+	Steps int
+	// Factor multiplies the time window at each step, DefaultSearchWideningFactor if below 2.
+	Factor int
+	// MaxWindow caps the widened time window, besides the maximum lookback of the search guardrails, 0 means no cap.
+	MaxWindow time.Duration
+}
+
+// SearchWindow is the time window searched by FindTracesWidening, the one which found the traces if any.
+type SearchWindow struct {
+	StartTimeMin time.Time
+	StartTimeMax time.Time
+	// Widened is the number of times the time window of the query was widened.
+	Widened int
+}
+
+// FindTracesWidening searches traces like FindTracesWithFilter and, while none is found, widens the time window
+// of the query back in time, by SearchWidening.Factor at each of at most SearchWidening.Steps steps, searching
+// only the part of the window not searched yet. It returns the time window which found the traces, or the widest
+// one searched. The widening stops at the deadline of the context, the traces found so far being returned
+// with a warning.
+func (qs QueryService) FindTracesWidening(ctx context.Context, query *spanstore.TraceQueryParameters, filter TraceFilter) ([]*model.Trace, SearchWindow, error) {
+	query, err := qs.applySearchGuardrails(ctx, query)
+	if err != nil {
+		return nil, SearchWindow{}, err
+	}
+	window := SearchWindow{StartTimeMin: query.StartTimeMin, StartTimeMax: query.StartTimeMax}
+	traces, err := qs.searchTraces(ctx, query, filter)
+	for err == nil && len(traces) == 0 && window.Widened < qs.options.SearchWidening.Steps {
+		startTimeMin, ok := qs.widenSearchWindow(window)
+		if !ok {
+			break
+		}
+		widenedQuery := *query
+		widenedQuery.StartTimeMin = startTimeMin
+		widenedQuery.StartTimeMax = window.StartTimeMin
+		traces, err = qs.searchTraces(ctx, &widenedQuery, filter)
+		if err != nil && ctx.Err() != nil {
+			AddWarning(ctx, "search time window not widened further at the deadline of the request")
+			return nil, window, nil
+		}
+		window.StartTimeMin = startTimeMin
+		window.Widened++
+	}
+	return traces, window, err
+}
+
+// widenSearchWindow returns the start of the next widened time window, false if it cannot be widened further.
+func (qs QueryService) widenSearchWindow(window SearchWindow) (time.Time, bool) {
+	factor := qs.options.SearchWidening.Factor
+	if factor < 2 {
+		factor = DefaultSearchWideningFactor
+	}
+	maxWindow := qs.options.SearchWidening.MaxWindow
+	if maxLookback := qs.options.SearchGuardrails.MaxLookback; maxLookback > 0 && (maxWindow <= 0 || maxLookback < maxWindow) {
+		maxWindow = maxLookback
+	}
+	current := window.StartTimeMax.Sub(window.StartTimeMin)
+	widened := current * time.Duration(factor)
+	if maxWindow > 0 && widened > maxWindow {
+		widened = maxWindow
+	}
+	if widened <= current {
+		return time.Time{}, false
+	}
+	return window.StartTimeMax.Add(-widened), true
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func withSearchWidening(widening SearchWidening) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.SearchWidening = widening
+	}
+}
+
+// searchedWindow matches the queries searching the time window ending at end.
+func searchedWindow(end time.Time, window time.Duration) any {
+	return mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		return query.StartTimeMax.Equal(end) && query.StartTimeMax.Sub(query.StartTimeMin) == window
+	})
+}
+
+func TestFindTracesWidening(t *testing.T) {
+	end := time.Now()
+	query := &spanstore.TraceQueryParameters{ServiceName: "shop", StartTimeMin: end.Add(-time.Hour), StartTimeMax: end}
+	tqs := initializeTestService(withSearchWidening(SearchWidening{Steps: 3, Factor: 4}))
+	tqs.spanReader.On("FindTraces", mock.Anything, searchedWindow(end, time.Hour)).Return(nil, nil).Once()
+	// only the part of the widened window not searched yet is searched
+	tqs.spanReader.On("FindTraces", mock.Anything, searchedWindow(end.Add(-time.Hour), 3*time.Hour)).Return(nil, nil).Once()
+	tqs.spanReader.On("FindTraces", mock.Anything, searchedWindow(end.Add(-4*time.Hour), 12*time.Hour)).
+		Return([]*model.Trace{mockTrace}, nil).Once()
+
+	traces, window, err := tqs.queryService.FindTracesWidening(context.Background(), query, TraceFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{mockTrace}, traces)
+	assert.Equal(t, SearchWindow{StartTimeMin: end.Add(-16 * time.Hour), StartTimeMax: end, Widened: 2}, window)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestFindTracesWideningNotNeeded(t *testing.T) {
+	end := time.Now()
+	query := &spanstore.TraceQueryParameters{ServiceName: "shop", StartTimeMin: end.Add(-time.Hour), StartTimeMax: end}
+	tqs := initializeTestService(withSearchWidening(SearchWidening{Steps: 3}))
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{mockTrace}, nil).Once()
+
+	traces, window, err := tqs.queryService.FindTracesWidening(context.Background(), query, TraceFilter{})
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+	assert.Equal(t, SearchWindow{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end}, window)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestFindTracesWideningExhausted(t *testing.T) {
+	end := time.Now()
+	query := &spanstore.TraceQueryParameters{ServiceName: "shop", StartTimeMin: end.Add(-time.Hour), StartTimeMax: end}
+
+	// the default factor widens the window up to the maximum window, then the maximum lookback
+	tqs := initializeTestService(
+		withSearchWidening(SearchWidening{Steps: 5, MaxWindow: 6 * time.Hour}),
+		func(_ *testQueryService, options *QueryServiceOptions) {
+			options.SearchGuardrails.MaxLookback = 2 * time.Hour
+		},
+	)
+	tqs.spanReader.On("FindTraces", mock.Anything, searchedWindow(end, time.Hour)).Return(nil, nil).Once()
+	tqs.spanReader.On("FindTraces", mock.Anything, searchedWindow(end.Add(-time.Hour), time.Hour)).Return(nil, nil).Once()
+
+	traces, window, err := tqs.queryService.FindTracesWidening(context.Background(), query, TraceFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+	assert.Equal(t, SearchWindow{StartTimeMin: end.Add(-2 * time.Hour), StartTimeMax: end, Widened: 1}, window)
+	tqs.spanReader.AssertExpectations(t)
+
+	// the widening is disabled
+	tqs = initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	_, window, err = tqs.queryService.FindTracesWidening(context.Background(), query, TraceFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, window.Widened)
+	tqs.spanReader.AssertExpectations(t)
+}
+
+func TestFindTracesWideningDeadline(t *testing.T) {
+	end := time.Now()
+	query := &spanstore.TraceQueryParameters{ServiceName: "shop", StartTimeMin: end.Add(-time.Hour), StartTimeMax: end}
+	tqs := initializeTestService(withSearchWidening(SearchWidening{Steps: 3}))
+	ctx, cancel := context.WithCancel(ContextWithWarnings(context.Background()))
+	defer cancel()
+	tqs.spanReader.On("FindTraces", mock.Anything, searchedWindow(end, time.Hour)).Return(nil, nil).Once()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		cancel()
+	}).Return(nil, context.Canceled).Once()
+
+	traces, window, err := tqs.queryService.FindTracesWidening(ctx, query, TraceFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+	assert.Zero(t, window.Widened)
+	assert.Equal(t, []string{"search time window not widened further at the deadline of the request"}, GetWarnings(ctx))
+}
+
+func TestFindTracesWideningErrors(t *testing.T) {
+	end := time.Now()
+	query := &spanstore.TraceQueryParameters{StartTimeMin: end.Add(-time.Hour), StartTimeMax: end}
+	tqs := initializeTestService(withSearchWidening(SearchWidening{Steps: 3}), func(_ *testQueryService, options *QueryServiceOptions) {
+		options.SearchGuardrails.RequireService = true
+	})
+	_, _, err := tqs.queryService.FindTracesWidening(context.Background(), query, TraceFilter{})
+	require.ErrorIs(t, err, ErrSearchRejected)
+
+	query.ServiceName = "shop"
+	tqs.spanReader.On("FindTraces", mock.Anything, searchedWindow(end, time.Hour)).Return(nil, nil).Once()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error")).Once()
+	_, _, err = tqs.queryService.FindTracesWidening(context.Background(), query, TraceFilter{})
+	require.EqualError(t, err, "storage error")
+	tqs.spanReader.AssertExpectations(t)
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const selfTraceInstrumentation = "github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+
+type selfTraceKey struct{}
+
+// selfTrace records the consecutive phases of a query, e.g. the validation of its parameters,
+// the access to the storage and the serialization of its response, under the span of the query.
+type selfTrace struct {
+	ctx    context.Context
+	tracer trace.Tracer
+
+	mu    sync.Mutex
+	phase trace.Span
+}
+
+// ContextWithSelfTrace returns a context recording the phases of the query in its self-trace, the trace
+// of the span of ctx, and the ID of the trace. It returns false, and ctx, if the span of ctx is not sampled,
+// e.g. with jtracer.NoOp(), as there is no self-trace to record then.
+func ContextWithSelfTrace(ctx context.Context) (context.Context, string, bool) {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsSampled() {
+		return ctx, "", false
+	}
+	st := &selfTrace{
+		ctx:    ctx,
+		tracer: span.TracerProvider().Tracer(selfTraceInstrumentation),
+	}
+	return context.WithValue(ctx, selfTraceKey{}, st), span.SpanContext().TraceID().String(), true
+}
+
+// StartSelfTracePhase ends the current phase of the self-trace of the query, if any, and starts the next one.
+func StartSelfTracePhase(ctx context.Context, name string) {
+	if st, ok := ctx.Value(selfTraceKey{}).(*selfTrace); ok {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		st.endPhase()
+		_, st.phase = st.tracer.Start(st.ctx, name)
+	}
+}
+
+// EndSelfTracePhase ends the current phase of the self-trace of the query, if any.
+func EndSelfTracePhase(ctx context.Context) {
+	if st, ok := ctx.Value(selfTraceKey{}).(*selfTrace); ok {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		st.endPhase()
+	}
+}
+
+func (st *selfTrace) endPhase() {
+	if st.phase != nil {
+		st.phase.End()
+		st.phase = nil
+	}
+}
+
+// startStorageSpan starts the span of a storage access in the self-trace of the query, ending the phase
+// preceding it, and returns the function ending the span. It does nothing without self-trace.
+func startStorageSpan(ctx context.Context, operation string) (context.Context, func(error)) {
+	st, ok := ctx.Value(selfTraceKey{}).(*selfTrace)
+	if !ok {
+		return ctx, func(error) {}
+	}
+	st.mu.Lock()
+	st.endPhase()
+	st.mu.Unlock()
+	ctx, span := st.tracer.Start(ctx, "storage "+operation, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// selfTracingSpanReader records the accesses to the span storage in the self-traces of the queries.
+type selfTracingSpanReader struct {
+	spanReader spanstore.Reader
+}
+
+// FindTraces implements spanstore.Reader#FindTraces
+func (r selfTracingSpanReader) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	ctx, end := startStorageSpan(ctx, "FindTraces")
+	traces, err := r.spanReader.FindTraces(ctx, query)
+	end(err)
+	return traces, err
+}
+
+// FindTraceIDs implements spanstore.Reader#FindTraceIDs
+func (r selfTracingSpanReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	ctx, end := startStorageSpan(ctx, "FindTraceIDs")
+	traceIDs, err := r.spanReader.FindTraceIDs(ctx, query)
+	end(err)
+	return traceIDs, err
+}
+
+// GetTrace implements spanstore.Reader#GetTrace
+func (r selfTracingSpanReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	ctx, end := startStorageSpan(ctx, "GetTrace")
+	trace, err := r.spanReader.GetTrace(ctx, traceID)
+	end(err)
+	return trace, err
+}
+
+// GetTraces implements spanstore.BatchReader#GetTraces, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.BatchReader.
+func (r selfTracingSpanReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	batchReader, ok := r.spanReader.(spanstore.BatchReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	ctx, end := startStorageSpan(ctx, "GetTraces")
+	traces, err := batchReader.GetTraces(ctx, traceIDs)
+	end(err)
+	return traces, err
+}
+
+// GetTracePage implements spanstore.PagedReader#GetTracePage, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.PagedReader.
+func (r selfTracingSpanReader) GetTracePage(ctx context.Context, traceID model.TraceID, pageToken string) (*model.Trace, string, error) {
+	pagedReader, ok := r.spanReader.(spanstore.PagedReader)
+	if !ok {
+		return nil, "", errors.ErrUnsupported
+	}
+	ctx, end := startStorageSpan(ctx, "GetTracePage")
+	trace, nextPageToken, err := pagedReader.GetTracePage(ctx, traceID, pageToken)
+	end(err)
+	return trace, nextPageToken, err
+}
+
+// DeleteTrace implements spanstore.TraceDeleter#DeleteTrace, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.TraceDeleter.
+func (r selfTracingSpanReader) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	deleter, ok := r.spanReader.(spanstore.TraceDeleter)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	ctx, end := startStorageSpan(ctx, "DeleteTrace")
+	err := deleter.DeleteTrace(ctx, traceID)
+	end(err)
+	return err
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix, it returns
+// errors.ErrUnsupported if the underlying reader is not a spanstore.TraceIDPrefixReader.
+func (r selfTracingSpanReader) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	prefixReader, ok := r.spanReader.(spanstore.TraceIDPrefixReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	ctx, end := startStorageSpan(ctx, "FindTraceIDsByPrefix")
+	traceIDs, err := prefixReader.FindTraceIDsByPrefix(ctx, prefix, limit)
+	end(err)
+	return traceIDs, err
+}
+
+// GetTagKeys implements spanstore.TagKeysReader#GetTagKeys, it returns
+// errors.ErrUnsupported if the underlying reader is not a spanstore.TagKeysReader.
+func (r selfTracingSpanReader) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	tagKeysReader, ok := r.spanReader.(spanstore.TagKeysReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	ctx, end := startStorageSpan(ctx, "GetTagKeys")
+	keys, err := tagKeysReader.GetTagKeys(ctx, query)
+	end(err)
+	return keys, err
+}
+
+// GetServices implements spanstore.Reader#GetServices
+func (r selfTracingSpanReader) GetServices(ctx context.Context) ([]string, error) {
+	ctx, end := startStorageSpan(ctx, "GetServices")
+	services, err := r.spanReader.GetServices(ctx)
+	end(err)
+	return services, err
+}
+
+// GetOperations implements spanstore.Reader#GetOperations
+func (r selfTracingSpanReader) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	ctx, end := startStorageSpan(ctx, "GetOperations")
+	operations, err := r.spanReader.GetOperations(ctx, query)
+	end(err)
+	return operations, err
+}
+
+// newSelfTracingDependencyReader returns a dependencystore.Reader recording the accesses to the dependency
+// storage in the self-traces of the queries. It is a dependencystore.ErrorCountReader and
+// a dependencystore.OperationDependencyReader if reader is.
+func newSelfTracingDependencyReader(reader dependencystore.Reader) dependencystore.Reader {
+	decorator := selfTracingDependencyReader{dependencyReader: reader}
+	errorCountReader, countsErrors := reader.(dependencystore.ErrorCountReader)
+	operationReader, readsOperations := reader.(dependencystore.OperationDependencyReader)
+	switch {
+	case countsErrors && readsOperations:
+		return selfTracingDependencyErrorCountOperationReader{
+			selfTracingDependencyErrorCountReader: selfTracingDependencyErrorCountReader{
+				selfTracingDependencyReader: decorator,
+				errorCountReader:            errorCountReader,
+			},
+			operationReader: operationReader,
+		}
+	case countsErrors:
+		return selfTracingDependencyErrorCountReader{selfTracingDependencyReader: decorator, errorCountReader: errorCountReader}
+	case readsOperations:
+		return selfTracingDependencyOperationReader{selfTracingDependencyReader: decorator, operationReader: operationReader}
+	default:
+		return decorator
+	}
+}
+
+type selfTracingDependencyReader struct {
+	dependencyReader dependencystore.Reader
+}
+
+// GetDependencies implements dependencystore.Reader#GetDependencies
+func (r selfTracingDependencyReader) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	ctx, end := startStorageSpan(ctx, "GetDependencies")
+	dependencies, err := r.dependencyReader.GetDependencies(ctx, endTs, lookback)
+	end(err)
+	return dependencies, err
+}
+
+type selfTracingDependencyErrorCountReader struct {
+	selfTracingDependencyReader
+	errorCountReader dependencystore.ErrorCountReader
+}
+
+// GetDependencyErrors implements dependencystore.ErrorCountReader#GetDependencyErrors
+func (r selfTracingDependencyErrorCountReader) GetDependencyErrors(
+	ctx context.Context,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.DependencyLinkErrors, error) {
+	ctx, end := startStorageSpan(ctx, "GetDependencyErrors")
+	linkErrors, err := r.errorCountReader.GetDependencyErrors(ctx, endTs, lookback)
+	end(err)
+	return linkErrors, err
+}
+
+type selfTracingDependencyOperationReader struct {
+	selfTracingDependencyReader
+	operationReader dependencystore.OperationDependencyReader
+}
+
+// GetOperationDependencies implements dependencystore.OperationDependencyReader#GetOperationDependencies
+func (r selfTracingDependencyOperationReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	return getSelfTracedOperationDependencies(ctx, r.operationReader, service, endTs, lookback)
+}
+
+type selfTracingDependencyErrorCountOperationReader struct {
+	selfTracingDependencyErrorCountReader
+	operationReader dependencystore.OperationDependencyReader
+}
+
+// GetOperationDependencies implements dependencystore.OperationDependencyReader#GetOperationDependencies
+func (r selfTracingDependencyErrorCountOperationReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	return getSelfTracedOperationDependencies(ctx, r.operationReader, service, endTs, lookback)
+}
+
+func getSelfTracedOperationDependencies(
+	ctx context.Context,
+	reader dependencystore.OperationDependencyReader,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	ctx, end := startStorageSpan(ctx, "GetOperationDependencies")
+	links, err := reader.GetOperationDependencies(ctx, service, endTs, lookback)
+	end(err)
+	return links, err
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	nooptrace "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+// startQuerySpan returns the context of a query span recorded in the exporter.
+func startQuerySpan(t *testing.T) (context.Context, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { require.NoError(t, tracerProvider.Shutdown(context.Background())) })
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "query")
+	t.Cleanup(func() { span.End() })
+	return ctx, exporter
+}
+
+func spanNames(exporter *tracetest.InMemoryExporter) []string {
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		names = append(names, span.Name)
+	}
+	return names
+}
+
+func TestSelfTracePhases(t *testing.T) {
+	ctx, exporter := startQuerySpan(t)
+	reader := &spanstoremocks.Reader{}
+	reader.On("GetServices", mock.Anything).Return([]string{"frontend"}, nil)
+	reader.On("GetOperations", mock.Anything, mock.Anything).Return(nil, errors.New("storage unavailable"))
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{SelfTracing: true})
+
+	ctx, traceID, ok := ContextWithSelfTrace(ctx)
+	require.True(t, ok)
+	StartSelfTracePhase(ctx, "validate parameters")
+	_, err := qs.GetServices(ctx)
+	require.NoError(t, err)
+	_, err = qs.GetOperations(ctx, spanstore.OperationQueryParameters{ServiceName: "frontend"})
+	require.Error(t, err)
+	StartSelfTracePhase(ctx, "serialize")
+	EndSelfTracePhase(ctx)
+	// ending the phase again does nothing
+	EndSelfTracePhase(ctx)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, []string{"validate parameters", "storage GetServices", "storage GetOperations", "serialize"}, spanNames(exporter))
+	for _, span := range spans {
+		assert.Equal(t, traceID, span.SpanContext.TraceID().String())
+	}
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	assert.Equal(t, codes.Error, spans[2].Status.Code)
+	assert.Equal(t, "storage unavailable", spans[2].Status.Description)
+}
+
+func TestSelfTraceNotSampled(t *testing.T) {
+	ctx, span := nooptrace.NewTracerProvider().Tracer("test").Start(context.Background(), "query")
+	defer span.End()
+	selfTraceCtx, traceID, ok := ContextWithSelfTrace(ctx)
+	assert.False(t, ok)
+	assert.Empty(t, traceID)
+	assert.Equal(t, ctx, selfTraceCtx)
+}
+
+func TestSelfTracingReadersWithoutSelfTrace(t *testing.T) {
+	ctx, exporter := startQuerySpan(t)
+	reader := &spanstoremocks.Reader{}
+	reader.On("GetServices", mock.Anything).Return([]string{"frontend"}, nil)
+	qs := NewQueryService(reader, &depsmocks.Reader{}, QueryServiceOptions{SelfTracing: true})
+
+	services, err := qs.GetServices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend"}, services)
+	StartSelfTracePhase(ctx, "serialize")
+	EndSelfTracePhase(ctx)
+	assert.Empty(t, exporter.GetSpans())
+}
+
+// fullSpanReader is a span reader implementing all of spanstore.BatchReader, spanstore.PagedReader and spanstore.TraceDeleter.
+type fullSpanReader struct {
+	*spanstoremocks.Reader
+}
+
+func (r fullSpanReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	return batchReader{r.Reader}.GetTraces(ctx, traceIDs)
+}
+
+func (r fullSpanReader) GetTracePage(ctx context.Context, traceID model.TraceID, pageToken string) (*model.Trace, string, error) {
+	return pagedReader{r.Reader}.GetTracePage(ctx, traceID, pageToken)
+}
+
+func (r fullSpanReader) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	return traceDeleter{r.Reader}.DeleteTrace(ctx, traceID)
+}
+
+func TestSelfTracingSpanReader(t *testing.T) {
+	ctx, exporter := startQuerySpan(t)
+	ctx, _, ok := ContextWithSelfTrace(ctx)
+	require.True(t, ok)
+	traceID := model.NewTraceID(0, 1)
+	query := &spanstore.TraceQueryParameters{ServiceName: "frontend"}
+	mockReader := &spanstoremocks.Reader{}
+	mockReader.On("FindTraces", mock.Anything, query).Return([]*model.Trace{{}}, nil)
+	mockReader.On("FindTraceIDs", mock.Anything, query).Return([]model.TraceID{traceID}, nil)
+	mockReader.On("GetTrace", mock.Anything, traceID).Return(&model.Trace{}, nil)
+	mockReader.On("GetTraces", mock.Anything, []model.TraceID{traceID}).Return([]*model.Trace{{}}, nil)
+	mockReader.On("GetTracePage", mock.Anything, traceID, "").Return(&model.Trace{}, "", nil)
+	mockReader.On("DeleteTrace", mock.Anything, traceID).Return(nil)
+
+	reader := selfTracingSpanReader{spanReader: fullSpanReader{mockReader}}
+	_, err := reader.FindTraces(ctx, query)
+	require.NoError(t, err)
+	_, err = reader.FindTraceIDs(ctx, query)
+	require.NoError(t, err)
+	_, err = reader.GetTrace(ctx, traceID)
+	require.NoError(t, err)
+	_, err = reader.GetTraces(ctx, []model.TraceID{traceID})
+	require.NoError(t, err)
+	_, _, err = reader.GetTracePage(ctx, traceID, "")
+	require.NoError(t, err)
+	require.NoError(t, reader.DeleteTrace(ctx, traceID))
+	assert.Equal(t, []string{
+		"storage FindTraces", "storage FindTraceIDs", "storage GetTrace",
+		"storage GetTraces", "storage GetTracePage", "storage DeleteTrace",
+	}, spanNames(exporter))
+}
+
+func TestSelfTracingSpanReaderUnsupported(t *testing.T) {
+	reader := selfTracingSpanReader{spanReader: &spanstoremocks.Reader{}}
+	_, err := reader.GetTraces(context.Background(), nil)
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	_, _, err = reader.GetTracePage(context.Background(), model.NewTraceID(0, 1), "")
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	require.ErrorIs(t, reader.DeleteTrace(context.Background(), model.NewTraceID(0, 1)), errors.ErrUnsupported)
+}
+
+func TestSelfTracingDependencyReader(t *testing.T) {
+	ctx, exporter := startQuerySpan(t)
+	ctx, _, ok := ContextWithSelfTrace(ctx)
+	require.True(t, ok)
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	mockReader := &depsmocks.Reader{}
+	mockReader.On("GetDependencies", mock.Anything, endTs, time.Hour).Return([]model.DependencyLink{}, nil)
+	mockReader.On("GetDependencyErrors", mock.Anything, endTs, time.Hour).Return([]dependencystore.DependencyLinkErrors{}, nil)
+
+	_, isErrorCountReader := newSelfTracingDependencyReader(mockReader).(dependencystore.ErrorCountReader)
+	assert.False(t, isErrorCountReader)
+
+	reader := newSelfTracingDependencyReader(errorCountDepsReader{mockReader})
+	_, err := reader.GetDependencies(ctx, endTs, time.Hour)
+	require.NoError(t, err)
+	errorCountReader, ok := reader.(dependencystore.ErrorCountReader)
+	require.True(t, ok)
+	_, err = errorCountReader.GetDependencyErrors(ctx, endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"storage GetDependencies", "storage GetDependencyErrors"}, spanNames(exporter))
+}
+
+// errorCountOperationDepsReader is a dependency reader implementing dependencystore.ErrorCountReader
+// and dependencystore.OperationDependencyReader.
+type errorCountOperationDepsReader struct {
+	errorCountDepsReader
+}
+
+func (r errorCountOperationDepsReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	return operationDepsReader{r.Reader}.GetOperationDependencies(ctx, service, endTs, lookback)
+}
+
+func TestSelfTracingOperationDependencyReader(t *testing.T) {
+	ctx, exporter := startQuerySpan(t)
+	ctx, _, ok := ContextWithSelfTrace(ctx)
+	require.True(t, ok)
+	endTs := time.Unix(0, 1476374248550*millisToNanosMultiplier)
+	mockReader := &depsmocks.Reader{}
+	mockReader.On("GetOperationDependencies", mock.Anything, "frontend", endTs, time.Hour).
+		Return([]dependencystore.OperationDependencyLink{}, nil)
+
+	reader := newSelfTracingDependencyReader(operationDepsReader{mockReader})
+	_, isErrorCountReader := reader.(dependencystore.ErrorCountReader)
+	assert.False(t, isErrorCountReader)
+	operationReader, ok := reader.(dependencystore.OperationDependencyReader)
+	require.True(t, ok)
+	_, err := operationReader.GetOperationDependencies(ctx, "frontend", endTs, time.Hour)
+	require.NoError(t, err)
+
+	reader = newSelfTracingDependencyReader(errorCountOperationDepsReader{errorCountDepsReader{mockReader}})
+	_, isErrorCountReader = reader.(dependencystore.ErrorCountReader)
+	assert.True(t, isErrorCountReader)
+	operationReader, ok = reader.(dependencystore.OperationDependencyReader)
+	require.True(t, ok)
+	_, err = operationReader.GetOperationDependencies(ctx, "frontend", endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"storage GetOperationDependencies", "storage GetOperationDependencies"}, spanNames(exporter))
+}
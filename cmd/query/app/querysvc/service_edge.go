@@ -0,0 +1,51 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const peerServiceTagKey = "peer.service"
+
+// ServiceEdgeFilter selects the traces where the caller service calls the callee service, i.e. with
+// a span of the callee whose parent span is a span of the caller, or, for the callees which are not
+// instrumented, with a span of the caller whose peer.service tag is the callee.
+type ServiceEdgeFilter struct {
+	CallerService string
+	CalleeService string
+}
+
+func (f ServiceEdgeFilter) enabled() bool {
+	return f.CallerService != "" && f.CalleeService != ""
+}
+
+func (f ServiceEdgeFilter) matches(trace *model.Trace) bool {
+	spans := make(map[model.SpanID]*model.Span, len(trace.Spans))
+	for _, span := range trace.Spans {
+		spans[span.SpanID] = span
+	}
+	for _, span := range trace.Spans {
+		switch spanServiceName(span) {
+		case f.CalleeService:
+			for _, ref := range span.References {
+				if parent, ok := spans[ref.SpanID]; ok && ref.TraceID == span.TraceID && spanServiceName(parent) == f.CallerService {
+					return true
+				}
+			}
+		case f.CallerService:
+			if peerService, ok := model.KeyValues(span.Tags).FindByKey(peerServiceTagKey); ok && peerService.AsString() == f.CalleeService {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func spanServiceName(span *model.Span) string {
+	if span.Process == nil {
+		return ""
+	}
+	return span.Process.ServiceName
+}
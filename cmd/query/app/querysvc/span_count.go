@@ -0,0 +1,26 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// SpanCountFilter selects traces by their number of spans; a zero bound is not enforced.
+type SpanCountFilter struct {
+	Min int
+	Max int
+}
+
+func (f SpanCountFilter) enabled() bool {
+	return f.Min > 0 || f.Max > 0
+}
+
+func (f SpanCountFilter) matches(trace *model.Trace) bool {
+	spans := len(trace.Spans)
+	if f.Min > 0 && spans < f.Min {
+		return false
+	}
+	return f.Max <= 0 || spans <= f.Max
+}
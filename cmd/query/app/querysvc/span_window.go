@@ -0,0 +1,33 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"sort"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// SpanWindow returns a copy of the trace with only its spans [offset, offset+limit), the spans being
+// ordered by start time then span ID so that consecutive windows of the same trace do not overlap.
+// A limit that is not positive keeps the spans up to the last one.
+func SpanWindow(trace *model.Trace, offset, limit int) *model.Trace {
+	spans := make([]*model.Span, len(trace.Spans))
+	copy(spans, trace.Spans)
+	sort.SliceStable(spans, func(i, j int) bool {
+		if !spans[i].StartTime.Equal(spans[j].StartTime) {
+			return spans[i].StartTime.Before(spans[j].StartTime)
+		}
+		return spans[i].SpanID < spans[j].SpanID
+	})
+
+	offset = min(max(offset, 0), len(spans))
+	end := len(spans)
+	if limit > 0 && limit < end-offset {
+		end = offset + limit
+	}
+	window := *trace
+	window.Spans = spans[offset:end]
+	return &window
+}
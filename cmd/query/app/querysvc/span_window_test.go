@@ -0,0 +1,80 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func windowSpanIDs(trace *model.Trace) []model.SpanID {
+	ids := make([]model.SpanID, 0, len(trace.Spans))
+	for _, span := range trace.Spans {
+		ids = append(ids, span.SpanID)
+	}
+	return ids
+}
+
+func TestSpanWindow(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	trace := &model.Trace{
+		Spans: []*model.Span{
+			{SpanID: 5, StartTime: start.Add(2 * time.Millisecond)},
+			{SpanID: 3, StartTime: start.Add(time.Millisecond)},
+			{SpanID: 1, StartTime: start},
+			{SpanID: 4, StartTime: start.Add(time.Millisecond)},
+			{SpanID: 2, StartTime: start.Add(time.Millisecond)},
+		},
+		Warnings: []string{"some warning"},
+	}
+
+	tests := []struct {
+		name     string
+		offset   int
+		limit    int
+		expected []model.SpanID
+	}{
+		{name: "first window", offset: 0, limit: 2, expected: []model.SpanID{1, 2}},
+		{name: "middle window", offset: 2, limit: 2, expected: []model.SpanID{3, 4}},
+		{name: "last window", offset: 4, limit: 2, expected: []model.SpanID{5}},
+		{name: "past the end", offset: 7, limit: 2, expected: []model.SpanID{}},
+		{name: "without limit", offset: 1, limit: 0, expected: []model.SpanID{2, 3, 4, 5}},
+		{name: "negative offset", offset: -1, limit: 1, expected: []model.SpanID{1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window := SpanWindow(trace, test.offset, test.limit)
+			assert.Equal(t, test.expected, windowSpanIDs(window))
+			assert.Equal(t, trace.Warnings, window.Warnings)
+		})
+	}
+	// the trace itself is left in the order of the storage
+	assert.Equal(t, []model.SpanID{5, 3, 1, 4, 2}, windowSpanIDs(trace))
+}
+
+func TestSpanWindowConsistent(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var spans []*model.Span
+	for i := 0; i < 50; i++ {
+		// many spans starting at the same time, in an order that differs between the reads
+		spans = append(spans, &model.Span{SpanID: model.SpanID(50 - i), StartTime: start.Add(time.Duration(i%3) * time.Millisecond)})
+	}
+	reversed := make([]*model.Span, len(spans))
+	for i, span := range spans {
+		reversed[len(spans)-1-i] = span
+	}
+
+	var fromFirstRead, fromSecondRead []model.SpanID
+	for offset := 0; offset < len(spans); offset += 7 {
+		fromFirstRead = append(fromFirstRead, windowSpanIDs(SpanWindow(&model.Trace{Spans: spans}, offset, 7))...)
+		fromSecondRead = append(fromSecondRead, windowSpanIDs(SpanWindow(&model.Trace{Spans: reversed}, offset, 7))...)
+	}
+	assert.Equal(t, fromFirstRead, fromSecondRead)
+	assert.Len(t, fromFirstRead, len(spans))
+	assert.ElementsMatch(t, windowSpanIDs(&model.Trace{Spans: spans}), fromFirstRead)
+}
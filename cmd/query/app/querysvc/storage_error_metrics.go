@@ -0,0 +1,43 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+// storageErrorMetrics counts errors returned by the storage readers, labeled by storage.ErrorCategory.
+type storageErrorMetrics struct {
+	Timeout     metrics.Counter `metric:"storage_errors" tags:"category=timeout"`
+	Canceled    metrics.Counter `metric:"storage_errors" tags:"category=canceled"`
+	Unavailable metrics.Counter `metric:"storage_errors" tags:"category=unavailable"`
+	NotFound    metrics.Counter `metric:"storage_errors" tags:"category=not_found"`
+	Other       metrics.Counter `metric:"storage_errors" tags:"category=other"`
+}
+
+func newStorageErrorMetrics(factory metrics.Factory) *storageErrorMetrics {
+	m := &storageErrorMetrics{}
+	metrics.Init(m, factory, nil)
+	return m
+}
+
+// record increments the counter for the category of err, if err is not nil.
+func (m *storageErrorMetrics) record(err error) {
+	if err == nil {
+		return
+	}
+	switch storage.ClassifyError(err) {
+	case storage.ErrorCategoryTimeout:
+		m.Timeout.Inc(1)
+	case storage.ErrorCategoryCanceled:
+		m.Canceled.Inc(1)
+	case storage.ErrorCategoryUnavailable:
+		m.Unavailable.Inc(1)
+	case storage.ErrorCategoryNotFound:
+		m.NotFound.Inc(1)
+	default:
+		m.Other.Inc(1)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// The functions below translate between the names seen by the tenants and the names stored with the
+// prefix of their tenant, see tenancy.Options.StoragePrefix. They do nothing when the names are not prefixed.
+
+// toStorageQuery returns the query with the service and operation names prefixed for the tenant.
+func (qs QueryService) toStorageQuery(ctx context.Context, query *spanstore.TraceQueryParameters) *spanstore.TraceQueryParameters {
+	if !qs.options.TenancyMgr.HasStoragePrefix(ctx) {
+		return query
+	}
+	storageQuery := *query
+	storageQuery.ServiceName = qs.options.TenancyMgr.ToStorageName(ctx, query.ServiceName)
+	storageQuery.OperationName = qs.options.TenancyMgr.ToStorageName(ctx, query.OperationName)
+	return &storageQuery
+}
+
+// fromStorageTrace strips the prefix of the tenant from the service and operation names of the spans.
+func (qs QueryService) fromStorageTrace(ctx context.Context, trace *model.Trace) {
+	if !qs.options.TenancyMgr.HasStoragePrefix(ctx) {
+		return
+	}
+	for _, span := range trace.Spans {
+		span.OperationName, _ = qs.options.TenancyMgr.FromStorageName(ctx, span.OperationName)
+		if span.Process != nil {
+			span.Process.ServiceName, _ = qs.options.TenancyMgr.FromStorageName(ctx, span.Process.ServiceName)
+		}
+	}
+}
+
+// fromStorageServices keeps the services of the tenant, without their prefix.
+func (qs QueryService) fromStorageServices(ctx context.Context, services []string) []string {
+	if !qs.options.TenancyMgr.HasStoragePrefix(ctx) {
+		return services
+	}
+	tenantServices := make([]string, 0, len(services))
+	for _, service := range services {
+		if name, ok := qs.options.TenancyMgr.FromStorageName(ctx, service); ok {
+			tenantServices = append(tenantServices, name)
+		}
+	}
+	return tenantServices
+}
+
+// fromStorageOperations strips the prefix of the tenant from the operation names.
+func (qs QueryService) fromStorageOperations(ctx context.Context, operations []spanstore.Operation) {
+	if !qs.options.TenancyMgr.HasStoragePrefix(ctx) {
+		return
+	}
+	for i := range operations {
+		operations[i].Name, _ = qs.options.TenancyMgr.FromStorageName(ctx, operations[i].Name)
+	}
+}
+
+// fromStorageDependencies keeps the dependencies between the services of the tenant, without their prefix.
+func (qs QueryService) fromStorageDependencies(ctx context.Context, dependencies []model.DependencyLink) []model.DependencyLink {
+	if !qs.options.TenancyMgr.HasStoragePrefix(ctx) {
+		return dependencies
+	}
+	tenantDependencies := make([]model.DependencyLink, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		parent, parentOK := qs.options.TenancyMgr.FromStorageName(ctx, dependency.Parent)
+		child, childOK := qs.options.TenancyMgr.FromStorageName(ctx, dependency.Child)
+		if parentOK && childOK {
+			dependency.Parent, dependency.Child = parent, child
+			tenantDependencies = append(tenantDependencies, dependency)
+		}
+	}
+	return tenantDependencies
+}
+
+// fromStorageOperationDependencies keeps the operation dependencies between the services of the tenant,
+// without the prefix of their services and operations.
+func (qs QueryService) fromStorageOperationDependencies(
+	ctx context.Context,
+	dependencies []dependencystore.OperationDependencyLink,
+) []dependencystore.OperationDependencyLink {
+	if !qs.options.TenancyMgr.HasStoragePrefix(ctx) {
+		return dependencies
+	}
+	fromStorageOperation := func(operation string) string {
+		// the operations merged by the storage are not prefixed
+		if operation == dependencystore.OtherOperations {
+			return operation
+		}
+		name, _ := qs.options.TenancyMgr.FromStorageName(ctx, operation)
+		return name
+	}
+	tenantDependencies := make([]dependencystore.OperationDependencyLink, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		parent, parentOK := qs.options.TenancyMgr.FromStorageName(ctx, dependency.Parent)
+		child, childOK := qs.options.TenancyMgr.FromStorageName(ctx, dependency.Child)
+		if parentOK && childOK {
+			dependency.Parent, dependency.Child = parent, child
+			dependency.ParentOperation = fromStorageOperation(dependency.ParentOperation)
+			dependency.ChildOperation = fromStorageOperation(dependency.ChildOperation)
+			tenantDependencies = append(tenantDependencies, dependency)
+		}
+	}
+	return tenantDependencies
+}
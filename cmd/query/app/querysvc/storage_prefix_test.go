@@ -0,0 +1,119 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func withStoragePrefix() testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.TenancyMgr = tenancy.NewManager(&tenancy.Options{Enabled: true, StoragePrefix: "{tenant}."})
+	}
+}
+
+func prefixedTrace(service, operation string) *model.Trace {
+	return &model.Trace{Spans: []*model.Span{{
+		TraceID:       mockTraceID,
+		OperationName: operation,
+		Process:       &model.Process{ServiceName: service},
+	}}}
+}
+
+func TestStoragePrefixGetServices(t *testing.T) {
+	tqs := initializeTestService(withStoragePrefix())
+	tqs.spanReader.On("GetServices", mock.Anything).
+		Return([]string{"acme.frontend", "megacorp.frontend", "acme.backend", "shared"}, nil)
+
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	services, err := tqs.queryService.GetServices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "backend"}, services)
+
+	// without a tenant, the names are returned as stored
+	services, err = tqs.queryService.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, services, 4)
+}
+
+func TestStoragePrefixGetOperations(t *testing.T) {
+	tqs := initializeTestService(withStoragePrefix())
+	tqs.spanReader.On("GetOperations", mock.Anything, spanstore.OperationQueryParameters{ServiceName: "acme.frontend"}).
+		Return([]spanstore.Operation{{Name: "acme.GET"}, {Name: "POST"}}, nil)
+
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	operations, err := tqs.queryService.GetOperations(ctx, spanstore.OperationQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{{Name: "GET"}, {Name: "POST"}}, operations)
+}
+
+func TestStoragePrefixFindTraces(t *testing.T) {
+	tqs := initializeTestService(withStoragePrefix())
+	query := &spanstore.TraceQueryParameters{
+		ServiceName:   "frontend",
+		OperationName: "GET",
+		StartTimeMin:  time.Now().Add(-time.Hour),
+		StartTimeMax:  time.Now(),
+	}
+	storageQuery := *query
+	storageQuery.ServiceName = "acme.frontend"
+	storageQuery.OperationName = "acme.GET"
+	tqs.spanReader.On("FindTraces", mock.Anything, &storageQuery).
+		Return([]*model.Trace{prefixedTrace("acme.frontend", "acme.GET")}, nil)
+
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	traces, err := tqs.queryService.FindTraces(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, "frontend", traces[0].Spans[0].Process.ServiceName)
+	assert.Equal(t, "GET", traces[0].Spans[0].OperationName)
+	// the query of the caller is left as is
+	assert.Equal(t, "frontend", query.ServiceName)
+}
+
+func TestStoragePrefixGetTrace(t *testing.T) {
+	tqs := initializeTestService(withStoragePrefix())
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).
+		Return(prefixedTrace("acme.frontend", "acme.GET"), nil).Once()
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).
+		Return(prefixedTrace("acme.frontend", "acme.GET"), nil).Once()
+
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	trace, err := tqs.queryService.GetTrace(ctx, mockTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", trace.Spans[0].Process.ServiceName)
+	assert.Equal(t, "GET", trace.Spans[0].OperationName)
+
+	traces, err := tqs.queryService.GetTraces(ctx, []model.TraceID{mockTraceID})
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", traces[0].Spans[0].Process.ServiceName)
+}
+
+func TestStoragePrefixGetDependencies(t *testing.T) {
+	depsReader := &depsmocks.Reader{}
+	depsReader.On("GetDependencies", mock.Anything, mock.Anything, mock.Anything).Return([]model.DependencyLink{
+		{Parent: "acme.frontend", Child: "acme.backend", CallCount: 1},
+		{Parent: "acme.frontend", Child: "megacorp.backend", CallCount: 2},
+	}, nil)
+	qs := NewQueryService(&spanstoremocks.Reader{}, depsReader, QueryServiceOptions{
+		TenancyMgr: tenancy.NewManager(&tenancy.Options{Enabled: true, StoragePrefix: "{tenant}."}),
+	})
+
+	ctx := tenancy.WithTenant(context.Background(), "acme")
+	dependencies, err := qs.GetDependencies(ctx, time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{{Parent: "frontend", Child: "backend", CallCount: 1}}, dependencies)
+}
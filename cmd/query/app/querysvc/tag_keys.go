@@ -0,0 +1,131 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// DefaultTagKeysLimit is the number of tag keys returned, unless the request specifies it.
+	DefaultTagKeysLimit = 100
+	// MaxTagKeysLimit caps the number of tag keys returned.
+	MaxTagKeysLimit = 1000
+	// DefaultTagKeysWindow is the time window before now of the spans whose tag keys are returned,
+	// unless the request specifies it.
+	DefaultTagKeysWindow = time.Hour
+	// TagKeysSampleTraces is the number of recent traces sampled for their tag keys
+	// when the span storage cannot enumerate them.
+	TagKeysSampleTraces = 100
+)
+
+// TagKeys are the distinct tag keys of the spans of a service, sorted.
+type TagKeys struct {
+	Keys []string
+	// Approximate tells whether the keys are collected from a sample of the traces,
+	// some keys of the service possibly missing.
+	Approximate bool
+	// Truncated tells whether more keys than returned were found.
+	Truncated bool
+}
+
+// GetTagKeys returns the distinct tag keys of the spans of the service started within the window before now,
+// DefaultTagKeysWindow if the window is not positive. At most limit keys are returned, DefaultTagKeysLimit
+// if not positive, capped to MaxTagKeysLimit. The keys are enumerated by the span storage when it supports it,
+// and otherwise collected from a sample of the recent traces of the service, the keys being then approximate.
+func (qs QueryService) GetTagKeys(ctx context.Context, service string, window time.Duration, limit int) (*TagKeys, error) {
+	if service == "" {
+		return nil, errors.New("service name is required")
+	}
+	if window <= 0 {
+		window = DefaultTagKeysWindow
+	}
+	switch {
+	case limit <= 0:
+		limit = DefaultTagKeysLimit
+	case limit > MaxTagKeysLimit:
+		AddWarning(ctx, fmt.Sprintf("tag keys limit %d reduced to the maximum of %d", limit, MaxTagKeysLimit))
+		limit = MaxTagKeysLimit
+	}
+	end := time.Now()
+	start := end.Add(-window)
+
+	tagKeys := &TagKeys{}
+	keys, err := qs.readTagKeys(ctx, service, start, end, limit)
+	if errors.Is(err, errors.ErrUnsupported) {
+		keys, err = qs.sampleTagKeys(ctx, service, start, end)
+		tagKeys.Approximate = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > limit {
+		keys, tagKeys.Truncated = keys[:limit], true
+	}
+	tagKeys.Keys = keys
+	return tagKeys, nil
+}
+
+// readTagKeys returns the tag keys enumerated by the span storage, errors.ErrUnsupported if it cannot.
+func (qs QueryService) readTagKeys(ctx context.Context, service string, start, end time.Time, limit int) ([]string, error) {
+	tagKeysReader, ok := qs.spanReader.(spanstore.TagKeysReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	ctx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.FindTraces)
+	defer cancel()
+	keys, err := tagKeysReader.GetTagKeys(ctx, spanstore.TagKeysQueryParameters{
+		ServiceName:  qs.options.TenancyMgr.ToStorageName(ctx, service),
+		StartTimeMin: start,
+		StartTimeMax: end,
+		// one more key tells whether there are more keys than returned
+		Limit: limit + 1,
+	})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		qs.errorMetrics.record(err)
+	}
+	return keys, err
+}
+
+// sampleTagKeys returns the sorted tag keys of the spans of the service in a sample of its recent traces.
+func (qs QueryService) sampleTagKeys(ctx context.Context, service string, start, end time.Time) ([]string, error) {
+	traces, err := qs.FindTraces(ctx, &spanstore.TraceQueryParameters{
+		ServiceName:  service,
+		StartTimeMin: start,
+		StartTimeMax: end,
+		NumTraces:    TagKeysSampleTraces,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collectTagKeys(traces, service), nil
+}
+
+// collectTagKeys returns the sorted distinct tag keys of the spans of the service.
+func collectTagKeys(traces []*model.Trace, service string) []string {
+	seen := make(map[string]struct{})
+	for _, trace := range traces {
+		for _, span := range trace.Spans {
+			if span.Process == nil || span.Process.ServiceName != service {
+				continue
+			}
+			for _, tag := range span.Tags {
+				seen[tag.Key] = struct{}{}
+			}
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
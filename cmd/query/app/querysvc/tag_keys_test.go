@@ -0,0 +1,103 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// tagKeyTraces returns traces whose "shop" spans share the http.method and span.kind tags,
+// a span of another service having its own tags.
+func tagKeyTraces() []*model.Trace {
+	traces := make([]*model.Trace, 10)
+	for i := range traces {
+		traceID := model.NewTraceID(0, uint64(i+1))
+		tags := model.KeyValues{model.String("span.kind", "server"), model.String("http.method", "GET")}
+		if i%2 == 0 {
+			tags = append(tags, model.String("http.status_code", "200"))
+		}
+		traces[i] = &model.Trace{Spans: []*model.Span{
+			{TraceID: traceID, SpanID: 1, Tags: tags, Process: &model.Process{ServiceName: "shop"}},
+			{TraceID: traceID, SpanID: 2, Tags: model.KeyValues{model.String("db.statement", "select")}, Process: &model.Process{ServiceName: "db"}},
+		}}
+	}
+	return traces
+}
+
+func TestGetTagKeysSampled(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.MatchedBy(func(query *spanstore.TraceQueryParameters) bool {
+		return query.ServiceName == "shop" && query.NumTraces == TagKeysSampleTraces &&
+			query.StartTimeMax.Sub(query.StartTimeMin) == DefaultTagKeysWindow
+	})).Return(tagKeyTraces(), nil).Once()
+
+	tagKeys, err := tqs.queryService.GetTagKeys(context.Background(), "shop", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &TagKeys{
+		Keys:        []string{"http.method", "http.status_code", "span.kind"},
+		Approximate: true,
+	}, tagKeys)
+}
+
+func TestGetTagKeysLimit(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(tagKeyTraces(), nil)
+
+	tagKeys, err := tqs.queryService.GetTagKeys(context.Background(), "shop", time.Minute, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http.method", "http.status_code"}, tagKeys.Keys)
+	assert.True(t, tagKeys.Truncated)
+
+	ctx := ContextWithWarnings(context.Background())
+	tagKeys, err = tqs.queryService.GetTagKeys(ctx, "shop", time.Minute, MaxTagKeysLimit+1)
+	require.NoError(t, err)
+	assert.False(t, tagKeys.Truncated)
+	assert.Equal(t, []string{fmt.Sprintf("tag keys limit %d reduced to the maximum of %d", MaxTagKeysLimit+1, MaxTagKeysLimit)}, GetWarnings(ctx))
+}
+
+func TestGetTagKeysNative(t *testing.T) {
+	store := memory.NewStore()
+	for _, trace := range tagKeyTraces() {
+		for _, span := range trace.Spans {
+			span.StartTime = time.Now().Add(-time.Minute)
+			require.NoError(t, store.WriteSpan(context.Background(), span))
+		}
+	}
+	qs := NewQueryService(store, nil, QueryServiceOptions{})
+
+	tagKeys, err := qs.GetTagKeys(context.Background(), "shop", time.Hour, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &TagKeys{Keys: []string{"http.method", "http.status_code", "span.kind"}}, tagKeys)
+
+	tagKeys, err = qs.GetTagKeys(context.Background(), "shop", time.Hour, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &TagKeys{Keys: []string{"http.method"}, Truncated: true}, tagKeys)
+
+	// the spans started before the window are left out
+	tagKeys, err = qs.GetTagKeys(context.Background(), "shop", time.Second, 0)
+	require.NoError(t, err)
+	assert.Empty(t, tagKeys.Keys)
+}
+
+func TestGetTagKeysErrors(t *testing.T) {
+	tqs := initializeTestService()
+	_, err := tqs.queryService.GetTagKeys(context.Background(), "", 0, 0)
+	require.EqualError(t, err, "service name is required")
+
+	tqs.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return(nil, errors.New("storage error")).Once()
+	_, err = tqs.queryService.GetTagKeys(context.Background(), "shop", 0, 0)
+	require.EqualError(t, err, "storage error")
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"time"
+)
+
+// QueryTimeouts limits the duration of the storage queries of each endpoint.
+// A zero timeout falls back to Default, and a zero Default means no timeout.
+type QueryTimeouts struct {
+	Default      time.Duration
+	Services     time.Duration
+	Operations   time.Duration
+	FindTraces   time.Duration
+	GetTrace     time.Duration
+	Dependencies time.Duration
+}
+
+// withTimeout returns a context bounded by the given endpoint timeout, or by the default one when it is unset.
+func (t QueryTimeouts) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = t.Default
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
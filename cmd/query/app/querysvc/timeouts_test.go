@@ -0,0 +1,73 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func withTimeouts(timeouts QueryTimeouts) testOption {
+	return func(_ *testQueryService, options *QueryServiceOptions) {
+		options.Timeouts = timeouts
+	}
+}
+
+// waitForDeadline simulates a slow storage query that only returns when the context is done.
+func waitForDeadline(args mock.Arguments) {
+	<-args.Get(0).(context.Context).Done()
+}
+
+func TestGetServicesTimeout(t *testing.T) {
+	tqs := initializeTestService(withTimeouts(QueryTimeouts{
+		Default:  time.Hour,
+		Services: 10 * time.Millisecond,
+	}))
+	tqs.spanReader.On("GetServices", mock.Anything).Run(waitForDeadline).Return(nil, context.DeadlineExceeded).Once()
+
+	start := time.Now()
+	_, err := tqs.queryService.GetServices(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Hour)
+}
+
+func TestGetTraceDefaultTimeout(t *testing.T) {
+	tqs := initializeTestService(withTimeouts(QueryTimeouts{
+		Default:  10 * time.Millisecond,
+		Services: time.Hour,
+	}))
+	tqs.spanReader.On("GetTrace", mock.Anything, mockTraceID).Run(waitForDeadline).Return(nil, context.DeadlineExceeded).Once()
+
+	_, err := tqs.queryService.GetTrace(context.Background(), mockTraceID)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestQueryTimeoutsWithTimeout(t *testing.T) {
+	testCases := []struct {
+		name        string
+		timeouts    QueryTimeouts
+		timeout     time.Duration
+		hasDeadline bool
+	}{
+		{name: "no timeouts", timeouts: QueryTimeouts{}},
+		{name: "endpoint timeout", timeouts: QueryTimeouts{}, timeout: time.Minute, hasDeadline: true},
+		{name: "default timeout", timeouts: QueryTimeouts{Default: time.Minute}, hasDeadline: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := tc.timeouts.withTimeout(context.Background(), tc.timeout)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			assert.Equal(t, tc.hasDeadline, ok)
+			if ok {
+				assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// filterCandidatesFactor is how many more traces than requested are searched
+// when the traces are filtered after the search, to make up for the traces filtered out.
+const filterCandidatesFactor = 10
+
+// TraceFilter selects the traces found by FindTracesWithFilter by criteria which
+// the storage backends rarely index, so that they are applied after the search.
+type TraceFilter struct {
+	SpanCount   SpanCountFilter
+	Error       ErrorFilter
+	ServiceEdge ServiceEdgeFilter
+}
+
+func (f TraceFilter) enabled() bool {
+	return f.SpanCount.enabled() || f.Error.enabled() || f.ServiceEdge.enabled()
+}
+
+// count returns the number of the criteria of the filter which are enabled.
+func (f TraceFilter) count() int {
+	count := 0
+	for _, enabled := range []bool{f.SpanCount.enabled(), f.Error.enabled(), f.ServiceEdge.enabled()} {
+		if enabled {
+			count++
+		}
+	}
+	return count
+}
+
+func (f TraceFilter) matches(trace *model.Trace) bool {
+	if f.SpanCount.enabled() && !f.SpanCount.matches(trace) {
+		return false
+	}
+	if f.Error.enabled() && !f.Error.matches(trace) {
+		return false
+	}
+	return !f.ServiceEdge.enabled() || f.ServiceEdge.matches(trace)
+}
+
+// apply returns the traces matching the filter, preserving their order.
+func (f TraceFilter) apply(traces []*model.Trace) []*model.Trace {
+	filtered := traces[:0]
+	for _, trace := range traces {
+		if f.matches(trace) {
+			filtered = append(filtered, trace)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// ParseTraceID parses a hexadecimal trace ID as entered by users or sent by API clients.
+// Both 64-bit and 128-bit IDs are accepted, with or without leading zeros, as well as
+// the value of a W3C traceparent header (version-traceid-parentid-flags).
+func ParseTraceID(s string) (model.TraceID, error) {
+	s = strings.TrimSpace(s)
+	if parts := strings.Split(s, "-"); len(parts) == 4 && len(parts[0]) == 2 && len(parts[1]) == 32 {
+		s = parts[1]
+	}
+	if len(s) > 32 {
+		// zero padding beyond 128 bits does not change the ID
+		s = strings.TrimLeft(s, "0")
+		if s == "" {
+			s = "0"
+		}
+	}
+	return model.TraceIDFromString(s)
+}
+
+// compatibleTraceID returns the alternative form of a trace ID under which the trace may have
+// been stored by clients that only support 64-bit trace IDs, i.e. the lower 64 bits of a 128-bit ID.
+// A 64-bit ID and its zero-padded 128-bit form are the same model.TraceID, so they have no alternative.
+func compatibleTraceID(traceID model.TraceID) (model.TraceID, bool) {
+	if traceID.High == 0 {
+		return model.TraceID{}, false
+	}
+	return model.NewTraceID(0, traceID.Low), true
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// TraceIDPrefixLimit is the maximum number of traces returned by SearchByTraceIDPrefix.
+const TraceIDPrefixLimit = 10
+
+// ErrTraceIDPrefixUnsupported is returned by SearchByTraceIDPrefix when the span storage cannot find traces by a prefix of their IDs.
+var ErrTraceIDPrefixUnsupported = errors.New("the span storage does not support searching traces by trace ID prefix")
+
+// ParseTraceIDPrefix parses a prefix of a hexadecimal trace ID, as formatted by model.TraceID.String,
+// returning it in lowercase.
+func ParseTraceIDPrefix(s string) (string, error) {
+	prefix := strings.ToLower(strings.TrimSpace(s))
+	if prefix == "" || len(prefix) > 32 {
+		return "", fmt.Errorf("trace ID prefix must have 1 to 32 hexadecimal characters: %q", s)
+	}
+	for _, c := range prefix {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", fmt.Errorf("trace ID prefix must have 1 to 32 hexadecimal characters: %q", s)
+		}
+	}
+	return prefix, nil
+}
+
+// SearchByTraceIDPrefix returns the traces whose ID starts with the prefix, e.g. to look up a trace whose ID
+// was truncated. A prefix may match several traces, which are all returned, up to TraceIDPrefixLimit traces;
+// truncated is true when more traces match. The prefix must be parsed with ParseTraceIDPrefix.
+func (qs QueryService) SearchByTraceIDPrefix(ctx context.Context, prefix string) (traces []*model.Trace, truncated bool, err error) {
+	prefixReader, ok := qs.spanReader.(spanstore.TraceIDPrefixReader)
+	if !ok {
+		return nil, false, ErrTraceIDPrefixUnsupported
+	}
+	limit := TraceIDPrefixLimit
+	if qs.options.MaxBatchTraces > 0 {
+		limit = min(limit, qs.options.MaxBatchTraces)
+	}
+	findCtx, cancel := qs.options.Timeouts.withTimeout(ctx, qs.options.Timeouts.FindTraces)
+	defer cancel()
+	// one more trace ID tells whether there are more matching traces than returned
+	traceIDs, err := prefixReader.FindTraceIDsByPrefix(findCtx, prefix, limit+1)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return nil, false, ErrTraceIDPrefixUnsupported
+	}
+	qs.errorMetrics.record(err)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(traceIDs) > limit {
+		traceIDs, truncated = traceIDs[:limit], true
+	}
+	found, err := qs.GetTraces(ctx, traceIDs)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, trace := range found {
+		// the traces may have expired since their IDs were found
+		if trace != nil {
+			traces = append(traces, trace)
+		}
+	}
+	return traces, truncated, nil
+}
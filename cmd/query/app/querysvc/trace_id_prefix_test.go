@@ -0,0 +1,117 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func TestParseTraceIDPrefix(t *testing.T) {
+	prefix, err := ParseTraceIDPrefix(" ABcd1234 ")
+	require.NoError(t, err)
+	assert.Equal(t, "abcd1234", prefix)
+
+	for _, input := range []string{"", "abcg", "000000000000000000000000000000000"} {
+		_, err := ParseTraceIDPrefix(input)
+		require.ErrorContains(t, err, "trace ID prefix must have 1 to 32 hexadecimal characters", input)
+	}
+}
+
+func newPrefixTestStore(t *testing.T, traceIDs ...model.TraceID) *memory.Store {
+	store := memory.NewStore()
+	for _, traceID := range traceIDs {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID: traceID,
+			SpanID:  model.NewSpanID(1),
+			Process: model.NewProcess("service", nil),
+		}))
+	}
+	return store
+}
+
+func traceIDsOf(traces []*model.Trace) []model.TraceID {
+	traceIDs := make([]model.TraceID, len(traces))
+	for i, trace := range traces {
+		traceIDs[i] = trace.Spans[0].TraceID
+	}
+	return traceIDs
+}
+
+func TestSearchByTraceIDPrefix(t *testing.T) {
+	first := model.NewTraceID(0xabcd1234aaaaaaaa, 1)
+	second := model.NewTraceID(0xabcd1234bbbbbbbb, 1)
+	other := model.NewTraceID(0xabcd5678aaaaaaaa, 1)
+	qs := NewQueryService(newPrefixTestStore(t, first, second, other), nil, QueryServiceOptions{})
+
+	traces, truncated, err := qs.SearchByTraceIDPrefix(context.Background(), "abcd1234a")
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, []model.TraceID{first}, traceIDsOf(traces))
+
+	// an ambiguous prefix returns all the candidates
+	traces, truncated, err = qs.SearchByTraceIDPrefix(context.Background(), "abcd1234")
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, []model.TraceID{first, second}, traceIDsOf(traces))
+
+	traces, truncated, err = qs.SearchByTraceIDPrefix(context.Background(), "ffff")
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Empty(t, traces)
+}
+
+func TestSearchByTraceIDPrefixLimit(t *testing.T) {
+	traceIDs := make([]model.TraceID, TraceIDPrefixLimit+1)
+	for i := range traceIDs {
+		traceIDs[i] = model.NewTraceID(0xabcd000000000000, uint64(i+1))
+	}
+	qs := NewQueryService(newPrefixTestStore(t, traceIDs...), nil, QueryServiceOptions{})
+	traces, truncated, err := qs.SearchByTraceIDPrefix(context.Background(), "abcd")
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, traceIDs[:TraceIDPrefixLimit], traceIDsOf(traces))
+
+	// the batches of traces are not larger than allowed
+	qs = NewQueryService(newPrefixTestStore(t, traceIDs...), nil, QueryServiceOptions{MaxBatchTraces: 2})
+	traces, truncated, err = qs.SearchByTraceIDPrefix(context.Background(), "abcd")
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, traceIDs[:2], traceIDsOf(traces))
+}
+
+type prefixReader struct {
+	*spanstoremocks.Reader
+}
+
+func (r prefixReader) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	args := r.Called(ctx, prefix, limit)
+	traceIDs, _ := args.Get(0).([]model.TraceID)
+	return traceIDs, args.Error(1)
+}
+
+func TestSearchByTraceIDPrefixErrors(t *testing.T) {
+	qs := NewQueryService(&spanstoremocks.Reader{}, nil, QueryServiceOptions{})
+	_, _, err := qs.SearchByTraceIDPrefix(context.Background(), "abcd")
+	require.ErrorIs(t, err, ErrTraceIDPrefixUnsupported)
+
+	reader := &spanstoremocks.Reader{}
+	qs = NewQueryService(prefixReader{reader}, nil, QueryServiceOptions{})
+	reader.On("FindTraceIDsByPrefix", mock.Anything, "abcd", TraceIDPrefixLimit+1).Return(nil, errors.ErrUnsupported).Once()
+	_, _, err = qs.SearchByTraceIDPrefix(context.Background(), "abcd")
+	require.ErrorIs(t, err, ErrTraceIDPrefixUnsupported)
+
+	reader.On("FindTraceIDsByPrefix", mock.Anything, "abcd", TraceIDPrefixLimit+1).Return(nil, errors.New("storage failure")).Once()
+	_, _, err = qs.SearchByTraceIDPrefix(context.Background(), "abcd")
+	require.EqualError(t, err, "storage failure")
+}
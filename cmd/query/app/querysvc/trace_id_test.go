@@ -0,0 +1,66 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestParseTraceID(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected model.TraceID
+		err      bool
+	}{
+		{input: "1e240", expected: model.NewTraceID(0, 123456)},
+		{input: "000000000001e240", expected: model.NewTraceID(0, 123456)},
+		{input: "0000000000000000000000000001e240", expected: model.NewTraceID(0, 123456)},
+		{input: "000000000000000000000000000000000001e240", expected: model.NewTraceID(0, 123456)},
+		{input: "1000000000001e240", expected: model.NewTraceID(1, 123456)},
+		{input: "0000000000000001000000000001E240", expected: model.NewTraceID(1, 123456)},
+		{input: " 1e240 ", expected: model.NewTraceID(0, 123456)},
+		{input: "00-0000000000000001000000000001e240-00f067aa0ba902b7-01", expected: model.NewTraceID(1, 123456)},
+		{input: "000000000000000000000000000000000000", expected: model.NewTraceID(0, 0)},
+		{input: "", err: true},
+		{input: "x1e240", err: true},
+		{input: "00-xyz-00f067aa0ba902b7-01", err: true},
+		{input: "10000000000000000000000000000001e240", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			traceID, err := ParseTraceID(tc.input)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, traceID)
+		})
+	}
+}
+
+func TestCompatibleTraceID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		traceID  model.TraceID
+		expected model.TraceID
+		ok       bool
+	}{
+		{name: "64-bit", traceID: model.NewTraceID(0, 123456)},
+		{name: "128-bit", traceID: model.NewTraceID(1, 123456), expected: model.NewTraceID(0, 123456), ok: true},
+		{name: "128-bit with zero low word", traceID: model.NewTraceID(1, 0), expected: model.NewTraceID(0, 0), ok: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			traceID, ok := compatibleTraceID(tc.traceID)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, traceID)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// readTrace loads a trace from the reader, assembling its pages when the reader supports spanstore.PagedReader,
+// and truncates it to MaxTraceSpans with a warning.
+func (qs QueryService) readTrace(ctx context.Context, reader spanstore.Reader, traceID model.TraceID) (*model.Trace, error) {
+	var trace *model.Trace
+	var err error
+	if pagedReader, ok := reader.(spanstore.PagedReader); ok {
+		trace, err = qs.readTracePages(ctx, pagedReader, traceID)
+	} else {
+		err = errors.ErrUnsupported
+	}
+	if errors.Is(err, errors.ErrUnsupported) {
+		trace, err = reader.GetTrace(ctx, traceID)
+	}
+	if err != nil || trace == nil {
+		return trace, err
+	}
+	if maxSpans := qs.options.MaxTraceSpans; maxSpans > 0 && len(trace.Spans) > maxSpans {
+		trace.Spans = trace.Spans[:maxSpans]
+		AddWarning(ctx, fmt.Sprintf("trace %s truncated to the maximum of %d spans", traceID, maxSpans))
+	}
+	return trace, nil
+}
+
+// readTracePages fetches the pages of a trace until the last one, or until the trace exceeds MaxTraceSpans.
+func (qs QueryService) readTracePages(ctx context.Context, reader spanstore.PagedReader, traceID model.TraceID) (*model.Trace, error) {
+	trace := &model.Trace{}
+	pageToken := ""
+	for {
+		page, nextPageToken, err := reader.GetTracePage(ctx, traceID, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		if page != nil {
+			trace.Spans = append(trace.Spans, page.Spans...)
+			trace.ProcessMap = append(trace.ProcessMap, page.ProcessMap...)
+			trace.Warnings = append(trace.Warnings, page.Warnings...)
+		}
+		if nextPageToken == "" {
+			return trace, nil
+		}
+		if nextPageToken == pageToken {
+			return nil, fmt.Errorf("span reader returned the page token %q again for trace %s", pageToken, traceID)
+		}
+		if maxSpans := qs.options.MaxTraceSpans; maxSpans > 0 && len(trace.Spans) > maxSpans {
+			return trace, nil
+		}
+		pageToken = nextPageToken
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+// pagedReader is a span reader implementing spanstore.PagedReader.
+type pagedReader struct {
+	*spanstoremocks.Reader
+}
+
+func (r pagedReader) GetTracePage(ctx context.Context, traceID model.TraceID, pageToken string) (*model.Trace, string, error) {
+	args := r.Called(ctx, traceID, pageToken)
+	page, _ := args.Get(0).(*model.Trace)
+	return page, args.String(1), args.Error(2)
+}
+
+func withPagedReader(maxTraceSpans int) testOption {
+	return func(tqs *testQueryService, options *QueryServiceOptions) {
+		tqs.pagedReader = true
+		options.MaxTraceSpans = maxTraceSpans
+	}
+}
+
+func tracePage(traceID model.TraceID, spanIDs ...uint64) *model.Trace {
+	page := &model.Trace{}
+	for _, spanID := range spanIDs {
+		page.Spans = append(page.Spans, &model.Span{TraceID: traceID, SpanID: model.NewSpanID(spanID), Process: &model.Process{}})
+	}
+	return page
+}
+
+func TestGetTracePages(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	tqs := initializeTestService(withPagedReader(0))
+	tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "").Return(tracePage(traceID, 1, 2), "p2", nil).Once()
+	tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "p2").Return(tracePage(traceID, 3, 4), "p3", nil).Once()
+	tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "p3").Return(tracePage(traceID, 5), "", nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	trace, err := tqs.queryService.GetTrace(ctx, traceID)
+	require.NoError(t, err)
+	assert.Equal(t, tracePage(traceID, 1, 2, 3, 4, 5).Spans, trace.Spans)
+	assert.Empty(t, GetWarnings(ctx))
+	tqs.spanReader.AssertNotCalled(t, "GetTrace", mock.Anything, mock.Anything)
+}
+
+func TestGetTracePagesTruncated(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	tqs := initializeTestService(withPagedReader(3))
+	tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "").Return(tracePage(traceID, 1, 2), "p2", nil).Once()
+	tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "p2").Return(tracePage(traceID, 3, 4), "p3", nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	trace, err := tqs.queryService.GetTrace(ctx, traceID)
+	require.NoError(t, err)
+	assert.Equal(t, tracePage(traceID, 1, 2, 3).Spans, trace.Spans)
+	assert.Equal(t, []string{"trace 0000000000000001 truncated to the maximum of 3 spans"}, GetWarnings(ctx))
+	tqs.spanReader.AssertNotCalled(t, "GetTracePage", mock.Anything, traceID, "p3")
+}
+
+func TestGetTracePagesErrors(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	t.Run("not found", func(t *testing.T) {
+		tqs := initializeTestService(withPagedReader(0))
+		tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "").Return(nil, "", spanstore.ErrTraceNotFound).Once()
+		_, err := tqs.queryService.GetTrace(context.Background(), traceID)
+		require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		tqs := initializeTestService(withPagedReader(0))
+		tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "").Return(nil, "", errors.ErrUnsupported).Once()
+		tqs.spanReader.On("GetTrace", mock.Anything, traceID).Return(tracePage(traceID, 1), nil).Once()
+		trace, err := tqs.queryService.GetTrace(context.Background(), traceID)
+		require.NoError(t, err)
+		assert.Equal(t, tracePage(traceID, 1).Spans, trace.Spans)
+	})
+	t.Run("repeated page token", func(t *testing.T) {
+		tqs := initializeTestService(withPagedReader(0))
+		tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "").Return(tracePage(traceID, 1), "p2", nil).Once()
+		tqs.spanReader.On("GetTracePage", mock.Anything, traceID, "p2").Return(tracePage(traceID, 2), "p2", nil).Once()
+		_, err := tqs.queryService.GetTrace(context.Background(), traceID)
+		require.EqualError(t, err, `span reader returned the page token "p2" again for trace 0000000000000001`)
+	})
+}
+
+func TestGetTraceTruncatedWithoutPages(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MaxTraceSpans = 2
+	})
+	tqs.spanReader.On("GetTrace", mock.Anything, traceID).Return(tracePage(traceID, 1, 2, 3), nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	trace, err := tqs.queryService.GetTrace(ctx, traceID)
+	require.NoError(t, err)
+	assert.Equal(t, tracePage(traceID, 1, 2).Spans, trace.Spans)
+	assert.Equal(t, []string{"trace 0000000000000001 truncated to the maximum of 2 spans"}, GetWarnings(ctx))
+}
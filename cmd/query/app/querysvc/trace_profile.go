@@ -0,0 +1,105 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// TraceProfile is the expected structure of a trace, e.g. a baseline asserted by CI.
+type TraceProfile struct {
+	// Operations are the operations required in the trace.
+	Operations []ProfileOperation
+}
+
+// ProfileOperation is an operation required in a trace.
+type ProfileOperation struct {
+	// ServiceName is the service of the operation, the operation matches any service if it is empty.
+	ServiceName string
+	// OperationName is the name of the operation.
+	OperationName string
+	// MaxDuration bounds the duration of every span of the operation, 0 means no bound.
+	MaxDuration time.Duration
+}
+
+// ProfileCheck is the outcome of checking an operation of a profile against a trace.
+type ProfileCheck struct {
+	Operation ProfileOperation
+	Passed    bool
+	// Spans is the number of spans of the operation in the trace.
+	Spans int
+	// LongestDuration is the duration of the longest span of the operation.
+	LongestDuration time.Duration
+	// OnCriticalPath tells whether a span of the operation is on the critical path of the trace,
+	// i.e. whether its latency contributes to the latency of the trace.
+	OnCriticalPath bool
+	// Message explains why the check failed.
+	Message string
+}
+
+// ProfileComparison is the outcome of comparing a trace against a profile,
+// which passes when all the checks pass.
+type ProfileComparison struct {
+	Passed bool
+	Checks []ProfileCheck
+}
+
+// CompareTraceProfile checks that the operations of the profile are present in the trace,
+// within their latency bounds.
+func CompareTraceProfile(trace *model.Trace, profile TraceProfile) *ProfileComparison {
+	return compareTraceProfile(trace, profile, CriticalPath(trace))
+}
+
+// compareTraceProfile compares the trace with the profile given the IDs of the spans on its critical path.
+func compareTraceProfile(trace *model.Trace, profile TraceProfile, criticalPathSpanIDs []model.SpanID) *ProfileComparison {
+	criticalPath := make(map[model.SpanID]struct{})
+	for _, spanID := range criticalPathSpanIDs {
+		criticalPath[spanID] = struct{}{}
+	}
+	comparison := &ProfileComparison{Passed: true}
+	for _, operation := range profile.Operations {
+		check := ProfileCheck{Operation: operation}
+		for _, span := range trace.Spans {
+			if !operation.matches(span) {
+				continue
+			}
+			check.Spans++
+			if span.Duration > check.LongestDuration {
+				check.LongestDuration = span.Duration
+			}
+			if _, ok := criticalPath[span.SpanID]; ok {
+				check.OnCriticalPath = true
+			}
+		}
+		switch {
+		case check.Spans == 0:
+			check.Message = fmt.Sprintf("operation %s not found", operation)
+		case operation.MaxDuration > 0 && check.LongestDuration > operation.MaxDuration:
+			check.Message = fmt.Sprintf("operation %s took %v, more than %v", operation, check.LongestDuration, operation.MaxDuration)
+		default:
+			check.Passed = true
+		}
+		comparison.Passed = comparison.Passed && check.Passed
+		comparison.Checks = append(comparison.Checks, check)
+	}
+	return comparison
+}
+
+func (o ProfileOperation) matches(span *model.Span) bool {
+	if span.OperationName != o.OperationName {
+		return false
+	}
+	return o.ServiceName == "" || (span.Process != nil && span.Process.ServiceName == o.ServiceName)
+}
+
+// String returns the operation as "service/operation", or "operation" when the service is not specified.
+func (o ProfileOperation) String() string {
+	if o.ServiceName == "" {
+		return o.OperationName
+	}
+	return o.ServiceName + "/" + o.OperationName
+}
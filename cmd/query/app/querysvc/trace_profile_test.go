@@ -0,0 +1,111 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// profiledTrace names the spans of bottleneckTrace, the database query (span 4) lasting queryMillis.
+func profiledTrace(queryMillis int64) *model.Trace {
+	trace := bottleneckTrace()
+	names := map[uint64][2]string{
+		1: {"frontend", "GET /orders"},
+		2: {"orders", "getOrders"},
+		3: {"orders", "authorize"},
+		4: {"mysql", "query"},
+		5: {"redis", "get"},
+		6: {"frontend", "render"},
+	}
+	for _, span := range trace.Spans {
+		name := names[uint64(span.SpanID)]
+		span.Process = &model.Process{ServiceName: name[0]}
+		span.OperationName = name[1]
+		if span.OperationName == "query" {
+			span.Duration = time.Duration(queryMillis) * time.Millisecond
+		}
+	}
+	return trace
+}
+
+var ordersProfile = TraceProfile{
+	Operations: []ProfileOperation{
+		{ServiceName: "frontend", OperationName: "GET /orders", MaxDuration: 150 * time.Millisecond},
+		{ServiceName: "mysql", OperationName: "query", MaxDuration: 50 * time.Millisecond},
+		{OperationName: "authorize"},
+	},
+}
+
+func TestCompareTraceProfile(t *testing.T) {
+	comparison := CompareTraceProfile(profiledTrace(45), ordersProfile)
+	assert.Equal(t, &ProfileComparison{
+		Passed: true,
+		Checks: []ProfileCheck{
+			{Operation: ordersProfile.Operations[0], Passed: true, Spans: 1, LongestDuration: 100 * time.Millisecond, OnCriticalPath: true},
+			{Operation: ordersProfile.Operations[1], Passed: true, Spans: 1, LongestDuration: 45 * time.Millisecond, OnCriticalPath: true},
+			{Operation: ordersProfile.Operations[2], Passed: true, Spans: 1, LongestDuration: 15 * time.Millisecond, OnCriticalPath: true},
+		},
+	}, comparison)
+}
+
+func TestCompareTraceProfileFailures(t *testing.T) {
+	profile := TraceProfile{Operations: append([]ProfileOperation{
+		{ServiceName: "redis", OperationName: "get", MaxDuration: 20 * time.Millisecond},
+		{ServiceName: "payments", OperationName: "charge"},
+	}, ordersProfile.Operations...)}
+
+	comparison := CompareTraceProfile(profiledTrace(70), profile)
+	assert.False(t, comparison.Passed)
+	require.Len(t, comparison.Checks, 5)
+
+	redis := comparison.Checks[0]
+	assert.True(t, redis.Passed)
+	assert.False(t, redis.OnCriticalPath, "the span runs in parallel with the critical path")
+
+	assert.Equal(t, ProfileCheck{
+		Operation: profile.Operations[1],
+		Message:   "operation payments/charge not found",
+	}, comparison.Checks[1])
+	assert.True(t, comparison.Checks[2].Passed)
+	assert.Equal(t, ProfileCheck{
+		Operation:       profile.Operations[3],
+		Spans:           1,
+		LongestDuration: 70 * time.Millisecond,
+		OnCriticalPath:  true,
+		Message:         "operation mysql/query took 70ms, more than 50ms",
+	}, comparison.Checks[3])
+	assert.True(t, comparison.Checks[4].Passed)
+}
+
+func TestCompareTrace(t *testing.T) {
+	tqs := initializeTestService()
+	passingID, failingID := model.NewTraceID(0, 1), model.NewTraceID(0, 2)
+	tqs.spanReader.On("GetTrace", mock.Anything, passingID).Return(profiledTrace(45), nil).Once()
+	tqs.spanReader.On("GetTrace", mock.Anything, failingID).Return(profiledTrace(70), nil).Once()
+
+	comparison, err := tqs.queryService.CompareTrace(context.Background(), passingID, ordersProfile)
+	require.NoError(t, err)
+	assert.True(t, comparison.Passed)
+
+	comparison, err = tqs.queryService.CompareTrace(context.Background(), failingID, ordersProfile)
+	require.NoError(t, err)
+	assert.False(t, comparison.Passed)
+}
+
+func TestCompareTraceNotFound(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	_, err := tqs.queryService.CompareTrace(context.Background(), criticalPathTraceID, ordersProfile)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// TraceSkeleton is the structure of a trace, for rendering its span tree before loading the details of the spans.
+type TraceSkeleton struct {
+	TraceID model.TraceID
+	Spans   []SkeletonSpan
+}
+
+// SkeletonSpan holds the structural fields of a span, without its tags, logs or process tags.
+type SkeletonSpan struct {
+	SpanID model.SpanID
+	// ParentSpanID is the parent of the span, see model.Span.ParentSpanID, or 0 for a root span.
+	ParentSpanID  model.SpanID
+	ServiceName   string
+	OperationName string
+	StartTime     time.Time
+	Duration      time.Duration
+}
+
+// SkeletonOf returns the skeleton of the trace, with its spans in the same order.
+func SkeletonOf(trace *model.Trace) *TraceSkeleton {
+	skeleton := &TraceSkeleton{Spans: make([]SkeletonSpan, len(trace.Spans))}
+	if len(trace.Spans) > 0 {
+		skeleton.TraceID = trace.Spans[0].TraceID
+	}
+	for i, span := range trace.Spans {
+		skeleton.Spans[i] = SkeletonSpan{
+			SpanID:        span.SpanID,
+			ParentSpanID:  span.ParentSpanID(),
+			ServiceName:   span.Process.GetServiceName(),
+			OperationName: span.OperationName,
+			StartTime:     span.StartTime,
+			Duration:      span.Duration,
+		}
+	}
+	return skeleton
+}
+
+// GetTraceSkeleton returns the skeleton of the trace, see SkeletonOf. The trace is adjusted first,
+// so that the tree is built from the deduplicated spans and their corrected timings.
+func (qs QueryService) GetTraceSkeleton(ctx context.Context, traceID model.TraceID) (*TraceSkeleton, error) {
+	trace, err := qs.GetTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	// adjusters return a usable trace even when they report problems with it
+	trace, _ = qs.Adjust(trace)
+	return SkeletonOf(trace), nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func TestSkeletonOf(t *testing.T) {
+	trace := &model.Trace{Spans: []*model.Span{
+		makeSpan(1, 0, model.ChildOf, 0, 100),
+		makeSpan(2, 1, model.FollowsFrom, 10, 90),
+	}}
+	for _, span := range trace.Spans {
+		span.OperationName = "op"
+		span.Process = model.NewProcess("frontend", []model.KeyValue{model.String("hostname", "h")})
+		span.Tags = []model.KeyValue{model.String("http.url", "/")}
+		span.Logs = []model.Log{{Timestamp: span.StartTime, Fields: []model.KeyValue{model.String("event", "e")}}}
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, &TraceSkeleton{
+		TraceID: criticalPathTraceID,
+		Spans: []SkeletonSpan{
+			{
+				SpanID:        model.NewSpanID(1),
+				ServiceName:   "frontend",
+				OperationName: "op",
+				StartTime:     base,
+				Duration:      100 * time.Millisecond,
+			},
+			{
+				SpanID:        model.NewSpanID(2),
+				ParentSpanID:  model.NewSpanID(1),
+				ServiceName:   "frontend",
+				OperationName: "op",
+				StartTime:     base.Add(10 * time.Millisecond),
+				Duration:      80 * time.Millisecond,
+			},
+		},
+	}, SkeletonOf(trace))
+
+	assert.Equal(t, &TraceSkeleton{Spans: []SkeletonSpan{}}, SkeletonOf(&model.Trace{}))
+}
+
+func TestGetTraceSkeleton(t *testing.T) {
+	tqs := initializeTestService()
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(summaryTrace(), nil).Once()
+
+	skeleton, err := tqs.queryService.GetTraceSkeleton(context.Background(), criticalPathTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, criticalPathTraceID, skeleton.TraceID)
+	require.Len(t, skeleton.Spans, 6)
+
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(nil, spanstore.ErrTraceNotFound).Once()
+	_, err = tqs.queryService.GetTraceSkeleton(context.Background(), criticalPathTraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
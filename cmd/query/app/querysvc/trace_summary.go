@@ -0,0 +1,139 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/criticalpath"
+)
+
+// TraceSummary holds the statistics of a trace, e.g. for displaying a trace without loading its spans.
+type TraceSummary struct {
+	TraceID   model.TraceID
+	SpanCount int
+	// ErrorSpanCount is the number of spans with an error.
+	ErrorSpanCount int
+	StartTime      time.Time
+	// Duration is the time between the start of the first span and the end of the last one.
+	Duration time.Duration
+	// Services are the services of the trace, by decreasing number of spans.
+	Services []ServiceSummary
+	// CriticalPath is the critical path of the trace, see criticalpath.Compute.
+	CriticalPath []criticalpath.Segment
+}
+
+// ServiceSummary holds the statistics of the spans of a service in a trace.
+type ServiceSummary struct {
+	ServiceName string
+	SpanCount   int
+	// SelfTime is the sum of the self times of the spans of the service, i.e. of the time
+	// each span did not wait on its children.
+	SelfTime time.Duration
+}
+
+// SummarizeTrace returns the summary of the trace.
+func SummarizeTrace(trace *model.Trace) *TraceSummary {
+	summary := summarizeTrace(trace)
+	summary.CriticalPath = criticalpath.Compute(trace)
+	return summary
+}
+
+// summarizeTrace returns the summary of the trace without its critical path.
+func summarizeTrace(trace *model.Trace) *TraceSummary {
+	summary := &TraceSummary{SpanCount: len(trace.Spans)}
+	if len(trace.Spans) > 0 {
+		summary.TraceID = trace.Spans[0].TraceID
+	}
+	var end time.Time
+	services := make(map[string]*ServiceSummary)
+	selfTimes := selfTimes(trace)
+	for _, span := range trace.Spans {
+		if summary.StartTime.IsZero() || span.StartTime.Before(summary.StartTime) {
+			summary.StartTime = span.StartTime
+		}
+		if spanEnd := span.StartTime.Add(span.Duration); spanEnd.After(end) {
+			end = spanEnd
+		}
+		if spanHasError(span) {
+			summary.ErrorSpanCount++
+		}
+		serviceName := span.Process.GetServiceName()
+		service, ok := services[serviceName]
+		if !ok {
+			service = &ServiceSummary{ServiceName: serviceName}
+			services[serviceName] = service
+		}
+		service.SpanCount++
+		service.SelfTime += selfTimes[span]
+	}
+	summary.Duration = end.Sub(summary.StartTime)
+	for _, service := range services {
+		summary.Services = append(summary.Services, *service)
+	}
+	sort.Slice(summary.Services, func(i, j int) bool {
+		a, b := summary.Services[i], summary.Services[j]
+		if a.SpanCount != b.SpanCount {
+			return a.SpanCount > b.SpanCount
+		}
+		return a.ServiceName < b.ServiceName
+	})
+	return summary
+}
+
+// selfTimes returns the self time of each span, its duration minus the time covered by its children.
+func selfTimes(trace *model.Trace) map[*model.Span]time.Duration {
+	type interval struct{ start, end time.Time }
+	children := make(map[model.SpanID][]interval)
+	for _, span := range trace.Spans {
+		if parentID := span.ParentSpanID(); parentID != span.SpanID {
+			children[parentID] = append(children[parentID], interval{span.StartTime, span.StartTime.Add(span.Duration)})
+		}
+	}
+	selfTimes := make(map[*model.Span]time.Duration, len(trace.Spans))
+	for _, span := range trace.Spans {
+		intervals := children[span.SpanID]
+		sort.Slice(intervals, func(i, j int) bool {
+			return intervals[i].start.Before(intervals[j].start)
+		})
+		self := span.Duration
+		// the time covered by the children, clipped to the span, is subtracted once
+		cursor, end := span.StartTime, span.StartTime.Add(span.Duration)
+		for _, child := range intervals {
+			start := child.start
+			if start.Before(cursor) {
+				start = cursor
+			}
+			childEnd := child.end
+			if childEnd.After(end) {
+				childEnd = end
+			}
+			if childEnd.After(start) {
+				self -= childEnd.Sub(start)
+				cursor = childEnd
+			}
+		}
+		selfTimes[span] = self
+	}
+	return selfTimes
+}
+
+// GetTraceSummary returns the summary of the trace, see SummarizeTrace. The trace is adjusted first,
+// so that the statistics are computed from the corrected span timings. The traces larger than
+// MaxTraceSpans are summarized from their first spans, and the critical path of the traces larger
+// than MaxTraversalSpans is truncated, with a warning.
+func (qs QueryService) GetTraceSummary(ctx context.Context, traceID model.TraceID) (*TraceSummary, error) {
+	trace, err := qs.GetTrace(ctx, traceID)
+	if err != nil {
+		return nil, err
+	}
+	// adjusters return a usable trace even when they report problems with it
+	trace, _ = qs.Adjust(trace)
+	summary := summarizeTrace(trace)
+	summary.CriticalPath = qs.criticalPath(ctx, trace)
+	return summary, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/criticalpath"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// summaryTrace is the bottleneck trace, with span 4 of another service failing.
+func summaryTrace() *model.Trace {
+	trace := bottleneckTrace()
+	for _, span := range trace.Spans {
+		span.Process = model.NewProcess("frontend", nil)
+	}
+	trace.Spans[2].Process = model.NewProcess("backend", nil)
+	trace.Spans[4].Process = model.NewProcess("backend", nil)
+	trace.Spans[4].Tags = []model.KeyValue{model.Bool("error", true)}
+	return trace
+}
+
+func TestSummarizeTrace(t *testing.T) {
+	summary := SummarizeTrace(summaryTrace())
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, criticalPathTraceID, summary.TraceID)
+	assert.Equal(t, 6, summary.SpanCount)
+	assert.Equal(t, 1, summary.ErrorSpanCount)
+	assert.Equal(t, base, summary.StartTime)
+	assert.Equal(t, 100*time.Millisecond, summary.Duration)
+	assert.Equal(t, []ServiceSummary{
+		// 1 waits on its children for 5-90 and 92-98, 3, 5 and 6 have no children
+		{ServiceName: "frontend", SpanCount: 4, SelfTime: (9 + 15 + 15 + 6) * time.Millisecond},
+		// 2 waits on its children for 15-30 and 40-85, 4 has no children
+		{ServiceName: "backend", SpanCount: 2, SelfTime: (20 + 45) * time.Millisecond},
+	}, summary.Services)
+
+	require.Len(t, summary.CriticalPath, 9)
+	assert.Equal(t, criticalpath.Segment{
+		SpanID:   model.NewSpanID(4),
+		Start:    base.Add(40 * time.Millisecond),
+		Duration: 45 * time.Millisecond,
+	}, summary.CriticalPath[4])
+}
+
+func TestSummarizeTraceOverlappingChildren(t *testing.T) {
+	summary := SummarizeTrace(&model.Trace{Spans: []*model.Span{
+		makeSpan(1, 0, model.ChildOf, 0, 100),
+		makeSpan(2, 1, model.ChildOf, 10, 50),
+		makeSpan(3, 1, model.FollowsFrom, 30, 60),
+		// an async span outliving its parent is clipped
+		makeSpan(4, 1, model.FollowsFrom, 90, 150),
+	}})
+	assert.Equal(t, []ServiceSummary{
+		{SpanCount: 4, SelfTime: (40 + 40 + 30 + 60) * time.Millisecond},
+	}, summary.Services)
+	assert.Equal(t, 150*time.Millisecond, summary.Duration)
+
+	assert.Equal(t, &TraceSummary{}, SummarizeTrace(&model.Trace{}))
+}
+
+func TestGetTraceSummary(t *testing.T) {
+	tqs := initializeTestService(func(_ *testQueryService, options *QueryServiceOptions) {
+		options.MaxTraceSpans = 3
+	})
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(summaryTrace(), nil).Once()
+
+	ctx := ContextWithWarnings(context.Background())
+	summary, err := tqs.queryService.GetTraceSummary(ctx, criticalPathTraceID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.SpanCount)
+	assert.Equal(t, []string{"trace 000000000000002a truncated to the maximum of 3 spans"}, GetWarnings(ctx))
+
+	tqs.spanReader.On("GetTrace", mock.Anything, criticalPathTraceID).Return(nil, spanstore.ErrTraceNotFound).Once()
+	_, err = tqs.queryService.GetTraceSummary(ctx, criticalPathTraceID)
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// WarningsMetadataKey is the gRPC metadata, or HTTP header, holding the warnings of the APIs
+// whose responses have no field for them, one value per warning.
+const WarningsMetadataKey = "jaeger-warnings"
+
+// ContextWithWarnings returns a context in which the query service and the storage readers can report
+// warnings, e.g. about partial results, see spanstore.ContextWithWarnings. The warnings are retrieved
+// with GetWarnings.
+func ContextWithWarnings(ctx context.Context) context.Context {
+	return spanstore.ContextWithWarnings(ctx)
+}
+
+// AddWarning reports a warning about the request of the context.
+// It is a no-op if the context was not created with ContextWithWarnings.
+func AddWarning(ctx context.Context, message string) {
+	spanstore.AddWarning(ctx, message)
+}
+
+// GetWarnings returns the warnings reported about the request of the context,
+// by the query service or by the storage readers.
+func GetWarnings(ctx context.Context) []string {
+	return spanstore.GetWarnings(ctx)
+}
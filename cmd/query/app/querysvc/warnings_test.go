@@ -0,0 +1,23 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package querysvc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarnings(t *testing.T) {
+	ctx := context.Background()
+	AddWarning(ctx, "ignored")
+	assert.Nil(t, GetWarnings(ctx))
+
+	ctx = ContextWithWarnings(ctx)
+	assert.Empty(t, GetWarnings(ctx))
+	AddWarning(ctx, "first")
+	AddWarning(ctx, "second")
+	assert.Equal(t, []string{"first", "second"}, GetWarnings(ctx))
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/pkg/cache"
+)
+
+const errRateLimited = "too many requests from the client IP"
+
+// RateLimitOptions limit the rate of the requests of each client IP
+type RateLimitOptions struct {
+	// RequestsPerSecond is the sustained rate of the requests allowed per client IP, 0 disables rate limiting
+	RequestsPerSecond float64
+	// Burst is the number of requests a client IP can make at once
+	Burst int
+	// MaxClients bounds the number of client IPs tracked, the least recently seen ones being forgotten
+	MaxClients int
+	// ClientIPHeader is the request header holding the client IP, e.g. X-Forwarded-For, which must be
+	// set by a trusted proxy; the last IP of the header, added by the proxy, is the client IP.
+	// The remote address of the connection is used when empty.
+	ClientIPHeader string
+}
+
+// ipRateLimiter holds a token bucket per client IP, in an LRU cache bounding its memory.
+// The buckets evicted are the ones of the least recently seen IPs, which are full again after
+// being idle for Burst/RequestsPerSecond anyway.
+type ipRateLimiter struct {
+	limit    rate.Limit
+	burst    int
+	header   string
+	limiters cache.Cache
+}
+
+func newIPRateLimiter(options RateLimitOptions) *ipRateLimiter {
+	return &ipRateLimiter{
+		limit:    rate.Limit(options.RequestsPerSecond),
+		burst:    options.Burst,
+		header:   options.ClientIPHeader,
+		limiters: cache.NewLRU(options.MaxClients),
+	}
+}
+
+// allow returns whether the client IP can make a request now.
+func (l *ipRateLimiter) allow(ip string) bool {
+	limiter, _ := l.limiters.Get(ip).(*rate.Limiter)
+	if limiter == nil {
+		// another request of the same IP may have created the limiter in the meantime
+		value, _ := l.limiters.CompareAndSwap(ip, nil, rate.NewLimiter(l.limit, l.burst))
+		limiter = value.(*rate.Limiter)
+	}
+	return limiter.Allow()
+}
+
+// lastIP returns the last IP of a comma-separated list, as found in X-Forwarded-For.
+func lastIP(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	list := values[len(values)-1]
+	return strings.TrimSpace(list[strings.LastIndex(list, ",")+1:])
+}
+
+// hostOf returns the host of the address, or the address when it has no port.
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func (l *ipRateLimiter) httpClientIP(r *http.Request) string {
+	if l.header != "" {
+		if ip := lastIP(r.Header.Values(l.header)); ip != "" {
+			return ip
+		}
+	}
+	return hostOf(r.RemoteAddr)
+}
+
+func (l *ipRateLimiter) grpcClientIP(ctx context.Context) string {
+	if l.header != "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ip := lastIP(md.Get(strings.ToLower(l.header))); ip != "" {
+				return ip
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return hostOf(p.Addr.String())
+	}
+	return ""
+}
+
+// rateLimitHandler rejects the requests of the client IPs over the rate limit with 429 Too Many Requests.
+func rateLimitHandler(h http.Handler, limiter *ipRateLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.allow(limiter.httpClientIP(r)) {
+			http.Error(w, errRateLimited, http.StatusTooManyRequests)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// newRateLimitUnaryInterceptor rejects the calls of the client IPs over the rate limit with ResourceExhausted.
+func newRateLimitUnaryInterceptor(limiter *ipRateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !limiter.allow(limiter.grpcClientIP(ctx)) {
+			return nil, status.Error(codes.ResourceExhausted, errRateLimited)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// newRateLimitStreamInterceptor rejects the streams of the client IPs over the rate limit with ResourceExhausted.
+func newRateLimitStreamInterceptor(limiter *ipRateLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !limiter.allow(limiter.grpcClientIP(ss.Context())) {
+			return status.Error(codes.ResourceExhausted, errRateLimited)
+		}
+		return handler(srv, ss)
+	}
+}
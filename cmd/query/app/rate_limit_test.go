@@ -0,0 +1,127 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// a rate so low that no token is added back during the tests
+const testRequestsPerSecond = 0.001
+
+func TestRateLimitHandler(t *testing.T) {
+	handler := rateLimitHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), newIPRateLimiter(RateLimitOptions{
+		RequestsPerSecond: testRequestsPerSecond,
+		Burst:             2,
+		MaxClients:        10,
+	}))
+	request := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/services", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("10.0.0.1:1234"))
+	assert.Equal(t, http.StatusOK, request("10.0.0.1:1235"))
+	assert.Equal(t, http.StatusTooManyRequests, request("10.0.0.1:1236"))
+	assert.Equal(t, http.StatusOK, request("10.0.0.2:1234"))
+	assert.Equal(t, http.StatusOK, request("[::1]:1234"))
+}
+
+func TestRateLimitHandlerClientIPHeader(t *testing.T) {
+	handler := rateLimitHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), newIPRateLimiter(RateLimitOptions{
+		RequestsPerSecond: testRequestsPerSecond,
+		Burst:             1,
+		MaxClients:        10,
+		ClientIPHeader:    "X-Forwarded-For",
+	}))
+	request := func(forwardedFor ...string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/services", nil)
+		req.RemoteAddr = "10.0.0.100:1234"
+		for _, value := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("203.0.113.1"))
+	// the IPs before the last one are set by the client, and cannot be trusted
+	assert.Equal(t, http.StatusTooManyRequests, request("198.51.100.7, 203.0.113.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("198.51.100.7", "203.0.113.1"))
+	assert.Equal(t, http.StatusOK, request("203.0.113.2"))
+	// the remote address is used without the header
+	assert.Equal(t, http.StatusOK, request())
+	assert.Equal(t, http.StatusTooManyRequests, request())
+}
+
+func TestIPRateLimiterEviction(t *testing.T) {
+	limiter := newIPRateLimiter(RateLimitOptions{RequestsPerSecond: testRequestsPerSecond, Burst: 1, MaxClients: 2})
+	assert.True(t, limiter.allow("10.0.0.1"))
+	assert.False(t, limiter.allow("10.0.0.1"))
+	assert.True(t, limiter.allow("10.0.0.2"))
+	assert.True(t, limiter.allow("10.0.0.3"))
+	assert.Equal(t, 2, limiter.limiters.Size())
+	// the least recently seen IP has been forgotten
+	assert.True(t, limiter.allow("10.0.0.1"))
+	assert.False(t, limiter.allow("10.0.0.3"))
+}
+
+func peerContext(ip string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}})
+}
+
+func TestRateLimitUnaryInterceptor(t *testing.T) {
+	interceptor := newRateLimitUnaryInterceptor(newIPRateLimiter(RateLimitOptions{
+		RequestsPerSecond: testRequestsPerSecond,
+		Burst:             1,
+		MaxClients:        10,
+		ClientIPHeader:    "X-Forwarded-For",
+	}))
+	handler := func(context.Context, any) (any, error) {
+		return "ok", nil
+	}
+	call := func(ctx context.Context) codes.Code {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		return status.Code(err)
+	}
+
+	assert.Equal(t, codes.OK, call(peerContext("10.0.0.1")))
+	assert.Equal(t, codes.ResourceExhausted, call(peerContext("10.0.0.1")))
+	assert.Equal(t, codes.OK, call(peerContext("10.0.0.2")))
+	forwarded := metadata.NewIncomingContext(peerContext("10.0.0.1"), metadata.Pairs("x-forwarded-for", "203.0.113.1"))
+	assert.Equal(t, codes.OK, call(forwarded))
+	assert.Equal(t, codes.ResourceExhausted, call(forwarded))
+}
+
+func TestRateLimitStreamInterceptor(t *testing.T) {
+	interceptor := newRateLimitStreamInterceptor(newIPRateLimiter(RateLimitOptions{
+		RequestsPerSecond: testRequestsPerSecond,
+		Burst:             1,
+		MaxClients:        10,
+	}))
+	handler := func(any, grpc.ServerStream) error {
+		return nil
+	}
+	stream := &contextServerStream{ctx: peerContext("10.0.0.1")}
+	require.NoError(t, interceptor(nil, stream, &grpc.StreamServerInfo{}, handler))
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{}, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.NoError(t, interceptor(nil, &contextServerStream{ctx: peerContext("10.0.0.2")}, &grpc.StreamServerInfo{}, handler))
+}
@@ -15,13 +15,18 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/handlers"
@@ -32,14 +37,17 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/jaegertracing/jaeger/cmd/query/app/apiv3"
 	"github.com/jaegertracing/jaeger/cmd/query/app/internal/api_v3"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/pkg/bearertoken"
+	_ "github.com/jaegertracing/jaeger/pkg/grpccompression" // register the gzip, zstd and snappy encodings
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	jaegerM "github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/netutils"
 	"github.com/jaegertracing/jaeger/pkg/recoveryhandler"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
@@ -61,13 +69,24 @@ type Server struct {
 	httpConn      net.Listener
 	cmuxServer    cmux.CMux
 	grpcServer    *grpc.Server
+	grpcDrainer   *grpcDrainer
 	httpServer    *httpServer
 	separatePorts bool
 	bgFinished    sync.WaitGroup
+
+	closeOnce   sync.Once
+	closeErr    error
+	closed      chan struct{}
+	signalsOnce sync.Once
+
+	healthServer  *health.Server
+	maintenance   *MaintenanceMode
+	storagePinger StoragePinger
+	storageHealth *storageHealthMonitor
 }
 
 // NewServer creates and initializes Server
-func NewServer(logger *zap.Logger, healthCheck *healthcheck.HealthCheck, querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService, options *QueryOptions, tm *tenancy.Manager, tracer *jtracer.JTracer) (*Server, error) {
+func NewServer(logger *zap.Logger, healthCheck *healthcheck.HealthCheck, metricsFactory jaegerM.Factory, querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService, options *QueryOptions, tm *tenancy.Manager, tracer *jtracer.JTracer) (*Server, error) {
 	_, httpPort, err := net.SplitHostPort(options.HTTPHostPort)
 	if err != nil {
 		return nil, fmt.Errorf("invalid HTTP server host:port: %w", err)
@@ -80,13 +99,19 @@ func NewServer(logger *zap.Logger, healthCheck *healthcheck.HealthCheck, querySv
 	if (options.TLSHTTP.Enabled || options.TLSGRPC.Enabled) && (grpcPort == httpPort) {
 		return nil, errors.New("server with TLS enabled can not use same host ports for gRPC and HTTP.  Use dedicated HTTP and gRPC host ports instead")
 	}
+	if !querySvc.HasSpanReader() && !options.AllowNoStorage {
+		return nil, errors.New("the query service has no span reader, check the storage configuration")
+	}
 
-	grpcServer, err := createGRPCServer(querySvc, metricsQuerySvc, options, tm, logger, tracer)
+	healthServer := health.NewServer()
+	maintenance := newMaintenanceMode(healthCheck, healthServer, logger)
+	grpcDrainer := newGRPCDrainer()
+	grpcServer, err := createGRPCServer(querySvc, metricsQuerySvc, options, tm, metricsFactory, logger, tracer, healthServer, maintenance, grpcDrainer)
 	if err != nil {
 		return nil, err
 	}
 
-	httpServer, err := createHTTPServer(querySvc, metricsQuerySvc, options, tm, tracer, logger)
+	httpServer, err := createHTTPServer(querySvc, metricsQuerySvc, options, tm, tracer, logger, maintenance)
 	if err != nil {
 		return nil, err
 	}
@@ -98,16 +123,35 @@ func NewServer(logger *zap.Logger, healthCheck *healthcheck.HealthCheck, querySv
 		queryOptions:  options,
 		tracer:        tracer,
 		grpcServer:    grpcServer,
+		grpcDrainer:   grpcDrainer,
 		httpServer:    httpServer,
 		separatePorts: grpcPort != httpPort,
+		healthServer:  healthServer,
+		maintenance:   maintenance,
+		storagePinger: queryServicePinger{querySvc: querySvc},
+		closed:        make(chan struct{}),
 	}, nil
 }
 
-func createGRPCServer(querySvc *querysvc.QueryService, metricsQuerySvc querysvc.MetricsQueryService, options *QueryOptions, tm *tenancy.Manager, logger *zap.Logger, tracer *jtracer.JTracer) (*grpc.Server, error) {
-	var grpcOpts []grpc.ServerOption
+func createGRPCServer(
+	querySvc *querysvc.QueryService,
+	metricsQuerySvc querysvc.MetricsQueryService,
+	options *QueryOptions,
+	tm *tenancy.Manager,
+	metricsFactory jaegerM.Factory,
+	logger *zap.Logger,
+	tracer *jtracer.JTracer,
+	healthServer *health.Server,
+	maintenance *MaintenanceMode,
+	drainer *grpcDrainer,
+) (*grpc.Server, error) {
+	grpcOpts := options.GRPCServer.GRPCServerOptions(keepalive.ServerParameters{})
+	if options.GRPCMaxReceiveMessageLength > 0 {
+		grpcOpts = append(grpcOpts, grpc.MaxRecvMsgSize(options.GRPCMaxReceiveMessageLength))
+	}
 
 	if options.TLSGRPC.Enabled {
-		tlsCfg, err := options.TLSGRPC.Config(logger)
+		tlsCfg, err := options.TLSGRPC.GRPCConfig(logger)
 		if err != nil {
 			return nil, err
 		}
@@ -116,29 +160,55 @@ func createGRPCServer(querySvc *querysvc.QueryService, metricsQuerySvc querysvc.
 
 		grpcOpts = append(grpcOpts, grpc.Creds(creds))
 	}
+
+	// the recovery interceptors come first so that they also protect the other interceptors
+	panics := metricsFactory.Counter(jaegerM.Options{Name: "grpc.panics"})
+	recoveryUnary, recoveryStream := recoveryhandler.NewGRPCRecoveryInterceptors(logger, panics)
+	unaryInterceptors := []grpc.UnaryServerInterceptor{recoveryUnary, newMaintenanceUnaryInterceptor(maintenance)}
+	streamInterceptors := []grpc.StreamServerInterceptor{recoveryStream, drainer.streamInterceptor(), newMaintenanceStreamInterceptor(maintenance)}
+	if options.RateLimit.RequestsPerSecond > 0 {
+		limiter := newIPRateLimiter(options.RateLimit)
+		unaryInterceptors = append(unaryInterceptors, newRateLimitUnaryInterceptor(limiter))
+		streamInterceptors = append(streamInterceptors, newRateLimitStreamInterceptor(limiter))
+	}
 	if tm.Enabled {
-		grpcOpts = append(grpcOpts,
-			grpc.StreamInterceptor(tenancy.NewGuardingStreamInterceptor(tm)),
-			grpc.UnaryInterceptor(tenancy.NewGuardingUnaryInterceptor(tm)),
-		)
+		// the tenant of the requests reaches the span and dependency readers through the context
+		unaryInterceptors = append(unaryInterceptors, tenancy.NewGuardingUnaryInterceptor(tm))
+		streamInterceptors = append(streamInterceptors, tenancy.NewGuardingStreamInterceptor(tm))
+	}
+	if options.SubjectHeader != "" {
+		unaryInterceptors = append(unaryInterceptors, newSubjectUnaryInterceptor(options.SubjectHeader))
+		streamInterceptors = append(streamInterceptors, newSubjectStreamInterceptor(options.SubjectHeader))
 	}
+	grpcOpts = append(grpcOpts,
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
 
 	server := grpc.NewServer(grpcOpts...)
-	reflection.Register(server)
+	if !options.GRPCServer.DisableReflection {
+		reflection.Register(server)
+	}
+	logger.Info("Query gRPC server options",
+		zap.Int("grpc.max-message-size", options.GRPCMaxReceiveMessageLength),
+		zap.Object("grpc.server-options", options.GRPCServer))
 
 	handler := NewGRPCHandler(querySvc, metricsQuerySvc, GRPCHandlerOptions{
 		Logger: logger,
 		Tracer: tracer,
 	})
-	healthServer := health.NewServer()
-
 	api_v2.RegisterQueryServiceServer(server, handler)
+	api_v2.RegisterCriticalPathServiceServer(server, handler)
+	api_v2.RegisterTraceProfileServiceServer(server, handler)
+	api_v2.RegisterOperationLatenciesServiceServer(server, handler)
+	api_v2.RegisterSearchValidationServiceServer(server, handler)
+	api_v2.RegisterTraceArchiveServiceServer(server, handler)
 	metrics.RegisterMetricsQueryServiceServer(server, handler)
 	api_v3.RegisterQueryServiceServer(server, &apiv3.Handler{QueryService: querySvc})
 
-	healthServer.SetServingStatus("jaeger.api_v2.QueryService", grpc_health_v1.HealthCheckResponse_SERVING)
-	healthServer.SetServingStatus("jaeger.api_v2.metrics.MetricsQueryService", grpc_health_v1.HealthCheckResponse_SERVING)
-	healthServer.SetServingStatus("jaeger.api_v3.QueryService", grpc_health_v1.HealthCheckResponse_SERVING)
+	for _, service := range healthCheckedServices {
+		healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
 
 	grpc_health_v1.RegisterHealthServer(server, healthServer)
 	return server, nil
@@ -158,11 +228,17 @@ func createHTTPServer(
 	tm *tenancy.Manager,
 	tracer *jtracer.JTracer,
 	logger *zap.Logger,
+	maintenance *MaintenanceMode,
 ) (*httpServer, error) {
 	apiHandlerOptions := []HandlerOption{
 		HandlerOptions.Logger(logger),
 		HandlerOptions.Tracer(tracer),
 		HandlerOptions.MetricsQueryService(metricsQuerySvc),
+		HandlerOptions.DefaultSearchLimit(queryOpts.DefaultSearchLimit),
+		HandlerOptions.ActiveServicesWindow(queryOpts.ActiveServicesWindow),
+		HandlerOptions.AllowedSearchTagKeys(queryOpts.AllowedSearchTagKeys),
+		HandlerOptions.MaxRequestBodyBytes(queryOpts.MaxRequestBodyBytes),
+		HandlerOptions.SelfTracing(queryOpts.SelfTracing),
 	}
 
 	apiHandler := NewAPIHandler(
@@ -184,10 +260,17 @@ func createHTTPServer(
 	apiHandler.RegisterRoutes(r)
 	var handler http.Handler = r
 	handler = additionalHeadersHandler(handler, queryOpts.AdditionalHeaders)
+	if queryOpts.SubjectHeader != "" {
+		handler = subjectHandler(handler, queryOpts.SubjectHeader)
+	}
 	if queryOpts.BearerTokenPropagation {
 		handler = bearertoken.PropagationHandler(logger, handler)
 	}
 	handler = handlers.CompressHandler(handler)
+	handler = maintenanceHandler(handler, maintenance, path.Join("/", queryOpts.BasePath, defaultAPIPrefix)+"/")
+	if queryOpts.RateLimit.RequestsPerSecond > 0 {
+		handler = rateLimitHandler(handler, newIPRateLimiter(queryOpts.RateLimit))
+	}
 	recoveryHandler := recoveryhandler.NewRecoveryHandler(logger, true)
 
 	errorLog, _ := zap.NewStdLogAt(logger, zapcore.ErrorLevel)
@@ -196,6 +279,7 @@ func createHTTPServer(
 			Handler:           recoveryHandler(handler),
 			ErrorLog:          errorLog,
 			ReadHeaderTimeout: 2 * time.Second,
+			MaxHeaderBytes:    queryOpts.HTTPMaxHeaderBytes,
 		},
 	}
 
@@ -219,6 +303,19 @@ func (hS httpServer) Close() error {
 	return errors.Join(errs...)
 }
 
+// drain shuts the server down once the running requests are done, interrupting the requests still
+// running after the timeout, or right away if it is 0.
+func (hS httpServer) drain(timeout time.Duration) error {
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := hS.Server.Shutdown(ctx); err == nil {
+			return hS.staticHandlerCloser.Close()
+		}
+	}
+	return hS.Close()
+}
+
 // initListener initialises listeners of the server
 func (s *Server) initListener() (cmux.CMux, error) {
 	if s.separatePorts { // use separate ports and listeners each for gRPC and HTTP requests
@@ -270,6 +367,11 @@ func (s *Server) initListener() (cmux.CMux, error) {
 	return cmuxServer, nil
 }
 
+// Maintenance returns the maintenance mode of the server, to register on the admin server at AdminMaintenancePath.
+func (s *Server) Maintenance() *MaintenanceMode {
+	return s.maintenance
+}
+
 // Start http, GRPC and cmux servers concurrently
 func (s *Server) Start() error {
 	cmuxServer, err := s.initListener()
@@ -326,6 +428,19 @@ func (s *Server) Start() error {
 		s.bgFinished.Done()
 	}()
 
+	if s.queryOptions.StorageHealthCheckInterval > 0 {
+		s.storageHealth = newStorageHealthMonitor(
+			s.storagePinger,
+			s.healthServer,
+			s.queryOptions.StorageHealthCheckInterval,
+			s.queryOptions.StorageFailureThreshold,
+			s.logger,
+		)
+		s.storageHealth.maintenance = s.maintenance
+		s.maintenance.setStorageFailing(s.storageHealth.notServing.Load)
+		s.storageHealth.start()
+	}
+
 	// Start cmux server concurrently.
 	if !s.separatePorts {
 		s.bgFinished.Add(1)
@@ -346,20 +461,58 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// HandleSignals makes the server stop, as with Close, when the process receives SIGTERM or SIGINT,
+// until ctx is done. It is opt-in so that the embedders handling the signals themselves are not
+// surprised, and calling it again has no effect.
+func (s *Server) HandleSignals(ctx context.Context) {
+	s.signalsOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+		go func() {
+			defer signal.Stop(signals)
+			select {
+			case sig := <-signals:
+				s.logger.Info("Received signal, stopping the server", zap.Stringer("signal", sig))
+				if err := s.Close(); err != nil {
+					s.logger.Error("Failed to stop the server", zap.Error(err))
+				}
+			case <-ctx.Done():
+			case <-s.closed:
+			}
+		}()
+	})
+}
+
 // Close stops HTTP, GRPC servers and closes the port listener.
+// It can be called several times, the servers are only stopped once.
 func (s *Server) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.close()
+		close(s.closed)
+	})
+	return s.closeErr
+}
+
+func (s *Server) close() error {
 	errs := []error{
 		s.queryOptions.TLSGRPC.Close(),
 		s.queryOptions.TLSHTTP.Close(),
 	}
 
-	s.logger.Info("Closing HTTP server")
-	if err := s.httpServer.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("failed to close HTTP server: %w", err))
+	if s.storageHealth != nil {
+		s.storageHealth.stop()
 	}
+	// let the health watchers know that the services are going away
+	s.healthServer.Shutdown()
+
+	// the gRPC server is drained first, its long-lived streams holding on to the connections
+	s.logger.Info("Draining gRPC server", zap.Duration("timeout", s.queryOptions.GRPCDrainTimeout))
+	s.grpcDrainer.drain(s.grpcServer, s.queryOptions.GRPCDrainTimeout)
 
-	s.logger.Info("Stopping gRPC server")
-	s.grpcServer.Stop()
+	s.logger.Info("Draining HTTP server", zap.Duration("timeout", s.queryOptions.HTTPDrainTimeout))
+	if err := s.httpServer.drain(s.queryOptions.HTTPDrainTimeout); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close HTTP server: %w", err))
+	}
 
 	if !s.separatePorts {
 		s.logger.Info("Closing CMux server")
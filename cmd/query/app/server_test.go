@@ -15,11 +15,16 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -30,20 +35,26 @@ import (
 	"go.uber.org/zap/zaptest"
 	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	"github.com/jaegertracing/jaeger/cmd/internal/flags"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/internal/grpctest"
+	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config/grpccfg"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/healthcheck"
 	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/ports"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
 )
 
@@ -67,7 +78,7 @@ func TestCreateTLSServerSinglePortError(t *testing.T) {
 		ClientCAPath: testCertKeyLocation + "/example-CA-cert.pem",
 	}
 
-	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, &querysvc.QueryService{}, nil,
 		&QueryOptions{HTTPHostPort: ":8080", GRPCHostPort: ":8080", TLSGRPC: tlsCfg, TLSHTTP: tlsCfg},
 		tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
 	require.Error(t, err)
@@ -81,8 +92,8 @@ func TestCreateTLSGrpcServerError(t *testing.T) {
 		ClientCAPath: "invalid/path",
 	}
 
-	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
-		&QueryOptions{HTTPHostPort: ":8080", GRPCHostPort: ":8081", TLSGRPC: tlsCfg},
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, &querysvc.QueryService{}, nil,
+		&QueryOptions{HTTPHostPort: ":8080", GRPCHostPort: ":8081", TLSGRPC: tlsCfg, AllowNoStorage: true},
 		tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
 	require.Error(t, err)
 }
@@ -95,8 +106,8 @@ func TestCreateTLSHttpServerError(t *testing.T) {
 		ClientCAPath: "invalid/path",
 	}
 
-	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
-		&QueryOptions{HTTPHostPort: ":8080", GRPCHostPort: ":8081", TLSHTTP: tlsCfg},
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, &querysvc.QueryService{}, nil,
+		&QueryOptions{HTTPHostPort: ":8080", GRPCHostPort: ":8081", TLSHTTP: tlsCfg, AllowNoStorage: true},
 		tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
 	require.Error(t, err)
 }
@@ -356,7 +367,7 @@ func TestServerHTTPTLS(t *testing.T) {
 			flagsSvc.Logger = zaptest.NewLogger(t)
 
 			querySvc := makeQuerySvc()
-			server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), querySvc.qs,
+			server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), metrics.NullFactory, querySvc.qs,
 				nil, serverOptions, tenancy.NewManager(&tenancy.Options{}),
 				jtracer.NoOp())
 			require.NoError(t, err)
@@ -493,7 +504,7 @@ func TestServerGRPCTLS(t *testing.T) {
 			flagsSvc.Logger = zaptest.NewLogger(t)
 
 			querySvc := makeQuerySvc()
-			server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), querySvc.qs,
+			server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), metrics.NullFactory, querySvc.qs,
 				nil, serverOptions, tenancy.NewManager(&tenancy.Options{}),
 				jtracer.NoOp())
 			require.NoError(t, err)
@@ -534,8 +545,80 @@ func TestServerGRPCTLS(t *testing.T) {
 	}
 }
 
+func TestServerTLSVersionsAndClientAuth(t *testing.T) {
+	serverTLS := tlscfg.Options{
+		Enabled:        true,
+		CertPath:       testCertKeyLocation + "/example-server-cert.pem",
+		KeyPath:        testCertKeyLocation + "/example-server-key.pem",
+		ClientCAPath:   testCertKeyLocation + "/example-CA-cert.pem",
+		ClientAuthType: tlscfg.ClientAuthRequest,
+		MinVersion:     "1.3",
+	}
+	serverOptions := &QueryOptions{
+		GRPCHostPort: ports.GetAddressFromCLIOptions(ports.QueryGRPC, ""),
+		HTTPHostPort: ports.GetAddressFromCLIOptions(ports.QueryHTTP, ""),
+		TLSHTTP:      serverTLS,
+		TLSGRPC:      serverTLS,
+		QueryOptionsBase: QueryOptionsBase{
+			BearerTokenPropagation: true,
+		},
+	}
+	logger := zaptest.NewLogger(t)
+	querySvc := makeQuerySvc()
+	server, err := NewServer(logger, healthcheck.New(), metrics.NullFactory, querySvc.qs,
+		nil, serverOptions, tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	tests := []struct {
+		name        string
+		maxVersion  string
+		expectError bool
+	}{
+		{name: "TLS 1.2 client rejected", maxVersion: "1.2", expectError: true},
+		{name: "TLS 1.3 client without certificate accepted", maxVersion: "1.3"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientTLS := tlscfg.Options{
+				Enabled:    true,
+				CAPath:     testCertKeyLocation + "/example-CA-cert.pem",
+				ServerName: "example.com",
+				MaxVersion: test.maxVersion,
+			}
+			clientTLSCfg, err := clientTLS.Config(logger)
+			require.NoError(t, err)
+			defer clientTLS.Close()
+
+			dialer := &net.Dialer{Timeout: 2 * time.Second}
+			conn, err := tls.DialWithDialer(dialer, "tcp", ports.PortToHostPort(ports.QueryHTTP), clientTLSCfg)
+			if test.expectError {
+				require.ErrorContains(t, err, "protocol version not supported")
+			} else {
+				require.NoError(t, err)
+				require.NoError(t, conn.Close())
+			}
+
+			client := newGRPCClientWithTLS(t, ports.PortToHostPort(ports.QueryGRPC), credentials.NewTLS(clientTLSCfg))
+			defer client.conn.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			res, err := client.GetServices(ctx, &api_v2.GetServicesRequest{})
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, querySvc.expectedServices, res.Services)
+			}
+		})
+	}
+}
+
 func TestServerBadHostPort(t *testing.T) {
-	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, &querysvc.QueryService{}, nil,
 		&QueryOptions{
 			HTTPHostPort: "8080", // bad string, not :port
 			GRPCHostPort: "127.0.0.1:8081",
@@ -547,7 +630,7 @@ func TestServerBadHostPort(t *testing.T) {
 		jtracer.NoOp())
 	require.Error(t, err)
 
-	_, err = NewServer(zaptest.NewLogger(t), healthcheck.New(), &querysvc.QueryService{}, nil,
+	_, err = NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, &querysvc.QueryService{}, nil,
 		&QueryOptions{
 			HTTPHostPort: "127.0.0.1:8081",
 			GRPCHostPort: "9123", // bad string, not :port
@@ -561,6 +644,26 @@ func TestServerBadHostPort(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestServerRequiresSpanReader(t *testing.T) {
+	options := &QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0"}
+	_, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, &querysvc.QueryService{}, nil,
+		options, tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
+	require.ErrorContains(t, err, "the query service has no span reader")
+
+	options.AllowNoStorage = true
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, &querysvc.QueryService{}, nil,
+		options, tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
+	require.NoError(t, err)
+	assert.NotNil(t, server)
+
+	// a self-tracing query service without reader is not mistaken for a configured one
+	querySvc := querysvc.NewQueryService(nil, nil, querysvc.QueryServiceOptions{SelfTracing: true})
+	options.AllowNoStorage = false
+	_, err = NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, querySvc, nil,
+		options, tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
+	require.Error(t, err)
+}
+
 func TestServerInUseHostPort(t *testing.T) {
 	const availableHostPort = "127.0.0.1:0"
 	conn, err := net.Listen("tcp", availableHostPort)
@@ -580,11 +683,13 @@ func TestServerInUseHostPort(t *testing.T) {
 			server, err := NewServer(
 				zaptest.NewLogger(t),
 				healthcheck.New(),
+				metrics.NullFactory,
 				&querysvc.QueryService{},
 				nil,
 				&QueryOptions{
-					HTTPHostPort: tc.httpHostPort,
-					GRPCHostPort: tc.grpcHostPort,
+					HTTPHostPort:   tc.httpHostPort,
+					GRPCHostPort:   tc.grpcHostPort,
+					AllowNoStorage: true,
 					QueryOptionsBase: QueryOptionsBase{
 						BearerTokenPropagation: true,
 					},
@@ -604,7 +709,7 @@ func TestServerSinglePort(t *testing.T) {
 	flagsSvc.Logger = zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller()))
 	hostPort := ports.GetAddressFromCLIOptions(ports.QueryHTTP, "")
 	querySvc := makeQuerySvc()
-	server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), querySvc.qs, nil,
+	server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), metrics.NullFactory, querySvc.qs, nil,
 		&QueryOptions{
 			GRPCHostPort: hostPort,
 			HTTPHostPort: hostPort,
@@ -634,6 +739,168 @@ func TestServerSinglePort(t *testing.T) {
 	assert.Equal(t, querySvc.expectedServices, res.Services)
 }
 
+func TestServerRecoversFromPanic(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Run(func(mock.Arguments) {
+		panic("storage exploded")
+	}).Return(nil, nil).Once()
+	spanReader.On("GetServices", mock.Anything).Return([]string{"test"}, nil)
+	querySvc := querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metricsFactory, querySvc, nil,
+		&QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0"},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	client := newGRPCClient(t, server.grpcConn.Addr().String())
+	t.Cleanup(func() {
+		require.NoError(t, client.conn.Close())
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "grpc.panics", Value: 1})
+
+	res, err := client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test"}, res.Services)
+}
+
+func TestServerGRPCTenancyStorageContext(t *testing.T) {
+	withTenant := func(tenant string) any {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			return tenancy.GetTenant(ctx) == tenant
+		})
+	}
+	spanReader := &spanstoremocks.Reader{}
+	for _, tenant := range []string{"acme", "megacorp"} {
+		spanReader.On("GetServices", withTenant(tenant)).Return([]string{tenant + "-service"}, nil).Once()
+	}
+	querySvc := querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	tm := tenancy.NewManager(&tenancy.Options{Enabled: true})
+
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, querySvc, nil,
+		&QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0"},
+		tm,
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	client := newGRPCClient(t, server.grpcConn.Addr().String())
+	t.Cleanup(func() {
+		require.NoError(t, client.conn.Close())
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err = client.GetServices(ctx, &api_v2.GetServicesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	for _, tenant := range []string{"acme", "megacorp"} {
+		res, err := client.GetServices(withOutgoingMetadata(t, ctx, tm.Header, tenant), &api_v2.GetServicesRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{tenant + "-service"}, res.Services)
+	}
+	spanReader.AssertExpectations(t)
+}
+
+func TestServerGRPCMessageSizes(t *testing.T) {
+	largeService := strings.Repeat("x", 2048)
+	tests := []struct {
+		name         string
+		options      QueryOptions
+		expectedCode codes.Code
+	}{
+		{name: "defaults", expectedCode: codes.OK},
+		{name: "request over limit", options: QueryOptions{GRPCMaxReceiveMessageLength: 1024}, expectedCode: codes.ResourceExhausted},
+		{name: "request within limit", options: QueryOptions{GRPCMaxReceiveMessageLength: 4096}, expectedCode: codes.OK},
+		{name: "response over limit", options: QueryOptions{GRPCServer: grpccfg.ServerOptions{MaxSendMessageSize: 1024}}, expectedCode: codes.ResourceExhausted},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spanReader := &spanstoremocks.Reader{}
+			spanReader.On("GetOperations", mock.Anything, mock.Anything).
+				Return([]spanstore.Operation{{Name: largeService}}, nil)
+			querySvc := querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+
+			options := test.options
+			options.GRPCHostPort, options.HTTPHostPort = ":0", ":0"
+			server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, querySvc, nil,
+				&options,
+				tenancy.NewManager(&tenancy.Options{}),
+				jtracer.NoOp())
+			require.NoError(t, err)
+			require.NoError(t, server.Start())
+			t.Cleanup(func() {
+				require.NoError(t, server.Close())
+			})
+
+			client := newGRPCClient(t, server.grpcConn.Addr().String())
+			t.Cleanup(func() {
+				require.NoError(t, client.conn.Close())
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			_, err = client.GetOperations(ctx, &api_v2.GetOperationsRequest{Service: largeService})
+			assert.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestServerHTTPMaxHeaderBytes(t *testing.T) {
+	tests := []struct {
+		name         string
+		headerSize   int
+		expectedCode int
+	}{
+		{name: "header within limit", headerSize: 512, expectedCode: http.StatusOK},
+		{name: "header over limit", headerSize: 16 * 1024, expectedCode: http.StatusRequestHeaderFieldsTooLarge},
+	}
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Return([]string{"test"}, nil)
+	querySvc := querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{})
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, querySvc, nil,
+		&QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0", HTTPMaxHeaderBytes: 1024},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+		// net/http lingers before closing the connections rejected for their headers
+		assert.Eventually(t, func() bool {
+			buf := make([]byte, 1<<20)
+			return !bytes.Contains(buf[:runtime.Stack(buf, true)], []byte("closeWriteAndWait"))
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://"+server.httpConn.Addr().String()+"/api/services", nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+strings.Repeat("x", test.headerSize))
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, test.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
 func TestServerGracefulExit(t *testing.T) {
 	flagsSvc := flags.NewService(ports.QueryAdminHTTP)
 
@@ -644,7 +911,7 @@ func TestServerGracefulExit(t *testing.T) {
 	hostPort := ports.PortToHostPort(ports.QueryAdminHTTP)
 
 	querySvc := makeQuerySvc()
-	server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), querySvc.qs, nil,
+	server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), metrics.NullFactory, querySvc.qs, nil,
 		&QueryOptions{GRPCHostPort: hostPort, HTTPHostPort: hostPort},
 		tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
 	require.NoError(t, err)
@@ -670,6 +937,35 @@ func TestServerGracefulExit(t *testing.T) {
 	}
 }
 
+func TestServerHandleSignals(t *testing.T) {
+	flagsSvc := flags.NewService(ports.QueryAdminHTTP)
+	flagsSvc.Logger = zaptest.NewLogger(t)
+
+	server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), metrics.NullFactory, &querysvc.QueryService{}, nil,
+		&QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0", AllowNoStorage: true},
+		tenancy.NewManager(&tenancy.Options{}), jtracer.NoOp())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	flagsSvc.HC().Ready()
+
+	server.HandleSignals(context.Background())
+	// installing the handler again has no effect
+	server.HandleSignals(context.Background())
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGTERM))
+
+	select {
+	case <-server.closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the server was not stopped by the signal")
+	}
+	assert.Equal(t, healthcheck.Unavailable, flagsSvc.HC().Get())
+	// Close after the drain has nothing left to do
+	require.NoError(t, server.Close())
+}
+
 func TestServerHandlesPortZero(t *testing.T) {
 	flagsSvc := flags.NewService(ports.QueryAdminHTTP)
 	zapCore, logs := observer.New(zap.InfoLevel)
@@ -677,8 +973,8 @@ func TestServerHandlesPortZero(t *testing.T) {
 
 	querySvc := &querysvc.QueryService{}
 	tracer := jtracer.NoOp()
-	server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), querySvc, nil,
-		&QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0"},
+	server, err := NewServer(flagsSvc.Logger, flagsSvc.HC(), metrics.NullFactory, querySvc, nil,
+		&QueryOptions{GRPCHostPort: ":0", HTTPHostPort: ":0", AllowNoStorage: true},
 		tenancy.NewManager(&tenancy.Options{}),
 		tracer)
 	require.NoError(t, err)
@@ -735,7 +1031,7 @@ func TestServerHTTPTenancy(t *testing.T) {
 	tenancyMgr := tenancy.NewManager(&serverOptions.Tenancy)
 	querySvc := makeQuerySvc()
 	querySvc.spanReader.On("FindTraces", mock.Anything, mock.Anything).Return([]*model.Trace{mockTrace}, nil).Once()
-	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), querySvc.qs,
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, querySvc.qs,
 		nil, serverOptions, tenancyMgr, jtracer.NoOp())
 	require.NoError(t, err)
 	require.NoError(t, server.Start())
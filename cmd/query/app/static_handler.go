@@ -17,6 +17,7 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -45,11 +46,19 @@ var (
 	basePathPattern    = regexp.MustCompile(`<base href="/"`) // Note: tag is not closed
 )
 
+const (
+	// the bundled assets under /static/ have content hashes in their names, so never change
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// index.html and the other assets are revalidated, e.g. to pick up a reloaded UI config
+	revalidateCacheControl = "no-cache"
+)
+
 // RegisterStaticHandler adds handler for static assets to the router.
 func RegisterStaticHandler(r *mux.Router, logger *zap.Logger, qOpts *QueryOptions, qCapabilities querysvc.StorageCapabilities) io.Closer {
 	staticHandler, err := NewStaticAssetsHandler(qOpts.StaticAssets.Path, StaticAssetsHandlerOptions{
 		BasePath:            qOpts.BasePath,
 		UIConfigPath:        qOpts.UIConfig,
+		AssetsSHA256:        qOpts.StaticAssets.SHA256,
 		StorageCapabilities: qCapabilities,
 		Logger:              logger,
 		LogAccess:           qOpts.StaticAssets.LogAccess,
@@ -69,12 +78,16 @@ type StaticAssetsHandler struct {
 	indexHTML atomic.Value // stores []byte
 	assetsFS  http.FileSystem
 	watcher   *fswatcher.FSWatcher
+	// tempDir holds the assets downloaded at startup, removed on Close
+	tempDir string
 }
 
 // StaticAssetsHandlerOptions defines options for NewStaticAssetsHandler
 type StaticAssetsHandlerOptions struct {
-	BasePath            string
-	UIConfigPath        string
+	BasePath     string
+	UIConfigPath string
+	// AssetsSHA256 is the checksum of the tarball of the assets, when staticAssetsRoot is a URL
+	AssetsSHA256        string
 	LogAccess           bool
 	StorageCapabilities querysvc.StorageCapabilities
 	Logger              *zap.Logger
@@ -85,30 +98,43 @@ type loadedConfig struct {
 	config []byte
 }
 
-// NewStaticAssetsHandler returns a StaticAssetsHandler
+// NewStaticAssetsHandler returns a StaticAssetsHandler serving the embedded assets, or the ones of
+// staticAssetsRoot if not empty, either a directory or the http(s) URL of a tarball downloaded now.
 func NewStaticAssetsHandler(staticAssetsRoot string, options StaticAssetsHandlerOptions) (*StaticAssetsHandler, error) {
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+
+	var tempDir string
+	if isUIAssetsURL(staticAssetsRoot) {
+		root, dir, err := downloadUIAssets(context.Background(), staticAssetsRoot, options.AssetsSHA256, options.Logger)
+		if err != nil {
+			return nil, err
+		}
+		staticAssetsRoot, tempDir = root, dir
+	}
+
 	assetsFS := ui.StaticFiles
 	if staticAssetsRoot != "" {
 		assetsFS = http.Dir(staticAssetsRoot)
 	}
 
-	if options.Logger == nil {
-		options.Logger = zap.NewNop()
-	}
-
 	h := &StaticAssetsHandler{
 		options:  options,
 		assetsFS: assetsFS,
+		tempDir:  tempDir,
 	}
 
 	indexHTML, err := h.loadAndEnrichIndexHTML(assetsFS.Open)
 	if err != nil {
+		h.removeTempDir()
 		return nil, err
 	}
 
 	options.Logger.Info("Using UI configuration", zap.String("path", options.UIConfigPath))
 	watcher, err := fswatcher.New([]string{options.UIConfigPath}, h.reloadUIConfig, h.options.Logger)
 	if err != nil {
+		h.removeTempDir()
 		return nil, err
 	}
 	h.watcher = watcher
@@ -230,16 +256,61 @@ func (sH *StaticAssetsHandler) RegisterRoutes(router *mux.Router) {
 	if sH.options.BasePath != "/" {
 		fileServer = http.StripPrefix(sH.options.BasePath+"/", fileServer)
 	}
-	router.PathPrefix("/static/").Handler(sH.loggingHandler(fileServer))
+	router.PathPrefix("/static/").Handler(sH.loggingHandler(cacheControlHandler(fileServer, immutableCacheControl)))
 	// index.html is served by notFound handler
 	router.NotFoundHandler = sH.loggingHandler(http.HandlerFunc(sH.notFound))
 }
 
-func (sH *StaticAssetsHandler) notFound(w http.ResponseWriter, _ *http.Request) {
+func cacheControlHandler(h http.Handler, cacheControl string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", cacheControl)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// notFound serves the other assets at the root of the bundle, e.g. favicon.ico,
+// and index.html for all the other paths, as they are routes of the UI.
+func (sH *StaticAssetsHandler) notFound(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", revalidateCacheControl)
+	if sH.serveAsset(w, r) {
+		return
+	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write(sH.indexHTML.Load().([]byte))
 }
 
+// serveAsset serves the file of the assets at the path of the request, if any, except index.html.
+func (sH *StaticAssetsHandler) serveAsset(w http.ResponseWriter, r *http.Request) bool {
+	name := r.URL.Path
+	if sH.options.BasePath != "/" {
+		name = strings.TrimPrefix(name, sH.options.BasePath)
+	}
+	if name == "" || name == "/" || name == "/index.html" {
+		return false
+	}
+	f, err := sH.assetsFS.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		return false
+	}
+	http.ServeContent(w, r, stat.Name(), stat.ModTime(), f)
+	return true
+}
+
+func (sH *StaticAssetsHandler) removeTempDir() {
+	if sH.tempDir == "" {
+		return
+	}
+	if err := os.RemoveAll(sH.tempDir); err != nil {
+		sH.options.Logger.Warn("Failed to remove the downloaded UI assets", zap.String("dir", sH.tempDir), zap.Error(err))
+	}
+}
+
 func (sH *StaticAssetsHandler) Close() error {
+	defer sH.removeTempDir()
 	return sH.watcher.Close()
 }
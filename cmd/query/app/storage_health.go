@@ -0,0 +1,142 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+)
+
+// healthCheckedServices are the services whose gRPC health status follows the state of the storage.
+// The empty name is the overall status of the server.
+var healthCheckedServices = []string{
+	"",
+	"jaeger.api_v2.QueryService",
+	"jaeger.api_v2.CriticalPathService",
+	"jaeger.api_v2.TraceProfileService",
+	"jaeger.api_v2.OperationLatenciesService",
+	"jaeger.api_v2.SearchValidationService",
+	"jaeger.api_v2.TraceArchiveService",
+	"jaeger.api_v2.metrics.MetricsQueryService",
+	"jaeger.api_v3.QueryService",
+}
+
+// StoragePinger checks that the storage backend is reachable.
+type StoragePinger interface {
+	Ping(ctx context.Context) error
+}
+
+// queryServicePinger pings the storage by listing the services, which is supported by all backends.
+type queryServicePinger struct {
+	querySvc *querysvc.QueryService
+}
+
+func (p queryServicePinger) Ping(ctx context.Context) error {
+	_, err := p.querySvc.GetServices(ctx)
+	return err
+}
+
+// storageHealthMonitor periodically pings the storage and reports the services as NOT_SERVING
+// through the gRPC health server when the storage has been failing for longer than the threshold.
+// The services are reported as SERVING again as soon as the storage recovers.
+type storageHealthMonitor struct {
+	pinger       StoragePinger
+	healthServer *health.Server
+	interval     time.Duration
+	threshold    time.Duration
+	logger       *zap.Logger
+
+	failingSince time.Time
+	notServing   atomic.Bool
+	// maintenance, if set, keeps the services not serving while in maintenance
+	maintenance *MaintenanceMode
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newStorageHealthMonitor(
+	pinger StoragePinger,
+	healthServer *health.Server,
+	interval time.Duration,
+	threshold time.Duration,
+	logger *zap.Logger,
+) *storageHealthMonitor {
+	return &storageHealthMonitor{
+		pinger:       pinger,
+		healthServer: healthServer,
+		interval:     interval,
+		threshold:    threshold,
+		logger:       logger,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+func (m *storageHealthMonitor) start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				m.check(now)
+			case <-m.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (m *storageHealthMonitor) stop() {
+	close(m.stopCh)
+	m.wg.Wait()
+}
+
+// check pings the storage once and updates the health status. It must not be called concurrently.
+func (m *storageHealthMonitor) check(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+	err := m.pinger.Ping(ctx)
+	cancel()
+
+	if err == nil {
+		if m.notServing.Load() {
+			m.logger.Info("Storage has recovered, reporting services as serving")
+			m.setStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+		}
+		m.failingSince = time.Time{}
+		m.notServing.Store(false)
+		return
+	}
+
+	if m.failingSince.IsZero() {
+		m.logger.Warn("Storage health check failed", zap.Error(err))
+		m.failingSince = now
+	}
+	if !m.notServing.Load() && now.Sub(m.failingSince) >= m.threshold {
+		m.logger.Error("Storage has been failing for too long, reporting services as not serving",
+			zap.Duration("failing-for", now.Sub(m.failingSince)), zap.Error(err))
+		m.setStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		m.notServing.Store(true)
+	}
+}
+
+func (m *storageHealthMonitor) setStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if m.maintenance != nil && m.maintenance.enabled() {
+		// the status is restored when exiting the maintenance
+		return
+	}
+	for _, service := range healthCheckedServices {
+		m.healthServer.SetServingStatus(service, status)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+	"github.com/jaegertracing/jaeger/pkg/healthcheck"
+	"github.com/jaegertracing/jaeger/pkg/jtracer"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	depsmocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanstoremocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+type fakePinger struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (p *fakePinger) Ping(context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *fakePinger) setError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+func assertServingStatus(t *testing.T, healthServer *health.Server, expected grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	for _, service := range healthCheckedServices {
+		res, err := healthServer.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, expected, res.Status, "service %q", service)
+	}
+}
+
+// apiServices are services registered by the query gRPC server, whose health status must follow
+// the storage and the maintenance mode.
+var apiServices = []string{
+	"jaeger.api_v2.TraceProfileService",
+	"jaeger.api_v2.OperationLatenciesService",
+	"jaeger.api_v2.SearchValidationService",
+	"jaeger.api_v2.TraceArchiveService",
+}
+
+// apiServicesHave returns whether the health service of the server reports the status for all the apiServices.
+func apiServicesHave(ctx context.Context, client grpc_health_v1.HealthClient, expected grpc_health_v1.HealthCheckResponse_ServingStatus) bool {
+	for _, service := range apiServices {
+		res, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil || res.Status != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStorageHealthMonitorTransitions(t *testing.T) {
+	pinger := &fakePinger{}
+	healthServer := health.NewServer()
+	m := newStorageHealthMonitor(pinger, healthServer, time.Second, 10*time.Second, zap.NewNop())
+	m.setStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+
+	start := time.Now()
+	m.check(start)
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	pinger.setError(assert.AnError)
+	m.check(start.Add(time.Second))
+	m.check(start.Add(10 * time.Second))
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	m.check(start.Add(11 * time.Second))
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	pinger.setError(nil)
+	m.check(start.Add(12 * time.Second))
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_SERVING)
+
+	// the failure duration starts again from the next failure
+	pinger.setError(assert.AnError)
+	m.check(start.Add(13 * time.Second))
+	m.check(start.Add(20 * time.Second))
+	assertServingStatus(t, healthServer, grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	updates chan grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+func (s *fakeWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchStream) Send(res *grpc_health_v1.HealthCheckResponse) error {
+	s.updates <- res.Status
+	return nil
+}
+
+func TestStorageHealthMonitorWatch(t *testing.T) {
+	pinger := &fakePinger{}
+	healthServer := health.NewServer()
+	m := newStorageHealthMonitor(pinger, healthServer, time.Second, time.Second, zap.NewNop())
+	m.setStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeWatchStream{ctx: ctx, updates: make(chan grpc_health_v1.HealthCheckResponse_ServingStatus, 1)}
+	done := make(chan error)
+	go func() {
+		done <- healthServer.Watch(&grpc_health_v1.HealthCheckRequest{Service: "jaeger.api_v2.QueryService"}, stream)
+	}()
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, <-stream.updates)
+
+	pinger.setError(assert.AnError)
+	start := time.Now()
+	m.check(start)
+	m.check(start.Add(2 * time.Second))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, <-stream.updates)
+
+	pinger.setError(nil)
+	m.check(start.Add(3 * time.Second))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, <-stream.updates)
+
+	cancel()
+	require.Error(t, <-done)
+}
+
+func TestServerStorageHealthWatch(t *testing.T) {
+	pinger := &fakePinger{}
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, makeQuerySvc().qs, nil,
+		&QueryOptions{
+			GRPCHostPort:               ":0",
+			HTTPHostPort:               ":0",
+			StorageHealthCheckInterval: 10 * time.Millisecond,
+			StorageFailureThreshold:    50 * time.Millisecond,
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	server.storagePinger = pinger
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	conn, err := grpc.NewClient(server.grpcConn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	res, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "jaeger.api_v2.QueryService"})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, res.Status)
+
+	watch, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "jaeger.api_v3.QueryService"})
+	require.NoError(t, err)
+	update, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, update.Status)
+
+	pinger.setError(assert.AnError)
+	update, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, update.Status)
+
+	res, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "jaeger.api_v2.QueryService"})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, res.Status)
+
+	pinger.setError(nil)
+	update, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, update.Status)
+}
+
+func TestServerStorageHealthServices(t *testing.T) {
+	pinger := &fakePinger{}
+	server, err := NewServer(zaptest.NewLogger(t), healthcheck.New(), metrics.NullFactory, makeQuerySvc().qs, nil,
+		&QueryOptions{
+			GRPCHostPort:               ":0",
+			HTTPHostPort:               ":0",
+			StorageHealthCheckInterval: 10 * time.Millisecond,
+			StorageFailureThreshold:    50 * time.Millisecond,
+		},
+		tenancy.NewManager(&tenancy.Options{}),
+		jtracer.NoOp())
+	require.NoError(t, err)
+	server.storagePinger = pinger
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+	})
+
+	conn, err := grpc.NewClient(server.grpcConn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+	client := grpc_health_v1.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	assert.True(t, apiServicesHave(ctx, client, grpc_health_v1.HealthCheckResponse_SERVING))
+	pinger.setError(assert.AnError)
+	assert.Eventually(t, func() bool {
+		return apiServicesHave(ctx, client, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}, 10*time.Second, 10*time.Millisecond)
+	pinger.setError(nil)
+	assert.Eventually(t, func() bool {
+		return apiServicesHave(ctx, client, grpc_health_v1.HealthCheckResponse_SERVING)
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestQueryServicePinger(t *testing.T) {
+	spanReader := &spanstoremocks.Reader{}
+	spanReader.On("GetServices", mock.Anything).Return(nil, assert.AnError).Once()
+	spanReader.On("GetServices", mock.Anything).Return([]string{"test"}, nil)
+	pinger := queryServicePinger{
+		querySvc: querysvc.NewQueryService(spanReader, &depsmocks.Reader{}, querysvc.QueryServiceOptions{}),
+	}
+	require.ErrorIs(t, pinger.Ping(context.Background()), assert.AnError)
+	require.NoError(t, pinger.Ping(context.Background()))
+}
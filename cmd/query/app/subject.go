@@ -0,0 +1,62 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+)
+
+// subjectHandler attaches the authenticated subject found in the header to the request context.
+func subjectHandler(h http.Handler, header string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subject := r.Header.Get(header); subject != "" {
+			r = r.WithContext(querysvc.ContextWithSubject(r.Context(), subject))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func subjectFromMetadata(ctx context.Context, header string) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if subjects := md.Get(strings.ToLower(header)); len(subjects) == 1 {
+		return querysvc.ContextWithSubject(ctx, subjects[0])
+	}
+	return ctx
+}
+
+// newSubjectUnaryInterceptor attaches the authenticated subject found in the metadata to the request context.
+func newSubjectUnaryInterceptor(header string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(subjectFromMetadata(ctx, header), req)
+	}
+}
+
+// newSubjectStreamInterceptor attaches the authenticated subject found in the metadata to the stream context.
+func newSubjectStreamInterceptor(header string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &subjectServerStream{
+			ServerStream: ss,
+			ctx:          subjectFromMetadata(ss.Context(), header),
+		})
+	}
+}
+
+type subjectServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *subjectServerStream) Context() context.Context {
+	return s.ctx
+}
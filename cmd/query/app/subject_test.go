@@ -0,0 +1,78 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+)
+
+func TestSubjectHandler(t *testing.T) {
+	var subject string
+	handler := subjectHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		subject = querysvc.GetSubject(r.Context())
+	}), "X-Forwarded-User")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/traces", nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "alice", subject)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/traces", nil))
+	assert.Empty(t, subject)
+}
+
+func TestSubjectUnaryInterceptor(t *testing.T) {
+	interceptor := newSubjectUnaryInterceptor("X-Forwarded-User")
+	handler := func(ctx context.Context, _ any) (any, error) {
+		return querysvc.GetSubject(ctx), nil
+	}
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{name: "no metadata", ctx: context.Background()},
+		{name: "no subject", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", "acme"))},
+		{name: "several subjects", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-user", "alice", "x-forwarded-user", "bob"))},
+		{name: "subject", ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-user", "alice")), expected: "alice"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject, err := interceptor(tc.ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, subject)
+		})
+	}
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestSubjectStreamInterceptor(t *testing.T) {
+	interceptor := newSubjectStreamInterceptor("X-Forwarded-User")
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-user", "alice"))
+	var subject string
+	err := interceptor(nil, &contextServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		subject = querysvc.GetSubject(stream.Context())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", subject)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// uiAssetsDownloadTimeout bounds the download of the UI bundle at startup
+	uiAssetsDownloadTimeout = 2 * time.Minute
+	// maxUIAssetsBytes bounds the size of the UI bundle tarball and of its extracted files
+	maxUIAssetsBytes = 256 * 1024 * 1024
+)
+
+// isUIAssetsURL returns whether the path of the UI assets is the URL of a tarball rather than a directory.
+func isUIAssetsURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// downloadUIAssets downloads the tarball of the UI bundle, optionally gzipped, verifies its SHA-256 checksum
+// and extracts it to a temporary directory, returning the directory holding index.html and the temporary one to remove.
+func downloadUIAssets(ctx context.Context, url string, checksum string, logger *zap.Logger) (string, string, error) {
+	expected, err := hex.DecodeString(checksum)
+	if err != nil || len(expected) != sha256.Size {
+		return "", "", fmt.Errorf("invalid SHA-256 checksum of the UI assets %q", checksum)
+	}
+	ctx, cancel := context.WithTimeout(ctx, uiAssetsDownloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid UI assets URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot download the UI assets from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("cannot download the UI assets from %s: %s", url, resp.Status)
+	}
+	tarball, err := io.ReadAll(io.LimitReader(resp.Body, maxUIAssetsBytes+1))
+	if err != nil {
+		return "", "", fmt.Errorf("cannot download the UI assets from %s: %w", url, err)
+	}
+	if len(tarball) > maxUIAssetsBytes {
+		return "", "", fmt.Errorf("the UI assets downloaded from %s exceed %d bytes", url, maxUIAssetsBytes)
+	}
+	if actual := sha256.Sum256(tarball); !bytes.Equal(actual[:], expected) {
+		return "", "", fmt.Errorf("the SHA-256 checksum of the UI assets downloaded from %s is %x, expected %s", url, actual, checksum)
+	}
+
+	tempDir, err := os.MkdirTemp("", "jaeger-ui-")
+	if err != nil {
+		return "", "", err
+	}
+	if err := extractTarball(bytes.NewReader(tarball), tempDir); err != nil {
+		os.RemoveAll(tempDir)
+		return "", "", fmt.Errorf("cannot extract the UI assets downloaded from %s: %w", url, err)
+	}
+	root, err := findIndexHTMLDir(tempDir)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return "", "", fmt.Errorf("invalid UI assets downloaded from %s: %w", url, err)
+	}
+	logger.Info("Downloaded the UI assets", zap.String("url", url), zap.String("dir", root))
+	return root, tempDir, nil
+}
+
+// extractTarball extracts the directories and regular files of the tarball, optionally gzipped, to dir.
+func extractTarball(r io.Reader, dir string) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+	tr := tar.NewReader(r)
+	var extracted int64
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path %q in the tarball", header.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			extracted += header.Size
+			if extracted > maxUIAssetsBytes {
+				return fmt.Errorf("the extracted files exceed %d bytes", maxUIAssetsBytes)
+			}
+			if err := extractFile(tr, target); err != nil {
+				return err
+			}
+		default:
+			// links and special files are not needed to serve the UI
+		}
+	}
+}
+
+func extractFile(r io.Reader, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// findIndexHTMLDir returns dir if it holds index.html, or its single subdirectory holding it,
+// as the bundles are often archived with their build directory.
+func findIndexHTMLDir(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); err == nil {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		subDir := filepath.Join(dir, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(subDir, "index.html")); err == nil {
+			return subDir, nil
+		}
+	}
+	return "", errors.New("index.html not found at the root of the tarball")
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
+)
+
+const fakeUIIndexHTML = `<!DOCTYPE html>
+<html>
+<base href="/" data-inject-target="BASE_URL" />
+<script>
+// JAEGER_CONFIG_JS
+JAEGER_CONFIG = DEFAULT_CONFIG;
+JAEGER_STORAGE_CAPABILITIES = DEFAULT_STORAGE_CAPABILITIES;
+JAEGER_VERSION = DEFAULT_VERSION;
+</script>
+<title>Custom Jaeger UI</title>
+</html>
+`
+
+// fakeUIBundle holds the files of a minimal UI bundle.
+var fakeUIBundle = map[string]string{
+	"index.html":       fakeUIIndexHTML,
+	"favicon.ico":      "icon",
+	"static/app-1a.js": "console.log('custom');",
+}
+
+func writeFakeUIBundle(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range fakeUIBundle {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return dir
+}
+
+// fakeUITarball returns the gzipped tarball of the fake UI bundle under the prefix, and its checksum.
+func fakeUITarball(t *testing.T, prefix string) ([]byte, string) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if prefix != "" {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: prefix, Typeflag: tar.TypeDir, Mode: 0o755}))
+	}
+	for _, name := range []string{"index.html", "favicon.ico", "static/app-1a.js"} {
+		content := fakeUIBundle[name]
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     prefix + name,
+			Typeflag: tar.TypeReg,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	checksum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(checksum[:])
+}
+
+func serveTarball(t *testing.T, tarball []byte) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jaeger-ui.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(tarball)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// assertFakeUIServed asserts that the fake UI bundle is served under /jaeger, with the injections.
+func assertFakeUIServed(t *testing.T, assetsPath string, checksum string) {
+	r := mux.NewRouter().PathPrefix("/jaeger").Subrouter()
+	closer := RegisterStaticHandler(r, zap.NewNop(), &QueryOptions{
+		QueryOptionsBase: QueryOptionsBase{
+			StaticAssets: QueryOptionsStaticAssets{Path: assetsPath, SHA256: checksum},
+			BasePath:     "/jaeger",
+			UIConfig:     "fixture/ui-config.json",
+		},
+	}, querysvc.StorageCapabilities{ArchiveStorage: true})
+	defer closer.Close()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	get := func(path string) (string, *http.Response) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "path %s", path)
+		return string(body), resp
+	}
+
+	// the client-side routes fall back to index.html
+	for _, path := range []string{"/jaeger/", "/jaeger/search", "/jaeger/trace/abc"} {
+		html, resp := get(path)
+		assert.Contains(t, html, "Custom Jaeger UI")
+		assert.Contains(t, html, `<base href="/jaeger/"`)
+		assert.Contains(t, html, `JAEGER_CONFIG = {"x":"y"};`)
+		assert.Contains(t, html, `JAEGER_STORAGE_CAPABILITIES = {"archiveStorage":true};`)
+		assert.Contains(t, html, `JAEGER_VERSION = {"gitCommit":"","gitVersion":"","buildDate":""};`)
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+		assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	}
+
+	js, resp := get("/jaeger/static/app-1a.js")
+	assert.Equal(t, fakeUIBundle["static/app-1a.js"], js)
+	assert.Equal(t, immutableCacheControl, resp.Header.Get("Cache-Control"))
+
+	icon, resp := get("/jaeger/favicon.ico")
+	assert.Equal(t, "icon", icon)
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+}
+
+func TestStaticAssetsHandlerDirectory(t *testing.T) {
+	assertFakeUIServed(t, writeFakeUIBundle(t), "")
+}
+
+func TestStaticAssetsHandlerTarball(t *testing.T) {
+	for _, prefix := range []string{"", "build/"} {
+		t.Run("prefix="+prefix, func(t *testing.T) {
+			tarball, checksum := fakeUITarball(t, prefix)
+			server := serveTarball(t, tarball)
+			assertFakeUIServed(t, server.URL+"/jaeger-ui.tar.gz", checksum)
+		})
+	}
+}
+
+func TestStaticAssetsHandlerTarballRemovedOnClose(t *testing.T) {
+	tarball, checksum := fakeUITarball(t, "")
+	server := serveTarball(t, tarball)
+	h, err := NewStaticAssetsHandler(server.URL+"/jaeger-ui.tar.gz", StaticAssetsHandlerOptions{AssetsSHA256: checksum})
+	require.NoError(t, err)
+	require.DirExists(t, h.tempDir)
+	require.NoError(t, h.Close())
+	assert.NoDirExists(t, h.tempDir)
+}
+
+func TestStaticAssetsHandlerTarballErrors(t *testing.T) {
+	tarball, checksum := fakeUITarball(t, "")
+	server := serveTarball(t, tarball)
+
+	var traversal bytes.Buffer
+	tw := tar.NewWriter(&traversal)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../index.html", Typeflag: tar.TypeReg, Mode: 0o644}))
+	require.NoError(t, tw.Close())
+	traversalChecksum := sha256.Sum256(traversal.Bytes())
+	traversalServer := serveTarball(t, traversal.Bytes())
+
+	emptyChecksum := sha256.Sum256(nil)
+	emptyServer := serveTarball(t, nil)
+
+	tests := []struct {
+		name          string
+		url           string
+		checksum      string
+		expectedError string
+	}{
+		{
+			name:          "missing checksum",
+			url:           server.URL + "/jaeger-ui.tar.gz",
+			expectedError: "invalid SHA-256 checksum",
+		},
+		{
+			name:          "checksum mismatch",
+			url:           server.URL + "/jaeger-ui.tar.gz",
+			checksum:      hex.EncodeToString(emptyChecksum[:]),
+			expectedError: "expected " + hex.EncodeToString(emptyChecksum[:]),
+		},
+		{
+			name:          "not found",
+			url:           server.URL + "/unknown.tar.gz",
+			checksum:      checksum,
+			expectedError: "404 Not Found",
+		},
+		{
+			name:          "path traversal",
+			url:           traversalServer.URL + "/jaeger-ui.tar.gz",
+			checksum:      hex.EncodeToString(traversalChecksum[:]),
+			expectedError: `invalid path "../index.html"`,
+		},
+		{
+			name:          "no index.html",
+			url:           emptyServer.URL + "/jaeger-ui.tar.gz",
+			checksum:      hex.EncodeToString(emptyChecksum[:]),
+			expectedError: "index.html not found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewStaticAssetsHandler(test.url, StaticAssetsHandlerOptions{AssetsSHA256: test.checksum})
+			require.ErrorContains(t, err, test.expectedError)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/zipkin/zipkinv2"
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+
+	"github.com/jaegertracing/jaeger/internal/otlptranslator"
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// zipkinV2Format is the format parameter of the JSON media type asking for the traces as Zipkin v2 spans.
+const zipkinV2Format = "zipkin-v2"
+
+// acceptsZipkinV2 returns whether the client asks for the traces in the Zipkin v2 JSON format,
+// with Accept: application/json; format=zipkin-v2.
+func acceptsZipkinV2(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err == nil && mediaType == "application/json" && params["format"] == zipkinV2Format {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modelToZipkin translates the spans of a trace to Zipkin v2 spans, through their OTLP representation.
+// The parent of a Zipkin span is the span of its first CHILD_OF reference, or of its first reference.
+func modelToZipkin(trace *model.Trace) ([]*zipkinmodel.SpanModel, error) {
+	td, err := otlptranslator.ProtoToTraces([]*model.Batch{{Spans: trace.Spans}})
+	if err != nil {
+		return nil, fmt.Errorf("cannot translate the trace to OTLP: %w", err)
+	}
+	spans, err := zipkinv2.FromTranslator{}.FromTraces(td)
+	if err != nil {
+		return nil, fmt.Errorf("cannot translate the trace to Zipkin: %w", err)
+	}
+	if spans == nil {
+		spans = []*zipkinmodel.SpanModel{}
+	}
+	return spans, nil
+}
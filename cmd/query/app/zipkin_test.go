@@ -0,0 +1,160 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var zipkinAcceptHeader = map[string]string{"Accept": "application/json; format=zipkin-v2"}
+
+func TestAcceptsZipkinV2(t *testing.T) {
+	tests := []struct {
+		accept   []string
+		expected bool
+	}{
+		{accept: nil, expected: false},
+		{accept: []string{"application/json"}, expected: false},
+		{accept: []string{"application/json; format=zipkin-v2"}, expected: true},
+		{accept: []string{`application/json;format="zipkin-v2"`}, expected: true},
+		{accept: []string{"text/event-stream, application/json; format=zipkin-v2"}, expected: true},
+		{accept: []string{"application/json", "application/json; format=zipkin-v2"}, expected: true},
+		{accept: []string{"application/json; format=zipkin-v1"}, expected: false},
+		{accept: []string{"text/plain; format=zipkin-v2"}, expected: false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/traces", nil)
+		for _, accept := range test.accept {
+			req.Header.Add("Accept", accept)
+		}
+		assert.Equal(t, test.expected, acceptsZipkinV2(req), "%v", test.accept)
+	}
+}
+
+// zipkinTestTrace has a root span of the frontend calling the backend, which follows up asynchronously.
+func zipkinTestTrace() *model.Trace {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	frontend := model.NewProcess("frontend", nil)
+	backend := model.NewProcess("backend", nil)
+	return &model.Trace{Spans: []*model.Span{
+		{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(1),
+			OperationName: "dispatch",
+			StartTime:     start,
+			Duration:      100 * time.Millisecond,
+			Tags:          []model.KeyValue{model.String("span.kind", "server")},
+			Process:       frontend,
+		},
+		{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "find-driver",
+			References:    []model.SpanRef{model.NewChildOfRef(mockTraceID, model.NewSpanID(1))},
+			StartTime:     start.Add(10 * time.Millisecond),
+			Duration:      50 * time.Millisecond,
+			Tags:          []model.KeyValue{model.String("span.kind", "server")},
+			Process:       backend,
+		},
+		{
+			TraceID:       mockTraceID,
+			SpanID:        model.NewSpanID(3),
+			OperationName: "notify",
+			References:    []model.SpanRef{model.NewFollowsFromRef(mockTraceID, model.NewSpanID(2))},
+			StartTime:     start.Add(70 * time.Millisecond),
+			Duration:      5 * time.Millisecond,
+			Process:       backend,
+		},
+	}}
+}
+
+// assertZipkinTrace verifies the Zipkin v2 spans of zipkinTestTrace.
+func assertZipkinTrace(t *testing.T, spans []zipkinmodel.SpanModel) {
+	require.Len(t, spans, 3)
+	byID := make(map[zipkinmodel.ID]zipkinmodel.SpanModel)
+	for _, span := range spans {
+		assert.Equal(t, zipkinmodel.TraceID{Low: mockTraceID.Low}, span.TraceID)
+		byID[span.ID] = span
+	}
+
+	root := byID[zipkinmodel.ID(1)]
+	assert.Nil(t, root.ParentID)
+	assert.Equal(t, "dispatch", root.Name)
+	assert.Equal(t, zipkinmodel.Server, root.Kind)
+	assert.Equal(t, "frontend", root.LocalEndpoint.ServiceName)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), root.Timestamp.UTC())
+	assert.Equal(t, 100*time.Millisecond, root.Duration)
+
+	child := byID[zipkinmodel.ID(2)]
+	require.NotNil(t, child.ParentID)
+	assert.Equal(t, zipkinmodel.ID(1), *child.ParentID)
+	assert.Equal(t, "backend", child.LocalEndpoint.ServiceName)
+
+	followsFrom := byID[zipkinmodel.ID(3)]
+	require.NotNil(t, followsFrom.ParentID)
+	assert.Equal(t, zipkinmodel.ID(2), *followsFrom.ParentID)
+}
+
+func TestGetTraceZipkinV2(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(zipkinTestTrace(), nil).Once()
+
+	// the Zipkin spans are validated while being unmarshalled
+	var spans []zipkinmodel.SpanModel
+	require.NoError(t, getJSONCustomHeaders(ts.server.URL+"/api/traces/"+mockTraceID.String(), zipkinAcceptHeader, &spans))
+	assertZipkinTrace(t, spans)
+}
+
+func TestSearchZipkinV2(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("FindTraces", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("*spanstore.TraceQueryParameters")).
+		Return([]*model.Trace{zipkinTestTrace()}, nil).Once()
+
+	var traces [][]zipkinmodel.SpanModel
+	require.NoError(t, getJSONCustomHeaders(ts.server.URL+"/api/traces?service=frontend", zipkinAcceptHeader, &traces))
+	require.Len(t, traces, 1)
+	assertZipkinTrace(t, traces[0])
+}
+
+func TestSearchByTraceIDsZipkinV2(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mockTraceID).
+		Return(zipkinTestTrace(), nil).Once()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), model.NewTraceID(0, 1)).
+		Return(nil, spanstore.ErrTraceNotFound).Once()
+
+	var traces [][]zipkinmodel.SpanModel
+	require.NoError(t, getJSONCustomHeaders(
+		ts.server.URL+"/api/traces?traceID="+mockTraceID.String()+"&traceID=1", zipkinAcceptHeader, &traces))
+	// the traces not found are left out
+	require.Len(t, traces, 1)
+	assertZipkinTrace(t, traces[0])
+}
+
+func TestGetTraceDefaultsToJaegerJSON(t *testing.T) {
+	ts := initializeTestServer()
+	defer ts.server.Close()
+	ts.spanReader.On("GetTrace", mock.AnythingOfType("*context.valueCtx"), mock.AnythingOfType("model.TraceID")).
+		Return(zipkinTestTrace(), nil).Once()
+
+	var response structuredTraceResponse
+	require.NoError(t, getJSON(ts.server.URL+"/api/traces/"+mockTraceID.String(), &response))
+	require.Len(t, response.Traces, 1)
+	assert.Len(t, response.Traces[0].Spans, 3)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/jaegertracing/jaeger/cmd/internal/printconfig"
 	"github.com/jaegertracing/jaeger/cmd/internal/status"
 	"github.com/jaegertracing/jaeger/cmd/query/app"
+	"github.com/jaegertracing/jaeger/cmd/query/app/federation"
 	"github.com/jaegertracing/jaeger/cmd/query/app/querysvc"
 	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/config"
@@ -42,7 +43,10 @@ import (
 	metricsPlugin "github.com/jaegertracing/jaeger/plugin/metrics"
 	"github.com/jaegertracing/jaeger/plugin/storage"
 	"github.com/jaegertracing/jaeger/ports"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	metricsstoreMetrics "github.com/jaegertracing/jaeger/storage/metricsstore/metrics"
+	"github.com/jaegertracing/jaeger/storage/slowquery"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 	spanstoreMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
 )
 
@@ -79,44 +83,86 @@ func main() {
 				logger.Fatal("Failed to configure query service", zap.Error(err))
 			}
 
+			tracingOpts, err := new(jtracer.Options).InitFromViper(v)
+			if err != nil {
+				logger.Fatal("Failed to configure the self-tracing", zap.Error(err))
+			}
 			jt := jtracer.NoOp()
-			if queryOpts.EnableTracing {
-				jt, err = jtracer.New("jaeger-query")
+			// selecting a self-tracing exporter enables the tracing on its own
+			if queryOpts.EnableTracing || tracingOpts.Exporter != "" {
+				jt, err = jtracer.NewFromOptions("jaeger-query", *tracingOpts)
 				if err != nil {
 					logger.Fatal("Failed to create tracer", zap.Error(err))
 				}
 			}
 
-			// TODO: Need to figure out set enable/disable propagation on storage plugins.
-			v.Set(bearertoken.StoragePropagationKey, queryOpts.BearerTokenPropagation)
-			storageFactory.InitFromViper(v, logger)
-			if err := storageFactory.Initialize(baseFactory, logger); err != nil {
-				logger.Fatal("Failed to init storage factory", zap.Error(err))
-			}
-			spanReader, err := storageFactory.CreateSpanReader()
-			if err != nil {
-				logger.Fatal("Failed to create span reader", zap.Error(err))
+			var spanReader spanstore.Reader
+			var dependencyReader dependencystore.Reader
+			var queryServiceOptions *querysvc.QueryServiceOptions
+			var closeStorage func() error
+			if len(queryOpts.Federation.Endpoints) > 0 {
+				logger.Info("Reading from federation upstreams instead of the storage", zap.Int("upstreams", len(queryOpts.Federation.Endpoints)))
+				conns, err := federation.Connect(queryOpts.Federation.Endpoints, logger)
+				if err != nil {
+					logger.Fatal("Failed to connect to federation upstreams", zap.Error(err))
+				}
+				reader := federation.NewReader(conns.Upstreams, queryOpts.Federation.Timeout, logger)
+				spanReader = spanstoreMetrics.NewReadMetricsDecorator(reader, metricsFactory)
+				dependencyReader = reader
+				queryServiceOptions = queryOpts.BuildQueryServiceOptions(nil, logger)
+				closeStorage = conns.Close
+			} else {
+				// TODO: Need to figure out set enable/disable propagation on storage plugins.
+				v.Set(bearertoken.StoragePropagationKey, queryOpts.BearerTokenPropagation)
+				storageFactory.InitFromViper(v, logger)
+				if err := storageFactory.Initialize(baseFactory, logger); err != nil {
+					logger.Fatal("Failed to init storage factory", zap.Error(err))
+				}
+				spanReader, err = storageFactory.CreateSpanReader()
+				if err != nil {
+					logger.Fatal("Failed to create span reader", zap.Error(err))
+				}
+				spanReader = spanstoreMetrics.NewReadMetricsDecorator(spanReader, metricsFactory)
+				dependencyReader, err = storageFactory.CreateDependencyReader()
+				if err != nil {
+					logger.Fatal("Failed to create dependency reader", zap.Error(err))
+				}
+				queryServiceOptions = queryOpts.BuildQueryServiceOptions(storageFactory, logger)
+				closeStorage = storageFactory.Close
 			}
-			spanReader = spanstoreMetrics.NewReadMetricsDecorator(spanReader, metricsFactory)
-			dependencyReader, err := storageFactory.CreateDependencyReader()
-			if err != nil {
-				logger.Fatal("Failed to create dependency reader", zap.Error(err))
+			if queryOpts.SlowQueries.Threshold > 0 {
+				slowQueries := slowquery.NewLog(queryOpts.SlowQueries, logger)
+				spanReader = slowquery.NewSpanReader(spanReader, slowQueries)
+				dependencyReader = slowquery.NewDependencyReader(dependencyReader, slowQueries)
+				svc.Admin.Handle(slowquery.Path, slowQueries)
 			}
 
 			metricsQueryService, err := createMetricsQueryService(metricsReaderFactory, v, logger, metricsFactory)
 			if err != nil {
 				logger.Fatal("Failed to create metrics query service", zap.Error(err))
 			}
-			queryServiceOptions := queryOpts.BuildQueryServiceOptions(storageFactory, logger)
+			queryServiceOptions.MetricsFactory = metricsFactory
 			queryService := querysvc.NewQueryService(
 				spanReader,
 				dependencyReader,
 				*queryServiceOptions)
-			tm := tenancy.NewManager(&queryOpts.Tenancy)
-			server, err := app.NewServer(svc.Logger, svc.HC(), queryService, metricsQueryService, queryOpts, tm, jt)
+			tm, err := tenancy.NewManagerWithConfigFile(&queryOpts.Tenancy, logger)
+			if err != nil {
+				logger.Fatal("Failed to create the tenancy manager", zap.Error(err))
+			}
+			server, err := app.NewServer(svc.Logger, svc.HC(), metricsFactory, queryService, metricsQueryService, queryOpts, tm, jt)
 			if err != nil {
 				logger.Fatal("Failed to create server", zap.Error(err))
 			}
+			svc.Admin.Handle(app.AdminTracesPath, app.NewAdminHandler(queryService, queryOpts, tm, jt, svc.Logger))
+			svc.Admin.Handle(app.AdminMaintenancePath, server.Maintenance())
+			if queryOpts.AdminTokenFile != "" {
+				configHandler, err := app.NewAdminConfigHandler(queryOpts)
+				if err != nil {
+					logger.Fatal("Failed to create the admin configuration handler", zap.Error(err))
+				}
+				svc.Admin.Handle(app.AdminConfigPath, configHandler)
+			}
 
 			if err := server.Start(); err != nil {
 				logger.Fatal("Could not start servers", zap.Error(err))
@@ -124,7 +170,10 @@ func main() {
 
 			svc.RunAndThen(func() {
 				server.Close()
-				if err := storageFactory.Close(); err != nil {
+				if err := tm.Close(); err != nil {
+					logger.Error("Failed to close the tenancy manager", zap.Error(err))
+				}
+				if err := closeStorage(); err != nil {
 					logger.Error("Failed to close storage factory", zap.Error(err))
 				}
 				if err = jt.Close(context.Background()); err != nil {
@@ -150,6 +199,7 @@ func main() {
 		metricsReaderFactory.AddFlags,
 		// add tenancy flags here to avoid panic caused by double registration in all-in-one
 		tenancy.AddFlags,
+		jtracer.AddFlags,
 	)
 
 	if err := command.Execute(); err != nil {
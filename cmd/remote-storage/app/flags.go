@@ -31,7 +31,8 @@ const (
 )
 
 var tlsGRPCFlagsConfig = tlscfg.ServerFlagsConfig{
-	Prefix: "grpc",
+	Prefix:              "grpc",
+	EnableALPNProtocols: true,
 }
 
 // Options holds configuration for remote-storage service.
@@ -101,7 +101,7 @@ func createGRPCServer(opts *Options, tm *tenancy.Manager, handler *shared.GRPCHa
 	var grpcOpts []grpc.ServerOption
 
 	if opts.TLSGRPC.Enabled {
-		tlsCfg, err := opts.TLSGRPC.Config(logger)
+		tlsCfg, err := opts.TLSGRPC.GRPCConfig(logger)
 		if err != nil {
 			return nil, fmt.Errorf("invalid TLS config: %w", err)
 		}
@@ -76,7 +76,10 @@ func main() {
 				logger.Fatal("Failed to init storage factory", zap.Error(err))
 			}
 
-			tm := tenancy.NewManager(&opts.Tenancy)
+			tm, err := tenancy.NewManagerWithConfigFile(&opts.Tenancy, logger)
+			if err != nil {
+				logger.Fatal("Failed to create the tenancy manager", zap.Error(err))
+			}
 			server, err := app.NewServer(opts, storageFactory, tm, svc.Logger, svc.HC())
 			if err != nil {
 				logger.Fatal("Failed to create server", zap.Error(err))
@@ -88,6 +91,9 @@ func main() {
 
 			svc.RunAndThen(func() {
 				server.Close()
+				if err := tm.Close(); err != nil {
+					logger.Error("Failed to close the tenancy manager", zap.Error(err))
+				}
 				if err := storageFactory.Close(); err != nil {
 					logger.Error("Failed to close storage factory", zap.Error(err))
 				}
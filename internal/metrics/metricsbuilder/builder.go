@@ -16,32 +16,52 @@
 package metricsbuilder
 
 import (
+	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/internal/metrics/otelmetrics"
 	jprom "github.com/jaegertracing/jaeger/internal/metrics/prometheus"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 )
 
 const (
 	metricsBackend        = "metrics-backend"
 	metricsHTTPRoute      = "metrics-http-route"
+	metricsOTLPProtocol   = "metrics.otlp.protocol"
+	metricsOTLPEndpoint   = "metrics.otlp.endpoint"
+	metricsOTLPHeaders    = "metrics.otlp.headers"
+	metricsOTLPInterval   = "metrics.otlp.interval"
 	defaultMetricsBackend = "prometheus"
 	defaultMetricsRoute   = "/metrics"
 )
 
+var otlpTLSFlagsConfig = tlscfg.ClientFlagsConfig{
+	Prefix: "metrics.otlp",
+}
+
 var errUnknownBackend = errors.New("unknown metrics backend specified")
 
 // Builder provides command line options to configure metrics backend used by Jaeger executables.
 type Builder struct {
 	Backend   string
 	HTTPRoute string // endpoint name to expose metrics, e.g. for scraping
-	handler   http.Handler
+	// OTLP configures the export of the metrics with the otlp backend
+	OTLP    otelmetrics.ExporterOptions
+	handler http.Handler
+	logger  *zap.Logger
+	// shutdown flushes and stops the exporting backends
+	shutdown func(context.Context) error
 }
 
 // AddFlags adds flags for Builder.
@@ -49,18 +69,63 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.String(
 		metricsBackend,
 		defaultMetricsBackend,
-		"Defines which metrics backend to use for metrics reporting: prometheus or none")
+		"Defines which metrics backend to use for metrics reporting: prometheus, otlp or none")
 	flags.String(
 		metricsHTTPRoute,
 		defaultMetricsRoute,
 		"Defines the route of HTTP endpoint for metrics backends that support scraping")
+	flags.String(
+		metricsOTLPProtocol,
+		otelmetrics.ProtocolGRPC,
+		"The protocol of the OTLP metrics exporter: grpc or http")
+	flags.String(
+		metricsOTLPEndpoint,
+		"",
+		"The host:port of the OTLP metrics receiver, defaults to localhost:4317 for grpc and localhost:4318 for http")
+	flags.String(
+		metricsOTLPHeaders,
+		"",
+		"Comma-separated list of key=value headers sent with the OTLP metrics exports, e.g. for authentication")
+	flags.Duration(
+		metricsOTLPInterval,
+		time.Minute,
+		"The interval between the exports of the OTLP metrics")
+	otlpTLSFlagsConfig.AddFlags(flags)
 }
 
 // InitFromViper initializes Builder with properties retrieved from Viper.
-func (b *Builder) InitFromViper(v *viper.Viper) *Builder {
+func (b *Builder) InitFromViper(v *viper.Viper, logger *zap.Logger) (*Builder, error) {
 	b.Backend = v.GetString(metricsBackend)
 	b.HTTPRoute = v.GetString(metricsHTTPRoute)
-	return b
+	b.logger = logger
+	b.OTLP.Protocol = v.GetString(metricsOTLPProtocol)
+	b.OTLP.Endpoint = v.GetString(metricsOTLPEndpoint)
+	b.OTLP.Interval = v.GetDuration(metricsOTLPInterval)
+	headers, err := parseHeaders(v.GetString(metricsOTLPHeaders))
+	if err != nil {
+		return b, err
+	}
+	b.OTLP.Headers = headers
+	if b.OTLP.TLS, err = otlpTLSFlagsConfig.InitFromViper(v); err != nil {
+		return b, err
+	}
+	return b, nil
+}
+
+// parseHeaders parses a comma-separated list of key=value headers.
+func parseHeaders(list string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, header := range strings.Split(list, ",") {
+		if header = strings.TrimSpace(header); header == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(header, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid header %q of %s, expected key=value", header, metricsOTLPHeaders)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return headers, nil
 }
 
 // CreateMetricsFactory creates a metrics factory based on the configured type of the backend.
@@ -72,6 +137,20 @@ func (b *Builder) CreateMetricsFactory(namespace string) (metrics.Factory, error
 		b.handler = promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{DisableCompression: true})
 		return metricsFactory, nil
 	}
+	if b.Backend == "otlp" {
+		logger := b.logger
+		if logger == nil {
+			logger = zap.NewNop()
+		}
+		meterProvider, err := otelmetrics.NewMeterProvider(context.Background(), b.OTLP, logger)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the OTLP metrics exporter: %w", err)
+		}
+		b.shutdown = func(ctx context.Context) error {
+			return errors.Join(meterProvider.Shutdown(ctx), b.OTLP.TLS.Close())
+		}
+		return otelmetrics.New(meterProvider).Namespace(metrics.NSOptions{Name: namespace, Tags: nil}), nil
+	}
 	if b.Backend == "none" || b.Backend == "" {
 		return metrics.NullFactory, nil
 	}
@@ -82,3 +161,11 @@ func (b *Builder) CreateMetricsFactory(namespace string) (metrics.Factory, error
 func (b *Builder) Handler() http.Handler {
 	return b.handler
 }
+
+// Shutdown exports the last metrics of the exporting backends, and stops them.
+func (b *Builder) Shutdown(ctx context.Context) error {
+	if b.shutdown == nil {
+		return nil
+	}
+	return b.shutdown(ctx)
+}
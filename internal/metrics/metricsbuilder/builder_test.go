@@ -16,15 +16,19 @@
 package metricsbuilder
 
 import (
+	"context"
 	"flag"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 
+	"github.com/jaegertracing/jaeger/internal/metrics/otelmetrics"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 )
@@ -40,13 +44,62 @@ func TestAddFlags(t *testing.T) {
 	command.ParseFlags([]string{
 		"--metrics-backend=foo",
 		"--metrics-http-route=bar",
+		"--metrics.otlp.protocol=http",
+		"--metrics.otlp.endpoint=collector:4318",
+		"--metrics.otlp.headers=authorization=Bearer token, x-tenant=acme",
+		"--metrics.otlp.interval=10s",
 	})
 
 	b := &Builder{}
-	b.InitFromViper(v)
+	_, err := b.InitFromViper(v, zap.NewNop())
+	require.NoError(t, err)
 
 	assert.Equal(t, "foo", b.Backend)
 	assert.Equal(t, "bar", b.HTTPRoute)
+	assert.Equal(t, "http", b.OTLP.Protocol)
+	assert.Equal(t, "collector:4318", b.OTLP.Endpoint)
+	assert.Equal(t, map[string]string{"authorization": "Bearer token", "x-tenant": "acme"}, b.OTLP.Headers)
+	assert.Equal(t, 10*time.Second, b.OTLP.Interval)
+	assert.False(t, b.OTLP.TLS.Enabled)
+}
+
+func TestInitFromViperBadHeaders(t *testing.T) {
+	v := viper.New()
+	command := cobra.Command{}
+	flags := &flag.FlagSet{}
+	AddFlags(flags)
+	command.PersistentFlags().AddGoFlagSet(flags)
+	v.BindPFlags(command.PersistentFlags())
+
+	command.ParseFlags([]string{"--metrics.otlp.headers=authorization"})
+
+	_, err := new(Builder).InitFromViper(v, zap.NewNop())
+	require.ErrorContains(t, err, `invalid header "authorization"`)
+}
+
+func TestBuilderOTLP(t *testing.T) {
+	b := &Builder{
+		Backend: "otlp",
+		OTLP: otelmetrics.ExporterOptions{
+			Protocol: otelmetrics.ProtocolHTTP,
+			// nothing listens there, the metrics are not exported before the shutdown
+			Endpoint: "localhost:1",
+			Interval: time.Hour,
+		},
+	}
+	mf, err := b.CreateMetricsFactory("foo")
+	require.NoError(t, err)
+	require.NotNil(t, mf)
+	assert.Nil(t, b.Handler())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// the export of the last metrics fails, the receiver being unreachable
+	require.Error(t, b.Shutdown(ctx))
+
+	b.OTLP.Protocol = "udp"
+	_, err = b.CreateMetricsFactory("foo")
+	require.ErrorContains(t, err, "unknown OTLP protocol")
 }
 
 func TestBuilder(t *testing.T) {
@@ -106,6 +159,7 @@ func TestBuilder(t *testing.T) {
 		if testCase.handler {
 			require.NotNil(t, b.Handler())
 		}
+		require.NoError(t, b.Shutdown(context.Background()))
 	}
 }
 
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelmetrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+// Protocols of the OTLP exporter
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// ExporterOptions configure the export of the metrics over OTLP
+type ExporterOptions struct {
+	// Protocol is grpc or http
+	Protocol string
+	// Endpoint is the host:port of the receiver, the OTLP default of the protocol being used when empty
+	Endpoint string
+	// Headers are sent with each export, e.g. for authentication
+	Headers map[string]string
+	// Interval is the period of the exports
+	Interval time.Duration
+	// TLS configures the connection to the receiver, which is insecure when TLS is disabled
+	TLS tlscfg.Options
+}
+
+// NewMeterProvider creates a meter provider exporting the metrics periodically over OTLP,
+// with cumulative temporality like Prometheus. It must be shut down to export the last metrics.
+func NewMeterProvider(ctx context.Context, options ExporterOptions, logger *zap.Logger) (*sdkmetric.MeterProvider, error) {
+	exporter, err := newExporter(ctx, options, logger)
+	if err != nil {
+		return nil, err
+	}
+	var readerOptions []sdkmetric.PeriodicReaderOption
+	if options.Interval > 0 {
+		readerOptions = append(readerOptions, sdkmetric.WithInterval(options.Interval))
+	}
+	reader := sdkmetric.NewPeriodicReader(exporter, readerOptions...)
+	return sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), nil
+}
+
+func newExporter(ctx context.Context, options ExporterOptions, logger *zap.Logger) (sdkmetric.Exporter, error) {
+	switch options.Protocol {
+	case ProtocolGRPC:
+		grpcOptions := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(options.Headers)}
+		if options.Endpoint != "" {
+			grpcOptions = append(grpcOptions, otlpmetricgrpc.WithEndpoint(options.Endpoint))
+		}
+		if options.TLS.Enabled {
+			tlsCfg, err := options.TLS.Config(logger)
+			if err != nil {
+				return nil, err
+			}
+			grpcOptions = append(grpcOptions, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsCfg)))
+		} else {
+			grpcOptions = append(grpcOptions, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, grpcOptions...)
+	case ProtocolHTTP:
+		httpOptions := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(options.Headers)}
+		if options.Endpoint != "" {
+			httpOptions = append(httpOptions, otlpmetrichttp.WithEndpoint(options.Endpoint))
+		}
+		if options.TLS.Enabled {
+			tlsCfg, err := options.TLS.Config(logger)
+			if err != nil {
+				return nil, err
+			}
+			httpOptions = append(httpOptions, otlpmetrichttp.WithTLSClientConfig(tlsCfg))
+		} else {
+			httpOptions = append(httpOptions, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, httpOptions...)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q, expected %s or %s", options.Protocol, ProtocolGRPC, ProtocolHTTP)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelmetrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// receiver records the metrics and the headers of the OTLP exports it receives.
+type receiver struct {
+	pmetricotlp.UnimplementedGRPCServer
+	mu      sync.Mutex
+	metrics []pmetric.Metrics
+	tenants []string
+}
+
+func (r *receiver) record(m pmetric.Metrics, tenant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+	r.tenants = append(r.tenants, tenant)
+}
+
+func (r *receiver) Export(ctx context.Context, req pmetricotlp.ExportRequest) (pmetricotlp.ExportResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.record(req.Metrics(), strings.Join(md.Get("x-tenant"), ","))
+	return pmetricotlp.NewExportResponse(), nil
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exportRequest := pmetricotlp.NewExportRequest()
+	if err := exportRequest.UnmarshalProto(body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.record(exportRequest.Metrics(), req.Header.Get("x-tenant"))
+	response, err := pmetricotlp.NewExportResponse().MarshalProto()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(response)
+}
+
+// find returns the metric of the name in the exports received.
+func (r *receiver) find(name string) (pmetric.Metric, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.metrics {
+		for i := 0; i < m.ResourceMetrics().Len(); i++ {
+			sms := m.ResourceMetrics().At(i).ScopeMetrics()
+			for j := 0; j < sms.Len(); j++ {
+				ms := sms.At(j).Metrics()
+				for k := 0; k < ms.Len(); k++ {
+					if ms.At(k).Name() == name {
+						return ms.At(k), true
+					}
+				}
+			}
+		}
+	}
+	return pmetric.Metric{}, false
+}
+
+func startGRPCReceiver(t *testing.T, r *receiver) string {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pmetricotlp.RegisterGRPCServer(server, r)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func startHTTPReceiver(t *testing.T, r *receiver) string {
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	tests := []struct {
+		protocol string
+		start    func(*testing.T, *receiver) string
+	}{
+		{protocol: ProtocolGRPC, start: startGRPCReceiver},
+		{protocol: ProtocolHTTP, start: startHTTPReceiver},
+	}
+	for _, test := range tests {
+		t.Run(test.protocol, func(t *testing.T) {
+			r := &receiver{}
+			meterProvider, err := NewMeterProvider(context.Background(), ExporterOptions{
+				Protocol: test.protocol,
+				Endpoint: test.start(t, r),
+				Headers:  map[string]string{"x-tenant": "acme"},
+			}, zap.NewNop())
+			require.NoError(t, err)
+
+			f := New(meterProvider).Namespace(metrics.NSOptions{Name: "jaeger", Tags: map[string]string{"host": "h1"}})
+			f.Counter(metrics.Options{Name: "spans.received", Tags: map[string]string{"format": "proto"}}).Inc(5)
+
+			// the shutdown exports the last metrics
+			require.NoError(t, meterProvider.Shutdown(context.Background()))
+
+			m, ok := r.find("jaeger_spans_received")
+			require.True(t, ok, "the counter must be exported")
+			require.Equal(t, pmetric.MetricTypeSum, m.Type())
+			dp := m.Sum().DataPoints().At(0)
+			assert.Equal(t, int64(5), dp.IntValue())
+			assert.Equal(t, map[string]any{"host": "h1", "format": "proto"}, dp.Attributes().AsRaw())
+			assert.Equal(t, "acme", r.tenants[0])
+		})
+	}
+}
+
+func TestNewMeterProviderErrors(t *testing.T) {
+	_, err := NewMeterProvider(context.Background(), ExporterOptions{Protocol: "udp"}, zap.NewNop())
+	require.ErrorContains(t, err, `unknown OTLP protocol "udp"`)
+
+	for _, protocol := range []string{ProtocolGRPC, ProtocolHTTP} {
+		_, err = NewMeterProvider(context.Background(), ExporterOptions{
+			Protocol: protocol,
+			TLS:      tlscfg.Options{Enabled: true, CAPath: "/does/not/exist"},
+		}, zap.NewNop())
+		require.Error(t, err, protocol)
+	}
+}
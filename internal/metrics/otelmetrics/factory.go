@@ -0,0 +1,181 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelmetrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+const meterName = "github.com/jaegertracing/jaeger"
+
+// defaultBuckets are the default buckets of Prometheus, in seconds for the timers,
+// the default buckets of OpenTelemetry being meant for milliseconds.
+var defaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Factory implements metrics.Factory with the instruments of an OpenTelemetry meter.
+//
+// The instruments are named like the metrics of the Prometheus factory, so that their translation
+// to Prometheus, e.g. by the OpenTelemetry Collector, results in the same metrics: the namespaces
+// are joined with underscores, dots and dashes are replaced with underscores, the _total suffix
+// added to the counters by Prometheus is left out, and the timers are recorded in seconds.
+// The tags become the attributes of the measurements.
+type Factory struct {
+	meter      metric.Meter
+	scope      string
+	tags       map[string]string
+	normalizer *strings.Replacer
+}
+
+var _ metrics.Factory = (*Factory)(nil)
+
+// New creates a Factory creating its instruments with the meter provider.
+func New(meterProvider metric.MeterProvider) *Factory {
+	return &Factory{
+		meter:      meterProvider.Meter(meterName),
+		normalizer: strings.NewReplacer(".", "_", "-", "_"),
+	}
+}
+
+// Counter implements Counter of metrics.Factory.
+func (f *Factory) Counter(options metrics.Options) metrics.Counter {
+	name := strings.TrimSuffix(f.subScope(options.Name), "_total")
+	c, err := f.meter.Int64Counter(name, metric.WithDescription(f.description(options.Help, options.Name)))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return &counter{counter: c, attributes: f.attributes(options.Tags)}
+}
+
+// Gauge implements Gauge of metrics.Factory.
+func (f *Factory) Gauge(options metrics.Options) metrics.Gauge {
+	g, err := f.meter.Int64Gauge(f.subScope(options.Name), metric.WithDescription(f.description(options.Help, options.Name)))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return &gauge{gauge: g, attributes: f.attributes(options.Tags)}
+}
+
+// Timer implements Timer of metrics.Factory.
+func (f *Factory) Timer(options metrics.TimerOptions) metrics.Timer {
+	buckets := defaultBuckets
+	if len(options.Buckets) > 0 {
+		buckets = make([]float64, len(options.Buckets))
+		for i, bucket := range options.Buckets {
+			buckets[i] = bucket.Seconds()
+		}
+	}
+	h := f.histogram(f.subScope(options.Name), f.description(options.Help, options.Name), buckets)
+	return &timer{histogram: h, attributes: f.attributes(options.Tags)}
+}
+
+// Histogram implements Histogram of metrics.Factory.
+func (f *Factory) Histogram(options metrics.HistogramOptions) metrics.Histogram {
+	buckets := defaultBuckets
+	if len(options.Buckets) > 0 {
+		buckets = options.Buckets
+	}
+	h := f.histogram(f.subScope(options.Name), f.description(options.Help, options.Name), buckets)
+	return &histogram{histogram: h, attributes: f.attributes(options.Tags)}
+}
+
+func (f *Factory) histogram(name, description string, buckets []float64) metric.Float64Histogram {
+	h, err := f.meter.Float64Histogram(name,
+		metric.WithDescription(description),
+		metric.WithExplicitBucketBoundaries(buckets...))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return h
+}
+
+// Namespace implements Namespace of metrics.Factory.
+func (f *Factory) Namespace(scope metrics.NSOptions) metrics.Factory {
+	return &Factory{
+		meter:      f.meter,
+		scope:      f.subScope(scope.Name),
+		tags:       f.mergeTags(scope.Tags),
+		normalizer: f.normalizer,
+	}
+}
+
+func (f *Factory) subScope(name string) string {
+	if f.scope == "" {
+		return f.normalizer.Replace(name)
+	}
+	if name == "" {
+		return f.normalizer.Replace(f.scope)
+	}
+	return f.normalizer.Replace(f.scope + "_" + name)
+}
+
+func (*Factory) description(help, name string) string {
+	if help = strings.TrimSpace(help); help != "" {
+		return help
+	}
+	return name
+}
+
+func (f *Factory) mergeTags(tags map[string]string) map[string]string {
+	merged := make(map[string]string, len(f.tags)+len(tags))
+	for k, v := range f.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (f *Factory) attributes(tags map[string]string) metric.MeasurementOption {
+	tags = f.mergeTags(tags)
+	kvs := make([]attribute.KeyValue, 0, len(tags))
+	for k, v := range tags {
+		kvs = append(kvs, attribute.String(k, v))
+	}
+	return metric.WithAttributeSet(attribute.NewSet(kvs...))
+}
+
+type counter struct {
+	counter    metric.Int64Counter
+	attributes metric.MeasurementOption
+}
+
+func (c *counter) Inc(v int64) {
+	c.counter.Add(context.Background(), v, c.attributes)
+}
+
+type gauge struct {
+	gauge      metric.Int64Gauge
+	attributes metric.MeasurementOption
+}
+
+func (g *gauge) Update(v int64) {
+	g.gauge.Record(context.Background(), v, g.attributes)
+}
+
+type timer struct {
+	histogram  metric.Float64Histogram
+	attributes metric.MeasurementOption
+}
+
+func (t *timer) Record(d time.Duration) {
+	t.histogram.Record(context.Background(), d.Seconds(), t.attributes)
+}
+
+type histogram struct {
+	histogram  metric.Float64Histogram
+	attributes metric.MeasurementOption
+}
+
+func (h *histogram) Record(v float64) {
+	h.histogram.Record(context.Background(), v, h.attributes)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package otelmetrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	collected := make(map[string]metricdata.Metrics)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			collected[m.Name] = m
+		}
+	}
+	return collected
+}
+
+func TestFactory(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	f := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	ns := f.Namespace(metrics.NSOptions{Name: "jaeger-query", Tags: map[string]string{"component": "query"}}).
+		Namespace(metrics.NSOptions{Name: "http.server", Tags: nil})
+
+	ns.Counter(metrics.Options{Name: "requests_total", Tags: map[string]string{"status": "ok"}, Help: "Number of requests"}).Inc(3)
+	ns.Gauge(metrics.Options{Name: "queue-length"}).Update(7)
+	ns.Timer(metrics.TimerOptions{Name: "latency"}).Record(250 * time.Millisecond)
+	ns.Timer(metrics.TimerOptions{Name: "custom_latency", Buckets: []time.Duration{time.Second, 2 * time.Second}}).Record(time.Second)
+	ns.Histogram(metrics.HistogramOptions{Name: "size", Buckets: []float64{10, 100}}).Record(42)
+
+	collected := collect(t, reader)
+
+	counter := collected["jaeger_query_http_server_requests"]
+	assert.Equal(t, "Number of requests", counter.Description)
+	sum := counter.Data.(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(3), sum.DataPoints[0].Value)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t,
+		attribute.NewSet(attribute.String("component", "query"), attribute.String("status", "ok")),
+		sum.DataPoints[0].Attributes)
+
+	gauge := collected["jaeger_query_http_server_queue_length"].Data.(metricdata.Gauge[int64])
+	require.Len(t, gauge.DataPoints, 1)
+	assert.Equal(t, int64(7), gauge.DataPoints[0].Value)
+
+	timer := collected["jaeger_query_http_server_latency"].Data.(metricdata.Histogram[float64])
+	require.Len(t, timer.DataPoints, 1)
+	assert.InDelta(t, 0.25, timer.DataPoints[0].Sum, 1e-9)
+	assert.Equal(t, defaultBuckets, timer.DataPoints[0].Bounds)
+
+	customTimer := collected["jaeger_query_http_server_custom_latency"].Data.(metricdata.Histogram[float64])
+	assert.Equal(t, []float64{1, 2}, customTimer.DataPoints[0].Bounds)
+
+	histogram := collected["jaeger_query_http_server_size"].Data.(metricdata.Histogram[float64])
+	require.Len(t, histogram.DataPoints, 1)
+	assert.InDelta(t, 42.0, histogram.DataPoints[0].Sum, 1e-9)
+	assert.Equal(t, []float64{10, 100}, histogram.DataPoints[0].Bounds)
+}
+
+func TestFactoryNamespaceWithoutName(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	f := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	f.Counter(metrics.Options{Name: "root"}).Inc(1)
+	f.Namespace(metrics.NSOptions{Name: "ns"}).Counter(metrics.Options{Name: ""}).Inc(1)
+	f.Namespace(metrics.NSOptions{Tags: map[string]string{"a": "b"}}).Counter(metrics.Options{Name: "tagged"}).Inc(1)
+
+	collected := collect(t, reader)
+	assert.Contains(t, collected, "root")
+	assert.Contains(t, collected, "ns")
+	assert.Equal(t, "tagged", collected["tagged"].Description)
+	sum := collected["tagged"].Data.(metricdata.Sum[int64])
+	assert.Equal(t, attribute.NewSet(attribute.String("a", "b")), sum.DataPoints[0].Attributes)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package otlptranslator converts between OTLP traces and the Jaeger model.
+// It wraps the OpenTelemetry Collector contrib translator and preserves the data that the
+// Jaeger model has no representation for as well-known tags, so that the conversion is lossless:
+//   - dropped attributes, events and links counts of spans, and dropped attributes counts
+//     of events, are stored in the otel.dropped_* span tags and log fields;
+//   - link attributes and dropped attributes counts are stored in span tags prefixed with
+//     otel.link.<trace-id>.<span-id>, identifying the link by the span it points to.
+package otlptranslator
+
+import (
+	"fmt"
+	"strings"
+
+	jaegertranslator "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// TagDroppedAttributesCount is the span tag or log field holding the number of dropped attributes.
+	TagDroppedAttributesCount = "otel.dropped_attributes_count"
+	// TagDroppedEventsCount is the span tag holding the number of dropped events.
+	TagDroppedEventsCount = "otel.dropped_events_count"
+	// TagDroppedLinksCount is the span tag holding the number of dropped links.
+	TagDroppedLinksCount = "otel.dropped_links_count"
+
+	// linkTagPrefix starts the span tags holding the data of a link, followed by
+	// <trace-id>.<span-id>. and either linkAttributesTagPrefix and the attribute key,
+	// or linkDroppedAttributesCountTag.
+	linkTagPrefix                 = "otel.link."
+	linkAttributesTagPrefix       = "attributes."
+	linkDroppedAttributesCountTag = "dropped_attributes_count"
+
+	// refTypeAttribute is the link attribute already represented by the type of the span reference.
+	refTypeAttribute = "opentracing.ref_type"
+)
+
+// ProtoFromTraces converts OTLP traces to Jaeger model batches.
+func ProtoFromTraces(td ptrace.Traces) ([]*model.Batch, error) {
+	batches, err := jaegertranslator.ProtoFromTraces(td)
+	if err != nil {
+		return nil, err
+	}
+	// the contrib translator creates a batch per resource, except for empty resources without spans,
+	// and the spans of a batch in the order of the scopes and of the spans within them
+	next := 0
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		if rs.Resource().Attributes().Len() == 0 && rs.ScopeSpans().Len() == 0 {
+			continue
+		}
+		if next >= len(batches) {
+			break
+		}
+		batch := batches[next]
+		next++
+		spanIndex := 0
+		for j := 0; j < rs.ScopeSpans().Len(); j++ {
+			spans := rs.ScopeSpans().At(j).Spans()
+			for k := 0; k < spans.Len() && spanIndex < len(batch.Spans); k++ {
+				addOTLPTags(spans.At(k), batch.Spans[spanIndex])
+				spanIndex++
+			}
+		}
+	}
+	return batches, nil
+}
+
+// ProtoToTraces converts Jaeger model batches to OTLP traces.
+func ProtoToTraces(batches []*model.Batch) (ptrace.Traces, error) {
+	td, err := jaegertranslator.ProtoToTraces(batches)
+	if err != nil {
+		return td, err
+	}
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				restoreOTLPFields(spans.At(k))
+			}
+		}
+	}
+	return td, nil
+}
+
+func addOTLPTags(span ptrace.Span, jSpan *model.Span) {
+	jSpan.Tags = appendCount(jSpan.Tags, TagDroppedAttributesCount, span.DroppedAttributesCount())
+	jSpan.Tags = appendCount(jSpan.Tags, TagDroppedEventsCount, span.DroppedEventsCount())
+	jSpan.Tags = appendCount(jSpan.Tags, TagDroppedLinksCount, span.DroppedLinksCount())
+
+	events := span.Events()
+	for i := 0; i < events.Len() && i < len(jSpan.Logs); i++ {
+		jSpan.Logs[i].Fields = appendCount(jSpan.Logs[i].Fields, TagDroppedAttributesCount, events.At(i).DroppedAttributesCount())
+	}
+
+	links := span.Links()
+	for i := 0; i < links.Len(); i++ {
+		link := links.At(i)
+		prefix := linkTagKeyPrefix(link.TraceID(), link.SpanID())
+		link.Attributes().Range(func(k string, v pcommon.Value) bool {
+			if k != refTypeAttribute {
+				jSpan.Tags = append(jSpan.Tags, valueToTag(prefix+linkAttributesTagPrefix+k, v))
+			}
+			return true
+		})
+		jSpan.Tags = appendCount(jSpan.Tags, prefix+linkDroppedAttributesCountTag, link.DroppedAttributesCount())
+	}
+}
+
+func restoreOTLPFields(span ptrace.Span) {
+	attrs := span.Attributes()
+	span.SetDroppedAttributesCount(removeCount(attrs, TagDroppedAttributesCount))
+	span.SetDroppedEventsCount(removeCount(attrs, TagDroppedEventsCount))
+	span.SetDroppedLinksCount(removeCount(attrs, TagDroppedLinksCount))
+
+	events := span.Events()
+	for i := 0; i < events.Len(); i++ {
+		event := events.At(i)
+		event.SetDroppedAttributesCount(removeCount(event.Attributes(), TagDroppedAttributesCount))
+	}
+
+	links := make(map[string]ptrace.SpanLink, span.Links().Len())
+	for i := 0; i < span.Links().Len(); i++ {
+		link := span.Links().At(i)
+		links[linkTagKeyPrefix(link.TraceID(), link.SpanID())] = link
+	}
+	attrs.RemoveIf(func(k string, v pcommon.Value) bool {
+		prefix, rest, ok := splitLinkTagKey(k)
+		if !ok {
+			return false
+		}
+		link, ok := links[prefix]
+		if !ok {
+			// the reference was removed, or it is the parent span and has no link
+			return true
+		}
+		if attrKey, ok := strings.CutPrefix(rest, linkAttributesTagPrefix); ok {
+			v.CopyTo(link.Attributes().PutEmpty(attrKey))
+		} else if rest == linkDroppedAttributesCountTag {
+			link.SetDroppedAttributesCount(uint32(v.Int()))
+		}
+		return true
+	})
+}
+
+func linkTagKeyPrefix(traceID pcommon.TraceID, spanID pcommon.SpanID) string {
+	return fmt.Sprintf("%s%s.%s.", linkTagPrefix, traceID, spanID)
+}
+
+// splitLinkTagKey splits a link tag key into the prefix identifying the link and the rest.
+func splitLinkTagKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, linkTagPrefix)
+	if !ok {
+		return "", "", false
+	}
+	parts := strings.SplitN(rest, ".", 3)
+	if len(parts) != 3 {
+		return "", "", false
+	}
+	return linkTagPrefix + parts[0] + "." + parts[1] + ".", parts[2], true
+}
+
+func appendCount(tags []model.KeyValue, key string, count uint32) []model.KeyValue {
+	if count == 0 {
+		return tags
+	}
+	return append(tags, model.Int64(key, int64(count)))
+}
+
+func removeCount(attrs pcommon.Map, key string) uint32 {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return 0
+	}
+	count := v.Int()
+	attrs.Remove(key)
+	return uint32(count)
+}
+
+// valueToTag converts an attribute value to a tag, in the same way as the contrib translator.
+func valueToTag(key string, v pcommon.Value) model.KeyValue {
+	switch v.Type() {
+	case pcommon.ValueTypeBool:
+		return model.Bool(key, v.Bool())
+	case pcommon.ValueTypeInt:
+		return model.Int64(key, v.Int())
+	case pcommon.ValueTypeDouble:
+		return model.Float64(key, v.Double())
+	case pcommon.ValueTypeBytes:
+		return model.Binary(key, v.Bytes().AsRaw())
+	default:
+		return model.String(key, v.AsString())
+	}
+}
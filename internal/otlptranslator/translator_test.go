@@ -0,0 +1,158 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlptranslator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+)
+
+func loadTraces(t *testing.T, name string) ptrace.Traces {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	td, err := new(ptrace.JSONUnmarshaler).UnmarshalTraces(data)
+	require.NoError(t, err)
+	return td
+}
+
+// normalizeTraces converts the traces to generic JSON with attributes sorted by key and
+// empty attributes removed, because neither is preserved by the contrib translator.
+func normalizeTraces(t *testing.T, td ptrace.Traces) any {
+	data, err := new(ptrace.JSONMarshaler).MarshalTraces(td)
+	require.NoError(t, err)
+	var v any
+	require.NoError(t, json.Unmarshal(data, &v))
+	sortAttributes(v)
+	return v
+}
+
+func sortAttributes(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if attrs, ok := child.([]any); ok && k == "attributes" && len(attrs) == 0 {
+				delete(v, k)
+				continue
+			}
+			if attrs, ok := child.([]any); ok && k == "attributes" {
+				sort.Slice(attrs, func(i, j int) bool {
+					return attrs[i].(map[string]any)["key"].(string) < attrs[j].(map[string]any)["key"].(string)
+				})
+			}
+			sortAttributes(child)
+		}
+	case []any:
+		for _, child := range v {
+			sortAttributes(child)
+		}
+	}
+}
+
+func findTag(t *testing.T, tags []model.KeyValue, key string) model.KeyValue {
+	tag, ok := model.KeyValues(tags).FindByKey(key)
+	require.True(t, ok, "tag %s not found", key)
+	return tag
+}
+
+func TestProtoFromTraces(t *testing.T) {
+	td := loadTraces(t, "traces_dropped_counts_and_links.json")
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0].Spans, 2)
+
+	span := batches[0].Spans[0]
+	assert.EqualValues(t, 3, findTag(t, span.Tags, TagDroppedAttributesCount).VInt64)
+	assert.EqualValues(t, 5, findTag(t, span.Tags, TagDroppedEventsCount).VInt64)
+	assert.EqualValues(t, 7, findTag(t, span.Tags, TagDroppedLinksCount).VInt64)
+	assert.EqualValues(t, 2, findTag(t, span.Logs[0].Fields, TagDroppedAttributesCount).VInt64)
+	_, ok := model.KeyValues(span.Logs[1].Fields).FindByKey(TagDroppedAttributesCount)
+	assert.False(t, ok, "zero counts are not stored")
+
+	linkPrefix := "otel.link.1112131415161718191a1b1c1d1e1f20.0000000000000009."
+	assert.Equal(t, "batch", findTag(t, span.Tags, linkPrefix+"attributes.link.reason").VStr)
+	assert.InDelta(t, 0.5, findTag(t, span.Tags, linkPrefix+"attributes.link.weight").VFloat64, 0.001)
+	assert.True(t, findTag(t, span.Tags, linkPrefix+"attributes.link.sampled").VBool)
+	assert.EqualValues(t, 4, findTag(t, span.Tags, linkPrefix+"dropped_attributes_count").VInt64)
+	_, ok = model.KeyValues(span.Tags).FindByKey(linkPrefix + "attributes.opentracing.ref_type")
+	assert.False(t, ok, "the reference type is stored in the span reference")
+
+	childSpan := batches[0].Spans[1]
+	_, ok = model.KeyValues(childSpan.Tags).FindByKey(TagDroppedAttributesCount)
+	assert.False(t, ok)
+	assert.EqualValues(t, 3, findTag(t, childSpan.Tags, "otel.link.1112131415161718191a1b1c1d1e1f20.000000000000000b.attributes.link.count").VInt64)
+}
+
+func TestRoundTrip(t *testing.T) {
+	td := loadTraces(t, "traces_dropped_counts_and_links.json")
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	actual, err := ProtoToTraces(batches)
+	require.NoError(t, err)
+	assert.Equal(t, normalizeTraces(t, td), normalizeTraces(t, actual))
+}
+
+func TestRoundTripWithoutOTLPFields(t *testing.T) {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "svc")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetTraceID([16]byte{1})
+	span.SetSpanID([8]byte{1})
+	span.SetName("op")
+	span.Attributes().PutStr("k", "v")
+
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	assert.Equal(t, []model.KeyValue{model.String("k", "v")}, batches[0].Spans[0].Tags)
+
+	actual, err := ProtoToTraces(batches)
+	require.NoError(t, err)
+	assert.Equal(t, normalizeTraces(t, td), normalizeTraces(t, actual))
+}
+
+func TestProtoFromTracesEmpty(t *testing.T) {
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty()
+	batches, err := ProtoFromTraces(td)
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+}
+
+func TestProtoToTracesLinkTagWithoutLink(t *testing.T) {
+	traceID := model.NewTraceID(1, 2)
+	batches := []*model.Batch{{
+		Process: model.NewProcess("svc", nil),
+		Spans: []*model.Span{{
+			TraceID:       traceID,
+			SpanID:        model.NewSpanID(2),
+			OperationName: "op",
+			References:    []model.SpanRef{model.NewChildOfRef(traceID, model.NewSpanID(1))},
+			Tags: []model.KeyValue{
+				model.String("otel.link.00000000000000010000000000000002.0000000000000001.attributes.k", "v"),
+				model.String("otel.link.malformed", "v"),
+			},
+		}},
+	}}
+	td, err := ProtoToTraces(batches)
+	require.NoError(t, err)
+	span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	assert.Equal(t, 0, span.Links().Len())
+	assert.Equal(t, map[string]any{"otel.link.malformed": "v"}, span.Attributes().AsRaw())
+}
+
+func TestMain(m *testing.M) {
+	testutils.VerifyGoLeaks(m)
+}
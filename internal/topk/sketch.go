@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package topk finds the heaviest keys of a stream, e.g. the services sending the most spans,
+// in bounded memory with the Space-Saving algorithm of Metwally, Agrawal and El Abbadi.
+package topk
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Item is a key with its estimated count. The estimate never undercounts,
+// and overcounts by at most Error, so the key was counted at least Count-Error times.
+type Item struct {
+	Key   string
+	Count uint64
+	Error uint64
+}
+
+// Sketch estimates the counts of the heaviest keys of a stream, monitoring at most capacity keys.
+// Any key counted more than total/capacity times is monitored. It is not safe for concurrent use.
+type Sketch struct {
+	capacity int
+	index    map[string]*entry
+	// entries is a min-heap on the counts, the least counted entry being replaced by new keys
+	entries entryHeap
+}
+
+type entry struct {
+	Item
+	heapIndex int
+}
+
+// NewSketch returns a Sketch monitoring at most capacity keys, at least 1.
+func NewSketch(capacity int) *Sketch {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Sketch{
+		capacity: capacity,
+		index:    make(map[string]*entry, capacity),
+		entries:  make(entryHeap, 0, capacity),
+	}
+}
+
+// Add counts the key weight times.
+func (s *Sketch) Add(key string, weight uint64) {
+	if e, ok := s.index[key]; ok {
+		e.Count += weight
+		heap.Fix(&s.entries, e.heapIndex)
+		return
+	}
+	if len(s.entries) < s.capacity {
+		e := &entry{Item: Item{Key: key, Count: weight}}
+		s.index[key] = e
+		heap.Push(&s.entries, e)
+		return
+	}
+	// the least counted key is replaced, the new key inheriting its count as the error
+	e := s.entries[0]
+	delete(s.index, e.Key)
+	e.Key = key
+	e.Error = e.Count
+	e.Count += weight
+	s.index[key] = e
+	heap.Fix(&s.entries, 0)
+}
+
+// Estimate returns the estimated count of the key, and false if the key is not monitored.
+func (s *Sketch) Estimate(key string) (Item, bool) {
+	e, ok := s.index[key]
+	if !ok {
+		return Item{}, false
+	}
+	return e.Item, true
+}
+
+// Top returns the n most counted keys, by decreasing counts.
+func (s *Sketch) Top(n int) []Item {
+	items := make([]Item, 0, len(s.entries))
+	for _, e := range s.entries {
+		items = append(items, e.Item)
+	}
+	return top(items, n)
+}
+
+// Len returns the number of monitored keys.
+func (s *Sketch) Len() int {
+	return len(s.entries)
+}
+
+// Reset forgets all the keys.
+func (s *Sketch) Reset() {
+	clear(s.index)
+	s.entries = s.entries[:0]
+}
+
+// top sorts the items by decreasing counts, then keys, and returns the first n.
+func top(items []Item, n int) []Item {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Key < items[j].Key
+	})
+	if n >= 0 && len(items) > n {
+		items = items[:n]
+	}
+	return items
+}
+
+type entryHeap []*entry
+
+func (h entryHeap) Len() int { return len(h) }
+
+func (h entryHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *entryHeap) Push(x any) {
+	e := x.(*entry)
+	e.heapIndex = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
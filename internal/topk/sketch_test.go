@@ -0,0 +1,102 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package topk
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+)
+
+func TestMain(m *testing.M) {
+	testutils.VerifyGoLeaks(m)
+}
+
+func TestSketchExactBelowCapacity(t *testing.T) {
+	s := NewSketch(3)
+	s.Add("a", 1)
+	s.Add("b", 5)
+	s.Add("a", 2)
+	s.Add("c", 3)
+	assert.Equal(t, []Item{{Key: "b", Count: 5}, {Key: "a", Count: 3}, {Key: "c", Count: 3}}, s.Top(10))
+	assert.Equal(t, []Item{{Key: "b", Count: 5}}, s.Top(1))
+	assert.Equal(t, 3, s.Len())
+
+	item, ok := s.Estimate("a")
+	require.True(t, ok)
+	assert.Equal(t, Item{Key: "a", Count: 3}, item)
+	_, ok = s.Estimate("d")
+	assert.False(t, ok)
+}
+
+func TestSketchReplacesLeastCounted(t *testing.T) {
+	s := NewSketch(2)
+	s.Add("a", 10)
+	s.Add("b", 2)
+	s.Add("c", 1)
+	// c replaces b, inheriting its count as the error
+	assert.Equal(t, []Item{{Key: "a", Count: 10}, {Key: "c", Count: 3, Error: 2}}, s.Top(-1))
+	_, ok := s.Estimate("b")
+	assert.False(t, ok)
+
+	s.Reset()
+	assert.Equal(t, 0, s.Len())
+	assert.Empty(t, s.Top(-1))
+}
+
+func TestSketchMinimumCapacity(t *testing.T) {
+	s := NewSketch(0)
+	s.Add("a", 1)
+	s.Add("b", 1)
+	assert.Equal(t, []Item{{Key: "b", Count: 2, Error: 1}}, s.Top(-1))
+}
+
+func TestSketchFindsHeavyHitters(t *testing.T) {
+	s := NewSketch(20)
+	exact := make(map[string]uint64)
+	r := rand.New(rand.NewSource(42))
+	add := func(key string) {
+		s.Add(key, 1)
+		exact[key]++
+	}
+	for i := 0; i < 100_000; i++ {
+		switch {
+		case i%10 < 3:
+			add("flood")
+		case i%10 < 5:
+			add("heavy")
+		case i%10 < 6:
+			add("medium")
+		default:
+			// a long tail of rare services
+			add(fmt.Sprintf("tail-%d", r.Intn(10_000)))
+		}
+	}
+	top := s.Top(3)
+	require.Len(t, top, 3)
+	for i, key := range []string{"flood", "heavy", "medium"} {
+		assert.Equal(t, key, top[i].Key)
+		// the estimates bound the exact counts
+		assert.LessOrEqual(t, top[i].Count-top[i].Error, exact[key])
+		assert.GreaterOrEqual(t, top[i].Count, exact[key])
+	}
+}
+
+func BenchmarkSketchAdd(b *testing.B) {
+	s := NewSketch(100)
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("service-%d", i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// a skewed stream, most spans coming from a few services
+		s.Add(keys[(i*i)%len(keys)%(1+i%len(keys))], 1)
+	}
+}
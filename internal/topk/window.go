@@ -0,0 +1,120 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package topk
+
+import (
+	"sync"
+	"time"
+)
+
+// Window estimates the counts of the heaviest keys over a sliding time window, split in buckets
+// each holding a Sketch of the keys counted during the bucket. It is safe for concurrent use.
+type Window struct {
+	bucketDuration time.Duration
+
+	mu      sync.Mutex
+	buckets []windowBucket
+}
+
+type windowBucket struct {
+	// epoch numbers the bucket durations since the Unix epoch, telling whether the bucket is stale
+	epoch  int64
+	sketch *Sketch
+}
+
+// NewWindow returns a Window over the duration split in the number of buckets, at least 1,
+// each monitoring at most capacity keys.
+func NewWindow(capacity int, window time.Duration, buckets int) *Window {
+	if buckets < 1 {
+		buckets = 1
+	}
+	w := &Window{
+		bucketDuration: window / time.Duration(buckets),
+		buckets:        make([]windowBucket, buckets),
+	}
+	if w.bucketDuration <= 0 {
+		w.bucketDuration = 1
+	}
+	for i := range w.buckets {
+		w.buckets[i] = windowBucket{epoch: -1, sketch: NewSketch(capacity)}
+	}
+	return w
+}
+
+// Duration returns the duration of the window.
+func (w *Window) Duration() time.Duration {
+	return w.bucketDuration * time.Duration(len(w.buckets))
+}
+
+// Add counts the key weight times at the time.
+func (w *Window) Add(now time.Time, key string, weight uint64) {
+	epoch := w.epoch(now)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := &w.buckets[epoch%int64(len(w.buckets))]
+	if b.epoch != epoch {
+		b.epoch = epoch
+		b.sketch.Reset()
+	}
+	b.sketch.Add(key, weight)
+}
+
+// Top returns the n most counted keys over the window ending at the time, by decreasing counts.
+// The counts of a key are summed over the buckets monitoring it, as well as the errors.
+func (w *Window) Top(now time.Time, n int) []Item {
+	items := make(map[string]Item)
+	w.forEachItem(now, func(item Item) {
+		sum := items[item.Key]
+		sum.Key = item.Key
+		sum.Count += item.Count
+		sum.Error += item.Error
+		items[item.Key] = sum
+	})
+	list := make([]Item, 0, len(items))
+	for _, item := range items {
+		list = append(list, item)
+	}
+	return top(list, n)
+}
+
+// Estimate returns the estimated count of the key over the window ending at the time.
+func (w *Window) Estimate(now time.Time, key string) Item {
+	epoch := w.epoch(now)
+	sum := Item{Key: key}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range w.buckets {
+		if !w.live(b, epoch) {
+			continue
+		}
+		if item, ok := b.sketch.Estimate(key); ok {
+			sum.Count += item.Count
+			sum.Error += item.Error
+		}
+	}
+	return sum
+}
+
+func (w *Window) forEachItem(now time.Time, f func(Item)) {
+	epoch := w.epoch(now)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range w.buckets {
+		if !w.live(b, epoch) {
+			continue
+		}
+		for _, e := range b.sketch.entries {
+			f(e.Item)
+		}
+	}
+}
+
+// live returns whether the bucket is within the window ending at the epoch.
+func (w *Window) live(b windowBucket, epoch int64) bool {
+	return b.epoch >= 0 && b.epoch <= epoch && b.epoch > epoch-int64(len(w.buckets))
+}
+
+func (w *Window) epoch(now time.Time) int64 {
+	return now.UnixNano() / int64(w.bucketDuration)
+}
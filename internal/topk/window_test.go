@@ -0,0 +1,57 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package topk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowSlides(t *testing.T) {
+	w := NewWindow(10, time.Minute, 6)
+	assert.Equal(t, time.Minute, w.Duration())
+
+	start := time.Unix(1_700_000_000, 0)
+	w.Add(start, "a", 5)
+	w.Add(start.Add(10*time.Second), "a", 1)
+	w.Add(start.Add(10*time.Second), "b", 3)
+	w.Add(start.Add(30*time.Second), "b", 4)
+
+	assert.Equal(t, []Item{{Key: "b", Count: 7}, {Key: "a", Count: 6}}, w.Top(start.Add(50*time.Second), 5))
+	assert.Equal(t, []Item{{Key: "b", Count: 7}}, w.Top(start.Add(50*time.Second), 1))
+	assert.Equal(t, Item{Key: "a", Count: 6}, w.Estimate(start.Add(50*time.Second), "a"))
+
+	// the first bucket leaves the window
+	assert.Equal(t, []Item{{Key: "b", Count: 7}, {Key: "a", Count: 1}}, w.Top(start.Add(60*time.Second), 5))
+	// then the second one
+	assert.Equal(t, []Item{{Key: "b", Count: 4}}, w.Top(start.Add(70*time.Second), 5))
+	assert.Equal(t, Item{Key: "a"}, w.Estimate(start.Add(70*time.Second), "a"))
+
+	// a bucket reused later starts from scratch
+	w.Add(start.Add(60*time.Second), "c", 2)
+	assert.Equal(t, []Item{{Key: "b", Count: 4}, {Key: "c", Count: 2}}, w.Top(start.Add(70*time.Second), 5))
+
+	// the future buckets are ignored
+	assert.Empty(t, w.Top(start.Add(-time.Minute), 5))
+}
+
+func TestWindowSumsErrors(t *testing.T) {
+	w := NewWindow(1, 2*time.Second, 2)
+	start := time.Unix(1_700_000_000, 0)
+	w.Add(start, "a", 1)
+	w.Add(start, "b", 1)
+	w.Add(start.Add(time.Second), "a", 1)
+	w.Add(start.Add(time.Second), "b", 2)
+	assert.Equal(t, []Item{{Key: "b", Count: 5, Error: 2}}, w.Top(start.Add(time.Second), -1))
+	assert.Equal(t, Item{Key: "a"}, w.Estimate(start.Add(time.Second), "a"))
+}
+
+func TestWindowMinimumBuckets(t *testing.T) {
+	w := NewWindow(1, 0, 0)
+	assert.Equal(t, time.Duration(1), w.Duration())
+	w.Add(time.Unix(0, 5), "a", 1)
+	assert.Equal(t, []Item{{Key: "a", Count: 1}}, w.Top(time.Unix(0, 5), 1))
+}
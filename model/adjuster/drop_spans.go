@@ -0,0 +1,119 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// DropSpans returns an Adjuster that removes the spans matching the predicate from the trace,
+// re-parenting their children to their nearest ancestor kept in the trace, so that the tree
+// of the remaining spans stays connected. The root spans, i.e. the spans without a parent in
+// the trace, are never dropped, nor are the spans sharing their ID with another span or the spans
+// whose parents form a cycle.
+//
+// The references of the children of the dropped spans keep their type and only change their
+// span ID; the references that would become duplicates are removed. It never returns any errors.
+func DropSpans(drop func(span *model.Span) bool) Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		spans := make(map[model.SpanID]*model.Span, len(trace.Spans))
+		shared := make(map[model.SpanID]bool)
+		for _, span := range trace.Spans {
+			if _, ok := spans[span.SpanID]; ok {
+				shared[span.SpanID] = true
+			}
+			spans[span.SpanID] = span
+		}
+		dropped := make(map[model.SpanID]*model.Span)
+		for _, span := range trace.Spans {
+			if shared[span.SpanID] || !drop(span) {
+				continue
+			}
+			if _, ok := spans[span.ParentSpanID()]; ok {
+				dropped[span.SpanID] = span
+			}
+		}
+		keepCycles(dropped)
+		if len(dropped) == 0 {
+			return trace, nil
+		}
+
+		kept := trace.Spans[:0]
+		for _, span := range trace.Spans {
+			if _, ok := dropped[span.SpanID]; ok {
+				continue
+			}
+			reparent(span, dropped)
+			kept = append(kept, span)
+		}
+		clear(trace.Spans[len(kept):])
+		trace.Spans = kept
+		return trace, nil
+	})
+}
+
+// reparent points the references of the span to dropped spans to their nearest kept ancestors.
+func reparent(span *model.Span, dropped map[model.SpanID]*model.Span) {
+	refs := span.References[:0]
+	for _, ref := range span.References {
+		if ref.TraceID == span.TraceID {
+			if parent, ok := dropped[ref.SpanID]; ok {
+				ref.SpanID = keptAncestor(parent, dropped)
+			}
+		}
+		if !hasReference(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	span.References = refs
+}
+
+// keepCycles keeps the spans of the cycles of dropped parents, so that every dropped span has a kept ancestor.
+func keepCycles(dropped map[model.SpanID]*model.Span) {
+	done := make(map[model.SpanID]bool, len(dropped))
+	for id := range dropped {
+		visited := make(map[model.SpanID]bool)
+		for span, ok := dropped[id]; ok && !done[span.SpanID]; span, ok = dropped[span.ParentSpanID()] {
+			if visited[span.SpanID] {
+				for cycle := span; ok; cycle = span {
+					span, ok = dropped[cycle.ParentSpanID()]
+					delete(dropped, cycle.SpanID)
+				}
+				break
+			}
+			visited[span.SpanID] = true
+		}
+		for visitedID := range visited {
+			done[visitedID] = true
+		}
+	}
+}
+
+// keptAncestor returns the ID of the nearest ancestor of the dropped span which is not dropped.
+func keptAncestor(span *model.Span, dropped map[model.SpanID]*model.Span) model.SpanID {
+	for {
+		parent, ok := dropped[span.ParentSpanID()]
+		if !ok {
+			return span.ParentSpanID()
+		}
+		span = parent
+	}
+}
+
+func hasReference(refs []model.SpanRef, ref model.SpanRef) bool {
+	for _, r := range refs {
+		if r.TraceID == ref.TraceID && r.SpanID == ref.SpanID {
+			return true
+		}
+	}
+	return false
+}
+
+// TrimZeroDurationSpans returns an Adjuster that removes the zero-duration spans without logs,
+// e.g. the placeholder spans of some instrumentations, re-parenting their children as DropSpans.
+func TrimZeroDurationSpans() Adjuster {
+	return DropSpans(func(span *model.Span) bool {
+		return span.Duration == 0 && len(span.Logs) == 0
+	})
+}
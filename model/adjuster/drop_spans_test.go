@@ -0,0 +1,115 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+func TestDropSpans(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spans   []testSpan
+		drop    []uint64
+		parents map[string]string
+	}{
+		{
+			name: "children re-parented to the nearest kept ancestor",
+			spans: []testSpan{
+				{id: 1},
+				{id: 2, childOf: []uint64{1}},
+				{id: 3, childOf: []uint64{2}},
+				{id: 4, childOf: []uint64{3}},
+				{id: 5, followsFrom: []uint64{3}},
+				{id: 6, childOf: []uint64{1}},
+			},
+			drop:    []uint64{2, 3},
+			parents: map[string]string{"1": "", "4": "1", "5": "1", "6": "1"},
+		},
+		{
+			name: "root spans kept",
+			spans: []testSpan{
+				{id: 1},
+				{id: 2, childOf: []uint64{1}},
+				{id: 3, childOf: []uint64{9}},
+			},
+			drop:    []uint64{1, 3},
+			parents: map[string]string{"1": "", "2": "1", "3": "missing:9"},
+		},
+		{
+			name: "references becoming duplicates removed",
+			spans: []testSpan{
+				{id: 1},
+				{id: 2, childOf: []uint64{1}},
+				{id: 3, childOf: []uint64{2}, followsFrom: []uint64{1}},
+			},
+			drop:    []uint64{2},
+			parents: map[string]string{"1": "", "3": "1"},
+		},
+		{
+			name: "cycles kept",
+			spans: []testSpan{
+				{id: 1},
+				{id: 2, childOf: []uint64{3}},
+				{id: 3, childOf: []uint64{2}},
+				{id: 4, childOf: []uint64{3}},
+			},
+			drop:    []uint64{2, 3, 4},
+			parents: map[string]string{"1": "", "2": "3", "3": "2"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			drop := make(map[model.SpanID]bool)
+			for _, id := range tc.drop {
+				drop[model.NewSpanID(id)] = true
+			}
+			trace, err := DropSpans(func(span *model.Span) bool {
+				return drop[span.SpanID]
+			}).Adjust(orphanTestTrace(tc.spans))
+			require.NoError(t, err)
+			assert.Equal(t, tc.parents, parents(trace))
+		})
+	}
+}
+
+func TestDropSpansSharedSpanIDs(t *testing.T) {
+	trace := orphanTestTrace([]testSpan{
+		{id: 1},
+		{id: 2, childOf: []uint64{1}},
+		{id: 2, childOf: []uint64{1}},
+		{id: 3, childOf: []uint64{2}},
+	})
+	trace, err := DropSpans(func(*model.Span) bool { return true }).Adjust(trace)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 3)
+	for _, span := range trace.Spans {
+		assert.NotEqual(t, model.NewSpanID(3), span.SpanID)
+	}
+}
+
+func TestTrimZeroDurationSpans(t *testing.T) {
+	trace := orphanTestTrace([]testSpan{
+		{id: 1},
+		{id: 2, childOf: []uint64{1}},
+		{id: 3, childOf: []uint64{2}},
+		{id: 4, childOf: []uint64{3}},
+		{id: 5, childOf: []uint64{1}},
+	})
+	trace.Spans[0].Duration = 0
+	trace.Spans[1].Duration = 0
+	trace.Spans[2].Duration = 0
+	trace.Spans[2].Logs = []model.Log{{Timestamp: baseTime}}
+	trace.Spans[4].Duration = 0
+
+	trace, err := TrimZeroDurationSpans().Adjust(trace)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"1": "", "3": "1", "4": "3"}, parents(trace))
+}
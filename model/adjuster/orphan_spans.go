@@ -0,0 +1,287 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// OrphanSpansMode selects how the OrphanSpans adjuster reattaches the spans whose parent is missing.
+type OrphanSpansMode string
+
+const (
+	// OrphanSpansRoot re-parents the orphan spans to the root span of the trace.
+	OrphanSpansRoot OrphanSpansMode = "root"
+	// OrphanSpansPlaceholder attaches the orphan spans under a synthetic placeholder span per service.
+	OrphanSpansPlaceholder OrphanSpansMode = "placeholder"
+
+	// OrphanTagKey is the tag added to the reattached spans, with the IDs of their original parents.
+	OrphanTagKey = "jaeger.orphan.parent_span_ids"
+	// PlaceholderTagKey is the tag marking the synthetic placeholder spans.
+	PlaceholderTagKey = "jaeger.placeholder"
+	// PlaceholderOperationName is the operation name of the synthetic placeholder spans.
+	PlaceholderOperationName = "missing parent span"
+
+	warningRemovedMissingRef = "reference to span %s missing from the trace removed"
+	warningMissingParent     = "parent span %s missing from the trace; reattached to %s"
+	warningReferenceCycle    = "parent span %s forms a reference cycle; reattached to %s"
+	warningPlaceholder       = "synthetic span standing in for the missing parents of the spans of the service"
+)
+
+// OrphanSpans returns an Adjuster that gives the trace a coherent tree when parents are missing,
+// e.g. because they were sampled out or lost. A span is an orphan when none of its references
+// within the trace leads to a span of the trace, or when its parent chain forms a cycle.
+// The orphan spans are reattached according to the mode, their original parents recorded in
+// the OrphanTagKey tag and in a warning.
+//
+// The references to missing spans of the spans which also reference a span of the trace are
+// removed, so that the parent of those spans is one of the trace.
+//
+// The adjuster assumes that all spans have unique IDs, so the trace may need to go through
+// SpanIDDeduper first. It never returns any errors.
+func OrphanSpans(mode OrphanSpansMode) Adjuster {
+	return Func(func(trace *model.Trace) (*model.Trace, error) {
+		adjuster := &orphanSpansAdjuster{
+			trace: trace,
+			mode:  mode,
+			spans: make(map[model.SpanID]*model.Span, len(trace.Spans)),
+		}
+		adjuster.adjust()
+		return trace, nil
+	})
+}
+
+// ValidOrphanSpansMode returns whether the mode is known to the OrphanSpans adjuster.
+func ValidOrphanSpansMode(mode OrphanSpansMode) bool {
+	return mode == OrphanSpansRoot || mode == OrphanSpansPlaceholder
+}
+
+type orphan struct {
+	span    *model.Span
+	parents []model.SpanID
+	warning string
+}
+
+type orphanSpansAdjuster struct {
+	trace   *model.Trace
+	mode    OrphanSpansMode
+	spans   map[model.SpanID]*model.Span
+	root    *model.Span
+	orphans []orphan
+}
+
+func (a *orphanSpansAdjuster) adjust() {
+	for _, span := range a.trace.Spans {
+		if _, ok := a.spans[span.SpanID]; !ok {
+			a.spans[span.SpanID] = span
+		}
+	}
+	isOrphan := make(map[model.SpanID]bool)
+	for _, span := range a.trace.Spans {
+		refs := localReferences(span)
+		if len(refs) == 0 {
+			if a.root == nil || span.StartTime.Before(a.root.StartTime) {
+				a.root = span
+			}
+			continue
+		}
+		if parents := a.removeMissingReferences(span); len(parents) > 0 {
+			a.orphans = append(a.orphans, orphan{span: span, parents: parents, warning: warningMissingParent})
+			isOrphan[span.SpanID] = true
+		}
+	}
+	a.breakCycles(isOrphan)
+	if len(a.orphans) == 0 {
+		return
+	}
+	switch a.mode {
+	case OrphanSpansRoot:
+		a.reattachToRoot()
+	case OrphanSpansPlaceholder:
+		a.reattachToPlaceholders()
+	}
+}
+
+// removeMissingReferences removes the references to missing spans when another reference of the span
+// leads to a span of the trace, and returns the parents of the span when none does.
+func (a *orphanSpansAdjuster) removeMissingReferences(span *model.Span) []model.SpanID {
+	var missing []model.SpanID
+	found := false
+	for _, ref := range localReferences(span) {
+		if _, ok := a.spans[ref.SpanID]; ok && ref.SpanID != span.SpanID {
+			found = true
+		} else {
+			missing = append(missing, ref.SpanID)
+		}
+	}
+	if !found {
+		return missing
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	references := make([]model.SpanRef, 0, len(span.References)-len(missing))
+	for _, ref := range span.References {
+		_, ok := a.spans[ref.SpanID]
+		if ref.TraceID == span.TraceID && (!ok || ref.SpanID == span.SpanID) {
+			span.Warnings = append(span.Warnings, fmt.Sprintf(warningRemovedMissingRef, ref.SpanID))
+			continue
+		}
+		references = append(references, ref)
+	}
+	span.References = references
+	return nil
+}
+
+// breakCycles follows the parents of the spans and makes an orphan of the first span of each cycle found,
+// in the order of the spans of the trace.
+func (a *orphanSpansAdjuster) breakCycles(isOrphan map[model.SpanID]bool) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[model.SpanID]int, len(a.trace.Spans))
+	for _, span := range a.trace.Spans {
+		var path []model.SpanID
+		current := span
+		for current != nil && state[current.SpanID] == 0 {
+			state[current.SpanID] = visiting
+			path = append(path, current.SpanID)
+			if isOrphan[current.SpanID] || len(localReferences(current)) == 0 {
+				current = nil
+				break
+			}
+			current = a.spans[current.ParentSpanID()]
+		}
+		if current != nil && state[current.SpanID] == visiting {
+			parent := current.ParentSpanID()
+			a.orphans = append(a.orphans, orphan{span: current, parents: []model.SpanID{parent}, warning: warningReferenceCycle})
+			isOrphan[current.SpanID] = true
+		}
+		for _, id := range path {
+			state[id] = visited
+		}
+	}
+}
+
+func (a *orphanSpansAdjuster) reattachToRoot() {
+	root := a.root
+	orphans := a.orphans
+	if root == nil {
+		// every span has a parent, the earliest orphan becomes the root
+		sort.SliceStable(orphans, func(i, j int) bool {
+			return orphans[i].span.StartTime.Before(orphans[j].span.StartTime)
+		})
+		root = orphans[0].span
+		reattach(orphans[0], nil, "a root span")
+		orphans = orphans[1:]
+	}
+	for _, o := range orphans {
+		reattach(o, root, "the root span")
+	}
+}
+
+func (a *orphanSpansAdjuster) reattachToPlaceholders() {
+	placeholders := make(map[string]*model.Span)
+	for _, o := range a.orphans {
+		service := serviceOf(o.span)
+		placeholder, ok := placeholders[service]
+		if !ok {
+			placeholder = a.newPlaceholder(o.span)
+			placeholders[service] = placeholder
+			a.trace.Spans = append(a.trace.Spans, placeholder)
+		}
+		extendPlaceholder(placeholder, o.span)
+		reattach(o, placeholder, "a placeholder span")
+	}
+}
+
+func (a *orphanSpansAdjuster) newPlaceholder(span *model.Span) *model.Span {
+	placeholder := &model.Span{
+		TraceID:       span.TraceID,
+		SpanID:        a.newSpanID(serviceOf(span)),
+		OperationName: PlaceholderOperationName,
+		StartTime:     span.StartTime,
+		Duration:      span.Duration,
+		Tags:          []model.KeyValue{model.Bool(PlaceholderTagKey, true)},
+		Process:       span.Process,
+		ProcessID:     span.ProcessID,
+		Warnings:      []string{warningPlaceholder},
+	}
+	if a.root != nil {
+		placeholder.References = []model.SpanRef{model.NewChildOfRef(a.root.TraceID, a.root.SpanID)}
+	}
+	a.spans[placeholder.SpanID] = placeholder
+	return placeholder
+}
+
+// newSpanID derives a span ID from the service name, which is not used by the spans of the trace.
+func (a *orphanSpansAdjuster) newSpanID(service string) model.SpanID {
+	h := fnv.New64a()
+	h.Write([]byte(service))
+	id := model.SpanID(h.Sum64())
+	for {
+		if _, ok := a.spans[id]; !ok && id != 0 {
+			return id
+		}
+		id++
+	}
+}
+
+// extendPlaceholder stretches the placeholder span over the span.
+func extendPlaceholder(placeholder *model.Span, span *model.Span) {
+	end := placeholder.StartTime.Add(placeholder.Duration)
+	if spanEnd := span.StartTime.Add(span.Duration); spanEnd.After(end) {
+		end = spanEnd
+	}
+	if span.StartTime.Before(placeholder.StartTime) {
+		placeholder.StartTime = span.StartTime
+	}
+	placeholder.Duration = end.Sub(placeholder.StartTime)
+}
+
+// reattach replaces the references within the trace of the orphan span with a reference to the parent,
+// or removes them when the parent is nil.
+func reattach(o orphan, parent *model.Span, parentName string) {
+	span := o.span
+	references := make([]model.SpanRef, 0, len(span.References))
+	if parent != nil {
+		references = append(references, model.NewChildOfRef(parent.TraceID, parent.SpanID))
+	}
+	for _, ref := range span.References {
+		if ref.TraceID != span.TraceID {
+			references = append(references, ref)
+		}
+	}
+	span.References = references
+	parents := make([]string, len(o.parents))
+	for i, id := range o.parents {
+		parents[i] = id.String()
+	}
+	span.Tags = append(span.Tags, model.String(OrphanTagKey, strings.Join(parents, ",")))
+	span.Warnings = append(span.Warnings, fmt.Sprintf(o.warning, strings.Join(parents, ","), parentName))
+}
+
+// localReferences returns the references of the span within its trace.
+func localReferences(span *model.Span) []model.SpanRef {
+	var refs []model.SpanRef
+	for _, ref := range span.References {
+		if ref.TraceID == span.TraceID {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func serviceOf(span *model.Span) string {
+	if span.Process != nil {
+		return span.Process.ServiceName
+	}
+	return span.ProcessID
+}
@@ -0,0 +1,330 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package adjuster
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// testSpan describes a span of a crafted topology, referencing its parents by span ID.
+type testSpan struct {
+	id          uint64
+	service     string
+	start       time.Duration
+	childOf     []uint64
+	followsFrom []uint64
+}
+
+var (
+	orphanTraceID = model.NewTraceID(0, 42)
+	otherTraceID  = model.NewTraceID(0, 43)
+	baseTime      = time.Unix(1700000000, 0).UTC()
+)
+
+func orphanTestTrace(spans []testSpan) *model.Trace {
+	trace := &model.Trace{}
+	for _, s := range spans {
+		span := &model.Span{
+			TraceID:   orphanTraceID,
+			SpanID:    model.NewSpanID(s.id),
+			StartTime: baseTime.Add(s.start),
+			Duration:  time.Millisecond,
+			Process:   model.NewProcess(s.service, nil),
+		}
+		for _, id := range s.childOf {
+			span.References = append(span.References, model.NewChildOfRef(orphanTraceID, model.NewSpanID(id)))
+		}
+		for _, id := range s.followsFrom {
+			span.References = append(span.References, model.NewFollowsFromRef(orphanTraceID, model.NewSpanID(id)))
+		}
+		trace.Spans = append(trace.Spans, span)
+	}
+	return trace
+}
+
+// name returns the span ID in decimal, as in the crafted topologies.
+func name(id model.SpanID) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// parents maps the spans of the trace to their parent, the placeholder spans being named after their service.
+func parents(trace *model.Trace) map[string]string {
+	names := make(map[model.SpanID]string)
+	for _, span := range trace.Spans {
+		names[span.SpanID] = name(span.SpanID)
+		if span.OperationName == PlaceholderOperationName {
+			names[span.SpanID] = "placeholder:" + span.Process.ServiceName
+		}
+	}
+	result := make(map[string]string)
+	for _, span := range trace.Spans {
+		parent := ""
+		if len(localReferences(span)) > 0 {
+			parent = names[span.ParentSpanID()]
+			if parent == "" {
+				parent = "missing:" + name(span.ParentSpanID())
+			}
+		}
+		result[names[span.SpanID]] = parent
+	}
+	return result
+}
+
+func orphanTags(trace *model.Trace) map[string]string {
+	result := make(map[string]string)
+	for _, span := range trace.Spans {
+		if tag, ok := model.KeyValues(span.Tags).FindByKey(OrphanTagKey); ok {
+			var ids []string
+			for _, s := range strings.Split(tag.AsString(), ",") {
+				id, err := model.SpanIDFromString(s)
+				if err != nil {
+					ids = append(ids, s)
+					continue
+				}
+				ids = append(ids, name(id))
+			}
+			result[name(span.SpanID)] = strings.Join(ids, ",")
+		}
+	}
+	return result
+}
+
+func TestOrphanSpans(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mode    OrphanSpansMode
+		spans   []testSpan
+		parents map[string]string
+		orphans map[string]string
+	}{
+		{
+			name: "complete tree unchanged",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "a", childOf: []uint64{1}},
+				{id: 3, service: "b", followsFrom: []uint64{2}},
+			},
+			parents: map[string]string{"1": "", "2": "1", "3": "2"},
+			orphans: map[string]string{},
+		},
+		{
+			name: "missing parent reattached to root",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "b", childOf: []uint64{9}},
+				{id: 3, service: "b", childOf: []uint64{2}},
+			},
+			parents: map[string]string{"1": "", "2": "1", "3": "2"},
+			orphans: map[string]string{"2": "9"},
+		},
+		{
+			name: "missing follows-from parent reattached to root",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "b", followsFrom: []uint64{9}},
+			},
+			parents: map[string]string{"1": "", "2": "1"},
+			orphans: map[string]string{"2": "9"},
+		},
+		{
+			name: "earliest span without references is the root",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a", start: time.Second},
+				{id: 2, service: "a"},
+				{id: 3, service: "b", childOf: []uint64{9}},
+			},
+			parents: map[string]string{"1": "", "2": "", "3": "2"},
+			orphans: map[string]string{"3": "9"},
+		},
+		{
+			name: "missing child-of removed when a follows-from reference is found",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "a", childOf: []uint64{1}},
+				{id: 3, service: "b", childOf: []uint64{9}, followsFrom: []uint64{2}},
+			},
+			parents: map[string]string{"1": "", "2": "1", "3": "2"},
+			orphans: map[string]string{},
+		},
+		{
+			name: "all references missing",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "b", childOf: []uint64{8}, followsFrom: []uint64{9}},
+			},
+			parents: map[string]string{"1": "", "2": "1"},
+			orphans: map[string]string{"2": "8,9"},
+		},
+		{
+			name: "self reference",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "a", childOf: []uint64{2}},
+			},
+			parents: map[string]string{"1": "", "2": "1"},
+			orphans: map[string]string{"2": "2"},
+		},
+		{
+			name: "cycle broken at the first span reached",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "a", childOf: []uint64{3}},
+				{id: 3, service: "a", childOf: []uint64{4}},
+				{id: 4, service: "a", childOf: []uint64{2}},
+				{id: 5, service: "a", childOf: []uint64{4}},
+			},
+			parents: map[string]string{"1": "", "2": "1", "3": "4", "4": "2", "5": "4"},
+			orphans: map[string]string{"2": "3"},
+		},
+		{
+			name: "without root the earliest orphan becomes the root",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 2, service: "a", start: time.Second, childOf: []uint64{8}},
+				{id: 3, service: "b", childOf: []uint64{9}},
+				{id: 4, service: "b", childOf: []uint64{3}},
+			},
+			parents: map[string]string{"2": "3", "3": "", "4": "3"},
+			orphans: map[string]string{"2": "8", "3": "9"},
+		},
+		{
+			name: "cycle without root",
+			mode: OrphanSpansRoot,
+			spans: []testSpan{
+				{id: 1, service: "a", childOf: []uint64{2}},
+				{id: 2, service: "a", childOf: []uint64{1}},
+			},
+			parents: map[string]string{"1": "", "2": "1"},
+			orphans: map[string]string{"1": "2"},
+		},
+		{
+			name: "placeholder per service",
+			mode: OrphanSpansPlaceholder,
+			spans: []testSpan{
+				{id: 1, service: "a"},
+				{id: 2, service: "b", childOf: []uint64{8}},
+				{id: 3, service: "b", childOf: []uint64{9}},
+				{id: 4, service: "c", followsFrom: []uint64{9}},
+				{id: 5, service: "c", childOf: []uint64{4}},
+			},
+			parents: map[string]string{
+				"1": "", "2": "placeholder:b", "3": "placeholder:b", "4": "placeholder:c", "5": "4",
+				"placeholder:b": "1", "placeholder:c": "1",
+			},
+			orphans: map[string]string{"2": "8", "3": "9", "4": "9"},
+		},
+		{
+			name: "placeholders without root",
+			mode: OrphanSpansPlaceholder,
+			spans: []testSpan{
+				{id: 1, service: "a", childOf: []uint64{2}},
+				{id: 2, service: "a", childOf: []uint64{1}},
+				{id: 3, service: "b", childOf: []uint64{9}},
+			},
+			parents: map[string]string{
+				"1": "placeholder:a", "2": "1", "3": "placeholder:b",
+				"placeholder:a": "", "placeholder:b": "",
+			},
+			orphans: map[string]string{"1": "2", "3": "9"},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			trace, err := OrphanSpans(tt.mode).Adjust(orphanTestTrace(tt.spans))
+			require.NoError(t, err)
+			assert.Equal(t, tt.parents, parents(trace))
+			assert.Equal(t, tt.orphans, orphanTags(trace))
+		})
+	}
+}
+
+func TestOrphanSpansWarnings(t *testing.T) {
+	trace := orphanTestTrace([]testSpan{
+		{id: 1, service: "a"},
+		{id: 2, service: "b", childOf: []uint64{9}},
+		{id: 3, service: "b", childOf: []uint64{8}, followsFrom: []uint64{2}},
+	})
+	trace, err := OrphanSpans(OrphanSpansRoot).Adjust(trace)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"parent span 0000000000000009 missing from the trace; reattached to the root span"}, trace.Spans[1].Warnings)
+	assert.Equal(t, []string{"reference to span 0000000000000008 missing from the trace removed"}, trace.Spans[2].Warnings)
+	assert.Equal(t, []model.SpanRef{model.NewFollowsFromRef(orphanTraceID, model.NewSpanID(2))}, trace.Spans[2].References)
+}
+
+func TestOrphanSpansKeepsReferencesToOtherTraces(t *testing.T) {
+	trace := orphanTestTrace([]testSpan{
+		{id: 1, service: "a"},
+		{id: 2, service: "b", childOf: []uint64{9}},
+	})
+	link := model.NewFollowsFromRef(otherTraceID, model.NewSpanID(7))
+	trace.Spans[1].References = append(trace.Spans[1].References, link)
+	// a span only linked to another trace is a root
+	trace.Spans[0].References = []model.SpanRef{link}
+
+	trace, err := OrphanSpans(OrphanSpansRoot).Adjust(trace)
+	require.NoError(t, err)
+	assert.Equal(t, []model.SpanRef{model.NewChildOfRef(orphanTraceID, model.NewSpanID(1)), link}, trace.Spans[1].References)
+	assert.Equal(t, []model.SpanRef{link}, trace.Spans[0].References)
+}
+
+func TestOrphanSpansPlaceholder(t *testing.T) {
+	trace := orphanTestTrace([]testSpan{
+		{id: 1, service: "a"},
+		{id: 2, service: "b", start: 10 * time.Millisecond, childOf: []uint64{9}},
+		{id: 3, service: "b", start: 5 * time.Millisecond, childOf: []uint64{9}},
+	})
+	trace, err := OrphanSpans(OrphanSpansPlaceholder).Adjust(trace)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 4)
+
+	placeholder := trace.Spans[3]
+	assert.Equal(t, PlaceholderOperationName, placeholder.OperationName)
+	assert.Equal(t, "b", placeholder.Process.ServiceName)
+	assert.Equal(t, baseTime.Add(5*time.Millisecond), placeholder.StartTime)
+	assert.Equal(t, 6*time.Millisecond, placeholder.Duration)
+	tag, ok := model.KeyValues(placeholder.Tags).FindByKey(PlaceholderTagKey)
+	require.True(t, ok)
+	assert.True(t, tag.Bool())
+	for _, span := range trace.Spans[:3] {
+		assert.NotEqual(t, span.SpanID, placeholder.SpanID)
+	}
+	assert.Equal(t, []string{"parent span 0000000000000009 missing from the trace; reattached to a placeholder span"}, trace.Spans[1].Warnings)
+}
+
+func TestOrphanSpansPlaceholderIDCollision(t *testing.T) {
+	trace := orphanTestTrace([]testSpan{
+		{id: 1, service: "a"},
+		{id: 2, service: "b", childOf: []uint64{9}},
+	})
+	a := &orphanSpansAdjuster{spans: map[model.SpanID]*model.Span{}}
+	// the span ID the placeholder of the service would use is taken
+	trace.Spans[0].SpanID = a.newSpanID("b")
+
+	trace, err := OrphanSpans(OrphanSpansPlaceholder).Adjust(trace)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 3)
+	assert.Equal(t, trace.Spans[0].SpanID+1, trace.Spans[2].SpanID)
+}
+
+func TestValidOrphanSpansMode(t *testing.T) {
+	assert.True(t, ValidOrphanSpansMode(OrphanSpansRoot))
+	assert.True(t, ValidOrphanSpansMode(OrphanSpansPlaceholder))
+	assert.False(t, ValidOrphanSpansMode("orphanage"))
+}
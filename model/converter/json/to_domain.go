@@ -0,0 +1,199 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/json"
+)
+
+// ToDomain converts json.Trace, e.g. as exported from the Jaeger UI, into model.Trace.
+// Unlike FromDomain, it validates the input, since it usually comes from files.
+func ToDomain(trace *json.Trace) (*model.Trace, error) {
+	processes := make(map[json.ProcessID]*model.Process, len(trace.Processes))
+	for processID, process := range trace.Processes {
+		p, err := convertProcess(process)
+		if err != nil {
+			return nil, fmt.Errorf("invalid process %s: %w", processID, err)
+		}
+		processes[processID] = p
+	}
+	spans := make([]*model.Span, len(trace.Spans))
+	for i := range trace.Spans {
+		span, err := convertSpanToDomain(&trace.Spans[i], processes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid span %s: %w", trace.Spans[i].SpanID, err)
+		}
+		spans[i] = span
+	}
+	return &model.Trace{Spans: spans, Warnings: trace.Warnings}, nil
+}
+
+func convertSpanToDomain(span *json.Span, processes map[json.ProcessID]*model.Process) (*model.Span, error) {
+	traceID, err := model.TraceIDFromString(string(span.TraceID))
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := model.SpanIDFromString(string(span.SpanID))
+	if err != nil {
+		return nil, err
+	}
+	references, err := convertReferencesToDomain(span)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := convertKeyValuesToDomain(span.Tags)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]model.Log, len(span.Logs))
+	for i, log := range span.Logs {
+		fields, err := convertKeyValuesToDomain(log.Fields)
+		if err != nil {
+			return nil, err
+		}
+		logs[i] = model.Log{Timestamp: model.EpochMicrosecondsAsTime(log.Timestamp), Fields: fields}
+	}
+	process := processes[span.ProcessID]
+	if span.Process != nil {
+		if process, err = convertProcess(*span.Process); err != nil {
+			return nil, err
+		}
+	}
+	if process == nil {
+		return nil, fmt.Errorf("unknown process %q", span.ProcessID)
+	}
+	return &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: span.OperationName,
+		References:    references,
+		Flags:         model.Flags(span.Flags),
+		StartTime:     model.EpochMicrosecondsAsTime(span.StartTime),
+		Duration:      model.MicrosecondsAsDuration(span.Duration),
+		Tags:          tags,
+		Logs:          logs,
+		Process:       process,
+		Warnings:      span.Warnings,
+	}, nil
+}
+
+func convertReferencesToDomain(span *json.Span) ([]model.SpanRef, error) {
+	references := make([]model.SpanRef, 0, len(span.References)+1)
+	for _, ref := range span.References {
+		traceID, err := model.TraceIDFromString(string(ref.TraceID))
+		if err != nil {
+			return nil, err
+		}
+		spanID, err := model.SpanIDFromString(string(ref.SpanID))
+		if err != nil {
+			return nil, err
+		}
+		refType := model.ChildOf
+		switch ref.RefType {
+		case json.ChildOf:
+		case json.FollowsFrom:
+			refType = model.FollowsFrom
+		default:
+			return nil, fmt.Errorf("unknown reference type %q", ref.RefType)
+		}
+		references = append(references, model.SpanRef{TraceID: traceID, SpanID: spanID, RefType: refType})
+	}
+	// the deprecated parent span ID is only honored without references
+	if len(references) == 0 && span.ParentSpanID != "" {
+		traceID, err := model.TraceIDFromString(string(span.TraceID))
+		if err != nil {
+			return nil, err
+		}
+		parentSpanID, err := model.SpanIDFromString(string(span.ParentSpanID))
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, model.NewChildOfRef(traceID, parentSpanID))
+	}
+	return references, nil
+}
+
+func convertProcess(process json.Process) (*model.Process, error) {
+	tags, err := convertKeyValuesToDomain(process.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewProcess(process.ServiceName, tags), nil
+}
+
+func convertKeyValuesToDomain(keyValues []json.KeyValue) (model.KeyValues, error) {
+	out := make(model.KeyValues, len(keyValues))
+	for i, kv := range keyValues {
+		keyValue, err := convertKeyValueToDomain(kv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", kv.Key, err)
+		}
+		out[i] = keyValue
+	}
+	return out, nil
+}
+
+// convertKeyValueToDomain converts a value decoded from JSON, where the numbers are float64,
+// or strings when out of the safe range of JavaScript, and the binary values are base64 strings.
+func convertKeyValueToDomain(kv json.KeyValue) (model.KeyValue, error) {
+	switch kv.Type {
+	case json.StringType, "":
+		if s, ok := kv.Value.(string); ok {
+			return model.String(kv.Key, s), nil
+		}
+	case json.BoolType:
+		switch v := kv.Value.(type) {
+		case bool:
+			return model.Bool(kv.Key, v), nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return model.KeyValue{}, err
+			}
+			return model.Bool(kv.Key, b), nil
+		}
+	case json.Int64Type:
+		switch v := kv.Value.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return model.KeyValue{}, fmt.Errorf("%v is not an integer", v)
+			}
+			return model.Int64(kv.Key, int64(v)), nil
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return model.KeyValue{}, err
+			}
+			return model.Int64(kv.Key, n), nil
+		}
+	case json.Float64Type:
+		switch v := kv.Value.(type) {
+		case float64:
+			return model.Float64(kv.Key, v), nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return model.KeyValue{}, err
+			}
+			return model.Float64(kv.Key, f), nil
+		}
+	case json.BinaryType:
+		if s, ok := kv.Value.(string); ok {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return model.KeyValue{}, err
+			}
+			return model.Binary(kv.Key, b), nil
+		}
+	default:
+		return model.KeyValue{}, fmt.Errorf("unknown type %q", kv.Type)
+	}
+	return model.KeyValue{}, fmt.Errorf("unexpected %T value of type %s", kv.Value, kv.Type)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	jModel "github.com/jaegertracing/jaeger/model/json"
+)
+
+func TestToDomain(t *testing.T) {
+	for i := 1; i <= NumberOfFixtures; i++ {
+		domainStr, jsonStr := loadFixturesUI(t, i)
+
+		var uiTrace jModel.Trace
+		require.NoError(t, json.Unmarshal(jsonStr, &uiTrace))
+		trace, err := ToDomain(&uiTrace)
+		require.NoError(t, err)
+
+		var expected model.Trace
+		require.NoError(t, jsonpb.Unmarshal(bytes.NewReader(domainStr), &expected))
+		// the UI model has microseconds precision and no empty tags or logs
+		expected.NormalizeTimestamps()
+		for _, span := range expected.Spans {
+			span.StartTime = span.StartTime.Truncate(1000)
+			span.Duration = span.Duration.Truncate(1000)
+			for j := range span.Logs {
+				span.Logs[j].Timestamp = span.Logs[j].Timestamp.Truncate(1000)
+			}
+		}
+		trace.NormalizeTimestamps()
+		// the round trip preserves the UI model
+		assert.Equal(t, FromDomain(&expected), FromDomain(trace))
+	}
+}
+
+func TestToDomainParentSpanID(t *testing.T) {
+	trace, err := ToDomain(&jModel.Trace{
+		Spans: []jModel.Span{{
+			TraceID:      "1f00",
+			SpanID:       "2",
+			ParentSpanID: "1",
+			Process:      &jModel.Process{ServiceName: "frontend"},
+			Tags: []jModel.KeyValue{
+				{Key: "big", Type: jModel.Int64Type, Value: "9223372036854775807"},
+				{Key: "binary", Type: jModel.BinaryType, Value: "AQI="},
+				{Key: "untyped", Value: "x"},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 1)
+	span := trace.Spans[0]
+	assert.Equal(t, []model.SpanRef{model.NewChildOfRef(model.NewTraceID(0, 0x1f00), model.NewSpanID(1))}, span.References)
+	assert.Equal(t, "frontend", span.Process.ServiceName)
+	assert.Equal(t, []model.KeyValue{
+		model.Int64("big", 9223372036854775807),
+		model.Binary("binary", []byte{1, 2}),
+		model.String("untyped", "x"),
+	}, span.Tags)
+}
+
+func TestToDomainErrors(t *testing.T) {
+	validSpan := jModel.Span{TraceID: "1", SpanID: "2", ProcessID: "p1"}
+	processes := map[jModel.ProcessID]jModel.Process{"p1": {ServiceName: "frontend"}}
+	tests := []struct {
+		name   string
+		span   func(span *jModel.Span)
+		errMsg string
+	}{
+		{name: "bad trace ID", span: func(span *jModel.Span) { span.TraceID = "x" }, errMsg: "invalid span 2"},
+		{name: "bad span ID", span: func(span *jModel.Span) { span.SpanID = "x" }, errMsg: "invalid span x"},
+		{name: "unknown process", span: func(span *jModel.Span) { span.ProcessID = "p2" }, errMsg: `unknown process "p2"`},
+		{
+			name: "bad reference type",
+			span: func(span *jModel.Span) {
+				span.References = []jModel.Reference{{RefType: "PARENT", TraceID: "1", SpanID: "1"}}
+			},
+			errMsg: `unknown reference type "PARENT"`,
+		},
+		{
+			name:   "bad tag type",
+			span:   func(span *jModel.Span) { span.Tags = []jModel.KeyValue{{Key: "k", Type: "date", Value: "x"}} },
+			errMsg: `invalid tag "k": unknown type "date"`,
+		},
+		{
+			name:   "bad tag value",
+			span:   func(span *jModel.Span) { span.Tags = []jModel.KeyValue{{Key: "k", Type: jModel.BoolType, Value: 1.0}} },
+			errMsg: `invalid tag "k": unexpected float64 value of type bool`,
+		},
+		{
+			name:   "fractional integer",
+			span:   func(span *jModel.Span) { span.Tags = []jModel.KeyValue{{Key: "k", Type: jModel.Int64Type, Value: 1.5}} },
+			errMsg: "1.5 is not an integer",
+		},
+		{
+			name: "bad log field",
+			span: func(span *jModel.Span) {
+				span.Logs = []jModel.Log{{Fields: []jModel.KeyValue{{Key: "k", Type: jModel.BinaryType, Value: "!"}}}}
+			},
+			errMsg: `invalid tag "k"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			span := validSpan
+			test.span(&span)
+			_, err := ToDomain(&jModel.Trace{Spans: []jModel.Span{span}, Processes: processes})
+			require.ErrorContains(t, err, test.errMsg)
+		})
+	}
+
+	_, err := ToDomain(&jModel.Trace{Processes: map[jModel.ProcessID]jModel.Process{
+		"p1": {ServiceName: "frontend", Tags: []jModel.KeyValue{{Key: "k", Type: jModel.Float64Type, Value: "x"}}},
+	}})
+	require.ErrorContains(t, err, "invalid process p1")
+}
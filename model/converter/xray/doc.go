@@ -0,0 +1,5 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package xray allows converting AWS X-Ray segment documents to model.Trace.
+package xray
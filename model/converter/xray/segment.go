@@ -0,0 +1,123 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package xray
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PutTraceSegmentsInput is the payload of the X-Ray PutTraceSegments API,
+// each document being a segment or an independent subsegment in JSON format.
+type PutTraceSegmentsInput struct {
+	TraceSegmentDocuments []string `json:"TraceSegmentDocuments"`
+}
+
+// Segment is an X-Ray segment document, or one of its subsegments.
+// See https://docs.aws.amazon.com/xray/latest/devguide/xray-api-segmentdocuments.html
+type Segment struct {
+	Name        string                    `json:"name"`
+	ID          string                    `json:"id"`
+	TraceID     string                    `json:"trace_id,omitempty"`
+	ParentID    string                    `json:"parent_id,omitempty"`
+	Type        string                    `json:"type,omitempty"`
+	StartTime   float64                   `json:"start_time"`
+	EndTime     float64                   `json:"end_time,omitempty"`
+	InProgress  bool                      `json:"in_progress,omitempty"`
+	Namespace   string                    `json:"namespace,omitempty"`
+	Origin      string                    `json:"origin,omitempty"`
+	User        string                    `json:"user,omitempty"`
+	Error       bool                      `json:"error,omitempty"`
+	Fault       bool                      `json:"fault,omitempty"`
+	Throttle    bool                      `json:"throttle,omitempty"`
+	Cause       *Cause                    `json:"cause,omitempty"`
+	HTTP        *HTTP                     `json:"http,omitempty"`
+	AWS         map[string]any            `json:"aws,omitempty"`
+	SQL         *SQL                      `json:"sql,omitempty"`
+	Service     *Service                  `json:"service,omitempty"`
+	Annotations map[string]any            `json:"annotations,omitempty"`
+	Metadata    map[string]map[string]any `json:"metadata,omitempty"`
+	Subsegments []*Segment                `json:"subsegments,omitempty"`
+}
+
+// Cause describes the exceptions which caused an error. It either holds the exceptions,
+// or only the ID of an exception recorded by another subsegment.
+type Cause struct {
+	ExceptionID      string      `json:"-"`
+	WorkingDirectory string      `json:"working_directory,omitempty"`
+	Exceptions       []Exception `json:"exceptions,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting an exception ID or an object.
+func (c *Cause) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &c.ExceptionID)
+	}
+	type cause Cause
+	return json.Unmarshal(data, (*cause)(c))
+}
+
+// Exception is an exception recorded by a segment.
+type Exception struct {
+	ID      string       `json:"id,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Type    string       `json:"type,omitempty"`
+	Remote  bool         `json:"remote,omitempty"`
+	Stack   []StackFrame `json:"stack,omitempty"`
+}
+
+// StackFrame is a frame of the stack of an exception.
+type StackFrame struct {
+	Path  string `json:"path,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Label string `json:"label,omitempty"`
+}
+
+// HTTP describes the HTTP request served or sent by a segment.
+type HTTP struct {
+	Request  *HTTPRequest  `json:"request,omitempty"`
+	Response *HTTPResponse `json:"response,omitempty"`
+}
+
+// HTTPRequest describes an HTTP request.
+type HTTPRequest struct {
+	Method        string `json:"method,omitempty"`
+	URL           string `json:"url,omitempty"`
+	UserAgent     string `json:"user_agent,omitempty"`
+	ClientIP      string `json:"client_ip,omitempty"`
+	XForwardedFor bool   `json:"x_forwarded_for,omitempty"`
+	Traced        bool   `json:"traced,omitempty"`
+}
+
+// HTTPResponse describes an HTTP response.
+type HTTPResponse struct {
+	Status        int   `json:"status,omitempty"`
+	ContentLength int64 `json:"content_length,omitempty"`
+}
+
+// SQL describes the query sent to a SQL database by a subsegment.
+type SQL struct {
+	ConnectionString string `json:"connection_string,omitempty"`
+	URL              string `json:"url,omitempty"`
+	SanitizedQuery   string `json:"sanitized_query,omitempty"`
+	DatabaseType     string `json:"database_type,omitempty"`
+	DatabaseVersion  string `json:"database_version,omitempty"`
+	DriverVersion    string `json:"driver_version,omitempty"`
+	User             string `json:"user,omitempty"`
+	Preparation      string `json:"preparation,omitempty"`
+}
+
+// Service describes the application which recorded a segment.
+type Service struct {
+	Version string `json:"version,omitempty"`
+}
+
+// DeserializeSegment parses a segment document in JSON format.
+func DeserializeSegment(document string) (*Segment, error) {
+	var segment Segment
+	if err := json.Unmarshal([]byte(document), &segment); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal X-Ray segment document: %w", err)
+	}
+	return &segment, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package xray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeserializeSegment(t *testing.T) {
+	segment, err := DeserializeSegment(`{
+		"name": "foo",
+		"id": "70de5b6f19ff9a0a",
+		"trace_id": "1-581cf771-a006649127e371903a2de979",
+		"start_time": 1478293361.271,
+		"end_time": 1478293361.449,
+		"http": {"response": {"status": 500}}
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", segment.Name)
+	assert.Equal(t, "70de5b6f19ff9a0a", segment.ID)
+	assert.Equal(t, 500, segment.HTTP.Response.Status)
+	assert.Nil(t, segment.HTTP.Request)
+
+	_, err = DeserializeSegment(`{"name": 42}`)
+	require.ErrorContains(t, err, "cannot unmarshal X-Ray segment document")
+}
+
+func TestDeserializeCause(t *testing.T) {
+	segment, err := DeserializeSegment(`{"cause": "0fd2ef1b5c7d3a90"}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Cause{ExceptionID: "0fd2ef1b5c7d3a90"}, segment.Cause)
+
+	segment, err = DeserializeSegment(`{"cause": {
+		"working_directory": "/app",
+		"exceptions": [{"id": "0fd2ef1b5c7d3a90", "message": "boom", "stack": [{"path": "main.go", "line": 7, "label": "main"}]}]
+	}}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Cause{
+		WorkingDirectory: "/app",
+		Exceptions: []Exception{{
+			ID:      "0fd2ef1b5c7d3a90",
+			Message: "boom",
+			Stack:   []StackFrame{{Path: "main.go", Line: 7, Label: "main"}},
+		}},
+	}, segment.Cause)
+
+	_, err = DeserializeSegment(`{"cause": 42}`)
+	require.Error(t, err)
+}
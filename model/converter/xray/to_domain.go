@@ -0,0 +1,341 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package xray
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const (
+	// UnknownServiceName is the service name of the independent subsegments,
+	// which do not carry the name of the segment they belong to.
+	UnknownServiceName = "unknown-service-name"
+
+	segmentTypeSubsegment = "subsegment"
+	namespaceAWS          = "aws"
+	namespaceRemote       = "remote"
+
+	traceIDVersion   = "1"
+	traceIDEpochLen  = 8
+	traceIDRandomLen = 24
+	spanIDLen        = 16
+
+	keySpanKind   = "span.kind"
+	keyError      = "error"
+	keyEvent      = "event"
+	keyErrorKind  = "error.kind"
+	keyMessage    = "message"
+	keyStack      = "stack"
+	keyAWS        = "aws"
+	keyMetaPrefix = "metadata."
+)
+
+// ToDomain transforms X-Ray segments into model.Trace. The segments may belong to different traces.
+// A valid model.Trace is always returned, even when there are errors, the invalid segments being skipped.
+func ToDomain(segments []*Segment) (*model.Trace, error) {
+	var errs []error
+	trace := &model.Trace{}
+	for _, segment := range segments {
+		spans, err := ToDomainSpans(segment)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		trace.Spans = append(trace.Spans, spans...)
+	}
+	return trace, errors.Join(errs...)
+}
+
+// ToDomainSpans transforms a segment and its subsegments into model spans, the subsegments
+// becoming children of their parent segment. A segment whose IDs are invalid is rejected as a whole.
+func ToDomainSpans(segment *Segment) ([]*model.Span, error) {
+	traceID, err := traceIDToDomain(segment.TraceID)
+	if err != nil {
+		return nil, err
+	}
+	var parentID model.SpanID
+	if segment.ParentID != "" {
+		if parentID, err = spanIDToDomain(segment.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	process := &model.Process{ServiceName: segment.Name}
+	if segment.Type == segmentTypeSubsegment {
+		process.ServiceName = UnknownServiceName
+	}
+	if segment.Service != nil && segment.Service.Version != "" {
+		process.Tags = append(process.Tags, model.String("service.version", segment.Service.Version))
+	}
+	if segment.Origin != "" {
+		process.Tags = append(process.Tags, model.String("xray.origin", segment.Origin))
+	}
+
+	var spans []*model.Span
+	if err := transformSegment(segment, traceID, parentID, process, &spans); err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+func transformSegment(
+	segment *Segment,
+	traceID model.TraceID,
+	parentID model.SpanID,
+	process *model.Process,
+	spans *[]*model.Span,
+) error {
+	spanID, err := spanIDToDomain(segment.ID)
+	if err != nil {
+		return err
+	}
+	startTime := timeToDomain(segment.StartTime)
+	span := &model.Span{
+		TraceID:       traceID,
+		SpanID:        spanID,
+		OperationName: segment.Name,
+		StartTime:     startTime,
+		Tags:          tagsToDomain(segment),
+		Process:       process,
+	}
+	if parentID != 0 {
+		span.References = []model.SpanRef{model.NewChildOfRef(traceID, parentID)}
+	}
+	endTime := startTime
+	if !segment.InProgress && segment.EndTime > segment.StartTime {
+		endTime = timeToDomain(segment.EndTime)
+		span.Duration = endTime.Sub(startTime)
+	}
+	span.Logs = causeToDomain(segment.Cause, endTime)
+	*spans = append(*spans, span)
+
+	for _, subsegment := range segment.Subsegments {
+		if err := transformSegment(subsegment, traceID, spanID, process, spans); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// traceIDToDomain maps an X-Ray trace ID, 1-<8 hex digits of epoch>-<24 hex digits of random>,
+// to the 128-bit trace ID made of the epoch followed by the random digits.
+func traceIDToDomain(xrayID string) (model.TraceID, error) {
+	parts := strings.Split(xrayID, "-")
+	if len(parts) != 3 || parts[0] != traceIDVersion ||
+		len(parts[1]) != traceIDEpochLen || len(parts[2]) != traceIDRandomLen {
+		return model.TraceID{}, fmt.Errorf("invalid X-Ray trace ID %q", xrayID)
+	}
+	high, err := strconv.ParseUint(parts[1]+parts[2][:8], 16, 64)
+	if err != nil {
+		return model.TraceID{}, fmt.Errorf("invalid X-Ray trace ID %q: %w", xrayID, err)
+	}
+	low, err := strconv.ParseUint(parts[2][8:], 16, 64)
+	if err != nil {
+		return model.TraceID{}, fmt.Errorf("invalid X-Ray trace ID %q: %w", xrayID, err)
+	}
+	return model.NewTraceID(high, low), nil
+}
+
+func spanIDToDomain(xrayID string) (model.SpanID, error) {
+	if len(xrayID) != spanIDLen {
+		return 0, fmt.Errorf("invalid X-Ray segment ID %q", xrayID)
+	}
+	id, err := strconv.ParseUint(xrayID, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid X-Ray segment ID %q: %w", xrayID, err)
+	}
+	return model.SpanID(id), nil
+}
+
+// timeToDomain converts the epoch seconds of X-Ray, with a microsecond precision.
+func timeToDomain(seconds float64) time.Time {
+	return time.UnixMicro(int64(math.Round(seconds * 1e6))).UTC()
+}
+
+func tagsToDomain(segment *Segment) []model.KeyValue {
+	var tags []model.KeyValue
+	if kind := spanKind(segment); kind != "" {
+		tags = append(tags, model.String(keySpanKind, kind))
+	}
+	if segment.InProgress {
+		tags = append(tags, model.Bool("xray.in_progress", true))
+	}
+	if segment.Error || segment.Fault || segment.Throttle {
+		tags = append(tags, model.Bool(keyError, true))
+	}
+	if segment.Error {
+		tags = append(tags, model.Bool("xray.error", true))
+	}
+	if segment.Fault {
+		tags = append(tags, model.Bool("xray.fault", true))
+	}
+	if segment.Throttle {
+		tags = append(tags, model.Bool("xray.throttle", true))
+	}
+	if segment.User != "" {
+		tags = append(tags, model.String("enduser.id", segment.User))
+	}
+	tags = append(tags, httpTagsToDomain(segment.HTTP)...)
+	tags = append(tags, sqlTagsToDomain(segment.SQL)...)
+	tags = appendFlattened(tags, keyAWS, segment.AWS)
+	for _, key := range sortedKeys(segment.Annotations) {
+		tags = append(tags, valueToDomain(key, segment.Annotations[key]))
+	}
+	for _, namespace := range sortedKeys(segment.Metadata) {
+		metadata := segment.Metadata[namespace]
+		for _, key := range sortedKeys(metadata) {
+			value, _ := json.Marshal(metadata[key])
+			tags = append(tags, model.String(keyMetaPrefix+namespace+"."+key, string(value)))
+		}
+	}
+	return tags
+}
+
+// spanKind returns server for the segments, and client for the subsegments calling remote services.
+func spanKind(segment *Segment) string {
+	if segment.Namespace == namespaceAWS || segment.Namespace == namespaceRemote {
+		return trace.SpanKindClient.String()
+	}
+	if segment.TraceID != "" && segment.Type != segmentTypeSubsegment {
+		return trace.SpanKindServer.String()
+	}
+	return ""
+}
+
+func httpTagsToDomain(http *HTTP) []model.KeyValue {
+	if http == nil {
+		return nil
+	}
+	var tags []model.KeyValue
+	if req := http.Request; req != nil {
+		tags = appendString(tags, "http.method", req.Method)
+		tags = appendString(tags, "http.url", req.URL)
+		tags = appendString(tags, "http.user_agent", req.UserAgent)
+		tags = appendString(tags, "http.client_ip", req.ClientIP)
+	}
+	if resp := http.Response; resp != nil {
+		if resp.Status != 0 {
+			tags = append(tags, model.Int64("http.status_code", int64(resp.Status)))
+		}
+		if resp.ContentLength != 0 {
+			tags = append(tags, model.Int64("http.response_content_length", resp.ContentLength))
+		}
+	}
+	return tags
+}
+
+func sqlTagsToDomain(sql *SQL) []model.KeyValue {
+	if sql == nil {
+		return nil
+	}
+	tags := []model.KeyValue{model.String("db.type", "sql")}
+	instance := sql.URL
+	if instance == "" {
+		instance = sql.ConnectionString
+	}
+	tags = appendString(tags, "db.instance", instance)
+	tags = appendString(tags, "db.statement", sql.SanitizedQuery)
+	tags = appendString(tags, "db.user", sql.User)
+	tags = appendString(tags, "sql.database_type", sql.DatabaseType)
+	tags = appendString(tags, "sql.database_version", sql.DatabaseVersion)
+	tags = appendString(tags, "sql.driver_version", sql.DriverVersion)
+	tags = appendString(tags, "sql.preparation", sql.Preparation)
+	return tags
+}
+
+func appendString(tags []model.KeyValue, key, value string) []model.KeyValue {
+	if value == "" {
+		return tags
+	}
+	return append(tags, model.String(key, value))
+}
+
+// appendFlattened appends the values of an object as tags, prefixing the keys of nested objects with their parents.
+func appendFlattened(tags []model.KeyValue, prefix string, object map[string]any) []model.KeyValue {
+	for _, key := range sortedKeys(object) {
+		if nested, ok := object[key].(map[string]any); ok {
+			tags = appendFlattened(tags, prefix+"."+key, nested)
+			continue
+		}
+		tags = append(tags, valueToDomain(prefix+"."+key, object[key]))
+	}
+	return tags
+}
+
+func valueToDomain(key string, value any) model.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return model.String(key, v)
+	case bool:
+		return model.Bool(key, v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+			return model.Int64(key, int64(v))
+		}
+		return model.Float64(key, v)
+	default:
+		encoded, _ := json.Marshal(v)
+		return model.String(key, string(encoded))
+	}
+}
+
+// causeToDomain converts the exceptions into error logs.
+func causeToDomain(cause *Cause, timestamp time.Time) []model.Log {
+	if cause == nil {
+		return nil
+	}
+	if cause.ExceptionID != "" {
+		return []model.Log{{
+			Timestamp: timestamp,
+			Fields: []model.KeyValue{
+				model.String(keyEvent, keyError),
+				model.String("xray.exception_id", cause.ExceptionID),
+			},
+		}}
+	}
+	logs := make([]model.Log, 0, len(cause.Exceptions))
+	for _, exception := range cause.Exceptions {
+		fields := []model.KeyValue{model.String(keyEvent, keyError)}
+		fields = appendString(fields, keyErrorKind, exception.Type)
+		fields = appendString(fields, keyMessage, exception.Message)
+		fields = appendString(fields, "xray.exception_id", exception.ID)
+		if exception.Remote {
+			fields = append(fields, model.Bool("xray.remote", true))
+		}
+		fields = appendString(fields, keyStack, stackToDomain(exception.Stack))
+		logs = append(logs, model.Log{Timestamp: timestamp, Fields: fields})
+	}
+	return logs
+}
+
+func stackToDomain(stack []StackFrame) string {
+	var sb strings.Builder
+	for i, frame := range stack {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "%s (%s:%d)", frame.Label, frame.Path, frame.Line)
+	}
+	return sb.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
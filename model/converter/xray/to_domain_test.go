@@ -0,0 +1,166 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package xray
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/kr/pretty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+const numberOfFixtures = 3
+
+func TestToDomain(t *testing.T) {
+	for i := 1; i <= numberOfFixtures; i++ {
+		in := fmt.Sprintf("fixtures/xray_%02d.json", i)
+		out := fmt.Sprintf("fixtures/domain_%02d.json", i)
+		segments := loadSegments(t, in)
+		expectedTrace := loadJaegerTrace(t, out)
+		expectedTrace.NormalizeTimestamps()
+		t.Run(in+" -> "+out+" : "+segments[0].Name, func(t *testing.T) {
+			trace, err := ToDomain(segments)
+			require.NoError(t, err)
+			trace.NormalizeTimestamps()
+			if !assert.Equal(t, expectedTrace, trace) {
+				for _, err := range pretty.Diff(expectedTrace, trace) {
+					t.Log(err)
+				}
+			}
+		})
+	}
+}
+
+func TestToDomainTraceID(t *testing.T) {
+	spans, err := ToDomainSpans(&Segment{
+		Name:      "foo",
+		ID:        "70de5b6f19ff9a0a",
+		TraceID:   "1-581cf771-a006649127e371903a2de979",
+		StartTime: 1478293361.271,
+		EndTime:   1478293361.449,
+	})
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	assert.Equal(t, model.NewTraceID(0x581cf771a0066491, 0x27e371903a2de979), spans[0].TraceID)
+	assert.Equal(t, model.SpanID(0x70de5b6f19ff9a0a), spans[0].SpanID)
+	assert.Equal(t, 178*time.Millisecond, spans[0].Duration)
+}
+
+func TestToDomainInvalidIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		segment Segment
+		err     string
+	}{
+		{
+			name:    "trace ID without version",
+			segment: Segment{ID: "70de5b6f19ff9a0a", TraceID: "581cf771-a006649127e371903a2de979"},
+			err:     `invalid X-Ray trace ID "581cf771-a006649127e371903a2de979"`,
+		},
+		{
+			name:    "trace ID with short random",
+			segment: Segment{ID: "70de5b6f19ff9a0a", TraceID: "1-581cf771-a006649127e371903a2de9"},
+			err:     `invalid X-Ray trace ID "1-581cf771-a006649127e371903a2de9"`,
+		},
+		{
+			name:    "trace ID with bad epoch",
+			segment: Segment{ID: "70de5b6f19ff9a0a", TraceID: "1-581cf77z-a006649127e371903a2de979"},
+			err:     `invalid X-Ray trace ID "1-581cf77z-a006649127e371903a2de979"`,
+		},
+		{
+			name:    "trace ID with bad random",
+			segment: Segment{ID: "70de5b6f19ff9a0a", TraceID: "1-581cf771-a006649127e371903a2de97z"},
+			err:     `invalid X-Ray trace ID "1-581cf771-a006649127e371903a2de97z"`,
+		},
+		{
+			name:    "short segment ID",
+			segment: Segment{ID: "70de5b6f", TraceID: "1-581cf771-a006649127e371903a2de979"},
+			err:     `invalid X-Ray segment ID "70de5b6f"`,
+		},
+		{
+			name: "bad parent ID",
+			segment: Segment{
+				ID:       "70de5b6f19ff9a0a",
+				TraceID:  "1-581cf771-a006649127e371903a2de979",
+				ParentID: "70de5b6f19ff9a0z",
+			},
+			err: `invalid X-Ray segment ID "70de5b6f19ff9a0z"`,
+		},
+		{
+			name: "bad subsegment ID",
+			segment: Segment{
+				ID:          "70de5b6f19ff9a0a",
+				TraceID:     "1-581cf771-a006649127e371903a2de979",
+				Subsegments: []*Segment{{ID: "bad"}},
+			},
+			err: `invalid X-Ray segment ID "bad"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			valid := &Segment{Name: "valid", ID: "70de5b6f19ff9a0b", TraceID: "1-581cf771-a006649127e371903a2de979"}
+			trace, err := ToDomain([]*Segment{&test.segment, valid})
+			require.ErrorContains(t, err, test.err)
+			require.Len(t, trace.Spans, 1)
+			assert.Equal(t, "valid", trace.Spans[0].OperationName)
+		})
+	}
+}
+
+func TestToDomainInProgress(t *testing.T) {
+	spans, err := ToDomainSpans(&Segment{
+		Name:       "foo",
+		ID:         "70de5b6f19ff9a0a",
+		TraceID:    "1-581cf771-a006649127e371903a2de979",
+		StartTime:  1478293361.271,
+		InProgress: true,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, spans[0].Duration)
+	inProgress, ok := model.KeyValues(spans[0].Tags).FindByKey("xray.in_progress")
+	require.True(t, ok)
+	assert.True(t, inProgress.Bool())
+}
+
+func TestValueToDomain(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected model.KeyValue
+	}{
+		{value: "bar", expected: model.String("foo", "bar")},
+		{value: true, expected: model.Bool("foo", true)},
+		{value: float64(42), expected: model.Int64("foo", 42)},
+		{value: 4.2, expected: model.Float64("foo", 4.2)},
+		{value: []any{"a", float64(1)}, expected: model.String("foo", `["a",1]`)},
+		{value: nil, expected: model.String("foo", "null")},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, valueToDomain("foo", test.value))
+	}
+}
+
+func loadSegments(t *testing.T, file string) []*Segment {
+	data, err := os.ReadFile(file)
+	require.NoError(t, err, "Failed to load json fixture file %s", file)
+	var segments []*Segment
+	require.NoError(t, json.Unmarshal(data, &segments), "Failed to parse json fixture file %s", file)
+	return segments
+}
+
+func loadJaegerTrace(t *testing.T, file string) *model.Trace {
+	jsonFile, err := os.Open(file)
+	require.NoError(t, err, "Failed to open json fixture file %s", file)
+	defer jsonFile.Close()
+	var trace model.Trace
+	require.NoError(t, jsonpb.Unmarshal(jsonFile, &trace), file)
+	return &trace
+}
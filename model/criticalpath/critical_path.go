@@ -0,0 +1,206 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package criticalpath
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+// Segment is a stretch of the critical path during which the latency of the trace was spent in a span.
+type Segment struct {
+	SpanID   model.SpanID
+	Start    time.Time
+	Duration time.Duration
+}
+
+type node struct {
+	spanID   model.SpanID
+	start    time.Time
+	end      time.Time
+	children []*node
+}
+
+// Compute returns the critical path of the trace, as segments in chronological order.
+//
+// The critical path is the chain of work that determines the overall latency of the trace.
+// It is computed backwards from the end of the root span:
+//   - starting at the end of a span, the child span that finished last is on the critical path,
+//     and the time between the end of that child and the cursor is attributed to the span itself;
+//   - the algorithm recurses into that child, then moves the cursor to the start of the child
+//     and repeats with the children that finished before it. Children running in parallel
+//     with a child on the critical path are therefore excluded, since waiting on them did not
+//     delay the parent;
+//   - gaps where no child was running are attributed to the span itself.
+//
+// Only CHILD_OF references are followed: the FOLLOWS_FROM spans run asynchronously, they do
+// not block their parent and are left out of the path along with their descendants. The root
+// is the span without parent in the trace that finished last.
+//
+// Child spans are clipped to the time range of their parent, and children entirely outside of it
+// are ignored. A span may have several segments when its own work alternates with the work of
+// its children, but consecutive segments of a span are merged. A leaf span on the path has a
+// segment even if it has no duration.
+func Compute(trace *model.Trace) []Segment {
+	path, _ := ComputeWithBudget(trace, 0)
+	return path
+}
+
+// ComputeWithBudget returns the critical path of the trace like Compute, following the references
+// of at most maxSpans spans, e.g. to bound the work on traces with a huge fan-out. When the budget
+// is exhausted, the spans beyond it are left out of the tree and the path computed from the spans
+// visited is returned as truncated. A budget of 0 or less means no budget.
+func ComputeWithBudget(trace *model.Trace, maxSpans int) (path []Segment, truncated bool) {
+	b := &budget{remaining: maxSpans, unlimited: maxSpans <= 0}
+	root := buildTree(trace, b)
+	if root == nil {
+		return nil, false
+	}
+	walk(root, root.end, &path)
+
+	// the path was collected backwards
+	result := make([]Segment, 0, len(path))
+	for i := len(path) - 1; i >= 0; i-- {
+		if last := len(result) - 1; last >= 0 && result[last].SpanID == path[i].SpanID {
+			result[last].Duration += path[i].Duration
+			continue
+		}
+		result = append(result, path[i])
+	}
+	return result, b.exhausted
+}
+
+// Contributions sums the durations of the segments of each span of the path.
+func Contributions(path []Segment) map[model.SpanID]time.Duration {
+	contributions := make(map[model.SpanID]time.Duration, len(path))
+	for _, segment := range path {
+		contributions[segment.SpanID] += segment.Duration
+	}
+	return contributions
+}
+
+// budget counts the spans visited when following the references.
+type budget struct {
+	remaining int
+	unlimited bool
+	exhausted bool
+}
+
+// visit returns whether one more span can be visited.
+func (b *budget) visit() bool {
+	if b.unlimited {
+		return true
+	}
+	if b.remaining <= 0 {
+		b.exhausted = true
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// buildTree links the spans of the trace by their CHILD_OF references and returns
+// the root span that finished last, the spans beyond the budget being left out.
+func buildTree(trace *model.Trace, b *budget) *node {
+	nodes := make(map[model.SpanID]*node, len(trace.Spans))
+	for _, span := range trace.Spans {
+		nodes[span.SpanID] = &node{
+			spanID: span.SpanID,
+			start:  span.StartTime,
+			end:    span.StartTime.Add(span.Duration),
+		}
+	}
+	var roots []*node
+	for _, span := range trace.Spans {
+		n := nodes[span.SpanID]
+		if parent, ok := nodes[childOfParent(span)]; ok && parent != n {
+			parent.children = append(parent.children, n)
+		} else if !hasParent(span, nodes) {
+			roots = append(roots, n)
+		}
+	}
+	var root *node
+	for _, r := range roots {
+		if root == nil || r.end.After(root.end) {
+			root = r
+		}
+	}
+	if root != nil {
+		// the budget is at least one span, the root is always visited
+		b.visit()
+		clipChildren(root, make(map[*node]struct{}), b)
+	}
+	return root
+}
+
+func childOfParent(span *model.Span) model.SpanID {
+	for _, ref := range span.References {
+		if ref.TraceID == span.TraceID && ref.RefType == model.ChildOf {
+			return ref.SpanID
+		}
+	}
+	return model.SpanID(0)
+}
+
+// hasParent returns whether the span references another span of the trace, e.g. with FOLLOWS_FROM.
+func hasParent(span *model.Span, nodes map[model.SpanID]*node) bool {
+	for _, ref := range span.References {
+		if _, ok := nodes[ref.SpanID]; ok && ref.TraceID == span.TraceID && ref.SpanID != span.SpanID {
+			return true
+		}
+	}
+	return false
+}
+
+// clipChildren restricts the children to the time range of their parent and sorts them
+// by end time, last finished first. The visited set protects against reference cycles,
+// and the children beyond the budget are dropped.
+func clipChildren(n *node, visited map[*node]struct{}, b *budget) {
+	visited[n] = struct{}{}
+	children := n.children[:0]
+	for _, child := range n.children {
+		if _, ok := visited[child]; ok {
+			continue
+		}
+		if !child.start.Before(n.end) || child.end.Before(n.start) {
+			continue
+		}
+		if child.start.Before(n.start) {
+			child.start = n.start
+		}
+		if child.end.After(n.end) {
+			child.end = n.end
+		}
+		if !b.visit() {
+			break
+		}
+		children = append(children, child)
+		clipChildren(child, visited, b)
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].end.After(children[j].end)
+	})
+	n.children = children
+}
+
+// walk appends the segments of the critical path of the node before the cursor time,
+// in reverse chronological order.
+func walk(n *node, cursor time.Time, path *[]Segment) {
+	for _, child := range n.children {
+		if child.end.After(cursor) {
+			// the child ran in parallel with a span already on the critical path
+			continue
+		}
+		if child.end.Before(cursor) {
+			*path = append(*path, Segment{SpanID: n.spanID, Start: child.end, Duration: cursor.Sub(child.end)})
+		}
+		walk(child, child.end, path)
+		cursor = child.start
+	}
+	if n.start.Before(cursor) || len(n.children) == 0 {
+		*path = append(*path, Segment{SpanID: n.spanID, Start: n.start, Duration: cursor.Sub(n.start)})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package criticalpath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+var (
+	testTraceID = model.NewTraceID(0, 42)
+	baseTime    = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func makeSpan(id uint64, parent uint64, refType model.SpanRefType, startMillis, endMillis int64) *model.Span {
+	span := &model.Span{
+		TraceID:   testTraceID,
+		SpanID:    model.NewSpanID(id),
+		StartTime: baseTime.Add(time.Duration(startMillis) * time.Millisecond),
+		Duration:  time.Duration(endMillis-startMillis) * time.Millisecond,
+	}
+	if parent != 0 {
+		span.References = []model.SpanRef{{
+			TraceID: testTraceID,
+			SpanID:  model.NewSpanID(parent),
+			RefType: refType,
+		}}
+	}
+	return span
+}
+
+// segment describes a segment of the path by span ID and start and end in milliseconds.
+type segment struct {
+	id         uint64
+	start, end int64
+}
+
+func toSegments(path []Segment) []segment {
+	var result []segment
+	for _, s := range path {
+		start := s.Start.Sub(baseTime).Milliseconds()
+		result = append(result, segment{
+			id:    uint64(s.SpanID),
+			start: start,
+			end:   start + s.Duration.Milliseconds(),
+		})
+	}
+	return result
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name     string
+		spans    []*model.Span
+		expected []segment
+	}{
+		{
+			// 1 [0-100] waits on 2 [10-90], which mostly waits on 4 [40-85];
+			// 5 [5-20] runs in parallel with 2, and 3 [15-30] with the self time of 2
+			name: "bottleneck",
+			spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 100),
+				makeSpan(5, 1, model.ChildOf, 5, 20),
+				makeSpan(2, 1, model.ChildOf, 10, 90),
+				makeSpan(3, 2, model.ChildOf, 15, 30),
+				makeSpan(4, 2, model.ChildOf, 40, 85),
+				makeSpan(6, 1, model.ChildOf, 92, 98),
+			},
+			expected: []segment{
+				{1, 0, 10}, {2, 10, 15}, {3, 15, 30}, {2, 30, 40}, {4, 40, 85},
+				{2, 85, 90}, {1, 90, 92}, {6, 92, 98}, {1, 98, 100},
+			},
+		},
+		{
+			name:     "single span",
+			spans:    []*model.Span{makeSpan(1, 0, model.ChildOf, 0, 10)},
+			expected: []segment{{1, 0, 10}},
+		},
+		{
+			name: "sequential children",
+			spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 30),
+				makeSpan(2, 1, model.ChildOf, 0, 10),
+				makeSpan(3, 1, model.ChildOf, 10, 30),
+			},
+			expected: []segment{{2, 0, 10}, {3, 10, 30}},
+		},
+		{
+			name: "child overflowing the parent is clipped",
+			spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+				makeSpan(2, 1, model.ChildOf, 5, 20),
+				makeSpan(3, 1, model.ChildOf, 20, 30),
+			},
+			expected: []segment{{1, 0, 5}, {2, 5, 10}},
+		},
+		{
+			name: "async follows from spans do not block the parent",
+			spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+				makeSpan(2, 1, model.ChildOf, 2, 6),
+				// a fire-and-forget span outliving its parent, with a child of its own
+				makeSpan(3, 1, model.FollowsFrom, 5, 50),
+				makeSpan(4, 3, model.ChildOf, 10, 45),
+			},
+			expected: []segment{{1, 0, 2}, {2, 2, 6}, {1, 6, 10}},
+		},
+		{
+			name: "root that finished last is used",
+			spans: []*model.Span{
+				makeSpan(1, 0, model.ChildOf, 0, 10),
+				makeSpan(2, 7, model.ChildOf, 0, 20),
+			},
+			expected: []segment{{2, 0, 20}},
+		},
+		{
+			name:     "zero-duration leaf",
+			spans:    []*model.Span{makeSpan(1, 0, model.ChildOf, 5, 5)},
+			expected: []segment{{1, 5, 5}},
+		},
+		{
+			name: "empty trace",
+		},
+		{
+			name: "reference cycle",
+			spans: []*model.Span{
+				makeSpan(1, 2, model.ChildOf, 0, 10),
+				makeSpan(2, 1, model.ChildOf, 0, 10),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := Compute(&model.Trace{Spans: test.spans})
+			assert.Equal(t, test.expected, toSegments(path))
+		})
+	}
+}
+
+func TestContributions(t *testing.T) {
+	path := Compute(&model.Trace{Spans: []*model.Span{
+		makeSpan(1, 0, model.ChildOf, 0, 100),
+		makeSpan(2, 1, model.ChildOf, 10, 90),
+		makeSpan(3, 2, model.ChildOf, 40, 85),
+	}})
+	assert.Equal(t, map[model.SpanID]time.Duration{
+		model.NewSpanID(1): 20 * time.Millisecond,
+		model.NewSpanID(2): 35 * time.Millisecond,
+		model.NewSpanID(3): 45 * time.Millisecond,
+	}, Contributions(path))
+}
+
+func TestComputeWithBudget(t *testing.T) {
+	// the root [0-200000] has a huge fan-out of children, one per millisecond
+	const fanOut = 100_000
+	spans := []*model.Span{makeSpan(1, 0, model.ChildOf, 0, 2*fanOut)}
+	for i := int64(0); i < fanOut; i++ {
+		spans = append(spans, makeSpan(uint64(i+2), 1, model.ChildOf, 2*i, 2*i+1))
+	}
+	trace := &model.Trace{Spans: spans}
+
+	const maxSpans = 1000
+	path, truncated := ComputeWithBudget(trace, maxSpans)
+	assert.True(t, truncated)
+	visited := Contributions(path)
+	assert.Len(t, visited, maxSpans, "the root and the children within the budget are on the path")
+	assert.Contains(t, visited, model.NewSpanID(1))
+	assert.NotContains(t, visited, model.NewSpanID(maxSpans+1))
+
+	path, truncated = ComputeWithBudget(trace, len(spans))
+	assert.False(t, truncated)
+	assert.Equal(t, Compute(trace), path)
+	assert.Len(t, Contributions(path), len(spans))
+}
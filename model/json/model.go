@@ -108,6 +108,19 @@ type DependencyLink struct {
 	Parent    string `json:"parent"`
 	Child     string `json:"child"`
 	CallCount uint64 `json:"callCount"`
+	// ErrorCount is the number of failed calls, only set when requested
+	ErrorCount *uint64 `json:"errorCount,omitempty"`
+	// ErrorCountApproximate is set when ErrorCount was derived from a sample of traces
+	ErrorCountApproximate bool `json:"errorCountApproximate,omitempty"`
+}
+
+// OperationDependencyLink shows dependencies between the operations of services
+type OperationDependencyLink struct {
+	Parent          string `json:"parent"`
+	ParentOperation string `json:"parentOperation"`
+	Child           string `json:"child"`
+	ChildOperation  string `json:"childOperation"`
+	CallCount       uint64 `json:"callCount"`
 }
 
 // Operation defines the data in the operation response when query operation by service and span kind
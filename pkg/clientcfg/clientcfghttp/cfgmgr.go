@@ -34,6 +34,15 @@ func (c *ConfigManager) GetSamplingStrategy(ctx context.Context, serviceName str
 	return c.SamplingProvider.GetSamplingStrategy(ctx, serviceName)
 }
 
+// GetAttributeStrategies implements samplingstrategy.AttributeProvider, returning no strategies
+// when the sampling provider has none.
+func (c *ConfigManager) GetAttributeStrategies(ctx context.Context, serviceName string) ([]samplingstrategy.AttributeStrategy, error) {
+	if p, ok := c.SamplingProvider.(samplingstrategy.AttributeProvider); ok {
+		return p.GetAttributeStrategies(ctx, serviceName)
+	}
+	return nil, nil
+}
+
 // GetBaggageRestrictions implements ClientConfigManager.GetBaggageRestrictions.
 func (c *ConfigManager) GetBaggageRestrictions(ctx context.Context, serviceName string) ([]*baggage.BaggageRestriction, error) {
 	if c.BaggageManager == nil {
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/thrift-gen/baggage"
 )
@@ -41,6 +42,16 @@ func (*mockSamplingProvider) Close() error {
 	return nil
 }
 
+type mockAttributeProvider struct {
+	mockSamplingProvider
+	attributeStrategies []samplingstrategy.AttributeStrategy
+	err                 error
+}
+
+func (m *mockAttributeProvider) GetAttributeStrategies(context.Context, string /* serviceName */) ([]samplingstrategy.AttributeStrategy, error) {
+	return m.attributeStrategies, m.err
+}
+
 type mockBaggageMgr struct {
 	baggageResponse []*baggage.BaggageRestriction
 }
@@ -65,6 +76,18 @@ func TestConfigManager(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, api_v2.SamplingStrategyResponse{}, *r)
 	})
+	t.Run("GetAttributeStrategiesUnsupported", func(t *testing.T) {
+		r, err := mgr.GetAttributeStrategies(context.Background(), "foo")
+		require.NoError(t, err)
+		assert.Empty(t, r)
+	})
+	t.Run("GetAttributeStrategies", func(t *testing.T) {
+		expResp := []samplingstrategy.AttributeStrategy{{Key: "tenant", Value: "vip", SamplingRate: 1}}
+		mgr := &ConfigManager{SamplingProvider: &mockAttributeProvider{attributeStrategies: expResp}}
+		r, err := mgr.GetAttributeStrategies(context.Background(), "foo")
+		require.NoError(t, err)
+		assert.Equal(t, expResp, r)
+	})
 	t.Run("GetBaggageRestrictions", func(t *testing.T) {
 		expResp := []*baggage.BaggageRestriction{}
 		bgm.baggageResponse = expResp
@@ -16,6 +16,7 @@
 package clientcfghttp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,13 +26,21 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/jaegertracing/jaeger/cmd/agent/app/configmanager"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	p2json "github.com/jaegertracing/jaeger/model/converter/json"
 	t2p "github.com/jaegertracing/jaeger/model/converter/thrift/jaeger"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
-const mimeTypeApplicationJSON = "application/json"
+const (
+	mimeTypeApplicationJSON = "application/json"
+
+	// attributeSamplingParam is the query parameter of the clients requesting the attribute strategies.
+	// The strategies are only returned on request, since the strict JSON decoders, like jsonpb,
+	// reject the responses with unknown fields.
+	attributeSamplingParam = "attributeSampling"
+)
 
 var errBadRequest = errors.New("bad request")
 
@@ -148,6 +157,13 @@ func (h *HTTPHandler) serveSamplingHTTP(
 		http.Error(w, "cannot marshall to JSON", http.StatusInternalServerError)
 		return
 	}
+	if r.URL.Query().Get(attributeSamplingParam) == "true" {
+		if jsonBytes, err = h.addAttributeSampling(r.Context(), service, jsonBytes); err != nil {
+			h.metrics.CollectorProxyFailures.Inc(1)
+			http.Error(w, fmt.Sprintf("collector error: %+v", err), http.StatusInternalServerError)
+			return
+		}
+	}
 	if err = h.writeJSON(w, jsonBytes); err != nil {
 		return
 	}
@@ -179,6 +195,45 @@ func (h *HTTPHandler) encodeProto(strategy *api_v2.SamplingStrategyResponse) ([]
 	return []byte(str), nil
 }
 
+// attributeSamplingStrategy is the JSON of a samplingstrategy.AttributeStrategy in the sampling responses.
+type attributeSamplingStrategy struct {
+	Key                   string                        `json:"key"`
+	Value                 string                        `json:"value"`
+	ProbabilisticSampling probabilisticSamplingStrategy `json:"probabilisticSampling"`
+}
+
+type probabilisticSamplingStrategy struct {
+	SamplingRate float64 `json:"samplingRate"`
+}
+
+// addAttributeSampling adds the attribute strategies of the service, if any, to the JSON of its sampling strategy
+// in the attributeSampling field.
+func (h *HTTPHandler) addAttributeSampling(ctx context.Context, service string, jsonBytes []byte) ([]byte, error) {
+	p, ok := h.params.ConfigManager.(samplingstrategy.AttributeProvider)
+	if !ok {
+		return jsonBytes, nil
+	}
+	strategies, err := p.GetAttributeStrategies(ctx, service)
+	if err != nil || len(strategies) == 0 {
+		return jsonBytes, err
+	}
+	attributeSampling := make([]attributeSamplingStrategy, len(strategies))
+	for i, s := range strategies {
+		attributeSampling[i] = attributeSamplingStrategy{
+			Key:                   s.Key,
+			Value:                 s.Value,
+			ProbabilisticSampling: probabilisticSamplingStrategy{SamplingRate: s.SamplingRate},
+		}
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return nil, err
+	}
+	// NB. it's literally impossible for this Marshal to fail
+	fields["attributeSampling"], _ = json.Marshal(attributeSampling)
+	return json.Marshal(fields)
+}
+
 func (h *HTTPHandler) serveBaggageHTTP(w http.ResponseWriter, r *http.Request) {
 	service, err := h.serviceFromRequest(w, r)
 	if err != nil {
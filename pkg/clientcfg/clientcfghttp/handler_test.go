@@ -28,6 +28,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	p2json "github.com/jaegertracing/jaeger/model/converter/json"
 	tSampling092 "github.com/jaegertracing/jaeger/pkg/clientcfg/clientcfghttp/thrift-0.9.2"
@@ -146,6 +147,72 @@ func testHTTPHandler(t *testing.T, basePath string) {
 	})
 }
 
+func TestHTTPHandlerAttributeSampling(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	samplingProvider := &mockAttributeProvider{
+		mockSamplingProvider: mockSamplingProvider{samplingResponse: probabilistic(0.001)},
+		attributeStrategies:  []samplingstrategy.AttributeStrategy{{Key: "tenant", Value: "vip", SamplingRate: 1}},
+	}
+	handler := NewHTTPHandler(HTTPHandlerParams{
+		ConfigManager:          &ConfigManager{SamplingProvider: samplingProvider},
+		MetricsFactory:         metricsFactory,
+		LegacySamplingEndpoint: true,
+	})
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	get := func(t *testing.T, url string) []byte {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return body
+	}
+	const attributeSampling = `"attributeSampling":[{"key":"tenant","value":"vip","probabilisticSampling":{"samplingRate":1}}]`
+	tests := []struct {
+		endpoint  string
+		expOutput string
+	}{
+		{
+			endpoint:  "/",
+			expOutput: `{` + attributeSampling + `,"probabilisticSampling":{"samplingRate":0.001},"strategyType":0}`,
+		},
+		{
+			endpoint:  "/sampling",
+			expOutput: `{` + attributeSampling + `,"probabilisticSampling":{"samplingRate":0.001},"strategyType":"PROBABILISTIC"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run("endpoint="+test.endpoint, func(t *testing.T) {
+			body := get(t, server.URL+test.endpoint+"?service=Y&attributeSampling=true")
+			assert.Equal(t, test.expOutput, string(body))
+
+			// the clients not requesting the attribute strategies get the usual response
+			body = get(t, server.URL+test.endpoint+"?service=Y")
+			assert.NotContains(t, string(body), "attributeSampling")
+		})
+	}
+
+	objResp, err := p2json.SamplingStrategyResponseFromJSON(get(t, server.URL+"/sampling?service=Y"))
+	require.NoError(t, err)
+	assert.EqualValues(t, samplingProvider.samplingResponse, objResp)
+
+	t.Run("attribute strategies error", func(t *testing.T) {
+		samplingProvider.err = errors.New("no attribute strategies")
+		resp, err := http.Get(server.URL + "/sampling?service=Y&attributeSampling=true")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+			Name: "http-server.errors", Tags: map[string]string{"source": "collector-proxy", "status": "5xx"}, Value: 1,
+		})
+	})
+}
+
 func TestHTTPHandlerErrors(t *testing.T) {
 	testCases := []struct {
 		description          string
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpccfg
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	maxSendMessageSize           = ".max-send-message-size"
+	maxConcurrentStreams         = ".max-concurrent-streams"
+	keepaliveTime                = ".keepalive.time"
+	keepaliveTimeout             = ".keepalive.timeout"
+	keepaliveMinTime             = ".keepalive.min-time"
+	keepalivePermitWithoutStream = ".keepalive.permit-without-stream"
+	disableReflection            = ".disable-reflection"
+)
+
+// ServerFlagsConfig describes which CLI flags for a gRPC server should be generated.
+type ServerFlagsConfig struct {
+	Prefix string
+}
+
+// AddFlags adds flags for the gRPC server to the FlagSet.
+func (c ServerFlagsConfig) AddFlags(flags *flag.FlagSet) {
+	flags.Int(c.Prefix+maxSendMessageSize, 0, "The maximum size of the messages sent by the gRPC server; set to 0 for the gRPC default")
+	flags.Uint(c.Prefix+maxConcurrentStreams, 0, "The maximum number of concurrent streams of each gRPC connection; set to 0 for no limit")
+	flags.Duration(c.Prefix+keepaliveTime, 0, "The idle time after which the gRPC server pings the client to check the connection; set to 0s for the gRPC default. See https://pkg.go.dev/google.golang.org/grpc/keepalive#ServerParameters")
+	flags.Duration(c.Prefix+keepaliveTimeout, 0, "How long the gRPC server waits for the answer to a keepalive ping before closing the connection; set to 0s for the gRPC default. See https://pkg.go.dev/google.golang.org/grpc/keepalive#ServerParameters")
+	flags.Duration(c.Prefix+keepaliveMinTime, 0, "The minimum time between the keepalive pings of a client, the connections of clients pinging more often being closed; set to 0s for the gRPC default. See https://pkg.go.dev/google.golang.org/grpc/keepalive#EnforcementPolicy")
+	flags.Bool(c.Prefix+keepalivePermitWithoutStream, false, "Allow the clients to send keepalive pings when there is no active stream. See https://pkg.go.dev/google.golang.org/grpc/keepalive#EnforcementPolicy")
+	flags.Bool(c.Prefix+disableReflection, false, "Disable the gRPC reflection service")
+}
+
+// InitFromViper creates ServerOptions populated with values retrieved from Viper.
+func (c ServerFlagsConfig) InitFromViper(v *viper.Viper) (ServerOptions, error) {
+	var p ServerOptions
+	p.MaxSendMessageSize = v.GetInt(c.Prefix + maxSendMessageSize)
+	p.MaxConcurrentStreams = v.GetUint32(c.Prefix + maxConcurrentStreams)
+	p.KeepaliveTime = v.GetDuration(c.Prefix + keepaliveTime)
+	p.KeepaliveTimeout = v.GetDuration(c.Prefix + keepaliveTimeout)
+	p.KeepaliveMinTime = v.GetDuration(c.Prefix + keepaliveMinTime)
+	p.KeepalivePermitWithoutStream = v.GetBool(c.Prefix + keepalivePermitWithoutStream)
+	p.DisableReflection = v.GetBool(c.Prefix + disableReflection)
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("invalid gRPC server options of %s: %w", c.Prefix, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpccfg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestServerFlags(t *testing.T) {
+	flagCfg := ServerFlagsConfig{Prefix: "prefix"}
+	v, command := config.Viperize(flagCfg.AddFlags)
+	err := command.ParseFlags([]string{
+		"--prefix.max-send-message-size=1024",
+		"--prefix.max-concurrent-streams=10",
+		"--prefix.keepalive.time=1m",
+		"--prefix.keepalive.timeout=5s",
+		"--prefix.keepalive.min-time=30s",
+		"--prefix.keepalive.permit-without-stream=true",
+		"--prefix.disable-reflection=true",
+	})
+	require.NoError(t, err)
+
+	opts, err := flagCfg.InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, ServerOptions{
+		MaxSendMessageSize:           1024,
+		MaxConcurrentStreams:         10,
+		KeepaliveTime:                time.Minute,
+		KeepaliveTimeout:             5 * time.Second,
+		KeepaliveMinTime:             30 * time.Second,
+		KeepalivePermitWithoutStream: true,
+		DisableReflection:            true,
+	}, opts)
+}
+
+func TestServerFlagsDefaults(t *testing.T) {
+	flagCfg := ServerFlagsConfig{Prefix: "prefix"}
+	v, command := config.Viperize(flagCfg.AddFlags)
+	require.NoError(t, command.ParseFlags(nil))
+
+	opts, err := flagCfg.InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, ServerOptions{}, opts)
+}
+
+func TestServerFlagsInvalid(t *testing.T) {
+	flagCfg := ServerFlagsConfig{Prefix: "prefix"}
+	v, command := config.Viperize(flagCfg.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--prefix.keepalive.time=-1s"}))
+
+	_, err := flagCfg.InitFromViper(v)
+	require.ErrorContains(t, err, "invalid gRPC server options of prefix: the keepalive durations cannot be negative")
+}
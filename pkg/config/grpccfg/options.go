@@ -0,0 +1,80 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpccfg
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ServerOptions describes the tuning of a gRPC server, the zero value keeping the gRPC defaults.
+type ServerOptions struct {
+	// MaxSendMessageSize is the maximum size of the messages sent by the server, 0 means the gRPC default.
+	MaxSendMessageSize int
+	// MaxConcurrentStreams is the maximum number of concurrent streams of each connection, 0 means no limit.
+	MaxConcurrentStreams uint32
+	// KeepaliveTime is the idle time after which the server pings the client, 0 means the gRPC default.
+	// See gRPC's keepalive.ServerParameters#Time.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is how long the server waits for the answer to a ping before closing the connection,
+	// 0 means the gRPC default. See gRPC's keepalive.ServerParameters#Timeout.
+	KeepaliveTimeout time.Duration
+	// KeepaliveMinTime is the minimum time between the pings of a client, the connection of clients pinging
+	// more often being closed. See gRPC's keepalive.EnforcementPolicy#MinTime.
+	KeepaliveMinTime time.Duration
+	// KeepalivePermitWithoutStream allows the clients to ping when there is no active stream.
+	// See gRPC's keepalive.EnforcementPolicy#PermitWithoutStream.
+	KeepalivePermitWithoutStream bool
+	// DisableReflection turns off the gRPC reflection service.
+	DisableReflection bool
+}
+
+// Validate checks that the options are consistent.
+func (o ServerOptions) Validate() error {
+	var errs []error
+	if o.MaxSendMessageSize < 0 {
+		errs = append(errs, errors.New("the maximum send message size cannot be negative"))
+	}
+	if o.KeepaliveTime < 0 || o.KeepaliveTimeout < 0 || o.KeepaliveMinTime < 0 {
+		errs = append(errs, errors.New("the keepalive durations cannot be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// GRPCServerOptions returns the gRPC server options implementing o, params being completed
+// with the keepalive time and timeout.
+func (o ServerOptions) GRPCServerOptions(params keepalive.ServerParameters) []grpc.ServerOption {
+	params.Time = o.KeepaliveTime
+	params.Timeout = o.KeepaliveTimeout
+	opts := []grpc.ServerOption{grpc.KeepaliveParams(params)}
+	if o.MaxSendMessageSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(o.MaxSendMessageSize))
+	}
+	if o.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(o.MaxConcurrentStreams))
+	}
+	if o.KeepaliveMinTime > 0 || o.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             o.KeepaliveMinTime,
+			PermitWithoutStream: o.KeepalivePermitWithoutStream,
+		}))
+	}
+	return opts
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, so that the options are logged at startup.
+func (o ServerOptions) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("max-send-message-size", o.MaxSendMessageSize)
+	enc.AddUint32("max-concurrent-streams", o.MaxConcurrentStreams)
+	enc.AddDuration("keepalive.time", o.KeepaliveTime)
+	enc.AddDuration("keepalive.timeout", o.KeepaliveTimeout)
+	enc.AddDuration("keepalive.min-time", o.KeepaliveMinTime)
+	enc.AddBool("keepalive.permit-without-stream", o.KeepalivePermitWithoutStream)
+	enc.AddBool("reflection", !o.DisableReflection)
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpccfg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/keepalive"
+)
+
+func TestServerOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ServerOptions
+		errMsg string
+	}{
+		{name: "defaults", opts: ServerOptions{}},
+		{name: "negative send size", opts: ServerOptions{MaxSendMessageSize: -1}, errMsg: "the maximum send message size cannot be negative"},
+		{name: "negative keepalive", opts: ServerOptions{KeepaliveMinTime: -time.Second}, errMsg: "the keepalive durations cannot be negative"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.opts.Validate()
+			if test.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.errMsg)
+			}
+		})
+	}
+}
+
+func TestServerOptionsGRPCServerOptions(t *testing.T) {
+	params := keepalive.ServerParameters{MaxConnectionAge: time.Minute}
+	assert.Len(t, ServerOptions{}.GRPCServerOptions(params), 1)
+	assert.Len(t, ServerOptions{
+		MaxSendMessageSize:   1024,
+		MaxConcurrentStreams: 10,
+		KeepaliveTime:        time.Minute,
+		KeepaliveTimeout:     time.Second,
+		KeepaliveMinTime:     time.Second,
+	}.GRPCServerOptions(params), 4)
+	assert.Len(t, ServerOptions{KeepalivePermitWithoutStream: true}.GRPCServerOptions(params), 2)
+}
+
+func TestServerOptionsLogging(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	zap.New(core).Info("gRPC server", zap.Object("options", ServerOptions{MaxConcurrentStreams: 10, DisableReflection: true}))
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()["options"].(map[string]any)
+	assert.Equal(t, uint32(10), fields["max-concurrent-streams"])
+	assert.Equal(t, false, fields["reflection"])
+}
@@ -31,11 +31,13 @@ const (
 	tlsKey            = tlsPrefix + ".key"
 	tlsServerName     = tlsPrefix + ".server-name"
 	tlsClientCA       = tlsPrefix + ".client-ca"
+	tlsClientAuth     = tlsPrefix + ".client-auth"
 	tlsSkipHostVerify = tlsPrefix + ".skip-host-verify"
 	tlsCipherSuites   = tlsPrefix + ".cipher-suites"
 	tlsMinVersion     = tlsPrefix + ".min-version"
 	tlsMaxVersion     = tlsPrefix + ".max-version"
 	tlsReloadInterval = tlsPrefix + ".reload-interval"
+	tlsALPNProtocols  = tlsPrefix + ".alpn-protocols"
 )
 
 // ClientFlagsConfig describes which CLI flags for TLS client should be generated.
@@ -47,6 +49,8 @@ type ClientFlagsConfig struct {
 type ServerFlagsConfig struct {
 	Prefix                   string
 	EnableCertReloadInterval bool
+	// EnableALPNProtocols adds the flag of the ALPN protocols of a gRPC server
+	EnableALPNProtocols bool
 }
 
 // AddFlags adds flags for TLS to the FlagSet.
@@ -57,6 +61,9 @@ func (c ClientFlagsConfig) AddFlags(flags *flag.FlagSet) {
 	flags.String(c.Prefix+tlsKey, "", "Path to a TLS Private Key file, used to identify this process to the remote server(s)")
 	flags.String(c.Prefix+tlsServerName, "", "Override the TLS server name we expect in the certificate of the remote server(s)")
 	flags.Bool(c.Prefix+tlsSkipHostVerify, false, "(insecure) Skip server's certificate chain and host name verification")
+	flags.String(c.Prefix+tlsCipherSuites, "", "Comma-separated list of cipher suites for the client, values are from tls package constants (https://golang.org/pkg/crypto/tls/#pkg-constants).")
+	flags.String(c.Prefix+tlsMinVersion, "", "Minimum TLS version supported, "+DefaultMinVersion+" if unset (Possible values: 1.0, 1.1, 1.2, 1.3)")
+	flags.String(c.Prefix+tlsMaxVersion, "", "Maximum TLS version supported (Possible values: 1.0, 1.1, 1.2, 1.3)")
 }
 
 // AddFlags adds flags for TLS to the FlagSet.
@@ -66,11 +73,15 @@ func (c ServerFlagsConfig) AddFlags(flags *flag.FlagSet) {
 	flags.String(c.Prefix+tlsKey, "", "Path to a TLS Private Key file, used to identify this server to clients")
 	flags.String(c.Prefix+tlsClientCA, "", "Path to a TLS CA (Certification Authority) file used to verify certificates presented by clients (if unset, all clients are permitted)")
 	flags.String(c.Prefix+tlsCipherSuites, "", "Comma-separated list of cipher suites for the server, values are from tls package constants (https://golang.org/pkg/crypto/tls/#pkg-constants).")
-	flags.String(c.Prefix+tlsMinVersion, "", "Minimum TLS version supported (Possible values: 1.0, 1.1, 1.2, 1.3)")
+	flags.String(c.Prefix+tlsClientAuth, "", "The policy for the certificates of the clients: "+ClientAuthNone+", "+ClientAuthRequest+" (verify the certificates presented) or "+ClientAuthRequireAndVerify+"; if unset, "+ClientAuthRequireAndVerify+" when "+c.Prefix+tlsClientCA+" is set and "+ClientAuthNone+" otherwise")
+	flags.String(c.Prefix+tlsMinVersion, "", "Minimum TLS version supported, "+DefaultMinVersion+" if unset (Possible values: 1.0, 1.1, 1.2, 1.3)")
 	flags.String(c.Prefix+tlsMaxVersion, "", "Maximum TLS version supported (Possible values: 1.0, 1.1, 1.2, 1.3)")
 	if c.EnableCertReloadInterval {
 		flags.Duration(c.Prefix+tlsReloadInterval, 0, "The duration after which the certificate will be reloaded (0s means will not be reloaded)")
 	}
+	if c.EnableALPNProtocols {
+		flags.String(c.Prefix+tlsALPNProtocols, GRPCALPNProtocol, "Comma-separated list of the protocols offered in the TLS ALPN negotiation, in order of preference; gRPC requires "+GRPCALPNProtocol)
+	}
 }
 
 // InitFromViper creates tls.Config populated with values retrieved from Viper.
@@ -82,6 +93,11 @@ func (c ClientFlagsConfig) InitFromViper(v *viper.Viper) (Options, error) {
 	p.KeyPath = v.GetString(c.Prefix + tlsKey)
 	p.ServerName = v.GetString(c.Prefix + tlsServerName)
 	p.SkipHostVerify = v.GetBool(c.Prefix + tlsSkipHostVerify)
+	if s := v.GetString(c.Prefix + tlsCipherSuites); s != "" {
+		p.CipherSuites = strings.Split(stripWhiteSpace(s), ",")
+	}
+	p.MinVersion = v.GetString(c.Prefix + tlsMinVersion)
+	p.MaxVersion = v.GetString(c.Prefix + tlsMaxVersion)
 
 	if !p.Enabled {
 		var empty Options
@@ -100,18 +116,28 @@ func (c ServerFlagsConfig) InitFromViper(v *viper.Viper) (Options, error) {
 	p.CertPath = v.GetString(c.Prefix + tlsCert)
 	p.KeyPath = v.GetString(c.Prefix + tlsKey)
 	p.ClientCAPath = v.GetString(c.Prefix + tlsClientCA)
+	p.ClientAuthType = v.GetString(c.Prefix + tlsClientAuth)
 	if s := v.GetString(c.Prefix + tlsCipherSuites); s != "" {
 		p.CipherSuites = strings.Split(stripWhiteSpace(v.GetString(c.Prefix+tlsCipherSuites)), ",")
 	}
 	p.MinVersion = v.GetString(c.Prefix + tlsMinVersion)
 	p.MaxVersion = v.GetString(c.Prefix + tlsMaxVersion)
 	p.ReloadInterval = v.GetDuration(c.Prefix + tlsReloadInterval)
+	var alpnProtocols string
+	if c.EnableALPNProtocols {
+		alpnProtocols = stripWhiteSpace(v.GetString(c.Prefix + tlsALPNProtocols))
+	}
 
 	if !p.Enabled {
 		var empty Options
-		if !reflect.DeepEqual(&p, &empty) {
+		// the ALPN protocols have a default value
+		if !reflect.DeepEqual(&p, &empty) || (alpnProtocols != "" && alpnProtocols != GRPCALPNProtocol) {
 			return p, fmt.Errorf("%s.tls.* options cannot be used when %s is false", c.Prefix, c.Prefix+tlsEnabled)
 		}
+		return p, nil
+	}
+	if alpnProtocols != "" {
+		p.ALPNProtocols = strings.Split(alpnProtocols, ",")
 	}
 
 	return p, nil
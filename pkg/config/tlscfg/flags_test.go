@@ -33,6 +33,9 @@ func TestClientFlags(t *testing.T) {
 		"--prefix.tls.key=key-file",
 		"--prefix.tls.server-name=HAL1",
 		"--prefix.tls.skip-host-verify=true",
+		"--prefix.tls.cipher-suites=TLS_AES_256_GCM_SHA384, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+		"--prefix.tls.min-version=1.2",
+		"--prefix.tls.max-version=1.3",
 	}
 
 	tests := []struct {
@@ -66,6 +69,9 @@ func TestClientFlags(t *testing.T) {
 				KeyPath:        "key-file",
 				ServerName:     "HAL1",
 				SkipHostVerify: true,
+				CipherSuites:   []string{"TLS_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+				MinVersion:     "1.2",
+				MaxVersion:     "1.3",
 			}, tlsOpts)
 		})
 	}
@@ -80,6 +86,7 @@ func TestServerFlags(t *testing.T) {
 		"--prefix.tls.cipher-suites=TLS_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
 		"--prefix.tls.min-version=1.2",
 		"--prefix.tls.max-version=1.3",
+		"--prefix.tls.client-auth=request",
 	}
 
 	tests := []struct {
@@ -110,13 +117,14 @@ func TestServerFlags(t *testing.T) {
 			tlsOpts, err := flagCfg.InitFromViper(v)
 			require.NoError(t, err)
 			assert.Equal(t, Options{
-				Enabled:      true,
-				CertPath:     "cert-file",
-				KeyPath:      "key-file",
-				ClientCAPath: test.file,
-				CipherSuites: []string{"TLS_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA"},
-				MinVersion:   "1.2",
-				MaxVersion:   "1.3",
+				Enabled:        true,
+				CertPath:       "cert-file",
+				KeyPath:        "key-file",
+				ClientCAPath:   test.file,
+				ClientAuthType: ClientAuthRequest,
+				CipherSuites:   []string{"TLS_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA"},
+				MinVersion:     "1.2",
+				MaxVersion:     "1.3",
 			}, tlsOpts)
 		})
 	}
@@ -164,11 +172,15 @@ func TestFailedTLSFlags(t *testing.T) {
 		".key=blah",
 		".server-name=blah",
 		".skip-host-verify=true",
+		".cipher-suites=blah",
+		".min-version=1.2",
+		".max-version=1.3",
 	}
 	serverTests := []string{
 		".cert=blah",
 		".key=blah",
 		".client-ca=blah",
+		".client-auth=none",
 		".cipher-suites=blah",
 		".min-version=1.1",
 		".max-version=1.3",
@@ -221,3 +233,34 @@ func TestFailedTLSFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestServerALPNProtocols(t *testing.T) {
+	flagCfg := ServerFlagsConfig{Prefix: "prefix", EnableALPNProtocols: true}
+
+	v, command := config.Viperize(flagCfg.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--prefix.tls.enabled=true"}))
+	tlsOpts, err := flagCfg.InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, []string{GRPCALPNProtocol}, tlsOpts.ALPNProtocols)
+
+	v, command = config.Viperize(flagCfg.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--prefix.tls.enabled=true", "--prefix.tls.alpn-protocols=h2, x-proxy"}))
+	tlsOpts, err = flagCfg.InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"h2", "x-proxy"}, tlsOpts.ALPNProtocols)
+
+	// the default protocols do not require TLS
+	v, command = config.Viperize(flagCfg.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	tlsOpts, err = flagCfg.InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, Options{}, tlsOpts)
+
+	v, command = config.Viperize(flagCfg.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--prefix.tls.alpn-protocols=x-proxy"}))
+	_, err = flagCfg.InitFromViper(v)
+	require.EqualError(t, err, "prefix.tls.* options cannot be used when prefix.tls.enabled is false")
+
+	_, command = config.Viperize(ServerFlagsConfig{Prefix: "prefix"}.AddFlags)
+	require.ErrorContains(t, command.ParseFlags([]string{"--prefix.tls.alpn-protocols=h2"}), "unknown flag")
+}
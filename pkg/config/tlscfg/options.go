@@ -21,6 +21,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/collector/config/configtls"
@@ -38,11 +39,31 @@ type Options struct {
 	CipherSuites   []string      `mapstructure:"cipher_suites"`
 	MinVersion     string        `mapstructure:"min_version"`
 	MaxVersion     string        `mapstructure:"max_version"`
+	ClientAuthType string        `mapstructure:"client_auth_type"` // only for server-side TLS config
 	SkipHostVerify bool          `mapstructure:"skip_host_verify"`
 	ReloadInterval time.Duration `mapstructure:"reload_interval"`
-	certWatcher    *certWatcher
+	// ALPNProtocols are the application protocols offered in the ALPN negotiation, in order of preference
+	ALPNProtocols []string `mapstructure:"alpn_protocols"`
+	certWatcher   *certWatcher
 }
 
+// DefaultMinVersion is the minimum TLS version used when MinVersion is not set.
+const DefaultMinVersion = "1.2"
+
+// GRPCALPNProtocol is the ALPN protocol of gRPC, i.e. HTTP/2.
+const GRPCALPNProtocol = "h2"
+
+// The policies of the servers for the certificates of their clients, see Options.ClientAuthType.
+const (
+	// ClientAuthNone does not request client certificates.
+	ClientAuthNone = "none"
+	// ClientAuthRequest requests client certificates and verifies them against the client CA,
+	// but accepts the clients without certificates.
+	ClientAuthRequest = "request"
+	// ClientAuthRequireAndVerify rejects the clients without a certificate signed by the client CA.
+	ClientAuthRequireAndVerify = "require-and-verify"
+)
+
 var systemCertPool = x509.SystemCertPool // to allow overriding in unit test
 
 // Config loads TLS certificates and returns a TLS Config.
@@ -59,11 +80,13 @@ func (o *Options) Config(logger *zap.Logger) (*tls.Config, error) {
 		return nil, fmt.Errorf("failed to get cipher suite ids from cipher suite names: %w", err)
 	}
 
-	if o.MinVersion != "" {
-		minVersionId, err = VersionNameToID(o.MinVersion)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get minimum tls version: %w", err)
-		}
+	minVersion := o.MinVersion
+	if minVersion == "" {
+		minVersion = DefaultMinVersion
+	}
+	minVersionId, err = VersionNameToID(minVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get minimum tls version: %w", err)
 	}
 
 	if o.MaxVersion != "" {
@@ -73,10 +96,16 @@ func (o *Options) Config(logger *zap.Logger) (*tls.Config, error) {
 		}
 	}
 
-	if o.MinVersion != "" && o.MaxVersion != "" {
-		if minVersionId > maxVersionId {
-			return nil, fmt.Errorf("minimum tls version can't be greater than maximum tls version")
+	if o.MaxVersion != "" && minVersionId > maxVersionId {
+		if o.MinVersion == "" {
+			return nil, fmt.Errorf("maximum tls version %s is lower than the default minimum tls version %s, the minimum version must be set", o.MaxVersion, DefaultMinVersion)
 		}
+		return nil, fmt.Errorf("minimum tls version can't be greater than maximum tls version")
+	}
+
+	clientAuth, err := o.clientAuth()
+	if err != nil {
+		return nil, err
 	}
 
 	tlsCfg := &tls.Config{
@@ -86,6 +115,7 @@ func (o *Options) Config(logger *zap.Logger) (*tls.Config, error) {
 		CipherSuites:       cipherSuiteIds,
 		MinVersion:         minVersionId,
 		MaxVersion:         maxVersionId,
+		NextProtos:         o.ALPNProtocols,
 	}
 
 	if o.ClientCAPath != "" {
@@ -95,8 +125,8 @@ func (o *Options) Config(logger *zap.Logger) (*tls.Config, error) {
 			return nil, err
 		}
 		tlsCfg.ClientCAs = certPool
-		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
 	}
+	tlsCfg.ClientAuth = clientAuth
 
 	certWatcher, err := newCertWatcher(*o, logger, tlsCfg.RootCAs, tlsCfg.ClientCAs)
 	if err != nil {
@@ -120,6 +150,48 @@ func (o *Options) Config(logger *zap.Logger) (*tls.Config, error) {
 	return tlsCfg, nil
 }
 
+// GRPCConfig returns the TLS config of a gRPC server like Config, offering GRPCALPNProtocol in the ALPN
+// negotiation when ALPNProtocols is not set. It warns when ALPNProtocols omits GRPCALPNProtocol,
+// which gRPC then offers after the configured protocols.
+func (o *Options) GRPCConfig(logger *zap.Logger) (*tls.Config, error) {
+	tlsCfg, err := o.Config(logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(tlsCfg.NextProtos) == 0 {
+		tlsCfg.NextProtos = []string{GRPCALPNProtocol}
+	} else if !slices.Contains(tlsCfg.NextProtos, GRPCALPNProtocol) {
+		logger.Warn("The ALPN protocols of the gRPC server do not include "+GRPCALPNProtocol+", gRPC adds it after them",
+			zap.Strings("alpn_protocols", tlsCfg.NextProtos))
+	}
+	return tlsCfg, nil
+}
+
+// clientAuth returns the policy of the server for the client certificates. When ClientAuthType is not set,
+// the clients must present a certificate if ClientCAPath is set, and are not asked for one otherwise.
+func (o Options) clientAuth() (tls.ClientAuthType, error) {
+	switch o.ClientAuthType {
+	case "":
+		if o.ClientCAPath != "" {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest, ClientAuthRequireAndVerify:
+		if o.ClientCAPath == "" {
+			return tls.NoClientCert, fmt.Errorf("client auth type %q requires a client CA", o.ClientAuthType)
+		}
+		if o.ClientAuthType == ClientAuthRequest {
+			return tls.VerifyClientCertIfGiven, nil
+		}
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown client auth type %q, expected %s, %s or %s",
+			o.ClientAuthType, ClientAuthNone, ClientAuthRequest, ClientAuthRequireAndVerify)
+	}
+}
+
 func (o Options) loadCertPool() (*x509.CertPool, error) {
 	if len(o.CAPath) == 0 { // no truststore given, use SystemCertPool
 		certPool, err := loadSystemCertPool()
@@ -18,6 +18,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"path/filepath"
 	"testing"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 var testCertKeyLocation = "./testdata"
@@ -152,6 +154,27 @@ func TestOptionsToConfig(t *testing.T) {
 			},
 			expectError: "minimum tls version can't be greater than maximum tls version",
 		},
+		{
+			name: "should fail with TLS Max Version lower than the default TLS Min Version",
+			options: Options{
+				MaxVersion: "1.1",
+			},
+			expectError: "maximum tls version 1.1 is lower than the default minimum tls version 1.2, the minimum version must be set",
+		},
+		{
+			name: "should fail with invalid client auth type",
+			options: Options{
+				ClientAuthType: "optional",
+			},
+			expectError: `unknown client auth type "optional", expected none, request or require-and-verify`,
+		},
+		{
+			name: "should fail with client auth type without TLS Client CA",
+			options: Options{
+				ClientAuthType: ClientAuthRequireAndVerify,
+			},
+			expectError: `client auth type "require-and-verify" requires a client CA`,
+		},
 	}
 
 	for _, test := range tests {
@@ -189,6 +212,279 @@ func TestOptionsToConfig(t *testing.T) {
 	}
 }
 
+func TestOptionsToConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name               string
+		options            Options
+		expectedMinVersion uint16
+		expectedClientAuth tls.ClientAuthType
+	}{
+		{
+			name:               "defaults",
+			options:            Options{},
+			expectedMinVersion: tls.VersionTLS12,
+			expectedClientAuth: tls.NoClientCert,
+		},
+		{
+			name:               "client CA without client auth type",
+			options:            Options{ClientCAPath: testCertKeyLocation + "/example-CA-cert.pem"},
+			expectedMinVersion: tls.VersionTLS12,
+			expectedClientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name: "client CA with client auth type none",
+			options: Options{
+				ClientCAPath:   testCertKeyLocation + "/example-CA-cert.pem",
+				ClientAuthType: ClientAuthNone,
+			},
+			expectedMinVersion: tls.VersionTLS12,
+			expectedClientAuth: tls.NoClientCert,
+		},
+		{
+			name: "client CA with client auth type request",
+			options: Options{
+				ClientCAPath:   testCertKeyLocation + "/example-CA-cert.pem",
+				ClientAuthType: ClientAuthRequest,
+			},
+			expectedMinVersion: tls.VersionTLS12,
+			expectedClientAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name:               "explicit min version",
+			options:            Options{MinVersion: "1.0"},
+			expectedMinVersion: tls.VersionTLS10,
+			expectedClientAuth: tls.NoClientCert,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := test.options.Config(zap.NewNop())
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedMinVersion, cfg.MinVersion)
+			assert.Equal(t, test.expectedClientAuth, cfg.ClientAuth)
+			require.NoError(t, test.options.Close())
+		})
+	}
+}
+
+func TestOptionsToGRPCConfigALPNProtocols(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		expected []string
+		warning  bool
+	}{
+		{
+			name:     "default",
+			expected: []string{GRPCALPNProtocol},
+		},
+		{
+			name:     "custom protocols",
+			options:  Options{ALPNProtocols: []string{"x-proxy", "h2"}},
+			expected: []string{"x-proxy", "h2"},
+		},
+		{
+			name:     "without h2",
+			options:  Options{ALPNProtocols: []string{"x-proxy"}},
+			expected: []string{"x-proxy"},
+			warning:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger, logs := observer.New(zap.WarnLevel)
+			cfg, err := test.options.GRPCConfig(zap.New(logger))
+			require.NoError(t, err)
+			defer test.options.Close()
+			assert.Equal(t, test.expected, cfg.NextProtos)
+			assert.Equal(t, test.warning, logs.Len() == 1)
+		})
+	}
+
+	options := Options{CipherSuites: []string{"unknown"}}
+	_, err := options.GRPCConfig(zap.NewNop())
+	require.Error(t, err)
+}
+
+func TestOptionsToConfigALPNProtocols(t *testing.T) {
+	options := Options{ALPNProtocols: []string{"h2", "x-proxy"}}
+	cfg, err := options.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer options.Close()
+	assert.Equal(t, []string{"h2", "x-proxy"}, cfg.NextProtos)
+}
+
+// handshake connects a client to a server, returning the first error of the TLS handshake.
+func handshake(t *testing.T, serverOptions, clientOptions Options) error {
+	serverCfg, err := serverOptions.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer serverOptions.Close()
+	clientCfg, err := clientOptions.Config(zap.NewNop())
+	require.NoError(t, err)
+	defer clientOptions.Close()
+
+	listener, err := tls.Listen("tcp", "localhost:0", serverCfg)
+	require.NoError(t, err)
+	defer listener.Close()
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		err = conn.(*tls.Conn).Handshake()
+		if err == nil {
+			// the client reads this byte, to learn if the server accepted its certificate with TLS 1.3
+			_, err = conn.Write([]byte{1})
+		}
+		serverErr <- err
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientCfg)
+	if err == nil {
+		_, err = io.ReadFull(conn, make([]byte, 1))
+		conn.Close()
+	}
+	if sErr := <-serverErr; err == nil {
+		err = sErr
+	}
+	return err
+}
+
+func TestHandshake(t *testing.T) {
+	serverOptions := Options{
+		CertPath: testCertKeyLocation + "/example-server-cert.pem",
+		KeyPath:  testCertKeyLocation + "/example-server-key.pem",
+	}
+	clientOptions := Options{
+		CAPath:     testCertKeyLocation + "/example-CA-cert.pem",
+		ServerName: "example.com",
+	}
+	withClientCert := func(o Options) Options {
+		o.CertPath = testCertKeyLocation + "/example-client-cert.pem"
+		o.KeyPath = testCertKeyLocation + "/example-client-key.pem"
+		return o
+	}
+	tests := []struct {
+		name          string
+		server        func(Options) Options
+		client        func(Options) Options
+		expectFailure bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "TLS 1.1 client rejected by default",
+			client: func(o Options) Options {
+				o.MinVersion, o.MaxVersion = "1.0", "1.1"
+				return o
+			},
+			expectFailure: true,
+		},
+		{
+			name: "TLS 1.0 client rejected by default",
+			client: func(o Options) Options {
+				o.MinVersion, o.MaxVersion = "1.0", "1.0"
+				return o
+			},
+			expectFailure: true,
+		},
+		{
+			name: "TLS 1.1 client accepted when allowed",
+			server: func(o Options) Options {
+				o.MinVersion = "1.1"
+				o.CipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"}
+				return o
+			},
+			client: func(o Options) Options {
+				o.MinVersion, o.MaxVersion = "1.1", "1.1"
+				return o
+			},
+		},
+		{
+			name: "TLS 1.2 client rejected by TLS 1.3 server",
+			server: func(o Options) Options {
+				o.MinVersion = "1.3"
+				return o
+			},
+			client: func(o Options) Options {
+				o.MaxVersion = "1.2"
+				return o
+			},
+			expectFailure: true,
+		},
+		{
+			name: "cipher suite not allowed",
+			server: func(o Options) Options {
+				o.MaxVersion = "1.2"
+				o.CipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}
+				return o
+			},
+			client: func(o Options) Options {
+				o.CipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}
+				return o
+			},
+			expectFailure: true,
+		},
+		{
+			name: "client without certificate rejected",
+			server: func(o Options) Options {
+				o.ClientCAPath = testCertKeyLocation + "/example-CA-cert.pem"
+				o.ClientAuthType = ClientAuthRequireAndVerify
+				return o
+			},
+			expectFailure: true,
+		},
+		{
+			name: "client with certificate accepted",
+			server: func(o Options) Options {
+				o.ClientCAPath = testCertKeyLocation + "/example-CA-cert.pem"
+				o.ClientAuthType = ClientAuthRequireAndVerify
+				return o
+			},
+			client: withClientCert,
+		},
+		{
+			name: "client without certificate accepted when requested",
+			server: func(o Options) Options {
+				o.ClientCAPath = testCertKeyLocation + "/example-CA-cert.pem"
+				o.ClientAuthType = ClientAuthRequest
+				return o
+			},
+		},
+		{
+			name: "client with untrusted certificate rejected when requested",
+			server: func(o Options) Options {
+				o.ClientCAPath = testCertKeyLocation + "/wrong-CA-cert.pem"
+				o.ClientAuthType = ClientAuthRequest
+				return o
+			},
+			client:        withClientCert,
+			expectFailure: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, client := serverOptions, clientOptions
+			if test.server != nil {
+				server = test.server(server)
+			}
+			if test.client != nil {
+				client = test.client(client)
+			}
+			err := handshake(t, server, client)
+			if test.expectFailure {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestToOtelClientConfig(t *testing.T) {
 	testCases := []struct {
 		name     string
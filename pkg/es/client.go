@@ -31,8 +31,18 @@ type Client interface {
 	Search(indices ...string) SearchService
 	MultiSearch() MultiSearchService
 	DeleteIndex(index string) IndicesDeleteService
+	DeleteByQuery(indices ...string) DeleteByQueryService
 	io.Closer
 	GetVersion() uint
+	BulkState() BulkState
+}
+
+// BulkState describes the index requests handed to the bulk processor of a Client.
+type BulkState struct {
+	// Pending is the number of index requests not yet committed to Elasticsearch.
+	Pending int64
+	// Rejected is the number of index requests Elasticsearch rejected with 429 Too Many Requests.
+	Rejected int64
 }
 
 // IndicesExistsService is an abstraction for elastic.IndicesExistsService
@@ -75,6 +85,15 @@ type SearchService interface {
 	Do(ctx context.Context) (*elastic.SearchResult, error)
 }
 
+// DeleteByQueryService is an abstraction for elastic.DeleteByQueryService
+type DeleteByQueryService interface {
+	Query(query elastic.Query) DeleteByQueryService
+	IgnoreUnavailable(ignoreUnavailable bool) DeleteByQueryService
+	ProceedOnVersionConflict() DeleteByQueryService
+	Refresh(refresh string) DeleteByQueryService
+	Do(ctx context.Context) (*elastic.BulkIndexByScrollResponse, error)
+}
+
 // MultiSearchService is an abstraction for elastic.MultiSearchService
 type MultiSearchService interface {
 	Add(requests ...*elastic.SearchRequest) MultiSearchService
@@ -24,41 +24,124 @@ import (
 
 var _ ClusterAPI = (*ClusterClient)(nil)
 
-// ClusterClient is a client used to get ES cluster information
-type ClusterClient struct {
-	Client
+const (
+	// DistributionElasticsearch identifies an Elasticsearch cluster
+	DistributionElasticsearch = "elasticsearch"
+	// DistributionOpenSearch identifies an OpenSearch cluster
+	DistributionOpenSearch = "opensearch"
+)
+
+// ClusterVersion identifies the distribution and the major version of a cluster
+type ClusterVersion struct {
+	Distribution string
+	Major        uint
 }
 
-// Version returns the major version of the ES cluster
-func (c *ClusterClient) Version() (uint, error) {
-	type clusterInfo struct {
-		Version map[string]any `json:"version"`
-		TagLine string         `json:"tagline"`
+// IsOpenSearch returns true if the cluster is an OpenSearch cluster
+func (v ClusterVersion) IsOpenSearch() bool {
+	return v.Distribution == DistributionOpenSearch
+}
+
+// EsCompatibleVersion returns the major version of the Elasticsearch API spoken by the cluster.
+// All OpenSearch versions are compatible with the Elasticsearch 7.x API.
+func (v ClusterVersion) EsCompatibleVersion() uint {
+	if v.IsOpenSearch() {
+		return 7
 	}
-	body, err := c.request(elasticRequest{
-		endpoint: "",
-		method:   http.MethodGet,
-	})
+	return v.Major
+}
+
+// UseComposableTemplates returns true if index templates must be created with the
+// composable _index_template API instead of the legacy _template API.
+func (v ClusterVersion) UseComposableTemplates() bool {
+	if v.IsOpenSearch() {
+		return v.Major >= 2
+	}
+	return v.Major >= 8
+}
+
+// String returns the version in the format accepted by ParseClusterVersion
+func (v ClusterVersion) String() string {
+	if v.IsOpenSearch() {
+		return fmt.Sprintf("%s%d", DistributionOpenSearch, v.Major)
+	}
+	return strconv.FormatUint(uint64(v.Major), 10)
+}
+
+// ParseClusterVersion parses an explicitly configured version, either an Elasticsearch
+// major version like "7" or an OpenSearch major version like "opensearch2".
+func ParseClusterVersion(version string) (ClusterVersion, error) {
+	distribution := DistributionElasticsearch
+	number := version
+	if strings.HasPrefix(version, DistributionOpenSearch) {
+		distribution = DistributionOpenSearch
+		number = strings.TrimPrefix(version, DistributionOpenSearch)
+	}
+	major, err := strconv.ParseUint(number, 10, 32)
 	if err != nil {
-		return 0, err
+		return ClusterVersion{}, fmt.Errorf("invalid version '%s', expecting a major version number or opensearch<major>", version)
+	}
+	return ClusterVersion{Distribution: distribution, Major: uint(major)}, nil
+}
+
+// ParseClusterInfo detects the cluster version from the response of the root endpoint
+func ParseClusterInfo(body []byte) (ClusterVersion, error) {
+	type clusterInfo struct {
+		Version struct {
+			Number       any    `json:"number"`
+			Distribution string `json:"distribution"`
+		} `json:"version"`
+		TagLine string `json:"tagline"`
 	}
 	var info clusterInfo
-	if err = json.Unmarshal(body, &info); err != nil {
-		return 0, err
+	if err := json.Unmarshal(body, &info); err != nil {
+		return ClusterVersion{}, err
 	}
 
-	versionField := info.Version["number"]
+	versionField := info.Version.Number
 	versionNumber, isString := versionField.(string)
 	if !isString {
-		return 0, fmt.Errorf("invalid version format: %v", versionField)
+		return ClusterVersion{}, fmt.Errorf("invalid version format: %v", versionField)
 	}
 	version := strings.Split(versionNumber, ".")
 	major, err := strconv.ParseUint(version[0], 10, 32)
 	if err != nil {
-		return 0, fmt.Errorf("invalid version format: %s", version[0])
+		return ClusterVersion{}, fmt.Errorf("invalid version format: %s", version[0])
+	}
+
+	if info.Version.Distribution != DistributionOpenSearch && !strings.Contains(info.TagLine, "OpenSearch") {
+		return ClusterVersion{Distribution: DistributionElasticsearch, Major: uint(major)}, nil
+	}
+	// OpenSearch running in compatibility mode reports the Elasticsearch version 7.10.2 instead of
+	// its own, in which case the legacy OpenSearch 1.x behavior is the only safe choice.
+	if major >= 7 {
+		major = 1
+	}
+	return ClusterVersion{Distribution: DistributionOpenSearch, Major: uint(major)}, nil
+}
+
+// ClusterClient is a client used to get ES cluster information
+type ClusterClient struct {
+	Client
+}
+
+// ClusterVersion returns the distribution and the major version of the cluster
+func (c *ClusterClient) ClusterVersion() (ClusterVersion, error) {
+	body, err := c.request(elasticRequest{
+		endpoint: "",
+		method:   http.MethodGet,
+	})
+	if err != nil {
+		return ClusterVersion{}, err
 	}
-	if strings.Contains(info.TagLine, "OpenSearch") && (major == 1 || major == 2) {
-		return 7, nil
+	return ParseClusterInfo(body)
+}
+
+// Version returns the major version of the Elasticsearch API supported by the cluster
+func (c *ClusterClient) Version() (uint, error) {
+	version, err := c.ClusterVersion()
+	if err != nil {
+		return 0, err
 	}
-	return uint(major), nil
+	return version.EsCompatibleVersion(), nil
 }
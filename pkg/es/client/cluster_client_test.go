@@ -103,6 +103,45 @@ const opensearch2 = `
   }
 `
 
+const opensearch3 = `
+{
+	"name" : "opensearch-node1",
+	"cluster_name" : "opensearch-cluster",
+	"version" : {
+	  "distribution" : "opensearch",
+	  "number" : "3.0.0",
+	  "lucene_version" : "10.1.0",
+	  "minimum_wire_compatibility_version" : "2.19.0",
+	  "minimum_index_compatibility_version" : "2.0.0"
+	},
+	"tagline" : "The OpenSearch Project: https://opensearch.org/"
+  }
+`
+
+// opensearch2CustomTagline is returned by distributions that replace the default tagline
+const opensearch2CustomTagline = `
+{
+	"name" : "opensearch-node1",
+	"version" : {
+	  "distribution" : "opensearch",
+	  "number" : "2.11.1"
+	},
+	"tagline" : "Managed search service"
+  }
+`
+
+// opensearchCompatibilityMode is returned by OpenSearch with compatibility.override_main_response_version enabled
+const opensearchCompatibilityMode = `
+{
+	"name" : "opensearch-node1",
+	"version" : {
+	  "distribution" : "opensearch",
+	  "number" : "7.10.2"
+	},
+	"tagline" : "The OpenSearch Project: https://opensearch.org/"
+  }
+`
+
 const elasticsearch7 = `
 {
 	"name" : "elasticsearch-0",
@@ -191,6 +230,18 @@ func TestVersion(t *testing.T) {
 			response:       opensearch2,
 			expectedResult: 7,
 		},
+		{
+			name:           "success with opensearch 3",
+			responseCode:   http.StatusOK,
+			response:       opensearch3,
+			expectedResult: 7,
+		},
+		{
+			name:           "success with opensearch 2 and custom tagline",
+			responseCode:   http.StatusOK,
+			response:       opensearch2CustomTagline,
+			expectedResult: 7,
+		},
 		{
 			name:         "client error",
 			responseCode: http.StatusBadRequest,
@@ -244,3 +295,108 @@ func TestVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterVersion(t *testing.T) {
+	tests := []struct {
+		name                string
+		response            string
+		expected            ClusterVersion
+		esCompatibleVersion uint
+		composableTemplates bool
+	}{
+		{
+			name:                "elasticsearch 7",
+			response:            elasticsearch7,
+			expected:            ClusterVersion{Distribution: DistributionElasticsearch, Major: 7},
+			esCompatibleVersion: 7,
+		},
+		{
+			name:                "elasticsearch 8",
+			response:            elasticsearch8,
+			expected:            ClusterVersion{Distribution: DistributionElasticsearch, Major: 8},
+			esCompatibleVersion: 8,
+			composableTemplates: true,
+		},
+		{
+			name:                "opensearch 1",
+			response:            opensearch1,
+			expected:            ClusterVersion{Distribution: DistributionOpenSearch, Major: 1},
+			esCompatibleVersion: 7,
+		},
+		{
+			name:                "opensearch 2",
+			response:            opensearch2,
+			expected:            ClusterVersion{Distribution: DistributionOpenSearch, Major: 2},
+			esCompatibleVersion: 7,
+			composableTemplates: true,
+		},
+		{
+			name:                "opensearch 3",
+			response:            opensearch3,
+			expected:            ClusterVersion{Distribution: DistributionOpenSearch, Major: 3},
+			esCompatibleVersion: 7,
+			composableTemplates: true,
+		},
+		{
+			name:                "opensearch 2 with custom tagline",
+			response:            opensearch2CustomTagline,
+			expected:            ClusterVersion{Distribution: DistributionOpenSearch, Major: 2},
+			esCompatibleVersion: 7,
+			composableTemplates: true,
+		},
+		{
+			name:                "opensearch in compatibility mode",
+			response:            opensearchCompatibilityMode,
+			expected:            ClusterVersion{Distribution: DistributionOpenSearch, Major: 1},
+			esCompatibleVersion: 7,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+				res.WriteHeader(http.StatusOK)
+				res.Write([]byte(test.response))
+			}))
+			defer testServer.Close()
+
+			c := &ClusterClient{
+				Client: Client{
+					Client:   testServer.Client(),
+					Endpoint: testServer.URL,
+				},
+			}
+			version, err := c.ClusterVersion()
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+			assert.Equal(t, test.esCompatibleVersion, version.EsCompatibleVersion())
+			assert.Equal(t, test.composableTemplates, version.UseComposableTemplates())
+		})
+	}
+}
+
+func TestParseClusterVersion(t *testing.T) {
+	tests := []struct {
+		version     string
+		expected    ClusterVersion
+		errContains string
+	}{
+		{version: "7", expected: ClusterVersion{Distribution: DistributionElasticsearch, Major: 7}},
+		{version: "8", expected: ClusterVersion{Distribution: DistributionElasticsearch, Major: 8}},
+		{version: "opensearch2", expected: ClusterVersion{Distribution: DistributionOpenSearch, Major: 2}},
+		{version: "opensearch3", expected: ClusterVersion{Distribution: DistributionOpenSearch, Major: 3}},
+		{version: "opensearch", errContains: "invalid version 'opensearch'"},
+		{version: "eight", errContains: "invalid version 'eight'"},
+	}
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			version, err := ParseClusterVersion(test.version)
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, version)
+			assert.Equal(t, test.version, version.String())
+		})
+	}
+}
@@ -236,9 +236,9 @@ func (i *IndicesClient) aliasAction(action string, aliases []Alias) error {
 	return err
 }
 
-func (i IndicesClient) version() (uint, error) {
+func (i IndicesClient) version() (ClusterVersion, error) {
 	cl := ClusterClient{Client: i.Client}
-	return cl.Version()
+	return cl.ClusterVersion()
 }
 
 // CreateTemplate an ES index template
@@ -246,7 +246,7 @@ func (i IndicesClient) CreateTemplate(template, name string) error {
 	endpointFmt := "_template/%s"
 	if v, err := i.version(); err != nil {
 		return err
-	} else if v >= 8 {
+	} else if v.UseComposableTemplates() {
 		endpointFmt = "_index_template/%s"
 	}
 	_, err := i.request(elasticRequest{
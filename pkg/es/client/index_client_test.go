@@ -483,28 +483,50 @@ func TestClientCreateTemplate(t *testing.T) {
 	templateName := "jaeger-template"
 	templateContent := "template content"
 	tests := []struct {
-		name         string
-		versionResp  string
-		responseCode int
-		response     string
-		errContains  string
+		name             string
+		versionResp      string
+		responseCode     int
+		response         string
+		errContains      string
+		expectedEndpoint string
 	}{
 		{
-			name:         "success/v7",
-			versionResp:  elasticsearch7,
-			responseCode: http.StatusOK,
+			name:             "success/v7",
+			versionResp:      elasticsearch7,
+			responseCode:     http.StatusOK,
+			expectedEndpoint: "/_template/jaeger-template",
 		},
 		{
-			name:         "success/v8",
-			versionResp:  elasticsearch8,
-			responseCode: http.StatusOK,
+			name:             "success/v8",
+			versionResp:      elasticsearch8,
+			responseCode:     http.StatusOK,
+			expectedEndpoint: "/_index_template/jaeger-template",
 		},
 		{
-			name:         "client error",
-			versionResp:  elasticsearch7,
-			responseCode: http.StatusBadRequest,
-			response:     esErrResponse,
-			errContains:  "failed to create template: jaeger-template",
+			name:             "success/opensearch1",
+			versionResp:      opensearch1,
+			responseCode:     http.StatusOK,
+			expectedEndpoint: "/_template/jaeger-template",
+		},
+		{
+			name:             "success/opensearch2",
+			versionResp:      opensearch2,
+			responseCode:     http.StatusOK,
+			expectedEndpoint: "/_index_template/jaeger-template",
+		},
+		{
+			name:             "success/opensearch3",
+			versionResp:      opensearch3,
+			responseCode:     http.StatusOK,
+			expectedEndpoint: "/_index_template/jaeger-template",
+		},
+		{
+			name:             "client error",
+			versionResp:      elasticsearch7,
+			responseCode:     http.StatusBadRequest,
+			response:         esErrResponse,
+			errContains:      "failed to create template: jaeger-template",
+			expectedEndpoint: "/_template/jaeger-template",
 		},
 	}
 	for _, test := range tests {
@@ -515,7 +537,7 @@ func TestClientCreateTemplate(t *testing.T) {
 					res.Write([]byte(test.versionResp))
 					return
 				}
-				assert.True(t, strings.HasSuffix(req.URL.String(), "_template/jaeger-template"))
+				assert.Equal(t, test.expectedEndpoint, req.URL.String())
 				assert.Equal(t, http.MethodPut, req.Method)
 				assert.Equal(t, "Basic foobar", req.Header.Get("Authorization"))
 				body, err := io.ReadAll(req.Body)
@@ -538,7 +560,9 @@ func TestClientCreateTemplate(t *testing.T) {
 			if test.errContains != "" {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), test.errContains)
+				return
 			}
+			require.NoError(t, err)
 		})
 	}
 }
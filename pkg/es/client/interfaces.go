@@ -26,6 +26,7 @@ type IndexAPI interface {
 
 type ClusterAPI interface {
 	Version() (uint, error)
+	ClusterVersion() (ClusterVersion, error)
 }
 
 type IndexManagementLifecycleAPI interface {
@@ -7,13 +7,44 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	client "github.com/jaegertracing/jaeger/pkg/es/client"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // ClusterAPI is an autogenerated mock type for the ClusterAPI type
 type ClusterAPI struct {
 	mock.Mock
 }
 
+// ClusterVersion provides a mock function with given fields:
+func (_m *ClusterAPI) ClusterVersion() (client.ClusterVersion, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClusterVersion")
+	}
+
+	var r0 client.ClusterVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (client.ClusterVersion, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() client.ClusterVersion); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(client.ClusterVersion)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Version provides a mock function with given fields:
 func (_m *ClusterAPI) Version() (uint, error) {
 	ret := _m.Called()
@@ -24,7 +24,6 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -39,6 +38,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 	"github.com/jaegertracing/jaeger/pkg/es"
+	"github.com/jaegertracing/jaeger/pkg/es/client"
 	eswrapper "github.com/jaegertracing/jaeger/pkg/es/wrapper"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	storageMetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
@@ -84,8 +84,10 @@ type Configuration struct {
 	CreateIndexTemplates           bool           `mapstructure:"create_mappings"`
 	UseILM                         bool           `mapstructure:"use_ilm"`
 	Version                        uint           `mapstructure:"version"`
+	Distribution                   string         `mapstructure:"distribution"`
 	LogLevel                       string         `mapstructure:"log_level"`
 	SendGetBodyAs                  string         `mapstructure:"send_get_body_as"`
+	ZipkinCompatIndexPrefix        string         `mapstructure:"zipkin_compat_index_prefix"`
 }
 
 // TagsAsFields holds configuration for tag schema.
@@ -118,6 +120,7 @@ func NewClient(c *Configuration, logger *zap.Logger, metricsFactory metrics.Fact
 	}
 
 	sm := storageMetrics.NewWriteMetrics(metricsFactory, "bulk_index")
+	tracker := eswrapper.NewBulkTracker(metricsFactory)
 	m := sync.Map{}
 
 	bulkProc, err := rawClient.BulkProcessor().
@@ -125,6 +128,7 @@ func NewClient(c *Configuration, logger *zap.Logger, metricsFactory metrics.Fact
 			m.Store(id, time.Now())
 		}).
 		After(func(id int64, requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
+			tracker.Committed(requests, response, err)
 			start, ok := m.Load(id)
 			if !ok {
 				return
@@ -169,39 +173,52 @@ func NewClient(c *Configuration, logger *zap.Logger, metricsFactory metrics.Fact
 	}
 
 	if c.Version == 0 {
-		// Determine ElasticSearch Version
-		pingResult, _, err := rawClient.Ping(c.Servers[0]).Do(context.Background())
+		version, err := detectClusterVersion(rawClient)
 		if err != nil {
 			return nil, err
 		}
-		esVersion, err := strconv.Atoi(string(pingResult.Version.Number[0]))
-		if err != nil {
-			return nil, err
-		}
-		// OpenSearch is based on ES 7.x
-		if strings.Contains(pingResult.TagLine, "OpenSearch") {
-			if pingResult.Version.Number[0] == '1' {
-				logger.Info("OpenSearch 1.x detected, using ES 7.x index mappings")
-				esVersion = 7
-			}
-			if pingResult.Version.Number[0] == '2' {
-				logger.Info("OpenSearch 2.x detected, using ES 7.x index mappings")
-				esVersion = 7
-			}
+		if version.IsOpenSearch() {
+			logger.Info("OpenSearch detected", zap.Uint("version", version.Major),
+				zap.Bool("composable_templates", version.UseComposableTemplates()))
+		} else {
+			logger.Info("Elasticsearch detected", zap.Uint("version", version.Major))
 		}
-		logger.Info("Elasticsearch detected", zap.Int("version", esVersion))
-		c.Version = uint(esVersion)
+		c.Version = version.Major
+		c.Distribution = version.Distribution
 	}
+	clusterVersion := c.ClusterVersion()
+	esVersion := clusterVersion.EsCompatibleVersion()
 
 	var rawClientV8 *esV8.Client
-	if c.Version >= 8 {
+	if esVersion >= 8 {
 		rawClientV8, err = newElasticsearchV8(c, logger)
 		if err != nil {
 			return nil, fmt.Errorf("error creating v8 client: %w", err)
 		}
 	}
 
-	return eswrapper.WrapESClient(rawClient, bulkProc, c.Version, rawClientV8), nil
+	return eswrapper.WrapESClient(rawClient, bulkProc, tracker, esVersion, rawClientV8, clusterVersion.UseComposableTemplates()), nil
+}
+
+// detectClusterVersion reads the distribution and the version of the cluster from its root endpoint
+func detectClusterVersion(rawClient *elastic.Client) (client.ClusterVersion, error) {
+	resp, err := rawClient.PerformRequest(context.Background(), elastic.PerformRequestOptions{
+		Method: http.MethodGet,
+		Path:   "/",
+	})
+	if err != nil {
+		return client.ClusterVersion{}, err
+	}
+	return client.ParseClusterInfo(resp.Body)
+}
+
+// ClusterVersion returns the configured or detected distribution and major version of the cluster
+func (c *Configuration) ClusterVersion() client.ClusterVersion {
+	distribution := c.Distribution
+	if distribution == "" {
+		distribution = client.DistributionElasticsearch
+	}
+	return client.ClusterVersion{Distribution: distribution, Major: c.Version}
 }
 
 func newElasticsearchV8(c *Configuration, logger *zap.Logger) (*esV8.Client, error) {
@@ -219,19 +236,11 @@ func newElasticsearchV8(c *Configuration, logger *zap.Logger) (*esV8.Client, err
 }
 
 // ApplyDefaults copies settings from source unless its own value is non-zero.
+// The settings connecting to Elasticsearch are not copied, see ApplyConnection.
 func (c *Configuration) ApplyDefaults(source *Configuration) {
 	if len(c.RemoteReadClusters) == 0 {
 		c.RemoteReadClusters = source.RemoteReadClusters
 	}
-	if c.Username == "" {
-		c.Username = source.Username
-	}
-	if c.Password == "" {
-		c.Password = source.Password
-	}
-	if !c.Sniffer {
-		c.Sniffer = source.Sniffer
-	}
 	if c.MaxSpanAge == 0 {
 		c.MaxSpanAge = source.MaxSpanAge
 	}
@@ -265,9 +274,6 @@ func (c *Configuration) ApplyDefaults(source *Configuration) {
 	if c.BulkFlushInterval == 0 {
 		c.BulkFlushInterval = source.BulkFlushInterval
 	}
-	if !c.SnifferTLSEnabled {
-		c.SnifferTLSEnabled = source.SnifferTLSEnabled
-	}
 	if !c.Tags.AllAsFields {
 		c.Tags.AllAsFields = source.Tags.AllAsFields
 	}
@@ -291,6 +297,22 @@ func (c *Configuration) ApplyDefaults(source *Configuration) {
 	}
 }
 
+// ApplyConnection copies the settings connecting to Elasticsearch from source,
+// for a configuration using the same cluster as source.
+func (c *Configuration) ApplyConnection(source *Configuration) {
+	c.Servers = source.Servers
+	c.Username = source.Username
+	c.Password = source.Password
+	c.TokenFilePath = source.TokenFilePath
+	c.PasswordFilePath = source.PasswordFilePath
+	c.AllowTokenFromContext = source.AllowTokenFromContext
+	c.Sniffer = source.Sniffer
+	c.SnifferTLSEnabled = source.SnifferTLSEnabled
+	c.TLS = source.TLS
+	c.Version = source.Version
+	c.Distribution = source.Distribution
+}
+
 // GetIndexRolloverFrequencySpansDuration returns jaeger-span index rollover frequency duration
 func (c *Configuration) GetIndexRolloverFrequencySpansDuration() time.Duration {
 	return getIndexRolloverFrequencyDuration(c.IndexRolloverFrequencySpans)
@@ -17,6 +17,24 @@ type Client struct {
 	mock.Mock
 }
 
+// BulkState provides a mock function with given fields:
+func (_m *Client) BulkState() es.BulkState {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkState")
+	}
+
+	var r0 es.BulkState
+	if rf, ok := ret.Get(0).(func() es.BulkState); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(es.BulkState)
+	}
+
+	return r0
+}
+
 // Close provides a mock function with given fields:
 func (_m *Client) Close() error {
 	ret := _m.Called()
@@ -75,6 +93,32 @@ func (_m *Client) CreateTemplate(id string) es.TemplateCreateService {
 	return r0
 }
 
+// DeleteByQuery provides a mock function with given fields: indices
+func (_m *Client) DeleteByQuery(indices ...string) es.DeleteByQueryService {
+	_va := make([]interface{}, len(indices))
+	for _i := range indices {
+		_va[_i] = indices[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByQuery")
+	}
+
+	var r0 es.DeleteByQueryService
+	if rf, ok := ret.Get(0).(func(...string) es.DeleteByQueryService); ok {
+		r0 = rf(indices...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.DeleteByQueryService)
+		}
+	}
+
+	return r0
+}
+
 // DeleteIndex provides a mock function with given fields: index
 func (_m *Client) DeleteIndex(index string) es.IndicesDeleteService {
 	ret := _m.Called(index)
@@ -0,0 +1,146 @@
+// Copyright (c) The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// Run 'make generate-mocks' to regenerate.
+
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	es "github.com/jaegertracing/jaeger/pkg/es"
+	elastic "github.com/olivere/elastic"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeleteByQueryService is an autogenerated mock type for the DeleteByQueryService type
+type DeleteByQueryService struct {
+	mock.Mock
+}
+
+// Do provides a mock function with given fields: ctx
+func (_m *DeleteByQueryService) Do(ctx context.Context) (*elastic.BulkIndexByScrollResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Do")
+	}
+
+	var r0 *elastic.BulkIndexByScrollResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*elastic.BulkIndexByScrollResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *elastic.BulkIndexByScrollResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*elastic.BulkIndexByScrollResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IgnoreUnavailable provides a mock function with given fields: ignoreUnavailable
+func (_m *DeleteByQueryService) IgnoreUnavailable(ignoreUnavailable bool) es.DeleteByQueryService {
+	ret := _m.Called(ignoreUnavailable)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IgnoreUnavailable")
+	}
+
+	var r0 es.DeleteByQueryService
+	if rf, ok := ret.Get(0).(func(bool) es.DeleteByQueryService); ok {
+		r0 = rf(ignoreUnavailable)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.DeleteByQueryService)
+		}
+	}
+
+	return r0
+}
+
+// ProceedOnVersionConflict provides a mock function with given fields:
+func (_m *DeleteByQueryService) ProceedOnVersionConflict() es.DeleteByQueryService {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProceedOnVersionConflict")
+	}
+
+	var r0 es.DeleteByQueryService
+	if rf, ok := ret.Get(0).(func() es.DeleteByQueryService); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.DeleteByQueryService)
+		}
+	}
+
+	return r0
+}
+
+// Query provides a mock function with given fields: query
+func (_m *DeleteByQueryService) Query(query elastic.Query) es.DeleteByQueryService {
+	ret := _m.Called(query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Query")
+	}
+
+	var r0 es.DeleteByQueryService
+	if rf, ok := ret.Get(0).(func(elastic.Query) es.DeleteByQueryService); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.DeleteByQueryService)
+		}
+	}
+
+	return r0
+}
+
+// Refresh provides a mock function with given fields: refresh
+func (_m *DeleteByQueryService) Refresh(refresh string) es.DeleteByQueryService {
+	ret := _m.Called(refresh)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 es.DeleteByQueryService
+	if rf, ok := ret.Get(0).(func(string) es.DeleteByQueryService); ok {
+		r0 = rf(refresh)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(es.DeleteByQueryService)
+		}
+	}
+
+	return r0
+}
+
+// NewDeleteByQueryService creates a new instance of DeleteByQueryService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeleteByQueryService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeleteByQueryService {
+	mock := &DeleteByQueryService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package eswrapper
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/olivere/elastic"
+
+	"github.com/jaegertracing/jaeger/pkg/es"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// retryItemStatusCodes are the statuses of bulk response items the bulk processor retries by default.
+var retryItemStatusCodes = map[int]struct{}{
+	http.StatusRequestTimeout:      {},
+	http.StatusTooManyRequests:     {},
+	http.StatusServiceUnavailable:  {},
+	http.StatusInsufficientStorage: {},
+}
+
+// BulkTracker keeps count of the index requests handed to a bulk processor,
+// so that writers can tell when Elasticsearch is falling behind.
+type BulkTracker struct {
+	pending         atomic.Int64
+	rejected        atomic.Int64
+	rejectedCounter metrics.Counter
+}
+
+// NewBulkTracker creates a BulkTracker reporting rejected requests to metricsFactory.
+func NewBulkTracker(metricsFactory metrics.Factory) *BulkTracker {
+	return &BulkTracker{
+		rejectedCounter: metricsFactory.Counter(metrics.Options{Name: "bulk_index.rejected"}),
+	}
+}
+
+// Added records an index request handed to the bulk processor.
+func (t *BulkTracker) Added() {
+	t.pending.Add(1)
+}
+
+// Committed records the outcome of a bulk request, it is meant to be called
+// from the After callback of the bulk processor. Requests the bulk processor
+// keeps for its next commit remain pending.
+func (t *BulkTracker) Committed(requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
+	var requeued, rejected int64
+	switch {
+	case err == nil:
+	case errors.Is(err, elastic.ErrBulkItemRetry):
+		// the bulk processor re-adds the items failed with a retryable status
+		for _, item := range response.Items {
+			for _, result := range item {
+				if _, ok := retryItemStatusCodes[result.Status]; ok {
+					requeued++
+				}
+			}
+		}
+	default:
+		// a failed bulk call leaves its requests in the bulk processor
+		requeued = int64(len(requests))
+		if elastic.IsStatusCode(err, http.StatusTooManyRequests) {
+			rejected = requeued
+		}
+	}
+	if response != nil && response.Errors {
+		for _, item := range response.Items {
+			for _, result := range item {
+				if result.Status == http.StatusTooManyRequests {
+					rejected++
+				}
+			}
+		}
+	}
+	t.pending.Add(requeued - int64(len(requests)))
+	if rejected > 0 {
+		t.rejected.Add(rejected)
+		t.rejectedCounter.Inc(rejected)
+	}
+}
+
+// State returns the current state of the tracked bulk processor.
+func (t *BulkTracker) State() es.BulkState {
+	return es.BulkState{
+		Pending:  t.pending.Load(),
+		Rejected: t.rejected.Load(),
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package eswrapper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/es"
+)
+
+// bulkServer is a mock Elasticsearch answering the first rejectedBursts
+// bulk calls with 429 Too Many Requests.
+type bulkServer struct {
+	rejectedBursts atomic.Int32
+	itemStatus     int
+	calls          atomic.Int32
+}
+
+func (s *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version":{"number":"7.10.2"}}`))
+		return
+	}
+	s.calls.Add(1)
+	if s.rejectedBursts.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"type":"es_rejected_execution_exception"},"status":429}`))
+		return
+	}
+	var lines int
+	for scanner := bufio.NewScanner(r.Body); scanner.Scan(); {
+		lines++
+	}
+	status, errors := http.StatusCreated, false
+	if s.itemStatus != 0 {
+		status, errors = s.itemStatus, true
+	}
+	items := make([]string, lines/2)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"index":{"_index":"jaeger-span","status":%d}}`, status)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"took":1,"errors":%t,"items":[%s]}`, errors, strings.Join(items, ","))
+}
+
+func newTrackedClient(t *testing.T, srv *bulkServer, backoff elastic.Backoff) (ClientWrapper, *metricstest.Factory) {
+	server := httptest.NewServer(srv)
+	t.Cleanup(server.Close)
+	rawClient, err := elastic.NewClient(
+		elastic.SetURL(server.URL),
+		elastic.SetSniff(false),
+		elastic.SetHealthcheck(false),
+	)
+	require.NoError(t, err)
+	metricsFactory := metricstest.NewFactory(0)
+	t.Cleanup(metricsFactory.Stop)
+	tracker := NewBulkTracker(metricsFactory)
+	bulkProc, err := rawClient.BulkProcessor().
+		After(func(_ int64, requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
+			tracker.Committed(requests, response, err)
+		}).
+		BulkActions(2).
+		Backoff(backoff).
+		Do(context.Background())
+	require.NoError(t, err)
+	client := WrapESClient(rawClient, bulkProc, tracker, 7, nil, false)
+	t.Cleanup(func() { client.Close() })
+	return client, metricsFactory
+}
+
+func addSpans(client es.Client, count int) {
+	for i := 0; i < count; i++ {
+		client.Index().Index("jaeger-span").Type("span").BodyJson(map[string]int{"span": i}).Add()
+	}
+}
+
+func TestBulkTrackerRejectedBurst(t *testing.T) {
+	srv := &bulkServer{}
+	srv.rejectedBursts.Store(3)
+	client, metricsFactory := newTrackedClient(t, srv, elastic.NewConstantBackoff(50*time.Millisecond))
+
+	addSpans(client, 2)
+	assert.Equal(t, int64(2), client.BulkState().Pending, "requests are pending while Elasticsearch rejects them")
+
+	assert.Eventually(t, func() bool {
+		return client.BulkState().Pending == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(4), srv.calls.Load())
+	// the burst was absorbed by the retries of the bulk processor
+	assert.Equal(t, int64(0), client.BulkState().Rejected)
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "bulk_index.rejected", Value: 0})
+}
+
+func TestBulkTrackerRejectedRequest(t *testing.T) {
+	srv := &bulkServer{}
+	srv.rejectedBursts.Store(1)
+	client, metricsFactory := newTrackedClient(t, srv, elastic.StopBackoff{})
+
+	addSpans(client, 2)
+	assert.Eventually(t, func() bool {
+		return client.BulkState().Rejected == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	// the bulk processor keeps the rejected requests for its next commit
+	assert.Equal(t, int64(2), client.BulkState().Pending)
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "bulk_index.rejected", Value: 2})
+
+	addSpans(client, 1)
+	assert.Eventually(t, func() bool {
+		return client.BulkState().Pending == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestBulkTrackerRejectedItems(t *testing.T) {
+	srv := &bulkServer{itemStatus: http.StatusTooManyRequests}
+	client, metricsFactory := newTrackedClient(t, srv, elastic.StopBackoff{})
+
+	addSpans(client, 2)
+	assert.Eventually(t, func() bool {
+		return client.BulkState().Rejected == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(2), client.BulkState().Pending, "rejected items are re-added to the bulk processor")
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "bulk_index.rejected", Value: 2})
+}
+
+func TestBulkTrackerCommittedWithError(t *testing.T) {
+	tracker := NewBulkTracker(metricstest.NewFactory(0))
+	tracker.Added()
+	tracker.Added()
+	tracker.Committed(make([]elastic.BulkableRequest, 2), nil, nil)
+	assert.Equal(t, es.BulkState{}, tracker.State())
+
+	tracker.Added()
+	tracker.Committed(make([]elastic.BulkableRequest, 1), nil, assert.AnError)
+	assert.Equal(t, es.BulkState{Pending: 1}, tracker.State())
+}
+
+func TestBulkStateWithoutTracker(t *testing.T) {
+	assert.Equal(t, es.BulkState{}, ClientWrapper{}.BulkState())
+}
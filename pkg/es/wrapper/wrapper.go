@@ -18,6 +18,7 @@ package eswrapper
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	esV8 "github.com/elastic/go-elasticsearch/v8"
@@ -31,10 +32,12 @@ import (
 
 // ClientWrapper is a wrapper around elastic.Client
 type ClientWrapper struct {
-	client      *elastic.Client
-	bulkService *elastic.BulkProcessor
-	esVersion   uint
-	clientV8    *esV8.Client
+	client              *elastic.Client
+	bulkService         *elastic.BulkProcessor
+	bulkTracker         *BulkTracker
+	esVersion           uint
+	clientV8            *esV8.Client
+	composableTemplates bool
 }
 
 // GetVersion returns the ElasticSearch Version
@@ -42,13 +45,26 @@ func (c ClientWrapper) GetVersion() uint {
 	return c.esVersion
 }
 
+// BulkState returns the state of the bulk processor used for indexing.
+func (c ClientWrapper) BulkState() es.BulkState {
+	if c.bulkTracker == nil {
+		return es.BulkState{}
+	}
+	return c.bulkTracker.State()
+}
+
 // WrapESClient creates a ESClient out of *elastic.Client.
-func WrapESClient(client *elastic.Client, s *elastic.BulkProcessor, esVersion uint, clientV8 *esV8.Client) ClientWrapper {
+// When composableTemplates is set, index templates are created with the _index_template API
+// even if esVersion is lower than 8, which is needed for OpenSearch 2.x and newer.
+// The optional tracker is notified of every index request added to the bulk processor.
+func WrapESClient(client *elastic.Client, s *elastic.BulkProcessor, tracker *BulkTracker, esVersion uint, clientV8 *esV8.Client, composableTemplates bool) ClientWrapper {
 	return ClientWrapper{
-		client:      client,
-		bulkService: s,
-		esVersion:   esVersion,
-		clientV8:    clientV8,
+		client:              client,
+		bulkService:         s,
+		bulkTracker:         tracker,
+		esVersion:           esVersion,
+		clientV8:            clientV8,
+		composableTemplates: composableTemplates,
 	}
 }
 
@@ -75,13 +91,19 @@ func (c ClientWrapper) CreateTemplate(ttype string) es.TemplateCreateService {
 			templateName: ttype,
 		}
 	}
+	if c.composableTemplates {
+		return ComposableTemplateCreatorWrapper{
+			client:       c.client,
+			templateName: ttype,
+		}
+	}
 	return WrapESTemplateCreateService(c.client.IndexPutTemplate(ttype))
 }
 
 // Index calls this function to internal client.
 func (c ClientWrapper) Index() es.IndexService {
 	r := elastic.NewBulkIndexRequest()
-	return WrapESIndexService(r, c.bulkService, c.esVersion)
+	return WrapESIndexService(r, c.bulkService, c.esVersion).withTracker(c.bulkTracker)
 }
 
 // Search calls this function to internal client.
@@ -102,6 +124,11 @@ func (c ClientWrapper) MultiSearch() es.MultiSearchService {
 	return WrapESMultiSearchService(multiSearchService)
 }
 
+// DeleteByQuery calls this function to internal client.
+func (c ClientWrapper) DeleteByQuery(indices ...string) es.DeleteByQueryService {
+	return WrapESDeleteByQueryService(c.client.DeleteByQuery(indices...))
+}
+
 // Close closes ESClient and flushes all data to the storage.
 func (c ClientWrapper) Close() error {
 	c.client.Stop()
@@ -212,11 +239,42 @@ func (c TemplateCreatorWrapperV8) Do(context.Context) (*elastic.IndicesPutTempla
 
 // ---
 
+// ComposableTemplateCreatorWrapper implements es.TemplateCreateService using the composable
+// _index_template API, which the v6 client does not support natively.
+type ComposableTemplateCreatorWrapper struct {
+	client          *elastic.Client
+	templateName    string
+	templateMapping string
+}
+
+// Body adds mapping to the future request.
+func (c ComposableTemplateCreatorWrapper) Body(mapping string) es.TemplateCreateService {
+	cc := c // clone
+	cc.templateMapping = mapping
+	return cc
+}
+
+// Do executes Put Index Template command.
+func (c ComposableTemplateCreatorWrapper) Do(ctx context.Context) (*elastic.IndicesPutTemplateResponse, error) {
+	_, err := c.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: http.MethodPut,
+		Path:   "/_index_template/" + c.templateName,
+		Body:   c.templateMapping,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating index template %s: %w", c.templateName, err)
+	}
+	return nil, nil // no response expected by span writer
+}
+
+// ---
+
 // IndexServiceWrapper is a wrapper around elastic.ESIndexService.
 // See wrapper_nolint.go for more functions.
 type IndexServiceWrapper struct {
 	bulkIndexReq *elastic.BulkIndexRequest
 	bulkService  *elastic.BulkProcessor
+	bulkTracker  *BulkTracker
 	esVersion    uint
 }
 
@@ -225,21 +283,29 @@ func WrapESIndexService(indexService *elastic.BulkIndexRequest, bulkService *ela
 	return IndexServiceWrapper{bulkIndexReq: indexService, bulkService: bulkService, esVersion: esVersion}
 }
 
+func (i IndexServiceWrapper) withTracker(tracker *BulkTracker) IndexServiceWrapper {
+	i.bulkTracker = tracker
+	return i
+}
+
 // Index calls this function to internal service.
 func (i IndexServiceWrapper) Index(index string) es.IndexService {
-	return WrapESIndexService(i.bulkIndexReq.Index(index), i.bulkService, i.esVersion)
+	return WrapESIndexService(i.bulkIndexReq.Index(index), i.bulkService, i.esVersion).withTracker(i.bulkTracker)
 }
 
 // Type calls this function to internal service.
 func (i IndexServiceWrapper) Type(typ string) es.IndexService {
 	if i.esVersion >= 7 {
-		return WrapESIndexService(i.bulkIndexReq, i.bulkService, i.esVersion)
+		return i
 	}
-	return WrapESIndexService(i.bulkIndexReq.Type(typ), i.bulkService, i.esVersion)
+	return WrapESIndexService(i.bulkIndexReq.Type(typ), i.bulkService, i.esVersion).withTracker(i.bulkTracker)
 }
 
 // Add adds the request to bulk service
 func (i IndexServiceWrapper) Add() {
+	if i.bulkTracker != nil {
+		i.bulkTracker.Added()
+	}
 	i.bulkService.Add(i.bulkIndexReq)
 }
 
@@ -304,3 +370,38 @@ func (s MultiSearchServiceWrapper) Index(indices ...string) es.MultiSearchServic
 func (s MultiSearchServiceWrapper) Do(ctx context.Context) (*elastic.MultiSearchResult, error) {
 	return s.multiSearchService.Do(ctx)
 }
+
+// DeleteByQueryServiceWrapper is a wrapper around elastic.DeleteByQueryService
+type DeleteByQueryServiceWrapper struct {
+	deleteByQueryService *elastic.DeleteByQueryService
+}
+
+// WrapESDeleteByQueryService creates an ESDeleteByQueryService out of *elastic.DeleteByQueryService.
+func WrapESDeleteByQueryService(deleteByQueryService *elastic.DeleteByQueryService) DeleteByQueryServiceWrapper {
+	return DeleteByQueryServiceWrapper{deleteByQueryService: deleteByQueryService}
+}
+
+// Query calls this function to internal service.
+func (s DeleteByQueryServiceWrapper) Query(query elastic.Query) es.DeleteByQueryService {
+	return WrapESDeleteByQueryService(s.deleteByQueryService.Query(query))
+}
+
+// IgnoreUnavailable calls this function to internal service.
+func (s DeleteByQueryServiceWrapper) IgnoreUnavailable(ignoreUnavailable bool) es.DeleteByQueryService {
+	return WrapESDeleteByQueryService(s.deleteByQueryService.IgnoreUnavailable(ignoreUnavailable))
+}
+
+// ProceedOnVersionConflict calls this function to internal service.
+func (s DeleteByQueryServiceWrapper) ProceedOnVersionConflict() es.DeleteByQueryService {
+	return WrapESDeleteByQueryService(s.deleteByQueryService.ProceedOnVersionConflict())
+}
+
+// Refresh calls this function to internal service.
+func (s DeleteByQueryServiceWrapper) Refresh(refresh string) es.DeleteByQueryService {
+	return WrapESDeleteByQueryService(s.deleteByQueryService.Refresh(refresh))
+}
+
+// Do calls this function to internal service.
+func (s DeleteByQueryServiceWrapper) Do(ctx context.Context) (*elastic.BulkIndexByScrollResponse, error) {
+	return s.deleteByQueryService.Do(ctx)
+}
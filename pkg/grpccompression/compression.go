@@ -0,0 +1,133 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package grpccompression registers the gzip, zstd and snappy compression codecs of the gRPC servers,
+// which can be disabled and count the bytes received with each codec.
+package grpccompression
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mostynb/go-grpc-compression/nonclobbering/snappy"
+	"github.com/mostynb/go-grpc-compression/nonclobbering/zstd"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// Codecs are the names of the compression codecs registered, as sent by the clients in the grpc-encoding header.
+var Codecs = []string{gzip.Name, zstd.Name, snappy.Name}
+
+// settings are the disabled codecs and the metrics, shared by the gRPC servers of the process
+// since the codecs are registered globally.
+type settings struct {
+	disabled      map[string]bool
+	receivedBytes map[string]metrics.Counter
+}
+
+var current atomic.Pointer[settings]
+
+func init() {
+	current.Store(&settings{})
+	for _, name := range Codecs {
+		// the codecs are wrapped once registered by their package
+		encoding.RegisterCompressor(&codec{Compressor: encoding.GetCompressor(name)})
+	}
+}
+
+// Validate checks that the names are the ones of registered codecs.
+func Validate(names []string) error {
+	for _, name := range names {
+		if !isCodec(name) {
+			return fmt.Errorf("unknown gRPC compression codec %q, expected one of %s", name, strings.Join(Codecs, ", "))
+		}
+	}
+	return nil
+}
+
+// Configure disables the codecs, the calls compressed with them failing, and creates the counters
+// of the compressed bytes received with each codec. It applies to all the gRPC servers of the process.
+func Configure(metricsFactory metrics.Factory, disabled []string) error {
+	if err := Validate(disabled); err != nil {
+		return err
+	}
+	s := &settings{
+		disabled:      make(map[string]bool, len(disabled)),
+		receivedBytes: make(map[string]metrics.Counter, len(Codecs)),
+	}
+	for _, name := range disabled {
+		s.disabled[name] = true
+	}
+	for _, name := range Codecs {
+		s.receivedBytes[name] = metricsFactory.Counter(metrics.Options{
+			Name: "grpc.compression.bytes.received",
+			Tags: map[string]string{"codec": name},
+			Help: "The number of compressed bytes received by the gRPC servers, by compression codec",
+		})
+	}
+	current.Store(s)
+	return nil
+}
+
+func isCodec(name string) bool {
+	for _, codec := range Codecs {
+		if name == codec {
+			return true
+		}
+	}
+	return false
+}
+
+// codec wraps a registered compressor, the size of the decompressed messages being capped by gRPC
+// to the max receive message size.
+type codec struct {
+	encoding.Compressor
+}
+
+func (c *codec) Compress(w io.Writer) (io.WriteCloser, error) {
+	if current.Load().disabled[c.Name()] {
+		return nil, c.disabledError()
+	}
+	return c.Compressor.Compress(w)
+}
+
+func (c *codec) Decompress(r io.Reader) (io.Reader, error) {
+	s := current.Load()
+	if s.disabled[c.Name()] {
+		return nil, c.disabledError()
+	}
+	if counter, ok := s.receivedBytes[c.Name()]; ok {
+		r = &countingReader{Reader: r, counter: counter}
+	}
+	return c.Compressor.Decompress(r)
+}
+
+// DecompressedSize lets gRPC reject the messages too large once decompressed before decompressing them,
+// when the codec supports it.
+func (c *codec) DecompressedSize(compressedBytes []byte) int {
+	if sizer, ok := c.Compressor.(interface {
+		DecompressedSize(compressedBytes []byte) int
+	}); ok {
+		return sizer.DecompressedSize(compressedBytes)
+	}
+	return -1
+}
+
+func (c *codec) disabledError() error {
+	return fmt.Errorf("the %s compression is disabled on this server", c.Name())
+}
+
+type countingReader struct {
+	io.Reader
+	counter metrics.Counter
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.counter.Inc(int64(n))
+	return n, err
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpccompression
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// startHealthServer starts a gRPC server with the health service, whose requests carry the name of a service
+// which can be made as large as needed, and returns a client connected to it.
+func startHealthServer(t *testing.T, opts ...grpc.ServerOption) grpc_health_v1.HealthClient {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(opts...)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(strings.Repeat("x", 1024), grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func configure(t *testing.T, metricsFactory metrics.Factory, disabled ...string) {
+	require.NoError(t, Configure(metricsFactory, disabled))
+	t.Cleanup(func() {
+		require.NoError(t, Configure(metrics.NullFactory, nil))
+	})
+}
+
+func TestCodecs(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	configure(t, metricsFactory)
+	client := startHealthServer(t)
+
+	for _, codec := range Codecs {
+		t.Run(codec, func(t *testing.T) {
+			response, err := client.Check(context.Background(),
+				&grpc_health_v1.HealthCheckRequest{Service: strings.Repeat("x", 1024)}, grpc.UseCompressor(codec))
+			require.NoError(t, err)
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+			counters, _ := metricsFactory.Snapshot()
+			received := counters["grpc.compression.bytes.received|codec="+codec]
+			assert.Positive(t, received)
+			assert.Less(t, received, int64(1024), "the compressed bytes are counted")
+		})
+	}
+}
+
+func TestDisabledCodec(t *testing.T) {
+	configure(t, metrics.NullFactory, "zstd")
+	client := startHealthServer(t)
+
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.UseCompressor("zstd"))
+	require.Error(t, err)
+	assert.Contains(t, status.Convert(err).Message(), "the zstd compression is disabled on this server")
+
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.UseCompressor("snappy"))
+	require.NoError(t, err, "the other codecs are still enabled")
+}
+
+func TestDecompressedSizeIsCapped(t *testing.T) {
+	// a request of 1MiB is compressed into a few KiB by all the codecs
+	const maxSize = 64 * 1024
+	client := startHealthServer(t, grpc.MaxRecvMsgSize(maxSize))
+	for _, codec := range Codecs {
+		t.Run(codec, func(t *testing.T) {
+			_, err := client.Check(context.Background(),
+				&grpc_health_v1.HealthCheckRequest{Service: strings.Repeat("x", 1024*1024)}, grpc.UseCompressor(codec))
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate([]string{"gzip", "zstd", "snappy"}))
+	require.EqualError(t, Validate([]string{"lz4"}), `unknown gRPC compression codec "lz4", expected one of gzip, zstd, snappy`)
+	require.Error(t, Configure(metrics.NullFactory, []string{"lz4"}))
+}
@@ -74,6 +74,7 @@ func initHelper(
 	svc string,
 	otelExporter func(_ context.Context) (sdktrace.SpanExporter, error),
 	otelResource func(_ context.Context, _ /* svc */ string) (*resource.Resource, error),
+	providerOpts ...sdktrace.TracerProviderOption,
 ) (*sdktrace.TracerProvider, error) {
 	res, err := otelResource(ctx, svc)
 	if err != nil {
@@ -89,10 +90,10 @@ func initHelper(
 	// span processor to aggregate spans before export.
 	bsp := sdktrace.NewBatchSpanProcessor(traceExporter)
 
-	tracerProvider := sdktrace.NewTracerProvider(
+	tracerProvider := sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{
 		sdktrace.WithSpanProcessor(bsp),
 		sdktrace.WithResource(res),
-	)
+	}, providerOpts...)...)
 
 	once.Do(func() {
 		otel.SetTextMapPropagator(
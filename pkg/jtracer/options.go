@@ -0,0 +1,237 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package jtracer
+
+import (
+	"context"
+	cryptotls "crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+const (
+	// ExporterOTLPGRPC exports the spans with OTLP over gRPC.
+	ExporterOTLPGRPC = "otlp-grpc"
+	// ExporterOTLPHTTP exports the spans with OTLP over HTTP.
+	ExporterOTLPHTTP = "otlp-http"
+	// ExporterNone disables the self-tracing.
+	ExporterNone = "none"
+
+	// SamplerAlwaysOn samples all the traces.
+	SamplerAlwaysOn = "always_on"
+	// SamplerAlwaysOff samples no trace.
+	SamplerAlwaysOff = "always_off"
+	// SamplerTraceIDRatio samples a ratio of the traces.
+	SamplerTraceIDRatio = "traceidratio"
+	// SamplerParentBasedTraceIDRatio follows the sampling decision of the parent span,
+	// and samples a ratio of the traces started locally.
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+
+	selfTracingPrefix      = "self-tracing"
+	exporterFlag           = selfTracingPrefix + ".exporter"
+	endpointFlag           = selfTracingPrefix + ".endpoint"
+	samplerFlag            = selfTracingPrefix + ".sampler"
+	samplerRatioFlag       = selfTracingPrefix + ".sampler-ratio"
+	resourceAttributesFlag = selfTracingPrefix + ".resource-attributes"
+)
+
+var tlsFlagsConfig = tlscfg.ClientFlagsConfig{
+	Prefix: selfTracingPrefix,
+}
+
+// Options configures the exporter, the sampler and the resource of the self-tracing,
+// independently of the OTEL_* environment variables.
+type Options struct {
+	// Exporter is one of the Exporter* constants, or empty for the default of the component,
+	// see NewFromOptions.
+	Exporter string
+	// Endpoint is the host:port of the OTLP receiver, the default of the exporter when empty.
+	Endpoint string
+	// TLS configures the connection to the OTLP receiver, which is insecure when TLS is disabled.
+	TLS tlscfg.Options
+	// Sampler is one of the Sampler* constants.
+	Sampler string
+	// SamplerRatio is the ratio of the traces sampled by the ratio samplers.
+	SamplerRatio float64
+	// ResourceAttributes are added to the resource of the spans.
+	ResourceAttributes map[string]string
+}
+
+// AddFlags adds the flags of the self-tracing Options.
+func AddFlags(flags *flag.FlagSet) {
+	flags.String(exporterFlag, "", fmt.Sprintf(
+		"The exporter of the traces of the process itself: %s, %s or %s to disable the self-tracing; "+
+			"when unset, the default of the process is used, configured by the OTEL_* environment variables",
+		ExporterOTLPGRPC, ExporterOTLPHTTP, ExporterNone))
+	flags.String(endpointFlag, "", "The host:port of the OTLP receiver of the traces of the process itself, the default of the exporter if unset")
+	flags.String(samplerFlag, SamplerAlwaysOn, fmt.Sprintf(
+		"The sampler of the traces of the process itself: %s, %s, %s or %s",
+		SamplerAlwaysOn, SamplerAlwaysOff, SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio))
+	flags.Float64(samplerRatioFlag, 1, "The ratio of the traces of the process itself sampled by the ratio samplers, between 0 and 1")
+	flags.String(resourceAttributesFlag, "", "Comma-separated key=value attributes added to the resource of the traces of the process itself")
+	tlsFlagsConfig.AddFlags(flags)
+}
+
+// InitFromViper initializes the Options with the flags.
+func (o *Options) InitFromViper(v *viper.Viper) (*Options, error) {
+	o.Exporter = v.GetString(exporterFlag)
+	switch o.Exporter {
+	case "", ExporterOTLPGRPC, ExporterOTLPHTTP, ExporterNone:
+	default:
+		return o, fmt.Errorf("invalid %s %q", exporterFlag, o.Exporter)
+	}
+	o.Endpoint = v.GetString(endpointFlag)
+	o.Sampler = v.GetString(samplerFlag)
+	switch o.Sampler {
+	case SamplerAlwaysOn, SamplerAlwaysOff, SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+	default:
+		return o, fmt.Errorf("invalid %s %q", samplerFlag, o.Sampler)
+	}
+	o.SamplerRatio = v.GetFloat64(samplerRatioFlag)
+	if o.SamplerRatio < 0 || o.SamplerRatio > 1 {
+		return o, fmt.Errorf("%s must be between 0 and 1, got %v", samplerRatioFlag, o.SamplerRatio)
+	}
+	attributes, err := parseResourceAttributes(v.GetString(resourceAttributesFlag))
+	if err != nil {
+		return o, err
+	}
+	o.ResourceAttributes = attributes
+	tls, err := tlsFlagsConfig.InitFromViper(v)
+	if err != nil {
+		return o, fmt.Errorf("failed to process TLS options: %w", err)
+	}
+	o.TLS = tls
+	return o, nil
+}
+
+func parseResourceAttributes(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	attributes := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected key=value", resourceAttributesFlag, pair)
+		}
+		attributes[key] = strings.TrimSpace(val)
+	}
+	return attributes, nil
+}
+
+// NewFromOptions returns the tracer configured by the options. Without exporter, the tracer is
+// configured by the environment variables like with New, and the none exporter returns the NoOp
+// tracer. The OTLP exporters ignore the OTEL_* environment variables of the endpoint, TLS, sampler
+// and resource.
+func NewFromOptions(serviceName string, opts Options) (*JTracer, error) {
+	switch opts.Exporter {
+	case "":
+		return New(serviceName)
+	case ExporterNone:
+		return NoOp(), nil
+	}
+	// the certificates watched for the exporter are released with the tracer
+	tls := opts.TLS
+	jt, err := newHelper(serviceName, func(ctx context.Context, svc string) (*sdktrace.TracerProvider, error) {
+		return initHelper(
+			ctx,
+			svc,
+			func(ctx context.Context) (sdktrace.SpanExporter, error) {
+				return optionsExporter(ctx, opts.Exporter, opts.Endpoint, &tls)
+			},
+			func(ctx context.Context, svc string) (*resource.Resource, error) {
+				return optionsResource(ctx, svc, opts.ResourceAttributes)
+			},
+			sdktrace.WithSampler(optionsSampler(opts)),
+		)
+	})
+	if err != nil {
+		return nil, errors.Join(err, tls.Close())
+	}
+	shutdown := jt.closer
+	jt.closer = func(ctx context.Context) error {
+		return errors.Join(shutdown(ctx), tls.Close())
+	}
+	return jt, nil
+}
+
+func optionsExporter(ctx context.Context, exporter, endpoint string, tls *tlscfg.Options) (sdktrace.SpanExporter, error) {
+	var tlsConfig *cryptotls.Config
+	if tls.Enabled {
+		var err error
+		if tlsConfig, err = tls.Config(zap.NewNop()); err != nil {
+			return nil, fmt.Errorf("failed to load the TLS configuration of the self-tracing exporter: %w", err)
+		}
+	}
+	switch exporter {
+	case ExporterOTLPGRPC:
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(map[string]string{})}
+		if endpoint != "" {
+			clientOpts = append(clientOpts, otlptracegrpc.WithEndpoint(endpoint))
+		}
+		if tlsConfig != nil {
+			clientOpts = append(clientOpts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		} else {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+		return otlptrace.New(ctx, otlptracegrpc.NewClient(clientOpts...))
+	case ExporterOTLPHTTP:
+		clientOpts := []otlptracehttp.Option{otlptracehttp.WithHeaders(map[string]string{})}
+		if endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(endpoint))
+		}
+		if tlsConfig != nil {
+			clientOpts = append(clientOpts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		} else {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		return otlptrace.New(ctx, otlptracehttp.NewClient(clientOpts...))
+	default:
+		return nil, fmt.Errorf("unknown self-tracing exporter %q", exporter)
+	}
+}
+
+func optionsResource(ctx context.Context, svc string, attributes map[string]string) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String(svc)}
+	for key, value := range attributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	return resource.New(
+		ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(attrs...),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithOSType(),
+	)
+}
+
+func optionsSampler(opts Options) sdktrace.Sampler {
+	switch opts.Sampler {
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample()
+	case SamplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(opts.SamplerRatio)
+	case SamplerParentBasedTraceIDRatio:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SamplerRatio))
+	default:
+		return sdktrace.AlwaysSample()
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package jtracer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+// otlpReceiver collects the OTLP export requests received over gRPC or HTTP.
+type otlpReceiver struct {
+	coltracepb.UnimplementedTraceServiceServer
+	requests chan *coltracepb.ExportTraceServiceRequest
+}
+
+func newOTLPReceiver() *otlpReceiver {
+	return &otlpReceiver{requests: make(chan *coltracepb.ExportTraceServiceRequest, 10)}
+}
+
+func (r *otlpReceiver) Export(_ context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	r.requests <- req
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.requests <- request
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+func startGRPCReceiver(t *testing.T, receiver *otlpReceiver) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, receiver)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func startHTTPReceiver(t *testing.T, receiver *otlpReceiver) string {
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestNewFromOptionsExporters(t *testing.T) {
+	tests := []struct {
+		exporter string
+		start    func(*testing.T, *otlpReceiver) string
+	}{
+		{exporter: ExporterOTLPGRPC, start: startGRPCReceiver},
+		{exporter: ExporterOTLPHTTP, start: startHTTPReceiver},
+	}
+	for _, test := range tests {
+		t.Run(test.exporter, func(t *testing.T) {
+			// the environment variables are ignored
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:1")
+			t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=from-env")
+			receiver := newOTLPReceiver()
+			jt, err := NewFromOptions("jaeger-query", Options{
+				Exporter:           test.exporter,
+				Endpoint:           test.start(t, receiver),
+				Sampler:            SamplerAlwaysOn,
+				ResourceAttributes: map[string]string{"deployment.environment": "staging", "k8s.pod.name": "query-0"},
+			})
+			require.NoError(t, err)
+
+			_, span := jt.OTEL.Tracer("test").Start(context.Background(), "operation")
+			span.End()
+			require.NoError(t, jt.Close(context.Background()))
+
+			var request *coltracepb.ExportTraceServiceRequest
+			select {
+			case request = <-receiver.requests:
+			case <-time.After(10 * time.Second):
+				t.Fatal("the span was not exported")
+			}
+			require.Len(t, request.ResourceSpans, 1)
+			attributes := make(map[string]string)
+			for _, kv := range request.ResourceSpans[0].Resource.Attributes {
+				attributes[kv.Key] = kv.Value.GetStringValue()
+			}
+			assert.Equal(t, "jaeger-query", attributes["service.name"])
+			assert.Equal(t, "staging", attributes["deployment.environment"])
+			assert.Equal(t, "query-0", attributes["k8s.pod.name"])
+			require.Len(t, request.ResourceSpans[0].ScopeSpans, 1)
+			require.Len(t, request.ResourceSpans[0].ScopeSpans[0].Spans, 1)
+			assert.Equal(t, "operation", request.ResourceSpans[0].ScopeSpans[0].Spans[0].Name)
+		})
+	}
+}
+
+func TestNewFromOptionsNone(t *testing.T) {
+	jt, err := NewFromOptions("svc", Options{Exporter: ExporterNone})
+	require.NoError(t, err)
+	assert.Equal(t, NoOp(), jt)
+}
+
+func TestNewFromOptionsDefault(t *testing.T) {
+	jt, err := NewFromOptions("svc", Options{})
+	require.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, jt.OTEL)
+	require.NoError(t, jt.Close(context.Background()))
+}
+
+func TestNewFromOptionsTLSError(t *testing.T) {
+	_, err := NewFromOptions("svc", Options{
+		Exporter: ExporterOTLPGRPC,
+		TLS:      tlscfg.Options{Enabled: true, CAPath: "invalid/path"},
+	})
+	require.ErrorContains(t, err, "failed to load the TLS configuration of the self-tracing exporter")
+}
+
+func TestOptionsSampler(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		sampled bool
+	}{
+		{opts: Options{Sampler: SamplerAlwaysOn}, sampled: true},
+		{opts: Options{Sampler: SamplerAlwaysOff}, sampled: false},
+		{opts: Options{Sampler: SamplerTraceIDRatio, SamplerRatio: 1}, sampled: true},
+		{opts: Options{Sampler: SamplerTraceIDRatio, SamplerRatio: 0}, sampled: false},
+		{opts: Options{Sampler: SamplerParentBasedTraceIDRatio, SamplerRatio: 0}, sampled: false},
+	}
+	for _, test := range tests {
+		t.Run(test.opts.Sampler, func(t *testing.T) {
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(optionsSampler(test.opts)))
+			_, span := provider.Tracer("test").Start(context.Background(), "operation")
+			assert.Equal(t, test.sampled, span.SpanContext().IsSampled())
+			span.End()
+			require.NoError(t, provider.Shutdown(context.Background()))
+		})
+	}
+	// the parent-based sampler follows a sampled parent
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(optionsSampler(Options{Sampler: SamplerParentBasedTraceIDRatio})))
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	_, span := provider.Tracer("test").Start(trace.ContextWithSpanContext(context.Background(), parent), "operation")
+	assert.True(t, span.SpanContext().IsSampled())
+}
+
+func TestOptionsFromViper(t *testing.T) {
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--self-tracing.exporter=otlp-http",
+		"--self-tracing.endpoint=otel-collector:4318",
+		"--self-tracing.sampler=traceidratio",
+		"--self-tracing.sampler-ratio=0.25",
+		"--self-tracing.resource-attributes=deployment.environment=prod, team = tracing",
+		"--self-tracing.tls.enabled=true",
+	}))
+	opts, err := new(Options).InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, ExporterOTLPHTTP, opts.Exporter)
+	assert.Equal(t, "otel-collector:4318", opts.Endpoint)
+	assert.Equal(t, SamplerTraceIDRatio, opts.Sampler)
+	assert.InDelta(t, 0.25, opts.SamplerRatio, 0)
+	assert.Equal(t, map[string]string{"deployment.environment": "prod", "team": "tracing"}, opts.ResourceAttributes)
+	assert.True(t, opts.TLS.Enabled)
+
+	v, command = config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags(nil))
+	opts, err = new(Options).InitFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, &Options{Sampler: SamplerAlwaysOn, SamplerRatio: 1}, opts)
+}
+
+func TestOptionsFromViperErrors(t *testing.T) {
+	tests := []struct {
+		flag     string
+		expected string
+	}{
+		{flag: "--self-tracing.exporter=jaeger", expected: `invalid self-tracing.exporter "jaeger"`},
+		{flag: "--self-tracing.sampler=sometimes", expected: `invalid self-tracing.sampler "sometimes"`},
+		{flag: "--self-tracing.sampler-ratio=2", expected: "self-tracing.sampler-ratio must be between 0 and 1, got 2"},
+		{flag: "--self-tracing.resource-attributes=team", expected: `invalid self-tracing.resource-attributes "team", expected key=value`},
+		{flag: "--self-tracing.tls.min-version=2.0", expected: "failed to process TLS options"},
+	}
+	for _, test := range tests {
+		t.Run(test.flag, func(t *testing.T) {
+			v, command := config.Viperize(AddFlags)
+			require.NoError(t, command.ParseFlags([]string{test.flag}))
+			_, err := new(Options).InitFromViper(v)
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
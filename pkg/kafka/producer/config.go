@@ -40,6 +40,7 @@ type Configuration struct {
 	BatchMinMessages          int                     `mapstructure:"batch_min_messages"`
 	BatchMaxMessages          int                     `mapstructure:"batch_max_messages"`
 	MaxMessageBytes           int                     `mapstructure:"max_message_bytes"`
+	Idempotent                bool                    `mapstructure:"idempotent"`
 	auth.AuthenticationConfig `mapstructure:"authentication"`
 }
 
@@ -55,6 +56,13 @@ func (c *Configuration) NewProducer(logger *zap.Logger) (sarama.AsyncProducer, e
 	saramaConfig.Producer.Flush.Messages = c.BatchMinMessages
 	saramaConfig.Producer.Flush.MaxMessages = c.BatchMaxMessages
 	saramaConfig.Producer.MaxMessageBytes = c.MaxMessageBytes
+	saramaConfig.Producer.Partitioner = NewKeyPartitioner
+	if c.Idempotent {
+		// the other requirements of the idempotent producer, e.g. the required acks,
+		// are checked by sarama since they change the guarantees of the producer
+		saramaConfig.Producer.Idempotent = true
+		saramaConfig.Net.MaxOpenRequests = 1
+	}
 	if len(c.ProtocolVersion) > 0 {
 		ver, err := sarama.ParseKafkaVersion(c.ProtocolVersion)
 		if err != nil {
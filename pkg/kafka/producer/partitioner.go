@@ -0,0 +1,45 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"github.com/Shopify/sarama"
+)
+
+// keyPartitioner sends the messages with a key to the partition of the hash of the key,
+// like the default sarama partitioner, and spreads the messages without a key over all
+// the partitions in turn instead of picking them at random.
+type keyPartitioner struct {
+	hash       sarama.Partitioner
+	roundRobin sarama.Partitioner
+}
+
+var _ sarama.DynamicConsistencyPartitioner = (*keyPartitioner)(nil)
+
+// NewKeyPartitioner returns the partitioner of the messages of the producer, see keyPartitioner.
+func NewKeyPartitioner(topic string) sarama.Partitioner {
+	return &keyPartitioner{
+		hash:       sarama.NewHashPartitioner(topic),
+		roundRobin: sarama.NewRoundRobinPartitioner(topic),
+	}
+}
+
+// Partition implements sarama.Partitioner.
+func (p *keyPartitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if message.Key == nil {
+		return p.roundRobin.Partition(message, numPartitions)
+	}
+	return p.hash.Partition(message, numPartitions)
+}
+
+// RequiresConsistency implements sarama.Partitioner.
+func (*keyPartitioner) RequiresConsistency() bool {
+	return true
+}
+
+// MessageRequiresConsistency implements sarama.DynamicConsistencyPartitioner: only the messages
+// with a key must always land on the same partition, the others may skip unavailable partitions.
+func (*keyPartitioner) MessageRequiresConsistency(message *sarama.ProducerMessage) bool {
+	return message.Key != nil
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package producer
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPartitionerHashesKeys(t *testing.T) {
+	p := NewKeyPartitioner("topic").(sarama.DynamicConsistencyPartitioner)
+	hash := sarama.NewHashPartitioner("topic")
+	for _, key := range []string{"frontend", "backend", "0000000000000001"} {
+		message := &sarama.ProducerMessage{Key: sarama.StringEncoder(key)}
+		expected, err := hash.Partition(message, 16)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			partition, err := p.Partition(message, 16)
+			require.NoError(t, err)
+			assert.Equal(t, expected, partition, key)
+		}
+		assert.True(t, p.MessageRequiresConsistency(message))
+	}
+	assert.True(t, p.RequiresConsistency())
+}
+
+func TestKeyPartitionerRoundRobinWithoutKey(t *testing.T) {
+	p := NewKeyPartitioner("topic").(sarama.DynamicConsistencyPartitioner)
+	message := &sarama.ProducerMessage{}
+	var partitions []int32
+	for i := 0; i < 5; i++ {
+		partition, err := p.Partition(message, 3)
+		require.NoError(t, err)
+		partitions = append(partitions, partition)
+	}
+	assert.Equal(t, []int32{0, 1, 2, 0, 1}, partitions)
+	assert.False(t, p.MessageRequiresConsistency(message))
+}
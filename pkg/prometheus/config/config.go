@@ -20,6 +20,12 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
 )
 
+// The storage modes of the latency histogram, see Configuration.LatencyHistogramMode.
+const (
+	LatencyHistogramClassic = "classic"
+	LatencyHistogramNative  = "native"
+)
+
 // Configuration describes the options to customize the storage behavior.
 type Configuration struct {
 	ServerURL                string
@@ -32,4 +38,20 @@ type Configuration struct {
 	LatencyUnit       string
 	NormalizeCalls    bool
 	NormalizeDuration bool
+
+	// CallsMetricName overrides the name of the calls counter, ignoring MetricNamespace and NormalizeCalls.
+	CallsMetricName string
+	// LatencyMetricName overrides the name of the latency histogram, without the "_bucket" suffix,
+	// ignoring MetricNamespace and NormalizeDuration.
+	LatencyMetricName string
+	// LatencyHistogramMode is how the latency histogram is stored: "classic", as "_bucket" series
+	// with an "le" label, or "native"; empty means classic.
+	LatencyHistogramMode string
+	// ExtraLabelMatchers are PromQL label matchers, e.g. `deployment_environment = "prod"`,
+	// added to the selectors of every query.
+	ExtraLabelMatchers []string
+	// ServiceLabel is the label holding the service name, empty meaning "service_name".
+	ServiceLabel string
+	// OperationLabel is the label holding the operation name, empty meaning "span_name".
+	OperationLabel string
 }
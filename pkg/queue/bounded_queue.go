@@ -24,6 +24,9 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 )
 
+// drainCheckInterval is how often StopWithDrain checks whether the queue is empty
+const drainCheckInterval = 10 * time.Millisecond
+
 // Consumer consumes data from a bounded queue
 type Consumer interface {
 	Consume(item any)
@@ -149,6 +152,29 @@ func (q *BoundedQueue) Stop() {
 	close(*q.items)
 }
 
+// StopWithDrain disables the producer and lets the consumers process the items
+// remaining in the queue until it is empty or the timeout elapses, then stops
+// the consumers like Stop does. It returns the number of items that were abandoned
+// in the queue.
+func (q *BoundedQueue) StopWithDrain(timeout time.Duration) int {
+	q.stopped.Store(1) // disable producer
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+drain:
+	for q.Size() > 0 {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			break drain
+		}
+	}
+	close(q.stopCh)
+	q.stopWG.Wait()
+	close(*q.items)
+	return q.Size()
+}
+
 // Size returns the current size of the queue
 func (q *BoundedQueue) Size() int {
 	return int(q.size.Load())
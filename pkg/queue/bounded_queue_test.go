@@ -332,6 +332,40 @@ func TestZeroSize(t *testing.T) {
 	assert.False(t, q.Produce("a")) // in process
 }
 
+func TestStopWithDrain(t *testing.T) {
+	q := NewBoundedQueue(10, func( /* item */ any) {})
+	var consumed atomic.Int32
+	q.StartConsumers(1, func( /* item */ any) {
+		time.Sleep(time.Millisecond)
+		consumed.Add(1)
+	})
+	for i := 0; i < 10; i++ {
+		require.True(t, q.Produce(i))
+	}
+
+	assert.Equal(t, 0, q.StopWithDrain(time.Second))
+	assert.EqualValues(t, 10, consumed.Load())
+	assert.False(t, q.Produce("a"), "producer is disabled after stop")
+}
+
+func TestStopWithDrainTimeout(t *testing.T) {
+	q := NewBoundedQueue(10, func( /* item */ any) {})
+	release := make(chan struct{})
+	var consumed atomic.Int32
+	q.StartConsumers(1, func( /* item */ any) {
+		<-release
+		consumed.Add(1)
+	})
+	for i := 0; i < 10; i++ {
+		require.True(t, q.Produce(i))
+	}
+
+	time.AfterFunc(100*time.Millisecond, func() { close(release) })
+	abandoned := q.StopWithDrain(10 * time.Millisecond)
+	assert.Positive(t, abandoned)
+	assert.EqualValues(t, 10, abandoned+int(consumed.Load()))
+}
+
 func BenchmarkBoundedQueue(b *testing.B) {
 	q := NewBoundedQueue(1000, func( /* item */ any) {})
 	q.StartConsumers(10, func( /* item */ any) {})
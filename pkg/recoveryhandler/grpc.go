@@ -0,0 +1,32 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package recoveryhandler
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// NewGRPCRecoveryInterceptors returns unary and stream gRPC server interceptors that recover
+// from panics in the handlers. The panic is logged with its stack trace, counted in the panics
+// counter, and returned to the client as an Internal error.
+func NewGRPCRecoveryInterceptors(logger *zap.Logger, panics metrics.Counter) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	handler := recovery.WithRecoveryHandlerContext(func(_ context.Context, p any) error {
+		panics.Inc(1)
+		logger.Error("Recovered from panic in gRPC handler",
+			zap.Any("panic", p),
+			zap.ByteString("stack", debug.Stack()),
+		)
+		return status.Errorf(codes.Internal, "internal error: %v", p)
+	})
+	return recovery.UnaryServerInterceptor(handler), recovery.StreamServerInterceptor(handler)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package recoveryhandler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/pkg/testutils"
+)
+
+func TestNewGRPCRecoveryInterceptors(t *testing.T) {
+	logger, log := testutils.NewLogger()
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	panics := metricsFactory.Counter(metrics.Options{Name: "panics"})
+
+	unary, stream := NewGRPCRecoveryInterceptors(logger, panics)
+
+	_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		panic("Unexpected error!")
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "Unexpected error!")
+
+	err = stream(nil, &fakeServerStream{}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+		panic("Unexpected stream error!")
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "panics", Value: 2})
+	assert.Equal(t, "Recovered from panic in gRPC handler", log.JSONLine(0)["msg"])
+	assert.Contains(t, log.JSONLine(0)["stack"], "recoveryhandler")
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+}
+
+func (*fakeServerStream) Context() context.Context {
+	return context.Background()
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tenancy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errUnknownTenant     = errors.New("unknown tenant")
+	errDisabledTenant    = errors.New("tenant is disabled")
+	errTenantRateLimited = errors.New("too many requests from the tenant")
+)
+
+// TenantConfig is the configuration of a tenant in the tenancy configuration file.
+type TenantConfig struct {
+	// DisplayName is the human-readable name of the tenant.
+	DisplayName string
+	// Enabled is false for the tenants whose requests are rejected, e.g. while suspended.
+	Enabled bool
+	// RateLimit limits the rate of the requests of the tenant.
+	RateLimit TenantRateLimit
+	// PassThroughHeaders are the HTTP headers of the tenant's requests forwarded to the gRPC API.
+	PassThroughHeaders []string
+}
+
+// TenantRateLimit limits the rate of the requests of a tenant.
+type TenantRateLimit struct {
+	// RequestsPerSecond is the sustained rate of the requests allowed, 0 means no limit.
+	RequestsPerSecond float64
+	// Burst is the number of requests the tenant can make at once.
+	Burst int
+}
+
+// configFile is the format of the tenancy configuration file, e.g.
+//
+//	tenants:
+//	  acme:
+//	    display_name: ACME Corp.
+//	    rate_limit:
+//	      requests_per_second: 10
+//	      burst: 20
+//	    pass_through_headers: [x-request-id]
+//	  country-store:
+//	    enabled: false
+type configFile struct {
+	Tenants map[string]struct {
+		DisplayName string `yaml:"display_name"`
+		Enabled     *bool  `yaml:"enabled"`
+		RateLimit   struct {
+			RequestsPerSecond float64 `yaml:"requests_per_second"`
+			Burst             int     `yaml:"burst"`
+		} `yaml:"rate_limit"`
+		PassThroughHeaders []string `yaml:"pass_through_headers"`
+	} `yaml:"tenants"`
+}
+
+// LoadConfigFile reads the configuration of the tenants from the YAML file, the tenants being enabled by default.
+func LoadConfigFile(path string) (map[string]TenantConfig, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tenancy configuration file: %w", err)
+	}
+	var file configFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse the tenancy configuration file %s: %w", path, err)
+	}
+	tenants := make(map[string]TenantConfig, len(file.Tenants))
+	for name, t := range file.Tenants {
+		if name == "" {
+			return nil, fmt.Errorf("invalid tenancy configuration file %s: empty tenant name", path)
+		}
+		if t.RateLimit.RequestsPerSecond < 0 || t.RateLimit.RequestsPerSecond > 0 && t.RateLimit.Burst < 1 {
+			return nil, fmt.Errorf("invalid rate limit of tenant %q in %s: requests_per_second must not be negative, and burst must be positive", name, path)
+		}
+		tenants[name] = TenantConfig{
+			DisplayName: t.DisplayName,
+			Enabled:     t.Enabled == nil || *t.Enabled,
+			RateLimit: TenantRateLimit{
+				RequestsPerSecond: t.RateLimit.RequestsPerSecond,
+				Burst:             t.RateLimit.Burst,
+			},
+			PassThroughHeaders: t.PassThroughHeaders,
+		}
+	}
+	return tenants, nil
+}
+
+// configuredTenant is a tenant of the configuration file, with its token bucket if it is rate limited.
+type configuredTenant struct {
+	config  TenantConfig
+	limiter *rate.Limiter
+}
+
+// tenantConfigs guards the tenants of the configuration file.
+type tenantConfigs struct {
+	tenants map[string]*configuredTenant
+}
+
+// newTenantConfigs returns the guard of the tenants, keeping the token buckets of the previous
+// configuration whose rate limit did not change, so that a reload does not refill them.
+func newTenantConfigs(tenants map[string]TenantConfig, previous *tenantConfigs) *tenantConfigs {
+	tc := &tenantConfigs{tenants: make(map[string]*configuredTenant, len(tenants))}
+	for name, config := range tenants {
+		t := &configuredTenant{config: config}
+		if limit := config.RateLimit; limit.RequestsPerSecond > 0 {
+			if old, ok := previous.lookup(name); ok && old.config.RateLimit == limit {
+				t.limiter = old.limiter
+			} else {
+				t.limiter = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst)
+			}
+		}
+		tc.tenants[name] = t
+	}
+	return tc
+}
+
+func (tc *tenantConfigs) lookup(tenant string) (*configuredTenant, bool) {
+	if tc == nil {
+		return nil, false
+	}
+	t, ok := tc.tenants[tenant]
+	return t, ok
+}
+
+func (tc *tenantConfigs) Valid(candidate string) bool {
+	t, ok := tc.lookup(candidate)
+	return ok && t.config.Enabled
+}
+
+// admit returns why a request of the tenant is rejected, or nil, counting it against its rate limit.
+func (tc *tenantConfigs) admit(tenant string) error {
+	t, ok := tc.lookup(tenant)
+	switch {
+	case !ok:
+		return errUnknownTenant
+	case !t.config.Enabled:
+		return errDisabledTenant
+	case t.limiter != nil && !t.limiter.Allow():
+		return errTenantRateLimited
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tenancy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const tenantsYAML = `
+tenants:
+  acme:
+    display_name: ACME Corp.
+    rate_limit:
+      requests_per_second: 0.001
+      burst: 2
+    pass_through_headers: [x-request-id]
+  country-store:
+    enabled: false
+  megacorp: {}
+`
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	// the file is replaced by a rename, like Kubernetes updates mounted config maps
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func newConfigFileManager(t *testing.T, content string) (*Manager, string) {
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	writeConfigFile(t, path, content)
+	tm, err := NewManagerWithConfigFile(&Options{Enabled: true, ConfigFile: path}, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tm.Close()) })
+	return tm, path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	writeConfigFile(t, path, tenantsYAML)
+	tenants, err := LoadConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]TenantConfig{
+		"acme": {
+			DisplayName:        "ACME Corp.",
+			Enabled:            true,
+			RateLimit:          TenantRateLimit{RequestsPerSecond: 0.001, Burst: 2},
+			PassThroughHeaders: []string{"x-request-id"},
+		},
+		"country-store": {Enabled: false},
+		"megacorp":      {Enabled: true},
+	}, tenants)
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name:    "malformed",
+			content: "tenants: [acme",
+			errMsg:  "failed to parse the tenancy configuration file",
+		},
+		{
+			name:    "negative rate limit",
+			content: "tenants:\n  acme:\n    rate_limit:\n      requests_per_second: -1\n",
+			errMsg:  `invalid rate limit of tenant "acme"`,
+		},
+		{
+			name:    "missing burst",
+			content: "tenants:\n  acme:\n    rate_limit:\n      requests_per_second: 10\n",
+			errMsg:  `invalid rate limit of tenant "acme"`,
+		},
+		{
+			name:    "empty tenant name",
+			content: "tenants:\n  \"\": {}\n",
+			errMsg:  "empty tenant name",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tenants.yaml")
+			writeConfigFile(t, path, test.content)
+			_, err := LoadConfigFile(path)
+			require.ErrorContains(t, err, test.errMsg)
+		})
+	}
+
+	_, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.ErrorContains(t, err, "failed to read the tenancy configuration file")
+}
+
+func TestNewManagerWithConfigFileErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	writeConfigFile(t, path, tenantsYAML)
+	_, err := NewManagerWithConfigFile(&Options{Enabled: true, Tenants: []string{"acme"}, ConfigFile: path}, zap.NewNop())
+	require.ErrorContains(t, err, "cannot be both listed and configured")
+
+	_, err = NewManagerWithConfigFile(&Options{Enabled: true, ConfigFile: path + ".missing"}, zap.NewNop())
+	require.ErrorContains(t, err, "failed to read the tenancy configuration file")
+
+	tm, err := NewManagerWithConfigFile(&Options{Enabled: true, Tenants: []string{"acme"}}, zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, tm.Valid("acme"))
+	require.NoError(t, tm.Close())
+}
+
+func TestManagerWithConfigFile(t *testing.T) {
+	tm, path := newConfigFileManager(t, tenantsYAML)
+	assert.True(t, tm.Valid("acme"))
+	assert.True(t, tm.Valid("megacorp"))
+	assert.False(t, tm.Valid("country-store"))
+	assert.False(t, tm.Valid("auto-repair"))
+
+	config, ok := tm.TenantConfig("acme")
+	require.True(t, ok)
+	assert.Equal(t, "ACME Corp.", config.DisplayName)
+	_, ok = tm.TenantConfig("auto-repair")
+	assert.False(t, ok)
+
+	// tenants are not checked when tenancy is disabled
+	disabled, err := NewManagerWithConfigFile(&Options{ConfigFile: path}, zap.NewNop())
+	require.NoError(t, err)
+	defer disabled.Close()
+	assert.True(t, disabled.Valid("auto-repair"))
+	require.NoError(t, disabled.admit("auto-repair"))
+}
+
+func TestConfigFileGuards(t *testing.T) {
+	tm, _ := newConfigFileManager(t, tenantsYAML)
+
+	httpTests := []struct {
+		tenant string
+		status int
+		body   string
+	}{
+		{tenant: "megacorp", status: http.StatusOK},
+		{tenant: "auto-repair", status: http.StatusUnauthorized, body: "unknown tenant"},
+		{tenant: "country-store", status: http.StatusForbidden, body: "tenant is disabled"},
+		// acme has a burst of 2 requests, the third one being rejected
+		{tenant: "acme", status: http.StatusOK},
+		{tenant: "acme", status: http.StatusOK},
+		{tenant: "acme", status: http.StatusTooManyRequests, body: "too many requests from the tenant"},
+	}
+	handler := ExtractTenantHTTPHandler(tm, &testHttpHandler{})
+	for _, test := range httpTests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("x-tenant", test.tenant)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, test.status, w.Code, test.tenant)
+		assert.Equal(t, test.body, w.Body.String(), test.tenant)
+	}
+
+	grpcTests := []struct {
+		tenant string
+		code   codes.Code
+		msg    string
+	}{
+		{tenant: "megacorp", code: codes.OK},
+		{tenant: "auto-repair", code: codes.PermissionDenied, msg: "unknown tenant"},
+		{tenant: "country-store", code: codes.PermissionDenied, msg: "tenant is disabled"},
+		// the burst of acme was spent by the HTTP requests
+		{tenant: "acme", code: codes.ResourceExhausted, msg: "too many requests from the tenant"},
+	}
+	interceptor := NewGuardingUnaryInterceptor(tm)
+	for _, test := range grpcTests {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", test.tenant))
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+		s := status.Convert(err)
+		assert.Equal(t, test.code, s.Code(), test.tenant)
+		assert.Equal(t, test.msg, s.Message(), test.tenant)
+	}
+}
+
+func TestConfigFileReloadRemovesTenant(t *testing.T) {
+	tm, path := newConfigFileManager(t, tenantsYAML)
+	httpHandler := ExtractTenantHTTPHandler(tm, &testHttpHandler{})
+	serveHTTP := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("x-tenant", "megacorp")
+		w := httptest.NewRecorder()
+		httpHandler.ServeHTTP(w, req)
+		return w.Code
+	}
+	streamInterceptor := NewGuardingStreamInterceptor(tm)
+	serveStream := func() error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", "megacorp"))
+		return streamInterceptor(nil, &tenantedServerStream{context: ctx}, &grpc.StreamServerInfo{},
+			func(any, grpc.ServerStream) error { return nil })
+	}
+	require.Equal(t, http.StatusOK, serveHTTP())
+	require.NoError(t, serveStream())
+
+	// the tenant is removed while its requests are being served
+	writeConfigFile(t, path, "tenants:\n  acme: {}\n")
+	assert.Eventually(t, func() bool {
+		return !tm.Valid("megacorp")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusUnauthorized, serveHTTP())
+	require.EqualError(t, serveStream(), "rpc error: code = PermissionDenied desc = unknown tenant")
+	assert.True(t, tm.Valid("acme"))
+
+	// a broken file keeps the previous tenants
+	writeConfigFile(t, path, "tenants: [acme")
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, tm.Valid("acme"))
+}
+
+func TestConfigFileReloadKeepsRateLimits(t *testing.T) {
+	tm, path := newConfigFileManager(t, tenantsYAML)
+	require.NoError(t, tm.admit("acme"))
+	require.NoError(t, tm.admit("acme"))
+	require.ErrorIs(t, tm.admit("acme"), errTenantRateLimited)
+
+	// an unrelated change does not refill the bucket of acme
+	writeConfigFile(t, path, tenantsYAML+"  auto-repair: {}\n")
+	assert.Eventually(t, func() bool {
+		return tm.Valid("auto-repair")
+	}, 5*time.Second, 10*time.Millisecond)
+	require.ErrorIs(t, tm.admit("acme"), errTenantRateLimited)
+}
+
+func TestMetadataAnnotatorPassThroughHeaders(t *testing.T) {
+	tm, _ := newConfigFileManager(t, tenantsYAML)
+	annotator := tm.MetadataAnnotator()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("x-tenant", "acme")
+	req.Header.Set("X-Request-ID", "42")
+	req.Header.Set("X-Other", "dropped")
+	assert.Equal(t, metadata.Pairs("x-tenant", "acme", "x-request-id", "42"), annotator(context.Background(), req))
+
+	req.Header.Set("x-tenant", "megacorp")
+	assert.Equal(t, metadata.Pairs("x-tenant", "megacorp"), annotator(context.Background(), req))
+}
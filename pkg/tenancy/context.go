@@ -29,7 +29,8 @@ func WithTenant(ctx context.Context, tenant string) context.Context {
 	return context.WithValue(ctx, tenantKey, tenant)
 }
 
-// GetTenant retrieves a tenant associated with a Context
+// GetTenant retrieves a tenant associated with a Context.
+// It is how the storage implementations read the tenant of a request, see NewGuardingUnaryInterceptor.
 func GetTenant(ctx context.Context) string {
 	tenant := ctx.Value(tenantKey)
 	if tenant == nil {
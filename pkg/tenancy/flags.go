@@ -27,6 +27,8 @@ const (
 	flagTenancyEnabled = flagPrefix + ".enabled"
 	flagTenancyHeader  = flagPrefix + ".header"
 	flagValidTenants   = flagPrefix + ".tenants"
+	flagStoragePrefix  = flagPrefix + ".storage-prefix"
+	flagConfigFile     = flagPrefix + ".config-file"
 )
 
 // AddFlags adds flags for tenancy to the FlagSet.
@@ -36,6 +38,13 @@ func AddFlags(flags *flag.FlagSet) {
 	flags.String(flagValidTenants, "",
 		fmt.Sprintf("comma-separated list of allowed values for --%s header.  (If not supplied, tenants are not restricted)",
 			flagTenancyHeader))
+	flags.String(flagStoragePrefix, "",
+		fmt.Sprintf("Prefix of the service and operation names stored for each tenant in a shared storage, where %s is replaced by the tenant (e.g. %q); "+
+			"the query service strips it, so that each tenant only sees its own services", tenantPlaceholder, tenantPlaceholder+"."))
+	flags.String(flagConfigFile, "",
+		fmt.Sprintf("The path to a YAML file configuring each tenant (display_name, enabled, rate_limit with requests_per_second and burst, "+
+			"and pass_through_headers), reloaded when it changes; it replaces --%s, unknown and disabled tenants being rejected",
+			flagValidTenants))
 }
 
 // InitFromViper creates tenancy.Options populated with values retrieved from Viper.
@@ -49,6 +58,8 @@ func InitFromViper(v *viper.Viper) Options {
 	} else {
 		p.Tenants = []string{}
 	}
+	p.StoragePrefix = v.GetString(flagStoragePrefix)
+	p.ConfigFile = v.GetString(flagConfigFile)
 
 	return p
 }
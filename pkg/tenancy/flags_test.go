@@ -67,6 +67,32 @@ func TestTenancyFlags(t *testing.T) {
 				Tenants: []string{"acme"},
 			},
 		},
+		{
+			name: "storage prefix",
+			cmd: []string{
+				"--multi-tenancy.enabled=true",
+				"--multi-tenancy.storage-prefix={tenant}.",
+			},
+			expected: Options{
+				Enabled:       true,
+				Header:        "x-tenant",
+				Tenants:       []string{},
+				StoragePrefix: "{tenant}.",
+			},
+		},
+		{
+			name: "config file",
+			cmd: []string{
+				"--multi-tenancy.enabled=true",
+				"--multi-tenancy.config-file=/etc/jaeger/tenants.yaml",
+			},
+			expected: Options{
+				Enabled:    true,
+				Header:     "x-tenant",
+				Tenants:    []string{},
+				ConfigFile: "/etc/jaeger/tenants.yaml",
+			},
+		},
 		{
 			// Not supplying a list of tenants will mean
 			// "tenant header required, but any value will pass"
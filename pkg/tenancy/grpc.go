@@ -16,6 +16,7 @@ package tenancy
 
 import (
 	"context"
+	"errors"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -33,13 +34,10 @@ func (tss *tenantedServerStream) Context() context.Context {
 	return tss.context
 }
 
-func getValidTenant(ctx context.Context, tc *Manager) (string, error) {
+// getTenant returns the tenant of the context, or of the tenancy header of its metadata.
+func getTenant(ctx context.Context, tc *Manager) (string, error) {
 	// Handle case where tenant is already directly in the context
-	tenant := GetTenant(ctx)
-	if tenant != "" {
-		if !tc.Valid(tenant) {
-			return tenant, status.Errorf(codes.PermissionDenied, "unknown tenant")
-		}
+	if tenant := GetTenant(ctx); tenant != "" {
 		return tenant, nil
 	}
 
@@ -48,16 +46,20 @@ func getValidTenant(ctx context.Context, tc *Manager) (string, error) {
 	if !ok {
 		return "", status.Errorf(codes.PermissionDenied, "missing tenant header")
 	}
+	return tenantFromMetadata(md, tc.Header)
+}
 
-	var err error
-	tenant, err = tenantFromMetadata(md, tc.Header)
+func getValidTenant(ctx context.Context, tc *Manager) (string, error) {
+	tenant, err := getTenant(ctx, tc)
 	if err != nil {
 		return "", err
 	}
-	if !tc.Valid(tenant) {
-		return tenant, status.Errorf(codes.PermissionDenied, "unknown tenant")
+	if err := tc.admit(tenant); err != nil {
+		if errors.Is(err, errTenantRateLimited) {
+			return tenant, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return tenant, status.Error(codes.PermissionDenied, err.Error())
 	}
-
 	return tenant, nil
 }
 
@@ -69,19 +71,17 @@ func directlyAttachedTenant(ctx context.Context) bool {
 // It also ensures the tenant is directly in the context, rather than context metadata.
 func NewGuardingStreamInterceptor(tc *Manager) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		tenant, err := getValidTenant(ss.Context(), tc)
+		ctx, err := storageContext(ss.Context(), tc)
 		if err != nil {
 			return err
 		}
 
-		if directlyAttachedTenant(ss.Context()) {
+		if ctx == ss.Context() {
 			return handler(srv, ss)
 		}
-
-		// "upgrade" the tenant to be part of the context, rather than just incoming metadata
 		return handler(srv, &tenantedServerStream{
 			ServerStream: ss,
-			context:      WithTenant(ss.Context(), tenant),
+			context:      ctx,
 		})
 	}
 }
@@ -101,16 +101,26 @@ func tenantFromMetadata(md metadata.MD, tenancyHeader string) (string, error) {
 // It also ensures the tenant is directly in the context, rather than context metadata.
 func NewGuardingUnaryInterceptor(tc *Manager) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		tenant, err := getValidTenant(ctx, tc)
+		ctx, err := storageContext(ctx, tc)
 		if err != nil {
 			return nil, err
 		}
+		return handler(ctx, req)
+	}
+}
 
-		if directlyAttachedTenant(ctx) {
+// NewAttachingUnaryInterceptor attaches the tenant of the RPC metadata to the context, when the tenant is valid.
+// Unlike NewGuardingUnaryInterceptor, it does not reject RPCs without a valid tenant, which are handled
+// without a tenant in the context.
+func NewAttachingUnaryInterceptor(tc *Manager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !tc.Enabled || directlyAttachedTenant(ctx) {
 			return handler(ctx, req)
 		}
-
-		return handler(WithTenant(ctx, tenant), req)
+		if tenant, err := getTenant(ctx, tc); err == nil && tc.Valid(tenant) {
+			ctx = WithTenant(ctx, tenant)
+		}
+		return handler(ctx, req)
 	}
 }
 
@@ -113,6 +113,60 @@ func TestTenancyInterceptors(t *testing.T) {
 	}
 }
 
+func TestAttachingUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name       string
+		tenancyMgr *Manager
+		ctx        context.Context
+		tenant     string
+	}{
+		{
+			name:       "untenanted",
+			tenancyMgr: NewManager(&Options{}),
+			ctx:        metadata.NewIncomingContext(context.Background(), map[string][]string{"x-tenant": {"acme"}}),
+			tenant:     "",
+		},
+		{
+			name:       "missing tenant header",
+			tenancyMgr: NewManager(&Options{Enabled: true}),
+			ctx:        context.Background(),
+			tenant:     "",
+		},
+		{
+			name:       "invalid tenant header",
+			tenancyMgr: NewManager(&Options{Enabled: true, Tenants: []string{"megacorp"}}),
+			ctx:        metadata.NewIncomingContext(context.Background(), map[string][]string{"x-tenant": {"acme"}}),
+			tenant:     "",
+		},
+		{
+			name:       "valid tenant header",
+			tenancyMgr: NewManager(&Options{Enabled: true, Tenants: []string{"acme"}}),
+			ctx:        metadata.NewIncomingContext(context.Background(), map[string][]string{"x-tenant": {"acme"}}),
+			tenant:     "acme",
+		},
+		{
+			name:       "valid tenant context",
+			tenancyMgr: NewManager(&Options{Enabled: true}),
+			ctx:        WithTenant(context.Background(), "acme"),
+			tenant:     "acme",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			interceptor := NewAttachingUnaryInterceptor(test.tenancyMgr)
+			var tenant string
+			handler := func(ctx context.Context, req any) (any, error) {
+				tenant = GetTenant(ctx)
+				return req, nil
+			}
+			_, err := interceptor(test.ctx, 0, &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+			assert.Equal(t, test.tenant, tenant)
+		})
+	}
+}
+
 func TestClientUnaryInterceptor(t *testing.T) {
 	tm := NewManager(&Options{Enabled: true, Tenants: []string{"acme"}})
 	interceptor := NewClientUnaryInterceptor(tm)
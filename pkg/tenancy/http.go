@@ -16,6 +16,7 @@ package tenancy
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"google.golang.org/grpc/metadata"
@@ -37,9 +38,16 @@ func ExtractTenantHTTPHandler(tc *Manager, h http.Handler) http.Handler {
 			return
 		}
 
-		if !tc.Valid(tenant) {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("unknown tenant"))
+		if err := tc.admit(tenant); err != nil {
+			switch {
+			case errors.Is(err, errDisabledTenant):
+				w.WriteHeader(http.StatusForbidden)
+			case errors.Is(err, errTenantRateLimited):
+				w.WriteHeader(http.StatusTooManyRequests)
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			w.Write([]byte(err.Error()))
 			return
 		}
 
@@ -48,8 +56,25 @@ func ExtractTenantHTTPHandler(tc *Manager, h http.Handler) http.Handler {
 	})
 }
 
+// AttachTenantHTTPHandler returns a http.Handler which inserts the tenant of the http.Request
+// into request.Context, when the tenant is valid. Unlike ExtractTenantHTTPHandler, requests
+// without a valid tenant are not rejected and are handled without a tenant.
+func AttachTenantHTTPHandler(tc *Manager, h http.Handler) http.Handler {
+	if !tc.Enabled {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenant := r.Header.Get(tc.Header); tenant != "" && tc.Valid(tenant) {
+			r = r.WithContext(WithTenant(r.Context(), tenant))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 // MetadataAnnotator returns a function suitable for propagating tenancy
-// via github.com/grpc-ecosystem/grpc-gateway/runtime.NewServeMux
+// via github.com/grpc-ecosystem/grpc-gateway/runtime.NewServeMux, along with
+// the pass-through headers of the tenant in the tenancy configuration file.
 func (tc *Manager) MetadataAnnotator() func(context.Context, *http.Request) metadata.MD {
 	return func(_ context.Context, req *http.Request) metadata.MD {
 		tenant := req.Header.Get(tc.Header)
@@ -58,8 +83,16 @@ func (tc *Manager) MetadataAnnotator() func(context.Context, *http.Request) meta
 			// empty metadata -- the gRPC query service will reject later.
 			return metadata.Pairs()
 		}
-		return metadata.New(map[string]string{
+		md := metadata.New(map[string]string{
 			tc.Header: tenant,
 		})
+		if config, ok := tc.TenantConfig(tenant); ok {
+			for _, header := range config.PassThroughHeaders {
+				if values := req.Header.Values(header); len(values) > 0 {
+					md.Append(header, values...)
+				}
+			}
+		}
+		return md
 	}
 }
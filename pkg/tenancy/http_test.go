@@ -84,6 +84,61 @@ func TestProgationHandler(t *testing.T) {
 	}
 }
 
+func TestAttachTenantHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		tenancyMgr     *Manager
+		requestHeaders map[string][]string
+		tenant         string
+	}{
+		{
+			name:           "untenanted",
+			tenancyMgr:     NewManager(&Options{}),
+			requestHeaders: map[string][]string{"x-tenant": {"acme"}},
+			tenant:         "",
+		},
+		{
+			name:           "missing tenant header",
+			tenancyMgr:     NewManager(&Options{Enabled: true}),
+			requestHeaders: map[string][]string{},
+			tenant:         "",
+		},
+		{
+			name:           "valid tenant header",
+			tenancyMgr:     NewManager(&Options{Enabled: true}),
+			requestHeaders: map[string][]string{"x-tenant": {"acme"}},
+			tenant:         "acme",
+		},
+		{
+			name:           "unauthorized tenant",
+			tenancyMgr:     NewManager(&Options{Enabled: true, Tenants: []string{"megacorp"}}),
+			requestHeaders: map[string][]string{"x-tenant": {"acme"}},
+			tenant:         "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reached := false
+			var tenant string
+			handler := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				reached = true
+				tenant = GetTenant(r.Context())
+			})
+			req, err := http.NewRequest(http.MethodGet, "/", strings.NewReader(""))
+			require.NoError(t, err)
+			for k, vs := range test.requestHeaders {
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			AttachTenantHTTPHandler(test.tenancyMgr, handler).ServeHTTP(httptest.NewRecorder(), req)
+			assert.True(t, reached)
+			assert.Equal(t, test.tenant, tenant)
+		})
+	}
+}
+
 func TestMetadataAnnotator(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -14,11 +14,26 @@
 
 package tenancy
 
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/fswatcher"
+)
+
 // Options describes the configuration properties for multitenancy
 type Options struct {
 	Enabled bool
 	Header  string
 	Tenants []string
+	// StoragePrefix is prepended to the service and operation names read from the storage,
+	// with "{tenant}" replaced by the tenant of the request. No prefix is applied when empty.
+	StoragePrefix string
+	// ConfigFile is the YAML file configuring each tenant, see LoadConfigFile, which replaces Tenants
+	// and is reloaded when it changes. It is only read by NewManagerWithConfigFile.
+	ConfigFile string
 }
 
 // Manager can check tenant usage for multi-tenant Jaeger configurations
@@ -26,6 +41,12 @@ type Manager struct {
 	Enabled bool
 	Header  string
 	guard   guard
+
+	storagePrefix string
+
+	// tenants holds the tenants of the configuration file, replaced on each reload; nil without a file.
+	tenants atomic.Pointer[tenantConfigs]
+	watcher *fswatcher.FSWatcher
 }
 
 // Guard verifies a valid tenant when tenancy is enabled
@@ -44,13 +65,88 @@ func NewManager(options *Options) *Manager {
 		Enabled: options.Enabled,
 		Header:  header,
 		guard:   tenancyGuardFactory(options),
+
+		storagePrefix: options.StoragePrefix,
+	}
+}
+
+// NewManagerWithConfigFile creates a tenancy.Manager like NewManager, with the tenants configured
+// by options.ConfigFile when set. The file is reloaded when it changes, until Close is called;
+// a file that fails to reload is logged and the previous configuration is kept.
+func NewManagerWithConfigFile(options *Options, logger *zap.Logger) (*Manager, error) {
+	tc := NewManager(options)
+	if options.ConfigFile == "" {
+		return tc, nil
+	}
+	if len(options.Tenants) > 0 {
+		return nil, fmt.Errorf("the tenants cannot be both listed and configured in %s", options.ConfigFile)
+	}
+	if err := tc.loadConfigFile(options.ConfigFile); err != nil {
+		return nil, err
 	}
+	watcher, err := fswatcher.New([]string{options.ConfigFile}, func() {
+		if err := tc.loadConfigFile(options.ConfigFile); err != nil {
+			logger.Error("Failed to reload the tenancy configuration file, keeping the previous tenants", zap.Error(err))
+			return
+		}
+		logger.Info("Reloaded the tenancy configuration file", zap.String("file", options.ConfigFile))
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch the tenancy configuration file: %w", err)
+	}
+	tc.watcher = watcher
+	return tc, nil
 }
 
+func (tc *Manager) loadConfigFile(path string) error {
+	tenants, err := LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	tc.tenants.Store(newTenantConfigs(tenants, tc.tenants.Load()))
+	return nil
+}
+
+// Close stops watching the tenancy configuration file.
+func (tc *Manager) Close() error {
+	if tc.watcher == nil {
+		return nil
+	}
+	return tc.watcher.Close()
+}
+
+// Valid returns whether the tenant is allowed, without counting a request against its rate limit.
 func (tc *Manager) Valid(tenant string) bool {
+	if tenants := tc.tenants.Load(); tenants != nil && tc.Enabled {
+		return tenants.Valid(tenant)
+	}
 	return tc.guard.Valid(tenant)
 }
 
+// admit returns why a request of the tenant is rejected, or nil, counting it against the rate limit
+// of the tenant when configured.
+func (tc *Manager) admit(tenant string) error {
+	if !tc.Enabled {
+		return nil
+	}
+	if tenants := tc.tenants.Load(); tenants != nil {
+		return tenants.admit(tenant)
+	}
+	if !tc.guard.Valid(tenant) {
+		return errUnknownTenant
+	}
+	return nil
+}
+
+// TenantConfig returns the configuration of the tenant in the tenancy configuration file, if any.
+func (tc *Manager) TenantConfig(tenant string) (TenantConfig, bool) {
+	t, ok := tc.tenants.Load().lookup(tenant)
+	if !ok {
+		return TenantConfig{}, false
+	}
+	return t.config, true
+}
+
 type tenantDontCare bool
 
 func (tenantDontCare) Valid(string /* candidate */) bool {
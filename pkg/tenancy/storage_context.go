@@ -0,0 +1,25 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tenancy
+
+import (
+	"context"
+)
+
+// storageContext returns the context passed to the storage for the tenant of the request, which is rejected
+// when the tenant is missing or not valid. The tenant of a request reaches the storage through the context
+// passed to the span readers and writers: it is stored with WithTenant once validated, and the storage
+// implementations read it with GetTenant. Backends serving several tenants must rely on this context rather
+// than on the request headers or metadata, which are not passed to the storage.
+func storageContext(ctx context.Context, tc *Manager) (context.Context, error) {
+	tenant, err := getValidTenant(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+	if directlyAttachedTenant(ctx) {
+		return ctx, nil
+	}
+	// "upgrade" the tenant to be part of the context, rather than just incoming metadata
+	return WithTenant(ctx, tenant), nil
+}
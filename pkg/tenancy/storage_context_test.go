@@ -0,0 +1,79 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGuardingInterceptorsStorageContext(t *testing.T) {
+	tests := []struct {
+		name           string
+		tenancyMgr     *Manager
+		ctx            context.Context
+		expectedTenant string
+		expectedCode   codes.Code
+	}{
+		{
+			name:           "tenant header",
+			tenancyMgr:     NewManager(&Options{Enabled: true, Tenants: []string{"acme"}}),
+			ctx:            metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", "acme")),
+			expectedTenant: "acme",
+		},
+		{
+			name:           "tenant already in the context",
+			tenancyMgr:     NewManager(&Options{Enabled: true}),
+			ctx:            WithTenant(context.Background(), "acme"),
+			expectedTenant: "acme",
+		},
+		{
+			name:         "invalid tenant",
+			tenancyMgr:   NewManager(&Options{Enabled: true, Tenants: []string{"megacorp"}}),
+			ctx:          metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", "acme")),
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "missing tenant",
+			tenancyMgr:   NewManager(&Options{Enabled: true}),
+			ctx:          metadata.NewIncomingContext(context.Background(), metadata.MD{}),
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var storageTenant string
+			uinterceptor := NewGuardingUnaryInterceptor(test.tenancyMgr)
+			_, err := uinterceptor(test.ctx, 0, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+				storageTenant = GetTenant(ctx)
+				return req, nil
+			})
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			assert.Equal(t, test.expectedTenant, storageTenant)
+
+			storageTenant = ""
+			sinterceptor := NewGuardingStreamInterceptor(test.tenancyMgr)
+			err = sinterceptor(0, &tenantedServerStream{context: test.ctx}, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+				storageTenant = GetTenant(ss.Context())
+				return nil
+			})
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			assert.Equal(t, test.expectedTenant, storageTenant)
+		})
+	}
+}
+
+func TestStorageContextKeepsAttachedTenant(t *testing.T) {
+	ctx := WithTenant(context.Background(), "acme")
+	storageCtx, err := storageContext(ctx, NewManager(&Options{Enabled: true}))
+	require.NoError(t, err)
+	assert.Equal(t, ctx, storageCtx)
+}
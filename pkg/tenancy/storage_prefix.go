@@ -0,0 +1,49 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tenancy
+
+import (
+	"context"
+	"strings"
+)
+
+// tenantPlaceholder is replaced by the tenant in Options.StoragePrefix.
+const tenantPlaceholder = "{tenant}"
+
+// storagePrefixOf returns the prefix of the names stored for the tenant of the context,
+// or an empty string when the names are not prefixed.
+func (tc *Manager) storagePrefixOf(ctx context.Context) string {
+	if tc == nil || !tc.Enabled || tc.storagePrefix == "" {
+		return ""
+	}
+	tenant := GetTenant(ctx)
+	if tenant == "" {
+		return ""
+	}
+	return strings.ReplaceAll(tc.storagePrefix, tenantPlaceholder, tenant)
+}
+
+// HasStoragePrefix returns true if the names stored for the tenant of the context are prefixed.
+func (tc *Manager) HasStoragePrefix(ctx context.Context) bool {
+	return tc.storagePrefixOf(ctx) != ""
+}
+
+// ToStorageName returns the name stored for the tenant of the context. The empty name,
+// which means any name in queries, is left as is.
+func (tc *Manager) ToStorageName(ctx context.Context, name string) string {
+	if name == "" {
+		return name
+	}
+	return tc.storagePrefixOf(ctx) + name
+}
+
+// FromStorageName strips the prefix of the tenant of the context from the stored name.
+// It returns false if the name is not prefixed for this tenant, i.e. it belongs to another tenant.
+func (tc *Manager) FromStorageName(ctx context.Context, name string) (string, bool) {
+	prefix := tc.storagePrefixOf(ctx)
+	if prefix == "" {
+		return name, true
+	}
+	return strings.CutPrefix(name, prefix)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoragePrefix(t *testing.T) {
+	tm := NewManager(&Options{Enabled: true, StoragePrefix: "{tenant}."})
+	ctx := WithTenant(context.Background(), "acme")
+
+	assert.True(t, tm.HasStoragePrefix(ctx))
+	assert.Equal(t, "acme.frontend", tm.ToStorageName(ctx, "frontend"))
+	assert.Equal(t, "", tm.ToStorageName(ctx, ""))
+
+	name, ok := tm.FromStorageName(ctx, "acme.frontend")
+	assert.True(t, ok)
+	assert.Equal(t, "frontend", name)
+	_, ok = tm.FromStorageName(ctx, "megacorp.frontend")
+	assert.False(t, ok)
+}
+
+func TestNoStoragePrefix(t *testing.T) {
+	ctx := WithTenant(context.Background(), "acme")
+	for _, tc := range []struct {
+		name string
+		tm   *Manager
+		ctx  context.Context
+	}{
+		{name: "nil manager", tm: nil, ctx: ctx},
+		{name: "tenancy disabled", tm: NewManager(&Options{StoragePrefix: "{tenant}."}), ctx: ctx},
+		{name: "no prefix", tm: NewManager(&Options{Enabled: true}), ctx: ctx},
+		{name: "no tenant", tm: NewManager(&Options{Enabled: true, StoragePrefix: "{tenant}."}), ctx: context.Background()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.False(t, tc.tm.HasStoragePrefix(tc.ctx))
+			assert.Equal(t, "frontend", tc.tm.ToStorageName(tc.ctx, "frontend"))
+			name, ok := tc.tm.FromStorageName(tc.ctx, "frontend")
+			assert.True(t, ok)
+			assert.Equal(t, "frontend", name)
+		})
+	}
+}
@@ -55,6 +55,9 @@ func TestWithDefaultConfiguration(t *testing.T) {
 
 	assert.Empty(t, f.options.Primary.MetricNamespace)
 	assert.Equal(t, "ms", f.options.Primary.LatencyUnit)
+	assert.Equal(t, "classic", f.options.Primary.LatencyHistogramMode)
+	assert.Equal(t, "service_name", f.options.Primary.ServiceLabel)
+	assert.Equal(t, "span_name", f.options.Primary.OperationLabel)
 }
 
 func TestWithConfiguration(t *testing.T) {
@@ -96,6 +99,40 @@ func TestWithConfiguration(t *testing.T) {
 		assert.Equal(t, "mynamespace", f.options.Primary.MetricNamespace)
 		assert.Equal(t, "ms", f.options.Primary.LatencyUnit)
 	})
+	t.Run("with custom metric names and labels of prometheus.query", func(t *testing.T) {
+		f := NewFactory()
+		v, command := config.Viperize(f.AddFlags)
+		err := command.ParseFlags([]string{
+			"--prometheus.query.calls-metric-name=traces_spanmetrics_calls_total",
+			"--prometheus.query.latency-metric-name=traces_spanmetrics_latency",
+			"--prometheus.query.latency-histogram-mode=native",
+			`--prometheus.query.extra-label-matchers=deployment_environment="prod", cluster =~ "eu-(west|north),1"`,
+			"--prometheus.query.service-label=service",
+			"--prometheus.query.operation-label=operation",
+		})
+		require.NoError(t, err)
+		f.InitFromViper(v, zap.NewNop())
+		assert.Equal(t, "traces_spanmetrics_calls_total", f.options.Primary.CallsMetricName)
+		assert.Equal(t, "traces_spanmetrics_latency", f.options.Primary.LatencyMetricName)
+		assert.Equal(t, "native", f.options.Primary.LatencyHistogramMode)
+		assert.Equal(t, []string{`deployment_environment="prod"`, `cluster =~ "eu-(west|north),1"`}, f.options.Primary.ExtraLabelMatchers)
+		assert.Equal(t, "service", f.options.Primary.ServiceLabel)
+		assert.Equal(t, "operation", f.options.Primary.OperationLabel)
+	})
+	for _, flag := range []string{
+		"--prometheus.query.latency-histogram-mode=exponential",
+		"--prometheus.query.service-label=service.name",
+		`--prometheus.query.extra-label-matchers=deployment_environment`,
+		`--prometheus.query.extra-label-matchers=env="prod"}`,
+		`--prometheus.query.extra-label-matchers=env="prod" cluster="eu"`,
+	} {
+		t.Run("with invalid "+flag, func(t *testing.T) {
+			f := NewFactory()
+			v, command := config.Viperize(f.AddFlags)
+			require.NoError(t, command.ParseFlags([]string{flag}))
+			assert.Panics(t, func() { f.InitFromViper(v, zap.NewNop()) })
+		})
+	}
 	t.Run("with invalid prometheus.query.duration-unit", func(t *testing.T) {
 		defer func() {
 			if r := recover(); r == nil {
@@ -28,11 +28,11 @@ type Translator struct {
 	labelMap map[string]string
 }
 
-// New returns a new Translator.
-func New(spanNameLabel string) Translator {
+// New returns a new Translator, renaming the labels holding the service and span names.
+func New(serviceNameLabel, spanNameLabel string) Translator {
 	return Translator{
-		// "operation" is the label name that Jaeger UI expects.
-		labelMap: map[string]string{spanNameLabel: "operation"},
+		// "service_name" and "operation" are the label names that Jaeger UI expects.
+		labelMap: map[string]string{serviceNameLabel: "service_name", spanNameLabel: "operation"},
 	}
 }
 
@@ -36,7 +36,7 @@ func TestToDomainMetricsFamily(t *testing.T) {
 			{Timestamp: model.Time(nowSec * 1000), Value: 1234},
 		},
 	})
-	translator := New("span_name")
+	translator := New("service_name", "span_name")
 	mf, err := translator.ToDomainMetricsFamily("the_metric_name", "the_metric_description", promMetrics)
 	require.NoError(t, err)
 
@@ -69,9 +69,27 @@ func TestToDomainMetricsFamily(t *testing.T) {
 	assert.Equal(t, []*metrics.MetricPoint{{Timestamp: &types.Timestamp{Seconds: nowSec}, Value: wantMpValue}}, mf.Metrics[0].MetricPoints)
 }
 
+func TestToDomainMetricsFamilyCustomLabels(t *testing.T) {
+	promMetrics := model.Matrix{
+		&model.SampleStream{
+			Metric: map[model.LabelName]model.LabelValue{"service": "frontend", "operation_name": "/dispatch"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 1}},
+		},
+	}
+	translator := New("service", "operation_name")
+	mf, err := translator.ToDomainMetricsFamily("the_metric_name", "the_metric_description", promMetrics)
+	require.NoError(t, err)
+
+	require.Len(t, mf.Metrics, 1)
+	assert.ElementsMatch(t, []*metrics.Label{
+		{Name: "service_name", Value: "frontend"},
+		{Name: "operation", Value: "/dispatch"},
+	}, mf.Metrics[0].Labels)
+}
+
 func TestUnexpectedMetricsFamilyType(t *testing.T) {
 	promMetrics := model.Vector{}
-	translator := New("span_name")
+	translator := New("service_name", "span_name")
 	mf, err := translator.ToDomainMetricsFamily("the_metric_name", "the_metric_description", promMetrics)
 
 	assert.NotNil(t, mf)
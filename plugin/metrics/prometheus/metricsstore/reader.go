@@ -43,6 +43,9 @@ import (
 
 const (
 	minStep = time.Millisecond
+
+	defaultServiceLabel   = "service_name"
+	defaultOperationLabel = "span_name"
 )
 
 type (
@@ -55,14 +58,17 @@ type (
 		metricsTranslator dbmodel.Translator
 		latencyMetricName string
 		callsMetricName   string
+		nativeHistogram   bool
+		serviceLabel      string // name of the attribute that contains the service name
 		operationLabel    string // name of the attribute that contains span name / operation
+		extraMatchers     []string
 	}
 
 	promQueryParams struct {
-		groupBy        string
-		spanKindFilter string
-		serviceFilter  string
-		rate           string
+		groupBy       string
+		filters       string
+		serviceFilter string
+		rate          string
 	}
 
 	metricsQueryParams struct {
@@ -90,17 +96,27 @@ func NewMetricsReader(cfg config.Configuration, logger *zap.Logger, tracer trace
 		return nil, fmt.Errorf("failed to initialize prometheus client: %w", err)
 	}
 
-	const operationLabel = "span_name"
+	serviceLabel := cfg.ServiceLabel
+	if serviceLabel == "" {
+		serviceLabel = defaultServiceLabel
+	}
+	operationLabel := cfg.OperationLabel
+	if operationLabel == "" {
+		operationLabel = defaultOperationLabel
+	}
 
 	mr := &MetricsReader{
 		client: promapi.NewAPI(client),
 		logger: logger,
 		tracer: tracer.Tracer("prom-metrics-reader"),
 
-		metricsTranslator: dbmodel.New(operationLabel),
+		metricsTranslator: dbmodel.New(serviceLabel, operationLabel),
 		callsMetricName:   buildFullCallsMetricName(cfg),
 		latencyMetricName: buildFullLatencyMetricName(cfg),
+		nativeHistogram:   cfg.LatencyHistogramMode == config.LatencyHistogramNative,
+		serviceLabel:      serviceLabel,
 		operationLabel:    operationLabel,
+		extraMatchers:     cfg.ExtraLabelMatchers,
 	}
 
 	logger.Info("Prometheus reader initialized", zap.String("addr", cfg.ServerURL))
@@ -115,13 +131,18 @@ func (m MetricsReader) GetLatencies(ctx context.Context, requestParams *metricss
 		metricName:          "service_latencies",
 		metricDesc:          fmt.Sprintf("%.2fth quantile latency, grouped by service", requestParams.Quantile),
 		buildPromQuery: func(p promQueryParams) string {
+			// Native histograms hold all their buckets in a single series, without "le" label.
+			seriesName := m.latencyMetricName + "_bucket"
+			if m.nativeHistogram {
+				seriesName = m.latencyMetricName
+			}
 			return fmt.Sprintf(
-				// Note: p.spanKindFilter can be ""; trailing commas are okay within a timeseries selection.
-				`histogram_quantile(%.2f, sum(rate(%s_bucket{service_name =~ "%s", %s}[%s])) by (%s))`,
+				// Note: p.filters can be ""; trailing commas are okay within a timeseries selection.
+				`histogram_quantile(%.2f, sum(rate(%s{%s, %s}[%s])) by (%s))`,
 				requestParams.Quantile,
-				m.latencyMetricName,
+				seriesName,
 				p.serviceFilter,
-				p.spanKindFilter,
+				p.filters,
 				p.rate,
 				p.groupBy,
 			)
@@ -131,6 +152,9 @@ func (m MetricsReader) GetLatencies(ctx context.Context, requestParams *metricss
 }
 
 func buildFullLatencyMetricName(cfg config.Configuration) string {
+	if cfg.LatencyMetricName != "" {
+		return cfg.LatencyMetricName
+	}
 	metricName := "duration"
 
 	if cfg.MetricNamespace != "" {
@@ -159,11 +183,11 @@ func (m MetricsReader) GetCallRates(ctx context.Context, requestParams *metricss
 		metricDesc:          "calls/sec, grouped by service",
 		buildPromQuery: func(p promQueryParams) string {
 			return fmt.Sprintf(
-				// Note: p.spanKindFilter can be ""; trailing commas are okay within a timeseries selection.
-				`sum(rate(%s{service_name =~ "%s", %s}[%s])) by (%s)`,
+				// Note: p.filters can be ""; trailing commas are okay within a timeseries selection.
+				`sum(rate(%s{%s, %s}[%s])) by (%s)`,
 				m.callsMetricName,
 				p.serviceFilter,
-				p.spanKindFilter,
+				p.filters,
 				p.rate,
 				p.groupBy,
 			)
@@ -173,6 +197,9 @@ func (m MetricsReader) GetCallRates(ctx context.Context, requestParams *metricss
 }
 
 func buildFullCallsMetricName(cfg config.Configuration) string {
+	if cfg.CallsMetricName != "" {
+		return cfg.CallsMetricName
+	}
 	metricName := "calls"
 	if cfg.MetricNamespace != "" {
 		metricName = cfg.MetricNamespace + "_" + metricName
@@ -193,10 +220,10 @@ func (m MetricsReader) GetErrorRates(ctx context.Context, requestParams *metrics
 		metricDesc:          "error rate, computed as a fraction of errors/sec over calls/sec, grouped by service",
 		buildPromQuery: func(p promQueryParams) string {
 			return fmt.Sprintf(
-				// Note: p.spanKindFilter can be ""; trailing commas are okay within a timeseries selection.
-				`sum(rate(%s{service_name =~ "%s", status_code = "STATUS_CODE_ERROR", %s}[%s])) by (%s) / sum(rate(%s{service_name =~ "%s", %s}[%s])) by (%s)`,
-				m.callsMetricName, p.serviceFilter, p.spanKindFilter, p.rate, p.groupBy,
-				m.callsMetricName, p.serviceFilter, p.spanKindFilter, p.rate, p.groupBy,
+				// Note: p.filters can be ""; trailing commas are okay within a timeseries selection.
+				`sum(rate(%s{%s, status_code = "STATUS_CODE_ERROR", %s}[%s])) by (%s) / sum(rate(%s{%s, %s}[%s])) by (%s)`,
+				m.callsMetricName, p.serviceFilter, p.filters, p.rate, p.groupBy,
+				m.callsMetricName, p.serviceFilter, p.filters, p.rate, p.groupBy,
 			)
 		},
 	}
@@ -276,24 +303,25 @@ func (m MetricsReader) executeQuery(ctx context.Context, p metricsQueryParams) (
 }
 
 func (m MetricsReader) buildPromQuery(metricsParams metricsQueryParams) string {
-	groupBy := []string{"service_name"}
+	groupBy := []string{m.serviceLabel}
 	if metricsParams.GroupByOperation {
 		groupBy = append(groupBy, m.operationLabel)
 	}
-	if metricsParams.groupByHistBucket {
+	if metricsParams.groupByHistBucket && !m.nativeHistogram {
 		// Group by the bucket value ("le" => "less than or equal to").
 		groupBy = append(groupBy, "le")
 	}
 
-	spanKindFilter := ""
+	var filters []string
 	if len(metricsParams.SpanKinds) > 0 {
-		spanKindFilter = fmt.Sprintf(`span_kind =~ "%s"`, strings.Join(metricsParams.SpanKinds, "|"))
+		filters = append(filters, fmt.Sprintf(`span_kind =~ "%s"`, strings.Join(metricsParams.SpanKinds, "|")))
 	}
+	filters = append(filters, m.extraMatchers...)
 	promParams := promQueryParams{
-		serviceFilter:  strings.Join(metricsParams.ServiceNames, "|"),
-		spanKindFilter: spanKindFilter,
-		rate:           promqlDurationString(metricsParams.RatePer),
-		groupBy:        strings.Join(groupBy, ","),
+		serviceFilter: fmt.Sprintf(`%s =~ "%s"`, m.serviceLabel, strings.Join(metricsParams.ServiceNames, "|")),
+		filters:       strings.Join(filters, ", "),
+		rate:          promqlDurationString(metricsParams.RatePer),
+		groupBy:       strings.Join(groupBy, ","),
 	}
 	return metricsParams.buildPromQuery(promParams)
 }
@@ -239,6 +239,63 @@ func TestGetLatencies(t *testing.T) {
 			wantPromQlQuery: `histogram_quantile(0.95, sum(rate(duration_seconds_bucket{service_name =~ "emailservice", ` +
 				`span_kind =~ "SPAN_KIND_SERVER"}[10m])) by (service_name,span_name,le))`,
 		},
+		{
+			name:             "custom metric name overrides the namespace and normalization",
+			serviceNames:     []string{"emailservice"},
+			spanKinds:        []string{"SPAN_KIND_SERVER"},
+			groupByOperation: true,
+			updateConfig: func(cfg config.Configuration) config.Configuration {
+				cfg.MetricNamespace = "span_metrics"
+				cfg.NormalizeDuration = true
+				cfg.LatencyMetricName = "traces_spanmetrics_latency"
+				return cfg
+			},
+			wantName:        "service_operation_latencies",
+			wantDescription: "0.95th quantile latency, grouped by service & operation",
+			wantLabels: map[string]string{
+				"operation":    "/OrderResult",
+				"service_name": "emailservice",
+			},
+			wantPromQlQuery: `histogram_quantile(0.95, sum(rate(traces_spanmetrics_latency_bucket{service_name =~ "emailservice", ` +
+				`span_kind =~ "SPAN_KIND_SERVER"}[10m])) by (service_name,span_name,le))`,
+		},
+		{
+			name:             "native histogram is queried without bucket suffix and le grouping",
+			serviceNames:     []string{"emailservice"},
+			spanKinds:        []string{"SPAN_KIND_SERVER"},
+			groupByOperation: false,
+			updateConfig: func(cfg config.Configuration) config.Configuration {
+				cfg.NormalizeDuration = true
+				cfg.LatencyHistogramMode = config.LatencyHistogramNative
+				cfg.ExtraLabelMatchers = []string{`deployment_environment = "prod"`}
+				return cfg
+			},
+			wantName:        "service_latencies",
+			wantDescription: "0.95th quantile latency, grouped by service",
+			wantLabels: map[string]string{
+				"service_name": "emailservice",
+			},
+			wantPromQlQuery: `histogram_quantile(0.95, sum(rate(duration_milliseconds{service_name =~ "emailservice", ` +
+				`span_kind =~ "SPAN_KIND_SERVER", deployment_environment = "prod"}[10m])) by (service_name))`,
+		},
+		{
+			name:             "custom service and operation labels with extra matchers and no span kinds",
+			serviceNames:     []string{"emailservice"},
+			groupByOperation: true,
+			updateConfig: func(cfg config.Configuration) config.Configuration {
+				cfg.ServiceLabel = "service"
+				cfg.OperationLabel = "operation_name"
+				cfg.ExtraLabelMatchers = []string{`deployment_environment = "prod"`, `cluster =~ "eu-.*"`}
+				return cfg
+			},
+			wantName:        "service_operation_latencies",
+			wantDescription: "0.95th quantile latency, grouped by service & operation",
+			wantLabels: map[string]string{
+				"service_name": "emailservice",
+			},
+			wantPromQlQuery: `histogram_quantile(0.95, sum(rate(duration_bucket{service =~ "emailservice", ` +
+				`deployment_environment = "prod", cluster =~ "eu-.*"}[10m])) by (service,operation_name,le))`,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			params := metricsstore.LatenciesQueryParameters{
@@ -340,6 +397,42 @@ func TestGetCallRates(t *testing.T) {
 			wantPromQlQuery: `sum(rate(calls_total{service_name =~ "emailservice", ` +
 				`span_kind =~ "SPAN_KIND_SERVER"}[10m])) by (service_name,span_name)`,
 		},
+		{
+			name:             "custom metric name with extra matchers",
+			serviceNames:     []string{"emailservice"},
+			spanKinds:        []string{"SPAN_KIND_SERVER"},
+			groupByOperation: true,
+			updateConfig: func(cfg config.Configuration) config.Configuration {
+				cfg.MetricNamespace = "span_metrics"
+				cfg.NormalizeCalls = true
+				cfg.CallsMetricName = "traces_spanmetrics_calls_count"
+				cfg.ExtraLabelMatchers = []string{`deployment_environment = "prod"`}
+				return cfg
+			},
+			wantName:        "service_operation_call_rate",
+			wantDescription: "calls/sec, grouped by service & operation",
+			wantLabels: map[string]string{
+				"operation":    "/OrderResult",
+				"service_name": "emailservice",
+			},
+			wantPromQlQuery: `sum(rate(traces_spanmetrics_calls_count{service_name =~ "emailservice", ` +
+				`span_kind =~ "SPAN_KIND_SERVER", deployment_environment = "prod"}[10m])) by (service_name,span_name)`,
+		},
+		{
+			name:             "custom service label",
+			serviceNames:     []string{"frontend", "emailservice"},
+			groupByOperation: false,
+			updateConfig: func(cfg config.Configuration) config.Configuration {
+				cfg.ServiceLabel = "service"
+				return cfg
+			},
+			wantName:        "service_call_rate",
+			wantDescription: "calls/sec, grouped by service",
+			wantLabels: map[string]string{
+				"service_name": "emailservice",
+			},
+			wantPromQlQuery: `sum(rate(calls{service =~ "frontend|emailservice", }[10m])) by (service)`,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			params := metricsstore.CallRateQueryParameters{
@@ -464,6 +557,28 @@ func TestGetErrorRates(t *testing.T) {
 				`span_kind =~ "SPAN_KIND_SERVER"}[10m])) by (service_name,span_name) / ` +
 				`sum(rate(calls_total{service_name =~ "emailservice", span_kind =~ "SPAN_KIND_SERVER"}[10m])) by (service_name,span_name)`,
 		},
+		{
+			name:             "custom metric name, labels and extra matchers",
+			serviceNames:     []string{"emailservice"},
+			spanKinds:        []string{"SPAN_KIND_SERVER"},
+			groupByOperation: true,
+			updateConfig: func(cfg config.Configuration) config.Configuration {
+				cfg.CallsMetricName = "traces_spanmetrics_calls_count"
+				cfg.ServiceLabel = "service"
+				cfg.OperationLabel = "operation_name"
+				cfg.ExtraLabelMatchers = []string{`deployment_environment = "prod"`}
+				return cfg
+			},
+			wantName:        "service_operation_error_rate",
+			wantDescription: "error rate, computed as a fraction of errors/sec over calls/sec, grouped by service & operation",
+			wantLabels: map[string]string{
+				"service_name": "emailservice",
+			},
+			wantPromQlQuery: `sum(rate(traces_spanmetrics_calls_count{service =~ "emailservice", status_code = "STATUS_CODE_ERROR", ` +
+				`span_kind =~ "SPAN_KIND_SERVER", deployment_environment = "prod"}[10m])) by (service,operation_name) / ` +
+				`sum(rate(traces_spanmetrics_calls_count{service =~ "emailservice", span_kind =~ "SPAN_KIND_SERVER", ` +
+				`deployment_environment = "prod"}[10m])) by (service,operation_name)`,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			params := metricsstore.ErrorRateQueryParameters{
@@ -17,6 +17,7 @@ package prometheus
 import (
 	"flag"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -37,6 +38,13 @@ const (
 	suffixNormalizeCalls    = ".query.normalize-calls"
 	suffixNormalizeDuration = ".query.normalize-duration"
 
+	suffixCallsMetricName      = ".query.calls-metric-name"
+	suffixLatencyMetricName    = ".query.latency-metric-name"
+	suffixLatencyHistogramMode = ".query.latency-histogram-mode"
+	suffixExtraLabelMatchers   = ".query.extra-label-matchers"
+	suffixServiceLabel         = ".query.service-label"
+	suffixOperationLabel       = ".query.operation-label"
+
 	defaultServerURL      = "http://localhost:9090"
 	defaultConnectTimeout = 30 * time.Second
 	defaultTokenFilePath  = ""
@@ -46,6 +54,14 @@ const (
 	defaultLatencyUnit                 = "ms"
 	defaultNormalizeCalls              = false
 	defaultNormalizeDuration           = false
+	defaultLatencyHistogramMode        = config.LatencyHistogramClassic
+	defaultServiceLabel                = "service_name"
+	defaultOperationLabel              = "span_name"
+)
+
+var (
+	labelNameRegexp    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	labelMatcherRegexp = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*\s*(?:=|!=|=~|!~)\s*"(?:[^"\\]|\\.)*")\s*(?:,|$)`)
 )
 
 type namespaceConfig struct {
@@ -68,6 +84,10 @@ func NewOptions(primaryNamespace string) *Options {
 		LatencyUnit:       defaultLatencyUnit,
 		NormalizeCalls:    defaultNormalizeCalls,
 		NormalizeDuration: defaultNormalizeCalls,
+
+		LatencyHistogramMode: defaultLatencyHistogramMode,
+		ServiceLabel:         defaultServiceLabel,
+		OperationLabel:       defaultOperationLabel,
 	}
 
 	return &Options{
@@ -107,6 +127,19 @@ func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 			`https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/main/pkg/translator/prometheus/README.md. `+
 			`For example: `+
 			`"duration_bucket" (not normalized) -> "duration_milliseconds_bucket (normalized)"`)
+	flagSet.String(nsConfig.namespace+suffixCallsMetricName, "",
+		`The name of the "calls" metric, overriding the name derived from the namespace and normalization, e.g. "traces_spanmetrics_calls_total".`)
+	flagSet.String(nsConfig.namespace+suffixLatencyMetricName, "",
+		`The name of the "latency" histogram without the "_bucket" suffix, overriding the name derived from the namespace and normalization, `+
+			`e.g. "traces_spanmetrics_latency".`)
+	flagSet.String(nsConfig.namespace+suffixLatencyHistogramMode, defaultLatencyHistogramMode,
+		`How the "latency" histogram is stored: "classic", as "_bucket" series with an "le" label, or "native", as Prometheus native histograms.`)
+	flagSet.String(nsConfig.namespace+suffixExtraLabelMatchers, "",
+		`Comma-separated list of PromQL label matchers added to the selectors of every query, e.g. 'deployment_environment="prod",cluster=~"eu-.*"'.`)
+	flagSet.String(nsConfig.namespace+suffixServiceLabel, defaultServiceLabel,
+		`The label holding the service name in the metrics.`)
+	flagSet.String(nsConfig.namespace+suffixOperationLabel, defaultOperationLabel,
+		`The label holding the operation (span name) in the metrics.`)
 
 	nsConfig.getTLSFlagsConfig().AddFlags(flagSet)
 }
@@ -123,13 +156,32 @@ func (opt *Options) InitFromViper(v *viper.Viper) error {
 	cfg.NormalizeCalls = v.GetBool(cfg.namespace + suffixNormalizeCalls)
 	cfg.NormalizeDuration = v.GetBool(cfg.namespace + suffixNormalizeDuration)
 	cfg.TokenOverrideFromContext = v.GetBool(cfg.namespace + suffixOverrideFromContext)
+	cfg.CallsMetricName = v.GetString(cfg.namespace + suffixCallsMetricName)
+	cfg.LatencyMetricName = v.GetString(cfg.namespace + suffixLatencyMetricName)
+	cfg.LatencyHistogramMode = v.GetString(cfg.namespace + suffixLatencyHistogramMode)
+	cfg.ServiceLabel = v.GetString(cfg.namespace + suffixServiceLabel)
+	cfg.OperationLabel = v.GetString(cfg.namespace + suffixOperationLabel)
 
 	isValidUnit := map[string]bool{"ms": true, "s": true}
 	if _, ok := isValidUnit[cfg.LatencyUnit]; !ok {
 		return fmt.Errorf(`duration-unit must be one of "ms" or "s", not %q`, cfg.LatencyUnit)
 	}
+	if cfg.LatencyHistogramMode != config.LatencyHistogramClassic && cfg.LatencyHistogramMode != config.LatencyHistogramNative {
+		return fmt.Errorf(`latency-histogram-mode must be one of %q or %q, not %q`,
+			config.LatencyHistogramClassic, config.LatencyHistogramNative, cfg.LatencyHistogramMode)
+	}
+	for _, label := range []string{cfg.ServiceLabel, cfg.OperationLabel} {
+		if !labelNameRegexp.MatchString(label) {
+			return fmt.Errorf("invalid label name %q", label)
+		}
+	}
 
 	var err error
+	cfg.ExtraLabelMatchers, err = parseLabelMatchers(v.GetString(cfg.namespace + suffixExtraLabelMatchers))
+	if err != nil {
+		return err
+	}
+
 	cfg.TLS, err = cfg.getTLSFlagsConfig().InitFromViper(v)
 	if err != nil {
 		return fmt.Errorf("failed to process Prometheus TLS options: %w", err)
@@ -137,6 +189,20 @@ func (opt *Options) InitFromViper(v *viper.Viper) error {
 	return nil
 }
 
+// parseLabelMatchers splits a comma-separated list of PromQL label matchers, whose values may contain commas.
+func parseLabelMatchers(list string) ([]string, error) {
+	var matchers []string
+	for rest := list; strings.TrimSpace(rest) != ""; {
+		match := labelMatcherRegexp.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf(`invalid label matchers %q, expected e.g. 'label="value",other=~"regex"'`, list)
+		}
+		matchers = append(matchers, match[1])
+		rest = rest[len(match[0]):]
+	}
+	return matchers, nil
+}
+
 func (config *namespaceConfig) getTLSFlagsConfig() tlscfg.ClientFlagsConfig {
 	return tlscfg.ClientFlagsConfig{
 		Prefix: config.namespace,
@@ -15,6 +15,7 @@
 package adaptive
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -42,6 +43,7 @@ type aggregator struct {
 	postAggregator      *PostAggregator
 	aggregationInterval time.Duration
 	storage             samplingstore.Store
+	attributeStrategies samplingstrategy.AttributeProvider
 	stop                chan struct{}
 	bgFinished          sync.WaitGroup
 }
@@ -67,6 +69,7 @@ func NewAggregator(options Options, logger *zap.Logger, metricsFactory metrics.F
 		aggregationInterval: options.CalculationInterval,
 		postAggregator:      postAggregator,
 		storage:             store,
+		attributeStrategies: options.AttributeStrategies,
 		stop:                make(chan struct{}),
 	}, nil
 }
@@ -159,5 +162,22 @@ func (a *aggregator) HandleRootSpan(span *span_model.Span, logger *zap.Logger) {
 	if samplerType == span_model.SamplerTypeUnrecognized {
 		return
 	}
+	if a.sampledByAttribute(service, span) {
+		return
+	}
 	a.RecordThroughput(service, span.OperationName, samplerType, samplerParam)
 }
+
+// sampledByAttribute returns whether the root span was sampled by an attribute strategy of its service,
+// with its probability rather than the one of its operation, the span being then left out of the throughput.
+func (a *aggregator) sampledByAttribute(service string, span *span_model.Span) bool {
+	if a.attributeStrategies == nil {
+		return false
+	}
+	strategies, err := a.attributeStrategies.GetAttributeStrategies(context.Background(), service)
+	if err != nil {
+		return false
+	}
+	_, ok := samplingstrategy.MatchAttributeStrategy(strategies, span.Tags)
+	return ok
+}
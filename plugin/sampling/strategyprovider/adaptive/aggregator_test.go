@@ -15,6 +15,7 @@
 package adaptive
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
 	epmocks "github.com/jaegertracing/jaeger/plugin/sampling/leaderelection/mocks"
@@ -155,3 +157,39 @@ func TestRecordThroughput(t *testing.T) {
 	a.HandleRootSpan(span, logger)
 	assert.EqualValues(t, 1, a.(*aggregator).currentThroughput["A"]["GET"].Count)
 }
+
+type attributeStrategies []ss.AttributeStrategy
+
+func (s attributeStrategies) GetAttributeStrategies(context.Context, string) ([]ss.AttributeStrategy, error) {
+	return s, nil
+}
+
+func TestRecordThroughputAttributeStrategies(t *testing.T) {
+	testOpts := Options{
+		CalculationInterval:   1 * time.Second,
+		AggregationBuckets:    1,
+		BucketsForCalculation: 1,
+		AttributeStrategies:   attributeStrategies{{Key: "tenant", Value: "vip", SamplingRate: 1}},
+	}
+	logger := zap.NewNop()
+	a, err := NewAggregator(testOpts, logger, metricstest.NewFactory(0), &epmocks.ElectionParticipant{}, &mocks.Store{})
+	require.NoError(t, err)
+
+	rootSpan := func(tenant string) *model.Span {
+		return &model.Span{
+			OperationName: "GET",
+			Process:       &model.Process{ServiceName: "A"},
+			Tags: model.KeyValues{
+				model.String("sampler.type", "probabilistic"),
+				model.String("sampler.param", "0.001"),
+				model.String("tenant", tenant),
+			},
+		}
+	}
+	// the spans sampled by the attribute strategy are not counted in the throughput of their operation
+	a.HandleRootSpan(rootSpan("vip"), logger)
+	require.Empty(t, a.(*aggregator).currentThroughput)
+
+	a.HandleRootSpan(rootSpan("free"), logger)
+	assert.EqualValues(t, 1, a.(*aggregator).currentThroughput["A"]["GET"].Count)
+}
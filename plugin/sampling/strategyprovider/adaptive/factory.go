@@ -17,6 +17,7 @@ package adaptive
 import (
 	"errors"
 	"flag"
+	"fmt"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -26,6 +27,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/plugin"
 	"github.com/jaegertracing/jaeger/plugin/sampling/leaderelection"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/static"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/samplingstore"
 )
@@ -43,6 +45,8 @@ type Factory struct {
 	lock           distributedlock.Lock
 	store          samplingstore.Store
 	participant    *leaderelection.DistributedElectionParticipant
+	// attributeStrategies is the static provider of the attribute strategies file, if any
+	attributeStrategies samplingstrategy.Provider
 }
 
 // NewFactory creates a new Factory.
@@ -87,6 +91,16 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, ssFactory storage.S
 		LeaderLeaseRefreshInterval:   f.options.LeaderLeaseRefreshInterval,
 		Logger:                       f.logger,
 	})
+	if f.options.AttributeStrategiesFile != "" {
+		f.attributeStrategies, err = static.NewProvider(static.Options{
+			StrategiesFile:             f.options.AttributeStrategiesFile,
+			IncludeDefaultOpStrategies: true,
+		}, f.logger)
+		if err != nil {
+			return fmt.Errorf("failed to load the attribute strategies: %w", err)
+		}
+		f.options.AttributeStrategies = f.attributeStrategies.(samplingstrategy.AttributeProvider)
+	}
 	f.participant.Start()
 
 	return nil
@@ -108,5 +122,8 @@ func (f *Factory) CreateStrategyProvider() (samplingstrategy.Provider, samplings
 
 // Closes the factory
 func (f *Factory) Close() error {
+	if f.attributeStrategies != nil {
+		f.attributeStrategies.Close()
+	}
 	return f.participant.Close()
 }
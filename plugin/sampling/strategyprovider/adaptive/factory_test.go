@@ -15,6 +15,7 @@
 package adaptive
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -79,6 +80,34 @@ func TestFactory(t *testing.T) {
 	require.NoError(t, f.Close())
 }
 
+func TestFactoryAttributeStrategies(t *testing.T) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	command.ParseFlags([]string{
+		"--sampling.attribute-strategies-file=../static/fixtures/attribute_strategies.json",
+	})
+	f.InitFromViper(v, zap.NewNop())
+	assert.Equal(t, "../static/fixtures/attribute_strategies.json", f.options.AttributeStrategiesFile)
+
+	require.NoError(t, f.Initialize(metrics.NullFactory, &mockSamplingStoreFactory{}, zap.NewNop()))
+	provider, aggregator, err := f.CreateStrategyProvider()
+	require.NoError(t, err)
+	strategies, err := provider.(ss.AttributeProvider).GetAttributeStrategies(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []ss.AttributeStrategy{
+		{Key: "tenant", Value: "vip", SamplingRate: 1},
+		{Key: "debug", Value: "true", SamplingRate: 1},
+	}, strategies)
+	require.NoError(t, provider.Close())
+	require.NoError(t, aggregator.Close())
+	require.NoError(t, f.Close())
+
+	f = NewFactory()
+	f.options.AttributeStrategiesFile = "fixtures/missing.json"
+	require.ErrorContains(t, f.Initialize(metrics.NullFactory, &mockSamplingStoreFactory{}, zap.NewNop()),
+		"failed to load the attribute strategies")
+}
+
 func TestBadConfigFail(t *testing.T) {
 	tests := []string{
 		"--sampling.aggregation-buckets=0",
@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 )
 
 const (
@@ -33,6 +35,7 @@ const (
 	minSamplesPerSecond          = "sampling.min-samples-per-second"
 	leaderLeaseRefreshInterval   = "sampling.leader-lease-refresh-interval"
 	followerLeaseRefreshInterval = "sampling.follower-lease-refresh-interval"
+	attributeStrategiesFile      = "sampling.attribute-strategies-file"
 
 	defaultTargetSamplesPerSecond       = 1
 	defaultDeltaTolerance               = 0.3
@@ -111,6 +114,16 @@ type Options struct {
 	// FollowerLeaseRefreshInterval is the duration to sleep if this processor is a follower
 	// (ie. failed to gain the leader lock).
 	FollowerLeaseRefreshInterval time.Duration
+
+	// AttributeStrategiesFile is the path of a sampling strategies file, in the format of the static
+	// strategies file, whose attribute strategies are returned to the clients along with the adaptive
+	// probabilities. The other strategies of the file are ignored.
+	AttributeStrategiesFile string
+
+	// AttributeStrategies provides the attribute strategies, loaded by the Factory from AttributeStrategiesFile.
+	// The root spans sampled by an attribute strategy are left out of the throughput of their operation,
+	// since they were not sampled with its probability.
+	AttributeStrategies samplingstrategy.AttributeProvider
 }
 
 // AddFlags adds flags for Options
@@ -148,6 +161,9 @@ func AddFlags(flagSet *flag.FlagSet) {
 	flagSet.Duration(followerLeaseRefreshInterval, defaultFollowerLeaseRefreshInterval,
 		"The duration to sleep if this processor is a follower.",
 	)
+	flagSet.String(attributeStrategiesFile, "",
+		"The path of a sampling strategies file in JSON format, whose attribute strategies are returned to the clients along with the adaptive probabilities.",
+	)
 }
 
 // InitFromViper initializes Options with properties from viper
@@ -163,5 +179,6 @@ func (opts *Options) InitFromViper(v *viper.Viper) *Options {
 	opts.MinSamplesPerSecond = v.GetFloat64(minSamplesPerSecond)
 	opts.LeaderLeaseRefreshInterval = v.GetDuration(leaderLeaseRefreshInterval)
 	opts.FollowerLeaseRefreshInterval = v.GetDuration(followerLeaseRefreshInterval)
+	opts.AttributeStrategiesFile = v.GetString(attributeStrategiesFile)
 	return opts
 }
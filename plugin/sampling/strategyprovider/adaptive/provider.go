@@ -15,12 +15,14 @@
 package adaptive
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/plugin/sampling/leaderelection"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/jaegertracing/jaeger/storage/samplingstore"
@@ -80,6 +82,15 @@ func (ss *Provider) Start() error {
 	return nil
 }
 
+// GetAttributeStrategies implements samplingstrategy.AttributeProvider, returning the attribute strategies
+// of Options.AttributeStrategies, if any.
+func (ss *Provider) GetAttributeStrategies(ctx context.Context, serviceName string) ([]samplingstrategy.AttributeStrategy, error) {
+	if ss.AttributeStrategies == nil {
+		return nil, nil
+	}
+	return ss.AttributeStrategies.GetAttributeStrategies(ctx, serviceName)
+}
+
 // Close stops the service from loading probabilities and generating strategies.
 func (ss *Provider) Close() error {
 	ss.logger.Info("stopping adaptive sampling service")
@@ -15,6 +15,7 @@
 package static
 
 import (
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
 
@@ -45,7 +46,8 @@ func defaultStrategyResponse() *api_v2.SamplingStrategyResponse {
 
 func defaultStrategies() *storedStrategies {
 	s := &storedStrategies{
-		serviceStrategies: make(map[string]*api_v2.SamplingStrategyResponse),
+		serviceStrategies:          make(map[string]*api_v2.SamplingStrategyResponse),
+		serviceAttributeStrategies: make(map[string][]ss.AttributeStrategy),
 	}
 	s.defaultStrategy = defaultStrategyResponse()
 	return s
@@ -24,6 +24,7 @@ import (
 const (
 	// samplingStrategiesFile contains the name of CLI option for config file.
 	samplingStrategiesFile           = "sampling.strategies-file"
+	samplingStrategiesDir            = "sampling.strategies-dir"
 	samplingStrategiesReloadInterval = "sampling.strategies-reload-interval"
 	samplingStrategiesBugfix5270     = "sampling.strategies.bugfix-5270"
 )
@@ -32,6 +33,9 @@ const (
 type Options struct {
 	// StrategiesFile is the path for the sampling strategies file in JSON format
 	StrategiesFile string
+	// StrategiesDir is the path for a directory of per-tenant sampling strategies files in JSON format,
+	// named <tenant>.json, with default.json used for the unknown tenants
+	StrategiesDir string
 	// ReloadInterval is the time interval to check and reload sampling strategies file
 	ReloadInterval time.Duration
 	// Flag for enabling possibly breaking change which includes default operations level
@@ -44,12 +48,14 @@ type Options struct {
 func AddFlags(flagSet *flag.FlagSet) {
 	flagSet.Duration(samplingStrategiesReloadInterval, 0, "Reload interval to check and reload sampling strategies file. Zero value means no reloading")
 	flagSet.String(samplingStrategiesFile, "", "The path for the sampling strategies file in JSON format. See sampling documentation to see format of the file")
+	flagSet.String(samplingStrategiesDir, "", "The path for a directory of per-tenant sampling strategies files in JSON format, named <tenant>.json. The strategies of default.json are used for the unknown tenants")
 	flagSet.Bool(samplingStrategiesBugfix5270, false, "Include default operation level strategies for Ratesampling type service level strategy. Cf. https://github.com/jaegertracing/jaeger/issues/5270")
 }
 
 // InitFromViper initializes Options with properties from viper
 func (opts *Options) InitFromViper(v *viper.Viper) *Options {
 	opts.StrategiesFile = v.GetString(samplingStrategiesFile)
+	opts.StrategiesDir = v.GetString(samplingStrategiesDir)
 	opts.ReloadInterval = v.GetDuration(samplingStrategiesReloadInterval)
 	opts.IncludeDefaultOpStrategies = v.GetBool(samplingStrategiesBugfix5270)
 	return opts
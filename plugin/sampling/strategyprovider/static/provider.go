@@ -41,6 +41,11 @@ type samplingProvider struct {
 	logger *zap.Logger
 
 	storedStrategies atomic.Value // holds *storedStrategies
+	tenantStrategies atomic.Value // holds map[string]*storedStrategies
+
+	// tenantFiles holds the files loaded from the strategies directory, by tenant.
+	// It is only accessed by the goroutine loading the files.
+	tenantFiles map[string]*tenantFile
 
 	cancelFunc context.CancelFunc
 
@@ -50,6 +55,10 @@ type samplingProvider struct {
 type storedStrategies struct {
 	defaultStrategy   *api_v2.SamplingStrategyResponse
 	serviceStrategies map[string]*api_v2.SamplingStrategyResponse
+
+	// the attribute strategies of the services are merged with the default ones
+	defaultAttributeStrategies []ss.AttributeStrategy
+	serviceAttributeStrategies map[string][]ss.AttributeStrategy
 }
 
 type strategyLoader func() ([]byte, error)
@@ -63,6 +72,22 @@ func NewProvider(options Options, logger *zap.Logger) (ss.Provider, error) {
 		options:    options,
 	}
 	h.storedStrategies.Store(defaultStrategies())
+	h.tenantStrategies.Store(map[string]*storedStrategies{})
+
+	if options.StrategiesDir != "" {
+		if options.StrategiesFile != "" {
+			cancelFunc()
+			return nil, fmt.Errorf("only one of %s and %s can be set", samplingStrategiesFile, samplingStrategiesDir)
+		}
+		if err := h.loadStrategiesDir(options.StrategiesDir); err != nil {
+			cancelFunc()
+			return nil, err
+		}
+		if options.ReloadInterval > 0 {
+			go h.autoUpdateStrategiesDir(ctx, options.ReloadInterval, options.StrategiesDir)
+		}
+		return h, nil
+	}
 
 	if options.StrategiesFile == "" {
 		h.logger.Info("No sampling strategies source provided, using defaults")
@@ -78,14 +103,7 @@ func NewProvider(options Options, logger *zap.Logger) (ss.Provider, error) {
 		return h, nil
 	}
 
-	if !h.options.IncludeDefaultOpStrategies {
-		h.logger.Warn("Default operations level strategies will not be included for Ratelimiting service strategies." +
-			"This behavior will be changed in future releases. " +
-			"Cf. https://github.com/jaegertracing/jaeger/issues/5270")
-		h.parseStrategies_deprecated(strategies)
-	} else {
-		h.parseStrategies(strategies)
-	}
+	h.storedStrategies.Store(h.parseStrategiesWithOptions(strategies))
 
 	if options.ReloadInterval > 0 {
 		go h.autoUpdateStrategies(ctx, options.ReloadInterval, loadFn)
@@ -94,8 +112,9 @@ func NewProvider(options Options, logger *zap.Logger) (ss.Provider, error) {
 }
 
 // GetSamplingStrategy implements StrategyStore#GetSamplingStrategy.
-func (h *samplingProvider) GetSamplingStrategy(_ context.Context, serviceName string) (*api_v2.SamplingStrategyResponse, error) {
-	ss := h.storedStrategies.Load().(*storedStrategies)
+// When the strategies are loaded from a directory, the strategies of the tenant of the context are used.
+func (h *samplingProvider) GetSamplingStrategy(ctx context.Context, serviceName string) (*api_v2.SamplingStrategyResponse, error) {
+	ss := h.strategiesOf(ctx)
 	serviceStrategies := ss.serviceStrategies
 	if strategy, ok := serviceStrategies[serviceName]; ok {
 		return strategy, nil
@@ -104,6 +123,16 @@ func (h *samplingProvider) GetSamplingStrategy(_ context.Context, serviceName st
 	return ss.defaultStrategy, nil
 }
 
+// GetAttributeStrategies implements samplingstrategy.AttributeProvider.
+// The services without attribute strategies use the ones of the default strategy.
+func (h *samplingProvider) GetAttributeStrategies(ctx context.Context, serviceName string) ([]ss.AttributeStrategy, error) {
+	ss := h.strategiesOf(ctx)
+	if strategies, ok := ss.serviceAttributeStrategies[serviceName]; ok {
+		return strategies, nil
+	}
+	return ss.defaultAttributeStrategies, nil
+}
+
 // Close stops updating the strategies
 func (h *samplingProvider) Close() error {
 	h.cancelFunc()
@@ -201,7 +230,7 @@ func (h *samplingProvider) updateSamplingStrategy(bytes []byte) error {
 	if err := json.Unmarshal(bytes, &strategies); err != nil {
 		return fmt.Errorf("failed to unmarshal sampling strategies: %w", err)
 	}
-	h.parseStrategies(&strategies)
+	h.storedStrategies.Store(h.parseStrategies(&strategies))
 	h.logger.Info("Updated sampling strategies:" + string(bytes))
 	return nil
 }
@@ -220,7 +249,18 @@ func loadStrategies(loadFn strategyLoader) (*strategies, error) {
 	return strategies, nil
 }
 
-func (h *samplingProvider) parseStrategies_deprecated(strategies *strategies) {
+// parseStrategiesWithOptions parses the strategies the way selected by Options.IncludeDefaultOpStrategies.
+func (h *samplingProvider) parseStrategiesWithOptions(strategies *strategies) *storedStrategies {
+	if !h.options.IncludeDefaultOpStrategies {
+		h.logger.Warn("Default operations level strategies will not be included for Ratelimiting service strategies." +
+			"This behavior will be changed in future releases. " +
+			"Cf. https://github.com/jaegertracing/jaeger/issues/5270")
+		return h.parseStrategies_deprecated(strategies)
+	}
+	return h.parseStrategies(strategies)
+}
+
+func (h *samplingProvider) parseStrategies_deprecated(strategies *strategies) *storedStrategies {
 	newStore := defaultStrategies()
 	if strategies.DefaultStrategy != nil {
 		newStore.defaultStrategy = h.parseServiceStrategies(strategies.DefaultStrategy)
@@ -256,10 +296,11 @@ func (h *samplingProvider) parseStrategies_deprecated(strategies *strategies) {
 				newStore.defaultStrategy.OperationSampling.PerOperationStrategies)
 		}
 	}
-	h.storedStrategies.Store(newStore)
+	h.parseAttributeStrategies(strategies, newStore)
+	return newStore
 }
 
-func (h *samplingProvider) parseStrategies(strategies *strategies) {
+func (h *samplingProvider) parseStrategies(strategies *strategies) *storedStrategies {
 	newStore := defaultStrategies()
 	if strategies.DefaultStrategy != nil {
 		newStore.defaultStrategy = h.parseServiceStrategies(strategies.DefaultStrategy)
@@ -294,7 +335,72 @@ func (h *samplingProvider) parseStrategies(strategies *strategies) {
 			opS.PerOperationStrategies,
 			newStore.defaultStrategy.OperationSampling.PerOperationStrategies)
 	}
-	h.storedStrategies.Store(newStore)
+	h.parseAttributeStrategies(strategies, newStore)
+	return newStore
+}
+
+// parseAttributeStrategies stores the attribute strategies of the default and service strategies,
+// the ones of each service being merged with the default ones.
+func (h *samplingProvider) parseAttributeStrategies(strategies *strategies, store *storedStrategies) {
+	if strategies.DefaultStrategy != nil {
+		store.defaultAttributeStrategies = h.parseServiceAttributeStrategies(strategies.DefaultStrategy)
+	}
+	for _, s := range strategies.ServiceStrategies {
+		if len(s.AttributeStrategies) == 0 {
+			continue
+		}
+		store.serviceAttributeStrategies[s.Service] = mergeAttributeStrategies(
+			h.parseServiceAttributeStrategies(s),
+			store.defaultAttributeStrategies)
+	}
+}
+
+// mergeAttributeStrategies merges two attribute strategies a and b, where a takes precedence over b
+// and is matched first.
+func mergeAttributeStrategies(a, b []ss.AttributeStrategy) []ss.AttributeStrategy {
+	m := make(map[ss.AttributeStrategy]bool)
+	for _, aAttr := range a {
+		m[ss.AttributeStrategy{Key: aAttr.Key, Value: aAttr.Value}] = true
+	}
+	for _, bAttr := range b {
+		if m[ss.AttributeStrategy{Key: bAttr.Key, Value: bAttr.Value}] {
+			continue
+		}
+		a = append(a, bAttr)
+	}
+	return a
+}
+
+// parseServiceAttributeStrategies returns the valid attribute strategies of the service, in order,
+// the invalid ones and the duplicates of a tag being skipped with a warning.
+func (h *samplingProvider) parseServiceAttributeStrategies(strategy *serviceStrategy) []ss.AttributeStrategy {
+	var attributeStrategies []ss.AttributeStrategy
+	seen := make(map[ss.AttributeStrategy]bool)
+	for _, attrStrategy := range strategy.AttributeStrategies {
+		if attrStrategy == nil {
+			continue
+		}
+		tag := ss.AttributeStrategy{Key: attrStrategy.Key, Value: attrStrategy.Value}
+		switch {
+		case attrStrategy.Key == "":
+			h.logger.Warn("Attribute strategy without key, skipping it", zap.Any("strategy", attrStrategy))
+			continue
+		case attrStrategy.Type != samplerTypeProbabilistic:
+			// like the operation strategies, the attribute strategies only support probabilistic sampling
+			h.logger.Warn("Attribute strategies only support probabilistic sampling, skipping it", zap.Any("strategy", attrStrategy))
+			continue
+		case attrStrategy.Param < 0 || attrStrategy.Param > 1:
+			h.logger.Warn("Attribute strategy with a sampling probability outside [0, 1], skipping it", zap.Any("strategy", attrStrategy))
+			continue
+		case seen[tag]:
+			h.logger.Warn("Duplicate attribute strategy, skipping it", zap.Any("strategy", attrStrategy))
+			continue
+		}
+		seen[tag] = true
+		tag.SamplingRate = attrStrategy.Param
+		attributeStrategies = append(attributeStrategies, tag)
+	}
+	return attributeStrategies
 }
 
 // mergePerOperationSamplingStrategies merges two operation strategies a and b, where a takes precedence over b.
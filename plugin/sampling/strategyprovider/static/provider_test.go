@@ -32,6 +32,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 
+	ss "github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 )
@@ -254,6 +255,55 @@ func TestPerOperationSamplingStrategies(t *testing.T) {
 	}
 }
 
+func TestAttributeSamplingStrategies(t *testing.T) {
+	for _, includeDefaultOpStrategies := range []bool{false, true} {
+		logger, buf := testutils.NewLogger()
+		provider, err := NewProvider(Options{
+			StrategiesFile:             "fixtures/attribute_strategies.json",
+			IncludeDefaultOpStrategies: includeDefaultOpStrategies,
+		}, logger)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Duplicate attribute strategy, skipping it")
+		assert.Contains(t, buf.String(), "Attribute strategies only support probabilistic sampling, skipping it")
+		assert.Contains(t, buf.String(), "Attribute strategy without key, skipping it")
+		assert.Contains(t, buf.String(), "Attribute strategy with a sampling probability outside [0, 1], skipping it")
+		attrProvider := provider.(ss.AttributeProvider)
+
+		// the strategies of the service take precedence over the default ones for the same tag
+		strategies, err := attrProvider.GetAttributeStrategies(context.Background(), "foo")
+		require.NoError(t, err)
+		assert.Equal(t, []ss.AttributeStrategy{
+			{Key: "tenant", Value: "vip", SamplingRate: 1},
+			{Key: "debug", Value: "true", SamplingRate: 1},
+		}, strategies)
+
+		// the attribute strategies do not change the operation strategies
+		s, err := provider.GetSamplingStrategy(context.Background(), "foo")
+		require.NoError(t, err)
+		require.NotNil(t, s.OperationSampling)
+		require.Len(t, s.OperationSampling.PerOperationStrategies, 1)
+		assert.EqualValues(t, 0.2, s.OperationSampling.PerOperationStrategies[0].ProbabilisticSampling.SamplingRate)
+
+		defaultStrategies := []ss.AttributeStrategy{
+			{Key: "tenant", Value: "vip", SamplingRate: 0.9},
+			{Key: "debug", Value: "true", SamplingRate: 1},
+		}
+		for _, service := range []string{"bar", "unknown"} {
+			strategies, err = attrProvider.GetAttributeStrategies(context.Background(), service)
+			require.NoError(t, err)
+			assert.Equal(t, defaultStrategies, strategies, service)
+		}
+	}
+}
+
+func TestAttributeSamplingStrategiesWithoutFile(t *testing.T) {
+	provider, err := NewProvider(Options{}, zap.NewNop())
+	require.NoError(t, err)
+	strategies, err := provider.(ss.AttributeProvider).GetAttributeStrategies(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Empty(t, strategies)
+}
+
 func TestMissingServiceSamplingStrategyTypes(t *testing.T) {
 	logger, buf := testutils.NewLogger()
 	provider, err := NewProvider(Options{StrategiesFile: "fixtures/missing-service-types.json"}, logger)
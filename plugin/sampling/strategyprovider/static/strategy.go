@@ -27,10 +27,18 @@ type operationStrategy struct {
 	strategy
 }
 
+// attributeStrategy defines a sampling strategy for the traces whose root span has the tag Key=Value.
+type attributeStrategy struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	strategy
+}
+
 // serviceStrategy defines a service specific sampling strategy.
 type serviceStrategy struct {
 	Service             string               `json:"service"`
 	OperationStrategies []*operationStrategy `json:"operation_strategies"`
+	AttributeStrategies []*attributeStrategy `json:"attribute_strategies"`
 	strategy
 }
 
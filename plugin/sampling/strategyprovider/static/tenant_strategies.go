@@ -0,0 +1,136 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+)
+
+const (
+	strategiesFileExt = ".json"
+	// defaultTenant is the name of the strategies file, without extension, used for the unknown tenants.
+	defaultTenant = "default"
+)
+
+// tenantFile is a strategies file of the strategies directory.
+type tenantFile struct {
+	content    string
+	strategies *storedStrategies
+}
+
+// strategiesOf returns the strategies of the tenant of the context,
+// or the default strategies if the tenant has no strategies file.
+func (h *samplingProvider) strategiesOf(ctx context.Context) *storedStrategies {
+	if tenant := tenancy.GetTenant(ctx); tenant != "" {
+		tenants := h.tenantStrategies.Load().(map[string]*storedStrategies)
+		if ss, ok := tenants[tenant]; ok {
+			return ss
+		}
+	}
+	return h.storedStrategies.Load().(*storedStrategies)
+}
+
+// loadStrategiesDir loads the strategies files of the directory which changed since the last load.
+// A file which fails to load keeps its previously loaded strategies, and a file which was removed
+// drops the strategies of its tenant.
+func (h *samplingProvider) loadStrategiesDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read strategies directory %s: %w", dir, err)
+	}
+
+	files := make(map[string]*tenantFile, len(entries))
+	changed := len(h.tenantFiles) == 0
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != strategiesFileExt {
+			continue
+		}
+		tenant := strings.TrimSuffix(name, strategiesFileExt)
+		last := h.tenantFiles[tenant]
+		file, err := h.loadTenantFile(filepath.Join(dir, name), last)
+		if err != nil {
+			errs = append(errs, err)
+			if last != nil {
+				files[tenant] = last
+			}
+			continue
+		}
+		if file != last {
+			h.logger.Info("Updated sampling strategies", zap.String("tenant", tenant))
+			changed = true
+		}
+		files[tenant] = file
+	}
+	if len(files) != len(h.tenantFiles) {
+		changed = true
+	}
+	h.tenantFiles = files
+
+	if changed {
+		defaultStore := defaultStrategies()
+		tenants := make(map[string]*storedStrategies, len(files))
+		for tenant, file := range files {
+			if tenant == defaultTenant {
+				defaultStore = file.strategies
+				continue
+			}
+			tenants[tenant] = file.strategies
+		}
+		h.storedStrategies.Store(defaultStore)
+		h.tenantStrategies.Store(tenants)
+	}
+	return errors.Join(errs...)
+}
+
+// loadTenantFile loads a strategies file, returning the last loaded file if its content did not change.
+func (h *samplingProvider) loadTenantFile(path string, last *tenantFile) (*tenantFile, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read strategies file %s: %w", path, err)
+	}
+	if last != nil && last.content == string(content) {
+		return last, nil
+	}
+
+	var strategies *strategies
+	if err := json.Unmarshal(content, &strategies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal strategies file %s: %w", path, err)
+	}
+	file := &tenantFile{
+		content:    string(content),
+		strategies: defaultStrategies(),
+	}
+	if strategies != nil {
+		file.strategies = h.parseStrategiesWithOptions(strategies)
+	}
+	return file, nil
+}
+
+func (h *samplingProvider) autoUpdateStrategiesDir(ctx context.Context, interval time.Duration, dir string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := h.loadStrategiesDir(dir); err != nil {
+				h.logger.Error("failed to re-load sampling strategies", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package static
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+)
+
+func writeStrategiesFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func assertTenantSamplingRate(t *testing.T, provider *samplingProvider, tenant string, samplingRate float64) {
+	ctx := context.Background()
+	if tenant != "" {
+		ctx = tenancy.WithTenant(ctx, tenant)
+	}
+	s, err := provider.GetSamplingStrategy(ctx, "foo")
+	require.NoError(t, err)
+	assert.EqualValues(t, makeResponse(api_v2.SamplingStrategyType_PROBABILISTIC, samplingRate), *s)
+}
+
+func TestStrategiesDir(t *testing.T) {
+	dir := t.TempDir()
+	writeStrategiesFile(t, dir, "default.json", strategiesJSON(0.8))
+	writeStrategiesFile(t, dir, "acme.json", strategiesJSON(0.2))
+	writeStrategiesFile(t, dir, "README.md", "not a strategies file")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "megacorp.json"), 0o700))
+
+	ss, err := NewProvider(Options{StrategiesDir: dir, IncludeDefaultOpStrategies: true}, zap.NewNop())
+	require.NoError(t, err)
+	provider := ss.(*samplingProvider)
+	defer provider.Close()
+
+	assertTenantSamplingRate(t, provider, "acme", 0.2)
+	assertTenantSamplingRate(t, provider, "megacorp", 0.8)
+	assertTenantSamplingRate(t, provider, "", 0.8)
+}
+
+func TestStrategiesDirWithoutDefault(t *testing.T) {
+	dir := t.TempDir()
+	writeStrategiesFile(t, dir, "acme.json", strategiesJSON(0.2))
+
+	ss, err := NewProvider(Options{StrategiesDir: dir}, zap.NewNop())
+	require.NoError(t, err)
+	provider := ss.(*samplingProvider)
+	defer provider.Close()
+
+	assertTenantSamplingRate(t, provider, "acme", 0.2)
+	assertTenantSamplingRate(t, provider, "megacorp", 0.001)
+}
+
+func TestStrategiesDirErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewProvider(Options{StrategiesDir: dir, StrategiesFile: "fixtures/strategies.json"}, zap.NewNop())
+	require.EqualError(t, err, "only one of sampling.strategies-file and sampling.strategies-dir can be set")
+
+	_, err = NewProvider(Options{StrategiesDir: filepath.Join(dir, "missing")}, zap.NewNop())
+	require.ErrorContains(t, err, "failed to read strategies directory")
+
+	writeStrategiesFile(t, dir, "acme.json", "bad json")
+	_, err = NewProvider(Options{StrategiesDir: dir}, zap.NewNop())
+	require.ErrorContains(t, err, "failed to unmarshal strategies file "+filepath.Join(dir, "acme.json"))
+}
+
+func TestReloadStrategiesDir(t *testing.T) {
+	dir := t.TempDir()
+	writeStrategiesFile(t, dir, "default.json", strategiesJSON(0.8))
+	writeStrategiesFile(t, dir, "acme.json", strategiesJSON(0.2))
+
+	ss, err := NewProvider(Options{StrategiesDir: dir, IncludeDefaultOpStrategies: true}, zap.NewNop())
+	require.NoError(t, err)
+	provider := ss.(*samplingProvider)
+	defer provider.Close()
+
+	// an unchanged file keeps its strategies
+	acme := provider.tenantFiles["acme"]
+	writeStrategiesFile(t, dir, "default.json", strategiesJSON(0.7))
+	require.NoError(t, provider.loadStrategiesDir(dir))
+	assert.Same(t, acme, provider.tenantFiles["acme"])
+	assertTenantSamplingRate(t, provider, "acme", 0.2)
+	assertTenantSamplingRate(t, provider, "megacorp", 0.7)
+
+	// a file which fails to load keeps its previous strategies
+	writeStrategiesFile(t, dir, "acme.json", "bad json")
+	writeStrategiesFile(t, dir, "megacorp.json", strategiesJSON(0.3))
+	require.ErrorContains(t, provider.loadStrategiesDir(dir), "failed to unmarshal strategies file")
+	assertTenantSamplingRate(t, provider, "acme", 0.2)
+	assertTenantSamplingRate(t, provider, "megacorp", 0.3)
+
+	// a removed file falls back to the default strategies
+	require.NoError(t, os.Remove(filepath.Join(dir, "acme.json")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "default.json")))
+	require.NoError(t, provider.loadStrategiesDir(dir))
+	assertTenantSamplingRate(t, provider, "acme", 0.001)
+	assertTenantSamplingRate(t, provider, "megacorp", 0.3)
+}
+
+func TestAutoUpdateStrategiesDir(t *testing.T) {
+	dir := t.TempDir()
+	writeStrategiesFile(t, dir, "default.json", strategiesJSON(0.8))
+	writeStrategiesFile(t, dir, "acme.json", strategiesJSON(0.2))
+
+	ss, err := NewProvider(Options{
+		StrategiesDir:              dir,
+		ReloadInterval:             10 * time.Millisecond,
+		IncludeDefaultOpStrategies: true,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	provider := ss.(*samplingProvider)
+	defer provider.Close()
+
+	writeStrategiesFile(t, dir, "acme.json", strategiesJSON(0.3))
+	assert.Eventually(t, func() bool {
+		s, err := provider.GetSamplingStrategy(tenancy.WithTenant(context.Background(), "acme"), "foo")
+		return err == nil && s.ProbabilisticSampling.SamplingRate == 0.3
+	}, time.Second, 10*time.Millisecond)
+	assertTenantSamplingRate(t, provider, "megacorp", 0.8)
+}
@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
@@ -68,6 +69,10 @@ type Factory struct {
 
 	tmpDir          string
 	maintenanceDone chan bool
+	metricsFactory  metrics.Factory
+
+	backfillCancel context.CancelFunc
+	backfillDone   sync.WaitGroup
 
 	// TODO initialize via reflection; convert comments to tag 'description'.
 	metrics struct {
@@ -126,6 +131,7 @@ func (f *Factory) configureFromOptions(opts *Options) {
 // Initialize implements storage.Factory
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
 	f.logger = logger
+	f.metricsFactory = metricsFactory
 
 	opts := badger.DefaultOptions("")
 
@@ -160,6 +166,11 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 
 	f.cache = badgerStore.NewCacheStore(f.store, f.Options.Primary.SpanStoreTTL, true)
 
+	if err := f.initializeCompositeIndex(); err != nil {
+		f.store.Close()
+		return err
+	}
+
 	f.metrics.ValueLogSpaceAvailable = metricsFactory.Gauge(metrics.Options{Name: valueLogSpaceAvailableName})
 	f.metrics.KeyLogSpaceAvailable = metricsFactory.Gauge(metrics.Options{Name: keyLogSpaceAvailableName})
 	f.metrics.LastMaintenanceRun = metricsFactory.Gauge(metrics.Options{Name: lastMaintenanceRunName})
@@ -175,6 +186,31 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 	return nil
 }
 
+// initializeCompositeIndex records whether the composite index is maintained, and starts its backfill if requested.
+func (f *Factory) initializeCompositeIndex() error {
+	if f.Options.Primary.ReadOnly {
+		return nil
+	}
+	if !f.Options.Primary.CompositeIndex {
+		return badgerStore.DisableCompositeIndex(f.store)
+	}
+	if err := badgerStore.EnableCompositeIndex(f.store); err != nil {
+		return err
+	}
+	if f.Options.Primary.CompositeIndexBackfill {
+		ctx, cancel := context.WithCancel(context.Background())
+		f.backfillCancel = cancel
+		f.backfillDone.Add(1)
+		go func() {
+			defer f.backfillDone.Done()
+			if _, err := badgerStore.BackfillCompositeIndex(ctx, f.store, f.logger); err != nil && !errors.Is(err, context.Canceled) {
+				f.logger.Error("Failed to backfill the composite index", zap.Error(err))
+			}
+		}()
+	}
+	return nil
+}
+
 // initializeDir makes the directory and parent directories if the path doesn't exists yet.
 func initializeDir(path string) {
 	if _, err := os.Stat(path); err != nil && os.IsNotExist(err) {
@@ -184,12 +220,16 @@ func initializeDir(path string) {
 
 // CreateSpanReader implements storage.Factory
 func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
-	return badgerStore.NewTraceReader(f.store, f.cache), nil
+	return badgerStore.NewTraceReader(f.store, f.cache, badgerStore.WithMetricsFactory(f.metricsFactory)), nil
 }
 
 // CreateSpanWriter implements storage.Factory
 func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
-	return badgerStore.NewSpanWriter(f.store, f.cache, f.Options.Primary.SpanStoreTTL), nil
+	var options []badgerStore.WriterOption
+	if f.Options.Primary.CompositeIndex {
+		options = append(options, badgerStore.WithCompositeIndex())
+	}
+	return badgerStore.NewSpanWriter(f.store, f.cache, f.Options.Primary.SpanStoreTTL, options...), nil
 }
 
 // CreateDependencyReader implements storage.Factory
@@ -214,6 +254,10 @@ func (f *Factory) Close() error {
 	if f.store == nil {
 		return nil
 	}
+	if f.backfillCancel != nil {
+		f.backfillCancel()
+		f.backfillDone.Wait()
+	}
 	err := f.store.Close()
 
 	// Remove tmp files if this was ephemeral storage
@@ -311,7 +355,12 @@ func (f *Factory) registerBadgerExpvarMetrics(metricsFactory metrics.Factory) {
 // This function is intended for testing purposes only and should not be used in production environments.
 // Calling Purge in production will result in permanent data loss.
 func (f *Factory) Purge(_ context.Context) error {
-	return f.store.Update(func(_ *badger.Txn) error {
+	err := f.store.Update(func(_ *badger.Txn) error {
 		return f.store.DropAll()
 	})
+	if err == nil && f.Options.Primary.CompositeIndex {
+		// the composite index coverage was dropped with the data
+		err = badgerStore.EnableCompositeIndex(f.store)
+	}
+	return err
 }
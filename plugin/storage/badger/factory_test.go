@@ -15,6 +15,7 @@
 package badger
 
 import (
+	"context"
 	"expvar"
 	"fmt"
 	"io"
@@ -27,8 +28,10 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 func TestInitializationErrors(t *testing.T) {
@@ -224,3 +227,55 @@ func TestBadgerStorageFactoryWithConfig(t *testing.T) {
 	require.NoError(t, err)
 	defer factory.Close()
 }
+
+func TestCompositeIndexBackfill(t *testing.T) {
+	dir := t.TempDir()
+	startTime := time.Now().Add(-time.Hour)
+	open := func(metricsFactory metrics.Factory, flags ...string) *Factory {
+		f := NewFactory()
+		v, command := config.Viperize(f.AddFlags)
+		command.ParseFlags(append([]string{
+			"--badger.ephemeral=false",
+			"--badger.directory-key=" + dir,
+			"--badger.directory-value=" + dir,
+		}, flags...))
+		f.InitFromViper(v, zap.NewNop())
+		require.NoError(t, f.Initialize(metricsFactory, zap.NewNop()))
+		return f
+	}
+
+	f := open(metrics.NullFactory)
+	sw, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, sw.WriteSpan(context.Background(), &model.Span{
+		TraceID:       model.TraceID{Low: 1},
+		SpanID:        model.SpanID(1),
+		OperationName: "operation",
+		Process:       &model.Process{ServiceName: "service"},
+		StartTime:     startTime,
+		Tags:          []model.KeyValue{model.String("key", "value")},
+	}))
+	require.NoError(t, f.Close())
+
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	f = open(metricsFactory, "--badger.index.composite=true", "--badger.index.composite-backfill=true")
+	defer f.Close()
+	sr, err := f.CreateSpanReader()
+	require.NoError(t, err)
+
+	query := &spanstore.TraceQueryParameters{
+		ServiceName:   "service",
+		OperationName: "operation",
+		Tags:          map[string]string{"key": "value"},
+		StartTimeMin:  startTime.Add(-time.Minute),
+		StartTimeMax:  startTime.Add(time.Minute),
+	}
+	require.Eventually(t, func() bool {
+		traceIDs, err := sr.FindTraceIDs(context.Background(), query)
+		require.NoError(t, err)
+		require.Len(t, traceIDs, 1)
+		counters, _ := metricsFactory.Snapshot()
+		return counters["badger_index_plans|plan=composite"] > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
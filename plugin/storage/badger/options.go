@@ -42,6 +42,11 @@ type NamespaceConfig struct {
 	MaintenanceInterval   time.Duration `mapstructure:"maintenance_interval"`
 	MetricsUpdateInterval time.Duration `mapstructure:"metrics_update_interval"`
 	ReadOnly              bool          `mapstructure:"read_only"`
+	// CompositeIndex enables the composite service+operation+tag index, which speeds up
+	// the searches on a service, an operation and tags.
+	CompositeIndex bool `mapstructure:"composite_index"`
+	// CompositeIndexBackfill indexes the spans stored before the composite index was enabled.
+	CompositeIndexBackfill bool `mapstructure:"composite_index_backfill"`
 }
 
 const (
@@ -59,6 +64,8 @@ const (
 	suffixMaintenanceInterval = ".maintenance-interval"
 	suffixMetricsInterval     = ".metrics-update-interval" // Intended only for testing purposes
 	suffixReadOnly            = ".read-only"
+	suffixCompositeIndex      = ".index.composite"
+	suffixCompositeBackfill   = ".index.composite-backfill"
 	defaultDataDir            = string(os.PathSeparator) + "data"
 	defaultValueDir           = defaultDataDir + string(os.PathSeparator) + "values"
 	defaultKeysDir            = defaultDataDir + string(os.PathSeparator) + "keys"
@@ -136,6 +143,16 @@ func addFlags(flagSet *flag.FlagSet, nsConfig NamespaceConfig) {
 		nsConfig.ReadOnly,
 		"Allows to open badger database in read only mode. Multiple instances can open same database in read-only mode. Values still in the write-ahead-log must be replayed before opening.",
 	)
+	flagSet.Bool(
+		nsConfig.namespace+suffixCompositeIndex,
+		nsConfig.CompositeIndex,
+		"Maintain a composite service+operation+tag index, which speeds up the searches on a service, an operation and tags at the cost of more writes.",
+	)
+	flagSet.Bool(
+		nsConfig.namespace+suffixCompositeBackfill,
+		nsConfig.CompositeIndexBackfill,
+		"Index the spans stored before the composite index was enabled in the background, so that the composite index is used for all the searches.",
+	)
 }
 
 // InitFromViper initializes Options with properties from viper
@@ -152,6 +169,8 @@ func initFromViper(cfg *NamespaceConfig, v *viper.Viper, _ *zap.Logger) {
 	cfg.MaintenanceInterval = v.GetDuration(cfg.namespace + suffixMaintenanceInterval)
 	cfg.MetricsUpdateInterval = v.GetDuration(cfg.namespace + suffixMetricsInterval)
 	cfg.ReadOnly = v.GetBool(cfg.namespace + suffixReadOnly)
+	cfg.CompositeIndex = v.GetBool(cfg.namespace + suffixCompositeIndex)
+	cfg.CompositeIndexBackfill = v.GetBool(cfg.namespace + suffixCompositeBackfill)
 }
 
 // GetPrimary returns the primary namespace configuration
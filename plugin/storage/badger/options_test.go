@@ -32,6 +32,7 @@ func TestDefaultOptionsParsing(t *testing.T) {
 
 	assert.True(t, opts.GetPrimary().Ephemeral)
 	assert.False(t, opts.GetPrimary().SyncWrites)
+	assert.False(t, opts.GetPrimary().CompositeIndex)
 	assert.Equal(t, time.Duration(72*time.Hour), opts.GetPrimary().SpanStoreTTL)
 }
 
@@ -64,3 +65,15 @@ func TestReadOnlyOptions(t *testing.T) {
 	opts.InitFromViper(v, zap.NewNop())
 	assert.True(t, opts.GetPrimary().ReadOnly)
 }
+
+func TestCompositeIndexOptions(t *testing.T) {
+	opts := NewOptions("badger")
+	v, command := config.Viperize(opts.AddFlags)
+	command.ParseFlags([]string{
+		"--badger.index.composite=true",
+		"--badger.index.composite-backfill=true",
+	})
+	opts.InitFromViper(v, zap.NewNop())
+	assert.True(t, opts.GetPrimary().CompositeIndex)
+	assert.True(t, opts.GetPrimary().CompositeIndexBackfill)
+}
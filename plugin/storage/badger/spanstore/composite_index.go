@@ -0,0 +1,185 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+/*
+	The composite index combines the service, operation and tag of a span in a single key:
+
+	KEY: ci<serviceName>0x00<operationName>0x00<tagKey>0x00<tagValue><startTime><traceId>
+
+	A query on a service, an operation and tags is then answered by one seek per tag, instead
+	of intersecting the service+tag and service+operation indexes, which are much larger.
+	Unlike these, the composite index matches the operation and the tag on the same span.
+
+	The composite index is only written when enabled, the metadata key compositeIndexCoverageKey
+	holds the start time from which it is complete, so that older data is still read with the
+	single-field indexes until it is backfilled.
+*/
+
+const (
+	metadataKeyPrefix       byte = 0x10
+	compositeIndexSeparator byte = 0x00
+)
+
+var compositeIndexCoverageKey = []byte{metadataKeyPrefix, compositeIndexKey}
+
+// createCompositeIndexValue returns the index value of the composite index key for the given fields.
+func createCompositeIndexValue(serviceName, operationName, tagKey, tagValue string) []byte {
+	value := make([]byte, 0, len(serviceName)+len(operationName)+len(tagKey)+len(tagValue)+3)
+	value = append(value, serviceName...)
+	value = append(value, compositeIndexSeparator)
+	value = append(value, operationName...)
+	value = append(value, compositeIndexSeparator)
+	value = append(value, tagKey...)
+	value = append(value, compositeIndexSeparator)
+	return append(value, tagValue...)
+}
+
+// createCompositeIndexKeys returns the composite index keys of the span, one per tag, process tag and log field.
+func createCompositeIndexKeys(span *model.Span, startTime uint64) [][]byte {
+	keys := make([][]byte, 0, len(span.Tags)+len(span.Process.Tags))
+	addKey := func(kv model.KeyValue) {
+		value := createCompositeIndexValue(span.Process.ServiceName, span.OperationName, kv.Key, kv.AsString())
+		keys = append(keys, createIndexKey(compositeIndexKey, value, startTime, span.TraceID))
+	}
+	for _, kv := range span.Tags {
+		addKey(kv)
+	}
+	for _, kv := range span.Process.Tags {
+		addKey(kv)
+	}
+	for _, log := range span.Logs {
+		for _, kv := range log.Fields {
+			addKey(kv)
+		}
+	}
+	return keys
+}
+
+// EnableCompositeIndex records that the composite index is complete from now on, unless it was already enabled.
+// It must be called when the spans start being written WithCompositeIndex.
+func EnableCompositeIndex(db *badger.DB) error {
+	return db.Update(func(txn *badger.Txn) error {
+		_, err := txn.Get(compositeIndexCoverageKey)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		return setCompositeIndexCoverage(txn, model.TimeAsEpochMicroseconds(time.Now()))
+	})
+}
+
+// DisableCompositeIndex records that the composite index is no longer maintained, so that it is not read anymore.
+func DisableCompositeIndex(db *badger.DB) error {
+	return db.Update(func(txn *badger.Txn) error {
+		// The key is looked up with an iterator, which unlike Get is not reported in badger's metrics
+		// of the reads.
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: compositeIndexCoverageKey})
+		it.Rewind()
+		found := it.Valid()
+		it.Close()
+		if !found {
+			return nil
+		}
+		return txn.Delete(compositeIndexCoverageKey)
+	})
+}
+
+func setCompositeIndexCoverage(txn *badger.Txn, startTime uint64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, startTime)
+	return txn.Set(compositeIndexCoverageKey, value)
+}
+
+// compositeIndexCoverage returns the start time from which the composite index is complete,
+// and false if the composite index is not enabled.
+func compositeIndexCoverage(db *badger.DB) (uint64, bool) {
+	var startTime uint64
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(compositeIndexCoverageKey)
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			startTime = binary.BigEndian.Uint64(val)
+			return nil
+		})
+	})
+	return startTime, err == nil
+}
+
+// BackfillCompositeIndex writes the composite index keys of the spans stored before the composite
+// index was enabled, which must be done with EnableCompositeIndex first. The keys expire with their span.
+// Once done, the composite index is used for all the queries. It returns the number of spans indexed.
+func BackfillCompositeIndex(ctx context.Context, db *badger.DB, logger *zap.Logger) (int, error) {
+	coverage, enabled := compositeIndexCoverage(db)
+	if !enabled {
+		return 0, errors.New("the composite index is not enabled")
+	}
+	if coverage == 0 {
+		return 0, nil
+	}
+	logger.Info("Backfilling the composite index")
+
+	batch := db.NewWriteBatch()
+	defer batch.Cancel()
+	var spans int
+	err := db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte{spanKeyPrefix}
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// The spans written since the composite index was enabled are indexed again, as their start time
+			// might predate the coverage, which is harmless since the keys are the same.
+			item := it.Item()
+			startTime := binary.BigEndian.Uint64(item.Key()[1+sizeOfTraceID:])
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			span, err := decodeValue(val, item.UserMeta()&encodingTypeBits)
+			if err != nil {
+				return err
+			}
+			for _, key := range createCompositeIndexKeys(span, startTime) {
+				if err := batch.SetEntry(&badger.Entry{Key: key, ExpiresAt: item.ExpiresAt()}); err != nil {
+					return err
+				}
+			}
+			spans++
+		}
+		return nil
+	})
+	if err == nil {
+		err = batch.Flush()
+	}
+	if err != nil {
+		return spans, err
+	}
+	err = db.Update(func(txn *badger.Txn) error {
+		return setCompositeIndexCoverage(txn, 0)
+	})
+	if err == nil {
+		logger.Info("Backfilled the composite index", zap.Int("spans", spans))
+	}
+	return spans, err
+}
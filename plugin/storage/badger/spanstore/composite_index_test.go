@@ -0,0 +1,175 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func TestCreateCompositeIndexValue(t *testing.T) {
+	value := createCompositeIndexValue("service", "operation", "key", "value")
+	assert.Equal(t, []byte("service\x00operation\x00key\x00value"), value)
+}
+
+func TestCreateCompositeIndexKeys(t *testing.T) {
+	span := createDummySpan()
+	keys := createCompositeIndexKeys(&span, 1)
+	// span tag, process tag and log field
+	require.Len(t, keys, 3)
+	expected := createIndexKey(compositeIndexKey, []byte("service\x00operation\x00key\x00value"), 1, span.TraceID)
+	for _, key := range keys {
+		assert.Equal(t, expected, key)
+	}
+}
+
+func TestCompositeIndexCoverage(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		_, enabled := compositeIndexCoverage(store)
+		assert.False(t, enabled)
+
+		before := model.TimeAsEpochMicroseconds(time.Now())
+		require.NoError(t, EnableCompositeIndex(store))
+		coverage, enabled := compositeIndexCoverage(store)
+		assert.True(t, enabled)
+		assert.GreaterOrEqual(t, coverage, before)
+
+		// enabling again keeps the coverage
+		require.NoError(t, EnableCompositeIndex(store))
+		again, _ := compositeIndexCoverage(store)
+		assert.Equal(t, coverage, again)
+
+		require.NoError(t, DisableCompositeIndex(store))
+		_, enabled = compositeIndexCoverage(store)
+		assert.False(t, enabled)
+	})
+}
+
+func compositeTestSpan(traceID, spanID uint64, operation string, startTime time.Time, tags ...model.KeyValue) *model.Span {
+	return &model.Span{
+		TraceID:       model.TraceID{Low: traceID},
+		SpanID:        model.SpanID(spanID),
+		OperationName: operation,
+		Process: &model.Process{
+			ServiceName: "service",
+		},
+		StartTime: startTime,
+		Duration:  time.Millisecond,
+		Tags:      tags,
+	}
+}
+
+func TestCompositeIndexPlan(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		startTime := time.Now().Add(-time.Hour)
+		cache := NewCacheStore(store, time.Hour, true)
+		metricsFactory := metricstest.NewFactory(0)
+		defer metricsFactory.Stop()
+		reader := NewTraceReader(store, cache, WithMetricsFactory(metricsFactory))
+
+		tag := model.String("http.method", "GET")
+		legacyWriter := NewSpanWriter(store, cache, time.Hour)
+		require.NoError(t, legacyWriter.WriteSpan(context.Background(), compositeTestSpan(1, 1, "get", startTime, tag)))
+
+		require.NoError(t, EnableCompositeIndex(store))
+		writer := NewSpanWriter(store, cache, time.Hour, WithCompositeIndex())
+		require.NoError(t, writer.WriteSpan(context.Background(), compositeTestSpan(2, 1, "get", startTime, tag)))
+		// the operation and the tag are on different spans
+		require.NoError(t, writer.WriteSpan(context.Background(), compositeTestSpan(3, 1, "get", startTime)))
+		require.NoError(t, writer.WriteSpan(context.Background(), compositeTestSpan(3, 2, "put", startTime, tag)))
+
+		query := func() *spanstore.TraceQueryParameters {
+			return &spanstore.TraceQueryParameters{
+				ServiceName:   "service",
+				OperationName: "get",
+				Tags:          map[string]string{"http.method": "GET"},
+				StartTimeMin:  startTime.Add(-time.Minute),
+				StartTimeMax:  startTime.Add(time.Minute),
+			}
+		}
+
+		// the composite index does not cover the spans written before it was enabled
+		traceIDs, err := reader.FindTraceIDs(context.Background(), query())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []model.TraceID{{Low: 1}, {Low: 2}, {Low: 3}}, traceIDs)
+
+		spans, err := BackfillCompositeIndex(context.Background(), store, zap.NewNop())
+		require.NoError(t, err)
+		assert.Equal(t, 4, spans)
+		coverage, enabled := compositeIndexCoverage(store)
+		assert.True(t, enabled)
+		assert.Zero(t, coverage)
+
+		traceIDs, err = reader.FindTraceIDs(context.Background(), query())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []model.TraceID{{Low: 1}, {Low: 2}}, traceIDs)
+
+		// a backfill which is done is not run again
+		spans, err = BackfillCompositeIndex(context.Background(), store, zap.NewNop())
+		require.NoError(t, err)
+		assert.Zero(t, spans)
+
+		// without a tag, the composite index is not used
+		noTags := query()
+		noTags.Tags = nil
+		traceIDs, err = reader.FindTraceIDs(context.Background(), noTags)
+		require.NoError(t, err)
+		assert.Len(t, traceIDs, 3)
+
+		require.NoError(t, DisableCompositeIndex(store))
+		traceIDs, err = reader.FindTraceIDs(context.Background(), query())
+		require.NoError(t, err)
+		assert.Len(t, traceIDs, 3)
+
+		metricsFactory.AssertCounterMetrics(t,
+			metricstest.ExpectedMetric{Name: "badger_index_plans", Tags: map[string]string{"plan": "composite"}, Value: 1},
+			metricstest.ExpectedMetric{Name: "badger_index_plans", Tags: map[string]string{"plan": "legacy"}, Value: 3},
+		)
+	})
+}
+
+func TestScanPlanMetric(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		metricsFactory := metricstest.NewFactory(0)
+		defer metricsFactory.Stop()
+		reader := NewTraceReader(store, NewCacheStore(store, time.Hour, true), WithMetricsFactory(metricsFactory))
+
+		_, err := reader.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			StartTimeMin: time.Now().Add(-time.Hour),
+			StartTimeMax: time.Now(),
+		})
+		require.NoError(t, err)
+		metricsFactory.AssertCounterMetrics(t,
+			metricstest.ExpectedMetric{Name: "badger_index_plans", Tags: map[string]string{"plan": "scan"}, Value: 1},
+		)
+	})
+}
+
+func TestBackfillCompositeIndexErrors(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		_, err := BackfillCompositeIndex(context.Background(), store, zap.NewNop())
+		require.EqualError(t, err, "the composite index is not enabled")
+
+		span := createDummySpan()
+		require.NoError(t, NewSpanWriter(store, NewCacheStore(store, time.Hour, true), time.Hour).WriteSpan(context.Background(), &span))
+		require.NoError(t, EnableCompositeIndex(store))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = BackfillCompositeIndex(ctx, store, zap.NewNop())
+		require.ErrorIs(t, err, context.Canceled)
+		coverage, _ := compositeIndexCoverage(store)
+		assert.NotZero(t, coverage)
+	})
+}
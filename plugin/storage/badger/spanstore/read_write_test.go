@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/pprof"
+	"sync"
 	"testing"
 	"time"
 
@@ -289,6 +290,39 @@ func TestIndexSeeks(t *testing.T) {
 	})
 }
 
+func TestCompositeIndexSeeks(t *testing.T) {
+	query := func(tid time.Time) *spanstore.TraceQueryParameters {
+		return &spanstore.TraceQueryParameters{
+			StartTimeMin:  tid,
+			StartTimeMax:  tid.Add(time.Second),
+			ServiceName:   "service-1",
+			OperationName: "operation-5",
+			Tags: map[string]string{
+				"http.method":      "method-1",
+				"http.status_code": "status-1",
+			},
+		}
+	}
+	var legacyIDs []model.TraceID
+	runFactoryTest(t, func(_ testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		tid := time.Now()
+		writeCompositeSpans(sw, 40, 8, tid)
+		var err error
+		legacyIDs, err = sr.FindTraceIDs(context.Background(), query(tid))
+		require.NoError(t, err)
+	})
+	runFactoryTest(t, func(_ testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		// the composite index covers the spans starting after the storage was initialized
+		tid := time.Now()
+		writeCompositeSpans(sw, 40, 8, tid)
+		traceIDs, err := sr.FindTraceIDs(context.Background(), query(tid))
+		require.NoError(t, err)
+		// i%8 == 1 for 40 traces
+		assert.Len(t, traceIDs, 5)
+		assert.ElementsMatch(t, legacyIDs, traceIDs)
+	}, "--badger.index.composite=true")
+}
+
 func TestFindNothing(t *testing.T) {
 	runFactoryTest(t, func(_ testing.TB, _ spanstore.Writer, sr spanstore.Reader) {
 		startT := time.Now()
@@ -426,6 +460,8 @@ func TestPersist(t *testing.T) {
 		}
 		err := sw.WriteSpan(context.Background(), &s)
 		require.NoError(t, err)
+		// syncs the logs of badger
+		require.NoError(t, spanstore.Flush(context.Background(), sw))
 	})
 
 	p(t, dir, func(t *testing.T, _ spanstore.Writer, sr spanstore.Reader) {
@@ -442,8 +478,44 @@ func TestPersist(t *testing.T) {
 	})
 }
 
+func TestFlush(t *testing.T) {
+	runFactoryTest(t, func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		const spans = 50
+		var wg sync.WaitGroup
+		for i := 0; i < spans; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(tb, sw.WriteSpan(context.Background(), &model.Span{
+					TraceID:       model.NewTraceID(1, 1),
+					SpanID:        model.NewSpanID(uint64(i)),
+					OperationName: "operation-f",
+					Process:       model.NewProcess("service-f", nil),
+					StartTime:     time.Now(),
+					Duration:      time.Millisecond,
+				}))
+			}(i)
+		}
+		wg.Wait()
+
+		// all the spans written before the flush are read back
+		require.NoError(tb, spanstore.Flush(context.Background(), sw))
+		trace, err := sr.GetTrace(context.Background(), model.NewTraceID(1, 1))
+		require.NoError(tb, err)
+		assert.Len(tb, trace.Spans, spans)
+		traces, err := sr.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:  "service-f",
+			StartTimeMin: time.Now().Add(-time.Minute),
+			StartTimeMax: time.Now().Add(time.Minute),
+			NumTraces:    10,
+		})
+		require.NoError(tb, err)
+		assert.Len(tb, traces, 1)
+	})
+}
+
 // Opens a badger db and runs a test on it.
-func runFactoryTest(tb testing.TB, test func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader)) {
+func runFactoryTest(tb testing.TB, test func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader), flags ...string) {
 	f := badger.NewFactory()
 	defer func() {
 		require.NoError(tb, f.Close())
@@ -451,10 +523,10 @@ func runFactoryTest(tb testing.TB, test func(tb testing.TB, sw spanstore.Writer,
 
 	opts := badger.NewOptions("badger")
 	v, command := config.Viperize(opts.AddFlags)
-	command.ParseFlags([]string{
+	command.ParseFlags(append([]string{
 		"--badger.ephemeral=true",
 		"--badger.consistency=false",
-	})
+	}, flags...))
 	f.InitFromViper(v, zap.NewNop())
 
 	err := f.Initialize(metrics.NullFactory, zap.NewNop())
@@ -538,6 +610,65 @@ func makeWriteSupports(tagsCount, spans int) ([]model.KeyValue, []string, []stri
 	return tags, services, operations
 }
 
+// writeCompositeSpans writes spans of a few services with many operations, each span having a tag with
+// a value shared by many spans, which is the worst case of the service+tag and service+operation indexes.
+func writeCompositeSpans(sw spanstore.Writer, traces, spans int, tid time.Time) {
+	for i := 0; i < traces; i++ {
+		for j := 0; j < spans; j++ {
+			s := model.Span{
+				TraceID: model.TraceID{
+					Low: uint64(i),
+				},
+				SpanID:        model.SpanID(j),
+				OperationName: fmt.Sprintf("operation-%d", j),
+				Process: &model.Process{
+					ServiceName: fmt.Sprintf("service-%d", j%4),
+				},
+				Tags: []model.KeyValue{
+					model.String("http.method", fmt.Sprintf("method-%d", i%4)),
+					model.String("http.status_code", fmt.Sprintf("status-%d", i%8)),
+				},
+				StartTime: tid.Add(time.Duration(i) * time.Millisecond),
+				Duration:  time.Millisecond,
+			}
+			_ = sw.WriteSpan(context.Background(), &s)
+		}
+	}
+}
+
+func makeCompositeReadBenchmark(b *testing.B, flags ...string) {
+	runLargeFactoryTest(b, func(_ testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
+		tid := time.Now()
+		writeCompositeSpans(sw, 2000, 32, tid)
+
+		params := &spanstore.TraceQueryParameters{
+			StartTimeMin:  tid,
+			StartTimeMax:  tid.Add(time.Hour),
+			ServiceName:   "service-1",
+			OperationName: "operation-5",
+			Tags: map[string]string{
+				"http.method":      "method-1",
+				"http.status_code": "status-1",
+			},
+			NumTraces: 50,
+		}
+
+		b.ResetTimer()
+		for a := 0; a < b.N; a++ {
+			sr.FindTraceIDs(context.Background(), params)
+		}
+		b.StopTimer()
+	}, flags...)
+}
+
+func BenchmarkServiceOperationTagsQuery(b *testing.B) {
+	makeCompositeReadBenchmark(b)
+}
+
+func BenchmarkServiceOperationTagsQueryCompositeIndex(b *testing.B) {
+	makeCompositeReadBenchmark(b, "--badger.index.composite=true")
+}
+
 func makeReadBenchmark(b *testing.B, _ time.Time, params *spanstore.TraceQueryParameters, outputFile string) {
 	runLargeFactoryTest(b, func(_ testing.TB, sw spanstore.Writer, sr spanstore.Reader) {
 		tid := time.Now()
@@ -606,7 +737,7 @@ func BenchmarkServiceIndexLimitFetch(b *testing.B) {
 }
 
 // Opens a badger db and runs a test on it.
-func runLargeFactoryTest(tb testing.TB, test func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader)) {
+func runLargeFactoryTest(tb testing.TB, test func(tb testing.TB, sw spanstore.Writer, sr spanstore.Reader), flags ...string) {
 	assertion := require.New(tb)
 	f := badger.NewFactory()
 	opts := badger.NewOptions("badger")
@@ -618,12 +749,12 @@ func runLargeFactoryTest(tb testing.TB, test func(tb testing.TB, sw spanstore.Wr
 	keyParam := fmt.Sprintf("--badger.directory-key=%s", dir)
 	valueParam := fmt.Sprintf("--badger.directory-value=%s", dir)
 
-	command.ParseFlags([]string{
+	command.ParseFlags(append([]string{
 		"--badger.ephemeral=false",
 		"--badger.consistency=false", // Consistency is false as default to reduce effect of disk speed
 		keyParam,
 		valueParam,
-	})
+	}, flags...))
 
 	f.InitFromViper(v, zap.NewNop())
 
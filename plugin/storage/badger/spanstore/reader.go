@@ -18,15 +18,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/dgraph-io/badger/v4"
 
 	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -66,8 +69,29 @@ const (
 
 // TraceReader reads traces from the local badger store
 type TraceReader struct {
-	store *badger.DB
-	cache *CacheStore
+	store   *badger.DB
+	cache   *CacheStore
+	metrics planMetrics
+}
+
+// planMetrics counts the index plans chosen for the trace searches
+type planMetrics struct {
+	// Composite counts the searches done with the composite service+operation+tag index
+	Composite metrics.Counter `metric:"badger_index_plans" tags:"plan=composite"`
+	// Legacy counts the searches done with the service, operation and tag indexes
+	Legacy metrics.Counter `metric:"badger_index_plans" tags:"plan=legacy"`
+	// Scan counts the searches done by scanning the spans in the time range
+	Scan metrics.Counter `metric:"badger_index_plans" tags:"plan=scan"`
+}
+
+// ReaderOption configures a TraceReader
+type ReaderOption func(r *TraceReader)
+
+// WithMetricsFactory sets the metrics factory used to report the index plans chosen by the TraceReader
+func WithMetricsFactory(metricsFactory metrics.Factory) ReaderOption {
+	return func(r *TraceReader) {
+		metrics.MustInit(&r.metrics, metricsFactory, nil)
+	}
 }
 
 // executionPlan is internal structure to track the index filtering
@@ -85,11 +109,16 @@ type executionPlan struct {
 }
 
 // NewTraceReader returns a TraceReader with cache
-func NewTraceReader(db *badger.DB, c *CacheStore) *TraceReader {
-	return &TraceReader{
+func NewTraceReader(db *badger.DB, c *CacheStore, options ...ReaderOption) *TraceReader {
+	r := &TraceReader{
 		store: db,
 		cache: c,
 	}
+	metrics.MustInit(&r.metrics, metrics.NullFactory, nil)
+	for _, option := range options {
+		option(r)
+	}
+	return r
 }
 
 func decodeValue(val []byte, encodeType byte) (*model.Span, error) {
@@ -172,6 +201,123 @@ func (r *TraceReader) GetTrace(_ context.Context, traceID model.TraceID) (*model
 	return nil, ErrInternalConsistencyError
 }
 
+// DeleteTrace implements spanstore.TraceDeleter#DeleteTrace, it deletes the spans of the trace
+// as well as their index keys.
+func (r *TraceReader) DeleteTrace(_ context.Context, traceID model.TraceID) error {
+	prefix := createPrimaryKeySeekPrefix(traceID)
+	keys := make([][]byte, 0)
+
+	err := r.store.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		val := []byte{}
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			val, err := item.ValueCopy(val)
+			if err != nil {
+				return err
+			}
+			sp, err := decodeValue(val, item.UserMeta()&encodingTypeBits)
+			if err != nil {
+				return err
+			}
+			startTime := model.TimeAsEpochMicroseconds(sp.StartTime)
+			keys = append(keys, item.KeyCopy(nil))
+			keys = append(keys, createIndexKeys(sp, startTime)...)
+			// The composite index keys are deleted even if the index is disabled,
+			// as the spans may have been written while it was enabled.
+			keys = append(keys, createCompositeIndexKeys(sp, startTime)...)
+		}
+		return nil
+	})
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	// A write batch splits the deletes of large traces in several transactions
+	batch := r.store.NewWriteBatch()
+	defer batch.Cancel()
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix with prefix scans of
+// the primary keys, which are ordered by trace ID. The prefix is looked up among the 64-bit trace IDs,
+// formatted with 16 hexadecimal characters, then among the 128-bit trace IDs, formatted with 32.
+func (r *TraceReader) FindTraceIDsByPrefix(_ context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	if len(prefix) > 2*sizeOfTraceID {
+		return nil, nil
+	}
+	hexPrefixes := []string{prefix}
+	if len(prefix) <= sizeOfTraceID {
+		// the high part of the 64-bit trace IDs is zero
+		hexPrefixes = []string{strings.Repeat("0", sizeOfTraceID) + prefix, prefix}
+	}
+	var traceIDs []model.TraceID
+	found := make(map[model.TraceID]struct{})
+	err := r.store.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for _, hexPrefix := range hexPrefixes {
+			seekPrefix, start, err := createPrimaryKeyHexSeekPrefix(hexPrefix)
+			if err != nil {
+				return err
+			}
+			for it.Seek(start); it.ValidForPrefix(seekPrefix); it.Next() {
+				traceIDBytes := it.Item().Key()[1 : sizeOfTraceID+1]
+				if !strings.HasPrefix(hex.EncodeToString(traceIDBytes), hexPrefix) {
+					// the trace IDs starting with the odd-length prefix were all scanned
+					break
+				}
+				traceID := bytesToTraceID(traceIDBytes)
+				if _, ok := found[traceID]; ok {
+					continue
+				}
+				if !strings.HasPrefix(traceID.String(), prefix) {
+					// a 64-bit trace ID found by the scan of the 128-bit ones
+					continue
+				}
+				found[traceID] = struct{}{}
+				traceIDs = append(traceIDs, traceID)
+				if limit > 0 && len(traceIDs) >= limit {
+					return nil
+				}
+			}
+		}
+		return nil
+	})
+	return traceIDs, err
+}
+
+// createPrimaryKeyHexSeekPrefix returns the prefix of the primary keys of the trace IDs whose bytes
+// start with the hexadecimal prefix, and the key the scan of the prefix starts from, the prefix
+// followed by the last hexadecimal character of an odd-length prefix.
+func createPrimaryKeyHexSeekPrefix(hexPrefix string) (seekPrefix []byte, start []byte, err error) {
+	even := len(hexPrefix) &^ 1
+	traceIDPrefix, err := hex.DecodeString(hexPrefix[:even])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid trace ID prefix %q: %w", hexPrefix, err)
+	}
+	seekPrefix = append([]byte{spanKeyPrefix}, traceIDPrefix...)
+	start = seekPrefix
+	if even < len(hexPrefix) {
+		nibble, err := hex.DecodeString(hexPrefix[even:] + "0")
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid trace ID prefix %q: %w", hexPrefix, err)
+		}
+		start = append(append([]byte{}, seekPrefix...), nibble...)
+	}
+	return seekPrefix, start, nil
+}
+
 // scanTimeRange returns all the Traces found between startTs and endTs
 func (r *TraceReader) scanTimeRange(plan *executionPlan) ([]model.TraceID, error) {
 	// We need to do a full table scan
@@ -289,6 +435,39 @@ func serviceQueries(query *spanstore.TraceQueryParameters, indexSeeks [][]byte)
 	return indexSeeks
 }
 
+// compositeQueries parses the query to composite index seeks, one per tag
+func compositeQueries(query *spanstore.TraceQueryParameters, indexSeeks [][]byte) [][]byte {
+	for k, v := range query.Tags {
+		indexValue := createCompositeIndexValue(query.ServiceName, query.OperationName, k, v)
+		indexSearchKey := make([]byte, 0, len(indexValue)+1)
+		indexSearchKey = append(indexSearchKey, compositeIndexKey)
+		indexSearchKey = append(indexSearchKey, indexValue...)
+		indexSeeks = append(indexSeeks, indexSearchKey)
+	}
+	return indexSeeks
+}
+
+// planIndexSeeks chooses the most selective index seeks for the query. The composite index is used
+// when the query has a service, an operation and tags, and the composite index covers the whole time range.
+// It returns no seeks if the time range must be scanned.
+func (r *TraceReader) planIndexSeeks(query *spanstore.TraceQueryParameters) [][]byte {
+	indexSeeks := make([][]byte, 0, 1)
+	if query.ServiceName != "" && query.OperationName != "" && len(query.Tags) > 0 {
+		coverage, enabled := compositeIndexCoverage(r.store)
+		if enabled && coverage <= model.TimeAsEpochMicroseconds(query.StartTimeMin) {
+			r.metrics.Composite.Inc(1)
+			return compositeQueries(query, indexSeeks)
+		}
+	}
+	indexSeeks = serviceQueries(query, indexSeeks)
+	if len(indexSeeks) > 0 {
+		r.metrics.Legacy.Inc(1)
+	} else {
+		r.metrics.Scan.Inc(1)
+	}
+	return indexSeeks
+}
+
 // indexSeeksToTraceIDs does the index scanning against badger based on the parsed index queries
 func (r *TraceReader) indexSeeksToTraceIDs(plan *executionPlan, indexSeeks [][]byte) ([]model.TraceID, error) {
 	for i := len(indexSeeks) - 1; i > 0; i-- {
@@ -473,8 +652,7 @@ func (r *TraceReader) FindTraceIDs(_ context.Context, query *spanstore.TraceQuer
 	setQueryDefaults(query)
 
 	// Find matches using indexes that are using service as part of the key
-	indexSeeks := make([][]byte, 0, 1)
-	indexSeeks = serviceQueries(query, indexSeeks)
+	indexSeeks := r.planIndexSeeks(query)
 
 	startStampBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(startStampBytes, model.TimeAsEpochMicroseconds(query.StartTimeMin))
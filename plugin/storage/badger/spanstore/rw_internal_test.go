@@ -130,6 +130,111 @@ func TestDuplicateTraceIDDetection(t *testing.T) {
 	})
 }
 
+func TestDeleteTrace(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour), WithCompositeIndex())
+		rw := NewTraceReader(store, cache)
+
+		deleted, kept := model.TraceID{High: 1, Low: 1}, model.TraceID{High: 1, Low: 2}
+		testSpan := createDummySpan()
+		for _, traceID := range []model.TraceID{deleted, kept} {
+			testSpan.TraceID = traceID
+			for i := 0; i < 3; i++ {
+				testSpan.SpanID = model.SpanID(i)
+				testSpan.Duration = time.Duration(i+1) * time.Millisecond
+				require.NoError(t, sw.WriteSpan(context.Background(), &testSpan))
+			}
+		}
+
+		require.NoError(t, rw.DeleteTrace(context.Background(), deleted))
+		_, err := rw.GetTrace(context.Background(), deleted)
+		require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+		trace, err := rw.GetTrace(context.Background(), kept)
+		require.NoError(t, err)
+		assert.Len(t, trace.Spans, 3)
+
+		traceIDs, err := rw.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:   "service",
+			OperationName: "operation",
+			Tags:          map[string]string{"key": "value"},
+			StartTimeMax:  time.Now().Add(time.Hour),
+			StartTimeMin:  time.Now().Add(-1 * time.Hour),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []model.TraceID{kept}, traceIDs)
+
+		// no key of the deleted trace is left
+		deletedBytes := createPrimaryKeySeekPrefix(deleted)[1:]
+		err = store.View(func(txn *badger.Txn) error {
+			it := txn.NewIterator(badger.DefaultIteratorOptions)
+			defer it.Close()
+			for it.Rewind(); it.Valid(); it.Next() {
+				key := it.Item().Key()
+				if key[0] == spanKeyPrefix {
+					assert.NotEqual(t, deletedBytes, key[1:sizeOfTraceID+1])
+				} else if key[0]&spanKeyPrefix == spanKeyPrefix {
+					assert.NotEqual(t, deletedBytes, key[len(key)-sizeOfTraceID:])
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		// deleting a trace which is not stored is not an error
+		require.NoError(t, rw.DeleteTrace(context.Background(), deleted))
+	})
+}
+
+func TestFindTraceIDsByPrefix(t *testing.T) {
+	runWithBadger(t, func(store *badger.DB, t *testing.T) {
+		cache := NewCacheStore(store, time.Duration(1*time.Hour), true)
+		sw := NewSpanWriter(store, cache, time.Duration(1*time.Hour))
+		rw := NewTraceReader(store, cache)
+
+		traceIDs := []model.TraceID{
+			{Low: 0xabcd123400000000},
+			{Low: 0xabcd5678abcd1234},
+			{High: 0xabcd123400000000, Low: 1},
+			{High: 0xabcd123400000000, Low: 2},
+			{High: 0xabcd567800000000, Low: 1},
+			{High: 0xabce000000000000, Low: 1},
+		}
+		testSpan := createDummySpan()
+		for _, traceID := range traceIDs {
+			testSpan.TraceID = traceID
+			for i := 0; i < 3; i++ {
+				testSpan.SpanID = model.SpanID(i)
+				require.NoError(t, sw.WriteSpan(context.Background(), &testSpan))
+			}
+		}
+
+		testCases := []struct {
+			prefix   string
+			limit    int
+			expected []model.TraceID
+		}{
+			{prefix: "abcd1234", limit: 10, expected: []model.TraceID{traceIDs[0], traceIDs[2], traceIDs[3]}},
+			{prefix: "abcd1234", limit: 2, expected: []model.TraceID{traceIDs[0], traceIDs[2]}},
+			{prefix: "abcd5", limit: 10, expected: []model.TraceID{traceIDs[1], traceIDs[4]}},
+			{prefix: "abc", limit: 10, expected: traceIDs},
+			{prefix: "abcd5678abcd1234", limit: 10, expected: []model.TraceID{traceIDs[1]}},
+			{prefix: "abcd567800000000000000000000000", limit: 10, expected: []model.TraceID{traceIDs[4]}},
+			// the 64-bit trace IDs are not matched by their 32 hexadecimal characters
+			{prefix: "0000000000000000abcd", limit: 10},
+			{prefix: "abcf", limit: 10},
+		}
+		for _, tc := range testCases {
+			found, err := rw.FindTraceIDsByPrefix(context.Background(), tc.prefix, tc.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, found, tc.prefix)
+		}
+
+		_, err := rw.FindTraceIDsByPrefix(context.Background(), "xyz", 10)
+		require.ErrorContains(t, err, "invalid trace ID prefix")
+	})
+}
+
 func createDummySpan() model.Span {
 	tid := time.Now()
 
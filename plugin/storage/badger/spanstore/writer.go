@@ -19,6 +19,7 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
@@ -41,6 +42,7 @@ const (
 	operationNameIndexKey byte = 0x82
 	tagIndexKey           byte = 0x83
 	durationIndexKey      byte = 0x84
+	compositeIndexKey     byte = 0x85
 	jsonEncoding          byte = 0x01 // Last 4 bits of the meta byte are for encoding type
 	protoEncoding         byte = 0x02 // Last 4 bits of the meta byte are for encoding type
 	defaultEncoding       byte = protoEncoding
@@ -48,24 +50,45 @@ const (
 
 // SpanWriter for writing spans to badger
 type SpanWriter struct {
-	store        *badger.DB
-	ttl          time.Duration
-	cache        *CacheStore
-	encodingType byte
+	store          *badger.DB
+	ttl            time.Duration
+	cache          *CacheStore
+	encodingType   byte
+	compositeIndex bool
+	// inFlight is read-locked by the writes, so that Flush can wait for the writes in progress
+	inFlight sync.RWMutex
+}
+
+// WriterOption configures a SpanWriter
+type WriterOption func(w *SpanWriter)
+
+// WithCompositeIndex makes the SpanWriter maintain the composite service+operation+tag index
+// alongside the single-field indexes.
+func WithCompositeIndex() WriterOption {
+	return func(w *SpanWriter) {
+		w.compositeIndex = true
+	}
 }
 
 // NewSpanWriter returns a SpawnWriter with cache
-func NewSpanWriter(db *badger.DB, c *CacheStore, ttl time.Duration) *SpanWriter {
-	return &SpanWriter{
+func NewSpanWriter(db *badger.DB, c *CacheStore, ttl time.Duration, options ...WriterOption) *SpanWriter {
+	w := &SpanWriter{
 		store:        db,
 		ttl:          ttl,
 		cache:        c,
 		encodingType: defaultEncoding, // TODO Make configurable
 	}
+	for _, option := range options {
+		option(w)
+	}
+	return w
 }
 
 // WriteSpan writes the encoded span as well as creates indexes with defined TTL
 func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
+	w.inFlight.RLock()
+	defer w.inFlight.RUnlock()
+
 	expireTime := uint64(time.Now().Add(w.ttl).Unix())
 	startTime := model.TimeAsEpochMicroseconds(span.StartTime)
 
@@ -78,27 +101,13 @@ func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
 	}
 
 	entriesToStore = append(entriesToStore, trace)
-	entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(serviceNameIndexKey, []byte(span.Process.ServiceName), startTime, span.TraceID), nil, expireTime))
-	entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(operationNameIndexKey, []byte(span.Process.ServiceName+span.OperationName), startTime, span.TraceID), nil, expireTime))
-
-	// It doesn't matter if we overwrite Duration index keys, everything is read at Trace level in any case
-	durationValue := make([]byte, 8)
-	binary.BigEndian.PutUint64(durationValue, uint64(model.DurationAsMicroseconds(span.Duration)))
-	entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(durationIndexKey, durationValue, startTime, span.TraceID), nil, expireTime))
-
-	for _, kv := range span.Tags {
-		// Convert everything to string since queries are done that way also
-		// KEY: it<serviceName><tagsKey><traceId> VALUE: <tagsValue>
-		entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID), nil, expireTime))
-	}
-
-	for _, kv := range span.Process.Tags {
-		entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID), nil, expireTime))
+	for _, key := range createIndexKeys(span, startTime) {
+		entriesToStore = append(entriesToStore, w.createBadgerEntry(key, nil, expireTime))
 	}
 
-	for _, log := range span.Logs {
-		for _, kv := range log.Fields {
-			entriesToStore = append(entriesToStore, w.createBadgerEntry(createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID), nil, expireTime))
+	if w.compositeIndex {
+		for _, key := range createCompositeIndexKeys(span, startTime) {
+			entriesToStore = append(entriesToStore, w.createBadgerEntry(key, nil, expireTime))
 		}
 	}
 
@@ -124,6 +133,48 @@ func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
 	return err
 }
 
+// Flush implements spanstore.WriterFlusher#Flush: it waits for the writes in progress, whose spans
+// and index entries are visible to the reads once committed, then syncs the write-ahead log and
+// the value log of badger, so that the spans are also persisted.
+func (w *SpanWriter) Flush(context.Context) error {
+	w.inFlight.Lock()
+	defer w.inFlight.Unlock()
+	if w.store.Opts().InMemory {
+		// the ephemeral store has no logs to sync
+		return nil
+	}
+	return w.store.Sync()
+}
+
+// createIndexKeys returns the keys of the single-field indexes of the span.
+func createIndexKeys(span *model.Span, startTime uint64) [][]byte {
+	keys := make([][]byte, 0, len(span.Tags)+3+len(span.Process.Tags)+len(span.Logs)*4)
+	keys = append(keys, createIndexKey(serviceNameIndexKey, []byte(span.Process.ServiceName), startTime, span.TraceID))
+	keys = append(keys, createIndexKey(operationNameIndexKey, []byte(span.Process.ServiceName+span.OperationName), startTime, span.TraceID))
+
+	// It doesn't matter if we overwrite Duration index keys, everything is read at Trace level in any case
+	durationValue := make([]byte, 8)
+	binary.BigEndian.PutUint64(durationValue, uint64(model.DurationAsMicroseconds(span.Duration)))
+	keys = append(keys, createIndexKey(durationIndexKey, durationValue, startTime, span.TraceID))
+
+	for _, kv := range span.Tags {
+		// Convert everything to string since queries are done that way also
+		// KEY: it<serviceName><tagsKey><traceId> VALUE: <tagsValue>
+		keys = append(keys, createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID))
+	}
+
+	for _, kv := range span.Process.Tags {
+		keys = append(keys, createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID))
+	}
+
+	for _, log := range span.Logs {
+		for _, kv := range log.Fields {
+			keys = append(keys, createIndexKey(tagIndexKey, []byte(span.Process.ServiceName+kv.Key+kv.AsString()), startTime, span.TraceID))
+		}
+	}
+	return keys
+}
+
 func createIndexKey(indexPrefixKey byte, value []byte, startTime uint64, traceID model.TraceID) []byte {
 	// KEY: indexKey<indexValue><startTime><traceId> (traceId is last 16 bytes of the key)
 	key := make([]byte, 1+len(value)+8+sizeOfTraceID)
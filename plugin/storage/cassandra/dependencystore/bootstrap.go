@@ -22,8 +22,16 @@ import (
 // GetDependencyVersion attempts to determine the version of the dependencies table.
 // TODO: Remove this once we've migrated to V2 permanently. https://github.com/jaegertracing/jaeger/issues/1344
 func GetDependencyVersion(s cassandra.Session) Version {
-	if err := s.Query("SELECT ts from dependencies_v2 limit 1;").Exec(); err != nil {
-		return V1
+	if err := s.Query("SELECT ts_bucket from dependencies_v3 limit 1;").Exec(); err == nil {
+		return V3
 	}
-	return V2
+	if HasDependenciesV2(s) {
+		return V2
+	}
+	return V1
+}
+
+// HasDependenciesV2 returns whether the dependencies_v2 table exists, e.g. to read it along the V3 table.
+func HasDependenciesV2(s cassandra.Session) bool {
+	return s.Query("SELECT ts from dependencies_v2 limit 1;").Exec() == nil
 }
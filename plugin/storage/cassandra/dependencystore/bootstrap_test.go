@@ -17,6 +17,7 @@ package dependencystore
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,14 +35,33 @@ func TestGetDependencyVersionV1(t *testing.T) {
 	query.On("Exec").Return(errors.New("error"))
 
 	assert.Equal(t, V1, GetDependencyVersion(session))
+	assert.False(t, HasDependenciesV2(session))
 }
 
 func TestGetDependencyVersionV2(t *testing.T) {
+	var (
+		session = &mocks.Session{}
+		v3Query = &mocks.Query{}
+		v2Query = &mocks.Query{}
+	)
+	session.On("Query", mock.MatchedBy(func(stmt string) bool {
+		return strings.Contains(stmt, "dependencies_v3")
+	}), mock.Anything).Return(v3Query)
+	session.On("Query", mock.MatchedBy(func(stmt string) bool {
+		return strings.Contains(stmt, "dependencies_v2")
+	}), mock.Anything).Return(v2Query)
+	v3Query.On("Exec").Return(errors.New("unconfigured table dependencies_v3"))
+	v2Query.On("Exec").Return(nil)
+	assert.Equal(t, V2, GetDependencyVersion(session))
+	assert.True(t, HasDependenciesV2(session))
+}
+
+func TestGetDependencyVersionV3(t *testing.T) {
 	var (
 		session = &mocks.Session{}
 		query   = &mocks.Query{}
 	)
 	session.On("Query", mock.AnythingOfType("string"), mock.Anything).Return(query)
 	query.On("Exec").Return(nil)
-	assert.Equal(t, V2, GetDependencyVersion(session))
+	assert.Equal(t, V3, GetDependencyVersion(session))
 }
@@ -0,0 +1,47 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"time"
+)
+
+// DefaultWindow is the default duration of the aggregation windows of the dependencies_v3 table.
+const DefaultWindow = time.Hour
+
+// Option is a function that sets some option on the dependency store.
+type Option func(o *Options)
+
+// Options control behavior of the dependency store.
+type Options struct {
+	window     time.Duration
+	readLegacy bool
+}
+
+// Window sets the duration of the aggregation windows of the V3 table, which must be
+// the same for all the writers and readers of the table.
+func Window(window time.Duration) Option {
+	return func(o *Options) {
+		o.window = window
+	}
+}
+
+// ReadLegacyDependencies makes the V3 store also read the dependencies of the dependencies_v2 table,
+// e.g. written before the migration or by the Spark dependencies job.
+func ReadLegacyDependencies() Option {
+	return func(o *Options) {
+		o.readLegacy = true
+	}
+}
+
+func applyOptions(opts ...Option) Options {
+	o := Options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.window <= 0 {
+		o.window = DefaultWindow
+	}
+	return o
+}
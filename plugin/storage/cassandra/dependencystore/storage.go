@@ -43,15 +43,25 @@ const (
 
 	// V2 is used when the dependency table is NOT SASI indexed.
 	V2
+
+	// V3 is used when the dependencies are aggregated in windows, with a row per link and window.
+	V3
 	versionEnumEnd
 
 	depsInsertStmtV1 = "INSERT INTO dependencies(ts, ts_index, dependencies) VALUES (?, ?, ?)"
 	depsInsertStmtV2 = "INSERT INTO dependencies_v2(ts, ts_bucket, dependencies) VALUES (?, ?, ?)"
+	depsInsertStmtV3 = "INSERT INTO dependencies_v3(ts_bucket, parent, child, source, call_count) VALUES (?, ?, ?, ?, ?) IF NOT EXISTS"
+	depsUpdateStmtV3 = "UPDATE dependencies_v3 SET call_count = ? WHERE ts_bucket = ? AND parent = ? AND child = ? AND source = ? IF call_count = ?"
 	depsSelectStmtV1 = "SELECT ts, dependencies FROM dependencies WHERE ts_index >= ? AND ts_index < ?"
 	depsSelectStmtV2 = "SELECT ts, dependencies FROM dependencies_v2 WHERE ts_bucket IN ? AND ts >= ? AND ts < ?"
+	depsSelectStmtV3 = "SELECT parent, child, source, call_count FROM dependencies_v3 WHERE ts_bucket IN ?"
 
 	// TODO: Make this customizable.
 	tsBucket = 24 * time.Hour
+
+	// maxUpsertAttempts bounds the attempts to increment the call count of a link
+	// updated concurrently by other writers.
+	maxUpsertAttempts = 10
 )
 
 var errInvalidVersion = errors.New("invalid version")
@@ -62,6 +72,7 @@ type DependencyStore struct {
 	dependenciesTableMetrics *casMetrics.Table
 	logger                   *zap.Logger
 	version                  Version
+	options                  Options
 }
 
 // NewDependencyStore returns a DependencyStore
@@ -70,6 +81,7 @@ func NewDependencyStore(
 	metricsFactory metrics.Factory,
 	logger *zap.Logger,
 	version Version,
+	opts ...Option,
 ) (*DependencyStore, error) {
 	if !version.IsValid() {
 		return nil, errInvalidVersion
@@ -79,11 +91,18 @@ func NewDependencyStore(
 		dependenciesTableMetrics: casMetrics.NewTable(metricsFactory, "dependencies"),
 		logger:                   logger,
 		version:                  version,
+		options:                  applyOptions(opts...),
 	}, nil
 }
 
 // WriteDependencies implements dependencystore.Writer#WriteDependencies.
+//
+// With V3, the call counts are added to the ones of the window of ts, so that the dependencies
+// can be flushed as often as needed without duplicating the links.
 func (s *DependencyStore) WriteDependencies(ts time.Time, dependencies []model.DependencyLink) error {
+	if s.version == V3 {
+		return s.upsertDependencies(ts.Truncate(s.options.window), dependencies)
+	}
 	deps := make([]Dependency, len(dependencies))
 	for i, d := range dependencies {
 		deps[i] = Dependency{
@@ -105,14 +124,24 @@ func (s *DependencyStore) WriteDependencies(ts time.Time, dependencies []model.D
 }
 
 // GetDependencies returns all interservice dependencies
+//
+// With V3, the links of the windows intersecting the lookback are merged, along the ones
+// of the dependencies_v2 table when reading it too.
 func (s *DependencyStore) GetDependencies(_ context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	if s.version == V3 {
+		return s.getMergedDependencies(endTs, lookback)
+	}
+	return s.getDependencies(s.version, endTs, lookback)
+}
+
+func (s *DependencyStore) getDependencies(version Version, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
 	startTs := endTs.Add(-1 * lookback)
 	var query cassandra.Query
-	switch s.version {
+	switch version {
 	case V1:
 		query = s.session.Query(depsSelectStmtV1, startTs, endTs)
 	case V2:
-		query = s.session.Query(depsSelectStmtV2, getBuckets(startTs, endTs), startTs, endTs)
+		query = s.session.Query(depsSelectStmtV2, getBuckets(startTs, endTs, tsBucket), startTs, endTs)
 	}
 	iter := query.Consistency(cassandra.One).Iter()
 
@@ -138,10 +167,106 @@ func (s *DependencyStore) GetDependencies(_ context.Context, endTs time.Time, lo
 	return mDependency, nil
 }
 
-func getBuckets(startTs time.Time, endTs time.Time) []time.Time {
+// getMergedDependencies reads the links of the windows intersecting the lookback, which may thus start
+// before endTs-lookback, and adds up the call counts of the same links.
+func (s *DependencyStore) getMergedDependencies(endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	startTs := endTs.Add(-1 * lookback)
+	iter := s.session.Query(depsSelectStmtV3, getBuckets(startTs, endTs, s.options.window)).Consistency(cassandra.One).Iter()
+
+	var links []model.DependencyLink
+	var dependency Dependency
+	for iter.Scan(&dependency.Parent, &dependency.Child, &dependency.Source, &dependency.CallCount) {
+		links = append(links, model.DependencyLink{
+			Parent:    dependency.Parent,
+			Child:     dependency.Child,
+			CallCount: uint64(dependency.CallCount),
+			Source:    dependency.Source,
+		}.ApplyDefaults())
+	}
+	if err := iter.Close(); err != nil {
+		s.logger.Error("Failure to read Dependencies", zap.Time("endTs", endTs), zap.Duration("lookback", lookback), zap.Error(err))
+		return nil, fmt.Errorf("error reading dependencies from storage: %w", err)
+	}
+
+	if s.options.readLegacy {
+		legacyLinks, err := s.getDependencies(V2, endTs, lookback)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, legacyLinks...)
+	}
+	return mergeDependencies(links), nil
+}
+
+// mergeDependencies adds up the call counts of the same links, keeping the order in which they are first found.
+func mergeDependencies(links []model.DependencyLink) []model.DependencyLink {
+	type key struct {
+		parent, child, source string
+	}
+	indexes := make(map[key]int, len(links))
+	var merged []model.DependencyLink
+	for _, link := range links {
+		k := key{parent: link.Parent, child: link.Child, source: link.Source}
+		if i, ok := indexes[k]; ok {
+			merged[i].CallCount += link.CallCount
+			continue
+		}
+		indexes[k] = len(merged)
+		merged = append(merged, link)
+	}
+	return merged
+}
+
+// upsertDependencies adds the call counts of the links to the ones of the window.
+func (s *DependencyStore) upsertDependencies(bucket time.Time, dependencies []model.DependencyLink) error {
+	for _, d := range dependencies {
+		d = d.ApplyDefaults()
+		start := time.Now()
+		err := s.upsertDependency(bucket, Dependency{
+			Parent:    d.Parent,
+			Child:     d.Child,
+			CallCount: int64(d.CallCount),
+			Source:    d.Source,
+		})
+		s.dependenciesTableMetrics.Emit(err, time.Since(start))
+		if err != nil {
+			s.logger.Error("Failed to write dependency", zap.String("parent", d.Parent), zap.String("child", d.Child), zap.Error(err))
+			return fmt.Errorf("failed to write dependency %s -> %s: %w", d.Parent, d.Child, err)
+		}
+	}
+	return nil
+}
+
+// upsertDependency inserts the link in the window, or adds its call count to the one stored,
+// with lightweight transactions so that concurrent writers do not lose each other's counts.
+// Counter columns would not need them, but cannot expire with the TTL of the table.
+func (s *DependencyStore) upsertDependency(bucket time.Time, d Dependency) error {
+	var (
+		existingBucket                time.Time
+		existingParent, existingChild string
+		existingSource                string
+		current                       int64
+	)
+	applied, err := s.session.Query(depsInsertStmtV3, bucket, d.Parent, d.Child, d.Source, d.CallCount).
+		ScanCAS(&existingBucket, &existingParent, &existingChild, &existingSource, &current)
+	if err != nil || applied {
+		return err
+	}
+	for attempt := 0; attempt < maxUpsertAttempts; attempt++ {
+		// when not applied, the call count stored in the meantime is returned
+		applied, err = s.session.Query(depsUpdateStmtV3, current+d.CallCount, bucket, d.Parent, d.Child, d.Source, current).
+			ScanCAS(&current)
+		if err != nil || applied {
+			return err
+		}
+	}
+	return fmt.Errorf("call count updated concurrently %d times", maxUpsertAttempts)
+}
+
+func getBuckets(startTs time.Time, endTs time.Time, bucket time.Duration) []time.Time {
 	// TODO: Preallocate the array using some maths and maybe use a pool? This endpoint probably isn't used enough to warrant this.
 	var tsBuckets []time.Time
-	for ts := startTs.Truncate(tsBucket); ts.Before(endTs); ts = ts.Add(tsBucket) {
+	for ts := startTs.Truncate(bucket); ts.Before(endTs); ts = ts.Add(bucket) {
 		tsBuckets = append(tsBuckets, ts)
 	}
 	return tsBuckets
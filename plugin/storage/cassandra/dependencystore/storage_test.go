@@ -43,12 +43,12 @@ type depStorageTest struct {
 	storage   *DependencyStore
 }
 
-func withDepStore(version Version, fn func(s *depStorageTest)) {
+func withDepStore(version Version, fn func(s *depStorageTest), opts ...Option) {
 	session := &mocks.Session{}
 	logger, logBuffer := testutils.NewLogger()
 	metricsFactory := metricstest.NewFactory(time.Second)
 	defer metricsFactory.Stop()
-	store, _ := NewDependencyStore(session, metricsFactory, logger, version)
+	store, _ := NewDependencyStore(session, metricsFactory, logger, version, opts...)
 	s := &depStorageTest{
 		session:   session,
 		logger:    logger,
@@ -66,6 +66,7 @@ var (
 func TestVersionIsValid(t *testing.T) {
 	assert.True(t, V1.IsValid())
 	assert.True(t, V2.IsValid())
+	assert.True(t, V3.IsValid())
 	assert.False(t, versionEnumEnd.IsValid())
 }
 
@@ -254,6 +255,170 @@ func TestDependencyStoreGetDependencies(t *testing.T) {
 	}
 }
 
+// casQuery returns a lightweight transaction, which stores the call count in its last destination when not applied.
+func casQuery(applied bool, callCount int64) *mocks.Query {
+	query := &mocks.Query{}
+	query.On("ScanCAS", mock.Anything).Run(func(args mock.Arguments) {
+		dest := args.Get(0).([]any)
+		*dest[len(dest)-1].(*int64) = callCount
+	}).Return(applied, nil)
+	return query
+}
+
+func TestDependencyStoreWriteV3(t *testing.T) {
+	withDepStore(V3, func(s *depStorageTest) {
+		ts := time.Date(2017, time.January, 24, 11, 15, 17, 12345, time.UTC)
+		bucket := time.Date(2017, time.January, 24, 11, 0, 0, 0, time.UTC)
+		// the a -> b link is new in the window
+		s.session.On("Query", depsInsertStmtV3, []any{bucket, "a", "b", "jaeger", int64(42)}).
+			Return(casQuery(true, 0)).Once()
+		// the b -> c link was stored with 5 calls, incremented to 6 by another writer in the meantime
+		s.session.On("Query", depsInsertStmtV3, []any{bucket, "b", "c", "jaeger", int64(3)}).
+			Return(casQuery(false, 5)).Once()
+		s.session.On("Query", depsUpdateStmtV3, []any{int64(8), bucket, "b", "c", "jaeger", int64(5)}).
+			Return(casQuery(false, 6)).Once()
+		s.session.On("Query", depsUpdateStmtV3, []any{int64(9), bucket, "b", "c", "jaeger", int64(6)}).
+			Return(casQuery(true, 0)).Once()
+
+		err := s.storage.WriteDependencies(ts, []model.DependencyLink{
+			{Parent: "a", Child: "b", CallCount: 42},
+			{Parent: "b", Child: "c", CallCount: 3, Source: model.JaegerDependencyLinkSource},
+		})
+		require.NoError(t, err)
+		s.session.AssertExpectations(t)
+	}, Window(time.Hour))
+}
+
+func TestDependencyStoreWriteV3Failures(t *testing.T) {
+	link := []model.DependencyLink{{Parent: "a", Child: "b", CallCount: 1}}
+	t.Run("query error", func(t *testing.T) {
+		withDepStore(V3, func(s *depStorageTest) {
+			query := &mocks.Query{}
+			query.On("ScanCAS", mock.Anything).Return(false, errors.New("write timeout"))
+			s.session.On("Query", depsInsertStmtV3, matchEverything()).Return(query)
+
+			err := s.storage.WriteDependencies(time.Now(), link)
+			require.EqualError(t, err, "failed to write dependency a -> b: write timeout")
+			assert.Contains(t, s.logBuffer.String(), "Failed to write dependency")
+		})
+	})
+	t.Run("contention", func(t *testing.T) {
+		withDepStore(V3, func(s *depStorageTest) {
+			s.session.On("Query", depsInsertStmtV3, matchEverything()).Return(casQuery(false, 1))
+			s.session.On("Query", depsUpdateStmtV3, matchEverything()).Return(casQuery(false, 1))
+
+			err := s.storage.WriteDependencies(time.Now(), link)
+			require.ErrorContains(t, err, "call count updated concurrently 10 times")
+		})
+	})
+}
+
+func TestDependencyStoreGetDependenciesV3(t *testing.T) {
+	endTs := time.Date(2017, time.January, 24, 11, 15, 17, 0, time.UTC)
+	rows := [][]any{
+		{"a", "b", "", int64(1)},
+		{"b", "c", "jaeger", int64(4)},
+		{"a", "b", "jaeger", int64(2)},
+		{"a", "b", "other", int64(7)},
+	}
+	newIterator := func() *mocks.Iterator {
+		remaining := rows
+		iter := &mocks.Iterator{}
+		iter.On("Scan", mock.MatchedBy(func(dest []any) bool {
+			if len(remaining) == 0 {
+				return false
+			}
+			*dest[0].(*string) = remaining[0][0].(string)
+			*dest[1].(*string) = remaining[0][1].(string)
+			*dest[2].(*string) = remaining[0][2].(string)
+			*dest[3].(*int64) = remaining[0][3].(int64)
+			remaining = remaining[1:]
+			return true
+		})).Return(true)
+		iter.On("Scan", matchEverything()).Return(false)
+		iter.On("Close").Return(nil)
+		return iter
+	}
+	newQuery := func(iter *mocks.Iterator) *mocks.Query {
+		query := &mocks.Query{}
+		query.On("Consistency", cassandra.One).Return(query)
+		query.On("Iter").Return(iter)
+		return query
+	}
+	buckets := []time.Time{
+		time.Date(2017, time.January, 24, 9, 0, 0, 0, time.UTC),
+		time.Date(2017, time.January, 24, 10, 0, 0, 0, time.UTC),
+		time.Date(2017, time.January, 24, 11, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("windows", func(t *testing.T) {
+		withDepStore(V3, func(s *depStorageTest) {
+			s.session.On("Query", depsSelectStmtV3, []any{buckets}).Return(newQuery(newIterator()))
+
+			deps, err := s.storage.GetDependencies(context.Background(), endTs, 2*time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, []model.DependencyLink{
+				{Parent: "a", Child: "b", CallCount: 3, Source: model.JaegerDependencyLinkSource},
+				{Parent: "b", Child: "c", CallCount: 4, Source: model.JaegerDependencyLinkSource},
+				{Parent: "a", Child: "b", CallCount: 7, Source: "other"},
+			}, deps)
+		}, Window(time.Hour))
+	})
+
+	t.Run("with legacy dependencies", func(t *testing.T) {
+		withDepStore(V3, func(s *depStorageTest) {
+			s.session.On("Query", depsSelectStmtV3, []any{buckets}).Return(newQuery(newIterator()))
+			legacyIter := &mocks.Iterator{}
+			legacyIter.On("Scan", mock.MatchedBy(func(dest []any) bool {
+				deps, ok := dest[1].(*[]Dependency)
+				if !ok || *deps != nil {
+					return false
+				}
+				*deps = []Dependency{{Parent: "a", Child: "b", CallCount: 10}, {Parent: "c", Child: "d", CallCount: 1}}
+				return true
+			})).Return(true).Once()
+			legacyIter.On("Scan", matchEverything()).Return(false)
+			legacyIter.On("Close").Return(nil)
+			s.session.On("Query", depsSelectStmtV2, matchEverything()).Return(newQuery(legacyIter))
+
+			deps, err := s.storage.GetDependencies(context.Background(), endTs, 2*time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, []model.DependencyLink{
+				{Parent: "a", Child: "b", CallCount: 13, Source: model.JaegerDependencyLinkSource},
+				{Parent: "b", Child: "c", CallCount: 4, Source: model.JaegerDependencyLinkSource},
+				{Parent: "a", Child: "b", CallCount: 7, Source: "other"},
+				{Parent: "c", Child: "d", CallCount: 1, Source: model.JaegerDependencyLinkSource},
+			}, deps)
+		}, Window(time.Hour), ReadLegacyDependencies())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		withDepStore(V3, func(s *depStorageTest) {
+			iter := &mocks.Iterator{}
+			iter.On("Scan", matchEverything()).Return(false)
+			iter.On("Close").Return(errors.New("query error"))
+			s.session.On("Query", depsSelectStmtV3, matchEverything()).Return(newQuery(iter))
+
+			_, err := s.storage.GetDependencies(context.Background(), endTs, 2*time.Hour)
+			require.EqualError(t, err, "error reading dependencies from storage: query error")
+			assert.Contains(t, s.logBuffer.String(), "Failure to read Dependencies")
+		})
+	})
+
+	t.Run("legacy failure", func(t *testing.T) {
+		withDepStore(V3, func(s *depStorageTest) {
+			s.session.On("Query", depsSelectStmtV3, matchEverything()).Return(newQuery(newIterator()))
+			legacyIter := &mocks.Iterator{}
+			legacyIter.On("Scan", matchEverything()).Return(false)
+			legacyIter.On("Close").Return(errors.New("legacy error"))
+			s.session.On("Query", depsSelectStmtV2, matchEverything()).Return(newQuery(legacyIter))
+
+			_, err := s.storage.GetDependencies(context.Background(), endTs, 2*time.Hour)
+			require.EqualError(t, err, "error reading dependencies from storage: legacy error")
+		}, ReadLegacyDependencies())
+	})
+}
+
 func TestGetBuckets(t *testing.T) {
 	var (
 		start    = time.Date(2017, time.January, 24, 11, 15, 17, 12345, time.UTC)
@@ -264,7 +429,7 @@ func TestGetBuckets(t *testing.T) {
 			time.Date(2017, time.January, 26, 0, 0, 0, 0, time.UTC),
 		}
 	)
-	assert.Equal(t, expected, getBuckets(start, end))
+	assert.Equal(t, expected, getBuckets(start, end, tsBucket))
 }
 
 func matchEverything() any {
@@ -183,7 +183,11 @@ func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
 // CreateDependencyReader implements storage.Factory
 func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
 	version := cDepStore.GetDependencyVersion(f.primarySession)
-	return cDepStore.NewDependencyStore(f.primarySession, f.primaryMetricsFactory, f.logger, version)
+	options := []cDepStore.Option{cDepStore.Window(f.Options.DependenciesWindow)}
+	if version == cDepStore.V3 && cDepStore.HasDependenciesV2(f.primarySession) {
+		options = append(options, cDepStore.ReadLegacyDependencies())
+	}
+	return cDepStore.NewDependencyStore(f.primarySession, f.primaryMetricsFactory, f.logger, version, options...)
 }
 
 // CreateArchiveSpanReader implements storage.ArchiveFactory
@@ -271,5 +275,12 @@ func (f *Factory) Close() error {
 }
 
 func (f *Factory) Purge(_ context.Context) error {
-	return f.primarySession.Query("TRUNCATE traces").Exec()
+	if err := f.primarySession.Query("TRUNCATE traces").Exec(); err != nil {
+		return err
+	}
+	// the call counts of the dependencies_v3 table are added up across the writes
+	if cDepStore.GetDependencyVersion(f.primarySession) == cDepStore.V3 {
+		return f.primarySession.Query("TRUNCATE dependencies_v3").Exec()
+	}
+	return nil
 }
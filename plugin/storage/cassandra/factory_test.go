@@ -262,6 +262,21 @@ func TestFactory_Purge(t *testing.T) {
 	err := f.Purge(context.Background())
 	require.NoError(t, err)
 
-	session.AssertCalled(t, "Query", mock.AnythingOfType("string"), mock.Anything)
+	session.AssertCalled(t, "Query", "TRUNCATE traces", mock.Anything)
+	session.AssertCalled(t, "Query", "TRUNCATE dependencies_v3", mock.Anything)
 	query.AssertCalled(t, "Exec")
 }
+
+func TestFactory_PurgeError(t *testing.T) {
+	f := NewFactory()
+	var (
+		session = &mocks.Session{}
+		query   = &mocks.Query{}
+	)
+	session.On("Query", mock.AnythingOfType("string"), mock.Anything).Return(query)
+	query.On("Exec").Return(errors.New("purge error"))
+	f.primarySession = session
+
+	require.EqualError(t, f.Purge(context.Background()), "purge error")
+	session.AssertNotCalled(t, "Query", "TRUNCATE dependencies_v3", mock.Anything)
+}
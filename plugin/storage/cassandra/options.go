@@ -25,6 +25,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/pkg/cassandra/config"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	cDepStore "github.com/jaegertracing/jaeger/plugin/storage/cassandra/dependencystore"
 )
 
 const (
@@ -53,6 +54,7 @@ const (
 	suffixIndexLogs              = ".index.logs"
 	suffixIndexTags              = ".index.tags"
 	suffixIndexProcessTags       = ".index.process-tags"
+	suffixDependenciesWindow     = ".dependencies.window"
 
 	defaultHost = "127.0.0.1"
 )
@@ -65,6 +67,8 @@ type Options struct {
 	others                 map[string]*namespaceConfig
 	SpanStoreWriteCacheTTL time.Duration `mapstructure:"span_store_write_cache_ttl"`
 	Index                  IndexConfig   `mapstructure:"index"`
+	// DependenciesWindow is the duration of the windows in which the dependencies_v3 table aggregates the links.
+	DependenciesWindow time.Duration `mapstructure:"dependencies_window"`
 }
 
 // IndexConfig configures indexing.
@@ -104,6 +108,7 @@ func NewOptions(primaryNamespace string, otherNamespaces ...string) *Options {
 		},
 		others:                 make(map[string]*namespaceConfig, len(otherNamespaces)),
 		SpanStoreWriteCacheTTL: time.Hour * 12,
+		DependenciesWindow:     cDepStore.DefaultWindow,
 	}
 
 	for _, namespace := range otherNamespaces {
@@ -122,6 +127,10 @@ func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 	flagSet.Duration(opt.Primary.namespace+suffixSpanStoreWriteCacheTTL,
 		opt.SpanStoreWriteCacheTTL,
 		"The duration to wait before rewriting an existing service or operation name")
+	flagSet.Duration(opt.Primary.namespace+suffixDependenciesWindow,
+		opt.DependenciesWindow,
+		"The duration of the windows in which the dependencies_v3 table aggregates the call counts of the links, "+
+			"which must be the same for all the readers and writers of the table")
 	flagSet.String(
 		opt.Primary.namespace+suffixIndexTagsBlacklist,
 		opt.Index.TagBlackList,
@@ -230,6 +239,7 @@ func (opt *Options) InitFromViper(v *viper.Viper) {
 		cfg.initFromViper(v)
 	}
 	opt.SpanStoreWriteCacheTTL = v.GetDuration(opt.Primary.namespace + suffixSpanStoreWriteCacheTTL)
+	opt.DependenciesWindow = v.GetDuration(opt.Primary.namespace + suffixDependenciesWindow)
 	opt.Index.TagBlackList = stripWhiteSpace(v.GetString(opt.Primary.namespace + suffixIndexTagsBlacklist))
 	opt.Index.TagWhiteList = stripWhiteSpace(v.GetString(opt.Primary.namespace + suffixIndexTagsWhitelist))
 	opt.Index.Tags = v.GetBool(opt.Primary.namespace + suffixIndexTags)
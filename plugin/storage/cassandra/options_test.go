@@ -64,6 +64,7 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--cas.index.tag-whitelist=flerg, flarg,florg ",
 		"--cas.index.tags=true",
 		"--cas.index.process-tags=false",
+		"--cas.dependencies.window=30m",
 		"--cas.basic.allowed-authenticators=org.apache.cassandra.auth.PasswordAuthenticator,com.datastax.bdp.cassandra.auth.DseAuthenticator",
 		"--cas.username=username",
 		"--cas.password=password",
@@ -88,6 +89,7 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.True(t, opts.Index.Tags)
 	assert.False(t, opts.Index.ProcessTags)
 	assert.True(t, opts.Index.Logs)
+	assert.Equal(t, 30*time.Minute, opts.DependenciesWindow)
 
 	aux := opts.Get("cas-aux")
 	require.NotNil(t, aux)
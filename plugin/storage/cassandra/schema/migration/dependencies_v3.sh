@@ -0,0 +1,61 @@
+#!/usr/bin/env bash
+
+# Create the dependencies_v3 table aggregating the dependencies in windows, with the TTL of the dependencies_v2 table.
+# The rows of dependencies_v2 are not copied: Jaeger reads both tables as long as dependencies_v2 exists,
+# and merges the links found in both.
+# Sample usage: KEYSPACE=jaeger_v1 CQL_CMD='cqlsh host 9042 -u test_user -p test_password --request-timeout=3000' bash
+# ./dependencies_v3.sh
+
+set -euo pipefail
+
+function usage {
+    >&2 echo "Error: $1"
+    >&2 echo ""
+    >&2 echo "Usage: KEYSPACE={keyspace} CQL_CMD={cql_cmd} $0"
+    >&2 echo ""
+    >&2 echo "The following parameters can be set via environment:"
+    >&2 echo "  KEYSPACE           - keyspace"
+    >&2 echo "  CQL_CMD            - cqlsh host port -u user -p password"
+    >&2 echo ""
+    exit 1
+}
+
+if [[ ${KEYSPACE} == "" ]]; then
+   usage "missing KEYSPACE parameter"
+fi
+
+if [[ ${KEYSPACE} =~ [^a-zA-Z0-9_] ]]; then
+    usage "invalid characters in KEYSPACE=$KEYSPACE parameter, please use letters, digits or underscores"
+fi
+
+keyspace=${KEYSPACE}
+old_table=dependencies_v2
+new_table=dependencies_v3
+cqlsh_cmd=${CQL_CMD}
+
+if [[ ${cqlsh_cmd} == "" ]]; then
+   cqlsh_cmd=cqlsh
+fi
+
+echo "Using cql command: $cqlsh_cmd"
+
+ttl=$(${cqlsh_cmd} -e "select default_time_to_live from system_schema.tables WHERE keyspace_name='$keyspace' AND table_name='$old_table';"|head -4|tail -1|tr -d ' ')
+
+echo "Creating new table $new_table with ttl: $ttl"
+
+${cqlsh_cmd} -e "CREATE TABLE IF NOT EXISTS $keyspace.$new_table (
+    ts_bucket    timestamp,
+    parent       text,
+    child        text,
+    source       text,
+    call_count   bigint,
+    PRIMARY KEY (ts_bucket, parent, child, source)
+)
+    WITH compaction = {
+        'min_threshold': '4',
+        'max_threshold': '32',
+        'class': 'org.apache.cassandra.db.compaction.SizeTieredCompactionStrategy'
+    }
+    AND default_time_to_live = $ttl;"
+
+echo "Table $keyspace.$new_table created, restart the Jaeger query services to use it."
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gocql/gocql"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
@@ -41,6 +42,10 @@ const (
 		SELECT trace_id, span_id, parent_id, operation_name, flags, start_time, duration, tags, logs, refs, process
 		FROM traces
 		WHERE trace_id = ?`
+	querySpansByTraceIDs = `
+		SELECT trace_id, span_id, parent_id, operation_name, flags, start_time, duration, tags, logs, refs, process
+		FROM traces
+		WHERE trace_id IN ?`
 	queryByTag = `
 		SELECT trace_id
 		FROM tag_index
@@ -165,8 +170,18 @@ func (s *SpanReader) readTrace(ctx context.Context, traceID dbmodel.TraceID) (*m
 }
 
 func (s *SpanReader) readTraceInSpan(_ context.Context, traceID dbmodel.TraceID) (*model.Trace, error) {
+	spans, err := s.readSpans(s.session.Query(querySpanByTraceID, traceID))
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return &model.Trace{Spans: spans}, nil
+}
+
+func (s *SpanReader) readSpans(q cassandra.Query) ([]*model.Span, error) {
 	start := time.Now()
-	q := s.session.Query(querySpanByTraceID, traceID)
 	i := q.Iter()
 	var traceIDFromSpan dbmodel.TraceID
 	var startTime, spanID, duration, parentID int64
@@ -176,7 +191,7 @@ func (s *SpanReader) readTraceInSpan(_ context.Context, traceID dbmodel.TraceID)
 	var refs []dbmodel.SpanRef
 	var tags []dbmodel.KeyValue
 	var logs []dbmodel.Log
-	retMe := &model.Trace{}
+	var retMe []*model.Span
 	for i.Scan(&traceIDFromSpan, &spanID, &parentID, &operationName, &flags, &startTime, &duration, &tags, &logs, &refs, &dbProcess) {
 		dbSpan := dbmodel.Span{
 			TraceID:       traceIDFromSpan,
@@ -197,7 +212,7 @@ func (s *SpanReader) readTraceInSpan(_ context.Context, traceID dbmodel.TraceID)
 			s.metrics.readTraces.Emit(err, time.Since(start))
 			return nil, err
 		}
-		retMe.Spans = append(retMe.Spans, span)
+		retMe = append(retMe, span)
 	}
 
 	err := i.Close()
@@ -205,9 +220,6 @@ func (s *SpanReader) readTraceInSpan(_ context.Context, traceID dbmodel.TraceID)
 	if err != nil {
 		return nil, fmt.Errorf("error reading traces from storage: %w", err)
 	}
-	if len(retMe.Spans) == 0 {
-		return nil, spanstore.ErrTraceNotFound
-	}
 	return retMe, nil
 }
 
@@ -216,6 +228,34 @@ func (s *SpanReader) GetTrace(ctx context.Context, traceID model.TraceID) (*mode
 	return s.readTrace(ctx, dbmodel.TraceIDFromDomain(traceID))
 }
 
+// GetTraces takes traceIDs and returns the Traces found for them, reading their partitions with a single query
+func (s *SpanReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	_, span := s.startSpanForQuery(ctx, "readTraces", querySpansByTraceIDs)
+	defer span.End()
+
+	dbTraceIDs := make([]dbmodel.TraceID, len(traceIDs))
+	for i, traceID := range traceIDs {
+		dbTraceIDs[i] = dbmodel.TraceIDFromDomain(traceID)
+	}
+	spans, err := s.readSpans(s.session.Query(querySpansByTraceIDs, dbTraceIDs))
+	logErrorToSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	var traces []*model.Trace
+	tracesByID := make(map[model.TraceID]*model.Trace)
+	for _, span := range spans {
+		trace, ok := tracesByID[span.TraceID]
+		if !ok {
+			trace = &model.Trace{}
+			tracesByID[span.TraceID] = trace
+			traces = append(traces, trace)
+		}
+		trace.Spans = append(trace.Spans, span)
+	}
+	return traces, nil
+}
+
 func validateQuery(p *spanstore.TraceQueryParameters) error {
 	if p == nil {
 		return ErrMalformedRequestObject
@@ -252,6 +292,9 @@ func (s *SpanReader) FindTraces(ctx context.Context, traceQuery *spanstore.Trace
 			continue
 		}
 		retMe = append(retMe, jTrace)
+		spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+			p.TracesLoaded++
+		})
 	}
 	return retMe, nil
 }
@@ -277,6 +320,9 @@ func (s *SpanReader) FindTraceIDs(ctx context.Context, traceQuery *spanstore.Tra
 		}
 		traceIDs = append(traceIDs, t.ToDomain())
 	}
+	spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+		p.TracesFound = len(traceIDs)
+	})
 	return traceIDs, nil
 }
 
@@ -334,6 +380,7 @@ func (s *SpanReader) queryByTagsAndLogs(ctx context.Context, tq *spanstore.Trace
 			return nil, err
 		}
 		results = append(results, t)
+		reportBucketScanned(ctx)
 	}
 	return dbmodel.IntersectTraceIDs(results), nil
 }
@@ -355,9 +402,12 @@ func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.
 	startTimeByHour := traceQuery.StartTimeMin.Round(durationBucketSize)
 	endTimeByHour := traceQuery.StartTimeMax.Round(durationBucketSize)
 
+	var buckets, timedOut int
+	var lastErr error
 	for timeBucket := endTimeByHour; timeBucket.After(startTimeByHour) || timeBucket.Equal(startTimeByHour); timeBucket = timeBucket.Add(-1 * durationBucketSize) {
 		_, childSpan := s.tracer.Start(ctx, "queryForTimeBucket")
 		childSpan.SetAttributes(attribute.Key("timeBucket").String(timeBucket.String()))
+		buckets++
 		query := s.session.Query(
 			queryByDuration,
 			timeBucket,
@@ -369,7 +419,14 @@ func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.
 		t, err := s.executeQuery(childSpan, query, s.metrics.queryDurationIndex)
 		childSpan.End()
 		if err != nil {
-			return nil, err
+			if !isTimeout(err) {
+				return nil, err
+			}
+			// the other buckets may still be read, the search returns partial results
+			timedOut++
+			lastErr = err
+			spanstore.AddWarning(ctx, fmt.Sprintf("the duration index query for the time bucket %s timed out, the results may be incomplete", timeBucket.UTC().Format(time.RFC3339)))
+			continue
 		}
 
 		for traceID := range t {
@@ -378,13 +435,27 @@ func (s *SpanReader) queryByDuration(ctx context.Context, traceQuery *spanstore.
 				break
 			}
 		}
+		spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+			p.BucketsScanned++
+			p.TracesFound = len(results)
+		})
+	}
+	if timedOut > 0 && timedOut == buckets {
+		return nil, lastErr
 	}
 	return results, nil
 }
 
+// isTimeout returns whether the query failed because Cassandra did not answer in time.
+func isTimeout(err error) bool {
+	var readTimeout *gocql.RequestErrReadTimeout
+	return errors.As(err, &readTimeout) || errors.Is(err, gocql.ErrTimeoutNoResponse)
+}
+
 func (s *SpanReader) queryByServiceNameAndOperation(ctx context.Context, tq *spanstore.TraceQueryParameters) (dbmodel.UniqueTraceIDs, error) {
 	_, span := s.startSpanForQuery(ctx, "queryByServiceNameAndOperation", queryByServiceAndOperationName)
 	defer span.End()
+	defer reportBucketScanned(ctx)
 	query := s.session.Query(
 		queryByServiceAndOperationName,
 		tq.ServiceName,
@@ -399,6 +470,7 @@ func (s *SpanReader) queryByServiceNameAndOperation(ctx context.Context, tq *spa
 func (s *SpanReader) queryByService(ctx context.Context, tq *spanstore.TraceQueryParameters) (dbmodel.UniqueTraceIDs, error) {
 	_, span := s.startSpanForQuery(ctx, "queryByService", queryByServiceAndOperationName)
 	defer span.End()
+	defer reportBucketScanned(ctx)
 	query := s.session.Query(
 		queryByServiceName,
 		tq.ServiceName,
@@ -427,6 +499,13 @@ func (s *SpanReader) executeQuery(span trace.Span, query cassandra.Query, tableM
 	return retMe, nil
 }
 
+// reportBucketScanned reports that one more index query of the search is done
+func reportBucketScanned(ctx context.Context) {
+	spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+		p.BucketsScanned++
+	})
+}
+
 func (s *SpanReader) startSpanForQuery(ctx context.Context, name, query string) (context.Context, trace.Span) {
 	ctx, span := s.tracer.Start(ctx, name)
 	span.SetAttributes(
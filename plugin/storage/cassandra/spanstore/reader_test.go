@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gocql/gocql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -186,6 +187,59 @@ func TestSpanReaderGetTrace_TraceNotFound(t *testing.T) {
 	})
 }
 
+func TestSpanReaderGetTraces(t *testing.T) {
+	withTraceID := func(traceID model.TraceID) any {
+		return matchOnceWithSideEffect(func(args []any) {
+			*args[0].(*dbmodel.TraceID) = dbmodel.TraceIDFromDomain(traceID)
+		})
+	}
+	traceID1, traceID2 := model.NewTraceID(0, 1), model.NewTraceID(0, 2)
+	withSpanReader(t, func(r *spanReaderTest) {
+		iter := &mocks.Iterator{}
+		iter.On("Scan", withTraceID(traceID1)).Return(true).Once()
+		iter.On("Scan", withTraceID(traceID2)).Return(true).Once()
+		iter.On("Scan", withTraceID(traceID1)).Return(true).Once()
+		iter.On("Scan", matchEverything()).Return(false)
+		iter.On("Close").Return(nil)
+
+		query := &mocks.Query{}
+		query.On("Iter").Return(iter)
+
+		traceIDs := []model.TraceID{traceID1, traceID2, model.NewTraceID(0, 3)}
+		dbTraceIDs := []dbmodel.TraceID{
+			dbmodel.TraceIDFromDomain(traceIDs[0]),
+			dbmodel.TraceIDFromDomain(traceIDs[1]),
+			dbmodel.TraceIDFromDomain(traceIDs[2]),
+		}
+		r.session.On("Query", stringMatcher("trace_id IN ?"), []any{dbTraceIDs}).Return(query).Once()
+
+		traces, err := r.reader.GetTraces(context.Background(), traceIDs)
+		require.NoError(t, err)
+		require.NotEmpty(t, r.traceBuffer.GetSpans(), "Spans recorded")
+		require.Len(t, traces, 2)
+		assert.Len(t, traces[0].Spans, 2)
+		assert.Equal(t, traceID1, traces[0].Spans[0].TraceID)
+		assert.Len(t, traces[1].Spans, 1)
+		assert.Equal(t, traceID2, traces[1].Spans[0].TraceID)
+	})
+}
+
+func TestSpanReaderGetTracesError(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		iter := &mocks.Iterator{}
+		iter.On("Scan", matchEverything()).Return(false)
+		iter.On("Close").Return(errors.New("error on close()"))
+
+		query := &mocks.Query{}
+		query.On("Iter").Return(iter)
+		r.session.On("Query", mock.AnythingOfType("string"), matchEverything()).Return(query)
+
+		traces, err := r.reader.GetTraces(context.Background(), []model.TraceID{{Low: 1}})
+		require.EqualError(t, err, "error reading traces from storage: error on close()")
+		assert.Nil(t, traces)
+	})
+}
+
 func TestSpanReaderFindTracesBadRequest(t *testing.T) {
 	withSpanReader(t, func(r *spanReaderTest) {
 		_, err := r.reader.FindTraces(context.Background(), nil)
@@ -209,20 +263,24 @@ func TestSpanReaderFindTraces(t *testing.T) {
 		expectedCount                     int
 		expectedError                     string
 		expectedLogs                      []string
+		expectedProgress                  spanstore.SearchProgress
 	}{
 		{
-			caption:       "main query",
-			expectedCount: 2,
+			caption:          "main query",
+			expectedCount:    2,
+			expectedProgress: spanstore.SearchProgress{BucketsScanned: 1, TracesFound: 2, TracesLoaded: 2},
 		},
 		{
-			caption:       "tag query",
-			expectedCount: 2,
-			queryTags:     true,
+			caption:          "tag query",
+			expectedCount:    2,
+			queryTags:        true,
+			expectedProgress: spanstore.SearchProgress{BucketsScanned: 1, TracesFound: 2, TracesLoaded: 2},
 		},
 		{
-			caption:       "with limit",
-			numTraces:     1,
-			expectedCount: 1,
+			caption:          "with limit",
+			numTraces:        1,
+			expectedCount:    1,
+			expectedProgress: spanstore.SearchProgress{BucketsScanned: 1, TracesFound: 1, TracesLoaded: 1},
 		},
 		{
 			caption:        "main query error",
@@ -250,10 +308,11 @@ func TestSpanReaderFindTraces(t *testing.T) {
 			expectedCount:  2,
 		},
 		{
-			caption:        "operation name and tag query",
-			queryTags:      true,
-			queryOperation: true,
-			expectedCount:  2,
+			caption:          "operation name and tag query",
+			queryTags:        true,
+			queryOperation:   true,
+			expectedCount:    2,
+			expectedProgress: spanstore.SearchProgress{BucketsScanned: 2, TracesFound: 2, TracesLoaded: 2},
 		},
 		{
 			caption:                           "operation name and tag error on operation query",
@@ -303,6 +362,7 @@ func TestSpanReaderFindTraces(t *testing.T) {
 				`"trace_id":"0000000000000001"`,
 				`"trace_id":"0000000000000002"`,
 			},
+			expectedProgress: spanstore.SearchProgress{BucketsScanned: 1, TracesFound: 2},
 		},
 	}
 	for _, tc := range testCases {
@@ -403,7 +463,11 @@ func TestSpanReaderFindTraces(t *testing.T) {
 					queryParams.DurationMin = time.Minute
 					queryParams.DurationMax = time.Minute * 3
 				}
-				res, err := r.reader.FindTraces(context.Background(), queryParams)
+				var progress spanstore.SearchProgress
+				ctx := spanstore.ContextWithProgress(context.Background(), func(p spanstore.SearchProgress) {
+					progress = p
+				})
+				res, err := r.reader.FindTraces(ctx, queryParams)
 				if testCase.expectedError == "" {
 					require.NotEmpty(t, r.traceBuffer.GetSpans(), "Spans recorded")
 					require.NoError(t, err)
@@ -417,6 +481,9 @@ func TestSpanReaderFindTraces(t *testing.T) {
 				if len(testCase.expectedLogs) == 0 {
 					assert.Equal(t, "", r.logBuffer.String())
 				}
+				if testCase.expectedProgress != (spanstore.SearchProgress{}) {
+					assert.Equal(t, testCase.expectedProgress, progress)
+				}
 			})
 		})
 	}
@@ -454,3 +521,58 @@ func TestTraceQueryParameterValidation(t *testing.T) {
 	err = validateQuery(tsp)
 	require.EqualError(t, err, ErrStartAndEndTimeNotSet.Error())
 }
+
+func TestSpanReaderQueryByDurationTimeouts(t *testing.T) {
+	mockBucketQuery := func(queryErr error) *mocks.Query {
+		iter := &mocks.Iterator{}
+		iter.On("Scan", matchEverything()).Return(false)
+		iter.On("Close").Return(queryErr)
+		query := &mocks.Query{}
+		query.On("Iter").Return(iter)
+		query.On("String").Return("queryString")
+		return query
+	}
+	queryParams := &spanstore.TraceQueryParameters{
+		ServiceName:  "service-a",
+		NumTraces:    10,
+		StartTimeMax: time.Now(),
+		StartTimeMin: time.Now().Add(-3 * time.Hour),
+	}
+
+	t.Run("partial results", func(t *testing.T) {
+		withSpanReader(t, func(r *spanReaderTest) {
+			r.session.On("Query", stringMatcher(queryByDuration), matchEverything()).
+				Return(mockBucketQuery(&gocql.RequestErrReadTimeout{})).Once()
+			r.session.On("Query", stringMatcher(queryByDuration), matchEverything()).
+				Return(mockBucketQuery(nil))
+
+			ctx := spanstore.ContextWithWarnings(context.Background())
+			_, err := r.reader.queryByDuration(ctx, queryParams)
+			require.NoError(t, err)
+			warnings := spanstore.GetWarnings(ctx)
+			require.Len(t, warnings, 1)
+			assert.Contains(t, warnings[0], "the duration index query for the time bucket")
+			assert.Contains(t, warnings[0], "timed out, the results may be incomplete")
+		})
+	})
+
+	t.Run("all buckets timed out", func(t *testing.T) {
+		withSpanReader(t, func(r *spanReaderTest) {
+			r.session.On("Query", stringMatcher(queryByDuration), matchEverything()).
+				Return(mockBucketQuery(gocql.ErrTimeoutNoResponse))
+
+			_, err := r.reader.queryByDuration(context.Background(), queryParams)
+			require.ErrorIs(t, err, gocql.ErrTimeoutNoResponse)
+		})
+	})
+
+	t.Run("other errors fail the search", func(t *testing.T) {
+		withSpanReader(t, func(r *spanReaderTest) {
+			r.session.On("Query", stringMatcher(queryByDuration), matchEverything()).
+				Return(mockBucketQuery(errors.New("unavailable"))).Once()
+
+			_, err := r.reader.queryByDuration(context.Background(), queryParams)
+			require.EqualError(t, err, "unavailable")
+		})
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	spanstoremetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
+)
+
+// maxErrorBytes caps the length of the error messages of ClickHouse read from the responses.
+const maxErrorBytes = 4096
+
+// client runs the statements on a ClickHouse server through its HTTP interface,
+// see https://clickhouse.com/docs/en/interfaces/http. The values of the statements are passed
+// as query parameters, e.g. {service:String}, rather than formatted into the SQL, and the tables
+// are qualified by their database, which may not exist yet when the schema is created.
+type client struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// newWriteMetrics creates the metrics of the statements of the given type, e.g. insert_spans.
+func newWriteMetrics(factory metrics.Factory, query string) *spanstoremetrics.WriteMetrics {
+	m := &spanstoremetrics.WriteMetrics{}
+	metrics.Init(m, factory.Namespace(metrics.NSOptions{Tags: map[string]string{"query": query}}), nil)
+	return m
+}
+
+// readMetrics are the metrics of the SELECT queries, like the ones of the span readers
+// decorated by spanstoremetrics.ReadMetricsDecorator.
+type readMetrics struct {
+	Errors     metrics.Counter `metric:"requests" tags:"result=err"`
+	Successes  metrics.Counter `metric:"requests" tags:"result=ok"`
+	Responses  metrics.Timer   `metric:"responses"` // used as a histogram of the number of rows
+	ErrLatency metrics.Timer   `metric:"latency" tags:"result=err"`
+	OKLatency  metrics.Timer   `metric:"latency" tags:"result=ok"`
+}
+
+// newReadMetrics creates the metrics of the queries of the given type, e.g. find_trace_ids.
+func newReadMetrics(factory metrics.Factory, query string) *readMetrics {
+	m := &readMetrics{}
+	metrics.Init(m, factory.Namespace(metrics.NSOptions{Tags: map[string]string{"query": query}}), nil)
+	return m
+}
+
+func (m *readMetrics) emit(err error, latency time.Duration, rows int) {
+	if err != nil {
+		m.Errors.Inc(1)
+		m.ErrLatency.Record(latency)
+		return
+	}
+	m.Successes.Inc(1)
+	m.OKLatency.Record(latency)
+	m.Responses.Record(time.Duration(rows))
+}
+
+// exec runs the statement, whose input data, if any, is read from data, and records it in m.
+func (c *client) exec(ctx context.Context, m *spanstoremetrics.WriteMetrics, statement string, params map[string]string, data io.Reader) error {
+	start := time.Now()
+	resp, err := c.do(ctx, statement, params, data)
+	if err == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	m.Emit(err, time.Since(start))
+	return err
+}
+
+// queryRows runs the SELECT query, records it in m, and returns its rows decoded from the JSONEachRow format.
+func queryRows[T any](ctx context.Context, c *client, m *readMetrics, query string, params map[string]string) ([]T, error) {
+	start := time.Now()
+	rows, err := decodeRows[T](ctx, c, query, params)
+	m.emit(err, time.Since(start), len(rows))
+	return rows, err
+}
+
+func decodeRows[T any](ctx context.Context, c *client, query string, params map[string]string) ([]T, error) {
+	resp, err := c.do(ctx, query+" FORMAT JSONEachRow", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var rows []T
+	decoder := json.NewDecoder(resp.Body)
+	for decoder.More() {
+		var row T
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode the ClickHouse response: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// do sends the statement to ClickHouse, in the body of the request unless it has input data,
+// and returns the response if successful.
+func (c *client) do(ctx context.Context, statement string, params map[string]string, data io.Reader) (*http.Response, error) {
+	values := url.Values{}
+	// the UInt64 values, e.g. the durations, are returned as JSON numbers rather than strings
+	values.Set("output_format_json_quote_64bit_integers", "0")
+	for name, value := range params {
+		values.Set("param_"+name, value)
+	}
+	body := data
+	if body == nil {
+		body = strings.NewReader(statement)
+	} else {
+		values.Set("query", statement)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/?"+values.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" {
+		req.Header.Set("X-ClickHouse-User", c.username)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ClickHouse: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		return nil, fmt.Errorf("ClickHouse error (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// escapeParam escapes the value of a query parameter, which ClickHouse parses like a TSV field.
+func escapeParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`).Replace(value)
+}
+
+// arrayParam formats the values of an Array(String) query parameter.
+func arrayParam(values []string) string {
+	quoted := make([]string, len(values))
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	for i, value := range values {
+		quoted[i] = "'" + replacer.Replace(value) + "'"
+	}
+	return escapeParam("[" + strings.Join(quoted, ",") + "]")
+}
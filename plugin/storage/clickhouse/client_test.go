@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// request is a statement received by the fake ClickHouse server.
+type request struct {
+	statement string
+	params    map[string]string
+	data      string
+	user      string
+}
+
+// fakeServer is a fake of the HTTP interface of ClickHouse, recording the statements
+// and responding with the rows returned by respond.
+type fakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []request
+	respond  func(request) (status int, rows string)
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{respond: func(request) (int, string) { return http.StatusOK, "" }}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := request{params: map[string]string{}, user: r.Header.Get("X-ClickHouse-User")}
+		for name, values := range r.URL.Query() {
+			if name, ok := strings.CutPrefix(name, "param_"); ok {
+				req.params[name] = values[0]
+			}
+		}
+		if query := r.URL.Query().Get("query"); query != "" {
+			req.statement, req.data = query, string(body)
+		} else {
+			req.statement = string(body)
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		respond := s.respond
+		s.mu.Unlock()
+		status, rows := respond(req)
+		w.WriteHeader(status)
+		w.Write([]byte(rows))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) client() *client {
+	return &client{url: s.URL, httpClient: s.Server.Client()}
+}
+
+func (s *fakeServer) setRespond(respond func(request) (int, string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.respond = respond
+}
+
+func (s *fakeServer) received() []request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]request(nil), s.requests...)
+}
+
+func TestClientQueryRows(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(request) (int, string) {
+		return http.StatusOK, `{"name":"a","count":1}` + "\n" + `{"name":"b","count":2}` + "\n"
+	})
+	c := s.client()
+	c.username, c.password = "jaeger", "secret"
+	metricsFactory := metricstest.NewFactory(0)
+
+	rows, err := queryRows[struct {
+		Name  string `json:"name"`
+		Count uint64 `json:"count"`
+	}](context.Background(), c, newReadMetrics(metricsFactory, "test"),
+		"SELECT name, count FROM t WHERE name = {name:String}", map[string]string{"name": "a"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "b", rows[1].Name)
+	assert.Equal(t, uint64(2), rows[1].Count)
+
+	requests := s.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "SELECT name, count FROM t WHERE name = {name:String} FORMAT JSONEachRow", requests[0].statement)
+	assert.Equal(t, map[string]string{"name": "a"}, requests[0].params)
+	assert.Equal(t, "jaeger", requests[0].user)
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "requests", Tags: map[string]string{"query": "test", "result": "ok"}, Value: 1,
+	})
+}
+
+func TestClientErrors(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(request) (int, string) {
+		return http.StatusBadRequest, "Code: 60. DB::Exception: Unknown table\n"
+	})
+	m := newWriteMetrics(metrics.NullFactory, "test")
+	err := s.client().exec(context.Background(), m, "TRUNCATE TABLE t", nil, nil)
+	require.EqualError(t, err, "ClickHouse error (HTTP 400): Code: 60. DB::Exception: Unknown table")
+
+	s.setRespond(func(request) (int, string) { return http.StatusOK, "not json" })
+	metricsFactory := metricstest.NewFactory(0)
+	_, err = queryRows[struct{}](context.Background(), s.client(), newReadMetrics(metricsFactory, "test"), "SELECT 1", nil)
+	require.ErrorContains(t, err, "failed to decode the ClickHouse response")
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "requests", Tags: map[string]string{"query": "test", "result": "err"}, Value: 1,
+	})
+
+	c := &client{url: "http://127.0.0.1:1", httpClient: http.DefaultClient}
+	err = c.exec(context.Background(), m, "SELECT 1", nil, nil)
+	require.ErrorContains(t, err, "failed to query ClickHouse")
+}
+
+func TestParamEscaping(t *testing.T) {
+	assert.Equal(t, `a\\b\tc\nd`, escapeParam("a\\b\tc\nd"))
+	assert.Equal(t, `['a','b\\'c']`, arrayParam([]string{"a", "b'c"}))
+}
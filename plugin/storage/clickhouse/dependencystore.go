@@ -0,0 +1,101 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	spanstoremetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
+)
+
+var (
+	_ dependencystore.Reader = (*DependencyStore)(nil)
+	_ dependencystore.Writer = (*DependencyStore)(nil)
+)
+
+// dependencyRow is a row of the dependencies table.
+type dependencyRow struct {
+	Timestamp string `json:"timestamp"`
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+	CallCount uint64 `json:"call_count"`
+	Source    string `json:"source"`
+}
+
+// DependencyStore reads and writes the links between services of the dependencies table,
+// the call counts of the links written at different times being summed by the reads.
+type DependencyStore struct {
+	client          *client
+	table           string
+	getDependencies *readMetrics
+	insert          *spanstoremetrics.WriteMetrics
+}
+
+// newDependencyStore creates a DependencyStore of the dependencies table of the database.
+func newDependencyStore(c *client, database string, metricsFactory metrics.Factory) *DependencyStore {
+	return &DependencyStore{
+		client:          c,
+		table:           database + ".dependencies",
+		getDependencies: newReadMetrics(metricsFactory, "get_dependencies"),
+		insert:          newWriteMetrics(metricsFactory, "insert_dependencies"),
+	}
+}
+
+// WriteDependencies implements dependencystore.Writer.
+func (s *DependencyStore) WriteDependencies(ts time.Time, dependencies []model.DependencyLink) error {
+	if len(dependencies) == 0 {
+		return nil
+	}
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, link := range dependencies {
+		source := link.Source
+		if source == "" {
+			source = model.JaegerDependencyLinkSource
+		}
+		err := encoder.Encode(dependencyRow{
+			Timestamp: ts.UTC().Format(timestampFormat),
+			Parent:    link.Parent,
+			Child:     link.Child,
+			CallCount: link.CallCount,
+			Source:    source,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.client.exec(context.Background(), s.insert, "INSERT INTO "+s.table+" FORMAT JSONEachRow", nil, &data)
+}
+
+// GetDependencies implements dependencystore.Reader.
+func (s *DependencyStore) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	rows, err := queryRows[struct {
+		Parent string `json:"parent"`
+		Child  string `json:"child"`
+		Calls  uint64 `json:"calls"`
+		Source string `json:"source"`
+	}](ctx, s.client, s.getDependencies,
+		"SELECT parent, child, sum(call_count) AS calls, source FROM "+s.table+
+			" WHERE timestamp >= fromUnixTimestamp64Micro({start:Int64}) AND timestamp <= fromUnixTimestamp64Micro({end:Int64})"+
+			" GROUP BY parent, child, source ORDER BY parent, child",
+		map[string]string{
+			"start": strconv.FormatInt(endTs.Add(-lookback).UnixMicro(), 10),
+			"end":   strconv.FormatInt(endTs.UnixMicro(), 10),
+		})
+	if err != nil {
+		return nil, err
+	}
+	dependencies := make([]model.DependencyLink, len(rows))
+	for i, row := range rows {
+		dependencies[i] = model.DependencyLink{Parent: row.Parent, Child: row.Child, CallCount: row.Calls, Source: row.Source}
+	}
+	return dependencies, nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func TestWriteDependencies(t *testing.T) {
+	s := newFakeServer(t)
+	store := newDependencyStore(s.client(), "jaeger", metrics.NullFactory)
+
+	require.NoError(t, store.WriteDependencies(time.Now(), nil))
+	assert.Empty(t, s.received())
+
+	ts := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.WriteDependencies(ts, []model.DependencyLink{
+		{Parent: "shop", Child: "db", CallCount: 3},
+		{Parent: "shop", Child: "cache", CallCount: 1, Source: "spark"},
+	}))
+	requests := s.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "INSERT INTO jaeger.dependencies FORMAT JSONEachRow", requests[0].statement)
+	var rows []dependencyRow
+	for _, line := range strings.Split(strings.TrimSpace(requests[0].data), "\n") {
+		var row dependencyRow
+		require.NoError(t, json.Unmarshal([]byte(line), &row))
+		rows = append(rows, row)
+	}
+	assert.Equal(t, []dependencyRow{
+		{Timestamp: "2024-06-01 12:00:00.000000", Parent: "shop", Child: "db", CallCount: 3, Source: model.JaegerDependencyLinkSource},
+		{Timestamp: "2024-06-01 12:00:00.000000", Parent: "shop", Child: "cache", CallCount: 1, Source: "spark"},
+	}, rows)
+}
+
+func TestGetDependencies(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(request) (int, string) {
+		return http.StatusOK, `{"parent":"shop","child":"db","calls":6,"source":"jaeger"}` + "\n"
+	})
+	store := newDependencyStore(s.client(), "jaeger", metrics.NullFactory)
+
+	endTs := time.UnixMicro(1700003600000000)
+	dependencies, err := store.GetDependencies(context.Background(), endTs, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DependencyLink{{Parent: "shop", Child: "db", CallCount: 6, Source: "jaeger"}}, dependencies)
+
+	requests := s.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "SELECT parent, child, sum(call_count) AS calls, source FROM jaeger.dependencies "+
+		"WHERE timestamp >= fromUnixTimestamp64Micro({start:Int64}) AND timestamp <= fromUnixTimestamp64Micro({end:Int64}) "+
+		"GROUP BY parent, child, source ORDER BY parent, child FORMAT JSONEachRow", requests[0].statement)
+	assert.Equal(t, map[string]string{"start": "1700000000000000", "end": "1700003600000000"}, requests[0].params)
+
+	s.setRespond(func(request) (int, string) { return http.StatusInternalServerError, "down" })
+	_, err = store.GetDependencies(context.Background(), endTs, time.Hour)
+	require.Error(t, err)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var ( // interface comformance checks
+	_ storage.Factory     = (*Factory)(nil)
+	_ storage.Purger      = (*Factory)(nil)
+	_ io.Closer           = (*Factory)(nil)
+	_ plugin.Configurable = (*Factory)(nil)
+)
+
+// Factory implements storage.Factory for ClickHouse, reached through its HTTP interface.
+type Factory struct {
+	Options Options
+
+	metricsFactory metrics.Factory
+	logger         *zap.Logger
+
+	client *client
+	writer *SpanWriter
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// AddFlags implements plugin.Configurable
+func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
+	f.Options.AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, logger *zap.Logger) {
+	if err := f.Options.InitFromViper(v); err != nil {
+		logger.Fatal("Failed to initialize ClickHouse storage", zap.Error(err))
+	}
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	f.metricsFactory = metricsFactory.Namespace(metrics.NSOptions{Name: "clickhouse"})
+	f.logger = logger
+	logger.Info("ClickHouse factory",
+		zap.String("url", f.Options.URL),
+		zap.String("database", f.Options.Database),
+		zap.Bool("create-schema", f.Options.CreateSchema))
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if f.Options.TLS.Enabled {
+		tlsConfig, err := f.Options.TLS.Config(logger)
+		if err != nil {
+			return err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	f.client = &client{
+		url:        strings.TrimSuffix(f.Options.URL, "/"),
+		username:   f.Options.Username,
+		password:   f.Options.Password,
+		httpClient: &http.Client{Transport: transport, Timeout: f.Options.Timeout},
+	}
+	if f.Options.CreateSchema {
+		return createSchema(context.Background(), f.client, f.Options.Database, f.Options.TTL, f.metricsFactory)
+	}
+	return nil
+}
+
+// CreateSpanReader implements storage.Factory
+func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
+	return newSpanReader(f.client, f.Options.Database, f.metricsFactory), nil
+}
+
+// CreateSpanWriter implements storage.Factory
+func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	if f.writer == nil {
+		f.writer = newSpanWriter(f.client, &f.Options, f.metricsFactory, f.logger)
+	}
+	return f.writer, nil
+}
+
+// CreateDependencyReader implements storage.Factory
+func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return newDependencyStore(f.client, f.Options.Database, f.metricsFactory), nil
+}
+
+// Purge implements storage.Purger, truncating the tables of Jaeger.
+func (f *Factory) Purge(ctx context.Context) error {
+	m := newWriteMetrics(f.metricsFactory, "purge")
+	for _, table := range []string{"spans", "operations", "dependencies"} {
+		if err := f.client.exec(ctx, m, "TRUNCATE TABLE IF EXISTS "+f.Options.Database+"."+table, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close inserts the spans not inserted yet, and closes the resources held by the factory
+func (f *Factory) Close() error {
+	var errs []error
+	if f.writer != nil {
+		errs = append(errs, f.writer.Close())
+	}
+	errs = append(errs, f.Options.TLS.Close())
+	return errors.Join(errs...)
+}
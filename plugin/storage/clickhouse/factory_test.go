@@ -0,0 +1,87 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func newTestFactory(t *testing.T, flags ...string) *Factory {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags(flags))
+	f.InitFromViper(v, zap.NewNop())
+	return f
+}
+
+func TestClickHouseFactory(t *testing.T) {
+	s := newFakeServer(t)
+	f := newTestFactory(t, "--clickhouse.url="+s.URL+"/", "--clickhouse.create-schema=true", "--clickhouse.username=jaeger")
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+
+	requests := s.received()
+	require.Len(t, requests, 5)
+	assert.Equal(t, "CREATE DATABASE IF NOT EXISTS jaeger", requests[0].statement)
+	assert.Equal(t, "jaeger", requests[0].user)
+
+	_, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	_, err = f.CreateDependencyReader()
+	require.NoError(t, err)
+	w1, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	w2, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	assert.Same(t, w1, w2)
+
+	require.NoError(t, f.Purge(context.Background()))
+	requests = s.received()
+	require.Len(t, requests, 8)
+	assert.Equal(t, "TRUNCATE TABLE IF EXISTS jaeger.spans", requests[5].statement)
+
+	// the spans not inserted yet are inserted on close
+	require.NoError(t, w1.WriteSpan(context.Background(), testSpan(1)))
+	require.NoError(t, f.Close())
+	requests = s.received()
+	require.Len(t, requests, 9)
+	assert.Equal(t, "INSERT INTO jaeger.spans FORMAT JSONEachRow", requests[8].statement)
+}
+
+func TestClickHouseFactoryWithoutSchema(t *testing.T) {
+	s := newFakeServer(t)
+	f := newTestFactory(t, "--clickhouse.url="+s.URL)
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	defer f.Close()
+	assert.Empty(t, s.received())
+}
+
+func TestClickHouseFactoryErrors(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(req request) (int, string) {
+		if strings.HasPrefix(req.statement, "CREATE TABLE") || strings.HasPrefix(req.statement, "TRUNCATE") {
+			return http.StatusForbidden, "not allowed"
+		}
+		return http.StatusOK, ""
+	})
+	f := newTestFactory(t, "--clickhouse.url="+s.URL, "--clickhouse.create-schema=true")
+	err := f.Initialize(metrics.NullFactory, zap.NewNop())
+	require.EqualError(t, err, "failed to create the ClickHouse schema: ClickHouse error (HTTP 403): not allowed")
+	require.NoError(t, f.Close())
+
+	require.Error(t, f.Purge(context.Background()))
+
+	f = newTestFactory(t, "--clickhouse.tls.enabled=true", "--clickhouse.tls.ca=/does/not/exist")
+	require.Error(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	require.NoError(t, f.Close())
+}
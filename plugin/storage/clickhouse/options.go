@@ -0,0 +1,177 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+const (
+	// ConfigPrefix is the prefix of the flags of the ClickHouse storage.
+	ConfigPrefix = "clickhouse"
+
+	suffixURL           = ".url"
+	suffixDatabase      = ".database"
+	suffixUsername      = ".username"
+	suffixPassword      = ".password"
+	suffixTimeout       = ".timeout"
+	suffixCreateSchema  = ".create-schema"
+	suffixTTL           = ".ttl"
+	suffixBatchSize     = ".batch-size"
+	suffixFlushInterval = ".flush-interval"
+	suffixMaxRetries    = ".max-retries"
+	suffixMaxPending    = ".max-pending-spans"
+
+	// DefaultURL is the default URL of the HTTP interface of the ClickHouse server.
+	DefaultURL = "http://127.0.0.1:8123"
+	// DefaultDatabase is the default ClickHouse database of the tables of Jaeger.
+	DefaultDatabase = "jaeger"
+	// DefaultBatchSize is the default number of spans inserted at once.
+	DefaultBatchSize = 10000
+	// DefaultFlushInterval is the default period after which the spans are inserted, even if fewer than the batch size.
+	DefaultFlushInterval = time.Second
+	// DefaultMaxRetries is the default number of times the insert of a failed batch is retried.
+	DefaultMaxRetries = 3
+	// DefaultMaxPendingSpans is the default number of spans waiting to be inserted over which the writes are rejected.
+	DefaultMaxPendingSpans = 10 * DefaultBatchSize
+
+	defaultTimeout = 30 * time.Second
+)
+
+// Options stores the configuration of the ClickHouse storage.
+type Options struct {
+	// URL is the URL of the HTTP interface of the ClickHouse server, e.g. http://127.0.0.1:8123.
+	URL      string `mapstructure:"url"`
+	Database string `mapstructure:"database"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password" json:"-"`
+	// Timeout limits the duration of each query.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// CreateSchema creates the database and the tables when missing, on start.
+	CreateSchema bool `mapstructure:"create_schema"`
+	// TTL is the retention of the spans and dependencies of the tables created, 0 means no expiration.
+	TTL time.Duration `mapstructure:"ttl"`
+	// BatchSize is the number of spans inserted at once.
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushInterval is the period after which the spans are inserted, even if fewer than BatchSize.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// MaxRetries is the number of times the insert of a failed batch is retried, once per FlushInterval,
+	// before its spans are dropped.
+	MaxRetries int `mapstructure:"max_retries"`
+	// MaxPendingSpans is the number of spans waiting to be inserted, including the ones of a failed batch,
+	// over which the writes are rejected.
+	MaxPendingSpans int            `mapstructure:"max_pending_spans"`
+	TLS             tlscfg.Options `mapstructure:"tls"`
+}
+
+// AddFlags adds flags for Options
+func (*Options) AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(
+		ConfigPrefix+suffixURL,
+		DefaultURL,
+		"The URL of the HTTP interface of the ClickHouse server")
+	flagSet.String(
+		ConfigPrefix+suffixDatabase,
+		DefaultDatabase,
+		"The ClickHouse database of the tables of the spans and dependencies")
+	flagSet.String(
+		ConfigPrefix+suffixUsername,
+		"",
+		"The username used to authenticate to ClickHouse")
+	flagSet.String(
+		ConfigPrefix+suffixPassword,
+		"",
+		"The password used to authenticate to ClickHouse")
+	flagSet.Duration(
+		ConfigPrefix+suffixTimeout,
+		defaultTimeout,
+		"The timeout of the ClickHouse queries")
+	flagSet.Bool(
+		ConfigPrefix+suffixCreateSchema,
+		false,
+		"Create the database, tables and materialized views of Jaeger on start, when missing")
+	flagSet.Duration(
+		ConfigPrefix+suffixTTL,
+		0,
+		"The retention of the spans and dependencies in the tables created by "+ConfigPrefix+suffixCreateSchema+"; set to 0s to keep them forever")
+	flagSet.Int(
+		ConfigPrefix+suffixBatchSize,
+		DefaultBatchSize,
+		"The number of spans inserted into ClickHouse at once")
+	flagSet.Duration(
+		ConfigPrefix+suffixFlushInterval,
+		DefaultFlushInterval,
+		"The period after which the spans are inserted into ClickHouse, even if fewer than the batch size")
+	flagSet.Int(
+		ConfigPrefix+suffixMaxRetries,
+		DefaultMaxRetries,
+		"The number of times the insert of a failed batch of spans is retried, once per flush interval, before the spans are dropped")
+	flagSet.Int(
+		ConfigPrefix+suffixMaxPending,
+		DefaultMaxPendingSpans,
+		"The number of spans waiting to be inserted into ClickHouse, e.g. while a failed batch is retried, over which the writes are rejected")
+	tlscfg.ClientFlagsConfig{Prefix: ConfigPrefix}.AddFlags(flagSet)
+}
+
+// InitFromViper initializes Options with properties from viper
+func (opt *Options) InitFromViper(v *viper.Viper) error {
+	opt.URL = v.GetString(ConfigPrefix + suffixURL)
+	opt.Database = v.GetString(ConfigPrefix + suffixDatabase)
+	opt.Username = v.GetString(ConfigPrefix + suffixUsername)
+	opt.Password = v.GetString(ConfigPrefix + suffixPassword)
+	opt.Timeout = v.GetDuration(ConfigPrefix + suffixTimeout)
+	opt.CreateSchema = v.GetBool(ConfigPrefix + suffixCreateSchema)
+	opt.TTL = v.GetDuration(ConfigPrefix + suffixTTL)
+	opt.BatchSize = v.GetInt(ConfigPrefix + suffixBatchSize)
+	opt.FlushInterval = v.GetDuration(ConfigPrefix + suffixFlushInterval)
+	opt.MaxRetries = v.GetInt(ConfigPrefix + suffixMaxRetries)
+	opt.MaxPendingSpans = v.GetInt(ConfigPrefix + suffixMaxPending)
+	var err error
+	opt.TLS, err = tlscfg.ClientFlagsConfig{Prefix: ConfigPrefix}.InitFromViper(v)
+	if err != nil {
+		return fmt.Errorf("failed to parse ClickHouse TLS options: %w", err)
+	}
+	return opt.validate()
+}
+
+func (opt *Options) validate() error {
+	if opt.URL == "" {
+		return fmt.Errorf("%s is required", ConfigPrefix+suffixURL)
+	}
+	if !validIdentifier(opt.Database) {
+		return fmt.Errorf("invalid %s %q: only letters, digits and underscores are allowed", ConfigPrefix+suffixDatabase, opt.Database)
+	}
+	if opt.BatchSize <= 0 || opt.FlushInterval <= 0 {
+		return fmt.Errorf("%s and %s must be positive", ConfigPrefix+suffixBatchSize, ConfigPrefix+suffixFlushInterval)
+	}
+	if opt.MaxRetries < 0 {
+		return fmt.Errorf("%s cannot be negative", ConfigPrefix+suffixMaxRetries)
+	}
+	if opt.MaxPendingSpans < opt.BatchSize {
+		return fmt.Errorf("%s must be at least %s", ConfigPrefix+suffixMaxPending, ConfigPrefix+suffixBatchSize)
+	}
+	if opt.TTL < 0 || opt.Timeout < 0 {
+		return fmt.Errorf("%s and %s cannot be negative", ConfigPrefix+suffixTTL, ConfigPrefix+suffixTimeout)
+	}
+	return nil
+}
+
+// validIdentifier tells whether name can be used as a ClickHouse identifier without quoting.
+func validIdentifier(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, c := range name {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
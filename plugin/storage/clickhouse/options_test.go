@@ -0,0 +1,86 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--clickhouse.url=https://clickhouse:8443",
+		"--clickhouse.database=traces",
+		"--clickhouse.username=jaeger",
+		"--clickhouse.password=secret",
+		"--clickhouse.timeout=10s",
+		"--clickhouse.create-schema=true",
+		"--clickhouse.ttl=168h",
+		"--clickhouse.batch-size=500",
+		"--clickhouse.flush-interval=200ms",
+		"--clickhouse.max-retries=5",
+		"--clickhouse.max-pending-spans=5000",
+		"--clickhouse.tls.enabled=true",
+		"--clickhouse.tls.server-name=clickhouse",
+	}))
+	require.NoError(t, opts.InitFromViper(v))
+
+	assert.Equal(t, "https://clickhouse:8443", opts.URL)
+	assert.Equal(t, "traces", opts.Database)
+	assert.Equal(t, "jaeger", opts.Username)
+	assert.Equal(t, "secret", opts.Password)
+	assert.Equal(t, 10*time.Second, opts.Timeout)
+	assert.True(t, opts.CreateSchema)
+	assert.Equal(t, 7*24*time.Hour, opts.TTL)
+	assert.Equal(t, 500, opts.BatchSize)
+	assert.Equal(t, 200*time.Millisecond, opts.FlushInterval)
+	assert.Equal(t, 5, opts.MaxRetries)
+	assert.Equal(t, 5000, opts.MaxPendingSpans)
+	assert.True(t, opts.TLS.Enabled)
+	assert.Equal(t, "clickhouse", opts.TLS.ServerName)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	require.NoError(t, opts.InitFromViper(v))
+
+	assert.Equal(t, DefaultURL, opts.URL)
+	assert.Equal(t, DefaultDatabase, opts.Database)
+	assert.Equal(t, defaultTimeout, opts.Timeout)
+	assert.False(t, opts.CreateSchema)
+	assert.Zero(t, opts.TTL)
+	assert.Equal(t, DefaultBatchSize, opts.BatchSize)
+	assert.Equal(t, DefaultFlushInterval, opts.FlushInterval)
+	assert.Equal(t, DefaultMaxRetries, opts.MaxRetries)
+	assert.Equal(t, DefaultMaxPendingSpans, opts.MaxPendingSpans)
+	assert.False(t, opts.TLS.Enabled)
+}
+
+func TestOptionsInvalid(t *testing.T) {
+	for _, flag := range []string{
+		"--clickhouse.url=",
+		"--clickhouse.database=jaeger;DROP",
+		"--clickhouse.database=1jaeger",
+		"--clickhouse.batch-size=0",
+		"--clickhouse.flush-interval=0s",
+		"--clickhouse.ttl=-1h",
+		"--clickhouse.max-retries=-1",
+		"--clickhouse.max-pending-spans=1",
+		"--clickhouse.tls.min-version=1.7",
+	} {
+		opts := &Options{}
+		v, command := config.Viperize(opts.AddFlags)
+		require.NoError(t, command.ParseFlags([]string{flag}))
+		require.Error(t, opts.InitFromViper(v), flag)
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogo/protobuf/jsonpb"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const defaultNumTraces = 100
+
+var (
+	// ErrMalformedRequestObject occurs when a request object is nil
+	ErrMalformedRequestObject = errors.New("malformed request object")
+
+	// ErrStartAndEndTimeNotSet occurs when start time and end time are not set
+	ErrStartAndEndTimeNotSet = errors.New("start and End Time must be set")
+
+	// ErrStartTimeMinGreaterThanMax occurs when start time min is above start time max
+	ErrStartTimeMinGreaterThanMax = errors.New("start Time Minimum is above Maximum")
+
+	// ErrDurationMinGreaterThanMax occurs when duration min is above duration max
+	ErrDurationMinGreaterThanMax = errors.New("duration Minimum is above Maximum")
+)
+
+var _ spanstore.BatchReader = (*SpanReader)(nil)
+
+type spanReaderMetrics struct {
+	getTraces     *readMetrics
+	findTraceIDs  *readMetrics
+	getServices   *readMetrics
+	getOperations *readMetrics
+}
+
+// SpanReader reads the spans from the spans table of ClickHouse, and the services and operations
+// from the operations table maintained by a materialized view of the spans table.
+type SpanReader struct {
+	client          *client
+	spansTable      string
+	operationsTable string
+	metrics         spanReaderMetrics
+}
+
+// newSpanReader creates a SpanReader reading the tables of the database.
+func newSpanReader(c *client, database string, metricsFactory metrics.Factory) *SpanReader {
+	return &SpanReader{
+		client:          c,
+		spansTable:      database + ".spans",
+		operationsTable: database + ".operations",
+		metrics: spanReaderMetrics{
+			getTraces:     newReadMetrics(metricsFactory, "get_traces"),
+			findTraceIDs:  newReadMetrics(metricsFactory, "find_trace_ids"),
+			getServices:   newReadMetrics(metricsFactory, "get_services"),
+			getOperations: newReadMetrics(metricsFactory, "get_operations"),
+		},
+	}
+}
+
+// GetTrace takes a traceID and returns a Trace associated with that traceID
+func (r *SpanReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	traces, err := r.GetTraces(ctx, []model.TraceID{traceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(traces) == 0 {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return traces[0], nil
+}
+
+// GetTraces implements spanstore.BatchReader, reading the spans of all the traces with one query.
+func (r *SpanReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	if len(traceIDs) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(traceIDs))
+	for i, traceID := range traceIDs {
+		ids[i] = traceID.String()
+	}
+	rows, err := queryRows[struct {
+		Span string `json:"span"`
+	}](ctx, r.client, r.metrics.getTraces,
+		"SELECT span FROM "+r.spansTable+" WHERE trace_id IN {trace_ids:Array(String)}",
+		map[string]string{"trace_ids": arrayParam(ids)})
+	if err != nil {
+		return nil, err
+	}
+	traces := make(map[model.TraceID]*model.Trace)
+	for _, row := range rows {
+		span := &model.Span{}
+		if err := jsonpb.UnmarshalString(row.Span, span); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the span: %w", err)
+		}
+		trace, ok := traces[span.TraceID]
+		if !ok {
+			trace = &model.Trace{}
+			traces[span.TraceID] = trace
+		}
+		trace.Spans = append(trace.Spans, span)
+	}
+	// the traces are returned in the order of their IDs
+	var result []*model.Trace
+	for _, traceID := range traceIDs {
+		if trace, ok := traces[traceID]; ok {
+			result = append(result, trace)
+			delete(traces, traceID)
+		}
+	}
+	return result, nil
+}
+
+// GetServices returns all services traced by Jaeger
+func (r *SpanReader) GetServices(ctx context.Context) ([]string, error) {
+	rows, err := queryRows[struct {
+		Service string `json:"service"`
+	}](ctx, r.client, r.metrics.getServices,
+		"SELECT DISTINCT service FROM "+r.operationsTable+" ORDER BY service", nil)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]string, len(rows))
+	for i, row := range rows {
+		services[i] = row.Service
+	}
+	return services, nil
+}
+
+// GetOperations returns all operations for a specific service traced by Jaeger
+func (r *SpanReader) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	sql := "SELECT DISTINCT operation, span_kind FROM " + r.operationsTable + " WHERE service = {service:String}"
+	params := map[string]string{"service": escapeParam(query.ServiceName)}
+	if query.SpanKind != "" {
+		sql += " AND span_kind = {span_kind:String}"
+		params["span_kind"] = escapeParam(query.SpanKind)
+	}
+	rows, err := queryRows[struct {
+		Operation string `json:"operation"`
+		SpanKind  string `json:"span_kind"`
+	}](ctx, r.client, r.metrics.getOperations, sql+" ORDER BY operation, span_kind", params)
+	if err != nil {
+		return nil, err
+	}
+	operations := make([]spanstore.Operation, len(rows))
+	for i, row := range rows {
+		operations[i] = spanstore.Operation{Name: row.Operation, SpanKind: row.SpanKind}
+	}
+	return operations, nil
+}
+
+// FindTraces retrieves traces that match the traceQuery
+func (r *SpanReader) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	traceIDs, err := r.FindTraceIDs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTraces(ctx, traceIDs)
+}
+
+// FindTraceIDs retrieves the IDs of the traces having a span matching the traceQuery,
+// the traces with the most recent matching spans first.
+func (r *SpanReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	sql, params := r.buildFindTraceIDsQuery(query)
+	rows, err := queryRows[struct {
+		TraceID string `json:"trace_id"`
+	}](ctx, r.client, r.metrics.findTraceIDs, sql, params)
+	if err != nil {
+		return nil, err
+	}
+	var traceIDs []model.TraceID
+	for _, row := range rows {
+		traceID, err := model.TraceIDFromString(row.TraceID)
+		if err != nil {
+			return nil, err
+		}
+		traceIDs = append(traceIDs, traceID)
+	}
+	return traceIDs, nil
+}
+
+// buildFindTraceIDsQuery returns the query of FindTraceIDs and its parameters, all the criteria,
+// including the tags, being matched by the same span.
+func (r *SpanReader) buildFindTraceIDsQuery(query *spanstore.TraceQueryParameters) (string, map[string]string) {
+	conditions := []string{
+		"timestamp >= fromUnixTimestamp64Micro({start_time_min:Int64})",
+		"timestamp <= fromUnixTimestamp64Micro({start_time_max:Int64})",
+	}
+	params := map[string]string{
+		"start_time_min": strconv.FormatInt(query.StartTimeMin.UnixMicro(), 10),
+		"start_time_max": strconv.FormatInt(query.StartTimeMax.UnixMicro(), 10),
+	}
+	if query.ServiceName != "" {
+		conditions = append(conditions, "service = {service:String}")
+		params["service"] = escapeParam(query.ServiceName)
+	}
+	if query.OperationName != "" {
+		conditions = append(conditions, "operation = {operation:String}")
+		params["operation"] = escapeParam(query.OperationName)
+	}
+	if query.DurationMin != 0 {
+		conditions = append(conditions, "duration >= {duration_min:UInt64}")
+		params["duration_min"] = strconv.FormatUint(model.DurationAsMicroseconds(query.DurationMin), 10)
+	}
+	if query.DurationMax != 0 {
+		conditions = append(conditions, "duration <= {duration_max:UInt64}")
+		params["duration_max"] = strconv.FormatUint(model.DurationAsMicroseconds(query.DurationMax), 10)
+	}
+	keys := make([]string, 0, len(query.Tags))
+	for key := range query.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		conditions = append(conditions, fmt.Sprintf("has(tags[{tag_key_%d:String}], {tag_value_%d:String})", i, i))
+		params[fmt.Sprintf("tag_key_%d", i)] = escapeParam(key)
+		params[fmt.Sprintf("tag_value_%d", i)] = escapeParam(query.Tags[key])
+	}
+	numTraces := query.NumTraces
+	if numTraces <= 0 {
+		numTraces = defaultNumTraces
+	}
+	params["num_traces"] = strconv.Itoa(numTraces)
+	sql := "SELECT trace_id FROM " + r.spansTable +
+		" WHERE " + strings.Join(conditions, " AND ") +
+		" GROUP BY trace_id ORDER BY max(timestamp) DESC LIMIT {num_traces:UInt32}"
+	return sql, params
+}
+
+func validateQuery(p *spanstore.TraceQueryParameters) error {
+	if p == nil {
+		return ErrMalformedRequestObject
+	}
+	if p.StartTimeMin.IsZero() || p.StartTimeMax.IsZero() {
+		return ErrStartAndEndTimeNotSet
+	}
+	if p.StartTimeMax.Before(p.StartTimeMin) {
+		return ErrStartTimeMinGreaterThanMax
+	}
+	if p.DurationMin != 0 && p.DurationMax != 0 && p.DurationMin > p.DurationMax {
+		return ErrDurationMinGreaterThanMax
+	}
+	return nil
+}
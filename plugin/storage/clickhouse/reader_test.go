@@ -0,0 +1,202 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// spanRows returns the rows of the span column of the spans.
+func spanRows(t *testing.T, spans ...*model.Span) string {
+	var rows strings.Builder
+	for _, span := range spans {
+		spanJSON, err := new(jsonpb.Marshaler).MarshalToString(span)
+		require.NoError(t, err)
+		row, err := json.Marshal(map[string]string{"span": spanJSON})
+		require.NoError(t, err)
+		rows.Write(row)
+		rows.WriteByte('\n')
+	}
+	return rows.String()
+}
+
+func TestGetTrace(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(request) (int, string) { return http.StatusOK, spanRows(t, testSpan(1), testSpan(2)) })
+	r := newSpanReader(s.client(), "jaeger", metrics.NullFactory)
+
+	trace, err := r.GetTrace(context.Background(), model.NewTraceID(0, 0xabc))
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 2)
+	assert.Equal(t, model.NewSpanID(2), trace.Spans[1].SpanID)
+	assert.Equal(t, "shop", trace.Spans[0].Process.ServiceName)
+
+	requests := s.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "SELECT span FROM jaeger.spans WHERE trace_id IN {trace_ids:Array(String)} FORMAT JSONEachRow", requests[0].statement)
+	assert.Equal(t, map[string]string{"trace_ids": "['0000000000000abc']"}, requests[0].params)
+
+	s.setRespond(func(request) (int, string) { return http.StatusOK, "" })
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 0xabc))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+
+	s.setRespond(func(request) (int, string) { return http.StatusOK, `{"span":"not a span"}` })
+	_, err = r.GetTrace(context.Background(), model.NewTraceID(0, 0xabc))
+	require.ErrorContains(t, err, "failed to unmarshal the span")
+}
+
+func TestFindTraces(t *testing.T) {
+	otherSpan := testSpan(3)
+	otherSpan.TraceID = model.NewTraceID(1, 2)
+	s := newFakeServer(t)
+	s.setRespond(func(req request) (int, string) {
+		if strings.HasPrefix(req.statement, "SELECT trace_id") {
+			return http.StatusOK, `{"trace_id":"00000000000000010000000000000002"}` + "\n" + `{"trace_id":"abc"}` + "\n"
+		}
+		return http.StatusOK, spanRows(t, testSpan(1), otherSpan, testSpan(2))
+	})
+	r := newSpanReader(s.client(), "jaeger", metrics.NullFactory)
+
+	start := time.UnixMicro(1700000000000000)
+	traces, err := r.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName:   "shop",
+		OperationName: "checkout",
+		Tags:          map[string]string{"http.status_code": "500", "error": "true"},
+		StartTimeMin:  start,
+		StartTimeMax:  start.Add(time.Hour),
+		DurationMin:   time.Millisecond,
+		DurationMax:   time.Second,
+		NumTraces:     20,
+	})
+	require.NoError(t, err)
+	// the traces are in the order of the trace IDs found
+	require.Len(t, traces, 2)
+	assert.Len(t, traces[0].Spans, 1)
+	assert.Equal(t, otherSpan.TraceID, traces[0].Spans[0].TraceID)
+	assert.Len(t, traces[1].Spans, 2)
+
+	requests := s.received()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "SELECT trace_id FROM jaeger.spans WHERE "+
+		"timestamp >= fromUnixTimestamp64Micro({start_time_min:Int64}) AND timestamp <= fromUnixTimestamp64Micro({start_time_max:Int64}) AND "+
+		"service = {service:String} AND operation = {operation:String} AND "+
+		"duration >= {duration_min:UInt64} AND duration <= {duration_max:UInt64} AND "+
+		"has(tags[{tag_key_0:String}], {tag_value_0:String}) AND has(tags[{tag_key_1:String}], {tag_value_1:String}) "+
+		"GROUP BY trace_id ORDER BY max(timestamp) DESC LIMIT {num_traces:UInt32} FORMAT JSONEachRow", requests[0].statement)
+	assert.Equal(t, map[string]string{
+		"start_time_min": "1700000000000000",
+		"start_time_max": "1700003600000000",
+		"service":        "shop",
+		"operation":      "checkout",
+		"duration_min":   "1000",
+		"duration_max":   "1000000",
+		"tag_key_0":      "error",
+		"tag_value_0":    "true",
+		"tag_key_1":      "http.status_code",
+		"tag_value_1":    "500",
+		"num_traces":     "20",
+	}, requests[0].params)
+	assert.Equal(t, "['00000000000000010000000000000002','0000000000000abc']", requests[1].params["trace_ids"])
+}
+
+func TestFindTraceIDsDefaults(t *testing.T) {
+	s := newFakeServer(t)
+	r := newSpanReader(s.client(), "jaeger", metrics.NullFactory)
+
+	traceIDs, err := r.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{
+		StartTimeMin: time.Now().Add(-time.Hour),
+		StartTimeMax: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, traceIDs)
+	requests := s.received()
+	require.Len(t, requests, 1)
+	assert.NotContains(t, requests[0].statement, "service")
+	assert.Equal(t, "100", requests[0].params["num_traces"])
+
+	// no query for no trace IDs
+	traces, err := r.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		StartTimeMin: time.Now().Add(-time.Hour),
+		StartTimeMax: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, traces)
+	assert.Len(t, s.received(), 2)
+}
+
+func TestFindTraceIDsErrors(t *testing.T) {
+	s := newFakeServer(t)
+	r := newSpanReader(s.client(), "jaeger", metrics.NullFactory)
+	now := time.Now()
+	for _, test := range []struct {
+		query *spanstore.TraceQueryParameters
+		err   error
+	}{
+		{query: nil, err: ErrMalformedRequestObject},
+		{query: &spanstore.TraceQueryParameters{StartTimeMax: now}, err: ErrStartAndEndTimeNotSet},
+		{query: &spanstore.TraceQueryParameters{StartTimeMin: now, StartTimeMax: now.Add(-time.Hour)}, err: ErrStartTimeMinGreaterThanMax},
+		{
+			query: &spanstore.TraceQueryParameters{StartTimeMin: now, StartTimeMax: now, DurationMin: time.Second, DurationMax: time.Millisecond},
+			err:   ErrDurationMinGreaterThanMax,
+		},
+	} {
+		_, err := r.FindTraces(context.Background(), test.query)
+		require.ErrorIs(t, err, test.err)
+	}
+	assert.Empty(t, s.received())
+
+	s.setRespond(func(request) (int, string) { return http.StatusOK, `{"trace_id":"not hex"}` })
+	_, err := r.FindTraceIDs(context.Background(), &spanstore.TraceQueryParameters{StartTimeMin: now, StartTimeMax: now})
+	require.Error(t, err)
+}
+
+func TestGetServicesAndOperations(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(req request) (int, string) {
+		if strings.Contains(req.statement, "DISTINCT service") {
+			return http.StatusOK, `{"service":"db"}` + "\n" + `{"service":"shop"}` + "\n"
+		}
+		return http.StatusOK, `{"operation":"checkout","span_kind":"server"}` + "\n" + `{"operation":"query","span_kind":""}` + "\n"
+	})
+	r := newSpanReader(s.client(), "jaeger", metrics.NullFactory)
+
+	services, err := r.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "shop"}, services)
+
+	operations, err := r.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "shop"})
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{{Name: "checkout", SpanKind: "server"}, {Name: "query"}}, operations)
+
+	_, err = r.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "shop", SpanKind: "server"})
+	require.NoError(t, err)
+
+	requests := s.received()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "SELECT DISTINCT service FROM jaeger.operations ORDER BY service FORMAT JSONEachRow", requests[0].statement)
+	assert.Equal(t, "SELECT DISTINCT operation, span_kind FROM jaeger.operations WHERE service = {service:String} "+
+		"ORDER BY operation, span_kind FORMAT JSONEachRow", requests[1].statement)
+	assert.Equal(t, map[string]string{"service": "shop", "span_kind": "server"}, requests[2].params)
+
+	s.setRespond(func(request) (int, string) { return http.StatusInternalServerError, "down" })
+	_, err = r.GetServices(context.Background())
+	require.Error(t, err)
+	_, err = r.GetOperations(context.Background(), spanstore.OperationQueryParameters{ServiceName: "shop"})
+	require.Error(t, err)
+	_, err = r.FindTraces(context.Background(), &spanstore.TraceQueryParameters{StartTimeMin: time.Now(), StartTimeMax: time.Now()})
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+//go:embed schema/schema.sql.tmpl
+var schemaFS embed.FS
+
+var schemaTemplate = template.Must(template.ParseFS(schemaFS, "schema/schema.sql.tmpl"))
+
+// schemaStatements returns the statements creating the database, tables and materialized views
+// of Jaeger when missing, one per element since the HTTP interface runs a single statement per request.
+func schemaStatements(database string, ttl time.Duration) ([]string, error) {
+	var buf bytes.Buffer
+	err := schemaTemplate.Execute(&buf, struct {
+		Database   string
+		TTLSeconds int64
+	}{
+		Database:   database,
+		TTLSeconds: int64(ttl / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var statements []string
+	for _, statement := range strings.Split(buf.String(), ";\n") {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			statements = append(statements, strings.Join(lines, "\n"))
+		}
+	}
+	return statements, nil
+}
+
+// createSchema creates the database, tables and materialized views of Jaeger when missing.
+func createSchema(ctx context.Context, c *client, database string, ttl time.Duration, metricsFactory metrics.Factory) error {
+	statements, err := schemaStatements(database, ttl)
+	if err != nil {
+		return err
+	}
+	m := newWriteMetrics(metricsFactory, "create_schema")
+	for _, statement := range statements {
+		if err := c.exec(ctx, m, statement, nil, nil); err != nil {
+			return fmt.Errorf("failed to create the ClickHouse schema: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaStatements(t *testing.T) {
+	statements, err := schemaStatements("tracing", 0)
+	require.NoError(t, err)
+	require.Len(t, statements, 5)
+	assert.Equal(t, "CREATE DATABASE IF NOT EXISTS tracing", statements[0])
+	assert.True(t, strings.HasPrefix(statements[1], "CREATE TABLE IF NOT EXISTS tracing.spans ("))
+	assert.Contains(t, statements[3], "TO tracing.operations")
+	for _, statement := range statements {
+		assert.NotContains(t, statement, "--")
+		assert.NotContains(t, statement, "TTL")
+		assert.False(t, strings.HasSuffix(statement, ";"))
+	}
+
+	statements, err = schemaStatements("jaeger", 48*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, statements, 5)
+	assert.True(t, strings.HasSuffix(statements[1], "TTL toDateTime(timestamp) + toIntervalSecond(172800)"))
+	assert.True(t, strings.HasSuffix(statements[2], "TTL date + toIntervalSecond(172800)"))
+	assert.True(t, strings.HasSuffix(statements[4], "TTL toDateTime(timestamp) + toIntervalSecond(172800)"))
+}
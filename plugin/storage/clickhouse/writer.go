@@ -0,0 +1,269 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanstoremetrics "github.com/jaegertracing/jaeger/storage/spanstore/metrics"
+)
+
+// timestampFormat is the format of the DateTime64(6, 'UTC') values inserted.
+const timestampFormat = "2006-01-02 15:04:05.000000"
+
+var (
+	_ spanstore.WriterFlusher          = (*SpanWriter)(nil)
+	_ spanstore.WriterWithBackpressure = (*SpanWriter)(nil)
+)
+
+// errTooManyPendingSpans is returned by WriteSpan when ClickHouse does not keep up with the writes.
+var errTooManyPendingSpans = errors.New("too many spans waiting to be inserted into ClickHouse")
+
+// spanRow is a row of the spans table.
+type spanRow struct {
+	Timestamp string              `json:"timestamp"`
+	TraceID   string              `json:"trace_id"`
+	SpanID    string              `json:"span_id"`
+	Service   string              `json:"service"`
+	Operation string              `json:"operation"`
+	SpanKind  string              `json:"span_kind"`
+	Duration  uint64              `json:"duration"`
+	Tags      map[string][]string `json:"tags"`
+	Span      string              `json:"span"`
+}
+
+// insertBatch is a batch of spans being inserted, kept until inserted or dropped after too many attempts.
+type insertBatch struct {
+	data     []byte
+	size     int
+	failures int
+}
+
+// SpanWriter inserts the spans into ClickHouse in batches, of BatchSize spans or of the spans
+// written during FlushInterval, in the background. A batch whose insert failed is kept and retried
+// once per FlushInterval, before the spans written since, and dropped after MaxRetries retries.
+// WriteSpan rejects the spans while MaxPendingSpans are waiting to be inserted.
+type SpanWriter struct {
+	client       *client
+	table        string
+	batchSize    int
+	maxRetries   int
+	maxPending   int
+	logger       *zap.Logger
+	metrics      *spanstoremetrics.WriteMetrics
+	droppedSpans metrics.Counter
+	marshaler    jsonpb.Marshaler
+
+	mu        sync.Mutex
+	batch     bytes.Buffer
+	size      int
+	inserting insertBatch
+
+	// flushMu serializes the inserts, so that Flush returns once the spans of a concurrent insert are inserted.
+	flushMu sync.Mutex
+	full    chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newSpanWriter creates a SpanWriter inserting the spans into the spans table of the database.
+func newSpanWriter(c *client, opts *Options, metricsFactory metrics.Factory, logger *zap.Logger) *SpanWriter {
+	w := &SpanWriter{
+		client:     c,
+		table:      opts.Database + ".spans",
+		batchSize:  opts.BatchSize,
+		maxRetries: opts.MaxRetries,
+		maxPending: opts.MaxPendingSpans,
+		logger:     logger,
+		metrics:    newWriteMetrics(metricsFactory, "insert_spans"),
+		droppedSpans: metricsFactory.Counter(metrics.Options{
+			Name: "dropped_spans",
+			Tags: map[string]string{"query": "insert_spans"},
+			Help: "The number of spans dropped after too many failed inserts into ClickHouse",
+		}),
+		full: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.flushPeriodically(opts.FlushInterval)
+	return w
+}
+
+// WriteSpan adds the span to the batch of the spans to insert. It returns an error when too many spans
+// are waiting to be inserted, e.g. while ClickHouse is unavailable.
+func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
+	row, err := w.spanToRow(span)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	if w.size+w.inserting.size >= w.maxPending {
+		w.mu.Unlock()
+		return errTooManyPendingSpans
+	}
+	w.batch.Write(row)
+	w.batch.WriteByte('\n')
+	w.size++
+	full := w.size >= w.batchSize
+	w.mu.Unlock()
+	if full {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush inserts the spans written so far, see spanstore.WriterFlusher.
+func (w *SpanWriter) Flush(ctx context.Context) error {
+	return w.flush(ctx)
+}
+
+// PendingWrites returns the number of spans waiting to be inserted, see spanstore.WriterWithBackpressure.
+func (w *SpanWriter) PendingWrites() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return int64(w.size + w.inserting.size)
+}
+
+// Close inserts the remaining spans and stops the background inserts.
+func (w *SpanWriter) Close() error {
+	close(w.done)
+	w.wg.Wait()
+	return w.flush(context.Background())
+}
+
+func (w *SpanWriter) flushPeriodically(interval time.Duration) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.full:
+			if w.retrying() {
+				// the failed batch is retried on the next tick
+				continue
+			}
+		case <-w.done:
+			return
+		}
+		// the failed inserts are logged by insert
+		_ = w.flush(context.Background())
+	}
+}
+
+// flush inserts the failed batch, if any, then the spans written since.
+func (w *SpanWriter) flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	for i := 0; i < 2; i++ {
+		w.mu.Lock()
+		if w.inserting.size == 0 {
+			w.inserting = insertBatch{data: w.batch.Bytes(), size: w.size}
+			w.batch = bytes.Buffer{}
+			w.size = 0
+		}
+		batch := w.inserting
+		w.mu.Unlock()
+		if batch.size == 0 {
+			return nil
+		}
+		if err := w.insert(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insert inserts the batch, which is kept to be retried if the insert fails, unless it failed too many times.
+func (w *SpanWriter) insert(ctx context.Context, batch insertBatch) error {
+	err := w.client.exec(ctx, w.metrics, "INSERT INTO "+w.table+" FORMAT JSONEachRow", nil, bytes.NewReader(batch.data))
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		w.inserting = insertBatch{}
+		return nil
+	}
+	w.inserting.failures++
+	if w.inserting.failures > w.maxRetries {
+		w.logger.Error("Failed to insert spans into ClickHouse, dropping them",
+			zap.Int("spans", batch.size), zap.Int("attempts", w.inserting.failures), zap.Error(err))
+		w.droppedSpans.Inc(int64(batch.size))
+		w.inserting = insertBatch{}
+		return fmt.Errorf("dropped %d spans after %d failed inserts: %w", batch.size, batch.failures+1, err)
+	}
+	w.logger.Warn("Failed to insert spans into ClickHouse, the insert will be retried",
+		zap.Int("spans", batch.size), zap.Int("attempts", w.inserting.failures), zap.Error(err))
+	return err
+}
+
+// retrying tells whether a failed batch is waiting to be retried.
+func (w *SpanWriter) retrying() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.inserting.failures > 0
+}
+
+func (w *SpanWriter) spanToRow(span *model.Span) ([]byte, error) {
+	spanJSON, err := w.marshaler.MarshalToString(span)
+	if err != nil {
+		return nil, err
+	}
+	kind, _ := span.GetSpanKind()
+	return json.Marshal(spanRow{
+		Timestamp: span.StartTime.UTC().Format(timestampFormat),
+		TraceID:   span.TraceID.String(),
+		SpanID:    span.SpanID.String(),
+		Service:   span.Process.GetServiceName(),
+		Operation: span.OperationName,
+		SpanKind:  spanKindString(kind),
+		Duration:  model.DurationAsMicroseconds(span.Duration),
+		Tags:      searchableTags(span),
+		Span:      spanJSON,
+	})
+}
+
+// spanKindString returns the span kind stored for the operations, empty if unspecified like the other storages.
+func spanKindString(kind trace.SpanKind) string {
+	if kind == trace.SpanKindUnspecified {
+		return ""
+	}
+	return kind.String()
+}
+
+// searchableTags returns the values of the tags searched by FindTraces, by key: the tags of the span,
+// of its process and the fields of its logs.
+func searchableTags(span *model.Span) map[string][]string {
+	tags := make(map[string][]string)
+	add := func(kvs []model.KeyValue) {
+		for _, kv := range kvs {
+			value := kv.AsString()
+			if !slices.Contains(tags[kv.Key], value) {
+				tags[kv.Key] = append(tags[kv.Key], value)
+			}
+		}
+	}
+	add(span.Tags)
+	add(span.Process.GetTags())
+	for _, log := range span.Logs {
+		add(log.Fields)
+	}
+	return tags
+}
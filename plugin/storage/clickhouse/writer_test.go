@@ -0,0 +1,189 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+func testSpan(spanID uint64) *model.Span {
+	return &model.Span{
+		TraceID:       model.NewTraceID(0, 0xabc),
+		SpanID:        model.NewSpanID(spanID),
+		OperationName: "checkout",
+		StartTime:     time.Date(2024, 6, 1, 12, 0, 0, 123456000, time.UTC),
+		Duration:      1500 * time.Microsecond,
+		Tags: model.KeyValues{
+			model.String("span.kind", "server"),
+			model.Int64("http.status_code", 200),
+		},
+		Process: &model.Process{
+			ServiceName: "shop",
+			Tags:        model.KeyValues{model.String("hostname", "web-1"), model.String("http.status_code", "500")},
+		},
+		Logs: []model.Log{{Fields: model.KeyValues{model.String("event", "retry")}}},
+	}
+}
+
+func parseSpanRows(t *testing.T, data string) []spanRow {
+	var rows []spanRow
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var row spanRow
+		require.NoError(t, json.Unmarshal([]byte(line), &row))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func newTestSpanWriter(s *fakeServer, batchSize int, flushInterval time.Duration) *SpanWriter {
+	opts := &Options{
+		Database:        "jaeger",
+		BatchSize:       batchSize,
+		FlushInterval:   flushInterval,
+		MaxRetries:      DefaultMaxRetries,
+		MaxPendingSpans: DefaultMaxPendingSpans,
+	}
+	return newSpanWriter(s.client(), opts, metrics.NullFactory, zap.NewNop())
+}
+
+func TestSpanWriterFlush(t *testing.T) {
+	s := newFakeServer(t)
+	w := newTestSpanWriter(s, 100, time.Hour)
+	defer w.Close()
+
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(1)))
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(2)))
+	assert.Empty(t, s.received())
+	require.NoError(t, w.Flush(context.Background()))
+
+	requests := s.received()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "INSERT INTO jaeger.spans FORMAT JSONEachRow", requests[0].statement)
+	rows := parseSpanRows(t, requests[0].data)
+	require.Len(t, rows, 2)
+	row := rows[0]
+	assert.Equal(t, "2024-06-01 12:00:00.123456", row.Timestamp)
+	assert.Equal(t, "0000000000000abc", row.TraceID)
+	assert.Equal(t, "0000000000000001", row.SpanID)
+	assert.Equal(t, "shop", row.Service)
+	assert.Equal(t, "checkout", row.Operation)
+	assert.Equal(t, "server", row.SpanKind)
+	assert.Equal(t, uint64(1500), row.Duration)
+	assert.Equal(t, map[string][]string{
+		"span.kind":        {"server"},
+		"http.status_code": {"200", "500"},
+		"hostname":         {"web-1"},
+		"event":            {"retry"},
+	}, row.Tags)
+	assert.Contains(t, row.Span, `"operationName":"checkout"`)
+
+	// nothing left to insert
+	require.NoError(t, w.Flush(context.Background()))
+	assert.Len(t, s.received(), 1)
+}
+
+func TestSpanWriterBatches(t *testing.T) {
+	s := newFakeServer(t)
+	w := newTestSpanWriter(s, 2, time.Hour)
+
+	// the full batch is inserted in the background
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(1)))
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(2)))
+	assert.Eventually(t, func() bool { return len(s.received()) == 1 }, 5*time.Second, time.Millisecond)
+
+	// the remaining spans are inserted on close
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(3)))
+	require.NoError(t, w.Close())
+	requests := s.received()
+	require.Len(t, requests, 2)
+	assert.Len(t, parseSpanRows(t, requests[1].data), 1)
+}
+
+func TestSpanWriterFlushInterval(t *testing.T) {
+	s := newFakeServer(t)
+	w := newTestSpanWriter(s, 100, time.Millisecond)
+	defer w.Close()
+
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(1)))
+	assert.Eventually(t, func() bool { return len(s.received()) == 1 }, 5*time.Second, time.Millisecond)
+}
+
+func TestSpanWriterInsertError(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(request) (int, string) { return http.StatusServiceUnavailable, "overloaded" })
+	w := newTestSpanWriter(s, 100, time.Hour)
+	defer w.Close()
+
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(1)))
+	require.EqualError(t, w.Flush(context.Background()), "ClickHouse error (HTTP 503): overloaded")
+	assert.Equal(t, int64(1), w.PendingWrites())
+
+	// the failed batch is retried before the spans written since
+	s.setRespond(func(request) (int, string) { return http.StatusOK, "" })
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(2)))
+	require.NoError(t, w.Flush(context.Background()))
+	assert.Equal(t, int64(0), w.PendingWrites())
+	requests := s.received()
+	require.Len(t, requests, 3)
+	assert.Equal(t, requests[0].data, requests[1].data)
+	rows := parseSpanRows(t, requests[2].data)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "0000000000000002", rows[0].SpanID)
+}
+
+func TestSpanWriterDropsAfterMaxRetries(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(request) (int, string) { return http.StatusServiceUnavailable, "overloaded" })
+	metricsFactory := metricstest.NewFactory(0)
+	opts := &Options{Database: "jaeger", BatchSize: 100, FlushInterval: time.Hour, MaxRetries: 1, MaxPendingSpans: 100}
+	w := newSpanWriter(s.client(), opts, metricsFactory, zap.NewNop())
+	defer w.Close()
+
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(1)))
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(2)))
+	require.EqualError(t, w.Flush(context.Background()), "ClickHouse error (HTTP 503): overloaded")
+	require.EqualError(t, w.Flush(context.Background()),
+		"dropped 2 spans after 2 failed inserts: ClickHouse error (HTTP 503): overloaded")
+	assert.Equal(t, int64(0), w.PendingWrites())
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{
+		Name: "dropped_spans", Tags: map[string]string{"query": "insert_spans"}, Value: 2,
+	})
+
+	// nothing left to insert
+	require.NoError(t, w.Flush(context.Background()))
+	assert.Len(t, s.received(), 2)
+}
+
+func TestSpanWriterBackpressure(t *testing.T) {
+	s := newFakeServer(t)
+	s.setRespond(func(request) (int, string) { return http.StatusServiceUnavailable, "overloaded" })
+	opts := &Options{Database: "jaeger", BatchSize: 2, FlushInterval: time.Hour, MaxRetries: 3, MaxPendingSpans: 3}
+	w := newSpanWriter(s.client(), opts, metrics.NullFactory, zap.NewNop())
+	defer w.Close()
+
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(1)))
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(2)))
+	require.Error(t, w.Flush(context.Background()))
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(3)))
+	assert.Equal(t, int64(3), w.PendingWrites())
+	require.ErrorIs(t, w.WriteSpan(context.Background(), testSpan(4)), errTooManyPendingSpans)
+
+	// the writes are accepted again once the spans are inserted
+	s.setRespond(func(request) (int, string) { return http.StatusOK, "" })
+	require.NoError(t, w.Flush(context.Background()))
+	require.NoError(t, w.WriteSpan(context.Background(), testSpan(4)))
+}
@@ -14,7 +14,10 @@
 
 package dbmodel
 
-import "github.com/jaegertracing/jaeger/model"
+import (
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+)
 
 // FromDomainDependencies converts model dependencies to database representation
 func FromDomainDependencies(dLinks []model.DependencyLink) []DependencyLink {
@@ -47,3 +50,25 @@ func ToDomainDependencies(dLinks []DependencyLink) []model.DependencyLink {
 	}
 	return ret
 }
+
+func FromDomainOperationDependencies(dLinks []dependencystore.OperationDependencyLink) []OperationDependencyLink {
+	if dLinks == nil {
+		return nil
+	}
+	ret := make([]OperationDependencyLink, len(dLinks))
+	for i, d := range dLinks {
+		ret[i] = OperationDependencyLink(d)
+	}
+	return ret
+}
+
+func ToDomainOperationDependencies(dLinks []OperationDependencyLink) []dependencystore.OperationDependencyLink {
+	if dLinks == nil {
+		return nil
+	}
+	ret := make([]dependencystore.OperationDependencyLink, len(dLinks))
+	for i, d := range dLinks {
+		ret[i] = dependencystore.OperationDependencyLink(d)
+	}
+	return ret
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 )
 
 func TestConvertDependencies(t *testing.T) {
@@ -51,6 +52,32 @@ func TestConvertDependencies(t *testing.T) {
 	}
 }
 
+func TestConvertOperationDependencies(t *testing.T) {
+	tests := []struct {
+		dLinks []dependencystore.OperationDependencyLink
+	}{
+		{
+			dLinks: []dependencystore.OperationDependencyLink{
+				{CallCount: 1, Parent: "foo", ParentOperation: "get", Child: "bar", ChildOperation: "find"},
+			},
+		},
+		{
+			dLinks: []dependencystore.OperationDependencyLink{},
+		},
+		{
+			dLinks: nil,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := FromDomainOperationDependencies(test.dLinks)
+			a := ToDomainOperationDependencies(got)
+			assert.Equal(t, test.dLinks, a)
+		})
+	}
+}
+
 func TestMain(m *testing.M) {
 	testutils.VerifyGoLeaks(m)
 }
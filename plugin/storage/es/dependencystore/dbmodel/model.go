@@ -28,3 +28,18 @@ type DependencyLink struct {
 	Child     string `json:"child"`
 	CallCount uint64 `json:"callCount"`
 }
+
+// TimeOperationDependencies encapsulates operation dependencies created at a given time
+type TimeOperationDependencies struct {
+	Timestamp    time.Time                 `json:"timestamp"`
+	Dependencies []OperationDependencyLink `json:"dependencies"`
+}
+
+// OperationDependencyLink shows dependencies between the operations of services
+type OperationDependencyLink struct {
+	Parent          string `json:"parent"`
+	ParentOperation string `json:"parentOperation"`
+	Child           string `json:"child"`
+	ChildOperation  string `json:"childOperation"`
+	CallCount       uint64 `json:"callCount"`
+}
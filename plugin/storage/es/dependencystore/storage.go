@@ -28,12 +28,14 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/es"
 	"github.com/jaegertracing/jaeger/plugin/storage/es/dependencystore/dbmodel"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 )
 
 const (
-	dependencyType       = "dependencies"
-	dependencyIndex      = "jaeger-dependencies-"
-	indexPrefixSeparator = "-"
+	dependencyType           = "dependencies"
+	dependencyIndex          = "jaeger-dependencies-"
+	operationDependencyIndex = "jaeger-operation-dependencies-"
+	indexPrefixSeparator     = "-"
 )
 
 // DependencyStore handles all queries and insertions to ElasticSearch dependencies
@@ -41,30 +43,42 @@ type DependencyStore struct {
 	client                func() es.Client
 	logger                *zap.Logger
 	dependencyIndexPrefix string
-	indexDateLayout       string
-	maxDocCount           int
-	useReadWriteAliases   bool
+	// operationDependencyIndexPrefix prefixes the indices of the dependencies between operations
+	operationDependencyIndexPrefix string
+	indexDateLayout                string
+	maxDocCount                    int
+	maxOperationsPerService        int
+	useReadWriteAliases            bool
 }
 
 // DependencyStoreParams holds constructor parameters for NewDependencyStore
 type Params struct {
-	Client              func() es.Client
-	Logger              *zap.Logger
-	IndexPrefix         string
-	IndexDateLayout     string
-	MaxDocCount         int
-	UseReadWriteAliases bool
+	Client          func() es.Client
+	Logger          *zap.Logger
+	IndexPrefix     string
+	IndexDateLayout string
+	MaxDocCount     int
+	// MaxOperationsPerService bounds the number of operations of each service in the operation
+	// dependencies, dependencystore.DefaultMaxOperationsPerService if not positive
+	MaxOperationsPerService int
+	UseReadWriteAliases     bool
 }
 
 // NewDependencyStore returns a DependencyStore
 func NewDependencyStore(p Params) *DependencyStore {
+	maxOperationsPerService := p.MaxOperationsPerService
+	if maxOperationsPerService <= 0 {
+		maxOperationsPerService = dependencystore.DefaultMaxOperationsPerService
+	}
 	return &DependencyStore{
-		client:                p.Client,
-		logger:                p.Logger,
-		dependencyIndexPrefix: prefixIndexName(p.IndexPrefix, dependencyIndex),
-		indexDateLayout:       p.IndexDateLayout,
-		maxDocCount:           p.MaxDocCount,
-		useReadWriteAliases:   p.UseReadWriteAliases,
+		client:                         p.Client,
+		logger:                         p.Logger,
+		dependencyIndexPrefix:          prefixIndexName(p.IndexPrefix, dependencyIndex),
+		operationDependencyIndexPrefix: prefixIndexName(p.IndexPrefix, operationDependencyIndex),
+		indexDateLayout:                p.IndexDateLayout,
+		maxDocCount:                    p.MaxDocCount,
+		maxOperationsPerService:        maxOperationsPerService,
+		useReadWriteAliases:            p.UseReadWriteAliases,
 	}
 }
 
@@ -124,21 +138,70 @@ func (s *DependencyStore) GetDependencies(ctx context.Context, endTs time.Time,
 	return dbmodel.ToDomainDependencies(retDependencies), nil
 }
 
+// WriteOperationDependencies implements dependencystore.OperationDependencyWriter#WriteOperationDependencies.
+// Only the top operations of each service are stored, the others being merged.
+func (s *DependencyStore) WriteOperationDependencies(ts time.Time, dependencies []dependencystore.OperationDependencyLink) error {
+	dependencies = dependencystore.LimitOperations(dependencies, s.maxOperationsPerService)
+	s.client().Index().Index(s.indexFor(s.operationDependencyIndexPrefix, ts)).Type(dependencyType).
+		BodyJson(&dbmodel.TimeOperationDependencies{
+			Timestamp:    ts,
+			Dependencies: dbmodel.FromDomainOperationDependencies(dependencies),
+		}).Add()
+	return nil
+}
+
+// GetOperationDependencies implements dependencystore.OperationDependencyReader#GetOperationDependencies
+func (s *DependencyStore) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	indices := s.readIndicesFor(s.operationDependencyIndexPrefix, endTs, lookback)
+	searchResult, err := s.client().Search(indices...).
+		Size(s.maxDocCount).
+		Query(buildTSQuery(endTs, lookback)).
+		IgnoreUnavailable(true).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for operation dependencies: %w", err)
+	}
+
+	var dependencies []dependencystore.OperationDependencyLink
+	for _, hit := range searchResult.Hits.Hits {
+		var tToD dbmodel.TimeOperationDependencies
+		if err := json.Unmarshal(*hit.Source, &tToD); err != nil {
+			return nil, errors.New("unmarshalling ElasticSearch documents failed")
+		}
+		for _, d := range dbmodel.ToDomainOperationDependencies(tToD.Dependencies) {
+			if d.Parent == service || d.Child == service {
+				dependencies = append(dependencies, d)
+			}
+		}
+	}
+	// the documents are limited separately, the links of the time range are limited again once added up
+	return dependencystore.LimitOperations(dependencies, s.maxOperationsPerService), nil
+}
+
 func buildTSQuery(endTs time.Time, lookback time.Duration) elastic.Query {
 	return elastic.NewRangeQuery("timestamp").Gte(endTs.Add(-lookback)).Lte(endTs)
 }
 
 func (s *DependencyStore) getReadIndices(ts time.Time, lookback time.Duration) []string {
+	return s.readIndicesFor(s.dependencyIndexPrefix, ts, lookback)
+}
+
+func (s *DependencyStore) readIndicesFor(indexPrefix string, ts time.Time, lookback time.Duration) []string {
 	if s.useReadWriteAliases {
-		return []string{s.dependencyIndexPrefix + "read"}
+		return []string{indexPrefix + "read"}
 	}
 	var indices []string
-	firstIndex := indexWithDate(s.dependencyIndexPrefix, s.indexDateLayout, ts.Add(-lookback))
-	currentIndex := indexWithDate(s.dependencyIndexPrefix, s.indexDateLayout, ts)
+	firstIndex := indexWithDate(indexPrefix, s.indexDateLayout, ts.Add(-lookback))
+	currentIndex := indexWithDate(indexPrefix, s.indexDateLayout, ts)
 	for currentIndex != firstIndex {
 		indices = append(indices, currentIndex)
 		ts = ts.Add(-24 * time.Hour)
-		currentIndex = indexWithDate(s.dependencyIndexPrefix, s.indexDateLayout, ts)
+		currentIndex = indexWithDate(indexPrefix, s.indexDateLayout, ts)
 	}
 	return append(indices, firstIndex)
 }
@@ -148,8 +211,12 @@ func indexWithDate(indexNamePrefix, indexDateLayout string, date time.Time) stri
 }
 
 func (s *DependencyStore) getWriteIndex(ts time.Time) string {
+	return s.indexFor(s.dependencyIndexPrefix, ts)
+}
+
+func (s *DependencyStore) indexFor(indexPrefix string, ts time.Time) string {
 	if s.useReadWriteAliases {
-		return s.dependencyIndexPrefix + "write"
+		return indexPrefix + "write"
 	}
-	return indexWithDate(s.dependencyIndexPrefix, s.indexDateLayout, ts)
+	return indexWithDate(indexPrefix, s.indexDateLayout, ts)
 }
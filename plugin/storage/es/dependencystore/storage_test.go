@@ -33,6 +33,7 @@ import (
 	"github.com/jaegertracing/jaeger/pkg/es"
 	"github.com/jaegertracing/jaeger/pkg/es/mocks"
 	"github.com/jaegertracing/jaeger/pkg/testutils"
+	"github.com/jaegertracing/jaeger/plugin/storage/es/dependencystore/dbmodel"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 )
 
@@ -66,6 +67,9 @@ func withDepStorage(indexPrefix, indexDateLayout string, maxDocCount int, fn fun
 var (
 	_ dependencystore.Reader = &DependencyStore{} // check API conformance
 	_ dependencystore.Writer = &DependencyStore{} // check API conformance
+
+	_ dependencystore.OperationDependencyReader = &DependencyStore{} // check API conformance
+	_ dependencystore.OperationDependencyWriter = &DependencyStore{} // check API conformance
 )
 
 func TestNewSpanReaderIndexPrefix(t *testing.T) {
@@ -201,6 +205,101 @@ func TestGetDependencies(t *testing.T) {
 	}
 }
 
+func TestWriteOperationDependencies(t *testing.T) {
+	withDepStorage("", "2006-01-02", defaultMaxDocCount, func(r *depStorageTest) {
+		r.storage.maxOperationsPerService = 1
+		fixedTime := time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC)
+		writeService := &mocks.IndexService{}
+		r.client.On("Index").Return(writeService)
+		writeService.On("Index", "jaeger-operation-dependencies-1995-04-21").Return(writeService)
+		writeService.On("Type", dependencyType).Return(writeService)
+		writeService.On("BodyJson", &dbmodel.TimeOperationDependencies{
+			Timestamp: fixedTime,
+			Dependencies: []dbmodel.OperationDependencyLink{
+				{Parent: "frontend", ParentOperation: "/checkout", Child: "orders", ChildOperation: "create", CallCount: 10},
+				{Parent: "frontend", ParentOperation: dependencystore.OtherOperations, Child: "orders", ChildOperation: dependencystore.OtherOperations, CallCount: 3},
+			},
+		}).Return(writeService)
+		writeService.On("Add").Return()
+
+		err := r.storage.WriteOperationDependencies(fixedTime, []dependencystore.OperationDependencyLink{
+			{Parent: "frontend", ParentOperation: "/cart", Child: "orders", ChildOperation: "get", CallCount: 3},
+			{Parent: "frontend", ParentOperation: "/checkout", Child: "orders", ChildOperation: "create", CallCount: 10},
+		})
+		require.NoError(t, err)
+		writeService.AssertExpectations(t)
+	})
+}
+
+func TestGetOperationDependencies(t *testing.T) {
+	dependencies := `{
+			"timestamp": "1995-04-21T00:00:00Z",
+			"dependencies": [
+				{ "parent": "frontend", "parentOperation": "/checkout", "child": "orders", "childOperation": "create", "callCount": 12 },
+				{ "parent": "orders", "parentOperation": "create", "child": "mysql", "childOperation": "insert", "callCount": 12 },
+				{ "parent": "customers", "parentOperation": "get", "child": "mysql", "childOperation": "select", "callCount": 5 }
+			]
+		}`
+	testCases := []struct {
+		name           string
+		searchResult   *elastic.SearchResult
+		searchError    error
+		expectedError  string
+		expectedOutput []dependencystore.OperationDependencyLink
+	}{
+		{
+			name:         "dependencies of the service",
+			searchResult: createSearchResults(dependencies, dependencies),
+			expectedOutput: []dependencystore.OperationDependencyLink{
+				{Parent: "frontend", ParentOperation: "/checkout", Child: "orders", ChildOperation: "create", CallCount: 24},
+				{Parent: "orders", ParentOperation: "create", Child: "mysql", ChildOperation: "insert", CallCount: 24},
+			},
+		},
+		{
+			name:          "bad document",
+			searchResult:  createSearchResult(`badJson{hello}world`),
+			expectedError: "unmarshalling ElasticSearch documents failed",
+		},
+		{
+			name:          "search failure",
+			searchError:   errors.New("search failure"),
+			expectedError: "failed to search for operation dependencies: search failure",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			withDepStorage("", "2006-01-02", defaultMaxDocCount, func(r *depStorageTest) {
+				fixedTime := time.Date(1995, time.April, 21, 4, 21, 19, 95, time.UTC)
+				searchService := &mocks.SearchService{}
+				r.client.On("Search", "jaeger-operation-dependencies-1995-04-21", "jaeger-operation-dependencies-1995-04-20").
+					Return(searchService)
+				searchService.On("Size", defaultMaxDocCount).Return(searchService)
+				searchService.On("Query", mock.Anything).Return(searchService)
+				searchService.On("IgnoreUnavailable", true).Return(searchService)
+				searchService.On("Do", mock.Anything).Return(testCase.searchResult, testCase.searchError)
+
+				actual, err := r.storage.GetOperationDependencies(context.Background(), "orders", fixedTime, 24*time.Hour)
+				if testCase.expectedError != "" {
+					require.EqualError(t, err, testCase.expectedError)
+					assert.Nil(t, actual)
+				} else {
+					require.NoError(t, err)
+					assert.Equal(t, testCase.expectedOutput, actual)
+				}
+			})
+		})
+	}
+}
+
+func createSearchResults(sources ...string) *elastic.SearchResult {
+	hits := make([]*elastic.SearchHit, len(sources))
+	for i, source := range sources {
+		raw := json.RawMessage(source)
+		hits[i] = &elastic.SearchHit{Source: &raw}
+	}
+	return &elastic.SearchResult{Hits: &elastic.SearchHits{Hits: hits}}
+}
+
 func createSearchResult(dependencyLink string) *elastic.SearchResult {
 	dependencyLinkRaw := []byte(dependencyLink)
 	hits := make([]*elastic.SearchHit, 1)
@@ -71,9 +71,12 @@ type Factory struct {
 
 	primaryConfig *config.Configuration
 	archiveConfig *config.Configuration
+	// secondaryConfig is the configuration of the secondary indices of the migration, nil without migration
+	secondaryConfig *config.Configuration
 
-	primaryClient atomic.Pointer[es.Client]
-	archiveClient atomic.Pointer[es.Client]
+	primaryClient   atomic.Pointer[es.Client]
+	archiveClient   atomic.Pointer[es.Client]
+	secondaryClient atomic.Pointer[es.Client]
 
 	watchers []*fswatcher.FSWatcher
 }
@@ -136,11 +139,18 @@ func (f *Factory) configureFromOptions(o *Options) {
 	f.Options = o
 	f.primaryConfig = f.Options.GetPrimary()
 	f.archiveConfig = f.Options.Get(archiveNamespace)
+	f.secondaryConfig = nil
+	if f.Options.Migration.enabled() {
+		f.secondaryConfig = f.Options.Migration.secondaryConfig(f.primaryConfig)
+	}
 }
 
 // Initialize implements storage.Factory.
 func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
 	f.metricsFactory, f.logger = metricsFactory, logger
+	if err := f.Options.Migration.validate(f.primaryConfig); err != nil {
+		return err
+	}
 
 	primaryClient, err := f.newClientFn(f.primaryConfig, logger, metricsFactory)
 	if err != nil {
@@ -172,9 +182,31 @@ func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger)
 		}
 	}
 
+	if f.secondaryConfig != nil {
+		secondaryClient, err := f.newClientFn(f.secondaryConfig, logger, f.secondaryMetricsFactory())
+		if err != nil {
+			return fmt.Errorf("failed to create the Elasticsearch client of the secondary indices of the migration: %w", err)
+		}
+		f.secondaryClient.Store(&secondaryClient)
+
+		if f.secondaryConfig.PasswordFilePath != "" {
+			secondaryWatcher, err := fswatcher.New([]string{f.secondaryConfig.PasswordFilePath}, f.onSecondaryPasswordChange, f.logger)
+			if err != nil {
+				return fmt.Errorf("failed to create watcher for the password of the ES client of the secondary indices: %w", err)
+			}
+			f.watchers = append(f.watchers, secondaryWatcher)
+		}
+	}
+
 	return nil
 }
 
+// secondaryMetricsFactory returns the metrics factory of the secondary indices of the migration,
+// so that their writes are counted apart from the primary ones.
+func (f *Factory) secondaryMetricsFactory() metrics.Factory {
+	return f.metricsFactory.Namespace(metrics.NSOptions{Name: migrationMetricsNamespace})
+}
+
 func (f *Factory) getPrimaryClient() es.Client {
 	if c := f.primaryClient.Load(); c != nil {
 		return *c
@@ -189,14 +221,39 @@ func (f *Factory) getArchiveClient() es.Client {
 	return nil
 }
 
-// CreateSpanReader implements storage.Factory
+func (f *Factory) getSecondaryClient() es.Client {
+	if c := f.secondaryClient.Load(); c != nil {
+		return *c
+	}
+	return nil
+}
+
+// CreateSpanReader implements storage.Factory. During a migration, the primary and the secondary
+// indices are read in the order of the read preference.
 func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
-	return createSpanReader(f.getPrimaryClient, f.primaryConfig, false, f.metricsFactory, f.logger, f.tracer)
+	reader, err := createSpanReader(f.getPrimaryClient, f.primaryConfig, false, f.metricsFactory, f.logger, f.tracer)
+	if err != nil || f.secondaryConfig == nil {
+		return reader, err
+	}
+	secondaryReader, err := createSpanReader(f.getSecondaryClient, f.secondaryConfig, false, f.secondaryMetricsFactory(), f.logger, f.tracer)
+	if err != nil {
+		return nil, err
+	}
+	return newMigrationReader(reader, secondaryReader, f.Options.Migration.ReadPreference), nil
 }
 
-// CreateSpanWriter implements storage.Factory
+// CreateSpanWriter implements storage.Factory. During a migration with dual writes, the spans are also
+// written to the secondary indices.
 func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
-	return createSpanWriter(f.getPrimaryClient, f.primaryConfig, false, f.metricsFactory, f.logger)
+	writer, err := createSpanWriter(f.getPrimaryClient, f.primaryConfig, false, f.metricsFactory, f.logger)
+	if err != nil || f.secondaryConfig == nil || !f.Options.Migration.DualWrite {
+		return writer, err
+	}
+	secondaryWriter, err := createSpanWriter(f.getSecondaryClient, f.secondaryConfig, false, f.secondaryMetricsFactory(), f.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the span writer of the secondary indices of the migration: %w", err)
+	}
+	return newDualWriter(writer, secondaryWriter, f.metricsFactory, f.logger), nil
 }
 
 // CreateDependencyReader implements storage.Factory
@@ -231,6 +288,11 @@ func createSpanReader(
 	if cfg.UseILM && !cfg.UseReadWriteAliases {
 		return nil, fmt.Errorf("--es.use-ilm must always be used in conjunction with --es.use-aliases to ensure ES writers and readers refer to the single index mapping")
 	}
+	var zipkinCompatIndexPrefix string
+	if !archive {
+		// archived traces are always stored by Jaeger
+		zipkinCompatIndexPrefix = cfg.ZipkinCompatIndexPrefix
+	}
 	return esSpanStore.NewSpanReader(esSpanStore.SpanReaderParams{
 		Client:                        clientFn,
 		MaxDocCount:                   cfg.MaxDocCount,
@@ -244,6 +306,7 @@ func createSpanReader(
 		UseReadWriteAliases:           cfg.UseReadWriteAliases,
 		Archive:                       archive,
 		RemoteReadClusters:            cfg.RemoteReadClusters,
+		ZipkinCompatIndexPrefix:       zipkinCompatIndexPrefix,
 		Logger:                        logger,
 		MetricsFactory:                mFactory,
 		Tracer:                        tp.Tracer("esSpanStore.SpanReader"),
@@ -327,7 +390,8 @@ func mappingBuilderFromConfig(cfg *config.Configuration) mappings.MappingBuilder
 		TemplateBuilder:              es.TextTemplateBuilder{},
 		Shards:                       cfg.NumShards,
 		Replicas:                     cfg.NumReplicas,
-		EsVersion:                    cfg.Version,
+		EsVersion:                    cfg.ClusterVersion().EsCompatibleVersion(),
+		UseComposableTemplates:       cfg.ClusterVersion().UseComposableTemplates(),
 		IndexPrefix:                  cfg.IndexPrefix,
 		UseILM:                       cfg.UseILM,
 		PrioritySpanTemplate:         cfg.PrioritySpanTemplate,
@@ -369,19 +433,26 @@ func (f *Factory) Close() error {
 	if client := f.getArchiveClient(); client != nil {
 		errs = append(errs, client.Close())
 	}
+	if client := f.getSecondaryClient(); client != nil {
+		errs = append(errs, client.Close())
+	}
 
 	return errors.Join(errs...)
 }
 
 func (f *Factory) onPrimaryPasswordChange() {
-	f.onClientPasswordChange(f.primaryConfig, &f.primaryClient)
+	f.onClientPasswordChange(f.primaryConfig, &f.primaryClient, f.metricsFactory)
 }
 
 func (f *Factory) onArchivePasswordChange() {
-	f.onClientPasswordChange(f.archiveConfig, &f.archiveClient)
+	f.onClientPasswordChange(f.archiveConfig, &f.archiveClient, f.metricsFactory)
+}
+
+func (f *Factory) onSecondaryPasswordChange() {
+	f.onClientPasswordChange(f.secondaryConfig, &f.secondaryClient, f.secondaryMetricsFactory())
 }
 
-func (f *Factory) onClientPasswordChange(cfg *config.Configuration, client *atomic.Pointer[es.Client]) {
+func (f *Factory) onClientPasswordChange(cfg *config.Configuration, client *atomic.Pointer[es.Client], metricsFactory metrics.Factory) {
 	newPassword, err := loadTokenFromFile(cfg.PasswordFilePath)
 	if err != nil {
 		f.logger.Error("failed to reload password for Elasticsearch client", zap.Error(err))
@@ -392,7 +463,7 @@ func (f *Factory) onClientPasswordChange(cfg *config.Configuration, client *atom
 	newCfg.Password = newPassword
 	newCfg.PasswordFilePath = "" // avoid error that both are set
 
-	newClient, err := f.newClientFn(&newCfg, f.logger, f.metricsFactory)
+	newClient, err := f.newClientFn(&newCfg, f.logger, metricsFactory)
 	if err != nil {
 		f.logger.Error("failed to recreate Elasticsearch client with new password", zap.Error(err))
 		return
@@ -406,8 +477,14 @@ func (f *Factory) onClientPasswordChange(cfg *config.Configuration, client *atom
 
 func (f *Factory) Purge(ctx context.Context) error {
 	esClient := f.getPrimaryClient()
-	_, err := esClient.DeleteIndex("*").Do(ctx)
-	return err
+	if _, err := esClient.DeleteIndex("*").Do(ctx); err != nil {
+		return err
+	}
+	if client := f.getSecondaryClient(); client != nil {
+		_, err := client.DeleteIndex("*").Do(ctx)
+		return err
+	}
+	return nil
 }
 
 func loadTokenFromFile(path string) (string, error) {
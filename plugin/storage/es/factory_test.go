@@ -287,6 +287,87 @@ func TestESStorageFactoryWithConfig(t *testing.T) {
 	defer factory.Close()
 }
 
+// fakeESCluster is a fake Elasticsearch server recording the paths of the requests it receives.
+type fakeESCluster struct {
+	*httptest.Server
+	paths sync.Map
+}
+
+func newFakeESCluster(t *testing.T) *fakeESCluster {
+	c := &fakeESCluster{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.paths.Store(r.URL.Path, true)
+		w.Write(mockEsServerResponse)
+	}))
+	t.Cleanup(c.Server.Close)
+	return c
+}
+
+func (c *fakeESCluster) received(path string) bool {
+	_, ok := c.paths.Load(path)
+	return ok
+}
+
+func TestArchiveInSeparateCluster(t *testing.T) {
+	hot, cold := newFakeESCluster(t), newFakeESCluster(t)
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--es.server-urls=" + hot.URL,
+		"--es.bulk.size=-1",
+		"--es-archive.enabled=true",
+		"--es-archive.server-urls=" + cold.URL,
+		"--es-archive.bulk.size=-1",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zaptest.NewLogger(t)))
+	defer f.Close()
+
+	span := &model.Span{
+		Process: &model.Process{ServiceName: "foo"},
+	}
+	archiveWriter, err := f.CreateArchiveSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, archiveWriter.WriteSpan(context.Background(), span))
+	assert.Eventually(t, func() bool { return cold.received("/_bulk") }, 5*time.Second, time.Millisecond)
+
+	archiveReader, err := f.CreateArchiveSpanReader()
+	require.NoError(t, err)
+	// the fake cluster does not return the trace, only the requested cluster matters
+	_, _ = archiveReader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	assert.True(t, cold.received("/_msearch"))
+	assert.False(t, hot.received("/_bulk"))
+	assert.False(t, hot.received("/_msearch"))
+
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteSpan(context.Background(), span))
+	assert.Eventually(t, func() bool { return hot.received("/_bulk") }, 5*time.Second, time.Millisecond)
+}
+
+func TestArchiveInPrimaryCluster(t *testing.T) {
+	hot := newFakeESCluster(t)
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--es.server-urls=" + hot.URL,
+		"--es-archive.enabled=true",
+		"--es-archive.bulk.size=-1",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zaptest.NewLogger(t)))
+	defer f.Close()
+
+	archiveWriter, err := f.CreateArchiveSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, archiveWriter.WriteSpan(context.Background(), &model.Span{
+		Process: &model.Process{ServiceName: "foo"},
+	}))
+	assert.Eventually(t, func() bool { return hot.received("/_bulk") }, 5*time.Second, time.Millisecond)
+}
+
 func TestConfigurationValidation(t *testing.T) {
 	testCases := []struct {
 		name    string
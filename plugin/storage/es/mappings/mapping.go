@@ -37,14 +37,16 @@ type MappingBuilder struct {
 	PriorityDependenciesTemplate int64
 	PrioritySamplingTemplate     int64
 	EsVersion                    uint
+	UseComposableTemplates       bool
 	IndexPrefix                  string
 	UseILM                       bool
 	ILMPolicyName                string
 }
 
-// GetMapping returns the rendered mapping based on elasticsearch version
+// GetMapping returns the rendered mapping based on elasticsearch version.
+// Composable templates, which are also used by OpenSearch 2.x and newer, are rendered from the ES 8.x mappings.
 func (mb *MappingBuilder) GetMapping(mapping string) (string, error) {
-	if mb.EsVersion == 8 {
+	if mb.EsVersion == 8 || mb.UseComposableTemplates {
 		return mb.fixMapping(mapping + "-8.json")
 	}
 	return mb.fixMapping(mapping + "-7.json")
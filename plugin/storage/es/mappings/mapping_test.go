@@ -37,15 +37,20 @@ var FIXTURES embed.FS
 
 func TestMappingBuilder_GetMapping(t *testing.T) {
 	tests := []struct {
-		mapping   string
-		esVersion uint
+		mapping             string
+		esVersion           uint
+		composableTemplates bool
+		fixtureVersion      uint
 	}{
-		{mapping: "jaeger-span", esVersion: 8},
-		{mapping: "jaeger-span", esVersion: 7},
-		{mapping: "jaeger-service", esVersion: 8},
-		{mapping: "jaeger-service", esVersion: 7},
-		{mapping: "jaeger-dependencies", esVersion: 8},
-		{mapping: "jaeger-dependencies", esVersion: 7},
+		{mapping: "jaeger-span", esVersion: 8, fixtureVersion: 8},
+		{mapping: "jaeger-span", esVersion: 7, fixtureVersion: 7},
+		{mapping: "jaeger-span", esVersion: 7, composableTemplates: true, fixtureVersion: 8},
+		{mapping: "jaeger-service", esVersion: 8, fixtureVersion: 8},
+		{mapping: "jaeger-service", esVersion: 7, fixtureVersion: 7},
+		{mapping: "jaeger-service", esVersion: 7, composableTemplates: true, fixtureVersion: 8},
+		{mapping: "jaeger-dependencies", esVersion: 8, fixtureVersion: 8},
+		{mapping: "jaeger-dependencies", esVersion: 7, fixtureVersion: 7},
+		{mapping: "jaeger-dependencies", esVersion: 7, composableTemplates: true, fixtureVersion: 8},
 	}
 	for _, tt := range tests {
 		t.Run(tt.mapping, func(t *testing.T) {
@@ -57,6 +62,7 @@ func TestMappingBuilder_GetMapping(t *testing.T) {
 				PriorityServiceTemplate:      501,
 				PriorityDependenciesTemplate: 502,
 				EsVersion:                    tt.esVersion,
+				UseComposableTemplates:       tt.composableTemplates,
 				IndexPrefix:                  "test-",
 				UseILM:                       true,
 				ILMPolicyName:                "jaeger-test-policy",
@@ -64,7 +70,7 @@ func TestMappingBuilder_GetMapping(t *testing.T) {
 			got, err := mb.GetMapping(tt.mapping)
 			require.NoError(t, err)
 			var wantbytes []byte
-			fileSuffix := fmt.Sprintf("-%d", tt.esVersion)
+			fileSuffix := fmt.Sprintf("-%d", tt.fixtureVersion)
 			wantbytes, err = FIXTURES.ReadFile("fixtures/" + tt.mapping + fileSuffix + ".json")
 			require.NoError(t, err)
 			want := string(wantbytes)
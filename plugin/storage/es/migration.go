@@ -0,0 +1,422 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package es
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/es/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const (
+	// ReadPreferenceOldFirst reads the indices of the old mapping, and the ones of the new mapping
+	// only when nothing is found in the old ones.
+	ReadPreferenceOldFirst = "old-first"
+	// ReadPreferenceNewFirst reads the indices of the new mapping, and the ones of the old mapping
+	// only when nothing is found in the new ones.
+	ReadPreferenceNewFirst = "new-first"
+	// ReadPreferenceMerge reads the indices of both mappings and merges the results.
+	ReadPreferenceMerge = "merge"
+
+	suffixMigrationDualWrite            = ".migration.dual-write"
+	suffixMigrationSecondaryIndexPrefix = ".migration.secondary-index-prefix"
+	suffixMigrationSecondaryServerURLs  = ".migration.secondary-server-urls"
+	suffixMigrationSecondaryTagsAll     = ".migration.secondary-tags-as-fields.all"
+	suffixMigrationReadPreference       = ".migration.read-preference"
+
+	// migrationMetricsNamespace holds the metrics of the writes to the indices of the new mapping,
+	// so that their failures are counted apart from the ones of the old mapping.
+	migrationMetricsNamespace = "migration_secondary"
+)
+
+// MigrationOptions configure the migration of the spans of the primary storage to a second set of indices,
+// the secondary indices, with a new mapping: the spans can be written to both sets of indices while the
+// older spans are reindexed, and the reads can be cut over to the new indices independently of the writes.
+type MigrationOptions struct {
+	// DualWrite writes the spans to the secondary indices in addition to the primary ones.
+	DualWrite bool
+	// SecondaryIndexPrefix is the index prefix of the secondary indices.
+	SecondaryIndexPrefix string
+	// SecondaryServers are the servers of the secondary indices, the primary servers if empty.
+	SecondaryServers []string
+	// SecondaryAllTagsAsFields stores the tags of the spans written to the secondary indices as object fields.
+	SecondaryAllTagsAsFields bool
+	// ReadPreference is one of the ReadPreference* constants, ReadPreferenceOldFirst if empty.
+	ReadPreference string
+}
+
+// enabled returns whether the secondary indices are configured.
+func (m *MigrationOptions) enabled() bool {
+	return m.SecondaryIndexPrefix != "" || len(m.SecondaryServers) > 0
+}
+
+func (m *MigrationOptions) validate(primary *config.Configuration) error {
+	switch m.ReadPreference {
+	case "", ReadPreferenceOldFirst, ReadPreferenceNewFirst, ReadPreferenceMerge:
+	default:
+		return fmt.Errorf("invalid %s %q, expected %s, %s or %s", primaryNamespace+suffixMigrationReadPreference,
+			m.ReadPreference, ReadPreferenceOldFirst, ReadPreferenceNewFirst, ReadPreferenceMerge)
+	}
+	if !m.enabled() {
+		if m.DualWrite {
+			return fmt.Errorf("%s requires %s or %s", primaryNamespace+suffixMigrationDualWrite,
+				primaryNamespace+suffixMigrationSecondaryIndexPrefix, primaryNamespace+suffixMigrationSecondaryServerURLs)
+		}
+		return nil
+	}
+	if len(m.SecondaryServers) == 0 && m.SecondaryIndexPrefix == primary.IndexPrefix {
+		return fmt.Errorf("the secondary indices of the migration must differ from the primary ones, set %s",
+			primaryNamespace+suffixMigrationSecondaryIndexPrefix)
+	}
+	return nil
+}
+
+// secondaryConfig returns the configuration of the secondary indices, derived from the primary one.
+func (m *MigrationOptions) secondaryConfig(primary *config.Configuration) *config.Configuration {
+	cfg := *primary
+	cfg.IndexPrefix = m.SecondaryIndexPrefix
+	if len(m.SecondaryServers) > 0 {
+		cfg.Servers = m.SecondaryServers
+	}
+	if m.SecondaryAllTagsAsFields {
+		cfg.Tags.AllAsFields = true
+	}
+	// the spans are only migrated by the span writer and read by the span reader
+	cfg.ZipkinCompatIndexPrefix = ""
+	return &cfg
+}
+
+func addMigrationFlags(flagSet *flag.FlagSet) {
+	flagSet.Bool(
+		primaryNamespace+suffixMigrationDualWrite,
+		false,
+		"(experimental) Write the spans to the secondary indices of a mapping migration in addition to the primary ones, "+
+			"e.g. while the older spans are reindexed; the failures of the secondary writes are counted apart and do not fail the spans")
+	flagSet.String(
+		primaryNamespace+suffixMigrationSecondaryIndexPrefix,
+		"",
+		"(experimental) The index prefix of the secondary indices of a mapping migration, which have the new mapping; "+
+			"the index templates are created like the primary ones when "+primaryNamespace+suffixCreateIndexTemplate+" is enabled")
+	flagSet.String(
+		primaryNamespace+suffixMigrationSecondaryServerURLs,
+		"",
+		"(experimental) The comma-separated list of the Elasticsearch servers of the secondary indices of a mapping migration, "+
+			"the primary servers if empty")
+	flagSet.Bool(
+		primaryNamespace+suffixMigrationSecondaryTagsAll,
+		false,
+		"(experimental) Store all the tags of the spans written to the secondary indices as object fields, "+
+			"e.g. to migrate from nested tags to flattened tags")
+	flagSet.String(
+		primaryNamespace+suffixMigrationReadPreference,
+		ReadPreferenceOldFirst,
+		fmt.Sprintf("(experimental) The indices read during a mapping migration: %s reads the secondary indices only when nothing is found "+
+			"in the primary ones, %s the other way around, and %s reads both and merges the results", ReadPreferenceOldFirst, ReadPreferenceNewFirst, ReadPreferenceMerge))
+}
+
+func (m *MigrationOptions) initFromViper(v *viper.Viper) {
+	m.DualWrite = v.GetBool(primaryNamespace + suffixMigrationDualWrite)
+	m.SecondaryIndexPrefix = v.GetString(primaryNamespace + suffixMigrationSecondaryIndexPrefix)
+	m.SecondaryServers = nil
+	if servers := stripWhiteSpace(v.GetString(primaryNamespace + suffixMigrationSecondaryServerURLs)); servers != "" {
+		m.SecondaryServers = strings.Split(servers, ",")
+	}
+	m.SecondaryAllTagsAsFields = v.GetBool(primaryNamespace + suffixMigrationSecondaryTagsAll)
+	m.ReadPreference = v.GetString(primaryNamespace + suffixMigrationReadPreference)
+}
+
+// dualWriter writes the spans to the primary and the secondary indices, only the failures
+// of the primary writes failing the spans.
+type dualWriter struct {
+	primary         spanstore.Writer
+	secondary       spanstore.Writer
+	secondaryErrors metrics.Counter
+	logger          *zap.Logger
+}
+
+func newDualWriter(primary, secondary spanstore.Writer, metricsFactory metrics.Factory, logger *zap.Logger) *dualWriter {
+	return &dualWriter{
+		primary:   primary,
+		secondary: secondary,
+		secondaryErrors: metricsFactory.Namespace(metrics.NSOptions{Name: migrationMetricsNamespace}).Counter(metrics.Options{
+			Name: "write_errors",
+			Help: "The number of spans which failed to be written to the secondary indices of the migration",
+		}),
+		logger: logger,
+	}
+}
+
+// WriteSpan writes the span to the secondary indices once written to the primary ones.
+func (w *dualWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	if err := w.primary.WriteSpan(ctx, span); err != nil {
+		return err
+	}
+	if err := w.secondary.WriteSpan(ctx, span); err != nil {
+		w.secondaryErrors.Inc(1)
+		w.logger.Debug("Failed to write the span to the secondary indices of the migration", zap.Error(err))
+	}
+	return nil
+}
+
+// PendingWrites returns the pending writes of the primary indices, the secondary writes applying no backpressure.
+func (w *dualWriter) PendingWrites() int64 {
+	return spanstore.PendingWrites(w.primary)
+}
+
+// migrationReader reads the primary indices, of the old mapping, and the secondary indices, of the new mapping,
+// in the order of the read preference.
+type migrationReader struct {
+	oldIndices spanstore.Reader
+	newIndices spanstore.Reader
+	preference string
+}
+
+func newMigrationReader(oldIndices, newIndices spanstore.Reader, preference string) *migrationReader {
+	return &migrationReader{oldIndices: oldIndices, newIndices: newIndices, preference: preference}
+}
+
+// readers returns the readers in the order of the preference.
+func (r *migrationReader) readers() (spanstore.Reader, spanstore.Reader) {
+	if r.preference == ReadPreferenceNewFirst {
+		return r.newIndices, r.oldIndices
+	}
+	return r.oldIndices, r.newIndices
+}
+
+// readBoth returns the results of the preferred reader, or of the other one if there are none,
+// or the results of both when merging.
+func readBoth[T any](r *migrationReader, read func(spanstore.Reader) ([]T, error), merge func([]T, []T) []T) ([]T, error) {
+	first, second := r.readers()
+	results, err := read(first)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) > 0 && r.preference != ReadPreferenceMerge {
+		return results, nil
+	}
+	others, err := read(second)
+	if err != nil {
+		return nil, err
+	}
+	return merge(results, others), nil
+}
+
+// GetTrace returns the trace found in the preferred indices, or in the other ones if not found,
+// or the spans of both when merging, without duplicates.
+func (r *migrationReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	traces, err := readBoth(r, func(reader spanstore.Reader) ([]*model.Trace, error) {
+		trace, err := reader.GetTrace(ctx, traceID)
+		if errors.Is(err, spanstore.ErrTraceNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []*model.Trace{trace}, nil
+	}, mergeMigratedTraces)
+	if err != nil {
+		return nil, err
+	}
+	if len(traces) == 0 {
+		return nil, spanstore.ErrTraceNotFound
+	}
+	return traces[0], nil
+}
+
+func (r *migrationReader) GetServices(ctx context.Context) ([]string, error) {
+	return readBoth(r, func(reader spanstore.Reader) ([]string, error) {
+		return reader.GetServices(ctx)
+	}, mergeUnique[string])
+}
+
+func (r *migrationReader) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	return readBoth(r, func(reader spanstore.Reader) ([]spanstore.Operation, error) {
+		return reader.GetOperations(ctx, query)
+	}, mergeUnique[spanstore.Operation])
+}
+
+func (r *migrationReader) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	traces, err := readBoth(r, func(reader spanstore.Reader) ([]*model.Trace, error) {
+		q := *query
+		return reader.FindTraces(ctx, &q)
+	}, mergeMigratedTraces)
+	if query.NumTraces > 0 && len(traces) > query.NumTraces {
+		traces = traces[:query.NumTraces]
+	}
+	return traces, err
+}
+
+func (r *migrationReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	traceIDs, err := readBoth(r, func(reader spanstore.Reader) ([]model.TraceID, error) {
+		q := *query
+		return reader.FindTraceIDs(ctx, &q)
+	}, mergeUnique[model.TraceID])
+	if query.NumTraces > 0 && len(traceIDs) > query.NumTraces {
+		traceIDs = traceIDs[:query.NumTraces]
+	}
+	return traceIDs, err
+}
+
+// GetTraces implements spanstore.BatchReader#GetTraces. The traces not found in the preferred indices
+// are read from the other ones, or all the traces are read from both when merging. It returns
+// errors.ErrUnsupported if the readers are not spanstore.BatchReaders.
+func (r *migrationReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	first, second := r.readers()
+	traces, err := getTraces(ctx, first, traceIDs)
+	if err != nil {
+		return nil, err
+	}
+	missing := traceIDs
+	if r.preference != ReadPreferenceMerge {
+		missing = missingTraceIDs(traceIDs, traces)
+		if len(missing) == 0 {
+			return traces, nil
+		}
+	}
+	others, err := getTraces(ctx, second, missing)
+	if err != nil {
+		return nil, err
+	}
+	return mergeMigratedTraces(traces, others), nil
+}
+
+func getTraces(ctx context.Context, reader spanstore.Reader, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	batchReader, ok := reader.(spanstore.BatchReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return batchReader.GetTraces(ctx, traceIDs)
+}
+
+// missingTraceIDs returns the IDs of the traces which were not found.
+func missingTraceIDs(traceIDs []model.TraceID, traces []*model.Trace) []model.TraceID {
+	found := make(map[model.TraceID]struct{}, len(traces))
+	for _, trace := range traces {
+		if len(trace.Spans) > 0 {
+			found[trace.Spans[0].TraceID] = struct{}{}
+		}
+	}
+	var missing []model.TraceID
+	for _, traceID := range traceIDs {
+		if _, ok := found[traceID]; !ok {
+			missing = append(missing, traceID)
+		}
+	}
+	return missing
+}
+
+// GetTagKeys implements spanstore.TagKeysReader#GetTagKeys, it returns errors.ErrUnsupported
+// if the readers are not spanstore.TagKeysReaders.
+func (r *migrationReader) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	keys, err := readBoth(r, func(reader spanstore.Reader) ([]string, error) {
+		tagKeysReader, ok := reader.(spanstore.TagKeysReader)
+		if !ok {
+			return nil, errors.ErrUnsupported
+		}
+		return tagKeysReader.GetTagKeys(ctx, query)
+	}, mergeUnique[string])
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	if query.Limit > 0 && len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+	}
+	return keys, nil
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix, it returns
+// errors.ErrUnsupported if the readers are not spanstore.TraceIDPrefixReaders.
+func (r *migrationReader) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	traceIDs, err := readBoth(r, func(reader spanstore.Reader) ([]model.TraceID, error) {
+		prefixReader, ok := reader.(spanstore.TraceIDPrefixReader)
+		if !ok {
+			return nil, errors.ErrUnsupported
+		}
+		return prefixReader.FindTraceIDsByPrefix(ctx, prefix, limit)
+	}, mergeUnique[model.TraceID])
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(traceIDs) > limit {
+		traceIDs = traceIDs[:limit]
+	}
+	return traceIDs, nil
+}
+
+// DeleteTrace implements spanstore.TraceDeleter#DeleteTrace. The trace is deleted from both indices,
+// whatever the read preference, since it may have been written or reindexed to either of them.
+// It returns errors.ErrUnsupported if the readers are not spanstore.TraceDeleters.
+func (r *migrationReader) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	var errs []error
+	for _, reader := range []spanstore.Reader{r.oldIndices, r.newIndices} {
+		deleter, ok := reader.(spanstore.TraceDeleter)
+		if !ok {
+			return errors.ErrUnsupported
+		}
+		if err := deleter.DeleteTrace(ctx, traceID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mergeUnique appends the values of others missing from values.
+func mergeUnique[T comparable](values, others []T) []T {
+	seen := make(map[T]struct{}, len(values))
+	for _, value := range values {
+		seen[value] = struct{}{}
+	}
+	for _, value := range others {
+		if _, ok := seen[value]; !ok {
+			seen[value] = struct{}{}
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// mergeMigratedTraces merges the traces read from both indices, the spans written to both being
+// kept once, as read from the first indices.
+func mergeMigratedTraces(traces, others []*model.Trace) []*model.Trace {
+	byID := make(map[model.TraceID]*model.Trace, len(traces))
+	for _, trace := range traces {
+		if len(trace.Spans) > 0 {
+			byID[trace.Spans[0].TraceID] = trace
+		}
+	}
+	for _, trace := range others {
+		if len(trace.Spans) == 0 {
+			continue
+		}
+		existing, ok := byID[trace.Spans[0].TraceID]
+		if !ok {
+			byID[trace.Spans[0].TraceID] = trace
+			traces = append(traces, trace)
+			continue
+		}
+		spanIDs := make(map[model.SpanID]struct{}, len(existing.Spans))
+		for _, span := range existing.Spans {
+			spanIDs[span.SpanID] = struct{}{}
+		}
+		for _, span := range trace.Spans {
+			if _, ok := spanIDs[span.SpanID]; !ok {
+				spanIDs[span.SpanID] = struct{}{}
+				existing.Spans = append(existing.Spans, span)
+			}
+		}
+	}
+	return traces
+}
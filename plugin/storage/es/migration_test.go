@@ -0,0 +1,386 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package es
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	escfg "github.com/jaegertracing/jaeger/pkg/es/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanStoreMocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func TestMigrationRoutesToBothClusters(t *testing.T) {
+	oldCluster, newCluster := newFakeESCluster(t), newFakeESCluster(t)
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--es.server-urls=" + oldCluster.URL,
+		"--es.bulk.size=-1",
+		"--es.migration.dual-write=true",
+		"--es.migration.secondary-index-prefix=new",
+		"--es.migration.secondary-server-urls=" + newCluster.URL,
+		"--es.migration.read-preference=merge",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zaptest.NewLogger(t)))
+	defer f.Close()
+
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteSpan(context.Background(), &model.Span{
+		Process: &model.Process{ServiceName: "foo"},
+	}))
+	assert.Eventually(t, func() bool { return oldCluster.received("/_bulk") }, 5*time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return newCluster.received("/_bulk") }, 5*time.Second, time.Millisecond)
+
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	// the fake clusters do not return the trace, only the requested clusters matter
+	_, _ = reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	assert.True(t, oldCluster.received("/_msearch"))
+	assert.True(t, newCluster.received("/_msearch"))
+}
+
+func TestMigrationWithoutDualWrite(t *testing.T) {
+	oldCluster, newCluster := newFakeESCluster(t), newFakeESCluster(t)
+
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--es.server-urls=" + oldCluster.URL,
+		"--es.bulk.size=-1",
+		"--es.migration.secondary-server-urls=" + newCluster.URL,
+		"--es.migration.read-preference=new-first",
+	}))
+	f.InitFromViper(v, zap.NewNop())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zaptest.NewLogger(t)))
+	defer f.Close()
+
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteSpan(context.Background(), &model.Span{
+		Process: &model.Process{ServiceName: "foo"},
+	}))
+	assert.Eventually(t, func() bool { return oldCluster.received("/_bulk") }, 5*time.Second, time.Millisecond)
+
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	// nothing is found in the new cluster, so the old one is read too
+	_, err = reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+	assert.True(t, newCluster.received("/_msearch"))
+	assert.True(t, oldCluster.received("/_msearch"))
+	assert.False(t, newCluster.received("/_bulk"), "the spans are only written to the new cluster with dual write")
+}
+
+func TestMigrationOptionsValidation(t *testing.T) {
+	primary := &escfg.Configuration{IndexPrefix: "jaeger"}
+	testCases := []struct {
+		name    string
+		options MigrationOptions
+		wantErr string
+	}{
+		{name: "disabled"},
+		{name: "secondary prefix", options: MigrationOptions{DualWrite: true, SecondaryIndexPrefix: "new"}},
+		{name: "secondary servers", options: MigrationOptions{SecondaryIndexPrefix: "jaeger", SecondaryServers: []string{"http://new:9200"}}},
+		{
+			name:    "invalid read preference",
+			options: MigrationOptions{SecondaryIndexPrefix: "new", ReadPreference: "newest"},
+			wantErr: `invalid es.migration.read-preference "newest"`,
+		},
+		{
+			name:    "dual write without secondary",
+			options: MigrationOptions{DualWrite: true},
+			wantErr: "es.migration.dual-write requires",
+		},
+		{
+			name:    "same indices",
+			options: MigrationOptions{SecondaryIndexPrefix: "jaeger", SecondaryAllTagsAsFields: true},
+			wantErr: "must differ from the primary ones",
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.validate(primary)
+			if test.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDualWriter(t *testing.T) {
+	span := &model.Span{TraceID: model.NewTraceID(0, 1), SpanID: 1}
+	primary, secondary := &spanStoreMocks.Writer{}, &spanStoreMocks.Writer{}
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	writer := newDualWriter(primary, secondary, metricsFactory, zap.NewNop())
+
+	primary.On("WriteSpan", mock.Anything, span).Return(nil).Once()
+	secondary.On("WriteSpan", mock.Anything, span).Return(errors.New("mapping error")).Once()
+	require.NoError(t, writer.WriteSpan(context.Background(), span), "the secondary errors do not fail the spans")
+	metricsFactory.AssertCounterMetrics(t, metricstest.ExpectedMetric{Name: "migration_secondary.write_errors", Value: 1})
+
+	primary.On("WriteSpan", mock.Anything, span).Return(errors.New("unavailable")).Once()
+	require.EqualError(t, writer.WriteSpan(context.Background(), span), "unavailable")
+	primary.AssertExpectations(t)
+	secondary.AssertExpectations(t)
+	assert.Zero(t, writer.PendingWrites())
+}
+
+func migratedTrace(spanIDs ...model.SpanID) *model.Trace {
+	trace := &model.Trace{}
+	for _, spanID := range spanIDs {
+		trace.Spans = append(trace.Spans, &model.Span{TraceID: model.NewTraceID(0, 1), SpanID: spanID})
+	}
+	return trace
+}
+
+func TestMigrationReaderGetTrace(t *testing.T) {
+	traceID := model.NewTraceID(0, 1)
+	testCases := []struct {
+		preference  string
+		oldTrace    *model.Trace
+		newTrace    *model.Trace
+		wantSpanIDs []model.SpanID
+	}{
+		{preference: ReadPreferenceOldFirst, oldTrace: migratedTrace(1), newTrace: migratedTrace(2), wantSpanIDs: []model.SpanID{1}},
+		{preference: ReadPreferenceOldFirst, newTrace: migratedTrace(2), wantSpanIDs: []model.SpanID{2}},
+		{preference: ReadPreferenceNewFirst, oldTrace: migratedTrace(1), newTrace: migratedTrace(2), wantSpanIDs: []model.SpanID{2}},
+		{preference: ReadPreferenceNewFirst, oldTrace: migratedTrace(1), wantSpanIDs: []model.SpanID{1}},
+		{preference: ReadPreferenceMerge, oldTrace: migratedTrace(1, 2), newTrace: migratedTrace(2, 3), wantSpanIDs: []model.SpanID{1, 2, 3}},
+		{preference: ReadPreferenceMerge, newTrace: migratedTrace(3), wantSpanIDs: []model.SpanID{3}},
+		{preference: ReadPreferenceMerge},
+	}
+	for _, test := range testCases {
+		t.Run(test.preference, func(t *testing.T) {
+			oldIndices, newIndices := &spanStoreMocks.Reader{}, &spanStoreMocks.Reader{}
+			for reader, trace := range map[*spanStoreMocks.Reader]*model.Trace{oldIndices: test.oldTrace, newIndices: test.newTrace} {
+				if trace == nil {
+					reader.On("GetTrace", mock.Anything, traceID).Return(nil, spanstore.ErrTraceNotFound).Maybe()
+				} else {
+					reader.On("GetTrace", mock.Anything, traceID).Return(trace, nil).Maybe()
+				}
+			}
+			trace, err := newMigrationReader(oldIndices, newIndices, test.preference).GetTrace(context.Background(), traceID)
+			if test.wantSpanIDs == nil {
+				require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+				return
+			}
+			require.NoError(t, err)
+			var spanIDs []model.SpanID
+			for _, span := range trace.Spans {
+				spanIDs = append(spanIDs, span.SpanID)
+			}
+			assert.Equal(t, test.wantSpanIDs, spanIDs)
+		})
+	}
+}
+
+func TestMigrationReaderOldFirstSkipsNewIndices(t *testing.T) {
+	oldIndices, newIndices := &spanStoreMocks.Reader{}, &spanStoreMocks.Reader{}
+	oldIndices.On("GetServices", mock.Anything).Return([]string{"foo"}, nil)
+	services, err := newMigrationReader(oldIndices, newIndices, ReadPreferenceOldFirst).GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, services)
+	newIndices.AssertNotCalled(t, "GetServices", mock.Anything)
+}
+
+func TestMigrationReaderMerge(t *testing.T) {
+	oldIndices, newIndices := &spanStoreMocks.Reader{}, &spanStoreMocks.Reader{}
+	reader := newMigrationReader(oldIndices, newIndices, ReadPreferenceMerge)
+	ctx := context.Background()
+
+	oldIndices.On("GetServices", ctx).Return([]string{"foo", "bar"}, nil)
+	newIndices.On("GetServices", ctx).Return([]string{"bar", "baz"}, nil)
+	services, err := reader.GetServices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, services)
+
+	operationQuery := spanstore.OperationQueryParameters{ServiceName: "foo"}
+	oldIndices.On("GetOperations", ctx, operationQuery).Return([]spanstore.Operation{{Name: "get"}}, nil)
+	newIndices.On("GetOperations", ctx, operationQuery).Return([]spanstore.Operation{{Name: "get"}, {Name: "put"}}, nil)
+	operations, err := reader.GetOperations(ctx, operationQuery)
+	require.NoError(t, err)
+	assert.Equal(t, []spanstore.Operation{{Name: "get"}, {Name: "put"}}, operations)
+
+	query := &spanstore.TraceQueryParameters{ServiceName: "foo", NumTraces: 2}
+	oldIndices.On("FindTraceIDs", ctx, query).Return([]model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}, nil)
+	newIndices.On("FindTraceIDs", ctx, query).Return([]model.TraceID{model.NewTraceID(0, 2), model.NewTraceID(0, 3)}, nil)
+	traceIDs, err := reader.FindTraceIDs(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}, traceIDs, "the limit of the query is kept")
+
+	other := &model.Trace{Spans: []*model.Span{{TraceID: model.NewTraceID(0, 2), SpanID: 1}}}
+	oldIndices.On("FindTraces", ctx, query).Return([]*model.Trace{migratedTrace(1)}, nil)
+	newIndices.On("FindTraces", ctx, query).Return([]*model.Trace{migratedTrace(1, 2), other}, nil)
+	traces, err := reader.FindTraces(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+	assert.Len(t, traces[0].Spans, 2)
+	assert.Equal(t, other, traces[1])
+}
+
+func TestMigrationReaderError(t *testing.T) {
+	oldIndices, newIndices := &spanStoreMocks.Reader{}, &spanStoreMocks.Reader{}
+	oldIndices.On("GetServices", mock.Anything).Return(nil, nil)
+	newIndices.On("GetServices", mock.Anything).Return(nil, errors.New("unavailable"))
+	_, err := newMigrationReader(oldIndices, newIndices, ReadPreferenceOldFirst).GetServices(context.Background())
+	require.EqualError(t, err, "unavailable")
+
+	oldIndices.On("GetTrace", mock.Anything, mock.Anything).Return(nil, errors.New("unavailable"))
+	_, err = newMigrationReader(oldIndices, newIndices, ReadPreferenceMerge).GetTrace(context.Background(), model.NewTraceID(0, 1))
+	require.EqualError(t, err, "unavailable")
+}
+
+// fullReader is a span reader mock implementing the optional interfaces forwarded by the migration reader.
+type fullReader struct {
+	*spanStoreMocks.Reader
+}
+
+func newFullReader() fullReader {
+	return fullReader{&spanStoreMocks.Reader{}}
+}
+
+func (r fullReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	args := r.Called(ctx, traceIDs)
+	traces, _ := args.Get(0).([]*model.Trace)
+	return traces, args.Error(1)
+}
+
+func (r fullReader) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	args := r.Called(ctx, query)
+	keys, _ := args.Get(0).([]string)
+	return keys, args.Error(1)
+}
+
+func (r fullReader) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	args := r.Called(ctx, prefix, limit)
+	traceIDs, _ := args.Get(0).([]model.TraceID)
+	return traceIDs, args.Error(1)
+}
+
+func (r fullReader) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	return r.Called(ctx, traceID).Error(0)
+}
+
+func TestMigrationReaderGetTraces(t *testing.T) {
+	ctx := context.Background()
+	traceIDs := []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2)}
+	other := &model.Trace{Spans: []*model.Span{{TraceID: model.NewTraceID(0, 2), SpanID: 3}}}
+
+	oldIndices, newIndices := newFullReader(), newFullReader()
+	oldIndices.On("GetTraces", ctx, traceIDs).Return([]*model.Trace{migratedTrace(1)}, nil)
+	newIndices.On("GetTraces", ctx, traceIDs[1:]).Return([]*model.Trace{other}, nil)
+	traces, err := newMigrationReader(oldIndices, newIndices, ReadPreferenceOldFirst).GetTraces(ctx, traceIDs)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Trace{migratedTrace(1), other}, traces, "only the missing traces are read from the new indices")
+
+	oldIndices, newIndices = newFullReader(), newFullReader()
+	oldIndices.On("GetTraces", ctx, traceIDs).Return([]*model.Trace{migratedTrace(1), other}, nil)
+	traces, err = newMigrationReader(oldIndices, newIndices, ReadPreferenceOldFirst).GetTraces(ctx, traceIDs)
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+	newIndices.AssertNotCalled(t, "GetTraces", mock.Anything, mock.Anything)
+
+	oldIndices, newIndices = newFullReader(), newFullReader()
+	oldIndices.On("GetTraces", ctx, traceIDs).Return([]*model.Trace{migratedTrace(1)}, nil)
+	newIndices.On("GetTraces", ctx, traceIDs).Return([]*model.Trace{migratedTrace(1, 2)}, nil)
+	traces, err = newMigrationReader(oldIndices, newIndices, ReadPreferenceMerge).GetTraces(ctx, traceIDs)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Len(t, traces[0].Spans, 2)
+
+	newIndices.On("GetTraces", ctx, traceIDs).Unset()
+	newIndices.On("GetTraces", ctx, traceIDs).Return(nil, errors.New("unavailable"))
+	_, err = newMigrationReader(oldIndices, newIndices, ReadPreferenceMerge).GetTraces(ctx, traceIDs)
+	require.EqualError(t, err, "unavailable")
+	_, err = newMigrationReader(newIndices, oldIndices, ReadPreferenceOldFirst).GetTraces(ctx, traceIDs)
+	require.EqualError(t, err, "unavailable")
+}
+
+func TestMigrationReaderGetTagKeys(t *testing.T) {
+	ctx := context.Background()
+	query := spanstore.TagKeysQueryParameters{ServiceName: "foo", Limit: 3}
+	oldIndices, newIndices := newFullReader(), newFullReader()
+	oldIndices.On("GetTagKeys", ctx, query).Return([]string{"http.method", "error"}, nil)
+	newIndices.On("GetTagKeys", ctx, query).Return([]string{"error", "db.statement", "peer.service"}, nil)
+	keys, err := newMigrationReader(oldIndices, newIndices, ReadPreferenceMerge).GetTagKeys(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db.statement", "error", "http.method"}, keys)
+
+	oldIndices.On("GetTagKeys", ctx, query).Unset()
+	oldIndices.On("GetTagKeys", ctx, query).Return(nil, errors.New("unavailable"))
+	_, err = newMigrationReader(oldIndices, newIndices, ReadPreferenceMerge).GetTagKeys(ctx, query)
+	require.EqualError(t, err, "unavailable")
+}
+
+func TestMigrationReaderFindTraceIDsByPrefix(t *testing.T) {
+	ctx := context.Background()
+	oldIndices, newIndices := newFullReader(), newFullReader()
+	oldIndices.On("FindTraceIDsByPrefix", ctx, "ab", 2).Return(nil, nil)
+	newIndices.On("FindTraceIDsByPrefix", ctx, "ab", 2).Return([]model.TraceID{model.NewTraceID(0, 0xab)}, nil)
+	traceIDs, err := newMigrationReader(oldIndices, newIndices, ReadPreferenceOldFirst).FindTraceIDsByPrefix(ctx, "ab", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 0xab)}, traceIDs)
+
+	oldIndices.On("FindTraceIDsByPrefix", ctx, "ab", 1).Return([]model.TraceID{model.NewTraceID(0, 0xab1)}, nil)
+	newIndices.On("FindTraceIDsByPrefix", ctx, "ab", 1).Return([]model.TraceID{model.NewTraceID(0, 0xab2)}, nil)
+	traceIDs, err = newMigrationReader(oldIndices, newIndices, ReadPreferenceMerge).FindTraceIDsByPrefix(ctx, "ab", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{model.NewTraceID(0, 0xab1)}, traceIDs, "the limit is kept")
+
+	newIndices.On("FindTraceIDsByPrefix", ctx, "cd", 1).Return(nil, errors.New("unavailable"))
+	_, err = newMigrationReader(oldIndices, newIndices, ReadPreferenceNewFirst).FindTraceIDsByPrefix(ctx, "cd", 1)
+	require.EqualError(t, err, "unavailable")
+}
+
+func TestMigrationReaderDeleteTrace(t *testing.T) {
+	ctx := context.Background()
+	traceID := model.NewTraceID(0, 1)
+	for _, preference := range []string{ReadPreferenceOldFirst, ReadPreferenceNewFirst, ReadPreferenceMerge} {
+		oldIndices, newIndices := newFullReader(), newFullReader()
+		oldIndices.On("DeleteTrace", ctx, traceID).Return(nil).Once()
+		newIndices.On("DeleteTrace", ctx, traceID).Return(nil).Once()
+		require.NoError(t, newMigrationReader(oldIndices, newIndices, preference).DeleteTrace(ctx, traceID))
+		oldIndices.AssertExpectations(t)
+		newIndices.AssertExpectations(t)
+	}
+
+	oldIndices, newIndices := newFullReader(), newFullReader()
+	oldIndices.On("DeleteTrace", ctx, traceID).Return(errors.New("old unavailable"))
+	newIndices.On("DeleteTrace", ctx, traceID).Return(nil)
+	err := newMigrationReader(oldIndices, newIndices, ReadPreferenceOldFirst).DeleteTrace(ctx, traceID)
+	require.EqualError(t, err, "old unavailable")
+	newIndices.AssertCalled(t, "DeleteTrace", ctx, traceID)
+}
+
+func TestMigrationReaderUnsupported(t *testing.T) {
+	ctx := context.Background()
+	reader := newMigrationReader(&spanStoreMocks.Reader{}, &spanStoreMocks.Reader{}, ReadPreferenceMerge)
+	_, err := reader.GetTraces(ctx, []model.TraceID{model.NewTraceID(0, 1)})
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	_, err = reader.GetTagKeys(ctx, spanstore.TagKeysQueryParameters{ServiceName: "foo"})
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	_, err = reader.FindTraceIDsByPrefix(ctx, "ab", 1)
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	require.ErrorIs(t, reader.DeleteTrace(ctx, model.NewTraceID(0, 1)), errors.ErrUnsupported)
+}
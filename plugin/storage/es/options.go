@@ -25,6 +25,7 @@ import (
 
 	"github.com/jaegertracing/jaeger/pkg/bearertoken"
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/es/client"
 	"github.com/jaegertracing/jaeger/pkg/es/config"
 )
 
@@ -36,6 +37,7 @@ const (
 	suffixTokenPath                      = ".token-file"
 	suffixPasswordPath                   = ".password-file"
 	suffixServerURLs                     = ".server-urls"
+	suffixBearerTokenPropagation         = ".bearer-token-propagation"
 	suffixRemoteReadClusters             = ".remote-read-clusters"
 	suffixMaxSpanAge                     = ".max-span-age"
 	suffixAdaptiveSamplingLookback       = ".adaptive-sampling.lookback"
@@ -68,6 +70,7 @@ const (
 	suffixMaxDocCount                    = ".max-doc-count"
 	suffixLogLevel                       = ".log-level"
 	suffixSendGetBodyAs                  = ".send-get-body-as"
+	suffixZipkinCompatIndexPrefix        = ".zipkin-compat.index-prefix"
 	// default number of documents to return from a query (elasticsearch allowed limit)
 	// see search.max_buckets and index.max_result_window
 	defaultMaxDocCount        = 10_000
@@ -87,6 +90,8 @@ const (
 // (e.g. archive) may be underspecified and infer the rest of its parameters from primary.
 type Options struct {
 	Primary namespaceConfig `mapstructure:",squash"`
+	// Migration configures the migration of the primary storage to indices with a new mapping
+	Migration MigrationOptions `mapstructure:"-"`
 
 	others map[string]*namespaceConfig
 }
@@ -108,8 +113,10 @@ func NewOptions(primaryNamespace string, otherNamespaces ...string) *Options {
 		others: make(map[string]*namespaceConfig, len(otherNamespaces)),
 	}
 
-	// Other namespaces need to be explicitly enabled.
+	// Other namespaces need to be explicitly enabled,
+	// and use the connection of primary unless they have servers of their own.
 	defaultConfig.Enabled = false
+	defaultConfig.Servers = nil
 	for _, namespace := range otherNamespaces {
 		options.others[namespace] = &namespaceConfig{
 			Configuration: defaultConfig,
@@ -132,6 +139,7 @@ func (opt *Options) AddFlags(flagSet *flag.FlagSet) {
 	for _, cfg := range opt.others {
 		addFlags(flagSet, cfg)
 	}
+	addMigrationFlags(flagSet)
 }
 
 func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
@@ -155,10 +163,15 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 		nsConfig.namespace+suffixSniffer,
 		nsConfig.Sniffer,
 		"The sniffer config for Elasticsearch; client uses sniffing process to find all nodes automatically, disable if not required")
+	serverURLsHelp := "The comma-separated list of Elasticsearch servers, must be full url i.e. http://localhost:9200"
+	if len(nsConfig.Servers) == 0 {
+		serverURLsHelp += ". If not specified, the connection settings of the primary storage are used " +
+			"(servers, authentication, TLS, version, sniffer and bearer token propagation)"
+	}
 	flagSet.String(
 		nsConfig.namespace+suffixServerURLs,
-		defaultServerURL,
-		"The comma-separated list of Elasticsearch servers, must be full url i.e. http://localhost:9200")
+		strings.Join(nsConfig.Servers, ","),
+		serverURLsHelp)
 	flagSet.String(
 		nsConfig.namespace+suffixRemoteReadClusters,
 		defaultRemoteReadClusters,
@@ -264,10 +277,11 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 		nsConfig.namespace+suffixCreateIndexTemplate,
 		nsConfig.CreateIndexTemplates,
 		"Create index templates at application startup. Set to false when templates are installed manually.")
-	flagSet.Uint(
+	flagSet.String(
 		nsConfig.namespace+suffixVersion,
-		0,
-		"The major Elasticsearch version. If not specified, the value will be auto-detected from Elasticsearch.")
+		"",
+		"The major Elasticsearch version, or opensearch1, opensearch2 or opensearch3 for OpenSearch. "+
+			"If not specified, the value will be auto-detected from the cluster.")
 	flagSet.Bool(
 		nsConfig.namespace+suffixSnifferTLSEnabled,
 		nsConfig.SnifferTLSEnabled,
@@ -293,6 +307,10 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 			nsConfig.namespace+suffixEnabled,
 			nsConfig.Enabled,
 			"Enable extra storage")
+		flagSet.Bool(
+			nsConfig.namespace+suffixBearerTokenPropagation,
+			false,
+			"Allow propagation of bearer token to the servers of the extra storage, when they are specified")
 	} else {
 		// MaxSpanAge is only relevant when searching for unarchived traces.
 		// Archived traces are searched with no look-back limit.
@@ -300,6 +318,11 @@ func addFlags(flagSet *flag.FlagSet, nsConfig *namespaceConfig) {
 			nsConfig.namespace+suffixMaxSpanAge,
 			nsConfig.MaxSpanAge,
 			"The maximum lookback for spans in Elasticsearch")
+		flagSet.String(
+			nsConfig.namespace+suffixZipkinCompatIndexPrefix,
+			nsConfig.ZipkinCompatIndexPrefix,
+			"(experimental) Prefix of Zipkin indices to read in addition to Jaeger indices, for example \"zipkin\" reads \"zipkin:span-*\". "+
+				"Zipkin indices are never written to, nor deleted from by the trace deletions. Empty value disables Zipkin compatibility reads.")
 	}
 	nsConfig.getTLSFlagsConfig().AddFlags(flagSet)
 }
@@ -310,6 +333,7 @@ func (opt *Options) InitFromViper(v *viper.Viper) {
 	for _, cfg := range opt.others {
 		initFromViper(cfg, v)
 	}
+	opt.Migration.initFromViper(v)
 }
 
 func initFromViper(cfg *namespaceConfig, v *viper.Viper) {
@@ -319,7 +343,10 @@ func initFromViper(cfg *namespaceConfig, v *viper.Viper) {
 	cfg.PasswordFilePath = v.GetString(cfg.namespace + suffixPasswordPath)
 	cfg.Sniffer = v.GetBool(cfg.namespace + suffixSniffer)
 	cfg.SnifferTLSEnabled = v.GetBool(cfg.namespace + suffixSnifferTLSEnabled)
-	cfg.Servers = strings.Split(stripWhiteSpace(v.GetString(cfg.namespace+suffixServerURLs)), ",")
+	cfg.Servers = nil
+	if servers := stripWhiteSpace(v.GetString(cfg.namespace + suffixServerURLs)); servers != "" {
+		cfg.Servers = strings.Split(servers, ",")
+	}
 	cfg.MaxSpanAge = v.GetDuration(cfg.namespace + suffixMaxSpanAge)
 	cfg.AdaptiveSamplingLookback = v.GetDuration(cfg.namespace + suffixAdaptiveSamplingLookback)
 	cfg.NumShards = v.GetInt64(cfg.namespace + suffixNumShards)
@@ -341,15 +368,27 @@ func initFromViper(cfg *namespaceConfig, v *viper.Viper) {
 	cfg.UseReadWriteAliases = v.GetBool(cfg.namespace + suffixReadAlias)
 	cfg.Enabled = v.GetBool(cfg.namespace + suffixEnabled)
 	cfg.CreateIndexTemplates = v.GetBool(cfg.namespace + suffixCreateIndexTemplate)
-	cfg.Version = uint(v.GetInt(cfg.namespace + suffixVersion))
+	if version := v.GetString(cfg.namespace + suffixVersion); version != "" && version != "0" {
+		clusterVersion, err := client.ParseClusterVersion(version)
+		if err != nil {
+			// TODO refactor to be able to return error
+			log.Fatal(err)
+		}
+		cfg.Version = clusterVersion.Major
+		cfg.Distribution = clusterVersion.Distribution
+	}
 	cfg.LogLevel = v.GetString(cfg.namespace + suffixLogLevel)
 	cfg.SendGetBodyAs = v.GetString(cfg.namespace + suffixSendGetBodyAs)
+	cfg.ZipkinCompatIndexPrefix = v.GetString(cfg.namespace + suffixZipkinCompatIndexPrefix)
 
 	cfg.MaxDocCount = v.GetInt(cfg.namespace + suffixMaxDocCount)
 	cfg.UseILM = v.GetBool(cfg.namespace + suffixUseILM)
 
 	// TODO: Need to figure out a better way for do this.
 	cfg.AllowTokenFromContext = v.GetBool(bearertoken.StoragePropagationKey)
+	if cfg.namespace == archiveNamespace {
+		cfg.AllowTokenFromContext = v.GetBool(cfg.namespace + suffixBearerTokenPropagation)
+	}
 
 	remoteReadClusters := stripWhiteSpace(v.GetString(cfg.namespace + suffixRemoteReadClusters))
 	if len(remoteReadClusters) > 0 {
@@ -387,10 +426,10 @@ func (opt *Options) Get(namespace string) *config.Configuration {
 		nsCfg = &namespaceConfig{}
 		opt.others[namespace] = nsCfg
 	}
-	nsCfg.Configuration.ApplyDefaults(&opt.Primary.Configuration)
 	if len(nsCfg.Configuration.Servers) == 0 {
-		nsCfg.Servers = opt.Primary.Servers
+		nsCfg.Configuration.ApplyConnection(&opt.Primary.Configuration)
 	}
+	nsCfg.Configuration.ApplyDefaults(&opt.Primary.Configuration)
 	return &nsCfg.Configuration
 }
 
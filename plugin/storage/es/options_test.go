@@ -78,6 +78,7 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--es.tags-as-fields.dot-replacement=!",
 		"--es.use-ilm=true",
 		"--es.send-get-body-as=POST",
+		"--es.zipkin-compat.index-prefix=zipkin",
 	})
 	require.NoError(t, err)
 	opts.InitFromViper(v)
@@ -90,6 +91,7 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, primary.Servers)
 	assert.Equal(t, []string{"cluster_one", "cluster_two"}, primary.RemoteReadClusters)
 	assert.Equal(t, 48*time.Hour, primary.MaxSpanAge)
+	assert.Equal(t, "zipkin", primary.ZipkinCompatIndexPrefix)
 	assert.True(t, primary.Sniffer)
 	assert.True(t, primary.SnifferTLSEnabled)
 	assert.True(t, primary.TLS.Enabled)
@@ -102,12 +104,16 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, "2006010215", primary.IndexDateLayoutSpans)
 	aux := opts.Get("es.aux")
 	assert.Equal(t, []string{"3.3.3.3", "4.4.4.4"}, aux.Servers)
-	assert.Equal(t, "hello", aux.Username)
-	assert.Equal(t, "world", aux.Password)
+	// the connection settings are not inherited with servers of its own
+	assert.Empty(t, aux.Username)
+	assert.Empty(t, aux.Password)
+	assert.Empty(t, aux.TokenFilePath)
+	assert.False(t, aux.TLS.Enabled)
 	assert.Equal(t, int64(5), aux.NumShards)
 	assert.Equal(t, int64(10), aux.NumReplicas)
 	assert.Equal(t, 24*time.Hour, aux.MaxSpanAge)
-	assert.True(t, aux.Sniffer)
+	assert.False(t, aux.Sniffer)
+	assert.False(t, aux.SnifferTLSEnabled)
 	assert.True(t, aux.Tags.AllAsFields)
 	assert.Equal(t, "@", aux.Tags.DotReplacement)
 	assert.Equal(t, "./file.txt", aux.Tags.File)
@@ -118,6 +124,66 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, "POST", aux.SendGetBodyAs)
 }
 
+func TestOptionsArchiveConnection(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     []string
+		servers   []string
+		username  string
+		sniffer   bool
+		tls       bool
+		version   uint
+		propagate bool
+	}{
+		{
+			name:     "primary connection",
+			servers:  []string{"http://hot:9200"},
+			username: "hot-user",
+			sniffer:  true,
+			tls:      true,
+			version:  7,
+		},
+		{
+			name: "archive connection",
+			flags: []string{
+				"--es-archive.server-urls=http://cold:9200",
+				"--es-archive.username=cold-user",
+				"--es-archive.tls.enabled=false",
+				"--es-archive.version=8",
+				"--es-archive.bearer-token-propagation=true",
+			},
+			servers:   []string{"http://cold:9200"},
+			username:  "cold-user",
+			version:   8,
+			propagate: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := NewOptions(primaryNamespace, archiveNamespace)
+			v, command := config.Viperize(opts.AddFlags)
+			require.NoError(t, command.ParseFlags(append([]string{
+				"--es.server-urls=http://hot:9200",
+				"--es.username=hot-user",
+				"--es.sniffer=true",
+				"--es.tls.enabled=true",
+				"--es.version=7",
+				"--es-archive.enabled=true",
+			}, test.flags...)))
+			opts.InitFromViper(v)
+
+			archive := opts.Get(archiveNamespace)
+			assert.True(t, archive.Enabled)
+			assert.Equal(t, test.servers, archive.Servers)
+			assert.Equal(t, test.username, archive.Username)
+			assert.Equal(t, test.sniffer, archive.Sniffer)
+			assert.Equal(t, test.tls, archive.TLS.Enabled)
+			assert.Equal(t, test.version, archive.Version)
+			assert.Equal(t, test.propagate, archive.AllowTokenFromContext)
+		})
+	}
+}
+
 func TestEmptyRemoteReadClusters(t *testing.T) {
 	opts := NewOptions("es", "es.aux")
 	v, command := config.Viperize(opts.AddFlags)
@@ -139,6 +205,34 @@ func TestMaxSpanAgeSetErrorInArchiveMode(t *testing.T) {
 	require.EqualError(t, err, "unknown flag: --es-archive.max-span-age")
 }
 
+func TestVersionOverride(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		flags                []string
+		wantVersion          uint
+		wantDistribution     string
+		wantComposableFormat bool
+	}{
+		{"auto-detect", []string{}, 0, "", false},
+		{"elasticsearch", []string{"--es.version=7"}, 7, "elasticsearch", false},
+		{"opensearch2", []string{"--es.version=opensearch2"}, 2, "opensearch", true},
+		{"opensearch3", []string{"--es.version=opensearch3"}, 3, "opensearch", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := NewOptions("es")
+			v, command := config.Viperize(opts.AddFlags)
+			command.ParseFlags(tc.flags)
+			opts.InitFromViper(v)
+
+			primary := opts.GetPrimary()
+			assert.Equal(t, tc.wantVersion, primary.Version)
+			assert.Equal(t, tc.wantDistribution, primary.Distribution)
+			assert.Equal(t, tc.wantComposableFormat, primary.ClusterVersion().UseComposableTemplates())
+		})
+	}
+}
+
 func TestMaxDocCount(t *testing.T) {
 	testCases := []struct {
 		name            string
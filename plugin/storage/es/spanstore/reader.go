@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/olivere/elastic"
@@ -108,6 +109,7 @@ type SpanReader struct {
 	sourceFn                      sourceFn
 	maxDocCount                   int
 	useReadWriteAliases           bool
+	zipkinSpanIndexPrefix         string
 	logger                        *zap.Logger
 	tracer                        trace.Tracer
 }
@@ -126,6 +128,7 @@ type SpanReaderParams struct {
 	Archive                       bool
 	UseReadWriteAliases           bool
 	RemoteReadClusters            []string
+	ZipkinCompatIndexPrefix       string
 	MetricsFactory                metrics.Factory
 	Logger                        *zap.Logger
 	Tracer                        trace.Tracer
@@ -139,6 +142,10 @@ func NewSpanReader(p SpanReaderParams) *SpanReader {
 	if p.UseReadWriteAliases {
 		maxSpanAge = rolloverMaxSpanAge
 	}
+	var zipkinSpanIndexPrefix string
+	if p.ZipkinCompatIndexPrefix != "" {
+		zipkinSpanIndexPrefix = p.ZipkinCompatIndexPrefix + zipkinSpanIndex
+	}
 	return &SpanReader{
 		client:                        p.Client,
 		maxSpanAge:                    maxSpanAge,
@@ -154,6 +161,7 @@ func NewSpanReader(p SpanReaderParams) *SpanReader {
 		sourceFn:                      getSourceFn(p.Archive, p.MaxDocCount),
 		maxDocCount:                   p.MaxDocCount,
 		useReadWriteAliases:           p.UseReadWriteAliases,
+		zipkinSpanIndexPrefix:         zipkinSpanIndexPrefix,
 		logger:                        p.Logger,
 		tracer:                        p.Tracer,
 	}
@@ -248,12 +256,79 @@ func (s *SpanReader) GetTrace(ctx context.Context, traceID model.TraceID) (*mode
 	if err != nil {
 		return nil, es.DetailedError(err)
 	}
+	if s.zipkinSpanIndexPrefix != "" {
+		zipkinTraces, err := s.readZipkinTraces(ctx, []model.TraceID{traceID}, currentTime.Add(-s.maxSpanAge), currentTime)
+		if err != nil {
+			return nil, err
+		}
+		traces = mergeTraces(traces, zipkinTraces)
+	}
 	if len(traces) == 0 {
 		return nil, spanstore.ErrTraceNotFound
 	}
 	return traces[0], nil
 }
 
+// GetTraces takes traceIDs and returns the Traces found for them, using a single multi search
+func (s *SpanReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	ctx, span := s.tracer.Start(ctx, "GetTraces")
+	defer span.End()
+	currentTime := time.Now()
+	traces, err := s.multiRead(ctx, traceIDs, currentTime.Add(-s.maxSpanAge), currentTime)
+	if err != nil {
+		return nil, es.DetailedError(err)
+	}
+	if s.zipkinSpanIndexPrefix != "" {
+		zipkinTraces, err := s.readZipkinTraces(ctx, traceIDs, currentTime.Add(-s.maxSpanAge), currentTime)
+		if err != nil {
+			return nil, err
+		}
+		traces = mergeTraces(traces, zipkinTraces)
+	}
+	return traces, nil
+}
+
+// DeleteTrace implements spanstore.TraceDeleter#DeleteTrace. The spans are deleted from the Jaeger indices
+// GetTrace reads, except the ones of the remote clusters. The Zipkin compatibility indices are read-only
+// and are not deleted from, so the spans of the trace stored by Zipkin are still returned by GetTrace.
+func (s *SpanReader) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	ctx, span := s.tracer.Start(ctx, "DeleteTrace")
+	defer span.End()
+	currentTime := time.Now()
+	startTime, endTime := currentTime.Add(-s.maxSpanAge).Add(-time.Hour), currentTime.Add(time.Hour)
+
+	indices := localIndices(s.timeRangeIndices(s.spanIndexPrefix, s.spanIndexDateLayout, startTime, endTime, s.spanIndexRolloverFrequency))
+	if err := s.deleteByQuery(ctx, indices, buildTraceByIDQuery(traceID)); err != nil {
+		logErrorToSpan(span, err)
+		return err
+	}
+	return nil
+}
+
+func (s *SpanReader) deleteByQuery(ctx context.Context, indices []string, query elastic.Query) error {
+	_, err := s.client().DeleteByQuery(indices...).
+		Query(query).
+		IgnoreUnavailable(true).
+		ProceedOnVersionConflict().
+		Refresh("true"). // the spans must not be found by the next reads
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("delete spans failed: %w", es.DetailedError(err))
+	}
+	return nil
+}
+
+// localIndices filters out the indices of the remote clusters, which are read-only.
+func localIndices(indices []string) []string {
+	local := make([]string, 0, len(indices))
+	for _, index := range indices {
+		if !strings.Contains(index, ":") {
+			local = append(local, index)
+		}
+	}
+	return local
+}
+
 func (s *SpanReader) collectSpans(esSpansRaw []*elastic.SearchHit) ([]*model.Span, error) {
 	spans := make([]*model.Span, len(esSpansRaw))
 
@@ -290,7 +365,25 @@ func (s *SpanReader) GetServices(ctx context.Context) ([]string, error) {
 	defer span.End()
 	currentTime := time.Now()
 	jaegerIndices := s.timeRangeIndices(s.serviceIndexPrefix, s.serviceIndexDateLayout, currentTime.Add(-s.maxSpanAge), currentTime, s.serviceIndexRolloverFrequency)
-	return s.serviceOperationStorage.getServices(ctx, jaegerIndices, s.maxDocCount)
+	services, err := s.serviceOperationStorage.getServices(ctx, jaegerIndices, s.maxDocCount)
+	if err != nil || s.zipkinSpanIndexPrefix == "" {
+		return services, err
+	}
+	zipkinServices, err := s.getZipkinServices(ctx, currentTime.Add(-s.maxSpanAge), currentTime)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		seen[service] = struct{}{}
+	}
+	for _, service := range zipkinServices {
+		if _, ok := seen[service]; !ok {
+			seen[service] = struct{}{}
+			services = append(services, service)
+		}
+	}
+	return services, nil
 }
 
 // GetOperations returns all operations for a specific service traced by Jaeger
@@ -339,7 +432,31 @@ func (s *SpanReader) FindTraces(ctx context.Context, traceQuery *spanstore.Trace
 	if err != nil {
 		return nil, es.DetailedError(err)
 	}
-	return s.multiRead(ctx, uniqueTraceIDs, traceQuery.StartTimeMin, traceQuery.StartTimeMax)
+	traces, err := s.multiRead(ctx, uniqueTraceIDs, traceQuery.StartTimeMin, traceQuery.StartTimeMax)
+	if err != nil || s.zipkinSpanIndexPrefix == "" {
+		return traces, err
+	}
+	zipkinTraceIDs, err := s.findZipkinTraceIDs(ctx, traceQuery)
+	if err != nil {
+		return nil, err
+	}
+	// Zipkin spans may belong to traces found in native indices as well as to Zipkin-only traces.
+	traceIDs := uniqueTraceIDs
+	seen := make(map[model.TraceID]struct{}, len(uniqueTraceIDs))
+	for _, traceID := range uniqueTraceIDs {
+		seen[traceID] = struct{}{}
+	}
+	for _, traceID := range zipkinTraceIDs {
+		if _, ok := seen[traceID]; !ok && len(traceIDs) < traceQuery.NumTraces {
+			seen[traceID] = struct{}{}
+			traceIDs = append(traceIDs, traceID)
+		}
+	}
+	zipkinTraces, err := s.readZipkinTraces(ctx, traceIDs, traceQuery.StartTimeMin, traceQuery.StartTimeMax)
+	if err != nil {
+		return nil, err
+	}
+	return mergeTraces(traces, zipkinTraces), nil
 }
 
 // FindTraceIDs retrieves traces IDs that match the traceQuery
@@ -422,6 +539,7 @@ func (s *SpanReader) multiRead(ctx context.Context, traceIDs []model.TraceID, st
 		}
 
 		for _, result := range results.Responses {
+			reportShardFailures(ctx, result)
 			if result.Hits == nil || len(result.Hits.Hits) == 0 {
 				continue
 			}
@@ -445,6 +563,11 @@ func (s *SpanReader) multiRead(ctx context.Context, traceIDs []model.TraceID, st
 				searchAfterTime[lastSpan.TraceID] = model.TimeAsEpochMicroseconds(lastSpan.StartTime)
 			}
 		}
+		// the traces with more spans to fetch are not loaded yet
+		loaded := len(tracesMap) - len(traceIDs)
+		spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+			p.TracesLoaded = loaded
+		})
 	}
 
 	var traces []*model.Trace
@@ -586,6 +709,7 @@ func (s *SpanReader) findTraceIDs(ctx context.Context, traceQuery *spanstore.Tra
 		s.logger.Info("es search services failed", zap.Any("traceQuery", traceQuery), zap.Error(err))
 		return nil, fmt.Errorf("search services failed: %w", err)
 	}
+	reportShardFailures(ctx, searchResult)
 	if searchResult.Aggregations == nil {
 		return []string{}, nil
 	}
@@ -595,9 +719,29 @@ func (s *SpanReader) findTraceIDs(ctx context.Context, traceQuery *spanstore.Tra
 	}
 
 	traceIDBuckets := bucket.Buckets
+	spanstore.UpdateProgress(ctx, func(p *spanstore.SearchProgress) {
+		p.BucketsScanned += len(jaegerIndices)
+		p.TracesFound = len(traceIDBuckets)
+	})
 	return bucketToStringArray(traceIDBuckets)
 }
 
+// reportShardFailures reports a warning when some of the shards searched failed, in which case
+// Elasticsearch returns the results of the other shards without error, so that the callers know
+// the results may be incomplete.
+func reportShardFailures(ctx context.Context, result *elastic.SearchResult) {
+	if result == nil || result.Shards == nil || result.Shards.Failed == 0 {
+		return
+	}
+	message := fmt.Sprintf("%d of %d Elasticsearch shards failed, the results may be incomplete",
+		result.Shards.Failed, result.Shards.Total)
+	if len(result.Shards.Failures) > 0 {
+		failure := result.Shards.Failures[0]
+		message += fmt.Sprintf(": index %s: %v", failure.Index, failure.Reason["reason"])
+	}
+	spanstore.AddWarning(ctx, message)
+}
+
 func (s *SpanReader) buildTraceIDAggregation(numOfTraces int) elastic.Aggregation {
 	return elastic.NewTermsAggregation().
 		Size(numOfTraces).
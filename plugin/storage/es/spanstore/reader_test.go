@@ -340,6 +340,94 @@ func TestSpanReader_GetTrace(t *testing.T) {
 	})
 }
 
+func TestSpanReader_GetTraces(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		hitsFor := func(traceID dbmodel.TraceID) *elastic.SearchHits {
+			spanBytes, err := json.Marshal(dbmodel.Span{SpanID: "0", TraceID: traceID})
+			require.NoError(t, err)
+			return &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: (*json.RawMessage)(&spanBytes)}}}
+		}
+		mockMultiSearchService(r).
+			Return(&elastic.MultiSearchResult{
+				Responses: []*elastic.SearchResult{
+					{Hits: hitsFor("1")},
+					{Hits: nil},
+					{Hits: hitsFor("3")},
+				},
+			}, nil).Once()
+
+		traces, err := r.reader.GetTraces(context.Background(),
+			[]model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 2), model.NewTraceID(0, 3)})
+		require.NotEmpty(t, r.traceBuffer.GetSpans(), "Spans recorded")
+		require.NoError(t, err)
+		require.Len(t, traces, 2)
+		var traceIDs []model.TraceID
+		for _, trace := range traces {
+			traceIDs = append(traceIDs, trace.Spans[0].TraceID)
+		}
+		assert.ElementsMatch(t, []model.TraceID{model.NewTraceID(0, 1), model.NewTraceID(0, 3)}, traceIDs)
+		r.client.AssertNumberOfCalls(t, "MultiSearch", 1)
+	})
+}
+
+func TestSpanReader_GetTracesQueryError(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		mockMultiSearchService(r).Return(nil, errors.New("query error occurred"))
+		traces, err := r.reader.GetTraces(context.Background(), []model.TraceID{model.NewTraceID(0, 1)})
+		require.ErrorContains(t, err, "query error occurred")
+		require.Nil(t, traces)
+	})
+}
+
+// mockDeleteByQueryService mocks the deletions from one or two indices, as the deleted time range
+// may be covered by one or two daily indices.
+func mockDeleteByQueryService(r *spanReaderTest, err error) *mocks.DeleteByQueryService {
+	deleteByQueryService := &mocks.DeleteByQueryService{}
+	deleteByQueryService.On("Query", mock.Anything).Return(deleteByQueryService)
+	deleteByQueryService.On("IgnoreUnavailable", true).Return(deleteByQueryService)
+	deleteByQueryService.On("ProceedOnVersionConflict").Return(deleteByQueryService)
+	deleteByQueryService.On("Refresh", "true").Return(deleteByQueryService)
+	deleteByQueryService.On("Do", mock.Anything).Return(&elastic.BulkIndexByScrollResponse{}, err)
+	r.client.On("DeleteByQuery", mock.AnythingOfType("string")).Return(deleteByQueryService)
+	r.client.On("DeleteByQuery", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(deleteByQueryService)
+	return deleteByQueryService
+}
+
+func TestSpanReader_DeleteTrace(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		deleteByQueryService := mockDeleteByQueryService(r, nil)
+		traceID := model.NewTraceID(0, 1)
+		require.NoError(t, r.reader.DeleteTrace(context.Background(), traceID))
+		require.NotEmpty(t, r.traceBuffer.GetSpans(), "Spans recorded")
+		deleteByQueryService.AssertCalled(t, "Query", buildTraceByIDQuery(traceID))
+		deleteByQueryService.AssertNumberOfCalls(t, "Do", 1)
+	})
+}
+
+func TestSpanReader_DeleteTraceZipkinCompat(t *testing.T) {
+	withZipkinCompatSpanReader(t, func(r *spanReaderTest) {
+		deleteByQueryService := mockDeleteByQueryService(r, nil)
+		traceID := model.NewTraceID(0, 1)
+		require.NoError(t, r.reader.DeleteTrace(context.Background(), traceID))
+		// the Zipkin compatibility indices are read-only
+		deleteByQueryService.AssertNotCalled(t, "Query", elastic.NewTermQuery(zipkinTraceIDField, traceID.String()))
+		deleteByQueryService.AssertNumberOfCalls(t, "Do", 1)
+	})
+}
+
+func TestSpanReader_DeleteTraceError(t *testing.T) {
+	withSpanReader(t, func(r *spanReaderTest) {
+		mockDeleteByQueryService(r, errors.New("delete error occurred"))
+		err := r.reader.DeleteTrace(context.Background(), model.NewTraceID(0, 1))
+		require.EqualError(t, err, "delete spans failed: delete error occurred")
+	})
+}
+
+func TestLocalIndices(t *testing.T) {
+	assert.Equal(t, []string{"jaeger-span-2024-01-01"},
+		localIndices([]string{"jaeger-span-2024-01-01", "remote:jaeger-span-2024-01-01"}))
+}
+
 func TestSpanReader_multiRead_followUp_query(t *testing.T) {
 	withSpanReader(t, func(r *spanReaderTest) {
 		date := time.Date(2019, 10, 10, 5, 0, 0, 0, time.UTC)
@@ -761,11 +849,22 @@ func TestSpanReader_FindTraces(t *testing.T) {
 			NumTraces:    1,
 		}
 
-		traces, err := r.reader.FindTraces(context.Background(), traceQuery)
+		var progress []spanstore.SearchProgress
+		ctx := spanstore.ContextWithProgress(context.Background(), func(p spanstore.SearchProgress) {
+			progress = append(progress, p)
+		})
+		traces, err := r.reader.FindTraces(ctx, traceQuery)
 		require.NotEmpty(t, r.traceBuffer.GetSpans(), "Spans recorded")
 		require.NoError(t, err)
 		assert.Len(t, traces, 1)
 
+		// the indices depend on whether the time range spans midnight
+		require.Len(t, progress, 2)
+		assert.Positive(t, progress[0].BucketsScanned)
+		assert.Equal(t, 3, progress[0].TracesFound)
+		assert.Zero(t, progress[0].TracesLoaded)
+		assert.Equal(t, 1, progress[1].TracesLoaded)
+
 		trace := traces[0]
 		expectedSpans, err := r.reader.collectSpans(hits)
 		require.NoError(t, err)
@@ -775,6 +874,47 @@ func TestSpanReader_FindTraces(t *testing.T) {
 	})
 }
 
+func TestSpanReader_FindTracesShardFailures(t *testing.T) {
+	goodAggregations := make(map[string]*json.RawMessage)
+	rawMessage := []byte(`{"buckets": [{"key": "1","doc_count": 16}]}`)
+	goodAggregations[traceIDAggregation] = (*json.RawMessage)(&rawMessage)
+	searchHits := &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: (*json.RawMessage)(&exampleESSpan)}}}
+	shards := &elastic.ShardsInfo{
+		Total:  5,
+		Failed: 2,
+		Failures: []*elastic.ShardFailure{{
+			Index:  "jaeger-span-2024-01-01",
+			Reason: map[string]any{"type": "node_not_connected_exception", "reason": "[node-2] not connected"},
+		}},
+	}
+
+	withSpanReader(t, func(r *spanReaderTest) {
+		mockSearchService(r).
+			Return(&elastic.SearchResult{Aggregations: elastic.Aggregations(goodAggregations), Shards: shards}, nil)
+		mockMultiSearchService(r).
+			Return(&elastic.MultiSearchResult{
+				Responses: []*elastic.SearchResult{
+					{Hits: searchHits, Shards: &elastic.ShardsInfo{Total: 5, Failed: 1}},
+				},
+			}, nil)
+
+		ctx := spanstore.ContextWithWarnings(context.Background())
+		traces, err := r.reader.FindTraces(ctx, &spanstore.TraceQueryParameters{
+			ServiceName:  serviceName,
+			StartTimeMin: time.Now().Add(-1 * time.Hour),
+			StartTimeMax: time.Now(),
+			NumTraces:    1,
+		})
+		// the partial results are returned with warnings
+		require.NoError(t, err)
+		assert.Len(t, traces, 1)
+		assert.Equal(t, []string{
+			"2 of 5 Elasticsearch shards failed, the results may be incomplete: index jaeger-span-2024-01-01: [node-2] not connected",
+			"1 of 5 Elasticsearch shards failed, the results may be incomplete",
+		}, spanstore.GetWarnings(ctx))
+	})
+}
+
 func TestSpanReader_FindTracesInvalidQuery(t *testing.T) {
 	goodAggregations := make(map[string]*json.RawMessage)
 	rawMessage := []byte(`{"buckets": [{"key": "1","doc_count": 16},{"key": "2","doc_count": 16},{"key": "3","doc_count": 16}]}`)
@@ -146,6 +146,11 @@ func (s *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
 	return nil
 }
 
+// PendingWrites returns the number of index requests not yet committed to ElasticSearch
+func (s *SpanWriter) PendingWrites() int64 {
+	return s.client().BulkState().Pending
+}
+
 // Close closes SpanWriter
 func (s *SpanWriter) Close() error {
 	return s.client().Close()
@@ -137,6 +137,14 @@ func TestClientClose(t *testing.T) {
 	})
 }
 
+func TestSpanWriterPendingWrites(t *testing.T) {
+	withSpanWriter(func(w *spanWriterTest) {
+		w.client.On("BulkState").Return(es.BulkState{Pending: 42, Rejected: 3})
+		var writer spanstore.WriterWithBackpressure = w.writer
+		assert.Equal(t, int64(42), writer.PendingWrites())
+	})
+}
+
 // This test behaves as a large test that checks WriteSpan's behavior as a whole.
 // Extra tests for individual functions are below.
 func TestSpanWriter_WriteSpan(t *testing.T) {
@@ -0,0 +1,369 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/olivere/elastic"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/model/converter/thrift/zipkin"
+	"github.com/jaegertracing/jaeger/pkg/es"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
+)
+
+// Zipkin stores spans in daily indices named <prefix>:span-yyyy-MM-dd.
+// https://github.com/openzipkin/zipkin/tree/master/zipkin-storage/elasticsearch
+const (
+	zipkinSpanIndex       = ":span-"
+	zipkinIndexDateLayout = "2006-01-02"
+
+	zipkinTraceIDField         = "traceId"
+	zipkinServiceNameField     = "localEndpoint.serviceName"
+	zipkinNameField            = "name"
+	zipkinDurationField        = "duration"
+	zipkinTimestampMillisField = "timestamp_millis"
+	zipkinQueryField           = "_q"
+
+	zipkinKindClient   = "CLIENT"
+	zipkinKindServer   = "SERVER"
+	zipkinKindProducer = "PRODUCER"
+	zipkinKindConsumer = "CONSUMER"
+
+	zipkinServicesAggregation = "zipkin_services"
+)
+
+// zipkinSpan is the Zipkin v2 span document as written by Zipkin into Elasticsearch.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ParentID       string             `json:"parentId,omitempty"`
+	ID             string             `json:"id"`
+	Kind           string             `json:"kind,omitempty"`
+	Name           string             `json:"name,omitempty"`
+	Timestamp      int64              `json:"timestamp,omitempty"`
+	Duration       int64              `json:"duration,omitempty"`
+	Debug          bool               `json:"debug,omitempty"`
+	Shared         bool               `json:"shared,omitempty"`
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint,omitempty"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint,omitempty"`
+	Annotations    []zipkinAnnotation `json:"annotations,omitempty"`
+	Tags           map[string]string  `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+	IPv4        string `json:"ipv4,omitempty"`
+	IPv6        string `json:"ipv6,omitempty"`
+	Port        int32  `json:"port,omitempty"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+// toThrift converts a Zipkin v2 span into the v1 (thrift) representation,
+// so that it can be translated to the domain model by the existing zipkin converter.
+func (zs *zipkinSpan) toThrift() (*zipkincore.Span, error) {
+	traceID, err := model.TraceIDFromString(zs.TraceID)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := model.SpanIDFromString(zs.ID)
+	if err != nil {
+		return nil, err
+	}
+	span := &zipkincore.Span{
+		TraceID: int64(traceID.Low),
+		ID:      int64(spanID),
+		Name:    zs.Name,
+		Debug:   zs.Debug,
+	}
+	if traceID.High != 0 {
+		high := int64(traceID.High)
+		span.TraceIDHigh = &high
+	}
+	if zs.ParentID != "" {
+		parentID, err := model.SpanIDFromString(zs.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		p := int64(parentID)
+		span.ParentID = &p
+	}
+	if zs.Timestamp != 0 {
+		ts := zs.Timestamp
+		span.Timestamp = &ts
+	}
+	if zs.Duration != 0 {
+		d := zs.Duration
+		span.Duration = &d
+	}
+
+	local := zs.LocalEndpoint.toThrift()
+	remote := zs.RemoteEndpoint.toThrift()
+	end := zs.Timestamp + zs.Duration
+	switch zs.Kind {
+	case zipkinKindClient:
+		span.Annotations = append(span.Annotations,
+			&zipkincore.Annotation{Timestamp: zs.Timestamp, Value: zipkincore.CLIENT_SEND, Host: local},
+			&zipkincore.Annotation{Timestamp: end, Value: zipkincore.CLIENT_RECV, Host: local})
+		span.BinaryAnnotations = appendAddress(span.BinaryAnnotations, zipkincore.SERVER_ADDR, remote)
+	case zipkinKindServer:
+		span.Annotations = append(span.Annotations,
+			&zipkincore.Annotation{Timestamp: zs.Timestamp, Value: zipkincore.SERVER_RECV, Host: local},
+			&zipkincore.Annotation{Timestamp: end, Value: zipkincore.SERVER_SEND, Host: local})
+		span.BinaryAnnotations = appendAddress(span.BinaryAnnotations, zipkincore.CLIENT_ADDR, remote)
+	case zipkinKindProducer, zipkinKindConsumer:
+		// v1 messaging annotations carry no span kind in the domain converter, so the kind is passed as a tag.
+		span.BinaryAnnotations = append(span.BinaryAnnotations, &zipkincore.BinaryAnnotation{
+			Key:            "span.kind",
+			Value:          []byte(lowerKind(zs.Kind)),
+			AnnotationType: zipkincore.AnnotationType_STRING,
+			Host:           local,
+		})
+		span.BinaryAnnotations = appendAddress(span.BinaryAnnotations, zipkincore.MESSAGE_ADDR, remote)
+	}
+	for _, a := range zs.Annotations {
+		span.Annotations = append(span.Annotations, &zipkincore.Annotation{
+			Timestamp: a.Timestamp,
+			Value:     a.Value,
+			Host:      local,
+		})
+	}
+	for k, v := range zs.Tags {
+		span.BinaryAnnotations = append(span.BinaryAnnotations, &zipkincore.BinaryAnnotation{
+			Key:            k,
+			Value:          []byte(v),
+			AnnotationType: zipkincore.AnnotationType_STRING,
+			Host:           local,
+		})
+	}
+	return span, nil
+}
+
+func (e *zipkinEndpoint) toThrift() *zipkincore.Endpoint {
+	if e == nil {
+		return nil
+	}
+	endpoint := &zipkincore.Endpoint{
+		ServiceName: e.ServiceName,
+		Port:        int16(e.Port),
+	}
+	if ip := net.ParseIP(e.IPv4).To4(); ip != nil {
+		endpoint.Ipv4 = int32(binary.BigEndian.Uint32(ip))
+	}
+	if ip := net.ParseIP(e.IPv6).To16(); ip != nil {
+		endpoint.Ipv6 = ip
+	}
+	return endpoint
+}
+
+func appendAddress(annotations []*zipkincore.BinaryAnnotation, key string, endpoint *zipkincore.Endpoint) []*zipkincore.BinaryAnnotation {
+	if endpoint == nil {
+		return annotations
+	}
+	return append(annotations, &zipkincore.BinaryAnnotation{
+		Key:            key,
+		Value:          []byte{1},
+		AnnotationType: zipkincore.AnnotationType_BOOL,
+		Host:           endpoint,
+	})
+}
+
+func lowerKind(kind string) string {
+	if kind == zipkinKindProducer {
+		return "producer"
+	}
+	return "consumer"
+}
+
+func (s *SpanReader) zipkinIndices(startTime, endTime time.Time) []string {
+	return timeRangeIndices(s.zipkinSpanIndexPrefix, zipkinIndexDateLayout, startTime, endTime, -24*time.Hour)
+}
+
+// readZipkinTraces loads spans stored in the Zipkin index layout for the given trace IDs.
+func (s *SpanReader) readZipkinTraces(ctx context.Context, traceIDs []model.TraceID, startTime, endTime time.Time) ([]*model.Trace, error) {
+	ctx, childSpan := s.tracer.Start(ctx, "readZipkinTraces")
+	defer childSpan.End()
+
+	if len(traceIDs) == 0 {
+		return nil, nil
+	}
+	ids := make([]any, len(traceIDs))
+	for i, traceID := range traceIDs {
+		ids[i] = traceID.String()
+	}
+	indices := s.zipkinIndices(startTime.Add(-time.Hour), endTime.Add(time.Hour))
+	searchResult, err := s.client().Search(indices...).
+		Size(s.maxDocCount).
+		IgnoreUnavailable(true).
+		Query(elastic.NewTermsQuery(zipkinTraceIDField, ids...)).
+		Do(ctx)
+	if err != nil {
+		err = es.DetailedError(err)
+		logErrorToSpan(childSpan, err)
+		return nil, fmt.Errorf("search zipkin spans failed: %w", err)
+	}
+	if searchResult.Hits == nil {
+		return nil, nil
+	}
+
+	var traces []*model.Trace
+	tracesMap := make(map[model.TraceID]*model.Trace)
+	for _, hit := range searchResult.Hits.Hits {
+		spans, err := s.zipkinHitToDomain(hit)
+		if err != nil {
+			return nil, err
+		}
+		for _, span := range spans {
+			trace, ok := tracesMap[span.TraceID]
+			if !ok {
+				trace = &model.Trace{}
+				tracesMap[span.TraceID] = trace
+				traces = append(traces, trace)
+			}
+			trace.Spans = append(trace.Spans, span)
+		}
+	}
+	return traces, nil
+}
+
+func (s *SpanReader) zipkinHitToDomain(hit *elastic.SearchHit) ([]*model.Span, error) {
+	var zSpan zipkinSpan
+	d := json.NewDecoder(bytes.NewReader(*hit.Source))
+	if err := d.Decode(&zSpan); err != nil {
+		return nil, fmt.Errorf("unmarshalling zipkin span failed: %w", err)
+	}
+	thriftSpan, err := zSpan.toThrift()
+	if err != nil {
+		return nil, fmt.Errorf("converting zipkin span failed: %w", err)
+	}
+	spans, err := zipkin.ToDomainSpan(thriftSpan)
+	if err != nil {
+		// the zipkin converter always returns valid spans, errors only describe issues in the data
+		s.logger.Debug("zipkin span converted with warnings", zap.Error(err))
+	}
+	if zSpan.LocalEndpoint != nil && zSpan.LocalEndpoint.ServiceName != "" {
+		for _, span := range spans {
+			if span.Process.ServiceName == zipkin.UnknownServiceName {
+				span.Process.ServiceName = zSpan.LocalEndpoint.ServiceName
+			}
+		}
+	}
+	return spans, nil
+}
+
+// findZipkinTraceIDs searches the Zipkin index layout for trace IDs matching the query.
+func (s *SpanReader) findZipkinTraceIDs(ctx context.Context, traceQuery *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	ctx, childSpan := s.tracer.Start(ctx, "findZipkinTraceIDs")
+	defer childSpan.End()
+
+	boolQuery := elastic.NewBoolQuery().Must(
+		elastic.NewRangeQuery(zipkinTimestampMillisField).
+			Gte(model.TimeAsEpochMicroseconds(traceQuery.StartTimeMin) / 1000).
+			Lte(model.TimeAsEpochMicroseconds(traceQuery.StartTimeMax) / 1000))
+	if traceQuery.DurationMax != 0 || traceQuery.DurationMin != 0 {
+		maxDuration := defaultMaxDuration
+		if traceQuery.DurationMax != 0 {
+			maxDuration = model.DurationAsMicroseconds(traceQuery.DurationMax)
+		}
+		boolQuery.Must(elastic.NewRangeQuery(zipkinDurationField).
+			Gte(model.DurationAsMicroseconds(traceQuery.DurationMin)).
+			Lte(maxDuration))
+	}
+	if traceQuery.ServiceName != "" {
+		boolQuery.Must(elastic.NewTermQuery(zipkinServiceNameField, traceQuery.ServiceName))
+	}
+	if traceQuery.OperationName != "" {
+		boolQuery.Must(elastic.NewTermQuery(zipkinNameField, traceQuery.OperationName))
+	}
+	for k, v := range traceQuery.Tags {
+		// Zipkin indexes tags as "key=value" terms in the _q field.
+		boolQuery.Must(elastic.NewTermQuery(zipkinQueryField, k+"="+v))
+	}
+	aggregation := elastic.NewTermsAggregation().
+		Size(traceQuery.NumTraces).
+		Field(zipkinTraceIDField).
+		Order(zipkinTimestampMillisField, false).
+		SubAggregation(zipkinTimestampMillisField, elastic.NewMaxAggregation().Field(zipkinTimestampMillisField))
+
+	searchResult, err := s.client().Search(s.zipkinIndices(traceQuery.StartTimeMin, traceQuery.StartTimeMax)...).
+		Size(0).
+		Aggregation(traceIDAggregation, aggregation).
+		IgnoreUnavailable(true).
+		Query(boolQuery).
+		Do(ctx)
+	if err != nil {
+		err = es.DetailedError(err)
+		logErrorToSpan(childSpan, err)
+		return nil, fmt.Errorf("search zipkin trace IDs failed: %w", err)
+	}
+	if searchResult.Aggregations == nil {
+		return nil, nil
+	}
+	bucket, found := searchResult.Aggregations.Terms(traceIDAggregation)
+	if !found {
+		return nil, ErrUnableToFindTraceIDAggregation
+	}
+	traceIDs, err := bucketToStringArray(bucket.Buckets)
+	if err != nil {
+		return nil, err
+	}
+	return convertTraceIDsStringsToModels(traceIDs)
+}
+
+// getZipkinServices returns the service names found in the Zipkin index layout.
+func (s *SpanReader) getZipkinServices(ctx context.Context, startTime, endTime time.Time) ([]string, error) {
+	aggregation := elastic.NewTermsAggregation().
+		Field(zipkinServiceNameField).
+		Size(s.maxDocCount)
+	searchResult, err := s.client().Search(s.zipkinIndices(startTime, endTime)...).
+		Size(0).
+		IgnoreUnavailable(true).
+		Aggregation(zipkinServicesAggregation, aggregation).
+		Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("search zipkin services failed: %w", es.DetailedError(err))
+	}
+	if searchResult.Aggregations == nil {
+		return nil, nil
+	}
+	bucket, found := searchResult.Aggregations.Terms(zipkinServicesAggregation)
+	if !found {
+		return nil, fmt.Errorf("could not find aggregation of %s", zipkinServicesAggregation)
+	}
+	return bucketToStringArray(bucket.Buckets)
+}
+
+// mergeTraces appends the spans of the extra traces into the matching traces by trace ID.
+func mergeTraces(traces []*model.Trace, extra []*model.Trace) []*model.Trace {
+	byID := make(map[model.TraceID]*model.Trace, len(traces))
+	for _, trace := range traces {
+		if len(trace.Spans) > 0 {
+			byID[trace.Spans[0].TraceID] = trace
+		}
+	}
+	for _, trace := range extra {
+		if len(trace.Spans) == 0 {
+			continue
+		}
+		if existing, ok := byID[trace.Spans[0].TraceID]; ok {
+			existing.Spans = append(existing.Spans, trace.Spans...)
+			continue
+		}
+		byID[trace.Spans[0].TraceID] = trace
+		traces = append(traces, trace)
+	}
+	return traces
+}
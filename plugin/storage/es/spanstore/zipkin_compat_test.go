@@ -0,0 +1,249 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/es"
+	"github.com/jaegertracing/jaeger/pkg/es/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var exampleZipkinSpan = []byte(
+	`{
+	   "traceId": "0000000000000001",
+	   "parentId": "0000000000000003",
+	   "id": "0000000000000004",
+	   "kind": "CLIENT",
+	   "name": "get /api",
+	   "timestamp": 1472470996199000,
+	   "duration": 207000,
+	   "localEndpoint": {"serviceName": "frontend", "ipv4": "192.168.99.1"},
+	   "remoteEndpoint": {"serviceName": "backend", "ipv4": "172.17.0.13", "port": 8080},
+	   "annotations": [{"timestamp": 1472470996238000, "value": "ws"}],
+	   "tags": {"http.path": "/api"}
+	}`)
+
+func withZipkinCompatSpanReader(t *testing.T, fn func(r *spanReaderTest)) {
+	client := &mocks.Client{}
+	tracer, exp, closer := tracerProvider(t)
+	defer closer()
+	r := &spanReaderTest{
+		client:      client,
+		traceBuffer: exp,
+		reader: NewSpanReader(SpanReaderParams{
+			Client:                  func() es.Client { return client },
+			Logger:                  zap.NewNop(),
+			Tracer:                  tracer.Tracer("test"),
+			TagDotReplacement:       "@",
+			MaxDocCount:             defaultMaxDocCount,
+			ZipkinCompatIndexPrefix: "zipkin",
+		}),
+	}
+	fn(r)
+}
+
+func mockZipkinSearchService(r *spanReaderTest) *mock.Call {
+	searchService := &mocks.SearchService{}
+	searchService.On("Query", mock.Anything).Return(searchService)
+	searchService.On("IgnoreUnavailable", true).Return(searchService)
+	searchService.On("Size", mock.AnythingOfType("int")).Return(searchService)
+	searchService.On("Aggregation", mock.AnythingOfType("string"), mock.Anything).Return(searchService)
+	zipkinIndex := mock.MatchedBy(func(index string) bool {
+		return strings.HasPrefix(index, "zipkin:span-")
+	})
+	r.client.On("Search", zipkinIndex).Return(searchService)
+	r.client.On("Search", zipkinIndex, zipkinIndex).Return(searchService)
+	return searchService.On("Do", mock.Anything)
+}
+
+func TestZipkinSpanToThrift(t *testing.T) {
+	r := &SpanReader{logger: zap.NewNop()}
+	spans, err := r.zipkinHitToDomain(&elastic.SearchHit{Source: (*json.RawMessage)(&exampleZipkinSpan)})
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, model.NewTraceID(0, 1), span.TraceID)
+	assert.Equal(t, model.NewSpanID(4), span.SpanID)
+	assert.Equal(t, model.NewSpanID(3), span.ParentSpanID())
+	assert.Equal(t, "get /api", span.OperationName)
+	assert.Equal(t, 207*time.Millisecond, span.Duration)
+	assert.Equal(t, "frontend", span.Process.ServiceName)
+	assert.True(t, span.IsRPCClient())
+	peerService, ok := model.KeyValues(span.Tags).FindByKey("peer.service")
+	require.True(t, ok)
+	assert.Equal(t, "backend", peerService.VStr)
+	peerPort, ok := model.KeyValues(span.Tags).FindByKey("peer.port")
+	require.True(t, ok)
+	assert.EqualValues(t, 8080, peerPort.VInt64)
+	path, ok := model.KeyValues(span.Tags).FindByKey("http.path")
+	require.True(t, ok)
+	assert.Equal(t, "/api", path.VStr)
+	require.Len(t, span.Logs, 1)
+	assert.Equal(t, "ws", span.Logs[0].Fields[0].VStr)
+}
+
+func TestZipkinSpanToThriftKinds(t *testing.T) {
+	testCases := []struct {
+		kind         string
+		expectedKind string
+	}{
+		{kind: zipkinKindServer, expectedKind: "server"},
+		{kind: zipkinKindProducer, expectedKind: "producer"},
+		{kind: zipkinKindConsumer, expectedKind: "consumer"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.kind, func(t *testing.T) {
+			zSpan := &zipkinSpan{
+				TraceID:        "00000000000000020000000000000001",
+				ID:             "0000000000000004",
+				Kind:           tc.kind,
+				Timestamp:      1000,
+				Duration:       10,
+				LocalEndpoint:  &zipkinEndpoint{ServiceName: "svc"},
+				RemoteEndpoint: &zipkinEndpoint{ServiceName: "remote"},
+			}
+			thriftSpan, err := zSpan.toThrift()
+			require.NoError(t, err)
+			require.NotNil(t, thriftSpan.TraceIDHigh)
+			assert.EqualValues(t, 2, *thriftSpan.TraceIDHigh)
+
+			raw, err := json.Marshal(zSpan)
+			require.NoError(t, err)
+			r := &SpanReader{logger: zap.NewNop()}
+			spans, err := r.zipkinHitToDomain(&elastic.SearchHit{Source: (*json.RawMessage)(&raw)})
+			require.NoError(t, err)
+			require.Len(t, spans, 1)
+			kind, ok := model.KeyValues(spans[0].Tags).FindByKey("span.kind")
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedKind, kind.VStr)
+			assert.Equal(t, "svc", spans[0].Process.ServiceName)
+		})
+	}
+}
+
+func TestZipkinSpanToThriftInvalidIDs(t *testing.T) {
+	_, err := (&zipkinSpan{TraceID: "zz", ID: "1"}).toThrift()
+	require.Error(t, err)
+	_, err = (&zipkinSpan{TraceID: "1", ID: "zz"}).toThrift()
+	require.Error(t, err)
+	_, err = (&zipkinSpan{TraceID: "1", ID: "1", ParentID: "zz"}).toThrift()
+	require.Error(t, err)
+}
+
+func TestSpanReader_GetTraceZipkinCompat(t *testing.T) {
+	withZipkinCompatSpanReader(t, func(r *spanReaderTest) {
+		mockZipkinSearchService(r).Return(&elastic.SearchResult{
+			Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: (*json.RawMessage)(&exampleZipkinSpan)}}},
+		}, nil)
+		mockMultiSearchService(r).Return(&elastic.MultiSearchResult{
+			Responses: []*elastic.SearchResult{
+				{Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: (*json.RawMessage)(&exampleESSpan)}}}},
+			},
+		}, nil)
+
+		trace, err := r.reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+		require.NoError(t, err)
+		require.Len(t, trace.Spans, 2)
+		assert.Equal(t, "serv", trace.Spans[0].Process.ServiceName)
+		assert.Equal(t, "frontend", trace.Spans[1].Process.ServiceName)
+	})
+}
+
+func TestSpanReader_GetTraceZipkinOnly(t *testing.T) {
+	withZipkinCompatSpanReader(t, func(r *spanReaderTest) {
+		mockZipkinSearchService(r).Return(&elastic.SearchResult{
+			Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: (*json.RawMessage)(&exampleZipkinSpan)}}},
+		}, nil)
+		mockMultiSearchService(r).Return(&elastic.MultiSearchResult{}, nil)
+
+		trace, err := r.reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+		require.NoError(t, err)
+		require.Len(t, trace.Spans, 1)
+		assert.Equal(t, "frontend", trace.Spans[0].Process.ServiceName)
+	})
+}
+
+func TestSpanReader_GetTraceZipkinError(t *testing.T) {
+	withZipkinCompatSpanReader(t, func(r *spanReaderTest) {
+		mockZipkinSearchService(r).Return(nil, assert.AnError)
+		mockMultiSearchService(r).Return(&elastic.MultiSearchResult{}, nil)
+
+		_, err := r.reader.GetTrace(context.Background(), model.NewTraceID(0, 1))
+		require.ErrorContains(t, err, "search zipkin spans failed")
+	})
+}
+
+func TestSpanReader_GetServicesZipkinCompat(t *testing.T) {
+	nativeAggregations := map[string]*json.RawMessage{}
+	nativeRaw := json.RawMessage(`{"buckets": [{"key": "serv","doc_count": 16}, {"key": "frontend","doc_count": 16}]}`)
+	nativeAggregations[servicesAggregation] = &nativeRaw
+	zipkinAggregations := map[string]*json.RawMessage{}
+	zipkinRaw := json.RawMessage(`{"buckets": [{"key": "frontend","doc_count": 3}, {"key": "legacy","doc_count": 2}]}`)
+	zipkinAggregations[zipkinServicesAggregation] = &zipkinRaw
+
+	withZipkinCompatSpanReader(t, func(r *spanReaderTest) {
+		mockZipkinSearchService(r).Return(&elastic.SearchResult{Aggregations: zipkinAggregations}, nil)
+		mockSearchService(r).Return(&elastic.SearchResult{Aggregations: nativeAggregations}, nil)
+
+		services, err := r.reader.GetServices(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"serv", "frontend", "legacy"}, services)
+	})
+}
+
+func TestSpanReader_FindTracesZipkinCompat(t *testing.T) {
+	nativeAggregations := map[string]*json.RawMessage{}
+	nativeRaw := json.RawMessage(`{"buckets": [{"key": "1","doc_count": 16}]}`)
+	nativeAggregations[traceIDAggregation] = &nativeRaw
+	zipkinAggregations := map[string]*json.RawMessage{}
+	zipkinRaw := json.RawMessage(`{"buckets": [{"key": "0000000000000001","doc_count": 3}, {"key": "0000000000000002","doc_count": 2}]}`)
+	zipkinAggregations[traceIDAggregation] = &zipkinRaw
+
+	zipkinOnlySpan := []byte(strings.Replace(string(exampleZipkinSpan), `"traceId": "0000000000000001"`, `"traceId": "0000000000000002"`, 1))
+
+	withZipkinCompatSpanReader(t, func(r *spanReaderTest) {
+		zipkinSearch := mockZipkinSearchService(r)
+		zipkinSearch.Return(&elastic.SearchResult{Aggregations: zipkinAggregations}, nil).Once()
+		mockSearchService(r).Return(&elastic.SearchResult{Aggregations: nativeAggregations}, nil)
+		mockMultiSearchService(r).Return(&elastic.MultiSearchResult{
+			Responses: []*elastic.SearchResult{
+				{Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{{Source: (*json.RawMessage)(&exampleESSpan)}}}},
+			},
+		}, nil)
+		zipkinSearch.Parent.On("Do", mock.Anything).Return(&elastic.SearchResult{
+			Hits: &elastic.SearchHits{Hits: []*elastic.SearchHit{
+				{Source: (*json.RawMessage)(&exampleZipkinSpan)},
+				{Source: (*json.RawMessage)(&zipkinOnlySpan)},
+			}},
+		}, nil).Once()
+
+		traces, err := r.reader.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+			ServiceName:  "frontend",
+			Tags:         map[string]string{"http.path": "/api"},
+			StartTimeMin: time.Now().Add(-time.Hour),
+			StartTimeMax: time.Now(),
+			DurationMin:  time.Millisecond,
+			NumTraces:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, traces, 2)
+		assert.Len(t, traces[0].Spans, 2)
+		assert.Len(t, traces[1].Spans, 1)
+		assert.Equal(t, model.NewTraceID(0, 2), traces[1].Spans[0].TraceID)
+	})
+}
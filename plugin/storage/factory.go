@@ -30,10 +30,13 @@ import (
 	"github.com/jaegertracing/jaeger/plugin/storage/badger"
 	"github.com/jaegertracing/jaeger/plugin/storage/blackhole"
 	"github.com/jaegertracing/jaeger/plugin/storage/cassandra"
+	"github.com/jaegertracing/jaeger/plugin/storage/clickhouse"
 	"github.com/jaegertracing/jaeger/plugin/storage/es"
+	"github.com/jaegertracing/jaeger/plugin/storage/file"
 	"github.com/jaegertracing/jaeger/plugin/storage/grpc"
 	"github.com/jaegertracing/jaeger/plugin/storage/kafka"
 	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/plugin/storage/nats"
 	"github.com/jaegertracing/jaeger/storage"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
@@ -41,14 +44,17 @@ import (
 
 const (
 	cassandraStorageType     = "cassandra"
+	clickhouseStorageType    = "clickhouse"
 	opensearchStorageType    = "opensearch"
 	elasticsearchStorageType = "elasticsearch"
 	memoryStorageType        = "memory"
 	kafkaStorageType         = "kafka"
+	natsStorageType          = "nats"
 	grpcStorageType          = "grpc"
 	grpcPluginDeprecated     = "grpc-plugin"
 	badgerStorageType        = "badger"
 	blackholeStorageType     = "blackhole"
+	fileStorageType          = "file"
 
 	downsamplingRatio    = "downsampling.ratio"
 	downsamplingHashSalt = "downsampling.hashsalt"
@@ -63,13 +69,16 @@ const (
 // AllStorageTypes defines all available storage backends
 var AllStorageTypes = []string{
 	cassandraStorageType,
+	clickhouseStorageType,
 	opensearchStorageType,
 	elasticsearchStorageType,
 	memoryStorageType,
 	kafkaStorageType,
+	natsStorageType,
 	badgerStorageType,
 	blackholeStorageType,
 	grpcStorageType,
+	fileStorageType,
 }
 
 // AllSamplingStorageTypes returns all storage backends that implement adaptive sampling
@@ -129,18 +138,24 @@ func (*Factory) getFactoryOfType(factoryType string) (storage.Factory, error) {
 	switch factoryType {
 	case cassandraStorageType:
 		return cassandra.NewFactory(), nil
+	case clickhouseStorageType:
+		return clickhouse.NewFactory(), nil
 	case elasticsearchStorageType, opensearchStorageType:
 		return es.NewFactory(), nil
 	case memoryStorageType:
 		return memory.NewFactory(), nil
 	case kafkaStorageType:
 		return kafka.NewFactory(), nil
+	case natsStorageType:
+		return nats.NewFactory(), nil
 	case badgerStorageType:
 		return badger.NewFactory(), nil
 	case grpcStorageType:
 		return grpc.NewFactory(), nil
 	case blackholeStorageType:
 		return blackhole.NewFactory(), nil
+	case fileStorageType:
+		return file.NewFactory(), nil
 	default:
 		return nil, fmt.Errorf("unknown storage type %s. Valid types are %v", factoryType, AllStorageTypes)
 	}
@@ -48,12 +48,15 @@ type FactoryConfig struct {
 // FactoryConfigFromEnvAndCLI reads the desired types of storage backends from SPAN_STORAGE_TYPE and
 // DEPENDENCY_STORAGE_TYPE environment variables. Allowed values:
 // * `cassandra` - built-in
+// * `clickhouse` - built-in
 // * `opensearch` - built-in
 // * `elasticsearch` - built-in
 // * `memory` - built-in
 // * `kafka` - built-in
+// * `nats` - built-in
 // * `blackhole` - built-in
 // * `grpc` - build-in
+// * `file` - built-in, read-only
 //
 // For backwards compatibility it also parses the args looking for deprecated --span-storage.type flag.
 // If found, it writes a deprecation warning to the log.
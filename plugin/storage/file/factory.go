@@ -0,0 +1,223 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var ( // interface comformance checks
+	_ storage.Factory     = (*Factory)(nil)
+	_ io.Closer           = (*Factory)(nil)
+	_ plugin.Configurable = (*Factory)(nil)
+)
+
+var errReadOnly = errors.New("file storage is read-only")
+
+var (
+	_ spanstore.TraceIDPrefixReader = (*spanReader)(nil)
+	_ spanstore.TagKeysReader       = (*spanReader)(nil)
+)
+
+// spanReader exposes the read capabilities of the memory store only, and not its DeleteTrace,
+// the traces of the files being read-only.
+type spanReader struct {
+	spanstore.Reader
+	store *memory.Store
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader.
+func (r *spanReader) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	return r.store.FindTraceIDsByPrefix(ctx, prefix, limit)
+}
+
+// GetTagKeys implements spanstore.TagKeysReader.
+func (r *spanReader) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	return r.store.GetTagKeys(ctx, query)
+}
+
+// fileMetrics counts the files loaded, or skipped because they are malformed, and their spans.
+type fileMetrics struct {
+	FilesLoaded    metrics.Counter `metric:"files" tags:"result=loaded"`
+	FilesMalformed metrics.Counter `metric:"files" tags:"result=malformed"`
+	SpansLoaded    metrics.Counter `metric:"spans_loaded"`
+}
+
+// Factory implements storage.Factory and creates read-only storage components serving the traces of
+// OTLP and Jaeger JSON export files, e.g. to browse the traces exported from an air-gapped site without
+// a storage backend. The spans are loaded at startup into the structures of the memory storage, along
+// with the files added later to the directories, which are watched.
+type Factory struct {
+	options Options
+	logger  *zap.Logger
+	metrics fileMetrics
+	store   *memory.Store
+
+	mu sync.Mutex
+	// loaded holds the files loaded, so that they are not loaded twice
+	loaded map[string]struct{}
+
+	watcher *fsnotify.Watcher
+	done    sync.WaitGroup
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// AddFlags implements plugin.Configurable
+func (*Factory) AddFlags(flagSet *flag.FlagSet) {
+	AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, _ *zap.Logger) {
+	f.options.InitFromViper(v)
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	if err := f.options.Validate(); err != nil {
+		return err
+	}
+	f.logger = logger
+	metrics.MustInit(&f.metrics, metricsFactory.Namespace(metrics.NSOptions{Name: "file"}), nil)
+	f.store = memory.NewStore()
+	f.loaded = make(map[string]struct{})
+
+	var dirs []string
+	for _, path := range f.options.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("cannot load the traces of %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			f.loadFile(path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return fmt.Errorf("cannot load the traces of %s: %w", path, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isTraceFile(entry.Name()) {
+				f.loadFile(filepath.Join(path, entry.Name()))
+			}
+		}
+		dirs = append(dirs, path)
+	}
+	logger.Info("File storage initialized", zap.Strings("paths", f.options.Paths), zap.Int("files", len(f.loaded)))
+
+	if f.options.Watch && len(dirs) > 0 {
+		return f.watch(dirs)
+	}
+	return nil
+}
+
+// watch loads the files added to the directories, or written, until the factory is closed.
+// The files are better moved to the directories once complete, the files read while they
+// are written being skipped as malformed until they are written again.
+func (f *Factory) watch(dirs []string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("cannot watch %s: %w", dir, err)
+		}
+	}
+	f.watcher = watcher
+	f.done.Add(1)
+	go func() {
+		defer f.done.Done()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create|fsnotify.Write) && isTraceFile(event.Name) {
+					f.loadFile(event.Name)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				f.logger.Error("File storage watcher reported an error", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// loadFile loads the spans of the file into the store, unless it was already loaded.
+// The malformed files are logged and skipped.
+func (f *Factory) loadFile(path string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.loaded[path]; ok {
+		return
+	}
+	spans, err := loadSpans(path)
+	if err != nil {
+		f.metrics.FilesMalformed.Inc(1)
+		f.logger.Warn("Skipping malformed trace file", zap.String("file", path), zap.Error(err))
+		return
+	}
+	for _, span := range spans {
+		// the memory store does not fail
+		_ = f.store.WriteSpan(context.Background(), span)
+	}
+	f.loaded[path] = struct{}{}
+	f.metrics.FilesLoaded.Inc(1)
+	f.metrics.SpansLoaded.Inc(int64(len(spans)))
+	f.logger.Info("Loaded trace file", zap.String("file", path), zap.Int("spans", len(spans)))
+}
+
+// CreateSpanReader implements storage.Factory
+func (f *Factory) CreateSpanReader() (spanstore.Reader, error) {
+	return &spanReader{Reader: f.store, store: f.store}, nil
+}
+
+// CreateSpanWriter implements storage.Factory
+func (*Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	return nil, errReadOnly
+}
+
+// CreateDependencyReader implements storage.Factory
+func (f *Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return f.store, nil
+}
+
+// Close stops watching the directories.
+func (f *Factory) Close() error {
+	if f.watcher == nil {
+		return nil
+	}
+	err := f.watcher.Close()
+	f.done.Wait()
+	return err
+}
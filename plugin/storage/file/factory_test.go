@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+func initializeFactory(t *testing.T, metricsFactory metrics.Factory, args ...string) (*Factory, error) {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags(args))
+	f.InitFromViper(v, zap.NewNop())
+	err := f.Initialize(metricsFactory, zap.NewNop())
+	if err == nil {
+		t.Cleanup(func() { require.NoError(t, f.Close()) })
+	}
+	return f, err
+}
+
+func TestFactoryLoadsDirectory(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	f, err := initializeFactory(t, metricsFactory, "--file.paths=fixtures", "--file.watch=false")
+	require.NoError(t, err)
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "file.files", Tags: map[string]string{"result": "loaded"}, Value: 3},
+		metricstest.ExpectedMetric{Name: "file.files", Tags: map[string]string{"result": "malformed"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "file.spans_loaded", Value: 6},
+	)
+
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+	services, err := reader.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"frontend", "inventory", "dispatch", "redis"}, services)
+
+	traceID, err := model.TraceIDFromString("5b8efff798038103d269b633813fc60c")
+	require.NoError(t, err)
+	trace, err := reader.GetTrace(context.Background(), traceID)
+	require.NoError(t, err)
+	assert.Len(t, trace.Spans, 2)
+
+	traces, err := reader.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName:  "inventory",
+		StartTimeMin: time.Unix(0, 0),
+		StartTimeMax: time.Now(),
+		NumTraces:    10,
+	})
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+
+	// the reader keeps the read capabilities of the memory store, but cannot delete the traces
+	assert.Implements(t, (*spanstore.TraceIDPrefixReader)(nil), reader)
+	assert.Implements(t, (*spanstore.TagKeysReader)(nil), reader)
+	_, ok := reader.(spanstore.TraceDeleter)
+	assert.False(t, ok)
+	traceIDs, err := reader.(spanstore.TraceIDPrefixReader).FindTraceIDsByPrefix(context.Background(), "5b8efff7", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{traceID}, traceIDs)
+	tagKeys, err := reader.(spanstore.TagKeysReader).GetTagKeys(context.Background(), spanstore.TagKeysQueryParameters{
+		ServiceName:  "inventory",
+		StartTimeMin: time.Unix(0, 0),
+		StartTimeMax: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tagKeys)
+
+	dependencyReader, err := f.CreateDependencyReader()
+	require.NoError(t, err)
+	assert.Same(t, f.store, dependencyReader)
+}
+
+func TestFactoryLoadsFiles(t *testing.T) {
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	_, err := initializeFactory(t, metricsFactory,
+		"--file.paths=fixtures/otlp_trace.json,fixtures/jaeger_trace.json,fixtures/otlp_trace.json")
+	require.NoError(t, err)
+
+	// the file given twice is loaded once
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "file.files", Tags: map[string]string{"result": "loaded"}, Value: 2},
+		metricstest.ExpectedMetric{Name: "file.spans_loaded", Value: 4},
+	)
+}
+
+func TestFactoryWatchesDirectories(t *testing.T) {
+	dir := t.TempDir()
+	f, err := initializeFactory(t, metrics.NullFactory, "--file.paths="+dir)
+	require.NoError(t, err)
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join("fixtures", "jaeger_trace.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "jaeger_trace.json"), data, 0o600))
+	assert.Eventually(t, func() bool {
+		services, err := reader.GetServices(context.Background())
+		return err == nil && len(services) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFactoryReadOnly(t *testing.T) {
+	f, err := initializeFactory(t, metrics.NullFactory, "--file.paths=fixtures", "--file.watch=false")
+	require.NoError(t, err)
+	_, err = f.CreateSpanWriter()
+	require.ErrorIs(t, err, errReadOnly)
+}
+
+func TestFactoryInitializeErrors(t *testing.T) {
+	_, err := initializeFactory(t, metrics.NullFactory)
+	require.EqualError(t, err, "the file storage requires at least one path in --file.paths")
+
+	_, err = initializeFactory(t, metrics.NullFactory, "--file.paths=fixtures/missing.json")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFactoryCloseWithoutWatcher(t *testing.T) {
+	require.NoError(t, NewFactory().Close())
+}
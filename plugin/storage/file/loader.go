@@ -0,0 +1,146 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/jaegertracing/jaeger/internal/otlptranslator"
+	"github.com/jaegertracing/jaeger/model"
+	uiconv "github.com/jaegertracing/jaeger/model/converter/json"
+	uimodel "github.com/jaegertracing/jaeger/model/json"
+)
+
+var (
+	jsonExtensions  = []string{".json", ".jsonl"}
+	protoExtensions = []string{".pb", ".binpb"}
+)
+
+// isTraceFile returns whether the file has the extension of one of the supported formats.
+func isTraceFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return slices.Contains(jsonExtensions, ext) || slices.Contains(protoExtensions, ext)
+}
+
+// loadSpans returns the spans of a file of OTLP/Protobuf, if it has a .pb or .binpb extension,
+// or else of a file of JSON documents, which are either OTLP/JSON exports, e.g. the lines
+// written by the file exporter of the OpenTelemetry Collector, or Jaeger JSON exports.
+func loadSpans(path string) ([]*model.Span, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var spans []*model.Span
+	if slices.Contains(protoExtensions, strings.ToLower(filepath.Ext(path))) {
+		traces, err := new(ptrace.ProtoUnmarshaler).UnmarshalTraces(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal OTLP/Protobuf: %w", err)
+		}
+		spans = otlpSpans(traces)
+	} else if spans, err = jsonSpans(data); err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, errors.New("the file has no spans")
+	}
+	return spans, nil
+}
+
+// jsonSpans returns the spans of the sequence of JSON documents.
+func jsonSpans(data []byte) ([]*model.Span, error) {
+	var spans []*model.Span
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var document json.RawMessage
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return spans, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse JSON: %w", err)
+		}
+		documentSpans, err := jsonDocumentSpans(document)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, documentSpans...)
+	}
+}
+
+// jsonDocumentSpans returns the spans of a JSON document, which is either an OTLP/JSON export, with resourceSpans,
+// a Jaeger JSON export of the Jaeger UI or of the HTTP API, with data, a single Jaeger JSON trace, or a list of them.
+func jsonDocumentSpans(document json.RawMessage) ([]*model.Span, error) {
+	var uiTraces []uimodel.Trace
+	if bytes.HasPrefix(bytes.TrimSpace(document), []byte("[")) {
+		if err := json.Unmarshal(document, &uiTraces); err != nil {
+			return nil, fmt.Errorf("cannot parse the Jaeger JSON traces: %w", err)
+		}
+		return jaegerSpans(uiTraces)
+	}
+	var probe struct {
+		ResourceSpans json.RawMessage `json:"resourceSpans"`
+		Data          json.RawMessage `json:"data"`
+		Spans         json.RawMessage `json:"spans"`
+	}
+	if err := json.Unmarshal(document, &probe); err != nil {
+		return nil, fmt.Errorf("cannot parse JSON: %w", err)
+	}
+	switch {
+	case probe.ResourceSpans != nil:
+		traces, err := new(ptrace.JSONUnmarshaler).UnmarshalTraces(document)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal OTLP/JSON: %w", err)
+		}
+		return otlpSpans(traces), nil
+	case probe.Data != nil:
+		if err := json.Unmarshal(probe.Data, &uiTraces); err != nil {
+			return nil, fmt.Errorf("cannot parse the Jaeger JSON traces: %w", err)
+		}
+	case probe.Spans != nil:
+		uiTraces = make([]uimodel.Trace, 1)
+		if err := json.Unmarshal(document, &uiTraces[0]); err != nil {
+			return nil, fmt.Errorf("cannot parse the Jaeger JSON trace: %w", err)
+		}
+	default:
+		return nil, errors.New("the JSON document is neither an OTLP nor a Jaeger export")
+	}
+	return jaegerSpans(uiTraces)
+}
+
+func jaegerSpans(uiTraces []uimodel.Trace) ([]*model.Span, error) {
+	var spans []*model.Span
+	for i := range uiTraces {
+		trace, err := uiconv.ToDomain(&uiTraces[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid Jaeger JSON trace %s: %w", uiTraces[i].TraceID, err)
+		}
+		spans = append(spans, trace.Spans...)
+	}
+	return spans, nil
+}
+
+func otlpSpans(traces ptrace.Traces) []*model.Span {
+	// ProtoFromTraces does not return errors
+	batches, _ := otlptranslator.ProtoFromTraces(traces)
+	var spans []*model.Span
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if span.Process == nil {
+				span.Process = batch.Process
+			}
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/jaegertracing/jaeger/model"
+)
+
+type loadedSpan struct {
+	traceID   string
+	service   string
+	operation string
+}
+
+func loadedSpans(spans []*model.Span) []loadedSpan {
+	out := make([]loadedSpan, len(spans))
+	for i, span := range spans {
+		out[i] = loadedSpan{traceID: span.TraceID.String(), service: span.Process.ServiceName, operation: span.OperationName}
+	}
+	return out
+}
+
+func TestLoadSpans(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []loadedSpan
+	}{
+		{
+			file: "otlp_trace.json",
+			expected: []loadedSpan{
+				{traceID: "5b8efff798038103d269b633813fc60c", service: "frontend", operation: "GET /checkout"},
+				{traceID: "5b8efff798038103d269b633813fc60c", service: "frontend", operation: "reserve"},
+			},
+		},
+		{
+			file: "otlp_traces.jsonl",
+			expected: []loadedSpan{
+				{traceID: "000000000000a001", service: "inventory", operation: "lookup"},
+				{traceID: "000000000000a002", service: "inventory", operation: "update"},
+			},
+		},
+		{
+			file: "jaeger_trace.json",
+			expected: []loadedSpan{
+				{traceID: "0000000000001f00", service: "dispatch", operation: "HTTP GET /dispatch"},
+				{traceID: "0000000000001f00", service: "redis", operation: "FindDriverIDs"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			spans, err := loadSpans(filepath.Join("fixtures", test.file))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, loadedSpans(spans))
+		})
+	}
+}
+
+func TestLoadSpansJaegerTraces(t *testing.T) {
+	spans, err := loadSpans(filepath.Join("fixtures", "jaeger_trace.json"))
+	require.NoError(t, err)
+	require.Len(t, spans, 2)
+	assert.Equal(t, []model.SpanRef{model.NewChildOfRef(spans[0].TraceID, spans[0].SpanID)}, spans[1].References)
+	errorTag, ok := model.KeyValues(spans[1].Tags).FindByKey("error")
+	require.True(t, ok)
+	assert.True(t, errorTag.Bool())
+	hostname, ok := model.KeyValues(spans[0].Process.Tags).FindByKey("hostname")
+	require.True(t, ok)
+	assert.Equal(t, "host-1", hostname.VStr)
+
+	// the traces can also be a list, or a single trace
+	dir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join("fixtures", "jaeger_trace.json"))
+	require.NoError(t, err)
+	for name, content := range map[string]string{
+		"list.json":  `[` + traceJSON(t, data) + `]`,
+		"trace.json": traceJSON(t, data),
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		listSpans, err := loadSpans(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, loadedSpans(spans), loadedSpans(listSpans), name)
+	}
+}
+
+// traceJSON returns the JSON of the trace of the Jaeger JSON export.
+func traceJSON(t *testing.T, export []byte) string {
+	var document struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(export, &document))
+	return string(document.Data[0])
+}
+
+func TestLoadSpansOTLPProto(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("fixtures", "otlp_trace.json"))
+	require.NoError(t, err)
+	traces, err := new(ptrace.JSONUnmarshaler).UnmarshalTraces(data)
+	require.NoError(t, err)
+	data, err = new(ptrace.ProtoMarshaler).MarshalTraces(traces)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "trace.binpb")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	spans, err := loadSpans(path)
+	require.NoError(t, err)
+	jsonSpans, err := loadSpans(filepath.Join("fixtures", "otlp_trace.json"))
+	require.NoError(t, err)
+	assert.Equal(t, loadedSpans(jsonSpans), loadedSpans(spans))
+}
+
+func TestLoadSpansErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{name: "malformed.json", content: `{"resourceSpans": [`, errMsg: "cannot parse JSON"},
+		{name: "unknown.json", content: `{"traces": []}`, errMsg: "the JSON document is neither an OTLP nor a Jaeger export"},
+		{name: "empty.json", content: ``, errMsg: "the file has no spans"},
+		{name: "bad_otlp.json", content: `{"resourceSpans": 1}`, errMsg: "cannot unmarshal OTLP/JSON"},
+		{name: "bad_data.json", content: `{"data": {}}`, errMsg: "cannot parse the Jaeger JSON traces"},
+		{name: "bad_list.json", content: `[1]`, errMsg: "cannot parse the Jaeger JSON traces"},
+		{name: "bad_trace.json", content: `{"spans": 1}`, errMsg: "cannot parse the Jaeger JSON trace"},
+		{name: "bad_span.json", content: `{"traceID": "1", "spans": [{"traceID": "1", "spanID": "x"}]}`, errMsg: "invalid Jaeger JSON trace 1"},
+		{name: "bad.pb", content: `not protobuf`, errMsg: "cannot unmarshal OTLP/Protobuf"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+			_, err := loadSpans(path)
+			require.ErrorContains(t, err, test.errMsg)
+		})
+	}
+
+	_, err := loadSpans(filepath.Join(dir, "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestIsTraceFile(t *testing.T) {
+	assert.True(t, isTraceFile("/exports/trace.json"))
+	assert.True(t, isTraceFile("/exports/traces.JSONL"))
+	assert.True(t, isTraceFile("/exports/traces.pb"))
+	assert.True(t, isTraceFile("/exports/traces.binpb"))
+	assert.False(t, isTraceFile("/exports/notes.txt"))
+	assert.False(t, isTraceFile("/exports/traces.json.tmp"))
+}
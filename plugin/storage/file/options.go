@@ -0,0 +1,57 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	pathsFlag = "file.paths"
+	watchFlag = "file.watch"
+)
+
+// Options stores the configuration of the file storage.
+type Options struct {
+	// Paths are the files and the directories of files of OTLP or Jaeger JSON exports to load.
+	Paths []string `mapstructure:"paths"`
+	// Watch enables loading the files added to the directories of Paths after the startup.
+	Watch bool `mapstructure:"watch"`
+}
+
+// AddFlags adds the flags of the file storage.
+func AddFlags(flagSet *flag.FlagSet) {
+	flagSet.String(
+		pathsFlag,
+		"",
+		"The comma-separated list of files, or directories of files, of OTLP exports (.json, .jsonl in OTLP/JSON, "+
+			".pb, .binpb in OTLP/Protobuf) or of Jaeger JSON exports (.json, as downloaded from the Jaeger UI) to load")
+	flagSet.Bool(
+		watchFlag,
+		true,
+		"Whether to load the files added to the directories of "+pathsFlag+" after the startup")
+}
+
+// InitFromViper initializes the options from viper.
+func (opt *Options) InitFromViper(v *viper.Viper) {
+	opt.Paths = nil
+	for _, path := range strings.Split(v.GetString(pathsFlag), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			opt.Paths = append(opt.Paths, path)
+		}
+	}
+	opt.Watch = v.GetBool(watchFlag)
+}
+
+// Validate returns an error if no files are configured.
+func (opt *Options) Validate() error {
+	if len(opt.Paths) == 0 {
+		return errors.New("the file storage requires at least one path in --" + pathsFlag)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--file.paths=/exports/site-a, /exports/trace.json,",
+		"--file.watch=false",
+	}))
+	opts.InitFromViper(v)
+
+	assert.Equal(t, []string{"/exports/site-a", "/exports/trace.json"}, opts.Paths)
+	assert.False(t, opts.Watch)
+	require.NoError(t, opts.Validate())
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	opts := &Options{}
+	v, _ := config.Viperize(AddFlags)
+	opts.InitFromViper(v)
+
+	assert.Empty(t, opts.Paths)
+	assert.True(t, opts.Watch)
+	require.EqualError(t, opts.Validate(), "the file storage requires at least one path in --file.paths")
+}
@@ -17,6 +17,7 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"net"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
@@ -29,15 +30,21 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
 	"github.com/jaegertracing/jaeger/plugin/storage/grpc/shared"
 )
 
 // Configuration describes the options to customize the storage behavior.
 type Configuration struct {
+	// RemoteServerAddr is the host:port of the remote storage server, a comma-separated list of them
+	// or a DNS SRV name prefixed by "dnssrv+", the calls being balanced across the servers.
 	RemoteServerAddr     string `yaml:"server" mapstructure:"server"`
 	RemoteTLS            tlscfg.Options
 	RemoteConnectTimeout time.Duration `yaml:"connection-timeout" mapstructure:"connection-timeout"`
+	RPCTimeout           time.Duration `yaml:"rpc-timeout" mapstructure:"rpc-timeout"`
+	Retry                RetryConfig
+	HedgeDelay           time.Duration `yaml:"hedge-delay" mapstructure:"hedge-delay"`
 	TenancyOpts          tenancy.Options
 }
 
@@ -45,6 +52,12 @@ type ConfigV2 struct {
 	Tenancy                        tenancy.Options `mapstructure:"multi_tenancy"`
 	configgrpc.ClientConfig        `mapstructure:",squash"`
 	exporterhelper.TimeoutSettings `mapstructure:",squash"`
+	// RPCTimeout bounds the duration of each call to the remote storage, 0 means no bound
+	RPCTimeout time.Duration `mapstructure:"rpc_timeout"`
+	// Retry configures the retries of the calls failing with Unavailable
+	Retry RetryConfig `mapstructure:"retry"`
+	// HedgeDelay is the delay after which the GetTrace calls are hedged, 0 disables hedging
+	HedgeDelay time.Duration `mapstructure:"hedge_delay"`
 }
 
 func (c *Configuration) TranslateToConfigV2() *ConfigV2 {
@@ -56,6 +69,9 @@ func (c *Configuration) TranslateToConfigV2() *ConfigV2 {
 		TimeoutSettings: exporterhelper.TimeoutSettings{
 			Timeout: c.RemoteConnectTimeout,
 		},
+		RPCTimeout: c.RPCTimeout,
+		Retry:      c.Retry,
+		HedgeDelay: c.HedgeDelay,
 	}
 }
 
@@ -67,26 +83,54 @@ type ClientPluginServices struct {
 }
 
 // TODO move this to factory.go
-func (c *ConfigV2) Build(logger *zap.Logger, tracerProvider trace.TracerProvider) (*ClientPluginServices, error) {
+func (c *ConfigV2) Build(logger *zap.Logger, tracerProvider trace.TracerProvider, metricsFactory metrics.Factory) (*ClientPluginServices, error) {
 	telset := component.TelemetrySettings{
 		Logger:         logger,
 		TracerProvider: tracerProvider,
 	}
-	newClientFn := func(opts ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
-		return c.ToClientConn(context.Background(), componenttest.NewNopHost(), telset, opts...)
+	newClientFn := func(target string, opts ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
+		clientConfig := c.ClientConfig
+		clientConfig.Endpoint = target
+		return clientConfig.ToClientConn(context.Background(), componenttest.NewNopHost(), telset, opts...)
 	}
-	return newRemoteStorage(c, telset, newClientFn)
+	return newRemoteStorage(c, telset, metricsFactory, newClientFn)
 }
 
-type newClientFn func(opts ...grpc.DialOption) (*grpc.ClientConn, error)
+type newClientFn func(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
 
-func newRemoteStorage(c *ConfigV2, telset component.TelemetrySettings, newClient newClientFn) (*ClientPluginServices, error) {
+func newRemoteStorage(c *ConfigV2, telset component.TelemetrySettings, metricsFactory metrics.Factory, newClient newClientFn) (*ClientPluginServices, error) {
 	opts := []grpc.DialOption{
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(telset.TracerProvider))),
+		grpc.WithStatsHandler(newConnMetrics(metricsFactory)),
 	}
 	if c.Auth != nil {
 		return nil, fmt.Errorf("authenticator is not supported")
 	}
+	if err := c.Retry.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid remote storage retries: %w", err)
+	}
+
+	target := c.Endpoint
+	balancer := c.BalancerName
+	if endpoints := parseEndpoints(c.Endpoint); endpoints.balanced() {
+		resolverBuilder := &endpointsResolverBuilder{endpoints: endpoints, lookupSRV: net.DefaultResolver.LookupSRV}
+		target = resolverBuilder.target()
+		opts = append(opts, grpc.WithResolvers(resolverBuilder))
+		if balancer == "" {
+			balancer = roundRobinBalancer
+		}
+	}
+	if balancer != roundRobinBalancer && c.HedgeDelay > 0 {
+		telset.Logger.Warn("The hedged GetTrace calls are likely sent to the same remote storage server without round_robin balancing",
+			zap.String("balancer", balancer))
+	}
+	serviceConfig, err := buildServiceConfig(balancer, c.RPCTimeout, c.Retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the remote storage service config: %w", err)
+	}
+	if serviceConfig != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(serviceConfig))
+	}
 
 	tenancyMgr := tenancy.NewManager(&c.Tenancy)
 	if tenancyMgr.Enabled {
@@ -94,11 +138,11 @@ func newRemoteStorage(c *ConfigV2, telset component.TelemetrySettings, newClient
 		opts = append(opts, grpc.WithStreamInterceptor(tenancy.NewClientStreamInterceptor(tenancyMgr)))
 	}
 
-	remoteConn, err := newClient(opts...)
+	remoteConn, err := newClient(target, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("error creating remote storage client: %w", err)
 	}
-	grpcClient := shared.NewGRPCClient(remoteConn)
+	grpcClient := shared.NewGRPCClientWithOptions(remoteConn, shared.GRPCClientOptions{HedgeDelay: c.HedgeDelay})
 	return &ClientPluginServices{
 		PluginServices: shared.PluginServices{
 			Store:               grpcClient,
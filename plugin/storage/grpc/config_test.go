@@ -10,15 +10,17 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"google.golang.org/grpc"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
 )
 
 func TestBuildRemoteNewClientError(t *testing.T) {
 	// this is a silly test to verify handling of error from grpc.NewClient, which cannot be induced via params.
 	c := &ConfigV2{}
-	newClientFn := func(_ ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
+	newClientFn := func(_ string, _ ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
 		return nil, errors.New("test error")
 	}
-	_, err := newRemoteStorage(c, component.TelemetrySettings{}, newClientFn)
+	_, err := newRemoteStorage(c, component.TelemetrySettings{}, metrics.NullFactory, newClientFn)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "error creating remote storage client")
 }
@@ -0,0 +1,80 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/stats"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+type connEndpointKey struct{}
+
+// connMetrics is a gRPC stats handler reporting the number of connections open to each
+// remote storage endpoint, e.g. to alert when a replica is unreachable.
+type connMetrics struct {
+	factory metrics.Factory
+
+	mu sync.Mutex
+	// open counts the open connections by endpoint address
+	open   map[string]int64
+	gauges map[string]metrics.Gauge
+}
+
+func newConnMetrics(factory metrics.Factory) *connMetrics {
+	return &connMetrics{
+		factory: factory,
+		open:    make(map[string]int64),
+		gauges:  make(map[string]metrics.Gauge),
+	}
+}
+
+// TagConn implements stats.Handler, keeping the endpoint of the connection in its context.
+func (*connMetrics) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	if info.RemoteAddr == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, connEndpointKey{}, info.RemoteAddr.String())
+}
+
+// HandleConn implements stats.Handler, counting the connections opened and closed.
+func (m *connMetrics) HandleConn(ctx context.Context, s stats.ConnStats) {
+	endpoint, ok := ctx.Value(connEndpointKey{}).(string)
+	if !ok {
+		return
+	}
+	switch s.(type) {
+	case *stats.ConnBegin:
+		m.update(endpoint, 1)
+	case *stats.ConnEnd:
+		m.update(endpoint, -1)
+	}
+}
+
+func (m *connMetrics) update(endpoint string, delta int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	gauge, ok := m.gauges[endpoint]
+	if !ok {
+		gauge = m.factory.Gauge(metrics.Options{
+			Name: "grpc_storage.connections",
+			Tags: map[string]string{"endpoint": endpoint},
+			Help: "The number of connections open to the remote storage endpoint",
+		})
+		m.gauges[endpoint] = gauge
+	}
+	m.open[endpoint] += delta
+	gauge.Update(m.open[endpoint])
+}
+
+// TagRPC implements stats.Handler.
+func (*connMetrics) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler.
+func (*connMetrics) HandleRPC(context.Context, stats.RPCStats) {}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	// srvEndpointPrefix prefixes the DNS SRV names resolved into the endpoints of the remote storage,
+	// e.g. dnssrv+_grpc._tcp.storage.example.com
+	srvEndpointPrefix = "dnssrv+"
+
+	endpointsScheme = "jaeger-remote-storage"
+)
+
+// lookupSRVFn resolves the DNS SRV records of a name, see net.Resolver.LookupSRV.
+type lookupSRVFn func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+
+// endpoints are the endpoints of the remote storage servers, either a list of host:port
+// or a DNS SRV name resolved into such a list.
+type endpoints struct {
+	addrs   []string
+	srvName string
+}
+
+// parseEndpoints parses a comma-separated list of host:port, or a DNS SRV name prefixed by srvEndpointPrefix.
+func parseEndpoints(endpoint string) endpoints {
+	if srvName, ok := strings.CutPrefix(endpoint, srvEndpointPrefix); ok {
+		return endpoints{srvName: srvName}
+	}
+	var e endpoints
+	for _, addr := range strings.Split(endpoint, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			e.addrs = append(e.addrs, addr)
+		}
+	}
+	return e
+}
+
+// balanced returns whether the calls are balanced across several endpoints.
+func (e endpoints) balanced() bool {
+	return e.srvName != "" || len(e.addrs) > 1
+}
+
+// resolve returns the addresses of the endpoints.
+func (e endpoints) resolve(ctx context.Context, lookupSRV lookupSRVFn) ([]string, error) {
+	if e.srvName == "" {
+		return e.addrs, nil
+	}
+	_, records, err := lookupSRV(ctx, "", "", e.srvName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the SRV records of %s: %w", e.srvName, err)
+	}
+	addrs := make([]string, 0, len(records))
+	for _, record := range records {
+		addrs = append(addrs, net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))))
+	}
+	return addrs, nil
+}
+
+// endpointsResolverBuilder resolves the target of the remote storage connection into the addresses
+// of the endpoints, so that the calls are balanced across them. It is registered with the connection
+// only, not globally.
+type endpointsResolverBuilder struct {
+	endpoints endpoints
+	lookupSRV lookupSRVFn
+}
+
+func (b *endpointsResolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	r := &endpointsResolver{builder: b, cc: cc}
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	return r, nil
+}
+
+func (*endpointsResolverBuilder) Scheme() string {
+	return endpointsScheme
+}
+
+// target returns the target of the connection resolved by the builder.
+func (*endpointsResolverBuilder) target() string {
+	return endpointsScheme + ":///remote-storage"
+}
+
+type endpointsResolver struct {
+	builder *endpointsResolverBuilder
+	cc      resolver.ClientConn
+}
+
+// ResolveNow resolves the endpoints again, which only changes the addresses of the DNS SRV names.
+func (r *endpointsResolver) ResolveNow(resolver.ResolveNowOptions) {
+	addrs, err := r.builder.endpoints.resolve(context.Background(), r.builder.lookupSRV)
+	if err != nil {
+		r.cc.ReportError(err)
+		return
+	}
+	state := resolver.State{Addresses: make([]resolver.Address, len(addrs))}
+	for i, addr := range addrs {
+		state.Addresses[i] = resolver.Address{Addr: addr}
+	}
+	if err := r.cc.UpdateState(state); err != nil {
+		r.cc.ReportError(err)
+	}
+}
+
+func (*endpointsResolver) Close() {}
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/plugin/storage/grpc/shared"
+	dependencyStoreMocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	spanStoreMocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+func TestParseEndpoints(t *testing.T) {
+	assert.Equal(t, endpoints{addrs: []string{"localhost:17271"}}, parseEndpoints("localhost:17271"))
+	assert.False(t, parseEndpoints("localhost:17271").balanced())
+
+	e := parseEndpoints("storage-0:17271, storage-1:17271,,storage-2:17271")
+	assert.Equal(t, endpoints{addrs: []string{"storage-0:17271", "storage-1:17271", "storage-2:17271"}}, e)
+	assert.True(t, e.balanced())
+
+	e = parseEndpoints("dnssrv+_grpc._tcp.storage.example.com")
+	assert.Equal(t, endpoints{srvName: "_grpc._tcp.storage.example.com"}, e)
+	assert.True(t, e.balanced())
+}
+
+func TestResolveSRVEndpoints(t *testing.T) {
+	lookupSRV := func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		assert.Empty(t, service)
+		assert.Empty(t, proto)
+		if name != "_grpc._tcp.storage.example.com" {
+			return "", nil, errors.New("no such host")
+		}
+		return "", []*net.SRV{
+			{Target: "storage-0.example.com.", Port: 17271},
+			{Target: "storage-1.example.com.", Port: 17272},
+		}, nil
+	}
+	addrs, err := parseEndpoints("dnssrv+_grpc._tcp.storage.example.com").resolve(context.Background(), lookupSRV)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"storage-0.example.com:17271", "storage-1.example.com:17272"}, addrs)
+
+	_, err = parseEndpoints("dnssrv+_grpc._tcp.unknown.example.com").resolve(context.Background(), lookupSRV)
+	require.ErrorContains(t, err, "failed to resolve the SRV records of _grpc._tcp.unknown.example.com")
+}
+
+type fakeStorageServer struct {
+	server *grpc.Server
+	addr   string
+}
+
+// startFakeStorageServer starts a remote storage server returning the name as the only service.
+func startFakeStorageServer(t *testing.T, name string) *fakeStorageServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	reader := new(spanStoreMocks.Reader)
+	reader.On("GetServices", mock.Anything).Return([]string{name}, nil)
+	handler := shared.NewGRPCHandlerWithPlugins(&store{
+		reader: reader,
+		writer: new(spanStoreMocks.Writer),
+		deps:   new(dependencyStoreMocks.Reader),
+	}, nil, nil)
+	s := grpc.NewServer()
+	require.NoError(t, handler.Register(s, health.NewServer()))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return &fakeStorageServer{server: s, addr: lis.Addr().String()}
+}
+
+func TestRemoteStorageBalancingAndFailover(t *testing.T) {
+	first := startFakeStorageServer(t, "first")
+	second := startFakeStorageServer(t, "second")
+
+	metricsFactory := metricstest.NewFactory(0)
+	defer metricsFactory.Stop()
+	f, err := NewFactoryWithConfig(ConfigV2{
+		ClientConfig: configgrpc.ClientConfig{
+			Endpoint:   first.addr + "," + second.addr,
+			TLSSetting: configtls.ClientConfig{Insecure: true},
+		},
+		RPCTimeout: 5 * time.Second,
+		Retry:      RetryConfig{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 100 * time.Millisecond},
+	}, metricsFactory, zap.NewNop())
+	require.NoError(t, err)
+	defer f.Close()
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+
+	// the calls are balanced across both servers once connected to them
+	seen := make(map[string]bool)
+	assert.Eventually(t, func() bool {
+		services, err := reader.GetServices(context.Background())
+		require.NoError(t, err)
+		seen[services[0]] = true
+		return seen["first"] && seen["second"]
+	}, 5*time.Second, time.Millisecond)
+	metricsFactory.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "grpc_storage.connections", Tags: map[string]string{"endpoint": first.addr}, Value: 1},
+		metricstest.ExpectedMetric{Name: "grpc_storage.connections", Tags: map[string]string{"endpoint": second.addr}, Value: 1},
+	)
+
+	// the calls fail over to the first server once the second one is killed
+	second.server.Stop()
+	for i := 0; i < 20; i++ {
+		services, err := reader.GetServices(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first"}, services)
+	}
+	assert.Eventually(t, func() bool {
+		_, gauges := metricsFactory.Snapshot()
+		return gauges["grpc_storage.connections|endpoint="+second.addr] == 0
+	}, 5*time.Second, time.Millisecond)
+	metricsFactory.AssertGaugeMetrics(t,
+		metricstest.ExpectedMetric{Name: "grpc_storage.connections", Tags: map[string]string{"endpoint": first.addr}, Value: 1},
+	)
+}
+
+func TestRemoteStorageInvalidRetries(t *testing.T) {
+	_, err := NewFactoryWithConfig(ConfigV2{
+		ClientConfig: configgrpc.ClientConfig{Endpoint: "localhost:17271"},
+		Retry:        RetryConfig{MaxAttempts: 10},
+	}, metricstest.NewFactory(0), zap.NewNop())
+	require.ErrorContains(t, err, "invalid remote storage retries")
+}
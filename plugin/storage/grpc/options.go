@@ -29,6 +29,12 @@ const (
 	remotePrefix             = "grpc-storage"
 	remoteServer             = remotePrefix + ".server"
 	remoteConnectionTimeout  = remotePrefix + ".connection-timeout"
+	remoteRPCTimeout         = remotePrefix + ".rpc-timeout"
+	remoteRetryMaxAttempts   = remotePrefix + ".retry.max-attempts"
+	remoteRetryInitial       = remotePrefix + ".retry.initial-backoff"
+	remoteRetryMax           = remotePrefix + ".retry.max-backoff"
+	remoteRetryWrites        = remotePrefix + ".retry.idempotent-writes"
+	remoteHedgeDelay         = remotePrefix + ".hedging.delay"
 	defaultConnectionTimeout = time.Duration(5 * time.Second)
 )
 
@@ -42,8 +48,17 @@ func tlsFlagsConfig() tlscfg.ClientFlagsConfig {
 func v1AddFlags(flagSet *flag.FlagSet) {
 	tlsFlagsConfig().AddFlags(flagSet)
 
-	flagSet.String(remoteServer, "", "The remote storage gRPC server address as host:port")
+	flagSet.String(remoteServer, "", "The remote storage gRPC server address as host:port, a comma-separated list of them, "+
+		"or a DNS SRV name prefixed by "+srvEndpointPrefix+", e.g. "+srvEndpointPrefix+"_grpc._tcp.storage.example.com, "+
+		"the calls being balanced round-robin across the servers; with TLS, the servers are verified against "+remotePrefix+".tls.server-name")
 	flagSet.Duration(remoteConnectionTimeout, defaultConnectionTimeout, "The remote storage gRPC server connection timeout")
+	flagSet.Duration(remoteRPCTimeout, 0, "The timeout of each call to the remote storage, including the retries; set to 0s for no timeout")
+	flagSet.Int(remoteRetryMaxAttempts, 3, "The number of attempts, up to 5, of the reads failing with Unavailable, e.g. when a remote storage server restarts; set to 1 to disable the retries")
+	flagSet.Duration(remoteRetryInitial, 100*time.Millisecond, "The maximum delay before the first retry, doubled at each retry up to "+remoteRetryMax)
+	flagSet.Duration(remoteRetryMax, time.Second, "The maximum delay before a retry")
+	flagSet.Bool(remoteRetryWrites, false, "Retry the writes of spans too, only if the remote storage handles the same span written twice")
+	flagSet.Duration(remoteHedgeDelay, 0, "Send a second GetTrace call, to another server, when the first one has not completed after this delay, "+
+		"returning the first trace received; set to 0s to disable hedging")
 }
 
 func v1InitFromViper(cfg *Configuration, v *viper.Viper) error {
@@ -54,6 +69,17 @@ func v1InitFromViper(cfg *Configuration, v *viper.Viper) error {
 		return fmt.Errorf("failed to parse gRPC storage TLS options: %w", err)
 	}
 	cfg.RemoteConnectTimeout = v.GetDuration(remoteConnectionTimeout)
+	cfg.RPCTimeout = v.GetDuration(remoteRPCTimeout)
+	cfg.Retry = RetryConfig{
+		MaxAttempts:      v.GetInt(remoteRetryMaxAttempts),
+		InitialBackoff:   v.GetDuration(remoteRetryInitial),
+		MaxBackoff:       v.GetDuration(remoteRetryMax),
+		IdempotentWrites: v.GetBool(remoteRetryWrites),
+	}
+	if err := cfg.Retry.Validate(); err != nil {
+		return fmt.Errorf("invalid gRPC storage retry options: %w", err)
+	}
+	cfg.HedgeDelay = v.GetDuration(remoteHedgeDelay)
 	cfg.TenancyOpts = tenancy.InitFromViper(v)
 	return nil
 }
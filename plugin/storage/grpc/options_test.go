@@ -72,6 +72,47 @@ func TestRemoteOptionsNoTLSWithFlags(t *testing.T) {
 	assert.Equal(t, 60*time.Second, cfg.RemoteConnectTimeout)
 }
 
+func TestRemoteOptionsBalancingFlags(t *testing.T) {
+	v, command := config.Viperize(v1AddFlags)
+	err := command.ParseFlags([]string{})
+	require.NoError(t, err)
+	var cfg Configuration
+	require.NoError(t, v1InitFromViper(&cfg, v))
+	assert.Equal(t, RetryConfig{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}, cfg.Retry)
+	assert.Zero(t, cfg.RPCTimeout)
+	assert.Zero(t, cfg.HedgeDelay)
+
+	err = command.ParseFlags([]string{
+		"--grpc-storage.server=storage-0:17271,storage-1:17271",
+		"--grpc-storage.rpc-timeout=10s",
+		"--grpc-storage.retry.max-attempts=5",
+		"--grpc-storage.retry.initial-backoff=50ms",
+		"--grpc-storage.retry.max-backoff=2s",
+		"--grpc-storage.retry.idempotent-writes=true",
+		"--grpc-storage.hedging.delay=300ms",
+	})
+	require.NoError(t, err)
+	require.NoError(t, v1InitFromViper(&cfg, v))
+	assert.Equal(t, "storage-0:17271,storage-1:17271", cfg.RemoteServerAddr)
+	assert.Equal(t, 10*time.Second, cfg.RPCTimeout)
+	assert.Equal(t, RetryConfig{
+		MaxAttempts:      5,
+		InitialBackoff:   50 * time.Millisecond,
+		MaxBackoff:       2 * time.Second,
+		IdempotentWrites: true,
+	}, cfg.Retry)
+	assert.Equal(t, 300*time.Millisecond, cfg.HedgeDelay)
+
+	cfgV2 := cfg.TranslateToConfigV2()
+	assert.Equal(t, cfg.Retry, cfgV2.Retry)
+	assert.Equal(t, cfg.RPCTimeout, cfgV2.RPCTimeout)
+	assert.Equal(t, cfg.HedgeDelay, cfgV2.HedgeDelay)
+
+	err = command.ParseFlags([]string{"--grpc-storage.retry.max-attempts=8"})
+	require.NoError(t, err)
+	require.ErrorContains(t, v1InitFromViper(&cfg, v), "invalid gRPC storage retry options")
+}
+
 func TestFailedTLSFlags(t *testing.T) {
 	v, command := config.Viperize(v1AddFlags)
 	err := command.ParseFlags([]string{
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRetryAttempts is the maximum number of attempts of a call supported by gRPC
+	maxRetryAttempts = 5
+
+	roundRobinBalancer = "round_robin"
+
+	readerServiceName        = "jaeger.storage.v1.SpanReaderPlugin"
+	archiveReaderServiceName = "jaeger.storage.v1.ArchiveSpanReaderPlugin"
+	depsServiceName          = "jaeger.storage.v1.DependenciesReaderPlugin"
+	writerServiceName        = "jaeger.storage.v1.SpanWriterPlugin"
+)
+
+// RetryConfig configures the retries of the calls failing with Unavailable, e.g. when
+// a remote storage server restarts. The reads are always retried, the writes only if idempotent.
+type RetryConfig struct {
+	// MaxAttempts is the number of attempts of a call, including the first one, up to 5;
+	// 0 or 1 disables the retries
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// InitialBackoff is the maximum delay before the first retry, doubled at each retry up to MaxBackoff
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	// MaxBackoff caps the delay before a retry
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+	// IdempotentWrites tells that the storage server handles the same span written twice,
+	// so that the writes can be retried
+	IdempotentWrites bool `mapstructure:"idempotent_writes"`
+}
+
+// Validate returns an error if the retry configuration is invalid.
+func (c RetryConfig) Validate() error {
+	if c.MaxAttempts < 0 || c.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("the maximum number of attempts must be between 0 and %d: %d", maxRetryAttempts, c.MaxAttempts)
+	}
+	if c.enabled() && (c.InitialBackoff <= 0 || c.MaxBackoff < c.InitialBackoff) {
+		return fmt.Errorf("the retry backoffs must be positive, the initial backoff %v not above the maximum %v",
+			c.InitialBackoff, c.MaxBackoff)
+	}
+	return nil
+}
+
+func (c RetryConfig) enabled() bool {
+	return c.MaxAttempts > 1
+}
+
+// The gRPC service config, see https://github.com/grpc/grpc/blob/master/doc/service_config.md
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig,omitempty"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	Timeout     string       `json:"timeout,omitempty"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type methodName struct {
+	Service string `json:"service,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// buildServiceConfig returns the gRPC service config balancing the calls with the balancer, if not empty,
+// with the timeout of the calls, if positive, and the retries, or an empty string if there is nothing to configure.
+func buildServiceConfig(balancer string, timeout time.Duration, retry RetryConfig) (string, error) {
+	var config serviceConfig
+	if balancer != "" {
+		config.LoadBalancingConfig = []map[string]struct{}{{balancer: {}}}
+	}
+	var policy *retryPolicy
+	if retry.enabled() {
+		policy = &retryPolicy{
+			MaxAttempts:          retry.MaxAttempts,
+			InitialBackoff:       durationString(retry.InitialBackoff),
+			MaxBackoff:           durationString(retry.MaxBackoff),
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+	}
+	var timeoutString string
+	if timeout > 0 {
+		timeoutString = durationString(timeout)
+	}
+	if policy != nil {
+		reads := methodConfig{
+			Name:        []methodName{{Service: readerServiceName}, {Service: archiveReaderServiceName}, {Service: depsServiceName}},
+			Timeout:     timeoutString,
+			RetryPolicy: policy,
+		}
+		config.MethodConfig = append(config.MethodConfig, reads)
+		if retry.IdempotentWrites {
+			writes := methodConfig{
+				Name:        []methodName{{Service: writerServiceName}},
+				Timeout:     timeoutString,
+				RetryPolicy: policy,
+			}
+			config.MethodConfig = append(config.MethodConfig, writes)
+		}
+	}
+	if timeoutString != "" {
+		// the empty name matches all the methods not configured above
+		config.MethodConfig = append(config.MethodConfig, methodConfig{
+			Name:    []methodName{{}},
+			Timeout: timeoutString,
+		})
+	}
+	if config.LoadBalancingConfig == nil && config.MethodConfig == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// durationString formats the duration in seconds, as expected by the service config.
+func durationString(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildServiceConfig(t *testing.T) {
+	retry := RetryConfig{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	retryPolicy := `"retryPolicy":{"maxAttempts":3,"initialBackoff":"0.1s","maxBackoff":"1s","backoffMultiplier":2,"retryableStatusCodes":["UNAVAILABLE"]}`
+	reads := `{"name":[{"service":"jaeger.storage.v1.SpanReaderPlugin"},{"service":"jaeger.storage.v1.ArchiveSpanReaderPlugin"},{"service":"jaeger.storage.v1.DependenciesReaderPlugin"}],`
+	tests := []struct {
+		name     string
+		balancer string
+		timeout  time.Duration
+		retry    RetryConfig
+		expected string
+	}{
+		{
+			name: "nothing configured",
+		},
+		{
+			name:     "retries disabled",
+			retry:    RetryConfig{MaxAttempts: 1, InitialBackoff: time.Second, MaxBackoff: time.Second},
+			expected: "",
+		},
+		{
+			name:     "balancer",
+			balancer: "round_robin",
+			expected: `{"loadBalancingConfig":[{"round_robin":{}}]}`,
+		},
+		{
+			name:     "timeout",
+			timeout:  2500 * time.Millisecond,
+			expected: `{"methodConfig":[{"name":[{}],"timeout":"2.5s"}]}`,
+		},
+		{
+			name:     "retried reads",
+			balancer: "round_robin",
+			timeout:  5 * time.Second,
+			retry:    retry,
+			expected: `{"loadBalancingConfig":[{"round_robin":{}}],"methodConfig":[` +
+				reads + `"timeout":"5s",` + retryPolicy + `},` +
+				`{"name":[{}],"timeout":"5s"}]}`,
+		},
+		{
+			name: "retried writes",
+			retry: RetryConfig{
+				MaxAttempts:      3,
+				InitialBackoff:   100 * time.Millisecond,
+				MaxBackoff:       time.Second,
+				IdempotentWrites: true,
+			},
+			expected: `{"methodConfig":[` +
+				reads + retryPolicy + `},` +
+				`{"name":[{"service":"jaeger.storage.v1.SpanWriterPlugin"}],` + retryPolicy + `}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := buildServiceConfig(test.balancer, test.timeout, test.retry)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, config)
+		})
+	}
+}
+
+func TestRetryConfigValidate(t *testing.T) {
+	require.NoError(t, RetryConfig{}.Validate())
+	require.NoError(t, RetryConfig{MaxAttempts: 5, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}.Validate())
+	require.EqualError(t, RetryConfig{MaxAttempts: 6}.Validate(), "the maximum number of attempts must be between 0 and 5: 6")
+	require.EqualError(t, RetryConfig{MaxAttempts: 2, InitialBackoff: time.Second, MaxBackoff: time.Millisecond}.Validate(),
+		"the retry backoffs must be positive, the initial backoff 1s not above the maximum 1ms")
+}
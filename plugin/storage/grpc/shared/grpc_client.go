@@ -55,10 +55,24 @@ type GRPCClient struct {
 	capabilitiesClient  storage_v1.PluginCapabilitiesClient
 	depsReaderClient    storage_v1.DependenciesReaderPluginClient
 	streamWriterClient  storage_v1.StreamingSpanWriterPluginClient
+	hedgeDelay          time.Duration
+}
+
+// GRPCClientOptions are the optional settings of GRPCClient.
+type GRPCClientOptions struct {
+	// HedgeDelay is the delay after which a second, hedged, GetTrace call is sent if the first one
+	// has not completed, e.g. because its server is slow, the first result being returned; 0 disables hedging.
+	HedgeDelay time.Duration
 }
 
 func NewGRPCClient(c *grpc.ClientConn) *GRPCClient {
+	return NewGRPCClientWithOptions(c, GRPCClientOptions{})
+}
+
+// NewGRPCClientWithOptions creates a GRPCClient with the options.
+func NewGRPCClientWithOptions(c *grpc.ClientConn, options GRPCClientOptions) *GRPCClient {
 	return &GRPCClient{
+		hedgeDelay:          options.HedgeDelay,
 		readerClient:        storage_v1.NewSpanReaderPluginClient(c),
 		writerClient:        storage_v1.NewSpanWriterPluginClient(c),
 		archiveReaderClient: storage_v1.NewArchiveSpanReaderPluginClient(c),
@@ -128,6 +142,48 @@ func (c *GRPCClient) ArchiveSpanWriter() spanstore.Writer {
 
 // GetTrace takes a traceID and returns a Trace associated with that traceID
 func (c *GRPCClient) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	if c.hedgeDelay > 0 {
+		return c.getTraceHedged(ctx, traceID)
+	}
+	return c.getTrace(ctx, traceID)
+}
+
+// getTraceHedged sends a second GetTrace call if the first one has not completed after the hedge delay,
+// which the load balancer likely sends to another server, and returns the first trace received.
+// The call still pending is canceled.
+func (c *GRPCClient) getTraceHedged(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		trace *model.Trace
+		err   error
+	}
+	results := make(chan result, 2)
+	call := func() {
+		trace, err := c.getTrace(ctx, traceID)
+		results <- result{trace: trace, err: err}
+	}
+	go call()
+	pending := 1
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+	hedge := timer.C
+	for {
+		select {
+		case <-hedge:
+			hedge = nil
+			pending++
+			go call()
+		case r := <-results:
+			pending--
+			if r.err == nil || errors.Is(r.err, spanstore.ErrTraceNotFound) || pending == 0 {
+				return r.trace, r.err
+			}
+		}
+	}
+}
+
+func (c *GRPCClient) getTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
 	stream, err := c.readerClient.GetTrace(upgradeContext(ctx), &storage_v1.GetTraceRequest{
 		TraceID: traceID,
 	})
@@ -208,6 +208,51 @@ func TestGRPCClientGetTrace(t *testing.T) {
 	})
 }
 
+func TestGRPCClientGetTraceHedged(t *testing.T) {
+	withGRPCClient(func(r *grpcClientTest) {
+		r.client.hedgeDelay = 10 * time.Millisecond
+		traceClient := new(grpcMocks.SpanReaderPlugin_GetTraceClient)
+		traceClient.On("Recv").Return(&storage_v1.SpansResponseChunk{
+			Spans: mockTraceSpans,
+		}, nil).Once()
+		traceClient.On("Recv").Return(nil, io.EOF)
+		// the first call hangs until canceled, e.g. on a slow server
+		canceled := make(chan struct{})
+		r.spanReader.On("GetTrace", mock.Anything, &storage_v1.GetTraceRequest{
+			TraceID: mockTraceID,
+		}).Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+			close(canceled)
+		}).Return(nil, status.Error(codes.Canceled, "canceled")).Once()
+		r.spanReader.On("GetTrace", mock.Anything, &storage_v1.GetTraceRequest{
+			TraceID: mockTraceID,
+		}).Return(traceClient, nil).Once()
+
+		s, err := r.client.GetTrace(context.Background(), mockTraceID)
+		require.NoError(t, err)
+		assert.Len(t, s.Spans, len(mockTraceSpans))
+		<-canceled
+		r.spanReader.AssertExpectations(t)
+	})
+}
+
+func TestGRPCClientGetTraceNotHedged(t *testing.T) {
+	withGRPCClient(func(r *grpcClientTest) {
+		r.client.hedgeDelay = time.Hour
+		r.spanReader.On("GetTrace", mock.Anything, &storage_v1.GetTraceRequest{
+			TraceID: mockTraceID,
+		}).Return(nil, status.Errorf(codes.NotFound, "")).Once()
+
+		// the calls completed before the hedge delay are not hedged, even when failing
+		_, err := r.client.GetTrace(context.Background(), mockTraceID)
+		require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+		r.spanReader.On("GetTrace", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Internal, "failed")).Once()
+		_, err = r.client.GetTrace(context.Background(), mockTraceID)
+		require.ErrorContains(t, err, "failed")
+		r.spanReader.AssertNumberOfCalls(t, "GetTrace", 2)
+	})
+}
+
 func TestGRPCClientGetTrace_StreamError(t *testing.T) {
 	withGRPCClient(func(r *grpcClientTest) {
 		traceClient := new(grpcMocks.SpanReaderPlugin_GetTraceClient)
@@ -64,6 +64,7 @@ func TestBadgerStorage(t *testing.T) {
 
 			// TODO: remove this badger supports returning spanKind from GetOperations
 			GetOperationsMissingSpanKind: true,
+			UnsupportedCapabilities:      []Capability{CapabilityOperationKind, CapabilitySingleSpanTags},
 		},
 	}
 	s.CleanUp = s.cleanUp
@@ -17,12 +17,16 @@ package integration
 
 import (
 	"context"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/plugin/storage/cassandra"
@@ -39,7 +43,8 @@ func newCassandraStorageIntegration() *CassandraStorageIntegration {
 		StorageIntegration: StorageIntegration{
 			GetDependenciesReturnsSource: true,
 
-			SkipList: CassandraSkippedTests,
+			SkipList:                CassandraSkippedTests,
+			UnsupportedCapabilities: CassandraUnsupportedCapabilities,
 		},
 	}
 	s.CleanUp = s.cleanUp
@@ -102,9 +107,69 @@ func (s *CassandraStorageIntegration) initializeDependencyReaderAndWriter(t *tes
 	}
 }
 
+// testDependenciesAcrossFlushes verifies that the call counts of the links are the same whether
+// they are written at once, or spread across several flushes.
+func (s *CassandraStorageIntegration) testDependenciesAcrossFlushes(t *testing.T) {
+	if s.DependencyReader == nil || s.DependencyWriter == nil {
+		t.Skip("Skipping the test because the dependency reader or writer is nil")
+	}
+	link := func(parent, child string, callCount uint64) model.DependencyLink {
+		return model.DependencyLink{Parent: parent, Child: child, CallCount: callCount, Source: model.JaegerDependencyLinkSource}
+	}
+	expected := []model.DependencyLink{link("frontend", "backend", 6), link("backend", "db", 3)}
+	flushPatterns := []struct {
+		name    string
+		flushes [][]model.DependencyLink
+	}{
+		{
+			name:    "single flush",
+			flushes: [][]model.DependencyLink{expected},
+		},
+		{
+			name: "frequent flushes",
+			flushes: [][]model.DependencyLink{
+				{link("frontend", "backend", 1), link("backend", "db", 1)},
+				{link("frontend", "backend", 2)},
+				{},
+				{link("frontend", "backend", 3), link("backend", "db", 2)},
+			},
+		},
+		{
+			name: "flush per call",
+			flushes: [][]model.DependencyLink{
+				{link("frontend", "backend", 1)}, {link("frontend", "backend", 1)}, {link("frontend", "backend", 1)},
+				{link("frontend", "backend", 1)}, {link("frontend", "backend", 1)}, {link("frontend", "backend", 1)},
+				{link("backend", "db", 1)}, {link("backend", "db", 1)}, {link("backend", "db", 1)},
+			},
+		},
+	}
+	for _, pattern := range flushPatterns {
+		t.Run(pattern.name, func(t *testing.T) {
+			s.cleanUp(t)
+			defer s.cleanUp(t)
+			for _, flush := range pattern.flushes {
+				require.NoError(t, s.DependencyWriter.WriteDependencies(time.Now(), flush))
+			}
+
+			var actual []model.DependencyLink
+			found := s.waitForCondition(t, func(t *testing.T) bool {
+				var err error
+				actual, err = s.DependencyReader.GetDependencies(context.Background(), time.Now(), 5*time.Minute)
+				require.NoError(t, err)
+				sort.Slice(actual, func(i, j int) bool {
+					return actual[i].Parent > actual[j].Parent
+				})
+				return assert.ObjectsAreEqualValues(expected, actual)
+			})
+			assert.True(t, found, "expected %v, got %v", expected, actual)
+		})
+	}
+}
+
 func TestCassandraStorage(t *testing.T) {
 	SkipUnlessEnv(t, "cassandra")
 	s := newCassandraStorageIntegration()
 	s.initializeCassandra(t)
 	s.RunAll(t)
+	t.Run("DependenciesAcrossFlushes", s.testDependenciesAcrossFlushes)
 }
@@ -0,0 +1,65 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/storage/clickhouse"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+)
+
+type ClickHouseStorageIntegration struct {
+	StorageIntegration
+	factory *clickhouse.Factory
+}
+
+func (s *ClickHouseStorageIntegration) initialize(t *testing.T) {
+	logger := zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller()))
+	f := clickhouse.NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--clickhouse.create-schema=true",
+		"--clickhouse.flush-interval=100ms",
+	}))
+	f.InitFromViper(v, logger)
+	require.NoError(t, f.Initialize(metrics.NullFactory, logger))
+	s.factory = f
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+
+	var err error
+	s.SpanWriter, err = f.CreateSpanWriter()
+	require.NoError(t, err)
+	s.SpanReader, err = f.CreateSpanReader()
+	require.NoError(t, err)
+	s.DependencyReader, err = f.CreateDependencyReader()
+	require.NoError(t, err)
+	s.DependencyWriter = s.DependencyReader.(dependencystore.Writer)
+}
+
+func (s *ClickHouseStorageIntegration) cleanUp(t *testing.T) {
+	require.NoError(t, s.factory.Purge(context.Background()))
+}
+
+func TestClickHouseStorage(t *testing.T) {
+	SkipUnlessEnv(t, "clickhouse")
+	s := &ClickHouseStorageIntegration{
+		StorageIntegration: StorageIntegration{
+			GetDependenciesReturnsSource: true,
+			SkipArchiveTest:              true,
+		},
+	}
+	s.CleanUp = s.cleanUp
+	s.initialize(t)
+	s.RunAll(t)
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+const conformanceFixturesDir = "fixtures/conformance"
+
+// Capability is a feature of the span storage exercised by some conformance fixtures.
+// A backend lists the capabilities it does not support in StorageIntegration.UnsupportedCapabilities,
+// and the fixtures requiring them are skipped with the reason reported.
+type Capability string
+
+const (
+	// CapabilityTagScoping is the search by the tags of the spans, of their process and of their logs.
+	CapabilityTagScoping Capability = "tag_scoping"
+	// CapabilitySingleSpanTags is the matching of all the tags of a search by a single span,
+	// rather than by different spans of the same trace.
+	CapabilitySingleSpanTags Capability = "single_span_tags"
+	// CapabilityDurationRange is the search by a range of span durations.
+	CapabilityDurationRange Capability = "duration_range"
+	// CapabilityOperationKind is the span kind of the operations, and the filtering of operations by span kind.
+	CapabilityOperationKind Capability = "operation_kind"
+	// CapabilityUnicode is the support of non-ASCII service names, operation names and tags.
+	CapabilityUnicode Capability = "unicode"
+)
+
+var knownCapabilities = map[Capability]struct{}{
+	CapabilityTagScoping:     {},
+	CapabilitySingleSpanTags: {},
+	CapabilityDurationRange:  {},
+	CapabilityOperationKind:  {},
+	CapabilityUnicode:        {},
+}
+
+// ConformanceFixture is a declarative test of the span storage, under ./fixtures/conformance/*.json.
+// The traces of all the fixtures are written before their queries run, so each fixture
+// uses its own service names. The capabilities of a fixture apply to all its queries.
+type ConformanceFixture struct {
+	Caption       string
+	Capabilities  []Capability
+	Traces        []json.RawMessage
+	FindTraces    []FindTracesCase
+	GetOperations []GetOperationsCase
+}
+
+// FindTracesCase is a search with the IDs of the traces it must find, in hexadecimal.
+type FindTracesCase struct {
+	Caption          string
+	Capabilities     []Capability
+	Query            *spanstore.TraceQueryParameters
+	ExpectedTraceIDs []string
+}
+
+// GetOperationsCase is a query of operations with the operations it must return, in any order.
+// The span kinds of the expected operations are ignored for the backends without CapabilityOperationKind.
+type GetOperationsCase struct {
+	Caption            string
+	Capabilities       []Capability
+	Query              spanstore.OperationQueryParameters
+	ExpectedOperations []spanstore.Operation
+}
+
+// LoadConformanceFixtures loads and parses the conformance fixtures.
+func LoadConformanceFixtures(t *testing.T) []*ConformanceFixture {
+	files, err := fs.Glob(fixtures, path.Join(conformanceFixturesDir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no conformance fixtures found")
+	result := make([]*ConformanceFixture, 0, len(files))
+	for _, file := range files {
+		var fixture ConformanceFixture
+		loadAndParseJSON(t, file, &fixture)
+		requireKnownCapabilities(t, file, fixture.Capabilities)
+		for _, c := range fixture.FindTraces {
+			requireKnownCapabilities(t, file, c.Capabilities)
+		}
+		for _, c := range fixture.GetOperations {
+			requireKnownCapabilities(t, file, c.Capabilities)
+		}
+		result = append(result, &fixture)
+	}
+	return result
+}
+
+func requireKnownCapabilities(t *testing.T, source string, capabilities []Capability) {
+	for _, capability := range capabilities {
+		_, ok := knownCapabilities[capability]
+		require.True(t, ok, "unknown capability %q in %s", capability, source)
+	}
+}
+
+// traces parses the traces of the fixture, in the JSONPB format of the trace fixtures.
+func (f *ConformanceFixture) traces(t *testing.T) []*model.Trace {
+	traces := make([]*model.Trace, len(f.Traces))
+	for i, raw := range f.Traces {
+		traces[i] = new(model.Trace)
+		err := jsonpb.Unmarshal(bytes.NewReader(raw), traces[i])
+		require.NoError(t, err, "Not expecting error when unmarshaling trace %d of fixture %s", i, f.Caption)
+	}
+	return traces
+}
+
+// skipIfUnsupported skips the test if the backend does not support one of the capabilities, reporting which one.
+func (s *StorageIntegration) skipIfUnsupported(t *testing.T, capabilities ...[]Capability) {
+	for _, list := range capabilities {
+		for _, capability := range list {
+			if s.supports(capability) {
+				continue
+			}
+			t.Skipf("Skipping conformance case because the backend does not support the %q capability", capability)
+		}
+	}
+}
+
+func (s *StorageIntegration) supports(capability Capability) bool {
+	for _, unsupported := range s.UnsupportedCapabilities {
+		if unsupported == capability {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *StorageIntegration) testConformance(t *testing.T) {
+	s.skipIfNeeded(t)
+	defer s.cleanUp(t)
+
+	requireKnownCapabilities(t, "UnsupportedCapabilities", s.UnsupportedCapabilities)
+	conformanceFixtures := LoadConformanceFixtures(t)
+	for _, fixture := range conformanceFixtures {
+		for _, trace := range fixture.traces(t) {
+			s.writeTrace(t, trace)
+		}
+	}
+	for _, fixture := range conformanceFixtures {
+		t.Run(fixture.Caption, func(t *testing.T) {
+			for _, testCase := range fixture.FindTraces {
+				t.Run(testCase.Caption, func(t *testing.T) {
+					s.skipIfNeeded(t)
+					s.skipIfUnsupported(t, fixture.Capabilities, testCase.Capabilities)
+					s.testConformanceFindTraces(t, testCase)
+				})
+			}
+			for _, testCase := range fixture.GetOperations {
+				t.Run(testCase.Caption, func(t *testing.T) {
+					s.skipIfNeeded(t)
+					s.skipIfUnsupported(t, fixture.Capabilities, testCase.Capabilities)
+					s.testConformanceGetOperations(t, testCase)
+				})
+			}
+		})
+	}
+}
+
+func (s *StorageIntegration) testConformanceFindTraces(t *testing.T, testCase FindTracesCase) {
+	expected := make([]string, 0, len(testCase.ExpectedTraceIDs))
+	for _, id := range testCase.ExpectedTraceIDs {
+		traceID, err := model.TraceIDFromString(id)
+		require.NoError(t, err, "invalid expected trace ID %q", id)
+		expected = append(expected, traceID.String())
+	}
+	sort.Strings(expected)
+
+	var actual []string
+	found := s.waitForCondition(t, func(t *testing.T) bool {
+		traces, err := s.SpanReader.FindTraces(context.Background(), testCase.Query)
+		require.NoError(t, err)
+		actual = make([]string, 0, len(traces))
+		for _, trace := range traces {
+			require.NotEmpty(t, trace.Spans, "the backend returned a trace without spans")
+			actual = append(actual, trace.Spans[0].TraceID.String())
+		}
+		sort.Strings(actual)
+		return assert.ObjectsAreEqual(expected, actual)
+	})
+	if !assert.True(t, found) {
+		t.Log("\t Expected trace IDs:", expected)
+		t.Log("\t Actual trace IDs  :", actual)
+	}
+}
+
+func (s *StorageIntegration) testConformanceGetOperations(t *testing.T, testCase GetOperationsCase) {
+	expected := make([]spanstore.Operation, len(testCase.ExpectedOperations))
+	copy(expected, testCase.ExpectedOperations)
+	if !s.supports(CapabilityOperationKind) {
+		for i := range expected {
+			expected[i].SpanKind = ""
+		}
+	}
+	sortOperations(expected)
+
+	var actual []spanstore.Operation
+	found := s.waitForCondition(t, func(t *testing.T) bool {
+		var err error
+		actual, err = s.SpanReader.GetOperations(context.Background(), testCase.Query)
+		require.NoError(t, err)
+		if actual == nil {
+			actual = []spanstore.Operation{}
+		}
+		sortOperations(actual)
+		return assert.ObjectsAreEqualValues(expected, actual)
+	})
+	if !assert.True(t, found) {
+		t.Log("\t Expected operations:", expected)
+		t.Log("\t Actual operations  :", actual)
+	}
+}
+
+func sortOperations(operations []spanstore.Operation) {
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Name != operations[j].Name {
+			return operations[i].Name < operations[j].Name
+		}
+		return operations[i].SpanKind < operations[j].SpanKind
+	})
+}
@@ -20,8 +20,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
 	"testing"
 	"time"
 
@@ -32,10 +30,13 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 
+	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/config"
+	esclient "github.com/jaegertracing/jaeger/pkg/es/client"
 	"github.com/jaegertracing/jaeger/pkg/metrics"
 	"github.com/jaegertracing/jaeger/plugin/storage/es"
 	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
 const (
@@ -53,6 +54,7 @@ const (
 	dependenciesTemplateName = "jaeger-dependencies"
 	primaryNamespace         = "es"
 	archiveNamespace         = "es-archive"
+	zipkinIndexPrefix        = "integration-zipkin"
 )
 
 type ESStorageIntegration struct {
@@ -64,22 +66,27 @@ type ESStorageIntegration struct {
 	factory *es.Factory
 }
 
-func (s *ESStorageIntegration) getVersion() (uint, error) {
-	pingResult, _, err := s.client.Ping(queryURL).Do(context.Background())
-	if err != nil {
-		return 0, err
-	}
-	esVersion, err := strconv.Atoi(string(pingResult.Version.Number[0]))
+func (s *ESStorageIntegration) getVersion() (esclient.ClusterVersion, error) {
+	resp, err := s.client.PerformRequest(context.Background(), elastic.PerformRequestOptions{
+		Method: http.MethodGet,
+		Path:   "/",
+	})
 	if err != nil {
-		return 0, err
+		return esclient.ClusterVersion{}, err
 	}
-	// OpenSearch is based on ES 7.x
-	if strings.Contains(pingResult.TagLine, "OpenSearch") {
-		if pingResult.Version.Number[0] == '1' || pingResult.Version.Number[0] == '2' {
-			esVersion = 7
-		}
-	}
-	return uint(esVersion), nil
+	return esclient.ParseClusterInfo(resp.Body)
+}
+
+// composableTemplateExists checks for a template created with the _index_template API
+// without the v8 client, which refuses to talk to OpenSearch.
+func (s *ESStorageIntegration) composableTemplateExists(t *testing.T, name string) bool {
+	resp, err := s.client.PerformRequest(context.Background(), elastic.PerformRequestOptions{
+		Method:       http.MethodHead,
+		Path:         "/_index_template/" + name,
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	require.NoError(t, err)
+	return resp.StatusCode == http.StatusOK
 }
 
 func (s *ESStorageIntegration) initializeES(t *testing.T, allTagsAsFields bool) {
@@ -177,6 +184,7 @@ func testElasticsearchStorage(t *testing.T, allTagsAsFields bool) {
 			// TODO: remove this flag after ES supports returning spanKind
 			//  Issue https://github.com/jaegertracing/jaeger/issues/1923
 			GetOperationsMissingSpanKind: true,
+			UnsupportedCapabilities:      []Capability{CapabilityOperationKind},
 		},
 	}
 	s.initializeES(t, allTagsAsFields)
@@ -198,17 +206,21 @@ func TestElasticsearchStorage_IndexTemplates(t *testing.T) {
 	}
 	s := &ESStorageIntegration{}
 	s.initializeES(t, true)
-	esVersion, err := s.getVersion()
+	version, err := s.getVersion()
 	require.NoError(t, err)
 	// TODO abstract this into pkg/es/client.IndexManagementLifecycleAPI
-	if esVersion == 7 {
+	switch {
+	case version.IsOpenSearch() && version.UseComposableTemplates():
+		assert.True(t, s.composableTemplateExists(t, indexPrefix+"-jaeger-service"))
+		assert.True(t, s.composableTemplateExists(t, indexPrefix+"-jaeger-span"))
+	case version.EsCompatibleVersion() == 7:
 		serviceTemplateExists, err := s.client.IndexTemplateExists(indexPrefix + "-jaeger-service").Do(context.Background())
 		require.NoError(t, err)
 		assert.True(t, serviceTemplateExists)
 		spanTemplateExists, err := s.client.IndexTemplateExists(indexPrefix + "-jaeger-span").Do(context.Background())
 		require.NoError(t, err)
 		assert.True(t, spanTemplateExists)
-	} else {
+	default:
 		serviceTemplateExistsResponse, err := s.v8Client.API.Indices.ExistsIndexTemplate(indexPrefix + "-jaeger-service")
 		require.NoError(t, err)
 		assert.Equal(t, 200, serviceTemplateExistsResponse.StatusCode)
@@ -219,21 +231,142 @@ func TestElasticsearchStorage_IndexTemplates(t *testing.T) {
 	s.cleanESIndexTemplates(t, indexPrefix)
 }
 
+func TestElasticsearchStorage_ZipkinCompat(t *testing.T) {
+	SkipUnlessEnv(t, "elasticsearch", "opensearch")
+	if err := healthCheck(); err != nil {
+		t.Fatal(err)
+	}
+	s := &ESStorageIntegration{}
+	s.initializeES(t, false)
+
+	logger := zaptest.NewLogger(t)
+	f := es.NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		fmt.Sprintf("--es.index-prefix=%v", indexPrefix),
+		fmt.Sprintf("--es.zipkin-compat.index-prefix=%v", zipkinIndexPrefix),
+	}))
+	f.InitFromViper(v, logger)
+	require.NoError(t, f.Initialize(metrics.NullFactory, logger))
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+	reader, err := f.CreateSpanReader()
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	zipkinIndex := zipkinIndexPrefix + ":span-" + now.Format(indexDateLayout)
+	t.Cleanup(func() {
+		_, err := s.client.DeleteIndex(zipkinIndex).Do(context.Background())
+		require.NoError(t, err)
+	})
+	// subset of the mapping installed by Zipkin's index template
+	_, err = s.client.CreateIndex(zipkinIndex).Body(`{
+		"mappings": {
+			"properties": {
+				"traceId": {"type": "keyword"},
+				"name": {"type": "keyword"},
+				"timestamp_millis": {"type": "date", "format": "epoch_millis"},
+				"duration": {"type": "long"},
+				"localEndpoint": {"properties": {"serviceName": {"type": "keyword"}}},
+				"_q": {"type": "keyword"}
+			}
+		}
+	}`).Do(context.Background())
+	require.NoError(t, err)
+	timestamp := model.TimeAsEpochMicroseconds(now.Add(-time.Minute))
+	zipkinDocs := []map[string]any{
+		{
+			"traceId":          "00000000000000000000000000abcdef",
+			"id":               "0000000000000001",
+			"kind":             "SERVER",
+			"name":             "get /api",
+			"timestamp":        timestamp,
+			"timestamp_millis": timestamp / 1000,
+			"duration":         1000,
+			"localEndpoint":    map[string]any{"serviceName": "zipkin-backend", "ipv4": "10.0.0.2"},
+			"remoteEndpoint":   map[string]any{"serviceName": "zipkin-frontend", "ipv4": "10.0.0.1"},
+		},
+		{
+			"traceId":          "00000000000000000000000000abcdef",
+			"parentId":         "0000000000000001",
+			"id":               "0000000000000002",
+			"kind":             "CLIENT",
+			"name":             "select",
+			"timestamp":        timestamp + 100,
+			"timestamp_millis": (timestamp + 100) / 1000,
+			"duration":         500,
+			"localEndpoint":    map[string]any{"serviceName": "zipkin-backend"},
+			"remoteEndpoint":   map[string]any{"serviceName": "mysql", "port": 3306},
+			"tags":             map[string]string{"sql.query": "select 1"},
+		},
+	}
+	for _, doc := range zipkinDocs {
+		_, err := s.client.Index().Index(zipkinIndex).Type("_doc").BodyJson(doc).Refresh("true").Do(context.Background())
+		require.NoError(t, err)
+	}
+
+	traceID := model.NewTraceID(0, 0xabcdef)
+	trace, err := reader.GetTrace(context.Background(), traceID)
+	require.NoError(t, err)
+	require.Len(t, trace.Spans, 2)
+	for _, span := range trace.Spans {
+		assert.Equal(t, traceID, span.TraceID)
+		assert.Equal(t, "zipkin-backend", span.Process.ServiceName)
+		switch span.SpanID {
+		case model.NewSpanID(1):
+			assert.True(t, span.IsRPCServer())
+		case model.NewSpanID(2):
+			assert.True(t, span.IsRPCClient())
+			assert.Equal(t, model.NewSpanID(1), span.ParentSpanID())
+			peer, ok := model.KeyValues(span.Tags).FindByKey("peer.service")
+			require.True(t, ok)
+			assert.Equal(t, "mysql", peer.VStr)
+		default:
+			t.Errorf("unexpected span %v", span.SpanID)
+		}
+	}
+
+	services, err := reader.GetServices(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, services, "zipkin-backend")
+
+	traces, err := reader.FindTraces(context.Background(), &spanstore.TraceQueryParameters{
+		ServiceName:  "zipkin-backend",
+		StartTimeMin: now.Add(-time.Hour),
+		StartTimeMax: now,
+		NumTraces:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Len(t, traces[0].Spans, 2)
+}
+
 func (s *ESStorageIntegration) cleanESIndexTemplates(t *testing.T, prefix string) error {
 	version, err := s.getVersion()
 	require.NoError(t, err)
-	if version > 7 {
-		prefixWithSeparator := prefix
-		if prefix != "" {
-			prefixWithSeparator += "-"
+	prefixWithSeparator := prefix
+	if prefix != "" {
+		prefixWithSeparator += "-"
+	}
+	switch {
+	case version.IsOpenSearch() && version.UseComposableTemplates():
+		for _, name := range []string{spanTemplateName, serviceTemplateName, dependenciesTemplateName} {
+			_, err := s.client.PerformRequest(context.Background(), elastic.PerformRequestOptions{
+				Method:       http.MethodDelete,
+				Path:         "/_index_template/" + prefixWithSeparator + name,
+				IgnoreErrors: []int{http.StatusNotFound},
+			})
+			require.NoError(t, err)
 		}
+	case version.EsCompatibleVersion() > 7:
 		_, err := s.v8Client.Indices.DeleteIndexTemplate(prefixWithSeparator + spanTemplateName)
 		require.NoError(t, err)
 		_, err = s.v8Client.Indices.DeleteIndexTemplate(prefixWithSeparator + serviceTemplateName)
 		require.NoError(t, err)
 		_, err = s.v8Client.Indices.DeleteIndexTemplate(prefixWithSeparator + dependenciesTemplateName)
 		require.NoError(t, err)
-	} else {
+	default:
 		_, err := s.client.IndexDeleteTemplate("*").Do(context.Background())
 		require.NoError(t, err)
 	}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
@@ -74,9 +75,16 @@ type StorageIntegration struct {
 	// List of tests which has to be skipped, it can be regex too.
 	SkipList []string
 
+	// Capabilities not supported by the storage backend, whose conformance fixtures are skipped
+	UnsupportedCapabilities []Capability
+
 	// CleanUp() should ensure that the storage backend is clean before another test.
 	// called either before or after each test, and should be idempotent
 	CleanUp func(t *testing.T)
+
+	// spansFlushed is set once the written spans were flushed, see spanstore.WriterFlusher,
+	// so that they are read back without polling until the next clean up
+	spansFlushed bool
 }
 
 // === SpanStore Integration Tests ===
@@ -97,6 +105,17 @@ type QueryFixtures struct {
 func (s *StorageIntegration) cleanUp(t *testing.T) {
 	require.NotNil(t, s.CleanUp, "CleanUp function must be provided")
 	s.CleanUp(t)
+	s.spansFlushed = false
+}
+
+// flushSpans flushes the span writer if it supports it, see spanstore.Flush.
+func (s *StorageIntegration) flushSpans(t *testing.T, writer spanstore.Writer) {
+	err := spanstore.Flush(context.Background(), writer)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return
+	}
+	require.NoError(t, err, "Not expecting error when flushing the span writer")
+	s.spansFlushed = true
 }
 
 func SkipUnlessEnv(t *testing.T, storage ...string) {
@@ -120,6 +139,13 @@ var CassandraSkippedTests = []string{
 	"Multiple_Traces",
 }
 
+// CassandraUnsupportedCapabilities are the conformance capabilities not supported by Cassandra,
+// whose searches by duration ignore the other criteria and whose tags are indexed per trace.
+var CassandraUnsupportedCapabilities = []Capability{
+	CapabilityDurationRange,
+	CapabilitySingleSpanTags,
+}
+
 func (s *StorageIntegration) skipIfNeeded(t *testing.T) {
 	for _, pat := range s.SkipList {
 		escapedPat := regexp.QuoteMeta(pat)
@@ -132,7 +158,11 @@ func (s *StorageIntegration) skipIfNeeded(t *testing.T) {
 	}
 }
 
-func (*StorageIntegration) waitForCondition(t *testing.T, predicate func(t *testing.T) bool) bool {
+func (s *StorageIntegration) waitForCondition(t *testing.T, predicate func(t *testing.T) bool) bool {
+	if s.spansFlushed {
+		// the spans written are already visible, no need to wait for the storage backend
+		return predicate(t)
+	}
 	const iterations = 100 // Will wait at most 100 seconds.
 	for i := 0; i < iterations; i++ {
 		if predicate(t) {
@@ -183,6 +213,7 @@ func (s *StorageIntegration) testArchiveTrace(t *testing.T) {
 	}
 
 	require.NoError(t, s.ArchiveSpanWriter.WriteSpan(context.Background(), expected))
+	s.flushSpans(t, s.ArchiveSpanWriter)
 
 	var actual *model.Trace
 	found := s.waitForCondition(t, func(_ *testing.T) bool {
@@ -279,6 +310,38 @@ func (s *StorageIntegration) testGetTrace(t *testing.T) {
 	})
 }
 
+func (s *StorageIntegration) testDeleteTrace(t *testing.T) {
+	s.skipIfNeeded(t)
+	defer s.cleanUp(t)
+
+	deleter, ok := s.SpanReader.(spanstore.TraceDeleter)
+	if !ok {
+		t.Skip("Skipping DeleteTrace test because the span reader does not implement spanstore.TraceDeleter")
+	}
+
+	expected := s.loadParseAndWriteExampleTrace(t)
+	expectedTraceID := expected.Spans[0].TraceID
+	found := s.waitForCondition(t, func(t *testing.T) bool {
+		actual, err := s.SpanReader.GetTrace(context.Background(), expectedTraceID)
+		if err != nil {
+			t.Log(err)
+		}
+		return err == nil && len(actual.Spans) == len(expected.Spans)
+	})
+	require.True(t, found)
+
+	err := deleter.DeleteTrace(context.Background(), expectedTraceID)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("Skipping DeleteTrace test because the span reader does not support deletions")
+	}
+	require.NoError(t, err)
+	deleted := s.waitForCondition(t, func(t *testing.T) bool {
+		_, err := s.SpanReader.GetTrace(context.Background(), expectedTraceID)
+		return errors.Is(err, spanstore.ErrTraceNotFound)
+	})
+	assert.True(t, deleted)
+}
+
 func (s *StorageIntegration) testFindTraces(t *testing.T) {
 	s.skipIfNeeded(t)
 	defer s.cleanUp(t)
@@ -339,6 +402,7 @@ func (s *StorageIntegration) writeTrace(t *testing.T, trace *model.Trace) {
 		err := s.SpanWriter.WriteSpan(context.Background(), span)
 		require.NoError(t, err, "Not expecting error when writing trace to storage")
 	}
+	s.flushSpans(t, s.SpanWriter)
 }
 
 func (s *StorageIntegration) loadParseAndWriteExampleTrace(t *testing.T) *model.Trace {
@@ -539,6 +603,8 @@ func (s *StorageIntegration) RunSpanStoreTests(t *testing.T) {
 	t.Run("GetServices", s.testGetServices)
 	t.Run("GetOperations", s.testGetOperations)
 	t.Run("GetTrace", s.testGetTrace)
+	t.Run("DeleteTrace", s.testDeleteTrace)
 	t.Run("GetLargeSpans", s.testGetLargeSpan)
 	t.Run("FindTraces", s.testFindTraces)
+	t.Run("Conformance", s.testConformance)
 }
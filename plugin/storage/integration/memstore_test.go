@@ -18,6 +18,7 @@ package integration
 import (
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/jaegertracing/jaeger/pkg/testutils"
@@ -51,3 +52,12 @@ func TestMemoryStorage(t *testing.T) {
 	s.initialize(t)
 	s.RunAll(t)
 }
+
+// TestMemoryStorageConformance runs the conformance fixtures against the memory storage,
+// which is their reference implementation and supports all the capabilities.
+func TestMemoryStorageConformance(t *testing.T) {
+	s := &MemStorageIntegrationTestSuite{}
+	s.initialize(t)
+	require.Empty(t, s.UnsupportedCapabilities)
+	t.Run("Conformance", s.testConformance)
+}
@@ -100,7 +100,7 @@ func (*Factory) CreateSpanReader() (spanstore.Reader, error) {
 
 // CreateSpanWriter implements storage.Factory
 func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
-	return NewSpanWriter(f.producer, f.marshaller, f.options.Topic, f.metricsFactory, f.logger), nil
+	return NewSpanWriter(f.producer, f.marshaller, f.options.Topic, f.options.PartitionKey, f.metricsFactory, f.logger), nil
 }
 
 // CreateDependencyReader implements storage.Factory
@@ -35,6 +35,13 @@ const (
 	// EncodingZipkinThrift is used for spans encoded as Zipkin Thrift.
 	EncodingZipkinThrift = "zipkin-thrift"
 
+	// PartitionKeyTraceID keys the messages by trace ID, so that the spans of a trace are on the same partition.
+	PartitionKeyTraceID = "traceID"
+	// PartitionKeyService keys the messages by service name, so that the spans of a service are in order.
+	PartitionKeyService = "service"
+	// PartitionKeyNone sends the messages without a key, spread over all the partitions in turn.
+	PartitionKeyNone = "none"
+
 	configPrefix           = "kafka.producer"
 	suffixBrokers          = ".brokers"
 	suffixTopic            = ".topic"
@@ -48,6 +55,8 @@ const (
 	suffixBatchMinMessages = ".batch-min-messages"
 	suffixBatchMaxMessages = ".batch-max-messages"
 	suffixMaxMessageBytes  = ".max-message-bytes"
+	suffixPartitionKey     = ".partition-key"
+	suffixIdempotent       = ".idempotent"
 
 	defaultBroker           = "127.0.0.1:9092"
 	defaultTopic            = "jaeger-spans"
@@ -55,6 +64,7 @@ const (
 	defaultRequiredAcks     = "local"
 	defaultCompression      = "none"
 	defaultCompressionLevel = 0
+	defaultPartitionKey     = PartitionKeyTraceID
 	defaultBatchLinger      = 0
 	defaultBatchSize        = 0
 	defaultBatchMinMessages = 0
@@ -112,9 +122,10 @@ var (
 
 // Options stores the configuration options for Kafka
 type Options struct {
-	Config   producer.Configuration `mapstructure:",squash"`
-	Topic    string                 `mapstructure:"topic"`
-	Encoding string                 `mapstructure:"encoding"`
+	Config       producer.Configuration `mapstructure:",squash"`
+	Topic        string                 `mapstructure:"topic"`
+	Encoding     string                 `mapstructure:"encoding"`
+	PartitionKey string                 `mapstructure:"partition_key"`
 }
 
 // AddFlags adds flags for Options
@@ -159,6 +170,18 @@ func (*Options) AddFlags(flagSet *flag.FlagSet) {
 		defaultMaxMessageBytes,
 		"(experimental) The maximum permitted size of a message. Should be set equal to or smaller than the broker's `message.max.bytes`.",
 	)
+	flagSet.String(
+		configPrefix+suffixPartitionKey,
+		defaultPartitionKey,
+		fmt.Sprintf(
+			`(experimental) Key of the messages, by which they are partitioned: "%s", "%s" for per-service ordering, or "%s" to spread them over all the partitions. The ingester does not depend on the order of the spans, it supports all the keys`,
+			PartitionKeyTraceID, PartitionKeyService, PartitionKeyNone),
+	)
+	flagSet.Bool(
+		configPrefix+suffixIdempotent,
+		false,
+		"(experimental) Enable the idempotent producer, which prevents duplicate messages when retrying. Requires --kafka.producer.required-acks=all",
+	)
 	flagSet.String(
 		configPrefix+suffixBrokers,
 		defaultBroker,
@@ -203,6 +226,11 @@ func (opt *Options) InitFromViper(v *viper.Viper) {
 		log.Fatal(err)
 	}
 
+	partitionKey, err := getPartitionKey(v.GetString(configPrefix + suffixPartitionKey))
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	opt.Config = producer.Configuration{
 		Brokers:              strings.Split(stripWhiteSpace(v.GetString(configPrefix+suffixBrokers)), ","),
 		RequiredAcks:         requiredAcks,
@@ -215,9 +243,11 @@ func (opt *Options) InitFromViper(v *viper.Viper) {
 		BatchMinMessages:     v.GetInt(configPrefix + suffixBatchMinMessages),
 		BatchMaxMessages:     v.GetInt(configPrefix + suffixBatchMaxMessages),
 		MaxMessageBytes:      v.GetInt(configPrefix + suffixMaxMessageBytes),
+		Idempotent:           v.GetBool(configPrefix + suffixIdempotent),
 	}
 	opt.Topic = v.GetString(configPrefix + suffixTopic)
 	opt.Encoding = v.GetString(configPrefix + suffixEncoding)
+	opt.PartitionKey = partitionKey
 }
 
 // stripWhiteSpace removes all whitespace characters from a string
@@ -261,3 +291,13 @@ func getRequiredAcks(acks string) (sarama.RequiredAcks, error) {
 	}
 	return requiredAcks, nil
 }
+
+// getPartitionKey validates the key of the messages
+func getPartitionKey(key string) (string, error) {
+	switch key {
+	case PartitionKeyTraceID, PartitionKeyService, PartitionKeyNone:
+		return key, nil
+	default:
+		return "", fmt.Errorf("unknown partition key: %s", key)
+	}
+}
@@ -43,6 +43,8 @@ func TestOptionsWithFlags(t *testing.T) {
 		"--kafka.producer.batch-min-messages=50",
 		"--kafka.producer.batch-max-messages=100",
 		"--kafka.producer.max-message-bytes=10485760",
+		"--kafka.producer.partition-key=service",
+		"--kafka.producer.idempotent=true",
 	})
 	opts.InitFromViper(v)
 
@@ -58,6 +60,8 @@ func TestOptionsWithFlags(t *testing.T) {
 	assert.Equal(t, 100, opts.Config.BatchMaxMessages)
 	assert.Equal(t, 100, opts.Config.BatchMaxMessages)
 	assert.Equal(t, 10485760, opts.Config.MaxMessageBytes)
+	assert.Equal(t, PartitionKeyService, opts.PartitionKey)
+	assert.True(t, opts.Config.Idempotent)
 }
 
 func TestFlagDefaults(t *testing.T) {
@@ -77,6 +81,8 @@ func TestFlagDefaults(t *testing.T) {
 	assert.Equal(t, 0, opts.Config.BatchMinMessages)
 	assert.Equal(t, 0, opts.Config.BatchMaxMessages)
 	assert.Equal(t, defaultMaxMessageBytes, opts.Config.MaxMessageBytes)
+	assert.Equal(t, PartitionKeyTraceID, opts.PartitionKey)
+	assert.False(t, opts.Config.Idempotent)
 }
 
 func TestCompressionLevelDefaults(t *testing.T) {
@@ -175,6 +181,16 @@ func TestRequiredAcksFailures(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestPartitionKey(t *testing.T) {
+	for _, key := range []string{PartitionKeyTraceID, PartitionKeyService, PartitionKeyNone} {
+		partitionKey, err := getPartitionKey(key)
+		require.NoError(t, err)
+		assert.Equal(t, key, partitionKey)
+	}
+	_, err := getPartitionKey("operation")
+	require.EqualError(t, err, "unknown partition key: operation")
+}
+
 func TestTLSFlags(t *testing.T) {
 	kerb := auth.KerberosConfig{ServiceName: "kafka", ConfigPath: "/etc/krb5.conf", KeyTabPath: "/etc/security/kafka.keytab"}
 	plain := auth.PlainTextConfig{Username: "", Password: "", Mechanism: "PLAIN"}
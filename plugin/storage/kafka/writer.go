@@ -35,13 +35,16 @@ type SpanWriter struct {
 	producer   sarama.AsyncProducer
 	marshaller Marshaller
 	topic      string
+	keyFn      func(*model.Span) sarama.Encoder
 }
 
-// NewSpanWriter initiates and returns a new kafka spanwriter
+// NewSpanWriter initiates and returns a new kafka spanwriter, keying the messages
+// as described by the partition key, e.g. PartitionKeyTraceID
 func NewSpanWriter(
 	producer sarama.AsyncProducer,
 	marshaller Marshaller,
 	topic string,
+	partitionKey string,
 	factory metrics.Factory,
 	logger *zap.Logger,
 ) *SpanWriter {
@@ -68,10 +71,30 @@ func NewSpanWriter(
 		producer:   producer,
 		marshaller: marshaller,
 		topic:      topic,
+		keyFn:      messageKeyFn(partitionKey),
 		metrics:    writeMetrics,
 	}
 }
 
+// messageKeyFn returns the function computing the key of the message of a span.
+// Without a key, the messages are spread over all the partitions by the producer.
+func messageKeyFn(partitionKey string) func(*model.Span) sarama.Encoder {
+	switch partitionKey {
+	case PartitionKeyService:
+		return func(span *model.Span) sarama.Encoder {
+			return sarama.StringEncoder(span.Process.GetServiceName())
+		}
+	case PartitionKeyNone:
+		return func(*model.Span) sarama.Encoder {
+			return nil
+		}
+	default:
+		return func(span *model.Span) sarama.Encoder {
+			return sarama.StringEncoder(span.TraceID.String())
+		}
+	}
+}
+
 // WriteSpan writes the span to kafka.
 func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
 	spanBytes, err := w.marshaller.Marshal(span)
@@ -84,7 +107,7 @@ func (w *SpanWriter) WriteSpan(_ context.Context, span *model.Span) error {
 	// in the background as efficiently as possible
 	w.producer.Input() <- &sarama.ProducerMessage{
 		Topic: w.topic,
-		Key:   sarama.StringEncoder(span.TraceID.String()),
+		Key:   w.keyFn(span),
 		Value: sarama.ByteEncoder(spanBytes),
 	}
 	return nil
@@ -22,6 +22,7 @@ import (
 
 	"github.com/Shopify/sarama"
 	saramaMocks "github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -88,7 +89,7 @@ func withSpanWriter(t *testing.T, fn func(span *model.Span, w *spanWriterTest))
 		producer:       producer,
 		marshaller:     marshaller,
 		metricsFactory: serviceMetrics,
-		writer:         NewSpanWriter(producer, marshaller, "someTopic", serviceMetrics, zap.NewNop()),
+		writer:         NewSpanWriter(producer, marshaller, "someTopic", PartitionKeyTraceID, serviceMetrics, zap.NewNop()),
 	}
 
 	fn(sampleSpan, writerTest)
@@ -183,3 +184,30 @@ func TestMarshallerErr(t *testing.T) {
 			})
 	})
 }
+
+func TestKafkaWriterPartitionKey(t *testing.T) {
+	testCases := []struct {
+		partitionKey string
+		expectedKey  sarama.Encoder
+	}{
+		{partitionKey: PartitionKeyTraceID, expectedKey: sarama.StringEncoder(sampleSpan.TraceID.String())},
+		{partitionKey: PartitionKeyService, expectedKey: sarama.StringEncoder("someServiceName")},
+		// a nil key lets the producer spread the messages over the partitions in turn
+		{partitionKey: PartitionKeyNone, expectedKey: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.partitionKey, func(t *testing.T) {
+			withSpanWriter(t, func(span *model.Span, w *spanWriterTest) {
+				w.writer.keyFn = messageKeyFn(tc.partitionKey)
+				w.producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+					assert.Equal(t, "someTopic", message.Topic)
+					assert.Equal(t, tc.expectedKey, message.Key)
+					return nil
+				})
+
+				require.NoError(t, w.writer.WriteSpan(context.Background(), span))
+				require.NoError(t, w.writer.Close())
+			})
+		})
+	}
+}
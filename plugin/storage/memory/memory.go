@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/jaegertracing/jaeger/model/adjuster"
 	"github.com/jaegertracing/jaeger/pkg/memory/config"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -95,11 +97,78 @@ func (st *Store) getTenant(tenantID string) *Tenant {
 
 // GetDependencies returns dependencies between services
 func (st *Store) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	deps := map[string]*model.DependencyLink{}
+	st.walkDependencies(ctx, endTs, lookback, func(parent, child *model.Span) {
+		depKey := parent.Process.ServiceName + "&&&" + child.Process.ServiceName
+		if _, ok := deps[depKey]; !ok {
+			deps[depKey] = &model.DependencyLink{
+				Parent:    parent.Process.ServiceName,
+				Child:     child.Process.ServiceName,
+				CallCount: 1,
+			}
+		} else {
+			deps[depKey].CallCount++
+		}
+	})
+	retMe := make([]model.DependencyLink, 0, len(deps))
+	for _, dep := range deps {
+		retMe = append(retMe, *dep)
+	}
+	return retMe, nil
+}
+
+// GetDependencyErrors returns the number of calls between services whose child span has an error tag.
+func (st *Store) GetDependencyErrors(ctx context.Context, endTs time.Time, lookback time.Duration) ([]dependencystore.DependencyLinkErrors, error) {
+	deps := map[string]*dependencystore.DependencyLinkErrors{}
+	st.walkDependencies(ctx, endTs, lookback, func(parent, child *model.Span) {
+		depKey := parent.Process.ServiceName + "&&&" + child.Process.ServiceName
+		if _, ok := deps[depKey]; !ok {
+			deps[depKey] = &dependencystore.DependencyLinkErrors{
+				Parent: parent.Process.ServiceName,
+				Child:  child.Process.ServiceName,
+			}
+		}
+		if spanHasError(child) {
+			deps[depKey].ErrorCount++
+		}
+	})
+	retMe := make([]dependencystore.DependencyLinkErrors, 0, len(deps))
+	for _, dep := range deps {
+		retMe = append(retMe, *dep)
+	}
+	return retMe, nil
+}
+
+// GetOperationDependencies returns the calls between the operations of the service and the operations
+// of the other services, limited to the dependencystore.DefaultMaxOperationsPerService operations of each service.
+func (st *Store) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	var links []dependencystore.OperationDependencyLink
+	st.walkDependencies(ctx, endTs, lookback, func(parent, child *model.Span) {
+		if parent.Process.ServiceName != service && child.Process.ServiceName != service {
+			return
+		}
+		links = append(links, dependencystore.OperationDependencyLink{
+			Parent:          parent.Process.ServiceName,
+			ParentOperation: parent.OperationName,
+			Child:           child.Process.ServiceName,
+			ChildOperation:  child.OperationName,
+			CallCount:       1,
+		})
+	})
+	return dependencystore.LimitOperations(links, dependencystore.DefaultMaxOperationsPerService), nil
+}
+
+// walkDependencies calls f for each span of the traces of the time range whose parent belongs to another service.
+func (st *Store) walkDependencies(ctx context.Context, endTs time.Time, lookback time.Duration, f func(parent, child *model.Span)) {
 	m := st.getTenant(tenancy.GetTenant(ctx))
 	// deduper used below can modify the spans, so we take an exclusive lock
 	m.Lock()
 	defer m.Unlock()
-	deps := map[string]*model.DependencyLink{}
 	startTs := endTs.Add(-1 * lookback)
 	for _, orig := range m.traces {
 		// SpanIDDeduper never returns an err
@@ -111,25 +180,16 @@ func (st *Store) GetDependencies(ctx context.Context, endTs time.Time, lookback
 					if parentSpan.Process.ServiceName == s.Process.ServiceName {
 						continue
 					}
-					depKey := parentSpan.Process.ServiceName + "&&&" + s.Process.ServiceName
-					if _, ok := deps[depKey]; !ok {
-						deps[depKey] = &model.DependencyLink{
-							Parent:    parentSpan.Process.ServiceName,
-							Child:     s.Process.ServiceName,
-							CallCount: 1,
-						}
-					} else {
-						deps[depKey].CallCount++
-					}
+					f(parentSpan, s)
 				}
 			}
 		}
 	}
-	retMe := make([]model.DependencyLink, 0, len(deps))
-	for _, dep := range deps {
-		retMe = append(retMe, *dep)
-	}
-	return retMe, nil
+}
+
+func spanHasError(span *model.Span) bool {
+	errorTag, ok := model.KeyValues(span.Tags).FindByKey("error")
+	return ok && errorTag.AsString() == "true"
 }
 
 func findSpan(trace *model.Trace, spanID model.SpanID) *model.Span {
@@ -193,6 +253,11 @@ func (st *Store) WriteSpan(ctx context.Context, span *model.Span) error {
 	return nil
 }
 
+// Flush implements spanstore.WriterFlusher#Flush, the spans being visible to the reads as soon as they are written.
+func (*Store) Flush(context.Context) error {
+	return nil
+}
+
 // GetTrace gets a trace
 func (st *Store) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
 	m := st.getTenant(tenancy.GetTenant(ctx))
@@ -205,6 +270,78 @@ func (st *Store) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Tr
 	return copyTrace(trace)
 }
 
+// DeleteTrace deletes a trace
+func (st *Store) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	m := st.getTenant(tenancy.GetTenant(ctx))
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.traces[traceID]; !ok {
+		return nil
+	}
+	delete(m.traces, traceID)
+	// free the position in the ring, so that the trace is not evicted if it is written again
+	for i, id := range m.ids {
+		if id != nil && *id == traceID {
+			m.ids[i] = nil
+		}
+	}
+	return nil
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix,
+// the matching trace IDs being returned in ascending order.
+func (st *Store) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	m := st.getTenant(tenancy.GetTenant(ctx))
+	m.RLock()
+	var traceIDs []model.TraceID
+	for traceID := range m.traces {
+		if strings.HasPrefix(traceID.String(), prefix) {
+			traceIDs = append(traceIDs, traceID)
+		}
+	}
+	m.RUnlock()
+	sort.Slice(traceIDs, func(i, j int) bool {
+		return traceIDs[i].String() < traceIDs[j].String()
+	})
+	if limit > 0 && len(traceIDs) > limit {
+		traceIDs = traceIDs[:limit]
+	}
+	return traceIDs, nil
+}
+
+// GetTagKeys implements spanstore.TagKeysReader#GetTagKeys.
+func (st *Store) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	m := st.getTenant(tenancy.GetTenant(ctx))
+	m.RLock()
+	seen := make(map[string]struct{})
+	for _, trace := range m.traces {
+		for _, span := range trace.Spans {
+			if span.Process.ServiceName != query.ServiceName {
+				continue
+			}
+			if !query.StartTimeMin.IsZero() && span.StartTime.Before(query.StartTimeMin) {
+				continue
+			}
+			if !query.StartTimeMax.IsZero() && span.StartTime.After(query.StartTimeMax) {
+				continue
+			}
+			for _, tag := range span.Tags {
+				seen[tag.Key] = struct{}{}
+			}
+		}
+	}
+	m.RUnlock()
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if query.Limit > 0 && len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+	}
+	return keys, nil
+}
+
 // Spans may still be added to traces after they are returned to user code, so make copies.
 func copyTrace(trace *model.Trace) (*model.Trace, error) {
 	bytes, err := proto.Marshal(trace)
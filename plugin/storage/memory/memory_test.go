@@ -26,6 +26,7 @@ import (
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/pkg/memory/config"
 	"github.com/jaegertracing/jaeger/pkg/tenancy"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
 	"github.com/jaegertracing/jaeger/storage/spanstore"
 )
 
@@ -161,6 +162,53 @@ func TestStoreGetDependencies(t *testing.T) {
 	})
 }
 
+func TestStoreGetDependencyErrors(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		failedChildSpan := *childSpan1
+		failedChildSpan.SpanID = model.NewSpanID(5)
+		failedChildSpan.Tags = model.KeyValues{model.Bool("error", true)}
+		require.NoError(t, store.WriteSpan(context.Background(), testingSpan))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan1))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan2))
+		require.NoError(t, store.WriteSpan(context.Background(), &failedChildSpan))
+
+		links, err := store.GetDependencyErrors(context.Background(), time.Now(), time.Hour)
+		require.NoError(t, err)
+		assert.Empty(t, links)
+
+		links, err = store.GetDependencyErrors(context.Background(), time.Unix(0, 0).Add(time.Hour), time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []dependencystore.DependencyLinkErrors{{
+			Parent:     "serviceName",
+			Child:      "childService",
+			ErrorCount: 1,
+		}}, links)
+	})
+}
+
+func TestStoreGetOperationDependencies(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		require.NoError(t, store.WriteSpan(context.Background(), testingSpan))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan1))
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan2))
+		endTs := time.Unix(0, 0).Add(time.Hour)
+
+		links, err := store.GetOperationDependencies(context.Background(), "childService", endTs, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []dependencystore.OperationDependencyLink{{
+			Parent:          "serviceName",
+			ParentOperation: "operationName",
+			Child:           "childService",
+			ChildOperation:  "childOperationName",
+			CallCount:       2,
+		}}, links)
+
+		links, err = store.GetOperationDependencies(context.Background(), "otherService", endTs, time.Hour)
+		require.NoError(t, err)
+		assert.Empty(t, links)
+	})
+}
+
 func TestStoreWriteSpan(t *testing.T) {
 	withMemoryStore(func(store *Store) {
 		err := store.WriteSpan(context.Background(), testingSpan)
@@ -168,6 +216,16 @@ func TestStoreWriteSpan(t *testing.T) {
 	})
 }
 
+func TestStoreFlush(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		require.NoError(t, store.WriteSpan(context.Background(), testingSpan))
+		require.NoError(t, spanstore.Flush(context.Background(), store))
+		trace, err := store.GetTrace(context.Background(), testingSpan.TraceID)
+		require.NoError(t, err)
+		assert.Len(t, trace.Spans, 1)
+	})
+}
+
 func TestStoreWithLimit(t *testing.T) {
 	maxTraces := 100
 	store := WithConfiguration(config.Configuration{MaxTraces: maxTraces})
@@ -242,6 +300,103 @@ func TestStoreGetTraceFailure(t *testing.T) {
 	})
 }
 
+func TestStoreDeleteTrace(t *testing.T) {
+	withPopulatedMemoryStore(func(store *Store) {
+		require.NoError(t, store.DeleteTrace(context.Background(), testingSpan.TraceID))
+		_, err := store.GetTrace(context.Background(), testingSpan.TraceID)
+		require.ErrorIs(t, err, spanstore.ErrTraceNotFound)
+
+		// deleting a trace which is not stored is not an error
+		require.NoError(t, store.DeleteTrace(context.Background(), testingSpan.TraceID))
+	})
+}
+
+func TestStoreDeleteTraceWithLimit(t *testing.T) {
+	store := WithConfiguration(config.Configuration{MaxTraces: 2})
+	writeTrace := func(low uint64) {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID: model.NewTraceID(1, low),
+			Process: &model.Process{ServiceName: "TestStoreDeleteTraceWithLimit"},
+		}))
+	}
+	writeTrace(1)
+	writeTrace(2)
+	require.NoError(t, store.DeleteTrace(context.Background(), model.NewTraceID(1, 1)))
+
+	// the trace written again is not evicted by the next one through its former position in the ring
+	writeTrace(1)
+	writeTrace(3)
+	_, err := store.GetTrace(context.Background(), model.NewTraceID(1, 1))
+	require.NoError(t, err)
+	assert.Len(t, store.getTenant("").traces, 2)
+}
+
+func TestStoreFindTraceIDsByPrefix(t *testing.T) {
+	store := NewStore()
+	traceIDs := []model.TraceID{
+		model.NewTraceID(0xabcd123400000000, 2),
+		model.NewTraceID(0xabcd123400000000, 1),
+		model.NewTraceID(0xabcd567800000000, 1),
+		model.NewTraceID(0, 0xabcd123400000000),
+	}
+	for _, traceID := range traceIDs {
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID: traceID,
+			Process: &model.Process{ServiceName: "TestStoreFindTraceIDsByPrefix"},
+		}))
+	}
+
+	found, err := store.FindTraceIDsByPrefix(context.Background(), "abcd5", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{traceIDs[2]}, found)
+
+	// the 64-bit trace IDs are matched by their 16 hexadecimal characters
+	found, err = store.FindTraceIDsByPrefix(context.Background(), "abcd1234", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{traceIDs[3], traceIDs[1], traceIDs[0]}, found)
+
+	found, err = store.FindTraceIDsByPrefix(context.Background(), "abcd1234", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{traceIDs[3], traceIDs[1]}, found)
+
+	found, err = store.FindTraceIDsByPrefix(context.Background(), "ffff", 10)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestStoreGetTagKeys(t *testing.T) {
+	withMemoryStore(func(store *Store) {
+		require.NoError(t, store.WriteSpan(context.Background(), childSpan1))
+		require.NoError(t, store.WriteSpan(context.Background(), &model.Span{
+			TraceID:   traceID2,
+			SpanID:    model.NewSpanID(1),
+			Process:   &model.Process{ServiceName: "childService"},
+			Tags:      model.KeyValues{model.String("http.method", "GET"), model.String("tagKey", "other")},
+			StartTime: time.Unix(600, 0),
+		}))
+
+		keys, err := store.GetTagKeys(context.Background(), spanstore.TagKeysQueryParameters{ServiceName: "childService"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"http.method", "span.kind", "tagKey"}, keys)
+
+		keys, err = store.GetTagKeys(context.Background(), spanstore.TagKeysQueryParameters{ServiceName: "childService", Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"http.method", "span.kind"}, keys)
+
+		keys, err = store.GetTagKeys(context.Background(), spanstore.TagKeysQueryParameters{
+			ServiceName:  "childService",
+			StartTimeMin: time.Unix(200, 0),
+			StartTimeMax: time.Unix(400, 0),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"span.kind", "tagKey"}, keys)
+
+		keys, err = store.GetTagKeys(context.Background(), spanstore.TagKeysQueryParameters{ServiceName: "unknown"})
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+}
+
 func TestStoreGetServices(t *testing.T) {
 	withPopulatedMemoryStore(func(store *Store) {
 		serviceNames, err := store.GetServices(context.Background())
@@ -0,0 +1,101 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+var ( // interface comformance checks
+	_ storage.Factory     = (*Factory)(nil)
+	_ io.Closer           = (*Factory)(nil)
+	_ plugin.Configurable = (*Factory)(nil)
+)
+
+// Factory implements storage.Factory and creates write-only storage components backed by NATS JetStream.
+type Factory struct {
+	options Options
+
+	metricsFactory metrics.Factory
+	logger         *zap.Logger
+
+	conn      *natsio.Conn
+	jetStream jetstream.JetStream
+}
+
+// NewFactory creates a new Factory.
+func NewFactory() *Factory {
+	return &Factory{}
+}
+
+// AddFlags implements plugin.Configurable
+func (f *Factory) AddFlags(flagSet *flag.FlagSet) {
+	f.options.AddFlags(flagSet)
+}
+
+// InitFromViper implements plugin.Configurable
+func (f *Factory) InitFromViper(v *viper.Viper, logger *zap.Logger) {
+	if err := f.options.InitFromViper(v); err != nil {
+		logger.Fatal("Failed to initialize NATS storage", zap.Error(err))
+	}
+}
+
+// Initialize implements storage.Factory
+func (f *Factory) Initialize(metricsFactory metrics.Factory, logger *zap.Logger) error {
+	f.metricsFactory, f.logger = metricsFactory, logger
+	logger.Info("NATS factory",
+		zap.String("url", f.options.URL),
+		zap.String("stream", f.options.Stream),
+		zap.String("subject", f.options.Subject))
+	conn, err := f.options.Connect(logger)
+	if err != nil {
+		return err
+	}
+	f.conn = conn
+	f.jetStream, err = jetstream.New(conn)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.options.ConnectTimeout)
+	defer cancel()
+	_, err = f.options.EnsureStream(ctx, f.jetStream)
+	return err
+}
+
+// CreateSpanReader implements storage.Factory
+func (*Factory) CreateSpanReader() (spanstore.Reader, error) {
+	return nil, errors.New("nats storage is write-only")
+}
+
+// CreateSpanWriter implements storage.Factory
+func (f *Factory) CreateSpanWriter() (spanstore.Writer, error) {
+	return NewSpanWriter(f.jetStream, f.options.Subject, f.metricsFactory), nil
+}
+
+// CreateDependencyReader implements storage.Factory
+func (*Factory) CreateDependencyReader() (dependencystore.Reader, error) {
+	return nil, errors.New("nats storage is write-only")
+}
+
+// Close closes the resources held by the factory
+func (f *Factory) Close() error {
+	if f.conn != nil {
+		f.conn.Close()
+	}
+	return f.options.TLS.Close()
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+// startServer runs an in-process NATS server with JetStream enabled.
+func startServer(t *testing.T) *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natsserver.RunServer(&opts)
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+	return srv
+}
+
+func newTestFactory(t *testing.T, url string) *Factory {
+	f := NewFactory()
+	v, command := config.Viperize(f.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{"--nats.producer.url=" + url}))
+	f.InitFromViper(v, zap.NewNop())
+	return f
+}
+
+func TestNATSFactory(t *testing.T) {
+	srv := startServer(t)
+	f := newTestFactory(t, srv.ClientURL())
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	defer f.Close()
+
+	stream, err := f.jetStream.Stream(context.Background(), DefaultStream)
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultSubject}, stream.CachedInfo().Config.Subjects)
+
+	_, err = f.CreateSpanWriter()
+	require.NoError(t, err)
+
+	_, err = f.CreateSpanReader()
+	require.EqualError(t, err, "nats storage is write-only")
+
+	_, err = f.CreateDependencyReader()
+	require.EqualError(t, err, "nats storage is write-only")
+}
+
+func TestNATSFactoryKeepsExistingStream(t *testing.T) {
+	srv := startServer(t)
+	f := newTestFactory(t, srv.ClientURL())
+	conn, err := f.options.Connect(zap.NewNop())
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	_, err = js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     DefaultStream,
+		Subjects: []string{"jaeger.>"},
+		MaxAge:   time.Hour,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.Initialize(metrics.NullFactory, zap.NewNop()))
+	defer f.Close()
+
+	stream, err := js.Stream(context.Background(), DefaultStream)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jaeger.>"}, stream.CachedInfo().Config.Subjects)
+	assert.Equal(t, time.Hour, stream.CachedInfo().Config.MaxAge)
+}
+
+func TestNATSFactoryConnectionError(t *testing.T) {
+	f := newTestFactory(t, "nats://127.0.0.1:1")
+	f.options.ConnectTimeout = time.Second
+	require.ErrorContains(t, f.Initialize(metrics.NullFactory, zap.NewNop()), "failed to connect to NATS")
+	require.NoError(t, f.Close())
+}
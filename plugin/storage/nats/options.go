@@ -0,0 +1,166 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config/tlscfg"
+)
+
+const (
+	// ProducerConfigPrefix is the prefix of the flags of the span writer.
+	ProducerConfigPrefix = "nats.producer"
+
+	suffixURL            = ".url"
+	suffixCredsFile      = ".creds-file"
+	suffixNKeyFile       = ".nkey-file"
+	suffixConnectTimeout = ".connect-timeout"
+	suffixStream         = ".stream"
+	suffixSubject        = ".subject"
+
+	// DefaultURL is the default NATS server URL.
+	DefaultURL = natsio.DefaultURL
+	// DefaultStream is the default JetStream stream of the spans.
+	DefaultStream = "jaeger-spans"
+	// DefaultSubject is the default subject the spans are published to.
+	DefaultSubject = "jaeger.spans"
+
+	defaultConnectTimeout = 5 * time.Second
+)
+
+// Configuration describes the connection to a NATS server.
+type Configuration struct {
+	URL            string         `mapstructure:"url"`
+	CredsFile      string         `mapstructure:"creds_file"`
+	NKeyFile       string         `mapstructure:"nkey_file"`
+	ConnectTimeout time.Duration  `mapstructure:"connect_timeout"`
+	TLS            tlscfg.Options `mapstructure:"tls"`
+}
+
+// Options stores the configuration options of the NATS span writer.
+type Options struct {
+	Configuration `mapstructure:",squash"`
+	Stream        string `mapstructure:"stream"`
+	Subject       string `mapstructure:"subject"`
+}
+
+// Connect opens a connection to the NATS server.
+func (c *Configuration) Connect(logger *zap.Logger) (*natsio.Conn, error) {
+	options := []natsio.Option{
+		natsio.Name("jaeger"),
+		natsio.Timeout(c.ConnectTimeout),
+		natsio.DisconnectErrHandler(func(_ *natsio.Conn, err error) {
+			if err != nil {
+				logger.Warn("Disconnected from NATS", zap.Error(err))
+			}
+		}),
+		natsio.ReconnectHandler(func(conn *natsio.Conn) {
+			logger.Info("Reconnected to NATS", zap.String("url", conn.ConnectedUrlRedacted()))
+		}),
+	}
+	if c.CredsFile != "" {
+		options = append(options, natsio.UserCredentials(c.CredsFile))
+	}
+	if c.NKeyFile != "" {
+		nkeyOption, err := natsio.NkeyOptionFromSeed(c.NKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load NATS NKey seed: %w", err)
+		}
+		options = append(options, nkeyOption)
+	}
+	if c.TLS.Enabled {
+		tlsConfig, err := c.TLS.Config(logger)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, natsio.Secure(tlsConfig))
+	}
+	conn, err := natsio.Connect(c.URL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", c.URL, err)
+	}
+	return conn, nil
+}
+
+// AddFlags adds flags for Options
+func (*Options) AddFlags(flagSet *flag.FlagSet) {
+	AddFlagsWithPrefix(ProducerConfigPrefix, flagSet)
+}
+
+// AddFlagsWithPrefix adds the flags of the connection to a NATS server and of the JetStream stream
+// of the spans under the given prefix.
+func AddFlagsWithPrefix(prefix string, flagSet *flag.FlagSet) {
+	flagSet.String(
+		prefix+suffixURL,
+		DefaultURL,
+		"The comma-separated list of NATS server URLs. i.e. 'nats://127.0.0.1:4222,nats://0.0.0.0:4223'")
+	flagSet.String(
+		prefix+suffixCredsFile,
+		"",
+		"Path to a NATS user credentials file (JWT and NKey seed) used to authenticate")
+	flagSet.String(
+		prefix+suffixNKeyFile,
+		"",
+		"Path to a NATS NKey seed file used to authenticate")
+	flagSet.Duration(
+		prefix+suffixConnectTimeout,
+		defaultConnectTimeout,
+		"The timeout of the connection to the NATS server")
+	flagSet.String(
+		prefix+suffixStream,
+		DefaultStream,
+		"The name of the JetStream stream of the spans, created when missing")
+	flagSet.String(
+		prefix+suffixSubject,
+		DefaultSubject,
+		"The NATS subject of the spans")
+	tlscfg.ClientFlagsConfig{Prefix: prefix}.AddFlags(flagSet)
+}
+
+// InitFromViper initializes Options with properties from viper
+func (opt *Options) InitFromViper(v *viper.Viper) error {
+	return opt.InitFromViperWithPrefix(ProducerConfigPrefix, v)
+}
+
+// InitFromViperWithPrefix initializes Options with the connection and stream flags under the given prefix.
+func (opt *Options) InitFromViperWithPrefix(prefix string, v *viper.Viper) error {
+	opt.URL = v.GetString(prefix + suffixURL)
+	opt.CredsFile = v.GetString(prefix + suffixCredsFile)
+	opt.NKeyFile = v.GetString(prefix + suffixNKeyFile)
+	opt.ConnectTimeout = v.GetDuration(prefix + suffixConnectTimeout)
+	var err error
+	opt.TLS, err = tlscfg.ClientFlagsConfig{Prefix: prefix}.InitFromViper(v)
+	if err != nil {
+		return fmt.Errorf("failed to parse NATS TLS options: %w", err)
+	}
+	opt.Stream = v.GetString(prefix + suffixStream)
+	opt.Subject = v.GetString(prefix + suffixSubject)
+	return nil
+}
+
+// EnsureStream returns the stream of the spans, creating it when missing.
+// An existing stream is left as configured by the operators.
+func (opt *Options) EnsureStream(ctx context.Context, js jetstream.JetStream) (jetstream.Stream, error) {
+	stream, err := js.Stream(ctx, opt.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		stream, err = js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     opt.Stream,
+			Subjects: []string{opt.Subject},
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the JetStream stream %s: %w", opt.Stream, err)
+	}
+	return stream, nil
+}
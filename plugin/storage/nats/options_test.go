@@ -0,0 +1,80 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/pkg/config"
+)
+
+func TestOptionsWithFlags(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--nats.producer.url=nats://nats-1:4222,nats://nats-2:4222",
+		"--nats.producer.creds-file=/etc/nats/jaeger.creds",
+		"--nats.producer.nkey-file=/etc/nats/jaeger.nk",
+		"--nats.producer.connect-timeout=1s",
+		"--nats.producer.stream=spans",
+		"--nats.producer.subject=spans.in",
+		"--nats.producer.tls.enabled=true",
+		"--nats.producer.tls.server-name=nats",
+	}))
+	require.NoError(t, opts.InitFromViper(v))
+
+	assert.Equal(t, "nats://nats-1:4222,nats://nats-2:4222", opts.URL)
+	assert.Equal(t, "/etc/nats/jaeger.creds", opts.CredsFile)
+	assert.Equal(t, "/etc/nats/jaeger.nk", opts.NKeyFile)
+	assert.Equal(t, time.Second, opts.ConnectTimeout)
+	assert.Equal(t, "spans", opts.Stream)
+	assert.Equal(t, "spans.in", opts.Subject)
+	assert.True(t, opts.TLS.Enabled)
+	assert.Equal(t, "nats", opts.TLS.ServerName)
+}
+
+func TestOptionsDefaults(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{}))
+	require.NoError(t, opts.InitFromViper(v))
+
+	assert.Equal(t, DefaultURL, opts.URL)
+	assert.Equal(t, DefaultStream, opts.Stream)
+	assert.Equal(t, DefaultSubject, opts.Subject)
+	assert.Equal(t, defaultConnectTimeout, opts.ConnectTimeout)
+	assert.Empty(t, opts.CredsFile)
+	assert.False(t, opts.TLS.Enabled)
+}
+
+func TestOptionsInvalidTLS(t *testing.T) {
+	opts := &Options{}
+	v, command := config.Viperize(opts.AddFlags)
+	require.NoError(t, command.ParseFlags([]string{
+		"--nats.producer.tls.enabled=true",
+		"--nats.producer.tls.min-version=1.7",
+	}))
+	require.NoError(t, opts.InitFromViper(v))
+
+	_, err := opts.Connect(zap.NewNop())
+	require.ErrorContains(t, err, "failed to get minimum tls version")
+}
+
+func TestConnectErrors(t *testing.T) {
+	t.Run("missing nkey seed", func(t *testing.T) {
+		opts := &Options{Configuration: Configuration{URL: DefaultURL, NKeyFile: "/does/not/exist.nk"}}
+		_, err := opts.Connect(zap.NewNop())
+		require.ErrorContains(t, err, "failed to load NATS NKey seed")
+	})
+	t.Run("no server", func(t *testing.T) {
+		opts := &Options{Configuration: Configuration{URL: "nats://127.0.0.1:1", ConnectTimeout: time.Second}}
+		_, err := opts.Connect(zap.NewNop())
+		require.ErrorContains(t, err, "failed to connect to NATS at nats://127.0.0.1:1")
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"context"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+)
+
+type spanWriterMetrics struct {
+	SpansWrittenSuccess metrics.Counter
+	SpansWrittenFailure metrics.Counter
+}
+
+// SpanWriter publishes spans encoded as Protobuf to a JetStream subject. Implements spanstore.Writer
+type SpanWriter struct {
+	metrics   spanWriterMetrics
+	jetStream jetstream.JetStream
+	subject   string
+}
+
+// NewSpanWriter creates a new NATS span writer
+func NewSpanWriter(jetStream jetstream.JetStream, subject string, factory metrics.Factory) *SpanWriter {
+	return &SpanWriter{
+		jetStream: jetStream,
+		subject:   subject,
+		metrics: spanWriterMetrics{
+			SpansWrittenSuccess: factory.Counter(metrics.Options{Name: "nats_spans_written", Tags: map[string]string{"status": "success"}}),
+			SpansWrittenFailure: factory.Counter(metrics.Options{Name: "nats_spans_written", Tags: map[string]string{"status": "failure"}}),
+		},
+	}
+}
+
+// WriteSpan publishes the span and waits for JetStream to acknowledge it has been stored.
+func (w *SpanWriter) WriteSpan(ctx context.Context, span *model.Span) error {
+	spanBytes, err := proto.Marshal(span)
+	if err != nil {
+		w.metrics.SpansWrittenFailure.Inc(1)
+		return err
+	}
+	if _, err := w.jetStream.Publish(ctx, w.subject, spanBytes); err != nil {
+		w.metrics.SpansWrittenFailure.Inc(1)
+		return err
+	}
+	w.metrics.SpansWrittenSuccess.Inc(1)
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaegertracing/jaeger/internal/metricstest"
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// Checks that NATS SpanWriter conforms to spanstore.Writer API
+var _ spanstore.Writer = &SpanWriter{}
+
+var sampleSpan = &model.Span{
+	TraceID:       model.NewTraceID(0, 1),
+	SpanID:        model.NewSpanID(2),
+	OperationName: "GET /orders",
+	StartTime:     time.Unix(1700000000, 0).UTC(),
+	Duration:      time.Millisecond,
+	Process:       model.NewProcess("frontend", nil),
+}
+
+func TestNATSWriter(t *testing.T) {
+	srv := startServer(t)
+	f := newTestFactory(t, srv.ClientURL())
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	require.NoError(t, f.Initialize(metricsFactory, zap.NewNop()))
+	defer f.Close()
+
+	writer, err := f.CreateSpanWriter()
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteSpan(context.Background(), sampleSpan))
+
+	stream, err := f.jetStream.Stream(context.Background(), DefaultStream)
+	require.NoError(t, err)
+	msg, err := stream.GetLastMsgForSubject(context.Background(), DefaultSubject)
+	require.NoError(t, err)
+	var span model.Span
+	require.NoError(t, proto.Unmarshal(msg.Data, &span))
+	assert.Equal(t, sampleSpan.TraceID, span.TraceID)
+	assert.Equal(t, sampleSpan.OperationName, span.OperationName)
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "nats_spans_written", Tags: map[string]string{"status": "success"}, Value: 1},
+		metricstest.ExpectedMetric{Name: "nats_spans_written", Tags: map[string]string{"status": "failure"}, Value: 0},
+	)
+}
+
+func TestNATSWriterNoStream(t *testing.T) {
+	srv := startServer(t)
+	f := newTestFactory(t, srv.ClientURL())
+	metricsFactory := metricstest.NewFactory(time.Second)
+	defer metricsFactory.Stop()
+	require.NoError(t, f.Initialize(metricsFactory, zap.NewNop()))
+	defer f.Close()
+
+	// no stream stores the subject, so JetStream cannot acknowledge the span
+	writer := NewSpanWriter(f.jetStream, "unknown.subject", metricsFactory)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.Error(t, writer.WriteSpan(ctx, sampleSpan))
+
+	metricsFactory.AssertCounterMetrics(t,
+		metricstest.ExpectedMetric{Name: "nats_spans_written", Tags: map[string]string{"status": "success"}, Value: 0},
+		metricstest.ExpectedMetric{Name: "nats_spans_written", Tags: map[string]string{"status": "failure"}, Value: 1},
+	)
+}
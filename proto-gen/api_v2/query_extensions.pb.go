@@ -0,0 +1,4168 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: query_extensions.proto
+
+package api_v2
+
+import (
+	context "context"
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	_ "github.com/gogo/protobuf/types"
+	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
+	github_com_jaegertracing_jaeger_model "github.com/jaegertracing/jaeger/model"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	time "time"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+var _ = time.Kitchen
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type GetOperationLatenciesResponse_Source int32
+
+const (
+	// METRICS is when the latencies come from the metrics store.
+	GetOperationLatenciesResponse_METRICS GetOperationLatenciesResponse_Source = 0
+	// TRACES is when the latencies are computed from a sample of the traces.
+	GetOperationLatenciesResponse_TRACES GetOperationLatenciesResponse_Source = 1
+)
+
+var GetOperationLatenciesResponse_Source_name = map[int32]string{
+	0: "METRICS",
+	1: "TRACES",
+}
+
+var GetOperationLatenciesResponse_Source_value = map[string]int32{
+	"METRICS": 0,
+	"TRACES":  1,
+}
+
+func (x GetOperationLatenciesResponse_Source) String() string {
+	return proto.EnumName(GetOperationLatenciesResponse_Source_name, int32(x))
+}
+
+func (GetOperationLatenciesResponse_Source) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{12, 0}
+}
+
+type GetCriticalPathRequest struct {
+	TraceID              github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
+	XXX_NoUnkeyedLiteral struct{}                                      `json:"-"`
+	XXX_unrecognized     []byte                                        `json:"-"`
+	XXX_sizecache        int32                                         `json:"-"`
+}
+
+func (m *GetCriticalPathRequest) Reset()         { *m = GetCriticalPathRequest{} }
+func (m *GetCriticalPathRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCriticalPathRequest) ProtoMessage()    {}
+func (*GetCriticalPathRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{0}
+}
+func (m *GetCriticalPathRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetCriticalPathRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetCriticalPathRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetCriticalPathRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCriticalPathRequest.Merge(m, src)
+}
+func (m *GetCriticalPathRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetCriticalPathRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCriticalPathRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCriticalPathRequest proto.InternalMessageInfo
+
+type GetCriticalPathResponse struct {
+	// span_ids are the IDs of the spans on the critical path, in order.
+	SpanIDs              []github_com_jaegertracing_jaeger_model.SpanID `protobuf:"bytes,1,rep,name=span_ids,json=spanIds,proto3,customtype=github.com/jaegertracing/jaeger/model.SpanID" json:"span_ids"`
+	XXX_NoUnkeyedLiteral struct{}                                       `json:"-"`
+	XXX_unrecognized     []byte                                         `json:"-"`
+	XXX_sizecache        int32                                          `json:"-"`
+}
+
+func (m *GetCriticalPathResponse) Reset()         { *m = GetCriticalPathResponse{} }
+func (m *GetCriticalPathResponse) String() string { return proto.CompactTextString(m) }
+func (*GetCriticalPathResponse) ProtoMessage()    {}
+func (*GetCriticalPathResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{1}
+}
+func (m *GetCriticalPathResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetCriticalPathResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetCriticalPathResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetCriticalPathResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCriticalPathResponse.Merge(m, src)
+}
+func (m *GetCriticalPathResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetCriticalPathResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCriticalPathResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCriticalPathResponse proto.InternalMessageInfo
+
+// ProfileOperation is an operation required in a trace by a profile.
+type ProfileOperation struct {
+	// service is the service of the operation, the operation matches any service if it is empty.
+	Service   string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Operation string `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	// max_duration bounds the duration of every span of the operation, zero means no bound.
+	MaxDuration          time.Duration `protobuf:"bytes,3,opt,name=max_duration,json=maxDuration,proto3,stdduration" json:"max_duration"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ProfileOperation) Reset()         { *m = ProfileOperation{} }
+func (m *ProfileOperation) String() string { return proto.CompactTextString(m) }
+func (*ProfileOperation) ProtoMessage()    {}
+func (*ProfileOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{2}
+}
+func (m *ProfileOperation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ProfileOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ProfileOperation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ProfileOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProfileOperation.Merge(m, src)
+}
+func (m *ProfileOperation) XXX_Size() int {
+	return m.Size()
+}
+func (m *ProfileOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProfileOperation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProfileOperation proto.InternalMessageInfo
+
+func (m *ProfileOperation) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *ProfileOperation) GetOperation() string {
+	if m != nil {
+		return m.Operation
+	}
+	return ""
+}
+
+func (m *ProfileOperation) GetMaxDuration() time.Duration {
+	if m != nil {
+		return m.MaxDuration
+	}
+	return 0
+}
+
+type CompareTraceRequest struct {
+	TraceID              github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
+	Operations           []ProfileOperation                            `protobuf:"bytes,2,rep,name=operations,proto3" json:"operations"`
+	XXX_NoUnkeyedLiteral struct{}                                      `json:"-"`
+	XXX_unrecognized     []byte                                        `json:"-"`
+	XXX_sizecache        int32                                         `json:"-"`
+}
+
+func (m *CompareTraceRequest) Reset()         { *m = CompareTraceRequest{} }
+func (m *CompareTraceRequest) String() string { return proto.CompactTextString(m) }
+func (*CompareTraceRequest) ProtoMessage()    {}
+func (*CompareTraceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{3}
+}
+func (m *CompareTraceRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CompareTraceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CompareTraceRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CompareTraceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareTraceRequest.Merge(m, src)
+}
+func (m *CompareTraceRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CompareTraceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareTraceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompareTraceRequest proto.InternalMessageInfo
+
+func (m *CompareTraceRequest) GetOperations() []ProfileOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+// ProfileCheck is the outcome of checking an operation of the profile against the trace.
+type ProfileCheck struct {
+	Operation ProfileOperation `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation"`
+	Passed    bool             `protobuf:"varint,2,opt,name=passed,proto3" json:"passed,omitempty"`
+	// spans is the number of spans of the operation in the trace.
+	Spans int32 `protobuf:"varint,3,opt,name=spans,proto3" json:"spans,omitempty"`
+	// longest_duration is the duration of the longest span of the operation.
+	LongestDuration time.Duration `protobuf:"bytes,4,opt,name=longest_duration,json=longestDuration,proto3,stdduration" json:"longest_duration"`
+	// on_critical_path tells whether a span of the operation is on the critical path of the trace.
+	OnCriticalPath bool `protobuf:"varint,5,opt,name=on_critical_path,json=onCriticalPath,proto3" json:"on_critical_path,omitempty"`
+	// message explains why the check failed.
+	Message              string   `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProfileCheck) Reset()         { *m = ProfileCheck{} }
+func (m *ProfileCheck) String() string { return proto.CompactTextString(m) }
+func (*ProfileCheck) ProtoMessage()    {}
+func (*ProfileCheck) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{4}
+}
+func (m *ProfileCheck) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ProfileCheck) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ProfileCheck.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ProfileCheck) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProfileCheck.Merge(m, src)
+}
+func (m *ProfileCheck) XXX_Size() int {
+	return m.Size()
+}
+func (m *ProfileCheck) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProfileCheck.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProfileCheck proto.InternalMessageInfo
+
+func (m *ProfileCheck) GetOperation() ProfileOperation {
+	if m != nil {
+		return m.Operation
+	}
+	return ProfileOperation{}
+}
+
+func (m *ProfileCheck) GetPassed() bool {
+	if m != nil {
+		return m.Passed
+	}
+	return false
+}
+
+func (m *ProfileCheck) GetSpans() int32 {
+	if m != nil {
+		return m.Spans
+	}
+	return 0
+}
+
+func (m *ProfileCheck) GetLongestDuration() time.Duration {
+	if m != nil {
+		return m.LongestDuration
+	}
+	return 0
+}
+
+func (m *ProfileCheck) GetOnCriticalPath() bool {
+	if m != nil {
+		return m.OnCriticalPath
+	}
+	return false
+}
+
+func (m *ProfileCheck) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+type CompareTraceResponse struct {
+	Passed               bool           `protobuf:"varint,1,opt,name=passed,proto3" json:"passed,omitempty"`
+	Checks               []ProfileCheck `protobuf:"bytes,2,rep,name=checks,proto3" json:"checks"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *CompareTraceResponse) Reset()         { *m = CompareTraceResponse{} }
+func (m *CompareTraceResponse) String() string { return proto.CompactTextString(m) }
+func (*CompareTraceResponse) ProtoMessage()    {}
+func (*CompareTraceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{5}
+}
+func (m *CompareTraceResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CompareTraceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CompareTraceResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CompareTraceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareTraceResponse.Merge(m, src)
+}
+func (m *CompareTraceResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *CompareTraceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareTraceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompareTraceResponse proto.InternalMessageInfo
+
+func (m *CompareTraceResponse) GetPassed() bool {
+	if m != nil {
+		return m.Passed
+	}
+	return false
+}
+
+func (m *CompareTraceResponse) GetChecks() []ProfileCheck {
+	if m != nil {
+		return m.Checks
+	}
+	return nil
+}
+
+type DryRunSearchRequest struct {
+	Query                *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *DryRunSearchRequest) Reset()         { *m = DryRunSearchRequest{} }
+func (m *DryRunSearchRequest) String() string { return proto.CompactTextString(m) }
+func (*DryRunSearchRequest) ProtoMessage()    {}
+func (*DryRunSearchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{6}
+}
+func (m *DryRunSearchRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DryRunSearchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DryRunSearchRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DryRunSearchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DryRunSearchRequest.Merge(m, src)
+}
+func (m *DryRunSearchRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DryRunSearchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DryRunSearchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DryRunSearchRequest proto.InternalMessageInfo
+
+func (m *DryRunSearchRequest) GetQuery() *TraceQueryParameters {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+type DryRunSearchResponse struct {
+	// query is the effective query of the search, after the defaults and the limits are applied.
+	Query *TraceQueryParameters `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// warnings report the adjustments of the query.
+	Warnings             []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DryRunSearchResponse) Reset()         { *m = DryRunSearchResponse{} }
+func (m *DryRunSearchResponse) String() string { return proto.CompactTextString(m) }
+func (*DryRunSearchResponse) ProtoMessage()    {}
+func (*DryRunSearchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{7}
+}
+func (m *DryRunSearchResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DryRunSearchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DryRunSearchResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DryRunSearchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DryRunSearchResponse.Merge(m, src)
+}
+func (m *DryRunSearchResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *DryRunSearchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DryRunSearchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DryRunSearchResponse proto.InternalMessageInfo
+
+func (m *DryRunSearchResponse) GetQuery() *TraceQueryParameters {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (m *DryRunSearchResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+// ArchiveTracesRequest selects the traces to archive either by their IDs or by a search query.
+type ArchiveTracesRequest struct {
+	TraceIDs []github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,rep,name=trace_ids,json=traceIds,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_ids"`
+	Query    *TraceQueryParameters                           `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	// max_traces caps the number of traces to archive, the server maximum if not positive.
+	MaxTraces            int32    `protobuf:"varint,3,opt,name=max_traces,json=maxTraces,proto3" json:"max_traces,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ArchiveTracesRequest) Reset()         { *m = ArchiveTracesRequest{} }
+func (m *ArchiveTracesRequest) String() string { return proto.CompactTextString(m) }
+func (*ArchiveTracesRequest) ProtoMessage()    {}
+func (*ArchiveTracesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{8}
+}
+func (m *ArchiveTracesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ArchiveTracesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ArchiveTracesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ArchiveTracesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ArchiveTracesRequest.Merge(m, src)
+}
+func (m *ArchiveTracesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ArchiveTracesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ArchiveTracesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ArchiveTracesRequest proto.InternalMessageInfo
+
+func (m *ArchiveTracesRequest) GetQuery() *TraceQueryParameters {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (m *ArchiveTracesRequest) GetMaxTraces() int32 {
+	if m != nil {
+		return m.MaxTraces
+	}
+	return 0
+}
+
+// ArchiveTracesResponse is the outcome of archiving one of the traces.
+type ArchiveTracesResponse struct {
+	TraceID  github_com_jaegertracing_jaeger_model.TraceID `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3,customtype=github.com/jaegertracing/jaeger/model.TraceID" json:"trace_id"`
+	Archived bool                                          `protobuf:"varint,2,opt,name=archived,proto3" json:"archived,omitempty"`
+	// error explains why the trace was not archived.
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ArchiveTracesResponse) Reset()         { *m = ArchiveTracesResponse{} }
+func (m *ArchiveTracesResponse) String() string { return proto.CompactTextString(m) }
+func (*ArchiveTracesResponse) ProtoMessage()    {}
+func (*ArchiveTracesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{9}
+}
+func (m *ArchiveTracesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ArchiveTracesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ArchiveTracesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ArchiveTracesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ArchiveTracesResponse.Merge(m, src)
+}
+func (m *ArchiveTracesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ArchiveTracesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ArchiveTracesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ArchiveTracesResponse proto.InternalMessageInfo
+
+func (m *ArchiveTracesResponse) GetArchived() bool {
+	if m != nil {
+		return m.Archived
+	}
+	return false
+}
+
+func (m *ArchiveTracesResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// GetOperationLatenciesRequest selects the operations of a service over a time window,
+// which defaults to the hour before start_time_max, itself defaulting to now.
+type GetOperationLatenciesRequest struct {
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// operation restricts the latencies to an operation of the service, all the operations if empty.
+	Operation    string    `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`
+	StartTimeMin time.Time `protobuf:"bytes,3,opt,name=start_time_min,json=startTimeMin,proto3,stdtime" json:"start_time_min"`
+	StartTimeMax time.Time `protobuf:"bytes,4,opt,name=start_time_max,json=startTimeMax,proto3,stdtime" json:"start_time_max"`
+	// max_samples caps the number of traces sampled when the latencies are computed from the traces.
+	MaxSamples           int32    `protobuf:"varint,5,opt,name=max_samples,json=maxSamples,proto3" json:"max_samples,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOperationLatenciesRequest) Reset()         { *m = GetOperationLatenciesRequest{} }
+func (m *GetOperationLatenciesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetOperationLatenciesRequest) ProtoMessage()    {}
+func (*GetOperationLatenciesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{10}
+}
+func (m *GetOperationLatenciesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetOperationLatenciesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetOperationLatenciesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetOperationLatenciesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOperationLatenciesRequest.Merge(m, src)
+}
+func (m *GetOperationLatenciesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetOperationLatenciesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOperationLatenciesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOperationLatenciesRequest proto.InternalMessageInfo
+
+func (m *GetOperationLatenciesRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *GetOperationLatenciesRequest) GetOperation() string {
+	if m != nil {
+		return m.Operation
+	}
+	return ""
+}
+
+func (m *GetOperationLatenciesRequest) GetStartTimeMin() time.Time {
+	if m != nil {
+		return m.StartTimeMin
+	}
+	return time.Time{}
+}
+
+func (m *GetOperationLatenciesRequest) GetStartTimeMax() time.Time {
+	if m != nil {
+		return m.StartTimeMax
+	}
+	return time.Time{}
+}
+
+func (m *GetOperationLatenciesRequest) GetMaxSamples() int32 {
+	if m != nil {
+		return m.MaxSamples
+	}
+	return 0
+}
+
+type OperationLatency struct {
+	Operation string `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	// samples is the number of spans the latencies are computed from, zero for the metrics store.
+	Samples              int32         `protobuf:"varint,2,opt,name=samples,proto3" json:"samples,omitempty"`
+	P50                  time.Duration `protobuf:"bytes,3,opt,name=p50,proto3,stdduration" json:"p50"`
+	P95                  time.Duration `protobuf:"bytes,4,opt,name=p95,proto3,stdduration" json:"p95"`
+	P99                  time.Duration `protobuf:"bytes,5,opt,name=p99,proto3,stdduration" json:"p99"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *OperationLatency) Reset()         { *m = OperationLatency{} }
+func (m *OperationLatency) String() string { return proto.CompactTextString(m) }
+func (*OperationLatency) ProtoMessage()    {}
+func (*OperationLatency) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{11}
+}
+func (m *OperationLatency) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OperationLatency) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OperationLatency.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OperationLatency) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OperationLatency.Merge(m, src)
+}
+func (m *OperationLatency) XXX_Size() int {
+	return m.Size()
+}
+func (m *OperationLatency) XXX_DiscardUnknown() {
+	xxx_messageInfo_OperationLatency.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OperationLatency proto.InternalMessageInfo
+
+func (m *OperationLatency) GetOperation() string {
+	if m != nil {
+		return m.Operation
+	}
+	return ""
+}
+
+func (m *OperationLatency) GetSamples() int32 {
+	if m != nil {
+		return m.Samples
+	}
+	return 0
+}
+
+func (m *OperationLatency) GetP50() time.Duration {
+	if m != nil {
+		return m.P50
+	}
+	return 0
+}
+
+func (m *OperationLatency) GetP95() time.Duration {
+	if m != nil {
+		return m.P95
+	}
+	return 0
+}
+
+func (m *OperationLatency) GetP99() time.Duration {
+	if m != nil {
+		return m.P99
+	}
+	return 0
+}
+
+type GetOperationLatenciesResponse struct {
+	Source               GetOperationLatenciesResponse_Source `protobuf:"varint,1,opt,name=source,proto3,enum=jaeger.api_v2.GetOperationLatenciesResponse_Source" json:"source,omitempty"`
+	Approximate          bool                                 `protobuf:"varint,2,opt,name=approximate,proto3" json:"approximate,omitempty"`
+	Operations           []OperationLatency                   `protobuf:"bytes,3,rep,name=operations,proto3" json:"operations"`
+	XXX_NoUnkeyedLiteral struct{}                             `json:"-"`
+	XXX_unrecognized     []byte                               `json:"-"`
+	XXX_sizecache        int32                                `json:"-"`
+}
+
+func (m *GetOperationLatenciesResponse) Reset()         { *m = GetOperationLatenciesResponse{} }
+func (m *GetOperationLatenciesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetOperationLatenciesResponse) ProtoMessage()    {}
+func (*GetOperationLatenciesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22ba8803742e15c4, []int{12}
+}
+func (m *GetOperationLatenciesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GetOperationLatenciesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GetOperationLatenciesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GetOperationLatenciesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOperationLatenciesResponse.Merge(m, src)
+}
+func (m *GetOperationLatenciesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *GetOperationLatenciesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOperationLatenciesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOperationLatenciesResponse proto.InternalMessageInfo
+
+func (m *GetOperationLatenciesResponse) GetSource() GetOperationLatenciesResponse_Source {
+	if m != nil {
+		return m.Source
+	}
+	return GetOperationLatenciesResponse_METRICS
+}
+
+func (m *GetOperationLatenciesResponse) GetApproximate() bool {
+	if m != nil {
+		return m.Approximate
+	}
+	return false
+}
+
+func (m *GetOperationLatenciesResponse) GetOperations() []OperationLatency {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("jaeger.api_v2.GetOperationLatenciesResponse_Source", GetOperationLatenciesResponse_Source_name, GetOperationLatenciesResponse_Source_value)
+	proto.RegisterType((*GetCriticalPathRequest)(nil), "jaeger.api_v2.GetCriticalPathRequest")
+	proto.RegisterType((*GetCriticalPathResponse)(nil), "jaeger.api_v2.GetCriticalPathResponse")
+	proto.RegisterType((*ProfileOperation)(nil), "jaeger.api_v2.ProfileOperation")
+	proto.RegisterType((*CompareTraceRequest)(nil), "jaeger.api_v2.CompareTraceRequest")
+	proto.RegisterType((*ProfileCheck)(nil), "jaeger.api_v2.ProfileCheck")
+	proto.RegisterType((*CompareTraceResponse)(nil), "jaeger.api_v2.CompareTraceResponse")
+	proto.RegisterType((*DryRunSearchRequest)(nil), "jaeger.api_v2.DryRunSearchRequest")
+	proto.RegisterType((*DryRunSearchResponse)(nil), "jaeger.api_v2.DryRunSearchResponse")
+	proto.RegisterType((*ArchiveTracesRequest)(nil), "jaeger.api_v2.ArchiveTracesRequest")
+	proto.RegisterType((*ArchiveTracesResponse)(nil), "jaeger.api_v2.ArchiveTracesResponse")
+	proto.RegisterType((*GetOperationLatenciesRequest)(nil), "jaeger.api_v2.GetOperationLatenciesRequest")
+	proto.RegisterType((*OperationLatency)(nil), "jaeger.api_v2.OperationLatency")
+	proto.RegisterType((*GetOperationLatenciesResponse)(nil), "jaeger.api_v2.GetOperationLatenciesResponse")
+}
+
+func init() { proto.RegisterFile("query_extensions.proto", fileDescriptor_22ba8803742e15c4) }
+
+var fileDescriptor_22ba8803742e15c4 = []byte{
+	// 1064 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xdd, 0x6e, 0x1b, 0x45,
+	0x14, 0xce, 0x38, 0xb1, 0x63, 0x1f, 0xbb, 0xa9, 0x35, 0x49, 0x13, 0xd7, 0xb4, 0xb1, 0xd9, 0x00,
+	0x8a, 0x44, 0xbb, 0xa9, 0x5c, 0xe5, 0x22, 0x57, 0x90, 0x38, 0xa1, 0x0a, 0x50, 0x08, 0xeb, 0x08,
+	0x89, 0x22, 0xd5, 0x9a, 0xd8, 0x53, 0x67, 0xc0, 0xbb, 0xb3, 0x9d, 0x99, 0x0d, 0xce, 0x53, 0xf0,
+	0x23, 0x21, 0xf1, 0x04, 0xbc, 0x03, 0x6f, 0xd0, 0xcb, 0x5e, 0x73, 0x11, 0x50, 0x5e, 0x80, 0x5b,
+	0x2e, 0xd1, 0xce, 0xcc, 0x3a, 0xf6, 0xda, 0x84, 0x24, 0x12, 0xbd, 0xf2, 0x9e, 0x99, 0xf3, 0x33,
+	0xe7, 0x3b, 0xe7, 0x3b, 0xc7, 0xb0, 0xfc, 0x32, 0xa2, 0xe2, 0xb4, 0x4d, 0x07, 0x8a, 0x06, 0x92,
+	0xf1, 0x40, 0xba, 0xa1, 0xe0, 0x8a, 0xe3, 0x5b, 0xdf, 0x10, 0xda, 0xa3, 0xc2, 0x25, 0x21, 0x6b,
+	0x9f, 0x34, 0xaa, 0x4b, 0x3d, 0xde, 0xe3, 0xfa, 0x66, 0x23, 0xfe, 0x32, 0x4a, 0xd5, 0xd5, 0x1e,
+	0xe7, 0xbd, 0x3e, 0xdd, 0xd0, 0xd2, 0x51, 0xf4, 0x62, 0xa3, 0x1b, 0x09, 0xa2, 0x18, 0x0f, 0xec,
+	0x7d, 0x2d, 0x7d, 0xaf, 0x98, 0x4f, 0xa5, 0x22, 0x7e, 0x68, 0x15, 0x8a, 0x3a, 0xba, 0x11, 0x9c,
+	0x08, 0x96, 0x9f, 0x50, 0xd5, 0x14, 0x4c, 0xb1, 0x0e, 0xe9, 0x1f, 0x10, 0x75, 0xec, 0xd1, 0x97,
+	0x11, 0x95, 0x0a, 0x7f, 0x0d, 0x79, 0x25, 0x48, 0x87, 0xb6, 0x59, 0xb7, 0x82, 0xea, 0x68, 0xbd,
+	0xb4, 0xf3, 0xe1, 0xab, 0xb3, 0xda, 0xcc, 0xef, 0x67, 0xb5, 0x87, 0x3d, 0xa6, 0x8e, 0xa3, 0x23,
+	0xb7, 0xc3, 0xfd, 0x0d, 0xf3, 0xe2, 0x58, 0x91, 0x05, 0x3d, 0x2b, 0x6d, 0xf8, 0xbc, 0x4b, 0xfb,
+	0xee, 0x61, 0x6c, 0xbd, 0xbf, 0x7b, 0x7e, 0x56, 0x9b, 0xb7, 0x9f, 0xde, 0xbc, 0xf6, 0xb8, 0xdf,
+	0x75, 0x22, 0x58, 0x99, 0x08, 0x2b, 0x43, 0x1e, 0x48, 0x8a, 0x9f, 0x41, 0x5e, 0x86, 0x24, 0x68,
+	0xb3, 0xae, 0xac, 0xa0, 0xfa, 0xec, 0x7a, 0x69, 0xe7, 0x03, 0x1b, 0xf7, 0xc1, 0xd5, 0xe2, 0xb6,
+	0x42, 0x12, 0x98, 0xb0, 0xe6, 0x4b, 0x7a, 0xf3, 0xb1, 0xc3, 0xfd, 0xae, 0x74, 0x7e, 0x42, 0x50,
+	0x3e, 0x10, 0xfc, 0x05, 0xeb, 0xd3, 0xcf, 0x43, 0x6a, 0x60, 0xc3, 0x15, 0x98, 0x97, 0x54, 0x9c,
+	0xb0, 0x0e, 0xd5, 0x79, 0x16, 0xbc, 0x44, 0xc4, 0xf7, 0xa0, 0xc0, 0x13, 0xb5, 0x4a, 0x46, 0xdf,
+	0x5d, 0x1c, 0xe0, 0x8f, 0xa0, 0xe4, 0x93, 0x41, 0x3b, 0x81, 0xbf, 0x32, 0x5b, 0x47, 0xeb, 0xc5,
+	0xc6, 0x5d, 0xd7, 0xe0, 0xef, 0x26, 0xf8, 0xbb, 0xbb, 0x56, 0x61, 0x27, 0x1f, 0xe7, 0xf1, 0xcb,
+	0x1f, 0x35, 0xe4, 0x15, 0x7d, 0x32, 0x48, 0x8e, 0x9d, 0xdf, 0x10, 0x2c, 0x36, 0xb9, 0x1f, 0x12,
+	0x41, 0x35, 0x4e, 0x6f, 0xa2, 0x00, 0x78, 0x0f, 0x60, 0x98, 0x89, 0xac, 0x64, 0xea, 0xb3, 0xeb,
+	0xc5, 0x46, 0xcd, 0x1d, 0xeb, 0x3f, 0x37, 0x8d, 0xd4, 0xce, 0x5c, 0x1c, 0xdf, 0x1b, 0x31, 0x74,
+	0x7e, 0xce, 0x40, 0xc9, 0xaa, 0x35, 0x8f, 0x69, 0xe7, 0x5b, 0xdc, 0x1c, 0x85, 0x0c, 0xd5, 0xd1,
+	0xd5, 0xdd, 0x8e, 0x20, 0xbb, 0x0c, 0xb9, 0x90, 0x48, 0x49, 0xbb, 0x1a, 0xf4, 0xbc, 0x67, 0x25,
+	0xbc, 0x04, 0xd9, 0xb8, 0x92, 0x52, 0x43, 0x9d, 0xf5, 0x8c, 0x80, 0x3f, 0x83, 0x72, 0x9f, 0x07,
+	0x3d, 0x2a, 0xd5, 0x45, 0x2d, 0xe6, 0xae, 0x5e, 0x8b, 0xdb, 0xd6, 0x38, 0xb9, 0xc2, 0xeb, 0x50,
+	0xe6, 0x41, 0xbb, 0x63, 0x7b, 0xb3, 0x1d, 0x12, 0x75, 0x5c, 0xc9, 0xea, 0x77, 0x2c, 0xf0, 0x60,
+	0xb4, 0x65, 0xe3, 0xce, 0xf1, 0xa9, 0x94, 0xa4, 0x47, 0x2b, 0x39, 0xd3, 0x39, 0x56, 0x74, 0x18,
+	0x2c, 0x8d, 0x97, 0xd4, 0x36, 0xf7, 0x45, 0x66, 0x68, 0x2c, 0xb3, 0x2d, 0xc8, 0x75, 0x62, 0xfc,
+	0x92, 0x52, 0xbc, 0x35, 0x1d, 0x33, 0x8d, 0xb1, 0xc5, 0xcb, 0x1a, 0x38, 0x07, 0xb0, 0xb8, 0x2b,
+	0x4e, 0xbd, 0x28, 0x68, 0x51, 0x22, 0x3a, 0x43, 0xfa, 0x6e, 0x41, 0x56, 0xf3, 0xdc, 0x16, 0x61,
+	0x2d, 0xe5, 0x50, 0x3f, 0xeb, 0x8b, 0x58, 0xe1, 0x80, 0x08, 0xe2, 0x53, 0x45, 0x85, 0xf4, 0x8c,
+	0x85, 0xe3, 0xc3, 0xd2, 0xb8, 0x47, 0xfb, 0xf8, 0x9b, 0xbb, 0xc4, 0x55, 0xc8, 0x7f, 0x47, 0x44,
+	0xc0, 0x82, 0x9e, 0xc9, 0xb0, 0xe0, 0x0d, 0x65, 0xe7, 0x35, 0x82, 0xa5, 0x6d, 0xd1, 0x39, 0x66,
+	0x27, 0x06, 0x2c, 0x99, 0xa4, 0xf0, 0x1c, 0x0a, 0x09, 0x01, 0x92, 0x51, 0xb0, 0x7d, 0x53, 0x06,
+	0xe4, 0xed, 0xa7, 0xf4, 0xf2, 0x96, 0x02, 0xf2, 0x22, 0x9f, 0xcc, 0xb5, 0xf3, 0xb9, 0x0f, 0x10,
+	0x73, 0x5f, 0xbb, 0x4a, 0xda, 0xb1, 0xe0, 0x93, 0x81, 0x49, 0xc0, 0xf9, 0x15, 0xc1, 0x9d, 0x54,
+	0x4a, 0x16, 0xc3, 0xff, 0x95, 0xd4, 0x55, 0xc8, 0x13, 0x13, 0x35, 0x61, 0xce, 0x50, 0x8e, 0xb9,
+	0x43, 0x85, 0xe0, 0x42, 0x3f, 0xb6, 0xe0, 0x19, 0xc1, 0xf9, 0x3e, 0x03, 0xf7, 0x9e, 0x50, 0x35,
+	0xe4, 0xe2, 0xa7, 0x44, 0xd1, 0xa0, 0xc3, 0x2e, 0x6a, 0x70, 0xd3, 0xe1, 0xf8, 0x31, 0x2c, 0x48,
+	0x45, 0x84, 0x6a, 0xc7, 0xdb, 0xa7, 0xed, 0xb3, 0x64, 0x3c, 0x56, 0x27, 0x28, 0x79, 0x98, 0xac,
+	0x27, 0xc3, 0xc9, 0x1f, 0x62, 0x4e, 0x96, 0xb4, 0x6d, 0x7c, 0xf3, 0x94, 0x4d, 0xf8, 0x22, 0x83,
+	0xca, 0xdc, 0xcd, 0x7c, 0x91, 0x01, 0xae, 0x41, 0x3c, 0x7b, 0xdb, 0x92, 0xf8, 0x61, 0x9f, 0x4a,
+	0xcd, 0xeb, 0xac, 0x17, 0xd7, 0xb2, 0x65, 0x4e, 0x9c, 0xbf, 0x10, 0x94, 0x53, 0x70, 0x9c, 0x8e,
+	0xe7, 0x8a, 0xd2, 0xb9, 0xc6, 0x18, 0x59, 0x7f, 0x19, 0xed, 0x2f, 0x11, 0xf1, 0x26, 0xcc, 0x86,
+	0x9b, 0x8f, 0xae, 0xb3, 0x19, 0x62, 0x7d, 0x6d, 0xb6, 0xb5, 0x79, 0x9d, 0x21, 0x16, 0xeb, 0x1b,
+	0xb3, 0xad, 0x4a, 0xf6, 0x5a, 0x66, 0x5b, 0xce, 0xdf, 0x08, 0xee, 0xff, 0x4b, 0x0f, 0xd8, 0xa6,
+	0xfd, 0x04, 0x72, 0x92, 0x47, 0xc2, 0xf6, 0xc0, 0x42, 0xe3, 0x71, 0x8a, 0x29, 0x97, 0x5a, 0xbb,
+	0x2d, 0x6d, 0xea, 0x59, 0x17, 0xb8, 0x0e, 0x45, 0x12, 0x86, 0x82, 0x0f, 0x98, 0x4f, 0x14, 0xb5,
+	0x7d, 0x3a, 0x7a, 0x94, 0xda, 0x4d, 0xb3, 0x53, 0x77, 0x53, 0xba, 0x44, 0x53, 0x76, 0xd3, 0xdb,
+	0x90, 0x33, 0xa1, 0x71, 0x11, 0xe6, 0x9f, 0xee, 0x1d, 0x7a, 0xfb, 0xcd, 0x56, 0x79, 0x06, 0x03,
+	0xe4, 0x0e, 0xbd, 0xed, 0xe6, 0x5e, 0xab, 0x8c, 0x1a, 0xa7, 0xb0, 0x38, 0x3a, 0xd0, 0x5b, 0xb6,
+	0xb5, 0x8f, 0xe0, 0x76, 0xea, 0xdf, 0x09, 0x7e, 0x77, 0x32, 0xe5, 0x29, 0x7f, 0x9a, 0xaa, 0xef,
+	0xfd, 0x97, 0x9a, 0xc1, 0xc4, 0x99, 0x69, 0x84, 0xb0, 0xa8, 0xf9, 0x6b, 0x27, 0x7b, 0x12, 0xfa,
+	0x2b, 0x28, 0x8d, 0x2e, 0x0e, 0xec, 0xa4, 0x1c, 0x4e, 0xf9, 0xa3, 0x50, 0x5d, 0xbb, 0x54, 0x67,
+	0x18, 0x51, 0xc1, 0x8a, 0x19, 0xe8, 0x5f, 0x92, 0x3e, 0xeb, 0x6a, 0x90, 0x46, 0xa2, 0x8e, 0x4e,
+	0xfc, 0x89, 0xa8, 0x53, 0x16, 0x4c, 0x75, 0xed, 0x52, 0x9d, 0x61, 0xd4, 0xc8, 0xe6, 0x69, 0xc7,
+	0x61, 0x12, 0xf1, 0x39, 0xdc, 0x1a, 0x1b, 0x90, 0x38, 0xed, 0x6e, 0xda, 0x46, 0xa8, 0xbe, 0x73,
+	0xb9, 0x52, 0x12, 0xf4, 0x11, 0x6a, 0xfc, 0x88, 0xe0, 0xee, 0x64, 0x4f, 0x26, 0xd1, 0x15, 0xdc,
+	0x99, 0xda, 0xb3, 0xf8, 0xfd, 0xab, 0x75, 0xb6, 0x79, 0xcd, 0x83, 0xeb, 0xd0, 0xc0, 0x99, 0xd9,
+	0x79, 0xf8, 0xea, 0x7c, 0x15, 0xbd, 0x3e, 0x5f, 0x45, 0x7f, 0x9e, 0xaf, 0x22, 0x58, 0x61, 0xdc,
+	0x1d, 0x9b, 0xf3, 0xd6, 0xcd, 0xb3, 0x9c, 0xf9, 0x3d, 0xca, 0x69, 0xe6, 0x3e, 0xfe, 0x67, 0x00,
+	0xf8, 0x45, 0xd1, 0x00, 0x2e, 0x0c, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CriticalPathServiceClient is the client API for CriticalPathService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CriticalPathServiceClient interface {
+	GetCriticalPath(ctx context.Context, in *GetCriticalPathRequest, opts ...grpc.CallOption) (*GetCriticalPathResponse, error)
+}
+
+type criticalPathServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCriticalPathServiceClient(cc *grpc.ClientConn) CriticalPathServiceClient {
+	return &criticalPathServiceClient{cc}
+}
+
+func (c *criticalPathServiceClient) GetCriticalPath(ctx context.Context, in *GetCriticalPathRequest, opts ...grpc.CallOption) (*GetCriticalPathResponse, error) {
+	out := new(GetCriticalPathResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.CriticalPathService/GetCriticalPath", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CriticalPathServiceServer is the server API for CriticalPathService service.
+type CriticalPathServiceServer interface {
+	GetCriticalPath(context.Context, *GetCriticalPathRequest) (*GetCriticalPathResponse, error)
+}
+
+// UnimplementedCriticalPathServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedCriticalPathServiceServer struct {
+}
+
+func (*UnimplementedCriticalPathServiceServer) GetCriticalPath(ctx context.Context, req *GetCriticalPathRequest) (*GetCriticalPathResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCriticalPath not implemented")
+}
+
+func RegisterCriticalPathServiceServer(s *grpc.Server, srv CriticalPathServiceServer) {
+	s.RegisterService(&_CriticalPathService_serviceDesc, srv)
+}
+
+func _CriticalPathService_GetCriticalPath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCriticalPathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CriticalPathServiceServer).GetCriticalPath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jaeger.api_v2.CriticalPathService/GetCriticalPath",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CriticalPathServiceServer).GetCriticalPath(ctx, req.(*GetCriticalPathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _CriticalPathService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "jaeger.api_v2.CriticalPathService",
+	HandlerType: (*CriticalPathServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCriticalPath",
+			Handler:    _CriticalPathService_GetCriticalPath_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "query_extensions.proto",
+}
+
+// TraceProfileServiceClient is the client API for TraceProfileService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TraceProfileServiceClient interface {
+	CompareTrace(ctx context.Context, in *CompareTraceRequest, opts ...grpc.CallOption) (*CompareTraceResponse, error)
+}
+
+type traceProfileServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTraceProfileServiceClient(cc *grpc.ClientConn) TraceProfileServiceClient {
+	return &traceProfileServiceClient{cc}
+}
+
+func (c *traceProfileServiceClient) CompareTrace(ctx context.Context, in *CompareTraceRequest, opts ...grpc.CallOption) (*CompareTraceResponse, error) {
+	out := new(CompareTraceResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.TraceProfileService/CompareTrace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TraceProfileServiceServer is the server API for TraceProfileService service.
+type TraceProfileServiceServer interface {
+	CompareTrace(context.Context, *CompareTraceRequest) (*CompareTraceResponse, error)
+}
+
+// UnimplementedTraceProfileServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedTraceProfileServiceServer struct {
+}
+
+func (*UnimplementedTraceProfileServiceServer) CompareTrace(ctx context.Context, req *CompareTraceRequest) (*CompareTraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareTrace not implemented")
+}
+
+func RegisterTraceProfileServiceServer(s *grpc.Server, srv TraceProfileServiceServer) {
+	s.RegisterService(&_TraceProfileService_serviceDesc, srv)
+}
+
+func _TraceProfileService_CompareTrace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareTraceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TraceProfileServiceServer).CompareTrace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jaeger.api_v2.TraceProfileService/CompareTrace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TraceProfileServiceServer).CompareTrace(ctx, req.(*CompareTraceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _TraceProfileService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "jaeger.api_v2.TraceProfileService",
+	HandlerType: (*TraceProfileServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CompareTrace",
+			Handler:    _TraceProfileService_CompareTrace_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "query_extensions.proto",
+}
+
+// SearchValidationServiceClient is the client API for SearchValidationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SearchValidationServiceClient interface {
+	DryRunSearch(ctx context.Context, in *DryRunSearchRequest, opts ...grpc.CallOption) (*DryRunSearchResponse, error)
+}
+
+type searchValidationServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSearchValidationServiceClient(cc *grpc.ClientConn) SearchValidationServiceClient {
+	return &searchValidationServiceClient{cc}
+}
+
+func (c *searchValidationServiceClient) DryRunSearch(ctx context.Context, in *DryRunSearchRequest, opts ...grpc.CallOption) (*DryRunSearchResponse, error) {
+	out := new(DryRunSearchResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.SearchValidationService/DryRunSearch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SearchValidationServiceServer is the server API for SearchValidationService service.
+type SearchValidationServiceServer interface {
+	DryRunSearch(context.Context, *DryRunSearchRequest) (*DryRunSearchResponse, error)
+}
+
+// UnimplementedSearchValidationServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedSearchValidationServiceServer struct {
+}
+
+func (*UnimplementedSearchValidationServiceServer) DryRunSearch(ctx context.Context, req *DryRunSearchRequest) (*DryRunSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DryRunSearch not implemented")
+}
+
+func RegisterSearchValidationServiceServer(s *grpc.Server, srv SearchValidationServiceServer) {
+	s.RegisterService(&_SearchValidationService_serviceDesc, srv)
+}
+
+func _SearchValidationService_DryRunSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DryRunSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchValidationServiceServer).DryRunSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jaeger.api_v2.SearchValidationService/DryRunSearch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchValidationServiceServer).DryRunSearch(ctx, req.(*DryRunSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _SearchValidationService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "jaeger.api_v2.SearchValidationService",
+	HandlerType: (*SearchValidationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DryRunSearch",
+			Handler:    _SearchValidationService_DryRunSearch_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "query_extensions.proto",
+}
+
+// TraceArchiveServiceClient is the client API for TraceArchiveService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TraceArchiveServiceClient interface {
+	// ArchiveTraces streams the outcome of each trace as soon as it is known. When the deadline
+	// of the request is exceeded, the archiving stops and the request fails after the outcomes
+	// of the traces archived so far.
+	ArchiveTraces(ctx context.Context, in *ArchiveTracesRequest, opts ...grpc.CallOption) (TraceArchiveService_ArchiveTracesClient, error)
+}
+
+type traceArchiveServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTraceArchiveServiceClient(cc *grpc.ClientConn) TraceArchiveServiceClient {
+	return &traceArchiveServiceClient{cc}
+}
+
+func (c *traceArchiveServiceClient) ArchiveTraces(ctx context.Context, in *ArchiveTracesRequest, opts ...grpc.CallOption) (TraceArchiveService_ArchiveTracesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TraceArchiveService_serviceDesc.Streams[0], "/jaeger.api_v2.TraceArchiveService/ArchiveTraces", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &traceArchiveServiceArchiveTracesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TraceArchiveService_ArchiveTracesClient interface {
+	Recv() (*ArchiveTracesResponse, error)
+	grpc.ClientStream
+}
+
+type traceArchiveServiceArchiveTracesClient struct {
+	grpc.ClientStream
+}
+
+func (x *traceArchiveServiceArchiveTracesClient) Recv() (*ArchiveTracesResponse, error) {
+	m := new(ArchiveTracesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TraceArchiveServiceServer is the server API for TraceArchiveService service.
+type TraceArchiveServiceServer interface {
+	// ArchiveTraces streams the outcome of each trace as soon as it is known. When the deadline
+	// of the request is exceeded, the archiving stops and the request fails after the outcomes
+	// of the traces archived so far.
+	ArchiveTraces(*ArchiveTracesRequest, TraceArchiveService_ArchiveTracesServer) error
+}
+
+// UnimplementedTraceArchiveServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedTraceArchiveServiceServer struct {
+}
+
+func (*UnimplementedTraceArchiveServiceServer) ArchiveTraces(req *ArchiveTracesRequest, srv TraceArchiveService_ArchiveTracesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ArchiveTraces not implemented")
+}
+
+func RegisterTraceArchiveServiceServer(s *grpc.Server, srv TraceArchiveServiceServer) {
+	s.RegisterService(&_TraceArchiveService_serviceDesc, srv)
+}
+
+func _TraceArchiveService_ArchiveTraces_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ArchiveTracesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TraceArchiveServiceServer).ArchiveTraces(m, &traceArchiveServiceArchiveTracesServer{stream})
+}
+
+type TraceArchiveService_ArchiveTracesServer interface {
+	Send(*ArchiveTracesResponse) error
+	grpc.ServerStream
+}
+
+type traceArchiveServiceArchiveTracesServer struct {
+	grpc.ServerStream
+}
+
+func (x *traceArchiveServiceArchiveTracesServer) Send(m *ArchiveTracesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _TraceArchiveService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "jaeger.api_v2.TraceArchiveService",
+	HandlerType: (*TraceArchiveServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ArchiveTraces",
+			Handler:       _TraceArchiveService_ArchiveTraces_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "query_extensions.proto",
+}
+
+// OperationLatenciesServiceClient is the client API for OperationLatenciesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type OperationLatenciesServiceClient interface {
+	GetOperationLatencies(ctx context.Context, in *GetOperationLatenciesRequest, opts ...grpc.CallOption) (*GetOperationLatenciesResponse, error)
+}
+
+type operationLatenciesServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewOperationLatenciesServiceClient(cc *grpc.ClientConn) OperationLatenciesServiceClient {
+	return &operationLatenciesServiceClient{cc}
+}
+
+func (c *operationLatenciesServiceClient) GetOperationLatencies(ctx context.Context, in *GetOperationLatenciesRequest, opts ...grpc.CallOption) (*GetOperationLatenciesResponse, error) {
+	out := new(GetOperationLatenciesResponse)
+	err := c.cc.Invoke(ctx, "/jaeger.api_v2.OperationLatenciesService/GetOperationLatencies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OperationLatenciesServiceServer is the server API for OperationLatenciesService service.
+type OperationLatenciesServiceServer interface {
+	GetOperationLatencies(context.Context, *GetOperationLatenciesRequest) (*GetOperationLatenciesResponse, error)
+}
+
+// UnimplementedOperationLatenciesServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedOperationLatenciesServiceServer struct {
+}
+
+func (*UnimplementedOperationLatenciesServiceServer) GetOperationLatencies(ctx context.Context, req *GetOperationLatenciesRequest) (*GetOperationLatenciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperationLatencies not implemented")
+}
+
+func RegisterOperationLatenciesServiceServer(s *grpc.Server, srv OperationLatenciesServiceServer) {
+	s.RegisterService(&_OperationLatenciesService_serviceDesc, srv)
+}
+
+func _OperationLatenciesService_GetOperationLatencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOperationLatenciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperationLatenciesServiceServer).GetOperationLatencies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/jaeger.api_v2.OperationLatenciesService/GetOperationLatencies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperationLatenciesServiceServer).GetOperationLatencies(ctx, req.(*GetOperationLatenciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _OperationLatenciesService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "jaeger.api_v2.OperationLatenciesService",
+	HandlerType: (*OperationLatenciesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetOperationLatencies",
+			Handler:    _OperationLatenciesService_GetOperationLatencies_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "query_extensions.proto",
+}
+
+func (m *GetCriticalPathRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetCriticalPathRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetCriticalPathRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	{
+		size := m.TraceID.Size()
+		i -= size
+		if _, err := m.TraceID.MarshalTo(dAtA[i:]); err != nil {
+			return 0, err
+		}
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *GetCriticalPathResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetCriticalPathResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetCriticalPathResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.SpanIDs) > 0 {
+		for iNdEx := len(m.SpanIDs) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.SpanIDs[iNdEx].Size()
+				i -= size
+				if _, err := m.SpanIDs[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ProfileOperation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProfileOperation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProfileOperation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	n1, err1 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxDuration):])
+	if err1 != nil {
+		return 0, err1
+	}
+	i -= n1
+	i = encodeVarintQueryExtensions(dAtA, i, uint64(n1))
+	i--
+	dAtA[i] = 0x1a
+	if len(m.Operation) > 0 {
+		i -= len(m.Operation)
+		copy(dAtA[i:], m.Operation)
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Operation)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Service) > 0 {
+		i -= len(m.Service)
+		copy(dAtA[i:], m.Service)
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Service)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CompareTraceRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CompareTraceRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CompareTraceRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Operations) > 0 {
+		for iNdEx := len(m.Operations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Operations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	{
+		size := m.TraceID.Size()
+		i -= size
+		if _, err := m.TraceID.MarshalTo(dAtA[i:]); err != nil {
+			return 0, err
+		}
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *ProfileCheck) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ProfileCheck) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ProfileCheck) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Message) > 0 {
+		i -= len(m.Message)
+		copy(dAtA[i:], m.Message)
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Message)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.OnCriticalPath {
+		i--
+		if m.OnCriticalPath {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	n2, err2 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.LongestDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.LongestDuration):])
+	if err2 != nil {
+		return 0, err2
+	}
+	i -= n2
+	i = encodeVarintQueryExtensions(dAtA, i, uint64(n2))
+	i--
+	dAtA[i] = 0x22
+	if m.Spans != 0 {
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(m.Spans))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Passed {
+		i--
+		if m.Passed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	{
+		size, err := m.Operation.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *CompareTraceResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CompareTraceResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CompareTraceResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Checks) > 0 {
+		for iNdEx := len(m.Checks) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Checks[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Passed {
+		i--
+		if m.Passed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DryRunSearchRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DryRunSearchRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DryRunSearchRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Query != nil {
+		{
+			size, err := m.Query.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DryRunSearchResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DryRunSearchResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DryRunSearchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Warnings) > 0 {
+		for iNdEx := len(m.Warnings) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Warnings[iNdEx])
+			copy(dAtA[i:], m.Warnings[iNdEx])
+			i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Warnings[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Query != nil {
+		{
+			size, err := m.Query.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ArchiveTracesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ArchiveTracesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ArchiveTracesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.MaxTraces != 0 {
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(m.MaxTraces))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Query != nil {
+		{
+			size, err := m.Query.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.TraceIDs) > 0 {
+		for iNdEx := len(m.TraceIDs) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.TraceIDs[iNdEx].Size()
+				i -= size
+				if _, err := m.TraceIDs[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ArchiveTracesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ArchiveTracesResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ArchiveTracesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Archived {
+		i--
+		if m.Archived {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	{
+		size := m.TraceID.Size()
+		i -= size
+		if _, err := m.TraceID.MarshalTo(dAtA[i:]); err != nil {
+			return 0, err
+		}
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *GetOperationLatenciesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetOperationLatenciesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetOperationLatenciesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.MaxSamples != 0 {
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(m.MaxSamples))
+		i--
+		dAtA[i] = 0x28
+	}
+	n7, err7 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTimeMax, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.StartTimeMax):])
+	if err7 != nil {
+		return 0, err7
+	}
+	i -= n7
+	i = encodeVarintQueryExtensions(dAtA, i, uint64(n7))
+	i--
+	dAtA[i] = 0x22
+	n8, err8 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.StartTimeMin, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.StartTimeMin):])
+	if err8 != nil {
+		return 0, err8
+	}
+	i -= n8
+	i = encodeVarintQueryExtensions(dAtA, i, uint64(n8))
+	i--
+	dAtA[i] = 0x1a
+	if len(m.Operation) > 0 {
+		i -= len(m.Operation)
+		copy(dAtA[i:], m.Operation)
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Operation)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Service) > 0 {
+		i -= len(m.Service)
+		copy(dAtA[i:], m.Service)
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Service)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OperationLatency) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OperationLatency) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OperationLatency) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	n9, err9 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.P99, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.P99):])
+	if err9 != nil {
+		return 0, err9
+	}
+	i -= n9
+	i = encodeVarintQueryExtensions(dAtA, i, uint64(n9))
+	i--
+	dAtA[i] = 0x2a
+	n10, err10 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.P95, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.P95):])
+	if err10 != nil {
+		return 0, err10
+	}
+	i -= n10
+	i = encodeVarintQueryExtensions(dAtA, i, uint64(n10))
+	i--
+	dAtA[i] = 0x22
+	n11, err11 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.P50, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.P50):])
+	if err11 != nil {
+		return 0, err11
+	}
+	i -= n11
+	i = encodeVarintQueryExtensions(dAtA, i, uint64(n11))
+	i--
+	dAtA[i] = 0x1a
+	if m.Samples != 0 {
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(m.Samples))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Operation) > 0 {
+		i -= len(m.Operation)
+		copy(dAtA[i:], m.Operation)
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(len(m.Operation)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *GetOperationLatenciesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetOperationLatenciesResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetOperationLatenciesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Operations) > 0 {
+		for iNdEx := len(m.Operations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Operations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintQueryExtensions(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.Approximate {
+		i--
+		if m.Approximate {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Source != 0 {
+		i = encodeVarintQueryExtensions(dAtA, i, uint64(m.Source))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintQueryExtensions(dAtA []byte, offset int, v uint64) int {
+	offset -= sovQueryExtensions(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *GetCriticalPathRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.TraceID.Size()
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetCriticalPathResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.SpanIDs) > 0 {
+		for _, e := range m.SpanIDs {
+			l = e.Size()
+			n += 1 + l + sovQueryExtensions(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProfileOperation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Service)
+	if l > 0 {
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	l = len(m.Operation)
+	if l > 0 {
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxDuration)
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *CompareTraceRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.TraceID.Size()
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if len(m.Operations) > 0 {
+		for _, e := range m.Operations {
+			l = e.Size()
+			n += 1 + l + sovQueryExtensions(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProfileCheck) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.Operation.Size()
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if m.Passed {
+		n += 2
+	}
+	if m.Spans != 0 {
+		n += 1 + sovQueryExtensions(uint64(m.Spans))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.LongestDuration)
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if m.OnCriticalPath {
+		n += 2
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *CompareTraceResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Passed {
+		n += 2
+	}
+	if len(m.Checks) > 0 {
+		for _, e := range m.Checks {
+			l = e.Size()
+			n += 1 + l + sovQueryExtensions(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DryRunSearchRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DryRunSearchResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovQueryExtensions(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ArchiveTracesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TraceIDs) > 0 {
+		for _, e := range m.TraceIDs {
+			l = e.Size()
+			n += 1 + l + sovQueryExtensions(uint64(l))
+		}
+	}
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	if m.MaxTraces != 0 {
+		n += 1 + sovQueryExtensions(uint64(m.MaxTraces))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ArchiveTracesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.TraceID.Size()
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if m.Archived {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetOperationLatenciesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Service)
+	if l > 0 {
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	l = len(m.Operation)
+	if l > 0 {
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.StartTimeMin)
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.StartTimeMax)
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if m.MaxSamples != 0 {
+		n += 1 + sovQueryExtensions(uint64(m.MaxSamples))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *OperationLatency) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Operation)
+	if l > 0 {
+		n += 1 + l + sovQueryExtensions(uint64(l))
+	}
+	if m.Samples != 0 {
+		n += 1 + sovQueryExtensions(uint64(m.Samples))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.P50)
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.P95)
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.P99)
+	n += 1 + l + sovQueryExtensions(uint64(l))
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *GetOperationLatenciesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Source != 0 {
+		n += 1 + sovQueryExtensions(uint64(m.Source))
+	}
+	if m.Approximate {
+		n += 2
+	}
+	if len(m.Operations) > 0 {
+		for _, e := range m.Operations {
+			l = e.Size()
+			n += 1 + l + sovQueryExtensions(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovQueryExtensions(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozQueryExtensions(x uint64) (n int) {
+	return sovQueryExtensions(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *GetCriticalPathRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetCriticalPathRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetCriticalPathRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetCriticalPathResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetCriticalPathResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetCriticalPathResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SpanIDs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v github_com_jaegertracing_jaeger_model.SpanID
+			m.SpanIDs = append(m.SpanIDs, v)
+			if err := m.SpanIDs[len(m.SpanIDs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProfileOperation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProfileOperation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProfileOperation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Service", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Service = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operation", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operation = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxDuration", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.MaxDuration, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CompareTraceRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CompareTraceRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CompareTraceRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operations = append(m.Operations, ProfileOperation{})
+			if err := m.Operations[len(m.Operations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProfileCheck) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProfileCheck: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProfileCheck: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operation", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Operation.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Passed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Passed = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Spans", wireType)
+			}
+			m.Spans = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Spans |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LongestDuration", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.LongestDuration, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OnCriticalPath", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.OnCriticalPath = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CompareTraceResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CompareTraceResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CompareTraceResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Passed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Passed = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Checks = append(m.Checks, ProfileCheck{})
+			if err := m.Checks[len(m.Checks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DryRunSearchRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DryRunSearchRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DryRunSearchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &TraceQueryParameters{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DryRunSearchResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DryRunSearchResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DryRunSearchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &TraceQueryParameters{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ArchiveTracesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ArchiveTracesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ArchiveTracesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceIDs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v github_com_jaegertracing_jaeger_model.TraceID
+			m.TraceIDs = append(m.TraceIDs, v)
+			if err := m.TraceIDs[len(m.TraceIDs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &TraceQueryParameters{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxTraces", wireType)
+			}
+			m.MaxTraces = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxTraces |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ArchiveTracesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ArchiveTracesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ArchiveTracesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.TraceID.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Archived", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Archived = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetOperationLatenciesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetOperationLatenciesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetOperationLatenciesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Service", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Service = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operation", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operation = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeMin", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTimeMin, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTimeMax", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.StartTimeMax, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxSamples", wireType)
+			}
+			m.MaxSamples = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxSamples |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OperationLatency) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OperationLatency: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OperationLatency: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operation", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operation = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Samples", wireType)
+			}
+			m.Samples = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Samples |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field P50", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.P50, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field P95", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.P95, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field P99", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.P99, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetOperationLatenciesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetOperationLatenciesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetOperationLatenciesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
+			}
+			m.Source = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Source |= GetOperationLatenciesResponse_Source(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Approximate", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Approximate = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Operations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Operations = append(m.Operations, OperationLatency{})
+			if err := m.Operations[len(m.Operations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryExtensions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthQueryExtensions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipQueryExtensions(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowQueryExtensions
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowQueryExtensions
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthQueryExtensions
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupQueryExtensions
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthQueryExtensions
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthQueryExtensions        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowQueryExtensions          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupQueryExtensions = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,37 @@
+#!/bin/bash
+
+set -e
+
+export STORAGE=clickhouse
+compose_file="docker-compose/clickhouse/docker-compose.yml"
+
+echo "Starting ClickHouse using Docker Compose..."
+docker compose -f "${compose_file}" up -d clickhouse
+echo "docker_compose_file=${compose_file}" >> "${GITHUB_OUTPUT:-/dev/null}"
+
+# Check if ClickHouse is ready by pinging its HTTP interface
+is_clickhouse_ready() {
+    curl --silent --fail http://127.0.0.1:8123/ping >/dev/null 2>&1
+}
+
+# Set the timeout in seconds
+timeout=180
+# Set the interval between checks in seconds
+interval=5
+# Calculate the end time
+end_time=$((SECONDS + timeout))
+
+while [ $SECONDS -lt $end_time ]; do
+    if is_clickhouse_ready; then
+        break
+    fi
+    echo "ClickHouse not ready, waiting ${interval} seconds"
+    sleep $interval
+done
+
+if ! is_clickhouse_ready; then
+    echo "Timed out waiting for ClickHouse to start"
+    exit 1
+fi
+
+make storage-integration-test
@@ -31,3 +31,38 @@ type Writer interface {
 type Reader interface {
 	GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error)
 }
+
+// DependencyLinkErrors is the number of failed calls from the parent service to the child service.
+type DependencyLinkErrors struct {
+	Parent     string
+	Child      string
+	ErrorCount uint64
+}
+
+// ErrorCountReader is implemented by the readers which record the failed calls between services,
+// in addition to the number of calls of the dependency links.
+type ErrorCountReader interface {
+	GetDependencyErrors(ctx context.Context, endTs time.Time, lookback time.Duration) ([]DependencyLinkErrors, error)
+}
+
+// OperationDependencyLink is the number of calls from an operation of the parent service
+// to an operation of the child service.
+type OperationDependencyLink struct {
+	Parent          string
+	ParentOperation string
+	Child           string
+	ChildOperation  string
+	CallCount       uint64
+}
+
+// OperationDependencyReader is implemented by the readers which record the calls between the operations
+// of the services, in addition to the calls between the services.
+type OperationDependencyReader interface {
+	// GetOperationDependencies returns the calls from and to the operations of the service.
+	GetOperationDependencies(ctx context.Context, service string, endTs time.Time, lookback time.Duration) ([]OperationDependencyLink, error)
+}
+
+// OperationDependencyWriter is implemented by the writers which record the calls between the operations of the services.
+type OperationDependencyWriter interface {
+	WriteOperationDependencies(ts time.Time, dependencies []OperationDependencyLink) error
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"cmp"
+	"slices"
+)
+
+const (
+	// DefaultMaxOperationsPerService is the default number of operations of each service
+	// kept in the operation dependencies, see LimitOperations.
+	DefaultMaxOperationsPerService = 50
+
+	// OtherOperations replaces the operations of a service beyond its top operations.
+	OtherOperations = "__other__"
+)
+
+type serviceOperation struct {
+	service   string
+	operation string
+}
+
+// LimitOperations bounds the cardinality of the operation dependencies: the operations of each service
+// beyond its maxOperations operations with the most calls are merged into OtherOperations, or none are
+// if maxOperations is not positive. The links are returned by decreasing number of calls.
+func LimitOperations(links []OperationDependencyLink, maxOperations int) []OperationDependencyLink {
+	kept := make(map[serviceOperation]bool)
+	if maxOperations > 0 {
+		calls := make(map[serviceOperation]uint64)
+		for _, l := range links {
+			calls[serviceOperation{l.Parent, l.ParentOperation}] += l.CallCount
+			calls[serviceOperation{l.Child, l.ChildOperation}] += l.CallCount
+		}
+		operations := make(map[string][]serviceOperation)
+		for op := range calls {
+			operations[op.service] = append(operations[op.service], op)
+		}
+		for _, ops := range operations {
+			slices.SortFunc(ops, func(a, b serviceOperation) int {
+				if c := cmp.Compare(calls[b], calls[a]); c != 0 {
+					return c
+				}
+				return cmp.Compare(a.operation, b.operation)
+			})
+			for _, op := range ops[:min(maxOperations, len(ops))] {
+				kept[op] = true
+			}
+		}
+	}
+	limit := func(service, operation string) string {
+		if maxOperations > 0 && !kept[serviceOperation{service, operation}] {
+			return OtherOperations
+		}
+		return operation
+	}
+
+	type linkKey struct {
+		parent, parentOperation, child, childOperation string
+	}
+	merged := make(map[linkKey]*OperationDependencyLink)
+	result := make([]OperationDependencyLink, 0, len(links))
+	for _, l := range links {
+		l.ParentOperation = limit(l.Parent, l.ParentOperation)
+		l.ChildOperation = limit(l.Child, l.ChildOperation)
+		key := linkKey{l.Parent, l.ParentOperation, l.Child, l.ChildOperation}
+		if m, ok := merged[key]; ok {
+			m.CallCount += l.CallCount
+			continue
+		}
+		link := l
+		merged[key] = &link
+	}
+	for _, l := range merged {
+		result = append(result, *l)
+	}
+	slices.SortFunc(result, func(a, b OperationDependencyLink) int {
+		if c := cmp.Compare(b.CallCount, a.CallCount); c != 0 {
+			return c
+		}
+		for _, c := range []int{
+			cmp.Compare(a.Parent, b.Parent),
+			cmp.Compare(a.ParentOperation, b.ParentOperation),
+			cmp.Compare(a.Child, b.Child),
+		} {
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.ChildOperation, b.ChildOperation)
+	})
+	return result
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package dependencystore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitOperations(t *testing.T) {
+	links := []OperationDependencyLink{
+		{Parent: "frontend", ParentOperation: "/checkout", Child: "orders", ChildOperation: "create", CallCount: 10},
+		{Parent: "frontend", ParentOperation: "/cart", Child: "orders", ChildOperation: "get", CallCount: 5},
+		{Parent: "frontend", ParentOperation: "/health", Child: "orders", ChildOperation: "ping", CallCount: 2},
+		{Parent: "frontend", ParentOperation: "/ready", Child: "orders", ChildOperation: "ping", CallCount: 1},
+	}
+	assert.Equal(t, []OperationDependencyLink{
+		{Parent: "frontend", ParentOperation: "/checkout", Child: "orders", ChildOperation: "create", CallCount: 10},
+		{Parent: "frontend", ParentOperation: "/cart", Child: "orders", ChildOperation: "get", CallCount: 5},
+		{Parent: "frontend", ParentOperation: OtherOperations, Child: "orders", ChildOperation: OtherOperations, CallCount: 3},
+	}, LimitOperations(links, 2))
+
+	// the operations are kept, the links sorted by decreasing number of calls
+	assert.Equal(t, []OperationDependencyLink{
+		links[0],
+		links[1],
+		links[2],
+		links[3],
+	}, LimitOperations([]OperationDependencyLink{links[3], links[1], links[2], links[0]}, 0))
+}
+
+func TestLimitOperationsMergesDuplicates(t *testing.T) {
+	link := OperationDependencyLink{Parent: "a", ParentOperation: "x", Child: "b", ChildOperation: "y", CallCount: 2}
+	merged := link
+	merged.CallCount = 4
+	assert.Equal(t, []OperationDependencyLink{merged}, LimitOperations([]OperationDependencyLink{link, link}, 1))
+}
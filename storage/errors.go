@@ -0,0 +1,107 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/gocql/gocql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// ErrorCategory is a coarse classification of an error returned by a storage backend.
+// The set of categories is fixed, so it is safe to use as a metric label.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryTimeout is used for errors caused by a deadline being exceeded.
+	ErrorCategoryTimeout ErrorCategory = "timeout"
+	// ErrorCategoryCanceled is used for errors caused by the caller canceling the request.
+	ErrorCategoryCanceled ErrorCategory = "canceled"
+	// ErrorCategoryUnavailable is used for errors caused by the backend not being reachable.
+	ErrorCategoryUnavailable ErrorCategory = "unavailable"
+	// ErrorCategoryNotFound is used for errors caused by the requested data not existing.
+	ErrorCategoryNotFound ErrorCategory = "not_found"
+	// ErrorCategoryOther is used for all other errors.
+	ErrorCategoryOther ErrorCategory = "other"
+)
+
+// ClassifyError returns the category of a non-nil error returned by a storage backend.
+// Only transient categories (timeout, unavailable) are generally worth retrying.
+func ClassifyError(err error) ErrorCategory {
+	if errors.Is(err, spanstore.ErrTraceNotFound) {
+		return ErrorCategoryNotFound
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCategoryTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorCategoryCanceled
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		switch s.Code() {
+		case codes.DeadlineExceeded:
+			return ErrorCategoryTimeout
+		case codes.Canceled:
+			return ErrorCategoryCanceled
+		case codes.Unavailable:
+			return ErrorCategoryUnavailable
+		case codes.NotFound:
+			return ErrorCategoryNotFound
+		}
+	}
+	// the coordinator of a Cassandra query reports the replicas which did not respond in time or are down
+	var readTimeout *gocql.RequestErrReadTimeout
+	if errors.As(err, &readTimeout) || errors.Is(err, gocql.ErrTimeoutNoResponse) {
+		return ErrorCategoryTimeout
+	}
+	var cassandraUnavailable *gocql.RequestErrUnavailable
+	if errors.As(err, &cassandraUnavailable) || errors.Is(err, gocql.ErrNoConnections) {
+		return ErrorCategoryUnavailable
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorCategoryTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) {
+		return ErrorCategoryUnavailable
+	}
+	return ErrorCategoryOther
+}
+
+// IsTransientError returns whether a non-nil error returned by a storage backend is transient,
+// i.e. a timeout or the unavailability of the backend, a retry of the read possibly succeeding.
+func IsTransientError(err error) bool {
+	switch ClassifyError(err) {
+	case ErrorCategoryTimeout, ErrorCategoryUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+type readAttemptKey struct{}
+
+// ContextWithReadAttempt returns a context telling the storage readers that their read is a retry,
+// the attempt-th attempt of the read, e.g. to report it in the slow query log.
+func ContextWithReadAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, readAttemptKey{}, attempt)
+}
+
+// ReadAttemptFromContext returns the attempt of the read of the context, 1 if the read is not a retry.
+func ReadAttemptFromContext(ctx context.Context) int {
+	if attempt, ok := ctx.Value(readAttemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
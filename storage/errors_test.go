@@ -0,0 +1,71 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorCategory
+	}{
+		{name: "trace not found", err: fmt.Errorf("wrapped: %w", spanstore.ErrTraceNotFound), expected: ErrorCategoryNotFound},
+		{name: "deadline exceeded", err: fmt.Errorf("wrapped: %w", context.DeadlineExceeded), expected: ErrorCategoryTimeout},
+		{name: "canceled", err: context.Canceled, expected: ErrorCategoryCanceled},
+		{name: "grpc deadline exceeded", err: status.Error(codes.DeadlineExceeded, "slow"), expected: ErrorCategoryTimeout},
+		{name: "grpc canceled", err: status.Error(codes.Canceled, "gone"), expected: ErrorCategoryCanceled},
+		{name: "grpc unavailable", err: status.Error(codes.Unavailable, "down"), expected: ErrorCategoryUnavailable},
+		{name: "grpc not found", err: status.Error(codes.NotFound, "missing"), expected: ErrorCategoryNotFound},
+		{name: "grpc internal", err: status.Error(codes.Internal, "boom"), expected: ErrorCategoryOther},
+		{name: "net timeout", err: &net.OpError{Op: "read", Err: timeoutError{}}, expected: ErrorCategoryTimeout},
+		{name: "cassandra read timeout", err: fmt.Errorf("error reading traces from storage: %w", &gocql.RequestErrReadTimeout{}), expected: ErrorCategoryTimeout},
+		{name: "cassandra no response", err: gocql.ErrTimeoutNoResponse, expected: ErrorCategoryTimeout},
+		{name: "cassandra unavailable", err: &gocql.RequestErrUnavailable{}, expected: ErrorCategoryUnavailable},
+		{name: "cassandra no connections", err: gocql.ErrNoConnections, expected: ErrorCategoryUnavailable},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, expected: ErrorCategoryUnavailable},
+		{name: "connection reset", err: fmt.Errorf("wrapped: %w", syscall.ECONNRESET), expected: ErrorCategoryUnavailable},
+		{name: "other", err: errors.New("bad query"), expected: ErrorCategoryOther},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ClassifyError(test.err))
+		})
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, IsTransientError(context.DeadlineExceeded))
+	assert.True(t, IsTransientError(status.Error(codes.Unavailable, "down")))
+	assert.True(t, IsTransientError(&gocql.RequestErrReadTimeout{}))
+	assert.False(t, IsTransientError(spanstore.ErrTraceNotFound))
+	assert.False(t, IsTransientError(context.Canceled))
+	assert.False(t, IsTransientError(errors.New("bad query")))
+}
+
+func TestReadAttempt(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, 1, ReadAttemptFromContext(ctx))
+	assert.Equal(t, 3, ReadAttemptFromContext(ContextWithReadAttempt(ctx, 3)))
+}
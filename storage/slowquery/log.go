@@ -0,0 +1,215 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slowquery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/jaegertracing/jaeger/storage"
+)
+
+// Path is the path of the slow queries on the admin server.
+const Path = "/debug/slow-queries"
+
+const (
+	// logsPerSecond and logBurst bound the rate of the slow queries logged, the ones over it
+	// being only counted in the next entry logged and kept in the buffer.
+	logsPerSecond = 1
+	logBurst      = 10
+)
+
+// Options configure the slow query log.
+type Options struct {
+	// Threshold is the duration over which a storage read is a slow query, 0 disabling the log
+	Threshold time.Duration
+	// BufferSize is the number of the last slow queries kept in memory
+	BufferSize int
+}
+
+// Query is a slow storage read, with a summary of its parameters.
+type Query struct {
+	Time       time.Time
+	Operation  string
+	Service    string
+	SpanName   string
+	TraceID    string
+	TraceIDs   int
+	TimeWindow time.Duration
+	Limit      int
+	Tags       int
+	Duration   time.Duration
+	Results    int
+	Err        error
+	// Attempt is the attempt of the read when it is retried after a transient error, starting at 1
+	Attempt int
+}
+
+// Log records the storage reads slower than its threshold: it logs them, at a bounded rate,
+// and keeps the last ones in a ring buffer served on the admin server.
+type Log struct {
+	threshold time.Duration
+	logger    *zap.Logger
+	limiter   *rate.Limiter
+
+	mu         sync.Mutex
+	queries    []Query
+	next       int
+	full       bool
+	suppressed int
+}
+
+// NewLog returns the slow query log of the options.
+func NewLog(options Options, logger *zap.Logger) *Log {
+	return &Log{
+		threshold: options.Threshold,
+		logger:    logger,
+		limiter:   rate.NewLimiter(logsPerSecond, logBurst),
+		queries:   make([]Query, max(options.BufferSize, 1)),
+	}
+}
+
+// Record records the query if it is slower than the threshold.
+func (l *Log) Record(query Query) {
+	if query.Duration < l.threshold {
+		return
+	}
+	l.mu.Lock()
+	l.queries[l.next] = query
+	l.next = (l.next + 1) % len(l.queries)
+	l.full = l.full || l.next == 0
+	allowed := l.limiter.AllowN(query.Time, 1)
+	suppressed := l.suppressed
+	if allowed {
+		l.suppressed = 0
+	} else {
+		l.suppressed++
+	}
+	l.mu.Unlock()
+	if !allowed {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("operation", query.Operation),
+		zap.Duration("duration", query.Duration),
+		zap.Int("results", query.Results),
+	}
+	if query.Service != "" {
+		fields = append(fields, zap.String("service", query.Service))
+	}
+	if query.SpanName != "" {
+		fields = append(fields, zap.String("span_name", query.SpanName))
+	}
+	if query.TraceID != "" {
+		fields = append(fields, zap.String("trace_id", query.TraceID))
+	}
+	if query.TraceIDs > 0 {
+		fields = append(fields, zap.Int("trace_ids", query.TraceIDs))
+	}
+	if query.TimeWindow > 0 {
+		fields = append(fields, zap.Duration("time_window", query.TimeWindow))
+	}
+	if query.Limit > 0 {
+		fields = append(fields, zap.Int("limit", query.Limit))
+	}
+	if query.Tags > 0 {
+		fields = append(fields, zap.Int("tags", query.Tags))
+	}
+	if query.Err != nil {
+		fields = append(fields, zap.Error(query.Err))
+	}
+	if query.Attempt > 1 {
+		fields = append(fields, zap.Int("attempt", query.Attempt))
+	}
+	if suppressed > 0 {
+		fields = append(fields, zap.Int("suppressed", suppressed))
+	}
+	l.logger.Warn("Slow storage query", fields...)
+}
+
+// record records the query started at start, with the given results and error,
+// and the attempt of the read of the context.
+func (l *Log) record(ctx context.Context, query Query, start time.Time, results int, err error) {
+	query.Attempt = storage.ReadAttemptFromContext(ctx)
+	query.Time = start
+	query.Duration = time.Since(start)
+	query.Results = results
+	query.Err = err
+	l.Record(query)
+}
+
+// Queries returns the last slow queries, the most recent first.
+func (l *Log) Queries() []Query {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.next
+	if l.full {
+		count = len(l.queries)
+	}
+	queries := make([]Query, 0, count)
+	for i := 1; i <= count; i++ {
+		queries = append(queries, l.queries[(l.next-i+len(l.queries))%len(l.queries)])
+	}
+	return queries
+}
+
+type jsonQuery struct {
+	Time       time.Time `json:"time"`
+	Operation  string    `json:"operation"`
+	Service    string    `json:"service,omitempty"`
+	SpanName   string    `json:"spanName,omitempty"`
+	TraceID    string    `json:"traceID,omitempty"`
+	TraceIDs   int       `json:"traceIDs,omitempty"`
+	TimeWindow string    `json:"timeWindow,omitempty"`
+	Limit      int       `json:"limit,omitempty"`
+	Tags       int       `json:"tags,omitempty"`
+	Duration   string    `json:"duration"`
+	Results    int       `json:"results"`
+	Error      string    `json:"error,omitempty"`
+	Attempt    int       `json:"attempt,omitempty"`
+}
+
+// ServeHTTP serves the last slow queries as JSON, the most recent first.
+func (l *Log) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	queries := l.Queries()
+	response := struct {
+		Threshold string      `json:"threshold"`
+		Queries   []jsonQuery `json:"queries"`
+	}{
+		Threshold: l.threshold.String(),
+		Queries:   make([]jsonQuery, 0, len(queries)),
+	}
+	for _, query := range queries {
+		q := jsonQuery{
+			Time:      query.Time,
+			Operation: query.Operation,
+			Service:   query.Service,
+			SpanName:  query.SpanName,
+			TraceID:   query.TraceID,
+			TraceIDs:  query.TraceIDs,
+			Limit:     query.Limit,
+			Tags:      query.Tags,
+			Duration:  query.Duration.String(),
+			Results:   query.Results,
+		}
+		if query.TimeWindow > 0 {
+			q.TimeWindow = query.TimeWindow.String()
+		}
+		if query.Err != nil {
+			q.Error = query.Err.Error()
+		}
+		if query.Attempt > 1 {
+			q.Attempt = query.Attempt
+		}
+		response.Queries = append(response.Queries, q)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slowquery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObservedLog(options Options) (*Log, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.InfoLevel)
+	return NewLog(options, zap.New(core)), logs
+}
+
+func TestLogRecordsSlowQueries(t *testing.T) {
+	log, logs := newObservedLog(Options{Threshold: time.Second, BufferSize: 10})
+	now := time.Now()
+	log.Record(Query{Time: now, Operation: "get_services", Duration: time.Millisecond})
+	log.Record(Query{
+		Time:       now,
+		Operation:  "find_traces",
+		Service:    "frontend",
+		SpanName:   "HTTP GET",
+		TimeWindow: time.Hour,
+		Limit:      20,
+		Tags:       2,
+		Duration:   2 * time.Second,
+		Results:    5,
+		Err:        errors.New("timeout"),
+	})
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "Slow storage query", entry.Message)
+	assert.Equal(t, map[string]any{
+		"operation":   "find_traces",
+		"duration":    2 * time.Second,
+		"results":     int64(5),
+		"service":     "frontend",
+		"span_name":   "HTTP GET",
+		"time_window": time.Hour,
+		"limit":       int64(20),
+		"tags":        int64(2),
+		"error":       "timeout",
+	}, entry.ContextMap())
+	queries := log.Queries()
+	require.Len(t, queries, 1)
+	assert.Equal(t, "find_traces", queries[0].Operation)
+}
+
+func TestLogRateLimitsLogging(t *testing.T) {
+	log, logs := newObservedLog(Options{Threshold: time.Second, BufferSize: 100})
+	now := time.Now()
+	for i := 0; i < logBurst+5; i++ {
+		log.Record(Query{Time: now, Operation: "get_trace", Duration: time.Minute})
+	}
+	assert.Equal(t, logBurst, logs.Len())
+	assert.Len(t, log.Queries(), logBurst+5)
+
+	log.Record(Query{Time: now.Add(time.Second), Operation: "get_trace", Duration: time.Minute})
+	require.Equal(t, logBurst+1, logs.Len())
+	assert.Equal(t, int64(5), logs.All()[logBurst].ContextMap()["suppressed"])
+}
+
+func TestLogKeepsLastQueries(t *testing.T) {
+	log, _ := newObservedLog(Options{Threshold: time.Second, BufferSize: 3})
+	assert.Empty(t, log.Queries())
+	for _, limit := range []int{1, 2, 3, 4, 5} {
+		log.Record(Query{Operation: "find_traces", Limit: limit, Duration: time.Minute})
+	}
+	var limits []int
+	for _, query := range log.Queries() {
+		limits = append(limits, query.Limit)
+	}
+	assert.Equal(t, []int{5, 4, 3}, limits)
+}
+
+func TestLogServeHTTP(t *testing.T) {
+	log, _ := newObservedLog(Options{Threshold: time.Second, BufferSize: 10})
+	log.Record(Query{Operation: "get_dependencies", TimeWindow: 24 * time.Hour, Duration: 1500 * time.Millisecond, Results: 3})
+	log.Record(Query{Operation: "get_trace", TraceID: "abc", Duration: 2 * time.Second, Err: errors.New("timeout"), Attempt: 2})
+
+	w := httptest.NewRecorder()
+	log.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response struct {
+		Threshold string           `json:"threshold"`
+		Queries   []map[string]any `json:"queries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "1s", response.Threshold)
+	require.Len(t, response.Queries, 2)
+	assert.Equal(t, "get_trace", response.Queries[0]["operation"])
+	assert.Equal(t, "abc", response.Queries[0]["traceID"])
+	assert.Equal(t, "2s", response.Queries[0]["duration"])
+	assert.Equal(t, "timeout", response.Queries[0]["error"])
+	assert.InDelta(t, 2, response.Queries[0]["attempt"], 0)
+	assert.NotContains(t, response.Queries[1], "attempt")
+	assert.Equal(t, "get_dependencies", response.Queries[1]["operation"])
+	assert.Equal(t, "24h0m0s", response.Queries[1]["timeWindow"])
+	assert.Equal(t, "1.5s", response.Queries[1]["duration"])
+	assert.InDelta(t, 3, response.Queries[1]["results"], 0)
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slowquery
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+)
+
+// SpanReader wraps a spanstore.Reader and records its slow queries in a Log.
+type SpanReader struct {
+	spanReader spanstore.Reader
+	log        *Log
+}
+
+// NewSpanReader returns a new SpanReader.
+func NewSpanReader(spanReader spanstore.Reader, log *Log) *SpanReader {
+	return &SpanReader{
+		spanReader: spanReader,
+		log:        log,
+	}
+}
+
+func traceQuery(operation string, query *spanstore.TraceQueryParameters) Query {
+	q := Query{Operation: operation}
+	if query == nil {
+		return q
+	}
+	q.Service = query.ServiceName
+	q.SpanName = query.OperationName
+	q.Limit = query.NumTraces
+	q.Tags = len(query.Tags)
+	if !query.StartTimeMin.IsZero() && !query.StartTimeMax.IsZero() {
+		q.TimeWindow = query.StartTimeMax.Sub(query.StartTimeMin)
+	}
+	return q
+}
+
+// FindTraces implements spanstore.Reader#FindTraces
+func (r *SpanReader) FindTraces(ctx context.Context, query *spanstore.TraceQueryParameters) ([]*model.Trace, error) {
+	start := time.Now()
+	traces, err := r.spanReader.FindTraces(ctx, query)
+	r.log.record(ctx, traceQuery("find_traces", query), start, len(traces), err)
+	return traces, err
+}
+
+// FindTraceIDs implements spanstore.Reader#FindTraceIDs
+func (r *SpanReader) FindTraceIDs(ctx context.Context, query *spanstore.TraceQueryParameters) ([]model.TraceID, error) {
+	start := time.Now()
+	traceIDs, err := r.spanReader.FindTraceIDs(ctx, query)
+	r.log.record(ctx, traceQuery("find_trace_ids", query), start, len(traceIDs), err)
+	return traceIDs, err
+}
+
+// GetTrace implements spanstore.Reader#GetTrace, the results being the number of spans.
+func (r *SpanReader) GetTrace(ctx context.Context, traceID model.TraceID) (*model.Trace, error) {
+	start := time.Now()
+	trace, err := r.spanReader.GetTrace(ctx, traceID)
+	r.log.record(ctx, Query{Operation: "get_trace", TraceID: traceID.String()}, start, len(trace.GetSpans()), err)
+	return trace, err
+}
+
+// GetTraces implements spanstore.BatchReader#GetTraces, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.BatchReader.
+func (r *SpanReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	batchReader, ok := r.spanReader.(spanstore.BatchReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	start := time.Now()
+	traces, err := batchReader.GetTraces(ctx, traceIDs)
+	r.log.record(ctx, Query{Operation: "get_traces", TraceIDs: len(traceIDs)}, start, len(traces), err)
+	return traces, err
+}
+
+// GetTracePage implements spanstore.PagedReader#GetTracePage, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.PagedReader.
+func (r *SpanReader) GetTracePage(ctx context.Context, traceID model.TraceID, pageToken string) (*model.Trace, string, error) {
+	pagedReader, ok := r.spanReader.(spanstore.PagedReader)
+	if !ok {
+		return nil, "", errors.ErrUnsupported
+	}
+	start := time.Now()
+	trace, nextPageToken, err := pagedReader.GetTracePage(ctx, traceID, pageToken)
+	r.log.record(ctx, Query{Operation: "get_trace_page", TraceID: traceID.String()}, start, len(trace.GetSpans()), err)
+	return trace, nextPageToken, err
+}
+
+// DeleteTrace implements spanstore.TraceDeleter#DeleteTrace, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.TraceDeleter.
+func (r *SpanReader) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	deleter, ok := r.spanReader.(spanstore.TraceDeleter)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return deleter.DeleteTrace(ctx, traceID)
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix, it returns
+// errors.ErrUnsupported if the underlying reader is not a spanstore.TraceIDPrefixReader.
+func (r *SpanReader) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	prefixReader, ok := r.spanReader.(spanstore.TraceIDPrefixReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	start := time.Now()
+	traceIDs, err := prefixReader.FindTraceIDsByPrefix(ctx, prefix, limit)
+	r.log.record(ctx, Query{Operation: "find_trace_ids_by_prefix", TraceID: prefix, Limit: limit}, start, len(traceIDs), err)
+	return traceIDs, err
+}
+
+// GetTagKeys implements spanstore.TagKeysReader#GetTagKeys, it returns
+// errors.ErrUnsupported if the underlying reader is not a spanstore.TagKeysReader.
+func (r *SpanReader) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	tagKeysReader, ok := r.spanReader.(spanstore.TagKeysReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	start := time.Now()
+	keys, err := tagKeysReader.GetTagKeys(ctx, query)
+	r.log.record(ctx, Query{
+		Operation:  "get_tag_keys",
+		Service:    query.ServiceName,
+		TimeWindow: query.StartTimeMax.Sub(query.StartTimeMin),
+		Limit:      query.Limit,
+	}, start, len(keys), err)
+	return keys, err
+}
+
+// GetServices implements spanstore.Reader#GetServices
+func (r *SpanReader) GetServices(ctx context.Context) ([]string, error) {
+	start := time.Now()
+	services, err := r.spanReader.GetServices(ctx)
+	r.log.record(ctx, Query{Operation: "get_services"}, start, len(services), err)
+	return services, err
+}
+
+// GetOperations implements spanstore.Reader#GetOperations
+func (r *SpanReader) GetOperations(ctx context.Context, query spanstore.OperationQueryParameters) ([]spanstore.Operation, error) {
+	start := time.Now()
+	operations, err := r.spanReader.GetOperations(ctx, query)
+	r.log.record(ctx, Query{Operation: "get_operations", Service: query.ServiceName}, start, len(operations), err)
+	return operations, err
+}
+
+// NewDependencyReader returns a dependencystore.Reader recording the slow queries of dependencyReader
+// in the log. It is a dependencystore.ErrorCountReader and a dependencystore.OperationDependencyReader
+// if dependencyReader is.
+func NewDependencyReader(reader dependencystore.Reader, log *Log) dependencystore.Reader {
+	decorator := &dependencyReader{dependencyReader: reader, log: log}
+	errorCountReader, countsErrors := reader.(dependencystore.ErrorCountReader)
+	operationReader, readsOperations := reader.(dependencystore.OperationDependencyReader)
+	switch {
+	case countsErrors && readsOperations:
+		return &dependencyErrorCountOperationReader{
+			dependencyErrorCountReader: &dependencyErrorCountReader{dependencyReader: decorator, errorCountReader: errorCountReader},
+			operationReader:            &dependencyOperationReader{dependencyReader: decorator, operationReader: operationReader},
+		}
+	case countsErrors:
+		return &dependencyErrorCountReader{dependencyReader: decorator, errorCountReader: errorCountReader}
+	case readsOperations:
+		return &dependencyOperationReader{dependencyReader: decorator, operationReader: operationReader}
+	default:
+		return decorator
+	}
+}
+
+type dependencyReader struct {
+	dependencyReader dependencystore.Reader
+	log              *Log
+}
+
+// GetDependencies implements dependencystore.Reader#GetDependencies
+func (r *dependencyReader) GetDependencies(ctx context.Context, endTs time.Time, lookback time.Duration) ([]model.DependencyLink, error) {
+	start := time.Now()
+	dependencies, err := r.dependencyReader.GetDependencies(ctx, endTs, lookback)
+	r.log.record(ctx, Query{Operation: "get_dependencies", TimeWindow: lookback}, start, len(dependencies), err)
+	return dependencies, err
+}
+
+type dependencyErrorCountReader struct {
+	*dependencyReader
+	errorCountReader dependencystore.ErrorCountReader
+}
+
+// GetDependencyErrors implements dependencystore.ErrorCountReader#GetDependencyErrors
+func (r *dependencyErrorCountReader) GetDependencyErrors(
+	ctx context.Context,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.DependencyLinkErrors, error) {
+	start := time.Now()
+	linkErrors, err := r.errorCountReader.GetDependencyErrors(ctx, endTs, lookback)
+	r.log.record(ctx, Query{Operation: "get_dependency_errors", TimeWindow: lookback}, start, len(linkErrors), err)
+	return linkErrors, err
+}
+
+type dependencyOperationReader struct {
+	*dependencyReader
+	operationReader dependencystore.OperationDependencyReader
+}
+
+// GetOperationDependencies implements dependencystore.OperationDependencyReader#GetOperationDependencies
+func (r *dependencyOperationReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	start := time.Now()
+	links, err := r.operationReader.GetOperationDependencies(ctx, service, endTs, lookback)
+	r.log.record(ctx, Query{Operation: "get_operation_dependencies", Service: service, TimeWindow: lookback}, start, len(links), err)
+	return links, err
+}
+
+type dependencyErrorCountOperationReader struct {
+	*dependencyErrorCountReader
+	operationReader *dependencyOperationReader
+}
+
+// GetOperationDependencies implements dependencystore.OperationDependencyReader#GetOperationDependencies
+func (r *dependencyErrorCountOperationReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	return r.operationReader.GetOperationDependencies(ctx, service, endTs, lookback)
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package slowquery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/jaegertracing/jaeger/storage"
+	"github.com/jaegertracing/jaeger/storage/dependencystore"
+	depStoreMocks "github.com/jaegertracing/jaeger/storage/dependencystore/mocks"
+	"github.com/jaegertracing/jaeger/storage/spanstore"
+	spanStoreMocks "github.com/jaegertracing/jaeger/storage/spanstore/mocks"
+)
+
+const (
+	threshold = 50 * time.Millisecond
+	delay     = 60 * time.Millisecond
+)
+
+func TestSpanReaderRecordsSlowQueries(t *testing.T) {
+	log, logs := newObservedLog(Options{Threshold: threshold, BufferSize: 10})
+	mockReader := &spanStoreMocks.Reader{}
+	reader := NewSpanReader(mockReader, log)
+	ctx := context.Background()
+	end := time.Now()
+	query := &spanstore.TraceQueryParameters{
+		ServiceName:   "frontend",
+		OperationName: "HTTP GET",
+		Tags:          map[string]string{"error": "true"},
+		StartTimeMin:  end.Add(-time.Hour),
+		StartTimeMax:  end,
+		NumTraces:     20,
+	}
+	traceID := model.NewTraceID(0, 1)
+
+	mockReader.On("FindTraces", ctx, query).Return([]*model.Trace{{}, {}}, nil).After(delay)
+	mockReader.On("FindTraceIDs", ctx, query).Return([]model.TraceID{traceID}, nil)
+	mockReader.On("GetTrace", ctx, traceID).
+		Return(&model.Trace{Spans: []*model.Span{{}, {}, {}}}, nil).After(delay)
+	mockReader.On("GetServices", ctx).Return(nil, errors.New("timeout")).After(delay)
+	mockReader.On("GetOperations", ctx, spanstore.OperationQueryParameters{ServiceName: "frontend"}).
+		Return([]spanstore.Operation{{Name: "HTTP GET"}}, nil)
+
+	traces, err := reader.FindTraces(ctx, query)
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+	_, err = reader.FindTraceIDs(ctx, query)
+	require.NoError(t, err)
+	_, err = reader.GetTrace(ctx, traceID)
+	require.NoError(t, err)
+	_, err = reader.GetServices(ctx)
+	require.EqualError(t, err, "timeout")
+	_, err = reader.GetOperations(ctx, spanstore.OperationQueryParameters{ServiceName: "frontend"})
+	require.NoError(t, err)
+
+	queries := log.Queries()
+	require.Len(t, queries, 3)
+	assert.Equal(t, "get_services", queries[0].Operation)
+	require.EqualError(t, queries[0].Err, "timeout")
+
+	assert.Equal(t, "get_trace", queries[1].Operation)
+	assert.Equal(t, traceID.String(), queries[1].TraceID)
+	assert.Equal(t, 3, queries[1].Results)
+
+	findTraces := queries[2]
+	assert.Equal(t, "find_traces", findTraces.Operation)
+	assert.Equal(t, "frontend", findTraces.Service)
+	assert.Equal(t, "HTTP GET", findTraces.SpanName)
+	assert.Equal(t, time.Hour, findTraces.TimeWindow)
+	assert.Equal(t, 20, findTraces.Limit)
+	assert.Equal(t, 1, findTraces.Tags)
+	assert.Equal(t, 2, findTraces.Results)
+	assert.GreaterOrEqual(t, findTraces.Duration, delay)
+	assert.Equal(t, 3, logs.Len())
+}
+
+func TestSpanReaderRecordsReadAttempt(t *testing.T) {
+	log, logs := newObservedLog(Options{Threshold: threshold, BufferSize: 10})
+	mockReader := &spanStoreMocks.Reader{}
+	reader := NewSpanReader(mockReader, log)
+	ctx := storage.ContextWithReadAttempt(context.Background(), 2)
+	mockReader.On("GetServices", ctx).Return([]string{"frontend"}, nil).After(delay)
+
+	_, err := reader.GetServices(ctx)
+	require.NoError(t, err)
+	queries := log.Queries()
+	require.Len(t, queries, 1)
+	assert.Equal(t, 2, queries[0].Attempt)
+	assert.EqualValues(t, 2, logs.All()[0].ContextMap()["attempt"])
+}
+
+type batchPagedReader struct {
+	spanStoreMocks.Reader
+}
+
+func (r *batchPagedReader) GetTraces(_ context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	time.Sleep(delay)
+	return make([]*model.Trace, len(traceIDs)), nil
+}
+
+func (r *batchPagedReader) GetTracePage(_ context.Context, _ model.TraceID, _ string) (*model.Trace, string, error) {
+	time.Sleep(delay)
+	return &model.Trace{Spans: []*model.Span{{}}}, "next", nil
+}
+
+func (*batchPagedReader) DeleteTrace(context.Context, model.TraceID) error {
+	return nil
+}
+
+func TestSpanReaderOptionalInterfaces(t *testing.T) {
+	log, _ := newObservedLog(Options{Threshold: threshold, BufferSize: 10})
+	ctx := context.Background()
+	traceID := model.NewTraceID(0, 1)
+
+	unsupported := NewSpanReader(&spanStoreMocks.Reader{}, log)
+	_, err := unsupported.GetTraces(ctx, []model.TraceID{traceID})
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	_, _, err = unsupported.GetTracePage(ctx, traceID, "")
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	require.ErrorIs(t, unsupported.DeleteTrace(ctx, traceID), errors.ErrUnsupported)
+	assert.Empty(t, log.Queries())
+
+	reader := NewSpanReader(&batchPagedReader{}, log)
+	traces, err := reader.GetTraces(ctx, []model.TraceID{traceID, traceID})
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+	_, nextPageToken, err := reader.GetTracePage(ctx, traceID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "next", nextPageToken)
+	require.NoError(t, reader.DeleteTrace(ctx, traceID))
+
+	queries := log.Queries()
+	require.Len(t, queries, 2)
+	assert.Equal(t, "get_trace_page", queries[0].Operation)
+	assert.Equal(t, 1, queries[0].Results)
+	assert.Equal(t, "get_traces", queries[1].Operation)
+	assert.Equal(t, 2, queries[1].TraceIDs)
+}
+
+type errorCountReader struct {
+	depStoreMocks.Reader
+}
+
+func (*errorCountReader) GetDependencyErrors(context.Context, time.Time, time.Duration) ([]dependencystore.DependencyLinkErrors, error) {
+	time.Sleep(delay)
+	return []dependencystore.DependencyLinkErrors{{Parent: "a", Child: "b", ErrorCount: 1}}, nil
+}
+
+func TestDependencyReaderRecordsSlowQueries(t *testing.T) {
+	log, _ := newObservedLog(Options{Threshold: threshold, BufferSize: 10})
+	ctx := context.Background()
+	end := time.Now()
+
+	mockReader := &depStoreMocks.Reader{}
+	mockReader.On("GetDependencies", ctx, end, 24*time.Hour).
+		Return([]model.DependencyLink{{Parent: "a", Child: "b", CallCount: 2}}, nil).After(delay)
+	reader := NewDependencyReader(mockReader, log)
+	_, ok := reader.(dependencystore.ErrorCountReader)
+	assert.False(t, ok, "the decorator must not hide that the errors are not counted")
+	dependencies, err := reader.GetDependencies(ctx, end, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, dependencies, 1)
+
+	withErrors := NewDependencyReader(&errorCountReader{}, log)
+	errorCounter, ok := withErrors.(dependencystore.ErrorCountReader)
+	require.True(t, ok)
+	_, err = errorCounter.GetDependencyErrors(ctx, end, time.Hour)
+	require.NoError(t, err)
+
+	queries := log.Queries()
+	require.Len(t, queries, 2)
+	assert.Equal(t, "get_dependency_errors", queries[0].Operation)
+	assert.Equal(t, time.Hour, queries[0].TimeWindow)
+	assert.Equal(t, "get_dependencies", queries[1].Operation)
+	assert.Equal(t, 24*time.Hour, queries[1].TimeWindow)
+	assert.Equal(t, 1, queries[1].Results)
+}
+
+type operationDependencyReader struct {
+	depStoreMocks.Reader
+}
+
+func (*operationDependencyReader) GetOperationDependencies(
+	context.Context,
+	string,
+	time.Time,
+	time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	time.Sleep(delay)
+	return []dependencystore.OperationDependencyLink{{Parent: "a", ParentOperation: "x", Child: "b", ChildOperation: "y", CallCount: 1}}, nil
+}
+
+type errorCountOperationDependencyReader struct {
+	errorCountReader
+}
+
+func (*errorCountOperationDependencyReader) GetOperationDependencies(
+	ctx context.Context,
+	service string,
+	endTs time.Time,
+	lookback time.Duration,
+) ([]dependencystore.OperationDependencyLink, error) {
+	return (&operationDependencyReader{}).GetOperationDependencies(ctx, service, endTs, lookback)
+}
+
+func TestDependencyReaderRecordsSlowOperationDependencyQueries(t *testing.T) {
+	log, _ := newObservedLog(Options{Threshold: threshold, BufferSize: 10})
+	ctx := context.Background()
+	end := time.Now()
+
+	reader := NewDependencyReader(&operationDependencyReader{}, log)
+	_, ok := reader.(dependencystore.ErrorCountReader)
+	assert.False(t, ok, "the decorator must not hide that the errors are not counted")
+	operationReader, ok := reader.(dependencystore.OperationDependencyReader)
+	require.True(t, ok)
+	_, err := operationReader.GetOperationDependencies(ctx, "a", end, time.Hour)
+	require.NoError(t, err)
+
+	reader = NewDependencyReader(&errorCountOperationDependencyReader{}, log)
+	_, ok = reader.(dependencystore.ErrorCountReader)
+	require.True(t, ok)
+	operationReader, ok = reader.(dependencystore.OperationDependencyReader)
+	require.True(t, ok)
+	links, err := operationReader.GetOperationDependencies(ctx, "b", end, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+
+	queries := log.Queries()
+	require.Len(t, queries, 2)
+	for _, query := range queries {
+		assert.Equal(t, "get_operation_dependencies", query.Operation)
+		assert.Equal(t, time.Hour, query.TimeWindow)
+		assert.Equal(t, 1, query.Results)
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, []string{queries[0].Service, queries[1].Service})
+}
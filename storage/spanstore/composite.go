@@ -44,3 +44,32 @@ func (c *CompositeWriter) WriteSpan(ctx context.Context, span *model.Span) error
 	}
 	return errors.Join(errs...)
 }
+
+// PendingWrites returns the largest number of pending writes among the span writers.
+func (c *CompositeWriter) PendingWrites() int64 {
+	var pending int64
+	for _, writer := range c.spanWriters {
+		pending = max(pending, PendingWrites(writer))
+	}
+	return pending
+}
+
+// Flush flushes the span writers able to, it returns errors.ErrUnsupported if none is a WriterFlusher.
+func (c *CompositeWriter) Flush(ctx context.Context) error {
+	var errs []error
+	supported := false
+	for _, writer := range c.spanWriters {
+		err := Flush(ctx, writer)
+		if errors.Is(err, errors.ErrUnsupported) {
+			continue
+		}
+		supported = true
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !supported {
+		return errors.ErrUnsupported
+	}
+	return errors.Join(errs...)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/jaegertracing/jaeger/model"
@@ -41,6 +42,26 @@ func (*noopWriteSpanStore) WriteSpan(context.Context, *model.Span) error {
 	return nil
 }
 
+type pendingWriteSpanStore struct {
+	noopWriteSpanStore
+	pending int64
+}
+
+func (s *pendingWriteSpanStore) PendingWrites() int64 {
+	return s.pending
+}
+
+type flushWriteSpanStore struct {
+	noopWriteSpanStore
+	err     error
+	flushed int
+}
+
+func (s *flushWriteSpanStore) Flush(context.Context) error {
+	s.flushed++
+	return s.err
+}
+
 func TestCompositeWriteSpanStoreSuccess(t *testing.T) {
 	c := spanstore.NewCompositeWriter(&noopWriteSpanStore{}, &noopWriteSpanStore{})
 	require.NoError(t, c.WriteSpan(context.Background(), nil))
@@ -55,3 +76,25 @@ func TestCompositeWriteSpanStoreFirstFailure(t *testing.T) {
 	c := spanstore.NewCompositeWriter(&errProneWriteSpanStore{}, &noopWriteSpanStore{})
 	require.EqualError(t, c.WriteSpan(context.Background(), nil), errIWillAlwaysFail.Error())
 }
+
+func TestCompositeWriterPendingWrites(t *testing.T) {
+	c := spanstore.NewCompositeWriter(&noopWriteSpanStore{}, &pendingWriteSpanStore{pending: 5}, &pendingWriteSpanStore{pending: 3})
+	assert.Equal(t, int64(5), c.PendingWrites())
+	assert.Equal(t, int64(0), spanstore.PendingWrites(&noopWriteSpanStore{}))
+}
+
+func TestCompositeWriterFlush(t *testing.T) {
+	flusher1, flusher2 := &flushWriteSpanStore{}, &flushWriteSpanStore{err: errIWillAlwaysFail}
+	c := spanstore.NewCompositeWriter(&noopWriteSpanStore{}, flusher1, flusher2)
+	require.ErrorIs(t, c.Flush(context.Background()), errIWillAlwaysFail)
+	assert.Equal(t, 1, flusher1.flushed)
+	assert.Equal(t, 1, flusher2.flushed)
+
+	c = spanstore.NewCompositeWriter(&noopWriteSpanStore{}, flusher1)
+	require.NoError(t, c.Flush(context.Background()))
+	assert.Equal(t, 2, flusher1.flushed)
+
+	c = spanstore.NewCompositeWriter(&noopWriteSpanStore{})
+	require.ErrorIs(t, c.Flush(context.Background()), errors.ErrUnsupported)
+	require.ErrorIs(t, spanstore.Flush(context.Background(), &noopWriteSpanStore{}), errors.ErrUnsupported)
+}
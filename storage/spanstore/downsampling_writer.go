@@ -78,6 +78,16 @@ func (ds *DownsamplingWriter) WriteSpan(ctx context.Context, span *model.Span) e
 	return ds.spanWriter.WriteSpan(ctx, span)
 }
 
+// PendingWrites returns the pending writes of the wrapped span writer.
+func (ds *DownsamplingWriter) PendingWrites() int64 {
+	return PendingWrites(ds.spanWriter)
+}
+
+// Flush flushes the wrapped span writer, see spanstore.Flush.
+func (ds *DownsamplingWriter) Flush(ctx context.Context) error {
+	return Flush(ctx, ds.spanWriter)
+}
+
 // hashBytes returns the uint64 hash value of byte slice.
 func (h *hasher) hashBytes() uint64 {
 	h.hash.Reset()
@@ -61,6 +61,41 @@ func TestDownSamplingWriter_WriteSpan(t *testing.T) {
 	require.Error(t, c.WriteSpan(context.Background(), span))
 }
 
+type pendingWriteSpanStore struct {
+	noopWriteSpanStore
+	pending int64
+}
+
+func (s *pendingWriteSpanStore) PendingWrites() int64 {
+	return s.pending
+}
+
+func TestDownSamplingWriter_PendingWrites(t *testing.T) {
+	c := NewDownsamplingWriter(&pendingWriteSpanStore{pending: 7}, DownsamplingOptions{Ratio: 1})
+	assert.Equal(t, int64(7), c.PendingWrites())
+	c = NewDownsamplingWriter(&noopWriteSpanStore{}, DownsamplingOptions{Ratio: 1})
+	assert.Equal(t, int64(0), c.PendingWrites())
+}
+
+type flushWriteSpanStore struct {
+	noopWriteSpanStore
+	flushed bool
+}
+
+func (s *flushWriteSpanStore) Flush(context.Context) error {
+	s.flushed = true
+	return nil
+}
+
+func TestDownSamplingWriter_Flush(t *testing.T) {
+	flusher := &flushWriteSpanStore{}
+	c := NewDownsamplingWriter(flusher, DownsamplingOptions{Ratio: 1})
+	require.NoError(t, c.Flush(context.Background()))
+	assert.True(t, flusher.flushed)
+	c = NewDownsamplingWriter(&noopWriteSpanStore{}, DownsamplingOptions{Ratio: 1})
+	require.ErrorIs(t, c.Flush(context.Background()), errors.ErrUnsupported)
+}
+
 // This test is to make sure h.hash.Reset() works and same traceID will always hash to the same value.
 func TestDownSamplingWriter_hashBytes(t *testing.T) {
 	downsamplingOptions := DownsamplingOptions{
@@ -31,6 +31,39 @@ type Writer interface {
 	WriteSpan(ctx context.Context, span *model.Span) error
 }
 
+// WriterWithBackpressure is a Writer that reports how far the storage is behind,
+// so that callers can slow down when the storage cannot keep up.
+type WriterWithBackpressure interface {
+	Writer
+	// PendingWrites returns the number of written spans not yet persisted by the storage.
+	PendingWrites() int64
+}
+
+// PendingWrites returns the pending writes of writer, or 0 if it does not report backpressure.
+func PendingWrites(writer Writer) int64 {
+	if w, ok := writer.(WriterWithBackpressure); ok {
+		return w.PendingWrites()
+	}
+	return 0
+}
+
+// WriterFlusher is a Writer able to make the spans it accepted visible to the reads,
+// for example so that tests can read the spans they wrote without polling.
+type WriterFlusher interface {
+	Writer
+	// Flush returns once all the spans accepted by WriteSpan before the call are persisted
+	// and visible to the subsequent reads.
+	Flush(ctx context.Context) error
+}
+
+// Flush flushes writer, it returns errors.ErrUnsupported if writer is not a WriterFlusher.
+func Flush(ctx context.Context, writer Writer) error {
+	if w, ok := writer.(WriterFlusher); ok {
+		return w.Flush(ctx)
+	}
+	return errors.ErrUnsupported
+}
+
 // Reader finds and loads traces and other data from storage.
 type Reader interface {
 	// GetTrace retrieves the trace with a given id.
@@ -52,6 +85,8 @@ type Reader interface {
 	// by different spans.
 	//
 	// If no matching traces are found, the function returns (nil, nil).
+	//
+	// Implementations may report the progress of long searches with UpdateProgress.
 	FindTraces(ctx context.Context, query *TraceQueryParameters) ([]*model.Trace, error)
 
 	// FindTraceIDs does the same search as FindTraces, but returns only the list
@@ -61,6 +96,56 @@ type Reader interface {
 	FindTraceIDs(ctx context.Context, query *TraceQueryParameters) ([]model.TraceID, error)
 }
 
+// BatchReader is implemented by the Readers able to load several traces
+// with fewer storage queries than one GetTrace per trace.
+type BatchReader interface {
+	// GetTraces retrieves the traces with the given ids, in no particular order.
+	// The traces that are not found are omitted from the result.
+	GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error)
+}
+
+// PagedReader is implemented by the Readers returning the spans of large traces in several pages.
+type PagedReader interface {
+	// GetTracePage retrieves a page of the spans of the trace with the given id. The first page
+	// is requested with an empty pageToken, and the returned nextPageToken is empty after the last page.
+	//
+	// If no spans are stored for this trace, it returns ErrTraceNotFound.
+	GetTracePage(ctx context.Context, traceID model.TraceID, pageToken string) (page *model.Trace, nextPageToken string, err error)
+}
+
+// TraceDeleter is implemented by the Readers able to delete traces, for example to honor erasure requests.
+type TraceDeleter interface {
+	// DeleteTrace deletes all the spans of the trace with the given id.
+	// Deleting a trace which is not stored is not an error.
+	DeleteTrace(ctx context.Context, traceID model.TraceID) error
+}
+
+// TraceIDPrefixReader is implemented by the Readers able to find traces by a prefix of their IDs,
+// for example to look up the traces whose IDs were truncated in logs.
+type TraceIDPrefixReader interface {
+	// FindTraceIDsByPrefix returns the IDs of at most limit traces whose ID, formatted as by
+	// model.TraceID.String, starts with the prefix of lowercase hexadecimal characters.
+	//
+	// If no matching traces are found, the function returns (nil, nil).
+	FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error)
+}
+
+// TagKeysReader is implemented by the Readers able to list the tag keys of the spans of a service,
+// for example to suggest the tags of the searches.
+type TagKeysReader interface {
+	// GetTagKeys returns the distinct keys of the tags of the spans of the service started within
+	// the time range of the query, in lexicographic order, the first query.Limit keys if it is positive.
+	GetTagKeys(ctx context.Context, query TagKeysQueryParameters) ([]string, error)
+}
+
+// TagKeysQueryParameters contains the parameters of a query of the tag keys of a service.
+type TagKeysQueryParameters struct {
+	ServiceName  string
+	StartTimeMin time.Time
+	StartTimeMax time.Time
+	Limit        int
+}
+
 // TraceQueryParameters contains parameters of a trace query.
 type TraceQueryParameters struct {
 	ServiceName   string
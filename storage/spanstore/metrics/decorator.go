@@ -17,6 +17,7 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jaegertracing/jaeger/model"
@@ -30,6 +31,10 @@ type ReadMetricsDecorator struct {
 	findTracesMetrics    *queryMetrics
 	findTraceIDsMetrics  *queryMetrics
 	getTraceMetrics      *queryMetrics
+	getTracesMetrics     *queryMetrics
+	getTracePageMetrics  *queryMetrics
+	findByPrefixMetrics  *queryMetrics
+	getTagKeysMetrics    *queryMetrics
 	getServicesMetrics   *queryMetrics
 	getOperationsMetrics *queryMetrics
 }
@@ -60,6 +65,10 @@ func NewReadMetricsDecorator(spanReader spanstore.Reader, metricsFactory metrics
 		findTracesMetrics:    buildQueryMetrics("find_traces", metricsFactory),
 		findTraceIDsMetrics:  buildQueryMetrics("find_trace_ids", metricsFactory),
 		getTraceMetrics:      buildQueryMetrics("get_trace", metricsFactory),
+		getTracesMetrics:     buildQueryMetrics("get_traces", metricsFactory),
+		getTracePageMetrics:  buildQueryMetrics("get_trace_page", metricsFactory),
+		findByPrefixMetrics:  buildQueryMetrics("find_trace_ids_by_prefix", metricsFactory),
+		getTagKeysMetrics:    buildQueryMetrics("get_tag_keys", metricsFactory),
 		getServicesMetrics:   buildQueryMetrics("get_services", metricsFactory),
 		getOperationsMetrics: buildQueryMetrics("get_operations", metricsFactory),
 	}
@@ -96,6 +105,68 @@ func (m *ReadMetricsDecorator) GetTrace(ctx context.Context, traceID model.Trace
 	return retMe, err
 }
 
+// GetTraces implements spanstore.BatchReader#GetTraces, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.BatchReader.
+func (m *ReadMetricsDecorator) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	batchReader, ok := m.spanReader.(spanstore.BatchReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	start := time.Now()
+	retMe, err := batchReader.GetTraces(ctx, traceIDs)
+	m.getTracesMetrics.emit(err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
+// GetTracePage implements spanstore.PagedReader#GetTracePage, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.PagedReader.
+func (m *ReadMetricsDecorator) GetTracePage(ctx context.Context, traceID model.TraceID, pageToken string) (*model.Trace, string, error) {
+	pagedReader, ok := m.spanReader.(spanstore.PagedReader)
+	if !ok {
+		return nil, "", errors.ErrUnsupported
+	}
+	start := time.Now()
+	retMe, nextPageToken, err := pagedReader.GetTracePage(ctx, traceID, pageToken)
+	m.getTracePageMetrics.emit(err, time.Since(start), len(retMe.GetSpans()))
+	return retMe, nextPageToken, err
+}
+
+// DeleteTrace implements spanstore.TraceDeleter#DeleteTrace, it returns errors.ErrUnsupported
+// if the underlying reader is not a spanstore.TraceDeleter.
+func (m *ReadMetricsDecorator) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	deleter, ok := m.spanReader.(spanstore.TraceDeleter)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return deleter.DeleteTrace(ctx, traceID)
+}
+
+// FindTraceIDsByPrefix implements spanstore.TraceIDPrefixReader#FindTraceIDsByPrefix, it returns
+// errors.ErrUnsupported if the underlying reader is not a spanstore.TraceIDPrefixReader.
+func (m *ReadMetricsDecorator) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	prefixReader, ok := m.spanReader.(spanstore.TraceIDPrefixReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	start := time.Now()
+	retMe, err := prefixReader.FindTraceIDsByPrefix(ctx, prefix, limit)
+	m.findByPrefixMetrics.emit(err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
+// GetTagKeys implements spanstore.TagKeysReader#GetTagKeys, it returns
+// errors.ErrUnsupported if the underlying reader is not a spanstore.TagKeysReader.
+func (m *ReadMetricsDecorator) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	tagKeysReader, ok := m.spanReader.(spanstore.TagKeysReader)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	start := time.Now()
+	retMe, err := tagKeysReader.GetTagKeys(ctx, query)
+	m.getTagKeysMetrics.emit(err, time.Since(start), len(retMe))
+	return retMe, err
+}
+
 // GetServices implements spanstore.Reader#GetServices
 func (m *ReadMetricsDecorator) GetServices(ctx context.Context) ([]string, error) {
 	start := time.Now()
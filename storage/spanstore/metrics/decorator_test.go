@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jaegertracing/jaeger/internal/metricstest"
 	"github.com/jaegertracing/jaeger/model"
@@ -143,3 +144,153 @@ func TestFailingUnderlyingCalls(t *testing.T) {
 
 	checkExpectedExistingAndNonExistentCounters(t, counters, expecteds, gauges, existingKeys, nonExistentKeys)
 }
+
+type batchReader struct {
+	*mocks.Reader
+}
+
+func (r batchReader) GetTraces(ctx context.Context, traceIDs []model.TraceID) ([]*model.Trace, error) {
+	args := r.Called(ctx, traceIDs)
+	traces, _ := args.Get(0).([]*model.Trace)
+	return traces, args.Error(1)
+}
+
+func TestGetTraces(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	mockReader := &mocks.Reader{}
+	traceIDs := []model.TraceID{{Low: 1}, {Low: 2}}
+
+	_, err := metrics.NewReadMetricsDecorator(mockReader, mf).GetTraces(context.Background(), traceIDs)
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+
+	mrs := metrics.NewReadMetricsDecorator(batchReader{mockReader}, mf)
+	mockReader.On("GetTraces", context.Background(), traceIDs).Return([]*model.Trace{{}}, nil).Once()
+	traces, err := mrs.GetTraces(context.Background(), traceIDs)
+	require.NoError(t, err)
+	assert.Len(t, traces, 1)
+	mockReader.On("GetTraces", context.Background(), traceIDs).Return(nil, errors.New("Failure")).Once()
+	_, err = mrs.GetTraces(context.Background(), traceIDs)
+	require.Error(t, err)
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=get_traces|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=get_traces|result=err"])
+}
+
+type pagedReader struct {
+	*mocks.Reader
+}
+
+func (r pagedReader) GetTracePage(ctx context.Context, traceID model.TraceID, pageToken string) (*model.Trace, string, error) {
+	args := r.Called(ctx, traceID, pageToken)
+	page, _ := args.Get(0).(*model.Trace)
+	return page, args.String(1), args.Error(2)
+}
+
+func TestGetTracePage(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	mockReader := &mocks.Reader{}
+	traceID := model.TraceID{Low: 1}
+
+	_, _, err := metrics.NewReadMetricsDecorator(mockReader, mf).GetTracePage(context.Background(), traceID, "")
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+
+	mrs := metrics.NewReadMetricsDecorator(pagedReader{mockReader}, mf)
+	mockReader.On("GetTracePage", context.Background(), traceID, "").Return(&model.Trace{}, "p2", nil).Once()
+	_, nextPageToken, err := mrs.GetTracePage(context.Background(), traceID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "p2", nextPageToken)
+	mockReader.On("GetTracePage", context.Background(), traceID, "p2").Return(nil, "", errors.New("Failure")).Once()
+	_, _, err = mrs.GetTracePage(context.Background(), traceID, "p2")
+	require.Error(t, err)
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=get_trace_page|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=get_trace_page|result=err"])
+}
+
+type traceDeleter struct {
+	*mocks.Reader
+}
+
+func (r traceDeleter) DeleteTrace(ctx context.Context, traceID model.TraceID) error {
+	return r.Called(ctx, traceID).Error(0)
+}
+
+func TestDeleteTrace(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	mockReader := &mocks.Reader{}
+	traceID := model.TraceID{Low: 1}
+
+	err := metrics.NewReadMetricsDecorator(mockReader, mf).DeleteTrace(context.Background(), traceID)
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+
+	mrs := metrics.NewReadMetricsDecorator(traceDeleter{mockReader}, mf)
+	mockReader.On("DeleteTrace", context.Background(), traceID).Return(nil).Once()
+	require.NoError(t, mrs.DeleteTrace(context.Background(), traceID))
+	mockReader.On("DeleteTrace", context.Background(), traceID).Return(errors.New("Failure")).Once()
+	require.EqualError(t, mrs.DeleteTrace(context.Background(), traceID), "Failure")
+}
+
+type prefixReader struct {
+	*mocks.Reader
+}
+
+func (r prefixReader) FindTraceIDsByPrefix(ctx context.Context, prefix string, limit int) ([]model.TraceID, error) {
+	args := r.Called(ctx, prefix, limit)
+	traceIDs, _ := args.Get(0).([]model.TraceID)
+	return traceIDs, args.Error(1)
+}
+
+func TestFindTraceIDsByPrefix(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	mockReader := &mocks.Reader{}
+
+	_, err := metrics.NewReadMetricsDecorator(mockReader, mf).FindTraceIDsByPrefix(context.Background(), "abcd", 10)
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+
+	mrs := metrics.NewReadMetricsDecorator(prefixReader{mockReader}, mf)
+	mockReader.On("FindTraceIDsByPrefix", context.Background(), "abcd", 10).Return([]model.TraceID{{Low: 1}}, nil).Once()
+	traceIDs, err := mrs.FindTraceIDsByPrefix(context.Background(), "abcd", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []model.TraceID{{Low: 1}}, traceIDs)
+	mockReader.On("FindTraceIDsByPrefix", context.Background(), "abcd", 10).Return(nil, errors.New("Failure")).Once()
+	_, err = mrs.FindTraceIDsByPrefix(context.Background(), "abcd", 10)
+	require.Error(t, err)
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=find_trace_ids_by_prefix|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=find_trace_ids_by_prefix|result=err"])
+}
+
+type tagKeysReader struct {
+	*mocks.Reader
+}
+
+func (r tagKeysReader) GetTagKeys(ctx context.Context, query spanstore.TagKeysQueryParameters) ([]string, error) {
+	args := r.Called(ctx, query)
+	keys, _ := args.Get(0).([]string)
+	return keys, args.Error(1)
+}
+
+func TestGetTagKeys(t *testing.T) {
+	mf := metricstest.NewFactory(0)
+	mockReader := &mocks.Reader{}
+	query := spanstore.TagKeysQueryParameters{ServiceName: "foo", Limit: 10}
+
+	_, err := metrics.NewReadMetricsDecorator(mockReader, mf).GetTagKeys(context.Background(), query)
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+
+	mrs := metrics.NewReadMetricsDecorator(tagKeysReader{mockReader}, mf)
+	mockReader.On("GetTagKeys", context.Background(), query).Return([]string{"http.method"}, nil).Once()
+	keys, err := mrs.GetTagKeys(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http.method"}, keys)
+	mockReader.On("GetTagKeys", context.Background(), query).Return(nil, errors.New("Failure")).Once()
+	_, err = mrs.GetTagKeys(context.Background(), query)
+	require.Error(t, err)
+
+	counters, _ := mf.Snapshot()
+	assert.EqualValues(t, 1, counters["requests|operation=get_tag_keys|result=ok"])
+	assert.EqualValues(t, 1, counters["requests|operation=get_tag_keys|result=err"])
+}
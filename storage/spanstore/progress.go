@@ -0,0 +1,49 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"sync"
+)
+
+// SearchProgress describes how far a search for traces has gone.
+type SearchProgress struct {
+	// BucketsScanned is the number of index partitions, such as time buckets or indices, already searched.
+	BucketsScanned int `json:"bucketsScanned"`
+	// TracesFound is the number of traces matching the search found so far.
+	TracesFound int `json:"tracesFound"`
+	// TracesLoaded is the number of matching traces already loaded.
+	TracesLoaded int `json:"tracesLoaded"`
+}
+
+// ProgressFunc is called with the progress of a search each time it changes.
+type ProgressFunc func(SearchProgress)
+
+type progressContextKey struct{}
+
+type progressReporter struct {
+	mu       sync.Mutex
+	progress SearchProgress
+	report   ProgressFunc
+}
+
+// ContextWithProgress returns a context making the Reader searches done with it report their progress to fn.
+// fn is never called concurrently. Reporting progress is optional for the Reader implementations.
+func ContextWithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, &progressReporter{report: fn})
+}
+
+// UpdateProgress applies update to the progress of the search done with ctx, and reports it.
+// It does nothing if the context was not created with ContextWithProgress.
+func UpdateProgress(ctx context.Context, update func(p *SearchProgress)) {
+	reporter, ok := ctx.Value(progressContextKey{}).(*progressReporter)
+	if !ok {
+		return
+	}
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	update(&reporter.progress)
+	reporter.report(reporter.progress)
+}
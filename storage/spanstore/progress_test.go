@@ -0,0 +1,35 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateProgress(t *testing.T) {
+	var reports []SearchProgress
+	ctx := ContextWithProgress(context.Background(), func(p SearchProgress) {
+		reports = append(reports, p)
+	})
+	UpdateProgress(ctx, func(p *SearchProgress) {
+		p.BucketsScanned++
+		p.TracesFound = 3
+	})
+	UpdateProgress(ctx, func(p *SearchProgress) {
+		p.TracesLoaded += 2
+	})
+	assert.Equal(t, []SearchProgress{
+		{BucketsScanned: 1, TracesFound: 3},
+		{BucketsScanned: 1, TracesFound: 3, TracesLoaded: 2},
+	}, reports)
+}
+
+func TestUpdateProgressWithoutReporter(t *testing.T) {
+	UpdateProgress(context.Background(), func(*SearchProgress) {
+		assert.Fail(t, "the progress must not be updated without a reporter")
+	})
+}
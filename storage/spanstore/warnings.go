@@ -0,0 +1,49 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type warningsContextKey struct{}
+
+type warnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// ContextWithWarnings returns a context in which the Readers can report non-fatal problems with
+// the results of the requests done with it, e.g. partial results, with AddWarning.
+// The warnings are retrieved with GetWarnings.
+func ContextWithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsContextKey{}, &warnings{})
+}
+
+// AddWarning reports a warning about the request of the context, once per message.
+// It does nothing if the context was not created with ContextWithWarnings.
+func AddWarning(ctx context.Context, message string) {
+	w, ok := ctx.Value(warningsContextKey{}).(*warnings)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !slices.Contains(w.messages, message) {
+		w.messages = append(w.messages, message)
+	}
+}
+
+// GetWarnings returns the warnings reported about the request of the context, in the order they were reported.
+func GetWarnings(ctx context.Context) []string {
+	w, ok := ctx.Value(warningsContextKey{}).(*warnings)
+	if !ok {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.messages)
+}
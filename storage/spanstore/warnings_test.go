@@ -0,0 +1,24 @@
+// Copyright (c) 2024 The Jaeger Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package spanstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarnings(t *testing.T) {
+	ctx := context.Background()
+	AddWarning(ctx, "ignored")
+	assert.Nil(t, GetWarnings(ctx))
+
+	ctx = ContextWithWarnings(ctx)
+	assert.Empty(t, GetWarnings(ctx))
+	AddWarning(ctx, "first")
+	AddWarning(ctx, "second")
+	AddWarning(ctx, "first")
+	assert.Equal(t, []string{"first", "second"}, GetWarnings(ctx))
+}